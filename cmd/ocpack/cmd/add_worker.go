@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"ocpack/pkg/day2"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// addWorkerCmd 表示 add-worker 命令
+var addWorkerCmd = &cobra.Command{
+	Use:   "add-worker [集群名称]",
+	Short: "集群安装完成后添加 worker 节点",
+	Long: `add-worker 命令用于在集群安装完成后扩容 worker 节点。
+
+此命令将执行以下操作：
+1. 将新节点追加到 config.toml 的 [[cluster.worker]] 中
+2. 生成 add-worker/<节点名称>/nodes-config.yaml
+3. 使用 oc adm node-image create 基于集群现有的 ignition 和 CA 生成节点 ISO (或 PXE 文件)
+4. 打印 CSR 批准步骤，或在指定 --approve-csr 时通过 kubeconfig 自动批准
+
+注意: 在运行此命令之前，请确保：
+- 集群已安装完成，installation/ignition/auth/kubeconfig 存在
+- PATH 中的 oc 版本为 4.17 及以上
+
+使用方式:
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32 --pxe --approve-csr`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		ip, _ := cmd.Flags().GetString("ip")
+		mac, _ := cmd.Flags().GetString("mac")
+		pxe, _ := cmd.Flags().GetBool("pxe")
+		approveCSR, _ := cmd.Flags().GetBool("approve-csr")
+
+		options := &day2.AddWorkerOptions{
+			Name:       name,
+			IP:         ip,
+			MAC:        mac,
+			PXE:        pxe,
+			ApproveCSR: approveCSR,
+		}
+
+		if err := day2.NewClient().AddWorker(cmd.Context(), clusterName, clusterDir, options); err != nil {
+			return i18n.Errorf("添加 worker 节点失败: %v", err)
+		}
+
+		i18n.Printf("🎉 worker 节点 %s 处理完成!\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addWorkerCmd)
+	withStageHooks(addWorkerCmd, "add_worker")
+
+	addWorkerCmd.Flags().String("name", "", "新 worker 节点的主机名 (必填)")
+	addWorkerCmd.Flags().String("ip", "", "新 worker 节点的 IP 地址 (必填)")
+	addWorkerCmd.Flags().String("mac", "", "新 worker 节点的 MAC 地址 (必填)")
+	addWorkerCmd.Flags().Bool("pxe", false, "生成 PXE 启动文件而不是 ISO")
+	addWorkerCmd.Flags().Bool("approve-csr", false, "通过 kubeconfig 自动批准新节点的 CSR")
+	addWorkerCmd.MarkFlagRequired("name")
+	addWorkerCmd.MarkFlagRequired("ip")
+	addWorkerCmd.MarkFlagRequired("mac")
+}
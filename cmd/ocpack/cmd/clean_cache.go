@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"ocpack/pkg/clierr"
 	"ocpack/pkg/config"
 	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/workspace"
 
 	"github.com/spf13/cobra"
 )
@@ -18,24 +21,41 @@ var cleanCacheCmd = &cobra.Command{
 
 This command helps manage the disk space used by oc-mirror operations by:
 - Cleaning cache directories in the cluster directory
+- Pruning the cache down to a size limit, removing the least recently used blobs first
 - Showing cache size and location information
 - Preventing the accumulation of cache files in $HOME/.oc-mirror
 
+The cluster lock is held while cleaning, so the cache of a running save-image or
+load-image is never removed. Use --force-unlock only if the lock holder is gone.
+
+With --max-cache-size the cache is pruned instead of removed: blobs are deleted in
+order of last access until the cache fits, and images whose layers were deleted are
+dropped from the cache so they are copied again on the next run.
+
 Examples:
   # Clean cache for a specific cluster
   ocpack clean-cache my-cluster
 
+  # Prune the cache to 200Gi, keeping the most recently used images
+  ocpack clean-cache my-cluster --max-cache-size 200Gi
+
+  # Show what pruning would remove without deleting anything
+  ocpack clean-cache my-cluster --max-cache-size 200Gi --dry-run
+
   # Show cache information without cleaning
   ocpack clean-cache my-cluster --info
 
   # Clean cache for the cluster specified in config.toml
   ocpack clean-cache --config config.toml`,
-	RunE: runCleanCache,
+	ValidArgsFunction: completeClusterName,
+	RunE:              runCleanCache,
 }
 
 var (
 	cleanCacheConfigPath string
 	showCacheInfo        bool
+	cleanCacheMaxSize    string
+	cleanCacheDryRun     bool
 )
 
 func init() {
@@ -43,6 +63,9 @@ func init() {
 
 	cleanCacheCmd.Flags().StringVarP(&cleanCacheConfigPath, "config", "c", "config.toml", "Path to configuration file")
 	cleanCacheCmd.Flags().BoolVar(&showCacheInfo, "info", false, "Show cache information without cleaning")
+	cleanCacheCmd.Flags().StringVar(&cleanCacheMaxSize, "max-cache-size", "", "Prune the cache down to this size (e.g. 200Gi) instead of removing it")
+	cleanCacheCmd.Flags().BoolVar(&cleanCacheDryRun, "dry-run", false, "Show what would be removed without deleting anything")
+	cleanCacheCmd.Flags().Bool("force-unlock", false, "Remove the cluster lock before cleaning (only if the lock holder is no longer running)")
 }
 
 func runCleanCache(cmd *cobra.Command, args []string) error {
@@ -71,7 +94,7 @@ func runCleanCache(cmd *cobra.Command, args []string) error {
 		// 必须从配置文件加载
 		cfg, err = config.LoadConfig(cleanCacheConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to load configuration: %v", err)
+			return fmt.Errorf("failed to load configuration: %w", err)
 		}
 		clusterName = cfg.ClusterInfo.ClusterID
 		if clusterName == "" {
@@ -117,6 +140,46 @@ func runCleanCache(cmd *cobra.Command, args []string) error {
 			fmt.Printf("\n📄 JSON Output:\n%s\n", jsonData)
 		}
 	} else {
+		// 持有集群锁，避免删除正在运行的 save-image 或 load-image 使用的缓存
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		clusterDir := filepath.Join(projectRoot, clusterName)
+		if _, err := os.Stat(clusterDir); err == nil {
+			unlock, err := lockCluster(cmd, clusterDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+		}
+
+		if cleanCacheMaxSize != "" {
+			maxSize, err := config.ParseSize(cleanCacheMaxSize)
+			if err != nil {
+				return clierr.New(clierr.Config, fmt.Errorf("--max-cache-size: %w", err))
+			}
+			result, err := wrapper.PruneCache(cfg, clusterName, maxSize, cleanCacheDryRun)
+			if err != nil {
+				return fmt.Errorf("failed to prune cache: %v", err)
+			}
+			printPruneResult(clusterName, maxSize, result, cleanCacheDryRun)
+			return nil
+		}
+
+		if cleanCacheDryRun {
+			info, err := wrapper.GetCacheInfo(cfg, clusterName)
+			if err != nil {
+				return fmt.Errorf("failed to get cache info: %v", err)
+			}
+			if info["cache_exists"].(bool) {
+				fmt.Printf("🔍 Would remove cache directory %s (%s)\n", info["cache_dir"], info["cache_size_human"])
+			} else {
+				fmt.Printf("ℹ️  Cache directory does not exist: %s\n", info["cache_dir"])
+			}
+			return nil
+		}
+
 		// 清理缓存
 		fmt.Printf("🧹 Cleaning cache for cluster: %s\n", clusterName)
 
@@ -133,3 +196,26 @@ func runCleanCache(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// printPruneResult 输出按大小上限清理缓存的结果
+func printPruneResult(clusterName string, maxSize int64, result *workspace.CacheResult, dryRun bool) {
+	if result.Blobs == 0 {
+		fmt.Printf("✅ Cache for cluster %s is %s, within the limit of %s, nothing to prune\n",
+			clusterName, workspace.FormatSize(result.SizeBefore), workspace.FormatSize(maxSize))
+		return
+	}
+
+	action := "Removed"
+	if dryRun {
+		action = "Would remove"
+	}
+	fmt.Printf("🧹 Cache for cluster %s is %s, over the limit of %s\n",
+		clusterName, workspace.FormatSize(result.SizeBefore), workspace.FormatSize(maxSize))
+	fmt.Printf("   %s %d least recently used blobs, freeing %s\n", action, result.Blobs, workspace.FormatSize(result.Reclaimed))
+	if len(result.Images) > 0 {
+		fmt.Printf("   %s %d images from the cache, they will be copied again on the next run:\n", action, len(result.Images))
+		for _, image := range result.Images {
+			fmt.Printf("   - %s\n", image)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/pxe"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/workspace"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanRemoteKeep   int
+	cleanRemoteDryRun bool
+)
+
+// cleanRemoteCmd 表示 clean-remote 命令
+var cleanRemoteCmd = &cobra.Command{
+	Use:   "clean-remote [集群名称]",
+	Short: "清理 Bastion 上积累的历史 PXE 启动文件",
+	Long: `setup-pxe 每次上传新的 PXE 文件时，Bastion 上的上传脚本将上一版本移动到
+/var/www/html/pxe/.history/<集群名称>/<上传时间>，便于需要时回退。上传完成后自动清理，
+只保留最新的 bastion.keep_boot_artifacts 个版本 (默认 2)。
+
+clean-remote 通过 SSH 列出并删除超出保留数量的历史版本，当前正在提供服务的
+/var/www/html/pxe/<集群名称> 始终保留。建议先使用 --dry-run 查看将要删除的内容。
+
+使用方式:
+  ocpack clean-remote demo --dry-run
+  ocpack clean-remote demo --keep 0`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if !cfg.BastionEnabled() {
+			return clierr.New(clierr.Config, i18n.Errorf("bastion.enabled = false，PXE 文件不在 Bastion 上，请在站点的 PXE 资源服务器上自行清理"))
+		}
+
+		keep := cfg.GetKeepBootArtifacts()
+		if cmd.Flags().Changed("keep") {
+			keep = cleanRemoteKeep
+		}
+
+		r := runner.NewExecRunner()
+		stale, err := pxe.StaleArtifacts(r, cfg, clusterName, keep)
+		if err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			i18n.Printf("✅ %s 上没有需要清理的历史 PXE 文件 (保留最新 %d 个版本)\n", cfg.Bastion.IP, keep)
+			return nil
+		}
+
+		var reclaimed int64
+		for _, artifact := range stale {
+			reclaimed += artifact.Size
+			fmt.Printf("  %10s  %s\n", workspace.FormatSize(artifact.Size), artifact.Path)
+		}
+		if cleanRemoteDryRun {
+			i18n.Printf("💡 将清理 %d 个历史版本，可释放 %s (--dry-run 未删除任何文件)\n", len(stale), workspace.FormatSize(reclaimed))
+			return nil
+		}
+
+		if err := pxe.RemoveArtifacts(r, cfg, clusterName, stale); err != nil {
+			return err
+		}
+		i18n.Printf("✅ 已清理 %d 个历史版本，释放 %s\n", len(stale), workspace.FormatSize(reclaimed))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanRemoteCmd)
+	cleanRemoteCmd.Flags().IntVar(&cleanRemoteKeep, "keep", config.DefaultKeepBootArtifacts, "保留最新的历史版本数量，默认为 bastion.keep_boot_artifacts")
+	cleanRemoteCmd.Flags().BoolVar(&cleanRemoteDryRun, "dry-run", false, "只列出将要清理的内容，不删除")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/workspace"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanWorkspaceKeep   int
+	cleanWorkspaceDryRun bool
+)
+
+// cleanWorkspaceCmd 表示 clean-workspace 命令
+var cleanWorkspaceCmd = &cobra.Command{
+	Use:   "clean-workspace [集群名称]",
+	Short: "清理 oc-mirror 工作目录中的旧结果、签名和日志",
+	Long: `清理多次执行 save-image/load-image 后在 oc-mirror 工作目录中积累的旧文件，并报告释放的空间。
+
+清理内容:
+  - results-* 目录: 保留最新的 --keep 个非空目录，空目录全部清理
+  - images/working-dir/signatures: 版本不在当前 release 范围内的签名
+  - images/working-dir/logs: 每类日志保留最新的 --keep 个
+
+generate-iso 和 day2 依赖的最新 results 目录、cluster-resources、hold-release、
+hold-operator 和 dry-run 目录始终保留。镜像缓存请使用 clean-cache 清理。
+
+使用方式:
+  ocpack clean-workspace demo --dry-run
+  ocpack clean-workspace demo --keep 2`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		result, err := workspace.Clean(cfg, clusterDir, cleanWorkspaceKeep, cleanWorkspaceDryRun)
+		if err != nil {
+			return err
+		}
+		if len(result.Items) == 0 {
+			i18n.Println("✅ 工作目录中没有需要清理的内容")
+			return nil
+		}
+
+		for _, item := range result.Items {
+			relPath, err := filepath.Rel(clusterDir, item.Path)
+			if err != nil {
+				relPath = item.Path
+			}
+			fmt.Printf("  %-9s %10s  %s\n", item.Kind, workspace.FormatSize(item.Size), relPath)
+		}
+		if cleanWorkspaceDryRun {
+			i18n.Printf("💡 将清理 %d 项，可释放 %s (--dry-run 未删除任何文件)\n", len(result.Items), workspace.FormatSize(result.Reclaimed))
+			return nil
+		}
+		i18n.Printf("✅ 已清理 %d 项，释放 %s\n", len(result.Items), workspace.FormatSize(result.Reclaimed))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanWorkspaceCmd)
+	cleanWorkspaceCmd.Flags().IntVar(&cleanWorkspaceKeep, "keep", 1, "保留最新的 results 目录和每类日志的数量")
+	cleanWorkspaceCmd.Flags().BoolVar(&cleanWorkspaceDryRun, "dry-run", false, "只列出将要清理的内容，不删除")
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"os"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/ocpack"
+)
+
+// newClient 创建以当前目录为项目目录、输出到标准输出的 ocpack.Client
+func newClient(verbosity progress.Verbosity) (*ocpack.Client, error) {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return nil, i18n.Errorf("获取当前目录失败: %v", err)
+	}
+	return ocpack.New(ocpack.Options{
+		ProjectRoot: projectRoot,
+		Out:         os.Stdout,
+		Verbosity:   verbosity,
+		Version:     version,
+	})
+}
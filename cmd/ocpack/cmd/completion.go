@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// listClusterNames 返回当前目录下包含 config.toml 的子目录，即 ocpack new cluster 创建的集群
+func listClusterNames() []string {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(entry.Name(), "config.toml")); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// completeClusterName 补全只接受一个集群名称的命令的第一个参数
+func completeClusterName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return listClusterNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClusterNames 补全接受多个集群名称的命令，已输入的集群不再出现在候选中
+func completeClusterNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, name := range listClusterNames() {
+		if !slices.Contains(args, name) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/day2"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// day2Cmd 表示 day2 命令
+var day2Cmd = &cobra.Command{
+	Use:   "day2",
+	Short: "集群安装完成后的 Day2 配置操作",
+	Long: `day2 命令用于在集群安装完成后，将集群配置为使用离线环境中的私有镜像仓库资源。
+
+使用方式:
+  ocpack day2 operatorhub demo
+  ocpack day2 update-service demo
+  ocpack day2 apply-bundle demo ./manifests`,
+}
+
+// day2OperatorHubCmd 表示 day2 operatorhub 命令
+var day2OperatorHubCmd = &cobra.Command{
+	Use:   "operatorhub [集群名称]",
+	Short: "配置 OperatorHub 使用私有镜像仓库中的 CatalogSource",
+	Long: `operatorhub 命令禁用默认的在线 catalog sources，并将 oc-mirror 生成的 CatalogSource 应用到集群。
+
+config.toml 中的每个 Operator 目录 ([save_image] operator_catalog 或 [[save_image.operator_catalogs]])
+都会生成一个独立的 CatalogSource，名称和显示名称可通过 catalog_source_name 和 display_name 设置。
+
+修改前的 OperatorHub 配置记录在集群目录的 .ocpack-operatorhub.json 中。任一步骤失败时自动回滚：
+删除本次应用的 CatalogSource 并恢复默认的 catalog sources，避免集群中没有可用的 catalog。
+使用 --rollback 可以在配置成功后手动撤销。
+
+使用方式:
+  ocpack day2 operatorhub demo
+  ocpack day2 operatorhub demo --rollback`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		if rollback, _ := cmd.Flags().GetBool("rollback"); rollback {
+			if err := day2.NewClient().RollbackOperatorHub(cmd.Context(), clusterName, clusterDir); err != nil {
+				return i18n.Errorf("回滚 OperatorHub 失败: %v", err)
+			}
+			i18n.Println("🎉 OperatorHub 回滚完成!")
+			return nil
+		}
+
+		if err := day2.NewClient().ConfigureOperatorHub(cmd.Context(), clusterName, clusterDir); err != nil {
+			return i18n.Errorf("配置 OperatorHub 失败: %v", err)
+		}
+
+		i18n.Println("🎉 OperatorHub 配置完成!")
+		return nil
+	},
+}
+
+// day2UpdateServiceCmd 表示 day2 update-service 命令
+var day2UpdateServiceCmd = &cobra.Command{
+	Use:   "update-service [集群名称]",
+	Short: "应用 UpdateService 资源，启用离线环境的集群升级推荐",
+	Long: `update-service 命令将 oc-mirror 生成的 UpdateService 资源应用到集群，
+并将 ClusterVersion 的升级源指向集群内的 OpenShift Update Service (OSUS)。
+
+注意: 在运行此命令之前，请确保：
+- config.toml 中已设置 [save_image] graph = true，并重新执行了 save-image 和 load-image
+- 已在 [save_image] ops 中加入 cincinnati-operator 并通过 OperatorHub 完成安装
+
+隔离网络 (enclave) 中配置了 [save_image] update_url_override 时，不在本集群部署 UpdateService，
+直接将 ClusterVersion 的升级源指向该地址 (如上级网络中的 OSUS)。
+
+使用方式:
+  ocpack day2 update-service demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		if err := day2.NewClient().ConfigureUpdateService(cmd.Context(), clusterName, clusterDir); err != nil {
+			return i18n.Errorf("配置 OpenShift Update Service 失败: %v", err)
+		}
+
+		i18n.Println("🎉 OpenShift Update Service 配置完成!")
+		return nil
+	},
+}
+
+// day2ApplyBundleCmd 表示 day2 apply-bundle 命令
+var day2ApplyBundleCmd = &cobra.Command{
+	Use:   "apply-bundle [集群名称] [目录]",
+	Short: "将目录中的 YAML 清单 (NNCP、MachineConfig、Tuned 等) 应用到集群",
+	Long: `apply-bundle 命令读取目录中的 *.yaml 和 *.yml 文件 (按文件名顺序，支持多文档和 kind: List)，
+使用 server-side apply 将其中的资源应用到集群，并等待资源就绪。
+
+应用顺序:
+1. Namespace、CustomResourceDefinition (等待 CRD 变为 Established)
+2. RBAC、ConfigMap、Secret、OperatorGroup、Subscription 及其他资源
+3. MachineConfigPool、MachineConfig、KubeletConfig、ContainerRuntimeConfig、Tuned、PerformanceProfile
+4. NodeNetworkConfigurationPolicy
+
+等待与校验:
+- MachineConfig 等触发 MachineConfigPool 渲染新配置时，等待所有节点完成更新 (节点会依次重启)
+- NodeNetworkConfigurationPolicy 等待 Available 条件
+- 其他资源确认已存在于集群中
+
+使用方式:
+  ocpack day2 apply-bundle demo ./manifests
+  ocpack day2 apply-bundle demo ./manifests --dry-run
+  ocpack day2 apply-bundle demo ./manifests --timeout 90m`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return completeClusterName(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		bundleDir := args[1]
+		if info, err := os.Stat(bundleDir); err != nil || !info.IsDir() {
+			return clierr.New(clierr.Config, i18n.Errorf("清单目录不存在: %s", bundleDir))
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noWait, _ := cmd.Flags().GetBool("no-wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		options := day2.ApplyBundleOptions{
+			DryRun:  dryRun,
+			NoWait:  noWait,
+			Timeout: timeout,
+		}
+
+		if err := day2.NewClient().ApplyBundle(cmd.Context(), clusterName, clusterDir, bundleDir, options); err != nil {
+			return i18n.Errorf("应用清单失败: %v", err)
+		}
+
+		i18n.Println("🎉 清单应用完成!")
+		return nil
+	},
+}
+
+// day2CNVBootSourcesCmd 表示 day2 cnv-boot-sources 命令
+var day2CNVBootSourcesCmd = &cobra.Command{
+	Use:   "cnv-boot-sources [集群名称]",
+	Short: "将 OpenShift Virtualization 的虚拟机启动源指向私有仓库",
+	Long: `cnv-boot-sources 命令为 [save_image] cnv_boot_sources 镜像的启动源生成 HyperConverged 清单，
+以与内置模板同名的 dataImportCronTemplates 覆盖其镜像地址，使离线集群中的 DataImportCron
+从私有仓库导入 RHEL、CentOS Stream 和 Fedora 虚拟机磁盘并更新对应的 DataSource。
+
+清单生成到 <集群名称>/day2/cnv-boot-sources，随后按 apply-bundle 的流程应用到集群。
+执行前需要已安装 OpenShift Virtualization 并创建 HyperConverged。
+
+使用方式:
+  ocpack day2 cnv-boot-sources demo --render-only
+  ocpack day2 cnv-boot-sources demo --dry-run
+  ocpack day2 cnv-boot-sources demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		bundleDir, err := day2.RenderCNVBootSources(clusterDir)
+		if err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+		i18n.Printf("✅ 启动源清单已生成: %s\n", bundleDir)
+
+		if renderOnly, _ := cmd.Flags().GetBool("render-only"); renderOnly {
+			i18n.Println("💡 可使用 ocpack day2 apply-bundle 应用，或去掉 --render-only 重新执行")
+			return nil
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if err := day2.NewClient().ApplyBundle(cmd.Context(), clusterName, clusterDir, bundleDir, day2.ApplyBundleOptions{DryRun: dryRun}); err != nil {
+			return i18n.Errorf("应用启动源清单失败: %v", err)
+		}
+
+		i18n.Println("🎉 OpenShift Virtualization 启动源配置完成!")
+		return nil
+	},
+}
+
+// day2ComputePoolsCmd 表示 day2 compute-pools 命令
+var day2ComputePoolsCmd = &cobra.Command{
+	Use:   "compute-pools [集群名称]",
+	Short: "为 [[cluster.compute_pool]] 中的节点设置角色标签、labels 和 taints",
+	Long: `compute-pools 命令为 [[cluster.compute_pool]] 中每个节点池的 worker 节点设置
+node-role.kubernetes.io/<节点池> 角色标签以及配置的 labels 和 taints，节点随后加入安装时生成的
+同名 MachineConfigPool。已存在的标签和污点会被覆盖，可重复执行。
+
+ocpack mon 在安装完成后会自动执行一次；安装后修改了节点池的 labels 或 taints，或自动执行失败时，
+使用该命令重新应用。
+
+使用方式:
+  ocpack day2 compute-pools demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterDir, err := getDay2ClusterDir(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if len(cfg.Cluster.ComputePools) == 0 {
+			i18n.Println("ℹ️  未配置 [[cluster.compute_pool]]，无需设置")
+			return nil
+		}
+		if err := day2.NewClient().ApplyComputePools(cmd.Context(), clusterDir, cfg); err != nil {
+			return err
+		}
+		i18n.Println("🎉 计算节点池设置完成!")
+		return nil
+	},
+}
+
+// day2PresetsCmd 表示 day2 presets 命令
+var day2PresetsCmd = &cobra.Command{
+	Use:   "presets [集群名称]",
+	Short: "安装 [save_image] presets 中的预置组件 (GitOps、ACM)",
+	Long: `presets 命令为 [save_image] presets 中的预置组件生成 Namespace、OperatorGroup 和 Subscription 清单，
+Subscription 订阅 day2 operatorhub 在集群中创建的私有仓库 CatalogSource，使用目录中的默认通道。
+
+支持的预置组件:
+  gitops  OpenShift GitOps (openshift-gitops-operator，安装到 openshift-gitops-operator)
+  acm     Advanced Cluster Management (advanced-cluster-management 及其依赖的 multicluster-engine，
+          安装到 open-cluster-management)
+
+清单生成到 <集群名称>/day2/presets，随后按 apply-bundle 的流程应用到集群。
+执行前需要已完成 save-image、load-image 和 day2 operatorhub。
+
+使用方式:
+  ocpack day2 presets demo --render-only
+  ocpack day2 presets demo --dry-run
+  ocpack day2 presets demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		bundleDir, err := day2.RenderPresets(clusterDir)
+		if err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+		i18n.Printf("✅ 预置组件清单已生成: %s\n", bundleDir)
+
+		if renderOnly, _ := cmd.Flags().GetBool("render-only"); renderOnly {
+			i18n.Println("💡 可使用 ocpack day2 apply-bundle 应用，或去掉 --render-only 重新执行")
+			return nil
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if err := day2.NewClient().ApplyBundle(cmd.Context(), clusterName, clusterDir, bundleDir, day2.ApplyBundleOptions{DryRun: dryRun}); err != nil {
+			return i18n.Errorf("应用预置组件清单失败: %v", err)
+		}
+
+		i18n.Println("🎉 预置组件的 Subscription 已创建，可使用 oc get csv -A 查看 Operator 安装进度")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(day2Cmd)
+
+	day2Cmd.AddCommand(day2OperatorHubCmd)
+	day2Cmd.AddCommand(day2UpdateServiceCmd)
+	day2OperatorHubCmd.Flags().Bool("rollback", false, "恢复 ocpack 修改前的 OperatorHub 配置，并删除 ocpack 应用的 CatalogSource")
+	withStageHooks(day2OperatorHubCmd, "day2_operatorhub")
+	withStageHooks(day2UpdateServiceCmd, "day2_update_service")
+
+	day2Cmd.AddCommand(day2ApplyBundleCmd)
+	withStageHooks(day2ApplyBundleCmd, "day2_apply_bundle")
+	day2ApplyBundleCmd.Flags().Bool("dry-run", false, "使用 --dry-run=server 校验清单，不修改集群")
+	day2ApplyBundleCmd.Flags().Bool("no-wait", false, "应用后不等待资源就绪")
+	day2ApplyBundleCmd.Flags().Duration("timeout", day2.DefaultBundleTimeout, "等待资源就绪的超时时间")
+
+	day2Cmd.AddCommand(day2CNVBootSourcesCmd)
+	withStageHooks(day2CNVBootSourcesCmd, "day2_cnv_boot_sources")
+	day2CNVBootSourcesCmd.Flags().Bool("render-only", false, "只生成清单，不应用到集群")
+	day2CNVBootSourcesCmd.Flags().Bool("dry-run", false, "使用 --dry-run=server 校验清单，不修改集群")
+
+	day2Cmd.AddCommand(day2ComputePoolsCmd)
+	withStageHooks(day2ComputePoolsCmd, "day2_compute_pools")
+
+	day2Cmd.AddCommand(day2PresetsCmd)
+	withStageHooks(day2PresetsCmd, "day2_presets")
+	day2PresetsCmd.Flags().Bool("render-only", false, "只生成清单，不应用到集群")
+	day2PresetsCmd.Flags().Bool("dry-run", false, "使用 --dry-run=server 校验清单，不修改集群")
+}
+
+// getDay2ClusterDir 获取并检查集群目录
+func getDay2ClusterDir(clusterName string) (string, error) {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return "", i18n.Errorf("获取当前目录失败: %v", err)
+	}
+
+	clusterDir := filepath.Join(projectRoot, clusterName)
+	if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+		return "", clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+	}
+	return clusterDir, nil
+}
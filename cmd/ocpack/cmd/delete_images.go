@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/gate"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/wrapper"
+
+	"github.com/spf13/cobra"
+)
+
+// deleteImagesCmd 表示 delete-images 命令
+var deleteImagesCmd = &cobra.Command{
+	Use:   "delete-images [集群名称]",
+	Short: "从私有仓库删除指定 Operator 版本的镜像",
+	Long: `delete-images 为指定 Operator 的版本范围生成 DeleteImageSetConfiguration，使用内置 oc-mirror 的
+delete 功能从私有仓库删除这些版本的 bundle 镜像和相关镜像，用于弃用 Operator 或旧版本后回收存储空间。
+
+此命令将执行以下操作：
+1. 在 config.toml 的 Operator 目录中查找该 Operator 所在的目录 (可用 --catalog 指定)
+2. 根据 load-image 工作目录中的目录数据解析出版本范围内的 bundle 及其相关镜像，
+   写入 images/working-dir/working-dir/delete/delete-images-<operator>.yaml
+3. 从私有仓库和本地缓存中删除列表中的镜像 (--dry-run 时跳过)
+
+--version 的格式: 5.8.3 (单个版本)、5.6.0..5.7.9、..5.7.9 或 5.6.0.. (均包含两端)，
+不指定时删除该 Operator 的全部版本。建议先使用 --dry-run 检查生成的镜像列表；
+仍需保留的版本共用的相关镜像同样会被删除，删除后请重新执行 load-image 补齐。
+
+使用方式:
+  ocpack delete-images demo --operator cluster-logging --version ..5.7.9 --dry-run
+  ocpack delete-images demo --operator cluster-logging --version 5.6.0..5.7.9
+  ocpack delete-images demo --operator odf-operator --catalog certified --channel stable-4.14`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		operator, _ := cmd.Flags().GetString("operator")
+		version, _ := cmd.Flags().GetString("version")
+		channel, _ := cmd.Flags().GetString("channel")
+		catalog, _ := cmd.Flags().GetString("catalog")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		forceCacheDelete, _ := cmd.Flags().GetBool("force-cache-delete")
+		skipChecks, _ := cmd.Flags().GetBool("skip-checks")
+		port, _ := cmd.Flags().GetUint16("port")
+
+		if _, _, err := wrapper.ParseVersionRange(version); err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if cfg.IsProxyCache() {
+			return clierr.New(clierr.Config, i18n.Errorf("[registry] mirror_mode = \"proxy-cache\" 时镜像由 Registry 拉取代理按需缓存，请在 Registry 中直接清理"))
+		}
+		if _, err := cfg.FindOperatorCatalog(operator, catalog); err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+
+		if version == "" {
+			i18n.Printf("⚠️  未指定 --version，将删除 %s 的全部版本\n", operator)
+		}
+		if !dryRun && !skipChecks {
+			i18n.Println("🔍 执行就绪检查...")
+			if err := gate.Run(cfg, gate.BeforeLoadImage(clusterDir)); err != nil {
+				return err
+			}
+		}
+
+		registryHost := cfg.GetMirrorDestination()
+		mirrorWrapper := newMirrorWrapper(mirrorVerbosity(cmd))
+		deleteFile, err := mirrorWrapper.DeleteOperatorImages(cfg, "docker://"+registryHost, &wrapper.DeleteOptions{
+			ClusterName:      clusterName,
+			Operator:         operator,
+			Catalog:          catalog,
+			Channel:          channel,
+			Version:          version,
+			Port:             port,
+			DryRun:           dryRun,
+			ForceCacheDelete: forceCacheDelete,
+		})
+		if err != nil {
+			return i18n.Errorf("删除镜像失败: %w", err)
+		}
+
+		switch {
+		case deleteFile == "":
+			i18n.Printf("✅ %s 中没有 %s 匹配的镜像\n", registryHost, operator)
+		case dryRun:
+			i18n.Printf("📋 待删除的镜像列表: %s\n", deleteFile)
+			i18n.Printf("💡 确认后移除 --dry-run 执行删除\n")
+		default:
+			i18n.Printf("✅ 已从 %s 删除 %s 的镜像，镜像列表: %s\n", registryHost, operator, deleteFile)
+			i18n.Printf("💡 Registry 的存储空间在其垃圾回收后释放\n")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteImagesCmd)
+	withStageReport(deleteImagesCmd, "delete_images")
+	withClusterLock(deleteImagesCmd)
+
+	addMirrorOutputFlags(deleteImagesCmd)
+	deleteImagesCmd.Flags().String("operator", "", "要删除的 Operator (package 名称)")
+	deleteImagesCmd.Flags().String("version", "", "版本范围，如 5.8.3、5.6.0..5.7.9、..5.7.9 或 5.6.0..，默认为全部版本")
+	deleteImagesCmd.Flags().String("channel", "", "只删除该通道中的版本")
+	deleteImagesCmd.Flags().String("catalog", "", "Operator 所在的目录地址或简称 (redhat、certified 等)，默认为 ops 中包含该 Operator 的目录")
+	deleteImagesCmd.Flags().Bool("dry-run", false, "只生成待删除的镜像列表，不删除")
+	deleteImagesCmd.Flags().Bool("force-cache-delete", false, "同时对本地缓存执行垃圾回收，释放不再被引用的 blob")
+	deleteImagesCmd.Flags().Bool("skip-checks", false, "跳过 registry 健康状态和认证检查")
+	deleteImagesCmd.Flags().Uint16("port", 0, portFlagUsage)
+	deleteImagesCmd.MarkFlagRequired("operator")
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/deploy"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/pipeline"
+
+	"github.com/spf13/cobra"
+)
+
+// deployInfraCmd 表示 deploy-infra 命令
+var deployInfraCmd = &cobra.Command{
+	Use:   "deploy-infra [集群名称]",
+	Short: "并行部署 Bastion 和 Registry 节点",
+	Long: `同时部署 Bastion 和 Registry 节点。
+
+两个节点相互独立，并行执行 deploy-bastion 和 deploy-registry 可以将部署时间缩短约一半。
+两者的输出以 [bastion] 和 [registry] 为前缀交错显示，任一节点失败不会中断另一个节点的部署，
+结束后汇总报告全部失败。bastion.enabled = false 时只部署 Registry 节点。
+
+deploy_bastion 和 deploy_registry 的 pre_ 钩子在部署开始前执行，post_ 钩子在对应节点部署成功后执行。
+
+使用方式:
+  ocpack deploy-infra demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		configPath := filepath.Join(clusterDir, "config.toml")
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		// 在启动任一部署之前完成全部验证，避免一个节点部署到一半时另一个节点才报告配置错误
+		downloadDir := cfg.GetDownloadDir(clusterDir)
+		if cfg.BastionEnabled() {
+			if err := config.ValidateBastionConfig(cfg); err != nil {
+				return clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+			}
+		}
+		if err := config.ValidateRegistryConfigWithDownloads(cfg, downloadDir); err != nil {
+			return clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+		}
+
+		// infraStage 一个部署阶段及其在 [hooks] 中对应的阶段
+		type infraStage struct {
+			pipeline.Stage
+			hookStage string
+		}
+		var stages []infraStage
+		if cfg.BastionEnabled() {
+			stages = append(stages, infraStage{pipeline.Stage{Name: "bastion", Run: func(out io.Writer) error {
+				deployer := deploy.NewBastionDeployer(cfg, downloadDir)
+				deployer.Out = out
+				return deployer.Deploy(cmd.Context(), configPath)
+			}}, "deploy_bastion"})
+		} else {
+			i18n.Println("ℹ️  bastion.enabled = false，跳过 Bastion 部署")
+		}
+		stages = append(stages, infraStage{pipeline.Stage{Name: "registry", Run: func(out io.Writer) error {
+			return deploy.DeployRegistryTo(cmd.Context(), out, cfg, configPath)
+		}}, "deploy_registry"})
+
+		var pipelineStages []pipeline.Stage
+		for _, stage := range stages {
+			if err := runStageHooks(cmd.Context(), clusterName, config.HookPre, stage.hookStage); err != nil {
+				return err
+			}
+			pipelineStages = append(pipelineStages, stage.Stage)
+		}
+
+		i18n.Printf("开始部署 %d 个节点...\n", len(pipelineStages))
+		err = pipeline.RunParallel(os.Stdout, pipelineStages...)
+
+		// 已成功部署的节点仍然执行 post_ 钩子
+		for _, stage := range stages {
+			if pipeline.Failed(err, stage.Name) {
+				continue
+			}
+			if hookErr := runStageHooks(cmd.Context(), clusterName, config.HookPost, stage.hookStage); hookErr != nil {
+				return hookErr
+			}
+		}
+		if err != nil {
+			return i18n.Errorf("节点部署失败:\n%v", err)
+		}
+
+		i18n.Println("节点部署成功！")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deployInfraCmd)
+	withClusterLock(deployInfraCmd)
+}
@@ -1,11 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-	"ocpack/pkg/config"
-	"ocpack/pkg/deploy"
-	"os"
-	"path/filepath"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
 
 	"github.com/spf13/cobra"
 )
@@ -21,62 +18,31 @@ var deployRegistryCmd = &cobra.Command{
 - OpenShift 工具 (oc, kubectl, oc-mirror)
 - Quay 镜像仓库
 
-使用方式:
-  ocpack deploy-registry demo`,
-	Args: cobra.ExactArgs(1), // 必须提供一个集群名参数
-	Run: func(cmd *cobra.Command, args []string) {
-		clusterName := args[0]
-
-		// 获取当前工作目录作为项目根目录
-		projectRoot, err := os.Getwd()
-		if err != nil {
-			fmt.Printf("获取当前目录失败: %v\n", err)
-			return
-		}
+mirror-registry 离线安装包优先使用下载目录中的文件，不存在时使用 save-image 在
+[save_image] mirror_registry = true 时随镜像归档的副本，重建 Registry 无需访问互联网。
 
-		// 检查集群目录是否存在
-		clusterDir := filepath.Join(projectRoot, clusterName)
-		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
-			fmt.Printf("集群目录不存在: %s\n", clusterDir)
-			return
-		}
-
-		configPath := filepath.Join(clusterDir, "config.toml")
-
-		// 检查配置文件是否存在
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			fmt.Printf("配置文件不存在: %s\n", configPath)
-			return
-		}
+部署前检查 storage_path 的可用空间是否足够存放镜像归档，配置 [registry.storage] device 时
+在该磁盘上创建文件系统并挂载到 storage_path，可选设置 XFS 项目配额。
 
-		fmt.Printf("使用集群配置文件: %s\n", configPath)
-
-		// 加载配置
-		cfg, err := config.LoadConfig(configPath)
+使用方式:
+  ocpack deploy-registry demo`,
+	Args:              cobra.ExactArgs(1), // 必须提供一个集群名参数
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient(progress.Normal)
 		if err != nil {
-			fmt.Printf("加载配置失败: %v\n", err)
-			return
-		}
-
-		// 获取下载目录
-		downloadDir := filepath.Join(filepath.Dir(configPath), cfg.Download.LocalPath)
-
-		// 验证 Registry 部署所需的配置和下载文件
-		if err := config.ValidateRegistryConfigWithDownloads(cfg, downloadDir); err != nil {
-			fmt.Printf("配置验证失败: %v\n", err)
-			return
+			return err
 		}
-
-		// 执行部署
-		if err := deploy.DeployRegistry(cfg, configPath); err != nil {
-			fmt.Printf("Registry 节点部署失败: %v\n", err)
-			return
+		if _, err := client.DeployRegistry(cmd.Context(), args[0]); err != nil {
+			return err
 		}
-
-		fmt.Println("Registry 节点部署成功！")
+		i18n.Println("Registry 节点部署成功！")
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(deployRegistryCmd)
+	withStageHooks(deployRegistryCmd, "deploy_registry")
+	withClusterLock(deployRegistryCmd)
 }
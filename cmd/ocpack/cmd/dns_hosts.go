@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/dnshosts"
+	"ocpack/pkg/gate"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var dnsHostsVerify bool
+
+// dnsHostsCmd 表示 dns-hosts 命令
+var dnsHostsCmd = &cobra.Command{
+	Use:   "dns-hosts [集群名称]",
+	Short: "生成集群的 /etc/hosts 片段和 dnsmasq 配置，并检查名称解析",
+	Long: `站点 DNS 无法将集群域委派给 Bastion 时，在 config.toml 中设置 [bastion.dns] mode = "hosts"，
+deploy-bastion 改为在 Bastion 上部署 dnsmasq: 集群域只根据生成的 hosts 文件应答，*.apps 通配到负载均衡，
+其余查询转发到 [bastion.dns] forwarders。
+
+dns-hosts 将以下文件生成到 <集群名称>/dns:
+  hosts          /etc/hosts 片段 (Bastion、Registry、API、常用 *.apps 路由和节点)，可追加到管理员工作站
+  dnsmasq.conf   Bastion 上 /etc/dnsmasq.d 中的集群配置
+
+--verify 通过节点使用的 DNS 服务器 (即 agent-config.yaml 中 dns-resolver 的服务器) 解析全部名称，
+与 generate-iso 前的就绪检查相同。
+
+使用方式:
+  ocpack dns-hosts demo
+  ocpack dns-hosts demo --verify`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		files, err := dnshosts.Write(cfg, clusterDir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			i18n.Printf("✅ 已生成: %s\n", file)
+		}
+		if !cfg.DNSHostsMode() {
+			i18n.Printf("💡 当前 bastion.dns.mode 为 %s，Bastion 使用 named；设置 mode = \"hosts\" 后重新执行 deploy-bastion 改用 dnsmasq\n", cfg.Bastion.DNS.GetMode())
+		}
+
+		if !dnsHostsVerify {
+			return nil
+		}
+		i18n.Printf("🔍 通过 DNS 服务器 %v 检查名称解析...\n", cfg.GetDNSServers())
+		if err := gate.VerifyHostEntries(cfg); err != nil {
+			return err
+		}
+		i18n.Println("✅ 全部名称解析正确")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dnsHostsCmd)
+	dnsHostsCmd.Flags().BoolVar(&dnsHostsVerify, "verify", false, "通过节点使用的 DNS 服务器检查名称解析")
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/doctor"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorLogFiles  []string
+	doctorNoNetwork bool
+)
+
+// doctorCmd 表示 doctor 命令
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [集群名称]",
+	Short: "诊断镜像和认证相关的常见故障",
+	Long: `doctor 命令扫描 oc-mirror 日志 (images/working-dir/logs) 和 --log 指定的文件，
+按已知的故障特征归类错误，并检查本地状态，最后给出针对性的修复建议。
+
+可识别的故障包括: pull-secret 过期、registry.redhat.io 返回 401、私有仓库认证失败、
+x509 证书不被信任、域名解析失败、磁盘空间不足、限流、网络不可达和镜像不存在。
+
+本地检查包括: pull-secret.txt、registry/merged-auth.json、集群目录所在分区的剩余空间，
+以及私有仓库的域名解析、服务状态和证书 (可用 --no-network 跳过)。
+
+发现问题时命令返回非零退出码。
+
+使用方式:
+  ocpack doctor demo
+  ocpack doctor demo --log save-image.log`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		diagnoses, err := doctor.Run(runner.NewExecRunner(), clusterDir, cfg, doctor.Options{LogFiles: doctorLogFiles, NoNetwork: doctorNoNetwork})
+		if err != nil {
+			return err
+		}
+		doctor.Print(diagnoses)
+		if len(diagnoses) > 0 {
+			return i18n.Errorf("发现 %d 个问题", len(diagnoses))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringSliceVar(&doctorLogFiles, "log", nil, "额外扫描的日志文件，如保存下来的命令输出 (可重复指定)")
+	doctorCmd.Flags().BoolVar(&doctorNoNetwork, "no-network", false, "跳过私有仓库的域名解析、连通性和证书检查")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/plain"
+
+	"github.com/spf13/cobra"
+)
+
+// lineArgEditors 支持 +<行号> 参数打开文件并定位到该行的编辑器
+var lineArgEditors = map[string]bool{"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true, "micro": true}
+
+// editCmd 表示 edit 命令
+var editCmd = &cobra.Command{
+	Use:   "edit [集群名称]",
+	Short: "编辑集群的 config.toml 并在保存后验证",
+	Long: `edit 命令使用 $VISUAL 或 $EDITOR (默认 vi) 打开集群的 config.toml，编辑器退出后立即验证配置。
+
+验证失败时输出错误所在的行和列以及附近的内容，并询问是否重新打开编辑器。vi、vim、nano 和 emacs
+重新打开时直接定位到出错的行。语法错误按 TOML 解析器给出的位置定位，其他验证错误按错误信息中的
+配置项 (如 save_image.local_storage_port) 定位，无法定位时只输出错误信息。
+
+使用方式:
+  ocpack edit demo
+  EDITOR=nano ocpack edit demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterDir, err := getDay2ClusterDir(args[0])
+		if err != nil {
+			return err
+		}
+		configPath := filepath.Join(clusterDir, "config.toml")
+		if !fileExists(configPath) {
+			return clierr.New(clierr.Config, i18n.Errorf("配置文件不存在: %s", configPath))
+		}
+
+		editor := editorCommand()
+		input := bufio.NewReader(cmd.InOrStdin())
+		line := 0
+		for {
+			if err := runEditor(editor, configPath, line); err != nil {
+				return clierr.New(clierr.Prereq, i18n.Errorf("执行编辑器 %s 失败: %w", strings.Join(editor, " "), err))
+			}
+
+			problem, err := config.CheckConfigFile(configPath)
+			if err != nil {
+				return clierr.New(clierr.Config, err)
+			}
+			if problem == nil {
+				i18n.Println("✅ 配置验证通过")
+				return nil
+			}
+
+			printConfigProblem(configPath, problem)
+			if !confirm(input, i18n.T("是否重新打开编辑器修改? [Y/n] ")) {
+				return clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %w", problem))
+			}
+			line = problem.Line
+		}
+	},
+}
+
+// editorCommand 返回 $VISUAL 或 $EDITOR 中的编辑器命令和参数，都未设置时使用 vi
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// runEditor 在当前终端中打开文件，line 大于 0 且编辑器支持时定位到该行
+func runEditor(editor []string, path string, line int) error {
+	args := append([]string(nil), editor[1:]...)
+	if line > 0 && lineArgEditors[filepath.Base(editor[0])] {
+		args = append(args, "+"+strconv.Itoa(line))
+	}
+	c := exec.Command(editor[0], append(args, path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = plain.Stdout()
+	c.Stderr = plain.Stderr()
+	return c.Run()
+}
+
+// printConfigProblem 输出验证错误及其在配置文件中的位置
+func printConfigProblem(configPath string, problem *config.ConfigProblem) {
+	if problem.Line == 0 {
+		i18n.Printf("❌ 配置验证失败: %s\n", problem.Message)
+		return
+	}
+	i18n.Printf("❌ 配置验证失败 (%s:%d:%d): %s\n", configPath, problem.Line, problem.Column, problem.Message)
+	fmt.Print(problem.Context)
+}
+
+// confirm 读取一行回答，直接回车或输入 y 时返回 true，输入结束 (如非交互执行) 时返回 false
+func confirm(input *bufio.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, err := input.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
@@ -1,18 +1,17 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-
-	"ocpack/pkg/iso"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/ocpack"
 
 	"github.com/spf13/cobra"
 )
 
 var generateISOCmd = &cobra.Command{
-	Use:   "generate-iso",
-	Short: "生成 OpenShift 安装 ISO 镜像",
+	Use:     "generate-iso",
+	Aliases: []string{"gi"},
+	Short:   "生成 OpenShift 安装 ISO 镜像",
 	Long: `generate-iso 命令用于生成 OpenShift 集群的安装 ISO 镜像。
 
 此命令将执行以下操作：
@@ -36,66 +35,72 @@ var generateISOCmd = &cobra.Command{
 - pull-secret.txt 文件存在
 - 集群配置文件已正确填写
 
-使用方式:
-  ocpack generate-iso demo`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		clusterName := args[0]
+使用 --render-only 可只渲染 install-config.yaml 和 agent-config.yaml 并显示
+与现有文件的差异，不执行 openshift-install，便于在变更管控环境中审阅配置。
 
-		// 获取当前工作目录作为项目根目录
-		projectRoot, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("获取当前目录失败: %v", err)
-		}
+生成 ISO 之前会检查私有仓库中是否已有 release 镜像，以及集群的 api、api-int 和 *.apps
+DNS 记录是否解析到负载均衡，检查失败时终止并给出修复建议 (可使用 --skip-checks 跳过)。
 
-		// 检查集群目录是否存在
-		clusterDir := filepath.Join(projectRoot, clusterName)
-		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
-			return fmt.Errorf("集群目录不存在: %s", clusterDir)
-		}
+节点配置了 kernel_args 或 console 时，参数相同的节点共用一个追加了这些内核参数的 ISO 副本
+(<集群名称>-agent-<节点名称>.x86_64.iso，需要 coreos-installer)，其他节点使用默认 ISO。
 
-		fmt.Printf("开始为集群 %s 生成 ISO 镜像\n", clusterName)
+使用 --unconfigured 生成 late-binding 所需的镜像 (OpenShift 4.14+，需要 coreos-installer):
+  - unconfigured-agent.x86_64.iso: 嵌入 unconfigured ignition 的 RHCOS ISO，不含集群配置，
+    使用同一私有仓库的多个集群可以复用
+  - <集群名称>-agentconfig.noarch.iso: 集群配置镜像，发布到 Bastion 的
+    http://<bastion>:8080/agent/<集群名称>/agentconfig.noarch.iso
+节点从前者启动后通过 BMC 虚拟介质挂载配置镜像，在启动时绑定到集群。
+RHCOS 基础 ISO 默认取自 openshift-install 的缓存，也可以通过 --base-iso 指定。
 
-		// 创建 ISO 生成器
-		generator, err := iso.NewISOGenerator(clusterName, projectRoot)
+使用方式:
+  ocpack generate-iso demo
+  ocpack generate-iso demo --render-only
+  ocpack generate-iso demo --unconfigured`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 获取命令行选项
+		opts := ocpack.GenerateISOOptions{}
+		opts.OutputPath, _ = cmd.Flags().GetString("output")
+		opts.BaseISOPath, _ = cmd.Flags().GetString("base-iso")
+		opts.SkipVerify, _ = cmd.Flags().GetBool("skip-verify")
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		opts.RenderOnly, _ = cmd.Flags().GetBool("render-only")
+		opts.SkipChecks, _ = cmd.Flags().GetBool("skip-checks")
+		opts.Unconfigured, _ = cmd.Flags().GetBool("unconfigured")
+
+		client, err := newClient(progress.Normal)
 		if err != nil {
-			return fmt.Errorf("创建 ISO 生成器失败: %v", err)
+			return err
 		}
-
-		// 获取命令行选项
-		outputPath, _ := cmd.Flags().GetString("output")
-		baseISOPath, _ := cmd.Flags().GetString("base-iso")
-		skipVerify, _ := cmd.Flags().GetBool("skip-verify")
-		force, _ := cmd.Flags().GetBool("force")
-
-		// 构建生成选项
-		options := &iso.GenerateOptions{
-			OutputPath:  outputPath,
-			BaseISOPath: baseISOPath,
-			SkipVerify:  skipVerify,
-			Force:       force,
+		result, err := client.GenerateISO(cmd.Context(), args[0], opts)
+		if err != nil {
+			return err
 		}
-
-		// 执行 ISO 生成
-		if err := generator.GenerateISO(options); err != nil {
-			return fmt.Errorf("ISO 生成失败: %v", err)
+		if opts.RenderOnly || opts.Unconfigured {
+			return nil
 		}
 
-		fmt.Println("ISO 生成完成!")
-		fmt.Printf("📁 安装文件位置: %s/installation/\n", clusterDir)
-		fmt.Printf("💿 ISO 文件位置: %s/installation/iso/\n", clusterDir)
-		fmt.Printf("🔧 Ignition 文件位置: %s/installation/ignition/\n", clusterDir)
-
+		i18n.Println("ISO 生成完成!")
+		i18n.Printf("📁 安装文件位置: %s/\n", result.InstallDir)
+		i18n.Printf("💿 ISO 文件位置: %s/\n", result.ISODir)
+		i18n.Printf("🔧 Ignition 文件位置: %s/\n", result.IgnitionDir)
 		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(generateISOCmd)
+	withStageHooks(generateISOCmd, "generate_iso")
+	withClusterLock(generateISOCmd)
 
 	// 添加命令行参数
 	generateISOCmd.Flags().StringP("output", "o", "", "指定输出目录 (可选)")
-	generateISOCmd.Flags().StringP("base-iso", "b", "", "指定基础 ISO 路径 (可选)")
+	generateISOCmd.Flags().StringP("base-iso", "b", "", "指定 RHCOS 基础 ISO 路径 (可选，用于 --unconfigured)")
 	generateISOCmd.Flags().BoolP("skip-verify", "", false, "跳过镜像验证步骤")
 	generateISOCmd.Flags().BoolP("force", "f", false, "强制重新生成，覆盖现有 ISO 文件")
+	generateISOCmd.Flags().BoolP("render-only", "", false, "只渲染配置文件并显示差异，不执行 openshift-install")
+	generateISOCmd.Flags().Bool("skip-checks", false, "跳过 release 镜像和 DNS 就绪检查")
+	generateISOCmd.Flags().Bool("unconfigured", false, "生成不含集群配置的 ISO 和单独的配置镜像 (late-binding)")
+	generateISOCmd.MarkFlagsMutuallyExclusive("unconfigured", "render-only")
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/hooks"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// withStageHooks 为命令注册 config.toml 中 [hooks] 配置的阶段钩子：
+// pre_<stage> 在命令执行前运行，失败时命令不会执行；post_<stage> 仅在命令成功后运行
+// 命令接受多个集群名称时按顺序为每个集群执行钩子。命令的耗时和结果同时记录到阶段报告中
+func withStageHooks(cmd *cobra.Command, stage string) {
+	withStageReport(cmd, stage)
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		for _, clusterName := range args {
+			if err := runStageHooks(cmd.Context(), clusterName, config.HookPre, stage); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cmd.PostRunE = func(cmd *cobra.Command, args []string) error {
+		for _, clusterName := range args {
+			if err := runStageHooks(cmd.Context(), clusterName, config.HookPost, stage); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// runStageHooks 加载集群配置并执行指定时机的钩子
+func runStageHooks(ctx context.Context, clusterName, phase, stage string) error {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	clusterDir := filepath.Join(projectRoot, clusterName)
+	cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		// 集群目录或配置文件无效时交给命令本身报告错误
+		return nil
+	}
+	if err := config.ValidateHooks(cfg); err != nil {
+		return err
+	}
+	return hooks.Run(ctx, runner.NewExecRunner(), cfg, clusterName, clusterDir, phase, stage)
+}
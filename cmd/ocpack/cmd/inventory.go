@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/inventory"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	inventoryOutput     string
+	inventoryFile       string
+	inventoryNoDiscover bool
+)
+
+// inventoryCmd 表示 inventory 命令
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory [集群名称]",
+	Short: "导出集群主机清单",
+	Long: `导出集群主机清单，用于交接给机房运维人员。
+
+清单包含 Bastion、Registry 和全部集群节点的 IP、MAC，以及节点配置中可选的
+bmc_address、serial、location 和 console_url。集群已安装时通过 kubeconfig 执行
+oc get nodes 获取每个节点的状态 (Ready、NotReady、NotJoined) 和 kubelet 版本，
+集群中存在但未在配置中的节点以 node 角色列出。
+
+使用方式:
+  ocpack inventory demo
+  ocpack inventory demo --output csv --file demo-inventory.csv
+  ocpack inventory demo -o json --no-discover`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if err := config.ValidateNodeMetadata(cfg); err != nil {
+			return clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+		}
+
+		inv := inventory.Collect(runner.NewExecRunner(), cfg, clusterDir, !inventoryNoDiscover)
+		for _, note := range inv.Notes {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", note)
+		}
+
+		if inventoryFile == "" {
+			return inventory.Write(os.Stdout, inv, inventoryOutput)
+		}
+		f, err := os.Create(inventoryFile)
+		if err != nil {
+			return i18n.Errorf("创建文件 %s 失败: %v", inventoryFile, err)
+		}
+		defer f.Close()
+		if err := inventory.Write(f, inv, inventoryOutput); err != nil {
+			return err
+		}
+		i18n.Fprintf(os.Stderr, "✅ 主机清单已保存到 %s\n", inventoryFile)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.Flags().StringVarP(&inventoryOutput, "output", "o", "markdown", "输出格式: csv、json 或 markdown")
+	inventoryCmd.Flags().StringVar(&inventoryFile, "file", "", "保存到文件，默认输出到标准输出")
+	inventoryCmd.Flags().BoolVar(&inventoryNoDiscover, "no-discover", false, "不访问集群，只导出配置中的信息")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/kubeconfig"
+
+	"github.com/spf13/cobra"
+)
+
+// kubeconfigCmd 表示 kubeconfig 命令
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig [集群名称]",
+	Short: "输出集群 kubeconfig 的 export 命令，或合并到 ~/.kube/config",
+	Long: `kubeconfig 命令用于管理集群安装生成的 kubeconfig。
+
+默认输出 export 命令，可配合 eval 使用；指定 --merge 时将集群凭据合并到
+~/.kube/config (或 --target 指定的文件)，并以 --context 命名上下文 (默认为集群名称)。
+
+使用方式:
+  eval $(ocpack kubeconfig demo)
+  ocpack kubeconfig demo --merge
+  ocpack kubeconfig demo --merge --context demo-admin`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		kubeconfigPath, err := findClusterKubeconfig(clusterName)
+		if err != nil {
+			return err
+		}
+
+		merge, _ := cmd.Flags().GetBool("merge")
+		if !merge {
+			fmt.Printf("export KUBECONFIG=%s\n", kubeconfigPath)
+			return nil
+		}
+
+		contextName, _ := cmd.Flags().GetString("context")
+		if contextName == "" {
+			contextName = clusterName
+		}
+		target, _ := cmd.Flags().GetString("target")
+		if target == "" {
+			if target, err = kubeconfig.DefaultTargetPath(); err != nil {
+				return err
+			}
+		}
+
+		if err := kubeconfig.Merge(kubeconfigPath, target, contextName, true); err != nil {
+			return i18n.Errorf("合并 kubeconfig 失败: %v", err)
+		}
+		i18n.Printf("✅ 已将集群 %s 合并到 %s，当前上下文: %s\n", clusterName, target, contextName)
+		return nil
+	},
+}
+
+// ocCmd 表示 oc 命令
+var ocCmd = &cobra.Command{
+	Use:   "oc [集群名称] -- [oc 参数]",
+	Short: "使用集群 kubeconfig 执行 oc 命令",
+	Long: `oc 命令使用集群安装生成的 kubeconfig 执行 oc，无需手动设置 KUBECONFIG。
+
+使用方式:
+  ocpack oc demo -- get nodes
+  ocpack oc demo -- get clusterversion`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kubeconfigPath, err := findClusterKubeconfig(args[0])
+		if err != nil {
+			return err
+		}
+		return runWithKubeconfig(kubeconfigPath, "oc", args[1:]...)
+	},
+}
+
+// shellCmd 表示 shell 命令
+var shellCmd = &cobra.Command{
+	Use:   "shell [集群名称]",
+	Short: "启动已设置集群 KUBECONFIG 的子 shell",
+	Long: `shell 命令启动一个新的 shell ($SHELL，默认 /bin/bash)，其中 KUBECONFIG 已指向集群的
+kubeconfig，可直接执行 oc / kubectl。退出 shell 即恢复原环境。
+
+使用方式:
+  ocpack shell demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kubeconfigPath, err := findClusterKubeconfig(args[0])
+		if err != nil {
+			return err
+		}
+
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/bash"
+		}
+		i18n.Printf("🐚 进入集群 %s 的 shell (KUBECONFIG=%s)，输入 exit 退出\n", args[0], kubeconfigPath)
+		return runWithKubeconfig(kubeconfigPath, shell)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kubeconfigCmd)
+	rootCmd.AddCommand(ocCmd)
+	rootCmd.AddCommand(shellCmd)
+
+	kubeconfigCmd.Flags().Bool("merge", false, "合并到 ~/.kube/config 并切换到该上下文")
+	kubeconfigCmd.Flags().String("context", "", "合并时使用的上下文名称 (默认: 集群名称)")
+	kubeconfigCmd.Flags().String("target", "", "合并的目标 kubeconfig 文件 (默认: ~/.kube/config)")
+}
+
+// findClusterKubeconfig 检查集群目录并返回集群的 kubeconfig 路径
+func findClusterKubeconfig(clusterName string) (string, error) {
+	clusterDir, err := getDay2ClusterDir(clusterName)
+	if err != nil {
+		return "", err
+	}
+	return kubeconfig.Find(clusterDir)
+}
+
+// runWithKubeconfig 使用指定 kubeconfig 执行命令，命令失败时以相同的退出码退出
+func runWithKubeconfig(kubeconfigPath, name string, args ...string) error {
+	err := kubeconfig.Command(kubeconfigPath, name, args...).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		restoreOutput()
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// setLanguage 按 --lang 或环境选择输出语言。帮助信息在解析参数时就会输出，
+// 因此在 cobra 解析参数之前预先扫描 args 中的 --lang
+func setLanguage(args []string) error {
+	lang := i18n.Detect()
+	if value, ok := langArg(args); ok {
+		parsed, err := i18n.Parse(value)
+		if err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+		lang = parsed
+	}
+	i18n.SetLang(lang)
+	if lang == i18n.English {
+		localizeCommand(rootCmd)
+	}
+	return nil
+}
+
+// langArg 返回命令行中 --lang 的值，-- 之后的参数不再扫描
+func langArg(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return value, true
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// localizeCommand 翻译命令及其子命令的说明、参数用法和 Use 中的参数说明
+func localizeCommand(cmd *cobra.Command) {
+	if name, args, found := strings.Cut(cmd.Use, " "); found {
+		cmd.Use = name + " " + i18n.T(args)
+	}
+	cmd.Short = i18n.T(cmd.Short)
+	cmd.Long = i18n.T(cmd.Long)
+	localizeFlag := func(f *pflag.Flag) { f.Usage = i18n.T(f.Usage) }
+	cmd.Flags().VisitAll(localizeFlag)
+	cmd.PersistentFlags().VisitAll(localizeFlag)
+	for _, sub := range cmd.Commands() {
+		localizeCommand(sub)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("lang", "", "输出语言: zh 或 en (默认按 OCPACK_LANG、LC_ALL、LC_MESSAGES 和 LANG 判断)")
+}
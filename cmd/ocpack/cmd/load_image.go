@@ -1,109 +1,80 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-
-	"ocpack/pkg/config"
-	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/ocpack"
 
 	"github.com/spf13/cobra"
 )
 
 var loadImageCmd = &cobra.Command{
-	Use:   "load-image",
-	Short: "从本地磁盘加载镜像到 mirror registry",
+	Use:     "load-image",
+	Aliases: []string{"li"},
+	Short:   "从本地磁盘加载镜像到 mirror registry",
 	Long: `load-image 命令将已保存到本地磁盘的 OpenShift 镜像加载到 mirror registry 中。
 
 此命令将执行以下操作：
 1. 读取集群配置文件
-2. 验证本地镜像目录是否存在
-3. 将镜像推送到 registry
+2. 使用 s3:// 存储时下载镜像归档，并验证镜像目录是否存在
+3. 验证 save-image 生成的传输清单：各归档的 sha256，配置了 [save_image.signing] 时还验证签名
+   (可使用 --skip-verify 跳过)
+4. 检查 registry 健康状态和认证 (可使用 --skip-checks 跳过)
+5. 配置了 [registry.quay] manage_organizations = true 时通过 Quay API 创建目标组织和仓库，
+   设置仓库可见性和组织配额
+6. 配置了 [scan] enabled = true 时扫描镜像漏洞，未通过 fail_on 阈值则终止
+7. 将镜像推送到 registry
+
+oc-mirror 日志实时输出，每个阶段开始时输出标题。使用 -v/-vv 输出 debug/trace 日志，
+--quiet 只输出错误和最终摘要。
+
+使用 --images-file 只推送 save-image --images-file 保存在镜像存储 adhoc/ 子目录中的镜像，
+跳过 release 和 Operator；启用 [scan] 时也只扫描列表中的镜像。
+
+推送前先查询 registry 中已有的镜像，跳过 digest 相同的镜像，重复执行时只推送变化的内容；
+使用 --full-copy 重新推送全部镜像。
+
+使用 --only release 先只推送 release，保证窗口关闭前已可安装集群，之后再推送 operators 等其余分组；
+生成的 IDMS/ITMS 和 CatalogSource 仍包含全部镜像。[save_image] mirror_order 按组依次推送。
+
+配置了 [save_image] max_cache_size 或 --max-cache-size 时，推送成功后按最近访问时间删除本地缓存中
+最久未使用的数据，使缓存不超过该大小。
 
 注意: 在运行此命令之前，请确保：
 - 已运行 'ocpack save-image' 命令保存镜像
 - Registry 已正确部署并运行
 
 使用方式:
-  ocpack load-image demo`,
-	Args: cobra.ExactArgs(1),
+  ocpack load-image demo
+  ocpack load-image demo --images-file images.txt
+  ocpack load-image demo --full-copy
+  ocpack load-image demo --only release`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		clusterName := args[0]
-
-		// 获取命令行参数
-		logLevel, _ := cmd.Flags().GetString("log-level")
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		enableRetry, _ := cmd.Flags().GetBool("enable-retry")
-		maxRetries, _ := cmd.Flags().GetInt("max-retries")
-		retryInterval, _ := cmd.Flags().GetInt("retry-interval")
-
-		// 获取当前工作目录作为项目根目录
-		projectRoot, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("获取当前目录失败: %v", err)
-		}
-
-		// 构建配置文件路径
-		configPath := filepath.Join(projectRoot, clusterName, "config.toml")
-
-		// 检查配置文件是否存在
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			return fmt.Errorf("配置文件不存在: %s", configPath)
-		}
-
-		// 检查镜像数据是否存在
-		imagesPath := filepath.Join(projectRoot, clusterName, "images")
-		if _, err := os.Stat(imagesPath); os.IsNotExist(err) {
-			return fmt.Errorf("镜像数据不存在: %s\n请先运行 'ocpack save-image %s'", imagesPath, clusterName)
-		}
-
-		fmt.Printf("🔄 开始从本地磁盘加载镜像到 registry: %s\n", clusterName)
-		fmt.Printf("⚙️  配置文件: %s\n", configPath)
-		if dryRun {
-			fmt.Printf("🔍 干运行模式: 只显示操作而不实际执行\n")
-		}
-
-		// 读取配置
-		cfg, err := config.LoadConfig(configPath)
+		verbosity := mirrorVerbosity(cmd)
+		mirrorOpts, err := mirrorOptions(cmd)
 		if err != nil {
-			return fmt.Errorf("读取配置文件失败: %v", err)
+			return err
 		}
+		opts := ocpack.LoadImagesOptions{MirrorOptions: mirrorOpts}
+		opts.SkipScan, _ = cmd.Flags().GetBool("skip-scan")
+		opts.SkipChecks, _ = cmd.Flags().GetBool("skip-checks")
+		opts.SkipVerify, _ = cmd.Flags().GetBool("skip-verify")
 
-		// 创建镜像包装器
-		mirrorWrapper, err := wrapper.NewMirrorWrapper(logLevel)
+		client, err := newClient(verbosity)
 		if err != nil {
-			return fmt.Errorf("创建镜像服务失败: %v", err)
+			return err
 		}
-
-		// 设置选项
-		opts := &wrapper.MirrorOptions{
-			ClusterName:   clusterName,
-			ConfigPath:    configPath,
-			Port:          55000, // 使用默认端口
-			DryRun:        dryRun,
-			Force:         false,
-			EnableRetry:   enableRetry,
-			MaxRetries:    maxRetries,
-			RetryInterval: retryInterval,
-		}
-
-		// 构建目标仓库地址
-		registryHost := fmt.Sprintf("registry.%s.%s:8443", cfg.ClusterInfo.ClusterID, cfg.ClusterInfo.Domain)
-		destination := fmt.Sprintf("docker://%s", registryHost)
-		source := fmt.Sprintf("file://%s", imagesPath)
-
-		// 执行磁盘到仓库操作
-		err = mirrorWrapper.DiskToMirror(cfg, source, destination, opts)
-		if err != nil {
-			return fmt.Errorf("镜像加载失败: %v", err)
+		result, err := client.LoadImages(cmd.Context(), clusterName, opts)
+		if err != nil || result.Skipped {
+			return err
 		}
-
-		if dryRun {
-			fmt.Printf("✅ 干运行完成！实际操作请移除 --dry-run 参数\n")
-		} else {
-			fmt.Printf("✅ 镜像加载完成！目标仓库: %s\n", registryHost)
-			fmt.Printf("📋 集群资源配置文件已生成在: %s/images/working-dir/cluster-resources/\n", clusterName)
+		if opts.DryRun {
+			i18n.Printf("✅ 干运行完成！实际操作请移除 --dry-run 参数\n")
+		} else if verbosity != progress.Quiet && result.ClusterResourcesDir != "" {
+			i18n.Printf("📋 集群资源配置文件已生成在: %s/\n", result.ClusterResourcesDir)
 		}
 		return nil
 	},
@@ -111,11 +82,23 @@ var loadImageCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(loadImageCmd)
+	withStageHooks(loadImageCmd, "load_image")
+	withClusterLock(loadImageCmd)
 
 	// 保留基本和有用的参数
-	loadImageCmd.Flags().String("log-level", "info", "日志级别 (info, debug, error)")
+	addMirrorOutputFlags(loadImageCmd)
 	loadImageCmd.Flags().Bool("dry-run", false, "只显示操作而不实际执行")
 	loadImageCmd.Flags().Bool("enable-retry", false, "启用重试机制")
 	loadImageCmd.Flags().Int("max-retries", 3, "最大重试次数")
 	loadImageCmd.Flags().Int("retry-interval", 5, "重试间隔时间（秒）")
+	loadImageCmd.Flags().Uint16("port", 0, portFlagUsage)
+	loadImageCmd.Flags().Bool("skip-scan", false, "跳过 [scan] 配置的镜像漏洞扫描")
+	loadImageCmd.Flags().Bool("skip-checks", false, "跳过 registry 健康状态和认证检查")
+	loadImageCmd.Flags().Bool("skip-verify", false, "跳过传输清单 (transfer-manifest.json) 的签名和校验和验证")
+	loadImageCmd.Flags().String("images-file", "", "只推送 save-image --images-file 保存的镜像列表中的镜像")
+	loadImageCmd.Flags().Bool("full-copy", false, fullCopyFlagUsage)
+	loadImageCmd.Flags().StringSlice("only", nil, onlyFlagUsage)
+	loadImageCmd.Flags().Int("copy-attempts", 0, copyAttemptsFlagUsage)
+	loadImageCmd.Flags().String("copy-backoff", "", copyBackoffFlagUsage)
+	loadImageCmd.Flags().String("max-cache-size", "", maxCacheSizeFlagUsage)
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/clusterlock"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// withClusterLock 为长时间运行的命令注册集群锁：命令执行期间持有集群目录中的锁文件，
+// 同一集群上的其他加锁命令直接失败，避免并发修改 oc-mirror 工作目录和集群状态。
+// 锁只在命令本身执行期间持有，[hooks] 中的钩子可以再次调用 ocpack 操作同一集群。
+// --force-unlock 先删除锁文件 (如集群目录位于文件锁不可靠的网络文件系统上) 再获取锁
+func withClusterLock(cmd *cobra.Command) {
+	cmd.Flags().Bool("force-unlock", false, "删除集群锁后再执行 (确认持有锁的命令已不再运行时使用)")
+
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return i18n.Errorf("获取当前目录失败: %v", err)
+		}
+		clusterDir := filepath.Join(projectRoot, args[0])
+		if _, err := os.Stat(filepath.Join(clusterDir, "config.toml")); err != nil {
+			// 集群目录或配置文件无效时交给命令本身报告错误
+			return run(cmd, args)
+		}
+
+		unlock, err := lockCluster(cmd, clusterDir)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return run(cmd, args)
+	}
+}
+
+// lockCluster 获取集群锁，命令设置了 --force-unlock 时先删除已有的锁。返回释放锁的函数
+func lockCluster(cmd *cobra.Command, clusterDir string) (func(), error) {
+	if forceUnlock, _ := cmd.Flags().GetBool("force-unlock"); forceUnlock {
+		holder, err := clusterlock.ForceUnlock(clusterDir)
+		if err != nil {
+			return nil, err
+		}
+		if holder != nil {
+			i18n.Printf("🔓 已删除集群锁: %s\n", holder)
+		}
+	}
+
+	lock, err := clusterlock.Acquire(clusterDir, cmd.Name())
+	if err != nil {
+		var held *clusterlock.HeldError
+		if errors.As(err, &held) {
+			return nil, clierr.New(clierr.Prereq, err)
+		}
+		return nil, err
+	}
+	return func() {
+		if err := lock.Release(); err != nil {
+			i18n.Fprintf(os.Stderr, "⚠️  释放集群锁失败: %v\n", err)
+		}
+	}, nil
+}
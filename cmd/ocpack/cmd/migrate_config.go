@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateConfigDryRun bool
+
+// migrateConfigCmd 表示 migrate-config 命令
+var migrateConfigCmd = &cobra.Command{
+	Use:   "migrate-config [集群名称]",
+	Short: "将 config.toml 升级到当前的格式版本",
+	Long: `将旧版本的 config.toml 升级到当前的格式版本 (config_version)。
+
+其他命令读取旧版本的配置时只在内存中升级并给出提示，不修改文件。本命令在升级前将原文件备份到
+config.toml.bak-<时间>；只需补充 config_version 时保留原有的注释和格式，其他变更会重新生成文件，
+原有注释不保留，请对照备份检查。
+
+使用方式:
+  ocpack migrate-config demo --dry-run
+  ocpack migrate-config demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterDir, err := getDay2ClusterDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		configPath := filepath.Join(clusterDir, "config.toml")
+		result, err := config.MigrateConfigFile(configPath, migrateConfigDryRun)
+		if err != nil {
+			return clierr.New(clierr.Config, i18n.Errorf("升级配置文件失败: %w", err))
+		}
+		if !result.Migrated() {
+			i18n.Printf("✅ 配置文件已是当前版本 %d: %s\n", result.ToVersion, configPath)
+			return nil
+		}
+
+		if migrateConfigDryRun {
+			i18n.Printf("🔍 配置文件将从版本 %d 升级到版本 %d: %s\n", result.FromVersion, result.ToVersion, configPath)
+		} else {
+			i18n.Printf("🔄 配置文件已从版本 %d 升级到版本 %d: %s\n", result.FromVersion, result.ToVersion, configPath)
+		}
+		for _, change := range result.Changes {
+			i18n.Printf("   - %s\n", change)
+		}
+		if result.Rewritten {
+			i18n.Println("⚠️  文件按升级后的内容重新生成，原有注释和键的顺序不保留")
+		}
+		if result.BackupPath != "" {
+			i18n.Printf("   原配置文件已备份到: %s\n", result.BackupPath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateConfigCmd)
+	migrateConfigCmd.Flags().BoolVar(&migrateConfigDryRun, "dry-run", false, "只显示变更，不修改文件")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/ocpack"
+
+	"github.com/spf13/cobra"
+)
+
+// portFlagUsage save-image、load-image 和 plan 共用的 --port 参数说明
+const portFlagUsage = "oc-mirror 本地缓存 registry 的端口 (覆盖 [save_image] local_storage_port)，默认从 55000 起自动选择空闲端口"
+
+// addMirrorOutputFlags 为执行内置 oc-mirror 的命令注册输出控制参数
+func addMirrorOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().String("log-level", "info", "日志级别 (info, debug, trace, error)，-v 和 --quiet 优先")
+	cmd.Flags().CountP("verbose", "v", "输出更详细的日志: -v 输出 debug 日志，-vv 输出 trace 日志")
+	cmd.Flags().BoolP("quiet", "q", false, "只输出错误和最终摘要")
+	cmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+	cmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"info", "debug", "trace", "error"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// mirrorVerbosity 根据 --quiet、-v 和 --log-level 计算日志详细程度
+func mirrorVerbosity(cmd *cobra.Command) progress.Verbosity {
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return progress.Quiet
+	}
+	if verbose, _ := cmd.Flags().GetCount("verbose"); verbose > 0 {
+		if verbose > int(progress.Trace) {
+			return progress.Trace
+		}
+		return progress.Verbosity(verbose)
+	}
+	logLevel, _ := cmd.Flags().GetString("log-level")
+	switch logLevel {
+	case "error":
+		return progress.Quiet
+	case "debug":
+		return progress.Verbose
+	case "trace":
+		return progress.Trace
+	}
+	return progress.Normal
+}
+
+// newMirrorWrapper 创建实时输出 oc-mirror 日志的镜像包装器，每个阶段开始时输出标题
+func newMirrorWrapper(verbosity progress.Verbosity) *wrapper.MirrorWrapper {
+	return wrapper.NewStreamingMirrorWrapper(os.Stdout, verbosity)
+}
+
+const offlineGraphFlagUsage = "只使用之前保存在工作目录中的升级图，不访问 Cincinnati (api.openshift.com)"
+
+const fullCopyFlagUsage = "复制全部镜像，不预先跳过目标中 digest 相同的已有镜像"
+
+const onlyFlagUsage = "只复制这些分组的镜像: release、operators、additional、helm，可指定多个，如 --only release"
+
+const copyAttemptsFlagUsage = "每个镜像复制失败时最多尝试的次数，覆盖 [save_image.retry] attempts (默认 3)"
+
+const copyBackoffFlagUsage = "镜像复制重试的退避方式: linear 或 exponential，覆盖 [save_image.retry] backoff"
+
+const maxCacheSizeFlagUsage = "本地缓存的大小上限，如 200Gi (覆盖 [save_image] max_cache_size)，镜像成功后删除最久未使用的数据"
+
+// mirrorOptions 读取 save-image 和 load-image 共用的参数，指定 --images-file 时读取镜像列表
+func mirrorOptions(cmd *cobra.Command) (ocpack.MirrorOptions, error) {
+	var opts ocpack.MirrorOptions
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Port, _ = cmd.Flags().GetUint16("port")
+	opts.EnableRetry, _ = cmd.Flags().GetBool("enable-retry")
+	opts.MaxRetries, _ = cmd.Flags().GetInt("max-retries")
+	opts.RetryInterval, _ = cmd.Flags().GetInt("retry-interval")
+	opts.FullCopy, _ = cmd.Flags().GetBool("full-copy")
+	opts.Only, _ = cmd.Flags().GetStringSlice("only")
+	opts.CopyAttempts, _ = cmd.Flags().GetInt("copy-attempts")
+	opts.CopyBackoff, _ = cmd.Flags().GetString("copy-backoff")
+	opts.MaxCacheSize, _ = cmd.Flags().GetString("max-cache-size")
+	opts.ImagesFile, _ = cmd.Flags().GetString("images-file")
+	if opts.ImagesFile != "" {
+		images, err := config.ReadImagesFile(opts.ImagesFile)
+		if err != nil {
+			return opts, clierr.New(clierr.Config, err)
+		}
+		opts.Images = images
+	}
+	return opts, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/rpms"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// mirrorRpmsCmd 表示 mirror-rpms 命令
+var mirrorRpmsCmd = &cobra.Command{
+	Use:   "mirror-rpms [集群名称]",
+	Short: "下载 Bastion/Registry/PXE 节点所需的 RPM 软件包并生成离线仓库",
+	Long: `mirror-rpms 命令下载 deploy-bastion、deploy-registry 和 PXE 部署所需的软件包
+(haproxy、bind、podman、httpd 等) 及其全部依赖，并使用 createrepo_c 生成离线 yum 仓库。
+
+仓库保存在下载目录的 rpms/ 中。之后执行部署命令时，playbook 会自动将仓库复制到目标节点，
+并只从该仓库安装软件包，无需目标节点访问外部软件源。
+
+注意: 在运行此命令之前，请确保：
+- 当前主机为联网的 RHEL，且系统版本与目标节点一致 (或在 [rpms] 中设置 release_ver)
+- 已安装 dnf-plugins-core 和 createrepo_c
+- 可在 [rpms] 中设置 repos 限制软件源，或通过 extra_packages 添加额外的软件包
+
+使用方式:
+  ocpack mirror-rpms demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		downloadDir := cfg.GetDownloadDir(clusterDir)
+		if err := rpms.Mirror(cmd.Context(), runner.NewExecRunner(), cfg, downloadDir); err != nil {
+			return i18n.Errorf("下载 RPM 软件包失败: %v", err)
+		}
+
+		i18n.Printf("✅ 离线 RPM 仓库已生成: %s\n", rpms.RepoDir(downloadDir))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorRpmsCmd)
+	withStageHooks(mirrorRpmsCmd, "mirror_rpms")
+}
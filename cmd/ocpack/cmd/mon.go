@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/day2"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/monitor"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+// monCmd 表示 mon 命令
+var monCmd = &cobra.Command{
+	Use:   "mon [集群名称]",
+	Short: "监控集群安装进度",
+	Long: `执行 openshift-install agent wait-for install-complete，等待集群安装完成或失败。
+
+使用 generate-iso 复制到 <集群>/installation/ignition 的安装状态，wait-for 的输出追加到其中的
+.openshift_install.log，可以随后执行 ocpack timeline 查看各安装阶段的耗时。
+安装结束的结果记录为 monitor_install 阶段，配置了 [notify] 时发送通知。
+generate-iso 生成的 ignition 已超过 24 小时时先给出警告: 其中的证书已过期，节点无法完成安装，
+需要执行 ocpack regenerate-iso 并使用新 ISO 重新启动节点。
+配置了 [[cluster.compute_pool]] 时，安装完成后为节点池中的节点设置角色标签、labels 和 taints。
+
+使用方式:
+  ocpack mon demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		if warning := kubeconfig.CredentialWarning(clusterDir, clusterName, time.Now()); warning != "" {
+			i18n.Printf("\n⚠️  警告: %s\n\n", warning)
+		}
+
+		i18n.Printf("👀 监控集群 %s 的安装进度...\n", clusterName)
+		if err := monitor.MonitorCluster(runner.NewExecRunner(), cfg, clusterDir); err != nil {
+			return err
+		}
+
+		// 安装完成后为计算节点池中的节点设置角色标签和污点，使其加入安装时生成的 MachineConfigPool
+		if err := day2.NewClient().ApplyComputePools(cmd.Context(), clusterDir, cfg); err != nil {
+			return i18n.Errorf("集群已安装完成，但设置计算节点池失败: %w\n💡 可执行 ocpack day2 compute-pools %s 重试", err, clusterName)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monCmd)
+	withStageReport(monCmd, "monitor_install")
+}
@@ -1,11 +1,11 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -21,7 +21,7 @@ var newCmd = &cobra.Command{
 	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		if args[0] != "cluster" {
-			fmt.Println("目前只支持 'ocpack new cluster [集群名称]' 命令")
+			i18n.Println("目前只支持 'ocpack new cluster [集群名称]' 命令")
 			return
 		}
 		clusterName := args[1]
@@ -38,14 +38,14 @@ func createNewCluster(clusterName string) {
 	// 1. 创建集群目录
 	clusterDir := clusterName
 	if err := os.MkdirAll(clusterDir, 0755); err != nil {
-		fmt.Printf("创建集群目录失败: %v\n", err)
+		i18n.Printf("创建集群目录失败: %v\n", err)
 		return
 	}
 
 	// 2. 生成并保存默认配置文件
 	configPath := filepath.Join(clusterDir, "config.toml")
 	if err := config.GenerateDefaultConfig(configPath, clusterName); err != nil {
-		fmt.Printf("生成配置文件失败: %v\n", err)
+		i18n.Printf("生成配置文件失败: %v\n", err)
 		return
 	}
 
@@ -57,11 +57,11 @@ func createNewCluster(clusterName string) {
 
 	for _, dir := range dirsToCreate {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			fmt.Printf("创建目录 %s 失败: %v\n", dir, err)
+			i18n.Printf("创建目录 %s 失败: %v\n", dir, err)
 			return
 		}
 	}
 
-	fmt.Printf("集群 '%s' 初始化成功！\n", clusterName)
-	fmt.Printf("请编辑配置文件: %s\n", configPath)
+	i18n.Printf("集群 '%s' 初始化成功！\n", clusterName)
+	i18n.Printf("请编辑配置文件: %s\n", configPath)
 }
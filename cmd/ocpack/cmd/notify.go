@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/batch"
+	"ocpack/pkg/notify"
+	"ocpack/pkg/report"
+	"ocpack/pkg/trustbundle"
+	"ocpack/pkg/workspace"
+)
+
+// notifyStage 按 [notify] 配置发送阶段结束的通知，未配置或发送失败时只输出警告，不影响命令的结果
+func notifyStage(clusterName, clusterDir string, run report.StageRun) {
+	cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+	if err != nil || !cfg.Notify.ShouldNotify(run.Stage, run.Status != report.StatusSuccess) {
+		return
+	}
+
+	event := notify.Event{
+		Cluster:  clusterName,
+		Stage:    run.Stage,
+		Status:   run.Status,
+		Start:    run.Start,
+		Duration: run.Duration,
+		Error:    run.Error,
+		Outputs:  run.Outputs,
+	}
+	event.Host, _ = os.Hostname()
+	// 只附带本次执行写入的镜像同步统计
+	if progress := batch.LatestProgress(workspace.LogsDirs(clusterDir)); progress != nil && !progress.Start.Before(run.Start) {
+		event.Mirror = progress
+	}
+
+	// 经过解密 TLS 的企业代理访问 webhook 时信任 infra.ca_bundle
+	notifier := notify.New(cfg.Notify, nil)
+	if bundle, err := trustbundle.LoadCABundle(cfg, clusterDir); err == nil && !bundle.Empty() {
+		notifier.HTTP = bundle.HTTPClient()
+	}
+	if err := notifier.Send(event); err != nil {
+		i18n.Fprintf(os.Stderr, "⚠️  发送阶段通知失败: %v\n", err)
+		return
+	}
+	i18n.Printf("📣 已发送阶段 %s 的通知\n", run.Stage)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/plan"
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	planOutput     string
+	planSkipSizes  bool
+	planSkipDryRun bool
+	planLogLevel   string
+	planPort       uint16
+	planOffline    bool
+)
+
+var (
+	planOperatorsOutput     string
+	planOperatorsSkipDryRun bool
+)
+
+// planCmd 表示 plan 命令
+var planCmd = &cobra.Command{
+	Use:   "plan [集群名称]",
+	Short: "列出 save-image 将要同步的全部镜像并估算传输大小",
+	Long: `plan 命令以 dry-run 模式执行 save-image 的镜像收集，列出将要同步的 release、Operator
+和附加镜像，并估算传输大小，便于在开始大规模下载之前确认镜像集的内容。
+
+此命令将执行以下操作：
+1. 使用 oc-mirror --dry-run 解析镜像集，生成 images/working-dir/dry-run/ 下的
+   mapping.txt 和带镜像类型的 images.json (不下载任何镜像)
+2. 使用 skopeo inspect --raw 读取每个镜像的清单，获取各层压缩后的大小
+3. 按类型分组输出镜像列表，预计传输大小按层去重计算
+
+读取镜像大小需要访问源仓库，失败的镜像标记为大小未知，不计入预计传输大小。
+使用 --skip-sizes 可只列出镜像；使用 --skip-dry-run 可直接使用上次 dry-run 的结果。
+升级图的查询结果在 [save_image] graph_cache_ttl 内复用，--offline-graph 只使用之前保存的升级图。
+
+使用方式:
+  ocpack plan demo
+  ocpack plan demo --skip-sizes
+  ocpack plan demo -o json > plan.json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		if !planSkipDryRun {
+			if err := runPlanDryRun(cmd.Context(), cfg, clusterName, clusterDir); err != nil {
+				return i18n.Errorf("镜像集解析失败: %w", err)
+			}
+		}
+
+		images, err := plan.Load(clusterDir)
+		if err != nil {
+			return err
+		}
+
+		var sizer plan.Sizer
+		if !planSkipSizes {
+			authFile, _, err := auth.EnsureMergedAuth(clusterDir, cfg)
+			if err != nil {
+				return i18n.Errorf("生成认证文件失败: %w", err)
+			}
+			// 单一架构时只统计该架构的清单，多种架构时 multi release payload 同步全部平台
+			arch := cfg.GetReleaseArchitecture()
+			if arch == config.ArchMulti {
+				arch = ""
+			}
+			// 按 save_image.registry_tls 校验上游仓库证书
+			policy, err := registrytls.Prepare(cfg, clusterDir)
+			if err != nil {
+				return clierr.New(clierr.Config, err)
+			}
+			sizer = &plan.SkopeoSizer{AuthFile: authFile, Arch: arch, TLS: policy, Runner: runner.NewExecRunner()}
+			i18n.Fprintf(os.Stderr, "📏 正在读取 %d 个镜像的大小...\n", len(images))
+		}
+
+		return plan.Write(os.Stdout, plan.Build(clusterName, images, sizer), planOutput)
+	},
+}
+
+// runPlanDryRun 以 dry-run 模式执行镜像到磁盘的收集，结果写入 <集群目录>/images/working-dir/dry-run
+func runPlanDryRun(ctx context.Context, cfg *config.ClusterConfig, clusterName, clusterDir string) error {
+	mirrorWrapper, err := wrapper.NewMirrorWrapper(planLogLevel)
+	if err != nil {
+		return i18n.Errorf("创建镜像服务失败: %v", err)
+	}
+
+	// oc-mirror 的日志输出到 stdout，dry-run 期间改为输出到 stderr，保证 stdout 只有计划本身
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	i18n.Fprintf(os.Stderr, "🔍 正在解析镜像集 (dry-run): %s\n", clusterName)
+	opts := &wrapper.MirrorOptions{
+		ClusterName:  clusterName,
+		Port:         planPort,
+		DryRun:       true,
+		OfflineGraph: planOffline,
+	}
+	return mirrorWrapper.MirrorToDisk(ctx, cfg, "file://"+filepath.Join(clusterDir, "images"), opts)
+}
+
+// planOperatorsCmd 表示 plan operators 命令
+var planOperatorsCmd = &cobra.Command{
+	Use:   "operators [集群名称]",
+	Short: "解析所选 Operator 的依赖，列出需要加入 ops 的依赖包和通道",
+	Long: `operators 命令从 Operator 目录的 File-Based Catalog 离线解析 [[save_image.operator_catalogs]] ops 中所选
+Operator 的依赖关系，在开始同步之前列出依赖的包、选择的通道和 bundle，以及相关镜像数量。
+
+此命令将执行以下操作：
+1. 使用 oc-mirror --dry-run 获取 Operator 目录，目录内容解压到
+   images/working-dir/operator-catalogs/ 下 (不下载 Operator 镜像)
+2. 从每个 Operator 默认通道的最新 bundle 开始，按 olm.package.required 和
+   olm.gvk.required 递归解析依赖的包
+3. 按目录输出解析结果，未在 ops 中列出的依赖以 + 标记
+
+oc-mirror 只同步 ops 中列出的包，不会自动包含依赖；以 + 标记的依赖需要加入 ops，
+否则离线环境中 OLM 无法满足依赖，Operator 安装会停在 Pending。
+使用 --skip-dry-run 可直接使用上次 dry-run 或 save-image 解压的目录。
+
+使用方式:
+  ocpack plan operators demo
+  ocpack plan operators demo -o json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		catalogs := cfg.GetOperatorCatalogs()
+		if len(catalogs) == 0 {
+			return i18n.Errorf("配置中没有 Operator 目录，请先在 [[save_image.operator_catalogs]] 中选择 Operator")
+		}
+
+		if !planOperatorsSkipDryRun {
+			// 解析依赖只需要目录内容，不论 include_operators 是否开启都获取目录
+			cfg.SaveImage.IncludeOperators = true
+			if err := runPlanDryRun(cmd.Context(), cfg, clusterName, clusterDir); err != nil {
+				return i18n.Errorf("镜像集解析失败: %w", err)
+			}
+		}
+
+		report, err := plan.BuildOperatorReport(cmd.Context(), clusterName, clusterDir, catalogs)
+		if err != nil {
+			return err
+		}
+		return plan.WriteOperatorReport(os.Stdout, report, planOperatorsOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planOperatorsCmd)
+	planOperatorsCmd.Flags().StringVarP(&planOperatorsOutput, "output", "o", "text", "输出格式: text 或 json")
+	planOperatorsCmd.Flags().BoolVar(&planOperatorsSkipDryRun, "skip-dry-run", false, "直接使用已解压的 Operator 目录")
+	planOperatorsCmd.Flags().StringVar(&planLogLevel, "log-level", "info", "日志级别 (info, debug, error)")
+	planOperatorsCmd.Flags().Uint16Var(&planPort, "port", 0, portFlagUsage)
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "text", "输出格式: text 或 json")
+	planCmd.Flags().BoolVar(&planSkipSizes, "skip-sizes", false, "不读取镜像大小，只列出镜像")
+	planCmd.Flags().BoolVar(&planSkipDryRun, "skip-dry-run", false, "直接使用上次 dry-run 生成的镜像列表")
+	planCmd.Flags().StringVar(&planLogLevel, "log-level", "info", "日志级别 (info, debug, error)")
+	planCmd.Flags().Uint16Var(&planPort, "port", 0, portFlagUsage)
+	planCmd.Flags().BoolVar(&planOffline, "offline-graph", false, offlineGraphFlagUsage)
+}
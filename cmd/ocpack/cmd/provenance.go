@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/iso"
+	mirrorversion "ocpack/pkg/mirror/version"
+	"ocpack/pkg/provenance"
+	"ocpack/pkg/pxe"
+	"ocpack/pkg/storage"
+)
+
+// recordProvenance 为阶段在 start 之后生成的产物 (ISO、PXE 文件、镜像归档) 写入复现清单，
+// 没有生成产物的阶段 (如 --render-only、--dry-run) 不记录，写入失败时只输出警告
+func recordProvenance(clusterName, clusterDir, stage string, start time.Time) {
+	if err := writeProvenance(clusterName, clusterDir, stage, start); err != nil {
+		i18n.Fprintf(os.Stderr, "⚠️  记录复现清单失败: %v\n", err)
+	}
+}
+
+func writeProvenance(clusterName, clusterDir, stage string, start time.Time) error {
+	cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		return err
+	}
+
+	var (
+		artifact string
+		paths    []string
+		hash     = true
+	)
+	ocpack := provenance.Tool{Name: "ocpack", Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, buildTime)}
+	tools := []provenance.Tool{ocpack}
+	switch stage {
+	case "generate_iso", "setup_pxe":
+		artifact = provenance.ArtifactISO
+		paths = []string{iso.ISOPath(clusterDir, clusterName), iso.ProfileISOPath(clusterDir, clusterName, "*"), iso.ConfigImagePath(clusterDir, clusterName), iso.UnconfiguredISOPath(clusterDir)}
+		if stage == "setup_pxe" {
+			artifact = provenance.ArtifactPXE
+			paths = []string{filepath.Join(pxe.FilesDir(clusterDir), "*")}
+		}
+		tools = append(tools, installerTool(cfg, clusterDir), lookPathTool("oc", "version", "--client"))
+	case "save_image":
+		backend, err := storage.New(clusterDir, cfg)
+		if err != nil {
+			return err
+		}
+		artifact = provenance.ArtifactMirrorArchive
+		paths = []string{
+			filepath.Join(backend.Dir(), "mirror_*.tar"),
+			filepath.Join(config.AdhocImagesDir(backend.Dir()), "mirror_*.tar"),
+		}
+		// 镜像归档通常有数百 GB，只记录大小和时间
+		hash = false
+		tools = append(tools, provenance.Tool{Name: "oc-mirror (内置)", Version: mirrorversion.Get().GitVersion})
+	default:
+		return nil
+	}
+
+	files, err := provenance.CollectFiles(paths, start, hash)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	record, err := provenance.NewRecord(artifact, stage, clusterDir, cfg.ClusterInfo.OpenShiftVersion, time.Now())
+	if err != nil {
+		return err
+	}
+	record.Tools = tools
+	record.Files = files
+	if err := provenance.Save(clusterDir, record); err != nil {
+		return err
+	}
+	i18n.Printf("🧾 已记录 %d 个产物文件的复现信息: %s\n", len(files), provenance.Path(clusterDir))
+	return nil
+}
+
+// installerTool 返回生成 ISO 或 PXE 文件使用的 openshift-install：与 FindOpenshiftInstall 的顺序一致，
+// 取第一个存在的位置
+func installerTool(cfg *config.ClusterConfig, clusterDir string) provenance.Tool {
+	for _, path := range agentinstall.InstallerPaths(cfg, clusterDir) {
+		if fileExists(path) {
+			return provenance.ExternalTool("openshift-install", path, "version")
+		}
+	}
+	return provenance.ExternalTool("openshift-install", "")
+}
+
+// lookPathTool 返回 PATH 中的工具及其版本
+func lookPathTool(name string, args ...string) provenance.Tool {
+	path, _ := exec.LookPath(name)
+	return provenance.ExternalTool(name, path, args...)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/iso"
+
+	"github.com/spf13/cobra"
+)
+
+// regenerateISOCmd 表示 regenerate-iso 命令
+var regenerateISOCmd = &cobra.Command{
+	Use:   "regenerate-iso [集群名称]",
+	Short: "复用现有配置重新生成 ISO，轮换过期的 ignition 和认证文件",
+	Long: `generate-iso 生成的 ignition 中的证书在 24 小时后过期，此后 installation/ignition/auth/ 中的
+kubeconfig 和 kubeadmin-password 随之失效，尚未从 ISO 启动的节点也无法完成安装。
+mon 和 report 会在 ignition 超过 24 小时时给出警告。
+
+regenerate-iso 复用 installation/ 中已渲染的 install-config.yaml、agent-config.yaml 和 openshift/ 清单，
+重新执行 openshift-install 生成 ISO 和新的认证文件，不重新渲染配置，因此 config.toml 或模板在此期间的
+改动不会带入新的 ISO。需要应用配置改动时请使用 ocpack generate-iso --force。
+
+上次使用 generate-iso --unconfigured 时只重新生成并发布集群配置镜像，不含集群配置的 ISO 保持不变。
+重新生成后需要使用新的 ISO 或配置镜像重新启动所有节点。
+
+使用方式:
+  ocpack regenerate-iso demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return i18n.Errorf("获取当前目录失败: %v", err)
+		}
+		clusterDir := filepath.Join(projectRoot, clusterName)
+		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+			return clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+		}
+
+		generator, err := iso.NewISOGenerator(clusterName, projectRoot)
+		if err != nil {
+			return i18n.Errorf("创建 ISO 生成器失败: %w", err)
+		}
+		if err := generator.RegenerateISO(cmd.Context()); err != nil {
+			return i18n.Errorf("ISO 重新生成失败: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(regenerateISOCmd)
+	// 产物与 generate-iso 相同，共用 generate_iso 阶段的钩子和报告
+	withStageHooks(regenerateISOCmd, "generate_iso")
+	withClusterLock(regenerateISOCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/bastion"
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// renderCmd 表示 render 命令
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "在本地渲染节点配置文件，便于审阅或手动应用",
+	Long: `render 命令用于在本地生成节点的配置文件，不连接远程节点，也不执行 Ansible。
+
+使用方式:
+  ocpack render bastion-config demo`,
+}
+
+// renderBastionConfigCmd 表示 render bastion-config 命令
+var renderBastionConfigCmd = &cobra.Command{
+	Use:   "bastion-config [集群名称]",
+	Short: "渲染 Bastion 节点的 DNS zone 文件和 haproxy.cfg",
+	Long: `bastion-config 命令根据 config.toml 在本地生成 Bastion 节点的配置文件：
+  named.conf
+  <cluster_id>.<domain>.zone
+  reverse.zone
+  haproxy.cfg
+
+[bastion.dns] mode = "hosts" 时以 dnsmasq.conf 和 hosts 代替 named.conf 和 zone 文件。
+
+默认输出到 <集群名称>/bastion-config/ 目录，可使用 --output 指定其他目录。
+生成的文件可用于审阅，或手动复制到 Bastion 节点的对应位置。
+
+使用方式:
+  ocpack render bastion-config demo
+  ocpack render bastion-config demo --output /tmp/bastion`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return i18n.Errorf("获取当前目录失败: %v", err)
+		}
+
+		clusterDir := filepath.Join(projectRoot, clusterName)
+		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+			return clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+		}
+
+		renderer, err := bastion.NewRenderer(clusterName, projectRoot)
+		if err != nil {
+			return i18n.Errorf("创建 Bastion 配置渲染器失败: %v", err)
+		}
+
+		outputDir, _ := cmd.Flags().GetString("output")
+		if outputDir == "" {
+			outputDir = renderer.OutputDir()
+		}
+
+		files, err := renderer.Render(outputDir)
+		if err != nil {
+			return i18n.Errorf("渲染 Bastion 配置失败: %v", err)
+		}
+
+		for _, f := range files {
+			fmt.Printf("📝 %s -> %s\n", f.Path, f.TargetPath)
+		}
+		i18n.Printf("✅ Bastion 配置已渲染到: %s\n", outputDir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.AddCommand(renderBastionConfigCmd)
+	renderBastionConfigCmd.Flags().StringP("output", "o", "", "指定输出目录 (默认: <集群名称>/bastion-config)")
+}
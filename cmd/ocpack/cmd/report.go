@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/iso"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/provenance"
+	"ocpack/pkg/report"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportOutput     string
+	reportProvenance bool
+)
+
+// reportCmd 表示 report 命令
+var reportCmd = &cobra.Command{
+	Use:   "report [集群名称]",
+	Short: "汇总各阶段的耗时、结果和关键输出",
+	Long: `download、save-image、deploy-registry、generate-iso 等阶段命令执行结束时，
+将耗时、结果和关键输出 (ISO 路径、Registry 地址等) 记录到集群目录的 .ocpack-report.json，
+每个阶段只保留最近一次执行。report 命令将其汇总为一张表格。
+
+generate-iso、setup-pxe 和 save-image 生成产物后，还会将使用的工具版本 (ocpack、内置 oc-mirror、
+openshift-install、oc)、config.toml 的 SHA256、执行的命令和产物文件记录到 .ocpack-provenance.json，
+每类产物只保留最近一次生成的记录。--provenance 输出该复现清单，供支持人员按相同的输入复现产物。
+
+集群尚未安装完成且 generate-iso 生成的 ignition 已超过 24 小时时，report 会警告其中的证书已过期，
+并提示执行 ocpack regenerate-iso。
+
+使用方式:
+  ocpack report demo
+  ocpack report demo -o json
+  ocpack report demo --provenance`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		if reportProvenance {
+			manifest, err := provenance.Load(clusterDir)
+			if err != nil {
+				return err
+			}
+			return provenance.Write(os.Stdout, clusterName, manifest, reportOutput)
+		}
+
+		r, err := report.Load(clusterDir)
+		if err != nil {
+			return err
+		}
+		if err := report.Write(os.Stdout, clusterName, r, reportOutput); err != nil {
+			return err
+		}
+		// 集群安装完成后不再使用 ISO，只在安装完成前提示 ignition 过期
+		if run, ok := r.Last("monitor_install"); !ok || run.Status != report.StatusSuccess {
+			if warning := kubeconfig.CredentialWarning(clusterDir, clusterName, time.Now()); warning != "" {
+				i18n.Fprintf(os.Stderr, "\n⚠️  警告: %s\n", warning)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "text", "输出格式: text 或 json")
+	reportCmd.Flags().BoolVar(&reportProvenance, "provenance", false, "输出产物的复现清单 (工具版本、配置哈希和产物文件)")
+}
+
+// withStageReport 包装命令的 RunE，执行结束后为每个集群记录阶段的耗时、结果和关键输出
+func withStageReport(cmd *cobra.Command, stage string) {
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		start := time.Now()
+		err := run(cmd, args)
+		duration := time.Since(start)
+
+		for _, clusterName := range args {
+			recordStage(clusterName, stage, start, duration, err)
+		}
+		if err == nil {
+			i18n.Printf("⏱️  阶段 %s 耗时 %s，执行 ocpack report 查看各阶段汇总\n", stage, duration.Round(time.Second))
+		}
+		return err
+	}
+}
+
+// recordStage 将阶段执行结果写入集群的阶段报告，集群目录不存在或写入失败时只输出警告
+func recordStage(clusterName, stage string, start time.Time, duration time.Duration, runErr error) {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	clusterDir := filepath.Join(projectRoot, clusterName)
+	if _, err := os.Stat(filepath.Join(clusterDir, "config.toml")); err != nil {
+		return
+	}
+
+	run := report.StageRun{Stage: stage, Start: start, Duration: duration, Status: report.StatusSuccess}
+	if runErr != nil {
+		run.Status = report.StatusFailed
+		run.Error = runErr.Error()
+	} else {
+		run.Outputs = stageOutputs(clusterName, clusterDir, stage)
+		recordProvenance(clusterName, clusterDir, stage, start)
+	}
+	if err := report.Record(clusterDir, run); err != nil {
+		i18n.Fprintf(os.Stderr, "⚠️  记录阶段报告失败: %v\n", err)
+	}
+	notifyStage(clusterName, clusterDir, run)
+}
+
+// stageOutputs 返回阶段成功后的关键输出
+func stageOutputs(clusterName, clusterDir, stage string) map[string]string {
+	cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		return nil
+	}
+
+	outputs := make(map[string]string)
+	switch stage {
+	case "download":
+		outputs["dir"] = cfg.GetDownloadDir(clusterDir)
+	case "deploy_bastion":
+		outputs["bastion"] = cfg.Bastion.IP
+	case "deploy_registry":
+		if cfg.IsProxyCache() {
+			for _, upstream := range cfg.GetProxyCacheUpstreams() {
+				outputs[upstream.Source] = cfg.ProxyCacheHost(upstream)
+			}
+		} else {
+			outputs["registry"] = cfg.GetRegistryHost()
+		}
+	case "save_image":
+		outputs["images"] = filepath.Join(clusterDir, "images")
+	case "load_image":
+		outputs["target"] = cfg.GetMirrorDestination()
+	case "generate_iso":
+		if path := iso.ISOPath(clusterDir, clusterName); fileExists(path) {
+			outputs["iso"] = path
+		}
+		if path := iso.ConfigImagePath(clusterDir, clusterName); fileExists(path) {
+			outputs["unconfigured_iso"] = iso.UnconfiguredISOPath(clusterDir)
+			outputs["config_image"] = path
+		}
+	}
+	return outputs
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
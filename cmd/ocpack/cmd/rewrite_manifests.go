@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/imagepolicy"
+	"ocpack/pkg/mirrormap"
+	"ocpack/pkg/rewrite"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewriteInDir  string
+	rewriteOutDir string
+)
+
+// rewriteManifestsCmd 表示 rewrite-manifests 命令
+var rewriteManifestsCmd = &cobra.Command{
+	Use:   "rewrite-manifests [集群名称]",
+	Short: "将应用清单和 Helm values 中的镜像地址改写为私有仓库地址",
+	Long: `按 load-image 记录的镜像映射 (mirror-mapping.json) 和 oc-mirror 生成的 IDMS/ITMS
+(proxy-cache 模式下为拉取代理) 将 --in 目录中的镜像地址改写为私有仓库中的地址，
+写入 --out 目录的相同位置，便于应用团队在离线环境中部署。
+
+改写的内容:
+  - 键为 image 或以 Image 结尾的字符串，如 Kubernetes 清单中的 containers[].image
+  - Helm values 中 {registry, repository, tag|digest} 形式的镜像，只改写 registry 和 repository
+
+*.yaml 和 *.yml 之外的文件，以及无法解析的 YAML (如 Helm 模板) 原样复制。
+没有对应镜像源的镜像保持不变并列出，通常需要将其加入 [save_image] additional_images 后重新镜像。
+
+使用方式:
+  ocpack rewrite-manifests demo --in ./app --out ./app-disconnected`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		policy := imagepolicy.ProxyCache(cfg)
+		if !cfg.IsProxyCache() {
+			policy, err = imagepolicy.Load(clusterDir)
+			if err != nil {
+				return clierr.New(clierr.Config, i18n.Errorf("%v，请先执行 save-image 和 load-image", err))
+			}
+		}
+
+		// load-image 记录的镜像映射精确到每个镜像，优先使用，其余镜像按镜像源规则改写
+		mirror, err := mirrormap.WithFallback(clusterDir, policy.MirrorImage)
+		if err != nil {
+			return err
+		}
+
+		result, err := rewrite.Dir(rewriteInDir, rewriteOutDir, mirror)
+		if err != nil {
+			return i18n.Errorf("改写清单失败: %w", err)
+		}
+
+		for _, change := range result.Changes {
+			fmt.Printf("✏️  %s:%d %s -> %s\n", change.File, change.Line, change.From, change.To)
+		}
+		for _, change := range result.Unmatched {
+			i18n.Printf("⚠️  %s:%d %s 没有对应的镜像源，保持不变\n", change.File, change.Line, change.From)
+		}
+		for _, file := range result.Skipped {
+			i18n.Printf("⏭️  %s 无法解析为 YAML (如 Helm 模板)，已原样复制\n", file)
+		}
+
+		i18n.Printf("🎉 已写入 %d 个文件到 %s，改写 %d 个镜像", result.Files, rewriteOutDir, len(result.Changes))
+		if len(result.Unmatched) > 0 {
+			i18n.Printf("，%d 个镜像没有对应的镜像源", len(result.Unmatched))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rewriteManifestsCmd)
+	rewriteManifestsCmd.Flags().StringVar(&rewriteInDir, "in", "", "包含 Kubernetes 清单或 Helm values 的输入目录 (必填)")
+	rewriteManifestsCmd.Flags().StringVar(&rewriteOutDir, "out", "", "改写后文件的输出目录 (必填)")
+	rewriteManifestsCmd.MarkFlagRequired("in")
+	rewriteManifestsCmd.MarkFlagRequired("out")
+	rewriteManifestsCmd.MarkFlagDirname("in")
+	rewriteManifestsCmd.MarkFlagDirname("out")
+}
@@ -1,7 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/plain"
 
 	"github.com/spf13/cobra"
 )
@@ -13,6 +24,19 @@ var (
 	buildTime string
 )
 
+var (
+	// globalTimeout --timeout 限制命令的总执行时间，为 0 时不限制
+	globalTimeout time.Duration
+	// cancelTimeout 释放 --timeout 的计时器
+	cancelTimeout context.CancelFunc = func() {}
+	// plainOutput --plain 使用纯 ASCII 输出，未指定时按 locale 和 TERM 自动判断
+	plainOutput bool
+	// restoreOutput 输出 --plain 模式下缓冲的内容并恢复标准输出
+	restoreOutput = func() {}
+	// errorFormat --error-format 命令失败时错误的输出格式，为空时跟随命令的 --output
+	errorFormat string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "ocpack",
 	Short: "ocpack 是用于离线环境中部署 OpenShift 集群的工具",
@@ -42,9 +66,32 @@ var rootCmd = &cobra.Command{
      6. ocpack deploy-registry [集群名称]
      7. ocpack load-image [集群名称]
      8. ocpack generate-iso [集群名称] 或 ocpack setup-pxe [集群名称]`,
-	// 禁用自动生成的completion命令
-	CompletionOptions: cobra.CompletionOptions{
-		DisableDefaultCmd: true,
+	// completion 命令生成 bash、zsh、fish 和 powershell 的补全脚本，集群名称从当前目录动态补全 (见 completion.go)
+	// 错误由 Execute 统一输出，以便 --error-format json 或 --output json 时输出 JSON 格式的错误
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if errorFormat != "" && errorFormat != "text" && errorFormat != "json" {
+			return clierr.New(clierr.Config, i18n.Errorf("不支持的错误输出格式: %s (可选 text 或 json)", errorFormat))
+		}
+		// JSON 输出供程序解析，命令失败时不再打印用法说明
+		if errorOutputFormat(cmd) == "json" {
+			cmd.SilenceUsage = true
+		}
+		// 超时后终止正在执行的 ansible-playbook、openshift-install、oc-mirror 等
+		if globalTimeout > 0 {
+			ctx, cancel := context.WithTimeoutCause(cmd.Context(), globalTimeout, clierr.ErrTimeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+		if !cmd.Flags().Changed("plain") {
+			plainOutput = plain.Detect()
+		}
+		if plainOutput {
+			if restore, err := plain.Enable(); err == nil {
+				restoreOutput = restore
+			}
+		}
+		return nil
 	},
 }
 
@@ -54,10 +101,10 @@ var versionCmd = &cobra.Command{
 	Short: "显示版本信息",
 	Long:  "显示 ocpack 的版本信息，包括版本号、提交哈希和构建时间",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("ocpack 版本信息:\n")
-		fmt.Printf("  版本: %s\n", version)
-		fmt.Printf("  提交: %s\n", commit)
-		fmt.Printf("  构建时间: %s\n", buildTime)
+		i18n.Printf("ocpack 版本信息:\n")
+		i18n.Printf("  版本: %s\n", version)
+		i18n.Printf("  提交: %s\n", commit)
+		i18n.Printf("  构建时间: %s\n", buildTime)
 	},
 }
 
@@ -71,14 +118,83 @@ func SetVersionInfo(v, c, bt string) {
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, buildTime)
 }
 
-// Execute 执行根命令
-func Execute() error {
-	return rootCmd.Execute()
+// Execute 执行根命令并返回进程退出码。Ctrl-C 或 SIGTERM 取消命令的 context，终止正在执行的外部命令。
+// 失败时错误输出到 stderr，退出码按错误类别区分 (见 pkg/clierr)，--error-format 为 json
+// (或未指定时命令的 --output 为 json) 时错误以 JSON 格式输出
+func Execute() int {
+	return execute(os.Args[1:], os.Stderr)
+}
+
+// execute 使用参数 args 执行根命令，错误输出到 stderr
+func execute(args []string, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer func() { cancelTimeout() }()
+	defer func() { restoreOutput() }()
+
+	if err := setLanguage(args); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return clierr.ExitCode(err)
+	}
+	rootCmd.SetArgs(args)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if err == nil {
+		return 0
+	}
+	if cmd != nil {
+		err = interrupted(cmd.Context(), err)
+	}
+	if cmd != nil && errorOutputFormat(cmd) == "json" {
+		clierr.WriteJSON(stderr, err)
+	} else {
+		fmt.Fprintln(stderr, "Error:", err)
+	}
+	return clierr.ExitCode(err)
+}
+
+// interrupted 命令因 --timeout 或中断信号而失败时，确保错误链中包含 clierr.ErrTimeout 或 context.Canceled，
+// 以对应的类别和退出码结束。内置的 oc-mirror 等只返回 context 自身的错误
+func interrupted(ctx context.Context, err error) error {
+	if ctx == nil || ctx.Err() == nil {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if errors.Is(cause, clierr.ErrTimeout) {
+		if errors.Is(err, clierr.ErrTimeout) {
+			return err
+		}
+		return fmt.Errorf("%w (%s): %w", clierr.ErrTimeout, globalTimeout, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
+
+// errorOutputFormat 返回命令失败时错误的输出格式: --error-format 的值，未指定时
+// 命令的 --output 为 json (如 inventory、timeline) 则为 json，否则为 text
+func errorOutputFormat(cmd *cobra.Command) string {
+	if errorFormat != "" {
+		return errorFormat
+	}
+	if flag := cmd.Flags().Lookup("output"); flag != nil && flag.Value.String() == "json" {
+		return "json"
+	}
+	return "text"
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	// 参数解析失败属于命令行输入错误
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return clierr.New(clierr.Config, err)
+	})
+
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "不使用 emoji 和制表符，只输出 ASCII 符号 (默认在非 UTF-8 locale 或 TERM=dumb 时启用)")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "", "命令失败时错误的输出格式: text 或 json (默认 text，命令的 --output 为 json 时为 json)")
+	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", 0, "命令的最长执行时间，如 2h，超时后终止正在执行的外部命令 (默认不限制)")
+
 	// 添加版本命令
 	rootCmd.AddCommand(versionCmd)
 }
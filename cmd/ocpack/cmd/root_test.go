@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestExecuteErrorFormat(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() { errorFormat = "" })
+	var stderr bytes.Buffer
+	run := func(args ...string) int {
+		// 子命令保留上一次执行时已取消的 context，每次执行前重置
+		validateCmd.SetContext(context.Background())
+		return execute(append([]string{"validate", "missing", "--plain=false"}, args...), &stderr)
+	}
+
+	// validate 没有 --output 参数，错误格式由全局的 --error-format 决定
+	code := run("--error-format", "json")
+	if code != 2 {
+		t.Errorf("execute() = %d, want 2 (config)", code)
+	}
+	var out struct {
+		Error struct {
+			Category string `json:"category"`
+			ExitCode int    `json:"exit_code"`
+			Message  string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &out); err != nil {
+		t.Fatalf("stderr is not JSON: %v\n%s", err, stderr.String())
+	}
+	if out.Error.Category != "config" || out.Error.ExitCode != code || out.Error.Message == "" {
+		t.Errorf("unexpected JSON error: %+v", out.Error)
+	}
+
+	// 参数的值在多次执行之间保留
+	errorFormat = ""
+	stderr.Reset()
+	if code := run(); code != 2 {
+		t.Errorf("execute() = %d, want 2", code)
+	}
+	if json.Valid(stderr.Bytes()) || !bytes.HasPrefix(stderr.Bytes(), []byte("Error:")) {
+		t.Errorf("expected a text error without --error-format, got %s", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run("--error-format", "yaml"); code != 2 {
+		t.Errorf("execute() with --error-format yaml = %d, want 2", code)
+	}
+}
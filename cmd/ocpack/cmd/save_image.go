@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/ocpack"
+
+	"github.com/spf13/cobra"
+)
+
+var saveImageCmd = &cobra.Command{
+	Use:   "save-image",
+	Short: "保存 OpenShift 镜像到本地磁盘或共享存储",
+	Long: `save-image 命令根据 config.toml 生成镜像集配置，使用内置的 oc-mirror 将 release、Operator
+和附加镜像保存为镜像归档 (mirror_*.tar)，供离线环境中的 load-image 推送到私有仓库。
+
+镜像归档默认保存在 <集群名称>/images，可通过 [save_image.storage] 修改：
+  file:///mnt/nfs/ocp-images   直接写入 NFS 等共享存储，离线站点挂载后即可 load-image
+  s3://<bucket>/<前缀>         保存到本地后使用 aws s3 sync 上传到 S3 兼容的对象存储，
+                               load-image 前自动下载
+
+[save_image] mirror_registry = true 时，同时将下载目录中的 mirror-registry 离线安装包
+归档到 mirror-registry/ 目录 (附 sha256 校验和)，随镜像一起保存。Registry 主机损坏后，
+deploy-registry 在下载目录中没有安装包时使用该副本重建 Registry，无需访问互联网。
+
+oc-mirror 日志实时输出，每个阶段 (collect、copy release、copy operators、archive) 开始时
+输出标题。使用 -v/-vv 输出 debug/trace 日志，--quiet 只输出错误和最终摘要；完整日志
+保存在 working-dir/logs/oc-mirror.log。
+
+使用 --dry-run 只解析镜像集，镜像列表写入 <集群名称>/images/working-dir/dry-run/，
+不下载镜像也不上传到存储。
+
+升级图 (Cincinnati graph) 的查询结果保存在 working-dir/hold-release/cincinnati-graph-data，
+在 [save_image] graph_cache_ttl (默认 1h) 内直接复用，查询失败时回退到已有的结果。
+无法访问 api.openshift.com 时，使用 --offline-graph 只使用之前保存的升级图。
+
+Operator 目录解析出的摘要记录在 working-dir/operator-catalogs/catalog-digests.json，目录已下载到工作目录时
+在 [save_image] catalog_cache_ttl (默认 24h) 内直接复用，不再查询 registry.redhat.io；无法访问源仓库时
+回退到之前下载的目录。--refresh-catalog 忽略缓存时间，总是查询源仓库获取最新的目录；--offline-catalog
+只使用之前下载的目录，从不查询源仓库。
+
+下载前先查询本地缓存中已有的镜像，跳过 digest 相同的镜像，使用 --full-copy 重新下载全部镜像。
+配置了 [save_image] max_cache_size 或 --max-cache-size 时，保存成功后按最近访问时间删除本地缓存中
+最久未使用的数据，使缓存不超过该大小，并输出被移除的镜像。
+
+传输窗口有限时，使用 --only release 先只保存 release (分组: release、operators、additional、helm)，
+归档中只包含所选分组的镜像，之后再不带 --only 执行一次补全。[save_image] mirror_order 按组依次复制，
+前一组完成后才开始下一组。
+
+使用 --images-file 只保存列表文件中的镜像 (每行一个，# 开头为注释)，跳过 release 和 Operator，
+适合为已有的私有仓库补充少量新的应用镜像。归档保存在镜像存储的 adhoc/ 子目录，不影响完整镜像集，
+之后使用 load-image --images-file 推送。
+
+保存完成后在镜像目录中生成 transfer-manifest.json，记录每个归档的大小和 sha256；配置了
+[save_image.signing] 时同时使用 gpg 或 cosign 签名。load-image 在导入前验证，发现摆渡传输中
+损坏或被篡改的归档。
+
+使用方式:
+  ocpack save-image demo
+  ocpack save-image demo --include-operators
+  ocpack save-image demo --dry-run
+  ocpack save-image demo --images-file images.txt
+  ocpack save-image demo --offline-graph
+  ocpack save-image demo --refresh-catalog
+  ocpack save-image demo --only release
+  ocpack save-image demo --quiet`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		mirrorOpts, err := mirrorOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts := ocpack.SaveImagesOptions{MirrorOptions: mirrorOpts}
+		opts.IncludeOperators, _ = cmd.Flags().GetBool("include-operators")
+		opts.OfflineGraph, _ = cmd.Flags().GetBool("offline-graph")
+		opts.RefreshCatalog, _ = cmd.Flags().GetBool("refresh-catalog")
+		opts.OfflineCatalog, _ = cmd.Flags().GetBool("offline-catalog")
+
+		client, err := newClient(mirrorVerbosity(cmd))
+		if err != nil {
+			return err
+		}
+		result, err := client.SaveImages(cmd.Context(), clusterName, opts)
+		if err != nil || result.Skipped {
+			return err
+		}
+		if opts.DryRun {
+			i18n.Printf("✅ 干运行完成！镜像列表: %s\n", result.MappingFile)
+			return nil
+		}
+		if len(opts.Images) > 0 {
+			i18n.Printf("💡 离线环境中使用 'ocpack load-image %s --images-file %s' 推送这些镜像\n", clusterName, opts.ImagesFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(saveImageCmd)
+	withStageHooks(saveImageCmd, "save_image")
+	withClusterLock(saveImageCmd)
+
+	addMirrorOutputFlags(saveImageCmd)
+	saveImageCmd.Flags().Bool("dry-run", false, "只解析镜像集而不下载镜像")
+	saveImageCmd.Flags().Bool("include-operators", false, "包含 Operator 镜像 (覆盖 [save_image] include_operators)")
+	saveImageCmd.Flags().Bool("enable-retry", false, "启用重试机制")
+	saveImageCmd.Flags().Int("max-retries", 3, "最大重试次数")
+	saveImageCmd.Flags().Int("retry-interval", 5, "重试间隔时间（秒）")
+	saveImageCmd.Flags().Uint16("port", 0, portFlagUsage)
+	saveImageCmd.Flags().String("images-file", "", "只保存镜像列表文件中的镜像，跳过 release 和 Operator")
+	saveImageCmd.Flags().Bool("offline-graph", false, offlineGraphFlagUsage)
+	saveImageCmd.Flags().Bool("refresh-catalog", false, "忽略 catalog_cache_ttl，总是从源仓库获取最新的 Operator 目录")
+	saveImageCmd.Flags().Bool("offline-catalog", false, "只使用之前下载到工作目录中的 Operator 目录，不访问源仓库")
+	saveImageCmd.MarkFlagsMutuallyExclusive("refresh-catalog", "offline-catalog")
+	saveImageCmd.Flags().Bool("full-copy", false, fullCopyFlagUsage)
+	saveImageCmd.Flags().StringSlice("only", nil, onlyFlagUsage)
+	saveImageCmd.Flags().Int("copy-attempts", 0, copyAttemptsFlagUsage)
+	saveImageCmd.Flags().String("copy-backoff", "", copyBackoffFlagUsage)
+	saveImageCmd.Flags().String("max-cache-size", "", maxCacheSizeFlagUsage)
+}
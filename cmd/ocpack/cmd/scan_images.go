@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/scan"
+
+	"github.com/spf13/cobra"
+)
+
+// scanImagesCmd 表示 scan-images 命令
+var scanImagesCmd = &cobra.Command{
+	Use:   "scan-images [集群名称]",
+	Short: "扫描镜像集中的镜像漏洞",
+	Long: `scan-images 命令使用 [scan] 配置的扫描器扫描镜像集，报告保存在 <集群名称>/scan/report.json。
+
+待扫描的镜像来自 scan.images_file，默认读取 save-image --dry-run 生成的
+images/working-dir/dry-run/mapping.txt；不存在时只扫描 release 镜像和 additional_images。
+
+扫描器:
+  trivy    执行 trivy image --format json (默认)
+  command  执行 scan.command，镜像通过环境变量 OCPACK_SCAN_IMAGE 传入，需输出 Trivy 格式的 JSON
+
+配置了 scan.fail_on 时，存在该级别及以上的漏洞或有镜像扫描失败则命令返回错误。
+配置 [scan] enabled = true 后，load-image 会在推送镜像前自动执行扫描。
+
+使用方式:
+  ocpack scan-images demo`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		if _, err := scan.Run(cmd.Context(), clusterDir, cfg); err != nil {
+			return err
+		}
+		i18n.Println("✅ 镜像扫描通过!")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanImagesCmd)
+	withStageHooks(scanImagesCmd, "scan_images")
+}
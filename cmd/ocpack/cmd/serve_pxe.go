@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/pxeserver"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	servePXEProxyDHCP     bool
+	servePXEServerIP      string
+	servePXEBootloaderDir string
+)
+
+// servePXECmd 表示 serve-pxe 命令
+var servePXECmd = &cobra.Command{
+	Use:   "serve-pxe [集群名称]",
+	Short: "在本机提供 TFTP 和 ProxyDHCP 服务，引导节点从 PXE 安装",
+	Long: `在本机启动内置的 TFTP 服务器，提供 <集群>/pxe/files 中生成的 PXE 文件，按 Ctrl+C 停止。
+
+使用 --proxy-dhcp 时同时启动 ProxyDHCP (UDP 67 和 4011)，适用于无法修改站点 DHCP 服务器的实验环境:
+站点 DHCP 服务器照常分配地址，ProxyDHCP 只为 config.toml 中配置了 MAC 地址的节点补充启动信息，
+其他主机的请求会被忽略。节点首先通过 TFTP 加载 iPXE (BIOS 使用 undionly.kpxe，UEFI 使用 ipxe.efi)，
+iPXE 再通过 TFTP 加载生成的 iPXE 脚本，内核、initrd 和 rootfs 从脚本中的 PXE 资源服务器下载。
+
+前提条件:
+  - 已生成 PXE 文件 (<集群>/pxe/files/*.ipxe)
+  - --bootloader-dir 中有 undionly.kpxe 和 ipxe.efi (或 ipxe-x86_64.efi)，可安装 ipxe-bootimgs 软件包
+  - 以 root 运行，且本机没有其他 DHCP/TFTP 服务占用端口
+
+使用方式:
+  sudo ocpack serve-pxe demo --proxy-dhcp
+  sudo ocpack serve-pxe demo --proxy-dhcp --server-ip 192.168.1.5`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		server, err := pxeserver.NewServer(cfg, clusterDir, servePXEServerIP, servePXEBootloaderDir)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(server.BootloaderDir, pxeserver.BIOSBootfile)); err != nil {
+			i18n.Printf("⚠️  %s 中未找到 %s，BIOS 节点将无法启动\n", server.BootloaderDir, pxeserver.BIOSBootfile)
+		}
+
+		i18n.Printf("✅ TFTP 服务: %s:69 (%s)\n", server.ServerIP, server.FilesDir)
+		if servePXEProxyDHCP {
+			i18n.Printf("✅ ProxyDHCP 服务: %s:67, %s:4011，应答 %d 个节点\n", server.ServerIP, server.ServerIP, len(server.Hosts))
+		} else {
+			i18n.Printf("💡 未启用 --proxy-dhcp，请在站点 DHCP 中将 next-server 设置为 %s\n", server.ServerIP)
+		}
+		i18n.Println("按 Ctrl+C 停止")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return server.ListenAndServe(ctx, servePXEProxyDHCP)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(servePXECmd)
+	servePXECmd.Flags().BoolVar(&servePXEProxyDHCP, "proxy-dhcp", false, "同时启动 ProxyDHCP，为 config.toml 中的节点提供启动信息")
+	servePXECmd.Flags().StringVar(&servePXEServerIP, "server-ip", "", "对节点通告的本机 IP，默认为本机位于 machine_network 中的地址")
+	servePXECmd.Flags().StringVar(&servePXEBootloaderDir, "bootloader-dir", pxeserver.DefaultBootloaderDir, "包含 undionly.kpxe 和 ipxe.efi 的目录")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/pxe"
+	"ocpack/pkg/sshsync"
+
+	"github.com/spf13/cobra"
+)
+
+var setupPXECmd = &cobra.Command{
+	Use:   "setup-pxe",
+	Short: "生成 PXE 启动文件并上传到 PXE 服务器",
+	Long: `setup-pxe 命令用于生成 OpenShift 集群的 PXE 启动文件。
+
+此命令将执行以下操作：
+1. 验证集群配置和依赖工具
+2. 创建 PXE 目录结构
+3. 生成 install-config.yaml 配置文件
+4. 生成 agent-config.yaml 配置文件
+5. 使用 openshift-install 生成 PXE 启动文件 (内核、initrd、rootfs 和 iPXE 脚本)
+6. 将文件上传到 Bastion 上的 PXE 服务器
+
+上传使用内置的 SSH 客户端 (密码认证时不需要 sshpass)，先同步到 Bastion 上 SSH 用户主目录中的
+.ocpack/upload/<集群名称>/pxe，按 SHA256 只上传内容变化的文件并显示每个文件的进度，
+--upload-parallel 控制同时上传的文件数，然后由上传脚本发布到 TFTP 和 HTTP 目录。
+
+生成的文件结构：
+  pxe/
+  ├── config/
+  │   ├── install-config.yaml
+  │   └── agent-config.yaml
+  ├── files/
+  │   └── [generated PXE files]
+  └── .fingerprint
+
+.fingerprint 记录生成 PXE 文件时的配置、OpenShift 版本和固定的 release 镜像。
+再次执行时如果这些内容没有变化，跳过生成和上传；有变化时先清空 files/ 目录再重新生成，
+避免新旧文件混在一起。使用 --force 可强制重新生成并上传。
+
+使用 --render-only 可只渲染 install-config.yaml 和 agent-config.yaml 并显示
+与现有文件的差异，不执行 openshift-install，也不上传文件。
+
+使用方式:
+  ocpack setup-pxe demo
+  ocpack setup-pxe demo --force
+  ocpack setup-pxe demo --render-only`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+
+		// 获取当前工作目录作为项目根目录
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return i18n.Errorf("获取当前目录失败: %v", err)
+		}
+
+		// 检查集群目录是否存在
+		clusterDir := filepath.Join(projectRoot, clusterName)
+		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+			return clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+		}
+
+		generator, err := pxe.NewPXEGenerator(clusterName, projectRoot)
+		if err != nil {
+			return i18n.Errorf("创建 PXE 生成器失败: %w", err)
+		}
+
+		// 获取命令行选项
+		assetServerURL, _ := cmd.Flags().GetString("asset-url")
+		skipVerify, _ := cmd.Flags().GetBool("skip-verify")
+		force, _ := cmd.Flags().GetBool("force")
+		renderOnly, _ := cmd.Flags().GetBool("render-only")
+		uploadParallel, _ := cmd.Flags().GetInt("upload-parallel")
+
+		options := &pxe.GenerateOptions{
+			AssetServerURL: assetServerURL,
+			SkipVerify:     skipVerify,
+			RenderOnly:     renderOnly,
+			Force:          force,
+			UploadParallel: uploadParallel,
+		}
+
+		start := time.Now()
+		if err := generator.GeneratePXE(options); err != nil {
+			return i18n.Errorf("PXE 文件生成失败: %w", err)
+		}
+		recordProvenance(clusterName, clusterDir, "setup_pxe", start)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setupPXECmd)
+	withClusterLock(setupPXECmd)
+
+	// 添加命令行参数
+	setupPXECmd.Flags().String("asset-url", "", "PXE 启动文件的下载地址 (可选，默认使用 infra.pxe_asset_url 或 Bastion 上的 PXE 服务器)")
+	setupPXECmd.Flags().BoolP("skip-verify", "", false, "跳过镜像验证步骤")
+	setupPXECmd.Flags().BoolP("force", "f", false, "强制重新生成并上传，即使配置没有变化")
+	setupPXECmd.Flags().BoolP("render-only", "", false, "只渲染配置文件并显示差异，不执行 openshift-install")
+	setupPXECmd.Flags().Int("upload-parallel", sshsync.DefaultParallel, "同时上传到 Bastion 的文件数")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/externaldns"
+	"ocpack/pkg/gate"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// syncDNSCmd 表示 sync-dns 命令
+var syncDNSCmd = &cobra.Command{
+	Use:   "sync-dns [集群名称]",
+	Short: "在站点托管的外部 DNS 中创建集群记录，并等待记录传播",
+	Long: `站点使用 Infoblox、Route53 等托管 DNS 而不是 Bastion 上的 named 时，在 config.toml 中配置
+[infra.external_dns]，sync-dns 将集群需要的记录交给站点的自动化创建:
+
+  api.<集群域>       -> 负载均衡
+  api-int.<集群域>   -> 负载均衡
+  *.apps.<集群域>    -> 负载均衡
+  registry.<集群域>  -> Registry 节点 (registry = false 时不创建)
+
+负载均衡配置为主机名时使用 CNAME 记录。记录以 JSON 交给以下之一:
+  provider = "webhook"   POST 到 url，配置 token 时带 Authorization: Bearer 请求头
+  provider = "exec"      在集群目录中执行 command，JSON 写入标准输入
+  provider = "<名称>"    执行 PATH 中的 ocpack-dns-<名称> 插件 (参数为 upsert)，JSON 写入标准输入
+
+创建后通过 resolvers (默认为节点使用的 DNS 服务器) 解析每条记录，通配符记录使用随机名称解析，
+直到全部生效或超过 propagation_timeout。generate-iso 前的就绪检查也会检查这些记录。
+
+使用方式:
+  ocpack sync-dns demo
+  ocpack sync-dns demo --dry-run
+  ocpack sync-dns demo --verify`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verifyOnly, _ := cmd.Flags().GetBool("verify")
+
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if !cfg.ExternalDNSEnabled() {
+			return clierr.New(clierr.Config, i18n.Errorf("未配置 [infra.external_dns]\n💡 请在 config.toml 中配置 provider 和 url、command 或插件名称"))
+		}
+		if err := config.ValidateExternalDNS(cfg); err != nil {
+			return clierr.New(clierr.Config, err)
+		}
+
+		req := externaldns.NewRequest(cfg)
+		if dryRun {
+			body, err := json.MarshalIndent(req, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(body))
+			return nil
+		}
+
+		if !verifyOnly {
+			i18n.Printf("🌐 通过 %s 创建 %d 条记录 (区域 %s)...\n", cfg.Infra.ExternalDNS.Provider, len(req.Records), req.Zone)
+			if err := externaldns.NewSyncer().Apply(cmd.Context(), cfg, clusterDir, req); err != nil {
+				return err
+			}
+		}
+		i18n.Printf("🔍 通过 DNS 服务器 %v 检查记录传播...\n", cfg.GetExternalDNSResolvers())
+		if err := gate.WaitForExternalDNS(cmd.Context(), os.Stdout, cfg); err != nil {
+			return err
+		}
+		i18n.Println("✅ 全部记录已生效")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncDNSCmd)
+	syncDNSCmd.Flags().Bool("dry-run", false, "只输出交给外部 DNS 的请求，不创建记录")
+	syncDNSCmd.Flags().Bool("verify", false, "不创建记录，只检查记录是否已传播")
+	syncDNSCmd.MarkFlagsMutuallyExclusive("dry-run", "verify")
+}
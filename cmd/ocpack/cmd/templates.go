@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/bastion"
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/day2"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/iso"
+	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/pxe"
+	"ocpack/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// templatesCmd 表示 templates 命令
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "管理用户自定义的配置模板",
+	Long: `templates 命令用于管理集群目录下的自定义模板。
+
+当 <集群名称>/templates/ 目录中存在以下文件时，将在渲染时替代内置模板：
+  install-config.yaml     generate-iso 和 setup-pxe 使用
+  agent-config.yaml       generate-iso 使用
+  agent-config-pxe.yaml   setup-pxe 使用
+  imageset-config.yaml    save-image 和 load-image 使用
+  named.conf、forward.zone、reverse.zone、haproxy.cfg
+                          render bastion-config 使用
+  nodes-config.yaml       add-worker 使用
+
+使用方式:
+  ocpack templates dump demo`,
+}
+
+// templatesDumpCmd 表示 templates dump 命令
+var templatesDumpCmd = &cobra.Command{
+	Use:               "dump [集群名称]",
+	Short:             "导出内置模板到集群的 templates 目录以便修改",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		force, _ := cmd.Flags().GetBool("force")
+
+		projectRoot, err := os.Getwd()
+		if err != nil {
+			return i18n.Errorf("获取当前目录失败: %v", err)
+		}
+
+		clusterDir := filepath.Join(projectRoot, clusterName)
+		if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+			return clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+		}
+
+		templatesDir := filepath.Join(clusterDir, utils.TemplateOverrideDirName)
+		dumpers := []func(string, bool) ([]string, error){
+			agentinstall.DumpTemplates,
+			iso.DumpTemplates,
+			pxe.DumpTemplates,
+			wrapper.DumpTemplates,
+			bastion.DumpTemplates,
+			day2.DumpTemplates,
+		}
+
+		var written []string
+		for _, dump := range dumpers {
+			files, err := dump(templatesDir, force)
+			if err != nil {
+				return i18n.Errorf("导出模板失败: %v", err)
+			}
+			written = append(written, files...)
+		}
+
+		if len(written) == 0 {
+			i18n.Printf("🟡 模板已存在于 %s，未做修改。使用 --force 覆盖。\n", templatesDir)
+			return nil
+		}
+		for _, file := range written {
+			i18n.Printf("📝 已导出: %s\n", file)
+		}
+		i18n.Printf("✅ 模板已导出到: %s\n", templatesDir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+
+	templatesCmd.AddCommand(templatesDumpCmd)
+	templatesDumpCmd.Flags().BoolP("force", "f", false, "覆盖已存在的模板文件")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/timeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	timelineOutput     string
+	timelineLogs       []string
+	timelineVerbose    bool
+	timelineNoDiscover bool
+)
+
+// timelineCmd 表示 timeline 命令
+var timelineCmd = &cobra.Command{
+	Use:   "timeline [集群名称]",
+	Short: "生成集群安装时间线",
+	Long: `合并安装日志和集群 Operator 状态，生成按时间排序的安装时间线，并统计每个阶段的耗时。
+
+时间线来源:
+  - installation/ignition/.openshift_install.log: generate-iso 生成 ISO 的日志，
+    以及使用 --dir installation/ignition 执行 openshift-install agent wait-for 时追加的输出
+  - --log 指定的其他 openshift-install 日志文件
+  - 集群安装后通过 kubeconfig 获取的 ClusterOperator Available/Degraded 状态变化
+
+阶段包括引导主机、主机发现与验证、安装准备、写入磁盘、Bootstrap 和集群初始化，
+便于定位安装慢在哪个阶段。
+
+使用方式:
+  ocpack timeline demo
+  ocpack timeline demo --verbose
+  ocpack timeline demo -o json --no-discover`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+
+		tl, err := timeline.Build(runner.NewExecRunner(), cfg.ClusterInfo.ClusterID, clusterDir, timelineLogs, !timelineNoDiscover)
+		if err != nil {
+			return err
+		}
+		if timelineOutput != "text" {
+			for _, note := range tl.Notes {
+				fmt.Fprintf(os.Stderr, "⚠️  %s\n", note)
+			}
+		}
+		return timeline.Write(os.Stdout, tl, timelineOutput, timelineVerbose)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+	timelineCmd.Flags().StringVarP(&timelineOutput, "output", "o", "text", "输出格式: text 或 json")
+	timelineCmd.Flags().StringSliceVar(&timelineLogs, "log", nil, "额外的 openshift-install 日志文件，可重复指定")
+	timelineCmd.Flags().BoolVarP(&timelineVerbose, "verbose", "v", false, "列出全部 info 事件，默认只列出阶段切换、警告和 Operator 事件")
+	timelineCmd.Flags().BoolVar(&timelineNoDiscover, "no-discover", false, "不访问集群，只使用日志")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/webui"
+
+	"github.com/spf13/cobra"
+)
+
+var uiListen string
+
+// uiCmd 表示 ui 命令
+var uiCmd = &cobra.Command{
+	Use:   "ui [集群名称]",
+	Short: "启动本地 Web 面板，查看阶段进度、镜像同步进度、磁盘占用、日志和产物",
+	Long: `在本机启动只读的 Web 面板，按 Ctrl+C 停止。多人共用跳板机时，可以在浏览器中查看集群进度而不必翻阅终端输出:
+
+  - 各阶段 (download、save-image、deploy-registry、load-image、generate-iso 等) 最近一次执行的结果和耗时
+  - 正在进行的 save-image/load-image 的镜像同步进度，每个镜像完成后更新
+  - 集群目录下各子目录的磁盘占用和所在分区的剩余空间
+  - oc-mirror 和 openshift-install 的日志
+  - ISO、PXE 文件和镜像归档的下载链接
+
+面板默认只监听 127.0.0.1，可以通过 SSH 端口转发访问；使用 --listen 监听其他地址时，
+任何能访问该端口的人都可以下载日志和产物。
+
+使用方式:
+  ocpack ui demo
+  ocpack ui demo --listen 0.0.0.0:8088`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterName := args[0]
+		clusterDir, err := getDay2ClusterDir(clusterName)
+		if err != nil {
+			return err
+		}
+
+		i18n.Printf("🌐 Web 面板: http://%s/\n", uiListen)
+		i18n.Println("按 Ctrl+C 停止")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return webui.NewServer(clusterName, clusterDir).ListenAndServe(ctx, uiListen)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().StringVar(&uiListen, "listen", webui.DefaultAddr, "面板的监听地址")
+}
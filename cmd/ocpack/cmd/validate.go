@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var validateStrict bool
+
+// validateCmd 表示 validate 命令
+var validateCmd = &cobra.Command{
+	Use:   "validate [集群名称]",
+	Short: "验证集群配置和节点规格",
+	Long: `验证 config.toml，并检查节点规格是否满足 OpenShift 的最低要求。
+
+节点配置了 cpu、memory_gb 或 disk_gb 时，按集群拓扑 (单节点 SNO、紧凑集群、标准集群)
+和节点角色与文档中的最低要求比较，低于要求的节点会给出警告。generate-iso 和 PXE
+生成时也会执行同样的检查。集群目录名与 cluster_info.cluster_id 不一致时同样给出警告。使用 --strict 时存在警告也返回错误，可用于在流水线中阻止安装。
+
+使用方式:
+  ocpack validate demo
+  ocpack validate demo --strict`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeClusterName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusterDir, err := getDay2ClusterDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+		if err != nil {
+			return i18n.Errorf("加载配置失败: %w", err)
+		}
+		if err := config.ValidateConfig(cfg); err != nil {
+			return clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+		}
+
+		_, topology := cfg.ControlPlaneSizing()
+		i18n.Printf("集群拓扑: %s (%d 个 Control Plane 节点, %d 个 Worker 节点)\n",
+			topology, len(cfg.Cluster.ControlPlane), len(cfg.Cluster.Worker))
+
+		warnings := config.CheckNodeSizing(cfg)
+		if warning := cfg.CheckClusterDir(clusterDir); warning != "" {
+			warnings = append(warnings, warning)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+		if len(warnings) > 0 && validateStrict {
+			return clierr.New(clierr.Config, i18n.Errorf("存在 %d 个警告", len(warnings)))
+		}
+		i18n.Println("✅ 配置验证通过")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "存在警告 (如节点规格低于最低要求) 时返回错误")
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"ocpack/cmd/ocpack/cmd"
@@ -18,8 +17,5 @@ func main() {
 	// 设置版本信息到 root 命令
 	cmd.SetVersionInfo(Version, Commit, BuildTime)
 	
-	if err := cmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	os.Exit(cmd.Execute())
 } 
\ No newline at end of file
@@ -0,0 +1,37 @@
+package agentinstall
+
+import (
+	"fmt"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/utils"
+)
+
+// BastionSSHCommand 构建在 Bastion 上执行 remoteCmd 的 ssh 命令，供列出和清理历史 PXE 文件等操作使用。
+// 未配置 SSH 密钥时使用 sshpass -e 从 SSHPASS 环境变量读取密码，避免密码出现在进程参数中
+func BastionSSHCommand(cfg *config.ClusterConfig, remoteCmd string) runner.Command {
+	sshUserHost := fmt.Sprintf("%s@%s", cfg.Bastion.Username, cfg.Bastion.IP)
+	if cfg.Bastion.SSHKeyPath != "" {
+		return runner.Command{
+			Name: "ssh",
+			Args: []string{"-i", cfg.Bastion.SSHKeyPath, "-o", "StrictHostKeyChecking=no", sshUserHost, remoteCmd},
+		}
+	}
+	return runner.Command{
+		Name:    "sshpass",
+		Args:    []string{"-e", "ssh", "-o", "StrictHostKeyChecking=no", sshUserHost, remoteCmd},
+		Env:     []string{"SSHPASS=" + cfg.Bastion.Password},
+		Secrets: []string{cfg.Bastion.Password},
+	}
+}
+
+// DialBastion 使用内置的 SSH 客户端连接 Bastion，用于上传 PXE 文件和配置镜像，密码认证时不需要 sshpass
+func DialBastion(cfg *config.ClusterConfig) (*utils.SSHClient, error) {
+	client, err := utils.NewSSHClient(cfg.Bastion.IP, cfg.Bastion.Username, cfg.Bastion.Password, cfg.Bastion.SSHKeyPath)
+	if err != nil {
+		return nil, i18n.Errorf("连接 Bastion %s 失败: %w", cfg.Bastion.IP, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,252 @@
+package agentinstall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/utils"
+)
+
+// releaseExtractTimeout 从 release 镜像中提取 openshift-install 的超时时间
+const releaseExtractTimeout = 10 * time.Minute
+
+// releaseImageOverrideEnv openshift-install 使用的 release 镜像，覆盖安装程序内置的镜像
+const releaseImageOverrideEnv = "OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE"
+
+// RunInstaller 将 configDir 中的 install-config.yaml、agent-config.yaml 和 openshift/ 额外清单复制到 workDir，
+// 然后执行 openshift-install agent create <target> --dir <workDir>，例如 target 为 image 或 pxe-files
+func (r *Renderer) RunInstaller(configDir, workDir, target string) error {
+	openshiftInstallPath, err := r.FindOpenshiftInstall()
+	if err != nil {
+		return i18n.Errorf("查找 openshift-install 失败: %w", err)
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return i18n.Errorf("创建临时目录失败: %w", err)
+	}
+
+	// openshift-install 会消费输入文件，因此在副本上执行
+	for _, filename := range []string{InstallConfigFilename, AgentConfigFilename} {
+		if err := utils.CopyFile(filepath.Join(configDir, filename), filepath.Join(workDir, filename)); err != nil {
+			return i18n.Errorf("复制 %s 失败: %w", filename, err)
+		}
+	}
+	// 额外清单 (ICSP/IDMS/ITMS 等) 放在 openshift/ 目录中，由 openshift-install 一并打包
+	if manifestsDir := filepath.Join(configDir, ManifestsDirName); utils.FileExists(manifestsDir) {
+		if err := utils.CopyFileOrDir(manifestsDir, filepath.Join(workDir, ManifestsDirName)); err != nil {
+			return i18n.Errorf("复制 %s 目录失败: %w", ManifestsDirName, err)
+		}
+	}
+
+	cmd := runner.Command{
+		Name:   openshiftInstallPath,
+		Args:   []string{"agent", "create", target, "--dir", workDir},
+		Stream: true,
+	}
+	pinned, err := r.PinnedReleaseImage()
+	if err != nil {
+		return err
+	}
+	if pinned != "" {
+		cmd.Env = []string{releaseImageOverrideEnv + "=" + pinned}
+		r.Hooks.Info(i18n.Sprintf("固定安装 release 镜像: %s", pinned))
+	}
+	r.Hooks.Info(i18n.Sprintf("执行命令: %s", cmd))
+	if _, err := r.Runner.Run(cmd); err != nil {
+		return i18n.Errorf("执行 openshift-install agent create %s 失败: %w", target, err)
+	}
+	return nil
+}
+
+// PinnedReleaseImage 返回 save-image/load-image 记录的 release 镜像摘要引用，如
+// registry.example.com:8443/openshift/release-images@sha256:abcd...。没有记录或记录的版本与 openshift_version 不同时返回空字符串
+func (r *Renderer) PinnedReleaseImage() (string, error) {
+	state, err := kubeconfig.LoadState(r.ClusterDir)
+	if err != nil {
+		return "", err
+	}
+	digest := state.PinnedReleaseDigest(r.Config.ClusterInfo.OpenShiftVersion)
+	if digest == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("%s@%s", r.Config.GetReleaseRepository(), digest), nil
+}
+
+// FindOpenshiftInstall 查找可用的 openshift-install 二进制文件，
+// 优先使用从私有仓库 release 镜像中提取的版本，其次是下载目录中的版本
+func (r *Renderer) FindOpenshiftInstall() (string, error) {
+	// 1. 首先尝试提取的二进制文件
+	extractedBinary := r.extractedInstallerPath()
+	if _, err := os.Stat(extractedBinary); err == nil {
+		r.Hooks.Info(fmt.Sprintf("Using openshift-install extracted from Registry: %s", extractedBinary))
+		return extractedBinary, nil
+	}
+
+	// 2. 尝试从 registry 提取 openshift-install。proxy-cache 模式的 Registry 中没有推送的 release 镜像，
+	// 直接使用下载的版本，其内置的 quay.io release 镜像通过镜像源从拉取代理获取
+	if !r.Config.IsProxyCache() {
+		r.Hooks.Info("Attempting to extract openshift-install tool from private registry...")
+		if err := r.extractOpenshiftInstall(); err != nil {
+			r.Hooks.Warn(fmt.Sprintf("Registry extraction failed: %v", err))
+		} else if _, err := os.Stat(extractedBinary); err == nil {
+			r.Hooks.Info(fmt.Sprintf("Successfully extracted openshift-install from Registry: %s", extractedBinary))
+			return extractedBinary, nil
+		}
+	}
+
+	// 3. 回退到下载的二进制文件
+	downloadedBinary := filepath.Join(r.DownloadDir, "bin", openshiftInstallCmd)
+	if _, err := os.Stat(downloadedBinary); err == nil {
+		r.Hooks.Info(fmt.Sprintf("Using downloaded openshift-install: %s", downloadedBinary))
+		return downloadedBinary, nil
+	}
+
+	return "", fmt.Errorf("%s tool not found in either %s or %s", openshiftInstallCmd, extractedBinary, downloadedBinary)
+}
+
+// --- Helper Functions ---
+
+// extractedInstallerPath 返回从私有仓库提取的 openshift-install 保存路径
+func (r *Renderer) extractedInstallerPath() string {
+	return InstallerPaths(r.Config, r.ClusterDir)[0]
+}
+
+// InstallerPaths 按 FindOpenshiftInstall 的优先顺序返回 openshift-install 的位置：
+// 从私有仓库提取的版本和下载目录中的版本
+func InstallerPaths(cfg *config.ClusterConfig, clusterDir string) []string {
+	registryHost := cfg.RegistryHostname()
+	return []string{
+		filepath.Join(clusterDir, fmt.Sprintf("%s-%s-%s", openshiftInstallCmd, cfg.ClusterInfo.OpenShiftVersion, registryHost)),
+		filepath.Join(cfg.GetDownloadDir(clusterDir), "bin", openshiftInstallCmd),
+	}
+}
+
+// extractOpenshiftInstall 从私有 registry 提取 openshift-install 工具
+func (r *Renderer) extractOpenshiftInstall() error {
+	// 构建认证文件路径
+	pullSecretPath := auth.MergedAuthPath(r.ClusterDir)
+	if _, err := os.Stat(pullSecretPath); os.IsNotExist(err) {
+		pullSecretPath = auth.PullSecretPath(r.ClusterDir)
+	}
+
+	outputPath := r.extractedInstallerPath()
+
+	// 私有仓库的证书由集群 CA 和 registry_tls 校验，只有配置为 insecure 的仓库跳过校验
+	tls, err := registrytls.Prepare(r.Config, r.ClusterDir)
+	if err != nil {
+		return i18n.Errorf("准备仓库 TLS 配置失败: %w", err)
+	}
+
+	// 已固定 release 摘要时直接从该摘要提取，保证 openshift-install 与安装的 release 一致
+	if pinned, err := r.PinnedReleaseImage(); err != nil {
+		return err
+	} else if pinned != "" {
+		r.Hooks.Info(fmt.Sprintf("Using pinned release image for extraction: %s", pinned))
+		return r.extractRelease(tls, pinned, outputPath, pullSecretPath)
+	}
+
+	// 尝试多种镜像标签格式，配置多种架构时为 multi payload 的标签
+	imageVariants := []string{
+		fmt.Sprintf("%s:%s", r.Config.GetReleaseRepository(), r.Config.GetReleaseTag()),
+		fmt.Sprintf("%s:%s", r.Config.GetReleaseRepository(), r.Config.ClusterInfo.OpenShiftVersion),
+	}
+
+	for _, imageRef := range imageVariants {
+		r.Hooks.Info(fmt.Sprintf("Trying image reference: %s", imageRef))
+
+		// 第一步：使用 skopeo 检查并获取镜像摘要
+		r.Hooks.Info("Using skopeo to get image digest...")
+		digest, err := r.getImageDigestWithSkopeo(tls, imageRef, pullSecretPath)
+		if err != nil {
+			r.Hooks.Warn(fmt.Sprintf("Failed to get digest: %v", err))
+			continue
+		}
+
+		// 第二步：使用摘要进行提取
+		releaseImageWithDigest := fmt.Sprintf("%s@%s", imageRef[:strings.LastIndex(imageRef, ":")], digest)
+		r.Hooks.Info(fmt.Sprintf("Using digest for extraction: %s", releaseImageWithDigest))
+
+		if err := r.extractRelease(tls, releaseImageWithDigest, outputPath, pullSecretPath); err != nil {
+			r.Hooks.Warn(fmt.Sprintf("Digest extraction failed: %v", err))
+			// 作为备选，尝试使用标签直接提取
+			if err := r.extractRelease(tls, imageRef, outputPath, pullSecretPath); err != nil {
+				r.Hooks.Warn(fmt.Sprintf("Tag extraction also failed: %v", err))
+				continue
+			}
+		}
+		return nil
+	}
+
+	return errors.New("failed to extract openshift-install from any image variant")
+}
+
+// getImageDigestWithSkopeo 使用 skopeo 获取镜像摘要
+func (r *Renderer) getImageDigestWithSkopeo(tls *registrytls.Policy, imageRef, authFile string) (string, error) {
+	args := append([]string{"inspect", "--authfile", authFile}, tls.SkopeoArgs(imageRef)...)
+	cmd := runner.Command{
+		Name:    "skopeo",
+		Args:    append(args, "docker://"+imageRef),
+		Timeout: runner.DefaultTimeout,
+	}
+
+	r.Hooks.Info(i18n.Sprintf("执行命令: %s", cmd))
+	result, err := r.Runner.Run(cmd)
+	if err != nil {
+		return "", i18n.Errorf("skopeo inspect 失败: %w, 输出: %s", err, string(result.Combined))
+	}
+
+	var inspectResult struct {
+		Digest string `json:"Digest"`
+	}
+	if err := json.Unmarshal(result.Stdout, &inspectResult); err != nil {
+		return "", i18n.Errorf("解析 skopeo inspect 输出失败: %w", err)
+	}
+	if inspectResult.Digest == "" {
+		return "", errors.New(i18n.T("镜像摘要为空"))
+	}
+
+	r.Hooks.Info(i18n.Sprintf("获取到镜像摘要: %s", inspectResult.Digest))
+	return inspectResult.Digest, nil
+}
+
+// extractRelease 使用 oc adm release extract 从 release 镜像 (标签或摘要) 中提取 openshift-install
+func (r *Renderer) extractRelease(tls *registrytls.Policy, releaseImage, outputPath, pullSecretPath string) error {
+	args := []string{"adm", "release", "extract",
+		"--command=" + openshiftInstallCmd,
+		"--to=" + filepath.Dir(outputPath),
+		"--registry-config=" + pullSecretPath}
+	args = append(args, tls.OCArgs(releaseImage)...)
+	cmd := runner.Command{
+		Name:    "oc",
+		Args:    append(args, releaseImage),
+		Env:     tls.OCEnv(),
+		Timeout: releaseExtractTimeout,
+	}
+
+	r.Hooks.Info(i18n.Sprintf("执行命令: %s", cmd))
+	result, err := r.Runner.Run(cmd)
+	if err != nil {
+		return i18n.Errorf("提取 openshift-install 失败: %w, 输出: %s", err, string(result.Combined))
+	}
+
+	// 重命名提取的文件并设置可执行权限
+	extractedFile := filepath.Join(filepath.Dir(outputPath), openshiftInstallCmd)
+	if err := os.Rename(extractedFile, outputPath); err != nil {
+		return i18n.Errorf("重命名提取的 openshift-install 失败: %w", err)
+	}
+	if err := os.Chmod(outputPath, 0755); err != nil {
+		return i18n.Errorf("设置 openshift-install 权限失败: %w", err)
+	}
+	return nil
+}
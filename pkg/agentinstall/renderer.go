@@ -0,0 +1,457 @@
+package agentinstall
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/imagepolicy"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/secrets"
+	"ocpack/pkg/utils"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// --- Constants ---
+const (
+	InstallConfigFilename = "install-config.yaml"
+	AgentConfigFilename   = "agent-config.yaml"
+	ManifestsDirName      = "openshift"
+
+	installConfigTemplate = "templates/install-config.yaml"
+	computePoolTemplate   = "templates/machineconfigpool.yaml"
+	openshiftInstallCmd   = "openshift-install"
+	defaultInterface      = "ens3"
+
+	// computePoolManifestPrefix 节点池 MachineConfigPool 清单的文件名前缀
+	computePoolManifestPrefix = "99-ocpack-machineconfigpool-"
+)
+
+// --- Struct Definitions ---
+
+// Renderer ISO 和 PXE 共用的 Agent-based 安装配置渲染器，
+// 负责 install-config.yaml、agent-config.yaml 的数据准备和 openshift-install 的调用
+type Renderer struct {
+	Config      *config.ClusterConfig
+	ClusterName string
+	ProjectRoot string
+	ClusterDir  string
+	DownloadDir string
+	Hooks       Hooks
+	Runner      runner.CommandRunner // 执行 openshift-install、oc、skopeo 等外部命令
+}
+
+// Hooks 由 ISO/PXE 生成器提供的扩展点
+type Hooks struct {
+	// Info 和 Warn 输出提示信息，便于各生成器保持自己的输出风格
+	Info func(message string)
+	Warn func(message string)
+}
+
+// InstallConfigData install-config.yaml 模板数据
+type InstallConfigData struct {
+	BaseDomain            string
+	ClusterName           string
+	NumWorkers            int
+	NumMasters            int
+	ClusterNetwork        string
+	ServiceNetwork        string
+	MachineNetwork        string
+	PrefixLength          int
+	HostPrefix            int
+	PullSecret            string
+	SSHKeyPub             string
+	AdditionalTrustBundle string
+	TrustBundlePolicy     string // additionalTrustBundlePolicy，为空时使用安装程序的默认值
+	ImageContentSources   string
+	ImageSourcesKey       string // imageContentSources (4.14 以下) 或 imageDigestSources
+	ArchShort             string
+	ComputeArch           string               // compute 节点池的架构，见 ClusterConfig.ComputeArchitecture
+	FeatureSet            string               // featureSet，为空时使用默认特性集
+	FeatureGates          []string             // featureGates，仅 CustomNoUpgrade 使用
+	Capabilities          *config.Capabilities // 可选集群组件，为 nil 时安装全部组件
+	UseProxy              bool
+	HTTPProxy             string
+	HTTPSProxy            string
+	NoProxy               string
+}
+
+// AgentConfigData agent-config.yaml 模板数据
+type AgentConfigData struct {
+	ClusterName          string
+	RendezvousIP         string
+	Hosts                []HostConfig
+	Port0                string
+	PrefixLength         int
+	NextHopAddress       string
+	DNSServers           []string
+	PinDNS               bool     // 为 true 时 DHCP 节点也使用 DNSServers，不使用 DHCP 下发的 DNS (bastion.dns.mode = hosts)
+	NTPSources           []string // additionalNTPSources
+	BootArtifactsBaseURL string   // 仅 PXE 使用
+}
+
+// HostConfig 主机配置
+type HostConfig struct {
+	Hostname   string
+	Role       string
+	MACAddress string
+	IPAddress  string
+	Interface  string
+	DHCP       bool   // 为 true 时不生成 networkConfig，由 DHCP 分配地址
+	RootDevice string // 安装磁盘，非空时生成 rootDeviceHints.deviceName
+}
+
+// RenderStep render-only 模式下渲染的一个文件
+type RenderStep struct {
+	Filename string
+	Render   func() error
+}
+
+// --- Main Logic ---
+
+// NewRenderer 加载集群配置并创建渲染器，默认输出风格与 generate-iso 一致
+func NewRenderer(clusterName, projectRoot string) (*Renderer, error) {
+	return NewRendererTo(os.Stdout, clusterName, projectRoot)
+}
+
+// NewRendererTo 与 NewRenderer 相同，配置升级提示和默认 Hooks 的输出写入 out
+func NewRendererTo(out io.Writer, clusterName, projectRoot string) (*Renderer, error) {
+	clusterDir := filepath.Join(projectRoot, clusterName)
+	cfg, err := config.LoadConfigTo(out, filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		return nil, i18n.Errorf("加载配置文件失败: %w", err)
+	}
+
+	return &Renderer{
+		Config:      cfg,
+		ClusterName: clusterName,
+		ProjectRoot: projectRoot,
+		ClusterDir:  clusterDir,
+		DownloadDir: cfg.GetDownloadDir(clusterDir),
+		Runner:      runner.NewExecRunner(),
+		Hooks: Hooks{
+			Info: func(message string) { fmt.Fprintf(out, "ℹ️  %s\n", message) },
+			Warn: func(message string) { fmt.Fprintf(out, "⚠️  %s\n", message) },
+		},
+	}, nil
+}
+
+// DumpTemplates 将共用的 install-config.yaml 模板导出到 dir，供 <cluster>/templates/ 覆盖使用
+func DumpTemplates(dir string, force bool) ([]string, error) {
+	return utils.DumpTemplates(templates, []string{installConfigTemplate}, dir, force)
+}
+
+// ValidateConfig 验证配置文件、openshift-install 工具和 pull-secret
+func (r *Renderer) ValidateConfig() error {
+	if err := r.ValidateRenderConfig(); err != nil {
+		return err
+	}
+	toolPath := filepath.Join(r.DownloadDir, "bin", openshiftInstallCmd)
+	if _, err := os.Stat(toolPath); errors.Is(err, os.ErrNotExist) {
+		return i18n.Errorf("缺少必需的工具: %s，请先运行 'ocpack download' 命令", openshiftInstallCmd)
+	}
+	return nil
+}
+
+// ValidateRenderConfig 验证只渲染配置文件时需要的配置和 pull-secret
+func (r *Renderer) ValidateRenderConfig() error {
+	if err := config.ValidateConfig(r.Config); err != nil {
+		return err
+	}
+	if _, err := os.Stat(auth.PullSecretPath(r.ClusterDir)); errors.Is(err, os.ErrNotExist) {
+		return i18n.Errorf("缺少 %s 文件，请先获取 Red Hat pull-secret", auth.PullSecretFilename)
+	}
+	// ocpack 不会在 Bastion 上部署 chrony，未配置 NTP 时节点只能依赖自身时钟
+	if len(r.Config.Cluster.Network.NTPServers) == 0 {
+		r.Hooks.Warn(i18n.T("未配置 [cluster.network] ntp_servers，且 Bastion 未提供 NTP 服务；节点时钟偏差可能导致安装失败"))
+	}
+	for _, warning := range config.CheckNodeSizing(r.Config) {
+		r.Hooks.Warn(warning)
+	}
+	if r.Config.BastionEnabled() {
+		for _, node := range append(append([]config.Node{}, r.Config.Cluster.ControlPlane...), r.Config.Cluster.Worker...) {
+			if node.DHCP && node.IP == "" {
+				r.Hooks.Warn(i18n.Sprintf("节点 %s 通过 DHCP 获取地址且未配置 ip，Bastion 不会为其生成 DNS 记录和 HAProxy 后端", node.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// RenderInstallConfig 在 configDir 中生成 install-config.yaml，
+// 并将适用于目标版本的镜像源清单写入 configDir/openshift
+func (r *Renderer) RenderInstallConfig(configDir string) error {
+	pullSecret, err := r.PullSecret()
+	if err != nil {
+		return err
+	}
+
+	sshKey, _ := r.SSHKey() // SSH key is optional
+
+	trustBundle, err := r.AdditionalTrustBundle()
+	if err != nil {
+		return i18n.Errorf("合并 CA 证书失败: %w", err)
+	}
+	trustBundlePolicy := ""
+	if trustBundle == "" {
+		r.Hooks.Info(i18n.T("未找到 CA 证书，将跳过 additionalTrustBundle"))
+	} else {
+		trustBundlePolicy = r.Config.GetTrustBundlePolicy()
+	}
+
+	if featureSet := r.Config.InstallConfig.FeatureSet; featureSet != "" {
+		r.Hooks.Warn(i18n.Sprintf("install_config.feature_set = %s，集群安装后无法升级", featureSet))
+	}
+
+	imageContentSources, err := r.renderImagePolicy(filepath.Join(configDir, ManifestsDirName))
+	if err != nil {
+		return err
+	}
+	if err := r.renderComputePools(filepath.Join(configDir, ManifestsDirName)); err != nil {
+		return err
+	}
+
+	data := InstallConfigData{
+		BaseDomain:            r.Config.ClusterInfo.Domain,
+		ClusterName:           r.Config.ClusterInfo.ClusterID,
+		NumWorkers:            len(r.Config.Cluster.Worker),
+		NumMasters:            len(r.Config.Cluster.ControlPlane),
+		ClusterNetwork:        r.Config.Cluster.Network.ClusterNetwork,
+		ServiceNetwork:        r.Config.Cluster.Network.ServiceNetwork,
+		MachineNetwork:        utils.ExtractNetworkBase(r.Config.Cluster.Network.MachineNetwork),
+		PrefixLength:          utils.ExtractPrefixLength(r.Config.Cluster.Network.MachineNetwork),
+		HostPrefix:            r.Config.GetHostPrefix(),
+		PullSecret:            pullSecret,
+		SSHKeyPub:             sshKey,
+		AdditionalTrustBundle: trustBundle,
+		TrustBundlePolicy:     trustBundlePolicy,
+		ImageContentSources:   imageContentSources,
+		ImageSourcesKey:       imagepolicy.InstallConfigKey(r.Config.ClusterInfo.OpenShiftVersion),
+		ArchShort:             "amd64",
+		ComputeArch:           r.Config.ComputeArchitecture(),
+		FeatureSet:            r.Config.InstallConfig.FeatureSet,
+		FeatureGates:          r.Config.InstallConfig.FeatureGates,
+		Capabilities:          r.Config.InstallConfig.Capabilities,
+	}
+
+	configPath := filepath.Join(configDir, InstallConfigFilename)
+	return r.ExecuteTemplate(templates, installConfigTemplate, configPath, data)
+}
+
+// AgentConfigData 根据集群配置构建 agent-config.yaml 的公共模板数据
+func (r *Renderer) AgentConfigData() *AgentConfigData {
+	var hosts []HostConfig
+	for _, cp := range r.Config.Cluster.ControlPlane {
+		hosts = append(hosts, HostConfig{Hostname: cp.Name, Role: "master", MACAddress: cp.MAC, IPAddress: cp.IP, Interface: defaultInterface, DHCP: cp.DHCP, RootDevice: cp.RootDevice})
+	}
+	for _, worker := range r.Config.Cluster.Worker {
+		hosts = append(hosts, HostConfig{Hostname: worker.Name, Role: "worker", MACAddress: worker.MAC, IPAddress: worker.IP, Interface: defaultInterface, DHCP: worker.DHCP, RootDevice: worker.RootDevice})
+	}
+
+	return &AgentConfigData{
+		ClusterName:    r.Config.ClusterInfo.ClusterID,
+		RendezvousIP:   r.Config.GetRendezvousIP(),
+		Hosts:          hosts,
+		Port0:          defaultInterface,
+		PrefixLength:   utils.ExtractPrefixLength(r.Config.Cluster.Network.MachineNetwork),
+		NextHopAddress: utils.ExtractGateway(r.Config.Cluster.Network.MachineNetwork),
+		DNSServers:     r.Config.GetDNSServers(),
+		PinDNS:         r.Config.DNSHostsMode(),
+		NTPSources:     r.Config.Cluster.Network.NTPServers,
+	}
+}
+
+// RendezvousSummary 返回 rendezvous 节点的说明，如 "master-2 (192.168.1.11, infra.bootstrap_node)"，
+// 用于生成结果摘要中提示应先启动哪个节点
+func (r *Renderer) RendezvousSummary() string {
+	source := i18n.T("默认第一个 Control Plane 节点")
+	switch {
+	case r.Config.Infra.RendezvousIP != "":
+		source = "infra.rendezvous_ip"
+	case r.Config.Infra.BootstrapNode != "":
+		source = "infra.bootstrap_node"
+	}
+	ip := r.Config.GetRendezvousIP()
+	if node := r.Config.GetRendezvousNode(); node != nil {
+		return fmt.Sprintf("%s (%s, %s)", node.Name, ip, source)
+	}
+	return fmt.Sprintf("%s (%s)", ip, source)
+}
+
+// RenderAgentConfig 使用生成器自己的模板在 configDir 中生成 agent-config.yaml。
+// customize 不为 nil 时可在渲染前补充生成器特有的数据
+func (r *Renderer) RenderAgentConfig(configDir string, tmplFS fs.FS, templatePath string, customize func(*AgentConfigData)) error {
+	data := r.AgentConfigData()
+	if customize != nil {
+		customize(data)
+	}
+	return r.ExecuteTemplate(tmplFS, templatePath, filepath.Join(configDir, AgentConfigFilename), data)
+}
+
+// RenderWithDiff 依次执行渲染步骤，并打印每个文件与渲染前内容的差异
+func (r *Renderer) RenderWithDiff(configDir string, steps []RenderStep) error {
+	for _, step := range steps {
+		path := filepath.Join(configDir, step.Filename)
+		before, err := utils.ReadFileIfExists(path)
+		if err != nil {
+			return i18n.Errorf("读取现有 %s 失败: %w", step.Filename, err)
+		}
+		if err := step.Render(); err != nil {
+			return i18n.Errorf("生成 %s 失败: %w", step.Filename, err)
+		}
+		after, err := os.ReadFile(path)
+		if err != nil {
+			return i18n.Errorf("读取生成的 %s 失败: %w", step.Filename, err)
+		}
+		diff, err := utils.UnifiedDiff(path, before, after)
+		if err != nil {
+			return err
+		}
+		// install-config.yaml 包含 pull-secret，打印前隐藏认证信息
+		diff = secrets.RedactAuth(diff)
+		switch {
+		case before == nil:
+			i18n.Printf("\n🆕 新文件: %s\n%s", path, diff)
+		case diff == "":
+			i18n.Printf("\n✅ 无变化: %s\n", path)
+		default:
+			i18n.Printf("\n📝 已更新: %s\n%s", path, diff)
+		}
+	}
+	return nil
+}
+
+// ExecuteTemplate 渲染模板并写入文件，<cluster>/templates/ 下存在同名模板时优先使用
+func (r *Renderer) ExecuteTemplate(tmplFS fs.FS, templatePath, outputPath string, data interface{}) error {
+	tmplContent, source, err := utils.ReadTemplate(r.ClusterDir, tmplFS, templatePath)
+	if err != nil {
+		return err
+	}
+	if source != "" {
+		r.Hooks.Info(fmt.Sprintf("Using custom template: %s", source))
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).
+		Funcs(template.FuncMap{"indent": indent}).
+		Parse(string(tmplContent))
+	if err != nil {
+		return i18n.Errorf("解析模板 %s 失败: %w", templatePath, err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return i18n.Errorf("创建文件 %s 失败: %w", outputPath, err)
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, data); err != nil {
+		return i18n.Errorf("执行模板生成 %s 失败: %w", outputPath, err)
+	}
+	return nil
+}
+
+// --- Helper Functions ---
+
+// renderComputePools 为每个 [[cluster.compute_pool]] 在 manifestsDir 中生成 MachineConfigPool，
+// 池中节点在 ocpack mon 设置角色标签后使用 worker 和该池的 MachineConfig。先删除之前生成的清单，
+// 避免已删除的节点池残留
+func (r *Renderer) renderComputePools(manifestsDir string) error {
+	stale, err := filepath.Glob(filepath.Join(manifestsDir, computePoolManifestPrefix+"*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return i18n.Errorf("删除旧的节点池清单失败: %w", err)
+		}
+	}
+	if len(r.Config.Cluster.ComputePools) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(manifestsDir, 0755); err != nil {
+		return i18n.Errorf("创建目录 %s 失败: %w", manifestsDir, err)
+	}
+	for _, pool := range r.Config.Cluster.ComputePools {
+		path := filepath.Join(manifestsDir, computePoolManifestPrefix+pool.Name+".yaml")
+		data := struct{ Name, RoleLabel string }{pool.Name, pool.RoleLabel()}
+		if err := r.ExecuteTemplate(templates, computePoolTemplate, path, data); err != nil {
+			return err
+		}
+		r.Hooks.Info(i18n.Sprintf("计算节点池 %s: %d 个节点，已生成 MachineConfigPool %s", pool.Name, len(r.Config.PoolNodes(pool.Name)), filepath.Base(path)))
+	}
+	return nil
+}
+
+// renderImagePolicy 解析 oc-mirror 生成的镜像源配置，将适用于目标版本的 ICSP 或 IDMS/ITMS
+// 写入 manifestsDir，并返回 install-config.yaml 中使用的镜像源内容
+func (r *Renderer) renderImagePolicy(manifestsDir string) (string, error) {
+	policy, err := r.loadImagePolicy()
+	if err != nil {
+		r.Hooks.Info(i18n.Sprintf("未找到镜像源配置文件，将跳过: %v", err))
+		return "", nil
+	}
+
+	written, warnings, err := policy.WriteManifests(manifestsDir, r.Config.ClusterInfo.OpenShiftVersion)
+	for _, warning := range warnings {
+		r.Hooks.Warn(warning)
+	}
+	if err != nil {
+		return "", i18n.Errorf("生成镜像源清单失败: %w", err)
+	}
+	for _, file := range written {
+		r.Hooks.Info(i18n.Sprintf("已生成镜像源清单: %s", file))
+	}
+	return policy.InstallConfigSources(), nil
+}
+
+// loadImagePolicy 返回镜像源配置：proxy-cache 模式指向 Registry 节点上的拉取代理，否则使用 oc-mirror 生成的文件
+func (r *Renderer) loadImagePolicy() (*imagepolicy.Policy, error) {
+	if r.Config.IsProxyCache() {
+		r.Hooks.Info(i18n.T("Registry 为 proxy-cache 模式，镜像源指向拉取代理"))
+		return imagepolicy.ProxyCache(r.Config), nil
+	}
+	policy, err := imagepolicy.Load(r.ClusterDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range policy.SourceFiles {
+		r.Hooks.Info(fmt.Sprintf("Using image mirror policy file: %s", file))
+	}
+	// IDMS 只匹配按 digest 拉取的镜像，按 tag 引用的附加镜像需要 ITMS
+	if added := policy.AddTagMirrors(r.Config); added > 0 {
+		r.Hooks.Info(i18n.Sprintf("为 %d 个按 tag 引用的附加镜像补充 ImageTagMirrorSet 镜像源", added))
+	}
+	return policy, nil
+}
+
+// indent 为多行文本的每个非空行添加缩进，供模板使用
+func indent(spaces int, text string) string {
+	if text == "" {
+		return ""
+	}
+	indentStr := strings.Repeat(" ", spaces)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indentStr + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// registryHost 返回私有镜像仓库的主机名
+func (r *Renderer) registryHost() string {
+	return r.Config.RegistryHostname()
+}
@@ -0,0 +1,611 @@
+package agentinstall
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/imagepolicy"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+func newTestRenderer(t *testing.T, version string) *Renderer {
+	t.Helper()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = version
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	cfg.Cluster.Network.MachineNetwork = "192.168.1.0/24"
+	for i := range cfg.Cluster.ControlPlane {
+		cfg.Cluster.ControlPlane[i].IP = "192.168.1.2" + string(rune('1'+i))
+		cfg.Cluster.ControlPlane[i].MAC = "52:54:00:00:00:0" + string(rune('1'+i))
+	}
+
+	clusterDir := t.TempDir()
+	mergedAuth := auth.MergedAuthPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(mergedAuth), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mergedAuth, []byte(`{"auths":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Renderer{
+		Config:      cfg,
+		ClusterName: "demo",
+		ClusterDir:  clusterDir,
+		Hooks:       Hooks{Info: func(string) {}, Warn: func(string) {}},
+	}
+}
+
+func writePolicy(t *testing.T, clusterDir string) {
+	t.Helper()
+	path := filepath.Join(clusterDir, "images", "working-dir", "cluster-resources", "idms-oc-mirror.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: idms-release-0
+spec:
+  imageDigestMirrors:
+  - mirrors:
+    - registry.demo.example.com:8443/openshift/release
+    source: quay.io/openshift-release-dev/ocp-v4.0-art-dev
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderInstallConfig(t *testing.T) {
+	tests := []struct {
+		version  string
+		key      string
+		manifest string
+	}{
+		{"4.12.30", "imageContentSources:", "image-content-source-policy.yaml"},
+		{"4.14.1", "imageDigestSources:", "image-digest-mirror-set.yaml"},
+	}
+
+	for _, tt := range tests {
+		r := newTestRenderer(t, tt.version)
+		writePolicy(t, r.ClusterDir)
+
+		configDir := filepath.Join(r.ClusterDir, "installation")
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.RenderInstallConfig(configDir); err != nil {
+			t.Fatalf("RenderInstallConfig(%s) error = %v", tt.version, err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"baseDomain: example.com",
+			"replicas: 3",
+			"- cidr: 192.168.1.0/24",
+			tt.key,
+			"  source: quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+		} {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("install-config.yaml for %s missing %q:\n%s", tt.version, want, content)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(configDir, ManifestsDirName, tt.manifest)); err != nil {
+			t.Errorf("expected manifest %s for %s: %v", tt.manifest, tt.version, err)
+		}
+	}
+}
+
+func TestRenderInstallConfigProxyCache(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	r.Config.Registry.MirrorMode = config.MirrorModeProxyCache
+	writePolicy(t, r.ClusterDir) // proxy-cache 模式忽略 oc-mirror 生成的文件
+
+	configDir := filepath.Join(r.ClusterDir, "installation")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenderInstallConfig(configDir); err != nil {
+		t.Fatalf("RenderInstallConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- registry.demo.example.com:5001\n    source: quay.io\n") ||
+		strings.Contains(string(content), "ocp-v4.0-art-dev") {
+		t.Errorf("install-config.yaml should point quay.io at the pull-through cache:\n%s", content)
+	}
+	for _, manifest := range []string{imagepolicy.IDMSManifestFilename, imagepolicy.ITMSManifestFilename} {
+		if _, err := os.Stat(filepath.Join(configDir, ManifestsDirName, manifest)); err != nil {
+			t.Errorf("expected manifest %s: %v", manifest, err)
+		}
+	}
+}
+
+// testCA 生成自签名 CA 证书的 PEM
+func testCA(t *testing.T, name string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestRenderInstallConfigTrustBundle(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	writePolicy(t, r.ClusterDir)
+	configDir := filepath.Join(r.ClusterDir, "installation")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	render := func() string {
+		t.Helper()
+		if err := r.RenderInstallConfig(configDir); err != nil {
+			t.Fatalf("RenderInstallConfig() error = %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	if content := render(); strings.Contains(content, "additionalTrustBundle") {
+		t.Errorf("expected no additionalTrustBundle without CA certificates:\n%s", content)
+	}
+
+	registryCA := filepath.Join(r.ClusterDir, "registry", r.Config.Registry.IP, "rootCA.pem")
+	if err := os.MkdirAll(filepath.Dir(registryCA), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(registryCA, testCA(t, "registry"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	content := render()
+	if strings.Count(content, "BEGIN CERTIFICATE") != 1 || strings.Contains(content, "additionalTrustBundlePolicy") {
+		t.Errorf("expected registry CA without policy:\n%s", content)
+	}
+
+	if err := os.WriteFile(filepath.Join(r.ClusterDir, "proxy-ca.pem"), testCA(t, "proxy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r.Config.Infra.TrustBundlePaths = []string{"proxy-ca.pem"}
+	content = render()
+	if strings.Count(content, "BEGIN CERTIFICATE") != 2 || !strings.Contains(content, "additionalTrustBundlePolicy: Always") {
+		t.Errorf("expected merged bundle with Always policy:\n%s", content)
+	}
+
+	r.Config.Infra.TrustBundlePaths = []string{"missing.pem"}
+	if err := r.RenderInstallConfig(configDir); err == nil {
+		t.Error("expected error for missing trust bundle file")
+	}
+}
+
+func TestRenderAgentConfigCustomize(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	tmplFS := os.DirFS(t.TempDir())
+	overrideDir := filepath.Join(r.ClusterDir, "templates")
+	if err := os.MkdirAll(overrideDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "rendezvousIP: {{ .RendezvousIP }}\nhosts: {{ len .Hosts }}\nurl: {{ .BootArtifactsBaseURL }}\n"
+	if err := os.WriteFile(filepath.Join(overrideDir, "agent.yaml"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := r.RenderAgentConfig(r.ClusterDir, tmplFS, "templates/agent.yaml", func(data *AgentConfigData) {
+		data.BootArtifactsBaseURL = "http://192.168.1.10:8080/pxe"
+	})
+	if err != nil {
+		t.Fatalf("RenderAgentConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(r.ClusterDir, AgentConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "rendezvousIP: 192.168.1.21\nhosts: 5\nurl: http://192.168.1.10:8080/pxe\n"
+	if string(content) != expected {
+		t.Errorf("agent-config.yaml = %q, expected %q", content, expected)
+	}
+}
+
+func TestRenderAgentConfigNTPSources(t *testing.T) {
+	tests := []struct {
+		ntpServers []string
+		expected   string
+	}{
+		{nil, "rendezvousIP: 192.168.1.21\nhosts:\n"},
+		{[]string{"192.168.1.10", "ntp.example.com"}, "rendezvousIP: 192.168.1.21\nadditionalNTPSources:\n  - 192.168.1.10\n  - ntp.example.com\nhosts:\n"},
+	}
+
+	for _, tt := range tests {
+		r := newTestRenderer(t, "4.14.1")
+		r.Config.Cluster.Network.NTPServers = tt.ntpServers
+		if err := r.RenderAgentConfig(r.ClusterDir, os.DirFS("../iso"), "templates/agent-config.yaml", nil); err != nil {
+			t.Fatalf("RenderAgentConfig() error = %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(r.ClusterDir, AgentConfigFilename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), tt.expected) {
+			t.Errorf("agent-config.yaml with NTP servers %v missing %q:\n%s", tt.ntpServers, tt.expected, content)
+		}
+	}
+}
+
+func TestValidateRenderConfigWarnsWithoutNTP(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	r.Config.Bastion.Password = "secret"
+	r.Config.Registry.Password = "secret"
+	for i := range r.Config.Cluster.Worker {
+		r.Config.Cluster.Worker[i].IP = "192.168.1.3" + string(rune('1'+i))
+		r.Config.Cluster.Worker[i].MAC = "52:54:00:00:01:0" + string(rune('1'+i))
+	}
+	if err := os.WriteFile(auth.PullSecretPath(r.ClusterDir), []byte(`{"auths":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	r.Hooks.Warn = func(msg string) { warnings = append(warnings, msg) }
+	if err := r.ValidateRenderConfig(); err != nil {
+		t.Fatalf("ValidateRenderConfig() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "ntp_servers") {
+		t.Errorf("expected ntp_servers warning, got %q", warnings)
+	}
+
+	warnings = nil
+	r.Config.Cluster.Network.NTPServers = []string{"192.168.1.10"}
+	if err := r.ValidateRenderConfig(); err != nil {
+		t.Fatalf("ValidateRenderConfig() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %q", warnings)
+	}
+}
+
+func TestAgentConfigDataWithoutBastion(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	disabled := false
+	r.Config.Bastion.Enabled = &disabled
+	r.Config.Bastion.IP = ""
+	r.Config.Infra = config.Infra{
+		DNSServers:   []string{"192.168.1.2", "192.168.1.3"},
+		LoadBalancer: "192.168.1.4",
+		RendezvousIP: "192.168.1.22",
+	}
+
+	data := r.AgentConfigData()
+	if data.RendezvousIP != "192.168.1.22" {
+		t.Errorf("RendezvousIP = %q, expected infra.rendezvous_ip", data.RendezvousIP)
+	}
+	if strings.Join(data.DNSServers, ",") != "192.168.1.2,192.168.1.3" {
+		t.Errorf("DNSServers = %v, expected infra.dns_servers", data.DNSServers)
+	}
+}
+
+func TestRendezvousBootstrapNode(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	if got := r.RendezvousSummary(); got != "master-0 (192.168.1.21, 默认第一个 Control Plane 节点)" {
+		t.Errorf("RendezvousSummary() = %q", got)
+	}
+
+	r.Config.Infra.BootstrapNode = "master-2"
+	if data := r.AgentConfigData(); data.RendezvousIP != "192.168.1.23" {
+		t.Errorf("RendezvousIP = %q, expected infra.bootstrap_node IP", data.RendezvousIP)
+	}
+	if got := r.RendezvousSummary(); got != "master-2 (192.168.1.23, infra.bootstrap_node)" {
+		t.Errorf("RendezvousSummary() = %q", got)
+	}
+}
+
+func TestRenderAgentConfigDHCPHosts(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	r.Config.Cluster.Worker[0].DHCP = true
+	r.Config.Cluster.Worker[0].MAC = "52:54:00:00:01:01"
+	r.Config.Cluster.Worker[1].IP = "192.168.1.32"
+	r.Config.Cluster.Worker[1].MAC = "52:54:00:00:01:02"
+	r.Config.Cluster.Worker = r.Config.Cluster.Worker[:2]
+	if err := r.RenderAgentConfig(r.ClusterDir, os.DirFS("../iso"), "templates/agent-config.yaml", nil); err != nil {
+		t.Fatalf("RenderAgentConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(r.ClusterDir, AgentConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(content)
+	if got := strings.Count(out, "networkConfig:"); got != 4 {
+		t.Errorf("expected networkConfig for 4 static hosts, got %d:\n%s", got, out)
+	}
+	dhcpHost := out[strings.Index(out, "hostname: worker-0"):strings.Index(out, "hostname: worker-1")]
+	if strings.Contains(dhcpHost, "networkConfig") || !strings.Contains(dhcpHost, "macAddress: 52:54:00:00:01:01") {
+		t.Errorf("unexpected agent-config for DHCP host:\n%s", dhcpHost)
+	}
+
+	// hosts 模式下 DHCP 节点不使用 DHCP 下发的 DNS，固定使用 Bastion
+	r.Config.Bastion.DNS.Mode = config.DNSModeHosts
+	if err := r.RenderAgentConfig(r.ClusterDir, os.DirFS("../iso"), "templates/agent-config.yaml", nil); err != nil {
+		t.Fatalf("RenderAgentConfig() error = %v", err)
+	}
+	content, err = os.ReadFile(filepath.Join(r.ClusterDir, AgentConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = string(content)
+	dhcpHost = out[strings.Index(out, "hostname: worker-0"):strings.Index(out, "hostname: worker-1")]
+	for _, want := range []string{"dhcp: true", "auto-dns: false", "- " + r.Config.Bastion.IP} {
+		if !strings.Contains(dhcpHost, want) {
+			t.Errorf("DHCP host in hosts mode missing %q:\n%s", want, dhcpHost)
+		}
+	}
+}
+
+func TestRenderAgentConfigRootDeviceHints(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	r.Config.Cluster.Worker[0].RootDevice = "/dev/disk/by-path/pci-0000:03:00.0-scsi-0:2:0:0"
+	for _, tmpl := range []struct{ dir, path string }{
+		{"../iso", "templates/agent-config.yaml"},
+		{"../pxe", "templates/agent-config-pxe.yaml"},
+	} {
+		if err := r.RenderAgentConfig(r.ClusterDir, os.DirFS(tmpl.dir), tmpl.path, nil); err != nil {
+			t.Fatalf("RenderAgentConfig(%s) error = %v", tmpl.path, err)
+		}
+		content, err := os.ReadFile(filepath.Join(r.ClusterDir, AgentConfigFilename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(content)
+		if got := strings.Count(out, "rootDeviceHints:"); got != 1 {
+			t.Errorf("%s: expected rootDeviceHints for 1 host, got %d:\n%s", tmpl.path, got, out)
+		}
+		host := out[strings.Index(out, "hostname: worker-0"):]
+		if !strings.Contains(host, "rootDeviceHints:\n      deviceName: /dev/disk/by-path/pci-0000:03:00.0-scsi-0:2:0:0\n") {
+			t.Errorf("%s: unexpected agent-config for worker-0:\n%s", tmpl.path, host)
+		}
+	}
+}
+
+func TestRenderInstallConfigNetworks(t *testing.T) {
+	r := newTestRenderer(t, "4.14.1")
+	r.Config.Cluster.Network.ClusterNetwork = "10.132.0.0/14"
+	r.Config.Cluster.Network.ServiceNetwork = "172.31.0.0/16"
+	r.Config.Cluster.Network.HostPrefix = 24
+
+	configDir := filepath.Join(r.ClusterDir, "installation")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenderInstallConfig(configDir); err != nil {
+		t.Fatalf("RenderInstallConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "  clusterNetwork:\n  - cidr: 10.132.0.0/14\n    hostPrefix: 24\n" +
+		"  machineNetwork:\n  - cidr: 192.168.1.0/24\n  networkType: OVNKubernetes\n  serviceNetwork:\n  - 172.31.0.0/16\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("install-config.yaml missing networking %q:\n%s", want, content)
+	}
+}
+
+func TestRenderInstallConfigCapabilities(t *testing.T) {
+	r := newTestRenderer(t, "4.16.3")
+	var warnings []string
+	r.Hooks.Warn = func(msg string) { warnings = append(warnings, msg) }
+	r.Config.InstallConfig = config.InstallConfig{
+		FeatureSet: config.FeatureSetTechPreviewNoUpgrade,
+		Capabilities: &config.Capabilities{
+			BaselineCapabilitySet:         "None",
+			AdditionalEnabledCapabilities: []string{"MachineAPI", "Ingress"},
+		},
+	}
+
+	configDir := filepath.Join(r.ClusterDir, "installation")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenderInstallConfig(configDir); err != nil {
+		t.Fatalf("RenderInstallConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "featureSet: TechPreviewNoUpgrade\ncapabilities:\n  baselineCapabilitySet: None\n" +
+		"  additionalEnabledCapabilities:\n  - MachineAPI\n  - Ingress\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("install-config.yaml missing %q:\n%s", want, content)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "无法升级") {
+		t.Errorf("warnings = %v, expected upgrade warning", warnings)
+	}
+}
+
+func TestRenderInstallConfigComputePools(t *testing.T) {
+	r := newTestRenderer(t, "4.16.3")
+	r.Config.SaveImage.Architectures = []string{config.ArchAMD64, config.ArchARM64}
+	for i := range r.Config.Cluster.Worker {
+		r.Config.Cluster.Worker[i].Pool = "infra"
+	}
+	r.Config.Cluster.ComputePools = []config.ComputePool{{
+		Name:         "infra",
+		Architecture: config.ArchARM64,
+		Taints:       []config.Taint{{Key: "node-role.kubernetes.io/infra", Effect: config.TaintNoSchedule}},
+	}}
+	if err := config.ValidateComputePools(r.Config); err != nil {
+		t.Fatalf("ValidateComputePools() error = %v", err)
+	}
+
+	configDir := filepath.Join(r.ClusterDir, "installation")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenderInstallConfig(configDir); err != nil {
+		t.Fatalf("RenderInstallConfig() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(configDir, InstallConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "compute:\n- architecture: arm64\n  hyperthreading: Enabled\n  name: worker\n  replicas: 2\n" +
+		"controlPlane:\n  architecture: amd64\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("install-config.yaml missing %q:\n%s", want, content)
+	}
+
+	manifest := filepath.Join(configDir, ManifestsDirName, "99-ocpack-machineconfigpool-infra.yaml")
+	pool, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"kind: MachineConfigPool\n", "      - worker\n      - infra\n", "      node-role.kubernetes.io/infra: \"\"\n"} {
+		if !strings.Contains(string(pool), want) {
+			t.Errorf("MachineConfigPool missing %q:\n%s", want, pool)
+		}
+	}
+
+	// removing the pool also removes its manifest
+	r.Config.Cluster.ComputePools = nil
+	if err := r.RenderInstallConfig(configDir); err != nil {
+		t.Fatalf("RenderInstallConfig() error = %v", err)
+	}
+	if _, err := os.Stat(manifest); !os.IsNotExist(err) {
+		t.Errorf("stale MachineConfigPool manifest should be removed, stat error = %v", err)
+	}
+}
+
+func TestRunInstallerPinnedRelease(t *testing.T) {
+	r := newTestRenderer(t, "4.16.3")
+	fake := &runner.Fake{}
+	r.Runner = fake
+	if err := os.WriteFile(r.extractedInstallerPath(), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	configDir := t.TempDir()
+	for _, name := range []string{InstallConfigFilename, AgentConfigFilename} {
+		if err := os.WriteFile(filepath.Join(configDir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 没有记录摘要时不覆盖 release 镜像
+	if err := r.RunInstaller(configDir, filepath.Join(t.TempDir(), "work"), "image"); err != nil {
+		t.Fatalf("RunInstaller: %v", err)
+	}
+	// 记录的版本与 openshift_version 不同时同样不覆盖
+	if err := kubeconfig.RecordRelease(r.ClusterDir, "4.16.5", "sha256:1111"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RunInstaller(configDir, filepath.Join(t.TempDir(), "work"), "image"); err != nil {
+		t.Fatalf("RunInstaller: %v", err)
+	}
+	if err := kubeconfig.RecordRelease(r.ClusterDir, "4.16.3", "sha256:abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RunInstaller(configDir, filepath.Join(t.TempDir(), "work"), "image"); err != nil {
+		t.Fatalf("RunInstaller: %v", err)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 openshift-install calls, got %v", fake.CommandLines())
+	}
+	if len(calls[0].Env) != 0 || len(calls[1].Env) != 0 {
+		t.Errorf("unexpected env without matching pinned digest: %v, %v", calls[0].Env, calls[1].Env)
+	}
+	want := "OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE=" + r.Config.GetReleaseRepository() + "@sha256:abcd"
+	if len(calls[2].Env) != 1 || calls[2].Env[0] != want {
+		t.Errorf("Env = %v, want %s", calls[2].Env, want)
+	}
+}
+
+func TestExtractOpenshiftInstallTLS(t *testing.T) {
+	r := newTestRenderer(t, "4.16.3")
+	registryCA := filepath.Join(r.ClusterDir, "registry", r.Config.Registry.IP, "rootCA.pem")
+	if err := os.MkdirAll(filepath.Dir(registryCA), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(registryCA, testCA(t, "registry"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Name == "skopeo" {
+			return &runner.Result{Stdout: []byte(`{"Digest":"sha256:abcd"}`)}, nil
+		}
+		return nil, os.WriteFile(filepath.Join(r.ClusterDir, openshiftInstallCmd), nil, 0644)
+	}}
+	r.Runner = fake
+
+	// 默认校验私有仓库的证书，信任集群 CA
+	if err := r.extractOpenshiftInstall(); err != nil {
+		t.Fatalf("extractOpenshiftInstall() error = %v", err)
+	}
+	calls := fake.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected skopeo and oc calls, got %v", fake.CommandLines())
+	}
+	skopeo, oc := strings.Join(calls[0].Args, " "), strings.Join(calls[1].Args, " ")
+	if strings.Contains(skopeo, "--tls-verify=false") || !strings.Contains(skopeo, "--cert-dir") {
+		t.Errorf("skopeo should verify the registry certificate: %s", skopeo)
+	}
+	if strings.Contains(oc, "--insecure") || len(calls[1].Env) != 1 || !strings.HasPrefix(calls[1].Env[0], "SSL_CERT_DIR=") {
+		t.Errorf("oc should verify the registry certificate: %s, env %v", oc, calls[1].Env)
+	}
+	if want := r.Config.GetReleaseRepository() + "@sha256:abcd"; !strings.HasSuffix(oc, " "+want) {
+		t.Errorf("oc should extract %s: %s", want, oc)
+	}
+
+	// registry_tls 中标记为 insecure 的仓库才跳过校验
+	host, _, _ := strings.Cut(r.Config.GetMirrorDestination(), "/")
+	r.Config.SaveImage.RegistryTLS = []config.RegistryTLS{{Registry: host, Insecure: true}}
+	fake = &runner.Fake{Handler: fake.Handler}
+	r.Runner = fake
+	if err := r.extractOpenshiftInstall(); err != nil {
+		t.Fatalf("extractOpenshiftInstall() error = %v", err)
+	}
+	calls = fake.Calls()
+	if len(calls) != 2 || !slices.Contains(calls[0].Args, "--tls-verify=false") || !slices.Contains(calls[1].Args, "--insecure") {
+		t.Errorf("insecure registry should skip verification: %v", fake.CommandLines())
+	}
+}
@@ -0,0 +1,68 @@
+package agentinstall
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/trustbundle"
+	"ocpack/pkg/utils"
+)
+
+// PullSecret 返回安装使用的 pull-secret。存在 pull-secret.txt 时重新生成包含私有仓库认证的 merged-auth.json 并使用它，
+// 否则使用已有的 merged-auth.json，生成失败时回退到原始 pull-secret.txt
+func (r *Renderer) PullSecret() (string, error) {
+	secretPath := auth.MergedAuthPath(r.ClusterDir)
+	if utils.FileExists(auth.PullSecretPath(r.ClusterDir)) {
+		path, changed, err := auth.EnsureMergedAuth(r.ClusterDir, r.Config)
+		if err != nil {
+			r.Hooks.Warn(fmt.Sprintf("Failed to create merged authentication file: %v. Will fall back to original pull-secret.", err))
+			secretPath = auth.PullSecretPath(r.ClusterDir)
+		} else {
+			if changed {
+				r.Hooks.Info("Authentication configuration saved to: " + path)
+			}
+			secretPath = path
+		}
+	}
+
+	r.Hooks.Info("Using authentication file " + filepath.Base(secretPath))
+	secretBytes, err := os.ReadFile(secretPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pull-secret: %w", err)
+	}
+	return strings.TrimSpace(string(secretBytes)), nil
+}
+
+// SSHKey 获取用户的公钥
+func (r *Renderer) SSHKey() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	sshKeyPath := filepath.Join(home, ".ssh", "id_rsa.pub")
+	sshKeyBytes, err := os.ReadFile(sshKeyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SSH public key (%s): %w", sshKeyPath, err)
+	}
+	return strings.TrimSpace(string(sshKeyBytes)), nil
+}
+
+// AdditionalTrustBundle 返回私有仓库 CA 和 infra.trust_bundle_paths 合并后的 PEM 证书，没有任何证书时返回空。
+// trust_bundle_paths 中的文件无法读取或不包含有效证书时返回错误
+func (r *Renderer) AdditionalTrustBundle() (string, error) {
+	bundle, err := trustbundle.Load(r.Config, r.ClusterDir)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range bundle.Warnings {
+		r.Hooks.Warn(warning)
+	}
+	if !bundle.Empty() {
+		r.Hooks.Info(i18n.Sprintf("additionalTrustBundle 包含 %d 个证书，来自 %s", len(bundle.Certificates), strings.Join(bundle.Sources, ", ")))
+	}
+	return strings.TrimSpace(string(bundle.PEM())), nil
+}
@@ -0,0 +1,214 @@
+// Package auth 生成 oc、oc-mirror、skopeo 等工具使用的合并认证文件 (registry/merged-auth.json)，
+// 内容为 Red Hat pull-secret 加上私有仓库和 [[registry.auths]] 中配置的额外仓库认证。
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+)
+
+const (
+	// PullSecretFilename 集群目录中 Red Hat pull-secret 的文件名
+	PullSecretFilename = "pull-secret.txt"
+	// MergedAuthFilename 合并认证文件的文件名
+	MergedAuthFilename = "merged-auth.json"
+	// RegistryDirName 集群目录中保存合并认证文件和仓库证书的目录
+	RegistryDirName = "registry"
+
+	authFileMode = 0600
+)
+
+// Credential 一个镜像仓库的认证信息
+type Credential struct {
+	Host     string
+	Username string
+	Password string // 密码或访问令牌
+}
+
+// PullSecretPath 返回集群目录中 pull-secret.txt 的路径
+func PullSecretPath(clusterDir string) string {
+	return filepath.Join(clusterDir, PullSecretFilename)
+}
+
+// MergedAuthPath 返回集群目录中 merged-auth.json 的路径
+func MergedAuthPath(clusterDir string) string {
+	return filepath.Join(clusterDir, RegistryDirName, MergedAuthFilename)
+}
+
+// Credentials 返回配置中的全部仓库认证：私有仓库在前，其后为 [[registry.auths]]
+func Credentials(cfg *config.ClusterConfig) []Credential {
+	creds := []Credential{{
+		Host:     cfg.GetRegistryHost(),
+		Username: cfg.Registry.RegistryUser,
+		Password: cfg.GetRegistryPassword(),
+	}}
+	for _, auth := range cfg.Registry.Auths {
+		creds = append(creds, Credential{Host: auth.Host, Username: auth.Username, Password: auth.Secret()})
+	}
+	return creds
+}
+
+// Merge 将 creds 写入认证配置 base 的 auths 中并返回新的内容，同名仓库的认证会被替换，
+// 其余字段保持不变。输出按键名排序，相同输入总是得到相同的内容。base 为空或没有 auths 时会自动创建
+func Merge(base []byte, creds []Credential) ([]byte, error) {
+	data := map[string]interface{}{}
+	if len(bytes.TrimSpace(base)) > 0 {
+		if err := json.Unmarshal(base, &data); err != nil {
+			return nil, fmt.Errorf("解析认证配置 JSON 失败: %w", err)
+		}
+	}
+
+	auths := map[string]interface{}{}
+	if raw, exists := data["auths"]; exists {
+		var ok bool
+		if auths, ok = raw.(map[string]interface{}); !ok {
+			return nil, errors.New("认证配置格式无效: 'auths' 字段不是对象")
+		}
+	}
+	data["auths"] = auths
+
+	for _, cred := range creds {
+		if cred.Host == "" {
+			continue
+		}
+		auths[cred.Host] = map[string]interface{}{
+			"auth": base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password)),
+		}
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化认证配置失败: %w", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// Lookup 返回认证配置 content 中仓库 host 的用户名和密码，没有该仓库或内容无效时 ok 为 false
+func Lookup(content []byte, host string) (cred Credential, ok bool) {
+	var data struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return Credential{}, false
+	}
+	entry, exists := data.Auths[host]
+	if !exists {
+		return Credential{}, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return Credential{}, false
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return Credential{}, false
+	}
+	return Credential{Host: host, Username: username, Password: password}, true
+}
+
+// WriteFile 以 0600 权限写入认证文件，内容未变化时不重写。已存在文件的权限过宽时会被收紧，
+// 返回值表示文件内容是否发生了变化
+func WriteFile(path string, content []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("读取认证文件 %s 失败: %w", path, err)
+	}
+
+	if err == nil && bytes.Equal(existing, content) {
+		if err := restrictMode(path); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, fmt.Errorf("创建认证文件目录失败: %w", err)
+	}
+	// 先写入同目录下的临时文件再重命名，避免中途失败留下不完整的认证文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return false, fmt.Errorf("创建临时认证文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("写入认证文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("写入认证文件失败: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), authFileMode); err != nil {
+		return false, fmt.Errorf("设置认证文件权限失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, fmt.Errorf("保存认证文件 %s 失败: %w", path, err)
+	}
+	return true, nil
+}
+
+// UpdateFile 将 creds 合并到已有的认证文件 (如 ~/.docker/config.json) 中，保留文件中的其他认证
+func UpdateFile(path string, creds []Credential) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("读取认证文件 %s 失败: %w", path, err)
+	}
+	content, err := Merge(existing, creds)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	return WriteFile(path, content)
+}
+
+// EnsureMergedAuth 根据 pull-secret.txt 和配置中的仓库认证生成 registry/merged-auth.json 并返回其路径。
+// 每次调用都会重新合并，因此修改 config.toml 后无需手动删除旧文件
+func EnsureMergedAuth(clusterDir string, cfg *config.ClusterConfig) (path string, changed bool, err error) {
+	pullSecret, err := os.ReadFile(PullSecretPath(clusterDir))
+	if err != nil {
+		return "", false, fmt.Errorf("读取 %s 失败: %w", PullSecretFilename, err)
+	}
+	var parsed struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(pullSecret, &parsed); err != nil {
+		return "", false, fmt.Errorf("解析 %s JSON 失败: %w", PullSecretFilename, err)
+	}
+	if parsed.Auths == nil {
+		return "", false, fmt.Errorf("%s 格式无效: 缺少 'auths' 字段", PullSecretFilename)
+	}
+
+	content, err := Merge(pullSecret, Credentials(cfg))
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", PullSecretFilename, err)
+	}
+
+	path = MergedAuthPath(clusterDir)
+	changed, err = WriteFile(path, content)
+	if err != nil {
+		return "", false, err
+	}
+	return path, changed, nil
+}
+
+// restrictMode 将权限过宽的认证文件收紧为 0600
+func restrictMode(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("读取认证文件 %s 权限失败: %w", path, err)
+	}
+	if info.Mode().Perm()&^authFileMode != 0 {
+		if err := os.Chmod(path, authFileMode); err != nil {
+			return fmt.Errorf("收紧认证文件 %s 权限失败: %w", path, err)
+		}
+	}
+	return nil
+}
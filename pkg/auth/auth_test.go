@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func newTestConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.Auths = []config.RegistryAuth{
+		{Host: "quay.example.com:8443", Username: "robot", Token: "robot-token"},
+	}
+	return cfg
+}
+
+func decodeAuths(t *testing.T, content []byte) map[string]string {
+	t.Helper()
+	var data struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("invalid auth JSON: %v\n%s", err, content)
+	}
+	auths := make(map[string]string)
+	for host, entry := range data.Auths {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			t.Fatalf("invalid auth for %s: %v", host, err)
+		}
+		auths[host] = string(decoded)
+	}
+	return auths
+}
+
+func TestMerge(t *testing.T) {
+	base := []byte(`{"auths":{"registry.redhat.io":{"auth":"cmg6c2VjcmV0","email":"me@example.com"}},"credsStore":"desktop"}`)
+	creds := Credentials(newTestConfig())
+
+	content, err := Merge(base, creds)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	auths := decodeAuths(t, content)
+	expected := map[string]string{
+		"registry.redhat.io":             "rh:secret",
+		"registry.demo.example.com:8443": "ocp4:" + config.DefaultRegistryPassword,
+		"quay.example.com:8443":          "robot:robot-token",
+	}
+	for host, value := range expected {
+		if auths[host] != value {
+			t.Errorf("auth for %s = %q, expected %q", host, auths[host], value)
+		}
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data["credsStore"] != "desktop" {
+		t.Errorf("credsStore not preserved: %s", content)
+	}
+	entries := data["auths"].(map[string]interface{})
+	if redhat := entries["registry.redhat.io"].(map[string]interface{}); redhat["email"] != "me@example.com" {
+		t.Errorf("existing auth entry not preserved: %v", redhat)
+	}
+	if local := entries["registry.demo.example.com:8443"].(map[string]interface{}); len(local) != 1 {
+		t.Errorf("local registry entry should only contain auth: %v", local)
+	}
+
+	again, err := Merge(content, creds)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if string(again) != string(content) {
+		t.Errorf("Merge() is not idempotent:\n%s\n---\n%s", content, again)
+	}
+}
+
+func TestMergeEmptyBase(t *testing.T) {
+	content, err := Merge(nil, []Credential{{Host: "quay.example.com", Username: "u", Password: "p"}})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if auths := decodeAuths(t, content); auths["quay.example.com"] != "u:p" {
+		t.Errorf("unexpected auths: %v", auths)
+	}
+
+	if _, err := Merge([]byte(`{"auths":[]}`), nil); err == nil {
+		t.Error("expected error for invalid auths field")
+	}
+}
+
+func TestEnsureMergedAuth(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := newTestConfig()
+
+	if _, _, err := EnsureMergedAuth(clusterDir, cfg); err == nil {
+		t.Fatal("expected error without pull-secret.txt")
+	}
+
+	if err := os.WriteFile(PullSecretPath(clusterDir), []byte(`{"auths":{"quay.io":{"auth":"eDp5"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, changed, err := EnsureMergedAuth(clusterDir, cfg)
+	if err != nil {
+		t.Fatalf("EnsureMergedAuth() error = %v", err)
+	}
+	if path != filepath.Join(clusterDir, RegistryDirName, MergedAuthFilename) || !changed {
+		t.Errorf("EnsureMergedAuth() = %s, %t", path, changed)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("merged auth mode = %o, expected 600", info.Mode().Perm())
+	}
+
+	// 内容未变化时不重写，但会收紧权限
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, err := EnsureMergedAuth(clusterDir, cfg); err != nil || changed {
+		t.Errorf("second EnsureMergedAuth() changed = %t, error = %v", changed, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("merged auth mode = %o after rerun, expected 600", info.Mode().Perm())
+	}
+
+	// 修改配置中的密码后重新生成
+	cfg.Registry.RegistryPassword = "changed"
+	if _, changed, err := EnsureMergedAuth(clusterDir, cfg); err != nil || !changed {
+		t.Errorf("EnsureMergedAuth() after password change changed = %t, error = %v", changed, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auths := decodeAuths(t, content)
+	if auths["registry.demo.example.com:8443"] != "ocp4:changed" || auths["quay.io"] != "x:y" {
+		t.Errorf("unexpected auths: %v", auths)
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".docker", "config.json")
+	creds := []Credential{{Host: "quay.example.com", Username: "u", Password: "p"}}
+
+	if changed, err := UpdateFile(path, creds); err != nil || !changed {
+		t.Fatalf("UpdateFile() changed = %t, error = %v", changed, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"auths":{"other.example.com":{"auth":"YTpi"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpdateFile(path, creds); err != nil {
+		t.Fatalf("UpdateFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auths := decodeAuths(t, content)
+	if auths["other.example.com"] != "a:b" || auths["quay.example.com"] != "u:p" {
+		t.Errorf("unexpected auths: %v", auths)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	content, err := Merge([]byte(`{"auths":{"quay.io":{"auth":"bm90LWJhc2U2NA"}}}`), []Credential{
+		{Host: "registry.redhat.io", Username: "user", Password: "p:ss"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, ok := Lookup(content, "registry.redhat.io")
+	if !ok || cred.Username != "user" || cred.Password != "p:ss" {
+		t.Errorf("Lookup(registry.redhat.io) = %+v, %t", cred, ok)
+	}
+	if _, ok := Lookup(content, "quay.io"); ok {
+		t.Error("Lookup(quay.io) succeeded with an auth entry that is not user:password")
+	}
+	if _, ok := Lookup(content, "ghcr.io"); ok {
+		t.Error("Lookup(ghcr.io) succeeded for a missing registry")
+	}
+}
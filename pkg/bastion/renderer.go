@@ -0,0 +1,295 @@
+package bastion
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/dnshosts"
+	"ocpack/pkg/utils"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// --- Constants ---
+const (
+	outputDirName       = "bastion-config"
+	namedConfFilename   = "named.conf"
+	reverseZoneFilename = "reverse.zone"
+	haproxyCfgFilename  = "haproxy.cfg"
+	forwardZoneTemplate = "templates/forward.zone"
+	reverseZoneTemplate = "templates/reverse.zone"
+	namedConfTemplate   = "templates/named.conf"
+	haproxyCfgTemplate  = "templates/haproxy.cfg"
+)
+
+// --- Struct Definitions ---
+
+// Renderer Bastion 节点 DNS 和 HAProxy 配置渲染器
+type Renderer struct {
+	Config      *config.ClusterConfig
+	ClusterName string
+	ClusterDir  string
+}
+
+// Node 模板中使用的集群节点
+type Node struct {
+	Name string
+	IP   string
+}
+
+// Record 模板中使用的 [bastion.dns] 额外记录
+type Record struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// ConfigData DNS 和 HAProxy 模板数据
+type ConfigData struct {
+	ClusterID    string
+	Domain       string
+	BastionIP    string
+	RegistryIP   string
+	ReverseZone  string
+	ControlPlane []Node
+	Workers      []Node
+
+	// [bastion.dns] 配置
+	Forwarders       []string
+	ForwardZones     []config.DNSForwardZone
+	Records          []Record
+	ReverseRecords   []Node // 位于反向解析区域中的额外 A 记录
+	ReverseEnabled   bool
+	DNSSECValidation bool
+
+	// [bastion.haproxy] 配置
+	StatsPort     int
+	StatsUser     string
+	StatsPassword string
+	HTTPPort      int
+	HTTPSPort     int
+}
+
+// RenderedFile 渲染生成的文件及其在 Bastion 节点上的目标路径
+type RenderedFile struct {
+	Path       string
+	TargetPath string
+}
+
+// --- Main Logic ---
+
+// NewRenderer 创建 Bastion 配置渲染器
+func NewRenderer(clusterName, projectRoot string) (*Renderer, error) {
+	clusterDir := filepath.Join(projectRoot, clusterName)
+	cfg, err := config.LoadConfig(filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		return nil, fmt.Errorf("加载配置文件失败: %w", err)
+	}
+
+	return &Renderer{
+		Config:      cfg,
+		ClusterName: clusterName,
+		ClusterDir:  clusterDir,
+	}, nil
+}
+
+// DumpTemplates 将 Bastion 配置的内置模板导出到 dir，供 <cluster>/templates/ 覆盖使用
+func DumpTemplates(dir string, force bool) ([]string, error) {
+	return utils.DumpTemplates(templates, []string{
+		forwardZoneTemplate,
+		reverseZoneTemplate,
+		namedConfTemplate,
+		haproxyCfgTemplate,
+	}, dir, force)
+}
+
+// OutputDir 返回渲染结果的默认输出目录
+func (r *Renderer) OutputDir() string {
+	return filepath.Join(r.ClusterDir, outputDirName)
+}
+
+// Render 渲染 named.conf、正向/反向 zone 文件和 haproxy.cfg 到 outputDir。
+// [bastion.dns] mode = "hosts" 时以 dnsmasq 配置和 hosts 文件代替 named 的配置
+func (r *Renderer) Render(outputDir string) ([]RenderedFile, error) {
+	data, err := r.buildConfigData()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录 %s 失败: %w", outputDir, err)
+	}
+
+	forwardZoneFilename := fmt.Sprintf("%s.%s.zone", data.ClusterID, data.Domain)
+	files := []struct {
+		template   string
+		filename   string
+		targetPath string
+	}{
+		{namedConfTemplate, namedConfFilename, "/etc/named.conf"},
+		{forwardZoneTemplate, forwardZoneFilename, "/var/named/" + forwardZoneFilename},
+		{reverseZoneTemplate, reverseZoneFilename, "/var/named/" + reverseZoneFilename},
+		{haproxyCfgTemplate, haproxyCfgFilename, "/etc/haproxy/haproxy.cfg"},
+	}
+
+	var rendered []RenderedFile
+	hostsMode := r.Config.DNSHostsMode()
+	if hostsMode {
+		hostsFiles := []struct {
+			filename   string
+			targetPath string
+			content    []byte
+		}{
+			{dnshosts.DnsmasqFilename, dnshosts.RemoteDnsmasqPath(r.Config), dnshosts.RenderDnsmasq(r.Config)},
+			{dnshosts.HostsFilename, dnshosts.RemoteHostsPath(r.Config), dnshosts.RenderHosts(r.Config)},
+		}
+		for _, f := range hostsFiles {
+			path := filepath.Join(outputDir, f.filename)
+			if err := os.WriteFile(path, f.content, 0644); err != nil {
+				return rendered, fmt.Errorf("写入 %s 失败: %w", path, err)
+			}
+			rendered = append(rendered, RenderedFile{Path: path, TargetPath: f.targetPath})
+		}
+	}
+
+	for _, f := range files {
+		if f.template == reverseZoneTemplate && !data.ReverseEnabled {
+			continue
+		}
+		if hostsMode && f.template != haproxyCfgTemplate {
+			continue
+		}
+		path := filepath.Join(outputDir, f.filename)
+		if err := r.executeTemplate(f.template, path, data); err != nil {
+			return rendered, err
+		}
+		rendered = append(rendered, RenderedFile{Path: path, TargetPath: f.targetPath})
+	}
+	return rendered, nil
+}
+
+// --- Helper Functions ---
+
+// buildConfigData 校验配置并构建模板数据
+func (r *Renderer) buildConfigData() (*ConfigData, error) {
+	cfg := r.Config
+	if cfg.ClusterInfo.ClusterID == "" || cfg.ClusterInfo.Domain == "" {
+		return nil, errors.New("集群ID和集群域名不能为空")
+	}
+	if cfg.Bastion.IP == "" {
+		return nil, errors.New("Bastion节点IP不能为空")
+	}
+	if cfg.Registry.IP == "" {
+		return nil, errors.New("registry节点IP不能为空（Bastion需要配置Registry的DNS解析）")
+	}
+	if len(cfg.Cluster.ControlPlane) == 0 {
+		return nil, errors.New("至少需要一个Control Plane节点")
+	}
+	if err := config.ValidateBastionDNS(cfg); err != nil {
+		return nil, err
+	}
+	if err := config.ValidateDNSMode(cfg); err != nil {
+		return nil, err
+	}
+	if err := config.ValidateBastionHAProxy(cfg); err != nil {
+		return nil, err
+	}
+
+	reverseZone, err := reverseZoneName(cfg.Cluster.Network.MachineNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	dns := cfg.Bastion.DNS
+	haproxy := cfg.Bastion.HAProxy
+	data := &ConfigData{
+		ClusterID:        cfg.ClusterInfo.ClusterID,
+		Domain:           cfg.ClusterInfo.Domain,
+		BastionIP:        cfg.Bastion.IP,
+		RegistryIP:       cfg.Registry.IP,
+		ReverseZone:      reverseZone,
+		Forwarders:       dns.Forwarders,
+		ReverseEnabled:   dns.ReverseZoneEnabled(),
+		DNSSECValidation: dns.DNSSECValidationEnabled(),
+		StatsPort:        haproxy.GetStatsPort(),
+		StatsUser:        haproxy.StatsUser,
+		StatsPassword:    haproxy.StatsPassword,
+		HTTPPort:         haproxy.GetHTTPPort(),
+		HTTPSPort:        haproxy.GetHTTPSPort(),
+	}
+	for _, zone := range dns.ConditionalForwarders {
+		data.ForwardZones = append(data.ForwardZones, config.DNSForwardZone{
+			Zone:    strings.TrimSuffix(zone.Zone, "."),
+			Servers: zone.Servers,
+		})
+	}
+	for _, record := range dns.Records {
+		data.Records = append(data.Records, Record{Name: record.Name, Type: record.RecordType(), Value: record.Value})
+	}
+	for _, record := range cfg.ReverseDNSRecords() {
+		data.ReverseRecords = append(data.ReverseRecords, Node{Name: record.Name, IP: record.Value})
+	}
+	// 未配置 ip 的 DHCP 节点地址未知，不生成 DNS 记录和 HAProxy 后端
+	for _, cp := range cfg.Cluster.ControlPlane {
+		if cp.IP != "" {
+			data.ControlPlane = append(data.ControlPlane, Node{Name: cp.Name, IP: cp.IP})
+		}
+	}
+	for _, worker := range cfg.Cluster.Worker {
+		if worker.IP != "" {
+			data.Workers = append(data.Workers, Node{Name: worker.Name, IP: worker.IP})
+		}
+	}
+	return data, nil
+}
+
+// reverseZoneName 根据机器网络生成 /24 反向解析区域名，例如 192.168.1.0/24 -> 1.168.192.in-addr.arpa
+func reverseZoneName(machineNetwork string) (string, error) {
+	parts := strings.Split(utils.ExtractNetworkBase(machineNetwork), ".")
+	if len(parts) != 4 {
+		return "", fmt.Errorf("无效的机器网络: %s", machineNetwork)
+	}
+	return fmt.Sprintf("%s.%s.%s.in-addr.arpa", parts[2], parts[1], parts[0]), nil
+}
+
+// lastOctet 返回 IPv4 地址的最后一段，用于反向解析记录
+func lastOctet(ip string) string {
+	parts := strings.Split(ip, ".")
+	return parts[len(parts)-1]
+}
+
+// executeTemplate 渲染模板并写入文件，<cluster>/templates/ 下存在同名模板时优先使用
+func (r *Renderer) executeTemplate(templatePath, outputPath string, data interface{}) error {
+	tmplContent, source, err := utils.ReadTemplate(r.ClusterDir, templates, templatePath)
+	if err != nil {
+		return err
+	}
+	if source != "" {
+		fmt.Printf("ℹ️  Using custom template: %s\n", source)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).
+		Funcs(template.FuncMap{"lastOctet": lastOctet}).
+		Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("解析模板 %s 失败: %w", templatePath, err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("创建文件 %s 失败: %w", outputPath, err)
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("执行模板生成 %s 失败: %w", outputPath, err)
+	}
+	return nil
+}
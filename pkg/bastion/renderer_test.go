@@ -0,0 +1,237 @@
+package bastion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func TestRender(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	for i := range cfg.Cluster.ControlPlane {
+		cfg.Cluster.ControlPlane[i].IP = fmt.Sprintf("192.168.1.%d", 20+i)
+	}
+	for i := range cfg.Cluster.Worker {
+		cfg.Cluster.Worker[i].IP = fmt.Sprintf("192.168.1.%d", 30+i)
+	}
+
+	clusterDir := t.TempDir()
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: clusterDir}
+	files, err := r.Render(r.OutputDir())
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("Render() returned %d files, expected 4", len(files))
+	}
+
+	expected := map[string][]string{
+		"named.conf": {
+			`zone "demo.example.com" IN {`,
+			`zone "1.168.192.in-addr.arpa" IN {`,
+		},
+		"demo.example.com.zone": {
+			"api     IN  A   192.168.1.10\n",
+			"\n; Control Plane nodes\nmaster-0   IN  A   192.168.1.20\nmaster-1   IN  A   192.168.1.21\nmaster-2   IN  A   192.168.1.22\n",
+			"_etcd-server-ssl._tcp   IN  SRV 0 10 2380 master-2.demo.example.com.",
+		},
+		"reverse.zone": {
+			"10   IN  PTR bastion.demo.example.com.\n11   IN  PTR registry.demo.example.com.\n20   IN  PTR master-0.demo.example.com.\n",
+			"31   IN  PTR worker-1.demo.example.com.",
+		},
+		"haproxy.cfg": {
+			"    mode tcp\n    server master-0 192.168.1.20:6443 check\n    server master-1 192.168.1.21:6443 check\n    server master-2 192.168.1.22:6443 check\n\n",
+			"    server worker-1 192.168.1.31:443 check\n",
+		},
+	}
+	for filename, snippets := range expected {
+		content, err := os.ReadFile(filepath.Join(clusterDir, outputDirName, filename))
+		if err != nil {
+			t.Fatalf("read %s: %v", filename, err)
+		}
+		for _, s := range snippets {
+			if !strings.Contains(string(content), s) {
+				t.Errorf("%s: expected %q in:\n%s", filename, s, content)
+			}
+		}
+	}
+}
+
+func TestRenderHostsMode(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	cfg.Bastion.DNS.Mode = config.DNSModeHosts
+	for i := range cfg.Cluster.ControlPlane {
+		cfg.Cluster.ControlPlane[i].IP = fmt.Sprintf("192.168.1.%d", 20+i)
+	}
+	for i := range cfg.Cluster.Worker {
+		cfg.Cluster.Worker[i].IP = fmt.Sprintf("192.168.1.%d", 30+i)
+	}
+
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: t.TempDir()}
+	files, err := r.Render(r.OutputDir())
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var targets []string
+	for _, f := range files {
+		targets = append(targets, f.TargetPath)
+	}
+	want := "/etc/dnsmasq.d/ocpack-demo.conf,/etc/ocpack/dns/demo.hosts,/etc/haproxy/haproxy.cfg"
+	if strings.Join(targets, ",") != want {
+		t.Errorf("rendered targets = %v, want %s", targets, want)
+	}
+
+	cfg.Cluster.Worker[0].IP = ""
+	if _, err := r.Render(r.OutputDir()); err == nil {
+		t.Error("expected error for node without ip in hosts mode")
+	}
+}
+
+func TestRenderRequiresRegistryIP(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: t.TempDir()}
+	if _, err := r.Render(r.OutputDir()); err == nil {
+		t.Error("Render() expected error when registry IP is empty")
+	}
+}
+
+func TestRenderBastionDNS(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	disabled := false
+	cfg.Bastion.DNS = config.BastionDNS{
+		Forwarders:            []string{"10.0.0.53", "10.0.0.54"},
+		ConditionalForwarders: []config.DNSForwardZone{{Zone: "idm.example.com.", Servers: []string{"10.0.0.10"}}},
+		Records: []config.DNSRecord{
+			{Name: "ntp", Value: "192.168.1.5"},
+			{Name: "proxy", Type: "cname", Value: "squid.example.com."},
+		},
+		DNSSECValidation: &disabled,
+	}
+
+	clusterDir := t.TempDir()
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: clusterDir}
+	if _, err := r.Render(r.OutputDir()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	expected := map[string][]string{
+		"named.conf": {
+			"    forwarders { 10.0.0.53; 10.0.0.54; };\n    forward only;\n",
+			"dnssec-validation no;",
+			"zone \"idm.example.com\" IN {\n    type forward;\n    forward only;\n    forwarders { 10.0.0.10; };\n};",
+		},
+		"demo.example.com.zone": {
+			"; Extra records ([bastion.dns])\nntp   IN  A   192.168.1.5\nproxy   IN  CNAME   squid.example.com.",
+		},
+		"reverse.zone": {
+			"5   IN  PTR ntp.demo.example.com.",
+		},
+	}
+	for filename, snippets := range expected {
+		content, err := os.ReadFile(filepath.Join(clusterDir, outputDirName, filename))
+		if err != nil {
+			t.Fatalf("read %s: %v", filename, err)
+		}
+		for _, s := range snippets {
+			if !strings.Contains(string(content), s) {
+				t.Errorf("%s: expected %q in:\n%s", filename, s, content)
+			}
+		}
+	}
+
+	// 站点 DNS 负责反向解析时不生成反向解析区域
+	cfg.Bastion.DNS.ReverseZone = &disabled
+	files, err := r.Render(t.TempDir())
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, f := range files {
+		if filepath.Base(f.Path) == reverseZoneFilename {
+			t.Errorf("reverse zone rendered with reverse_zone = false")
+		}
+		if filepath.Base(f.Path) == namedConfFilename {
+			content, _ := os.ReadFile(f.Path)
+			if strings.Contains(string(content), "in-addr.arpa") {
+				t.Errorf("named.conf contains reverse zone:\n%s", content)
+			}
+		}
+	}
+}
+
+func TestRenderRejectsInvalidBastionDNS(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	cfg.Bastion.DNS.Records = []config.DNSRecord{{Name: "api", Value: "192.168.1.5"}}
+
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: t.TempDir()}
+	if _, err := r.Render(r.OutputDir()); err == nil {
+		t.Error("Render() expected error for a record conflicting with api")
+	}
+}
+
+func TestRenderHAProxyPorts(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	cfg.Bastion.HAProxy = config.BastionHAProxy{StatsPort: 9001, StatsUser: "admin", StatsPassword: "secret", HTTPSPort: 8443}
+
+	clusterDir := t.TempDir()
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: clusterDir}
+	if _, err := r.Render(r.OutputDir()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(clusterDir, outputDirName, haproxyCfgFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"    bind *:9001\n",
+		"    stats auth admin:secret\n",
+		"frontend openshift-ingress-http\n    bind *:80\n",
+		"frontend openshift-ingress-https\n    bind *:8443\n",
+		"frontend openshift-api-server\n    bind *:6443\n",
+	} {
+		if !strings.Contains(string(content), s) {
+			t.Errorf("haproxy.cfg: expected %q in:\n%s", s, content)
+		}
+	}
+}
+
+func TestRenderSkipsDHCPNodesWithoutIP(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	for i := range cfg.Cluster.ControlPlane {
+		cfg.Cluster.ControlPlane[i].IP = "192.168.1.2" + string(rune('1'+i))
+	}
+	cfg.Cluster.Worker[0].IP = "192.168.1.31"
+	cfg.Cluster.Worker[1].DHCP = true
+
+	clusterDir := t.TempDir()
+	r := &Renderer{Config: cfg, ClusterName: "demo", ClusterDir: clusterDir}
+	if _, err := r.Render(r.OutputDir()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, name := range []string{haproxyCfgFilename, "demo." + cfg.ClusterInfo.Domain + ".zone"} {
+		content, err := os.ReadFile(filepath.Join(clusterDir, outputDirName, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "worker-0") || strings.Contains(string(content), "worker-1") {
+			t.Errorf("%s: expected only worker-0 in:\n%s", name, content)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package clierr 定义 ocpack 命令失败的错误类别和对应的进程退出码，便于调用 ocpack 的自动化脚本
+// 区分配置错误、前置条件不满足、网络故障、认证失败和部分镜像失败等情况。
+package clierr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os/exec"
+)
+
+// Category 错误类别
+type Category string
+
+// 错误类别，未归类的错误视为 Internal
+const (
+	Internal Category = "internal" // 未预期的内部错误
+	Config   Category = "config"   // 配置文件、命令行参数或集群目录无效
+	Prereq   Category = "prereq"   // 前置条件不满足，如缺少工具、镜像或 DNS 记录
+	Network  Category = "network"  // 无法访问仓库、升级图等网络服务
+	Auth     Category = "auth"     // 认证失败或拒绝访问
+	Partial  Category = "partial"  // 操作部分成功，如部分镜像同步失败
+	Timeout  Category = "timeout"  // 超过 --timeout 限制的时间
+	Canceled Category = "canceled" // 被 Ctrl-C 或 SIGTERM 中断
+)
+
+// exitCodes 每个类别的进程退出码
+var exitCodes = map[Category]int{
+	Internal: 1,
+	Config:   2,
+	Prereq:   3,
+	Network:  4,
+	Auth:     5,
+	Partial:  6,
+	Timeout:  7,
+	Canceled: 130, // 与 shell 中被 SIGINT 中断的命令一致
+}
+
+// ErrTimeout 操作超过 --timeout 限制的时间，作为超时 context 的 cause，
+// 与单个命令自身的超时 (context.DeadlineExceeded) 区分
+var ErrTimeout = errors.New("超过 --timeout 限制的时间")
+
+// Error 带有类别的错误
+type Error struct {
+	Category Category
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New 为 err 标记类别，err 为 nil 时返回 nil
+func New(category Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Category: category, Err: err}
+}
+
+// CategoryOf 返回 err 的类别。超时和中断优先，因为此时其他错误通常只是被终止的命令的结果；
+// 其次使用错误链中最外层的 Error，未标记时根据错误类型识别网络错误和找不到可执行文件的错误，其余视为 Internal
+func CategoryOf(err error) Category {
+	if errors.Is(err, ErrTimeout) {
+		return Timeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return Canceled
+	}
+	var categorized *Error
+	if errors.As(err, &categorized) {
+		return categorized.Category
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Network
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return Prereq
+	}
+	return Internal
+}
+
+// ExitCode 返回 err 对应的进程退出码，err 为 nil 时返回 0
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[CategoryOf(err)]
+}
+
+// jsonError --output json 时输出到 stderr 的错误格式
+type jsonError struct {
+	Error struct {
+		Category Category `json:"category"`
+		ExitCode int      `json:"exit_code"`
+		Message  string   `json:"message"`
+	} `json:"error"`
+}
+
+// WriteJSON 将 err 以 JSON 格式写入 w，如 {"error":{"category":"auth","exit_code":5,"message":"..."}}
+func WriteJSON(w io.Writer, err error) error {
+	var out jsonError
+	out.Error.Category = CategoryOf(err)
+	out.Error.ExitCode = ExitCode(err)
+	out.Error.Message = err.Error()
+	return json.NewEncoder(w).Encode(out)
+}
@@ -0,0 +1,62 @@
+package clierr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain", errors.New("boom"), 1},
+		{"config", New(Config, errors.New("bad toml")), 2},
+		{"wrapped prereq", fmt.Errorf("check: %w", New(Prereq, errors.New("missing release"))), 3},
+		{"network error", fmt.Errorf("request: %w", dialErr), 4},
+		{"auth", New(Auth, errors.New("401")), 5},
+		{"explicit category wins over network", New(Partial, dialErr), 6},
+		{"missing executable", fmt.Errorf("run: %w", &exec.Error{Name: "oc", Err: exec.ErrNotFound}), 3},
+		{"timeout wins over the failed command", New(Network, fmt.Errorf("oc-mirror: %w", ErrTimeout)), 7},
+		{"command timeout is a network error", fmt.Errorf("skopeo: %w", context.DeadlineExceeded), 4},
+		{"canceled", fmt.Errorf("ansible-playbook: %w", context.Canceled), 130},
+		{"joined uses first category", errors.Join(New(Auth, errors.New("a")), New(Network, errors.New("b"))), 5},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if New(Config, nil) != nil {
+		t.Error("New(nil) should return nil")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, fmt.Errorf("加载配置失败: %w", New(Config, errors.New("invalid ip")))); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Error struct {
+			Category string `json:"category"`
+			ExitCode int    `json:"exit_code"`
+			Message  string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if out.Error.Category != "config" || out.Error.ExitCode != 2 || out.Error.Message != "加载配置失败: invalid ip" {
+		t.Errorf("unexpected JSON error: %+v", out.Error)
+	}
+}
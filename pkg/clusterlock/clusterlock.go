@@ -0,0 +1,165 @@
+// Package clusterlock 为集群目录提供建议性锁，防止 save-image、load-image、generate-iso、deploy-* 等长时间运行的命令
+// 对同一集群并发执行而破坏 oc-mirror 工作目录和集群状态。锁使用 utils.TryLockFile 的文件锁，
+// 持有进程退出 (包括被 kill -9) 时由操作系统自动释放；持有者的 PID、主机和命令另外记录在 Filename 中，
+// 用于提示谁在使用集群。文件锁在某些网络文件系统上不可靠，此时可使用 --force-unlock 手动删除锁。
+package clusterlock
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+const (
+	// Filename 记录锁持有者的文件名，位于集群目录下
+	Filename = ".ocpack-lock.json"
+	// LockFilename 文件锁使用的文件名，位于集群目录下
+	LockFilename = ".ocpack-cluster.lock"
+)
+
+// Holder 锁的持有者
+type Holder struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+func (h *Holder) String() string {
+	return i18n.Sprintf("%s (PID %d@%s，开始于 %s)", h.Command, h.PID, h.Host, h.Started.Local().Format("2006-01-02 15:04:05"))
+}
+
+// HeldError 集群已被其他进程锁定。Holder 为 nil 表示持有者记录尚未写入或无法解析
+type HeldError struct {
+	Path   string
+	Holder *Holder
+}
+
+func (e *HeldError) Error() string {
+	holder := i18n.T("未知持有者")
+	if e.Holder != nil {
+		holder = e.Holder.String()
+	}
+	return i18n.Sprintf("集群正被其他 ocpack 命令使用: %s\n锁文件: %s\n确认该命令已不再运行后，可使用 --force-unlock 删除锁", holder, e.Path)
+}
+
+// Lock 已获取的集群锁
+type Lock struct {
+	path    string
+	holder  Holder
+	release func() error
+}
+
+// Path 返回集群目录中记录锁持有者的文件路径
+func Path(clusterDir string) string {
+	return filepath.Join(clusterDir, Filename)
+}
+
+// lockPath 返回集群目录中文件锁的路径
+func lockPath(clusterDir string) string {
+	return filepath.Join(clusterDir, LockFilename)
+}
+
+// Acquire 为 command 获取集群锁，不等待。锁已被其他进程持有时返回 *HeldError
+func Acquire(clusterDir, command string) (*Lock, error) {
+	release, err := utils.TryLockFile(lockPath(clusterDir))
+	if errors.Is(err, utils.ErrLocked) {
+		holder, _ := read(Path(clusterDir))
+		return nil, &HeldError{Path: lockPath(clusterDir), Holder: holder}
+	}
+	if err != nil {
+		return nil, i18n.Errorf("获取集群锁失败: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	lock := &Lock{
+		path:    Path(clusterDir),
+		holder:  Holder{PID: os.Getpid(), Host: host, Command: command, Started: time.Now()},
+		release: release,
+	}
+	if err := writeHolder(lock.path, lock.holder); err != nil {
+		release()
+		return nil, i18n.Errorf("写入集群锁持有者失败: %w", err)
+	}
+	return lock, nil
+}
+
+// Release 删除持有者记录并释放锁。持有者记录已被 --force-unlock 删除或被其他进程重写时不删除
+func (l *Lock) Release() error {
+	holder, err := read(l.path)
+	if err == nil && holder.PID == l.holder.PID && holder.Host == l.holder.Host && holder.Started.Equal(l.holder.Started) {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			l.release()
+			return i18n.Errorf("删除锁文件失败: %w", err)
+		}
+	}
+	return l.release()
+}
+
+// Read 返回集群锁的持有者，集群未被锁定时返回 nil
+func Read(clusterDir string) (*Holder, error) {
+	release, err := utils.TryLockFile(lockPath(clusterDir))
+	if err == nil {
+		return nil, release()
+	}
+	if !errors.Is(err, utils.ErrLocked) {
+		return nil, err
+	}
+	holder, err := read(Path(clusterDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return holder, err
+}
+
+// ForceUnlock 删除集群锁并返回原持有者 (持有者记录不存在或无法解析时为 nil)。
+// 持有锁的进程如仍在运行，它持有的是已删除的锁文件，不再阻止新的命令获取锁
+func ForceUnlock(clusterDir string) (*Holder, error) {
+	holder, _ := read(Path(clusterDir))
+	for _, path := range []string{lockPath(clusterDir), Path(clusterDir)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, i18n.Errorf("删除锁文件失败: %w", err)
+		}
+	}
+	return holder, nil
+}
+
+// read 读取持有者记录
+func read(path string) (*Holder, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var holder Holder
+	if err := json.Unmarshal(content, &holder); err != nil {
+		return nil, i18n.Errorf("解析锁文件失败: %w", err)
+	}
+	return &holder, nil
+}
+
+// writeHolder 先写入临时文件再重命名，其他进程读到的持有者记录总是完整的
+func writeHolder(path string, holder Holder) error {
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), Filename+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,123 @@
+package clusterlock
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// flock 按打开的文件描述区分持有者，同一进程中的两次 Acquire 也会冲突，可以模拟另一个进程
+func skipWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上文件锁为空操作")
+	}
+}
+
+func TestAcquireRelease(t *testing.T) {
+	skipWindows(t)
+	dir := t.TempDir()
+
+	lock, err := Acquire(dir, "save-image")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	holder, err := Read(dir)
+	if err != nil || holder == nil {
+		t.Fatalf("Read() = %v, %v, want holder", holder, err)
+	}
+	if holder.PID != os.Getpid() || holder.Command != "save-image" {
+		t.Errorf("holder = %+v", holder)
+	}
+
+	_, err = Acquire(dir, "load-image")
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("second Acquire() error = %v, want HeldError", err)
+	}
+	if held.Holder == nil || held.Holder.Command != "save-image" {
+		t.Errorf("HeldError.Holder = %+v", held.Holder)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if holder, err := Read(dir); err != nil || holder != nil {
+		t.Fatalf("Read() after Release = %v, %v, want nil", holder, err)
+	}
+	lock, err = Acquire(dir, "load-image")
+	if err != nil {
+		t.Fatalf("Acquire() after Release error = %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLeftoverHolder(t *testing.T) {
+	skipWindows(t)
+	dir := t.TempDir()
+
+	// 被 kill -9 的进程留下的持有者记录不影响获取锁
+	if err := writeHolder(Path(dir), Holder{PID: 4242, Host: "bastion", Command: "load-image"}); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := Acquire(dir, "generate-iso")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer lock.Release()
+	if holder, _ := Read(dir); holder == nil || holder.Command != "generate-iso" {
+		t.Errorf("holder = %+v, want generate-iso", holder)
+	}
+}
+
+func TestHeldErrorUnknownHolder(t *testing.T) {
+	skipWindows(t)
+	dir := t.TempDir()
+
+	lock, err := Acquire(dir, "save-image")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer lock.Release()
+	if err := os.Remove(Path(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Acquire(dir, "load-image")
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder != nil {
+		t.Fatalf("Acquire() error = %v, want HeldError without holder", err)
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	skipWindows(t)
+	dir := t.TempDir()
+
+	if holder, err := ForceUnlock(dir); err != nil || holder != nil {
+		t.Fatalf("ForceUnlock() on unlocked dir = %v, %v", holder, err)
+	}
+
+	lock, err := Acquire(dir, "deploy-registry")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	holder, err := ForceUnlock(dir)
+	if err != nil || holder == nil || holder.Command != "deploy-registry" {
+		t.Fatalf("ForceUnlock() = %v, %v", holder, err)
+	}
+	other, err := Acquire(dir, "deploy-bastion")
+	if err != nil {
+		t.Fatalf("Acquire() after ForceUnlock error = %v", err)
+	}
+
+	// 被强制解锁的持有者释放时不删除新持有者的记录
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if holder, _ := Read(dir); holder == nil || holder.Command != "deploy-bastion" {
+		t.Errorf("Release() removed another holder's record: %+v", holder)
+	}
+	other.Release()
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// 支持的节点架构
+const (
+	ArchAMD64   = "amd64"
+	ArchARM64   = "arm64"
+	ArchPPC64LE = "ppc64le"
+	ArchS390X   = "s390x"
+
+	// ArchMulti 多架构 release payload：release 镜像和组件镜像都是包含全部架构的清单列表
+	ArchMulti = "multi"
+)
+
+// releaseTagSuffixes 各架构 release 镜像标签的后缀，如 4.16.3-x86_64
+var releaseTagSuffixes = map[string]string{
+	ArchAMD64:   "x86_64",
+	ArchARM64:   "aarch64",
+	ArchPPC64LE: "ppc64le",
+	ArchS390X:   "s390x",
+	ArchMulti:   "multi",
+}
+
+// GetArchitectures 返回 save_image.architectures 中的节点架构，未配置时为 amd64
+func (c *ClusterConfig) GetArchitectures() []string {
+	if len(c.SaveImage.Architectures) == 0 {
+		return []string{ArchAMD64}
+	}
+	return append([]string(nil), c.SaveImage.Architectures...)
+}
+
+// IsMultiArch 返回集群是否包含多种架构的节点 (如 x86 控制平面和 arm 计算节点)
+func (c *ClusterConfig) IsMultiArch() bool {
+	return len(c.GetArchitectures()) > 1
+}
+
+// GetReleaseArchitecture 返回镜像的 release payload 架构。配置多种架构时使用 multi payload，
+// 其清单列表包含全部架构，两种节点都可以从私有仓库拉取与自身架构对应的镜像
+func (c *ClusterConfig) GetReleaseArchitecture() string {
+	if c.IsMultiArch() {
+		return ArchMulti
+	}
+	return c.GetArchitectures()[0]
+}
+
+// GetReleaseTag 返回私有仓库中 release 镜像的标签，如 4.16.3-x86_64 或 4.16.3-multi
+func (c *ClusterConfig) GetReleaseTag() string {
+	return c.ClusterInfo.OpenShiftVersion + "-" + releaseTagSuffixes[c.GetReleaseArchitecture()]
+}
+
+// ValidateArchitectures 验证 [save_image] architectures。ocpack 生成的 ISO 和 PXE 文件用于 x86_64 控制平面，
+// 因此必须包含 amd64，其他架构的节点在安装后作为计算节点加入集群
+func ValidateArchitectures(config *ClusterConfig) error {
+	if len(config.SaveImage.Architectures) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, arch := range config.SaveImage.Architectures {
+		if _, ok := releaseTagSuffixes[arch]; !ok || arch == ArchMulti {
+			return i18n.Errorf("save_image.architectures 中的架构 %q 无效，可选 %s", arch,
+				strings.Join([]string{ArchAMD64, ArchARM64, ArchPPC64LE, ArchS390X}, i18n.T("、")))
+		}
+		if seen[arch] {
+			return i18n.Errorf("save_image.architectures 中的架构 %s 重复", arch)
+		}
+		seen[arch] = true
+	}
+	if !seen[ArchAMD64] {
+		return i18n.Errorf("save_image.architectures 必须包含 %s，控制平面节点使用 x86_64 架构", ArchAMD64)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import "testing"
+
+func TestGetReleaseTag(t *testing.T) {
+	tests := []struct {
+		name          string
+		architectures []string
+		wantArch      string
+		wantTag       string
+	}{
+		{"default", nil, "amd64", "4.16.3-x86_64"},
+		{"amd64", []string{"amd64"}, "amd64", "4.16.3-x86_64"},
+		{"heterogeneous", []string{"amd64", "arm64"}, "multi", "4.16.3-multi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+			cfg.SaveImage.Architectures = tt.architectures
+			if got := cfg.GetReleaseArchitecture(); got != tt.wantArch {
+				t.Errorf("GetReleaseArchitecture() = %s, want %s", got, tt.wantArch)
+			}
+			if got := cfg.GetReleaseTag(); got != tt.wantTag {
+				t.Errorf("GetReleaseTag() = %s, want %s", got, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestValidateArchitectures(t *testing.T) {
+	tests := []struct {
+		name          string
+		architectures []string
+		wantErr       bool
+	}{
+		{"unset", nil, false},
+		{"heterogeneous", []string{"amd64", "arm64"}, false},
+		{"all", []string{"amd64", "arm64", "ppc64le", "s390x"}, false},
+		{"unknown", []string{"amd64", "x86_64"}, true},
+		{"multi is not a node architecture", []string{"multi"}, true},
+		{"duplicate", []string{"amd64", "amd64"}, true},
+		{"without amd64", []string{"arm64"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.SaveImage.Architectures = tt.architectures
+			if err := ValidateArchitectures(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateArchitectures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Bastion DNS 额外记录支持的类型
+const (
+	DNSRecordA     = "A"
+	DNSRecordCNAME = "CNAME"
+)
+
+// dnsNamePattern 记录名或域名，由点分隔的标签组成
+var dnsNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// BastionDNS Bastion 上 named 的自定义配置，对应 [bastion.dns]
+type BastionDNS struct {
+	// 工作模式: authoritative (默认，named 作为集群域的权威 DNS) 或 hosts (dnsmasq 根据生成的 hosts 文件应答)
+	Mode string `toml:"mode,omitempty"`
+	// 上游 DNS 服务器，集群域以外的查询转发到这些服务器，未配置时 named 从根服务器递归解析
+	Forwarders []string `toml:"forwarders,omitempty"`
+	// 按域名转发，如将 idm.example.com 的查询转发到 IdM 服务器
+	ConditionalForwarders []DNSForwardZone `toml:"conditional_forwarders,omitempty"`
+	// 集群域中的额外记录，如 ntp、idm、proxy 等站点主机
+	Records []DNSRecord `toml:"records,omitempty"`
+	// 是否生成机器网络的反向解析区域，站点 DNS 已负责反向解析时设置为 false，默认为 true
+	ReverseZone *bool `toml:"reverse_zone,omitempty"`
+	// 是否启用 DNSSEC 校验，转发到未签名的内部域时设置为 false，默认为 true
+	DNSSECValidation *bool `toml:"dnssec_validation,omitempty"`
+}
+
+// DNSForwardZone 转发到指定 DNS 服务器的域
+type DNSForwardZone struct {
+	Zone    string   `toml:"zone"`
+	Servers []string `toml:"servers"`
+}
+
+// DNSRecord 集群域中的额外记录。Name 相对于 <cluster_id>.<domain>，
+// A 记录的 Value 为 IPv4 地址，CNAME 记录的 Value 为主机名 (以 . 结尾时为完整域名)
+type DNSRecord struct {
+	Name  string `toml:"name"`
+	Type  string `toml:"type"`
+	Value string `toml:"value"`
+}
+
+// ReverseZoneEnabled 返回是否生成反向解析区域
+func (d BastionDNS) ReverseZoneEnabled() bool {
+	return d.ReverseZone == nil || *d.ReverseZone
+}
+
+// DNSSECValidationEnabled 返回是否启用 DNSSEC 校验
+func (d BastionDNS) DNSSECValidationEnabled() bool {
+	return d.DNSSECValidation == nil || *d.DNSSECValidation
+}
+
+// RecordType 返回大写的记录类型，未配置时为 A
+func (r DNSRecord) RecordType() string {
+	if r.Type == "" {
+		return DNSRecordA
+	}
+	return strings.ToUpper(r.Type)
+}
+
+// ReverseDNSRecords 返回位于机器网络 /24 反向解析区域中的额外 A 记录，用于生成 PTR 记录
+func (c *ClusterConfig) ReverseDNSRecords() []DNSRecord {
+	prefix := ipv4Prefix(c.Cluster.Network.MachineNetwork)
+	var records []DNSRecord
+	for _, record := range c.Bastion.DNS.Records {
+		if record.RecordType() == DNSRecordA && ipv4Prefix(record.Value) == prefix {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// ipv4Prefix 返回 IPv4 地址或网络的前三段，如 192.168.1.0/24 -> 192.168.1
+func ipv4Prefix(address string) string {
+	base := strings.SplitN(address, "/", 2)[0]
+	if idx := strings.LastIndex(base, "."); idx >= 0 {
+		return base[:idx]
+	}
+	return base
+}
+
+// ValidateBastionDNS 验证 [bastion.dns] 配置
+func ValidateBastionDNS(config *ClusterConfig) error {
+	dns := config.Bastion.DNS
+	for _, server := range dns.Forwarders {
+		if net.ParseIP(server) == nil {
+			return i18n.Errorf("bastion.dns.forwarders 中的 %q 不是有效的 IP 地址", server)
+		}
+	}
+
+	zones := make(map[string]bool)
+	clusterZone := strings.ToLower(config.ClusterDomain())
+	for _, zone := range dns.ConditionalForwarders {
+		name := strings.ToLower(strings.TrimSuffix(zone.Zone, "."))
+		if !dnsNamePattern.MatchString(name) {
+			return i18n.Errorf("bastion.dns.conditional_forwarders 中的域名 %q 无效", zone.Zone)
+		}
+		if name == clusterZone || strings.HasSuffix(name, "."+clusterZone) {
+			return i18n.Errorf("bastion.dns.conditional_forwarders 不能转发集群域 %s", clusterZone)
+		}
+		if zones[name] {
+			return i18n.Errorf("bastion.dns.conditional_forwarders 中的域名 %s 重复", name)
+		}
+		zones[name] = true
+		if len(zone.Servers) == 0 {
+			return i18n.Errorf("bastion.dns.conditional_forwarders 中的域 %s 必须配置 servers", name)
+		}
+		for _, server := range zone.Servers {
+			if net.ParseIP(server) == nil {
+				return i18n.Errorf("bastion.dns.conditional_forwarders 中域 %s 的 %q 不是有效的 IP 地址", name, server)
+			}
+		}
+	}
+
+	reserved := config.reservedDNSNames()
+	names := make(map[string]bool)
+	for _, record := range dns.Records {
+		name := strings.ToLower(record.Name)
+		if !dnsNamePattern.MatchString(name) {
+			return i18n.Errorf("bastion.dns.records 中的记录名 %q 无效，应为相对于 %s 的名称，如 ntp", record.Name, clusterZone)
+		}
+		if reserved[name] {
+			return i18n.Errorf("bastion.dns.records 中的 %s 与 ocpack 生成的记录冲突", name)
+		}
+		if names[name] {
+			return i18n.Errorf("bastion.dns.records 中的记录名 %s 重复", name)
+		}
+		names[name] = true
+		switch record.RecordType() {
+		case DNSRecordA:
+			if ip := net.ParseIP(record.Value); ip == nil || ip.To4() == nil {
+				return i18n.Errorf("bastion.dns.records 中 %s 的值 %q 不是有效的 IPv4 地址", name, record.Value)
+			}
+		case DNSRecordCNAME:
+			if !dnsNamePattern.MatchString(strings.TrimSuffix(record.Value, ".")) {
+				return i18n.Errorf("bastion.dns.records 中 %s 的值 %q 不是有效的主机名", name, record.Value)
+			}
+		default:
+			return i18n.Errorf("bastion.dns.records 中 %s 的类型 %q 不支持，支持: A、CNAME", name, record.Type)
+		}
+	}
+	return nil
+}
+
+// reservedDNSNames 返回 ocpack 在集群域中生成的记录名
+func (c *ClusterConfig) reservedDNSNames() map[string]bool {
+	reserved := map[string]bool{
+		"bastion":  true,
+		"registry": true,
+		"api":      true,
+		"api-int":  true,
+	}
+	for _, node := range c.Cluster.ControlPlane {
+		reserved[strings.ToLower(node.Name)] = true
+	}
+	for _, node := range c.Cluster.Worker {
+		reserved[strings.ToLower(node.Name)] = true
+	}
+	return reserved
+}
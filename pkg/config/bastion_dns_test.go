@@ -0,0 +1,69 @@
+package config
+
+import "testing"
+
+func TestValidateBastionDNS(t *testing.T) {
+	tests := []struct {
+		name  string
+		dns   BastionDNS
+		valid bool
+	}{
+		{"default", BastionDNS{}, true},
+		{"forwarders", BastionDNS{Forwarders: []string{"10.0.0.53", "fd00::53"}}, true},
+		{"invalid forwarder", BastionDNS{Forwarders: []string{"dns.example.com"}}, false},
+		{"conditional forwarder", BastionDNS{ConditionalForwarders: []DNSForwardZone{{Zone: "idm.example.com.", Servers: []string{"10.0.0.10"}}}}, true},
+		{"parent domain forwarder", BastionDNS{ConditionalForwarders: []DNSForwardZone{{Zone: "example.com", Servers: []string{"10.0.0.10"}}}}, true},
+		{"cluster zone forwarder", BastionDNS{ConditionalForwarders: []DNSForwardZone{{Zone: "apps.demo.example.com", Servers: []string{"10.0.0.10"}}}}, false},
+		{"forwarder without servers", BastionDNS{ConditionalForwarders: []DNSForwardZone{{Zone: "idm.example.com"}}}, false},
+		{"duplicate zone", BastionDNS{ConditionalForwarders: []DNSForwardZone{
+			{Zone: "idm.example.com", Servers: []string{"10.0.0.10"}},
+			{Zone: "IDM.example.com", Servers: []string{"10.0.0.11"}},
+		}}, false},
+		{"records", BastionDNS{Records: []DNSRecord{
+			{Name: "ntp", Value: "192.168.1.5"},
+			{Name: "proxy", Type: "cname", Value: "squid.example.com."},
+		}}, true},
+		{"invalid A value", BastionDNS{Records: []DNSRecord{{Name: "ntp", Type: "A", Value: "fd00::5"}}}, false},
+		{"invalid CNAME value", BastionDNS{Records: []DNSRecord{{Name: "proxy", Type: "CNAME", Value: "squid_example"}}}, false},
+		{"unsupported type", BastionDNS{Records: []DNSRecord{{Name: "mail", Type: "MX", Value: "10 mx"}}}, false},
+		{"reserved name", BastionDNS{Records: []DNSRecord{{Name: "api", Value: "192.168.1.5"}}}, false},
+		{"node name", BastionDNS{Records: []DNSRecord{{Name: "master-0", Value: "192.168.1.5"}}}, false},
+		{"duplicate record", BastionDNS{Records: []DNSRecord{{Name: "ntp", Value: "192.168.1.5"}, {Name: "NTP", Value: "192.168.1.6"}}}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.ClusterID = "demo"
+		cfg.ClusterInfo.Domain = "example.com"
+		cfg.Cluster.ControlPlane = []Node{{Name: "master-0", IP: "192.168.1.10"}}
+		cfg.Bastion.DNS = tt.dns
+		if err := ValidateBastionDNS(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateBastionDNS error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestBastionDNSDefaults(t *testing.T) {
+	var dns BastionDNS
+	if !dns.ReverseZoneEnabled() || !dns.DNSSECValidationEnabled() {
+		t.Error("reverse zone and DNSSEC validation should be enabled by default")
+	}
+	disabled := false
+	dns.ReverseZone, dns.DNSSECValidation = &disabled, &disabled
+	if dns.ReverseZoneEnabled() || dns.DNSSECValidationEnabled() {
+		t.Error("reverse zone and DNSSEC validation should follow the config")
+	}
+}
+
+func TestReverseDNSRecords(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Cluster.Network.MachineNetwork = "192.168.1.0/24"
+	cfg.Bastion.DNS.Records = []DNSRecord{
+		{Name: "ntp", Value: "192.168.1.5"},
+		{Name: "idm", Value: "10.0.0.10"},
+		{Name: "proxy", Type: DNSRecordCNAME, Value: "squid.example.com."},
+	}
+	records := cfg.ReverseDNSRecords()
+	if len(records) != 1 || records[0].Name != "ntp" {
+		t.Errorf("ReverseDNSRecords() = %v, expected only ntp", records)
+	}
+}
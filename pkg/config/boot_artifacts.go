@@ -0,0 +1,22 @@
+package config
+
+import "ocpack/pkg/i18n"
+
+// DefaultKeepBootArtifacts Bastion 上每个集群默认保留的历史 PXE 文件版本数
+const DefaultKeepBootArtifacts = 2
+
+// GetKeepBootArtifacts 返回 [bastion] keep_boot_artifacts，未配置时返回 DefaultKeepBootArtifacts
+func (c *ClusterConfig) GetKeepBootArtifacts() int {
+	if c.Bastion.KeepBootArtifacts > 0 {
+		return c.Bastion.KeepBootArtifacts
+	}
+	return DefaultKeepBootArtifacts
+}
+
+// ValidateKeepBootArtifacts 验证 [bastion] keep_boot_artifacts
+func ValidateKeepBootArtifacts(config *ClusterConfig) error {
+	if keep := config.Bastion.KeepBootArtifacts; keep < 0 {
+		return i18n.Errorf("bastion.keep_boot_artifacts %d 无效，不能为负数", keep)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestGetKeepBootArtifacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		keep    int
+		want    int
+		wantErr bool
+	}{
+		{"default", 0, DefaultKeepBootArtifacts, false},
+		{"custom", 5, 5, false},
+		{"negative", -1, DefaultKeepBootArtifacts, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.Bastion.KeepBootArtifacts = tt.keep
+			if got := cfg.GetKeepBootArtifacts(); got != tt.want {
+				t.Errorf("GetKeepBootArtifacts() = %d, want %d", got, tt.want)
+			}
+			if err := ValidateKeepBootArtifacts(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKeepBootArtifacts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
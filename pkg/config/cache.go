@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"ocpack/pkg/i18n"
+)
+
+// ParseSize 解析大小，单位与配额相同，支持 K/M/G/T (十进制) 和 Ki/Mi/Gi/Ti (二进制)
+func ParseSize(size string) (int64, error) {
+	return parseByteSize(size, i18n.T("大小"), i18n.T("200Gi、1Ti"))
+}
+
+// GetMaxCacheSize 返回 [save_image] max_cache_size 的字节数，未配置时返回 0 (不自动清理)
+func (c *ClusterConfig) GetMaxCacheSize() (int64, error) {
+	if c.SaveImage.MaxCacheSize == "" {
+		return 0, nil
+	}
+	return ParseSize(c.SaveImage.MaxCacheSize)
+}
+
+// ValidateMaxCacheSize 验证 [save_image] max_cache_size
+func ValidateMaxCacheSize(config *ClusterConfig) error {
+	if _, err := config.GetMaxCacheSize(); err != nil {
+		return fmt.Errorf("save_image.max_cache_size: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+// legacyCatalogSourceName 未配置 operator_catalogs 时 day2 使用的 CatalogSource 名称，与旧版本保持一致
+const legacyCatalogSourceName = "redhat-operators"
+
+// wellKnownCatalogs Red Hat 提供的 Operator 目录简称，catalog 填写简称时标签根据 openshift_version 自动生成
+var wellKnownCatalogs = map[string]string{
+	"redhat":      "registry.redhat.io/redhat/redhat-operator-index",
+	"certified":   "registry.redhat.io/redhat/certified-operator-index",
+	"community":   "registry.redhat.io/redhat/community-operator-index",
+	"marketplace": "registry.redhat.io/redhat/redhat-marketplace-index",
+}
+
+// DefaultCatalogCacheTTL 未配置 catalog_cache_ttl 时 Operator 目录摘要的缓存时间
+const DefaultCatalogCacheTTL = 24 * time.Hour
+
+// ociCatalogPrefix 本地磁盘上 OCI 格式 FBC 目录的前缀，如 oci:///data/catalogs/my-index
+const ociCatalogPrefix = "oci:"
+
+// catalogSourceNamePattern CatalogSource 名称必须是合法的 DNS-1035 label
+var catalogSourceNamePattern = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// OperatorCatalog 一个需要镜像的 Operator 目录，对应 [[save_image.operator_catalogs]]
+type OperatorCatalog struct {
+	Catalog           string   `toml:"catalog"`                       // 源目录镜像，简称 redhat、certified、community、marketplace，或本地 OCI 目录 oci://<路径>
+	TargetCatalog     string   `toml:"target_catalog,omitempty"`      // 可选，镜像到私有仓库后的目录路径 (不含 registry 主机和标签)
+	TargetTag         string   `toml:"target_tag,omitempty"`          // 可选，镜像到私有仓库后的目录标签
+	CatalogSourceName string   `toml:"catalog_source_name,omitempty"` // 可选，集群中 CatalogSource 的名称，默认根据目录名生成
+	DisplayName       string   `toml:"display_name,omitempty"`        // 可选，OperatorHub 中显示的名称
+	Ops               []string `toml:"ops"`                           // 需要的 Operator 列表
+}
+
+// GetOperatorCatalogs 返回需要镜像的全部 Operator 目录，目录简称已展开为完整的镜像地址。
+// 未配置 operator_catalogs 时，使用 operator_catalog 和 ops 组成单个目录，CatalogSource 名称为 redhat-operators。
+// presets 中预置组件的 Operator 已加入对应的目录
+func (c *ClusterConfig) GetOperatorCatalogs() []OperatorCatalog {
+	return c.withPresetOperators(c.configuredOperatorCatalogs())
+}
+
+// configuredOperatorCatalogs 返回 config.toml 中配置的 Operator 目录
+func (c *ClusterConfig) configuredOperatorCatalogs() []OperatorCatalog {
+	if len(c.SaveImage.OperatorCatalogs) > 0 {
+		catalogs := make([]OperatorCatalog, 0, len(c.SaveImage.OperatorCatalogs))
+		for _, catalog := range c.SaveImage.OperatorCatalogs {
+			if repository, ok := wellKnownCatalogs[catalog.Catalog]; ok {
+				catalog.Catalog = c.versionedCatalog(repository)
+			}
+			catalogs = append(catalogs, c.withOCITag(catalog))
+		}
+		return catalogs
+	}
+	if len(c.SaveImage.Ops) == 0 {
+		return nil
+	}
+	return []OperatorCatalog{c.withOCITag(OperatorCatalog{
+		Catalog:           c.GetOperatorCatalog(),
+		CatalogSourceName: legacyCatalogSourceName,
+		Ops:               c.SaveImage.Ops,
+	})}
+}
+
+// FindOperatorCatalog 返回 operator 所在的 Operator 目录，用于 delete-images 定向删除。
+// catalog 非空时按目录地址或简称 (redhat、certified 等) 查找；否则使用 ops 中包含 operator 的目录，
+// operator 已从 ops 中移除且只配置了一个目录时使用该目录
+func (c *ClusterConfig) FindOperatorCatalog(operator, catalog string) (OperatorCatalog, error) {
+	catalogs := c.GetOperatorCatalogs()
+	if catalog != "" {
+		if repository, ok := wellKnownCatalogs[catalog]; ok {
+			catalog = c.versionedCatalog(repository)
+		}
+		for _, candidate := range catalogs {
+			if candidate.Catalog == catalog {
+				return candidate, nil
+			}
+		}
+		return OperatorCatalog{}, i18n.Errorf("config.toml 中没有 Operator 目录 %s", catalog)
+	}
+
+	var found []OperatorCatalog
+	for _, candidate := range catalogs {
+		for _, op := range candidate.Ops {
+			if op == operator {
+				found = append(found, candidate)
+				break
+			}
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1:
+		return OperatorCatalog{}, i18n.Errorf("多个 Operator 目录包含 %s，请使用 --catalog 指定目录", operator)
+	case len(catalogs) == 1:
+		return catalogs[0], nil
+	case len(catalogs) == 0:
+		return OperatorCatalog{}, i18n.Errorf("config.toml 中未配置 Operator 目录")
+	default:
+		return OperatorCatalog{}, i18n.Errorf("没有 Operator 目录的 ops 包含 %s，请使用 --catalog 指定目录", operator)
+	}
+}
+
+// withOCITag OCI 目录没有标签，未设置 target_tag 时按 openshift_version 使用 v4.x 标签，
+// 与 Red Hat 目录一致，避免重新构建的目录镜像推送到私有仓库后都使用 latest
+func (c *ClusterConfig) withOCITag(catalog OperatorCatalog) OperatorCatalog {
+	if catalog.IsOCI() && catalog.TargetTag == "" {
+		catalog.TargetTag = "v" + utils.ExtractMajorVersion(c.ClusterInfo.OpenShiftVersion)
+	}
+	return catalog
+}
+
+// versionedCatalog 为目录仓库加上与 openshift_version 对应的标签，如 v4.14
+func (c *ClusterConfig) versionedCatalog(repository string) string {
+	return fmt.Sprintf("%s:v%s", repository, utils.ExtractMajorVersion(c.ClusterInfo.OpenShiftVersion))
+}
+
+// ValidateOperatorCatalogs 验证 operator_catalogs 配置，CatalogSource 名称不能重复
+func ValidateOperatorCatalogs(config *ClusterConfig) error {
+	names := make(map[string]string)
+	for i, catalog := range config.GetOperatorCatalogs() {
+		if catalog.Catalog == "" {
+			return i18n.Errorf("operator_catalogs[%d] 的 catalog 不能为空", i)
+		}
+		if len(catalog.Ops) == 0 {
+			return i18n.Errorf("operator_catalogs[%d] %s 的 ops 不能为空", i, catalog.Catalog)
+		}
+		if catalog.IsOCI() && catalog.OCIPath("") == "." {
+			return i18n.Errorf("operator_catalogs[%d] 的 OCI 目录 %s 缺少路径", i, catalog.Catalog)
+		}
+		if strings.ContainsAny(catalog.TargetCatalog, ":@") {
+			return i18n.Errorf("operator_catalogs[%d] 的 target_catalog %s 不能包含标签或摘要", i, catalog.TargetCatalog)
+		}
+		if strings.ContainsAny(catalog.TargetTag, ":@/") {
+			return i18n.Errorf("operator_catalogs[%d] 的 target_tag %s 无效", i, catalog.TargetTag)
+		}
+
+		name := catalog.GetCatalogSourceName()
+		if !catalogSourceNamePattern.MatchString(name) {
+			return i18n.Errorf("operator_catalogs[%d] 的 CatalogSource 名称 %s 无效，只能包含小写字母、数字和 '-'，且以字母开头", i, name)
+		}
+		if other, ok := names[name]; ok {
+			return i18n.Errorf("目录 %s 和 %s 的 CatalogSource 名称均为 %s，请设置 catalog_source_name", other, catalog.Catalog, name)
+		}
+		names[name] = catalog.Catalog
+	}
+	return nil
+}
+
+// GetCatalogSourceName 返回集群中 CatalogSource 的名称。未配置时根据目录名生成，
+// 例如 redhat-operator-index 对应 redhat-operators，certified-operator-index 对应 certified-operators
+func (o OperatorCatalog) GetCatalogSourceName() string {
+	if o.CatalogSourceName != "" {
+		return o.CatalogSourceName
+	}
+	path, _ := o.MirroredRepository()
+	name := path[strings.LastIndex(path, "/")+1:]
+	name = strings.TrimSuffix(name, "-index")
+	if strings.HasSuffix(name, "-operator") {
+		name += "s"
+	}
+	return strings.ToLower(strings.ReplaceAll(name, ".", "-"))
+}
+
+// GetDisplayName 返回 OperatorHub 中显示的名称，未配置时使用 CatalogSource 名称和私有仓库地址
+func (o OperatorCatalog) GetDisplayName(registryHost string) string {
+	if o.DisplayName != "" {
+		return o.DisplayName
+	}
+	return fmt.Sprintf("%s (%s)", o.GetCatalogSourceName(), registryHost)
+}
+
+// IsOCI 判断目录是否为本地磁盘上的 OCI 格式 FBC 目录 (oci://<路径>)
+func (o OperatorCatalog) IsOCI() bool {
+	return strings.HasPrefix(o.Catalog, ociCatalogPrefix)
+}
+
+// OCIPath 返回 OCI 目录在本地磁盘上的路径，相对路径基于 clusterDir
+func (o OperatorCatalog) OCIPath(clusterDir string) string {
+	dir := strings.TrimPrefix(strings.TrimPrefix(o.Catalog, ociCatalogPrefix), "//")
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(clusterDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// CheckOCILayout 检查 OCI 目录是否存在且为 OCI 镜像布局 (包含 oci-layout 和 index.json)
+func (o OperatorCatalog) CheckOCILayout(clusterDir string) error {
+	dir := o.OCIPath(clusterDir)
+	for _, name := range []string{"oci-layout", "index.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return i18n.Errorf("OCI 目录 %s 无效，缺少 %s: %w", dir, name, err)
+		}
+	}
+	return nil
+}
+
+// MirroredRepository 返回目录镜像到私有仓库后的仓库路径 (不含 registry 主机) 和标签，
+// 使用摘要引用且未设置 target_tag 时标签为空。
+// OCI 目录与 oc-mirror 一致，默认使用目录名作为仓库路径、latest 作为标签
+func (o OperatorCatalog) MirroredRepository() (path, tag string) {
+	if o.IsOCI() {
+		path, tag = filepath.Base(o.OCIPath("")), "latest"
+	} else {
+		path, tag = splitImageReference(o.Catalog)
+	}
+	if o.TargetCatalog != "" {
+		path = strings.Trim(o.TargetCatalog, "/")
+	}
+	if o.TargetTag != "" {
+		tag = o.TargetTag
+	}
+	return path, tag
+}
+
+// MatchesImage 判断私有仓库中的镜像引用 (如 CatalogSource 的 spec.image) 是否为该目录的镜像
+func (o OperatorCatalog) MatchesImage(image string) bool {
+	path, tag := o.MirroredRepository()
+	imagePath, imageTag := splitImageReference(image)
+	if imagePath != path && !strings.HasSuffix(imagePath, "/"+path) {
+		return false
+	}
+	return tag == "" || imageTag == "" || tag == imageTag
+}
+
+// splitImageReference 将镜像引用拆分为去掉 registry 主机的仓库路径和标签，摘要引用的标签为空
+func splitImageReference(ref string) (path, tag string) {
+	digest := false
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		ref, digest = ref[:idx], true
+	}
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		ref, tag = ref[:idx], ref[idx+1:]
+	}
+	if digest {
+		tag = ""
+	}
+	// 第一段包含 '.' 或 ':' 或为 localhost 时视为 registry 主机
+	if idx := strings.Index(ref, "/"); idx >= 0 {
+		host := ref[:idx]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref = ref[idx+1:]
+		}
+	}
+	return ref, tag
+}
+
+// GetCatalogCacheTTL 返回 Operator 目录摘要的缓存时间，为 0 时每次都查询源仓库
+func (c *ClusterConfig) GetCatalogCacheTTL() time.Duration {
+	if c.SaveImage.CatalogCacheTTL == "" {
+		return DefaultCatalogCacheTTL
+	}
+	ttl, err := time.ParseDuration(c.SaveImage.CatalogCacheTTL)
+	if err != nil {
+		return DefaultCatalogCacheTTL
+	}
+	return ttl
+}
+
+// ValidateCatalogCacheTTL 验证 [save_image] catalog_cache_ttl，必须是非负的时长，如 "6h"、"48h" 或 "0"
+func ValidateCatalogCacheTTL(config *ClusterConfig) error {
+	value := config.SaveImage.CatalogCacheTTL
+	if value == "" {
+		return nil
+	}
+	if ttl, err := time.ParseDuration(value); err != nil || ttl < 0 {
+		return i18n.Errorf("save_image.catalog_cache_ttl %q 必须是非负的时长，如 \"6h\"、\"48h\" 或 \"0\"", value)
+	}
+	return nil
+}
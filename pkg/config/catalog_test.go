@@ -0,0 +1,270 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetOperatorCatalogsLegacy(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+
+	catalogs := cfg.GetOperatorCatalogs()
+	if len(catalogs) != 1 {
+		t.Fatalf("expected 1 catalog, got %d", len(catalogs))
+	}
+	if catalogs[0].Catalog != "registry.redhat.io/redhat/redhat-operator-index:v4.16" {
+		t.Errorf("Catalog = %q", catalogs[0].Catalog)
+	}
+	if name := catalogs[0].GetCatalogSourceName(); name != "redhat-operators" {
+		t.Errorf("GetCatalogSourceName() = %q, expected redhat-operators", name)
+	}
+
+	cfg.SaveImage.Ops = nil
+	if catalogs := cfg.GetOperatorCatalogs(); len(catalogs) != 0 {
+		t.Errorf("expected no catalogs without ops, got %d", len(catalogs))
+	}
+}
+
+func TestOperatorCatalogNames(t *testing.T) {
+	tests := []struct {
+		catalog  OperatorCatalog
+		expected string
+	}{
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14"}, "redhat-operators"},
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14"}, "certified-operators"},
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/community-operator-index:v4.14"}, "community-operators"},
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/redhat-marketplace-index:v4.14"}, "redhat-marketplace"},
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14", TargetCatalog: "mirror/partner-index"}, "partner"},
+		{OperatorCatalog{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14", CatalogSourceName: "custom"}, "custom"},
+	}
+
+	for _, tt := range tests {
+		if name := tt.catalog.GetCatalogSourceName(); name != tt.expected {
+			t.Errorf("GetCatalogSourceName(%+v) = %q, expected %q", tt.catalog, name, tt.expected)
+		}
+	}
+}
+
+func TestOperatorCatalogMatchesImage(t *testing.T) {
+	catalog := OperatorCatalog{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14"}
+	retargeted := OperatorCatalog{
+		Catalog:       "registry.redhat.io/redhat/certified-operator-index:v4.14",
+		TargetCatalog: "partner/index",
+		TargetTag:     "latest",
+	}
+
+	tests := []struct {
+		catalog  OperatorCatalog
+		image    string
+		expected bool
+	}{
+		{catalog, "registry.demo.example.com:8443/redhat/certified-operator-index:v4.14", true},
+		{catalog, "registry.demo.example.com:8443/mirror/redhat/certified-operator-index:v4.14", true},
+		{catalog, "registry.demo.example.com:8443/redhat/certified-operator-index@sha256:abcd", true},
+		{catalog, "registry.demo.example.com:8443/redhat/certified-operator-index:v4.15", false},
+		{catalog, "registry.demo.example.com:8443/redhat/redhat-operator-index:v4.14", false},
+		{retargeted, "registry.demo.example.com:8443/partner/index:latest", true},
+		{retargeted, "registry.demo.example.com:8443/redhat-mirror/partner/index:latest", true},
+		{retargeted, "registry.demo.example.com:8443/redhat/certified-operator-index:v4.14", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.catalog.MatchesImage(tt.image); got != tt.expected {
+			t.Errorf("MatchesImage(%s) for %s = %t, expected %t", tt.image, tt.catalog.Catalog, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateOperatorCatalogs(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+		{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14", Ops: []string{"cluster-logging"}},
+		{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14", Ops: []string{"gpu-operator-certified"}},
+	}
+	if err := ValidateOperatorCatalogs(cfg); err != nil {
+		t.Fatalf("ValidateOperatorCatalogs() error = %v", err)
+	}
+
+	invalid := []func(c *OperatorCatalog){
+		func(c *OperatorCatalog) { c.CatalogSourceName = "redhat-operators" },
+		func(c *OperatorCatalog) { c.CatalogSourceName = "Certified_Operators" },
+		func(c *OperatorCatalog) { c.TargetCatalog = "partner/index:latest" },
+		func(c *OperatorCatalog) { c.TargetTag = "v1/2" },
+		func(c *OperatorCatalog) { c.Ops = nil },
+	}
+	for i, mutate := range invalid {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+			{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14", Ops: []string{"cluster-logging"}},
+			{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14", Ops: []string{"gpu-operator-certified"}},
+		}
+		mutate(&cfg.SaveImage.OperatorCatalogs[1])
+		if err := ValidateOperatorCatalogs(cfg); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestGetOperatorCatalogsWellKnown(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+		{Catalog: "redhat", Ops: []string{"cluster-logging"}},
+		{Catalog: "certified", Ops: []string{"gpu-operator-certified"}},
+		{Catalog: "community", Ops: []string{"prometheus"}},
+		{Catalog: "marketplace", Ops: []string{"crunchy-postgres-operator-rhmp"}},
+		{Catalog: "quay.io/example/custom-index:latest", Ops: []string{"custom-operator"}},
+	}
+
+	expected := []struct{ catalog, name string }{
+		{"registry.redhat.io/redhat/redhat-operator-index:v4.16", "redhat-operators"},
+		{"registry.redhat.io/redhat/certified-operator-index:v4.16", "certified-operators"},
+		{"registry.redhat.io/redhat/community-operator-index:v4.16", "community-operators"},
+		{"registry.redhat.io/redhat/redhat-marketplace-index:v4.16", "redhat-marketplace"},
+		{"quay.io/example/custom-index:latest", "custom"},
+	}
+	catalogs := cfg.GetOperatorCatalogs()
+	if len(catalogs) != len(expected) {
+		t.Fatalf("expected %d catalogs, got %d", len(expected), len(catalogs))
+	}
+	for i, want := range expected {
+		if catalogs[i].Catalog != want.catalog {
+			t.Errorf("catalogs[%d].Catalog = %q, expected %q", i, catalogs[i].Catalog, want.catalog)
+		}
+		if name := catalogs[i].GetCatalogSourceName(); name != want.name {
+			t.Errorf("catalogs[%d] CatalogSource name = %q, expected %q", i, name, want.name)
+		}
+	}
+	if cfg.SaveImage.OperatorCatalogs[1].Catalog != "certified" {
+		t.Error("GetOperatorCatalogs() should not modify the configuration")
+	}
+	if err := ValidateOperatorCatalogs(cfg); err != nil {
+		t.Errorf("ValidateOperatorCatalogs() error = %v", err)
+	}
+}
+
+func TestFindOperatorCatalog(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+		{Catalog: "redhat", Ops: []string{"cluster-logging", "odf-operator"}},
+		{Catalog: "certified", Ops: []string{"gpu-operator-certified", "odf-operator"}},
+	}
+
+	tests := []struct {
+		operator, catalog, expected string
+	}{
+		{"cluster-logging", "", "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+		{"gpu-operator-certified", "", "registry.redhat.io/redhat/certified-operator-index:v4.16"},
+		{"odf-operator", "certified", "registry.redhat.io/redhat/certified-operator-index:v4.16"},
+		{"odf-operator", "registry.redhat.io/redhat/redhat-operator-index:v4.16", "registry.redhat.io/redhat/redhat-operator-index:v4.16"},
+		{"odf-operator", "", ""},        // 多个目录包含
+		{"deprecated-operator", "", ""}, // 没有目录包含，且配置了多个目录
+		{"cluster-logging", "community", ""},
+	}
+	for _, tt := range tests {
+		catalog, err := cfg.FindOperatorCatalog(tt.operator, tt.catalog)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("FindOperatorCatalog(%q, %q) expected error, got %s", tt.operator, tt.catalog, catalog.Catalog)
+			}
+			continue
+		}
+		if err != nil || catalog.Catalog != tt.expected {
+			t.Errorf("FindOperatorCatalog(%q, %q) = %q, %v, expected %q", tt.operator, tt.catalog, catalog.Catalog, err, tt.expected)
+		}
+	}
+
+	// 只有一个目录时，已从 ops 中移除的 Operator 也使用该目录
+	cfg.SaveImage.OperatorCatalogs = cfg.SaveImage.OperatorCatalogs[:1]
+	if catalog, err := cfg.FindOperatorCatalog("deprecated-operator", ""); err != nil || catalog.Catalog != "registry.redhat.io/redhat/redhat-operator-index:v4.16" {
+		t.Errorf("FindOperatorCatalog() = %q, %v", catalog.Catalog, err)
+	}
+}
+
+func TestOperatorCatalogOCI(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+		{Catalog: "oci:///data/catalogs/my-operator-index", Ops: []string{"my-operator"}},
+		{Catalog: "oci://catalogs/partner", TargetTag: "v1", Ops: []string{"partner-operator"}},
+	}
+
+	catalogs := cfg.GetOperatorCatalogs()
+	if !catalogs[0].IsOCI() || catalogs[0].OCIPath("/work/demo") != "/data/catalogs/my-operator-index" {
+		t.Errorf("OCIPath() = %q", catalogs[0].OCIPath("/work/demo"))
+	}
+	if got := catalogs[1].OCIPath("/work/demo"); got != filepath.Join("/work/demo", "catalogs", "partner") {
+		t.Errorf("OCIPath() = %q, expected path relative to cluster directory", got)
+	}
+
+	// 未设置 target_tag 时按版本打标签，仓库路径为目录名
+	if path, tag := catalogs[0].MirroredRepository(); path != "my-operator-index" || tag != "v4.16" {
+		t.Errorf("MirroredRepository() = %s:%s, expected my-operator-index:v4.16", path, tag)
+	}
+	if path, tag := catalogs[1].MirroredRepository(); path != "partner" || tag != "v1" {
+		t.Errorf("MirroredRepository() = %s:%s, expected partner:v1", path, tag)
+	}
+	if name := catalogs[0].GetCatalogSourceName(); name != "my-operators" {
+		t.Errorf("GetCatalogSourceName() = %q, expected my-operators", name)
+	}
+	if !catalogs[0].MatchesImage("registry.demo.example.com:8443/my-operator-index:v4.16") {
+		t.Error("MatchesImage() = false for mirrored OCI catalog")
+	}
+	if err := ValidateOperatorCatalogs(cfg); err != nil {
+		t.Errorf("ValidateOperatorCatalogs() error = %v", err)
+	}
+
+	cfg.SaveImage.OperatorCatalogs[1].Catalog = "oci://"
+	if err := ValidateOperatorCatalogs(cfg); err == nil {
+		t.Error("expected error for OCI catalog without path")
+	}
+}
+
+func TestOperatorCatalogCheckOCILayout(t *testing.T) {
+	clusterDir := t.TempDir()
+	catalog := OperatorCatalog{Catalog: "oci://catalog"}
+	if err := catalog.CheckOCILayout(clusterDir); err == nil {
+		t.Error("expected error for missing OCI directory")
+	}
+
+	dir := filepath.Join(clusterDir, "catalog")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"oci-layout", "index.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := catalog.CheckOCILayout(clusterDir); err != nil {
+		t.Errorf("CheckOCILayout() error = %v", err)
+	}
+}
+
+func TestCatalogCacheTTL(t *testing.T) {
+	tests := []struct {
+		value string
+		ttl   time.Duration
+		valid bool
+	}{
+		{"", DefaultCatalogCacheTTL, true},
+		{"48h", 48 * time.Hour, true},
+		{"0", 0, true},
+		{"-1h", 0, false},
+		{"1d", 0, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.CatalogCacheTTL = tt.value
+		if err := ValidateCatalogCacheTTL(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateCatalogCacheTTL(%q) error = %v, expected valid = %t", tt.value, err, tt.valid)
+		}
+		if tt.valid && cfg.GetCatalogCacheTTL() != tt.ttl {
+			t.Errorf("GetCatalogCacheTTL(%q) = %s, want %s", tt.value, cfg.GetCatalogCacheTTL(), tt.ttl)
+		}
+	}
+}
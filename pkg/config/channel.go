@@ -0,0 +1,164 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+// DefaultChannel 未配置 cluster_info.channel 时使用的升级通道
+const DefaultChannel = "stable"
+
+// releaseChannels 支持的升级通道前缀
+var releaseChannels = []string{"stable", "fast", "candidate", "eus"}
+
+// channelFallbacks 版本尚未进入通道时依次尝试的通道。新的 .0 版本通常先进入 fast 和 candidate，
+// EUS 通道在版本进入 stable 之后才会更新
+var channelFallbacks = map[string][]string{
+	"eus":    {"stable", "fast", "candidate"},
+	"stable": {"fast", "candidate"},
+	"fast":   {"candidate"},
+}
+
+// ChannelVersionsFunc 返回通道中的全部 release 版本，用于确认需要镜像的版本已进入该通道
+type ChannelVersionsFunc func(channel string) ([]string, error)
+
+// GetReleaseChannel 返回 Cincinnati 升级通道的完整名称，如 stable-4.14 或 eus-4.14。
+// cluster_info.channel 可以是 stable/fast/candidate/eus，也可以是完整的通道名称
+func (c *ClusterConfig) GetReleaseChannel() string {
+	return c.releaseChannel(c.ClusterInfo.OpenShiftVersion)
+}
+
+// GetMirrorChannel 返回镜像 release 时使用的通道。升级路径需要目标版本所在的通道，
+// 因此未配置完整通道名称时按最高版本生成，如 4.14 到 4.16 使用 stable-4.16
+func (c *ClusterConfig) GetMirrorChannel() string {
+	_, maxVersion := c.GetReleaseRange()
+	return c.releaseChannel(maxVersion)
+}
+
+// ResolveMirrorChannel 确认镜像使用的通道包含需要镜像的最高版本。
+// 版本尚未进入按 cluster_info.channel 生成的通道时，依次改用 fast、candidate (eus 先改用 stable)，并返回说明；
+// cluster_info.channel 为完整的通道名称 (如 fast-4.16) 时视为用户指定，不自动切换。
+// 查询通道失败 (如离线环境) 时继续使用生成的通道
+func (c *ClusterConfig) ResolveMirrorChannel(versions ChannelVersionsFunc) (channel, warning string, err error) {
+	channel = c.GetMirrorChannel()
+	_, maxVersion := c.GetReleaseRange()
+
+	found, lookupErr := channelHasVersion(versions, channel, maxVersion)
+	if lookupErr != nil {
+		return channel, i18n.Sprintf("无法查询通道 %s 中的版本 (%v)，继续使用该通道", channel, lookupErr), nil
+	}
+	if found {
+		return channel, "", nil
+	}
+	if strings.Contains(c.ClusterInfo.Channel, "-") {
+		return channel, i18n.Sprintf("通道 %s 中未找到版本 %s，按 cluster_info.channel 的配置继续使用该通道", channel, maxVersion), nil
+	}
+
+	prefix, minor, _ := strings.Cut(channel, "-")
+	tried := []string{channel}
+	for _, fallback := range channelFallbacks[prefix] {
+		candidate := fallback + "-" + minor
+		found, lookupErr := channelHasVersion(versions, candidate, maxVersion)
+		if lookupErr != nil {
+			return channel, i18n.Sprintf("通道 %s 中未找到版本 %s，且无法查询通道 %s (%v)，继续使用 %s", channel, maxVersion, candidate, lookupErr, channel), nil
+		}
+		if found {
+			return candidate, i18n.Sprintf("版本 %s 尚未进入通道 %s，改用 %s", maxVersion, channel, candidate), nil
+		}
+		tried = append(tried, candidate)
+	}
+	return "", "", i18n.Errorf("通道 %s 中均未找到版本 %s，请检查 openshift_version", strings.Join(tried, "、"), maxVersion)
+}
+
+// channelHasVersion 判断通道中是否包含指定版本
+func channelHasVersion(versions ChannelVersionsFunc, channel, version string) (bool, error) {
+	list, err := versions(channel)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range list {
+		if v == version {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetReleaseRange 返回需要镜像的 release 最低和最高版本，未配置时均为 openshift_version
+func (c *ClusterConfig) GetReleaseRange() (minVersion, maxVersion string) {
+	minVersion, maxVersion = c.SaveImage.OpenShiftVersionMin, c.SaveImage.OpenShiftVersionMax
+	if minVersion == "" {
+		minVersion = c.ClusterInfo.OpenShiftVersion
+	}
+	if maxVersion == "" {
+		maxVersion = c.ClusterInfo.OpenShiftVersion
+	}
+	return minVersion, maxVersion
+}
+
+// releaseChannel 根据 cluster_info.channel 和版本生成完整的通道名称
+func (c *ClusterConfig) releaseChannel(version string) string {
+	channel := c.ClusterInfo.Channel
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	if strings.Contains(channel, "-") {
+		return channel
+	}
+	return channel + "-" + utils.ExtractMajorVersion(version)
+}
+
+// ValidateReleaseRange 验证 release 版本范围，openshift_version 必须位于范围内
+func ValidateReleaseRange(config *ClusterConfig) error {
+	minVersion, maxVersion := config.GetReleaseRange()
+	version := config.ClusterInfo.OpenShiftVersion
+	if utils.CompareVersion(minVersion, maxVersion) > 0 {
+		return i18n.Errorf("openshift_version_min %s 不能高于 openshift_version_max %s", minVersion, maxVersion)
+	}
+	if utils.CompareVersion(version, minVersion) < 0 || utils.CompareVersion(version, maxVersion) > 0 {
+		return i18n.Errorf("openshift_version %s 不在镜像版本范围 %s - %s 内", version, minVersion, maxVersion)
+	}
+	return nil
+}
+
+// ValidateReleaseChannel 验证安装和镜像使用的升级通道，eus 通道只适用于偶数次版本 (如 4.14、4.16)
+func ValidateReleaseChannel(config *ClusterConfig) error {
+	for _, channel := range []string{config.GetReleaseChannel(), config.GetMirrorChannel()} {
+		if err := validateChannel(channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateChannel 验证单个完整的通道名称
+func validateChannel(channel string) error {
+	prefix, version, _ := strings.Cut(channel, "-")
+
+	valid := false
+	for _, c := range releaseChannels {
+		if prefix == c {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return i18n.Errorf("升级通道 %s 无效，cluster_info.channel 只能是 %s 或完整的通道名称", channel, strings.Join(releaseChannels, "/"))
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 2 {
+		return i18n.Errorf("升级通道 %s 无效，版本格式应为 <主版本>.<次版本>", channel)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return i18n.Errorf("升级通道 %s 无效，版本格式应为 <主版本>.<次版本>", channel)
+	}
+	if prefix == "eus" && minor%2 != 0 {
+		return i18n.Errorf("升级通道 %s 无效，EUS 通道只适用于偶数次版本", channel)
+	}
+	return nil
+}
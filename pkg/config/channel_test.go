@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetReleaseChannel(t *testing.T) {
+	tests := []struct {
+		version  string
+		channel  string
+		expected string
+		valid    bool
+	}{
+		{"4.14.10", "", "stable-4.14", true},
+		{"4.14.10", "fast", "fast-4.14", true},
+		{"4.15.2", "candidate", "candidate-4.15", true},
+		{"4.16.3", "eus", "eus-4.16", true},
+		{"4.15.2", "eus", "eus-4.15", false},
+		{"4.16.3", "eus-4.14", "eus-4.14", true},
+		{"4.16.3", "nightly", "nightly-4.16", false},
+		{"4.16.3", "stable-4", "stable-4", false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = tt.version
+		cfg.ClusterInfo.Channel = tt.channel
+
+		if channel := cfg.GetReleaseChannel(); channel != tt.expected {
+			t.Errorf("GetReleaseChannel(%s, %q) = %q, expected %q", tt.version, tt.channel, channel, tt.expected)
+		}
+		if err := ValidateReleaseChannel(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateReleaseChannel(%s, %q) error = %v, expected valid = %t", tt.version, tt.channel, err, tt.valid)
+		}
+	}
+}
+
+func TestGetReleaseRange(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.14.10"
+
+	if minVersion, maxVersion := cfg.GetReleaseRange(); minVersion != "4.14.10" || maxVersion != "4.14.10" {
+		t.Errorf("GetReleaseRange() = %s, %s, expected openshift_version for both", minVersion, maxVersion)
+	}
+
+	cfg.SaveImage.OpenShiftVersionMax = "4.16.3"
+	if minVersion, maxVersion := cfg.GetReleaseRange(); minVersion != "4.14.10" || maxVersion != "4.16.3" {
+		t.Errorf("GetReleaseRange() = %s, %s", minVersion, maxVersion)
+	}
+	if channel := cfg.GetMirrorChannel(); channel != "stable-4.16" {
+		t.Errorf("GetMirrorChannel() = %q, expected stable-4.16", channel)
+	}
+	if channel := cfg.GetReleaseChannel(); channel != "stable-4.14" {
+		t.Errorf("GetReleaseChannel() = %q, expected stable-4.14", channel)
+	}
+	if err := ValidateReleaseRange(cfg); err != nil {
+		t.Errorf("ValidateReleaseRange() error = %v", err)
+	}
+
+	// EUS 到 EUS 升级
+	cfg.ClusterInfo.Channel = "eus"
+	if err := ValidateReleaseChannel(cfg); err != nil {
+		t.Errorf("ValidateReleaseChannel() error = %v", err)
+	}
+	cfg.SaveImage.OpenShiftVersionMax = "4.15.2"
+	if err := ValidateReleaseChannel(cfg); err == nil {
+		t.Error("expected error for eus channel with odd maximum version")
+	}
+
+	invalid := []struct{ min, max string }{
+		{"4.16.0", "4.14.0"},
+		{"4.14.11", "4.16.3"},
+		{"", "4.14.9"},
+	}
+	for _, tt := range invalid {
+		cfg.SaveImage.OpenShiftVersionMin, cfg.SaveImage.OpenShiftVersionMax = tt.min, tt.max
+		if err := ValidateReleaseRange(cfg); err == nil {
+			t.Errorf("ValidateReleaseRange(%q, %q) expected error", tt.min, tt.max)
+		}
+	}
+}
+
+func TestResolveMirrorChannel(t *testing.T) {
+	channels := map[string][]string{
+		"stable-4.16":    {"4.16.1", "4.16.2"},
+		"fast-4.16":      {"4.16.1", "4.16.2", "4.16.3"},
+		"candidate-4.16": {"4.16.1", "4.16.2", "4.16.3", "4.16.4"},
+		"eus-4.16":       {"4.16.1"},
+	}
+	versions := func(channel string) ([]string, error) {
+		return channels[channel], nil
+	}
+
+	tests := []struct {
+		version  string
+		channel  string
+		expected string
+		warning  bool
+		valid    bool
+	}{
+		{"4.16.2", "", "stable-4.16", false, true},
+		{"4.16.3", "", "fast-4.16", true, true},
+		{"4.16.4", "stable", "candidate-4.16", true, true},
+		{"4.16.2", "eus", "stable-4.16", true, true},
+		{"4.16.3", "stable-4.16", "stable-4.16", true, true}, // 完整通道名称不自动切换
+		{"4.16.9", "", "", false, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = tt.version
+		cfg.ClusterInfo.Channel = tt.channel
+
+		channel, warning, err := cfg.ResolveMirrorChannel(versions)
+		if (err == nil) != tt.valid {
+			t.Errorf("ResolveMirrorChannel(%s, %q) error = %v, expected valid = %t", tt.version, tt.channel, err, tt.valid)
+			continue
+		}
+		if channel != tt.expected || (warning != "") != tt.warning {
+			t.Errorf("ResolveMirrorChannel(%s, %q) = %q, %q, expected %q (warning %t)", tt.version, tt.channel, channel, warning, tt.expected, tt.warning)
+		}
+	}
+
+	// 无法查询通道时继续使用生成的通道
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	channel, warning, err := cfg.ResolveMirrorChannel(func(string) ([]string, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err != nil || channel != "stable-4.16" || warning == "" {
+		t.Errorf("ResolveMirrorChannel() offline = %q, %q, %v", channel, warning, err)
+	}
+}
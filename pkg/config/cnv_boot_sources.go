@@ -0,0 +1,40 @@
+package config
+
+// BootSource OpenShift Virtualization 的一个默认启动源：DataImportCron 定期从容器镜像导入虚拟机磁盘，
+// 并更新同名的 DataSource 供虚拟机模板引用
+type BootSource struct {
+	Name       string // DataImportCron 名称，与 HyperConverged 内置的模板同名时覆盖内置模板
+	DataSource string // DataImportCron 管理的 DataSource 名称
+	Image      string // 上游容器磁盘镜像
+}
+
+// cnvBootSources HyperConverged 默认启用的启动源
+var cnvBootSources = []BootSource{
+	{Name: "rhel8-image-cron", DataSource: "rhel8", Image: "registry.redhat.io/rhel8/rhel-guest-image:latest"},
+	{Name: "rhel9-image-cron", DataSource: "rhel9", Image: "registry.redhat.io/rhel9/rhel-guest-image:latest"},
+	{Name: "centos-stream9-image-cron", DataSource: "centos-stream9", Image: "quay.io/containerdisks/centos-stream:9"},
+	{Name: "fedora-image-cron", DataSource: "fedora", Image: "quay.io/containerdisks/fedora:latest"},
+}
+
+// GetCNVBootSources 返回 save_image.cnv_boot_sources = true 时镜像的 OpenShift Virtualization 启动源
+func (c *ClusterConfig) GetCNVBootSources() []BootSource {
+	if !c.SaveImage.CNVBootSources {
+		return nil
+	}
+	return append([]BootSource(nil), cnvBootSources...)
+}
+
+// GetCNVBootSourceImages 返回启动源的容器磁盘镜像
+func (c *ClusterConfig) GetCNVBootSourceImages() []string {
+	var images []string
+	for _, source := range c.GetCNVBootSources() {
+		images = append(images, source.Image)
+	}
+	return images
+}
+
+// KubeVirtContainerEnabled 返回是否从 release payload 中提取 KubeVirt 启动源镜像。
+// 启用 cnv_boot_sources 时一并启用，使 HyperConverged 的 RHCOS 启动源在离线环境中可用
+func (c *ClusterConfig) KubeVirtContainerEnabled() bool {
+	return c.SaveImage.KubeVirtContainer || c.SaveImage.CNVBootSources
+}
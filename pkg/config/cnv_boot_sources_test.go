@@ -0,0 +1,36 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetCNVBootSources(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if got := cfg.GetCNVBootSources(); got != nil {
+		t.Errorf("GetCNVBootSources() = %v, want nil when disabled", got)
+	}
+	if cfg.KubeVirtContainerEnabled() {
+		t.Error("KubeVirtContainerEnabled() = true, want false by default")
+	}
+
+	cfg.SaveImage.CNVBootSources = true
+	sources := cfg.GetCNVBootSources()
+	if len(sources) != 4 || sources[1].DataSource != "rhel9" {
+		t.Errorf("GetCNVBootSources() = %+v", sources)
+	}
+	if !cfg.KubeVirtContainerEnabled() {
+		t.Error("KubeVirtContainerEnabled() = false, want true with cnv_boot_sources")
+	}
+
+	cfg.SaveImage.AdditionalImages = []string{"quay.io/containerdisks/fedora:latest"}
+	want := []string{
+		"quay.io/containerdisks/fedora:latest",
+		"registry.redhat.io/rhel8/rhel-guest-image:latest",
+		"registry.redhat.io/rhel9/rhel-guest-image:latest",
+		"quay.io/containerdisks/centos-stream:9",
+	}
+	if got := cfg.GetAdditionalImages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAdditionalImages() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// 污点的效果
+const (
+	TaintNoSchedule       = "NoSchedule"
+	TaintPreferNoSchedule = "PreferNoSchedule"
+	TaintNoExecute        = "NoExecute"
+)
+
+var (
+	// poolNamePattern 计算节点池名称，用作 MachineConfigPool 名称和 node-role.kubernetes.io/<名称> 标签
+	poolNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// labelKeyPattern Kubernetes 标签和污点的键，可带 DNS 前缀，如 example.com/zone
+	labelKeyPattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	// labelValuePattern Kubernetes 标签和污点的值，可以为空
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+)
+
+// ComputePool 计算节点池，对应 [[cluster.compute_pool]]。worker 节点通过 pool 字段加入节点池，
+// 安装时为每个节点池生成 MachineConfigPool，安装完成后为其节点设置 node-role.kubernetes.io/<name> 标签、
+// labels 和 taints，用于 infra 节点等在安装时就确定的节点角色
+type ComputePool struct {
+	Name         string            `toml:"name"`                   // 节点池名称，如 infra
+	Replicas     int               `toml:"replicas,omitempty"`     // 可选，节点数量，必须与 pool = name 的 worker 节点数一致
+	Architecture string            `toml:"architecture,omitempty"` // 可选，节点架构，默认 amd64
+	Labels       map[string]string `toml:"labels,omitempty"`       // 可选，额外的节点标签
+	Taints       []Taint           `toml:"taints,omitempty"`       // 可选，节点污点
+}
+
+// Taint 节点污点
+type Taint struct {
+	Key    string `toml:"key"`
+	Value  string `toml:"value,omitempty"`
+	Effect string `toml:"effect"` // NoSchedule、PreferNoSchedule 或 NoExecute
+}
+
+// String 返回 oc adm taint 使用的格式，如 node-role.kubernetes.io/infra=reserved:NoSchedule
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
+}
+
+// RoleLabel 返回节点池的角色标签，MachineConfigPool 通过它选择节点
+func (p ComputePool) RoleLabel() string {
+	return "node-role.kubernetes.io/" + p.Name
+}
+
+// NodeLabels 返回节点池为节点设置的全部标签，按键排序，角色标签在前
+func (p ComputePool) NodeLabels() []string {
+	labels := []string{p.RoleLabel() + "="}
+	for _, key := range slices.Sorted(maps.Keys(p.Labels)) {
+		labels = append(labels, key+"="+p.Labels[key])
+	}
+	return labels
+}
+
+// PoolNodes 返回加入节点池 name 的 worker 节点
+func (c *ClusterConfig) PoolNodes(name string) []Node {
+	var nodes []Node
+	for _, node := range c.Cluster.Worker {
+		if node.Pool == name {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// ComputeArchitecture 返回 install-config.yaml compute 使用的架构。安装程序只支持一个名为 worker 的
+// compute 节点池，所有 worker 节点 (包括各节点池) 的架构必须相同 (见 ValidateComputePools)
+func (c *ClusterConfig) ComputeArchitecture() string {
+	for _, pool := range c.Cluster.ComputePools {
+		if pool.Architecture != "" {
+			return pool.Architecture
+		}
+	}
+	return ArchAMD64
+}
+
+// ValidateComputePools 验证 [[cluster.compute_pool]] 和 worker 节点的 pool 字段，返回全部问题
+func ValidateComputePools(config *ClusterConfig) error {
+	var errs []error
+	pools := make(map[string]bool)
+	architectures := make(map[string]bool)
+	for i, pool := range config.Cluster.ComputePools {
+		label := fmt.Sprintf("cluster.compute_pool[%d] %s", i, pool.Name)
+		switch {
+		case !poolNamePattern.MatchString(pool.Name) || len(pool.Name) > 63:
+			errs = append(errs, i18n.Errorf("%s 的名称无效，只能包含小写字母、数字和 -", label))
+		case pool.Name == "worker" || pool.Name == "master":
+			errs = append(errs, i18n.Errorf("%s 的名称不能为 worker 或 master，不属于任何节点池的 worker 节点即为 worker 节点池", label))
+		case pools[pool.Name]:
+			errs = append(errs, i18n.Errorf("%s 的名称重复", label))
+		}
+		pools[pool.Name] = true
+
+		nodes := len(config.PoolNodes(pool.Name))
+		if nodes == 0 {
+			errs = append(errs, i18n.Errorf("%s 中没有节点，请在 [[cluster.worker]] 中设置 pool = %q", label, pool.Name))
+		}
+		if pool.Replicas < 0 || (pool.Replicas > 0 && pool.Replicas != nodes) {
+			errs = append(errs, i18n.Errorf("%s 的 replicas = %d，但有 %d 个 worker 节点设置了 pool = %q", label, pool.Replicas, nodes, pool.Name))
+		}
+
+		arch := pool.Architecture
+		if arch == "" {
+			arch = ArchAMD64
+		}
+		if _, ok := releaseTagSuffixes[arch]; !ok || arch == ArchMulti {
+			errs = append(errs, i18n.Errorf("%s 的架构 %q 无效，可选 %s", label, pool.Architecture,
+				strings.Join([]string{ArchAMD64, ArchARM64, ArchPPC64LE, ArchS390X}, i18n.T("、"))))
+		} else if arch != ArchAMD64 && !slices.Contains(config.SaveImage.Architectures, arch) {
+			errs = append(errs, i18n.Errorf("%s 的架构 %s 不在 save_image.architectures 中，私有仓库中没有该架构的镜像", label, arch))
+		}
+		architectures[arch] = true
+
+		for key, value := range pool.Labels {
+			if !labelKeyPattern.MatchString(key) || !labelValuePattern.MatchString(value) || len(value) > 63 {
+				errs = append(errs, i18n.Errorf("%s 的标签 %s=%s 无效", label, key, value))
+			}
+		}
+		for _, taint := range pool.Taints {
+			if !labelKeyPattern.MatchString(taint.Key) || !labelValuePattern.MatchString(taint.Value) || len(taint.Value) > 63 {
+				errs = append(errs, i18n.Errorf("%s 的污点 %s 无效", label, taint))
+			}
+			if !slices.Contains([]string{TaintNoSchedule, TaintPreferNoSchedule, TaintNoExecute}, taint.Effect) {
+				errs = append(errs, i18n.Errorf("%s 的污点 %s 的 effect 无效，可选 %s、%s、%s", label, taint, TaintNoSchedule, TaintPreferNoSchedule, TaintNoExecute))
+			}
+		}
+	}
+
+	for i, node := range config.Cluster.ControlPlane {
+		if node.Pool != "" {
+			errs = append(errs, i18n.Errorf("control Plane节点[%d] %s 不能设置 pool，节点池只适用于 worker 节点", i, node.Name))
+		}
+	}
+	for i, node := range config.Cluster.Worker {
+		if node.Pool == "" {
+			architectures[ArchAMD64] = true
+		} else if !pools[node.Pool] {
+			errs = append(errs, i18n.Errorf("worker节点[%d] %s 的 pool %q 未在 [[cluster.compute_pool]] 中定义", i, node.Name, node.Pool))
+		}
+	}
+	if len(architectures) > 1 {
+		errs = append(errs, i18n.Errorf("install-config.yaml 只有一个 compute 节点池，所有 worker 节点的架构必须相同 (当前: %s)",
+			strings.Join(slices.Sorted(maps.Keys(architectures)), i18n.T("、"))))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateComputePools(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Cluster.Worker[0].Pool = "infra"
+	cfg.Cluster.ComputePools = []ComputePool{{
+		Name:     "infra",
+		Replicas: 1,
+		Labels:   map[string]string{"example.com/zone": "dmz", "tier": "infra"},
+		Taints:   []Taint{{Key: "node-role.kubernetes.io/infra", Value: "reserved", Effect: TaintNoSchedule}},
+	}}
+	if err := ValidateComputePools(cfg); err != nil {
+		t.Fatalf("ValidateComputePools() error = %v", err)
+	}
+	pool := cfg.Cluster.ComputePools[0]
+	if got := strings.Join(pool.NodeLabels(), " "); got != "node-role.kubernetes.io/infra= example.com/zone=dmz tier=infra" {
+		t.Errorf("NodeLabels() = %q", got)
+	}
+	if got := pool.Taints[0].String(); got != "node-role.kubernetes.io/infra=reserved:NoSchedule" {
+		t.Errorf("Taint.String() = %q", got)
+	}
+
+	// every problem is reported at once
+	cfg.Cluster.ComputePools = append(cfg.Cluster.ComputePools, ComputePool{
+		Name:         "worker",
+		Architecture: ArchARM64,
+		Labels:       map[string]string{"bad key": "x"},
+		Taints:       []Taint{{Key: "dedicated", Effect: "NoWay"}},
+	})
+	cfg.Cluster.ComputePools[0].Replicas = 3
+	cfg.Cluster.Worker[1].Pool = "gpu"
+	cfg.Cluster.ControlPlane[0].Pool = "infra"
+	err := ValidateComputePools(cfg)
+	if err == nil {
+		t.Fatal("ValidateComputePools() should fail")
+	}
+	for _, want := range []string{
+		"replicas = 3",
+		"不能为 worker 或 master",
+		"compute_pool[1] worker 中没有节点",
+		"arm64 不在 save_image.architectures 中",
+		"标签 bad key=x 无效",
+		"effect 无效",
+		"不能设置 pool",
+		`pool "gpu" 未在`,
+		"架构必须相同",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q:\n%v", want, err)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/i18n"
 	"ocpack/pkg/utils"
 
 	"github.com/pelletier/go-toml/v2"
@@ -11,19 +16,32 @@ import (
 
 // ClusterConfig 表示集群配置
 type ClusterConfig struct {
+	// 配置文件格式版本，用于自动升级旧的配置文件
+	ConfigVersion int `toml:"config_version"`
+
 	// 集群基本信息
 	ClusterInfo struct {
 		ClusterID        string `toml:"cluster_id"` // 集群ID，用于构建域名和标识
 		Domain           string `toml:"domain"`
 		OpenShiftVersion string `toml:"openshift_version"`
+		Channel          string `toml:"channel,omitempty"` // 升级通道: stable/fast/candidate/eus 或完整通道名称，默认 stable
 	} `toml:"cluster_info"`
 
 	// Bastion 节点配置
 	Bastion struct {
+		Enabled    *bool  `toml:"enabled,omitempty"` // 为 false 时不部署 Bastion，使用 [infra] 中站点已有的 DNS 和负载均衡
 		IP         string `toml:"ip"`
 		Username   string `toml:"username"`
 		SSHKeyPath string `toml:"ssh_key_path"`
 		Password   string `toml:"password"`
+
+		// 每个集群保留的历史 PXE 文件版本数，默认为 DefaultKeepBootArtifacts
+		KeepBootArtifacts int `toml:"keep_boot_artifacts,omitempty"`
+
+		// 可选，Bastion 上 named 的上游转发、按域名转发、额外记录和反向解析区域
+		DNS BastionDNS `toml:"dns,omitempty"`
+		// 可选，HAProxy 统计页面的端口和认证，以及 Ingress 前端端口
+		HAProxy BastionHAProxy `toml:"haproxy,omitempty"`
 	} `toml:"bastion"`
 
 	// Registry 节点配置
@@ -34,44 +52,149 @@ type ClusterConfig struct {
 		Password     string `toml:"password"`
 		StoragePath  string `toml:"storage_path"`
 		RegistryUser string `toml:"registry_user"`
+		// Registry 密码，未配置时使用 DefaultRegistryPassword
+		RegistryPassword string `toml:"registry_password,omitempty"`
+		// 额外镜像仓库的认证信息，与 pull-secret 一起合并到 merged-auth.json
+		Auths []RegistryAuth `toml:"auths,omitempty"`
+		// 可选，镜像模式: mirror (默认，保存并推送镜像) 或 proxy-cache (部署拉取代理，跳过 save-image 和 load-image)
+		MirrorMode string `toml:"mirror_mode,omitempty"`
+		// 可选，proxy-cache 模式代理的上游仓库，未配置时代理 quay.io 和 registry.redhat.io
+		ProxyCache []ProxyCacheUpstream `toml:"proxy_cache,omitempty"`
+		// 可选，proxy-cache 模式使用的镜像，默认 DefaultProxyCacheImage
+		ProxyCacheImage string `toml:"proxy_cache_image,omitempty"`
+		// 可选，load-image 前在 Quay 中创建组织和仓库、设置可见性和配额
+		Quay RegistryQuay `toml:"quay,omitempty"`
+		// 可选，部署前准备镜像存储: 格式化并挂载专用磁盘、设置配额和检查可用空间
+		Storage RegistryStorage `toml:"storage,omitempty"`
+		// 可选，ocpack 访问私有仓库和拉取代理时不校验 TLS 证书，只用于证书无法校验的测试环境
+		SkipTLSVerify bool `toml:"skip_tls_verify,omitempty"`
 	} `toml:"registry"`
 
 	// 集群节点配置
 	Cluster struct {
 		// Control Plane 节点
-		ControlPlane []struct {
-			Name string `toml:"name"`
-			IP   string `toml:"ip"`
-			MAC  string `toml:"mac"`
-		} `toml:"control_plane"`
+		ControlPlane []Node `toml:"control_plane"`
 
 		// Worker 节点
-		Worker []struct {
-			Name string `toml:"name"`
-			IP   string `toml:"ip"`
-			MAC  string `toml:"mac"`
-		} `toml:"worker"`
+		Worker []Node `toml:"worker"`
+
+		// 可选，计算节点池，如带污点的 infra 节点
+		ComputePools []ComputePool `toml:"compute_pool,omitempty"`
 
 		// 网络配置
 		Network struct {
-			ClusterNetwork string `toml:"cluster_network"`
-			ServiceNetwork string `toml:"service_network"`
-			MachineNetwork string `toml:"machine_network"`
+			ClusterNetwork string   `toml:"cluster_network"`
+			ServiceNetwork string   `toml:"service_network"`
+			MachineNetwork string   `toml:"machine_network"`
+			HostPrefix     int      `toml:"host_prefix,omitempty"` // 每个节点分配的 Pod 子网前缀长度，默认 DefaultHostPrefix
+			NTPServers     []string `toml:"ntp_servers"`           // 额外的 NTP 服务器，渲染到 agent-config.yaml 的 additionalNTPSources
 		} `toml:"network"`
 	} `toml:"cluster"`
 
+	// 站点已有的基础设施服务 (DNS、负载均衡、PXE 资源服务器)
+	Infra Infra `toml:"infra,omitempty"`
+
+	// install-config.yaml 的特性集和可选集群组件
+	InstallConfig InstallConfig `toml:"install_config,omitempty"`
+
 	// 下载配置
 	Download struct {
 		LocalPath string `toml:"local_path"`
+		Shared    bool   `toml:"shared,omitempty"` // 可选，多个集群共用项目级下载目录
+		// 可选，客户端和安装程序等 tar.gz 边下载边提取，只保留提取出的工具，不在磁盘上保存归档
+		Stream bool `toml:"stream,omitempty"`
+		// 可选，stream 时仍将归档保存到下载目录，供传输到其他环境
+		KeepArchives bool `toml:"keep_archives,omitempty"`
 	} `toml:"download"`
 
 	// 镜像保存配置
 	SaveImage struct {
-		IncludeOperators bool     `toml:"include_operators"`
-		OperatorCatalog  string   `toml:"operator_catalog,omitempty"` // 可选，如果为空则自动基于版本生成
-		Ops              []string `toml:"ops"`
-		AdditionalImages []string `toml:"additional_images"`
+		IncludeOperators  bool     `toml:"include_operators"`
+		OperatorCatalog   string   `toml:"operator_catalog,omitempty"` // 可选，如果为空则自动基于版本生成
+		Ops               []string `toml:"ops"`
+		AdditionalImages  []string `toml:"additional_images"`
+		Graph             bool     `toml:"graph"`              // 是否构建 Cincinnati graph-data 镜像，用于离线 OSUS 升级推荐
+		KubeVirtContainer bool     `toml:"kubevirt_container"` // 是否从 release payload 中提取 KubeVirt (CNV) 启动源镜像
+
+		// 可选，为 true 时自动镜像与 openshift_version 对应的 must-gather、support-tools 和 tools 排障镜像，
+		// 使 oc adm must-gather、toolbox 和 oc debug node 在离线环境中可用
+		SupportImages bool `toml:"support_images,omitempty"`
+
+		// 可选，为 true 时镜像 OpenShift Virtualization 默认启动源 (RHEL、CentOS Stream、Fedora) 的容器磁盘镜像，
+		// 并启用 kubevirt_container，由 day2 cnv-boot-sources 将 DataImportCron 指向私有仓库
+		CNVBootSources bool `toml:"cnv_boot_sources,omitempty"`
+
+		// 可选，预置的常用 Day2 组件，如 ["gitops"]、["acm"]。save-image 镜像组件需要的 Operator (不需要 include_operators)，
+		// day2 presets 在集群中创建指向私有仓库目录的 Namespace、OperatorGroup 和 Subscription
+		Presets []string `toml:"presets,omitempty"`
+
+		// 可选，镜像的 release 版本范围，默认与 openshift_version 相同。
+		// 范围不同时按 Cincinnati 最短升级路径镜像其间的全部 release，用于离线环境分阶段升级
+		OpenShiftVersionMin string `toml:"openshift_version_min,omitempty"`
+		OpenShiftVersionMax string `toml:"openshift_version_max,omitempty"`
+
+		// 可选，隔离网络 (enclave) 中可访问的 Cincinnati 兼容升级图地址，如上级网络中的 OSUS。
+		// 设置后 save-image 通过 UPDATE_URL_OVERRIDE 使用该地址，day2 update-service 将其设为集群的升级源
+		UpdateURLOverride string `toml:"update_url_override,omitempty"`
+
+		// 可选，升级图 (Cincinnati graph) 查询结果的缓存时间，如 "6h"，默认 1h，"0" 表示每次都查询。
+		// 查询结果保存在 oc-mirror 工作目录中，缓存未过期时 plan 和 save-image 直接复用
+		GraphCacheTTL string `toml:"graph_cache_ttl,omitempty"`
+
+		// 可选，Operator 目录摘要的缓存时间，如 "6h"，默认 24h，"0" 表示每次都查询源仓库。
+		// 缓存未过期且目录已在 oc-mirror 工作目录中时 save-image 直接复用，--refresh-catalog 强制重新查询
+		CatalogCacheTTL string `toml:"catalog_cache_ttl,omitempty"`
+
+		// 可选，私有仓库中存放全部镜像的命名空间前缀，如 redhat-mirror，用于满足仓库的路径规范
+		TargetNamespace string `toml:"target_namespace,omitempty"`
+
+		// 可选，多个 Operator 目录 (如 certified、community)，配置后替代 operator_catalog 和 ops
+		OperatorCatalogs []OperatorCatalog `toml:"operator_catalogs,omitempty"`
+
+		// 可选，为 true 时 save-image 将 mirror-registry 离线安装包归档到镜像目录，
+		// 随镜像一起保存，deploy-registry 在下载目录中没有安装包时使用该副本重建 Registry
+		MirrorRegistry bool `toml:"mirror_registry,omitempty"`
+
+		// 可选，oc-mirror 本地缓存 registry 监听的端口。未配置时使用 55000，被占用时自动选择其后的空闲端口；
+		// 配置后 (或使用 --port) 端口被占用时直接报错，避免同一主机上多个镜像任务互相冲突
+		LocalStoragePort int `toml:"local_storage_port,omitempty"`
+
+		// 可选，oc-mirror 本地缓存的大小上限，如 "200Gi"。save-image 和 load-image 成功后按 blob 的最近访问时间
+		// 删除最久未使用的镜像数据，使缓存不超过该大小；未配置时不自动清理
+		MaxCacheSize string `toml:"max_cache_size,omitempty"`
+
+		// 可选，镜像分组 (release、operators、additional、helm) 的复制顺序，如 ["release", "operators"]。
+		// 配置后前一组全部复制完成才开始下一组，传输窗口有限时保证先完成安装集群所需的 release
+		MirrorOrder []string `toml:"mirror_order,omitempty"`
+
+		// 可选，集群节点的架构，如 ["amd64", "arm64"]，默认为 ["amd64"]。配置多种架构时镜像 multi release payload，
+		// 私有仓库中的 release 和组件镜像为包含全部架构的清单列表，x86 控制平面和 arm 计算节点都可以使用
+		Architectures []string `toml:"architectures,omitempty"`
+
+		// 可选，镜像归档的存储位置，如 NFS 挂载点或 S3 兼容的对象存储
+		Storage ImageStorage `toml:"storage,omitempty"`
+
+		// 可选，单个镜像复制失败时的重试策略
+		Retry ImageRetry `toml:"retry,omitempty"`
+
+		// 可选，传输清单的签名方式，load-image 导入前验证
+		Signing TransferSigning `toml:"signing,omitempty"`
+
+		// 可选，复制镜像时单个仓库的 TLS 配置 (额外信任的 CA 或不校验证书)，未列出的仓库使用系统信任的 CA 校验证书
+		RegistryTLS []RegistryTLS `toml:"registry_tls,omitempty"`
 	} `toml:"save_image"`
+
+	// 镜像漏洞扫描配置
+	Scan Scan `toml:"scan,omitempty"`
+
+	// 离线 RPM 仓库配置
+	Rpms Rpms `toml:"rpms,omitempty"`
+
+	// 阶段钩子，键为 pre_<阶段> 或 post_<阶段>，值为在集群目录中执行的命令列表
+	Hooks map[string][]string `toml:"hooks,omitempty"`
+
+	// 阶段结束时的通知 (webhook 或邮件)
+	Notify Notify `toml:"notify,omitempty"`
 }
 
 // GetOperatorCatalog 获取 Operator 目录镜像地址
@@ -81,8 +204,7 @@ func (c *ClusterConfig) GetOperatorCatalog() string {
 	if c.SaveImage.OperatorCatalog != "" {
 		return c.SaveImage.OperatorCatalog
 	}
-	majorVersion := utils.ExtractMajorVersion(c.ClusterInfo.OpenShiftVersion)
-	return fmt.Sprintf("registry.redhat.io/redhat/redhat-operator-index:v%s", majorVersion)
+	return c.versionedCatalog(wellKnownCatalogs["redhat"])
 }
 
 // NewDefaultConfig 创建默认配置
@@ -90,9 +212,11 @@ func NewDefaultConfig(clusterName string) *ClusterConfig {
 	config := &ClusterConfig{}
 
 	// 设置默认值
+	config.ConfigVersion = CurrentConfigVersion
 	config.ClusterInfo.ClusterID = clusterName
 	config.ClusterInfo.Domain = "example.com"
 	config.ClusterInfo.OpenShiftVersion = "4.14.0"
+	config.ClusterInfo.Channel = DefaultChannel
 
 	config.Bastion.Username = "root"
 
@@ -101,21 +225,13 @@ func NewDefaultConfig(clusterName string) *ClusterConfig {
 	config.Registry.RegistryUser = "ocp4"
 
 	// 设置集群节点默认值
-	config.Cluster.ControlPlane = []struct {
-		Name string `toml:"name"`
-		IP   string `toml:"ip"`
-		MAC  string `toml:"mac"`
-	}{
+	config.Cluster.ControlPlane = []Node{
 		{Name: "master-0", IP: "", MAC: ""},
 		{Name: "master-1", IP: "", MAC: ""},
 		{Name: "master-2", IP: "", MAC: ""},
 	}
 
-	config.Cluster.Worker = []struct {
-		Name string `toml:"name"`
-		IP   string `toml:"ip"`
-		MAC  string `toml:"mac"`
-	}{
+	config.Cluster.Worker = []Node{
 		{Name: "worker-0", IP: "", MAC: ""},
 		{Name: "worker-1", IP: "", MAC: ""},
 	}
@@ -150,16 +266,43 @@ func GenerateDefaultConfig(filePath string, clusterName string) error {
 	configContent := fmt.Sprintf(`# OpenShift 集群配置文件
 # 请根据实际环境修改以下配置项
 
+config_version = %d             # 配置文件格式版本，请勿手动修改
+
 [cluster_info]
 cluster_id = "%s"              # 集群ID，用于构建域名 (如 api.cluster_id.domain)
 domain = "%s"                  # 集群域名
 openshift_version = "%s"       # OpenShift 版本
+channel = "%s"                 # 升级通道: stable、fast、candidate、eus (仅偶数次版本)，版本尚未进入该通道时 save-image 自动改用 fast/candidate；填写完整通道名称如 eus-4.14 时不自动切换
 
 [bastion]
+# enabled = false              # 站点已有 DNS 和负载均衡时设置为 false，跳过 Bastion 部署并配置下方的 [infra]
 ip = ""                        # Bastion 节点 IP (必填)
 username = "%s"                # SSH 用户名
 ssh_key_path = ""              # SSH 私钥路径 (可选，与 password 二选一)
 password = ""                  # SSH 密码 (可选，与 ssh_key_path 二选一)
+# keep_boot_artifacts = 2      # setup-pxe 重新上传时保留的历史 PXE 文件版本数，超出部分自动清理
+
+# Bastion 上 named 的自定义配置 (可选)
+# [bastion.dns]
+# mode = "hosts"               # 站点 DNS 无法将集群域委派给 Bastion 时使用，dnsmasq 根据生成的 hosts 文件应答集群域的查询
+# forwarders = ["10.0.0.53"]   # 上游 DNS 服务器，集群域以外的查询转发到这些服务器
+# reverse_zone = false         # 站点 DNS 已负责机器网络的反向解析时不生成反向解析区域
+# dnssec_validation = false    # 转发到未签名的内部域时关闭 DNSSEC 校验
+# conditional_forwarders = [   # 按域名转发
+#   { zone = "idm.example.com", servers = ["10.0.0.10", "10.0.0.11"] },
+# ]
+# records = [                  # 集群域中的额外记录，名称相对于 <cluster_id>.<domain>
+#   { name = "ntp", type = "A", value = "192.168.1.5" },
+#   { name = "proxy", type = "CNAME", value = "squid.example.com." },
+# ]
+
+# Bastion 上 HAProxy 的配置 (可选)
+# [bastion.haproxy]
+# stats_port = 9000            # 统计页面端口
+# stats_user = "admin"         # 设置后访问统计页面需要认证
+# stats_password = ""
+# http_port = 80               # Ingress HTTP 前端端口
+# https_port = 443             # Ingress HTTPS 前端端口，443 已被占用时可改为 8443 (API 6443 和 22623 不能修改)
 
 [registry]
 ip = ""                        # Registry 节点 IP (必填)
@@ -168,12 +311,57 @@ ssh_key_path = ""              # SSH 私钥路径 (可选，与 password 二选
 password = ""                  # SSH 密码 (可选，与 ssh_key_path 二选一)
 storage_path = "%s"            # 镜像存储路径
 registry_user = "%s"           # Registry 用户名
+registry_password = ""         # Registry 密码 (可选，默认 %s，需在部署 Registry 前设置)
+# mirror_mode = "proxy-cache"  # 可选，半联网环境部署拉取代理缓存，不再需要 save-image 和 load-image
+# proxy_cache_image = "docker.io/library/registry:2"  # 可选，拉取代理使用的镜像
+# skip_tls_verify = true       # 可选，就绪检查等访问私有仓库和拉取代理时不校验证书 (只用于测试环境)
+
+# 额外镜像仓库的认证信息 (可选)，会与 pull-secret 一起合并到 registry/merged-auth.json
+# [[registry.auths]]
+# host = "quay.example.com:8443"  # 仓库地址
+# username = "robot"              # 用户名
+# password = ""                   # 密码，与 token 二选一
+# token = ""                      # 访问令牌，与 password 二选一
+
+# proxy-cache 模式代理的上游仓库 (可选，默认 quay.io:5001 和 registry.redhat.io:5002)，上游认证从 pull-secret 读取
+# [[registry.proxy_cache]]
+# source = "quay.io"              # 上游仓库地址
+# port = 5001                     # Registry 节点上代理该上游的端口
+
+# load-image 前通过 Quay API 创建目标组织和仓库 (可选)，避免推送到不存在或超出配额的组织时中途返回 403
+# [registry.quay]
+# manage_organizations = true
+# api_token = ""                  # Quay OAuth 访问令牌，为空时使用 registry_user 和 registry_password
+# visibility = "private"          # 仓库可见性: private 或 public
+# quota = "500Gi"                 # 每个组织的存储配额 (需要启用 FEATURE_QUOTA_MANAGEMENT)
+# [[registry.quay.organizations]]
+# name = "apps"                   # 额外的组织，或覆盖自动识别的组织的 visibility 和 quota
+# repositories = ["team/api"]     # 预先创建的仓库
+
+# Registry 节点的镜像存储 (可选)，deploy-registry 部署前检查 storage_path 的可用空间是否足够存放镜像归档
+# [registry.storage]
+# device = "/dev/sdb"             # 专用磁盘，没有文件系统时格式化并挂载到 storage_path (已有文件系统时不重新格式化)
+# filesystem = "xfs"              # device 上创建的文件系统: xfs 或 ext4
+# quota = "800Gi"                 # storage_path 的 XFS 项目配额 (需要 device 且 filesystem = "xfs")
+# min_free = "%s"               # storage_path 至少需要的可用空间
 
 # Control Plane 节点配置
 [[cluster.control_plane]]
 name = "master-0"
-ip = ""                        # 节点 IP (必填)
+ip = ""                        # 节点 IP (必填，dhcp = true 时可选)
 mac = ""                       # 节点 MAC 地址 (必填)
+# dhcp = true                  # 可选，通过 DHCP 获取地址，agent-config 中不生成静态网络配置；ip 可留空或填写
+#                              # DHCP 保留地址 (用于 Bastion DNS 和 HAProxy)，rendezvous 节点必须使用静态 IP
+# bmc_address = ""             # 可选，BMC 地址，以下资产信息用于 ocpack inventory 导出
+# serial = ""                  # 可选，服务器序列号
+# location = ""                # 可选，机房位置，如 "DC1/R05/U12"
+# console_url = ""             # 可选，远程控制台地址
+# cpu = 8                      # 可选，vCPU 数量，以下规格用于检查是否满足 OpenShift 最低要求
+# memory_gb = 16               # 可选，内存 (GB)
+# disk_gb = 120                # 可选，安装磁盘容量 (GB)
+# root_device = ""            # 可选，安装磁盘 (rootDeviceHints.deviceName)，如 "/dev/disk/by-path/..."
+# kernel_args = ["nomodeset"]  # 可选，启动 agent 时追加的内核参数，以下两项不同的节点使用单独的 ISO
+# console = "ttyS0,115200n8"   # 可选，启动 agent 时使用的控制台 (串口服务器)
 
 [[cluster.control_plane]]
 name = "master-1"
@@ -188,21 +376,70 @@ mac = ""
 # Worker 节点配置
 [[cluster.worker]]
 name = "worker-0"
-ip = ""                        # 节点 IP (必填)
+ip = ""                        # 节点 IP (必填，dhcp = true 时可选)
 mac = ""                       # 节点 MAC 地址 (必填)
+# dhcp = true                  # 可选，通过 DHCP 获取地址，agent-config 中不生成静态网络配置；ip 可留空或填写
+#                              # DHCP 保留地址 (用于 Bastion DNS 和 HAProxy)，rendezvous 节点必须使用静态 IP
 
 [[cluster.worker]]
 name = "worker-1"
 ip = ""
 mac = ""
+# pool = "infra"               # 可选，加入 [[cluster.compute_pool]] 定义的计算节点池
+
+# 计算节点池 (可选)，如只运行 router、registry 和监控的 infra 节点。安装时生成 MachineConfigPool，
+# ocpack mon 在安装完成后为池中节点设置角色标签、labels 和 taints
+# [[cluster.compute_pool]]
+# name = "infra"
+# replicas = 2                 # 可选，必须与 pool = "infra" 的 worker 节点数一致
+# architecture = "amd64"       # 可选，所有 worker 节点的架构必须相同
+# labels = { "example.com/zone" = "dmz" }
+# taints = [{ key = "node-role.kubernetes.io/infra", value = "reserved", effect = "NoSchedule" }]
 
 [cluster.network]
-cluster_network = "%s"         # 集群网络 CIDR
+cluster_network = "%s"         # 集群网络 (Pod) CIDR
 service_network = "%s"         # 服务网络 CIDR
-machine_network = "%s"         # 机器网络 CIDR
+machine_network = "%s"         # 机器网络 CIDR，三个网络不能重叠
+host_prefix = %d                # 每个节点分配的 Pod 子网前缀长度
+ntp_servers = []               # 节点使用的 NTP 服务器 (强烈建议配置，离线环境时钟偏差会导致安装失败)
+
+# 站点已有的基础设施服务 (可选，bastion.enabled = false 时 dns_servers、load_balancer 和 rendezvous_ip/bootstrap_node 必填)
+# [infra]
+# dns_servers = ["192.168.1.2"]      # 节点使用的 DNS 服务器，需提供 api、api-int、*.apps 和 registry 的解析，默认为 Bastion IP
+# load_balancer = "192.168.1.3"      # API (6443、22623) 和 Ingress (80、443) 负载均衡地址，默认为 Bastion 上的 HAProxy
+# rendezvous_ip = "192.168.1.10"     # agent 安装的 rendezvous 节点 IP，必须是某个 Control Plane 节点，默认为第一个
+# bootstrap_node = "master-2"       # 或按名称指定 rendezvous (bootstrap) 节点，必须是某个 Control Plane 节点
+# pxe_asset_url = "http://192.168.1.4:8080/pxe/demo"  # PXE 启动文件的 HTTP 地址，默认为 Bastion 上的 PXE 服务
+# trust_bundle_paths = ["certs/proxy-ca.pem"]  # 额外信任的 CA 证书 (如企业代理)，与私有仓库 CA 合并到 additionalTrustBundle
+# trust_bundle_policy = "Always"     # additionalTrustBundlePolicy: Proxyonly 或 Always，配置了 trust_bundle_paths 时默认为 Always
+# ca_bundle = "certs/proxy-ca.pem"  # ocpack 下载工具、查询升级图和从上游仓库复制镜像时额外信任的 CA (如解密 TLS 的代理)，
+#                                    # 配置后校验上游仓库证书，不加入集群的 additionalTrustBundle
+
+# 站点托管的 DNS (Infoblox、Route53 等)，ocpack sync-dns 创建 api、api-int、*.apps 和 registry 记录 (可选)
+# [infra.external_dns]
+# provider = "webhook"               # webhook、exec 或插件名称 (执行 PATH 中的 ocpack-dns-<名称>，如 ocpack-dns-infoblox)
+# url = "https://dns-api.example.com/ocpack"  # provider = "webhook" 时接收记录的地址
+# token = ""                         # 可选，webhook 的 Bearer token
+# command = "./scripts/dns.sh"       # provider = "exec" 时执行的命令，记录以 JSON 写入标准输入
+# zone = "example.com"               # 可选，记录所在的区域，默认为 cluster_info.domain
+# ttl = 300                          # 可选，记录的 TTL (秒)
+# registry = false                   # 可选，registry 记录已存在时不再创建
+# resolvers = ["10.0.0.53"]          # 可选，检查传播的 DNS 服务器，默认为节点使用的 DNS 服务器
+# propagation_timeout = "10m"        # 可选，sync-dns 等待记录传播的时间
+
+# install-config.yaml 的特性集和可选集群组件 (可选)
+# [install_config]
+# feature_set = "TechPreviewNoUpgrade"   # TechPreviewNoUpgrade、DevPreviewNoUpgrade 或 CustomNoUpgrade，启用后集群无法升级
+# feature_gates = []                     # feature_set = "CustomNoUpgrade" 时的特性开关，如 ["SomeFeature=true"]
+# [install_config.capabilities]          # 资源受限的边缘集群可以不安装 marketplace、openshift-samples 等组件
+# baseline_capability_set = "None"       # None、vCurrent 或 v4.x
+# additional_enabled_capabilities = ["MachineAPI", "Ingress", "NodeTuning"]
 
 [download]
 local_path = "%s"              # 下载文件存储路径
+# shared = true                 # 可选，多个集群共用项目目录下的 shared-downloads/<版本>，避免重复下载
+# stream = true                 # 可选，tar.gz 边下载边提取，不保存归档，节省磁盘空间
+# keep_archives = true          # 可选，stream 时仍保存归档
 
 [save_image]
 include_operators = %t         # 是否包含 Operator 镜像
@@ -213,41 +450,164 @@ ops = [                        # 需要的 Operator 列表
   "%s"
 ]
 additional_images = []         # 额外的镜像列表
+graph = %t                     # 是否构建 Cincinnati graph-data 镜像 (离线 OpenShift Update Service)
+kubevirt_container = %t        # 是否镜像 OpenShift Virtualization (CNV) 的 RHCOS 启动源镜像
+# openshift_version_min = ""   # 可选，镜像的最低 release 版本，默认与 openshift_version 相同
+# openshift_version_max = ""   # 可选，镜像的最高 release 版本，与最低版本不同时镜像两者之间的最短升级路径
+# update_url_override = ""     # 可选，隔离网络中可访问的升级图地址 (如 https://<osus>/api/upgrades_info/graph)，
+#                              # 替代官方 Cincinnati API，并作为 day2 update-service 设置的集群升级源
+# graph_cache_ttl = "1h"       # 可选，升级图查询结果的缓存时间，"0" 表示每次都查询；查询失败时总是回退到已有的缓存
+# catalog_cache_ttl = "24h"    # 可选，Operator 目录的缓存时间，"0" 表示每次都查询；无法访问源仓库时回退到已下载的目录
+# target_namespace = ""        # 可选，私有仓库中存放全部镜像的命名空间，如 "redhat-mirror"
+# mirror_registry = true       # 可选，将 mirror-registry 离线安装包随镜像一起归档，便于离线重建 Registry
+# local_storage_port = 55000   # 可选，oc-mirror 本地缓存 registry 的端口，未配置时自动选择空闲端口
+# max_cache_size = "200Gi"     # 可选，本地缓存的大小上限，镜像成功后自动清理最久未使用的数据
+# mirror_order = ["release", "operators", "additional", "helm"]  # 可选，按组依次复制，前一组完成后才开始下一组
+# support_images = true        # 可选，镜像 must-gather、support-tools 和 tools 等排障镜像，离线环境也能收集诊断数据
+# cnv_boot_sources = true      # 可选，镜像 OpenShift Virtualization 的虚拟机启动源，并启用 kubevirt_container
+# presets = ["gitops"]         # 可选，预置组件 gitops (OpenShift GitOps) 或 acm (Advanced Cluster Management)，
+#                              # 自动镜像所需的 Operator，由 day2 presets 安装
+# architectures = ["amd64", "arm64"]  # 可选，集群节点的架构 (必须包含 amd64)，多种架构时镜像 multi release payload
+
+# 镜像归档的存储位置 (可选)，默认为集群目录下的 images。file:// 直接写入该目录 (如 NFS 挂载点)，
+# s3:// 在 save-image 后上传、load-image 前下载镜像归档 (需要 aws CLI)。s3:// 的归档先完整暂存在
+# 集群目录下的 images 中，本地需要能容纳全部镜像归档的空间 (ocpack doctor 检查剩余空间)
+# [save_image.storage]
+# url = "s3://ocp-mirror/demo"
+# endpoint = "https://minio.example.com:9000"   # S3 兼容存储的地址，为空时使用 AWS S3
+# access_key = ""                              # 为空时使用 aws CLI 的默认凭据
+# secret_key = ""
+
+# 单个镜像复制失败时的重试策略 (可选)，默认最多尝试 3 次，线性退避
+# [save_image.retry]
+# attempts = 5                                 # 每个镜像最多尝试的次数
+# backoff = "exponential"                      # linear 或 exponential
+# delay = "2s"                                 # 第一次重试前的等待时间
+# jitter = true                                # 在等待时间上增加随机抖动，避免并发的重试同时到达仓库
+# non_retryable_errors = ["quota exceeded"]    # 额外的不重试错误 (不区分大小写的子串)
+# retryable_errors = ["not found"]             # 总是重试的错误，优先于不重试的错误
+
+# 传输清单签名 (可选)。save-image 总是生成记录各归档 sha256 的 transfer-manifest.json，
+# 配置签名后 load-image 在导入前同时验证签名，防止归档在摆渡传输中被篡改
+# [save_image.signing]
+# method = "gpg"                               # gpg 或 cosign
+# key = "ocpack@example.com"                   # gpg 密钥 ID 或 cosign 私钥文件
+# public_key = "/etc/ocpack/transfer.pub"      # load-image 验证签名使用的公钥
+
+# 复制镜像时校验仓库的 TLS 证书，信任系统 CA、私有仓库 CA、infra.trust_bundle_paths 和 infra.ca_bundle。
+# 由其他 CA 签发证书的仓库可单独指定 CA，确实无法校验的仓库需要显式标记为 insecure (可选):
+# [[save_image.registry_tls]]
+# registry = "registry.lab.example.com:5000"   # 仓库地址 host[:port]
+# ca_file = "certs/lab-ca.pem"                 # 该仓库的 CA 证书，相对路径相对于集群目录
+# [[save_image.registry_tls]]
+# registry = "legacy.example.com"
+# insecure = true                              # 不校验该仓库的证书
+
+# 需要镜像多个 Operator 目录时，使用 operator_catalogs 替代上面的 operator_catalog 和 ops，
+# 每个目录在 day2 operatorhub 中生成独立的 CatalogSource。catalog 可填写完整镜像地址，
+# 或简称 redhat、certified、community、marketplace (标签根据 openshift_version 自动生成)，
+# 也可以是本地磁盘上的 OCI 格式目录 oci://<路径> (相对路径基于集群目录):
+# [[save_image.operator_catalogs]]
+# catalog = "certified"
+# target_catalog = "certified/operator-index"   # 可选，私有仓库中的目录路径
+# target_tag = "v4.14"                          # 可选，私有仓库中的目录标签
+# catalog_source_name = "certified-operators"   # 可选，默认根据目录名生成
+# display_name = "Certified Operators"          # 可选，OperatorHub 中显示的名称
+# ops = ["gpu-operator-certified"]
+
+# 镜像漏洞扫描 (可选)，启用后 load-image 在推送镜像前先扫描，报告保存在 scan/report.json
+# [scan]
+# enabled = true
+# scanner = "trivy"            # trivy 或 command (自定义命令，需输出 Trivy 格式的 JSON)
+# fail_on = "CRITICAL"         # 存在该级别及以上漏洞时终止 load-image，为空时只生成报告
+
+# 离线 RPM 仓库 (可选)，在联网的同版本 RHEL 主机上执行 mirror-rpms 下载 Bastion/Registry/PXE 所需的软件包，
+# 部署时自动使用下载目录中的 rpms/ 仓库
+# [rpms]
+# release_ver = "9"            # 可选，默认与执行 mirror-rpms 的主机一致
+# repos = []                   # 可选，只从这些仓库下载，如 ["rhel-9-for-x86_64-baseos-rpms", "rhel-9-for-x86_64-appstream-rpms"]
+# extra_packages = []          # 可选，额外下载的软件包
+
+# 阶段钩子 (可选)，在对应命令执行前 (pre_) 或成功后 (post_) 在集群目录中依次执行，
+# 可用阶段: download、mirror_rpms、deploy_bastion、deploy_registry、scan_images、load_image、
+# generate_iso、add_worker、day2_operatorhub、day2_update_service、
+# day2_apply_bundle、day2_cnv_boot_sources、day2_compute_pools、day2_presets。pre_ 钩子失败时阶段不会执行。
+# 钩子可通过 OCPACK_CLUSTER_NAME、OCPACK_CLUSTER_DIR、OCPACK_STAGE 等环境变量获取集群信息
+# [hooks]
+# pre_deploy_registry = ["./scripts/approve.sh"]
+# post_load_image = ["./scripts/notify.sh"]
+
+# 长时间运行的阶段结束或失败时发送通知 (可选)，默认通知 save_image、load_image、generate_iso 和 monitor_install
+# [notify]
+# stages = ["save_image", "load_image", "generate_iso", "monitor_install"]
+# only_failures = false                          # 为 true 时只在失败时通知
+# [[notify.webhooks]]
+# url = "https://hooks.slack.com/services/XXX"
+# format = "slack"                               # generic (阶段结果的 JSON)、slack 或 teams
+# [notify.smtp]
+# host = "smtp.example.com"
+# port = 587
+# username = "ocpack"
+# password = ""
+# from = "ocpack@example.com"
+# to = ["ops@example.com"]
 `,
+		config.ConfigVersion,
 		config.ClusterInfo.ClusterID,
 		config.ClusterInfo.Domain,
 		config.ClusterInfo.OpenShiftVersion,
+		config.ClusterInfo.Channel,
 		config.Bastion.Username,
 		config.Registry.Username,
 		config.Registry.StoragePath,
 		config.Registry.RegistryUser,
+		DefaultRegistryPassword,
+		DefaultRegistryMinFree,
 		config.Cluster.Network.ClusterNetwork,
 		config.Cluster.Network.ServiceNetwork,
 		config.Cluster.Network.MachineNetwork,
+		DefaultHostPrefix,
 		config.Download.LocalPath,
 		config.SaveImage.IncludeOperators,
 		config.SaveImage.Ops[0],
 		config.SaveImage.Ops[1],
+		config.SaveImage.Graph,
+		config.SaveImage.KubeVirtContainer,
 	)
 
 	// 写入文件
 	if err := os.WriteFile(filePath, []byte(configContent), 0644); err != nil {
-		return fmt.Errorf("写入配置文件失败: %w", err)
+		return i18n.Errorf("写入配置文件失败: %w", err)
 	}
 
 	return nil
 }
 
-// LoadConfig 从文件加载配置
+// LoadConfig 从文件加载配置，旧版本配置的提示输出到 stderr (见 LoadConfigTo)
 func LoadConfig(filePath string) (*ClusterConfig, error) {
+	return LoadConfigTo(os.Stderr, filePath)
+}
+
+// LoadConfigTo 从文件加载配置。旧版本的配置文件只在内存中升级到当前版本，不修改文件，
+// 并向 out 输出提示，由用户执行 ocpack migrate-config 升级文件
+func LoadConfigTo(out io.Writer, filePath string) (*ClusterConfig, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		return nil, clierr.New(clierr.Config, i18n.Errorf("读取配置文件失败: %w", err))
+	}
+
+	data, result, err := MigrateConfigData(data)
+	if err != nil {
+		return nil, clierr.New(clierr.Config, i18n.Errorf("升级配置文件失败: %w", err))
+	}
+	if result.Migrated() {
+		i18n.Fprintf(out, "⚠️  配置文件 %s 的格式版本为 %d，已按版本 %d 读取，执行 ocpack migrate-config 升级文件\n",
+			filePath, result.FromVersion, result.ToVersion)
 	}
 
 	config := &ClusterConfig{}
 	if err := toml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		return nil, clierr.New(clierr.Config, i18n.Errorf("解析配置文件失败: %w", err))
 	}
 
 	return config, nil
@@ -257,160 +617,228 @@ func LoadConfig(filePath string) (*ClusterConfig, error) {
 func SaveConfig(config *ClusterConfig, filePath string) error {
 	data, err := toml.Marshal(config)
 	if err != nil {
-		return fmt.Errorf("序列化配置失败: %w", err)
+		return i18n.Errorf("序列化配置失败: %w", err)
 	}
 
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("写入配置文件失败: %w", err)
+		return i18n.Errorf("写入配置文件失败: %w", err)
 	}
 
 	return nil
 }
 
-// ValidateConfig 验证配置是否有效
+// ValidateConfig 验证配置是否有效，返回的错误类别为 clierr.Config
 func ValidateConfig(config *ClusterConfig) error {
+	return clierr.New(clierr.Config, validateConfig(config))
+}
+
+func validateConfig(config *ClusterConfig) error {
+	// 相互独立的问题全部报告，避免修复一个后再执行才发现下一个
+	var errs []error
+
 	// 验证集群基本信息
 	if config.ClusterInfo.ClusterID == "" {
-		return fmt.Errorf("集群ID不能为空")
+		errs = append(errs, i18n.Errorf("集群ID不能为空"))
 	}
 	if config.ClusterInfo.Domain == "" {
-		return fmt.Errorf("集群域名不能为空")
+		errs = append(errs, i18n.Errorf("集群域名不能为空"))
 	}
 	if config.ClusterInfo.OpenShiftVersion == "" {
-		return fmt.Errorf("OpenShift版本不能为空")
+		errs = append(errs, i18n.Errorf("OpenShift版本不能为空"))
+	} else if utils.CompareVersion(config.ClusterInfo.OpenShiftVersion, utils.MinLegacyMirrorVersion) < 0 {
+		errs = append(errs, i18n.Errorf("OpenShift 版本 %s 不受支持，最低支持 4.10 (4.10 至 4.13 的 release 通过 oc adm release mirror 镜像)", config.ClusterInfo.OpenShiftVersion))
 	}
 
-	// 验证Bastion节点配置
-	if config.Bastion.IP == "" {
-		return fmt.Errorf("Bastion节点IP不能为空")
-	}
-	if config.Bastion.Username == "" {
-		return fmt.Errorf("Bastion节点用户名不能为空")
-	}
-	if config.Bastion.SSHKeyPath == "" && config.Bastion.Password == "" {
-		return fmt.Errorf("Bastion节点必须提供SSH密钥或密码")
+	// 验证Bastion节点配置 (未启用 Bastion 时由 ValidateInfraConfig 验证站点的 DNS 和负载均衡)
+	if config.BastionEnabled() {
+		if config.Bastion.IP == "" {
+			errs = append(errs, i18n.Errorf("Bastion节点IP不能为空"))
+		}
+		if config.Bastion.Username == "" {
+			errs = append(errs, i18n.Errorf("Bastion节点用户名不能为空"))
+		}
+		if config.Bastion.SSHKeyPath == "" && config.Bastion.Password == "" {
+			errs = append(errs, i18n.Errorf("Bastion节点必须提供SSH密钥或密码"))
+		}
 	}
 
 	// 验证Registry节点配置
 	if config.Registry.IP == "" {
-		return fmt.Errorf("registry节点IP不能为空")
+		errs = append(errs, i18n.Errorf("registry节点IP不能为空"))
 	}
 	if config.Registry.Username == "" {
-		return fmt.Errorf("registry节点用户名不能为空")
+		errs = append(errs, i18n.Errorf("registry节点用户名不能为空"))
 	}
 	if config.Registry.SSHKeyPath == "" && config.Registry.Password == "" {
-		return fmt.Errorf("registry节点必须提供SSH密钥或密码")
+		errs = append(errs, i18n.Errorf("registry节点必须提供SSH密钥或密码"))
 	}
 	if config.Registry.StoragePath == "" {
-		return fmt.Errorf("registry节点存储路径不能为空")
+		errs = append(errs, i18n.Errorf("registry节点存储路径不能为空"))
 	}
 
 	// 验证集群节点配置
 	if len(config.Cluster.ControlPlane) == 0 {
-		return fmt.Errorf("至少需要配置一个Control Plane节点")
+		errs = append(errs, i18n.Errorf("至少需要配置一个Control Plane节点"))
 	}
 
 	for i, cp := range config.Cluster.ControlPlane {
 		if cp.Name == "" {
-			return fmt.Errorf("control Plane节点[%d]名称不能为空", i)
+			errs = append(errs, i18n.Errorf("control Plane节点[%d]名称不能为空", i))
 		}
-		if cp.IP == "" {
-			return fmt.Errorf("control Plane节点[%d] %s 的IP不能为空", i, cp.Name)
+		if cp.IP == "" && !cp.DHCP {
+			errs = append(errs, i18n.Errorf("control Plane节点[%d] %s 的IP不能为空 (通过 DHCP 获取地址时设置 dhcp = true)", i, cp.Name))
 		}
 		if cp.MAC == "" {
-			return fmt.Errorf("control Plane节点[%d] %s 的MAC地址不能为空", i, cp.Name)
+			errs = append(errs, i18n.Errorf("control Plane节点[%d] %s 的MAC地址不能为空", i, cp.Name))
 		}
 	}
 
 	for i, worker := range config.Cluster.Worker {
 		if worker.Name == "" {
-			return fmt.Errorf("worker节点[%d]名称不能为空", i)
+			errs = append(errs, i18n.Errorf("worker节点[%d]名称不能为空", i))
 		}
-		if worker.IP == "" {
-			return fmt.Errorf("worker节点[%d] %s 的IP不能为空", i, worker.Name)
+		if worker.IP == "" && !worker.DHCP {
+			errs = append(errs, i18n.Errorf("worker节点[%d] %s 的IP不能为空 (通过 DHCP 获取地址时设置 dhcp = true)", i, worker.Name))
 		}
 		if worker.MAC == "" {
-			return fmt.Errorf("worker节点[%d] %s 的MAC地址不能为空", i, worker.Name)
+			errs = append(errs, i18n.Errorf("worker节点[%d] %s 的MAC地址不能为空", i, worker.Name))
 		}
 	}
 
-	// 验证网络配置
-	if config.Cluster.Network.ClusterNetwork == "" {
-		return fmt.Errorf("集群网络CIDR不能为空")
+	// 验证网络配置，缺少 CIDR 时不再检查网段之间的关系
+	network := config.Cluster.Network
+	if network.ClusterNetwork == "" {
+		errs = append(errs, i18n.Errorf("集群网络CIDR不能为空"))
 	}
-	if config.Cluster.Network.ServiceNetwork == "" {
-		return fmt.Errorf("服务网络CIDR不能为空")
+	if network.ServiceNetwork == "" {
+		errs = append(errs, i18n.Errorf("服务网络CIDR不能为空"))
 	}
-	if config.Cluster.Network.MachineNetwork == "" {
-		return fmt.Errorf("机器网络CIDR不能为空")
+	if network.MachineNetwork == "" {
+		errs = append(errs, i18n.Errorf("机器网络CIDR不能为空"))
+	}
+	if network.ClusterNetwork != "" && network.ServiceNetwork != "" && network.MachineNetwork != "" {
+		if err := ValidateNetworkConfig(config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, server := range network.NTPServers {
+		if strings.TrimSpace(server) == "" {
+			errs = append(errs, i18n.Errorf("NTP服务器[%d]不能为空", i))
+		}
 	}
 
-	return nil
+	for _, validate := range []func(*ClusterConfig) error{
+		ValidateNodeMetadata,
+		ValidateNodeBoot,
+		ValidateNodeAddresses,
+		ValidateComputePools,
+		ValidateInfraConfig,
+		ValidateExternalDNS,
+		ValidateDHCPNodes,
+		ValidateBastionDNS,
+		ValidateDNSMode,
+		ValidateInstallConfig,
+		ValidateBastionHAProxy,
+		ValidateRegistryAuths,
+		ValidateProxyCache,
+		ValidateRegistryQuay,
+		ValidateRegistryStorage,
+		ValidateHooks,
+		ValidateScanConfig,
+		ValidateRpmsConfig,
+		ValidateUpdateURLOverride,
+		ValidateGraphCacheTTL,
+		ValidateCatalogCacheTTL,
+		ValidateTargetNamespace,
+		ValidateImageStorage,
+		ValidateLocalStoragePort,
+		ValidateKeepBootArtifacts,
+		ValidateArchitectures,
+		ValidateRegistryTLS,
+		ValidateNotify,
+		ValidatePresets,
+		ValidateMaxCacheSize,
+		ValidateImageRetry,
+		ValidateTransferSigning,
+	} {
+		if err := validate(config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := ValidateMirrorGroups(config.SaveImage.MirrorOrder, "save_image.mirror_order"); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
 // ValidateBastionConfig 验证 Bastion 部署所需的配置
 func ValidateBastionConfig(config *ClusterConfig) error {
 	// 验证集群基本信息
 	if config.ClusterInfo.ClusterID == "" {
-		return fmt.Errorf("集群ID不能为空")
+		return i18n.Errorf("集群ID不能为空")
 	}
 	if config.ClusterInfo.Domain == "" {
-		return fmt.Errorf("集群域名不能为空")
+		return i18n.Errorf("集群域名不能为空")
 	}
 	if config.ClusterInfo.OpenShiftVersion == "" {
-		return fmt.Errorf("OpenShift版本不能为空")
+		return i18n.Errorf("OpenShift版本不能为空")
+	}
+
+	if !config.BastionEnabled() {
+		return i18n.Errorf("bastion.enabled = false，无需部署 Bastion 节点")
 	}
 
 	// 验证Bastion节点配置
 	if config.Bastion.IP == "" {
-		return fmt.Errorf("Bastion节点IP不能为空")
+		return i18n.Errorf("Bastion节点IP不能为空")
 	}
 	if config.Bastion.Username == "" {
-		return fmt.Errorf("Bastion节点用户名不能为空")
+		return i18n.Errorf("Bastion节点用户名不能为空")
 	}
 	if config.Bastion.SSHKeyPath == "" && config.Bastion.Password == "" {
-		return fmt.Errorf("Bastion节点必须提供SSH密钥或密码")
+		return i18n.Errorf("Bastion节点必须提供SSH密钥或密码")
 	}
 
 	// 验证Registry节点IP（Bastion需要配置Registry的DNS解析）
 	if config.Registry.IP == "" {
-		return fmt.Errorf("registry节点IP不能为空（Bastion需要配置Registry的DNS解析）")
+		return i18n.Errorf("registry节点IP不能为空（Bastion需要配置Registry的DNS解析）")
 	}
 
 	// 验证集群节点配置（Bastion 需要这些信息来配置 DNS 和 HAProxy）
 	if len(config.Cluster.ControlPlane) == 0 {
-		return fmt.Errorf("至少需要配置一个control Plane节点")
+		return i18n.Errorf("至少需要配置一个control Plane节点")
 	}
 
 	for i, cp := range config.Cluster.ControlPlane {
 		if cp.Name == "" {
-			return fmt.Errorf("control Plane节点[%d]名称不能为空", i)
+			return i18n.Errorf("control Plane节点[%d]名称不能为空", i)
 		}
-		if cp.IP == "" {
-			return fmt.Errorf("control Plane节点[%d] %s 的IP不能为空", i, cp.Name)
+		if cp.IP == "" && !cp.DHCP {
+			return i18n.Errorf("control Plane节点[%d] %s 的IP不能为空", i, cp.Name)
 		}
 		// MAC 地址对于 Bastion 部署不是必需的
 	}
 
 	for i, worker := range config.Cluster.Worker {
 		if worker.Name == "" {
-			return fmt.Errorf("worker节点[%d]名称不能为空", i)
+			return i18n.Errorf("worker节点[%d]名称不能为空", i)
 		}
-		if worker.IP == "" {
-			return fmt.Errorf("worker节点[%d] %s 的IP不能为空", i, worker.Name)
+		if worker.IP == "" && !worker.DHCP {
+			return i18n.Errorf("worker节点[%d] %s 的IP不能为空", i, worker.Name)
 		}
 		// MAC 地址对于 Bastion 部署不是必需的
 	}
 
 	// 验证网络配置
 	if config.Cluster.Network.ClusterNetwork == "" {
-		return fmt.Errorf("集群网络CIDR不能为空")
+		return i18n.Errorf("集群网络CIDR不能为空")
 	}
 	if config.Cluster.Network.ServiceNetwork == "" {
-		return fmt.Errorf("服务网络CIDR不能为空")
+		return i18n.Errorf("服务网络CIDR不能为空")
 	}
 	if config.Cluster.Network.MachineNetwork == "" {
-		return fmt.Errorf("机器网络CIDR不能为空")
+		return i18n.Errorf("机器网络CIDR不能为空")
 	}
 
 	return nil
@@ -420,24 +848,35 @@ func ValidateBastionConfig(config *ClusterConfig) error {
 func ValidateRegistryConfig(config *ClusterConfig) error {
 	// 验证集群基本信息
 	if config.ClusterInfo.ClusterID == "" {
-		return fmt.Errorf("集群ID不能为空")
+		return i18n.Errorf("集群ID不能为空")
 	}
 	if config.ClusterInfo.OpenShiftVersion == "" {
-		return fmt.Errorf("OpenShift版本不能为空")
+		return i18n.Errorf("OpenShift版本不能为空")
 	}
 
 	// 验证Registry节点配置
 	if config.Registry.IP == "" {
-		return fmt.Errorf("registry节点IP不能为空")
+		return i18n.Errorf("registry节点IP不能为空")
 	}
 	if config.Registry.Username == "" {
-		return fmt.Errorf("registry节点用户名不能为空")
+		return i18n.Errorf("registry节点用户名不能为空")
 	}
 	if config.Registry.SSHKeyPath == "" && config.Registry.Password == "" {
-		return fmt.Errorf("registry节点必须提供SSH密钥或密码")
+		return i18n.Errorf("registry节点必须提供SSH密钥或密码")
 	}
 	if config.Registry.StoragePath == "" {
-		return fmt.Errorf("registry节点存储路径不能为空")
+		return i18n.Errorf("registry节点存储路径不能为空")
+	}
+	if err := ValidateProxyCache(config); err != nil {
+		return err
+	}
+	if err := ValidateRegistryStorage(config); err != nil {
+		return err
+	}
+
+	// 未启用 Bastion 时 Registry 节点使用站点的 DNS 服务器
+	if !config.BastionEnabled() && len(config.Infra.DNSServers) == 0 {
+		return i18n.Errorf("bastion.enabled = false 时必须配置 infra.dns_servers")
 	}
 
 	return nil
@@ -450,17 +889,13 @@ func ValidateRegistryConfigWithDownloads(config *ClusterConfig, downloadDir stri
 		return err
 	}
 
-	// 验证必需的下载文件是否存在
+	// 验证必需的下载文件是否存在。mirror-registry 安装包也可以使用 save-image 归档的副本，
+	// 由 deploy 在部署时查找
 	requiredFiles := []struct {
 		path        string
 		description string
 		required    bool
 	}{
-		{
-			path:        downloadDir + "/mirror-registry-amd64.tar.gz",
-			description: "Quay 镜像仓库安装包",
-			required:    true,
-		},
 		{
 			path:        downloadDir + "/bin/oc",
 			description: "OpenShift 客户端工具",
@@ -481,10 +916,10 @@ func ValidateRegistryConfigWithDownloads(config *ClusterConfig, downloadDir stri
 	for _, file := range requiredFiles {
 		if _, err := os.Stat(file.path); os.IsNotExist(err) {
 			if file.required {
-				return fmt.Errorf("缺少必需的文件: %s (%s)\n请先运行 'ocpack download' 命令下载所需文件", file.path, file.description)
+				return i18n.Errorf("缺少必需的文件: %s (%s)\n请先运行 'ocpack download' 命令下载所需文件", file.path, i18n.T(file.description))
 			}
 			// 对于可选文件，只记录警告
-			fmt.Printf("ℹ️  可选文件不存在: %s (%s)\n", file.path, file.description)
+			i18n.Printf("ℹ️  可选文件不存在: %s (%s)\n", file.path, i18n.T(file.description))
 		}
 	}
 
@@ -495,7 +930,7 @@ func ValidateRegistryConfigWithDownloads(config *ClusterConfig, downloadDir stri
 func ValidateDownloadConfig(config *ClusterConfig) error {
 	// 验证集群基本信息
 	if config.ClusterInfo.OpenShiftVersion == "" {
-		return fmt.Errorf("OpenShift版本不能为空")
+		return i18n.Errorf("OpenShift版本不能为空")
 	}
 
 	return nil
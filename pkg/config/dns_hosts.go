@@ -0,0 +1,148 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Bastion DNS 的工作模式
+const (
+	// DNSModeAuthoritative Bastion 上的 named 作为集群域的权威 DNS，需要站点 DNS 将集群域委派给 Bastion
+	DNSModeAuthoritative = "authoritative"
+	// DNSModeHosts 站点 DNS 无法委派集群域时，Bastion 上的 dnsmasq 根据生成的 hosts 文件应答集群域的查询，
+	// 其余查询转发到上游 DNS。管理员工作站可以直接使用生成的 /etc/hosts 片段
+	DNSModeHosts = "hosts"
+)
+
+// defaultAppsRoutes 集群安装和日常管理用到的 *.apps 路由。/etc/hosts 不支持通配符，逐个列出
+var defaultAppsRoutes = []string{
+	"console-openshift-console",
+	"oauth-openshift",
+	"downloads-openshift-console",
+	"canary-openshift-ingress-canary",
+	"default-route-openshift-image-registry",
+	"alertmanager-main-openshift-monitoring",
+	"prometheus-k8s-openshift-monitoring",
+	"thanos-querier-openshift-monitoring",
+}
+
+// HostEntry hosts 文件中的一行，Names 为完整域名
+type HostEntry struct {
+	IP    string
+	Names []string
+}
+
+// GetMode 返回 DNS 工作模式，未配置时为 DNSModeAuthoritative
+func (d BastionDNS) GetMode() string {
+	if d.Mode == "" {
+		return DNSModeAuthoritative
+	}
+	return strings.ToLower(d.Mode)
+}
+
+// DNSHostsMode 返回是否使用 hosts 文件模式
+func (c *ClusterConfig) DNSHostsMode() bool {
+	return c.Bastion.DNS.GetMode() == DNSModeHosts
+}
+
+// ClusterDomain 返回集群域，如 demo.example.com。集群域只由 cluster_info.cluster_id 和 domain 决定，
+// 与集群目录的名称无关；集群域中的主机名都应通过 HostFQDN 等方法生成，避免各处拼接的结果不一致
+func (c *ClusterConfig) ClusterDomain() string {
+	return c.ClusterInfo.ClusterID + "." + c.ClusterInfo.Domain
+}
+
+// HostFQDN 返回集群域中主机的完整域名，如 HostFQDN("registry") 为 registry.demo.example.com
+func (c *ClusterConfig) HostFQDN(name string) string {
+	return name + "." + c.ClusterDomain()
+}
+
+// RegistryHostname 返回私有仓库的主机名 (不含端口)，如 registry.demo.example.com
+func (c *ClusterConfig) RegistryHostname() string {
+	return c.HostFQDN("registry")
+}
+
+// APIHostname 返回集群 API 的主机名，如 api.demo.example.com
+func (c *ClusterConfig) APIHostname() string {
+	return c.HostFQDN("api")
+}
+
+// CheckClusterDir 检查集群目录名是否与 cluster_info.cluster_id 一致。命令按目录名查找配置、镜像和缓存，
+// 生成的主机名使用 cluster_id，两者不同时返回警告信息，一致时返回空字符串
+func (c *ClusterConfig) CheckClusterDir(clusterDir string) string {
+	name := filepath.Base(filepath.Clean(clusterDir))
+	if c.ClusterInfo.ClusterID == "" || name == c.ClusterInfo.ClusterID {
+		return ""
+	}
+	return i18n.Sprintf("集群目录 %s 与 cluster_info.cluster_id (%s) 不一致，仓库等主机名将使用 %s",
+		name, c.ClusterInfo.ClusterID, c.RegistryHostname())
+}
+
+// AppsDomain 返回 Ingress 的通配域，如 apps.demo.example.com
+func (c *ClusterConfig) AppsDomain() string {
+	return "apps." + c.ClusterDomain()
+}
+
+// AppsRoutes 返回需要在 hosts 文件中逐个列出的 *.apps 路由的完整域名
+func (c *ClusterConfig) AppsRoutes() []string {
+	routes := make([]string, len(defaultAppsRoutes))
+	for i, route := range defaultAppsRoutes {
+		routes[i] = route + "." + c.AppsDomain()
+	}
+	return routes
+}
+
+// HostEntries 返回集群域中 ocpack 管理的地址: Bastion、Registry、API、常用 *.apps 路由、
+// 配置了 ip 的节点以及 [bastion.dns] 中的额外 A 记录
+func (c *ClusterConfig) HostEntries() []HostEntry {
+	fqdn := c.HostFQDN
+
+	var entries []HostEntry
+	if c.BastionEnabled() {
+		entries = append(entries, HostEntry{IP: c.Bastion.IP, Names: []string{fqdn("bastion")}})
+	}
+	entries = append(entries,
+		HostEntry{IP: c.Registry.IP, Names: []string{fqdn("registry")}},
+		HostEntry{IP: c.GetLoadBalancer(), Names: append([]string{fqdn("api"), fqdn("api-int")}, c.AppsRoutes()...)},
+	)
+	for _, nodes := range [][]Node{c.Cluster.ControlPlane, c.Cluster.Worker} {
+		for _, node := range nodes {
+			if node.IP != "" {
+				entries = append(entries, HostEntry{IP: node.IP, Names: []string{fqdn(node.Name)}})
+			}
+		}
+	}
+	for _, record := range c.Bastion.DNS.Records {
+		if record.RecordType() == DNSRecordA {
+			entries = append(entries, HostEntry{IP: record.Value, Names: []string{fqdn(record.Name)}})
+		}
+	}
+	return entries
+}
+
+// ValidateDNSMode 验证 [bastion.dns] mode。hosts 模式下 DNS 无法反映 DHCP 分配的地址，所有节点必须配置 ip；
+// dnsmasq 的 CNAME 只能指向它自己应答的名称，额外记录只支持 A 记录
+func ValidateDNSMode(config *ClusterConfig) error {
+	switch config.Bastion.DNS.GetMode() {
+	case DNSModeAuthoritative:
+		return nil
+	case DNSModeHosts:
+	default:
+		return i18n.Errorf("bastion.dns.mode %q 不支持，支持: %s、%s", config.Bastion.DNS.Mode, DNSModeAuthoritative, DNSModeHosts)
+	}
+
+	for _, nodes := range [][]Node{config.Cluster.ControlPlane, config.Cluster.Worker} {
+		for _, node := range nodes {
+			if node.IP == "" {
+				return i18n.Errorf("bastion.dns.mode = %q 时节点 %s 必须配置 ip (DHCP 节点填写保留地址)", DNSModeHosts, node.Name)
+			}
+		}
+	}
+	for _, record := range config.Bastion.DNS.Records {
+		if record.RecordType() != DNSRecordA {
+			return i18n.Errorf("bastion.dns.mode = %q 时 bastion.dns.records 只支持 A 记录，%s 为 %s", DNSModeHosts, record.Name, record.RecordType())
+		}
+	}
+	return nil
+}
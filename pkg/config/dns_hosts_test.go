@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func hostsModeConfig() *ClusterConfig {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.ClusterID = "demo"
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Bastion.DNS.Mode = DNSModeHosts
+	cfg.Cluster.ControlPlane = []Node{{Name: "master-0", IP: "192.168.1.10"}}
+	cfg.Cluster.Worker = []Node{{Name: "worker-0", IP: "192.168.1.20", DHCP: true}}
+	return cfg
+}
+
+func TestValidateDNSMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ClusterConfig)
+		valid  bool
+	}{
+		{"hosts", func(c *ClusterConfig) {}, true},
+		{"default", func(c *ClusterConfig) { c.Bastion.DNS.Mode = "" }, true},
+		{"upper case", func(c *ClusterConfig) { c.Bastion.DNS.Mode = "Hosts" }, true},
+		{"unsupported", func(c *ClusterConfig) { c.Bastion.DNS.Mode = "dnsmasq" }, false},
+		{"cname record", func(c *ClusterConfig) {
+			c.Bastion.DNS.Records = []DNSRecord{{Name: "proxy", Type: "CNAME", Value: "squid.example.com."}}
+		}, false},
+		{"dhcp node without ip", func(c *ClusterConfig) { c.Cluster.Worker[0].IP = "" }, false},
+		{"authoritative allows dhcp node without ip", func(c *ClusterConfig) {
+			c.Bastion.DNS.Mode = DNSModeAuthoritative
+			c.Cluster.Worker[0].IP = ""
+		}, true},
+	}
+	for _, tt := range tests {
+		cfg := hostsModeConfig()
+		tt.modify(cfg)
+		if err := ValidateDNSMode(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateDNSMode error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestHostEntries(t *testing.T) {
+	cfg := hostsModeConfig()
+	cfg.Bastion.DNS.Mode = DNSModeAuthoritative
+	cfg.Bastion.DNS.Records = []DNSRecord{
+		{Name: "ntp", Value: "192.168.1.5"},
+		{Name: "proxy", Type: "CNAME", Value: "squid.example.com."},
+	}
+
+	entries := cfg.HostEntries()
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.IP+" "+entry.Names[0])
+	}
+	want := []string{
+		"192.168.1.2 bastion.demo.example.com",
+		"192.168.1.3 registry.demo.example.com",
+		"192.168.1.2 api.demo.example.com",
+		"192.168.1.10 master-0.demo.example.com",
+		"192.168.1.20 worker-0.demo.example.com",
+		"192.168.1.5 ntp.demo.example.com",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HostEntries() = %v, want %v", got, want)
+	}
+
+	api := entries[2].Names
+	if api[1] != "api-int.demo.example.com" || api[2] != "console-openshift-console.apps.demo.example.com" {
+		t.Errorf("API entry names = %v", api)
+	}
+}
+
+func TestClusterHostnames(t *testing.T) {
+	cfg := hostsModeConfig()
+	cfg.ClusterInfo.ClusterID = "prod"
+	if got := cfg.RegistryHostname(); got != "registry.prod.example.com" {
+		t.Errorf("RegistryHostname() = %q", got)
+	}
+	if got := cfg.APIHostname(); got != "api.prod.example.com" {
+		t.Errorf("APIHostname() = %q", got)
+	}
+	if got := cfg.GetRegistryHost(); got != "registry.prod.example.com:8443" {
+		t.Errorf("GetRegistryHost() = %q", got)
+	}
+
+	if warning := cfg.CheckClusterDir("/work/prod/"); warning != "" {
+		t.Errorf("CheckClusterDir() = %q, expected no warning", warning)
+	}
+	if warning := cfg.CheckClusterDir("/work/demo"); warning == "" {
+		t.Error("CheckClusterDir() should warn when the directory differs from cluster_id")
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "path/filepath"
+
+// SharedDownloadsDir 项目级共享下载目录名，与各集群目录同级
+const SharedDownloadsDir = "shared-downloads"
+
+// GetDownloadDir 返回集群使用的下载目录
+// download.shared = true 时按 OpenShift 版本放在项目目录下的 shared-downloads/<版本>，
+// 同版本的集群共用同一份工具和安装包；否则为集群目录下的 local_path
+func (c *ClusterConfig) GetDownloadDir(clusterDir string) string {
+	if c.Download.Shared {
+		return filepath.Join(filepath.Dir(filepath.Clean(clusterDir)), SharedDownloadsDir, c.ClusterInfo.OpenShiftVersion)
+	}
+	return filepath.Join(clusterDir, c.Download.LocalPath)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDownloadDir(t *testing.T) {
+	cfg := NewDefaultConfig("c1")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.20"
+	clusterDir := filepath.Join("/work", "c1")
+
+	if got, want := cfg.GetDownloadDir(clusterDir), filepath.Join("/work", "c1", "downloads"); got != want {
+		t.Errorf("GetDownloadDir() = %s, expected %s", got, want)
+	}
+
+	// 共享下载目录与集群目录同级，并按版本隔离
+	cfg.Download.Shared = true
+	if got, want := cfg.GetDownloadDir(clusterDir+"/"), filepath.Join("/work", SharedDownloadsDir, "4.16.20"); got != want {
+		t.Errorf("GetDownloadDir() = %s, expected %s", got, want)
+	}
+
+	other := NewDefaultConfig("c2")
+	other.ClusterInfo.OpenShiftVersion = "4.16.20"
+	other.Download.Shared = true
+	if cfg.GetDownloadDir(clusterDir) != other.GetDownloadDir(filepath.Join("/work", "c2")) {
+		t.Error("clusters with the same version should share the download directory")
+	}
+}
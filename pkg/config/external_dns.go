@@ -0,0 +1,136 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"ocpack/pkg/i18n"
+)
+
+// [infra.external_dns] provider 的内置取值，其他值表示 PATH 中名为 ExternalDNSPluginPrefix+provider 的插件
+const (
+	ExternalDNSWebhook = "webhook"
+	ExternalDNSExec    = "exec"
+)
+
+// ExternalDNSPluginPrefix 外部 DNS 插件可执行文件的名称前缀，provider = "infoblox" 时执行 ocpack-dns-infoblox
+const ExternalDNSPluginPrefix = "ocpack-dns-"
+
+// DefaultExternalDNSTTL 外部 DNS 记录默认的 TTL (秒)
+const DefaultExternalDNSTTL = 300
+
+// DefaultDNSPropagationTimeout sync-dns 等待记录传播到全部 DNS 服务器的默认时间
+const DefaultDNSPropagationTimeout = 10 * time.Minute
+
+// pluginNamePattern 插件名称，只允许字母、数字和连字符，避免拼接出其他路径
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ExternalDNS 站点托管的 DNS (Infoblox、Route53 等)，对应 [infra.external_dns]。配置后 ocpack sync-dns 通过
+// webhook、命令或插件创建集群的 api、api-int、*.apps 和 registry 记录，并等待记录传播到 resolvers
+type ExternalDNS struct {
+	// webhook、exec 或插件名称 (执行 PATH 中的 ocpack-dns-<名称>)
+	Provider string `toml:"provider,omitempty"`
+	// provider = "webhook" 时接收记录的地址，请求体为 JSON
+	URL string `toml:"url,omitempty"`
+	// 可选，webhook 请求的 Bearer token
+	Token string `toml:"token,omitempty"`
+	// provider = "exec" 时执行的命令，记录以 JSON 写入标准输入
+	Command string `toml:"command,omitempty"`
+	// 可选，记录所在的区域，默认为 cluster_info.domain
+	Zone string `toml:"zone,omitempty"`
+	// 可选，记录的 TTL (秒)，默认 DefaultExternalDNSTTL
+	TTL int `toml:"ttl,omitempty"`
+	// 可选，是否同时创建 registry 记录，默认 true
+	Registry *bool `toml:"registry,omitempty"`
+	// 可选，检查传播的 DNS 服务器，默认为节点使用的 DNS 服务器
+	Resolvers []string `toml:"resolvers,omitempty"`
+	// 可选，sync-dns 等待传播的时间，默认 10m
+	PropagationTimeout string `toml:"propagation_timeout,omitempty"`
+}
+
+// ExternalDNSEnabled 返回是否配置了外部 DNS
+func (c *ClusterConfig) ExternalDNSEnabled() bool {
+	return c.Infra.ExternalDNS.Provider != ""
+}
+
+// GetExternalDNSZone 返回外部 DNS 记录所在的区域
+func (c *ClusterConfig) GetExternalDNSZone() string {
+	if zone := c.Infra.ExternalDNS.Zone; zone != "" {
+		return strings.TrimSuffix(zone, ".")
+	}
+	return c.ClusterInfo.Domain
+}
+
+// GetTTL 返回记录的 TTL (秒)
+func (e ExternalDNS) GetTTL() int {
+	if e.TTL == 0 {
+		return DefaultExternalDNSTTL
+	}
+	return e.TTL
+}
+
+// RegistryEnabled 返回是否创建 registry 记录
+func (e ExternalDNS) RegistryEnabled() bool {
+	return e.Registry == nil || *e.Registry
+}
+
+// GetPropagationTimeout 返回等待记录传播的时间
+func (e ExternalDNS) GetPropagationTimeout() time.Duration {
+	timeout, err := time.ParseDuration(e.PropagationTimeout)
+	if e.PropagationTimeout == "" || err != nil {
+		return DefaultDNSPropagationTimeout
+	}
+	return timeout
+}
+
+// GetExternalDNSResolvers 返回检查记录传播的 DNS 服务器
+func (c *ClusterConfig) GetExternalDNSResolvers() []string {
+	if len(c.Infra.ExternalDNS.Resolvers) > 0 {
+		return c.Infra.ExternalDNS.Resolvers
+	}
+	return c.GetDNSServers()
+}
+
+// ValidateExternalDNS 验证 [infra.external_dns]
+func ValidateExternalDNS(config *ClusterConfig) error {
+	ext := config.Infra.ExternalDNS
+	switch ext.Provider {
+	case "":
+		return nil
+	case ExternalDNSWebhook:
+		u, err := url.Parse(ext.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("infra.external_dns.url %q 必须是 http 或 https 地址", ext.URL)
+		}
+	case ExternalDNSExec:
+		if strings.TrimSpace(ext.Command) == "" {
+			return i18n.Errorf("infra.external_dns.provider = \"exec\" 时必须配置 command")
+		}
+	default:
+		if !pluginNamePattern.MatchString(ext.Provider) {
+			return i18n.Errorf("infra.external_dns.provider %q 无效，可选值: %s、%s 或插件名称 (执行 %s<名称>)",
+				ext.Provider, ExternalDNSWebhook, ExternalDNSExec, ExternalDNSPluginPrefix)
+		}
+	}
+	if ext.TTL < 0 {
+		return i18n.Errorf("infra.external_dns.ttl 不能为负数")
+	}
+	zone := config.GetExternalDNSZone()
+	if domain := config.ClusterDomain(); domain != zone && !strings.HasSuffix(domain, "."+zone) {
+		return i18n.Errorf("infra.external_dns.zone %q 不包含集群域 %s", zone, domain)
+	}
+	for i, resolver := range ext.Resolvers {
+		if net.ParseIP(resolver) == nil {
+			return i18n.Errorf("infra.external_dns.resolvers[%d] %q 不是有效的 IP 地址", i, resolver)
+		}
+	}
+	if value := ext.PropagationTimeout; value != "" {
+		if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+			return i18n.Errorf("infra.external_dns.propagation_timeout %q 必须是正的时长，如 \"10m\"", value)
+		}
+	}
+	return nil
+}
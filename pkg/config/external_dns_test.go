@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateExternalDNS(t *testing.T) {
+	tests := []struct {
+		name  string
+		ext   ExternalDNS
+		valid bool
+	}{
+		{"disabled", ExternalDNS{}, true},
+		{"webhook", ExternalDNS{Provider: "webhook", URL: "https://dns.example.com/hooks/ocpack", Token: "t0ken"}, true},
+		{"webhook without url", ExternalDNS{Provider: "webhook"}, false},
+		{"webhook invalid url", ExternalDNS{Provider: "webhook", URL: "dns.example.com"}, false},
+		{"exec", ExternalDNS{Provider: "exec", Command: "./dns/upsert.sh"}, true},
+		{"exec without command", ExternalDNS{Provider: "exec", Command: " "}, false},
+		{"plugin", ExternalDNS{Provider: "infoblox"}, true},
+		{"plugin path", ExternalDNS{Provider: "../infoblox"}, false},
+		{"negative ttl", ExternalDNS{Provider: "infoblox", TTL: -1}, false},
+		{"parent zone", ExternalDNS{Provider: "infoblox", Zone: "example.com."}, true},
+		{"cluster zone", ExternalDNS{Provider: "infoblox", Zone: "demo.example.com"}, true},
+		{"unrelated zone", ExternalDNS{Provider: "infoblox", Zone: "example.org"}, false},
+		{"resolvers", ExternalDNS{Provider: "infoblox", Resolvers: []string{"10.0.0.53", "fd00::53"}}, true},
+		{"invalid resolver", ExternalDNS{Provider: "infoblox", Resolvers: []string{"dns.example.com"}}, false},
+		{"propagation timeout", ExternalDNS{Provider: "infoblox", PropagationTimeout: "30m"}, true},
+		{"invalid propagation timeout", ExternalDNS{Provider: "infoblox", PropagationTimeout: "0s"}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.Domain = "example.com"
+		cfg.Infra.ExternalDNS = tt.ext
+		if err := ValidateExternalDNS(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateExternalDNS error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestExternalDNSDefaults(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	ext := cfg.Infra.ExternalDNS
+	if cfg.ExternalDNSEnabled() || ext.GetTTL() != DefaultExternalDNSTTL || !ext.RegistryEnabled() || ext.GetPropagationTimeout() != DefaultDNSPropagationTimeout {
+		t.Errorf("unexpected defaults for %+v", ext)
+	}
+	if zone := cfg.GetExternalDNSZone(); zone != "example.com" {
+		t.Errorf("GetExternalDNSZone() = %s, expected the base domain", zone)
+	}
+	if resolvers := cfg.GetExternalDNSResolvers(); len(resolvers) != 1 || resolvers[0] != "192.168.1.2" {
+		t.Errorf("GetExternalDNSResolvers() = %v, expected the node DNS servers", resolvers)
+	}
+
+	disabled := false
+	cfg.Infra.ExternalDNS = ExternalDNS{Provider: "infoblox", Zone: "example.com.", TTL: 60, Registry: &disabled, Resolvers: []string{"10.0.0.53"}, PropagationTimeout: "2m"}
+	ext = cfg.Infra.ExternalDNS
+	if !cfg.ExternalDNSEnabled() || ext.GetTTL() != 60 || ext.RegistryEnabled() || ext.GetPropagationTimeout() != 2*time.Minute {
+		t.Errorf("configured values were not used for %+v", ext)
+	}
+	if zone := cfg.GetExternalDNSZone(); zone != "example.com" {
+		t.Errorf("GetExternalDNSZone() = %s, expected the trailing dot to be removed", zone)
+	}
+	if resolvers := cfg.GetExternalDNSResolvers(); resolvers[0] != "10.0.0.53" {
+		t.Errorf("GetExternalDNSResolvers() = %v, expected the configured resolvers", resolvers)
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import "ocpack/pkg/i18n"
+
+// Bastion HAProxy 的默认端口。API (6443) 和 Machine Config Server (22623) 的端口由 OpenShift 固定，不能修改
+const (
+	DefaultHAProxyStatsPort = 9000
+	DefaultIngressHTTPPort  = 80
+	DefaultIngressHTTPSPort = 443
+	APIServerPort           = 6443
+	MachineConfigPort       = 22623
+)
+
+// BastionHAProxy Bastion 上 HAProxy 的统计页面和 Ingress 前端端口，对应 [bastion.haproxy]
+type BastionHAProxy struct {
+	StatsPort     int    `toml:"stats_port,omitempty"`     // 统计页面端口，默认 9000
+	StatsUser     string `toml:"stats_user,omitempty"`     // 设置后访问统计页面需要 HTTP Basic 认证
+	StatsPassword string `toml:"stats_password,omitempty"` // 与 stats_user 同时设置
+	HTTPPort      int    `toml:"http_port,omitempty"`      // Ingress HTTP 前端端口，默认 80
+	HTTPSPort     int    `toml:"https_port,omitempty"`     // Ingress HTTPS 前端端口，默认 443，如 443 已被占用可改为 8443
+}
+
+// GetStatsPort 返回统计页面端口
+func (h BastionHAProxy) GetStatsPort() int {
+	if h.StatsPort != 0 {
+		return h.StatsPort
+	}
+	return DefaultHAProxyStatsPort
+}
+
+// GetHTTPPort 返回 Ingress HTTP 前端端口
+func (h BastionHAProxy) GetHTTPPort() int {
+	if h.HTTPPort != 0 {
+		return h.HTTPPort
+	}
+	return DefaultIngressHTTPPort
+}
+
+// GetHTTPSPort 返回 Ingress HTTPS 前端端口
+func (h BastionHAProxy) GetHTTPSPort() int {
+	if h.HTTPSPort != 0 {
+		return h.HTTPSPort
+	}
+	return DefaultIngressHTTPSPort
+}
+
+// StatsAuthEnabled 返回统计页面是否需要认证
+func (h BastionHAProxy) StatsAuthEnabled() bool {
+	return h.StatsUser != ""
+}
+
+// ValidateBastionHAProxy 验证 [bastion.haproxy] 配置：端口有效且互不冲突，统计页面的用户名和密码同时设置
+func ValidateBastionHAProxy(config *ClusterConfig) error {
+	haproxy := config.Bastion.HAProxy
+	for name, port := range map[string]int{
+		"stats_port": haproxy.StatsPort,
+		"http_port":  haproxy.HTTPPort,
+		"https_port": haproxy.HTTPSPort,
+	} {
+		if port < 0 || port > 65535 {
+			return i18n.Errorf("bastion.haproxy.%s %d 无效，必须在 1-65535 之间", name, port)
+		}
+	}
+
+	// Bastion 上已占用的端口: DNS、API、Machine Config Server 和 PXE HTTP 服务
+	used := map[int]string{
+		53:                "DNS",
+		APIServerPort:     "API Server",
+		MachineConfigPort: "Machine Config Server",
+		8080:              i18n.T("PXE HTTP 服务"),
+	}
+	for _, p := range []struct {
+		name string
+		port int
+	}{
+		{"stats_port", haproxy.GetStatsPort()},
+		{"http_port", haproxy.GetHTTPPort()},
+		{"https_port", haproxy.GetHTTPSPort()},
+	} {
+		if owner, ok := used[p.port]; ok {
+			return i18n.Errorf("bastion.haproxy.%s %d 与 %s 的端口冲突", p.name, p.port, owner)
+		}
+		used[p.port] = "bastion.haproxy." + p.name
+	}
+
+	if (haproxy.StatsUser == "") != (haproxy.StatsPassword == "") {
+		return i18n.Errorf("bastion.haproxy 的 stats_user 和 stats_password 必须同时设置")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import "testing"
+
+func TestValidateBastionHAProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		haproxy BastionHAProxy
+		valid   bool
+	}{
+		{"default", BastionHAProxy{}, true},
+		{"remapped ingress", BastionHAProxy{HTTPPort: 8081, HTTPSPort: 8443, StatsPort: 9001}, true},
+		{"stats auth", BastionHAProxy{StatsUser: "admin", StatsPassword: "secret"}, true},
+		{"stats user without password", BastionHAProxy{StatsUser: "admin"}, false},
+		{"invalid port", BastionHAProxy{HTTPSPort: 70000}, false},
+		{"api port", BastionHAProxy{HTTPSPort: APIServerPort}, false},
+		{"pxe port", BastionHAProxy{HTTPPort: 8080}, false},
+		{"duplicate port", BastionHAProxy{HTTPPort: 8443, HTTPSPort: 8443}, false},
+		{"stats on ingress port", BastionHAProxy{StatsPort: 443}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Bastion.HAProxy = tt.haproxy
+		if err := ValidateBastionHAProxy(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateBastionHAProxy error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestBastionHAProxyDefaults(t *testing.T) {
+	var haproxy BastionHAProxy
+	if haproxy.GetStatsPort() != DefaultHAProxyStatsPort || haproxy.GetHTTPPort() != 80 || haproxy.GetHTTPSPort() != 443 {
+		t.Errorf("unexpected default ports: %d %d %d", haproxy.GetStatsPort(), haproxy.GetHTTPPort(), haproxy.GetHTTPSPort())
+	}
+	if haproxy.StatsAuthEnabled() {
+		t.Error("stats auth should be disabled by default")
+	}
+}
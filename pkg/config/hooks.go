@@ -0,0 +1,69 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// 钩子的执行时机，与阶段名称组成 [hooks] 中的键，如 pre_load_image、post_load_image
+const (
+	HookPre  = "pre"
+	HookPost = "post"
+)
+
+// HookStages 支持配置钩子的阶段，名称与对应的命令一致 (将 '-' 替换为 '_')
+var HookStages = []string{
+	"download",
+	"mirror_rpms",
+	"deploy_bastion",
+	"deploy_registry",
+	"scan_images",
+	"load_image",
+	"generate_iso",
+	"add_worker",
+	"day2_operatorhub",
+	"day2_update_service",
+	"day2_apply_bundle",
+	"day2_cnv_boot_sources",
+	"day2_compute_pools",
+	"day2_presets",
+}
+
+// HookKey 返回阶段钩子在 [hooks] 中的键，如 HookKey(HookPost, "load_image") 为 post_load_image
+func HookKey(phase, stage string) string {
+	return phase + "_" + stage
+}
+
+// GetHooks 返回指定阶段和时机需要执行的钩子命令
+func (c *ClusterConfig) GetHooks(phase, stage string) []string {
+	return c.Hooks[HookKey(phase, stage)]
+}
+
+// ValidateHooks 验证 [hooks] 配置，键必须为 pre_<阶段> 或 post_<阶段>，命令不能为空
+func ValidateHooks(config *ClusterConfig) error {
+	valid := make(map[string]bool)
+	for _, stage := range HookStages {
+		valid[HookKey(HookPre, stage)] = true
+		valid[HookKey(HookPost, stage)] = true
+	}
+
+	keys := make([]string, 0, len(config.Hooks))
+	for key := range config.Hooks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !valid[key] {
+			return i18n.Errorf("hooks.%s 无效，支持的阶段: %s (前缀 pre_ 或 post_)", key, strings.Join(HookStages, ", "))
+		}
+		for i, hook := range config.Hooks[key] {
+			if strings.TrimSpace(hook) == "" {
+				return i18n.Errorf("hooks.%s[%d] 不能为空", key, i)
+			}
+		}
+	}
+	return nil
+}
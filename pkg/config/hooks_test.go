@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		hooks map[string][]string
+		valid bool
+	}{
+		{nil, true},
+		{map[string][]string{"post_load_image": {"./scripts/notify.sh"}}, true},
+		{map[string][]string{"pre_day2_operatorhub": {"./scan.sh", "echo ok"}}, true},
+		{map[string][]string{"post_save_image": {"./scripts/notify.sh"}}, false},
+		{map[string][]string{"load_image": {"./scripts/notify.sh"}}, false},
+		{map[string][]string{"pre_download": {" "}}, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Hooks = tt.hooks
+		if err := ValidateHooks(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateHooks(%v) error = %v, expected valid = %t", tt.hooks, err, tt.valid)
+		}
+	}
+}
+
+func TestLoadConfigHooks(t *testing.T) {
+	path := t.TempDir() + "/config.toml"
+	if err := GenerateDefaultConfig(path, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Hooks) != 0 {
+		t.Errorf("default config should not define hooks: %v", cfg.Hooks)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"time"
+
+	"ocpack/pkg/i18n"
+)
+
+// 镜像复制重试的退避方式
+const (
+	BackoffLinear      = "linear"      // 第 n 次重试前等待 n 倍的 delay
+	BackoffExponential = "exponential" // 第 n 次重试前等待 2^(n-1) 倍的 delay
+)
+
+// ImageRetry [save_image.retry] 单个镜像复制失败时的重试策略，由内置 oc-mirror 的批量复制使用。
+// 与 save-image/load-image 的 --enable-retry 不同，后者在整个镜像任务失败后重新执行
+type ImageRetry struct {
+	Attempts int    `toml:"attempts,omitempty"` // 每个镜像最多尝试的次数，默认 3
+	Backoff  string `toml:"backoff,omitempty"`  // linear (默认) 或 exponential
+	Delay    string `toml:"delay,omitempty"`    // 第一次重试前的等待时间，默认 1s
+	Jitter   bool   `toml:"jitter,omitempty"`   // 在等待时间上增加随机抖动
+
+	// 不重试的错误 (不区分大小写的子串)，追加到内置的认证失败、镜像不存在等错误之后
+	NonRetryableErrors []string `toml:"non_retryable_errors,omitempty"`
+	// 总是重试的错误，优先于不重试的错误，如仓库返回的临时 "not found"
+	RetryableErrors []string `toml:"retryable_errors,omitempty"`
+}
+
+// GetDelay 返回第一次重试前的等待时间，未配置时为 0，由 oc-mirror 使用默认值
+func (r ImageRetry) GetDelay() time.Duration {
+	delay, _ := time.ParseDuration(r.Delay)
+	return delay
+}
+
+// ValidateImageRetry 验证 [save_image.retry]
+func ValidateImageRetry(config *ClusterConfig) error {
+	retry := config.SaveImage.Retry
+	if retry.Attempts < 0 {
+		return i18n.Errorf("save_image.retry.attempts 不能为负数")
+	}
+	if err := ValidateBackoff(retry.Backoff, "save_image.retry.backoff"); err != nil {
+		return err
+	}
+	if retry.Delay != "" {
+		if delay, err := time.ParseDuration(retry.Delay); err != nil || delay < 0 {
+			return i18n.Errorf("save_image.retry.delay %q 无效，应为非负的时长，如 \"2s\"", retry.Delay)
+		}
+	}
+	return nil
+}
+
+// ValidateBackoff 验证退避方式，key 为出错时显示的配置项或参数名
+func ValidateBackoff(backoff, key string) error {
+	switch backoff {
+	case "", BackoffLinear, BackoffExponential:
+		return nil
+	}
+	return i18n.Errorf("%s %q 无效，可选值: %s、%s", key, backoff, BackoffLinear, BackoffExponential)
+}
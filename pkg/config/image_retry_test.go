@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateImageRetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry ImageRetry
+		valid bool
+	}{
+		{"default", ImageRetry{}, true},
+		{"exponential", ImageRetry{Attempts: 5, Backoff: BackoffExponential, Delay: "2s", Jitter: true}, true},
+		{"negative attempts", ImageRetry{Attempts: -1}, false},
+		{"unknown backoff", ImageRetry{Backoff: "fibonacci"}, false},
+		{"invalid delay", ImageRetry{Delay: "2"}, false},
+		{"negative delay", ImageRetry{Delay: "-1s"}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.Retry = tt.retry
+		if err := ValidateImageRetry(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateImageRetry error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+
+	if delay := (ImageRetry{Delay: "2s"}).GetDelay(); delay != 2*time.Second {
+		t.Errorf("GetDelay() = %s, expected 2s", delay)
+	}
+}
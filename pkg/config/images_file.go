@@ -0,0 +1,54 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// AdhocImagesDirName save-image/load-image --images-file 的镜像归档目录，位于镜像存储目录下，
+// 与完整镜像集的归档和 oc-mirror 工作目录分开，互不影响
+const AdhocImagesDirName = "adhoc"
+
+// AdhocImagesDir 返回 --images-file 的镜像归档目录
+func AdhocImagesDir(imagesDir string) string {
+	return filepath.Join(imagesDir, AdhocImagesDirName)
+}
+
+// ReadImagesFile 读取镜像列表文件，每行一个镜像，可带 docker:// 前缀。空行和 # 开头的注释会被忽略，
+// 重复的镜像只保留一次。文件中没有镜像或某行不是合法的镜像引用时返回错误
+func ReadImagesFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("读取镜像列表失败: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var images []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		image := strings.TrimPrefix(line, "docker://")
+		if strings.ContainsAny(image, " \t") || strings.Contains(image, "://") || !strings.Contains(image, "/") {
+			return nil, i18n.Errorf("%s 第 %d 行不是合法的镜像引用: %s", path, lineNo, line)
+		}
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取镜像列表失败: %w", err)
+	}
+	if len(images) == 0 {
+		return nil, i18n.Errorf("镜像列表 %s 中没有镜像", path)
+	}
+	return images, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadImagesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{"images", "quay.io/acme/app:v1\nregistry.example.com/team/api@sha256:abc\n", []string{
+			"quay.io/acme/app:v1",
+			"registry.example.com/team/api@sha256:abc",
+		}, false},
+		{"comments and blanks", "# new app images\n\n  quay.io/acme/app:v1  \n", []string{"quay.io/acme/app:v1"}, false},
+		{"docker transport", "docker://quay.io/acme/app:v1\n", []string{"quay.io/acme/app:v1"}, false},
+		{"duplicates", "quay.io/acme/app:v1\ndocker://quay.io/acme/app:v1\n", []string{"quay.io/acme/app:v1"}, false},
+		{"empty", "# nothing yet\n", nil, true},
+		{"oci transport", "oci:///data/catalog\n", nil, true},
+		{"two per line", "quay.io/acme/app:v1 quay.io/acme/web:v1\n", nil, true},
+		{"no registry", "app\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "images.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadImagesFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadImagesFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadImagesFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadImagesFileMissing(t *testing.T) {
+	if _, err := ReadImagesFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("ReadImagesFile() on a missing file should fail")
+	}
+}
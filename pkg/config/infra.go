@@ -0,0 +1,173 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Infra 站点已有的基础设施服务，对应 [infra]。bastion.enabled = false 时 ocpack 不部署 Bastion，
+// 集群使用这里配置的 DNS、负载均衡和 PXE 资源服务器
+type Infra struct {
+	DNSServers    []string `toml:"dns_servers,omitempty"`    // 节点使用的 DNS 服务器，默认为 Bastion IP
+	LoadBalancer  string   `toml:"load_balancer,omitempty"`  // API 和 Ingress 负载均衡地址，默认为 Bastion 上的 HAProxy
+	RendezvousIP  string   `toml:"rendezvous_ip,omitempty"`  // agent 安装的 rendezvous 节点 IP，默认为第一个 Control Plane 节点
+	BootstrapNode string   `toml:"bootstrap_node,omitempty"` // 按名称指定作为 rendezvous (bootstrap) 的 Control Plane 节点
+	PXEAssetURL   string   `toml:"pxe_asset_url,omitempty"`  // PXE 启动文件的 HTTP 地址，默认为 Bastion 上的 http://<ip>:8080/pxe
+
+	// 额外信任的 CA 证书文件 (如企业代理的 CA)，与私有仓库 CA 合并到 additionalTrustBundle，相对路径相对于集群目录
+	TrustBundlePaths []string `toml:"trust_bundle_paths,omitempty"`
+	// additionalTrustBundlePolicy: Proxyonly 或 Always，配置了 trust_bundle_paths 时默认为 Always
+	TrustBundlePolicy string `toml:"trust_bundle_policy,omitempty"`
+	// ocpack 访问外部服务时额外信任的 CA 证书文件 (如解密 TLS 的企业代理的 CA)，用于下载工具、查询升级图和
+	// 从上游仓库复制镜像，不加入集群的 additionalTrustBundle，相对路径相对于集群目录
+	CABundle string `toml:"ca_bundle,omitempty"`
+
+	// 可选，站点托管的 DNS，由 ocpack sync-dns 创建集群需要的记录
+	ExternalDNS ExternalDNS `toml:"external_dns,omitempty"`
+}
+
+// additionalTrustBundlePolicy 的取值
+const (
+	TrustBundlePolicyProxyOnly = "Proxyonly"
+	TrustBundlePolicyAlways    = "Always"
+)
+
+// BastionEnabled 返回是否由 ocpack 部署 Bastion 节点 (DNS + HAProxy)，未配置 bastion.enabled 时为 true
+func (c *ClusterConfig) BastionEnabled() bool {
+	return c.Bastion.Enabled == nil || *c.Bastion.Enabled
+}
+
+// GetDNSServers 返回节点使用的 DNS 服务器，未配置 infra.dns_servers 时使用 Bastion
+func (c *ClusterConfig) GetDNSServers() []string {
+	if len(c.Infra.DNSServers) > 0 {
+		return c.Infra.DNSServers
+	}
+	return []string{c.Bastion.IP}
+}
+
+// GetLoadBalancer 返回 API 和 Ingress 负载均衡地址，未配置 infra.load_balancer 时使用 Bastion
+func (c *ClusterConfig) GetLoadBalancer() string {
+	if c.Infra.LoadBalancer != "" {
+		return c.Infra.LoadBalancer
+	}
+	return c.Bastion.IP
+}
+
+// GetTrustBundlePolicy 返回 install-config.yaml 的 additionalTrustBundlePolicy。配置了 trust_bundle_paths 时默认为 Always，
+// 使集群内的工作负载也信任代理等站点 CA；只有私有仓库 CA 时返回空，使用安装程序的默认值
+func (c *ClusterConfig) GetTrustBundlePolicy() string {
+	if c.Infra.TrustBundlePolicy != "" {
+		return c.Infra.TrustBundlePolicy
+	}
+	if len(c.Infra.TrustBundlePaths) > 0 {
+		return TrustBundlePolicyAlways
+	}
+	return ""
+}
+
+// GetCABundlePath 返回 infra.ca_bundle 的绝对路径，相对路径相对于集群目录，未配置时返回空
+func (c *ClusterConfig) GetCABundlePath(clusterDir string) string {
+	path := c.Infra.CABundle
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(clusterDir, path)
+}
+
+// GetRendezvousIP 返回 rendezvous 节点 IP。优先使用 infra.rendezvous_ip，其次是 infra.bootstrap_node
+// 指定节点的 IP，都未配置时使用第一个静态 IP 的 Control Plane 节点
+func (c *ClusterConfig) GetRendezvousIP() string {
+	if c.Infra.RendezvousIP != "" {
+		return c.Infra.RendezvousIP
+	}
+	if c.Infra.BootstrapNode != "" {
+		if node := c.findControlPlane(func(n Node) bool { return n.Name == c.Infra.BootstrapNode }); node != nil {
+			return node.IP
+		}
+	}
+	if node := c.findControlPlane(func(n Node) bool { return !n.DHCP }); node != nil {
+		return node.IP
+	}
+	return ""
+}
+
+// GetRendezvousNode 返回作为 rendezvous 的 Control Plane 节点，rendezvous IP 不属于任何 Control Plane 节点时返回 nil
+func (c *ClusterConfig) GetRendezvousNode() *Node {
+	ip := c.GetRendezvousIP()
+	if ip == "" {
+		return nil
+	}
+	return c.findControlPlane(func(n Node) bool { return n.IP == ip })
+}
+
+func (c *ClusterConfig) findControlPlane(match func(Node) bool) *Node {
+	for i := range c.Cluster.ControlPlane {
+		if match(c.Cluster.ControlPlane[i]) {
+			return &c.Cluster.ControlPlane[i]
+		}
+	}
+	return nil
+}
+
+// ValidateInfraConfig 验证 [infra] 配置。bastion.enabled = false 时必须显式配置
+// dns_servers、load_balancer 和 rendezvous_ip (或 bootstrap_node)，这些服务由站点自行提供
+func ValidateInfraConfig(config *ClusterConfig) error {
+	for i, server := range config.Infra.DNSServers {
+		if net.ParseIP(strings.TrimSpace(server)) == nil {
+			return i18n.Errorf("infra.dns_servers[%d] %q 不是有效的 IP 地址", i, server)
+		}
+	}
+	if rendezvousIP := config.Infra.RendezvousIP; rendezvousIP != "" {
+		if config.findControlPlane(func(n Node) bool { return n.IP == rendezvousIP }) == nil {
+			return i18n.Errorf("infra.rendezvous_ip %s 必须是某个 Control Plane 节点的 IP", rendezvousIP)
+		}
+	}
+	if bootstrapNode := config.Infra.BootstrapNode; bootstrapNode != "" {
+		node := config.findControlPlane(func(n Node) bool { return n.Name == bootstrapNode })
+		if node == nil {
+			return i18n.Errorf("infra.bootstrap_node %s 必须是某个 Control Plane 节点的名称", bootstrapNode)
+		}
+		if config.Infra.RendezvousIP != "" && config.Infra.RendezvousIP != node.IP {
+			return i18n.Errorf("infra.rendezvous_ip %s 与 infra.bootstrap_node %s 的 IP %s 不一致，只需配置其中一项",
+				config.Infra.RendezvousIP, bootstrapNode, node.IP)
+		}
+	}
+	for i, path := range config.Infra.TrustBundlePaths {
+		if strings.TrimSpace(path) == "" {
+			return i18n.Errorf("infra.trust_bundle_paths[%d] 不能为空", i)
+		}
+	}
+	if config.Infra.CABundle != "" && strings.TrimSpace(config.Infra.CABundle) == "" {
+		return i18n.Errorf("infra.ca_bundle 不能为空白")
+	}
+	switch config.Infra.TrustBundlePolicy {
+	case "", TrustBundlePolicyProxyOnly, TrustBundlePolicyAlways:
+	default:
+		return i18n.Errorf("infra.trust_bundle_policy %q 无效，可选值: %s、%s",
+			config.Infra.TrustBundlePolicy, TrustBundlePolicyProxyOnly, TrustBundlePolicyAlways)
+	}
+	if assetURL := config.Infra.PXEAssetURL; assetURL != "" {
+		u, err := url.Parse(assetURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("infra.pxe_asset_url %q 必须是 http:// 或 https:// 地址", assetURL)
+		}
+	}
+
+	if config.BastionEnabled() {
+		return nil
+	}
+	if len(config.Infra.DNSServers) == 0 {
+		return i18n.Errorf("bastion.enabled = false 时必须配置 infra.dns_servers")
+	}
+	if config.Infra.LoadBalancer == "" {
+		return i18n.Errorf("bastion.enabled = false 时必须配置 infra.load_balancer")
+	}
+	if config.Infra.RendezvousIP == "" && config.Infra.BootstrapNode == "" {
+		return i18n.Errorf("bastion.enabled = false 时必须配置 infra.rendezvous_ip 或 infra.bootstrap_node")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInfraDefaults(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Cluster.ControlPlane[0].IP = "192.168.1.10"
+
+	if !cfg.BastionEnabled() {
+		t.Error("BastionEnabled() = false, expected bastion enabled by default")
+	}
+	if servers := cfg.GetDNSServers(); !reflect.DeepEqual(servers, []string{"192.168.1.2"}) {
+		t.Errorf("GetDNSServers() = %v, expected bastion IP", servers)
+	}
+	if lb := cfg.GetLoadBalancer(); lb != "192.168.1.2" {
+		t.Errorf("GetLoadBalancer() = %q, expected bastion IP", lb)
+	}
+	if ip := cfg.GetRendezvousIP(); ip != "192.168.1.10" {
+		t.Errorf("GetRendezvousIP() = %q, expected first control plane IP", ip)
+	}
+
+	if node := cfg.GetRendezvousNode(); node == nil || node.Name != "master-0" {
+		t.Errorf("GetRendezvousNode() = %v, expected master-0", node)
+	}
+
+	cfg.Cluster.ControlPlane[2].IP = "192.168.1.12"
+	cfg.Infra.BootstrapNode = "master-2"
+	if ip := cfg.GetRendezvousIP(); ip != "192.168.1.12" {
+		t.Errorf("GetRendezvousIP() = %q, expected infra.bootstrap_node IP", ip)
+	}
+	if node := cfg.GetRendezvousNode(); node == nil || node.Name != "master-2" {
+		t.Errorf("GetRendezvousNode() = %v, expected master-2", node)
+	}
+
+	cfg.Infra = Infra{DNSServers: []string{"10.0.0.53", "10.0.0.54"}, LoadBalancer: "10.0.0.80", RendezvousIP: "192.168.1.10"}
+	if servers := cfg.GetDNSServers(); !reflect.DeepEqual(servers, cfg.Infra.DNSServers) {
+		t.Errorf("GetDNSServers() = %v, expected infra.dns_servers", servers)
+	}
+	if lb := cfg.GetLoadBalancer(); lb != "10.0.0.80" {
+		t.Errorf("GetLoadBalancer() = %q, expected infra.load_balancer", lb)
+	}
+}
+
+func TestValidateInfraConfig(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name    string
+		enabled *bool
+		infra   Infra
+		valid   bool
+	}{
+		{"bastion enabled", nil, Infra{}, true},
+		{"invalid dns server", nil, Infra{DNSServers: []string{"dns.example.com"}}, false},
+		{"rendezvous not control plane", nil, Infra{RendezvousIP: "192.168.1.99"}, false},
+		{"invalid pxe url", nil, Infra{PXEAssetURL: "192.168.1.4/pxe"}, false},
+		{"trust bundle", nil, Infra{TrustBundlePaths: []string{"certs/proxy.pem"}, TrustBundlePolicy: "Proxyonly"}, true},
+		{"empty trust bundle path", nil, Infra{TrustBundlePaths: []string{" "}}, false},
+		{"invalid trust bundle policy", nil, Infra{TrustBundlePolicy: "always"}, false},
+		{"ca bundle", nil, Infra{CABundle: "certs/proxy-ca.pem"}, true},
+		{"blank ca bundle", nil, Infra{CABundle: " "}, false},
+		{"bootstrap node", nil, Infra{BootstrapNode: "master-0"}, true},
+		{"bootstrap node not control plane", nil, Infra{BootstrapNode: "worker-0"}, false},
+		{"bootstrap node matches rendezvous", nil, Infra{BootstrapNode: "master-0", RendezvousIP: "192.168.1.10"}, true},
+		{"bootstrap node conflicts with rendezvous", nil, Infra{BootstrapNode: "master-1", RendezvousIP: "192.168.1.10"}, false},
+		{"bastion disabled with bootstrap node", &disabled, Infra{DNSServers: []string{"10.0.0.53"}, LoadBalancer: "10.0.0.80", BootstrapNode: "master-0"}, true},
+		{"bastion disabled without infra", &disabled, Infra{}, false},
+		{"bastion disabled without load balancer", &disabled, Infra{DNSServers: []string{"10.0.0.53"}, RendezvousIP: "192.168.1.10"}, false},
+		{"bastion disabled without rendezvous", &disabled, Infra{DNSServers: []string{"10.0.0.53"}, LoadBalancer: "10.0.0.80"}, false},
+		{"bastion disabled", &disabled, Infra{
+			DNSServers:   []string{"10.0.0.53"},
+			LoadBalancer: "10.0.0.80",
+			RendezvousIP: "192.168.1.10",
+			PXEAssetURL:  "http://10.0.0.4:8080/pxe/demo",
+		}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Cluster.ControlPlane[0].IP = "192.168.1.10"
+		cfg.Bastion.Enabled = tt.enabled
+		cfg.Infra = tt.infra
+		if err := ValidateInfraConfig(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateInfraConfig() error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateBastionConfigDisabled(t *testing.T) {
+	disabled := false
+	cfg := NewDefaultConfig("demo")
+	cfg.Bastion.Enabled = &disabled
+	if err := ValidateBastionConfig(cfg); err == nil {
+		t.Error("ValidateBastionConfig() succeeded with bastion.enabled = false")
+	}
+}
+
+func TestGetTrustBundlePolicy(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if policy := cfg.GetTrustBundlePolicy(); policy != "" {
+		t.Errorf("GetTrustBundlePolicy() = %q, expected installer default without trust_bundle_paths", policy)
+	}
+	cfg.Infra.TrustBundlePaths = []string{"certs/proxy.pem"}
+	if policy := cfg.GetTrustBundlePolicy(); policy != TrustBundlePolicyAlways {
+		t.Errorf("GetTrustBundlePolicy() = %q, expected Always with trust_bundle_paths", policy)
+	}
+	cfg.Infra.TrustBundlePolicy = TrustBundlePolicyProxyOnly
+	if policy := cfg.GetTrustBundlePolicy(); policy != TrustBundlePolicyProxyOnly {
+		t.Errorf("GetTrustBundlePolicy() = %q, expected configured policy", policy)
+	}
+}
+
+func TestGetCABundlePath(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if path := cfg.GetCABundlePath("/work/demo"); path != "" {
+		t.Errorf("GetCABundlePath() = %q without ca_bundle", path)
+	}
+	cfg.Infra.CABundle = "certs/proxy-ca.pem"
+	if path := cfg.GetCABundlePath("/work/demo"); path != "/work/demo/certs/proxy-ca.pem" {
+		t.Errorf("GetCABundlePath() = %q, expected path relative to the cluster directory", path)
+	}
+	cfg.Infra.CABundle = "/etc/pki/proxy-ca.pem"
+	if path := cfg.GetCABundlePath("/work/demo"); path != "/etc/pki/proxy-ca.pem" {
+		t.Errorf("GetCABundlePath() = %q, expected absolute path unchanged", path)
+	}
+}
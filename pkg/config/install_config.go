@@ -0,0 +1,162 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+// install-config.yaml featureSet 的取值。非默认的特性集启用后集群无法升级
+const (
+	FeatureSetTechPreviewNoUpgrade = "TechPreviewNoUpgrade"
+	FeatureSetDevPreviewNoUpgrade  = "DevPreviewNoUpgrade"
+	FeatureSetCustomNoUpgrade      = "CustomNoUpgrade"
+)
+
+// BaselineCapabilitySetNone 不启用任何可选组件，只启用 additional_enabled_capabilities 中的组件
+const BaselineCapabilitySetNone = "None"
+
+// capabilityMinVersions 可选集群组件 (capability) 及其引入的 OpenShift 版本
+var capabilityMinVersions = map[string]string{
+	"baremetal":                  "4.11",
+	"marketplace":                "4.11",
+	"openshift-samples":          "4.11",
+	"Console":                    "4.12",
+	"Insights":                   "4.12",
+	"Storage":                    "4.12",
+	"CSISnapshot":                "4.12",
+	"NodeTuning":                 "4.13",
+	"MachineAPI":                 "4.14",
+	"Build":                      "4.14",
+	"DeploymentConfig":           "4.14",
+	"ImageRegistry":              "4.14",
+	"OperatorLifecycleManager":   "4.15",
+	"CloudCredential":            "4.15",
+	"Ingress":                    "4.16",
+	"CloudControllerManager":     "4.16",
+	"OperatorLifecycleManagerV1": "4.18",
+}
+
+// capabilityDependencies 启用时依赖其他组件的 capability
+var capabilityDependencies = map[string]string{
+	"baremetal":   "MachineAPI",
+	"marketplace": "OperatorLifecycleManager",
+}
+
+// baselineCapabilitySetPattern 按版本命名的基线集合，如 v4.14
+var baselineCapabilitySetPattern = regexp.MustCompile(`^v4\.(\d+)$`)
+
+// featureGatePattern CustomNoUpgrade 特性集中的特性开关，如 SomeFeature=true
+var featureGatePattern = regexp.MustCompile(`^[A-Za-z0-9]+=(true|false)$`)
+
+// InstallConfig install-config.yaml 中的可选安装配置，对应 [install_config]
+type InstallConfig struct {
+	// 特性集: TechPreviewNoUpgrade、DevPreviewNoUpgrade 或 CustomNoUpgrade，启用后集群无法升级
+	FeatureSet string `toml:"feature_set,omitempty"`
+	// feature_set = "CustomNoUpgrade" 时启用或禁用的特性，如 ["SomeFeature=true"]
+	FeatureGates []string `toml:"feature_gates,omitempty"`
+	// 可选集群组件，未配置时安装全部组件
+	Capabilities *Capabilities `toml:"capabilities,omitempty"`
+}
+
+// Capabilities 对应 install-config.yaml 的 capabilities
+type Capabilities struct {
+	// 基线集合: None、vCurrent 或 v4.x
+	BaselineCapabilitySet string `toml:"baseline_capability_set"`
+	// 在基线之外额外启用的组件，如 ["MachineAPI", "Ingress"]
+	AdditionalEnabledCapabilities []string `toml:"additional_enabled_capabilities,omitempty"`
+}
+
+// CapabilityEnabled 返回可选组件是否会被安装。未配置 capabilities 或基线不为 None 时视为已安装
+func (c *ClusterConfig) CapabilityEnabled(name string) bool {
+	caps := c.InstallConfig.Capabilities
+	if caps == nil || caps.BaselineCapabilitySet != BaselineCapabilitySetNone {
+		return true
+	}
+	for _, enabled := range caps.AdditionalEnabledCapabilities {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateInstallConfig 验证 [install_config] 的特性集和可选组件配置
+func ValidateInstallConfig(config *ClusterConfig) error {
+	ic := config.InstallConfig
+	switch ic.FeatureSet {
+	case "", FeatureSetTechPreviewNoUpgrade, FeatureSetDevPreviewNoUpgrade, FeatureSetCustomNoUpgrade:
+	default:
+		return i18n.Errorf("install_config.feature_set %q 不支持，支持: %s、%s、%s",
+			ic.FeatureSet, FeatureSetTechPreviewNoUpgrade, FeatureSetDevPreviewNoUpgrade, FeatureSetCustomNoUpgrade)
+	}
+	if len(ic.FeatureGates) > 0 && ic.FeatureSet != FeatureSetCustomNoUpgrade {
+		return i18n.Errorf("install_config.feature_gates 只能与 feature_set = %q 一起使用", FeatureSetCustomNoUpgrade)
+	}
+	for _, gate := range ic.FeatureGates {
+		if !featureGatePattern.MatchString(gate) {
+			return i18n.Errorf("install_config.feature_gates 中的 %q 格式无效，应为 <特性名称>=true 或 <特性名称>=false", gate)
+		}
+	}
+
+	caps := ic.Capabilities
+	if caps == nil {
+		return nil
+	}
+	version := config.ClusterInfo.OpenShiftVersion
+	if utils.CompareVersion(version, "4.11") < 0 {
+		return i18n.Errorf("install_config.capabilities 需要 OpenShift 4.11 及以上版本，当前为 %s", version)
+	}
+
+	switch baseline := caps.BaselineCapabilitySet; {
+	case baseline == BaselineCapabilitySetNone, baseline == "vCurrent":
+	case baselineCapabilitySetPattern.MatchString(baseline):
+		minor, _ := strconv.Atoi(baselineCapabilitySetPattern.FindStringSubmatch(baseline)[1])
+		if minor < 11 || utils.CompareVersion(version, "4."+strconv.Itoa(minor)) < 0 {
+			return i18n.Errorf("install_config.capabilities.baseline_capability_set %s 不适用于 OpenShift %s", baseline, version)
+		}
+	default:
+		return i18n.Errorf("install_config.capabilities.baseline_capability_set %q 无效，应为 None、vCurrent 或 v4.x", baseline)
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range caps.AdditionalEnabledCapabilities {
+		minVersion, ok := capabilityMinVersions[name]
+		if !ok {
+			return i18n.Errorf("install_config.capabilities.additional_enabled_capabilities 中的 %q 不是已知的组件，支持: %s", name, strings.Join(knownCapabilities(), "、"))
+		}
+		if utils.CompareVersion(version, minVersion) < 0 {
+			return i18n.Errorf("组件 %s 需要 OpenShift %s 及以上版本，当前为 %s", name, minVersion, version)
+		}
+		if enabled[name] {
+			return i18n.Errorf("install_config.capabilities.additional_enabled_capabilities 中的 %s 重复", name)
+		}
+		enabled[name] = true
+	}
+
+	// 基线为 None 时，依赖的组件也必须显式启用 (该版本中存在时)
+	if caps.BaselineCapabilitySet == BaselineCapabilitySetNone {
+		for name := range enabled {
+			dependency, ok := capabilityDependencies[name]
+			if !ok || enabled[dependency] || utils.CompareVersion(version, capabilityMinVersions[dependency]) < 0 {
+				continue
+			}
+			return i18n.Errorf("组件 %s 依赖 %s，请将其加入 install_config.capabilities.additional_enabled_capabilities", name, dependency)
+		}
+	}
+	return nil
+}
+
+// knownCapabilities 返回按名称排序的全部可选组件
+func knownCapabilities() []string {
+	names := make([]string, 0, len(capabilityMinVersions))
+	for name := range capabilityMinVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
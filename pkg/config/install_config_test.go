@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestValidateInstallConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		ic      InstallConfig
+		valid   bool
+	}{
+		{"default", "4.16.3", InstallConfig{}, true},
+		{"tech preview", "4.16.3", InstallConfig{FeatureSet: FeatureSetTechPreviewNoUpgrade}, true},
+		{"unknown feature set", "4.16.3", InstallConfig{FeatureSet: "Default"}, false},
+		{"custom feature gates", "4.16.3", InstallConfig{FeatureSet: FeatureSetCustomNoUpgrade, FeatureGates: []string{"SomeFeature=true"}}, true},
+		{"feature gates without custom", "4.16.3", InstallConfig{FeatureGates: []string{"SomeFeature=true"}}, false},
+		{"invalid feature gate", "4.16.3", InstallConfig{FeatureSet: FeatureSetCustomNoUpgrade, FeatureGates: []string{"SomeFeature"}}, false},
+		{"minimal edge", "4.16.3", InstallConfig{Capabilities: &Capabilities{
+			BaselineCapabilitySet:         "None",
+			AdditionalEnabledCapabilities: []string{"MachineAPI", "Ingress", "NodeTuning"},
+		}}, true},
+		{"versioned baseline", "4.16.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "v4.14"}}, true},
+		{"baseline newer than release", "4.14.10", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "v4.16"}}, false},
+		{"invalid baseline", "4.16.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "minimal"}}, false},
+		{"unknown capability", "4.16.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"Monitoring"}}}, false},
+		{"capability newer than release", "4.13.5", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"MachineAPI"}}}, false},
+		{"duplicate capability", "4.16.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"Console", "Console"}}}, false},
+		{"marketplace without OLM", "4.16.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"marketplace"}}}, false},
+		{"marketplace before OLM capability", "4.14.10", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"marketplace"}}}, true},
+		{"capabilities before 4.11", "4.10.3", InstallConfig{Capabilities: &Capabilities{BaselineCapabilitySet: "None"}}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = tt.version
+		cfg.InstallConfig = tt.ic
+		if err := ValidateInstallConfig(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateInstallConfig error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestCapabilityEnabled(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if !cfg.CapabilityEnabled("marketplace") {
+		t.Error("all capabilities should be enabled by default")
+	}
+	cfg.InstallConfig.Capabilities = &Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"OperatorLifecycleManager"}}
+	if cfg.CapabilityEnabled("marketplace") || !cfg.CapabilityEnabled("OperatorLifecycleManager") {
+		t.Error("only additional capabilities should be enabled with baseline None")
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"ocpack/pkg/i18n"
+)
+
+// CurrentConfigVersion 当前 config.toml 的格式版本
+// 未声明 config_version 的旧配置文件视为版本 0
+const CurrentConfigVersion = 1
+
+// configMigration 描述一次配置格式升级
+type configMigration struct {
+	toVersion   int
+	description string
+	migrate     func(raw map[string]interface{}) []string
+}
+
+// configMigrations 按版本顺序排列的升级步骤
+var configMigrations = []configMigration{
+	{
+		toVersion:   1,
+		description: "统一集群标识与网络配置位置",
+		migrate:     migrateToV1,
+	},
+}
+
+// MigrationResult 记录一次配置升级的结果
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Changes     []string
+	BackupPath  string
+	// Rewritten 为 true 时文件按升级后的内容重新生成，原有注释和键的顺序不保留 (见备份)，否则只在文件头部补充版本号
+	Rewritten bool
+}
+
+// Migrated 返回配置是否发生了升级
+func (r *MigrationResult) Migrated() bool {
+	return r != nil && r.FromVersion != r.ToVersion
+}
+
+// MigrateConfigData 将原始配置内容升级到当前版本，返回升级后的内容和变更说明
+func MigrateConfigData(data []byte) ([]byte, *MigrationResult, error) {
+	raw := map[string]interface{}{}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, nil, i18n.Errorf("解析配置文件失败: %w", err)
+	}
+
+	fromVersion, err := rawConfigVersion(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fromVersion > CurrentConfigVersion {
+		return nil, nil, i18n.Errorf("配置文件版本 %d 高于当前 ocpack 支持的版本 %d，请升级 ocpack", fromVersion, CurrentConfigVersion)
+	}
+
+	result := &MigrationResult{FromVersion: fromVersion, ToVersion: fromVersion}
+	if fromVersion == CurrentConfigVersion {
+		return data, result, nil
+	}
+
+	for _, m := range configMigrations {
+		if m.toVersion <= fromVersion {
+			continue
+		}
+		for _, change := range m.migrate(raw) {
+			result.Changes = append(result.Changes, fmt.Sprintf("[v%d %s] %s", m.toVersion, i18n.T(m.description), change))
+		}
+		result.ToVersion = m.toVersion
+	}
+
+	// 没有实际内容变更时只在文件头部补充版本号，保留原有注释和格式
+	if len(result.Changes) == 0 {
+		result.Changes = append(result.Changes, i18n.Sprintf("已添加 config_version = %d", result.ToVersion))
+		header := fmt.Sprintf("config_version = %d\n\n", result.ToVersion)
+		return append([]byte(header), data...), result, nil
+	}
+	raw["config_version"] = int64(result.ToVersion)
+	result.Rewritten = true
+
+	migrated, err := toml.Marshal(raw)
+	if err != nil {
+		return nil, nil, i18n.Errorf("序列化升级后的配置失败: %w", err)
+	}
+	return migrated, result, nil
+}
+
+// MigrateConfigFile 检查并升级配置文件 (ocpack migrate-config)。需要升级时先在同目录下保存原文件的备份，
+// dryRun 为 true 时只返回变更说明，不修改文件
+func MigrateConfigFile(filePath string, dryRun bool) (*MigrationResult, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, i18n.Errorf("读取配置文件失败: %w", err)
+	}
+	migrated, result, err := MigrateConfigData(data)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Migrated() || dryRun {
+		return result, nil
+	}
+
+	result.BackupPath = fmt.Sprintf("%s.bak-%s", filePath, time.Now().Format("20060102150405"))
+	if err := os.WriteFile(result.BackupPath, data, 0644); err != nil {
+		return nil, i18n.Errorf("备份配置文件失败: %w", err)
+	}
+	if err := os.WriteFile(filePath, migrated, 0644); err != nil {
+		return nil, i18n.Errorf("写入升级后的配置文件失败: %w", err)
+	}
+	return result, nil
+}
+
+// rawConfigVersion 读取原始配置中的 config_version
+func rawConfigVersion(raw map[string]interface{}) (int, error) {
+	value, ok := raw["config_version"]
+	if !ok {
+		return 0, nil
+	}
+	version, ok := value.(int64)
+	if !ok || version < 0 {
+		return 0, i18n.Errorf("config_version 必须是非负整数，当前值: %v", value)
+	}
+	return int(version), nil
+}
+
+// rawTable 获取指定名称的表，create 为 true 时不存在则创建
+func rawTable(parent map[string]interface{}, name string, create bool) map[string]interface{} {
+	if table, ok := parent[name].(map[string]interface{}); ok {
+		return table
+	}
+	if !create {
+		return nil
+	}
+	table := map[string]interface{}{}
+	parent[name] = table
+	return table
+}
+
+// migrateToV1 升级无版本号的旧配置:
+//   - [cluster_info] name 重命名为 cluster_id
+//   - 顶层 [network] 移动到 [cluster.network]
+//   - 补全 [download] local_path
+func migrateToV1(raw map[string]interface{}) []string {
+	var changes []string
+
+	if clusterInfo := rawTable(raw, "cluster_info", false); clusterInfo != nil {
+		if name, ok := clusterInfo["name"]; ok {
+			if _, exists := clusterInfo["cluster_id"]; !exists {
+				clusterInfo["cluster_id"] = name
+				changes = append(changes, i18n.T("[cluster_info] name 已重命名为 cluster_id"))
+			} else {
+				changes = append(changes, i18n.T("[cluster_info] 已移除与 cluster_id 重复的 name"))
+			}
+			delete(clusterInfo, "name")
+		}
+	}
+
+	if network := rawTable(raw, "network", false); network != nil {
+		cluster := rawTable(raw, "cluster", true)
+		target := rawTable(cluster, "network", true)
+		for key, value := range network {
+			if _, exists := target[key]; !exists {
+				target[key] = value
+			}
+		}
+		delete(raw, "network")
+		changes = append(changes, i18n.T("顶层 [network] 已移动到 [cluster.network]"))
+	}
+
+	download := rawTable(raw, "download", true)
+	if path, _ := download["local_path"].(string); path == "" {
+		download["local_path"] = "downloads"
+		changes = append(changes, i18n.T(`[download] 已补全 local_path = "downloads"`))
+	}
+
+	return changes
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestMigrateConfigDataLegacyKeys(t *testing.T) {
+	legacy := `
+[cluster_info]
+name = "demo"
+domain = "example.com"
+openshift_version = "4.16.0"
+
+[network]
+cluster_network = "10.128.0.0/14"
+service_network = "172.30.0.0/16"
+machine_network = "192.168.1.0/24"
+`
+	migrated, result, err := MigrateConfigData([]byte(legacy))
+	if err != nil {
+		t.Fatalf("MigrateConfigData() error = %v", err)
+	}
+	if result.FromVersion != 0 || result.ToVersion != CurrentConfigVersion {
+		t.Errorf("version = %d -> %d, expected 0 -> %d", result.FromVersion, result.ToVersion, CurrentConfigVersion)
+	}
+	if len(result.Changes) != 3 {
+		t.Errorf("expected 3 changes, got %d: %v", len(result.Changes), result.Changes)
+	}
+
+	cfg := &ClusterConfig{}
+	if err := toml.Unmarshal(migrated, cfg); err != nil {
+		t.Fatalf("unmarshal migrated config: %v", err)
+	}
+	if cfg.ConfigVersion != CurrentConfigVersion {
+		t.Errorf("ConfigVersion = %d, expected %d", cfg.ConfigVersion, CurrentConfigVersion)
+	}
+	if cfg.ClusterInfo.ClusterID != "demo" {
+		t.Errorf("ClusterID = %q, expected %q", cfg.ClusterInfo.ClusterID, "demo")
+	}
+	if cfg.Cluster.Network.MachineNetwork != "192.168.1.0/24" {
+		t.Errorf("MachineNetwork = %q, expected %q", cfg.Cluster.Network.MachineNetwork, "192.168.1.0/24")
+	}
+	if cfg.Download.LocalPath != "downloads" {
+		t.Errorf("Download.LocalPath = %q, expected %q", cfg.Download.LocalPath, "downloads")
+	}
+}
+
+func TestMigrateConfigDataKeepsComments(t *testing.T) {
+	legacy := "# 用户注释\n[cluster_info]\ncluster_id = \"demo\"\n\n[download]\nlocal_path = \"downloads\"\n"
+
+	migrated, result, err := MigrateConfigData([]byte(legacy))
+	if err != nil {
+		t.Fatalf("MigrateConfigData() error = %v", err)
+	}
+	if !result.Migrated() {
+		t.Fatal("expected config to be migrated")
+	}
+	if !strings.HasPrefix(string(migrated), "config_version = 1\n") || !strings.Contains(string(migrated), "# 用户注释") {
+		t.Errorf("unexpected migrated content:\n%s", migrated)
+	}
+}
+
+func TestMigrateConfigDataRejectsNewerVersion(t *testing.T) {
+	if _, _, err := MigrateConfigData([]byte("config_version = 99\n")); err == nil {
+		t.Error("expected error for newer config_version")
+	}
+}
+
+func TestLoadConfigMigratesInMemory(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	legacy := "# 用户注释\n[cluster_info]\nname = \"demo\"\n"
+	if err := os.WriteFile(configPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cfg, err := LoadConfigTo(&out, configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigTo() error = %v", err)
+	}
+	if cfg.ClusterInfo.ClusterID != "demo" {
+		t.Errorf("ClusterID = %q, expected %q", cfg.ClusterInfo.ClusterID, "demo")
+	}
+	if !strings.Contains(out.String(), "ocpack migrate-config") {
+		t.Errorf("expected a migrate-config hint, got %q", out.String())
+	}
+	// 加载配置不修改文件
+	if data, _ := os.ReadFile(configPath); string(data) != legacy {
+		t.Errorf("LoadConfigTo() rewrote config.toml:\n%s", data)
+	}
+	if backups, _ := filepath.Glob(configPath + ".bak-*"); len(backups) != 0 {
+		t.Errorf("LoadConfigTo() wrote backups: %v", backups)
+	}
+}
+
+func TestMigrateConfigFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	legacy := "[cluster_info]\nname = \"demo\"\n"
+	if err := os.WriteFile(configPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// dry-run 只返回变更说明
+	result, err := MigrateConfigFile(configPath, true)
+	if err != nil || !result.Migrated() || !result.Rewritten || result.BackupPath != "" {
+		t.Fatalf("MigrateConfigFile(dry-run) = %+v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != legacy {
+		t.Errorf("dry-run rewrote config.toml:\n%s", data)
+	}
+
+	result, err = MigrateConfigFile(configPath, false)
+	if err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+	backup, err := os.ReadFile(result.BackupPath)
+	if err != nil || string(backup) != legacy {
+		t.Errorf("backup content = %q, %v, expected %q", backup, err, legacy)
+	}
+	var out bytes.Buffer
+	if _, err := LoadConfigTo(&out, configPath); err != nil || out.Len() != 0 {
+		t.Errorf("migrated config should load without hints: %q, %v", out.String(), err)
+	}
+
+	// 再次执行时不应重复升级
+	result, err = MigrateConfigFile(configPath, false)
+	if err != nil {
+		t.Fatalf("MigrateConfigFile() error = %v", err)
+	}
+	if result.Migrated() {
+		t.Error("expected no migration on already migrated config")
+	}
+}
+
+func TestLoadConfigErrorCategory(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadConfig(filepath.Join(dir, "missing.toml")); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("missing file: category = %s, expected config", clierr.CategoryOf(err))
+	}
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("[cluster_info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("invalid toml: category = %s, expected config", clierr.CategoryOf(err))
+	}
+	if err := ValidateConfig(&ClusterConfig{}); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("ValidateConfig: category = %s, expected config", clierr.CategoryOf(err))
+	}
+}
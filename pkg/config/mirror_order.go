@@ -0,0 +1,31 @@
+package config
+
+import (
+	"slices"
+
+	"ocpack/pkg/i18n"
+)
+
+// 镜像分组，用于 [save_image] mirror_order 和 save-image/load-image --only
+const (
+	MirrorGroupRelease    = "release"
+	MirrorGroupOperators  = "operators"
+	MirrorGroupAdditional = "additional"
+	MirrorGroupHelm       = "helm"
+)
+
+// MirrorGroups 全部镜像分组，按默认的复制顺序排列
+var MirrorGroups = []string{MirrorGroupRelease, MirrorGroupOperators, MirrorGroupAdditional, MirrorGroupHelm}
+
+// ValidateMirrorGroups 验证镜像分组列表，只能包含 MirrorGroups 中的分组且不能重复，key 为出错时显示的配置项或参数名
+func ValidateMirrorGroups(groups []string, key string) error {
+	for i, group := range groups {
+		if !slices.Contains(MirrorGroups, group) {
+			return i18n.Errorf("%s 中的分组 %q 无效，可选值: %v", key, group, MirrorGroups)
+		}
+		if slices.Contains(groups[:i], group) {
+			return i18n.Errorf("%s 中的分组 %q 重复", key, group)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestValidateMirrorGroups(t *testing.T) {
+	tests := []struct {
+		groups []string
+		valid  bool
+	}{
+		{nil, true},
+		{[]string{"release", "operators", "additional", "helm"}, true},
+		{[]string{"operators"}, true},
+		{[]string{"platform"}, false},
+		{[]string{"release", "release"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateMirrorGroups(tt.groups, "save_image.mirror_order"); (err == nil) != tt.valid {
+			t.Errorf("ValidateMirrorGroups(%v) error = %v, expected valid = %t", tt.groups, err, tt.valid)
+		}
+	}
+}
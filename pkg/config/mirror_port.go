@@ -0,0 +1,20 @@
+package config
+
+import "ocpack/pkg/i18n"
+
+// DefaultLocalStoragePort oc-mirror 本地缓存 registry 的默认端口
+const DefaultLocalStoragePort = 55000
+
+// GetLocalStoragePort 返回 [save_image] local_storage_port，未配置时返回 0，由调用方自动选择端口
+func (c *ClusterConfig) GetLocalStoragePort() int {
+	return c.SaveImage.LocalStoragePort
+}
+
+// ValidateLocalStoragePort 验证 [save_image] local_storage_port
+func ValidateLocalStoragePort(config *ClusterConfig) error {
+	port := config.SaveImage.LocalStoragePort
+	if port != 0 && (port < 1024 || port > 65535) {
+		return i18n.Errorf("save_image.local_storage_port %d 无效，必须在 1024-65535 之间", port)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestValidateLocalStoragePort(t *testing.T) {
+	tests := []struct {
+		port  int
+		valid bool
+	}{
+		{0, true},
+		{DefaultLocalStoragePort, true},
+		{1024, true},
+		{65535, true},
+		{80, false},
+		{70000, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.LocalStoragePort = tt.port
+		if err := ValidateLocalStoragePort(cfg); (err == nil) != tt.valid {
+			t.Errorf("port %d: ValidateLocalStoragePort error = %v, expected valid = %t", tt.port, err, tt.valid)
+		}
+	}
+}
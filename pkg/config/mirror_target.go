@@ -0,0 +1,90 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// releaseRepositoryPath oc-mirror 推送 release 镜像的仓库路径 (相对于目标命名空间)
+const releaseRepositoryPath = "openshift/release-images"
+
+// releaseComponentsRepositoryPath oc-mirror 推送 release 组件镜像的仓库路径 (相对于目标命名空间)
+const releaseComponentsRepositoryPath = "openshift/release"
+
+// namespaceSegmentPattern 仓库路径的每一段只能包含小写字母、数字和分隔符 '.'、'_'、'-'
+var namespaceSegmentPattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// GetMirrorNamespace 返回镜像在私有仓库中的命名空间前缀 (不含首尾的 '/')，未配置时为空
+func (c *ClusterConfig) GetMirrorNamespace() string {
+	return strings.Trim(c.SaveImage.TargetNamespace, "/")
+}
+
+// GetMirrorDestination 返回 oc-mirror 推送镜像的目标地址，如 registry.demo.example.com:8443/redhat-mirror。
+// release、Operator 目录和额外镜像都会推送到该命名空间下
+func (c *ClusterConfig) GetMirrorDestination() string {
+	if namespace := c.GetMirrorNamespace(); namespace != "" {
+		return c.GetRegistryHost() + "/" + namespace
+	}
+	return c.GetRegistryHost()
+}
+
+// GetReleaseRepository 返回私有仓库中 release 镜像的仓库地址，如 registry.demo.example.com:8443/openshift/release-images
+func (c *ClusterConfig) GetReleaseRepository() string {
+	return c.GetMirrorDestination() + "/" + releaseRepositoryPath
+}
+
+// GetReleaseComponentsRepository 返回私有仓库中 release 组件镜像的仓库地址，如 registry.demo.example.com:8443/openshift/release
+func (c *ClusterConfig) GetReleaseComponentsRepository() string {
+	return c.GetMirrorDestination() + "/" + releaseComponentsRepositoryPath
+}
+
+// MirroredRepositories 返回 load-image 推送的仓库路径 (含 target_namespace，不含 registry 主机)。
+// images 非空时 (--images-file) 只包含这些镜像的仓库，否则包含 release、启用的 Operator 目录和附加镜像的仓库。
+// Operator 的 bundle 和相关镜像由 oc-mirror 解析目录后才能确定，不在其中
+func (c *ClusterConfig) MirroredRepositories(images []string) []string {
+	var paths []string
+	if len(images) == 0 {
+		paths = append(paths, releaseRepositoryPath, releaseComponentsRepositoryPath)
+		if c.OperatorsEnabled() {
+			for _, catalog := range c.GetOperatorCatalogs() {
+				path, _ := catalog.MirroredRepository()
+				paths = append(paths, path)
+			}
+		}
+		images = c.GetAdditionalImages()
+	}
+	for _, image := range images {
+		path, _ := splitImageReference(image)
+		paths = append(paths, path)
+	}
+
+	namespace := c.GetMirrorNamespace()
+	seen := make(map[string]bool)
+	var repositories []string
+	for _, path := range paths {
+		if namespace != "" {
+			path = namespace + "/" + path
+		}
+		if !seen[path] {
+			seen[path] = true
+			repositories = append(repositories, path)
+		}
+	}
+	return repositories
+}
+
+// ValidateTargetNamespace 验证 [save_image] target_namespace，必须是合法的仓库路径，如 redhat-mirror 或 mirror/ocp
+func ValidateTargetNamespace(config *ClusterConfig) error {
+	namespace := config.GetMirrorNamespace()
+	if namespace == "" {
+		return nil
+	}
+	for _, segment := range strings.Split(namespace, "/") {
+		if !namespaceSegmentPattern.MatchString(segment) {
+			return i18n.Errorf("save_image.target_namespace %q 无效，每一段只能包含小写字母、数字和 '.'、'_'、'-'", config.SaveImage.TargetNamespace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMirrorDestination(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if got := cfg.GetReleaseRepository(); got != "registry.demo.example.com:8443/openshift/release-images" {
+		t.Errorf("GetReleaseRepository() = %s", got)
+	}
+
+	cfg.SaveImage.TargetNamespace = "/redhat-mirror/"
+	if got := cfg.GetMirrorDestination(); got != "registry.demo.example.com:8443/redhat-mirror" {
+		t.Errorf("GetMirrorDestination() = %s", got)
+	}
+	if got := cfg.GetReleaseRepository(); got != "registry.demo.example.com:8443/redhat-mirror/openshift/release-images" {
+		t.Errorf("GetReleaseRepository() = %s", got)
+	}
+}
+
+func TestValidateTargetNamespace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		valid     bool
+	}{
+		{"", true},
+		{"redhat-mirror", true},
+		{"mirror/ocp_4.16", true},
+		{"Mirror", false},
+		{"mirror//ocp", false},
+		{"mirror:v1", false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.TargetNamespace = tt.namespace
+		if err := ValidateTargetNamespace(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateTargetNamespace(%q) error = %v, expected valid = %t", tt.namespace, err, tt.valid)
+		}
+	}
+}
+
+func TestMirroredRepositories(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.SaveImage.IncludeOperators = true
+	cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{{Catalog: "redhat", Ops: []string{"cluster-logging"}}}
+	cfg.SaveImage.AdditionalImages = []string{"quay.io/acme/app:v1", "docker.io/library/nginx@sha256:abc", "quay.io/acme/app:v2"}
+
+	want := []string{
+		"openshift/release-images",
+		"openshift/release",
+		"redhat/redhat-operator-index",
+		"acme/app",
+		"library/nginx",
+	}
+	if got := cfg.MirroredRepositories(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("MirroredRepositories(nil) = %v, want %v", got, want)
+	}
+
+	cfg.SaveImage.TargetNamespace = "redhat-mirror"
+	want = []string{"redhat-mirror/team/api"}
+	if got := cfg.MirroredRepositories([]string{"registry.example.com/team/api:v3"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("MirroredRepositories(images) = %v, want %v", got, want)
+	}
+}
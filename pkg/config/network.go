@@ -0,0 +1,57 @@
+package config
+
+import (
+	"net"
+
+	"ocpack/pkg/i18n"
+)
+
+// DefaultHostPrefix 未配置 cluster.network.host_prefix 时每个节点分配的 Pod 子网前缀长度
+const DefaultHostPrefix = 23
+
+// GetHostPrefix 返回每个节点分配的 Pod 子网前缀长度
+func (c *ClusterConfig) GetHostPrefix() int {
+	if c.Cluster.Network.HostPrefix != 0 {
+		return c.Cluster.Network.HostPrefix
+	}
+	return DefaultHostPrefix
+}
+
+// ValidateNetworkConfig 验证集群网络、服务网络和机器网络均为有效的 CIDR 且互不重叠，
+// host_prefix 需要大于集群网络的前缀长度，使集群网络能够划分出多个节点子网
+func ValidateNetworkConfig(config *ClusterConfig) error {
+	network := config.Cluster.Network
+	networks := []struct {
+		key   string
+		value string
+		ipNet *net.IPNet
+	}{
+		{key: "cluster_network", value: network.ClusterNetwork},
+		{key: "service_network", value: network.ServiceNetwork},
+		{key: "machine_network", value: network.MachineNetwork},
+	}
+	for i := range networks {
+		_, ipNet, err := net.ParseCIDR(networks[i].value)
+		if err != nil {
+			return i18n.Errorf("cluster.network.%s %q 不是有效的 CIDR", networks[i].key, networks[i].value)
+		}
+		networks[i].ipNet = ipNet
+	}
+
+	for i := range networks {
+		for j := i + 1; j < len(networks); j++ {
+			if networks[i].ipNet.Contains(networks[j].ipNet.IP) || networks[j].ipNet.Contains(networks[i].ipNet.IP) {
+				return i18n.Errorf("cluster.network.%s %s 与 %s %s 重叠",
+					networks[i].key, networks[i].value, networks[j].key, networks[j].value)
+			}
+		}
+	}
+
+	clusterPrefix, bits := networks[0].ipNet.Mask.Size()
+	hostPrefix := config.GetHostPrefix()
+	if hostPrefix <= clusterPrefix || hostPrefix > bits {
+		return i18n.Errorf("cluster.network.host_prefix %d 无效，需要大于 cluster_network 的前缀长度 %d 且不超过 %d",
+			hostPrefix, clusterPrefix, bits)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestValidateNetworkConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cluster    string
+		service    string
+		machine    string
+		hostPrefix int
+		valid      bool
+	}{
+		{"defaults", "10.128.0.0/14", "172.30.0.0/16", "192.168.1.0/24", 0, true},
+		{"custom host prefix", "10.128.0.0/14", "172.30.0.0/16", "192.168.1.0/24", 24, true},
+		{"invalid cidr", "10.128.0.0", "172.30.0.0/16", "192.168.1.0/24", 0, false},
+		{"cluster overlaps machine", "10.0.0.0/8", "172.30.0.0/16", "10.1.0.0/24", 0, false},
+		{"service overlaps cluster", "10.128.0.0/14", "10.130.0.0/16", "192.168.1.0/24", 0, false},
+		{"machine contains service", "10.128.0.0/14", "172.30.0.0/16", "172.16.0.0/12", 0, false},
+		{"host prefix too small", "10.128.0.0/14", "172.30.0.0/16", "192.168.1.0/24", 14, false},
+		{"host prefix too large", "10.128.0.0/14", "172.30.0.0/16", "192.168.1.0/24", 33, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Cluster.Network.ClusterNetwork = tt.cluster
+		cfg.Cluster.Network.ServiceNetwork = tt.service
+		cfg.Cluster.Network.MachineNetwork = tt.machine
+		cfg.Cluster.Network.HostPrefix = tt.hostPrefix
+		if err := ValidateNetworkConfig(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateNetworkConfig() error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestGetHostPrefix(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if prefix := cfg.GetHostPrefix(); prefix != DefaultHostPrefix {
+		t.Errorf("GetHostPrefix() = %d, expected %d", prefix, DefaultHostPrefix)
+	}
+	cfg.Cluster.Network.HostPrefix = 25
+	if prefix := cfg.GetHostPrefix(); prefix != 25 {
+		t.Errorf("GetHostPrefix() = %d, expected 25", prefix)
+	}
+}
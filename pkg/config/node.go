@@ -0,0 +1,80 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Node 集群节点配置，对应 [[cluster.control_plane]] 和 [[cluster.worker]]。
+// bmc_address、serial、location 和 console_url 为可选的资产信息，只用于 inventory 导出，不影响安装；
+// cpu、memory_gb 和 disk_gb 为可选的节点规格，用于检查是否满足 OpenShift 的最低要求 (见 CheckNodeSizing)；
+// dhcp = true 时 agent-config.yaml 中不生成该节点的 networkConfig，由 DHCP 分配地址 (见 ValidateDHCPNodes)；
+// root_device、kernel_args 和 console 为节点单独的启动和安装磁盘设置 (见 node_boot.go)
+type Node struct {
+	Name       string   `toml:"name"`
+	IP         string   `toml:"ip"`
+	MAC        string   `toml:"mac"`
+	DHCP       bool     `toml:"dhcp,omitempty"`        // 可选，通过 DHCP 获取地址，此时 ip 可为空或填写 DHCP 保留地址
+	BMCAddress string   `toml:"bmc_address,omitempty"` // 可选，BMC (iDRAC/iLO/IPMI) 地址
+	Serial     string   `toml:"serial,omitempty"`      // 可选，服务器序列号
+	Location   string   `toml:"location,omitempty"`    // 可选，机房位置，如 "DC1/R05/U12"
+	ConsoleURL string   `toml:"console_url,omitempty"` // 可选，远程控制台地址 (http/https)
+	CPU        int      `toml:"cpu,omitempty"`         // 可选，vCPU 数量
+	MemoryGB   int      `toml:"memory_gb,omitempty"`   // 可选，内存 (GB)
+	DiskGB     int      `toml:"disk_gb,omitempty"`     // 可选，安装磁盘容量 (GB)
+	RootDevice string   `toml:"root_device,omitempty"` // 可选，安装磁盘，如 "/dev/disk/by-path/pci-0000:03:00.0-scsi-0:2:0:0"
+	KernelArgs []string `toml:"kernel_args,omitempty"` // 可选，启动 agent 时追加的内核参数
+	Console    string   `toml:"console,omitempty"`     // 可选，启动 agent 时使用的控制台，如 "ttyS0,115200n8"
+	Pool       string   `toml:"pool,omitempty"`        // 可选，worker 节点所属的计算节点池，见 [[cluster.compute_pool]]
+}
+
+// ValidateNodeMetadata 验证节点的可选资产信息和规格
+func ValidateNodeMetadata(config *ClusterConfig) error {
+	check := func(role string, i int, node Node) error {
+		if node.CPU < 0 || node.MemoryGB < 0 || node.DiskGB < 0 {
+			return i18n.Errorf("%s节点[%d] %s 的 cpu、memory_gb 和 disk_gb 不能为负数", role, i, node.Name)
+		}
+		if strings.ContainsAny(node.BMCAddress, " \t") {
+			return i18n.Errorf("%s节点[%d] %s 的 bmc_address %q 不能包含空白字符", role, i, node.Name, node.BMCAddress)
+		}
+		if node.ConsoleURL != "" {
+			u, err := url.Parse(node.ConsoleURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return i18n.Errorf("%s节点[%d] %s 的 console_url %q 必须是 http 或 https 地址", role, i, node.Name, node.ConsoleURL)
+			}
+		}
+		return nil
+	}
+
+	for i, node := range config.Cluster.ControlPlane {
+		if err := check("control Plane", i, node); err != nil {
+			return err
+		}
+	}
+	for i, node := range config.Cluster.Worker {
+		if err := check("worker", i, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateDHCPNodes 验证 dhcp = true 的节点。rendezvous 节点在安装开始前就要提供服务，必须使用静态 IP；
+// 未配置 ip 的 DHCP 节点不会生成 Bastion DNS 记录和 HAProxy 后端
+func ValidateDHCPNodes(config *ClusterConfig) error {
+	if bootstrapNode := config.Infra.BootstrapNode; bootstrapNode != "" {
+		if node := config.findControlPlane(func(n Node) bool { return n.Name == bootstrapNode }); node != nil && node.DHCP {
+			return i18n.Errorf("infra.bootstrap_node %s 是 DHCP 节点，rendezvous 节点必须使用静态 IP", bootstrapNode)
+		}
+	}
+	if config.Infra.RendezvousIP == "" && config.Infra.BootstrapNode == "" &&
+		config.findControlPlane(func(n Node) bool { return !n.DHCP }) == nil {
+		return i18n.Errorf("所有 Control Plane 节点都配置了 dhcp = true，至少需要一个静态 IP 的 Control Plane 节点作为 rendezvous 节点")
+	}
+	if node := config.GetRendezvousNode(); node != nil && node.DHCP {
+		return i18n.Errorf("rendezvous IP %s 属于 DHCP 节点 %s，rendezvous 节点必须使用静态 IP", node.IP, node.Name)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// macPattern agent-config.yaml 和 PXE 配置使用的 MAC 地址格式，如 52:54:00:aa:bb:01
+var macPattern = regexp.MustCompile(`^[0-9A-Fa-f]{2}(:[0-9A-Fa-f]{2}){5}$`)
+
+// ValidateNodeAddresses 验证节点的 MAC 和 IP：MAC 格式正确且不重复，IP 有效、不重复且属于 machine_network，
+// Bastion 和 Registry 的 IP 不能与节点相同。返回全部问题而不是第一个，便于一次修正
+func ValidateNodeAddresses(config *ClusterConfig) error {
+	var errs []error
+	_, machineNet, err := net.ParseCIDR(config.Cluster.Network.MachineNetwork)
+	if err != nil {
+		machineNet = nil
+	}
+
+	macs := make(map[string]string)
+	ips := make(map[string]string)
+	check := func(role string, i int, node Node) {
+		label := i18n.Sprintf("%s节点[%d] %s", role, i, node.Name)
+		if node.MAC != "" {
+			if !macPattern.MatchString(node.MAC) {
+				errs = append(errs, i18n.Errorf("%s 的MAC地址 %q 格式无效，应为 xx:xx:xx:xx:xx:xx", label, node.MAC))
+			} else if other, ok := macs[strings.ToLower(node.MAC)]; ok {
+				errs = append(errs, i18n.Errorf("%s 的MAC地址 %s 与 %s 重复", label, node.MAC, other))
+			} else {
+				macs[strings.ToLower(node.MAC)] = label
+			}
+		}
+
+		if node.IP == "" {
+			return
+		}
+		ip := net.ParseIP(node.IP)
+		if ip == nil {
+			errs = append(errs, i18n.Errorf("%s 的IP %q 无效", label, node.IP))
+			return
+		}
+		if other, ok := ips[ip.String()]; ok {
+			errs = append(errs, i18n.Errorf("%s 的IP %s 与 %s 重复", label, node.IP, other))
+		} else {
+			ips[ip.String()] = label
+		}
+		if machineNet != nil && !machineNet.Contains(ip) {
+			errs = append(errs, i18n.Errorf("%s 的IP %s 不在 cluster.network.machine_network %s 中", label, node.IP, config.Cluster.Network.MachineNetwork))
+		}
+	}
+	for i, node := range config.Cluster.ControlPlane {
+		check("control Plane", i, node)
+	}
+	for i, node := range config.Cluster.Worker {
+		check("worker", i, node)
+	}
+
+	// Bastion 和 Registry 可以是同一台主机，但不能与集群节点共用地址
+	hosts := []struct{ key, ip string }{{"registry.ip", config.Registry.IP}}
+	if config.BastionEnabled() {
+		hosts = append([]struct{ key, ip string }{{"bastion.ip", config.Bastion.IP}}, hosts...)
+	}
+	for _, host := range hosts {
+		if host.ip == "" {
+			continue
+		}
+		ip := net.ParseIP(host.ip)
+		if ip == nil {
+			errs = append(errs, i18n.Errorf("%s %q 无效", host.key, host.ip))
+			continue
+		}
+		if node, ok := ips[ip.String()]; ok {
+			errs = append(errs, i18n.Errorf("%s %s 与 %s 的IP相同", host.key, host.ip, node))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func addressConfig() *ClusterConfig {
+	cfg := NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.10"
+	cfg.Registry.IP = "192.168.1.11"
+	cfg.Cluster.ControlPlane = []Node{
+		{Name: "master-0", IP: "192.168.1.21", MAC: "52:54:00:aa:bb:01"},
+		{Name: "master-1", IP: "192.168.1.22", MAC: "52:54:00:aa:bb:02"},
+		{Name: "master-2", IP: "192.168.1.23", MAC: "52:54:00:AA:BB:03"},
+	}
+	cfg.Cluster.Worker = []Node{
+		{Name: "worker-0", IP: "192.168.1.31", MAC: "52:54:00:aa:bb:11"},
+		{Name: "worker-1", MAC: "52:54:00:aa:bb:12", DHCP: true},
+	}
+	return cfg
+}
+
+func TestValidateNodeAddresses(t *testing.T) {
+	if err := ValidateNodeAddresses(addressConfig()); err != nil {
+		t.Fatalf("ValidateNodeAddresses() error = %v", err)
+	}
+
+	// 多个问题一次全部报告
+	cfg := addressConfig()
+	cfg.Cluster.ControlPlane[1].MAC = "52-54-00-aa-bb-02"
+	cfg.Cluster.ControlPlane[2].MAC = "52:54:00:aa:bb:01"
+	cfg.Cluster.Worker[0].IP = "192.168.1.21"
+	cfg.Cluster.Worker[1].IP = "10.0.0.5"
+	cfg.Registry.IP = "192.168.1.22"
+	cfg.Bastion.IP = "192.168.1"
+
+	err := ValidateNodeAddresses(cfg)
+	if err == nil {
+		t.Fatal("ValidateNodeAddresses() expected error")
+	}
+	for _, want := range []string{
+		`control Plane节点[1] master-1 的MAC地址 "52-54-00-aa-bb-02" 格式无效`,
+		"control Plane节点[2] master-2 的MAC地址 52:54:00:aa:bb:01 与 control Plane节点[0] master-0 重复",
+		"worker节点[0] worker-0 的IP 192.168.1.21 与 control Plane节点[0] master-0 重复",
+		"worker节点[1] worker-1 的IP 10.0.0.5 不在 cluster.network.machine_network 192.168.1.0/24 中",
+		"registry.ip 192.168.1.22 与 control Plane节点[1] master-1 的IP相同",
+		`bastion.ip "192.168.1" 无效`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+	if lines := strings.Count(err.Error(), "\n") + 1; lines != 6 {
+		t.Errorf("expected 6 problems, got %d:\n%v", lines, err)
+	}
+
+	// 未启用 Bastion 时不检查 bastion.ip
+	cfg = addressConfig()
+	disabled := false
+	cfg.Bastion.Enabled = &disabled
+	cfg.Bastion.IP = "192.168.1.21"
+	if err := ValidateNodeAddresses(cfg); err != nil {
+		t.Errorf("ValidateNodeAddresses() error = %v, bastion is disabled", err)
+	}
+}
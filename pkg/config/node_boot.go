@@ -0,0 +1,84 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// consolePattern 控制台设备和可选的串口参数，如 tty0、ttyS0,115200n8
+var consolePattern = regexp.MustCompile(`^tty[A-Za-z0-9]+(,[0-9]+[noe]?[5-8]?)?$`)
+
+// BootProfile 启动参数相同的一组节点。agent ISO 对所有节点相同，generate-iso 为每组节点生成追加了
+// 这些内核参数的 ISO 副本，PXE 则按节点 MAC 在 iPXE 脚本中追加
+type BootProfile struct {
+	Name       string // 组内第一个节点的名称，用于 ISO 文件名
+	KernelArgs []string
+	Nodes      []Node
+}
+
+// BootKernelArgs 返回节点启动 agent 时追加的内核参数，console 转换为 console=<console>
+func (n Node) BootKernelArgs() []string {
+	args := append([]string(nil), n.KernelArgs...)
+	if n.Console != "" {
+		args = append(args, "console="+n.Console)
+	}
+	return args
+}
+
+// BootProfiles 按内核参数将节点分组，没有额外内核参数的节点使用默认 ISO，不包含在结果中。
+// 分组和组内节点按配置文件中的顺序排列
+func (c *ClusterConfig) BootProfiles() []BootProfile {
+	var profiles []BootProfile
+	index := make(map[string]int)
+	nodes := append(append([]Node(nil), c.Cluster.ControlPlane...), c.Cluster.Worker...)
+	for _, node := range nodes {
+		args := node.BootKernelArgs()
+		if len(args) == 0 {
+			continue
+		}
+		key := strings.Join(args, " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(profiles)
+			index[key] = i
+			profiles = append(profiles, BootProfile{Name: node.Name, KernelArgs: args})
+		}
+		profiles[i].Nodes = append(profiles[i].Nodes, node)
+	}
+	return profiles
+}
+
+// ValidateNodeBoot 验证节点的 root_device、kernel_args 和 console
+func ValidateNodeBoot(config *ClusterConfig) error {
+	check := func(role string, i int, node Node) error {
+		if node.RootDevice != "" && (!strings.HasPrefix(node.RootDevice, "/dev/") || strings.ContainsAny(node.RootDevice, " \t")) {
+			return i18n.Errorf("%s节点[%d] %s 的 root_device %q 必须是 /dev/ 下的设备路径", role, i, node.Name, node.RootDevice)
+		}
+		for _, arg := range node.KernelArgs {
+			if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+				return i18n.Errorf("%s节点[%d] %s 的 kernel_args %q 无效，每项只能包含一个参数且不能包含空白字符或引号", role, i, node.Name, arg)
+			}
+			if node.Console != "" && strings.HasPrefix(arg, "console=") {
+				return i18n.Errorf("%s节点[%d] %s 同时配置了 console 和 kernel_args 中的 %s，只能使用其中一种", role, i, node.Name, arg)
+			}
+		}
+		if node.Console != "" && !consolePattern.MatchString(node.Console) {
+			return i18n.Errorf("%s节点[%d] %s 的 console %q 格式无效，应为 tty0 或 ttyS0,115200n8 的形式", role, i, node.Name, node.Console)
+		}
+		return nil
+	}
+
+	for i, node := range config.Cluster.ControlPlane {
+		if err := check("control Plane", i, node); err != nil {
+			return err
+		}
+	}
+	for i, node := range config.Cluster.Worker {
+		if err := check("worker", i, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateNodeBoot(t *testing.T) {
+	tests := []struct {
+		name  string
+		node  Node
+		valid bool
+	}{
+		{"empty", Node{}, true},
+		{"all set", Node{RootDevice: "/dev/disk/by-path/pci-0000:03:00.0-scsi-0:2:0:0", KernelArgs: []string{"nomodeset", "rd.driver.blacklist=nouveau"}, Console: "ttyS0,115200n8"}, true},
+		{"console without options", Node{Console: "tty0"}, true},
+		{"relative root device", Node{RootDevice: "sda"}, false},
+		{"kernel arg with space", Node{KernelArgs: []string{"nomodeset quiet"}}, false},
+		{"empty kernel arg", Node{KernelArgs: []string{""}}, false},
+		{"duplicate console", Node{KernelArgs: []string{"console=tty0"}, Console: "ttyS0"}, false},
+		{"invalid console", Node{Console: "/dev/ttyS0"}, false},
+		{"invalid console options", Node{Console: "ttyS0,fast"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			tt.node.Name = cfg.Cluster.Worker[0].Name
+			cfg.Cluster.Worker[0] = tt.node
+			err := ValidateNodeBoot(cfg)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateNodeBoot() error = %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("ValidateNodeBoot() expected error")
+			}
+		})
+	}
+}
+
+func TestBootProfiles(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if profiles := cfg.BootProfiles(); len(profiles) != 0 {
+		t.Fatalf("BootProfiles() without kernel args = %+v", profiles)
+	}
+
+	cfg.Cluster.ControlPlane[1].Console = "ttyS0,115200n8"
+	cfg.Cluster.Worker[0].KernelArgs = []string{"nomodeset"}
+	cfg.Cluster.Worker[1].Console = "ttyS0,115200n8"
+	profiles := cfg.BootProfiles()
+	if len(profiles) != 2 {
+		t.Fatalf("BootProfiles() = %+v, want 2 profiles", profiles)
+	}
+
+	serial := profiles[0]
+	if serial.Name != cfg.Cluster.ControlPlane[1].Name || !reflect.DeepEqual(serial.KernelArgs, []string{"console=ttyS0,115200n8"}) {
+		t.Errorf("first profile = %+v", serial)
+	}
+	var names []string
+	for _, node := range serial.Nodes {
+		names = append(names, node.Name)
+	}
+	if want := []string{cfg.Cluster.ControlPlane[1].Name, cfg.Cluster.Worker[1].Name}; !reflect.DeepEqual(names, want) {
+		t.Errorf("first profile nodes = %v, want %v", names, want)
+	}
+	if got := profiles[1]; got.Name != cfg.Cluster.Worker[0].Name || !reflect.DeepEqual(got.KernelArgs, []string{"nomodeset"}) {
+		t.Errorf("second profile = %+v", got)
+	}
+}
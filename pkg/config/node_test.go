@@ -0,0 +1,71 @@
+package config
+
+import "testing"
+
+func TestValidateNodeMetadata(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Cluster.ControlPlane[0].BMCAddress = "10.0.0.10"
+	cfg.Cluster.ControlPlane[0].ConsoleURL = "https://10.0.0.10/console"
+	if err := ValidateNodeMetadata(cfg); err != nil {
+		t.Errorf("ValidateNodeMetadata() error = %v", err)
+	}
+
+	cfg.Cluster.Worker[0].ConsoleURL = "10.0.0.20"
+	if err := ValidateNodeMetadata(cfg); err == nil {
+		t.Error("expected error for console_url without http scheme")
+	}
+
+	cfg.Cluster.Worker[0].ConsoleURL = ""
+	cfg.Cluster.ControlPlane[1].BMCAddress = "10.0.0.11 10.0.0.12"
+	if err := ValidateNodeMetadata(cfg); err == nil {
+		t.Error("expected error for bmc_address with whitespace")
+	}
+}
+
+func TestValidateNodeMetadataSizing(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Cluster.Worker[0].MemoryGB = -1
+	if err := ValidateNodeMetadata(cfg); err == nil {
+		t.Error("expected error for negative memory_gb")
+	}
+}
+
+func TestValidateDHCPNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		dhcp  []int
+		infra Infra
+		valid bool
+	}{
+		{"all static", nil, Infra{}, true},
+		{"dhcp worker master", []int{1, 2}, Infra{}, true},
+		{"first master dhcp", []int{0}, Infra{}, true},
+		{"all masters dhcp", []int{0, 1, 2}, Infra{}, false},
+		{"bootstrap node dhcp", []int{1}, Infra{BootstrapNode: "master-1"}, false},
+		{"rendezvous ip dhcp reservation", []int{0}, Infra{RendezvousIP: "192.168.1.10"}, false},
+		{"rendezvous ip static", []int{0}, Infra{RendezvousIP: "192.168.1.11"}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		for i := range cfg.Cluster.ControlPlane {
+			cfg.Cluster.ControlPlane[i].IP = "192.168.1.1" + string(rune('0'+i))
+		}
+		for _, i := range tt.dhcp {
+			cfg.Cluster.ControlPlane[i].DHCP = true
+		}
+		cfg.Infra = tt.infra
+		if err := ValidateDHCPNodes(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateDHCPNodes() error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestGetRendezvousIPSkipsDHCP(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.Cluster.ControlPlane[0].DHCP = true
+	cfg.Cluster.ControlPlane[1].IP = "192.168.1.11"
+	if got := cfg.GetRendezvousIP(); got != "192.168.1.11" {
+		t.Errorf("GetRendezvousIP() = %q, expected first static control plane", got)
+	}
+}
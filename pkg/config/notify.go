@@ -0,0 +1,123 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// webhook 的消息格式
+const (
+	WebhookGeneric = "generic" // 阶段结果的 JSON
+	WebhookSlack   = "slack"   // Slack incoming webhook ({"text": ...})，Mattermost、Rocket.Chat 等兼容
+	WebhookTeams   = "teams"   // Microsoft Teams incoming webhook (MessageCard)
+)
+
+// WebhookFormats 支持的 webhook 消息格式
+var WebhookFormats = []string{WebhookGeneric, WebhookSlack, WebhookTeams}
+
+// DefaultNotifyStages 未配置 notify.stages 时发送通知的阶段，均为耗时较长的阶段
+var DefaultNotifyStages = []string{"save_image", "load_image", "generate_iso", "monitor_install"}
+
+// Notify 阶段结束时的通知配置，对应 [notify]
+type Notify struct {
+	Stages       []string  `toml:"stages,omitempty"`        // 发送通知的阶段，默认为 DefaultNotifyStages
+	OnlyFailures bool      `toml:"only_failures,omitempty"` // 只在阶段失败时通知
+	Webhooks     []Webhook `toml:"webhooks,omitempty"`
+	SMTP         SMTP      `toml:"smtp,omitempty"`
+}
+
+// Webhook 接收通知的 webhook，对应 [[notify.webhooks]]
+type Webhook struct {
+	URL    string `toml:"url"`
+	Format string `toml:"format,omitempty"` // generic (默认)、slack 或 teams
+}
+
+// SMTP 通过邮件发送通知，对应 [notify.smtp]，未配置 host 时不发送邮件
+type SMTP struct {
+	Host     string   `toml:"host,omitempty"`
+	Port     int      `toml:"port,omitempty"`     // 默认 25，服务器支持时自动使用 STARTTLS
+	Username string   `toml:"username,omitempty"` // 可选，配置时使用 PLAIN 认证
+	Password string   `toml:"password,omitempty"`
+	From     string   `toml:"from,omitempty"`
+	To       []string `toml:"to,omitempty"`
+}
+
+// GetFormat 返回 webhook 的消息格式，默认 generic
+func (w Webhook) GetFormat() string {
+	if w.Format == "" {
+		return WebhookGeneric
+	}
+	return w.Format
+}
+
+// GetPort 返回 SMTP 服务器端口，默认 25
+func (s SMTP) GetPort() int {
+	if s.Port == 0 {
+		return 25
+	}
+	return s.Port
+}
+
+// Enabled 判断是否配置了任何通知方式
+func (n Notify) Enabled() bool {
+	return len(n.Webhooks) > 0 || n.SMTP.Host != ""
+}
+
+// GetStages 返回发送通知的阶段
+func (n Notify) GetStages() []string {
+	if len(n.Stages) == 0 {
+		return DefaultNotifyStages
+	}
+	return n.Stages
+}
+
+// ShouldNotify 判断阶段以 failed 结果结束时是否需要发送通知
+func (n Notify) ShouldNotify(stage string, failed bool) bool {
+	if !n.Enabled() || (n.OnlyFailures && !failed) {
+		return false
+	}
+	for _, s := range n.GetStages() {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateNotify 验证 [notify] 配置
+func ValidateNotify(config *ClusterConfig) error {
+	notify := config.Notify
+	for i, webhook := range notify.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return i18n.Errorf("notify.webhooks[%d].url %q 必须是 http:// 或 https:// 地址", i, webhook.URL)
+		}
+		valid := false
+		for _, format := range WebhookFormats {
+			valid = valid || webhook.GetFormat() == format
+		}
+		if !valid {
+			return i18n.Errorf("notify.webhooks[%d].format %s 无效，支持: %s", i, webhook.Format, strings.Join(WebhookFormats, ", "))
+		}
+	}
+
+	smtp := notify.SMTP
+	if smtp.Host == "" {
+		if smtp.From != "" || len(smtp.To) > 0 {
+			return i18n.Errorf("notify.smtp 需要配置 host")
+		}
+		return nil
+	}
+	if smtp.From == "" || len(smtp.To) == 0 {
+		return i18n.Errorf("notify.smtp 需要配置 from 和 to")
+	}
+	if smtp.Port < 0 || smtp.Port > 65535 {
+		return i18n.Errorf("notify.smtp.port %d 无效", smtp.Port)
+	}
+	if (smtp.Username == "") != (smtp.Password == "") {
+		return i18n.Errorf("notify.smtp 的 username 和 password 需要同时配置")
+	}
+	return nil
+}
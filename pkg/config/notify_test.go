@@ -0,0 +1,55 @@
+package config
+
+import "testing"
+
+func TestValidateNotify(t *testing.T) {
+	tests := []struct {
+		name   string
+		notify Notify
+		valid  bool
+	}{
+		{"none", Notify{}, true},
+		{"slack webhook", Notify{Webhooks: []Webhook{{URL: "https://hooks.slack.com/services/x", Format: "slack"}}}, true},
+		{"generic webhook", Notify{Webhooks: []Webhook{{URL: "http://10.0.0.1:8080/hook"}}}, true},
+		{"webhook without scheme", Notify{Webhooks: []Webhook{{URL: "hooks.example.com/x"}}}, false},
+		{"unknown format", Notify{Webhooks: []Webhook{{URL: "https://hooks.example.com/x", Format: "discord"}}}, false},
+		{"smtp", Notify{SMTP: SMTP{Host: "smtp.example.com", From: "ocpack@example.com", To: []string{"ops@example.com"}}}, true},
+		{"smtp without recipients", Notify{SMTP: SMTP{Host: "smtp.example.com", From: "ocpack@example.com"}}, false},
+		{"smtp without host", Notify{SMTP: SMTP{From: "ocpack@example.com", To: []string{"ops@example.com"}}}, false},
+		{"smtp username without password", Notify{SMTP: SMTP{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}, Username: "ocpack"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.Notify = tt.notify
+			err := ValidateNotify(cfg)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateNotify() error = %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("ValidateNotify() expected error")
+			}
+		})
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	notify := Notify{}
+	if notify.ShouldNotify("save_image", true) {
+		t.Error("should not notify without targets")
+	}
+
+	notify.Webhooks = []Webhook{{URL: "https://hooks.example.com/x"}}
+	if !notify.ShouldNotify("save_image", false) || !notify.ShouldNotify("monitor_install", true) {
+		t.Error("default stages should be notified")
+	}
+	if notify.ShouldNotify("download", true) {
+		t.Error("download is not a default stage")
+	}
+
+	notify.Stages = []string{"download"}
+	notify.OnlyFailures = true
+	if notify.ShouldNotify("download", false) || !notify.ShouldNotify("download", true) {
+		t.Error("only_failures should skip successful stages")
+	}
+}
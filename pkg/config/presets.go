@@ -0,0 +1,146 @@
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// redHatCatalogRepository 预置组件的 Operator 所在的 Red Hat 目录
+const redHatCatalogRepository = "registry.redhat.io/redhat/redhat-operator-index"
+
+// PresetOperator 预置组件中由 day2 presets 订阅的 Operator
+type PresetOperator struct {
+	Package   string // Operator 的 package 名称
+	Namespace string // 安装的命名空间，同时是 OperatorGroup 和 Subscription 所在的命名空间
+	// AllNamespaces 为 true 时 OperatorGroup 不限定目标命名空间 (AllNamespaces 安装模式)，
+	// 否则只监听 Namespace 本身 (OwnNamespace 安装模式)
+	AllNamespaces bool
+}
+
+// Preset 常用 Day2 组件的预置配置：需要镜像的 Operator，以及安装时使用的 Subscription 和 OperatorGroup。
+// Subscription 不指定通道，使用目录中的默认通道，与 oc-mirror 未配置通道时镜像的默认通道一致
+type Preset struct {
+	Name      string
+	Operators []PresetOperator
+	// Dependencies 只需要镜像的依赖 Operator，由 OLM 在安装 Operators 时自动安装
+	Dependencies []string
+}
+
+// presets 支持的预置组件，对应 [save_image] presets
+var presets = map[string]Preset{
+	"gitops": {
+		Name: "gitops",
+		Operators: []PresetOperator{
+			{Package: "openshift-gitops-operator", Namespace: "openshift-gitops-operator", AllNamespaces: true},
+		},
+	},
+	"acm": {
+		Name: "acm",
+		Operators: []PresetOperator{
+			{Package: "advanced-cluster-management", Namespace: "open-cluster-management"},
+		},
+		Dependencies: []string{"multicluster-engine"},
+	},
+}
+
+// PresetNames 返回支持的预置组件名称
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPresets 返回 [save_image] presets 中启用的预置组件，重复的名称只保留一次，未知的名称被忽略
+func (c *ClusterConfig) GetPresets() []Preset {
+	seen := make(map[string]bool)
+	var result []Preset
+	for _, name := range c.SaveImage.Presets {
+		preset, ok := presets[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, preset)
+	}
+	return result
+}
+
+// Packages 返回预置组件需要镜像的全部 Operator，包括依赖
+func (p Preset) Packages() []string {
+	var packages []string
+	for _, op := range p.Operators {
+		packages = append(packages, op.Package)
+	}
+	return append(packages, p.Dependencies...)
+}
+
+// OperatorsEnabled 返回是否镜像 Operator 目录：include_operators = true 或配置了 presets
+func (c *ClusterConfig) OperatorsEnabled() bool {
+	return c.SaveImage.IncludeOperators || len(c.GetPresets()) > 0
+}
+
+// withPresetOperators 将预置组件的 Operator 加入 Red Hat 目录的 ops，已在任一目录中的 Operator 不重复加入。
+// 没有 Red Hat 目录时新增一个，使用 operator_catalog 和 ops 的旧配置时加入该目录
+func (c *ClusterConfig) withPresetOperators(catalogs []OperatorCatalog) []OperatorCatalog {
+	var missing []string
+	for _, preset := range c.GetPresets() {
+		for _, pkg := range preset.Packages() {
+			if !catalogsContain(catalogs, pkg) && !slices.Contains(missing, pkg) {
+				missing = append(missing, pkg)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return catalogs
+	}
+
+	if len(c.SaveImage.OperatorCatalogs) == 0 {
+		if len(catalogs) == 0 {
+			return []OperatorCatalog{c.withOCITag(OperatorCatalog{
+				Catalog:           c.GetOperatorCatalog(),
+				CatalogSourceName: legacyCatalogSourceName,
+				Ops:               missing,
+			})}
+		}
+		catalogs[0].Ops = append(append([]string(nil), catalogs[0].Ops...), missing...)
+		return catalogs
+	}
+
+	for i, catalog := range catalogs {
+		if catalog.IsOCI() || !strings.HasPrefix(catalog.Catalog, redHatCatalogRepository+":") {
+			continue
+		}
+		catalogs[i].Ops = append(append([]string(nil), catalog.Ops...), missing...)
+		return catalogs
+	}
+	return append(catalogs, OperatorCatalog{
+		Catalog: c.versionedCatalog(redHatCatalogRepository),
+		Ops:     missing,
+	})
+}
+
+// catalogsContain 判断 Operator 是否已在任一目录的 ops 中
+func catalogsContain(catalogs []OperatorCatalog, pkg string) bool {
+	for _, catalog := range catalogs {
+		if slices.Contains(catalog.Ops, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePresets 验证 [save_image] presets 中的名称
+func ValidatePresets(config *ClusterConfig) error {
+	for _, name := range config.SaveImage.Presets {
+		if _, ok := presets[name]; !ok {
+			return i18n.Errorf("save_image.presets 中的 %q 无效，支持: %s", name, strings.Join(PresetNames(), ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetOperatorCatalogsWithPresets(t *testing.T) {
+	t.Run("旧配置加入 ops", func(t *testing.T) {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+		cfg.SaveImage.Ops = []string{"cluster-logging", "openshift-gitops-operator"}
+		cfg.SaveImage.Presets = []string{"gitops", "acm", "gitops"}
+
+		catalogs := cfg.GetOperatorCatalogs()
+		if len(catalogs) != 1 {
+			t.Fatalf("expected 1 catalog, got %d", len(catalogs))
+		}
+		expected := []string{"cluster-logging", "openshift-gitops-operator", "advanced-cluster-management", "multicluster-engine"}
+		if !reflect.DeepEqual(catalogs[0].Ops, expected) {
+			t.Errorf("Ops = %v, expected %v", catalogs[0].Ops, expected)
+		}
+		if len(cfg.SaveImage.Ops) != 2 {
+			t.Errorf("GetOperatorCatalogs() modified save_image.ops: %v", cfg.SaveImage.Ops)
+		}
+	})
+
+	t.Run("旧配置没有 ops", func(t *testing.T) {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+		cfg.SaveImage.Ops = nil
+		cfg.SaveImage.Presets = []string{"gitops"}
+
+		catalogs := cfg.GetOperatorCatalogs()
+		if len(catalogs) != 1 || catalogs[0].GetCatalogSourceName() != "redhat-operators" {
+			t.Fatalf("unexpected catalogs: %+v", catalogs)
+		}
+		if !reflect.DeepEqual(catalogs[0].Ops, []string{"openshift-gitops-operator"}) {
+			t.Errorf("Ops = %v", catalogs[0].Ops)
+		}
+		if !cfg.OperatorsEnabled() {
+			t.Error("OperatorsEnabled() = false, expected true with presets")
+		}
+	})
+
+	t.Run("operator_catalogs 中的 Red Hat 目录", func(t *testing.T) {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+		cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{
+			{Catalog: "certified", Ops: []string{"gpu-operator-certified"}},
+			{Catalog: "redhat", Ops: []string{"cluster-logging"}},
+		}
+		cfg.SaveImage.Presets = []string{"gitops"}
+
+		catalogs := cfg.GetOperatorCatalogs()
+		if len(catalogs) != 2 {
+			t.Fatalf("expected 2 catalogs, got %d", len(catalogs))
+		}
+		if !reflect.DeepEqual(catalogs[1].Ops, []string{"cluster-logging", "openshift-gitops-operator"}) {
+			t.Errorf("Ops = %v", catalogs[1].Ops)
+		}
+	})
+
+	t.Run("没有 Red Hat 目录时新增", func(t *testing.T) {
+		cfg := NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+		cfg.SaveImage.OperatorCatalogs = []OperatorCatalog{{Catalog: "certified", Ops: []string{"gpu-operator-certified"}}}
+		cfg.SaveImage.Presets = []string{"acm"}
+
+		catalogs := cfg.GetOperatorCatalogs()
+		if len(catalogs) != 2 {
+			t.Fatalf("expected 2 catalogs, got %d", len(catalogs))
+		}
+		if catalogs[1].Catalog != "registry.redhat.io/redhat/redhat-operator-index:v4.16" || catalogs[1].GetCatalogSourceName() != "redhat-operators" {
+			t.Errorf("unexpected catalog: %+v", catalogs[1])
+		}
+		if err := ValidateOperatorCatalogs(cfg); err != nil {
+			t.Errorf("ValidateOperatorCatalogs() error = %v", err)
+		}
+	})
+}
+
+func TestValidatePresets(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.SaveImage.Presets = []string{"gitops", "acm"}
+	if err := ValidatePresets(cfg); err != nil {
+		t.Errorf("ValidatePresets() error = %v", err)
+	}
+
+	cfg.SaveImage.Presets = []string{"logging"}
+	if err := ValidatePresets(cfg); err == nil {
+		t.Error("ValidatePresets() expected error for an unknown preset")
+	}
+}
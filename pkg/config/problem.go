@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"ocpack/pkg/i18n"
+)
+
+// problemContextLines 显示出错行前后的行数
+const problemContextLines = 2
+
+// ConfigProblem 配置文件中的错误及其位置，Line 为 0 时无法定位到具体的行
+type ConfigProblem struct {
+	Line    int // 从 1 开始
+	Column  int // 从 1 开始
+	Message string
+	Context string // 出错位置附近的内容，出错的行带有 > 标记，出错的列下方有 ^ 标记
+}
+
+func (p *ConfigProblem) Error() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return i18n.Sprintf("第 %d 行第 %d 列: %s", p.Line, p.Column, p.Message)
+}
+
+// CheckConfigFile 解析并验证配置文件，返回错误及其位置，配置有效时返回 nil。
+// 语法和类型错误使用 TOML 解析器给出的位置，验证错误按错误信息中的配置项 (如 save_image.storage.url) 定位，
+// 有多个验证错误时全部列出，按第一个定位
+func CheckConfigFile(filePath string) (*ConfigProblem, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, i18n.Errorf("读取配置文件失败: %w", err)
+	}
+	// 先检查语法，位置对应用户编辑的内容
+	var raw map[string]interface{}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return decodeProblem(string(data), err), nil
+	}
+
+	// 旧版本配置先在内存中升级，与 LoadConfig 一致，行号按升级后的内容计算
+	migrated, _, err := MigrateConfigData(data)
+	if err != nil {
+		return &ConfigProblem{Message: i18n.Sprintf("升级配置文件失败: %v", err)}, nil
+	}
+	text := string(migrated)
+
+	cfg := &ClusterConfig{}
+	if err := toml.Unmarshal(migrated, cfg); err != nil {
+		return decodeProblem(text, err), nil
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		line, column := locateKey(text, problemKey(firstError(err).Error()))
+		return newConfigProblem(text, line, column, err.Error()), nil
+	}
+	return nil, nil
+}
+
+// firstError 返回 errors.Join 合并的第一个错误，其他错误原样返回
+func firstError(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok && len(joined.Unwrap()) > 0 {
+		return joined.Unwrap()[0]
+	}
+	return err
+}
+
+// decodeProblem 将 TOML 解析错误转换为 ConfigProblem，使用解析器给出的位置
+func decodeProblem(text string, err error) *ConfigProblem {
+	var decodeErr *toml.DecodeError
+	if !errors.As(err, &decodeErr) {
+		return &ConfigProblem{Message: err.Error()}
+	}
+	line, column := decodeErr.Position()
+	return newConfigProblem(text, line, column, decodeErr.Error())
+}
+
+func newConfigProblem(text string, line, column int, message string) *ConfigProblem {
+	problem := &ConfigProblem{Line: line, Column: column, Message: message}
+	if line > 0 {
+		problem.Context = problemContext(text, line, column)
+	}
+	return problem
+}
+
+// problemContext 返回 line 前后 problemContextLines 行的内容，带有行号
+func problemContext(text string, line, column int) string {
+	lines := strings.Split(text, "\n")
+	first := max(line-problemContextLines, 1)
+	last := min(line+problemContextLines, len(lines))
+	width := len(fmt.Sprint(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %*d | %s\n", marker, width, n, lines[n-1])
+		if n == line && column > 0 {
+			fmt.Fprintf(&b, "  %*s | %s^\n", width, "", strings.Repeat(" ", column-1))
+		}
+	}
+	return b.String()
+}
+
+var (
+	quotedValue = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	dottedKey   = regexp.MustCompile(`\b[a-z][a-z0-9_]*(?:\.[a-z][a-z0-9_]*)+\b`)
+)
+
+// problemKey 从验证错误信息中提取配置项，如 save_image.storage.url。引号中的值 (如域名) 不参与匹配
+func problemKey(message string) string {
+	message = quotedValue.ReplaceAllString(message, `""`)
+	return dottedKey.FindString(message)
+}
+
+// locateKey 返回配置项在文件中的行和列。找不到该项时依次尝试其所在的表，如 save_image.storage，
+// 都找不到时返回 0
+func locateKey(text, key string) (int, int) {
+	for key != "" {
+		if line, column := findKey(text, key); line > 0 {
+			return line, column
+		}
+		idx := strings.LastIndex(key, ".")
+		if idx < 0 {
+			break
+		}
+		key = key[:idx]
+	}
+	return 0, 0
+}
+
+// findKey 查找 key = 赋值或 [key] 表头所在的行和列
+func findKey(text, key string) (int, int) {
+	table := ""
+	for i, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		column := strings.Index(raw, line) + 1
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			table = strings.TrimSpace(strings.Trim(line[:end], "[]"))
+			if table == key {
+				return i + 1, column
+			}
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		name := strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+		if table != "" {
+			name = table + "." + name
+		}
+		if name == key {
+			return i + 1, column
+		}
+	}
+	return 0, 0
+}
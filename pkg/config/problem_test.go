@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validConfig 返回一个可以通过验证的配置
+func validConfig() *ClusterConfig {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Bastion.Password = "secret"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Registry.Password = "secret"
+	cfg.Cluster.ControlPlane = []Node{{Name: "master-0", IP: "192.168.1.10", MAC: "52:54:00:00:00:10"}}
+	cfg.Cluster.Worker = nil
+	return cfg
+}
+
+// writeValidConfig 保存一个可以通过验证的配置，返回文件路径和内容
+func writeValidConfig(t *testing.T) (string, string) {
+	t.Helper()
+	cfg := validConfig()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, string(data)
+}
+
+func lineOf(t *testing.T, text, substr string) int {
+	t.Helper()
+	for i, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, substr) {
+			return i + 1
+		}
+	}
+	t.Fatalf("%q not found", substr)
+	return 0
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	path, valid := writeValidConfig(t)
+	if problem, err := CheckConfigFile(path); err != nil || problem != nil {
+		t.Fatalf("CheckConfigFile(valid) = %v, %v", problem, err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		line    string // 期望定位到的行包含的内容
+		column  int
+	}{
+		{
+			name:    "syntax error",
+			content: strings.Replace(valid, `domain = 'example.com'`, `domain = 'example.com`, 1),
+			line:    `domain = 'example.com`,
+		},
+		{
+			name:    "type error",
+			content: strings.Replace(valid, "[save_image]\n", "[save_image]\nlocal_storage_port = 'high'\n", 1),
+			line:    "local_storage_port = 'high'",
+		},
+		{
+			name:    "validation error located by key",
+			content: strings.Replace(valid, "[save_image]\n", "[save_image]\n  local_storage_port = 80\n", 1),
+			line:    "local_storage_port = 80",
+			column:  3,
+		},
+		{
+			name:    "validation error located by table",
+			content: strings.Replace(valid, "[save_image]\n", "[save_image]\narchitectures = ['arm64']\n", 1),
+			line:    "architectures = ['arm64']",
+			column:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			problem, err := CheckConfigFile(path)
+			if err != nil || problem == nil {
+				t.Fatalf("CheckConfigFile() = %v, %v, want a problem", problem, err)
+			}
+			if want := lineOf(t, tt.content, tt.line); problem.Line != want {
+				t.Errorf("Line = %d, want %d (%s)", problem.Line, want, problem.Message)
+			}
+			if tt.column != 0 && problem.Column != tt.column {
+				t.Errorf("Column = %d, want %d", problem.Column, tt.column)
+			}
+			if !strings.Contains(problem.Context, "> ") || !strings.Contains(problem.Context, "^") {
+				t.Errorf("Context does not mark the problem:\n%s", problem.Context)
+			}
+		})
+	}
+}
+
+func TestValidateConfigReportsAllErrors(t *testing.T) {
+	cfg := validConfig()
+	if err := ValidateConfig(cfg); err != nil {
+		t.Fatalf("ValidateConfig(valid) error = %v", err)
+	}
+
+	// 两个相互独立的问题都应报告，而不是只报告第一个
+	cfg.ClusterInfo.Domain = ""
+	cfg.SaveImage.LocalStoragePort = 80
+	err := ValidateConfig(cfg)
+	if err == nil {
+		t.Fatal("ValidateConfig() error = nil, want two problems")
+	}
+	for _, want := range []string{"集群域名不能为空", "save_image.local_storage_port"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	// CheckConfigFile 列出全部问题，按第一个定位
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	problem, err := CheckConfigFile(path)
+	if err != nil || problem == nil {
+		t.Fatalf("CheckConfigFile() = %v, %v, want a problem", problem, err)
+	}
+	if !strings.Contains(problem.Message, "集群域名不能为空") || !strings.Contains(problem.Message, "save_image.local_storage_port") {
+		t.Errorf("Message = %q, want both problems", problem.Message)
+	}
+}
+
+func TestLocateKey(t *testing.T) {
+	text := `[cluster_info]
+domain = "example.com"
+
+[save_image.storage]
+url = "s3://bucket"
+
+[[cluster.control_plane]]
+name = "master-0"
+`
+	tests := []struct {
+		key          string
+		line, column int
+	}{
+		{"cluster_info.domain", 2, 1},
+		{"save_image.storage.url", 5, 1},
+		{"save_image.storage.endpoint", 4, 1},
+		{"cluster.control_plane", 7, 1},
+		{"rpms.repos", 0, 0},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		line, column := locateKey(text, tt.key)
+		if line != tt.line || column != tt.column {
+			t.Errorf("locateKey(%q) = %d:%d, want %d:%d", tt.key, line, column, tt.line, tt.column)
+		}
+	}
+
+	if key := problemKey(`save_image.storage.url "s3://demo.example.com" 无效`); key != "save_image.storage.url" {
+		t.Errorf("problemKey() = %q", key)
+	}
+	if key := problemKey(`Bastion节点IP不能为空`); key != "" {
+		t.Errorf("problemKey() = %q, want empty", key)
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Registry 的镜像模式，对应 [registry] mirror_mode
+const (
+	// MirrorModeMirror 默认模式：save-image 保存镜像，load-image 推送到 mirror-registry (Quay)
+	MirrorModeMirror = "mirror"
+	// MirrorModeProxyCache 半联网环境：Registry 节点部署 registry:2 拉取代理，节点拉取镜像时按需从上游缓存，
+	// 不再需要 save-image 和 load-image
+	MirrorModeProxyCache = "proxy-cache"
+)
+
+// DefaultProxyCacheImage proxy-cache 模式在 Registry 节点上运行的镜像
+const DefaultProxyCacheImage = "docker.io/library/registry:2"
+
+// ProxyCacheUpstream 一个上游仓库的拉取代理，对应 [[registry.proxy_cache]]。
+// registry:2 的一个实例只能代理一个上游，因此每个上游使用 Registry 节点上的一个端口
+type ProxyCacheUpstream struct {
+	Source string `toml:"source"` // 上游仓库地址，如 quay.io
+	Port   int    `toml:"port"`   // Registry 节点上代理该上游的端口
+}
+
+// defaultProxyCacheUpstreams 未配置 [[registry.proxy_cache]] 时代理的上游：release 镜像和 Red Hat Operator 镜像
+var defaultProxyCacheUpstreams = []ProxyCacheUpstream{
+	{Source: "quay.io", Port: 5001},
+	{Source: "registry.redhat.io", Port: 5002},
+}
+
+// IsProxyCache 返回 Registry 是否以拉取代理模式部署
+func (c *ClusterConfig) IsProxyCache() bool {
+	return c.Registry.MirrorMode == MirrorModeProxyCache
+}
+
+// GetProxyCacheUpstreams 返回代理的上游仓库，未配置时为 quay.io 和 registry.redhat.io
+func (c *ClusterConfig) GetProxyCacheUpstreams() []ProxyCacheUpstream {
+	if len(c.Registry.ProxyCache) > 0 {
+		return c.Registry.ProxyCache
+	}
+	return defaultProxyCacheUpstreams
+}
+
+// GetProxyCacheImage 返回拉取代理使用的镜像
+func (c *ClusterConfig) GetProxyCacheImage() string {
+	if c.Registry.ProxyCacheImage != "" {
+		return c.Registry.ProxyCacheImage
+	}
+	return DefaultProxyCacheImage
+}
+
+// ProxyCacheHost 返回上游在 Registry 节点上的代理地址，如 registry.demo.example.com:5001
+func (c *ClusterConfig) ProxyCacheHost(upstream ProxyCacheUpstream) string {
+	return fmt.Sprintf("%s:%d", c.RegistryHostname(), upstream.Port)
+}
+
+// ValidateProxyCache 验证 [registry] mirror_mode 和 [[registry.proxy_cache]]
+func ValidateProxyCache(config *ClusterConfig) error {
+	switch config.Registry.MirrorMode {
+	case "", MirrorModeMirror, MirrorModeProxyCache:
+	default:
+		return i18n.Errorf("registry.mirror_mode %q 无效，可选值: %s、%s", config.Registry.MirrorMode, MirrorModeMirror, MirrorModeProxyCache)
+	}
+
+	sources := make(map[string]bool)
+	ports := map[int]string{8443: "mirror-registry (Quay)"}
+	for i, upstream := range config.Registry.ProxyCache {
+		source := strings.ToLower(upstream.Source)
+		host, _, _ := strings.Cut(source, ":")
+		if !dnsNamePattern.MatchString(host) || strings.Contains(source, "/") {
+			return i18n.Errorf("registry.proxy_cache[%d] 的 source %q 无效，应为仓库地址，如 quay.io", i, upstream.Source)
+		}
+		if sources[source] {
+			return i18n.Errorf("registry.proxy_cache 中的 source %s 重复", source)
+		}
+		sources[source] = true
+		if upstream.Port < 1024 || upstream.Port > 65535 {
+			return i18n.Errorf("registry.proxy_cache[%d] %s 的 port %d 无效，必须在 1024-65535 之间", i, source, upstream.Port)
+		}
+		if owner, ok := ports[upstream.Port]; ok {
+			return i18n.Errorf("registry.proxy_cache[%d] %s 的 port %d 与 %s 冲突", i, source, upstream.Port, owner)
+		}
+		ports[upstream.Port] = "registry.proxy_cache " + source
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import "testing"
+
+func TestValidateProxyCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		proxyCache []ProxyCacheUpstream
+		valid      bool
+	}{
+		{"default", "", nil, true},
+		{"proxy cache defaults", MirrorModeProxyCache, nil, true},
+		{"invalid mode", "pull-through", nil, false},
+		{"custom upstreams", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io", 5001}, {"ghcr.io", 5003}}, true},
+		{"upstream with port", MirrorModeProxyCache, []ProxyCacheUpstream{{"nexus.example.com:8082", 5001}}, true},
+		{"repository path", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io/openshift-release-dev", 5001}}, false},
+		{"duplicate source", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io", 5001}, {"Quay.io", 5002}}, false},
+		{"duplicate port", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io", 5001}, {"ghcr.io", 5001}}, false},
+		{"quay port", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io", 8443}}, false},
+		{"privileged port", MirrorModeProxyCache, []ProxyCacheUpstream{{"quay.io", 443}}, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Registry.MirrorMode = tt.mode
+		cfg.Registry.ProxyCache = tt.proxyCache
+		if err := ValidateProxyCache(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateProxyCache() error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestProxyCacheDefaults(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if cfg.IsProxyCache() {
+		t.Error("IsProxyCache() = true for default config")
+	}
+	upstreams := cfg.GetProxyCacheUpstreams()
+	if len(upstreams) != 2 || upstreams[0].Source != "quay.io" {
+		t.Errorf("GetProxyCacheUpstreams() = %v", upstreams)
+	}
+	if got := cfg.ProxyCacheHost(upstreams[0]); got != "registry.demo.example.com:5001" {
+		t.Errorf("ProxyCacheHost() = %q", got)
+	}
+	if cfg.GetProxyCacheImage() != DefaultProxyCacheImage {
+		t.Errorf("GetProxyCacheImage() = %q", cfg.GetProxyCacheImage())
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Quay 仓库的可见性
+const (
+	QuayVisibilityPrivate = "private"
+	QuayVisibilityPublic  = "public"
+)
+
+// quotaPattern 配额大小，如 500Gi、2Ti、800G
+var quotaPattern = regexp.MustCompile(`^([0-9]+)([KMGT]i?)$`)
+
+// quotaUnits 配额单位对应的字节数，带 i 的为二进制单位
+var quotaUnits = map[string]int64{
+	"K": 1000, "M": 1000 * 1000, "G": 1000 * 1000 * 1000, "T": 1000 * 1000 * 1000 * 1000,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40,
+}
+
+// RegistryQuay load-image 推送前在 mirror-registry (Quay) 中准备组织和仓库，对应 [registry.quay]。
+// 站点的 Quay 关闭了推送时自动创建组织 (CREATE_NAMESPACE_ON_PUSH) 或启用了配额时，
+// 镜像推送到不存在或超出配额的组织会在中途返回 403
+type RegistryQuay struct {
+	// 是否在 load-image 前通过 Quay API 创建目标组织和仓库
+	ManageOrganizations bool `toml:"manage_organizations"`
+	// 可选，Quay OAuth 访问令牌 (需要 super:user、org:admin 和 repo:create 权限)，为空时使用 registry_user 和 registry_password
+	APIToken string `toml:"api_token,omitempty"`
+	// 新建和已有仓库的可见性: private (默认) 或 public
+	Visibility string `toml:"visibility,omitempty"`
+	// 可选，每个组织的存储配额，如 500Gi、2Ti，为空时不设置 (需要 Quay 启用 FEATURE_QUOTA_MANAGEMENT)
+	Quota string `toml:"quota,omitempty"`
+	// 可选，额外需要准备的组织，或覆盖自动识别的组织的可见性和配额
+	Organizations []QuayOrganization `toml:"organizations,omitempty"`
+}
+
+// QuayOrganization 一个 Quay 组织，对应 [[registry.quay.organizations]]
+type QuayOrganization struct {
+	Name         string   `toml:"name"`
+	Visibility   string   `toml:"visibility,omitempty"`   // 为空时使用 [registry.quay] visibility
+	Quota        string   `toml:"quota,omitempty"`        // 为空时使用 [registry.quay] quota
+	Repositories []string `toml:"repositories,omitempty"` // 额外预先创建的仓库 (组织内的路径)
+}
+
+// GetQuayVisibility 返回组织中仓库的可见性，组织未设置时使用 [registry.quay] visibility，默认 private
+func (c *ClusterConfig) GetQuayVisibility(org QuayOrganization) string {
+	if org.Visibility != "" {
+		return org.Visibility
+	}
+	if c.Registry.Quay.Visibility != "" {
+		return c.Registry.Quay.Visibility
+	}
+	return QuayVisibilityPrivate
+}
+
+// GetQuayQuota 返回组织的配额字节数，未配置时返回 0
+func (c *ClusterConfig) GetQuayQuota(org QuayOrganization) (int64, error) {
+	quota := org.Quota
+	if quota == "" {
+		quota = c.Registry.Quay.Quota
+	}
+	if quota == "" {
+		return 0, nil
+	}
+	return ParseQuota(quota)
+}
+
+// ParseQuota 解析配额大小，支持 K/M/G/T (十进制) 和 Ki/Mi/Gi/Ti (二进制) 单位
+func ParseQuota(quota string) (int64, error) {
+	return parseByteSize(quota, i18n.T("配额"), i18n.T("500Gi、2Ti"))
+}
+
+// parseByteSize 解析数字加单位的大小，kind 和 example 用于错误信息
+func parseByteSize(size, kind, example string) (int64, error) {
+	match := quotaPattern.FindStringSubmatch(strings.TrimSpace(size))
+	if match == nil {
+		return 0, i18n.Errorf("%s %q 无效，应为数字加单位，如 %s", kind, size, example)
+	}
+	value, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || value == 0 {
+		return 0, i18n.Errorf("%s %q 无效，必须大于 0", kind, size)
+	}
+	return value * quotaUnits[match[2]], nil
+}
+
+// ValidateRegistryQuay 验证 [registry.quay]
+func ValidateRegistryQuay(config *ClusterConfig) error {
+	quay := config.Registry.Quay
+	if err := validateQuayVisibility("registry.quay.visibility", quay.Visibility); err != nil {
+		return err
+	}
+	if quay.Quota != "" {
+		if _, err := ParseQuota(quay.Quota); err != nil {
+			return fmt.Errorf("registry.quay.quota: %v", err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, org := range quay.Organizations {
+		if !namespaceSegmentPattern.MatchString(org.Name) {
+			return i18n.Errorf("registry.quay.organizations[%d] 的 name %q 无效，只能包含小写字母、数字和 '.'、'_'、'-'", i, org.Name)
+		}
+		if names[org.Name] {
+			return i18n.Errorf("registry.quay.organizations 中的组织 %s 重复", org.Name)
+		}
+		names[org.Name] = true
+		if err := validateQuayVisibility(i18n.Sprintf("registry.quay.organizations[%d] %s 的 visibility", i, org.Name), org.Visibility); err != nil {
+			return err
+		}
+		if org.Quota != "" {
+			if _, err := ParseQuota(org.Quota); err != nil {
+				return i18n.Errorf("registry.quay.organizations[%d] %s 的 quota: %v", i, org.Name, err)
+			}
+		}
+		for _, repo := range org.Repositories {
+			for _, segment := range strings.Split(repo, "/") {
+				if !namespaceSegmentPattern.MatchString(segment) {
+					return i18n.Errorf("registry.quay.organizations[%d] %s 的仓库 %q 无效", i, org.Name, repo)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateQuayVisibility(field, visibility string) error {
+	switch visibility {
+	case "", QuayVisibilityPrivate, QuayVisibilityPublic:
+		return nil
+	}
+	return i18n.Errorf("%s %q 无效，可选值: %s、%s", field, visibility, QuayVisibilityPrivate, QuayVisibilityPublic)
+}
@@ -0,0 +1,80 @@
+package config
+
+import "testing"
+
+func TestParseQuota(t *testing.T) {
+	tests := []struct {
+		quota   string
+		want    int64
+		wantErr bool
+	}{
+		{"500Gi", 500 << 30, false},
+		{"2Ti", 2 << 40, false},
+		{"800G", 800 * 1000 * 1000 * 1000, false},
+		{"10Mi", 10 << 20, false},
+		{"0Gi", 0, true},
+		{"500", 0, true},
+		{"1.5Ti", 0, true},
+		{"500gb", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quota, func(t *testing.T) {
+			got, err := ParseQuota(tt.quota)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuota() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuota() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryQuay(t *testing.T) {
+	tests := []struct {
+		name    string
+		quay    RegistryQuay
+		wantErr bool
+	}{
+		{"empty", RegistryQuay{}, false},
+		{"valid", RegistryQuay{ManageOrganizations: true, Visibility: "public", Quota: "500Gi", Organizations: []QuayOrganization{
+			{Name: "apps", Visibility: "private", Quota: "1Ti", Repositories: []string{"team/api"}},
+		}}, false},
+		{"bad visibility", RegistryQuay{Visibility: "internal"}, true},
+		{"bad quota", RegistryQuay{Quota: "lots"}, true},
+		{"bad org name", RegistryQuay{Organizations: []QuayOrganization{{Name: "Apps"}}}, true},
+		{"duplicate org", RegistryQuay{Organizations: []QuayOrganization{{Name: "apps"}, {Name: "apps"}}}, true},
+		{"bad org quota", RegistryQuay{Organizations: []QuayOrganization{{Name: "apps", Quota: "1TB"}}}, true},
+		{"bad repository", RegistryQuay{Organizations: []QuayOrganization{{Name: "apps", Repositories: []string{"team//api"}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.Registry.Quay = tt.quay
+			if err := ValidateRegistryQuay(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRegistryQuay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuayOrganizationDefaults(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if got := cfg.GetQuayVisibility(QuayOrganization{}); got != QuayVisibilityPrivate {
+		t.Errorf("default visibility = %s", got)
+	}
+	cfg.Registry.Quay.Visibility = QuayVisibilityPublic
+	cfg.Registry.Quay.Quota = "100Gi"
+	if got := cfg.GetQuayVisibility(QuayOrganization{}); got != QuayVisibilityPublic {
+		t.Errorf("visibility = %s, want public", got)
+	}
+	if got := cfg.GetQuayVisibility(QuayOrganization{Visibility: QuayVisibilityPrivate}); got != QuayVisibilityPrivate {
+		t.Errorf("organization visibility = %s, want private", got)
+	}
+	if got, _ := cfg.GetQuayQuota(QuayOrganization{}); got != 100<<30 {
+		t.Errorf("quota = %d", got)
+	}
+	if got, _ := cfg.GetQuayQuota(QuayOrganization{Quota: "1Ti"}); got != 1<<40 {
+		t.Errorf("organization quota = %d", got)
+	}
+}
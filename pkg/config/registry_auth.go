@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// DefaultRegistryPassword 未配置 registry_password 时 mirror-registry 的初始密码，与旧版本保持一致
+const DefaultRegistryPassword = "ztesoft123"
+
+// registryPort mirror-registry (Quay) 的服务端口
+const registryPort = "8443"
+
+// RegistryAuth 额外镜像仓库的认证信息，对应 [[registry.auths]]，会合并到 merged-auth.json 中
+type RegistryAuth struct {
+	Host     string `toml:"host"`               // 仓库地址，如 quay.example.com:8443
+	Username string `toml:"username"`           // 用户名
+	Password string `toml:"password,omitempty"` // 密码，与 token 二选一
+	Token    string `toml:"token,omitempty"`    // 访问令牌 (如 robot 账号令牌)，与 password 二选一
+}
+
+// Secret 返回用于认证的密码或令牌，优先使用密码
+func (a RegistryAuth) Secret() string {
+	if a.Password != "" {
+		return a.Password
+	}
+	return a.Token
+}
+
+// GetRegistryPassword 返回私有仓库的密码，未配置时使用 DefaultRegistryPassword
+func (c *ClusterConfig) GetRegistryPassword() string {
+	if c.Registry.RegistryPassword != "" {
+		return c.Registry.RegistryPassword
+	}
+	return DefaultRegistryPassword
+}
+
+// GetRegistryHost 返回私有仓库的地址 (含端口)，如 registry.demo.example.com:8443
+func (c *ClusterConfig) GetRegistryHost() string {
+	return c.RegistryHostname() + ":" + registryPort
+}
+
+// ValidateRegistryAuths 验证 [[registry.auths]] 配置，仓库地址不能重复且必须提供密码或令牌
+func ValidateRegistryAuths(config *ClusterConfig) error {
+	hosts := map[string]bool{config.GetRegistryHost(): true}
+	for i, auth := range config.Registry.Auths {
+		if auth.Host == "" {
+			return i18n.Errorf("registry.auths[%d] 的 host 不能为空", i)
+		}
+		if strings.Contains(auth.Host, "://") {
+			return i18n.Errorf("registry.auths[%d] 的 host %s 不能包含协议前缀", i, auth.Host)
+		}
+		if auth.Username == "" {
+			return i18n.Errorf("registry.auths[%d] %s 的 username 不能为空", i, auth.Host)
+		}
+		if auth.Password != "" && auth.Token != "" {
+			return i18n.Errorf("registry.auths[%d] %s 的 password 和 token 只能设置一个", i, auth.Host)
+		}
+		if auth.Secret() == "" {
+			return i18n.Errorf("registry.auths[%d] %s 必须设置 password 或 token", i, auth.Host)
+		}
+		if hosts[auth.Host] {
+			return i18n.Errorf("registry.auths[%d] 的 host %s 重复", i, auth.Host)
+		}
+		hosts[auth.Host] = true
+	}
+	return nil
+}
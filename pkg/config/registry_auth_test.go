@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestValidateRegistryAuths(t *testing.T) {
+	tests := []struct {
+		name  string
+		auths []RegistryAuth
+		valid bool
+	}{
+		{"empty", nil, true},
+		{"password", []RegistryAuth{{Host: "quay.example.com", Username: "u", Password: "p"}}, true},
+		{"token", []RegistryAuth{{Host: "quay.example.com", Username: "robot", Token: "t"}}, true},
+		{"missing host", []RegistryAuth{{Username: "u", Password: "p"}}, false},
+		{"scheme", []RegistryAuth{{Host: "https://quay.example.com", Username: "u", Password: "p"}}, false},
+		{"missing secret", []RegistryAuth{{Host: "quay.example.com", Username: "u"}}, false},
+		{"password and token", []RegistryAuth{{Host: "quay.example.com", Username: "u", Password: "p", Token: "t"}}, false},
+		{"duplicate", []RegistryAuth{
+			{Host: "quay.example.com", Username: "u", Password: "p"},
+			{Host: "quay.example.com", Username: "v", Password: "q"},
+		}, false},
+		{"local registry", []RegistryAuth{{Host: "registry.demo.example.com:8443", Username: "u", Password: "p"}}, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Registry.Auths = tt.auths
+		if err := ValidateRegistryAuths(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateRegistryAuths() error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestGetRegistryPassword(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if password := cfg.GetRegistryPassword(); password != DefaultRegistryPassword {
+		t.Errorf("GetRegistryPassword() = %q, expected %q", password, DefaultRegistryPassword)
+	}
+	cfg.Registry.RegistryPassword = "changed"
+	if password := cfg.GetRegistryPassword(); password != "changed" {
+		t.Errorf("GetRegistryPassword() = %q, expected changed", password)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Registry 存储设备支持的文件系统
+const (
+	FilesystemXFS  = "xfs"
+	FilesystemExt4 = "ext4"
+)
+
+// DefaultRegistryMinFree Registry 存储路径至少需要的可用空间，镜像归档之外 Quay 的数据库、
+// Redis 和镜像层上传时的临时文件也需要空间
+const DefaultRegistryMinFree = "20Gi"
+
+// devicePattern 块设备路径，如 /dev/sdb、/dev/disk/by-id/wwn-0x5000c500a0b1c2d3
+var devicePattern = regexp.MustCompile(`^/dev/[A-Za-z0-9/_.:-]+$`)
+
+// RegistryStorage Registry 节点的存储准备，对应 [registry.storage]。配置 device 时 deploy-registry 在
+// 设备上创建文件系统 (设备已有文件系统时不重新格式化) 并挂载到 storage_path；quota 使用 XFS 项目配额
+// 限制 storage_path 的大小。部署前按镜像归档的大小检查可用空间，空间不足时在执行 playbook 前失败
+type RegistryStorage struct {
+	// 可选，专用于镜像存储的块设备，如 /dev/sdb
+	Device string `toml:"device,omitempty"`
+	// 可选，device 上创建的文件系统: xfs (默认) 或 ext4
+	Filesystem string `toml:"filesystem,omitempty"`
+	// 可选，storage_path 的 XFS 项目配额，如 800Gi，需要 filesystem = "xfs" 并配置 device
+	Quota string `toml:"quota,omitempty"`
+	// 可选，部署前 storage_path 至少需要的可用空间，默认 DefaultRegistryMinFree
+	MinFree string `toml:"min_free,omitempty"`
+}
+
+// GetFilesystem 返回 device 上创建的文件系统，默认 xfs
+func (s RegistryStorage) GetFilesystem() string {
+	if s.Filesystem == "" {
+		return FilesystemXFS
+	}
+	return s.Filesystem
+}
+
+// GetQuota 返回 storage_path 的配额字节数，未配置时返回 0
+func (s RegistryStorage) GetQuota() (int64, error) {
+	if s.Quota == "" {
+		return 0, nil
+	}
+	return ParseQuota(s.Quota)
+}
+
+// GetMinFree 返回部署前 storage_path 至少需要的可用空间字节数
+func (s RegistryStorage) GetMinFree() (int64, error) {
+	if s.MinFree == "" {
+		return ParseSize(DefaultRegistryMinFree)
+	}
+	return ParseSize(s.MinFree)
+}
+
+// ValidateRegistryStorage 验证 [registry.storage]
+func ValidateRegistryStorage(config *ClusterConfig) error {
+	storage := config.Registry.Storage
+	if storage.Device != "" && !devicePattern.MatchString(storage.Device) {
+		return i18n.Errorf("registry.storage.device %q 无效，应为块设备路径，如 /dev/sdb", storage.Device)
+	}
+	switch storage.Filesystem {
+	case "", FilesystemXFS, FilesystemExt4:
+	default:
+		return i18n.Errorf("registry.storage.filesystem %q 无效，可选值: %s、%s", storage.Filesystem, FilesystemXFS, FilesystemExt4)
+	}
+	if storage.Device != "" {
+		path := filepath.Clean(config.Registry.StoragePath)
+		if !filepath.IsAbs(path) || path == "/" || strings.HasPrefix(path, "/dev/") {
+			return i18n.Errorf("配置 registry.storage.device 时 registry.storage_path %q 必须是单独的挂载点，如 /var/lib/registry", config.Registry.StoragePath)
+		}
+	}
+	if storage.Quota != "" {
+		if _, err := storage.GetQuota(); err != nil {
+			return fmt.Errorf("registry.storage.quota: %v", err)
+		}
+		if storage.Device == "" || storage.GetFilesystem() != FilesystemXFS {
+			return i18n.Errorf("registry.storage.quota 使用 XFS 项目配额，需要配置 registry.storage.device 且 filesystem 为 %s", FilesystemXFS)
+		}
+	}
+	if _, err := storage.GetMinFree(); err != nil {
+		return fmt.Errorf("registry.storage.min_free: %v", err)
+	}
+	if quota, _ := storage.GetQuota(); quota > 0 {
+		if minFree, _ := storage.GetMinFree(); minFree > quota {
+			return i18n.Errorf("registry.storage.min_free (%s) 不能大于 quota (%s)", storage.MinFree, storage.Quota)
+		}
+	}
+	return nil
+}
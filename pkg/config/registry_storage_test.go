@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestValidateRegistryStorage(t *testing.T) {
+	tests := []struct {
+		name        string
+		storagePath string
+		storage     RegistryStorage
+		wantErr     bool
+	}{
+		{"empty", "", RegistryStorage{}, false},
+		{"device", "", RegistryStorage{Device: "/dev/sdb", Filesystem: "ext4", MinFree: "100Gi"}, false},
+		{"quota", "", RegistryStorage{Device: "/dev/disk/by-id/wwn-0x5000c500a0b1c2d3", Quota: "800Gi"}, false},
+		{"bad device", "", RegistryStorage{Device: "sdb"}, true},
+		{"bad filesystem", "", RegistryStorage{Device: "/dev/sdb", Filesystem: "btrfs"}, true},
+		{"device on root", "/", RegistryStorage{Device: "/dev/sdb"}, true},
+		{"quota without device", "", RegistryStorage{Quota: "800Gi"}, true},
+		{"quota on ext4", "", RegistryStorage{Device: "/dev/sdb", Filesystem: "ext4", Quota: "800Gi"}, true},
+		{"bad quota", "", RegistryStorage{Device: "/dev/sdb", Quota: "800GB"}, true},
+		{"bad min_free", "", RegistryStorage{MinFree: "lots"}, true},
+		{"min_free above quota", "", RegistryStorage{Device: "/dev/sdb", Quota: "100Gi", MinFree: "200Gi"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			if tt.storagePath != "" {
+				cfg.Registry.StoragePath = tt.storagePath
+			}
+			cfg.Registry.Storage = tt.storage
+			if err := ValidateRegistryStorage(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRegistryStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// RegistryTLS 复制镜像时单个仓库的 TLS 配置，对应 [[save_image.registry_tls]]。
+// 配置 ca_file 时额外信任该 CA，insecure = true 时不校验该仓库的证书，两者不能同时配置
+type RegistryTLS struct {
+	Registry string `toml:"registry"`           // 仓库地址 host[:port]，如 registry.lab.example.com:5000
+	CAFile   string `toml:"ca_file,omitempty"`  // 可选，CA 证书文件，相对路径相对于集群目录
+	Insecure bool   `toml:"insecure,omitempty"` // 可选，不校验证书
+}
+
+// GetInsecureRegistries 返回 registry_tls 中标记为 insecure 的仓库
+func (c *ClusterConfig) GetInsecureRegistries() []string {
+	var registries []string
+	for _, entry := range c.SaveImage.RegistryTLS {
+		if entry.Insecure {
+			registries = append(registries, entry.Registry)
+		}
+	}
+	return registries
+}
+
+// GetRegistryCAFiles 返回 registry_tls 中配置的 CA 证书文件的绝对路径，相对路径相对于集群目录
+func (c *ClusterConfig) GetRegistryCAFiles(clusterDir string) []string {
+	var paths []string
+	for _, entry := range c.SaveImage.RegistryTLS {
+		if entry.CAFile == "" {
+			continue
+		}
+		path := entry.CAFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(clusterDir, path)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// ValidateRegistryTLS 验证 [[save_image.registry_tls]]
+func ValidateRegistryTLS(config *ClusterConfig) error {
+	seen := make(map[string]bool)
+	for i, entry := range config.SaveImage.RegistryTLS {
+		registry := entry.Registry
+		if registry == "" {
+			return i18n.Errorf("save_image.registry_tls[%d] 的 registry 不能为空", i)
+		}
+		if strings.Contains(registry, "://") || strings.ContainsAny(registry, "/ \t") {
+			return i18n.Errorf("save_image.registry_tls[%d] 的 registry %q 必须是 host[:port]，不能包含协议或路径", i, registry)
+		}
+		if seen[registry] {
+			return i18n.Errorf("save_image.registry_tls 中的仓库 %s 重复", registry)
+		}
+		seen[registry] = true
+		if entry.Insecure && entry.CAFile != "" {
+			return i18n.Errorf("save_image.registry_tls 中的仓库 %s 不能同时配置 ca_file 和 insecure", registry)
+		}
+		if !entry.Insecure && strings.TrimSpace(entry.CAFile) == "" {
+			return i18n.Errorf("save_image.registry_tls 中的仓库 %s 需要配置 ca_file 或 insecure = true", registry)
+		}
+	}
+	return nil
+}
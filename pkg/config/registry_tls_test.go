@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateRegistryTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []RegistryTLS
+		valid   bool
+	}{
+		{"none", nil, true},
+		{"ca file", []RegistryTLS{{Registry: "registry.lab.example.com:5000", CAFile: "certs/lab-ca.pem"}}, true},
+		{"insecure", []RegistryTLS{{Registry: "legacy.example.com", Insecure: true}}, true},
+		{"empty registry", []RegistryTLS{{CAFile: "certs/lab-ca.pem"}}, false},
+		{"registry with scheme", []RegistryTLS{{Registry: "https://legacy.example.com", Insecure: true}}, false},
+		{"registry with path", []RegistryTLS{{Registry: "legacy.example.com/ns", Insecure: true}}, false},
+		{"duplicate registry", []RegistryTLS{{Registry: "legacy.example.com", Insecure: true}, {Registry: "legacy.example.com", CAFile: "ca.pem"}}, false},
+		{"ca file and insecure", []RegistryTLS{{Registry: "legacy.example.com", CAFile: "ca.pem", Insecure: true}}, false},
+		{"neither ca file nor insecure", []RegistryTLS{{Registry: "legacy.example.com"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.SaveImage.RegistryTLS = tt.entries
+			err := ValidateRegistryTLS(cfg)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateRegistryTLS() error = %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("ValidateRegistryTLS() expected error")
+			}
+		})
+	}
+}
+
+func TestRegistryTLSGetters(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.SaveImage.RegistryTLS = []RegistryTLS{
+		{Registry: "registry.lab.example.com:5000", CAFile: "certs/lab-ca.pem"},
+		{Registry: "legacy.example.com", Insecure: true},
+		{Registry: "other.example.com", CAFile: "/etc/pki/other-ca.pem"},
+	}
+	if got := cfg.GetInsecureRegistries(); !reflect.DeepEqual(got, []string{"legacy.example.com"}) {
+		t.Errorf("GetInsecureRegistries() = %v", got)
+	}
+	want := []string{"/work/demo/certs/lab-ca.pem", "/etc/pki/other-ca.pem"}
+	if got := cfg.GetRegistryCAFiles("/work/demo"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRegistryCAFiles() = %v, want %v", got, want)
+	}
+}
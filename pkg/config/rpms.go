@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// Rpms 离线 RPM 仓库配置，对应 [rpms]，由 mirror-rpms 使用
+type Rpms struct {
+	ReleaseVer    string   `toml:"release_ver,omitempty"`    // 可选，下载的系统版本 (如 9)，默认与执行 mirror-rpms 的主机一致
+	Repos         []string `toml:"repos,omitempty"`          // 可选，只从这些仓库下载 (dnf --repo)
+	ExtraPackages []string `toml:"extra_packages,omitempty"` // 可选，额外下载的软件包
+}
+
+// ValidateRpmsConfig 验证 [rpms] 配置
+func ValidateRpmsConfig(config *ClusterConfig) error {
+	for i, repo := range config.Rpms.Repos {
+		if strings.TrimSpace(repo) == "" {
+			return i18n.Errorf("rpms.repos[%d] 不能为空", i)
+		}
+	}
+	for i, pkg := range config.Rpms.ExtraPackages {
+		if strings.TrimSpace(pkg) == "" {
+			return i18n.Errorf("rpms.extra_packages[%d] 不能为空", i)
+		}
+	}
+	return nil
+}
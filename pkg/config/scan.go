@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// 支持的镜像扫描器
+const (
+	ScannerTrivy   = "trivy"
+	ScannerCommand = "command"
+)
+
+// SeverityLevels 漏洞严重级别，按从低到高排列
+var SeverityLevels = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// Scan 镜像漏洞扫描配置，对应 [scan]
+type Scan struct {
+	Enabled    bool   `toml:"enabled"`               // 为 true 时 load-image 在推送镜像前先执行扫描
+	Scanner    string `toml:"scanner,omitempty"`     // trivy (默认) 或 command
+	Command    string `toml:"command,omitempty"`     // scanner 为 command 时执行的命令，需输出 Trivy 格式的 JSON
+	FailOn     string `toml:"fail_on,omitempty"`     // 存在该级别及以上的漏洞时扫描失败，为空时只生成报告
+	ImagesFile string `toml:"images_file,omitempty"` // 可选，待扫描镜像列表，默认使用 save-image --dry-run 生成的 mapping.txt
+}
+
+// GetScanner 返回使用的扫描器，默认 trivy
+func (s Scan) GetScanner() string {
+	if s.Scanner == "" {
+		return ScannerTrivy
+	}
+	return s.Scanner
+}
+
+// SeverityRank 返回严重级别的排序值，无法识别的级别视为 UNKNOWN
+func SeverityRank(severity string) int {
+	rank, _ := severityIndex(severity)
+	return rank
+}
+
+func severityIndex(severity string) (int, bool) {
+	severity = strings.ToUpper(severity)
+	for i, level := range SeverityLevels {
+		if level == severity {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateScanConfig 验证 [scan] 配置
+func ValidateScanConfig(config *ClusterConfig) error {
+	scan := config.Scan
+	switch scan.GetScanner() {
+	case ScannerTrivy:
+	case ScannerCommand:
+		if strings.TrimSpace(scan.Command) == "" {
+			return i18n.Errorf("scan.scanner 为 command 时必须设置 scan.command")
+		}
+	default:
+		return i18n.Errorf("scan.scanner %s 无效，支持: %s, %s", scan.Scanner, ScannerTrivy, ScannerCommand)
+	}
+	if _, ok := severityIndex(scan.FailOn); scan.FailOn != "" && !ok {
+		return i18n.Errorf("scan.fail_on %s 无效，支持: %s", scan.FailOn, strings.Join(SeverityLevels, ", "))
+	}
+	return nil
+}
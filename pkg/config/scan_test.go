@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestValidateScanConfig(t *testing.T) {
+	tests := []struct {
+		scan  Scan
+		valid bool
+	}{
+		{Scan{}, true},
+		{Scan{Enabled: true, FailOn: "critical"}, true},
+		{Scan{Scanner: ScannerCommand, Command: "./scan.sh"}, true},
+		{Scan{Scanner: ScannerCommand}, false},
+		{Scan{Scanner: "clair"}, false},
+		{Scan{FailOn: "SEVERE"}, false},
+	}
+
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.Scan = tt.scan
+		if err := ValidateScanConfig(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateScanConfig(%+v) error = %v, expected valid = %t", tt.scan, err, tt.valid)
+		}
+	}
+}
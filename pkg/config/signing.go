@@ -0,0 +1,36 @@
+package config
+
+import "ocpack/pkg/i18n"
+
+// 传输清单的签名方式
+const (
+	SigningGPG    = "gpg"
+	SigningCosign = "cosign"
+)
+
+// TransferSigning [save_image.signing] save-image 对传输清单 (各镜像归档的 sha256) 的签名方式，
+// load-image 导入前使用 public_key 验证签名。未配置 method 时清单只包含校验和，能发现传输中的损坏但不能防止篡改
+type TransferSigning struct {
+	Method    string `toml:"method,omitempty"`     // gpg 或 cosign
+	Key       string `toml:"key,omitempty"`        // gpg: 签名使用的密钥 ID，为空时使用默认密钥；cosign: 私钥文件
+	PublicKey string `toml:"public_key,omitempty"` // gpg: gpg --export 导出的公钥文件，为空时使用默认密钥环；cosign: 公钥文件
+}
+
+// ValidateTransferSigning 验证 [save_image.signing]
+func ValidateTransferSigning(config *ClusterConfig) error {
+	signing := config.SaveImage.Signing
+	switch signing.Method {
+	case "":
+		if signing.Key != "" || signing.PublicKey != "" {
+			return i18n.Errorf("设置 save_image.signing.key 或 public_key 时需要同时设置 method (%s 或 %s)", SigningGPG, SigningCosign)
+		}
+	case SigningGPG:
+	case SigningCosign:
+		if signing.Key == "" && signing.PublicKey == "" {
+			return i18n.Errorf("save_image.signing.method = %q 时需要设置 key (save-image) 或 public_key (load-image)", SigningCosign)
+		}
+	default:
+		return i18n.Errorf("save_image.signing.method %q 无效，可选值: %s、%s", signing.Method, SigningGPG, SigningCosign)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestValidateTransferSigning(t *testing.T) {
+	tests := []struct {
+		name    string
+		signing TransferSigning
+		valid   bool
+	}{
+		{"checksums only", TransferSigning{}, true},
+		{"gpg default key", TransferSigning{Method: SigningGPG}, true},
+		{"cosign", TransferSigning{Method: SigningCosign, Key: "cosign.key", PublicKey: "cosign.pub"}, true},
+		{"cosign without keys", TransferSigning{Method: SigningCosign}, false},
+		{"key without method", TransferSigning{Key: "cosign.key"}, false},
+		{"unknown method", TransferSigning{Method: "minisign"}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.Signing = tt.signing
+		if err := ValidateTransferSigning(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateTransferSigning error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
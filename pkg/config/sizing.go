@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// NodeSizing 节点的最低规格要求
+type NodeSizing struct {
+	CPU      int
+	MemoryGB int
+	DiskGB   int
+}
+
+// OpenShift 文档中 Agent-based 安装的最低资源要求。单节点和紧凑集群 (无 Worker) 的
+// Control Plane 节点同时承载业务负载，要求高于标准集群的 Control Plane
+var (
+	SNOMinimum          = NodeSizing{CPU: 8, MemoryGB: 16, DiskGB: 120}
+	CompactMinimum      = NodeSizing{CPU: 8, MemoryGB: 16, DiskGB: 120}
+	ControlPlaneMinimum = NodeSizing{CPU: 4, MemoryGB: 16, DiskGB: 120}
+	WorkerMinimum       = NodeSizing{CPU: 2, MemoryGB: 8, DiskGB: 120}
+)
+
+// ControlPlaneSizing 按集群拓扑返回 Control Plane 节点的最低要求和拓扑名称
+func (c *ClusterConfig) ControlPlaneSizing() (NodeSizing, string) {
+	switch {
+	case len(c.Cluster.ControlPlane) == 1 && len(c.Cluster.Worker) == 0:
+		return SNOMinimum, i18n.T("单节点 (SNO)")
+	case len(c.Cluster.Worker) == 0:
+		return CompactMinimum, i18n.T("紧凑集群")
+	default:
+		return ControlPlaneMinimum, i18n.T("标准集群")
+	}
+}
+
+// CheckNodeSizing 检查配置了 cpu、memory_gb 或 disk_gb 的节点是否满足最低要求，
+// 返回不满足要求的警告信息。未配置的字段不做检查
+func CheckNodeSizing(config *ClusterConfig) []string {
+	var warnings []string
+	minimum, topology := config.ControlPlaneSizing()
+	for _, node := range config.Cluster.ControlPlane {
+		if warning := checkNode(node, minimum); warning != "" {
+			warnings = append(warnings, i18n.Sprintf("%s Control Plane 节点 %s %s", topology, node.Name, warning))
+		}
+	}
+	for _, node := range config.Cluster.Worker {
+		if warning := checkNode(node, WorkerMinimum); warning != "" {
+			warnings = append(warnings, i18n.Sprintf("Worker 节点 %s %s", node.Name, warning))
+		}
+	}
+	return warnings
+}
+
+// checkNode 返回节点低于最低要求的项目，满足要求时返回空字符串
+func checkNode(node Node, minimum NodeSizing) string {
+	var below []string
+	if node.CPU > 0 && node.CPU < minimum.CPU {
+		below = append(below, fmt.Sprintf("cpu %d < %d", node.CPU, minimum.CPU))
+	}
+	if node.MemoryGB > 0 && node.MemoryGB < minimum.MemoryGB {
+		below = append(below, fmt.Sprintf("memory_gb %d < %d", node.MemoryGB, minimum.MemoryGB))
+	}
+	if node.DiskGB > 0 && node.DiskGB < minimum.DiskGB {
+		below = append(below, fmt.Sprintf("disk_gb %d < %d", node.DiskGB, minimum.DiskGB))
+	}
+	if len(below) == 0 {
+		return ""
+	}
+	return i18n.Sprintf("低于 OpenShift 最低要求: %s", strings.Join(below, ", "))
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckNodeSizing(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	if warnings := CheckNodeSizing(cfg); len(warnings) != 0 {
+		t.Errorf("nodes without sizing should not warn, got %v", warnings)
+	}
+
+	cfg.Cluster.ControlPlane[0].CPU = 4
+	cfg.Cluster.ControlPlane[0].MemoryGB = 16
+	cfg.Cluster.ControlPlane[0].DiskGB = 120
+	cfg.Cluster.Worker[0].CPU = 2
+	cfg.Cluster.Worker[0].MemoryGB = 4
+	warnings := CheckNodeSizing(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "worker-0") || !strings.Contains(warnings[0], "memory_gb 4 < 8") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	// 紧凑集群的 Control Plane 需要承载业务负载
+	cfg.Cluster.Worker = nil
+	warnings = CheckNodeSizing(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "紧凑集群") || !strings.Contains(warnings[0], "cpu 4 < 8") {
+		t.Errorf("unexpected compact warnings: %v", warnings)
+	}
+
+	cfg.Cluster.ControlPlane = cfg.Cluster.ControlPlane[:1]
+	cfg.Cluster.ControlPlane[0].DiskGB = 100
+	warnings = CheckNodeSizing(cfg)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "SNO") || !strings.Contains(warnings[0], "disk_gb 100 < 120") {
+		t.Errorf("unexpected SNO warnings: %v", warnings)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// 镜像数据支持的存储方式
+const (
+	StorageSchemeFile = "file"
+	StorageSchemeS3   = "s3"
+)
+
+// ImageStorage save-image 保存镜像归档 (mirror_*.tar) 的位置，对应 [save_image.storage]。
+// 未配置时保存在集群目录下的 images 目录。s3:// 存储同样先在该目录中暂存全部归档，再上传或导入
+type ImageStorage struct {
+	URL                string `toml:"url,omitempty"`                  // file:///mnt/nfs/ocp-images 或 s3://bucket/prefix
+	Endpoint           string `toml:"endpoint,omitempty"`             // S3 兼容存储的地址，如 https://minio.example.com:9000，为空时使用 AWS S3
+	Region             string `toml:"region,omitempty"`               // 可选，S3 区域
+	AccessKey          string `toml:"access_key,omitempty"`           // 可选，为空时使用 aws CLI 的默认凭据 (环境变量、~/.aws/credentials)
+	SecretKey          string `toml:"secret_key,omitempty"`           // 与 access_key 同时设置
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify,omitempty"` // 不校验 S3 服务的 TLS 证书
+}
+
+// Scheme 返回存储方式，未配置 url 时为 file
+func (s ImageStorage) Scheme() string {
+	if s.URL == "" {
+		return StorageSchemeFile
+	}
+	if idx := strings.Index(s.URL, "://"); idx >= 0 {
+		return s.URL[:idx]
+	}
+	return ""
+}
+
+// GetImagesDir 返回 save-image 写入、load-image 读取镜像归档的本地目录。
+// 使用 file:// 存储 (如 NFS 挂载点) 时直接读写该目录；未配置或使用 s3:// 存储时为集群目录下的 images，
+// s3:// 存储在保存后上传、加载前下载该目录中的归档
+func (c *ClusterConfig) GetImagesDir(clusterDir string) string {
+	storage := c.SaveImage.Storage
+	if storage.URL != "" && storage.Scheme() == StorageSchemeFile {
+		return filepath.Clean(strings.TrimPrefix(storage.URL, "file://"))
+	}
+	return filepath.Join(clusterDir, "images")
+}
+
+// ValidateImageStorage 验证 [save_image.storage] 配置
+func ValidateImageStorage(config *ClusterConfig) error {
+	storage := config.SaveImage.Storage
+	if storage.URL == "" {
+		return nil
+	}
+	switch storage.Scheme() {
+	case StorageSchemeFile:
+		if !filepath.IsAbs(strings.TrimPrefix(storage.URL, "file://")) {
+			return i18n.Errorf("save_image.storage.url %q 必须是绝对路径，如 file:///mnt/nfs/ocp-images", storage.URL)
+		}
+		if storage.Endpoint != "" || storage.AccessKey != "" || storage.SecretKey != "" {
+			return i18n.Errorf("save_image.storage 的 endpoint、access_key 和 secret_key 只适用于 s3:// 存储")
+		}
+	case StorageSchemeS3:
+		parsed, err := url.Parse(storage.URL)
+		if err != nil || parsed.Host == "" {
+			return i18n.Errorf("save_image.storage.url %q 无效，格式为 s3://<bucket>/<前缀>", storage.URL)
+		}
+		if storage.Endpoint != "" {
+			endpoint, err := url.Parse(storage.Endpoint)
+			if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+				return i18n.Errorf("save_image.storage.endpoint %q 必须是 http 或 https 地址", storage.Endpoint)
+			}
+		}
+		if (storage.AccessKey == "") != (storage.SecretKey == "") {
+			return i18n.Errorf("save_image.storage 的 access_key 和 secret_key 必须同时设置")
+		}
+	default:
+		return i18n.Errorf("save_image.storage.url %q 不支持，支持: file://<路径>、s3://<bucket>/<前缀>", storage.URL)
+	}
+	return nil
+}
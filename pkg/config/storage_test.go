@@ -0,0 +1,48 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateImageStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage ImageStorage
+		valid   bool
+	}{
+		{"default", ImageStorage{}, true},
+		{"nfs", ImageStorage{URL: "file:///mnt/nfs/ocp-images"}, true},
+		{"relative path", ImageStorage{URL: "file://images"}, false},
+		{"file with credentials", ImageStorage{URL: "file:///mnt/nfs", AccessKey: "a", SecretKey: "b"}, false},
+		{"s3", ImageStorage{URL: "s3://ocp-mirror/demo", Endpoint: "https://minio.example.com:9000", AccessKey: "a", SecretKey: "b"}, true},
+		{"s3 default credentials", ImageStorage{URL: "s3://ocp-mirror"}, true},
+		{"s3 without bucket", ImageStorage{URL: "s3:///demo"}, false},
+		{"s3 invalid endpoint", ImageStorage{URL: "s3://ocp-mirror", Endpoint: "minio:9000"}, false},
+		{"s3 partial credentials", ImageStorage{URL: "s3://ocp-mirror", AccessKey: "a"}, false},
+		{"unsupported", ImageStorage{URL: "gs://ocp-mirror"}, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.Storage = tt.storage
+		if err := ValidateImageStorage(cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateImageStorage error = %v, expected valid = %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestGetImagesDir(t *testing.T) {
+	clusterDir := filepath.Join("/work", "demo")
+	cfg := NewDefaultConfig("demo")
+	if got := cfg.GetImagesDir(clusterDir); got != filepath.Join(clusterDir, "images") {
+		t.Errorf("default images dir = %s", got)
+	}
+	cfg.SaveImage.Storage.URL = "file:///mnt/nfs/ocp-images/"
+	if got := cfg.GetImagesDir(clusterDir); got != "/mnt/nfs/ocp-images" {
+		t.Errorf("file storage images dir = %s", got)
+	}
+	cfg.SaveImage.Storage.URL = "s3://ocp-mirror/demo"
+	if got := cfg.GetImagesDir(clusterDir); got != filepath.Join(clusterDir, "images") {
+		t.Errorf("s3 staging dir = %s", got)
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	"ocpack/pkg/utils"
+)
+
+// GetSupportImages 返回 save_image.support_images = true 时自动镜像的排障镜像，标签与 openshift_version 对应:
+// must-gather 用于 oc adm must-gather --image，support-tools 是 RHCOS 上 toolbox 使用的镜像，
+// tools 用于 oc debug node --image。4.16 起 openshift4 下的镜像使用 -rhel9 后缀，4.13 起 RHCOS 基于 RHEL 9
+func (c *ClusterConfig) GetSupportImages() []string {
+	if !c.SaveImage.SupportImages {
+		return nil
+	}
+
+	version := c.ClusterInfo.OpenShiftVersion
+	tag := "v" + utils.ExtractMajorVersion(version)
+
+	suffix := ""
+	if utils.CompareVersion(version, "4.16.0") >= 0 {
+		suffix = "-rhel9"
+	}
+	rhel := "rhel8"
+	if utils.CompareVersion(version, "4.13.0") >= 0 {
+		rhel = "rhel9"
+	}
+
+	return []string{
+		fmt.Sprintf("registry.redhat.io/openshift4/ose-must-gather%s:%s", suffix, tag),
+		fmt.Sprintf("registry.redhat.io/openshift4/ose-tools%s:%s", suffix, tag),
+		fmt.Sprintf("registry.redhat.io/%s/support-tools:latest", rhel),
+	}
+}
+
+// GetAdditionalImages 返回需要镜像的额外镜像: additional_images、排障镜像和 OpenShift Virtualization 启动源，
+// 重复的镜像只保留一次
+func (c *ClusterConfig) GetAdditionalImages() []string {
+	candidates := append([]string(nil), c.SaveImage.AdditionalImages...)
+	candidates = append(candidates, c.GetSupportImages()...)
+	candidates = append(candidates, c.GetCNVBootSourceImages()...)
+
+	seen := make(map[string]bool)
+	var images []string
+	for _, image := range candidates {
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	return images
+}
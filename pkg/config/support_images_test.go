@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSupportImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		enabled bool
+		want    []string
+	}{
+		{"disabled", "4.14.10", false, nil},
+		{"4.12", "4.12.30", true, []string{
+			"registry.redhat.io/openshift4/ose-must-gather:v4.12",
+			"registry.redhat.io/openshift4/ose-tools:v4.12",
+			"registry.redhat.io/rhel8/support-tools:latest",
+		}},
+		{"4.14", "4.14.10", true, []string{
+			"registry.redhat.io/openshift4/ose-must-gather:v4.14",
+			"registry.redhat.io/openshift4/ose-tools:v4.14",
+			"registry.redhat.io/rhel9/support-tools:latest",
+		}},
+		{"4.16", "4.16.3", true, []string{
+			"registry.redhat.io/openshift4/ose-must-gather-rhel9:v4.16",
+			"registry.redhat.io/openshift4/ose-tools-rhel9:v4.16",
+			"registry.redhat.io/rhel9/support-tools:latest",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig("demo")
+			cfg.ClusterInfo.OpenShiftVersion = tt.version
+			cfg.SaveImage.SupportImages = tt.enabled
+			if got := cfg.GetSupportImages(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSupportImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAdditionalImages(t *testing.T) {
+	cfg := NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.14.10"
+	cfg.SaveImage.AdditionalImages = []string{
+		"quay.io/example/app:v1",
+		"registry.redhat.io/rhel9/support-tools:latest",
+	}
+
+	if got, want := cfg.GetAdditionalImages(), cfg.SaveImage.AdditionalImages; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAdditionalImages() = %v, want %v", got, want)
+	}
+
+	cfg.SaveImage.SupportImages = true
+	want := []string{
+		"quay.io/example/app:v1",
+		"registry.redhat.io/rhel9/support-tools:latest",
+		"registry.redhat.io/openshift4/ose-must-gather:v4.14",
+		"registry.redhat.io/openshift4/ose-tools:v4.14",
+	}
+	if got := cfg.GetAdditionalImages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAdditionalImages() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"net/url"
+	"time"
+
+	"ocpack/pkg/i18n"
+)
+
+// UpdateURLOverrideEnv oc-mirror 读取的环境变量，设置后使用该地址替代官方 Cincinnati API 查询升级图，
+// 并且不再下载 graph-data 重新构建 graph 镜像，而是复用缓存或工作目录中已有的 graph 镜像
+const UpdateURLOverrideEnv = "UPDATE_URL_OVERRIDE"
+
+// DefaultGraphCacheTTL 未配置 graph_cache_ttl 时升级图查询结果的缓存时间
+const DefaultGraphCacheTTL = time.Hour
+
+// ValidateUpdateURLOverride 验证 [save_image] update_url_override，必须是 http 或 https 地址
+func ValidateUpdateURLOverride(config *ClusterConfig) error {
+	override := config.SaveImage.UpdateURLOverride
+	if override == "" {
+		return nil
+	}
+	u, err := url.Parse(override)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return i18n.Errorf("save_image.update_url_override %q 必须是 http 或 https 地址，如 https://osus.example.com/api/upgrades_info/graph", override)
+	}
+	return nil
+}
+
+// GetGraphCacheTTL 返回升级图查询结果的缓存时间，为 0 时每次都查询
+func (c *ClusterConfig) GetGraphCacheTTL() time.Duration {
+	if c.SaveImage.GraphCacheTTL == "" {
+		return DefaultGraphCacheTTL
+	}
+	ttl, err := time.ParseDuration(c.SaveImage.GraphCacheTTL)
+	if err != nil {
+		return DefaultGraphCacheTTL
+	}
+	return ttl
+}
+
+// ValidateGraphCacheTTL 验证 [save_image] graph_cache_ttl，必须是非负的时长，如 "30m"、"6h" 或 "0"
+func ValidateGraphCacheTTL(config *ClusterConfig) error {
+	value := config.SaveImage.GraphCacheTTL
+	if value == "" {
+		return nil
+	}
+	if ttl, err := time.ParseDuration(value); err != nil || ttl < 0 {
+		return i18n.Errorf("save_image.graph_cache_ttl %q 必须是非负的时长，如 \"30m\"、\"6h\" 或 \"0\"", value)
+	}
+	return nil
+}
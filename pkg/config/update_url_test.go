@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateUpdateURLOverride(t *testing.T) {
+	tests := []struct {
+		override string
+		valid    bool
+	}{
+		{"", true},
+		{"https://osus.example.com/api/upgrades_info/graph", true},
+		{"http://10.0.0.5:8080/graph", true},
+		{"osus.example.com/graph", false},
+		{"ftp://osus.example.com/graph", false},
+		{"https://", false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.UpdateURLOverride = tt.override
+		if err := ValidateUpdateURLOverride(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateUpdateURLOverride(%q) error = %v, expected valid = %t", tt.override, err, tt.valid)
+		}
+	}
+}
+
+func TestGraphCacheTTL(t *testing.T) {
+	tests := []struct {
+		value string
+		ttl   time.Duration
+		valid bool
+	}{
+		{"", DefaultGraphCacheTTL, true},
+		{"6h", 6 * time.Hour, true},
+		{"0", 0, true},
+		{"-1h", 0, false},
+		{"1d", 0, false},
+	}
+	for _, tt := range tests {
+		cfg := NewDefaultConfig("demo")
+		cfg.SaveImage.GraphCacheTTL = tt.value
+		if err := ValidateGraphCacheTTL(cfg); (err == nil) != tt.valid {
+			t.Errorf("ValidateGraphCacheTTL(%q) error = %v, expected valid = %t", tt.value, err, tt.valid)
+		}
+		if tt.valid && cfg.GetGraphCacheTTL() != tt.ttl {
+			t.Errorf("GetGraphCacheTTL(%q) = %s, want %s", tt.value, cfg.GetGraphCacheTTL(), tt.ttl)
+		}
+	}
+}
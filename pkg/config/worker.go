@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// AppendWorker 向配置文件追加一个 worker 节点。新节点以文本方式插入到 [cluster.network] 之前，
+// 保留文件中已有的注释和格式
+func AppendWorker(filePath, name, ip, mac string) error {
+	cfg, err := LoadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	if err := checkNodeConflict(cfg, name, ip, mac); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return i18n.Errorf("读取配置文件失败: %w", err)
+	}
+
+	block := fmt.Sprintf("[[cluster.worker]]\nname = %q\nip = %q\nmac = %q\n\n", name, ip, mac)
+	content := string(data)
+	if idx := strings.Index(content, "\n[cluster.network]"); idx >= 0 {
+		content = content[:idx+1] + block + content[idx+1:]
+	} else {
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "\n" + strings.TrimSuffix(block, "\n")
+	}
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return i18n.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// checkNodeConflict 检查新节点的名称、IP 和 MAC 是否与已有节点重复
+func checkNodeConflict(cfg *ClusterConfig, name, ip, mac string) error {
+	type node struct{ name, ip, mac string }
+	var nodes []node
+	for _, cp := range cfg.Cluster.ControlPlane {
+		nodes = append(nodes, node{cp.Name, cp.IP, cp.MAC})
+	}
+	for _, worker := range cfg.Cluster.Worker {
+		nodes = append(nodes, node{worker.Name, worker.IP, worker.MAC})
+	}
+
+	for _, n := range nodes {
+		switch {
+		case n.name == name:
+			return i18n.Errorf("节点名称 %s 已存在", name)
+		case n.ip != "" && n.ip == ip:
+			return i18n.Errorf("IP %s 已被节点 %s 使用", ip, n.name)
+		case n.mac != "" && strings.EqualFold(n.mac, mac):
+			return i18n.Errorf("MAC 地址 %s 已被节点 %s 使用", mac, n.name)
+		}
+	}
+	return nil
+}
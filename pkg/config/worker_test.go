@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendWorker(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := GenerateDefaultConfig(configPath, "demo"); err != nil {
+		t.Fatalf("GenerateDefaultConfig() error = %v", err)
+	}
+
+	if err := AppendWorker(configPath, "worker-2", "192.168.1.32", "52:54:00:00:00:32"); err != nil {
+		t.Fatalf("AppendWorker() error = %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Cluster.Worker) != 3 {
+		t.Fatalf("expected 3 workers, got %d", len(cfg.Cluster.Worker))
+	}
+	added := cfg.Cluster.Worker[2]
+	if added.Name != "worker-2" || added.IP != "192.168.1.32" || added.MAC != "52:54:00:00:00:32" {
+		t.Errorf("unexpected worker: %+v", added)
+	}
+	if cfg.Cluster.Network.MachineNetwork != "192.168.1.0/24" {
+		t.Errorf("MachineNetwork = %q, network table should be preserved", cfg.Cluster.Network.MachineNetwork)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# Worker 节点配置") {
+		t.Error("comments should be preserved")
+	}
+
+	if err := AppendWorker(configPath, "worker-2", "192.168.1.33", "52:54:00:00:00:33"); err == nil {
+		t.Error("expected error for duplicate worker name")
+	}
+	if err := AppendWorker(configPath, "worker-3", "192.168.1.32", "52:54:00:00:00:33"); err == nil {
+		t.Error("expected error for duplicate IP")
+	}
+}
@@ -0,0 +1,288 @@
+package day2
+
+import (
+	"context"
+	"crypto/x509"
+	"embed"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/utils"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// --- Constants ---
+const (
+	addWorkerDirName       = "add-worker"
+	nodesConfigFilename    = "nodes-config.yaml"
+	nodesConfigTemplate    = "templates/nodes-config.yaml"
+	defaultWorkerInterface = "ens3"
+	csrPollInterval        = 30 * time.Second
+	csrMaxAttempts         = 60
+)
+
+// AddWorkerOptions add-worker 命令选项
+type AddWorkerOptions struct {
+	Name       string
+	IP         string
+	MAC        string
+	PXE        bool // 生成 PXE 启动文件而不是 ISO
+	ApproveCSR bool // 通过 kubeconfig 自动批准新节点的 CSR
+}
+
+// nodesConfigData nodes-config.yaml 模板数据
+type nodesConfigData struct {
+	Hostname       string
+	MACAddress     string
+	IPAddress      string
+	Interface      string
+	PrefixLength   int
+	NextHopAddress string
+	DNSServer      string   // 第一个 DNS 服务器，保留用于兼容已导出的自定义模板
+	DNSServers     []string // 节点使用的全部 DNS 服务器
+}
+
+// DumpTemplates 将 add-worker 使用的内置模板导出到 dir，供 <cluster>/templates/ 覆盖使用
+func DumpTemplates(dir string, force bool) ([]string, error) {
+	return utils.DumpTemplates(templates, []string{nodesConfigTemplate}, dir, force)
+}
+
+// AddWorker 将新的 worker 节点加入 config.toml，并使用 oc adm node-image create 基于集群现有的
+// ignition 和 CA 生成该节点的启动介质
+func (c *Client) AddWorker(ctx context.Context, clusterName, clusterDir string, opts *AddWorkerOptions) error {
+	c = c.withContext(ctx)
+	fmt.Printf("🔧 开始为集群 %s 添加 worker 节点 %s\n", clusterName, opts.Name)
+
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 找到 kubeconfig: %s\n", kubeconfigPath)
+
+	steps := 4
+	fmt.Printf("➡️  步骤 1/%d: 将节点写入 config.toml\n", steps)
+	cfg, err := registerWorker(clusterDir, opts)
+	if err != nil {
+		return fmt.Errorf("更新 config.toml 失败: %w", err)
+	}
+
+	fmt.Printf("➡️  步骤 2/%d: 生成 %s\n", steps, nodesConfigFilename)
+	workDir := filepath.Join(clusterDir, addWorkerDirName, opts.Name)
+	if err := generateNodesConfig(cfg, clusterDir, workDir, opts); err != nil {
+		return fmt.Errorf("生成 %s 失败: %w", nodesConfigFilename, err)
+	}
+	fmt.Printf("✅ %s 已生成\n", filepath.Join(workDir, nodesConfigFilename))
+
+	fmt.Printf("➡️  步骤 3/%d: 生成节点启动介质\n", steps)
+	if err := c.createNodeImage(clusterDir, workDir, kubeconfigPath, opts.PXE); err != nil {
+		return fmt.Errorf("生成节点启动介质失败: %w", err)
+	}
+	fmt.Printf("✅ 节点启动介质已生成: %s\n", workDir)
+
+	fmt.Printf("➡️  步骤 4/%d: 批准节点 CSR\n", steps)
+	printAddWorkerNextSteps(clusterName, opts)
+	if !opts.ApproveCSR {
+		printCSRInstructions(kubeconfigPath, opts.IP)
+		return nil
+	}
+	return c.approveNodeCSRs(kubeconfigPath, opts.Name)
+}
+
+// registerWorker 将新节点追加到 config.toml，节点已以相同参数存在时直接复用
+func registerWorker(clusterDir string, opts *AddWorkerOptions) (*config.ClusterConfig, error) {
+	configPath := filepath.Join(clusterDir, "config.toml")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, worker := range cfg.Cluster.Worker {
+		if worker.Name == opts.Name && worker.IP == opts.IP && strings.EqualFold(worker.MAC, opts.MAC) {
+			fmt.Printf("ℹ️  节点 %s 已存在于 config.toml，跳过写入\n", opts.Name)
+			return cfg, nil
+		}
+	}
+
+	if err := config.AppendWorker(configPath, opts.Name, opts.IP, opts.MAC); err != nil {
+		return nil, err
+	}
+	fmt.Printf("✅ 节点 %s (%s, %s) 已写入 config.toml\n", opts.Name, opts.IP, opts.MAC)
+	return config.LoadConfig(configPath)
+}
+
+// generateNodesConfig 渲染 oc adm node-image create 所需的 nodes-config.yaml
+func generateNodesConfig(cfg *config.ClusterConfig, clusterDir, workDir string, opts *AddWorkerOptions) error {
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return fmt.Errorf("创建目录 %s 失败: %w", workDir, err)
+	}
+
+	tmplContent, source, err := utils.ReadTemplate(clusterDir, templates, nodesConfigTemplate)
+	if err != nil {
+		return err
+	}
+	if source != "" {
+		fmt.Printf("ℹ️  Using custom template: %s\n", source)
+	}
+
+	tmpl, err := template.New(nodesConfigFilename).Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("解析模板 %s 失败: %w", nodesConfigTemplate, err)
+	}
+
+	file, err := os.Create(filepath.Join(workDir, nodesConfigFilename))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data := nodesConfigData{
+		Hostname:       opts.Name,
+		MACAddress:     opts.MAC,
+		IPAddress:      opts.IP,
+		Interface:      defaultWorkerInterface,
+		PrefixLength:   utils.ExtractPrefixLength(cfg.Cluster.Network.MachineNetwork),
+		NextHopAddress: utils.ExtractGateway(cfg.Cluster.Network.MachineNetwork),
+		DNSServer:      cfg.GetDNSServers()[0],
+		DNSServers:     cfg.GetDNSServers(),
+	}
+	return tmpl.Execute(file, data)
+}
+
+// createNodeImage 执行 oc adm node-image create 生成 ISO 或 PXE 启动文件
+func (c *Client) createNodeImage(clusterDir, workDir, kubeconfigPath string, pxe bool) error {
+	args := []string{"adm", "node-image", "create", "--dir", workDir, "--kubeconfig", kubeconfigPath}
+	if pxe {
+		args = append(args, "--pxe")
+	}
+	mergedAuthPath := auth.MergedAuthPath(clusterDir)
+	if _, err := os.Stat(mergedAuthPath); err == nil {
+		args = append(args, "--registry-config", mergedAuthPath)
+	}
+
+	cmd := runner.Command{Name: "oc", Args: args, Stream: true}
+	fmt.Printf("ℹ️  执行命令: %s\n", cmd)
+	if _, err := c.Runner.Run(cmd); err != nil {
+		return fmt.Errorf("执行 oc adm node-image create 失败 (需要 oc 4.17 及以上版本): %w", err)
+	}
+	return nil
+}
+
+// printAddWorkerNextSteps 提示启动新节点前需要完成的操作
+func printAddWorkerNextSteps(clusterName string, opts *AddWorkerOptions) {
+	media := "ISO"
+	if opts.PXE {
+		media = "PXE 启动文件"
+	}
+	fmt.Println("📋 启动新节点前请确认:")
+	fmt.Printf("   1. Bastion DNS 已包含 %s 的解析记录，可执行 'ocpack deploy-bastion %s' 更新 DNS 和 HAProxy\n", opts.Name, clusterName)
+	fmt.Printf("      或执行 'ocpack render bastion-config %s' 在本地生成配置后手动应用\n", clusterName)
+	fmt.Printf("   2. 使用生成的 %s 启动 MAC 地址为 %s 的机器\n", media, opts.MAC)
+}
+
+// printCSRInstructions 打印手动批准 CSR 的步骤
+func printCSRInstructions(kubeconfigPath, ip string) {
+	fmt.Println("💡 节点启动后需要批准其 CSR 才能加入集群:")
+	fmt.Printf("   export KUBECONFIG=%s\n", kubeconfigPath)
+	fmt.Printf("   oc adm node-image monitor --ip-addresses %s\n", ip)
+	fmt.Println("   oc get csr | grep Pending")
+	fmt.Println("   oc adm certificate approve <csr-name>")
+	fmt.Println("   (节点会依次提交 client 和 serving 两个 CSR，均需批准)")
+}
+
+// nodeCSRList oc get csr -o json 的输出中需要的字段
+type nodeCSRList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Request  []byte `json:"request"`
+			Username string `json:"username"`
+		} `json:"spec"`
+		Status struct {
+			Conditions []struct {
+				Type string `json:"type"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// approveNodeCSRs 轮询并批准新节点的 client 和 serving CSR，直到 serving CSR 被批准
+func (c *Client) approveNodeCSRs(kubeconfigPath, nodeName string) error {
+	nodeUser := "system:node:" + nodeName
+	fmt.Printf("⏳ 等待节点 %s 提交 CSR，请启动该节点...\n", nodeName)
+
+	for i := 1; i <= csrMaxAttempts; i++ {
+		result, err := c.runOC("get", "csr", "-o", "json", "--kubeconfig", kubeconfigPath)
+		output := result.Stdout
+		if err != nil {
+			fmt.Printf("⚠️  获取 CSR 列表失败 (尝试 %d/%d): %v\n", i, csrMaxAttempts, err)
+		} else {
+			var csrs nodeCSRList
+			if err := json.Unmarshal(output, &csrs); err != nil {
+				return fmt.Errorf("解析 CSR 列表失败: %w", err)
+			}
+
+			for _, csr := range csrs.Items {
+				if csrCommonName(csr.Spec.Request) != nodeUser {
+					continue
+				}
+				approved := len(csr.Status.Conditions) > 0
+				if !approved {
+					if err := c.approveCSR(kubeconfigPath, csr.Metadata.Name); err != nil {
+						return err
+					}
+					fmt.Printf("✅ 已批准 CSR: %s (%s)\n", csr.Metadata.Name, csr.Spec.Username)
+				}
+				// serving CSR 由节点自身提交，批准后节点即可加入集群
+				if csr.Spec.Username == nodeUser {
+					fmt.Printf("🎉 节点 %s 的 CSR 已全部批准\n", nodeName)
+					return nil
+				}
+			}
+		}
+
+		if i < csrMaxAttempts {
+			fmt.Print(".")
+			if err := c.wait(csrPollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return fmt.Errorf("等待超时，未检测到节点 %s 的 serving CSR，请使用 'oc get csr' 检查并手动批准", nodeName)
+}
+
+// csrCommonName 解析 CSR 请求中的 CommonName，解析失败时返回空字符串
+func csrCommonName(request []byte) string {
+	block, _ := pem.Decode(request)
+	if block == nil {
+		return ""
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return csr.Subject.CommonName
+}
+
+// approveCSR 批准指定的 CSR
+func (c *Client) approveCSR(kubeconfigPath, name string) error {
+	result, err := c.runOC("adm", "certificate", "approve", name, "--kubeconfig", kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("批准 CSR %s 失败: %w\n输出: %s", name, err, string(result.Combined))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package day2
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func newCSRRequest(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestApproveNodeCSRs(t *testing.T) {
+	const bootstrapper = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+	csr := func(name, commonName, username string) map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec":     map[string]interface{}{"request": newCSRRequest(t, commonName), "username": username},
+		}
+	}
+	list := map[string]interface{}{"items": []interface{}{
+		// 其他节点的 CSR 不应被批准
+		csr("csr-other", "system:node:worker-0", bootstrapper),
+		csr("csr-client", "system:node:worker-3", bootstrapper),
+		csr("csr-serving", "system:node:worker-3", "system:node:worker-3"),
+	}}
+	output, err := json.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[0] == "get" {
+			return &runner.Result{Stdout: output}, nil
+		}
+		return nil, nil
+	}}
+	c := &Client{Runner: fake}
+
+	if err := c.approveNodeCSRs("/tmp/kubeconfig", "worker-3"); err != nil {
+		t.Fatalf("approveNodeCSRs() error = %v", err)
+	}
+
+	expected := []string{
+		"oc get csr -o json --kubeconfig /tmp/kubeconfig",
+		"oc adm certificate approve csr-client --kubeconfig /tmp/kubeconfig",
+		"oc adm certificate approve csr-serving --kubeconfig /tmp/kubeconfig",
+	}
+	if got := fake.CommandLines(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("commands = %q, expected %q", got, expected)
+	}
+	for _, cmd := range fake.Calls() {
+		if cmd.Timeout != runner.DefaultTimeout {
+			t.Errorf("%s timeout = %s, expected %s", cmd, cmd.Timeout, runner.DefaultTimeout)
+		}
+	}
+}
+
+func TestApproveNodeCSRsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fake := &runner.Fake{}
+	c := (&Client{Runner: fake}).withContext(ctx)
+
+	// 取消后不再轮询，而不是等到 csrMaxAttempts 次尝试结束
+	if err := c.approveNodeCSRs("/tmp/kubeconfig", "worker-3"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("approveNodeCSRs() error = %v, want context.Canceled", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("oc ran after cancel: %v", fake.CommandLines())
+	}
+}
+
+func TestGenerateNodesConfigDNSServers(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Cluster.Network.MachineNetwork = "192.168.1.0/24"
+	cfg.Infra.DNSServers = []string{"192.168.1.2", "192.168.1.3"}
+	workDir := filepath.Join(t.TempDir(), "work")
+
+	opts := &AddWorkerOptions{Name: "worker-2", IP: "192.168.1.30", MAC: "52:54:00:00:00:30"}
+	if err := generateNodesConfig(cfg, t.TempDir(), workDir, opts); err != nil {
+		t.Fatalf("generateNodesConfig() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(workDir, nodesConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- 192.168.1.2\n            - 192.168.1.3\n") {
+		t.Errorf("nodes-config.yaml missing DNS servers:\n%s", content)
+	}
+}
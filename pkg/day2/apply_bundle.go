@@ -0,0 +1,458 @@
+package day2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bundleFieldManager server-side apply 使用的字段管理者名称
+const bundleFieldManager = "ocpack"
+
+// DefaultBundleTimeout apply-bundle 等待资源就绪的默认超时时间，MachineConfig 会触发节点滚动重启，耗时较长
+const DefaultBundleTimeout = 60 * time.Minute
+
+// mcpRolloutGrace 应用 MachineConfig 后等待 MachineConfigPool 开始渲染新配置的时间，超过后视为配置未变化
+var mcpRolloutGrace = 3 * time.Minute
+
+// bundlePollInterval 轮询 MachineConfigPool 状态的间隔，测试时可缩短
+var bundlePollInterval = 15 * time.Second
+
+// ApplyBundleOptions apply-bundle 的选项
+type ApplyBundleOptions struct {
+	DryRun  bool          // 使用 --dry-run=server，只校验不修改集群
+	NoWait  bool          // 应用后不等待资源就绪
+	Timeout time.Duration // 等待资源就绪的超时时间，为 0 时使用 DefaultBundleTimeout
+}
+
+// bundleObject 清单目录中的单个资源
+type bundleObject struct {
+	File      string
+	Kind      string
+	Name      string
+	Namespace string
+	Content   []byte
+}
+
+// String 返回便于打印的资源名称，如 MachineConfig/99-worker-chrony
+func (o bundleObject) String() string {
+	if o.Namespace != "" {
+		return fmt.Sprintf("%s/%s (%s)", o.Kind, o.Name, o.Namespace)
+	}
+	return o.Kind + "/" + o.Name
+}
+
+// bundleKindOrder 资源的应用顺序：先创建命名空间和 CRD，再应用普通资源，
+// 最后应用会触发节点变更的 MachineConfigPool、MachineConfig 和 NodeNetworkConfigurationPolicy
+var bundleKindOrder = map[string]int{
+	"Namespace":                      0,
+	"CustomResourceDefinition":       1,
+	"ServiceAccount":                 2,
+	"ClusterRole":                    2,
+	"ClusterRoleBinding":             2,
+	"Role":                           2,
+	"RoleBinding":                    2,
+	"ConfigMap":                      2,
+	"Secret":                         2,
+	"OperatorGroup":                  3,
+	"Subscription":                   3,
+	"MachineConfigPool":              5,
+	"MachineConfig":                  6,
+	"KubeletConfig":                  6,
+	"ContainerRuntimeConfig":         6,
+	"Tuned":                          6,
+	"PerformanceProfile":             6,
+	"NodeNetworkConfigurationPolicy": 7,
+}
+
+// defaultBundleKindOrder 未列出的资源类型的顺序
+const defaultBundleKindOrder = 4
+
+// machineConfigKinds 由 Machine Config Operator 渲染到节点的资源，应用后需要等待 MachineConfigPool 完成更新
+var machineConfigKinds = map[string]bool{
+	"MachineConfig":          true,
+	"KubeletConfig":          true,
+	"ContainerRuntimeConfig": true,
+}
+
+// ApplyBundle 将目录中的 YAML 清单按顺序以 server-side apply 应用到集群，并等待资源就绪
+func (c *Client) ApplyBundle(ctx context.Context, clusterName, clusterDir, bundleDir string, options ApplyBundleOptions) error {
+	c = c.withContext(ctx)
+	fmt.Printf("🔧 开始将清单目录 %s 应用到集群 %s\n", bundleDir, clusterName)
+
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 找到 kubeconfig: %s\n", kubeconfigPath)
+
+	objects, err := loadBundle(bundleDir)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return fmt.Errorf("目录 %s 中没有找到 YAML 清单", bundleDir)
+	}
+	fmt.Printf("📋 共 %d 个资源\n", len(objects))
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBundleTimeout
+	}
+
+	// 记录应用前各 MachineConfigPool 的渲染配置，用于判断 MachineConfig 是否触发了更新
+	hasMachineConfig := false
+	for _, obj := range objects {
+		if machineConfigKinds[obj.Kind] {
+			hasMachineConfig = true
+		}
+	}
+	var poolsBefore map[string]string
+	if hasMachineConfig && !options.DryRun && !options.NoWait {
+		pools, err := c.getMachineConfigPools(kubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("获取 MachineConfigPool 状态失败: %w", err)
+		}
+		poolsBefore = renderedConfigs(pools)
+	}
+
+	fmt.Println("➡️  步骤 1/2: 应用资源")
+	for _, obj := range objects {
+		if err := c.applyBundleObject(kubeconfigPath, obj, options.DryRun); err != nil {
+			return err
+		}
+		// 同一清单目录中的自定义资源依赖 CRD 已生效
+		if obj.Kind == "CustomResourceDefinition" && !options.DryRun {
+			if err := c.waitForCRDEstablished(kubeconfigPath, obj.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if options.DryRun {
+		fmt.Println("✅ 服务端校验通过 (--dry-run=server)，未修改集群")
+		return nil
+	}
+	fmt.Println("✅ 资源已应用")
+
+	if options.NoWait {
+		fmt.Println("⏭️  已跳过等待资源就绪 (--no-wait)")
+		return nil
+	}
+
+	fmt.Println("➡️  步骤 2/2: 等待资源就绪")
+	deadline := time.Now().Add(timeout)
+	for _, obj := range objects {
+		if err := c.verifyBundleObject(kubeconfigPath, obj, time.Until(deadline)); err != nil {
+			return err
+		}
+	}
+	if hasMachineConfig {
+		if err := c.waitForMachineConfigPools(kubeconfigPath, poolsBefore, deadline); err != nil {
+			return err
+		}
+	}
+	fmt.Println("✅ 资源已就绪")
+	return nil
+}
+
+// loadBundle 按文件名顺序读取目录中的 *.yaml 和 *.yml 文件，拆分多文档 YAML 并按资源类型排序
+func loadBundle(dir string) ([]bundleObject, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取清单目录失败: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext == ".yaml" || ext == ".yml" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	var objects []bundleObject
+	for _, file := range files {
+		fileObjects, err := parseBundleFile(file)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, fileObjects...)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindOrder(objects[i].Kind) < kindOrder(objects[j].Kind)
+	})
+	return objects, nil
+}
+
+// parseBundleFile 拆分文件中的 YAML 文档，空文档被忽略，kind: List 展开为其中的资源
+func parseBundleFile(file string) ([]bundleObject, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取清单文件失败: %w", err)
+	}
+
+	var objects []bundleObject
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for index := 1; ; index++ {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("解析清单文件 %s 第 %d 个文档失败: %w", file, index, err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+
+		docs := []map[string]interface{}{doc}
+		if kind, _ := doc["kind"].(string); strings.HasSuffix(kind, "List") {
+			items, _ := doc["items"].([]interface{})
+			docs = docs[:0]
+			for _, item := range items {
+				if itemDoc, ok := item.(map[string]interface{}); ok {
+					docs = append(docs, itemDoc)
+				}
+			}
+		}
+
+		for _, d := range docs {
+			obj, err := newBundleObject(file, d)
+			if err != nil {
+				return nil, fmt.Errorf("清单文件 %s 第 %d 个文档无效: %w", file, index, err)
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// newBundleObject 从 YAML 文档中读取资源类型和名称，并重新序列化为单个资源
+func newBundleObject(file string, doc map[string]interface{}) (bundleObject, error) {
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	if apiVersion == "" || kind == "" {
+		return bundleObject{}, fmt.Errorf("缺少 apiVersion 或 kind")
+	}
+	if name == "" {
+		return bundleObject{}, fmt.Errorf("%s 缺少 metadata.name", kind)
+	}
+
+	content, err := yaml.Marshal(doc)
+	if err != nil {
+		return bundleObject{}, fmt.Errorf("序列化 %s/%s 失败: %w", kind, name, err)
+	}
+	return bundleObject{File: file, Kind: kind, Name: name, Namespace: namespace, Content: content}, nil
+}
+
+// kindOrder 返回资源类型的应用顺序
+func kindOrder(kind string) int {
+	if order, ok := bundleKindOrder[kind]; ok {
+		return order
+	}
+	return defaultBundleKindOrder
+}
+
+// applyBundleObject 以 server-side apply 应用单个资源，字段冲突时以清单为准
+func (c *Client) applyBundleObject(kubeconfigPath string, obj bundleObject, dryRun bool) error {
+	fmt.Printf("🔧 应用 %s\n", obj)
+
+	args := []string{"apply", "--server-side", "--force-conflicts",
+		"--field-manager=" + bundleFieldManager, "-f", "-"}
+	if dryRun {
+		args = append(args, "--dry-run=server")
+	}
+	args = append(args, "--kubeconfig", kubeconfigPath)
+
+	result, err := c.Runner.Run(runner.Command{Name: "oc", Args: args, Stdin: obj.Content, Timeout: runner.DefaultTimeout})
+	output := result.Combined
+	if err != nil {
+		return fmt.Errorf("应用 %s (%s) 失败: %w\n输出: %s", obj, filepath.Base(obj.File), err, strings.TrimSpace(string(output)))
+	}
+
+	fmt.Printf("📋 命令输出: %s\n", strings.TrimSpace(string(output)))
+	return nil
+}
+
+// waitForCRDEstablished 等待 CRD 变为 Established，之后才能应用对应的自定义资源
+func (c *Client) waitForCRDEstablished(kubeconfigPath, name string) error {
+	result, err := c.runOC("wait", "crd/"+name,
+		"--for=condition=Established",
+		"--timeout=60s",
+		"--kubeconfig", kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("等待 CRD %s 生效失败: %w\n输出: %s", name, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// verifyBundleObject 确认资源已存在于集群中；NodeNetworkConfigurationPolicy 还需等待 Available 条件
+func (c *Client) verifyBundleObject(kubeconfigPath string, obj bundleObject, timeout time.Duration) error {
+	if obj.Kind == "NodeNetworkConfigurationPolicy" {
+		return c.waitForNNCP(kubeconfigPath, obj.Name, timeout)
+	}
+
+	args := []string{"get", obj.Kind, obj.Name, "-o", "name"}
+	if obj.Namespace != "" {
+		args = append(args, "-n", obj.Namespace)
+	}
+	args = append(args, "--kubeconfig", kubeconfigPath)
+	if result, err := c.runOC(args...); err != nil {
+		return fmt.Errorf("未在集群中找到 %s: %w\n输出: %s", obj, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// waitForNNCP 等待 NodeNetworkConfigurationPolicy 在所有匹配的节点上生效
+func (c *Client) waitForNNCP(kubeconfigPath, name string, timeout time.Duration) error {
+	fmt.Printf("⏳ 等待 NodeNetworkConfigurationPolicy %s 变为 Available...\n", name)
+	if timeout <= 0 {
+		return fmt.Errorf("等待超时，NodeNetworkConfigurationPolicy %s 尚未变为 Available", name)
+	}
+
+	result, err := c.Runner.Run(runner.Command{
+		Name: "oc",
+		Args: []string{"wait", "nncp/" + name,
+			"--for=condition=Available",
+			"--timeout=" + timeout.Round(time.Second).String(),
+			"--kubeconfig", kubeconfigPath},
+		Timeout: timeout + runner.DefaultTimeout,
+	})
+	if err != nil {
+		fmt.Printf("💡 您可以手动检查状态: oc get nncp %s; oc get nnce\n", name)
+		return fmt.Errorf("等待 NodeNetworkConfigurationPolicy %s 失败: %w\n输出: %s", name, err, strings.TrimSpace(string(result.Combined)))
+	}
+	fmt.Printf("✅ NodeNetworkConfigurationPolicy %s 已生效\n", name)
+	return nil
+}
+
+// machineConfigPool MachineConfigPool 中判断更新进度所需的字段
+type machineConfigPool struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Configuration struct {
+			Name string `json:"name"`
+		} `json:"configuration"`
+	} `json:"spec"`
+	Status struct {
+		MachineCount        int `json:"machineCount"`
+		UpdatedMachineCount int `json:"updatedMachineCount"`
+		Conditions          []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// condition 返回指定类型条件的状态，不存在时为空
+func (p machineConfigPool) condition(conditionType string) (string, string) {
+	for _, c := range p.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status, c.Message
+		}
+	}
+	return "", ""
+}
+
+// getMachineConfigPools 获取集群中全部 MachineConfigPool
+func (c *Client) getMachineConfigPools(kubeconfigPath string) ([]machineConfigPool, error) {
+	result, err := c.runOC("get", "machineconfigpools", "-o", "json", "--kubeconfig", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n输出: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	var list struct {
+		Items []machineConfigPool `json:"items"`
+	}
+	if err := json.Unmarshal(result.Stdout, &list); err != nil {
+		return nil, fmt.Errorf("解析 MachineConfigPool 列表失败: %w", err)
+	}
+	return list.Items, nil
+}
+
+// renderedConfigs 返回各 MachineConfigPool 当前的渲染配置名称
+func renderedConfigs(pools []machineConfigPool) map[string]string {
+	configs := make(map[string]string, len(pools))
+	for _, pool := range pools {
+		configs[pool.Metadata.Name] = pool.Spec.Configuration.Name
+	}
+	return configs
+}
+
+// waitForMachineConfigPools 等待 MachineConfigPool 渲染新配置并完成全部节点的更新。
+// 在 mcpRolloutGrace 内没有任何池的渲染配置发生变化时，认为 MachineConfig 与现有配置一致，无需等待
+func (c *Client) waitForMachineConfigPools(kubeconfigPath string, before map[string]string, deadline time.Time) error {
+	fmt.Println("⏳ 等待 MachineConfigPool 完成更新 (节点将依次重启)...")
+
+	graceDeadline := time.Now().Add(mcpRolloutGrace)
+	changed := false
+	for {
+		pools, err := c.getMachineConfigPools(kubeconfigPath)
+		if err != nil {
+			fmt.Printf("⚠️  获取 MachineConfigPool 状态失败: %v\n", err)
+		} else {
+			if !changed {
+				for name, rendered := range renderedConfigs(pools) {
+					if before[name] != rendered {
+						fmt.Printf("🔍 MachineConfigPool %s 已渲染新配置: %s\n", name, rendered)
+						changed = true
+					}
+				}
+			}
+
+			if changed {
+				done := true
+				for _, pool := range pools {
+					if status, message := pool.condition("Degraded"); status == "True" {
+						return fmt.Errorf("MachineConfigPool %s 处于 Degraded 状态: %s", pool.Metadata.Name, message)
+					}
+					updated, _ := pool.condition("Updated")
+					if updated != "True" || pool.Status.UpdatedMachineCount != pool.Status.MachineCount {
+						fmt.Printf("🔍 MachineConfigPool %s: %d/%d 个节点已更新\n",
+							pool.Metadata.Name, pool.Status.UpdatedMachineCount, pool.Status.MachineCount)
+						done = false
+					}
+				}
+				if done {
+					fmt.Println("✅ MachineConfigPool 已完成更新")
+					return nil
+				}
+			} else if time.Now().After(graceDeadline) {
+				fmt.Println("✅ MachineConfig 未改变节点配置，无需等待更新")
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			fmt.Println("💡 您可以手动检查状态: oc get mcp")
+			return fmt.Errorf("等待超时，MachineConfigPool 尚未完成更新")
+		}
+		if err := c.wait(bundlePollInterval); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package day2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+func writeBundleFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadBundleOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeBundleFile(t, dir, "10-network.yaml", `apiVersion: nmstate.io/v1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: bond0
+spec: {}
+---
+apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-chrony
+`)
+	writeBundleFile(t, dir, "20-app.yml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: demo
+---
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+`)
+	writeBundleFile(t, dir, "30-list.yaml", `apiVersion: v1
+kind: List
+items:
+- apiVersion: tuned.openshift.io/v1
+  kind: Tuned
+  metadata:
+    name: sysctl
+    namespace: openshift-cluster-node-tuning-operator
+`)
+	writeBundleFile(t, dir, "README.md", "not a manifest")
+
+	objects, err := loadBundle(dir)
+	if err != nil {
+		t.Fatalf("loadBundle() error = %v", err)
+	}
+
+	var got []string
+	for _, obj := range objects {
+		got = append(got, obj.Kind+"/"+obj.Name)
+	}
+	want := []string{
+		"Namespace/demo",
+		"ConfigMap/settings",
+		"MachineConfig/99-worker-chrony",
+		"Tuned/sysctl",
+		"NodeNetworkConfigurationPolicy/bond0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadBundle() order = %v, want %v", got, want)
+	}
+	if objects[1].Namespace != "demo" {
+		t.Errorf("ConfigMap namespace = %q", objects[1].Namespace)
+	}
+	if strings.Contains(string(objects[0].Content), "ConfigMap") {
+		t.Errorf("Namespace content contains other documents:\n%s", objects[0].Content)
+	}
+}
+
+func TestLoadBundleInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing kind", "apiVersion: v1\nmetadata:\n  name: x\n", "缺少 apiVersion 或 kind"},
+		{"missing name", "apiVersion: v1\nkind: ConfigMap\nmetadata: {}\n", "缺少 metadata.name"},
+		{"invalid yaml", "kind: [\n", "解析清单文件"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeBundleFile(t, dir, "bad.yaml", tt.content)
+			if _, err := loadBundle(dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadBundle() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func setupBundleCluster(t *testing.T) string {
+	t.Helper()
+	clusterDir := t.TempDir()
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return clusterDir
+}
+
+func TestApplyBundle(t *testing.T) {
+	clusterDir := setupBundleCluster(t)
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	bundleDir := t.TempDir()
+	writeBundleFile(t, bundleDir, "bundle.yaml", `apiVersion: nmstate.io/v1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: bond0
+---
+apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-chrony
+`)
+
+	originalGrace, originalInterval := mcpRolloutGrace, bundlePollInterval
+	mcpRolloutGrace, bundlePollInterval = time.Minute, time.Millisecond
+	defer func() { mcpRolloutGrace, bundlePollInterval = originalGrace, originalInterval }()
+
+	// 第一次查询返回应用前的状态，第二次渲染了新配置但仍在更新，第三次完成更新
+	pools := []string{
+		`{"items":[{"metadata":{"name":"worker"},"spec":{"configuration":{"name":"rendered-worker-a"}},"status":{"machineCount":2,"updatedMachineCount":2,"conditions":[{"type":"Updated","status":"True"}]}}]}`,
+		`{"items":[{"metadata":{"name":"worker"},"spec":{"configuration":{"name":"rendered-worker-b"}},"status":{"machineCount":2,"updatedMachineCount":1,"conditions":[{"type":"Updated","status":"False"}]}}]}`,
+		`{"items":[{"metadata":{"name":"worker"},"spec":{"configuration":{"name":"rendered-worker-b"}},"status":{"machineCount":2,"updatedMachineCount":2,"conditions":[{"type":"Updated","status":"True"}]}}]}`,
+	}
+	var applied []string
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		switch cmd.Args[0] {
+		case "apply":
+			applied = append(applied, strings.SplitN(string(cmd.Stdin), "\n", 3)[1])
+		case "get":
+			if cmd.Args[1] == "machineconfigpools" {
+				out := pools[0]
+				if len(pools) > 1 {
+					pools = pools[1:]
+				}
+				return &runner.Result{Stdout: []byte(out)}, nil
+			}
+		}
+		return nil, nil
+	}}
+	c := &Client{Runner: fake}
+
+	if err := c.ApplyBundle(context.Background(), "demo", clusterDir, bundleDir, ApplyBundleOptions{Timeout: time.Minute}); err != nil {
+		t.Fatalf("ApplyBundle() error = %v", err)
+	}
+
+	if want := []string{"kind: MachineConfig", "kind: NodeNetworkConfigurationPolicy"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied = %v, want %v", applied, want)
+	}
+	lines := fake.CommandLines()
+	if want := "oc apply --server-side --force-conflicts --field-manager=ocpack -f - --kubeconfig " + kubeconfigPath; lines[1] != want {
+		t.Errorf("apply command = %q, want %q", lines[1], want)
+	}
+	if want := "oc get MachineConfig 99-worker-chrony -o name --kubeconfig " + kubeconfigPath; lines[3] != want {
+		t.Errorf("verify command = %q, want %q", lines[3], want)
+	}
+	if !strings.HasPrefix(lines[4], "oc wait nncp/bond0 --for=condition=Available --timeout=") {
+		t.Errorf("nncp wait command = %q", lines[4])
+	}
+	if len(pools) != 1 {
+		t.Errorf("expected MachineConfigPool to be polled until updated, %d responses left", len(pools))
+	}
+}
+
+func TestApplyBundleDryRun(t *testing.T) {
+	clusterDir := setupBundleCluster(t)
+	bundleDir := t.TempDir()
+	writeBundleFile(t, bundleDir, "mc.yaml", `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-chrony
+`)
+
+	fake := &runner.Fake{}
+	c := &Client{Runner: fake}
+
+	if err := c.ApplyBundle(context.Background(), "demo", clusterDir, bundleDir, ApplyBundleOptions{DryRun: true}); err != nil {
+		t.Fatalf("ApplyBundle() error = %v", err)
+	}
+	lines := fake.CommandLines()
+	if len(lines) != 1 || !strings.Contains(lines[0], "--dry-run=server") {
+		t.Errorf("commands = %v, want a single server-side dry run apply", lines)
+	}
+}
+
+func TestWaitForMachineConfigPoolsDegraded(t *testing.T) {
+	originalInterval := bundlePollInterval
+	bundlePollInterval = time.Millisecond
+	defer func() { bundlePollInterval = originalInterval }()
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Stdout: []byte(`{"items":[{"metadata":{"name":"worker"},"spec":{"configuration":{"name":"rendered-worker-b"}},"status":{"conditions":[{"type":"Degraded","status":"True","message":"failed to render"}]}}]}`)}, nil
+	}}
+	c := &Client{Runner: fake}
+
+	err := c.waitForMachineConfigPools("/tmp/kubeconfig", map[string]string{"worker": "rendered-worker-a"}, time.Now().Add(time.Minute))
+	if err == nil || !strings.Contains(err.Error(), "Degraded") {
+		t.Errorf("waitForMachineConfigPools() error = %v, want Degraded error", err)
+	}
+}
@@ -0,0 +1,160 @@
+package day2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/imagepolicy"
+	"ocpack/pkg/mirrormap"
+
+	"gopkg.in/yaml.v3"
+)
+
+// --- Constants ---
+const (
+	cnvBootSourcesDirName  = "cnv-boot-sources"
+	cnvBootSourcesFilename = "hyperconverged.yaml"
+	hyperConvergedName     = "kubevirt-hyperconverged"
+	hyperConvergedNS       = "openshift-cnv"
+	// 与 HyperConverged 内置启动源相同的导入周期和磁盘大小
+	bootSourceSchedule = "0 */12 * * *"
+	bootSourceStorage  = "30Gi"
+)
+
+// hyperConverged 只包含 ocpack 管理的字段，server-side apply 不会改动 HyperConverged 的其他配置
+type hyperConverged struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   objectMeta         `yaml:"metadata"`
+	Spec       hyperConvergedSpec `yaml:"spec"`
+}
+
+type objectMeta struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type hyperConvergedSpec struct {
+	EnableCommonBootImageImport bool                     `yaml:"enableCommonBootImageImport"`
+	DataImportCronTemplates     []dataImportCronTemplate `yaml:"dataImportCronTemplates"`
+}
+
+type dataImportCronTemplate struct {
+	Metadata objectMeta         `yaml:"metadata"`
+	Spec     dataImportCronSpec `yaml:"spec"`
+}
+
+type dataImportCronSpec struct {
+	Schedule          string             `yaml:"schedule"`
+	Template          dataVolumeTemplate `yaml:"template"`
+	GarbageCollect    string             `yaml:"garbageCollect"`
+	ManagedDataSource string             `yaml:"managedDataSource"`
+}
+
+type dataVolumeTemplate struct {
+	Spec struct {
+		Source struct {
+			Registry struct {
+				URL        string `yaml:"url"`
+				PullMethod string `yaml:"pullMethod"`
+			} `yaml:"registry"`
+		} `yaml:"source"`
+		Storage struct {
+			Resources struct {
+				Requests map[string]string `yaml:"requests"`
+			} `yaml:"resources"`
+		} `yaml:"storage"`
+	} `yaml:"spec"`
+}
+
+// CNVBootSourcesDir 返回 DataImportCron 清单的生成目录
+func CNVBootSourcesDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "day2", cnvBootSourcesDirName)
+}
+
+// RenderCNVBootSources 生成将 OpenShift Virtualization 启动源指向私有仓库的 HyperConverged 清单，返回清单目录。
+// 镜像地址优先使用 load-image 记录的镜像映射，其余按镜像源规则推导
+func RenderCNVBootSources(clusterDir string) (string, error) {
+	cfg, err := loadClusterConfig(clusterDir)
+	if err != nil {
+		return "", fmt.Errorf("加载集群配置失败: %w", err)
+	}
+	sources := cfg.GetCNVBootSources()
+	if len(sources) == 0 {
+		return "", fmt.Errorf("config.toml 中未启用 [save_image] cnv_boot_sources，请启用后重新执行 save-image 和 load-image")
+	}
+
+	policy := imagepolicy.ProxyCache(cfg)
+	if !cfg.IsProxyCache() {
+		if policy, err = imagepolicy.Load(clusterDir); err != nil {
+			policy = &imagepolicy.Policy{}
+		}
+	}
+	mirror, err := mirrormap.WithFallback(clusterDir, policy.MirrorImage)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := renderHyperConvergedBootSources(sources, mirror)
+	if err != nil {
+		return "", err
+	}
+
+	dir := CNVBootSourcesDir(clusterDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, cnvBootSourcesFilename), content, 0644); err != nil {
+		return "", fmt.Errorf("写入 %s 失败: %w", cnvBootSourcesFilename, err)
+	}
+	return dir, nil
+}
+
+// renderHyperConvergedBootSources 为每个启动源生成与内置模板同名的 dataImportCronTemplates 条目，
+// 覆盖内置模板的镜像地址。启动源由节点直接拉取 (pullMethod: node)，使用节点已信任的私有仓库证书
+func renderHyperConvergedBootSources(sources []config.BootSource, mirror func(string) (string, bool)) ([]byte, error) {
+	hco := hyperConverged{
+		APIVersion: "hco.kubevirt.io/v1beta1",
+		Kind:       "HyperConverged",
+		Metadata:   objectMeta{Name: hyperConvergedName, Namespace: hyperConvergedNS},
+		Spec:       hyperConvergedSpec{EnableCommonBootImageImport: true},
+	}
+
+	var unmatched []string
+	for _, source := range sources {
+		image, ok := mirror(source.Image)
+		if !ok {
+			unmatched = append(unmatched, source.Image)
+			continue
+		}
+
+		template := dataImportCronTemplate{
+			Metadata: objectMeta{
+				Name:        source.Name,
+				Annotations: map[string]string{"cdi.kubevirt.io/storage.bind.immediate.requested": "true"},
+			},
+			Spec: dataImportCronSpec{
+				Schedule:          bootSourceSchedule,
+				GarbageCollect:    "Outdated",
+				ManagedDataSource: source.DataSource,
+			},
+		}
+		template.Spec.Template.Spec.Source.Registry.URL = "docker://" + image
+		template.Spec.Template.Spec.Source.Registry.PullMethod = "node"
+		template.Spec.Template.Spec.Storage.Resources.Requests = map[string]string{"storage": bootSourceStorage}
+		hco.Spec.DataImportCronTemplates = append(hco.Spec.DataImportCronTemplates, template)
+	}
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("以下启动源镜像没有对应的私有仓库地址，请确认已执行 save-image 和 load-image: %s", strings.Join(unmatched, ", "))
+	}
+
+	content, err := yaml.Marshal(hco)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 HyperConverged 失败: %w", err)
+	}
+	return content, nil
+}
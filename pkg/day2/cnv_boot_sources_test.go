@@ -0,0 +1,77 @@
+package day2
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirrormap"
+)
+
+func TestRenderCNVBootSources(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.CNVBootSources = true
+	if err := config.SaveConfig(cfg, filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []mirrormap.Entry
+	for _, source := range cfg.GetCNVBootSources() {
+		entries = append(entries, mirrormap.Entry{
+			Source: source.Image,
+			Mirror: "registry.demo.example.com:8443/" + strings.SplitN(source.Image, "/", 2)[1],
+		})
+	}
+	if err := mirrormap.Save(clusterDir, &mirrormap.Mapping{Images: entries}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := RenderCNVBootSources(clusterDir)
+	if err != nil {
+		t.Fatalf("RenderCNVBootSources() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, cnvBootSourcesFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"kind: HyperConverged",
+		"namespace: openshift-cnv",
+		"name: rhel9-image-cron",
+		"url: docker://registry.demo.example.com:8443/rhel9/rhel-guest-image:latest",
+		"managedDataSource: rhel9",
+		"pullMethod: node",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("rendered manifest missing %q:\n%s", want, content)
+		}
+	}
+
+	objects, err := loadBundle(dir)
+	if err != nil || len(objects) != 1 || objects[0].Kind != "HyperConverged" {
+		t.Errorf("loadBundle() = %v, %v", objects, err)
+	}
+}
+
+func TestRenderCNVBootSourcesUnmatched(t *testing.T) {
+	sources := []config.BootSource{{Name: "fedora-image-cron", DataSource: "fedora", Image: "quay.io/containerdisks/fedora:latest"}}
+	_, err := renderHyperConvergedBootSources(sources, func(string) (string, bool) { return "", false })
+	if err == nil || !strings.Contains(err.Error(), "quay.io/containerdisks/fedora:latest") {
+		t.Errorf("expected unmatched error, got %v", err)
+	}
+}
+
+func TestRenderCNVBootSourcesDisabled(t *testing.T) {
+	clusterDir := t.TempDir()
+	if err := config.SaveConfig(config.NewDefaultConfig("demo"), filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderCNVBootSources(clusterDir); err == nil {
+		t.Error("expected error when cnv_boot_sources is disabled")
+	}
+}
@@ -0,0 +1,49 @@
+package day2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+)
+
+// ApplyComputePools 为 [[cluster.compute_pool]] 中的节点设置角色标签、labels 和 taints。
+// 安装时生成的 MachineConfigPool 通过角色标签选择节点；已存在的标签和污点会被覆盖，可重复执行。
+// 未配置节点池时不做任何操作
+func (c *Client) ApplyComputePools(ctx context.Context, clusterDir string, cfg *config.ClusterConfig) error {
+	c = c.withContext(ctx)
+	if len(cfg.Cluster.ComputePools) == 0 {
+		return nil
+	}
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, pool := range cfg.Cluster.ComputePools {
+		nodes := cfg.PoolNodes(pool.Name)
+		fmt.Printf("🏷️  计算节点池 %s: %d 个节点\n", pool.Name, len(nodes))
+		for _, node := range nodes {
+			args := append([]string{"label", "node", node.Name}, pool.NodeLabels()...)
+			if result, err := c.runOC(append(args, "--overwrite", "--kubeconfig", kubeconfigPath)...); err != nil {
+				errs = append(errs, fmt.Errorf("为节点 %s 设置标签失败: %w\n输出: %s", node.Name, err, result.Combined))
+				continue
+			}
+			if len(pool.Taints) > 0 {
+				args := []string{"adm", "taint", "node", node.Name}
+				for _, taint := range pool.Taints {
+					args = append(args, taint.String())
+				}
+				if result, err := c.runOC(append(args, "--overwrite", "--kubeconfig", kubeconfigPath)...); err != nil {
+					errs = append(errs, fmt.Errorf("为节点 %s 设置污点失败: %w\n输出: %s", node.Name, err, result.Combined))
+					continue
+				}
+			}
+			fmt.Printf("✅ %s\n", node.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
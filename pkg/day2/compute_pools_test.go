@@ -0,0 +1,57 @@
+package day2
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+func TestApplyComputePools(t *testing.T) {
+	clusterDir := t.TempDir()
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	for i := range cfg.Cluster.Worker {
+		cfg.Cluster.Worker[i].Pool = "infra"
+	}
+	cfg.Cluster.ComputePools = []config.ComputePool{{
+		Name:   "infra",
+		Labels: map[string]string{"tier": "infra"},
+		Taints: []config.Taint{{Key: "node-role.kubernetes.io/infra", Effect: config.TaintNoSchedule}},
+	}}
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[0] == "adm" && cmd.Args[3] == "worker-1" {
+			return &runner.Result{Combined: []byte("nodes \"worker-1\" not found")}, errors.New("exit status 1")
+		}
+		return nil, nil
+	}}
+	c := &Client{Runner: fake}
+
+	err := c.ApplyComputePools(context.Background(), clusterDir, cfg)
+	if err == nil || !strings.Contains(err.Error(), "worker-1 设置污点失败") {
+		t.Errorf("expected taint error for worker-1, got %v", err)
+	}
+	want := []string{
+		"oc label node worker-0 node-role.kubernetes.io/infra= tier=infra --overwrite --kubeconfig " + kubeconfigPath,
+		"oc adm taint node worker-0 node-role.kubernetes.io/infra:NoSchedule --overwrite --kubeconfig " + kubeconfigPath,
+		"oc label node worker-1 node-role.kubernetes.io/infra= tier=infra --overwrite --kubeconfig " + kubeconfigPath,
+		"oc adm taint node worker-1 node-role.kubernetes.io/infra:NoSchedule --overwrite --kubeconfig " + kubeconfigPath,
+	}
+	if got := fake.CommandLines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected oc calls:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -1,20 +1,66 @@
 package day2
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
 	"ocpack/pkg/utils"
+
+	"gopkg.in/yaml.v3"
 )
 
+// Client 在已安装的集群上执行 day2 操作
+type Client struct {
+	Runner runner.CommandRunner // 执行 oc 等外部命令，测试时可替换为 runner.Fake
+
+	ctx context.Context // withContext 绑定的 context，轮询等待时检查
+}
+
+// NewClient 创建使用默认 CommandRunner 的 Client
+func NewClient() *Client {
+	return &Client{Runner: runner.NewExecRunner()}
+}
+
+// withContext 返回外部命令绑定到 ctx 的 Client 副本，ctx 被取消或超时时终止正在执行的 oc 命令
+func (c *Client) withContext(ctx context.Context) *Client {
+	bound := *c
+	bound.Runner = runner.WithContext(ctx, c.Runner)
+	bound.ctx = ctx
+	return &bound
+}
+
+// wait 在两次轮询之间等待 d，context 被取消或超时时立即返回其原因
+func (c *Client) wait(d time.Duration) error {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// runOC 以默认超时执行一次 oc 命令并捕获输出
+func (c *Client) runOC(args ...string) (*runner.Result, error) {
+	return c.Runner.Run(runner.Command{Name: "oc", Args: args, Timeout: runner.DefaultTimeout})
+}
+
 // ConfigureOperatorHub 配置 OperatorHub 连接到私有镜像仓库
-func ConfigureOperatorHub(clusterName, clusterDir string) error {
+func (c *Client) ConfigureOperatorHub(ctx context.Context, clusterName, clusterDir string) error {
+	c = c.withContext(ctx)
 	fmt.Printf("🔧 开始配置集群 %s 的 OperatorHub\n", clusterName)
 
 	// 1. 加载集群配置
@@ -22,48 +68,78 @@ func ConfigureOperatorHub(clusterName, clusterDir string) error {
 	if err != nil {
 		return fmt.Errorf("加载集群配置失败: %w", err)
 	}
+	// 未安装 marketplace 组件时集群中没有 OperatorHub 资源和默认 catalog sources
+	if !cfg.CapabilityEnabled("marketplace") {
+		return fmt.Errorf("install_config.capabilities 未启用 marketplace，集群中没有 OperatorHub；" +
+			"如已启用 OperatorLifecycleManager，可使用 ocpack day2 apply-bundle 直接应用 oc-mirror 生成的 CatalogSource")
+	}
 
 	// 2. 检查 kubeconfig 是否存在
-	kubeconfigPath := filepath.Join(clusterDir, "installation", "ignition", "auth", "kubeconfig")
-	if _, err := os.Stat(kubeconfigPath); err != nil {
-		return fmt.Errorf("kubeconfig 文件不存在: %s\n请确保集群已经安装完成", kubeconfigPath)
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("✅ 找到 kubeconfig: %s\n", kubeconfigPath)
 
 	// 3. 构建 registry 主机名
-	registryHost := fmt.Sprintf("registry.%s.%s", cfg.ClusterInfo.ClusterID, cfg.ClusterInfo.Domain)
+	registryHost := cfg.RegistryHostname()
 	fmt.Printf("📋 私有镜像仓库: %s:8443\n", registryHost)
 
-	steps := 5
+	// 4. 记录修改前的状态，失败时回滚，避免默认 catalog sources 已禁用而新的 CatalogSource 不可用
+	var managed []string
+	for _, catalog := range cfg.GetOperatorCatalogs() {
+		managed = append(managed, catalog.GetCatalogSourceName())
+	}
+	snapshot, err := c.snapshotOperatorHub(clusterDir, kubeconfigPath, managed)
+	if err != nil {
+		return fmt.Errorf("记录 OperatorHub 当前状态失败: %w", err)
+	}
+
+	if err := c.configureCatalogSources(clusterDir, kubeconfigPath, cfg, registryHost); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println("↩️  回滚 OperatorHub 配置到修改前的状态")
+		if rollbackErr := c.rollbackOperatorHub(kubeconfigPath, snapshot); rollbackErr != nil {
+			return fmt.Errorf("%w\n回滚失败: %v\n💡 可稍后执行 ocpack day2 operatorhub %s --rollback 重试", err, rollbackErr, clusterName)
+		}
+		if removeErr := os.Remove(operatorHubSnapshotPath(clusterDir)); removeErr != nil {
+			fmt.Printf("⚠️  删除 OperatorHub 回滚快照失败: %v\n", removeErr)
+		}
+		return err
+	}
+
+	fmt.Printf("💡 如需撤销，执行: ocpack day2 operatorhub %s --rollback\n", clusterName)
+	return nil
+}
+
+// configureCatalogSources 禁用默认的在线 catalog sources，应用 oc-mirror 生成的 CatalogSource 并等待其就绪
+func (c *Client) configureCatalogSources(clusterDir, kubeconfigPath string, cfg *config.ClusterConfig, registryHost string) error {
+	steps := 4
 	fmt.Printf("➡️  步骤 1/%d: 禁用默认的在线 catalog sources\n", steps)
-	if err := disableDefaultCatalogSources(kubeconfigPath); err != nil {
+	if err := c.disableDefaultCatalogSources(kubeconfigPath); err != nil {
 		return fmt.Errorf("禁用默认 catalog sources 失败: %w", err)
 	}
 	fmt.Println("✅ 默认 catalog sources 已禁用")
 
-	fmt.Printf("➡️  步骤 2/%d: 查找最新的 CatalogSource 文件\n", steps)
-	catalogSourceFile, err := findLatestCatalogSource(clusterDir)
+	fmt.Printf("➡️  步骤 2/%d: 查找 oc-mirror 生成的 CatalogSource 文件\n", steps)
+	catalogSourceFiles, err := findCatalogSourceFiles(clusterDir)
 	if err != nil {
 		return fmt.Errorf("查找 CatalogSource 文件失败: %w", err)
 	}
-	fmt.Printf("✅ 找到 CatalogSource 文件: %s\n", catalogSourceFile)
+	fmt.Printf("✅ 找到 %d 个 CatalogSource 文件\n", len(catalogSourceFiles))
 
 	fmt.Printf("➡️  步骤 3/%d: 应用 CatalogSource\n", steps)
-	if err := applyCatalogSource(kubeconfigPath, catalogSourceFile); err != nil {
+	names, err := c.applyCatalogSources(kubeconfigPath, catalogSourceFiles, cfg.GetOperatorCatalogs(), registryHost)
+	if err != nil {
 		return fmt.Errorf("应用 CatalogSource 失败: %w", err)
 	}
-	fmt.Println("✅ CatalogSource 已应用")
+	fmt.Printf("✅ CatalogSource 已应用: %s\n", strings.Join(names, ", "))
 
-	fmt.Printf("➡️  步骤 4/%d: 配置 CatalogSource 属性\n", steps)
-	if err := configureCatalogSource(kubeconfigPath, registryHost); err != nil {
-		return fmt.Errorf("配置 CatalogSource 失败: %w", err)
-	}
-	fmt.Println("✅ CatalogSource 属性已配置")
-
-	fmt.Printf("➡️  步骤 5/%d: 等待 CatalogSource 状态变为 ready\n", steps)
-	if err := waitForCatalogSourceReady(kubeconfigPath); err != nil {
-		return fmt.Errorf("等待 CatalogSource ready 失败: %w", err)
+	fmt.Printf("➡️  步骤 4/%d: 等待 CatalogSource 状态变为 ready\n", steps)
+	for _, name := range names {
+		if err := c.waitForCatalogSourceReady(kubeconfigPath, name); err != nil {
+			return fmt.Errorf("等待 CatalogSource %s ready 失败: %w", name, err)
+		}
 	}
 	fmt.Println("✅ CatalogSource 状态已就绪")
 
@@ -77,15 +153,14 @@ func loadClusterConfig(clusterDir string) (*config.ClusterConfig, error) {
 }
 
 // disableDefaultCatalogSources 禁用默认的在线 catalog sources
-func disableDefaultCatalogSources(kubeconfigPath string) error {
+func (c *Client) disableDefaultCatalogSources(kubeconfigPath string) error {
 	fmt.Println("🔧 禁用默认的在线 catalog sources...")
 
-	cmd := exec.Command("oc", "patch", "OperatorHub", "cluster",
+	result, err := c.runOC("patch", "OperatorHub", "cluster",
 		"--type", "json",
 		"-p", `[{"op": "add", "path": "/spec/disableAllDefaultSources", "value": true}]`,
 		"--kubeconfig", kubeconfigPath)
-
-	output, err := cmd.CombinedOutput()
+	output := result.Combined
 	if err != nil {
 		return fmt.Errorf("执行 oc patch 命令失败: %w\n输出: %s", err, string(output))
 	}
@@ -94,43 +169,56 @@ func disableDefaultCatalogSources(kubeconfigPath string) error {
 	return nil
 }
 
-// findLatestCatalogSource 查找最新的 CatalogSource 文件
-func findLatestCatalogSource(clusterDir string) (string, error) {
-	fmt.Println("🔍 查找最新的 CatalogSource 文件...")
+// findCatalogSourceFiles 查找 oc-mirror 生成的全部 CatalogSource 文件，
+// 优先使用 cluster-resources 目录 (cs-*.yaml)，其次是旧版本 oc-mirror 工作空间最新的 results-* 目录
+func findCatalogSourceFiles(clusterDir string) ([]string, error) {
+	fmt.Println("🔍 查找 CatalogSource 文件...")
+
+	clusterResourcesDir := filepath.Join(clusterDir, "images", "working-dir", "cluster-resources")
+	if files := matchCatalogSourceFiles(clusterResourcesDir, "cs-"); len(files) > 0 {
+		return files, nil
+	}
 
-	// 查找 oc-mirror workspace 目录
+	// 兼容旧版本 oc-mirror 工作空间的 results-* 目录
 	workspaceDir, err := findOcMirrorWorkspace(clusterDir)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// 查找最新的 results 目录
 	latestResultsDir, err := findLatestResultsDir(workspaceDir)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	// 使用模式匹配查找 catalogSource 文件
-	entries, err := os.ReadDir(latestResultsDir)
-	if err != nil {
-		return "", fmt.Errorf("读取 results 目录失败: %w", err)
+	if files := matchCatalogSourceFiles(latestResultsDir, "catalogSource"); len(files) > 0 {
+		return files, nil
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "catalogSource") && strings.HasSuffix(entry.Name(), ".yaml") {
-			catalogSourceFile := filepath.Join(latestResultsDir, entry.Name())
-			fmt.Printf("📄 找到 CatalogSource 文件: %s\n", catalogSourceFile)
-			return catalogSourceFile, nil
+	// 如果没有找到，列出目录中的所有文件用于调试
+	fmt.Printf("🔍 目录 %s 中的文件:\n", latestResultsDir)
+	if entries, err := os.ReadDir(latestResultsDir); err == nil {
+		for _, entry := range entries {
+			fmt.Printf("  - %s\n", entry.Name())
 		}
 	}
 
-	// 如果没有找到，列出目录中的所有文件用于调试
-	fmt.Printf("🔍 目录 %s 中的文件:\n", latestResultsDir)
-	for _, entry := range entries {
-		fmt.Printf("  - %s\n", entry.Name())
+	return nil, fmt.Errorf("在 %s 和 %s 中未找到 CatalogSource 文件", clusterResourcesDir, latestResultsDir)
+}
+
+// matchCatalogSourceFiles 返回目录中以 prefix 开头的 YAML 文件
+func matchCatalogSourceFiles(dir, prefix string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
 	}
 
-	return "", fmt.Errorf("在 %s 中未找到 CatalogSource 文件", latestResultsDir)
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".yaml") {
+			file := filepath.Join(dir, entry.Name())
+			fmt.Printf("📄 找到 CatalogSource 文件: %s\n", file)
+			files = append(files, file)
+		}
+	}
+	return files
 }
 
 // findOcMirrorWorkspace 查找 oc-mirror workspace 目录
@@ -189,103 +277,111 @@ func findLatestResultsDir(workspaceDir string) (string, error) {
 	return latestDir, nil
 }
 
-// applyCatalogSource 应用 CatalogSource
-func applyCatalogSource(kubeconfigPath, catalogSourceFile string) error {
-	fmt.Printf("🔧 应用 CatalogSource: %s\n", catalogSourceFile)
+// applyCatalogSources 将每个 CatalogSource 文件与配置中的 Operator 目录按镜像匹配，
+// 按配置设置名称、显示名称和轮询间隔后应用，返回已应用的 CatalogSource 名称
+func (c *Client) applyCatalogSources(kubeconfigPath string, files []string, catalogs []config.OperatorCatalog, registryHost string) ([]string, error) {
+	var names []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CatalogSource 文件失败: %w", err)
+		}
 
-	// 首先读取原始文件
-	content, err := os.ReadFile(catalogSourceFile)
-	if err != nil {
-		return fmt.Errorf("读取 CatalogSource 文件失败: %w", err)
-	}
+		var catalogSource map[string]interface{}
+		if err := yaml.Unmarshal(content, &catalogSource); err != nil {
+			return nil, fmt.Errorf("解析 CatalogSource 文件 %s 失败: %w", file, err)
+		}
+		spec, _ := catalogSource["spec"].(map[string]interface{})
+		metadata, _ := catalogSource["metadata"].(map[string]interface{})
+		if spec == nil || metadata == nil {
+			return nil, fmt.Errorf("CatalogSource 文件 %s 缺少 metadata 或 spec", file)
+		}
+		image, _ := spec["image"].(string)
 
-	// 修改名称为 redhat-operators（如果不是的话）
-	modifiedContent := string(content)
-	// 支持多种可能的原始名称格式
-	possibleNames := []string{
-		"name: redhat-operator-index",
-		"name: cs-redhat-operator-index",
-		"name: redhat-operators-index",
-	}
+		catalog, ok := matchOperatorCatalog(catalogs, image, len(files))
+		if !ok {
+			fmt.Printf("⚠️  CatalogSource 镜像 %s 未在 config.toml 的 Operator 目录中配置，跳过: %s\n", image, file)
+			continue
+		}
 
-	for _, oldName := range possibleNames {
-		if strings.Contains(modifiedContent, oldName) {
-			modifiedContent = strings.ReplaceAll(modifiedContent, oldName, "name: redhat-operators")
-			fmt.Printf("📋 已将 '%s' 替换为 'name: redhat-operators'\n", oldName)
-			break
+		name := catalog.GetCatalogSourceName()
+		if original, _ := metadata["name"].(string); original != name {
+			fmt.Printf("📋 已将 CatalogSource '%s' 重命名为 '%s'\n", original, name)
+		}
+		metadata["name"] = name
+		spec["displayName"] = catalog.GetDisplayName(registryHost)
+		spec["updateStrategy"] = map[string]interface{}{
+			"registryPoll": map[string]interface{}{"interval": "2m"},
 		}
-	}
 
-	// 创建临时文件
-	tempFile := filepath.Join(filepath.Dir(catalogSourceFile), "catalogSource-modified.yaml")
-	if err := os.WriteFile(tempFile, []byte(modifiedContent), 0644); err != nil {
-		return fmt.Errorf("创建临时 CatalogSource 文件失败: %w", err)
+		if err := c.applyCatalogSource(kubeconfigPath, name, catalogSource); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
 	}
-	defer os.Remove(tempFile)
 
-	// 应用 CatalogSource
-	cmd := exec.Command("oc", "apply", "-f", tempFile, "--kubeconfig", kubeconfigPath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("应用 CatalogSource 失败: %w\n输出: %s", err, string(output))
+	if len(names) == 0 {
+		return nil, fmt.Errorf("没有与 config.toml 中 Operator 目录匹配的 CatalogSource")
 	}
-
-	fmt.Printf("📋 命令输出: %s\n", strings.TrimSpace(string(output)))
-	return nil
+	return names, nil
 }
 
-// configureCatalogSource 配置 CatalogSource 属性
-func configureCatalogSource(kubeconfigPath, registryHost string) error {
-	fmt.Println("🔧 配置 CatalogSource 显示名称和轮询间隔...")
-
-	// 设置友好的显示名称
-	displayName := fmt.Sprintf("Private Catalog (%s)", registryHost)
-	patchDisplayName := fmt.Sprintf(`{"spec": {"displayName": "%s"}}`, displayName)
+// matchOperatorCatalog 查找与镜像匹配的 Operator 目录。只有一个目录和一个 CatalogSource 文件时直接使用该目录，
+// 以兼容 load-image 改变了仓库路径的情况
+func matchOperatorCatalog(catalogs []config.OperatorCatalog, image string, fileCount int) (config.OperatorCatalog, bool) {
+	for _, catalog := range catalogs {
+		if catalog.MatchesImage(image) {
+			return catalog, true
+		}
+	}
+	if len(catalogs) == 1 && fileCount == 1 {
+		return catalogs[0], true
+	}
+	return config.OperatorCatalog{}, false
+}
 
-	cmd1 := exec.Command("oc", "patch", "CatalogSource", "redhat-operators",
-		"-n", "openshift-marketplace",
-		"--type", "merge",
-		"-p", patchDisplayName,
-		"--kubeconfig", kubeconfigPath)
+// applyCatalogSource 将修改后的 CatalogSource 写入临时文件并应用
+func (c *Client) applyCatalogSource(kubeconfigPath, name string, catalogSource map[string]interface{}) error {
+	fmt.Printf("🔧 应用 CatalogSource: %s\n", name)
 
-	output1, err := cmd1.CombinedOutput()
+	content, err := yaml.Marshal(catalogSource)
 	if err != nil {
-		return fmt.Errorf("设置 CatalogSource 显示名称失败: %w\n输出: %s", err, string(output1))
+		return fmt.Errorf("序列化 CatalogSource %s 失败: %w", name, err)
 	}
 
-	fmt.Printf("📋 显示名称设置输出: %s\n", strings.TrimSpace(string(output1)))
-
-	// 设置轮询间隔
-	patchInterval := `{"spec": {"updateStrategy": {"registryPoll": {"interval": "2m"}}}}`
-
-	cmd2 := exec.Command("oc", "patch", "CatalogSource", "redhat-operators",
-		"-n", "openshift-marketplace",
-		"--type", "merge",
-		"-p", patchInterval,
-		"--kubeconfig", kubeconfigPath)
+	tempFile, err := os.CreateTemp("", "catalogSource-"+name+"-*.yaml")
+	if err != nil {
+		return fmt.Errorf("创建临时 CatalogSource 文件失败: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("写入临时 CatalogSource 文件失败: %w", err)
+	}
+	tempFile.Close()
 
-	output2, err := cmd2.CombinedOutput()
+	result, err := c.runOC("apply", "-f", tempFile.Name(), "--kubeconfig", kubeconfigPath)
+	output := result.Combined
 	if err != nil {
-		return fmt.Errorf("设置 CatalogSource 轮询间隔失败: %w\n输出: %s", err, string(output2))
+		return fmt.Errorf("应用 CatalogSource %s 失败: %w\n输出: %s", name, err, string(output))
 	}
 
-	fmt.Printf("📋 轮询间隔设置输出: %s\n", strings.TrimSpace(string(output2)))
+	fmt.Printf("📋 命令输出: %s\n", strings.TrimSpace(string(output)))
 	return nil
 }
 
 // waitForCatalogSourceReady 等待 CatalogSource 状态变为 ready
-func waitForCatalogSourceReady(kubeconfigPath string) error {
-	fmt.Println("⏳ 等待 CatalogSource 状态变为 ready...")
+func (c *Client) waitForCatalogSourceReady(kubeconfigPath, name string) error {
+	fmt.Printf("⏳ 等待 CatalogSource %s 状态变为 ready...\n", name)
 
 	maxAttempts := 40
 	for i := 1; i <= maxAttempts; i++ {
 		// 获取 CatalogSource 状态
-		cmd := exec.Command("oc", "get", "catalogsources.operators.coreos.com", "redhat-operators",
+		result, err := c.runOC("get", "catalogsources.operators.coreos.com", name,
 			"-n", "openshift-marketplace",
 			"-o", "json",
 			"--kubeconfig", kubeconfigPath)
-
-		output, err := cmd.Output()
+		output := result.Stdout
 		if err != nil {
 			fmt.Printf("⚠️  获取 CatalogSource 状态失败 (尝试 %d/%d): %v\n", i, maxAttempts, err)
 		} else {
@@ -308,11 +404,13 @@ func waitForCatalogSourceReady(kubeconfigPath string) error {
 
 		if i < maxAttempts {
 			fmt.Print(".")
-			time.Sleep(time.Duration(i) * time.Second)
+			if err := c.wait(time.Duration(i) * time.Second); err != nil {
+				return err
+			}
 		}
 	}
 
-	fmt.Printf("⚠️  警告: 等待超时，CatalogSource 可能仍在初始化中\n")
+	fmt.Printf("⚠️  警告: 等待超时，CatalogSource %s 可能仍在初始化中\n", name)
 	fmt.Println("💡 您可以手动检查状态: oc get catalogsources -n openshift-marketplace")
 	return nil // 不返回错误，只是警告
 }
@@ -0,0 +1,184 @@
+package day2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+func writeCatalogSource(t *testing.T, dir, name, image string) string {
+	t.Helper()
+	content := `apiVersion: operators.coreos.com/v1alpha1
+kind: CatalogSource
+metadata:
+  name: ` + name + `
+  namespace: openshift-marketplace
+spec:
+  image: ` + image + `
+  sourceType: grpc
+`
+	path := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyCatalogSources(t *testing.T) {
+	clusterDir := t.TempDir()
+	resourcesDir := filepath.Join(clusterDir, "images", "working-dir", "cluster-resources")
+	if err := os.MkdirAll(resourcesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeCatalogSource(t, resourcesDir, "cs-redhat-operator-index-v4-14", "registry.demo.example.com:8443/redhat/redhat-operator-index:v4.14")
+	writeCatalogSource(t, resourcesDir, "cs-index-latest", "registry.demo.example.com:8443/partner/index:latest")
+	writeCatalogSource(t, resourcesDir, "cs-community-operator-index-v4-14", "registry.demo.example.com:8443/redhat/community-operator-index:v4.14")
+
+	files, err := findCatalogSourceFiles(clusterDir)
+	if err != nil {
+		t.Fatalf("findCatalogSourceFiles() error = %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 CatalogSource files, got %v", files)
+	}
+
+	catalogs := []config.OperatorCatalog{
+		{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14"},
+		{
+			Catalog:           "registry.redhat.io/redhat/certified-operator-index:v4.14",
+			TargetCatalog:     "partner/index",
+			TargetTag:         "latest",
+			CatalogSourceName: "certified-operators",
+			DisplayName:       "Partner Operators",
+		},
+	}
+
+	applied := make(map[string]string)
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		content, err := os.ReadFile(cmd.Args[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "    name: ") {
+				applied[strings.TrimPrefix(line, "    name: ")] = string(content)
+			}
+		}
+		return nil, nil
+	}}
+	c := &Client{Runner: fake}
+
+	names, err := c.applyCatalogSources("/tmp/kubeconfig", files, catalogs, "registry.demo.example.com")
+	if err != nil {
+		t.Fatalf("applyCatalogSources() error = %v", err)
+	}
+
+	// community 目录未配置，应被跳过
+	expected := []string{"certified-operators", "redhat-operators"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("names = %v, expected %v", names, expected)
+	}
+	if len(fake.Calls()) != 2 {
+		t.Fatalf("expected 2 oc apply calls, got %v", fake.CommandLines())
+	}
+	for name, want := range map[string][]string{
+		"redhat-operators":    {"displayName: redhat-operators (registry.demo.example.com)", "interval: 2m"},
+		"certified-operators": {"displayName: Partner Operators", "image: registry.demo.example.com:8443/partner/index:latest"},
+	} {
+		content, ok := applied[name]
+		if !ok {
+			t.Errorf("CatalogSource %s not applied, applied: %v", name, applied)
+			continue
+		}
+		for _, s := range want {
+			if !strings.Contains(content, s) {
+				t.Errorf("CatalogSource %s missing %q:\n%s", name, s, content)
+			}
+		}
+	}
+}
+
+func TestSavedChannelVersions(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+
+	// 没有 graph 数据时继续使用配置的通道
+	channel, warning, err := cfg.ResolveMirrorChannel(savedChannelVersions(clusterDir))
+	if err != nil || channel != "stable-4.16" || warning == "" {
+		t.Errorf("ResolveMirrorChannel() without graph data = %q, %q, %v", channel, warning, err)
+	}
+
+	graphDataDir := filepath.Join(clusterDir, "images", "working-dir", "hold-release", "cincinnati-graph-data")
+	if err := os.MkdirAll(graphDataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	graph := `{"nodes": [{"version": "4.16.2"}, {"version": "4.16.3"}], "edges": [[0, 1]]}`
+	if err := os.WriteFile(filepath.Join(graphDataDir, "amd64-fast-4.16.json"), []byte(graph), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// save-image 改用了 fast 通道时选出相同的通道
+	channel, _, err = cfg.ResolveMirrorChannel(savedChannelVersions(clusterDir))
+	if err != nil || channel != "fast-4.16" {
+		t.Errorf("ResolveMirrorChannel() = %q, %v, expected fast-4.16", channel, err)
+	}
+}
+
+func TestConfigureUpdateServiceOverride(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.UpdateURLOverride = "https://osus.example.com/api/upgrades_info/graph"
+	if err := config.SaveConfig(cfg, filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{}
+	c := &Client{Runner: fake}
+
+	if err := c.ConfigureUpdateService(context.Background(), "demo", clusterDir); err != nil {
+		t.Fatalf("ConfigureUpdateService() error = %v", err)
+	}
+	lines := fake.CommandLines()
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "oc patch clusterversion version") ||
+		!strings.Contains(lines[0], `"upstream": "https://osus.example.com/api/upgrades_info/graph"`) ||
+		!strings.Contains(lines[0], `"channel": "stable-4.16"`) {
+		t.Errorf("unexpected oc calls: %v", lines)
+	}
+}
+
+func TestConfigureOperatorHubWithoutMarketplace(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.InstallConfig.Capabilities = &config.Capabilities{BaselineCapabilitySet: "None", AdditionalEnabledCapabilities: []string{"OperatorLifecycleManager"}}
+	if err := config.SaveConfig(cfg, filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{}
+	c := &Client{Runner: fake}
+
+	err := c.ConfigureOperatorHub(context.Background(), "demo", clusterDir)
+	if err == nil || !strings.Contains(err.Error(), "marketplace") {
+		t.Errorf("expected marketplace capability error, got %v", err)
+	}
+	if len(fake.Calls()) != 0 {
+		t.Errorf("no command should run, got %v", fake.CommandLines())
+	}
+}
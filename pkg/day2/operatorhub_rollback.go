@@ -0,0 +1,207 @@
+package day2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"ocpack/pkg/kubeconfig"
+)
+
+// --- Constants ---
+const (
+	operatorHubSnapshotFilename = ".ocpack-operatorhub.json"
+	marketplaceNamespace        = "openshift-marketplace"
+)
+
+// defaultCatalogSources marketplace operator 管理的默认 CatalogSource，
+// 恢复 disableAllDefaultSources 后由 marketplace operator 重新创建
+var defaultCatalogSources = []string{"redhat-operators", "certified-operators", "community-operators", "redhat-marketplace"}
+
+// operatorHubSnapshot ocpack 第一次修改 OperatorHub 之前的集群状态，用于回滚
+type operatorHubSnapshot struct {
+	DisableAllDefaultSources bool            `json:"disableAllDefaultSources"`
+	Sources                  json.RawMessage `json:"sources,omitempty"`        // OperatorHub spec.sources
+	CatalogSources           []string        `json:"catalogSources,omitempty"` // 修改前已存在的非默认 CatalogSource
+	Managed                  []string        `json:"managed,omitempty"`        // ocpack 应用的 CatalogSource
+}
+
+// operatorHubSnapshotPath 返回回滚快照在集群目录中的位置
+func operatorHubSnapshotPath(clusterDir string) string {
+	return filepath.Join(clusterDir, operatorHubSnapshotFilename)
+}
+
+// loadOperatorHubSnapshot 读取回滚快照，文件不存在时返回 nil
+func loadOperatorHubSnapshot(clusterDir string) (*operatorHubSnapshot, error) {
+	data, err := os.ReadFile(operatorHubSnapshotPath(clusterDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 OperatorHub 回滚快照失败: %w", err)
+	}
+	snapshot := &operatorHubSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("解析 OperatorHub 回滚快照失败: %w", err)
+	}
+	return snapshot, nil
+}
+
+// saveOperatorHubSnapshot 保存回滚快照
+func saveOperatorHubSnapshot(clusterDir string, snapshot *operatorHubSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化 OperatorHub 回滚快照失败: %w", err)
+	}
+	if err := os.WriteFile(operatorHubSnapshotPath(clusterDir), data, 0644); err != nil {
+		return fmt.Errorf("写入 OperatorHub 回滚快照失败: %w", err)
+	}
+	return nil
+}
+
+// snapshotOperatorHub 记录修改前的 OperatorHub 配置和已存在的 CatalogSource。
+// 已有快照时保留其中的原始状态，只追加本次将要应用的 CatalogSource，使多次执行后仍能回滚到最初的状态
+func (c *Client) snapshotOperatorHub(clusterDir, kubeconfigPath string, managed []string) (*operatorHubSnapshot, error) {
+	snapshot, err := loadOperatorHubSnapshot(clusterDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot == nil {
+		result, err := c.runOC("get", "OperatorHub", "cluster", "-o", "json", "--kubeconfig", kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("获取 OperatorHub 配置失败: %w\n输出: %s", err, strings.TrimSpace(string(result.Combined)))
+		}
+		var operatorHub struct {
+			Spec struct {
+				DisableAllDefaultSources bool            `json:"disableAllDefaultSources"`
+				Sources                  json.RawMessage `json:"sources"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(result.Stdout, &operatorHub); err != nil {
+			return nil, fmt.Errorf("解析 OperatorHub 配置失败: %w", err)
+		}
+
+		existing, err := c.listCatalogSources(kubeconfigPath)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = &operatorHubSnapshot{
+			DisableAllDefaultSources: operatorHub.Spec.DisableAllDefaultSources,
+			Sources:                  operatorHub.Spec.Sources,
+		}
+		// 默认 CatalogSource 由 marketplace operator 管理，回滚时通过恢复 OperatorHub 配置重新创建
+		for _, name := range existing {
+			if !slices.Contains(defaultCatalogSources, name) {
+				snapshot.CatalogSources = append(snapshot.CatalogSources, name)
+			}
+		}
+	}
+
+	for _, name := range managed {
+		if !slices.Contains(snapshot.Managed, name) {
+			snapshot.Managed = append(snapshot.Managed, name)
+		}
+	}
+	if err := saveOperatorHubSnapshot(clusterDir, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// listCatalogSources 返回 openshift-marketplace 中全部 CatalogSource 的名称
+func (c *Client) listCatalogSources(kubeconfigPath string) ([]string, error) {
+	result, err := c.runOC("get", "catalogsources.operators.coreos.com",
+		"-n", marketplaceNamespace,
+		"-o", "json",
+		"--kubeconfig", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取 CatalogSource 列表失败: %w\n输出: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(result.Stdout, &list); err != nil {
+		return nil, fmt.Errorf("解析 CatalogSource 列表失败: %w", err)
+	}
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	return names, nil
+}
+
+// RollbackOperatorHub 将 OperatorHub 恢复到 ocpack 第一次修改之前的状态，并删除 ocpack 应用的 CatalogSource
+func (c *Client) RollbackOperatorHub(ctx context.Context, clusterName, clusterDir string) error {
+	c = c.withContext(ctx)
+	fmt.Printf("🔧 开始回滚集群 %s 的 OperatorHub 配置\n", clusterName)
+
+	snapshot, err := loadOperatorHubSnapshot(clusterDir)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return fmt.Errorf("未找到 OperatorHub 回滚快照 %s，请确认已执行过 day2 operatorhub", operatorHubSnapshotPath(clusterDir))
+	}
+
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
+	}
+
+	if err := c.rollbackOperatorHub(kubeconfigPath, snapshot); err != nil {
+		return err
+	}
+	if err := os.Remove(operatorHubSnapshotPath(clusterDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除 OperatorHub 回滚快照失败: %w", err)
+	}
+	return nil
+}
+
+// rollbackOperatorHub 删除 ocpack 新建的 CatalogSource，并恢复 OperatorHub 的 disableAllDefaultSources 和 sources
+func (c *Client) rollbackOperatorHub(kubeconfigPath string, snapshot *operatorHubSnapshot) error {
+	var errs []error
+	for _, name := range snapshot.Managed {
+		if slices.Contains(snapshot.CatalogSources, name) {
+			fmt.Printf("⏭️  CatalogSource %s 在配置前已存在，保留\n", name)
+			continue
+		}
+		fmt.Printf("🗑️  删除 CatalogSource: %s\n", name)
+		result, err := c.runOC("delete", "catalogsources.operators.coreos.com", name,
+			"-n", marketplaceNamespace,
+			"--ignore-not-found",
+			"--kubeconfig", kubeconfigPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("删除 CatalogSource %s 失败: %w\n输出: %s", name, err, strings.TrimSpace(string(result.Combined))))
+		}
+	}
+
+	sources := snapshot.Sources
+	if len(sources) == 0 {
+		sources = json.RawMessage("null")
+	}
+	patch := fmt.Sprintf(`{"spec": {"disableAllDefaultSources": %t, "sources": %s}}`, snapshot.DisableAllDefaultSources, sources)
+	fmt.Printf("🔧 恢复 OperatorHub: disableAllDefaultSources=%t\n", snapshot.DisableAllDefaultSources)
+	result, err := c.runOC("patch", "OperatorHub", "cluster",
+		"--type", "merge",
+		"-p", patch,
+		"--kubeconfig", kubeconfigPath)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("恢复 OperatorHub 配置失败: %w\n输出: %s", err, strings.TrimSpace(string(result.Combined))))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	fmt.Println("✅ OperatorHub 已恢复到配置前的状态")
+	return nil
+}
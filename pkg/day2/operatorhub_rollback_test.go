@@ -0,0 +1,118 @@
+package day2
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const operatorHubJSON = `{"spec":{"sources":[{"name":"community-operators","disabled":true}]}}`
+
+const catalogSourcesJSON = `{"items":[
+{"metadata":{"name":"redhat-operators"}},
+{"metadata":{"name":"certified-operators"}},
+{"metadata":{"name":"partner-operators"}}]}`
+
+// fakeOperatorHubRunner 返回固定的 OperatorHub 和 CatalogSource 列表，applyErr 不为空时 oc apply 失败
+func fakeOperatorHubRunner(applyErr error) *runner.Fake {
+	return &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		switch {
+		case cmd.Args[0] == "get" && cmd.Args[1] == "OperatorHub":
+			return &runner.Result{Stdout: []byte(operatorHubJSON)}, nil
+		case cmd.Args[0] == "get" && cmd.Args[1] == "catalogsources.operators.coreos.com":
+			return &runner.Result{Stdout: []byte(catalogSourcesJSON)}, nil
+		case cmd.Args[0] == "apply":
+			return nil, applyErr
+		}
+		return nil, nil
+	}}
+}
+
+func TestSnapshotOperatorHub(t *testing.T) {
+	clusterDir := t.TempDir()
+	fake := fakeOperatorHubRunner(nil)
+	c := &Client{Runner: fake}
+
+	snapshot, err := c.snapshotOperatorHub(clusterDir, "/tmp/kubeconfig", []string{"redhat-operators"})
+	if err != nil {
+		t.Fatalf("snapshotOperatorHub() error = %v", err)
+	}
+	if snapshot.DisableAllDefaultSources {
+		t.Error("DisableAllDefaultSources should be false")
+	}
+	if want := []string{"partner-operators"}; !reflect.DeepEqual(snapshot.CatalogSources, want) {
+		t.Errorf("CatalogSources = %v, want %v (default sources excluded)", snapshot.CatalogSources, want)
+	}
+
+	// 再次执行时保留原始状态，只追加 CatalogSource
+	calls := len(fake.Calls())
+	snapshot, err = c.snapshotOperatorHub(clusterDir, "/tmp/kubeconfig", []string{"redhat-operators", "partner-operators"})
+	if err != nil {
+		t.Fatalf("snapshotOperatorHub() error = %v", err)
+	}
+	if len(fake.Calls()) != calls {
+		t.Errorf("existing snapshot should not query the cluster again: %v", fake.CommandLines()[calls:])
+	}
+	if want := []string{"redhat-operators", "partner-operators"}; !reflect.DeepEqual(snapshot.Managed, want) {
+		t.Errorf("Managed = %v, want %v", snapshot.Managed, want)
+	}
+}
+
+func TestRollbackOperatorHub(t *testing.T) {
+	fake := &runner.Fake{}
+	c := &Client{Runner: fake}
+
+	snapshot := &operatorHubSnapshot{
+		Sources:        []byte(`[{"name":"community-operators","disabled":true}]`),
+		CatalogSources: []string{"partner-operators"},
+		Managed:        []string{"redhat-operators", "partner-operators"},
+	}
+	if err := c.rollbackOperatorHub("/tmp/kubeconfig", snapshot); err != nil {
+		t.Fatalf("rollbackOperatorHub() error = %v", err)
+	}
+
+	want := []string{
+		"oc delete catalogsources.operators.coreos.com redhat-operators -n openshift-marketplace --ignore-not-found --kubeconfig /tmp/kubeconfig",
+		`oc patch OperatorHub cluster --type merge -p {"spec": {"disableAllDefaultSources": false, "sources": [{"name":"community-operators","disabled":true}]}} --kubeconfig /tmp/kubeconfig`,
+	}
+	if got := fake.CommandLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestConfigureOperatorHubRollsBackOnFailure(t *testing.T) {
+	clusterDir := setupBundleCluster(t)
+	cfg := config.NewDefaultConfig("demo")
+	if err := config.SaveConfig(cfg, filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+	resourcesDir := filepath.Join(clusterDir, "images", "working-dir", "cluster-resources")
+	if err := os.MkdirAll(resourcesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeCatalogSource(t, resourcesDir, "cs-redhat-operator-index-v4-14", "registry.demo.example.com:8443/redhat/redhat-operator-index:v4.14")
+
+	fake := fakeOperatorHubRunner(errors.New("exit status 1"))
+	c := &Client{Runner: fake}
+
+	err := c.ConfigureOperatorHub(context.Background(), "demo", clusterDir)
+	if err == nil || !strings.Contains(err.Error(), "应用 CatalogSource 失败") {
+		t.Fatalf("ConfigureOperatorHub() error = %v", err)
+	}
+
+	lines := fake.CommandLines()
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, "oc patch OperatorHub cluster --type merge") || !strings.Contains(last, `"disableAllDefaultSources": false`) {
+		t.Errorf("expected OperatorHub to be restored last, got %q", last)
+	}
+	if _, err := os.Stat(operatorHubSnapshotPath(clusterDir)); !os.IsNotExist(err) {
+		t.Errorf("snapshot should be removed after rollback, stat error = %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package day2
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const presetsDirName = "presets"
+
+// presetObject 预置组件清单中的资源，只包含 ocpack 设置的字段
+type presetObject struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   presetMeta  `yaml:"metadata"`
+	Spec       interface{} `yaml:"spec,omitempty"`
+}
+
+type presetMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type operatorGroupSpec struct {
+	TargetNamespaces []string `yaml:"targetNamespaces,omitempty"`
+}
+
+type subscriptionSpec struct {
+	Name                string `yaml:"name"`
+	Source              string `yaml:"source"`
+	SourceNamespace     string `yaml:"sourceNamespace"`
+	InstallPlanApproval string `yaml:"installPlanApproval"`
+}
+
+// PresetsDir 返回预置组件清单的生成目录
+func PresetsDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "day2", presetsDirName)
+}
+
+// RenderPresets 为 [save_image] presets 中的每个预置组件生成 <名称>.yaml，包含 Namespace、OperatorGroup
+// 和订阅私有仓库中 Operator 目录的 Subscription，返回清单目录
+func RenderPresets(clusterDir string) (string, error) {
+	cfg, err := loadClusterConfig(clusterDir)
+	if err != nil {
+		return "", fmt.Errorf("加载集群配置失败: %w", err)
+	}
+	presets := cfg.GetPresets()
+	if len(presets) == 0 {
+		return "", fmt.Errorf("config.toml 中未配置 [save_image] presets，请配置后重新执行 save-image 和 load-image")
+	}
+
+	dir := PresetsDir(clusterDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("清理目录 %s 失败: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+	for _, preset := range presets {
+		content, err := renderPreset(cfg, preset)
+		if err != nil {
+			return "", err
+		}
+		filename := preset.Name + ".yaml"
+		if err := os.WriteFile(filepath.Join(dir, filename), content, 0644); err != nil {
+			return "", fmt.Errorf("写入 %s 失败: %w", filename, err)
+		}
+	}
+	return dir, nil
+}
+
+// renderPreset 生成一个预置组件的多文档清单。Subscription 的 source 为 Operator 所在目录在 day2 operatorhub
+// 中创建的 CatalogSource，不指定通道，使用目录中的默认通道
+func renderPreset(cfg *config.ClusterConfig, preset config.Preset) ([]byte, error) {
+	var objects []presetObject
+	for _, op := range preset.Operators {
+		catalog, err := cfg.FindOperatorCatalog(op.Package, "")
+		if err != nil {
+			return nil, fmt.Errorf("预置组件 %s: %w", preset.Name, err)
+		}
+
+		groupSpec := operatorGroupSpec{}
+		if !op.AllNamespaces {
+			groupSpec.TargetNamespaces = []string{op.Namespace}
+		}
+		objects = append(objects,
+			presetObject{
+				APIVersion: "v1",
+				Kind:       "Namespace",
+				Metadata: presetMeta{
+					Name:   op.Namespace,
+					Labels: map[string]string{"openshift.io/cluster-monitoring": "true"},
+				},
+			},
+			presetObject{
+				APIVersion: "operators.coreos.com/v1",
+				Kind:       "OperatorGroup",
+				Metadata:   presetMeta{Name: op.Namespace, Namespace: op.Namespace},
+				Spec:       groupSpec,
+			},
+			presetObject{
+				APIVersion: "operators.coreos.com/v1alpha1",
+				Kind:       "Subscription",
+				Metadata:   presetMeta{Name: op.Package, Namespace: op.Namespace},
+				Spec: subscriptionSpec{
+					Name:                op.Package,
+					Source:              catalog.GetCatalogSourceName(),
+					SourceNamespace:     marketplaceNamespace,
+					InstallPlanApproval: "Automatic",
+				},
+			},
+		)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, obj := range objects {
+		if err := encoder.Encode(obj); err != nil {
+			return nil, fmt.Errorf("序列化 %s/%s 失败: %w", obj.Kind, obj.Metadata.Name, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
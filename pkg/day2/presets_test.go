@@ -0,0 +1,75 @@
+package day2
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func TestRenderPresets(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.OperatorCatalogs = []config.OperatorCatalog{
+		{Catalog: "redhat", CatalogSourceName: "mirror-redhat", Ops: []string{"cluster-logging"}},
+	}
+	cfg.SaveImage.Presets = []string{"gitops", "acm"}
+	if err := config.SaveConfig(cfg, filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := RenderPresets(clusterDir)
+	if err != nil {
+		t.Fatalf("RenderPresets() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "gitops.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"kind: Namespace",
+		"kind: OperatorGroup",
+		"kind: Subscription",
+		"name: openshift-gitops-operator",
+		"source: mirror-redhat",
+		"sourceNamespace: openshift-marketplace",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("gitops.yaml missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "targetNamespaces") {
+		t.Errorf("gitops OperatorGroup should target all namespaces:\n%s", content)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "acm.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "targetNamespaces:\n    - open-cluster-management") {
+		t.Errorf("acm.yaml missing targetNamespaces:\n%s", data)
+	}
+
+	objects, err := loadBundle(dir)
+	if err != nil || len(objects) != 6 {
+		t.Fatalf("loadBundle() = %v, %v", objects, err)
+	}
+	if objects[0].Kind != "Namespace" || objects[5].Kind != "Subscription" {
+		t.Errorf("unexpected apply order: %v", objects)
+	}
+}
+
+func TestRenderPresetsWithoutPresets(t *testing.T) {
+	clusterDir := t.TempDir()
+	if err := config.SaveConfig(config.NewDefaultConfig("demo"), filepath.Join(clusterDir, "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderPresets(clusterDir); err == nil {
+		t.Error("RenderPresets() expected error without presets")
+	}
+}
@@ -0,0 +1,245 @@
+package day2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+)
+
+// --- Constants ---
+const (
+	updateServiceFilename  = "updateService.yaml"
+	updateServiceNamespace = "openshift-update-service"
+	updateServiceCRD       = "updateservices.updateservice.operator.openshift.io"
+	upgradesInfoGraphPath  = "/api/upgrades_info/graph"
+)
+
+// ConfigureUpdateService 应用 oc-mirror 生成的 UpdateService 资源，并将集群的升级源指向本地 OSUS。
+// 配置了 [save_image] update_url_override 时直接将升级源指向该地址
+func (c *Client) ConfigureUpdateService(ctx context.Context, clusterName, clusterDir string) error {
+	c = c.withContext(ctx)
+	fmt.Printf("🔧 开始配置集群 %s 的 OpenShift Update Service\n", clusterName)
+
+	cfg, err := loadClusterConfig(clusterDir)
+	if err != nil {
+		return fmt.Errorf("加载集群配置失败: %w", err)
+	}
+	if err := config.ValidateUpdateURLOverride(cfg); err != nil {
+		return err
+	}
+
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 找到 kubeconfig: %s\n", kubeconfigPath)
+
+	// 隔离网络 (enclave) 使用上级网络中的 OSUS，不在本集群部署 UpdateService
+	if override := cfg.SaveImage.UpdateURLOverride; override != "" {
+		fmt.Printf("🔒 已配置 update_url_override，集群升级源将指向: %s\n", override)
+		channel, err := resolveUpdateChannel(cfg, clusterDir)
+		if err != nil {
+			return err
+		}
+		if err := c.patchClusterVersionUpstream(kubeconfigPath, override, channel); err != nil {
+			return fmt.Errorf("更新 ClusterVersion 升级源失败: %w", err)
+		}
+		fmt.Printf("✅ ClusterVersion 升级源已指向: %s (通道: %s)\n", override, channel)
+		return nil
+	}
+
+	if !cfg.SaveImage.Graph {
+		fmt.Println("⚠️  config.toml 中未启用 [save_image] graph，镜像仓库中可能没有 graph-data 镜像")
+	}
+
+	steps := 4
+	fmt.Printf("➡️  步骤 1/%d: 查找 UpdateService 文件\n", steps)
+	updateServiceFile, err := findUpdateServiceFile(clusterDir)
+	if err != nil {
+		return fmt.Errorf("查找 UpdateService 文件失败: %w", err)
+	}
+	fmt.Printf("✅ 找到 UpdateService 文件: %s\n", updateServiceFile)
+
+	fmt.Printf("➡️  步骤 2/%d: 检查 OpenShift Update Service Operator\n", steps)
+	if err := c.checkUpdateServiceOperator(kubeconfigPath); err != nil {
+		return err
+	}
+	fmt.Println("✅ OpenShift Update Service Operator 已安装")
+
+	fmt.Printf("➡️  步骤 3/%d: 应用 UpdateService\n", steps)
+	if err := c.applyUpdateService(kubeconfigPath, updateServiceFile); err != nil {
+		return fmt.Errorf("应用 UpdateService 失败: %w", err)
+	}
+	fmt.Println("✅ UpdateService 已应用")
+
+	fmt.Printf("➡️  步骤 4/%d: 等待 policy engine 就绪并更新 ClusterVersion 升级源\n", steps)
+	policyEngineURI, err := c.waitForPolicyEngineURI(kubeconfigPath, updateServiceFile)
+	if err != nil {
+		return fmt.Errorf("等待 UpdateService 就绪失败: %w", err)
+	}
+	channel, err := resolveUpdateChannel(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	if err := c.patchClusterVersionUpstream(kubeconfigPath, policyEngineURI+upgradesInfoGraphPath, channel); err != nil {
+		return fmt.Errorf("更新 ClusterVersion 升级源失败: %w", err)
+	}
+	fmt.Printf("✅ ClusterVersion 升级源已指向: %s%s (通道: %s)\n", policyEngineURI, upgradesInfoGraphPath, channel)
+
+	return nil
+}
+
+// resolveUpdateChannel 返回集群使用的升级通道。与 save-image 一致，目标版本尚未进入配置的通道时
+// 使用镜像时改用的 fast/candidate 通道
+func resolveUpdateChannel(cfg *config.ClusterConfig, clusterDir string) (string, error) {
+	channel, warning, err := cfg.ResolveMirrorChannel(savedChannelVersions(clusterDir))
+	if err != nil {
+		return "", err
+	}
+	if warning != "" {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	return channel, nil
+}
+
+// savedChannelVersions 从 save-image 保存在 oc-mirror 工作目录中的 Cincinnati graph 数据读取通道中的版本，
+// 未镜像的通道没有 graph 文件，视为不包含任何版本
+func savedChannelVersions(clusterDir string) config.ChannelVersionsFunc {
+	graphDataDir := filepath.Join(clusterDir, "images", "working-dir", "hold-release", "cincinnati-graph-data")
+	return func(channel string) ([]string, error) {
+		if _, err := os.Stat(graphDataDir); err != nil {
+			return nil, fmt.Errorf("未找到 graph 数据目录 %s", graphDataDir)
+		}
+		data, err := os.ReadFile(filepath.Join(graphDataDir, "amd64-"+channel+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var graph struct {
+			Nodes []struct {
+				Version string `json:"version"`
+			} `json:"nodes"`
+		}
+		if err := json.Unmarshal(data, &graph); err != nil {
+			return nil, fmt.Errorf("解析 graph 数据失败: %w", err)
+		}
+		versions := make([]string, 0, len(graph.Nodes))
+		for _, node := range graph.Nodes {
+			versions = append(versions, node.Version)
+		}
+		return versions, nil
+	}
+}
+
+// findUpdateServiceFile 在 oc-mirror 的 cluster-resources 目录中查找 UpdateService 文件
+func findUpdateServiceFile(clusterDir string) (string, error) {
+	candidates := []string{
+		filepath.Join(clusterDir, "images", "working-dir", "cluster-resources", updateServiceFilename),
+	}
+
+	// 兼容旧版本 oc-mirror 工作空间的 results-* 目录
+	if workspaceDir, err := findOcMirrorWorkspace(clusterDir); err == nil {
+		if latestResultsDir, err := findLatestResultsDir(workspaceDir); err == nil {
+			candidates = append(candidates, filepath.Join(latestResultsDir, updateServiceFilename))
+		}
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("未找到 %s，请在 config.toml 中设置 [save_image] graph = true 后重新执行 save-image 和 load-image", updateServiceFilename)
+}
+
+// checkUpdateServiceOperator 检查 UpdateService CRD 是否存在
+func (c *Client) checkUpdateServiceOperator(kubeconfigPath string) error {
+	if result, err := c.runOC("get", "crd", updateServiceCRD, "--kubeconfig", kubeconfigPath); err != nil {
+		return fmt.Errorf("未检测到 OpenShift Update Service Operator (%s): %w\n输出: %s\n💡 请先在 [save_image] ops 中加入 cincinnati-operator 并通过 OperatorHub 安装",
+			updateServiceCRD, err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// applyUpdateService 在 openshift-update-service 命名空间中应用 UpdateService
+func (c *Client) applyUpdateService(kubeconfigPath, updateServiceFile string) error {
+	fmt.Printf("🔧 应用 UpdateService: %s\n", updateServiceFile)
+
+	result, err := c.runOC("apply", "-f", updateServiceFile,
+		"-n", updateServiceNamespace,
+		"--kubeconfig", kubeconfigPath)
+	output := result.Combined
+	if err != nil {
+		return fmt.Errorf("执行 oc apply 命令失败: %w\n输出: %s", err, string(output))
+	}
+
+	fmt.Printf("📋 命令输出: %s\n", strings.TrimSpace(string(output)))
+	return nil
+}
+
+// waitForPolicyEngineURI 等待 UpdateService 在状态中报告 policyEngineURI
+func (c *Client) waitForPolicyEngineURI(kubeconfigPath, updateServiceFile string) (string, error) {
+	fmt.Println("⏳ 等待 UpdateService 状态中出现 policyEngineURI...")
+
+	maxAttempts := 40
+	for i := 1; i <= maxAttempts; i++ {
+		result, err := c.runOC("get", "-f", updateServiceFile,
+			"-n", updateServiceNamespace,
+			"-o", "json",
+			"--kubeconfig", kubeconfigPath)
+		output := result.Stdout
+		if err != nil {
+			fmt.Printf("⚠️  获取 UpdateService 状态失败 (尝试 %d/%d): %v\n", i, maxAttempts, err)
+		} else {
+			var updateService struct {
+				Status struct {
+					PolicyEngineURI string `json:"policyEngineURI"`
+				} `json:"status"`
+			}
+			if err := json.Unmarshal(output, &updateService); err == nil && updateService.Status.PolicyEngineURI != "" {
+				fmt.Printf("🔍 policyEngineURI: %s\n", updateService.Status.PolicyEngineURI)
+				return updateService.Status.PolicyEngineURI, nil
+			}
+		}
+
+		if i < maxAttempts {
+			fmt.Print(".")
+			if err := c.wait(time.Duration(i) * time.Second); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	fmt.Println("💡 您可以手动检查状态: oc get updateservice -n " + updateServiceNamespace)
+	return "", fmt.Errorf("等待超时，UpdateService 尚未报告 policyEngineURI")
+}
+
+// patchClusterVersionUpstream 将 ClusterVersion 的 upstream 指向本地 OSUS，并设置与镜像时一致的升级通道，
+// 使 OSUS 按该通道 (如 eus-4.14) 计算升级路径
+func (c *Client) patchClusterVersionUpstream(kubeconfigPath, upstreamURL, channel string) error {
+	patch := fmt.Sprintf(`{"spec": {"upstream": "%s", "channel": "%s"}}`, upstreamURL, channel)
+
+	result, err := c.runOC("patch", "clusterversion", "version",
+		"--type", "merge",
+		"-p", patch,
+		"--kubeconfig", kubeconfigPath)
+	output := result.Combined
+	if err != nil {
+		return fmt.Errorf("执行 oc patch 命令失败: %w\n输出: %s", err, string(output))
+	}
+
+	fmt.Printf("📋 命令输出: %s\n", strings.TrimSpace(string(output)))
+	return nil
+}
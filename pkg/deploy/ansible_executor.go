@@ -3,13 +3,20 @@ package deploy
 import (
 	"embed"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
 
+	"ocpack/pkg/auth"
 	"ocpack/pkg/config"
+	"ocpack/pkg/dnshosts"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/registrybundle"
+	"ocpack/pkg/rpms"
+	"ocpack/pkg/runner"
 )
 
 //go:embed ansible/bastion/*
@@ -26,7 +33,12 @@ type AnsibleExecutor struct {
 	config         *config.ClusterConfig
 	workDir        string
 	inventory      string
-	ConfigFilePath string // 配置文件路径
+	ConfigFilePath string               // 配置文件路径
+	Runner         runner.CommandRunner // 执行 ansible-playbook
+	Output         io.Writer            // playbook 输出的目标，默认为标准输出
+	// MirrorRegistryBundle Registry playbook 使用的 mirror-registry 离线安装包，
+	// 为空时使用下载目录中的安装包
+	MirrorRegistryBundle string
 }
 
 // NewAnsibleExecutor 创建新的 Ansible 执行器
@@ -34,27 +46,52 @@ func NewAnsibleExecutor(cfg *config.ClusterConfig, configFilePath string) (*Ansi
 	// 创建临时工作目录
 	workDir, err := os.MkdirTemp("", "ocpack-ansible-*")
 	if err != nil {
-		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+		return nil, i18n.Errorf("创建临时目录失败: %w", err)
 	}
 
 	return &AnsibleExecutor{
 		config:         cfg,
 		workDir:        workDir,
 		ConfigFilePath: configFilePath,
+		Runner:         runner.NewExecRunner(),
+		Output:         os.Stdout,
 	}, nil
 }
 
-// getAnsibleEnv 获取 Ansible 执行环境变量
+// getAnsibleEnv 获取追加到当前环境中的 Ansible 执行环境变量
 func (ae *AnsibleExecutor) getAnsibleEnv() []string {
-	env := os.Environ()
+	return []string{
+		// 设置基本的 Ansible 环境变量以获得清洁的输出
+		"ANSIBLE_STDOUT_CALLBACK=default",     // 使用默认回调插件
+		"ANSIBLE_HOST_KEY_CHECKING=false",     // 禁用主机密钥检查
+		"ANSIBLE_DISPLAY_SKIPPED_HOSTS=false", // 不显示跳过的主机
+		"ANSIBLE_VERBOSITY=0",                 // 设置最小详细程度
+	}
+}
 
-	// 设置基本的 Ansible 环境变量以获得清洁的输出
-	env = append(env, "ANSIBLE_STDOUT_CALLBACK=default")     // 使用默认回调插件
-	env = append(env, "ANSIBLE_HOST_KEY_CHECKING=false")     // 禁用主机密钥检查
-	env = append(env, "ANSIBLE_DISPLAY_SKIPPED_HOSTS=false") // 不显示跳过的主机
-	env = append(env, "ANSIBLE_VERBOSITY=0")                 // 设置最小详细程度
+// createInventoryFile 创建 inventory 文件。inventory 中可能包含 ansible_ssh_pass，因此仅允许当前用户读写
+func createInventoryFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
 
-	return env
+// runPlaybook 在工作目录中执行 playbook，输出直接透传到 ae.Output
+func (ae *AnsibleExecutor) runPlaybook(playbookPath, varsPath string) error {
+	i18n.Fprintf(ae.Output, "执行 Ansible playbook: %s\n", playbookPath)
+	i18n.Fprintf(ae.Output, "使用 inventory: %s\n", ae.inventory)
+	i18n.Fprintf(ae.Output, "工作目录: %s\n", ae.workDir)
+
+	_, err := ae.Runner.Run(runner.Command{
+		Name:   "ansible-playbook",
+		Args:   []string{"-i", ae.inventory, "-e", fmt.Sprintf("@%s", varsPath), playbookPath},
+		Dir:    ae.workDir,
+		Env:    ae.getAnsibleEnv(), // 包括 Ansible 回调插件配置
+		Stream: true,
+		Output: ae.Output,
+	})
+	if err != nil {
+		return i18n.Errorf("执行 Ansible playbook 失败: %w", err)
+	}
+	return nil
 }
 
 // ExtractBastionFiles 提取 bastion 相关的 Ansible 文件到临时目录
@@ -81,24 +118,24 @@ func (ae *AnsibleExecutor) ExtractBastionFiles() error {
 		// 读取文件内容
 		content, err := bastionAnsibleFiles.ReadFile(path)
 		if err != nil {
-			return fmt.Errorf("读取嵌入文件 %s 失败: %w", path, err)
+			return i18n.Errorf("读取嵌入文件 %s 失败: %w", path, err)
 		}
 
 		// 确保目标目录存在
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-			return fmt.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
+			return i18n.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
 		}
 
 		// 写入文件
 		if err := os.WriteFile(targetPath, content, 0644); err != nil {
-			return fmt.Errorf("写入文件 %s 失败: %w", targetPath, err)
+			return i18n.Errorf("写入文件 %s 失败: %w", targetPath, err)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("提取 Ansible 文件失败: %w", err)
+		return i18n.Errorf("提取 Ansible 文件失败: %w", err)
 	}
 
 	return nil
@@ -111,26 +148,26 @@ func (ae *AnsibleExecutor) GenerateInventory() error {
 	// 读取模板文件
 	templateContent, err := os.ReadFile(inventoryTemplatePath)
 	if err != nil {
-		return fmt.Errorf("读取 inventory 模板失败: %w", err)
+		return i18n.Errorf("读取 inventory 模板失败: %w", err)
 	}
 
 	// 解析模板
 	tmpl, err := template.New("inventory").Parse(string(templateContent))
 	if err != nil {
-		return fmt.Errorf("解析 inventory 模板失败: %w", err)
+		return i18n.Errorf("解析 inventory 模板失败: %w", err)
 	}
 
 	// 生成 inventory 文件
 	inventoryPath := filepath.Join(ae.workDir, "inventory")
-	inventoryFile, err := os.Create(inventoryPath)
+	inventoryFile, err := createInventoryFile(inventoryPath)
 	if err != nil {
-		return fmt.Errorf("创建 inventory 文件失败: %w", err)
+		return i18n.Errorf("创建 inventory 文件失败: %w", err)
 	}
 	defer inventoryFile.Close()
 
 	// 执行模板
 	if err := tmpl.Execute(inventoryFile, ae.config); err != nil {
-		return fmt.Errorf("生成 inventory 文件失败: %w", err)
+		return i18n.Errorf("生成 inventory 文件失败: %w", err)
 	}
 
 	ae.inventory = inventoryPath
@@ -141,39 +178,45 @@ func (ae *AnsibleExecutor) GenerateInventory() error {
 func (ae *AnsibleExecutor) GenerateVarsFile() error {
 	varsPath := filepath.Join(ae.workDir, "vars.yml")
 
-	// 获取当前工作目录（项目根目录）
-	currentDir, err := os.Getwd()
+	// 项目根目录为集群目录的上级目录，配置文件使用绝对路径时不依赖当前工作目录
+	clusterPath, err := ae.clusterDirPath()
 	if err != nil {
-		return fmt.Errorf("获取当前工作目录失败: %w", err)
+		return err
 	}
-
-	// 获取配置文件所在的目录名
-	configDir := filepath.Dir(filepath.Join(currentDir, ae.ConfigFilePath))
-	clusterDir := filepath.Base(configDir)
+	projectRoot := filepath.Dir(clusterPath)
+	clusterDir := filepath.Base(clusterPath)
+	downloadDir := ae.config.GetDownloadDir(clusterPath)
 
 	varsContent := fmt.Sprintf(`---
 cluster_info:
-  name: "%s"
   domain: "%s"
   cluster_id: "%s"
+  registry_hostname: "%s"
 
 bastion:
   ip: "%s"
 
+dns_servers: %s
+
 registry:
   ip: "%s"
   storage_path: "%s"
   registry_user: "%s"
+  registry_password: %q
 
 project_root: "%s"
 cluster_dir: "%s"
+download_dir: "%s"
 
 cluster:
   control_plane:
-`, ae.config.ClusterInfo.ClusterID, ae.config.ClusterInfo.Domain, ae.config.ClusterInfo.ClusterID, ae.config.Bastion.IP, ae.config.Registry.IP, ae.config.Registry.StoragePath, ae.config.Registry.RegistryUser, currentDir, clusterDir)
+`, ae.config.ClusterInfo.Domain, ae.config.ClusterInfo.ClusterID, ae.config.RegistryHostname(), ae.config.Bastion.IP, yamlList(ae.config.GetDNSServers()), ae.config.Registry.IP, ae.config.Registry.StoragePath, ae.config.Registry.RegistryUser, ae.config.GetRegistryPassword(), projectRoot, clusterDir, downloadDir)
 
-	// 添加 Control Plane 节点
+	// 添加 Control Plane 节点，未配置 ip 的 DHCP 节点不生成 DNS 记录和 HAProxy 后端
 	for _, cp := range ae.config.Cluster.ControlPlane {
+		if cp.IP == "" {
+			continue
+		}
 		varsContent += fmt.Sprintf(`    - name: "%s"
       ip: "%s"
       mac: "%s"
@@ -183,6 +226,9 @@ cluster:
 	varsContent += "  worker:\n"
 	// 添加 Worker 节点
 	for _, worker := range ae.config.Cluster.Worker {
+		if worker.IP == "" {
+			continue
+		}
 		varsContent += fmt.Sprintf(`    - name: "%s"
       ip: "%s"
       mac: "%s"
@@ -196,18 +242,161 @@ cluster:
     machine_network: "%s"
 `, ae.config.Cluster.Network.ClusterNetwork, ae.config.Cluster.Network.ServiceNetwork, ae.config.Cluster.Network.MachineNetwork)
 
-	if err := os.WriteFile(varsPath, []byte(varsContent), 0644); err != nil {
-		return fmt.Errorf("创建变量文件失败: %w", err)
+	// 添加 [bastion.dns] 和 [bastion.haproxy] 配置
+	varsContent += ae.bastionDNSVars(clusterPath)
+	varsContent += ae.haproxyVars()
+
+	// 添加软件包和离线 RPM 仓库配置
+	varsContent += ae.rpmRepoVars(downloadDir)
+
+	// 添加 [registry.storage] 的存储准备配置
+	varsContent += ae.registryStorageVars()
+
+	// 添加 proxy-cache 模式的拉取代理配置
+	if ae.config.IsProxyCache() {
+		varsContent += ae.proxyCacheVars(clusterPath)
+	}
+
+	bundle := ae.MirrorRegistryBundle
+	if bundle == "" {
+		bundle = registrybundle.DownloadPath(downloadDir)
+	}
+	varsContent += fmt.Sprintf("\nmirror_registry_bundle: %q\n", bundle)
+
+	// 变量文件包含 Registry 密码，仅允许当前用户读取
+	if err := os.WriteFile(varsPath, []byte(varsContent), 0600); err != nil {
+		return i18n.Errorf("创建变量文件失败: %w", err)
 	}
 
 	return nil
 }
 
+// clusterDirPath 返回配置文件所在的集群目录的绝对路径，相对路径基于当前工作目录
+func (ae *AnsibleExecutor) clusterDirPath() (string, error) {
+	configPath, err := filepath.Abs(ae.ConfigFilePath)
+	if err != nil {
+		return "", i18n.Errorf("解析配置文件路径失败: %w", err)
+	}
+	return filepath.Dir(configPath), nil
+}
+
+// bastionDNSVars 生成 Bastion named 的上游转发、按域名转发、额外记录和反向解析区域配置，
+// 以及 hosts 模式下 dnsmasq 使用的文件 (由 dnshosts.Write 预先生成在集群目录中)
+func (ae *AnsibleExecutor) bastionDNSVars(clusterDir string) string {
+	dns := ae.config.Bastion.DNS
+	vars := fmt.Sprintf(`
+bastion_dns:
+  mode: %q
+  forwarders: %s
+  reverse_zone: %t
+  dnssec_validation: %t
+  hosts_file: %q
+  dnsmasq_conf: %q
+  remote_hosts_file: %q
+  remote_dnsmasq_conf: %q
+`, dns.GetMode(), yamlList(dns.Forwarders), dns.ReverseZoneEnabled(), dns.DNSSECValidationEnabled(),
+		filepath.Join(dnshosts.Dir(clusterDir), dnshosts.HostsFilename), filepath.Join(dnshosts.Dir(clusterDir), dnshosts.DnsmasqFilename),
+		dnshosts.RemoteHostsPath(ae.config), dnshosts.RemoteDnsmasqPath(ae.config))
+
+	vars += "  conditional_forwarders:" + yamlEmptyList(len(dns.ConditionalForwarders))
+	for _, zone := range dns.ConditionalForwarders {
+		vars += fmt.Sprintf("    - zone: %q\n      servers: %s\n", strings.TrimSuffix(zone.Zone, "."), yamlList(zone.Servers))
+	}
+
+	vars += "  records:" + yamlEmptyList(len(dns.Records))
+	for _, record := range dns.Records {
+		vars += fmt.Sprintf("    - name: %q\n      type: %q\n      value: %q\n", record.Name, record.RecordType(), record.Value)
+	}
+
+	// 位于机器网络反向解析区域中的 A 记录，同时生成 PTR 记录
+	reverse := ae.config.ReverseDNSRecords()
+	vars += "  reverse_records:" + yamlEmptyList(len(reverse))
+	for _, record := range reverse {
+		vars += fmt.Sprintf("    - name: %q\n      ip: %q\n", record.Name, record.Value)
+	}
+	return vars
+}
+
+// haproxyVars 生成 HAProxy 统计页面和 Ingress 前端端口配置
+func (ae *AnsibleExecutor) haproxyVars() string {
+	haproxy := ae.config.Bastion.HAProxy
+	return fmt.Sprintf(`
+haproxy:
+  stats_port: %d
+  stats_user: %q
+  stats_password: %q
+  http_port: %d
+  https_port: %d
+`, haproxy.GetStatsPort(), haproxy.StatsUser, haproxy.StatsPassword, haproxy.GetHTTPPort(), haproxy.GetHTTPSPort())
+}
+
+// registryStorageVars 生成 Registry 存储设备、文件系统和 XFS 项目配额 (KiB) 配置
+func (ae *AnsibleExecutor) registryStorageVars() string {
+	storage := ae.config.Registry.Storage
+	quota, _ := storage.GetQuota()
+	return fmt.Sprintf(`
+registry_storage:
+  device: %q
+  filesystem: %q
+  quota_kib: %d
+  project_id: %d
+`, storage.Device, storage.GetFilesystem(), (quota+1023)/1024, registryQuotaProjectID)
+}
+
+// proxyCacheVars 生成拉取代理的镜像和上游仓库配置，上游的认证从 merged-auth.json (不存在时为 pull-secret.txt) 读取
+func (ae *AnsibleExecutor) proxyCacheVars(clusterDir string) string {
+	authFile, err := os.ReadFile(auth.MergedAuthPath(clusterDir))
+	if err != nil {
+		authFile, _ = os.ReadFile(auth.PullSecretPath(clusterDir))
+	}
+
+	vars := fmt.Sprintf("\nproxy_cache:\n  image: %q\n  upstreams:\n", ae.config.GetProxyCacheImage())
+	for _, upstream := range ae.config.GetProxyCacheUpstreams() {
+		cred, _ := auth.Lookup(authFile, upstream.Source)
+		vars += fmt.Sprintf("    - source: %q\n      port: %d\n      username: %q\n      password: %q\n",
+			upstream.Source, upstream.Port, cred.Username, cred.Password)
+	}
+	return vars
+}
+
+// yamlEmptyList 列表为空时返回行内空列表，否则换行开始块列表
+func yamlEmptyList(length int) string {
+	if length == 0 {
+		return " []\n"
+	}
+	return "\n"
+}
+
+// rpmRepoVars 生成各节点安装的软件包列表，以及 mirror-rpms 生成的离线仓库 (存在时 playbook 只从该仓库安装软件包)
+func (ae *AnsibleExecutor) rpmRepoVars(downloadDir string) string {
+	vars := "\npackages:\n"
+	for _, role := range []string{"bastion", "registry", "pxe"} {
+		vars += fmt.Sprintf("  %s: %s\n", role, yamlList(rpms.Packages[role]))
+	}
+	vars += fmt.Sprintf("  optional: %s\n", yamlList(rpms.OptionalPackages))
+
+	vars += fmt.Sprintf(`
+rpm_repo:
+  enabled: %t
+  path: %q
+`, rpms.HasRepo(downloadDir), rpms.RepoDir(downloadDir))
+	return vars
+}
+
+// yamlList 将字符串列表格式化为 YAML 行内列表
+func yamlList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 // CheckAnsibleInstalled 检查 Ansible 是否已安装
 func (ae *AnsibleExecutor) CheckAnsibleInstalled() error {
-	_, err := exec.LookPath("ansible-playbook")
+	_, err := ae.Runner.LookPath("ansible-playbook")
 	if err != nil {
-		return fmt.Errorf("未找到 ansible-playbook 命令，请先安装 Ansible")
+		return i18n.Errorf("未找到 ansible-playbook 命令，请先安装 Ansible")
 	}
 	return nil
 }
@@ -237,32 +426,7 @@ func (ae *AnsibleExecutor) RunBastionPlaybook() error {
 	// 执行 playbook
 	playbookPath := filepath.Join(ae.workDir, "ansible/bastion/playbook.yml")
 	varsPath := filepath.Join(ae.workDir, "vars.yml")
-
-	cmd := exec.Command("ansible-playbook",
-		"-i", ae.inventory,
-		"-e", fmt.Sprintf("@%s", varsPath),
-		playbookPath,
-	)
-
-	// 设置工作目录
-	cmd.Dir = ae.workDir
-
-	// 设置环境变量（包括 Ansible 回调插件配置）
-	cmd.Env = ae.getAnsibleEnv()
-
-	// 设置输出
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	fmt.Printf("执行 Ansible playbook: %s\n", playbookPath)
-	fmt.Printf("使用 inventory: %s\n", ae.inventory)
-	fmt.Printf("工作目录: %s\n", ae.workDir)
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("执行 Ansible playbook 失败: %w", err)
-	}
-
-	return nil
+	return ae.runPlaybook(playbookPath, varsPath)
 }
 
 // Cleanup 清理临时文件
@@ -302,24 +466,24 @@ func (ae *AnsibleExecutor) ExtractRegistryFiles() error {
 		// 读取文件内容
 		content, err := registryAnsibleFiles.ReadFile(path)
 		if err != nil {
-			return fmt.Errorf("读取嵌入文件 %s 失败: %w", path, err)
+			return i18n.Errorf("读取嵌入文件 %s 失败: %w", path, err)
 		}
 
 		// 确保目标目录存在
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-			return fmt.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
+			return i18n.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
 		}
 
 		// 写入文件
 		if err := os.WriteFile(targetPath, content, 0644); err != nil {
-			return fmt.Errorf("写入文件 %s 失败: %w", targetPath, err)
+			return i18n.Errorf("写入文件 %s 失败: %w", targetPath, err)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("提取 Ansible 文件失败: %w", err)
+		return i18n.Errorf("提取 Ansible 文件失败: %w", err)
 	}
 
 	return nil
@@ -332,26 +496,26 @@ func (ae *AnsibleExecutor) GenerateRegistryInventory() error {
 	// 读取模板文件
 	templateContent, err := os.ReadFile(inventoryTemplatePath)
 	if err != nil {
-		return fmt.Errorf("读取 inventory 模板失败: %w", err)
+		return i18n.Errorf("读取 inventory 模板失败: %w", err)
 	}
 
 	// 解析模板
 	tmpl, err := template.New("inventory").Parse(string(templateContent))
 	if err != nil {
-		return fmt.Errorf("解析 inventory 模板失败: %w", err)
+		return i18n.Errorf("解析 inventory 模板失败: %w", err)
 	}
 
 	// 生成 inventory 文件
 	inventoryPath := filepath.Join(ae.workDir, "registry_inventory")
-	inventoryFile, err := os.Create(inventoryPath)
+	inventoryFile, err := createInventoryFile(inventoryPath)
 	if err != nil {
-		return fmt.Errorf("创建 inventory 文件失败: %w", err)
+		return i18n.Errorf("创建 inventory 文件失败: %w", err)
 	}
 	defer inventoryFile.Close()
 
 	// 执行模板
 	if err := tmpl.Execute(inventoryFile, ae.config); err != nil {
-		return fmt.Errorf("生成 inventory 文件失败: %w", err)
+		return i18n.Errorf("生成 inventory 文件失败: %w", err)
 	}
 
 	ae.inventory = inventoryPath
@@ -380,35 +544,13 @@ func (ae *AnsibleExecutor) RunRegistryPlaybook() error {
 		return err
 	}
 
-	// 执行 playbook
+	// 执行 playbook，proxy-cache 模式部署拉取代理而不是 mirror-registry
 	playbookPath := filepath.Join(ae.workDir, "ansible/registry/playbook.yml")
-	varsPath := filepath.Join(ae.workDir, "vars.yml")
-
-	cmd := exec.Command("ansible-playbook",
-		"-i", ae.inventory,
-		"-e", fmt.Sprintf("@%s", varsPath),
-		playbookPath,
-	)
-
-	// 设置工作目录
-	cmd.Dir = ae.workDir
-
-	// 设置环境变量（包括 Ansible 回调插件配置）
-	cmd.Env = ae.getAnsibleEnv()
-
-	// 设置输出
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	fmt.Printf("执行 Ansible playbook: %s\n", playbookPath)
-	fmt.Printf("使用 inventory: %s\n", ae.inventory)
-	fmt.Printf("工作目录: %s\n", ae.workDir)
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("执行 Ansible playbook 失败: %w", err)
+	if ae.config.IsProxyCache() {
+		playbookPath = filepath.Join(ae.workDir, "ansible/registry/proxy-cache.yml")
 	}
-
-	return nil
+	varsPath := filepath.Join(ae.workDir, "vars.yml")
+	return ae.runPlaybook(playbookPath, varsPath)
 }
 
 // ExtractPXEFiles 提取 PXE 相关的 Ansible 文件到临时目录
@@ -435,24 +577,24 @@ func (ae *AnsibleExecutor) ExtractPXEFiles() error {
 		// 读取文件内容
 		content, err := pxeAnsibleFiles.ReadFile(path)
 		if err != nil {
-			return fmt.Errorf("读取嵌入文件 %s 失败: %w", path, err)
+			return i18n.Errorf("读取嵌入文件 %s 失败: %w", path, err)
 		}
 
 		// 确保目标目录存在
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-			return fmt.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
+			return i18n.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
 		}
 
 		// 写入文件
 		if err := os.WriteFile(targetPath, content, 0644); err != nil {
-			return fmt.Errorf("写入文件 %s 失败: %w", targetPath, err)
+			return i18n.Errorf("写入文件 %s 失败: %w", targetPath, err)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("提取 Ansible 文件失败: %w", err)
+		return i18n.Errorf("提取 Ansible 文件失败: %w", err)
 	}
 
 	return nil
@@ -465,26 +607,26 @@ func (ae *AnsibleExecutor) GeneratePXEInventory() error {
 	// 读取模板文件
 	templateContent, err := os.ReadFile(inventoryTemplatePath)
 	if err != nil {
-		return fmt.Errorf("读取 inventory 模板失败: %w", err)
+		return i18n.Errorf("读取 inventory 模板失败: %w", err)
 	}
 
 	// 解析模板
 	tmpl, err := template.New("inventory").Parse(string(templateContent))
 	if err != nil {
-		return fmt.Errorf("解析 inventory 模板失败: %w", err)
+		return i18n.Errorf("解析 inventory 模板失败: %w", err)
 	}
 
 	// 生成 inventory 文件
 	inventoryPath := filepath.Join(ae.workDir, "pxe_inventory")
-	inventoryFile, err := os.Create(inventoryPath)
+	inventoryFile, err := createInventoryFile(inventoryPath)
 	if err != nil {
-		return fmt.Errorf("创建 inventory 文件失败: %w", err)
+		return i18n.Errorf("创建 inventory 文件失败: %w", err)
 	}
 	defer inventoryFile.Close()
 
 	// 执行模板
 	if err := tmpl.Execute(inventoryFile, ae.config); err != nil {
-		return fmt.Errorf("生成 inventory 文件失败: %w", err)
+		return i18n.Errorf("生成 inventory 文件失败: %w", err)
 	}
 
 	ae.inventory = inventoryPath
@@ -516,30 +658,5 @@ func (ae *AnsibleExecutor) RunPXEPlaybook() error {
 	// 执行 playbook
 	playbookPath := filepath.Join(ae.workDir, "ansible/pxe/playbook.yml")
 	varsPath := filepath.Join(ae.workDir, "vars.yml")
-
-	cmd := exec.Command("ansible-playbook",
-		"-i", ae.inventory,
-		"-e", fmt.Sprintf("@%s", varsPath),
-		playbookPath,
-	)
-
-	// 设置工作目录
-	cmd.Dir = ae.workDir
-
-	// 设置环境变量（包括 Ansible 回调插件配置）
-	cmd.Env = ae.getAnsibleEnv()
-
-	// 设置输出
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	fmt.Printf("执行 Ansible playbook: %s\n", playbookPath)
-	fmt.Printf("使用 inventory: %s\n", ae.inventory)
-	fmt.Printf("工作目录: %s\n", ae.workDir)
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("执行 Ansible playbook 失败: %w", err)
-	}
-
-	return nil
+	return ae.runPlaybook(playbookPath, varsPath)
 }
@@ -1,23 +1,30 @@
 package deploy
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/dnshosts"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
 )
 
 // --- Constants ---
-// 优化：将硬编码的端口号定义为常量，便于管理
+// 优化：将硬编码的端口号定义为常量，便于管理；HAProxy 的端口来自 [bastion.haproxy]
 const (
-	dnsPort       = 53
-	haproxyPort   = 9000
-	apiServerPort = 6443
+	dnsPort = 53
 )
 
 // BastionDeployer 用于部署 Bastion 节点
 type BastionDeployer struct {
 	config      *config.ClusterConfig
 	downloadDir string
+	Out         io.Writer // 部署过程的输出目标，默认为标准输出
 }
 
 // NewBastionDeployer 创建一个新的 Bastion 部署器
@@ -25,26 +32,38 @@ func NewBastionDeployer(cfg *config.ClusterConfig, downloadDir string) *BastionD
 	return &BastionDeployer{
 		config:      cfg,
 		downloadDir: downloadDir,
+		Out:         os.Stdout,
 	}
 }
 
-// Deploy 执行 Bastion 节点部署
+// Deploy 执行 Bastion 节点部署，ctx 被取消或超时时终止正在执行的 playbook
 // 优化：重构为职责更单一的"编排器"函数
-func (d *BastionDeployer) Deploy(configFilePath string) error {
-	fmt.Printf("▶️  开始部署 Bastion 节点 (%s)...\n", d.config.Bastion.IP)
+func (d *BastionDeployer) Deploy(ctx context.Context, configFilePath string) error {
+	i18n.Fprintf(d.Out, "▶️  开始部署 Bastion 节点 (%s)...\n", d.config.Bastion.IP)
 
 	// 1. 创建 Ansible 执行器
-	fmt.Println("➡️  正在初始化部署环境...")
+	i18n.Fprintln(d.Out, "➡️  正在初始化部署环境...")
 	executor, err := NewAnsibleExecutor(d.config, configFilePath)
 	if err != nil {
-		return fmt.Errorf("创建ansible执行器失败: %w", err)
+		return i18n.Errorf("创建ansible执行器失败: %w", err)
 	}
 	defer executor.Cleanup()
+	executor.Output = d.Out
+	executor.Runner = runner.WithContext(ctx, executor.Runner)
+
+	// hosts 模式下 dnsmasq 使用的文件先生成在集群目录中，playbook 将其复制到 Bastion
+	if d.config.DNSHostsMode() {
+		files, err := dnshosts.Write(d.config, filepath.Dir(configFilePath))
+		if err != nil {
+			return i18n.Errorf("生成 hosts 模式 DNS 配置失败: %w", err)
+		}
+		i18n.Fprintf(d.Out, "📝 DNS 使用 hosts 模式，已生成: %s\n", strings.Join(files, ", "))
+	}
 
 	// 2. 执行 Bastion playbook
-	fmt.Println("🚀 正在执行 Bastion 部署 playbook (此过程可能需要几分钟)...")
+	i18n.Fprintln(d.Out, "🚀 正在执行 Bastion 部署 playbook (此过程可能需要几分钟)...")
 	if err := executor.RunBastionPlaybook(); err != nil {
-		return fmt.Errorf("bastion节点部署失败: %w", err)
+		return i18n.Errorf("bastion节点部署失败: %w", err)
 	}
 
 	// 3. 打印成功信息
@@ -57,9 +76,18 @@ func (d *BastionDeployer) Deploy(configFilePath string) error {
 // printSuccessMessage 打印部署成功后的信息
 // 优化：提取重复的打印逻辑到此函数中
 func (d *BastionDeployer) printSuccessMessage() {
-	fmt.Println("\n✅ Bastion 节点部署完成！")
-	fmt.Printf("   DNS 服务器: %s:%d\n", d.config.Bastion.IP, dnsPort)
-	fmt.Printf("   HAProxy 统计页面: http://%s:%d/stats\n", d.config.Bastion.IP, haproxyPort)
+	i18n.Fprintln(d.Out, "\n✅ Bastion 节点部署完成！")
+	i18n.Fprintf(d.Out, "   DNS 服务器: %s:%d\n", d.config.Bastion.IP, dnsPort)
+	if d.config.DNSHostsMode() {
+		i18n.Fprintf(d.Out, "   DNS 模式: hosts (dnsmasq)，管理员工作站可使用 ocpack dns-hosts 生成的 /etc/hosts 片段\n")
+	}
+	haproxy := d.config.Bastion.HAProxy
+	i18n.Fprintf(d.Out, "   HAProxy 统计页面: http://%s:%d/stats\n", d.config.Bastion.IP, haproxy.GetStatsPort())
+	if haproxy.StatsAuthEnabled() {
+		i18n.Fprintf(d.Out, "   统计页面用户: %s (密码见 [bastion.haproxy] stats_password)\n", haproxy.StatsUser)
+	}
+	fmt.Fprintf(d.Out, "   API: %s:%d, Machine Config: %s:%d\n", d.config.Bastion.IP, config.APIServerPort, d.config.Bastion.IP, config.MachineConfigPort)
+	fmt.Fprintf(d.Out, "   Ingress: HTTP %s:%d, HTTPS %s:%d\n", d.config.Bastion.IP, haproxy.GetHTTPPort(), d.config.Bastion.IP, haproxy.GetHTTPSPort())
 }
 
 /*
@@ -1,9 +1,11 @@
 package deploy
 
 import (
-	"fmt"
+	"context"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
 )
 
 // PXEDeployer 用于部署 PXE 服务
@@ -20,29 +22,33 @@ func NewPXEDeployer(cfg *config.ClusterConfig, downloadDir string) *PXEDeployer
 	}
 }
 
-// Deploy 执行 PXE 服务部署
-func (d *PXEDeployer) Deploy(configFilePath string) error {
-	fmt.Printf("开始在 Bastion 节点 (%s) 上部署 PXE 服务...\n", d.config.Bastion.IP)
+// Deploy 执行 PXE 服务部署，ctx 被取消或超时时终止正在执行的 playbook
+func (d *PXEDeployer) Deploy(ctx context.Context, configFilePath string) error {
+	if !d.config.BastionEnabled() {
+		return i18n.Errorf("bastion.enabled = false，PXE 服务需部署在 Bastion 节点上；请使用站点已有的 PXE 服务并配置 infra.pxe_asset_url")
+	}
+	i18n.Printf("开始在 Bastion 节点 (%s) 上部署 PXE 服务...\n", d.config.Bastion.IP)
 
 	// 使用 Ansible 执行器进行部署
 	executor, err := NewAnsibleExecutor(d.config, configFilePath)
 	if err != nil {
-		return fmt.Errorf("创建 Ansible 执行器失败: %w", err)
+		return i18n.Errorf("创建 Ansible 执行器失败: %w", err)
 	}
 	defer executor.Cleanup()
+	executor.Runner = runner.WithContext(ctx, executor.Runner)
 
 	// 执行 PXE playbook
 	if err := executor.RunPXEPlaybook(); err != nil {
-		return fmt.Errorf("PXE 服务部署失败: %w", err)
+		return i18n.Errorf("PXE 服务部署失败: %w", err)
 	}
 
-	fmt.Println("PXE 服务部署完成！")
-	fmt.Printf("PXE 服务器: %s\n", d.config.Bastion.IP)
-	fmt.Printf("TFTP 服务: tftp://%s\n", d.config.Bastion.IP)
-	fmt.Printf("HTTP 服务: http://%s:8080/pxe (端口8080避免与HAProxy冲突)\n", d.config.Bastion.IP)
-	fmt.Printf("DHCP 服务: %s (已配置MAC-IP映射)\n", d.config.Bastion.IP)
-	fmt.Printf("PXE 文件目录: /var/lib/tftpboot\n")
-	fmt.Printf("HTTP 文件目录: /var/www/html/pxe\n")
+	i18n.Println("PXE 服务部署完成！")
+	i18n.Printf("PXE 服务器: %s\n", d.config.Bastion.IP)
+	i18n.Printf("TFTP 服务: tftp://%s\n", d.config.Bastion.IP)
+	i18n.Printf("HTTP 服务: http://%s:8080/pxe (端口8080避免与HAProxy冲突)\n", d.config.Bastion.IP)
+	i18n.Printf("DHCP 服务: %s (已配置MAC-IP映射)\n", d.config.Bastion.IP)
+	i18n.Printf("PXE 文件目录: /var/lib/tftpboot\n")
+	i18n.Printf("HTTP 文件目录: /var/www/html/pxe\n")
 
 	return nil
 }
@@ -1,99 +1,216 @@
 package deploy
 
 import (
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/registry"
+	"ocpack/pkg/registrybundle"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/storage"
+	"ocpack/pkg/utils"
 )
 
 // --- Constants ---
 // 优化: 将硬编码的值定义为常量
 const (
-	registryPort            = "8443"
-	registryHealthEndpoint  = "/health/instance"
-	registryDefaultPassword = "ztesoft123"
+	registryPort = "8443"
 )
 
-// DeployRegistry 部署 Registry 节点，如果它尚未部署。
-func DeployRegistry(cfg *config.ClusterConfig, configFilePath string) error {
-	fmt.Println("▶️  开始部署 Registry 节点...")
+// DeployRegistry 部署 Registry 节点，如果它尚未部署。ctx 被取消或超时时终止正在执行的 playbook
+func DeployRegistry(ctx context.Context, cfg *config.ClusterConfig, configFilePath string) error {
+	return DeployRegistryTo(ctx, os.Stdout, cfg, configFilePath)
+}
+
+// DeployRegistryTo 与 DeployRegistry 相同，部署过程的输出写入 out，便于与其他部署任务并行执行。
+func DeployRegistryTo(ctx context.Context, out io.Writer, cfg *config.ClusterConfig, configFilePath string) error {
+	i18n.Fprintln(out, "▶️  开始部署 Registry 节点...")
 
 	// 1. 验证配置
 	if err := config.ValidateRegistryConfig(cfg); err != nil {
-		return fmt.Errorf("配置验证失败: %w", err)
+		return i18n.Errorf("配置验证失败: %w", err)
+	}
+
+	// proxy-cache 模式部署拉取代理，不使用 mirror-registry 安装包
+	if cfg.IsProxyCache() {
+		return deployProxyCache(ctx, out, cfg, configFilePath)
 	}
 
 	// 2. 检查 Registry 是否已经部署
 	registryHostPort := fmt.Sprintf("%s:%s", cfg.Registry.IP, registryPort)
-	fmt.Printf("➡️  正在检查 Registry 在 %s 的状态...\n", registryHostPort)
+	i18n.Fprintf(out, "➡️  正在检查 Registry 在 %s 的状态...\n", registryHostPort)
 
 	deployed, err := checkRegistryDeployed(cfg)
 	if err == nil && deployed {
-		fmt.Println("🔄 Registry 节点已经部署并运行。跳过重复部署。")
-		printSuccessMessage(cfg) // 优化: 调用统一的成功消息函数
+		i18n.Fprintln(out, "🔄 Registry 节点已经部署并运行。跳过重复部署。")
+		printSuccessMessage(out, cfg) // 优化: 调用统一的成功消息函数
 		return nil
 	}
 
 	// 如果检查出错，打印信息但继续执行部署，因为错误通常意味着服务不可用
 	if err != nil {
-		fmt.Printf("ℹ️  检查失败 (这通常意味着 Registry 未部署): %v\n", err)
+		i18n.Fprintf(out, "ℹ️  检查失败 (这通常意味着 Registry 未部署): %v\n", err)
 	}
 
-	// 3. 执行部署
-	fmt.Printf("🚀 Registry 未部署或不可访问，开始执行部署 playbook (%s)...\n", cfg.Registry.IP)
+	// 3. 检查存储设备和可用空间，空间不足时在执行 playbook 前失败
+	if err := checkRegistryStorage(out, cfg, clusterDirOf(configFilePath)); err != nil {
+		return err
+	}
+
+	// 4. 查找 mirror-registry 离线安装包：下载目录中没有时使用 save-image 归档的副本
+	bundle, err := findMirrorRegistryBundle(ctx, out, cfg, configFilePath)
+	if err != nil {
+		return err
+	}
+	i18n.Fprintf(out, "📦 mirror-registry 安装包: %s\n", bundle)
+
+	// 5. 执行部署
+	i18n.Fprintf(out, "🚀 Registry 未部署或不可访问，开始执行部署 playbook (%s)...\n", cfg.Registry.IP)
 
 	// 创建 Ansible 执行器
 	executor, err := NewAnsibleExecutor(cfg, configFilePath)
 	if err != nil {
-		return fmt.Errorf("创建ansible执行器失败: %w", err)
+		return i18n.Errorf("创建ansible执行器失败: %w", err)
 	}
 	defer executor.Cleanup()
+	executor.Output = out
+	executor.Runner = runner.WithContext(ctx, executor.Runner)
+	executor.MirrorRegistryBundle = bundle
 
 	// 执行 Registry playbook
 	if err := executor.RunRegistryPlaybook(); err != nil {
-		return fmt.Errorf("registry节点部署失败: %w", err)
+		return i18n.Errorf("registry节点部署失败: %w", err)
 	}
 
-	printSuccessMessage(cfg) // 优化: 调用统一的成功消息函数
+	printSuccessMessage(out, cfg) // 优化: 调用统一的成功消息函数
 	return nil
 }
 
-// checkRegistryDeployed 检查 Registry 是否已经部署并返回结果和错误。
-// 优化: 返回 (bool, error) 以提供更丰富的上下文。
-func checkRegistryDeployed(cfg *config.ClusterConfig) (bool, error) {
-	registryURL := fmt.Sprintf("https://%s:%s%s", cfg.Registry.IP, registryPort, registryHealthEndpoint)
+// deployProxyCache 在 Registry 节点上为每个上游仓库部署 registry:2 拉取代理，全部代理可访问时跳过
+func deployProxyCache(ctx context.Context, out io.Writer, cfg *config.ClusterConfig, configFilePath string) error {
+	i18n.Fprintf(out, "➡️  Registry 为 proxy-cache 模式，正在检查 %s 上的拉取代理...\n", cfg.Registry.IP)
+	err := checkProxyCacheDeployed(cfg, clusterDirOf(configFilePath))
+	if err == nil {
+		i18n.Fprintln(out, "🔄 拉取代理已经部署并运行。跳过重复部署。")
+		printProxyCacheMessage(out, cfg)
+		return nil
+	}
+	i18n.Fprintf(out, "ℹ️  检查失败 (这通常意味着拉取代理未部署): %v\n", err)
+	if err := checkRegistryStorage(out, cfg, clusterDirOf(configFilePath)); err != nil {
+		return err
+	}
+
+	i18n.Fprintf(out, "🚀 开始执行拉取代理部署 playbook (%s)...\n", cfg.Registry.IP)
+	executor, err := NewAnsibleExecutor(cfg, configFilePath)
+	if err != nil {
+		return i18n.Errorf("创建ansible执行器失败: %w", err)
+	}
+	defer executor.Cleanup()
+	executor.Output = out
+	executor.Runner = runner.WithContext(ctx, executor.Runner)
+
+	if err := executor.RunRegistryPlaybook(); err != nil {
+		return i18n.Errorf("拉取代理部署失败: %w", err)
+	}
+	printProxyCacheMessage(out, cfg)
+	return nil
+}
 
-	// 创建一个可重用的 HTTP 客户端，并设置超时和不安全的 TLS
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// checkProxyCacheDeployed 检查每个上游的拉取代理是否响应 /v2/，使用部署时保存到集群目录的 CA 校验证书。
+// 首次部署前集群目录中还没有该 CA，证书校验失败同样视为未部署
+func checkProxyCacheDeployed(cfg *config.ClusterConfig, clusterDir string) error {
+	tlsConfig, err := registry.TLSConfig(cfg, clusterDir)
+	if err != nil {
+		return err
 	}
 	client := &http.Client{
 		Timeout:   10 * time.Second,
-		Transport: tr,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	for _, upstream := range cfg.GetProxyCacheUpstreams() {
+		url := fmt.Sprintf("https://%s:%d/v2/", cfg.Registry.IP, upstream.Port)
+		resp, err := client.Get(url)
+		if err != nil {
+			return i18n.Errorf("无法访问 %s 的拉取代理 '%s': %w", upstream.Source, url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return i18n.Errorf("%s 的拉取代理 '%s' 返回 %s", upstream.Source, url, resp.Status)
+		}
 	}
+	return nil
+}
+
+// printProxyCacheMessage 打印拉取代理的地址
+func printProxyCacheMessage(out io.Writer, cfg *config.ClusterConfig) {
+	i18n.Fprintln(out, "✅ 拉取代理部署完成！")
+	for _, upstream := range cfg.GetProxyCacheUpstreams() {
+		fmt.Fprintf(out, "   %s -> %s\n", upstream.Source, cfg.ProxyCacheHost(upstream))
+	}
+	i18n.Fprintln(out, "   镜像在节点首次拉取时从上游缓存，无需执行 save-image 和 load-image")
+}
 
-	// 尝试访问 Registry 健康检查端点
-	resp, err := client.Get(registryURL)
+// findMirrorRegistryBundle 返回部署使用的 mirror-registry 离线安装包。下载目录中没有安装包时，
+// 使用 save-image 随镜像归档的副本，s3:// 存储先从对象存储下载该副本
+func findMirrorRegistryBundle(ctx context.Context, out io.Writer, cfg *config.ClusterConfig, configFilePath string) (string, error) {
+	clusterDir := clusterDirOf(configFilePath)
+	downloadDir := cfg.GetDownloadDir(clusterDir)
+
+	backend, err := storage.NewTo(out, clusterDir, cfg)
 	if err != nil {
-		return false, fmt.Errorf("无法访问 health check 端点 '%s': %w", registryURL, err)
+		return "", err
+	}
+	if !utils.FileExists(registrybundle.DownloadPath(downloadDir)) && !utils.FileExists(registrybundle.ArchivePath(backend.Dir())) {
+		i18n.Fprintf(out, "➡️  下载目录中没有 mirror-registry 安装包，尝试从镜像存储 %s 获取...\n", backend)
+		if err := backend.Pull(ctx, registrybundle.ArchiveDir+"/*"); err != nil {
+			return "", err
+		}
+	}
+	return registrybundle.Find(downloadDir, backend.Dir())
+}
+
+// clusterDirOf 返回配置文件所在的集群目录
+func clusterDirOf(configFilePath string) string {
+	if configPath, err := filepath.Abs(configFilePath); err == nil {
+		configFilePath = configPath
 	}
-	defer resp.Body.Close()
+	return filepath.Dir(configFilePath)
+}
 
-	// 如果返回 200 OK 状态码，说明 Registry 已经部署并运行
-	return resp.StatusCode == http.StatusOK, nil
+// checkRegistryDeployed 检查 Registry 是否已经部署并返回结果和错误。
+// 优化: 返回 (bool, error) 以提供更丰富的上下文。
+func checkRegistryDeployed(cfg *config.ClusterConfig) (bool, error) {
+	// 部署前本机还没有 Registry 的 CA 证书，也可能无法解析仓库域名，因此通过 IP 访问且不校验证书；
+	// 健康检查接口不需要认证，请求中不带仓库密码
+	client := registry.NewInsecureClient(cfg)
+	client.Host = net.JoinHostPort(cfg.Registry.IP, registryPort)
+
+	// 健康检查端点返回 200 OK 说明 Registry 已经部署并运行
+	err := client.Health()
+	var statusErr *registry.StatusError
+	if errors.As(err, &statusErr) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // printSuccessMessage 打印部署成功后的信息。
 // 优化: 提取重复代码到此函数中。
-func printSuccessMessage(cfg *config.ClusterConfig) {
+func printSuccessMessage(out io.Writer, cfg *config.ClusterConfig) {
 	registryURL := fmt.Sprintf("https://%s:%s", cfg.Registry.IP, registryPort)
-	fmt.Println("✅ Registry 部署完成！")
-	fmt.Printf("   Quay 镜像仓库: %s\n", registryURL)
-	fmt.Printf("   用户名: %s\n", cfg.Registry.RegistryUser)
-	fmt.Printf("   密码: %s\n", registryDefaultPassword)
+	i18n.Fprintln(out, "✅ Registry 部署完成！")
+	i18n.Fprintf(out, "   Quay 镜像仓库: %s\n", registryURL)
+	i18n.Fprintf(out, "   用户名: %s\n", cfg.Registry.RegistryUser)
+	i18n.Fprintf(out, "   密码: %s\n", cfg.GetRegistryPassword())
 }
 
 /*
@@ -0,0 +1,181 @@
+package deploy
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/storage"
+	"ocpack/pkg/transfer"
+	"ocpack/pkg/utils"
+	"ocpack/pkg/workspace"
+)
+
+// registryQuotaProjectID storage_path 的 XFS 项目配额使用的项目 ID
+const registryQuotaProjectID = 4242
+
+// registryHeadroomPercent 镜像归档之外需要的余量：推送时 Quay 先写入上传中的镜像层，数据库也随镜像增长
+const registryHeadroomPercent = 20
+
+// runOnRegistry 通过 SSH 在 Registry 节点上执行脚本并返回输出，测试时可替换
+var runOnRegistry = func(cfg *config.ClusterConfig, script string) (string, error) {
+	client, err := utils.NewSSHClient(cfg.Registry.IP, cfg.Registry.Username, cfg.Registry.Password, cfg.Registry.SSHKeyPath)
+	if err != nil {
+		return "", i18n.Errorf("连接 Registry 节点 %s 失败: %w", cfg.Registry.IP, err)
+	}
+	defer client.Close()
+	return client.RunCommand(script)
+}
+
+// storageState Registry 节点上存储路径和存储设备的状态
+type storageState struct {
+	available  int64  // storage_path 所在文件系统的可用空间
+	mountPoint string // storage_path 所在文件系统的挂载点
+
+	device      bool   // 配置的设备是否存在
+	deviceSize  int64  // 设备大小
+	deviceFS    string // 设备上已有的文件系统，没有时为空
+	deviceMount string // 设备的挂载点，未挂载时为空
+}
+
+// storageScript 生成在 Registry 节点上执行的检查脚本。storage_path 还不存在时检查最近的已存在的上级目录，
+// 输出 "df <可用字节数> <挂载点>"，配置了设备时再输出 "device <大小> <文件系统|-> <挂载点|->" 或 "nodevice"
+func storageScript(cfg *config.ClusterConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "p=%s; while [ ! -e \"$p\" ]; do p=$(dirname \"$p\"); done\n", shellQuote(cfg.Registry.StoragePath))
+	b.WriteString("echo df $(df -PB1 \"$p\" | awk 'NR==2 {print $4, $6}')\n")
+	if device := cfg.Registry.Storage.Device; device != "" {
+		d := shellQuote(device)
+		fmt.Fprintf(&b, "if [ -b %s ]; then size=$(lsblk -bdno SIZE %s); fs=$(blkid -o value -s TYPE %s); mnt=$(findmnt -no TARGET --source %s | head -1); "+
+			"echo device ${size:-0} ${fs:--} ${mnt:--}; else echo nodevice; fi\n", d, d, d, d)
+	}
+	return b.String()
+}
+
+// parseStorageState 解析检查脚本的输出
+func parseStorageState(output string) (storageState, error) {
+	var state storageState
+	var seenDF bool
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 3 && fields[0] == "df":
+			available, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return state, i18n.Errorf("无法解析 df 的输出 %q", line)
+			}
+			state.available, state.mountPoint, seenDF = available, fields[2], true
+		case len(fields) == 4 && fields[0] == "device":
+			size, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return state, i18n.Errorf("无法解析 lsblk 的输出 %q", line)
+			}
+			state.device, state.deviceSize = true, size
+			if fields[2] != "-" {
+				state.deviceFS = fields[2]
+			}
+			if fields[3] != "-" {
+				state.deviceMount = fields[3]
+			}
+		}
+	}
+	if !seenDF {
+		return state, i18n.Errorf("无法获取存储路径的可用空间: %s", strings.TrimSpace(output))
+	}
+	return state, nil
+}
+
+// requiredStorage 返回 storage_path 需要的可用空间：镜像归档大小加 registryHeadroomPercent 余量，
+// 不小于 registry.storage.min_free
+func requiredStorage(cfg *config.ClusterConfig, archiveSize int64) (int64, error) {
+	minFree, err := cfg.Registry.Storage.GetMinFree()
+	if err != nil {
+		return 0, err
+	}
+	return max(minFree, archiveSize+archiveSize*registryHeadroomPercent/100), nil
+}
+
+// checkStorageState 根据存储状态检查设备和可用空间，返回全部问题
+func checkStorageState(cfg *config.ClusterConfig, state storageState, required int64) []string {
+	storageCfg := cfg.Registry.Storage
+	path := cfg.Registry.StoragePath
+	available := state.available
+	var problems []string
+
+	if storageCfg.Device != "" {
+		switch {
+		case !state.device:
+			return []string{i18n.Sprintf("Registry 节点上不存在块设备 %s\n💡 请使用 lsblk 确认磁盘名称，或使用 /dev/disk/by-id 下不会随启动顺序变化的路径", storageCfg.Device)}
+		case state.deviceFS != "" && state.deviceFS != storageCfg.GetFilesystem():
+			problems = append(problems, i18n.Sprintf("%s 上已有 %s 文件系统，与 registry.storage.filesystem = %s 不一致，ocpack 不会格式化已有数据的磁盘\n"+
+				"💡 确认磁盘上的数据不再需要后执行 wipefs -a %s，或将 filesystem 改为 %s", storageCfg.Device, state.deviceFS, storageCfg.GetFilesystem(), storageCfg.Device, state.deviceFS))
+		case state.deviceMount != "" && state.deviceMount != path:
+			problems = append(problems, i18n.Sprintf("%s 已挂载到 %s，不是 storage_path %s\n💡 请先卸载该设备并从 /etc/fstab 中删除", storageCfg.Device, state.deviceMount, path))
+		}
+		// 设备尚未挂载到 storage_path 时，部署后可用的是整个设备
+		if state.deviceMount != path {
+			available = state.deviceSize
+		}
+		if quota, _ := storageCfg.GetQuota(); quota > 0 && quota < available {
+			available = quota
+		}
+	}
+
+	if available < required {
+		where := i18n.Sprintf("%s (%s 所在的文件系统)", state.mountPoint, path)
+		if storageCfg.Device != "" {
+			where = storageCfg.Device
+		}
+		problems = append(problems, i18n.Sprintf("Registry 存储 %s 可用 %s，预计需要 %s\n"+
+			"💡 扩容该文件系统、在 [registry.storage] 中设置 device 使用单独的磁盘，或将 storage_path 改到更大的文件系统；"+
+			"需要的空间为镜像归档大小加 %d%% 余量，且不小于 registry.storage.min_free",
+			where, workspace.FormatSize(available), workspace.FormatSize(required), registryHeadroomPercent))
+	}
+	return problems
+}
+
+// checkRegistryStorage 部署前检查 Registry 节点的存储：配置的设备存在、已有的文件系统与配置一致且未挂载到其他位置，
+// 以及 storage_path 的可用空间足够存放 save-image 生成的镜像归档。空间不足时在执行 playbook 前失败
+func checkRegistryStorage(out io.Writer, cfg *config.ClusterConfig, clusterDir string) error {
+	var archiveSize int64
+	if !cfg.IsProxyCache() {
+		backend, err := storage.NewTo(out, clusterDir, cfg)
+		if err != nil {
+			return err
+		}
+		if archiveSize, err = transfer.ArchiveSize(backend.Dir()); err != nil {
+			return i18n.Errorf("统计镜像归档大小失败: %w", err)
+		}
+	}
+	required, err := requiredStorage(cfg, archiveSize)
+	if err != nil {
+		return clierr.New(clierr.Config, fmt.Errorf("registry.storage.min_free: %w", err))
+	}
+	if archiveSize > 0 {
+		i18n.Fprintf(out, "➡️  正在检查 Registry 存储空间 (镜像归档 %s，预计需要 %s)...\n", workspace.FormatSize(archiveSize), workspace.FormatSize(required))
+	} else {
+		i18n.Fprintf(out, "➡️  正在检查 Registry 存储空间 (至少需要 %s)...\n", workspace.FormatSize(required))
+	}
+
+	output, err := runOnRegistry(cfg, storageScript(cfg))
+	if err != nil {
+		return clierr.New(clierr.Network, i18n.Errorf("检查 Registry 存储失败: %v\n💡 请确认 [registry] 的 SSH 用户、密码或密钥正确", err))
+	}
+	state, err := parseStorageState(output)
+	if err != nil {
+		return err
+	}
+	if problems := checkStorageState(cfg, state, required); len(problems) > 0 {
+		return clierr.New(clierr.Prereq, i18n.Errorf("Registry 存储检查发现 %d 个问题:\n  %s", len(problems), strings.Join(problems, "\n  ")))
+	}
+	return nil
+}
+
+// shellQuote 将字符串作为单个 shell 参数引用
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,76 @@
+package deploy
+
+import (
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+)
+
+const gib = int64(1) << 30
+
+func TestParseStorageState(t *testing.T) {
+	state, err := parseStorageState("df 53687091200 /var\ndevice 1099511627776 - -\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.available != 50*gib || state.mountPoint != "/var" || !state.device || state.deviceSize != 1024*gib || state.deviceFS != "" || state.deviceMount != "" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if _, err := parseStorageState("df: /var/lib/registry: No such file or directory\n"); err == nil {
+		t.Error("expected an error without df output")
+	}
+}
+
+func TestCheckStorageState(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage config.RegistryStorage
+		state   storageState
+		want    string // problem expected in the result, empty for none
+	}{
+		{"enough space", config.RegistryStorage{}, storageState{available: 600 * gib, mountPoint: "/"}, ""},
+		{"too small", config.RegistryStorage{}, storageState{available: 100 * gib, mountPoint: "/"}, "可用 100.0 GiB，预计需要 600.0 GiB"},
+		{"new device", config.RegistryStorage{Device: "/dev/sdb"}, storageState{available: 10 * gib, mountPoint: "/", device: true, deviceSize: 1024 * gib}, ""},
+		{"quota caps device", config.RegistryStorage{Device: "/dev/sdb", Quota: "300Gi"}, storageState{available: 10 * gib, mountPoint: "/", device: true, deviceSize: 1024 * gib}, "/dev/sdb 可用 300.0 GiB"},
+		{"mounted device", config.RegistryStorage{Device: "/dev/sdb"}, storageState{available: 100 * gib, mountPoint: "/var/lib/registry", device: true, deviceSize: 1024 * gib, deviceFS: "xfs", deviceMount: "/var/lib/registry"}, "可用 100.0 GiB"},
+		{"missing device", config.RegistryStorage{Device: "/dev/sdb"}, storageState{available: 600 * gib, mountPoint: "/"}, "不存在块设备 /dev/sdb"},
+		{"other filesystem", config.RegistryStorage{Device: "/dev/sdb"}, storageState{available: 10 * gib, mountPoint: "/", device: true, deviceSize: 1024 * gib, deviceFS: "ext4"}, "已有 ext4 文件系统"},
+		{"mounted elsewhere", config.RegistryStorage{Device: "/dev/sdb"}, storageState{available: 10 * gib, mountPoint: "/", device: true, deviceSize: 1024 * gib, deviceFS: "xfs", deviceMount: "/data"}, "已挂载到 /data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig("demo")
+			cfg.Registry.Storage = tt.storage
+			required, err := requiredStorage(cfg, 500*gib)
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems := strings.Join(checkStorageState(cfg, tt.state, required), "\n")
+			if tt.want == "" && problems != "" || !strings.Contains(problems, tt.want) {
+				t.Errorf("checkStorageState() = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRegistryStorage(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.Storage.MinFree = "100Gi"
+	var script string
+	orig := runOnRegistry
+	t.Cleanup(func() { runOnRegistry = orig })
+	runOnRegistry = func(_ *config.ClusterConfig, s string) (string, error) {
+		script = s
+		return "df 10737418240 /\n", nil
+	}
+
+	err := checkRegistryStorage(&strings.Builder{}, cfg, t.TempDir())
+	if clierr.CategoryOf(err) != clierr.Prereq || !strings.Contains(err.Error(), "预计需要 100.0 GiB") {
+		t.Errorf("checkRegistryStorage() error = %v, expected the min_free sizing guidance", err)
+	}
+	if !strings.Contains(script, "'/var/lib/registry'") || strings.Contains(script, "lsblk") {
+		t.Errorf("unexpected script:\n%s", script)
+	}
+}
@@ -0,0 +1,102 @@
+// Package dnshosts 为站点 DNS 无法将集群域委派给 Bastion 的环境生成 /etc/hosts 片段和 dnsmasq 配置
+// ([bastion.dns] mode = "hosts")。
+package dnshosts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+)
+
+// --- Constants ---
+const (
+	// DirName 集群目录中生成文件的目录名
+	DirName         = "dns"
+	HostsFilename   = "hosts"
+	DnsmasqFilename = "dnsmasq.conf"
+	// RemoteHostsDir Bastion 上 dnsmasq 读取 hosts 文件的目录，不能放在 /etc/dnsmasq.d 中 (其中的文件均按配置解析)
+	RemoteHostsDir = "/etc/ocpack/dns"
+)
+
+// Dir 返回集群目录中生成文件的目录
+func Dir(clusterDir string) string {
+	return filepath.Join(clusterDir, DirName)
+}
+
+// RemoteHostsPath 返回 Bastion 上集群 hosts 文件的位置
+func RemoteHostsPath(cfg *config.ClusterConfig) string {
+	return RemoteHostsDir + "/" + cfg.ClusterInfo.ClusterID + ".hosts"
+}
+
+// RemoteDnsmasqPath 返回 Bastion 上集群 dnsmasq 配置的位置，多个集群共用 Bastion 时各自一个文件
+func RemoteDnsmasqPath(cfg *config.ClusterConfig) string {
+	return "/etc/dnsmasq.d/ocpack-" + cfg.ClusterInfo.ClusterID + ".conf"
+}
+
+// RenderHosts 生成集群的 /etc/hosts 片段，以 BEGIN/END 标记包围，便于追加到管理员工作站或替换旧版本
+func RenderHosts(cfg *config.ClusterConfig) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# BEGIN ocpack %s\n", cfg.ClusterDomain())
+	for _, entry := range cfg.HostEntries() {
+		fmt.Fprintf(&b, "%s\t%s\n", entry.IP, strings.Join(entry.Names, " "))
+	}
+	fmt.Fprintf(&b, "# END ocpack %s\n", cfg.ClusterDomain())
+	return []byte(b.String())
+}
+
+// RenderDnsmasq 生成 Bastion 上 dnsmasq 的集群配置: 集群域只从 hosts 文件本地应答，*.apps 通配到负载均衡，
+// 其余查询按 [bastion.dns] 转发
+func RenderDnsmasq(cfg *config.ClusterConfig) []byte {
+	dns := cfg.Bastion.DNS
+	domain := cfg.ClusterDomain()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 由 ocpack 生成: 集群 %s 的 DNS (bastion.dns.mode = %s)\n", domain, config.DNSModeHosts)
+	fmt.Fprintf(&b, "listen-address=127.0.0.1,%s\n", cfg.Bastion.IP)
+	b.WriteString("bind-interfaces\n")
+	b.WriteString("domain-needed\n")
+	fmt.Fprintf(&b, "addn-hosts=%s\n", RemoteHostsPath(cfg))
+	fmt.Fprintf(&b, "local=/%s/\n", domain)
+	fmt.Fprintf(&b, "address=/%s/%s\n", cfg.AppsDomain(), cfg.GetLoadBalancer())
+	for _, node := range cfg.Cluster.ControlPlane {
+		fmt.Fprintf(&b, "srv-host=_etcd-server-ssl._tcp.%s,%s.%s,2380,0,10\n", domain, node.Name, domain)
+	}
+
+	if len(dns.Forwarders) > 0 {
+		b.WriteString("no-resolv\n")
+		for _, server := range dns.Forwarders {
+			fmt.Fprintf(&b, "server=%s\n", server)
+		}
+	}
+	for _, zone := range dns.ConditionalForwarders {
+		for _, server := range zone.Servers {
+			fmt.Fprintf(&b, "server=/%s/%s\n", strings.TrimSuffix(zone.Zone, "."), server)
+		}
+	}
+	return []byte(b.String())
+}
+
+// Write 将 /etc/hosts 片段和 dnsmasq 配置写入集群目录，返回写入的文件
+func Write(cfg *config.ClusterConfig, clusterDir string) ([]string, error) {
+	dir := Dir(clusterDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+
+	files := map[string][]byte{
+		HostsFilename:   RenderHosts(cfg),
+		DnsmasqFilename: RenderDnsmasq(cfg),
+	}
+	var written []string
+	for _, name := range []string{HostsFilename, DnsmasqFilename} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
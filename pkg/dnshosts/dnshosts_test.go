@@ -0,0 +1,68 @@
+package dnshosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func hostsConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.ClusterID = "demo"
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Bastion.DNS.Mode = config.DNSModeHosts
+	cfg.Bastion.DNS.Forwarders = []string{"10.0.0.53"}
+	cfg.Bastion.DNS.ConditionalForwarders = []config.DNSForwardZone{{Zone: "idm.example.com.", Servers: []string{"10.0.0.10"}}}
+	cfg.Cluster.ControlPlane = []config.Node{{Name: "master-0", IP: "192.168.1.10"}}
+	return cfg
+}
+
+func TestRenderHosts(t *testing.T) {
+	hosts := string(RenderHosts(hostsConfig()))
+	for _, want := range []string{
+		"# BEGIN ocpack demo.example.com\n",
+		"192.168.1.3\tregistry.demo.example.com\n",
+		"192.168.1.2\tapi.demo.example.com api-int.demo.example.com console-openshift-console.apps.demo.example.com",
+		"192.168.1.10\tmaster-0.demo.example.com\n",
+		"# END ocpack demo.example.com\n",
+	} {
+		if !strings.Contains(hosts, want) {
+			t.Errorf("hosts missing %q:\n%s", want, hosts)
+		}
+	}
+}
+
+func TestRenderDnsmasq(t *testing.T) {
+	conf := string(RenderDnsmasq(hostsConfig()))
+	for _, want := range []string{
+		"addn-hosts=/etc/ocpack/dns/demo.hosts\n",
+		"local=/demo.example.com/\n",
+		"address=/apps.demo.example.com/192.168.1.2\n",
+		"srv-host=_etcd-server-ssl._tcp.demo.example.com,master-0.demo.example.com,2380,0,10\n",
+		"no-resolv\nserver=10.0.0.53\n",
+		"server=/idm.example.com/10.0.0.10\n",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("dnsmasq.conf missing %q:\n%s", want, conf)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	clusterDir := t.TempDir()
+	files, err := Write(hostsConfig(), clusterDir)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Write() = %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(clusterDir, DirName, DnsmasqFilename)); err != nil {
+		t.Error(err)
+	}
+}
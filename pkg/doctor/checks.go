@@ -0,0 +1,216 @@
+package doctor
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+// minFreeDiskKB 集群目录所在分区的最小剩余空间 (20 GiB)，低于该值时提示磁盘空间不足
+const minFreeDiskKB = 20 * 1024 * 1024
+
+// requiredPullSecretHosts 镜像 OpenShift release 和 Red Hat Operator 所需的认证
+var requiredPullSecretHosts = []string{"cloud.openshift.com", "quay.io", "registry.redhat.io"}
+
+// 网络检查使用的函数，测试时可替换
+var (
+	lookupHost = net.LookupHost
+	httpGet    = func(url string, insecure bool) (*http.Response, error) {
+		client := &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure}},
+		}
+		return client.Get(url)
+	}
+)
+
+// CheckState 检查本地状态：pull-secret、merged-auth.json 和磁盘空间；network 为 true 时
+// 还会检查私有仓库的域名解析、连通性和证书
+func CheckState(r runner.CommandRunner, clusterDir string, cfg *config.ClusterConfig, network bool) []Diagnosis {
+	var diagnoses []Diagnosis
+	add := func(d *Diagnosis) {
+		if d != nil {
+			diagnoses = append(diagnoses, *d)
+		}
+	}
+	add(checkPullSecret(clusterDir))
+	add(checkMergedAuth(clusterDir, cfg))
+	add(checkDiskSpace(r, clusterDir, cfg))
+	if network && cfg.Registry.IP != "" {
+		add(checkRegistry(cfg))
+	}
+	return diagnoses
+}
+
+// checkPullSecret 检查 pull-secret.txt 是否存在且包含所需仓库的有效认证
+func checkPullSecret(clusterDir string) *Diagnosis {
+	path := auth.PullSecretPath(clusterDir)
+	remedy := signature("pull-secret-expired").Remedy
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return &Diagnosis{ID: "pull-secret-missing", Title: "未找到 pull-secret", Evidence: []string{err.Error()}, Remedy: remedy}
+	}
+
+	var parsed struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &parsed); err != nil || parsed.Auths == nil {
+		return &Diagnosis{ID: "pull-secret-invalid", Title: "pull-secret 格式无效", Evidence: []string{fmt.Sprintf("%s 不是包含 auths 的 JSON", path)}, Remedy: remedy}
+	}
+
+	var evidence []string
+	for _, host := range requiredPullSecretHosts {
+		entry, ok := parsed.Auths[host]
+		if !ok {
+			evidence = append(evidence, fmt.Sprintf("缺少 %s 的认证", host))
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			evidence = append(evidence, fmt.Sprintf("%s 的认证不是有效的 base64(用户名:密码)", host))
+		}
+	}
+	if len(evidence) == 0 {
+		fmt.Println("✅ pull-secret 包含所需仓库的认证")
+		return nil
+	}
+	return &Diagnosis{ID: "pull-secret-invalid", Title: "pull-secret 缺少认证或认证无效", Evidence: evidence, Remedy: remedy}
+}
+
+// checkMergedAuth 检查 merged-auth.json 中是否包含私有仓库的认证
+func checkMergedAuth(clusterDir string, cfg *config.ClusterConfig) *Diagnosis {
+	path := auth.MergedAuthPath(clusterDir)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// 尚未执行 save-image 或 load-image
+		return nil
+	}
+	var parsed struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &parsed)
+	}
+	registryHost := cfg.GetRegistryHost()
+	if err == nil && parsed.Auths[registryHost] != nil {
+		fmt.Printf("✅ %s 包含 %s 的认证\n", auth.MergedAuthFilename, registryHost)
+		return nil
+	}
+
+	evidence := fmt.Sprintf("%s 中缺少 %s 的认证", path, registryHost)
+	if err != nil {
+		evidence = fmt.Sprintf("读取 %s 失败: %v", path, err)
+	}
+	return &Diagnosis{
+		ID:       "merged-auth-invalid",
+		Title:    "合并的认证文件无效",
+		Evidence: []string{evidence},
+		Remedy:   signature("registry-unauthorized").Remedy,
+	}
+}
+
+// checkDiskSpace 使用 df 检查集群目录所在分区的剩余空间，df 不可用时跳过。
+// s3:// 存储的镜像归档也完整暂存在集群目录中，空间不足时在修复建议中说明
+func checkDiskSpace(r runner.CommandRunner, clusterDir string, cfg *config.ClusterConfig) *Diagnosis {
+	if _, err := r.LookPath("df"); err != nil {
+		return nil
+	}
+	result, err := r.Run(runner.Command{Name: "df", Args: []string{"-Pk", clusterDir}, Timeout: runner.DefaultTimeout})
+	if err != nil {
+		return nil
+	}
+	// df -P 的输出第二行为: 文件系统 总容量 已用 可用 使用率 挂载点
+	lines := strings.Split(strings.TrimSpace(string(result.Stdout)), "\n")
+	if len(lines) < 2 {
+		return nil
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return nil
+	}
+	availableKB, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	available := fmt.Sprintf("%.1f GiB", float64(availableKB)/1024/1024)
+	if availableKB >= minFreeDiskKB {
+		fmt.Printf("✅ 集群目录所在分区剩余 %s\n", available)
+		return nil
+	}
+	remedy := signature("disk-full").Remedy
+	if cfg.SaveImage.Storage.Scheme() == config.StorageSchemeS3 {
+		staging := fmt.Sprintf("s3:// 存储的镜像归档先完整暂存在 %s 中再上传或导入，该分区需要能容纳全部镜像归档", cfg.GetImagesDir(clusterDir))
+		remedy = append([]string{staging}, remedy...)
+	}
+	return &Diagnosis{
+		ID:       "disk-full",
+		Title:    "磁盘剩余空间不足",
+		Evidence: []string{fmt.Sprintf("%s 所在分区 (%s) 仅剩 %s", clusterDir, fields[len(fields)-1], available)},
+		Remedy:   remedy,
+	}
+}
+
+// checkRegistry 检查私有仓库的域名解析、服务状态和证书
+func checkRegistry(cfg *config.ClusterConfig) *Diagnosis {
+	registryHost := cfg.GetRegistryHost()
+	hostname, _, _ := net.SplitHostPort(registryHost)
+
+	if _, err := lookupHost(hostname); err != nil {
+		return &Diagnosis{ID: "dns-failure", Title: "私有仓库域名无法解析", Evidence: []string{err.Error()}, Remedy: signature("dns-failure").Remedy}
+	}
+	fmt.Printf("✅ %s 可以解析\n", hostname)
+
+	healthURL := fmt.Sprintf("https://%s/health/instance", net.JoinHostPort(cfg.Registry.IP, "8443"))
+	resp, err := httpGet(healthURL, true)
+	if err != nil {
+		return &Diagnosis{ID: "registry-unreachable", Title: "私有仓库无法访问", Evidence: []string{err.Error()}, Remedy: signature("network-unreachable").Remedy}
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &Diagnosis{
+			ID:       "registry-unhealthy",
+			Title:    "私有仓库状态异常",
+			Evidence: []string{fmt.Sprintf("%s 返回 %s", healthURL, resp.Status)},
+			Remedy:   signature("network-unreachable").Remedy,
+		}
+	}
+	fmt.Println("✅ 私有仓库运行正常")
+
+	// 使用系统信任的 CA 访问仓库 API，检查证书是否被信任
+	resp, err = httpGet(fmt.Sprintf("https://%s/v2/", registryHost), false)
+	if err != nil {
+		var verifyErr *tls.CertificateVerificationError
+		if errors.As(err, &verifyErr) {
+			return &Diagnosis{ID: "x509-unknown-authority", Title: "私有仓库证书未被信任", Evidence: []string{err.Error()}, Remedy: signature("x509-unknown-authority").Remedy}
+		}
+		return &Diagnosis{ID: "registry-unreachable", Title: "私有仓库无法访问", Evidence: []string{err.Error()}, Remedy: signature("network-unreachable").Remedy}
+	}
+	resp.Body.Close()
+	fmt.Println("✅ 私有仓库证书已被系统信任")
+	return nil
+}
+
+// signature 根据 ID 返回已知故障特征
+func signature(id string) Signature {
+	for _, sig := range Signatures {
+		if sig.ID == id {
+			return sig
+		}
+	}
+	return Signature{ID: id}
+}
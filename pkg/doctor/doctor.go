@@ -0,0 +1,160 @@
+// Package doctor 诊断镜像和认证相关的常见故障：扫描 oc-mirror 日志中的已知错误特征，
+// 检查 pull-secret、认证文件、磁盘空间、域名解析和私有仓库的状态，并给出针对性的修复建议。
+package doctor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+// maxEvidence 每个问题最多保留的日志证据行数
+const maxEvidence = 3
+
+// Diagnosis 一个诊断出的问题
+type Diagnosis struct {
+	ID       string
+	Title    string
+	Count    int      // 匹配的日志行数，本地状态检查为 0
+	Evidence []string // 日志中的匹配行 (文件:行号: 内容) 或检查结果
+	Remedy   []string
+}
+
+// Options 诊断选项
+type Options struct {
+	LogFiles  []string // 额外扫描的日志文件，如保存下来的命令输出
+	NoNetwork bool     // 跳过域名解析和私有仓库连通性检查
+}
+
+// LogsDir 返回 oc-mirror 的日志目录
+func LogsDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "working-dir", "logs")
+}
+
+// Run 诊断集群目录，返回发现的问题 (按日志命中次数排序，本地状态问题在前)
+func Run(r runner.CommandRunner, clusterDir string, cfg *config.ClusterConfig, opts Options) ([]Diagnosis, error) {
+	files, err := logFiles(LogsDir(clusterDir))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, opts.LogFiles...)
+	fmt.Printf("🔍 扫描 %d 个日志文件\n", len(files))
+
+	diagnoses := CheckState(r, clusterDir, cfg, !opts.NoNetwork)
+	logDiagnoses, err := ScanLogs(files)
+	if err != nil {
+		return nil, err
+	}
+	return append(diagnoses, logDiagnoses...), nil
+}
+
+// ScanLogs 扫描日志文件，按已知故障特征归类匹配的日志行
+func ScanLogs(files []string) ([]Diagnosis, error) {
+	found := make(map[string]*Diagnosis)
+	for _, file := range files {
+		if err := scanFile(file, found); err != nil {
+			return nil, err
+		}
+	}
+
+	diagnoses := make([]Diagnosis, 0, len(found))
+	for _, sig := range Signatures {
+		if d, ok := found[sig.ID]; ok {
+			diagnoses = append(diagnoses, *d)
+		}
+	}
+	sort.SliceStable(diagnoses, func(i, j int) bool {
+		return diagnoses[i].Count > diagnoses[j].Count
+	})
+	return diagnoses, nil
+}
+
+// scanFile 扫描单个日志文件
+func scanFile(path string, found map[string]*Diagnosis) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		sig := match(line)
+		if sig == nil {
+			continue
+		}
+		d, ok := found[sig.ID]
+		if !ok {
+			d = &Diagnosis{ID: sig.ID, Title: sig.Title, Remedy: sig.Remedy}
+			found[sig.ID] = d
+		}
+		d.Count++
+		if len(d.Evidence) < maxEvidence {
+			d.Evidence = append(d.Evidence, fmt.Sprintf("%s:%d: %s", path, lineNo, truncate(line, 200)))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取日志文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// logFiles 返回日志目录中的 .log 和 .txt 文件，目录不存在时返回空列表
+func logFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取日志目录失败: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".log" || ext == ".txt") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// Print 打印诊断结果
+func Print(diagnoses []Diagnosis) {
+	if len(diagnoses) == 0 {
+		fmt.Println("✅ 未发现已知问题")
+		return
+	}
+	for i, d := range diagnoses {
+		title := d.Title
+		if d.Count > 0 {
+			title = fmt.Sprintf("%s (日志中出现 %d 次)", title, d.Count)
+		}
+		fmt.Printf("\n❌ [%d] %s\n", i+1, title)
+		for _, evidence := range d.Evidence {
+			fmt.Printf("   %s\n", evidence)
+		}
+		fmt.Println("   修复建议:")
+		for j, remedy := range d.Remedy {
+			fmt.Printf("     %d. %s\n", j+1, remedy)
+		}
+	}
+}
+
+// truncate 截断过长的日志行
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,184 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func TestScanLogs(t *testing.T) {
+	logsDir := filepath.Join(t.TempDir(), "logs")
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Join([]string{
+		"2024/01/02 10:00:00  [INFO]   : copying release image",
+		`error: reading manifest 4.14.1-x86_64 in registry.redhat.io/openshift4/ose-cli: unauthorized: Please login to the Red Hat Registry using your Customer Portal credentials.`,
+		`error: initializing source docker://registry.redhat.io/rhel9/postgresql-15: reading manifest latest: unauthorized: authentication required`,
+		`error: pinging container registry registry.demo.example.com:8443: Get "https://registry.demo.example.com:8443/v2/": tls: failed to verify certificate: x509: certificate signed by unknown authority`,
+		`error: writing blob: write /data/images/working-dir/blob: no space left on device`,
+		`error: dial tcp: lookup registry.demo.example.com on 10.0.0.53:53: no such host`,
+		`error: writing blob: write /data/images/working-dir/blob2: no space left on device`,
+	}, "\n")
+	logFile := filepath.Join(logsDir, "mirroring_errors_20240102_100000.txt")
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := logFiles(logsDir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("logFiles() = %v, %v", files, err)
+	}
+	diagnoses, err := ScanLogs(files)
+	if err != nil {
+		t.Fatalf("ScanLogs() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, d := range diagnoses {
+		counts[d.ID] = d.Count
+	}
+	expected := map[string]int{
+		"pull-secret-expired":    1,
+		"redhat-unauthorized":    1,
+		"x509-unknown-authority": 1,
+		"disk-full":              2,
+		"dns-failure":            1,
+	}
+	for id, count := range expected {
+		if counts[id] != count {
+			t.Errorf("%s count = %d, expected %d (diagnoses: %v)", id, counts[id], count, counts)
+		}
+	}
+	if len(diagnoses) != len(expected) {
+		t.Errorf("got %d diagnoses, expected %d: %v", len(diagnoses), len(expected), counts)
+	}
+	if diagnoses[0].ID != "disk-full" {
+		t.Errorf("diagnoses[0] = %s, expected most frequent problem first", diagnoses[0].ID)
+	}
+	if !strings.HasPrefix(diagnoses[0].Evidence[0], logFile+":5: ") {
+		t.Errorf("evidence = %q, expected file and line number", diagnoses[0].Evidence[0])
+	}
+}
+
+func TestScanLogsLimitsEvidence(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "oc-mirror.log")
+	line := "error: copying image quay.io/foo/bar: toomanyrequests: rate limit exceeded\n"
+	if err := os.WriteFile(logFile, []byte(strings.Repeat(line, 10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diagnoses, err := ScanLogs([]string{logFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnoses) != 1 || diagnoses[0].ID != "rate-limited" || diagnoses[0].Count != 10 || len(diagnoses[0].Evidence) != maxEvidence {
+		t.Errorf("ScanLogs() = %+v", diagnoses)
+	}
+}
+
+func TestCheckPullSecret(t *testing.T) {
+	validAuth := "dXNlcjpwYXNz" // user:pass
+	tests := []struct {
+		name    string
+		content string
+		ok      bool
+	}{
+		{"missing", "", false},
+		{"invalid json", "not json", false},
+		{"missing host", `{"auths":{"quay.io":{"auth":"` + validAuth + `"}}}`, false},
+		{"invalid auth", `{"auths":{"cloud.openshift.com":{"auth":"` + validAuth + `"},"quay.io":{"auth":"!!"},"registry.redhat.io":{"auth":"` + validAuth + `"}}}`, false},
+		{"valid", `{"auths":{"cloud.openshift.com":{"auth":"` + validAuth + `"},"quay.io":{"auth":"` + validAuth + `"},"registry.redhat.io":{"auth":"` + validAuth + `"}}}`, true},
+	}
+
+	for _, tt := range tests {
+		clusterDir := t.TempDir()
+		if tt.content != "" {
+			if err := os.WriteFile(auth.PullSecretPath(clusterDir), []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if d := checkPullSecret(clusterDir); (d == nil) != tt.ok {
+			t.Errorf("%s: checkPullSecret() = %+v, expected ok = %t", tt.name, d, tt.ok)
+		}
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	for _, tt := range []struct {
+		availableKB int64
+		ok          bool
+	}{
+		{100 * 1024 * 1024, true},
+		{5 * 1024 * 1024, false},
+	} {
+		fake := &runner.Fake{
+			Paths: map[string]string{"df": "/usr/bin/df"},
+			Handler: func(cmd runner.Command) (*runner.Result, error) {
+				out := fmt.Sprintf("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 209715200 0 %d 50%% /data\n", tt.availableKB)
+				return &runner.Result{Stdout: []byte(out)}, nil
+			},
+		}
+		d := checkDiskSpace(fake, "/data/demo", cfg)
+		if (d == nil) != tt.ok {
+			t.Errorf("available %d KB: checkDiskSpace() = %+v, expected ok = %t", tt.availableKB, d, tt.ok)
+		}
+		if d != nil && !strings.Contains(d.Evidence[0], "/data)") {
+			t.Errorf("evidence = %q, expected mount point", d.Evidence[0])
+		}
+	}
+
+	// s3:// 存储的归档暂存在集群目录中，修复建议需要说明
+	cfg.SaveImage.Storage.URL = "s3://ocp-mirror/demo"
+	fake := &runner.Fake{
+		Paths: map[string]string{"df": "/usr/bin/df"},
+		Handler: func(cmd runner.Command) (*runner.Result, error) {
+			return &runner.Result{Stdout: []byte("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 209715200 0 1024 99% /data\n")}, nil
+		},
+	}
+	d := checkDiskSpace(fake, "/data/demo", cfg)
+	if d == nil || !strings.Contains(d.Remedy[0], "/data/demo/images") {
+		t.Errorf("checkDiskSpace() with s3:// storage = %+v, expected the staging directory in the remedy", d)
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	defer func(lookup func(string) ([]string, error), get func(string, bool) (*http.Response, error)) {
+		lookupHost, httpGet = lookup, get
+	}(lookupHost, httpGet)
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.IP = "192.168.1.11"
+
+	lookupHost = func(string) ([]string, error) { return nil, errors.New("lookup registry.demo.example.com: no such host") }
+	if d := checkRegistry(cfg); d == nil || d.ID != "dns-failure" {
+		t.Errorf("checkRegistry() = %+v, expected dns-failure", d)
+	}
+
+	lookupHost = func(string) ([]string, error) { return []string{"192.168.1.11"}, nil }
+	httpGet = func(string, bool) (*http.Response, error) { return nil, errors.New("connection refused") }
+	if d := checkRegistry(cfg); d == nil || d.ID != "registry-unreachable" {
+		t.Errorf("checkRegistry() = %+v, expected registry-unreachable", d)
+	}
+
+	var urls []string
+	httpGet = func(url string, insecure bool) (*http.Response, error) {
+		urls = append(urls, url)
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: http.NoBody}, nil
+	}
+	if d := checkRegistry(cfg); d != nil {
+		t.Errorf("checkRegistry() = %+v, expected healthy registry", d)
+	}
+	expected := []string{"https://192.168.1.11:8443/health/instance", "https://registry.demo.example.com:8443/v2/"}
+	if strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Errorf("requested %v, expected %v", urls, expected)
+	}
+}
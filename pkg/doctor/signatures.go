@@ -0,0 +1,135 @@
+package doctor
+
+import "regexp"
+
+// Signature 一种已知的故障特征，日志行匹配 Patterns 中任意一个正则时判定为该故障
+type Signature struct {
+	ID       string
+	Title    string
+	Patterns []*regexp.Regexp
+	Remedy   []string
+}
+
+// Signatures 已知的镜像和认证故障特征。一行日志只归入第一个匹配的特征，因此更具体的特征排在前面
+var Signatures = []Signature{
+	{
+		ID:    "pull-secret-expired",
+		Title: "pull-secret 已过期或已失效",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)token (has )?expired|expired token|Please login to the Red Hat Registry`),
+			regexp.MustCompile(`(?i)invalid username/password`),
+		},
+		Remedy: []string{
+			"从 https://console.redhat.com/openshift/install/pull-secret 重新下载 pull-secret，覆盖 <集群目录>/pull-secret.txt",
+			"重新执行 save-image，ocpack 会根据新的 pull-secret 重新生成 registry/merged-auth.json",
+		},
+	},
+	{
+		ID:    "redhat-unauthorized",
+		Title: "Red Hat 镜像仓库认证失败 (401)",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)(registry\.redhat\.io|registry\.connect\.redhat\.com|quay\.io|cloud\.openshift\.com).*(\b401\b|unauthorized|authentication required)`),
+			regexp.MustCompile(`(?i)(\b401\b|unauthorized|authentication required).*(registry\.redhat\.io|registry\.connect\.redhat\.com|quay\.io)`),
+		},
+		Remedy: []string{
+			"确认 pull-secret.txt 中包含 registry.redhat.io、quay.io 和 registry.connect.redhat.com 的认证信息",
+			"使用 podman login --authfile <集群目录>/pull-secret.txt registry.redhat.io 验证 pull-secret 是否有效",
+			"certified/marketplace 目录需要账号订阅对应的产品，否则会返回 401",
+		},
+	},
+	{
+		ID:    "registry-unauthorized",
+		Title: "私有镜像仓库认证失败 (401)",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i):8443.*(\b401\b|unauthorized|authentication required)`),
+			regexp.MustCompile(`(?i)(\b401\b|unauthorized|authentication required).*:8443`),
+		},
+		Remedy: []string{
+			"确认 config.toml 中的 [registry] registry_user 和 registry_password 与部署 Registry 时一致",
+			"删除 registry/merged-auth.json 后重新执行 load-image，重新生成认证信息和 ~/.docker/config.json",
+		},
+	},
+	{
+		ID:    "x509-unknown-authority",
+		Title: "证书校验失败 (x509)",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`x509: certificate signed by unknown authority`),
+			regexp.MustCompile(`x509: certificate (is valid for|is not valid for|has expired|is not yet valid)`),
+			regexp.MustCompile(`(?i)tls: failed to verify certificate`),
+		},
+		Remedy: []string{
+			"私有仓库使用自签名证书时，将 Registry 的 CA 证书复制到 /etc/pki/ca-trust/source/anchors/ 并执行 update-ca-trust",
+			"证书中的主机名需要与 registry.<cluster_id>.<domain> 一致，主机名变化后需要重新部署 Registry",
+			"代理服务器替换了证书时，需要将代理的 CA 证书加入系统信任，并加入 [infra] trust_bundle_paths 使集群和升级图查询也信任该证书",
+		},
+	},
+	{
+		ID:    "disk-full",
+		Title: "磁盘空间不足",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)no space left on device|disk quota exceeded`),
+		},
+		Remedy: []string{
+			"使用 df -h 检查集群目录和 $HOME/.oc-mirror 所在的分区",
+			"执行 ocpack clean-cache <集群名称> 清理 oc-mirror 缓存",
+			"完整镜像集通常需要数百 GB，请为集群目录预留足够的空间",
+		},
+	},
+	{
+		ID:    "dns-failure",
+		Title: "域名解析失败",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)no such host|server misbehaving|temporary failure in name resolution|name or service not known`),
+		},
+		Remedy: []string{
+			"确认 /etc/resolv.conf 中的 DNS 服务器可用 (Bastion 或 [infra] dns_servers)",
+			"确认 DNS 中存在 registry.<cluster_id>.<domain> 记录，或在 /etc/hosts 中添加",
+			"联网执行 save-image 时，确认可以解析 registry.redhat.io 和 quay.io",
+		},
+	},
+	{
+		ID:    "rate-limited",
+		Title: "镜像仓库限流 (429)",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)toomanyrequests|too many requests|\b429\b`),
+		},
+		Remedy: []string{
+			"等待一段时间后重新执行 save-image，已下载的镜像会被跳过",
+		},
+	},
+	{
+		ID:    "network-unreachable",
+		Title: "网络连接失败",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)connection refused|connection reset by peer|no route to host|network is unreachable|TLS handshake timeout|i/o timeout`),
+		},
+		Remedy: []string{
+			"确认目标仓库地址和端口可以访问，私有仓库需要在 Registry 节点上开放 8443 端口",
+			"需要通过代理访问外网时，设置 HTTPS_PROXY 并将私有仓库加入 NO_PROXY",
+			"私有仓库不可用时，在 Registry 节点上执行 systemctl status quay-app 检查服务状态",
+		},
+	},
+	{
+		ID:    "manifest-unknown",
+		Title: "镜像或标签不存在",
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)manifest unknown|manifest .*not found|name unknown`),
+		},
+		Remedy: []string{
+			"确认 openshift_version、ops 中的 Operator 名称和 additional_images 中的标签存在",
+			"Operator 目录的标签根据 openshift_version 生成，新版本发布初期目录可能尚未提供",
+		},
+	},
+}
+
+// match 返回日志行匹配的第一个特征
+func match(line string) *Signature {
+	for i := range Signatures {
+		for _, pattern := range Signatures[i].Patterns {
+			if pattern.MatchString(line) {
+				return &Signatures[i]
+			}
+		}
+	}
+	return nil
+}
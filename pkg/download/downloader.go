@@ -1,7 +1,6 @@
 package download
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
 	"ocpack/pkg/utils"
 )
 
@@ -24,6 +24,8 @@ const (
 	quayReleaseURL       = "https://mirror.openshift.com/pub/cgw/mirror-registry/latest/mirror-registry-amd64.tar.gz"
 	progressBarWidth     = 30
 	progressUpdateFreq   = 100 * time.Millisecond
+	lockFileName         = ".ocpack.lock"
+	binDirName           = "bin"
 )
 
 // --- Struct Definitions ---
@@ -32,11 +34,20 @@ const (
 type Downloader struct {
 	config      *config.ClusterConfig
 	downloadDir string
+
+	// Out receives all progress output, defaults to os.Stdout.
+	Out io.Writer
+	// Quiet disables the in-place progress bar, used when several downloads share one terminal.
+	Quiet bool
+	// HTTP is the client used for downloads, defaults to http.DefaultClient.
+	// Set it to a client trusting infra.ca_bundle behind a TLS-intercepting proxy.
+	HTTP *http.Client
 }
 
 // ProgressReader is an io.Reader that displays download progress.
 type ProgressReader struct {
 	io.Reader
+	out        io.Writer
 	total      int64
 	downloaded int64
 	fileName   string
@@ -50,7 +61,8 @@ type DownloadTask struct {
 	URL        string
 	FileName   string
 	Required   bool
-	VersionDep bool // Does this depend on a specific OCP version?
+	VersionDep bool     // Does this depend on a specific OCP version?
+	Extract    []string // Binaries extracted from the tar.gz into bin/.
 }
 
 // --- Main Logic ---
@@ -60,42 +72,58 @@ func NewDownloader(cfg *config.ClusterConfig, downloadDir string) *Downloader {
 	return &Downloader{
 		config:      cfg,
 		downloadDir: downloadDir,
+		Out:         os.Stdout,
+		HTTP:        http.DefaultClient,
 	}
 }
 
 // DownloadAll orchestrates the download of all necessary files.
+// The download directory is locked for the whole run so that clusters sharing
+// it (download.shared) can be downloaded concurrently from several processes.
 func (d *Downloader) DownloadAll() error {
-	fmt.Println("▶️  开始下载所需工具和文件...")
+	i18n.Fprintln(d.Out, "▶️  开始下载所需工具和文件...")
 
 	if err := os.MkdirAll(d.downloadDir, 0755); err != nil {
-		return fmt.Errorf("创建下载目录失败: %w", err)
+		return i18n.Errorf("创建下载目录失败: %w", err)
 	}
 
+	unlock, err := utils.LockFile(filepath.Join(d.downloadDir, lockFileName), func() {
+		i18n.Fprintf(d.Out, "⏳ 下载目录 %s 正被其他 ocpack 进程使用，等待其完成...\n", d.downloadDir)
+	})
+	if err != nil {
+		return i18n.Errorf("锁定下载目录失败: %w", err)
+	}
+	defer unlock()
+
 	version := d.config.ClusterInfo.OpenShiftVersion
 	tasks := d.buildDownloadTasks(version)
 
 	for i, task := range tasks {
-		fmt.Printf("\n➡️  任务 %d/%d: %s\n", i+1, len(tasks), task.Name)
+		i18n.Fprintf(d.Out, "\n➡️  任务 %d/%d: %s\n", i+1, len(tasks), task.Name)
 
 		if task.VersionDep && !utils.SupportsOcMirror(version) {
-			fmt.Printf("⚠️  跳过 %s: OpenShift 版本 %s 不支持 (需要 4.14.0 及以上版本)\n", task.Name, version)
+			i18n.Fprintf(d.Out, "ℹ️  跳过 %s: OpenShift %s 的 release 通过 oc adm release mirror 镜像 (oc-mirror 需要 4.14.0 及以上版本)\n", task.Name, version)
 		} else {
 			filePath := filepath.Join(d.downloadDir, task.FileName)
-			if err := d.downloadFile(task.URL, filePath); err != nil {
+			fetch := d.downloadFile
+			if d.streams(task) {
+				fetch = func(string, string) error { return d.streamExtract(task) }
+			}
+			if err := fetch(task.URL, filePath); err != nil {
 				if task.Required {
-					return fmt.Errorf("下载必需文件 '%s' 失败: %w", task.Name, err)
+					return i18n.Errorf("下载必需文件 '%s' 失败: %w", task.Name, err)
 				}
-				fmt.Printf("⚠️  下载可选文件 '%s' 失败，已跳过: %v\n", task.Name, err)
+				i18n.Fprintf(d.Out, "⚠️  下载可选文件 '%s' 失败，已跳过: %v\n", task.Name, err)
 			}
 		}
 	}
 
-	fmt.Println("\n➡️  正在提取工具...")
+	i18n.Fprintln(d.Out, "\n➡️  正在提取工具...")
 	if err := d.extractTools(version); err != nil {
-		return fmt.Errorf("提取工具失败: %w", err)
+		return i18n.Errorf("提取工具失败: %w", err)
 	}
 
-	fmt.Println("\n🎉 所有下载和提取操作完成！")
+	i18n.Fprintln(d.Out, "\n🎉 所有下载和提取操作完成！")
 	return nil
 }
 
@@ -108,32 +136,35 @@ func (d *Downloader) buildDownloadTasks(version string) []DownloadTask {
 
 	return []DownloadTask{
 		{
-			Name:     "OpenShift 客户端 (oc, kubectl)",
+			Name:     i18n.T("OpenShift 客户端 (oc, kubectl)"),
 			URL:      fmt.Sprintf(ocpClientsURLPattern, version, fmt.Sprintf("openshift-client-linux-%s.tar.gz", version)),
 			FileName: fmt.Sprintf("openshift-client-linux-%s.tar.gz", version),
 			Required: true,
+			Extract:  []string{"oc", "kubectl"},
 		},
 		{
-			Name:     "OpenShift 安装程序 (openshift-install)",
+			Name:     i18n.T("OpenShift 安装程序 (openshift-install)"),
 			URL:      fmt.Sprintf(ocpClientsURLPattern, version, fmt.Sprintf("openshift-install-linux-%s.tar.gz", version)),
 			FileName: fmt.Sprintf("openshift-install-linux-%s.tar.gz", version),
 			Required: true,
+			Extract:  []string{"openshift-install"},
 		},
 		{
-			Name:       "oc-mirror 工具",
+			Name:       i18n.T("oc-mirror 工具"),
 			URL:        fmt.Sprintf(ocMirrorURLPattern, arch, version),
 			FileName:   fmt.Sprintf("oc-mirror-%s.tar.gz", version),
 			Required:   false, // Not required if version is too old
 			VersionDep: true,
+			Extract:    []string{"oc-mirror"},
 		},
 		{
-			Name:     "Butane 工具",
+			Name:     i18n.T("Butane 工具"),
 			URL:      fmt.Sprintf(butaneURLPattern, butaneArch),
 			FileName: fmt.Sprintf("butane-%s", butaneArch),
 			Required: true,
 		},
 		{
-			Name:     "Quay 镜像仓库安装包",
+			Name:     i18n.T("Quay 镜像仓库安装包"),
 			URL:      quayReleaseURL,
 			FileName: "mirror-registry-amd64.tar.gz",
 			Required: true,
@@ -146,7 +177,7 @@ func (d *Downloader) buildDownloadTasks(version string) []DownloadTask {
 // downloadFile downloads a single file to a destination path with progress.
 func (d *Downloader) downloadFile(url, destPath string) error {
 	if _, err := os.Stat(destPath); err == nil {
-		fmt.Printf("✅ 文件已存在，跳过下载: %s\n", filepath.Base(destPath))
+		i18n.Fprintf(d.Out, "✅ 文件已存在，跳过下载: %s\n", filepath.Base(destPath))
 		return nil
 	}
 
@@ -154,108 +185,223 @@ func (d *Downloader) downloadFile(url, destPath string) error {
 	tmpPath := destPath + ".tmp"
 	defer os.Remove(tmpPath)
 
-	headResp, err := http.Head(url)
+	body, contentLength, err := d.get(url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return i18n.Errorf("创建临时文件失败: %w", err)
+	}
+	defer out.Close()
+
+	start := time.Now()
+	n, err := io.Copy(out, d.progressReader(body, contentLength, fileName))
+	d.finishProgress(fileName, n, start)
+	if err != nil {
+		return i18n.Errorf("保存文件时出错: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return i18n.Errorf("重命名临时文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// get sends the GET request for url and returns the body and its size, taken from a HEAD
+// request first since some mirrors omit Content-Length on GET.
+func (d *Downloader) get(url string) (io.ReadCloser, int64, error) {
 	var contentLength int64
-	if err == nil {
-		defer headResp.Body.Close()
+	if headResp, err := d.HTTP.Head(url); err == nil {
+		headResp.Body.Close()
 		contentLength = headResp.ContentLength
 	}
 
-	resp, err := http.Get(url)
+	resp, err := d.HTTP.Get(url)
 	if err != nil {
-		return fmt.Errorf("HTTP GET 请求失败: %w", err)
+		return nil, 0, i18n.Errorf("HTTP GET 请求失败: %w", err)
 	}
-	defer resp.Body.Close()
-
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载失败，HTTP 状态码: %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, 0, i18n.Errorf("下载失败，HTTP 状态码: %d", resp.StatusCode)
 	}
 	if contentLength <= 0 {
 		contentLength = resp.ContentLength
 	}
+	return resp.Body, contentLength, nil
+}
 
-	out, err := os.Create(tmpPath)
-	if err != nil {
-		return fmt.Errorf("创建临时文件失败: %w", err)
+// progressReader wraps r with the progress bar unless the downloader is quiet.
+func (d *Downloader) progressReader(r io.Reader, total int64, fileName string) io.Reader {
+	if d.Quiet {
+		return r
 	}
-	defer out.Close()
-
-	progressReader := &ProgressReader{
-		Reader:    resp.Body,
-		total:     contentLength,
+	return &ProgressReader{
+		Reader:    r,
+		out:       d.Out,
+		total:     total,
 		fileName:  fileName,
 		startTime: time.Now(),
 	}
+}
 
-	if _, err = io.Copy(out, progressReader); err != nil {
-		fmt.Println()
-		return fmt.Errorf("保存文件时出错: %w", err)
+// finishProgress ends the progress bar line, or prints a one-line summary when quiet.
+func (d *Downloader) finishProgress(fileName string, n int64, start time.Time) {
+	if !d.Quiet {
+		fmt.Fprintln(d.Out)
+		return
 	}
-	fmt.Println()
+	i18n.Fprintf(d.Out, "⬇️  %s 下载完成 (%s, %s)\n", fileName, formatBytes(n), formatDuration(time.Since(start)))
+}
 
-	if err := os.Rename(tmpPath, destPath); err != nil {
-		return fmt.Errorf("重命名临时文件失败: %w", err)
+// streams reports whether the task is extracted while downloading (download.stream).
+// An archive already in the download directory is extracted from disk instead.
+func (d *Downloader) streams(task DownloadTask) bool {
+	return d.config.Download.Stream && len(task.Extract) > 0 && !utils.FileExists(filepath.Join(d.downloadDir, task.FileName))
+}
+
+// streamExtract 将 tar.gz 的 HTTP 响应直接经 gzip/tar 解压到 bin/，归档不落盘；
+// 启用 download.keep_archives 时同时将响应保存为归档
+func (d *Downloader) streamExtract(task DownloadTask) error {
+	binDir := filepath.Join(d.downloadDir, binDirName)
+	if d.extracted(binDir, task) {
+		i18n.Fprintf(d.Out, "✅ 工具已从 %s 提取，跳过下载\n", task.FileName)
+		return nil
+	}
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return i18n.Errorf("创建 bin 目录失败: %w", err)
+	}
+	if err := d.cleanupBinDir(binDir, task.Extract); err != nil {
+		i18n.Fprintf(d.Out, "⚠️  清理 bin 目录时发出警告: %v\n", err)
 	}
 
-	return nil
+	body, contentLength, err := d.get(task.URL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	counter := &countingReader{Reader: d.progressReader(body, contentLength, task.FileName)}
+	var reader io.Reader = counter
+	archivePath := filepath.Join(d.downloadDir, task.FileName)
+	tmpPath := archivePath + ".tmp"
+	if d.config.Download.KeepArchives {
+		defer os.Remove(tmpPath)
+		out, err := os.Create(tmpPath)
+		if err != nil {
+			return i18n.Errorf("创建临时文件失败: %w", err)
+		}
+		defer out.Close()
+		reader = io.TeeReader(counter, out)
+	}
+
+	start := time.Now()
+	err = utils.ExtractTarGzReader(reader, binDir, task.Extract)
+	d.finishProgress(task.FileName, counter.n, start)
+	if err != nil {
+		return i18n.Errorf("边下载边提取失败: %w", err)
+	}
+	if d.config.Download.KeepArchives {
+		if err := os.Rename(tmpPath, archivePath); err != nil {
+			return i18n.Errorf("重命名临时文件失败: %w", err)
+		}
+	}
+	return d.markExtracted(binDir, task)
 }
 
-// extractTools extracts binaries from downloaded tarballs.
-func (d *Downloader) extractTools(version string) error {
-	binDir := filepath.Join(d.downloadDir, "bin")
-	if err := os.MkdirAll(binDir, 0755); err != nil {
-		return fmt.Errorf("创建 bin 目录失败: %w", err)
+// extractedMarker returns the file recording which archive the task's binaries came from.
+func extractedMarker(binDir string, task DownloadTask) string {
+	return filepath.Join(binDir, "."+task.Extract[0]+".source")
+}
+
+// extracted reports whether bin/ already holds the task's binaries from the same archive,
+// so a streamed download is not repeated and a kept archive is not extracted twice.
+func (d *Downloader) extracted(binDir string, task DownloadTask) bool {
+	source, err := os.ReadFile(extractedMarker(binDir, task))
+	if err != nil || string(source) != task.FileName {
+		return false
 	}
+	for _, name := range task.Extract {
+		if !utils.FileExists(filepath.Join(binDir, name)) {
+			return false
+		}
+	}
+	return true
+}
 
-	if err := d.cleanupBinDir(binDir); err != nil {
-		fmt.Printf("⚠️  清理 bin 目录时发出警告: %v\n", err)
+func (d *Downloader) markExtracted(binDir string, task DownloadTask) error {
+	if err := os.WriteFile(extractedMarker(binDir, task), []byte(task.FileName), 0644); err != nil {
+		return i18n.Errorf("记录提取来源失败: %w", err)
 	}
+	return nil
+}
 
-	extractTasks := []struct {
-		tarName   string
-		tarPath   string
-		files     []string
-		condition bool
-	}{
-		{"OpenShift 客户端", fmt.Sprintf("openshift-client-linux-%s.tar.gz", version), []string{"oc", "kubectl"}, true},
-		{"OpenShift 安装程序", fmt.Sprintf("openshift-install-linux-%s.tar.gz", version), []string{"openshift-install"}, true},
-		{"oc-mirror 工具", fmt.Sprintf("oc-mirror-%s.tar.gz", version), []string{"oc-mirror"}, utils.SupportsOcMirror(version)},
+// countingReader counts the bytes read, for the download summary.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// extractTools extracts binaries from downloaded tarballs.
+func (d *Downloader) extractTools(version string) error {
+	binDir := filepath.Join(d.downloadDir, binDirName)
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return i18n.Errorf("创建 bin 目录失败: %w", err)
 	}
 
-	for _, task := range extractTasks {
-		if !task.condition {
+	for _, task := range d.buildDownloadTasks(version) {
+		if len(task.Extract) == 0 || (task.VersionDep && !utils.SupportsOcMirror(version)) {
 			continue
 		}
-		fullPath := filepath.Join(d.downloadDir, task.tarPath)
-		if err := utils.ExtractTarGz(fullPath, binDir, task.files); err != nil {
-			if os.IsNotExist(errors.Unwrap(err)) {
-				fmt.Printf("ℹ️  归档文件 %s 不存在，跳过提取。\n", task.tarPath)
-				continue
-			}
-			return fmt.Errorf("提取 '%s' 失败: %w", task.tarName, err)
+		if d.extracted(binDir, task) {
+			i18n.Fprintf(d.Out, "✅ %s 已提取，跳过。\n", task.Name)
+			continue
+		}
+		fullPath := filepath.Join(d.downloadDir, task.FileName)
+		if !utils.FileExists(fullPath) {
+			i18n.Fprintf(d.Out, "ℹ️  归档文件 %s 不存在，跳过提取。\n", task.FileName)
+			continue
+		}
+		if err := d.cleanupBinDir(binDir, task.Extract); err != nil {
+			i18n.Fprintf(d.Out, "⚠️  清理 bin 目录时发出警告: %v\n", err)
+		}
+		if err := utils.ExtractTarGz(fullPath, binDir, task.Extract); err != nil {
+			return i18n.Errorf("提取 '%s' 失败: %w", task.Name, err)
+		}
+		if err := d.markExtracted(binDir, task); err != nil {
+			return err
 		}
 	}
 
 	if err := d.copyButaneTool(binDir); err != nil {
-		return fmt.Errorf("复制 butane 工具失败: %w", err)
+		return i18n.Errorf("复制 butane 工具失败: %w", err)
 	}
 	if err := utils.MakeExecutable(binDir); err != nil {
-		return fmt.Errorf("设置可执行权限失败: %w", err)
+		return i18n.Errorf("设置可执行权限失败: %w", err)
 	}
 
-	fmt.Println("✅ 工具提取完成。")
+	i18n.Fprintln(d.Out, "✅ 工具提取完成。")
 	return nil
 }
 
 // --- File System Helpers ---
 
-func (d *Downloader) cleanupBinDir(binDir string) error {
-	filesToClean := []string{"oc", "kubectl", "openshift-install", "oc-mirror", "butane"}
-	for _, fileName := range filesToClean {
+// cleanupBinDir removes previously extracted binaries before they are replaced.
+func (d *Downloader) cleanupBinDir(binDir string, files []string) error {
+	for _, fileName := range files {
 		filePath := filepath.Join(binDir, fileName)
 		if _, err := os.Stat(filePath); err == nil {
 			if err := os.Remove(filePath); err != nil {
-				return fmt.Errorf("删除文件 %s 失败: %w", fileName, err)
+				return i18n.Errorf("删除文件 %s 失败: %w", fileName, err)
 			}
 		}
 	}
@@ -268,7 +414,10 @@ func (d *Downloader) copyButaneTool(binDir string) error {
 	dstPath := filepath.Join(binDir, "butane")
 
 	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-		return fmt.Errorf("butane 源文件不存在: %s", srcPath)
+		return i18n.Errorf("butane 源文件不存在: %s", srcPath)
+	}
+	if err := d.cleanupBinDir(binDir, []string{"butane"}); err != nil {
+		return err
 	}
 	return utils.CopyFile(srcPath, dstPath)
 }
@@ -288,7 +437,7 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 
 func (pr *ProgressReader) printProgress() {
 	if pr.total <= 0 {
-		fmt.Printf("\rDownloading %s: %s", pr.fileName, formatBytes(pr.downloaded))
+		fmt.Fprintf(pr.out, "\rDownloading %s: %s", pr.fileName, formatBytes(pr.downloaded))
 		return
 	}
 	percent := float64(pr.downloaded) * 100 / float64(pr.total)
@@ -300,7 +449,7 @@ func (pr *ProgressReader) printProgress() {
 	if speed > 0 && pr.downloaded < pr.total {
 		eta = time.Duration(float64(pr.total-pr.downloaded)/speed) * time.Second
 	}
-	fmt.Printf("\r⬇️  %s [%s] %.1f%% (%s/%s) %s/s ETA: %s ",
+	fmt.Fprintf(pr.out, "\r⬇️  %s [%s] %.1f%% (%s/%s) %s/s ETA: %s ",
 		pr.fileName, bar, percent,
 		formatBytes(pr.downloaded), formatBytes(pr.total),
 		formatBytes(int64(speed)), formatDuration(eta))
@@ -0,0 +1,90 @@
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func testArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStreamExtract(t *testing.T) {
+	archive := testArchive(t, map[string]string{"README.md": "readme", "oc": "oc-binary", "kubectl": "kubectl-binary"})
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	for _, keep := range []bool{false, true} {
+		gets = 0
+		cfg := config.NewDefaultConfig("demo")
+		cfg.Download.Stream = true
+		cfg.Download.KeepArchives = keep
+		d := NewDownloader(cfg, t.TempDir())
+		d.Out = io.Discard
+		d.Quiet = true
+		task := DownloadTask{URL: server.URL, FileName: "openshift-client-linux-4.16.3.tar.gz", Extract: []string{"oc", "kubectl"}}
+
+		if !d.streams(task) {
+			t.Fatalf("keep_archives=%t: expected the task to be streamed", keep)
+		}
+		if err := d.streamExtract(task); err != nil {
+			t.Fatalf("keep_archives=%t: streamExtract() error = %v", keep, err)
+		}
+		binDir := filepath.Join(d.downloadDir, binDirName)
+		if content, err := os.ReadFile(filepath.Join(binDir, "oc")); err != nil || string(content) != "oc-binary" {
+			t.Errorf("keep_archives=%t: bin/oc = %q, %v", keep, content, err)
+		}
+		if _, err := os.Stat(filepath.Join(binDir, "README.md")); !os.IsNotExist(err) {
+			t.Errorf("keep_archives=%t: README.md should not be extracted", keep)
+		}
+		_, err := os.Stat(filepath.Join(d.downloadDir, task.FileName))
+		if keep != (err == nil) {
+			t.Errorf("keep_archives=%t: archive on disk = %t", keep, err == nil)
+		}
+
+		// binaries already extracted from the same archive are not downloaded again
+		if err := d.streamExtract(task); err != nil {
+			t.Fatal(err)
+		}
+		if gets != 1 {
+			t.Errorf("keep_archives=%t: %d GET requests, expected 1", keep, gets)
+		}
+		task.FileName = "openshift-client-linux-4.16.4.tar.gz"
+		if d.extracted(binDir, task) {
+			t.Errorf("keep_archives=%t: binaries from another archive should not count as extracted", keep)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package externaldns 在站点托管的 DNS (Infoblox、Route53 等) 中创建集群需要的 api、api-int、*.apps 和 registry 记录。
+// ocpack 不直接调用各厂商的 API，而是把记录以 JSON 交给 [infra.external_dns] 配置的 webhook、命令或
+// ocpack-dns-<名称> 插件，由站点自己的自动化完成创建；记录的传播由 gate.WaitForExternalDNS 检查。
+package externaldns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+// requestTimeout 一次 webhook 请求的超时时间
+const requestTimeout = time.Minute
+
+// ActionUpsert 创建记录，已存在时更新为请求中的值
+const ActionUpsert = "upsert"
+
+// Record 一条 DNS 记录
+type Record struct {
+	Name  string `json:"name"` // 完整域名，通配符记录为 *.apps.<集群域>
+	Type  string `json:"type"` // A 或 CNAME
+	Value string `json:"value"`
+	TTL   int    `json:"ttl"`
+}
+
+// Request 交给 webhook、命令或插件的请求
+type Request struct {
+	Action  string   `json:"action"`
+	Cluster string   `json:"cluster"`
+	Zone    string   `json:"zone"`
+	Records []Record `json:"records"`
+}
+
+// Records 返回集群需要的记录: api、api-int 和 *.apps 指向负载均衡，registry 指向 Registry 节点。
+// 负载均衡配置为主机名时使用 CNAME 记录
+func Records(cfg *config.ClusterConfig) []Record {
+	ttl := cfg.Infra.ExternalDNS.GetTTL()
+	record := func(name, value string) Record {
+		recordType := config.DNSRecordA
+		if net.ParseIP(value) == nil {
+			recordType, value = config.DNSRecordCNAME, strings.TrimSuffix(value, ".")+"."
+		}
+		return Record{Name: name, Type: recordType, Value: value, TTL: ttl}
+	}
+	loadBalancer := cfg.GetLoadBalancer()
+	records := []Record{
+		record(cfg.APIHostname(), loadBalancer),
+		record(cfg.HostFQDN("api-int"), loadBalancer),
+		record("*."+cfg.AppsDomain(), loadBalancer),
+	}
+	if cfg.Infra.ExternalDNS.RegistryEnabled() {
+		records = append(records, record(cfg.RegistryHostname(), cfg.Registry.IP))
+	}
+	return records
+}
+
+// NewRequest 返回创建集群记录的请求
+func NewRequest(cfg *config.ClusterConfig) Request {
+	return Request{
+		Action:  ActionUpsert,
+		Cluster: cfg.ClusterInfo.ClusterID,
+		Zone:    cfg.GetExternalDNSZone(),
+		Records: Records(cfg),
+	}
+}
+
+// Syncer 按 [infra.external_dns] 创建记录
+type Syncer struct {
+	Runner runner.CommandRunner // 执行 exec 命令和插件，测试时可替换为 runner.Fake
+	HTTP   *http.Client         // 发送 webhook 请求
+	Out    io.Writer            // 命令和插件的输出目标
+}
+
+// NewSyncer 创建 Syncer，输出写入标准输出
+func NewSyncer() *Syncer {
+	return &Syncer{Runner: runner.NewExecRunner(), HTTP: &http.Client{Timeout: requestTimeout}, Out: os.Stdout}
+}
+
+// Apply 将请求交给配置的 webhook、命令或插件。命令和插件在 clusterDir 中执行，请求以 JSON 写入标准输入
+func (s *Syncer) Apply(ctx context.Context, cfg *config.ClusterConfig, clusterDir string, req Request) error {
+	ext := cfg.Infra.ExternalDNS
+	body, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch ext.Provider {
+	case "":
+		return clierr.New(clierr.Config, fmt.Errorf("未配置 [infra.external_dns]"))
+	case config.ExternalDNSWebhook:
+		return s.postWebhook(ctx, ext, body)
+	case config.ExternalDNSExec:
+		return s.run(ctx, runner.Command{Name: "/bin/sh", Args: []string{"-c", ext.Command}}, clusterDir, req, body)
+	default:
+		plugin := config.ExternalDNSPluginPrefix + ext.Provider
+		path, err := s.Runner.LookPath(plugin)
+		if err != nil {
+			return clierr.New(clierr.Prereq, fmt.Errorf("未找到外部 DNS 插件 %s\n💡 请将 %s 安装到 PATH 中，或使用 provider = \"webhook\"/\"exec\"", plugin, plugin))
+		}
+		return s.run(ctx, runner.Command{Name: path, Args: []string{req.Action}}, clusterDir, req, body)
+	}
+}
+
+// run 执行命令或插件，请求写入标准输入，环境变量中提供区域和动作便于简单的脚本使用
+func (s *Syncer) run(ctx context.Context, cmd runner.Command, clusterDir string, req Request, body []byte) error {
+	cmd.Dir = clusterDir
+	cmd.Stdin = body
+	cmd.Stream = true
+	cmd.Output = s.Out
+	cmd.Context = ctx
+	cmd.Env = []string{
+		"OCPACK_DNS_ACTION=" + req.Action,
+		"OCPACK_DNS_ZONE=" + req.Zone,
+		"OCPACK_CLUSTER_NAME=" + req.Cluster,
+	}
+	if _, err := s.Runner.Run(cmd); err != nil {
+		return fmt.Errorf("外部 DNS 命令 %s 执行失败: %w", cmd, err)
+	}
+	return nil
+}
+
+// postWebhook 将请求发送到 webhook，非 2xx 响应视为失败
+func (s *Syncer) postWebhook(ctx context.Context, ext config.ExternalDNS, body []byte) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, ext.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if ext.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+ext.Token)
+	}
+	resp, err := s.HTTP.Do(httpReq)
+	if err != nil {
+		return clierr.New(clierr.Network, fmt.Errorf("请求外部 DNS webhook %s 失败: %w", ext.URL, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("外部 DNS webhook %s 返回 %s: %s", ext.URL, resp.Status, strings.TrimSpace(string(detail)))
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return clierr.New(clierr.Auth, fmt.Errorf("%w\n💡 请检查 infra.external_dns.token", err))
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package externaldns
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func testConfig(ext config.ExternalDNS) *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Infra.ExternalDNS = ext
+	return cfg
+}
+
+func TestRecords(t *testing.T) {
+	cfg := testConfig(config.ExternalDNS{Provider: "infoblox", TTL: 60})
+	got := Records(cfg)
+	want := []Record{
+		{Name: "api.demo.example.com", Type: "A", Value: "192.168.1.2", TTL: 60},
+		{Name: "api-int.demo.example.com", Type: "A", Value: "192.168.1.2", TTL: 60},
+		{Name: "*.apps.demo.example.com", Type: "A", Value: "192.168.1.2", TTL: 60},
+		{Name: "registry.demo.example.com", Type: "A", Value: "192.168.1.3", TTL: 60},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Records() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Records()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	disabled := false
+	cfg.Infra.ExternalDNS.Registry = &disabled
+	cfg.Infra.LoadBalancer = "lb.example.com"
+	got = Records(cfg)
+	if len(got) != 3 || got[2].Type != "CNAME" || got[2].Value != "lb.example.com." {
+		t.Errorf("Records() = %+v, expected CNAME records to the load balancer without registry", got)
+	}
+}
+
+func TestApplyWebhook(t *testing.T) {
+	var received Request
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if authorization != "Bearer t0ken" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	cfg := testConfig(config.ExternalDNS{Provider: "webhook", URL: server.URL, Token: "t0ken"})
+	syncer := &Syncer{HTTP: server.Client(), Out: io.Discard}
+	if err := syncer.Apply(context.Background(), cfg, t.TempDir(), NewRequest(cfg)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if received.Action != ActionUpsert || received.Cluster != "demo" || received.Zone != "example.com" || len(received.Records) != 4 {
+		t.Errorf("webhook received %+v", received)
+	}
+
+	cfg.Infra.ExternalDNS.Token = "wrong"
+	err := syncer.Apply(context.Background(), cfg, t.TempDir(), NewRequest(cfg))
+	if clierr.CategoryOf(err) != clierr.Auth || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Apply() with a rejected token error = %v, expected an auth error", err)
+	}
+}
+
+func TestApplyCommand(t *testing.T) {
+	fake := &runner.Fake{Paths: map[string]string{"ocpack-dns-infoblox": "/usr/local/bin/ocpack-dns-infoblox"}}
+	syncer := &Syncer{Runner: fake, Out: io.Discard}
+	clusterDir := t.TempDir()
+
+	cfg := testConfig(config.ExternalDNS{Provider: "infoblox"})
+	if err := syncer.Apply(context.Background(), cfg, clusterDir, NewRequest(cfg)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	cfg = testConfig(config.ExternalDNS{Provider: "exec", Command: "./dns/upsert.sh"})
+	if err := syncer.Apply(context.Background(), cfg, clusterDir, NewRequest(cfg)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	lines := fake.CommandLines()
+	if len(lines) != 2 || lines[0] != "/usr/local/bin/ocpack-dns-infoblox upsert" || !strings.Contains(lines[1], "./dns/upsert.sh") {
+		t.Errorf("commands = %q", lines)
+	}
+	for _, call := range fake.Calls() {
+		var req Request
+		if err := json.Unmarshal(call.Stdin, &req); err != nil || len(req.Records) != 4 {
+			t.Errorf("%s stdin = %s, expected the JSON request", call, call.Stdin)
+		}
+		if call.Dir != clusterDir || !strings.Contains(strings.Join(call.Env, " "), "OCPACK_DNS_ZONE=example.com") {
+			t.Errorf("%s ran in %s with env %v", call, call.Dir, call.Env)
+		}
+	}
+
+	cfg = testConfig(config.ExternalDNS{Provider: "route53"})
+	if err := syncer.Apply(context.Background(), cfg, clusterDir, NewRequest(cfg)); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("Apply() with a missing plugin error = %v, expected a prerequisite error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package gate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/externaldns"
+	"ocpack/pkg/i18n"
+)
+
+// propagationInterval WaitForExternalDNS 两次检查之间的间隔，测试时可缩短
+var propagationInterval = 10 * time.Second
+
+// CheckExternalDNS 配置了 [infra.external_dns] 时检查集群记录是否已传播到全部 resolvers，未配置时跳过检查
+func CheckExternalDNS(cfg *config.ClusterConfig) error {
+	if !cfg.ExternalDNSEnabled() {
+		return nil
+	}
+	if failures := externalDNSFailures(cfg); len(failures) > 0 {
+		return clierr.New(clierr.Prereq, i18n.Errorf("%d 条外部 DNS 记录尚未生效:\n  %s\n💡 请执行 ocpack sync-dns %s 创建记录并等待传播",
+			len(failures), strings.Join(failures, "\n  "), cfg.ClusterInfo.ClusterID))
+	}
+	return nil
+}
+
+// WaitForExternalDNS 每隔 propagationInterval 检查一次记录，直到全部 resolvers 都返回期望的地址，
+// 超过 infra.external_dns.propagation_timeout 或 ctx 结束时返回仍未生效的记录
+func WaitForExternalDNS(ctx context.Context, out io.Writer, cfg *config.ClusterConfig) error {
+	timeout := cfg.Infra.ExternalDNS.GetPropagationTimeout()
+	deadline := time.Now().Add(timeout)
+	for {
+		failures := externalDNSFailures(cfg)
+		if len(failures) == 0 {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return clierr.New(clierr.Prereq, i18n.Errorf("%s 内 %d 条外部 DNS 记录未传播到全部 DNS 服务器:\n  %s\n💡 请检查外部 DNS 的同步状态，或增大 infra.external_dns.propagation_timeout",
+				timeout, len(failures), strings.Join(failures, "\n  ")))
+		}
+		i18n.Fprintf(out, "⏳ %d 条记录尚未生效，%s 后重新检查...\n", len(failures), propagationInterval)
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(propagationInterval):
+		}
+	}
+}
+
+// externalDNSFailures 通过每个 resolver 解析全部记录，返回未生效的记录。通配符记录使用一个随机名称解析，
+// 确认 *.apps 确实是通配符而不只是为个别路由添加的记录
+func externalDNSFailures(cfg *config.ClusterConfig) []string {
+	var failures []string
+	for _, server := range cfg.GetExternalDNSResolvers() {
+		for _, record := range externaldns.Records(cfg) {
+			name := record.Name
+			if strings.HasPrefix(name, "*.") {
+				name = randomLabel() + name[1:]
+			}
+			addrs, err := lookupHost(server, name)
+			switch {
+			case err != nil:
+				failures = append(failures, fmt.Sprintf("%s @%s: %v", name, server, err))
+			case record.Type == config.DNSRecordA && !contains(addrs, record.Value):
+				failures = append(failures, i18n.Sprintf("%s @%s 解析为 %v，期望 %s", name, server, addrs, record.Value))
+			}
+		}
+	}
+	return failures
+}
+
+// randomLabel 返回检查通配符记录使用的随机主机名
+func randomLabel() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "ocpack-dns-check-" + hex.EncodeToString(b)
+}
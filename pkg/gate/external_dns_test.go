@@ -0,0 +1,86 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+)
+
+func TestCheckExternalDNS(t *testing.T) {
+	records := map[string]string{
+		"api.demo.example.com":      "192.168.1.2",
+		"api-int.demo.example.com":  "192.168.1.2",
+		"registry.demo.example.com": "192.168.1.3",
+	}
+	var lookups []string
+	original := lookupHost
+	lookupHost = func(server, host string) ([]string, error) {
+		lookups = append(lookups, host+"@"+server)
+		if strings.HasSuffix(host, ".apps.demo.example.com") {
+			host = "*.apps.demo.example.com"
+		}
+		if addr, ok := records[host]; ok {
+			return []string{addr}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+	defer func() { lookupHost = original }()
+
+	cfg := testConfig()
+	if err := CheckExternalDNS(cfg); err != nil || len(lookups) != 0 {
+		t.Errorf("check should be skipped without [infra.external_dns], got %v after %v", err, lookups)
+	}
+
+	cfg.Infra.ExternalDNS = config.ExternalDNS{Provider: "infoblox", Resolvers: []string{"10.0.0.53"}}
+	err := CheckExternalDNS(cfg)
+	if err == nil || !strings.Contains(err.Error(), "1 条外部 DNS 记录尚未生效") || !strings.Contains(err.Error(), "ocpack sync-dns demo") {
+		t.Errorf("expected the wildcard record to be missing, got %v", err)
+	}
+	if !strings.Contains(lookups[2], "ocpack-dns-check-") || !strings.HasSuffix(lookups[2], "@10.0.0.53") {
+		t.Errorf("wildcard lookup = %s, expected a random name on the configured resolver", lookups[2])
+	}
+
+	records["*.apps.demo.example.com"] = "192.168.1.2"
+	records["registry.demo.example.com"] = "192.168.1.9"
+	if err := CheckExternalDNS(cfg); err == nil || !strings.Contains(err.Error(), "期望 192.168.1.3") {
+		t.Errorf("expected a registry address mismatch, got %v", err)
+	}
+}
+
+func TestWaitForExternalDNS(t *testing.T) {
+	checks := 0
+	original := lookupHost
+	lookupHost = func(server, host string) ([]string, error) {
+		checks++
+		if checks <= 4 {
+			return nil, fmt.Errorf("no such host")
+		}
+		if strings.HasPrefix(host, "registry.") {
+			return []string{"192.168.1.3"}, nil
+		}
+		return []string{"192.168.1.2"}, nil
+	}
+	originalInterval := propagationInterval
+	propagationInterval = time.Millisecond
+	defer func() { lookupHost, propagationInterval = original, originalInterval }()
+
+	cfg := testConfig()
+	cfg.Infra.ExternalDNS = config.ExternalDNS{Provider: "infoblox", PropagationTimeout: "1m"}
+	out := &strings.Builder{}
+	if err := WaitForExternalDNS(context.Background(), out, cfg); err != nil {
+		t.Fatalf("WaitForExternalDNS() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "4 条记录尚未生效") {
+		t.Errorf("expected a progress message, got %q", out)
+	}
+
+	checks = 0
+	cfg.Infra.ExternalDNS.PropagationTimeout = "1ns"
+	if err := WaitForExternalDNS(context.Background(), out, cfg); err == nil || !strings.Contains(err.Error(), "未传播到全部 DNS 服务器") {
+		t.Errorf("expected a propagation timeout, got %v", err)
+	}
+}
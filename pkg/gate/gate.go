@@ -0,0 +1,363 @@
+// Package gate 在部署阶段之间执行就绪检查：生成 ISO 前确认私有仓库中已有 release 镜像、集群 DNS 记录可以解析，
+// 并在机器网络中的 Bastion 上模拟节点的名称解析和端口访问；加载镜像前确认私有仓库运行正常且认证有效。
+// 检查失败时返回可操作的错误，避免后续命令以难以理解的方式失败。
+package gate
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/registry"
+)
+
+// checkTimeout 单个网络检查的超时时间
+const checkTimeout = 10 * time.Second
+
+// 网络访问使用的函数，测试时可替换
+var (
+	// httpDo 发送请求，HTTPS 请求按 tlsConfig 校验证书，tlsConfig 为 nil 时只信任系统的 CA
+	httpDo = func(req *http.Request, tlsConfig *tls.Config) (*http.Response, error) {
+		client := &http.Client{
+			Timeout:   checkTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+		return client.Do(req)
+	}
+	// lookupHost 通过指定的 DNS 服务器解析域名
+	lookupHost = func(server, host string) ([]string, error) {
+		resolver := &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, net.JoinHostPort(server, "53"))
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		defer cancel()
+		return resolver.LookupHost(ctx, host)
+	}
+	// newRegistryClient 创建访问私有仓库的客户端，使用集群目录中的私有仓库 CA 校验证书
+	newRegistryClient = registry.ForCluster
+	// dialTCP 确认 TCP 端口可以连接
+	dialTCP = func(address string) error {
+		conn, err := net.DialTimeout("tcp", address, checkTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+)
+
+// Check 一项就绪检查
+type Check struct {
+	Name string
+	Run  func(cfg *config.ClusterConfig) error
+}
+
+// BeforeGenerateISO 返回生成 ISO 前的检查，访问私有仓库时使用 clusterDir 中的私有仓库 CA 校验证书
+func BeforeGenerateISO(clusterDir string) []Check {
+	return []Check{
+		{Name: i18n.T("私有仓库中的 release 镜像"), Run: func(cfg *config.ClusterConfig) error { return CheckReleasePayload(cfg, clusterDir) }},
+		{Name: i18n.T("集群 DNS 记录"), Run: CheckClusterDNS},
+		{Name: i18n.T("外部 DNS 记录传播"), Run: CheckExternalDNS},
+		{Name: i18n.T("hosts 模式主机名解析"), Run: CheckHostEntries},
+		{Name: i18n.T("Bastion 负载均衡"), Run: CheckBastionHAProxy},
+		{Name: i18n.T("节点视角的名称解析和端口"), Run: CheckInstallerView},
+	}
+}
+
+// BeforeLoadImage 返回加载镜像前的检查，访问私有仓库时使用 clusterDir 中的私有仓库 CA 校验证书
+func BeforeLoadImage(clusterDir string) []Check {
+	return []Check{
+		{Name: i18n.T("私有仓库状态"), Run: func(cfg *config.ClusterConfig) error { return CheckRegistryHealth(cfg, clusterDir) }},
+		{Name: i18n.T("私有仓库认证"), Run: func(cfg *config.ClusterConfig) error { return CheckRegistryCredentials(cfg, clusterDir) }},
+	}
+}
+
+// Run 依次执行全部检查并输出结果，返回由全部失败检查组成的错误
+func Run(cfg *config.ClusterConfig, checks []Check) error {
+	return RunTo(os.Stdout, cfg, checks)
+}
+
+// RunTo 与 Run 相同，检查结果写入 out
+func RunTo(out io.Writer, cfg *config.ClusterConfig, checks []Check) error {
+	var errs []error
+	for _, check := range checks {
+		if err := check.Run(cfg); err != nil {
+			fmt.Fprintf(out, "❌ %s: %v\n", check.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, err))
+			continue
+		}
+		fmt.Fprintf(out, "✅ %s\n", check.Name)
+	}
+	if len(errs) > 0 {
+		// 错误类别取第一个失败检查的类别，未归类的检查失败视为前置条件不满足
+		err := i18n.Errorf("就绪检查未通过 (可使用 --skip-checks 跳过):\n%w", errors.Join(errs...))
+		if clierr.CategoryOf(err) == clierr.Internal {
+			err = clierr.New(clierr.Prereq, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// ReleaseTags 返回私有仓库中 release 镜像可能使用的标签，与 openshift-install 提取时尝试的顺序一致。
+// 配置多种架构时为 multi payload 的标签，如 4.16.3-multi
+func ReleaseTags(cfg *config.ClusterConfig) []string {
+	return []string{cfg.GetReleaseTag(), cfg.ClusterInfo.OpenShiftVersion}
+}
+
+// CheckReleasePayload 通过 HEAD 清单确认私有仓库中已有 openshift/release-images 的 release 镜像，
+// proxy-cache 模式改为检查拉取代理 (见 CheckProxyCache)
+func CheckReleasePayload(cfg *config.ClusterConfig, clusterDir string) error {
+	if cfg.IsProxyCache() {
+		return CheckProxyCache(cfg, clusterDir)
+	}
+	registryHost := cfg.GetRegistryHost()
+	repository := strings.TrimPrefix(cfg.GetReleaseRepository(), registryHost+"/")
+	client, err := registryClient(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	var statuses []string
+	for _, tag := range ReleaseTags(cfg) {
+		exists, err := client.ManifestExists(repository, tag)
+		var statusErr *registry.StatusError
+		switch {
+		case exists:
+			return nil
+		case clierr.CategoryOf(err) == clierr.Auth:
+			return clierr.New(clierr.Auth, i18n.Errorf("私有仓库拒绝访问 (%v)\n💡 请检查 [registry] registry_user 和 registry_password 是否与部署时一致", err))
+		case errors.As(err, &statusErr):
+			statuses = append(statuses, fmt.Sprintf("%s: %s", tag, statusErr.Status))
+		case err != nil:
+			return clierr.New(clierr.Network, i18n.Errorf("%v\n💡 请确认 Registry 已部署 (ocpack deploy-registry) 且本机可以解析并访问该地址", err))
+		default:
+			statuses = append(statuses, i18n.Sprintf("%s: 未找到", tag))
+		}
+	}
+	return clierr.New(clierr.Prereq, i18n.Errorf("私有仓库 %s 中未找到 OpenShift %s 的 release 镜像 (%v)\n💡 请先执行 ocpack save-image 和 ocpack load-image",
+		registryHost, cfg.ClusterInfo.OpenShiftVersion, statuses))
+}
+
+// proxyCacheReleaseRepository quay.io 上 release 镜像的仓库，proxy-cache 模式通过代理查询该仓库确认上游可以访问
+const proxyCacheReleaseRepository = "openshift-release-dev/ocp-release"
+
+// CheckProxyCache 确认每个上游的拉取代理响应 /v2/，并通过 quay.io 的代理查询 release 镜像，
+// 确认代理可以访问上游且 pull-secret 中的认证有效。使用 clusterDir 中的 CA 校验代理的证书
+func CheckProxyCache(cfg *config.ClusterConfig, clusterDir string) error {
+	tlsConfig, err := registry.TLSConfig(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	for _, upstream := range cfg.GetProxyCacheUpstreams() {
+		base := fmt.Sprintf("https://%s", net.JoinHostPort(cfg.Registry.IP, fmt.Sprint(upstream.Port)))
+		url := base + "/v2/"
+		if upstream.Source == "quay.io" {
+			url = fmt.Sprintf("%s/v2/%s/manifests/%s", base, proxyCacheReleaseRepository, ReleaseTags(cfg)[0])
+		}
+		req, err := http.NewRequest(http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		for _, accept := range registry.ManifestMediaTypes {
+			req.Header.Add("Accept", accept)
+		}
+		resp, err := httpDo(req, tlsConfig)
+		if err != nil {
+			return clierr.New(clierr.Network, i18n.Errorf("无法访问 %s 的拉取代理 %s: %v\n💡 请确认已执行 ocpack deploy-registry 且端口 %d 可访问", upstream.Source, base, err, upstream.Port))
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return clierr.New(clierr.Auth, i18n.Errorf("%s 的拉取代理访问上游被拒绝 (%s)\n💡 请检查 pull-secret.txt 中 %s 的认证并重新执行 ocpack deploy-registry", upstream.Source, resp.Status, upstream.Source))
+		default:
+			return clierr.New(clierr.Prereq, i18n.Errorf("%s 的拉取代理 %s 返回 %s\n💡 请确认 Registry 节点可以访问 %s", upstream.Source, url, resp.Status, upstream.Source))
+		}
+	}
+	return nil
+}
+
+// CheckClusterDNS 通过节点使用的 DNS 服务器解析 api、api-int 和 *.apps 记录，并确认指向负载均衡
+func CheckClusterDNS(cfg *config.ClusterConfig) error {
+	servers := cfg.GetDNSServers()
+	if len(servers) == 0 || servers[0] == "" {
+		return clierr.New(clierr.Prereq, i18n.Errorf("未配置 DNS 服务器\n💡 请配置 [bastion] ip 或 [infra] dns_servers"))
+	}
+	server := servers[0]
+	loadBalancer := cfg.GetLoadBalancer()
+
+	deployHint := i18n.T("请确认已执行 ocpack deploy-bastion")
+	switch {
+	case cfg.ExternalDNSEnabled():
+		deployHint = i18n.Sprintf("请执行 ocpack sync-dns %s 在外部 DNS 中创建记录", cfg.ClusterInfo.ClusterID)
+	case !cfg.BastionEnabled():
+		deployHint = i18n.T("请在站点 DNS 中添加 api、api-int 和 *.apps 记录")
+	}
+	for _, host := range []string{cfg.APIHostname(), cfg.HostFQDN("api-int"), "console-openshift-console." + cfg.AppsDomain()} {
+		addrs, err := lookupHost(server, host)
+		if err != nil {
+			return clierr.New(clierr.Prereq, i18n.Errorf("DNS 服务器 %s 无法解析 %s: %v\n💡 %s", server, host, err, deployHint))
+		}
+		if !contains(addrs, loadBalancer) {
+			return clierr.New(clierr.Prereq, i18n.Errorf("%s 解析为 %v，未指向负载均衡 %s\n💡 %s", host, addrs, loadBalancer, deployHint))
+		}
+	}
+	return nil
+}
+
+// CheckHostEntries 在 [bastion.dns] mode = "hosts" 时检查全部主机名的解析，其他模式跳过检查
+func CheckHostEntries(cfg *config.ClusterConfig) error {
+	if !cfg.DNSHostsMode() {
+		return nil
+	}
+	return VerifyHostEntries(cfg)
+}
+
+// VerifyHostEntries 通过节点使用的每个 DNS 服务器 (与 ISO 启动后的节点相同) 解析 hosts 文件中的全部名称
+// 和一个任意的 *.apps 名称，确认地址与生成的 hosts 文件一致
+func VerifyHostEntries(cfg *config.ClusterConfig) error {
+	wildcard := config.HostEntry{IP: cfg.GetLoadBalancer(), Names: []string{"ocpack-dns-check." + cfg.AppsDomain()}}
+	expected := make(map[string]string)
+	var names []string
+	for _, entry := range append(cfg.HostEntries(), wildcard) {
+		for _, name := range entry.Names {
+			expected[name] = entry.IP
+			names = append(names, name)
+		}
+	}
+
+	var failures []string
+	for _, server := range cfg.GetDNSServers() {
+		for _, name := range names {
+			addrs, err := lookupHost(server, name)
+			switch {
+			case err != nil:
+				failures = append(failures, fmt.Sprintf("%s @%s: %v", name, server, err))
+			case !contains(addrs, expected[name]):
+				failures = append(failures, i18n.Sprintf("%s @%s 解析为 %v，期望 %s", name, server, addrs, expected[name]))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return clierr.New(clierr.Prereq, i18n.Errorf("%d 个名称解析失败:\n  %s\n💡 请执行 ocpack deploy-bastion 更新 Bastion 上的 dnsmasq，或检查 ocpack dns-hosts 生成的文件",
+			len(failures), strings.Join(failures, "\n  ")))
+	}
+	return nil
+}
+
+// CheckBastionHAProxy 访问 Bastion 上 HAProxy 的统计页面，并确认 API、Machine Config Server 和 Ingress 前端端口
+// 可以连接 (端口来自 [bastion.haproxy])。未部署 Bastion 时使用站点的负载均衡，跳过检查
+func CheckBastionHAProxy(cfg *config.ClusterConfig) error {
+	if !cfg.BastionEnabled() {
+		return nil
+	}
+	haproxy := cfg.Bastion.HAProxy
+	url := fmt.Sprintf("http://%s/stats", net.JoinHostPort(cfg.Bastion.IP, fmt.Sprint(haproxy.GetStatsPort())))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if haproxy.StatsAuthEnabled() {
+		req.SetBasicAuth(haproxy.StatsUser, haproxy.StatsPassword)
+	}
+	resp, err := httpDo(req, nil)
+	if err != nil {
+		return clierr.New(clierr.Network, i18n.Errorf("无法访问 HAProxy 统计页面 %s: %v\n💡 请确认已执行 ocpack deploy-bastion 且 [bastion.haproxy] stats_port 与部署时一致", url, err))
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return clierr.New(clierr.Auth, i18n.Errorf("HAProxy 统计页面拒绝访问 (%s)\n💡 请检查 [bastion.haproxy] stats_user 和 stats_password 是否与部署时一致", resp.Status))
+	default:
+		return clierr.New(clierr.Prereq, i18n.Errorf("HAProxy 统计页面 %s 返回 %s", url, resp.Status))
+	}
+
+	for _, frontend := range []struct {
+		name string
+		port int
+	}{
+		{"API", config.APIServerPort},
+		{"Machine Config Server", config.MachineConfigPort},
+		{"Ingress HTTP", haproxy.GetHTTPPort()},
+		{"Ingress HTTPS", haproxy.GetHTTPSPort()},
+	} {
+		address := net.JoinHostPort(cfg.Bastion.IP, fmt.Sprint(frontend.port))
+		if err := dialTCP(address); err != nil {
+			return clierr.New(clierr.Network, i18n.Errorf("无法连接 HAProxy 的 %s 前端 %s: %v\n💡 请确认 haproxy 服务正在运行，且端口未被其他服务占用", frontend.name, address, err))
+		}
+	}
+	return nil
+}
+
+// CheckRegistryHealth 通过 Registry IP 检查私有仓库的健康检查接口，不依赖仓库域名的解析
+func CheckRegistryHealth(cfg *config.ClusterConfig, clusterDir string) error {
+	client, err := registryClient(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	client.Host = net.JoinHostPort(cfg.Registry.IP, "8443")
+	err = client.Health()
+	var statusErr *registry.StatusError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &statusErr):
+		return clierr.New(clierr.Prereq, i18n.Errorf("私有仓库健康检查 %s 返回 %s\n💡 请登录 Registry 节点检查 quay-app 服务状态", statusErr.URL, statusErr.Status))
+	default:
+		return clierr.New(clierr.Network, i18n.Errorf("%v\n💡 请确认 Registry 已部署 (ocpack deploy-registry) 且 8443 端口可访问", err))
+	}
+}
+
+// CheckRegistryCredentials 使用配置中的仓库用户和密码访问 /v2/，确认认证有效
+func CheckRegistryCredentials(cfg *config.ClusterConfig, clusterDir string) error {
+	registryHost := cfg.GetRegistryHost()
+	client, err := registryClient(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	err = client.Ping()
+	var statusErr *registry.StatusError
+	switch {
+	case err == nil:
+		return nil
+	case clierr.CategoryOf(err) == clierr.Auth:
+		return clierr.New(clierr.Auth, i18n.Errorf("用户 %s 认证失败 (%v)\n💡 请检查 [registry] registry_user 和 registry_password 是否与部署时一致", cfg.Registry.RegistryUser, err))
+	case errors.As(err, &statusErr):
+		return clierr.New(clierr.Prereq, i18n.Errorf("私有仓库 %s/v2/ 返回 %s", registryHost, statusErr.Status))
+	default:
+		return clierr.New(clierr.Network, i18n.Errorf("%v\n💡 请确认本机可以解析 %s (可在 /etc/hosts 中添加记录)", err, registryHost))
+	}
+}
+
+// registryClient 返回使用集群私有仓库 CA 校验证书的客户端，单个请求的超时时间为 checkTimeout
+func registryClient(cfg *config.ClusterConfig, clusterDir string) (*registry.Client, error) {
+	client, err := newRegistryClient(cfg, clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	client.HTTP.Timeout = checkTimeout
+	return client, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
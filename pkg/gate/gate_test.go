@@ -0,0 +1,317 @@
+package gate
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/registry"
+)
+
+func testConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Registry.RegistryPassword = "secret"
+	return cfg
+}
+
+// fakeRegistry 模拟使用 Bearer 令牌认证的私有仓库，images 为仓库中已有的镜像 (仓库路径:标签)
+func fakeRegistry(t *testing.T, images ...string) func() {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/auth", func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "ocp4" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token": "t0ken"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="https://registry.demo.example.com:8443/v2/auth",service="registry.demo.example.com:8443"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		for _, image := range images {
+			repository, tag, _ := strings.Cut(image, ":")
+			if r.URL.Path == "/v2/"+repository+"/manifests/"+tag {
+				return
+			}
+		}
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/health/instance", func(w http.ResponseWriter, r *http.Request) {})
+
+	original, originalClient := httpDo, newRegistryClient
+	httpDo = func(req *http.Request, _ *tls.Config) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+		return recorder.Result(), nil
+	}
+	newRegistryClient = func(cfg *config.ClusterConfig, clusterDir string) (*registry.Client, error) {
+		client := registry.NewClient(cfg, nil)
+		client.HTTP = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) { return httpDo(req, nil) })}
+		return client, nil
+	}
+	return func() { httpDo, newRegistryClient = original, originalClient }
+}
+
+// roundTripFunc 将函数用作 http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCheckReleasePayload(t *testing.T) {
+	cfg := testConfig()
+
+	restore := fakeRegistry(t, "openshift/release-images:4.16.3-x86_64")
+	if err := CheckReleasePayload(cfg, t.TempDir()); err != nil {
+		t.Errorf("CheckReleasePayload() error = %v", err)
+	}
+	restore()
+
+	restore = fakeRegistry(t, "openshift/release-images:4.16.3-multi")
+	cfg.SaveImage.Architectures = []string{"amd64", "arm64"}
+	if err := CheckReleasePayload(cfg, t.TempDir()); err != nil {
+		t.Errorf("CheckReleasePayload() with multi-arch release error = %v", err)
+	}
+	cfg.SaveImage.Architectures = nil
+	restore()
+
+	restore = fakeRegistry(t, "redhat-mirror/openshift/release-images:4.16.3")
+	cfg.SaveImage.TargetNamespace = "redhat-mirror"
+	if err := CheckReleasePayload(cfg, t.TempDir()); err != nil {
+		t.Errorf("CheckReleasePayload() with target_namespace error = %v", err)
+	}
+	restore()
+
+	restore = fakeRegistry(t, "openshift/release-images:4.15.0-x86_64")
+	defer restore()
+	err := CheckReleasePayload(cfg, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "load-image") || clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("expected missing release prereq error, got %v", err)
+	}
+
+	cfg.Registry.RegistryPassword = "wrong"
+	if err := CheckReleasePayload(cfg, t.TempDir()); err == nil || !strings.Contains(err.Error(), "拒绝访问") || clierr.CategoryOf(err) != clierr.Auth {
+		t.Errorf("expected unauthorized auth error, got %v", err)
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	defer fakeRegistry(t)()
+	cfg := testConfig()
+
+	if err := Run(cfg, BeforeLoadImage(t.TempDir())); err != nil {
+		t.Errorf("Run(BeforeLoadImage) error = %v", err)
+	}
+
+	cfg.Registry.RegistryPassword = "wrong"
+	err := Run(cfg, BeforeLoadImage(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "认证失败") || strings.Contains(err.Error(), "私有仓库状态") {
+		t.Errorf("expected only the credentials check to fail, got %v", err)
+	}
+	if clierr.ExitCode(err) != 5 {
+		t.Errorf("exit code = %d, expected auth exit code", clierr.ExitCode(err))
+	}
+}
+
+func TestCheckRegistryUsesClusterCA(t *testing.T) {
+	cfg := testConfig()
+	cfg.Infra.TrustBundlePaths = []string{"missing.pem"}
+	for name, check := range map[string]func(*config.ClusterConfig, string) error{
+		"CheckReleasePayload":      CheckReleasePayload,
+		"CheckRegistryHealth":      CheckRegistryHealth,
+		"CheckRegistryCredentials": CheckRegistryCredentials,
+	} {
+		if err := check(cfg, t.TempDir()); clierr.CategoryOf(err) != clierr.Config || !strings.Contains(err.Error(), "missing.pem") {
+			t.Errorf("%s() with unreadable trust bundle error = %v", name, err)
+		}
+	}
+}
+
+func TestCheckClusterDNS(t *testing.T) {
+	records := map[string][]string{
+		"api.demo.example.com":                            {"192.168.1.2"},
+		"api-int.demo.example.com":                        {"192.168.1.2"},
+		"console-openshift-console.apps.demo.example.com": {"192.168.1.9"},
+	}
+	var servers []string
+	original := lookupHost
+	lookupHost = func(server, host string) ([]string, error) {
+		servers = append(servers, server)
+		if addrs, ok := records[host]; ok {
+			return addrs, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+	defer func() { lookupHost = original }()
+
+	cfg := testConfig()
+	err := CheckClusterDNS(cfg)
+	if err == nil || !strings.Contains(err.Error(), "未指向负载均衡 192.168.1.2") {
+		t.Errorf("expected apps record mismatch, got %v", err)
+	}
+	if servers[0] != "192.168.1.2" {
+		t.Errorf("lookup used DNS server %s, expected bastion", servers[0])
+	}
+
+	records["console-openshift-console.apps.demo.example.com"] = []string{"192.168.1.2"}
+	if err := CheckClusterDNS(cfg); err != nil {
+		t.Errorf("CheckClusterDNS() error = %v", err)
+	}
+
+	delete(records, "api-int.demo.example.com")
+	if err := CheckClusterDNS(cfg); err == nil || !strings.Contains(err.Error(), "deploy-bastion") {
+		t.Errorf("expected resolve error with deploy hint, got %v", err)
+	}
+}
+
+func TestCheckHostEntries(t *testing.T) {
+	cfg := testConfig()
+	cfg.Cluster.ControlPlane = []config.Node{{Name: "master-0", IP: "192.168.1.10"}}
+
+	calls := 0
+	original := lookupHost
+	lookupHost = func(server, host string) ([]string, error) {
+		calls++
+		switch {
+		case host == "registry.demo.example.com":
+			return []string{"192.168.1.3"}, nil
+		case host == "master-0.demo.example.com":
+			return nil, fmt.Errorf("no such host")
+		default:
+			return []string{"192.168.1.2"}, nil
+		}
+	}
+	defer func() { lookupHost = original }()
+
+	if err := CheckHostEntries(cfg); err != nil || calls != 0 {
+		t.Errorf("authoritative mode should be skipped, got %v after %d lookups", err, calls)
+	}
+
+	cfg.Bastion.DNS.Mode = config.DNSModeHosts
+	err := CheckHostEntries(cfg)
+	if err == nil || !strings.Contains(err.Error(), "1 个名称解析失败") || !strings.Contains(err.Error(), "master-0.demo.example.com @192.168.1.2") {
+		t.Errorf("expected master-0 failure, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "dns-hosts") {
+		t.Errorf("expected dns-hosts hint, got %v", err)
+	}
+}
+
+func TestCheckBastionHAProxy(t *testing.T) {
+	var statsURL, statsUser string
+	originalHTTP := httpDo
+	httpDo = func(req *http.Request, _ *tls.Config) (*http.Response, error) {
+		statsURL = req.URL.String()
+		user, password, _ := req.BasicAuth()
+		statsUser = user
+		recorder := httptest.NewRecorder()
+		if user != "" && password != "secret" {
+			recorder.WriteHeader(http.StatusUnauthorized)
+		}
+		return recorder.Result(), nil
+	}
+	var dialed []string
+	listening := map[string]bool{"192.168.1.2:6443": true, "192.168.1.2:22623": true, "192.168.1.2:80": true, "192.168.1.2:8443": true}
+	originalDial := dialTCP
+	dialTCP = func(address string) error {
+		dialed = append(dialed, address)
+		if !listening[address] {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+	defer func() { httpDo, dialTCP = originalHTTP, originalDial }()
+
+	cfg := testConfig()
+	cfg.Bastion.HAProxy = config.BastionHAProxy{StatsPort: 9001, StatsUser: "admin", StatsPassword: "secret", HTTPSPort: 8443}
+	if err := CheckBastionHAProxy(cfg); err != nil {
+		t.Fatalf("CheckBastionHAProxy() error = %v", err)
+	}
+	if statsURL != "http://192.168.1.2:9001/stats" || statsUser != "admin" {
+		t.Errorf("stats request = %s (user %q)", statsURL, statsUser)
+	}
+	if strings.Join(dialed, " ") != "192.168.1.2:6443 192.168.1.2:22623 192.168.1.2:80 192.168.1.2:8443" {
+		t.Errorf("dialed %v", dialed)
+	}
+
+	cfg.Bastion.HAProxy.StatsPassword = "wrong"
+	if err := CheckBastionHAProxy(cfg); clierr.CategoryOf(err) != clierr.Auth {
+		t.Errorf("expected auth error, got %v", err)
+	}
+
+	cfg.Bastion.HAProxy = config.BastionHAProxy{}
+	if err := CheckBastionHAProxy(cfg); err == nil || !strings.Contains(err.Error(), "Ingress HTTPS") {
+		t.Errorf("expected https frontend error, got %v", err)
+	}
+
+	disabled := false
+	cfg.Bastion.Enabled = &disabled
+	if err := CheckBastionHAProxy(cfg); err != nil {
+		t.Errorf("expected check to be skipped without bastion, got %v", err)
+	}
+}
+
+func TestCheckProxyCache(t *testing.T) {
+	var requested []string
+	var tlsConfigs []*tls.Config
+	status := map[string]int{}
+	originalHTTP := httpDo
+	httpDo = func(req *http.Request, tlsConfig *tls.Config) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		tlsConfigs = append(tlsConfigs, tlsConfig)
+		recorder := httptest.NewRecorder()
+		if code, ok := status[req.URL.Host]; ok {
+			recorder.WriteHeader(code)
+		}
+		return recorder.Result(), nil
+	}
+	defer func() { httpDo = originalHTTP }()
+
+	cfg := testConfig()
+	cfg.Registry.MirrorMode = config.MirrorModeProxyCache
+	if err := CheckReleasePayload(cfg, t.TempDir()); err != nil {
+		t.Fatalf("CheckReleasePayload() error = %v", err)
+	}
+	expected := []string{
+		"https://192.168.1.3:5001/v2/openshift-release-dev/ocp-release/manifests/4.16.3-x86_64",
+		"https://192.168.1.3:5002/v2/",
+	}
+	if strings.Join(requested, ",") != strings.Join(expected, ",") {
+		t.Errorf("requested %v, expected %v", requested, expected)
+	}
+
+	for _, tlsConfig := range tlsConfigs {
+		if tlsConfig == nil || tlsConfig.InsecureSkipVerify {
+			t.Errorf("proxy cache requests should verify certificates, got %+v", tlsConfig)
+		}
+	}
+
+	tlsConfigs = nil
+	cfg.Registry.SkipTLSVerify = true
+	if err := CheckProxyCache(cfg, t.TempDir()); err != nil {
+		t.Fatalf("CheckProxyCache() error = %v", err)
+	}
+	if len(tlsConfigs) == 0 || !tlsConfigs[0].InsecureSkipVerify {
+		t.Errorf("registry.skip_tls_verify should skip certificate verification, got %+v", tlsConfigs)
+	}
+
+	status["192.168.1.3:5001"] = http.StatusUnauthorized
+	if err := CheckProxyCache(cfg, t.TempDir()); err == nil || clierr.CategoryOf(err) != clierr.Auth || !strings.Contains(err.Error(), "pull-secret") {
+		t.Errorf("expected upstream auth error, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package gate
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+// simulateDialTimeout 在 Bastion 上连接单个端口的超时时间 (秒)
+const simulateDialTimeout = 5
+
+// runOnBastion 通过 SSH 在 Bastion 上执行脚本并返回输出，测试时可替换
+var runOnBastion = func(cfg *config.ClusterConfig, script string) (string, error) {
+	client, err := utils.NewSSHClient(cfg.Bastion.IP, cfg.Bastion.Username, cfg.Bastion.Password, cfg.Bastion.SSHKeyPath)
+	if err != nil {
+		return "", i18n.Errorf("连接 Bastion %s 失败: %w", cfg.Bastion.IP, err)
+	}
+	defer client.Close()
+	return client.RunCommand(script)
+}
+
+// nameCheck 一个需要解析到 expected 的名称
+type nameCheck struct {
+	name     string
+	expected string
+}
+
+// portCheck 一个需要可以连接的端口
+type portCheck struct {
+	name    string
+	address string
+}
+
+// simulationTargets 返回节点启动后安装程序需要的名称和端口：api、api-int、*.apps 解析到负载均衡，
+// Registry 名称解析到 Registry 节点，以及负载均衡的 API、Machine Config Server、Ingress 端口和 Registry 端口
+func simulationTargets(cfg *config.ClusterConfig) ([]nameCheck, []portCheck) {
+	loadBalancer := cfg.GetLoadBalancer()
+	names := []nameCheck{
+		{cfg.APIHostname(), loadBalancer},
+		{cfg.HostFQDN("api-int"), loadBalancer},
+		{"console-openshift-console." + cfg.AppsDomain(), loadBalancer},
+		{cfg.RegistryHostname(), cfg.Registry.IP},
+	}
+
+	haproxy := cfg.Bastion.HAProxy
+	ports := []portCheck{
+		{"API", net.JoinHostPort(loadBalancer, fmt.Sprint(config.APIServerPort))},
+		{"Machine Config Server", net.JoinHostPort(loadBalancer, fmt.Sprint(config.MachineConfigPort))},
+		{"Ingress HTTP", net.JoinHostPort(loadBalancer, fmt.Sprint(haproxy.GetHTTPPort()))},
+		{"Ingress HTTPS", net.JoinHostPort(loadBalancer, fmt.Sprint(haproxy.GetHTTPSPort()))},
+	}
+	if cfg.IsProxyCache() {
+		for _, upstream := range cfg.GetProxyCacheUpstreams() {
+			ports = append(ports, portCheck{i18n.Sprintf("%s 拉取代理", upstream.Source), net.JoinHostPort(cfg.Registry.IP, fmt.Sprint(upstream.Port))})
+		}
+	} else {
+		ports = append(ports, portCheck{"Registry", net.JoinHostPort(cfg.Registry.IP, "8443")})
+	}
+	return names, ports
+}
+
+// simulationScript 生成在 Bastion 上执行的脚本：通过节点使用的每个 DNS 服务器 (dig，bind-utils) 解析名称，
+// 并使用 bash 的 /dev/tcp 连接端口。每项检查输出一行，格式为 "dns <服务器> <名称> <地址...>" 或 "tcp <地址> ok|fail"
+func simulationScript(servers []string, names []nameCheck, ports []portCheck) string {
+	var b strings.Builder
+	b.WriteString("command -v dig >/dev/null || { echo 'missing dig'; exit 0; }\n")
+	for _, server := range servers {
+		for _, check := range names {
+			fmt.Fprintf(&b, "echo dns %s %s $(dig +short +time=3 +tries=1 @%s %s A 2>&1 | tr '\\n' ' ')\n", server, check.name, server, check.name)
+		}
+	}
+	for _, check := range ports {
+		host, port, _ := net.SplitHostPort(check.address)
+		fmt.Fprintf(&b, "if timeout %d bash -c '</dev/tcp/%s/%s' 2>/dev/null; then echo tcp %s ok; else echo tcp %s fail; fi\n",
+			simulateDialTimeout, host, port, check.address, check.address)
+	}
+	return b.String()
+}
+
+// parseSimulation 将脚本输出与期望对比，返回全部问题
+func parseSimulation(output string, names []nameCheck, ports []portCheck) []string {
+	expected := make(map[string]string, len(names))
+	for _, check := range names {
+		expected[check.name] = check.expected
+	}
+	portNames := make(map[string]string, len(ports))
+	for _, check := range ports {
+		portNames[check.address] = check.name
+	}
+
+	var failures []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "missing":
+			return []string{i18n.T("Bastion 上没有 dig 命令\n💡 请安装 bind-utils (dnf install -y bind-utils)")}
+		case len(fields) >= 3 && fields[0] == "dns":
+			server, name, addrs := fields[1], fields[2], fields[3:]
+			if want, ok := expected[name]; ok && !contains(addrs, want) {
+				got := strings.Join(addrs, " ")
+				if got == "" {
+					got = i18n.T("无记录")
+				}
+				failures = append(failures, i18n.Sprintf("%s @%s 解析为 %s，期望 %s", name, server, got, want))
+			}
+		case len(fields) == 3 && fields[0] == "tcp" && fields[2] != "ok":
+			failures = append(failures, i18n.Sprintf("无法连接 %s %s", portNames[fields[1]], fields[1]))
+		}
+	}
+	return failures
+}
+
+// CheckInstallerView 在节点启动前模拟安装程序的视角：通过 SSH 在机器网络中的 Bastion 上，使用节点配置的
+// DNS 服务器解析 api、api-int、*.apps 和 Registry 名称，并连接负载均衡的 API、Machine Config Server、Ingress
+// 端口和 Registry 端口。本机的检查可能因为本机 DNS 或防火墙与节点不同而通过，而安装在等待 API 时才失败。
+// 未部署 Bastion 时跳过检查
+func CheckInstallerView(cfg *config.ClusterConfig) error {
+	if !cfg.BastionEnabled() {
+		return nil
+	}
+	names, ports := simulationTargets(cfg)
+	output, err := runOnBastion(cfg, simulationScript(cfg.GetDNSServers(), names, ports))
+	if err != nil {
+		return clierr.New(clierr.Network, i18n.Errorf("在 Bastion 上执行检查失败: %v\n💡 请确认 [bastion] 的 SSH 用户、密码或密钥正确", err))
+	}
+	if failures := parseSimulation(output, names, ports); len(failures) > 0 {
+		return clierr.New(clierr.Prereq, i18n.Errorf("从机器网络 (Bastion %s) 检查发现 %d 个问题:\n  %s\n💡 请执行 ocpack deploy-bastion 更新 DNS 和 HAProxy 配置，并检查 firewalld 是否放行这些端口",
+			cfg.Bastion.IP, len(failures), strings.Join(failures, "\n  ")))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package gate
+
+import (
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+// fakeBastion 替换 runOnBastion，按 answers (名称 -> 地址) 应答 dig，closed 中的端口无法连接
+func fakeBastion(t *testing.T, answers map[string]string, closed ...string) *string {
+	t.Helper()
+	var script string
+	original := runOnBastion
+	runOnBastion = func(cfg *config.ClusterConfig, s string) (string, error) {
+		script = s
+		var out []string
+		for _, line := range strings.Split(s, "\n") {
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) > 3 && fields[0] == "echo" && fields[1] == "dns":
+				out = append(out, strings.Join([]string{"dns", fields[2], fields[3], answers[fields[3]]}, " "))
+			case len(fields) > 0 && fields[0] == "if":
+				address := fields[len(fields)-3]
+				status := "ok"
+				for _, port := range closed {
+					if address == port {
+						status = "fail"
+					}
+				}
+				out = append(out, "tcp "+address+" "+status)
+			}
+		}
+		return strings.Join(out, "\n"), nil
+	}
+	t.Cleanup(func() { runOnBastion = original })
+	return &script
+}
+
+func TestCheckInstallerView(t *testing.T) {
+	cfg := testConfig()
+	answers := map[string]string{
+		"api.demo.example.com":                            "192.168.1.2",
+		"api-int.demo.example.com":                        "192.168.1.2",
+		"console-openshift-console.apps.demo.example.com": "192.168.1.2",
+		"registry.demo.example.com":                       "192.168.1.3",
+	}
+	script := fakeBastion(t, answers)
+	if err := CheckInstallerView(cfg); err != nil {
+		t.Fatalf("CheckInstallerView() error = %v", err)
+	}
+	for _, want := range []string{"@192.168.1.2 api-int.demo.example.com", "/dev/tcp/192.168.1.2/22623", "/dev/tcp/192.168.1.3/8443"} {
+		if !strings.Contains(*script, want) {
+			t.Errorf("script does not contain %q:\n%s", want, *script)
+		}
+	}
+
+	delete(answers, "api-int.demo.example.com")
+	fakeBastion(t, answers, "192.168.1.2:6443")
+	err := CheckInstallerView(cfg)
+	if err == nil {
+		t.Fatal("CheckInstallerView() expected an error")
+	}
+	for _, want := range []string{"api-int.demo.example.com @192.168.1.2 解析为 无记录", "无法连接 API 192.168.1.2:6443", "2 个问题"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	cfg.Bastion.IP = ""
+	if err := CheckInstallerView(cfg); err != nil {
+		t.Errorf("CheckInstallerView() without bastion error = %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package hooks 执行 config.toml 中 [hooks] 配置的阶段钩子，便于在流水线各阶段之间
+// 接入工单、镜像扫描、人工审批等站点自定义步骤。
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+// Shell 执行钩子命令使用的 shell，钩子可以是脚本路径，也可以是带参数的命令行
+const Shell = "/bin/sh"
+
+// Run 依次执行阶段 stage 在 phase (config.HookPre 或 config.HookPost) 时机的钩子。
+// 钩子在集群目录中执行，任一钩子失败时停止并返回错误；ctx 被取消或超时时终止正在执行的钩子
+func Run(ctx context.Context, r runner.CommandRunner, cfg *config.ClusterConfig, clusterName, clusterDir, phase, stage string) error {
+	hooks := cfg.GetHooks(phase, stage)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	r = runner.WithContext(ctx, r)
+	key := config.HookKey(phase, stage)
+	env := Env(cfg, clusterName, clusterDir, phase, stage)
+	for i, hook := range hooks {
+		fmt.Printf("🪝  执行钩子 %s (%d/%d): %s\n", key, i+1, len(hooks), hook)
+		cmd := runner.Command{
+			Name:   Shell,
+			Args:   []string{"-c", hook},
+			Dir:    clusterDir,
+			Env:    env,
+			Stream: true,
+		}
+		if _, err := r.Run(cmd); err != nil {
+			return fmt.Errorf("钩子 %s 执行失败 (%s): %w", key, hook, err)
+		}
+	}
+	return nil
+}
+
+// Env 返回传递给钩子的集群上下文环境变量
+func Env(cfg *config.ClusterConfig, clusterName, clusterDir, phase, stage string) []string {
+	env := []string{
+		"OCPACK_STAGE=" + stage,
+		"OCPACK_HOOK=" + config.HookKey(phase, stage),
+		"OCPACK_CLUSTER_NAME=" + clusterName,
+		"OCPACK_CLUSTER_DIR=" + clusterDir,
+		"OCPACK_CONFIG=" + filepath.Join(clusterDir, "config.toml"),
+		"OCPACK_CLUSTER_DOMAIN=" + cfg.ClusterInfo.Domain,
+		"OCPACK_OPENSHIFT_VERSION=" + cfg.ClusterInfo.OpenShiftVersion,
+		"OCPACK_BASTION_IP=" + cfg.Bastion.IP,
+		"OCPACK_REGISTRY_IP=" + cfg.Registry.IP,
+		"OCPACK_REGISTRY_HOST=" + cfg.GetRegistryHost(),
+		"OCPACK_DNS_SERVERS=" + strings.Join(cfg.GetDNSServers(), ","),
+		"OCPACK_LOAD_BALANCER=" + cfg.GetLoadBalancer(),
+	}
+	if kubeconfigPath, err := kubeconfig.Find(clusterDir); err == nil {
+		env = append(env, "OCPACK_KUBECONFIG="+kubeconfigPath)
+	}
+	return env
+}
@@ -0,0 +1,90 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func TestRun(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Hooks = map[string][]string{
+		"post_load_image": {"./scripts/notify.sh", "echo done"},
+	}
+	clusterDir := t.TempDir()
+
+	fake := &runner.Fake{}
+
+	if err := Run(context.Background(), fake, cfg, "demo", clusterDir, config.HookPre, "load_image"); err != nil {
+		t.Fatalf("Run(pre) error = %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Fatalf("unexpected calls for stage without hooks: %v", fake.CommandLines())
+	}
+
+	if err := Run(context.Background(), fake, cfg, "demo", clusterDir, config.HookPost, "load_image"); err != nil {
+		t.Fatalf("Run(post) error = %v", err)
+	}
+	calls := fake.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %v", fake.CommandLines())
+	}
+	if calls[0].Name != Shell || strings.Join(calls[0].Args, " ") != "-c ./scripts/notify.sh" || calls[0].Dir != clusterDir {
+		t.Errorf("unexpected command: %+v", calls[0])
+	}
+
+	env := strings.Join(calls[0].Env, "\n")
+	for _, expected := range []string{
+		"OCPACK_STAGE=load_image",
+		"OCPACK_HOOK=post_load_image",
+		"OCPACK_CLUSTER_NAME=demo",
+		"OCPACK_CLUSTER_DIR=" + clusterDir,
+		"OCPACK_REGISTRY_HOST=registry.demo.example.com:8443",
+	} {
+		if !strings.Contains(env, expected) {
+			t.Errorf("hook env missing %q:\n%s", expected, env)
+		}
+	}
+	if strings.Contains(env, "OCPACK_KUBECONFIG=") {
+		t.Errorf("OCPACK_KUBECONFIG should not be set before installation:\n%s", env)
+	}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Hooks = map[string][]string{
+		"pre_deploy_registry": {"./scripts/approve.sh", "echo never"},
+	}
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return nil, errors.New("exit status 1")
+	}}
+
+	err := Run(context.Background(), fake, cfg, "demo", t.TempDir(), config.HookPre, "deploy_registry")
+	if err == nil || !strings.Contains(err.Error(), "pre_deploy_registry") {
+		t.Fatalf("expected hook failure, got %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 1 {
+		t.Errorf("expected hooks to stop after failure, got %v", fake.CommandLines())
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Hooks = map[string][]string{"pre_save_image": {"./scripts/approve.sh"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fake := &runner.Fake{}
+	err := Run(ctx, fake, cfg, "demo", t.TempDir(), config.HookPre, "save_image")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("hook ran after cancel: %v", fake.CommandLines())
+	}
+}
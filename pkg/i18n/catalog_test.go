@@ -0,0 +1,224 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// localizedDirs 输出已本地化的包，其中的消息必须都有英文译文
+var localizedDirs = []string{
+	"../../cmd/ocpack/cmd",
+	"../agentinstall",
+	"../clusterlock",
+	"../config",
+	"../deploy",
+	"../download",
+	"../gate",
+	"../iso",
+	"../loadimage",
+	"../mirror/wrapper",
+	"../ocpack",
+	"../pxe",
+	"../storage",
+}
+
+// translatedFuncs 参数会被翻译的本包函数
+var translatedFuncs = map[string]bool{
+	"T": true, "Sprintf": true, "Errorf": true, "Fprintf": true, "Printf": true,
+	"Println": true, "Fprintln": true, "Print": true, "Fprint": true, "Sprint": true,
+}
+
+// forwardingFuncs 将格式字符串转发给本包函数的方法，如 ocpack.Client.printf，第一个参数需要翻译
+var forwardingFuncs = map[string]bool{"printf": true}
+
+// flagFuncs 定义命令行参数的 pflag 方法，最后一个参数为用法说明
+var flagFuncs = map[string]bool{
+	"String": true, "StringP": true, "StringVar": true, "StringVarP": true,
+	"Bool": true, "BoolP": true, "BoolVar": true, "BoolVarP": true,
+	"Int": true, "IntP": true, "IntVar": true, "IntVarP": true, "Int64": true, "Int64Var": true,
+	"Duration": true, "DurationVar": true, "DurationP": true, "DurationVarP": true,
+	"StringSlice": true, "StringSliceP": true, "StringSliceVar": true, "StringSliceVarP": true,
+	"StringArray": true, "StringArrayP": true, "StringArrayVar": true, "StringArrayVarP": true,
+	"IntSlice": true, "Float64": true, "Float64Var": true, "Uint": true, "Var": true, "VarP": true,
+}
+
+// hasHan 返回字符串是否包含中文
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue 返回字符串字面量或字面量拼接的值，consts 为同一包中的字符串常量
+func stringValue(expr ast.Expr, consts map[string]string) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X, consts)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y, consts)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X, consts)
+	case *ast.Ident:
+		s, ok := consts[e.Name]
+		return s, ok
+	}
+	return "", false
+}
+
+// sourceMessages 返回 dir 中需要翻译的消息及其位置: 本包函数的字符串参数、
+// cobra 命令的 Use 参数部分、Short、Long 以及命令行参数的用法说明
+func sourceMessages(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	consts := map[string]string{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, name := range value.Names {
+					if i < len(value.Values) {
+						if s, ok := stringValue(value.Values[i], consts); ok {
+							consts[name.Name] = s
+						}
+					}
+				}
+			}
+		}
+	}
+
+	messages := map[string]string{}
+	add := func(expr ast.Expr) {
+		if s, ok := stringValue(expr, consts); ok && hasHan(s) {
+			messages[s] = fset.Position(expr.Pos()).String()
+		}
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == "i18n" && translatedFuncs[sel.Sel.Name] {
+					for _, arg := range node.Args {
+						add(arg)
+					}
+				}
+				if forwardingFuncs[sel.Sel.Name] && len(node.Args) > 0 {
+					add(node.Args[0])
+				}
+				if flagFuncs[sel.Sel.Name] && len(node.Args) > 0 {
+					if inner, ok := sel.X.(*ast.CallExpr); ok {
+						if s, ok := inner.Fun.(*ast.SelectorExpr); ok && strings.HasSuffix(s.Sel.Name, "Flags") {
+							add(node.Args[len(node.Args)-1])
+						}
+					}
+				}
+			case *ast.KeyValueExpr:
+				key, ok := node.Key.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				switch key.Name {
+				case "Short", "Long":
+					add(node.Value)
+				case "Use":
+					if s, ok := stringValue(node.Value, consts); ok {
+						if _, args, found := strings.Cut(s, " "); found && hasHan(args) {
+							messages[args] = fset.Position(node.Pos()).String()
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return messages
+}
+
+func TestCatalogCoversSource(t *testing.T) {
+	var missing []string
+	for _, dir := range localizedDirs {
+		for message, pos := range sourceMessages(t, dir) {
+			if _, ok := catalog[message]; !ok {
+				missing = append(missing, pos+": "+strconv.Quote(message))
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		if path := os.Getenv("I18N_MISSING"); path != "" {
+			os.WriteFile(path, []byte(strings.Join(missing, "\n")+"\n"), 0644)
+		}
+		t.Errorf("%d messages have no English translation:\n%s", len(missing), strings.Join(missing, "\n"))
+	}
+}
+
+func TestCatalogFormatVerbs(t *testing.T) {
+	for message, translated := range catalog {
+		if verbs(message) != verbs(translated) {
+			t.Errorf("translation of %q uses verbs %q, source uses %q", message, verbs(translated), verbs(message))
+		}
+	}
+}
+
+// verbs 返回格式字符串中按顺序出现的格式化动词
+func verbs(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) {
+			b.WriteString(format[i : j+1])
+			b.WriteByte(' ')
+		}
+		i = j
+	}
+	return b.String()
+}
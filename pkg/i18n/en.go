@@ -0,0 +1,2477 @@
+package i18n
+
+// catalog 英文译文，键为源代码中的中文消息。新增或修改消息时需要同时更新这里，
+// TestCatalogCoversSource 检查已本地化的包中的消息都有译文
+var catalog = map[string]string{
+	// cmd/ocpack/cmd/add_worker.go
+	"集群安装完成后添加 worker 节点": "Add a worker node after the cluster is installed",
+	`add-worker 命令用于在集群安装完成后扩容 worker 节点。
+
+此命令将执行以下操作：
+1. 将新节点追加到 config.toml 的 [[cluster.worker]] 中
+2. 生成 add-worker/<节点名称>/nodes-config.yaml
+3. 使用 oc adm node-image create 基于集群现有的 ignition 和 CA 生成节点 ISO (或 PXE 文件)
+4. 打印 CSR 批准步骤，或在指定 --approve-csr 时通过 kubeconfig 自动批准
+
+注意: 在运行此命令之前，请确保：
+- 集群已安装完成，installation/ignition/auth/kubeconfig 存在
+- PATH 中的 oc 版本为 4.17 及以上
+
+使用方式:
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32 --pxe --approve-csr`: `add-worker scales out worker nodes after the cluster has been installed.
+
+This command will:
+1. Append the new node to [[cluster.worker]] in config.toml
+2. Generate add-worker/<node-name>/nodes-config.yaml
+3. Use oc adm node-image create to build a node ISO (or PXE files) from the cluster's existing ignition and CA
+4. Print the CSR approval steps, or approve the CSRs through the kubeconfig when --approve-csr is given
+
+Note: before running this command, make sure that:
+- The cluster is installed and installation/ignition/auth/kubeconfig exists
+- The oc in PATH is version 4.17 or later
+
+Usage:
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32
+  ocpack add-worker demo --name worker-2 --ip 192.168.1.32 --mac 52:54:00:00:00:32 --pxe --approve-csr`,
+	"添加 worker 节点失败: %v":         "failed to add worker node: %v",
+	"🎉 worker 节点 %s 处理完成!\n":     "🎉 Worker node %s done!\n",
+	"新 worker 节点的主机名 (必填)":       "Hostname of the new worker node (required)",
+	"新 worker 节点的 IP 地址 (必填)":    "IP address of the new worker node (required)",
+	"新 worker 节点的 MAC 地址 (必填)":   "MAC address of the new worker node (required)",
+	"生成 PXE 启动文件而不是 ISO":         "Generate PXE boot files instead of an ISO",
+	"通过 kubeconfig 自动批准新节点的 CSR": "Approve the new node's CSRs through the kubeconfig",
+
+	// cmd/ocpack/cmd/clean_remote.go
+	"清理 Bastion 上积累的历史 PXE 启动文件": "Clean up historical PXE boot files accumulated on the Bastion",
+	`setup-pxe 每次上传新的 PXE 文件时，Bastion 上的上传脚本将上一版本移动到
+/var/www/html/pxe/.history/<集群名称>/<上传时间>，便于需要时回退。上传完成后自动清理，
+只保留最新的 bastion.keep_boot_artifacts 个版本 (默认 2)。
+
+clean-remote 通过 SSH 列出并删除超出保留数量的历史版本，当前正在提供服务的
+/var/www/html/pxe/<集群名称> 始终保留。建议先使用 --dry-run 查看将要删除的内容。
+
+使用方式:
+  ocpack clean-remote demo --dry-run
+  ocpack clean-remote demo --keep 0`: `Each time setup-pxe uploads new PXE files, the upload script on the Bastion moves the previous version to
+/var/www/html/pxe/.history/<cluster-name>/<upload-time> so it can be restored if needed. Old versions are pruned after
+each upload, keeping only the latest bastion.keep_boot_artifacts versions (default 2).
+
+clean-remote lists and deletes historical versions beyond the retention count over SSH. The currently served
+/var/www/html/pxe/<cluster-name> is always kept. Use --dry-run first to see what will be deleted.
+
+Usage:
+  ocpack clean-remote demo --dry-run
+  ocpack clean-remote demo --keep 0`,
+	"bastion.enabled = false，PXE 文件不在 Bastion 上，请在站点的 PXE 资源服务器上自行清理": "bastion.enabled = false, the PXE files are not on a Bastion; clean them up on the site's PXE asset server",
+	"✅ %s 上没有需要清理的历史 PXE 文件 (保留最新 %d 个版本)\n":                          "✅ No historical PXE files to clean up on %s (keeping the latest %d versions)\n",
+	"💡 将清理 %d 个历史版本，可释放 %s (--dry-run 未删除任何文件)\n":                     "💡 %d historical versions would be removed, freeing %s (--dry-run deleted nothing)\n",
+	"✅ 已清理 %d 个历史版本，释放 %s\n":                                          "✅ Removed %d historical versions, freed %s\n",
+	"保留最新的历史版本数量，默认为 bastion.keep_boot_artifacts":                     "Number of historical versions to keep, defaults to bastion.keep_boot_artifacts",
+
+	// cmd/ocpack/cmd/clean_workspace.go
+	"清理 oc-mirror 工作目录中的旧结果、签名和日志": "Clean up old results, signatures and logs in the oc-mirror workspace",
+	`清理多次执行 save-image/load-image 后在 oc-mirror 工作目录中积累的旧文件，并报告释放的空间。
+
+清理内容:
+  - results-* 目录: 保留最新的 --keep 个非空目录，空目录全部清理
+  - images/working-dir/signatures: 版本不在当前 release 范围内的签名
+  - images/working-dir/logs: 每类日志保留最新的 --keep 个
+
+generate-iso 和 day2 依赖的最新 results 目录、cluster-resources、hold-release、
+hold-operator 和 dry-run 目录始终保留。镜像缓存请使用 clean-cache 清理。
+
+使用方式:
+  ocpack clean-workspace demo --dry-run
+  ocpack clean-workspace demo --keep 2`: `Cleans up old files that accumulate in the oc-mirror workspace after repeated save-image/load-image runs and reports the space freed.
+
+What is cleaned:
+  - results-* directories: the latest --keep non-empty directories are kept, empty ones are always removed
+  - images/working-dir/signatures: signatures for versions outside the current release range
+  - images/working-dir/logs: the latest --keep logs of each kind are kept
+
+The latest results directory, cluster-resources, hold-release, hold-operator and dry-run directories,
+which generate-iso and day2 depend on, are always kept. Use clean-cache to clean the image cache.
+
+Usage:
+  ocpack clean-workspace demo --dry-run
+  ocpack clean-workspace demo --keep 2`,
+	"✅ 工作目录中没有需要清理的内容":                        "✅ Nothing to clean up in the workspace",
+	"💡 将清理 %d 项，可释放 %s (--dry-run 未删除任何文件)\n": "💡 %d items would be removed, freeing %s (--dry-run deleted nothing)\n",
+	"✅ 已清理 %d 项，释放 %s\n":                      "✅ Removed %d items, freed %s\n",
+	"保留最新的 results 目录和每类日志的数量":                "Number of results directories and logs of each kind to keep",
+	"只列出将要清理的内容，不删除":                          "Only list what would be removed, do not delete",
+
+	// cmd/ocpack/cmd/day2.go
+	"[集群名称] [目录]": "[cluster-name] [directory]",
+	"将目录中的 YAML 清单 (NNCP、MachineConfig、Tuned 等) 应用到集群": "Apply the YAML manifests in a directory (NNCP, MachineConfig, Tuned, ...) to the cluster",
+	`apply-bundle 命令读取目录中的 *.yaml 和 *.yml 文件 (按文件名顺序，支持多文档和 kind: List)，
+使用 server-side apply 将其中的资源应用到集群，并等待资源就绪。
+
+应用顺序:
+1. Namespace、CustomResourceDefinition (等待 CRD 变为 Established)
+2. RBAC、ConfigMap、Secret、OperatorGroup、Subscription 及其他资源
+3. MachineConfigPool、MachineConfig、KubeletConfig、ContainerRuntimeConfig、Tuned、PerformanceProfile
+4. NodeNetworkConfigurationPolicy
+
+等待与校验:
+- MachineConfig 等触发 MachineConfigPool 渲染新配置时，等待所有节点完成更新 (节点会依次重启)
+- NodeNetworkConfigurationPolicy 等待 Available 条件
+- 其他资源确认已存在于集群中
+
+使用方式:
+  ocpack day2 apply-bundle demo ./manifests
+  ocpack day2 apply-bundle demo ./manifests --dry-run
+  ocpack day2 apply-bundle demo ./manifests --timeout 90m`: `apply-bundle reads the *.yaml and *.yml files in a directory (in file name order, multi-document files and kind: List
+are supported), applies the resources to the cluster with server-side apply and waits for them to become ready.
+
+Apply order:
+1. Namespace, CustomResourceDefinition (waits for CRDs to become Established)
+2. RBAC, ConfigMap, Secret, OperatorGroup, Subscription and other resources
+3. MachineConfigPool, MachineConfig, KubeletConfig, ContainerRuntimeConfig, Tuned, PerformanceProfile
+4. NodeNetworkConfigurationPolicy
+
+Waiting and verification:
+- When a MachineConfig or similar makes a MachineConfigPool render a new config, waits for all nodes to update (nodes reboot one by one)
+- NodeNetworkConfigurationPolicy waits for the Available condition
+- Other resources are checked to exist in the cluster
+
+Usage:
+  ocpack day2 apply-bundle demo ./manifests
+  ocpack day2 apply-bundle demo ./manifests --dry-run
+  ocpack day2 apply-bundle demo ./manifests --timeout 90m`,
+	"清单目录不存在: %s": "manifest directory does not exist: %s",
+	"应用清单失败: %v":  "failed to apply manifests: %v",
+	"🎉 清单应用完成!":   "🎉 Manifests applied!",
+	"将 OpenShift Virtualization 的虚拟机启动源指向私有仓库": "Point OpenShift Virtualization VM boot sources to the private registry",
+	`cnv-boot-sources 命令为 [save_image] cnv_boot_sources 镜像的启动源生成 HyperConverged 清单，
+以与内置模板同名的 dataImportCronTemplates 覆盖其镜像地址，使离线集群中的 DataImportCron
+从私有仓库导入 RHEL、CentOS Stream 和 Fedora 虚拟机磁盘并更新对应的 DataSource。
+
+清单生成到 <集群名称>/day2/cnv-boot-sources，随后按 apply-bundle 的流程应用到集群。
+执行前需要已安装 OpenShift Virtualization 并创建 HyperConverged。
+
+使用方式:
+  ocpack day2 cnv-boot-sources demo --render-only
+  ocpack day2 cnv-boot-sources demo --dry-run
+  ocpack day2 cnv-boot-sources demo`: `cnv-boot-sources generates a HyperConverged manifest for the boot sources of the [save_image] cnv_boot_sources images.
+dataImportCronTemplates with the same names as the built-in templates override their image addresses, so the
+DataImportCrons in a disconnected cluster import RHEL, CentOS Stream and Fedora VM disks from the private registry
+and update the matching DataSources.
+
+Manifests are generated into <cluster-name>/day2/cnv-boot-sources and then applied to the cluster like apply-bundle.
+OpenShift Virtualization must be installed and a HyperConverged created first.
+
+Usage:
+  ocpack day2 cnv-boot-sources demo --render-only
+  ocpack day2 cnv-boot-sources demo --dry-run
+  ocpack day2 cnv-boot-sources demo`,
+	"集群安装完成后的 Day2 配置操作": "Day 2 configuration after the cluster is installed",
+	"✅ 启动源清单已生成: %s\n":   "✅ Boot source manifests generated: %s\n",
+	`day2 命令用于在集群安装完成后，将集群配置为使用离线环境中的私有镜像仓库资源。
+
+使用方式:
+  ocpack day2 operatorhub demo
+  ocpack day2 update-service demo
+  ocpack day2 apply-bundle demo ./manifests`: `day2 configures an installed cluster to use the private registry resources of the disconnected environment.
+
+Usage:
+  ocpack day2 operatorhub demo
+  ocpack day2 update-service demo
+  ocpack day2 apply-bundle demo ./manifests`,
+	"应用启动源清单失败: %v":                                         "failed to apply boot source manifests: %v",
+	"🎉 OpenShift Virtualization 启动源配置完成!":                   "🎉 OpenShift Virtualization boot sources configured!",
+	"为 [[cluster.compute_pool]] 中的节点设置角色标签、labels 和 taints": "Set role labels, labels and taints on the nodes in [[cluster.compute_pool]]",
+	`compute-pools 命令为 [[cluster.compute_pool]] 中每个节点池的 worker 节点设置
+node-role.kubernetes.io/<节点池> 角色标签以及配置的 labels 和 taints，节点随后加入安装时生成的
+同名 MachineConfigPool。已存在的标签和污点会被覆盖，可重复执行。
+
+ocpack mon 在安装完成后会自动执行一次；安装后修改了节点池的 labels 或 taints，或自动执行失败时，
+使用该命令重新应用。
+
+使用方式:
+  ocpack day2 compute-pools demo`: `compute-pools sets the node-role.kubernetes.io/<pool> role label and the configured labels and taints on the
+worker nodes of each pool in [[cluster.compute_pool]]; the nodes then join the MachineConfigPool of the same name
+generated at install time. Existing labels and taints are overwritten, so it is safe to run repeatedly.
+
+ocpack mon runs it once after the installation completes. Use this command to apply again after changing a pool's
+labels or taints, or when the automatic run failed.
+
+Usage:
+  ocpack day2 compute-pools demo`,
+	"ℹ️  未配置 [[cluster.compute_pool]]，无需设置":       "ℹ️  No [[cluster.compute_pool]] configured, nothing to do",
+	"🎉 计算节点池设置完成!":                                "🎉 Compute pools configured!",
+	"安装 [save_image] presets 中的预置组件 (GitOps、ACM)": "Install the preset components in [save_image] presets (GitOps, ACM)",
+	`presets 命令为 [save_image] presets 中的预置组件生成 Namespace、OperatorGroup 和 Subscription 清单，
+Subscription 订阅 day2 operatorhub 在集群中创建的私有仓库 CatalogSource，使用目录中的默认通道。
+
+支持的预置组件:
+  gitops  OpenShift GitOps (openshift-gitops-operator，安装到 openshift-gitops-operator)
+  acm     Advanced Cluster Management (advanced-cluster-management 及其依赖的 multicluster-engine，
+          安装到 open-cluster-management)
+
+清单生成到 <集群名称>/day2/presets，随后按 apply-bundle 的流程应用到集群。
+执行前需要已完成 save-image、load-image 和 day2 operatorhub。
+
+使用方式:
+  ocpack day2 presets demo --render-only
+  ocpack day2 presets demo --dry-run
+  ocpack day2 presets demo`: `presets generates Namespace, OperatorGroup and Subscription manifests for the preset components in [save_image] presets.
+The Subscriptions use the private registry CatalogSource created by day2 operatorhub and the catalog's default channel.
+
+Supported presets:
+  gitops  OpenShift GitOps (openshift-gitops-operator, installed into openshift-gitops-operator)
+  acm     Advanced Cluster Management (advanced-cluster-management and the multicluster-engine it depends on,
+          installed into open-cluster-management)
+
+Manifests are generated into <cluster-name>/day2/presets and then applied to the cluster like apply-bundle.
+save-image, load-image and day2 operatorhub must have been run first.
+
+Usage:
+  ocpack day2 presets demo --render-only
+  ocpack day2 presets demo --dry-run
+  ocpack day2 presets demo`,
+	"✅ 预置组件清单已生成: %s\n":                                           "✅ Preset manifests generated: %s\n",
+	"💡 可使用 ocpack day2 apply-bundle 应用，或去掉 --render-only 重新执行":    "💡 Apply them with ocpack day2 apply-bundle, or run again without --render-only",
+	"应用预置组件清单失败: %v":                                              "failed to apply preset manifests: %v",
+	"🎉 预置组件的 Subscription 已创建，可使用 oc get csv -A 查看 Operator 安装进度": "🎉 The preset Subscriptions have been created, use oc get csv -A to follow the operator installation",
+	"恢复 ocpack 修改前的 OperatorHub 配置，并删除 ocpack 应用的 CatalogSource":  "Restore the OperatorHub configuration from before ocpack changed it and delete the CatalogSources ocpack applied",
+	"应用后不等待资源就绪":                                                  "Do not wait for resources to become ready after applying",
+	"等待资源就绪的超时时间":                                                 "Timeout for resources to become ready",
+	"配置 OperatorHub 使用私有镜像仓库中的 CatalogSource":                     "Configure OperatorHub to use the CatalogSources in the private registry",
+	"只生成清单，不应用到集群":                                                "Only generate the manifests, do not apply them to the cluster",
+	"使用 --dry-run=server 校验清单，不修改集群":                              "Validate the manifests with --dry-run=server without changing the cluster",
+	`operatorhub 命令禁用默认的在线 catalog sources，并将 oc-mirror 生成的 CatalogSource 应用到集群。
+
+config.toml 中的每个 Operator 目录 ([save_image] operator_catalog 或 [[save_image.operator_catalogs]])
+都会生成一个独立的 CatalogSource，名称和显示名称可通过 catalog_source_name 和 display_name 设置。
+
+修改前的 OperatorHub 配置记录在集群目录的 .ocpack-operatorhub.json 中。任一步骤失败时自动回滚：
+删除本次应用的 CatalogSource 并恢复默认的 catalog sources，避免集群中没有可用的 catalog。
+使用 --rollback 可以在配置成功后手动撤销。
+
+使用方式:
+  ocpack day2 operatorhub demo
+  ocpack day2 operatorhub demo --rollback`: `operatorhub disables the default online catalog sources and applies the CatalogSources generated by oc-mirror to the cluster.
+
+Every operator catalog in config.toml ([save_image] operator_catalog or [[save_image.operator_catalogs]]) gets its own
+CatalogSource; the name and display name can be set with catalog_source_name and display_name.
+
+The OperatorHub configuration from before the change is recorded in .ocpack-operatorhub.json in the cluster directory.
+If any step fails the change is rolled back automatically: the CatalogSources applied in this run are deleted and the
+default catalog sources are restored, so the cluster is never left without a usable catalog.
+Use --rollback to undo a successful configuration manually.
+
+Usage:
+  ocpack day2 operatorhub demo
+  ocpack day2 operatorhub demo --rollback`,
+	"回滚 OperatorHub 失败: %v":             "failed to roll back OperatorHub: %v",
+	"🎉 OperatorHub 回滚完成!":               "🎉 OperatorHub rolled back!",
+	"配置 OperatorHub 失败: %v":             "failed to configure OperatorHub: %v",
+	"🎉 OperatorHub 配置完成!":               "🎉 OperatorHub configured!",
+	"应用 UpdateService 资源，启用离线环境的集群升级推荐": "Apply the UpdateService resource to enable upgrade recommendations in a disconnected cluster",
+	`update-service 命令将 oc-mirror 生成的 UpdateService 资源应用到集群，
+并将 ClusterVersion 的升级源指向集群内的 OpenShift Update Service (OSUS)。
+
+注意: 在运行此命令之前，请确保：
+- config.toml 中已设置 [save_image] graph = true，并重新执行了 save-image 和 load-image
+- 已在 [save_image] ops 中加入 cincinnati-operator 并通过 OperatorHub 完成安装
+
+隔离网络 (enclave) 中配置了 [save_image] update_url_override 时，不在本集群部署 UpdateService，
+直接将 ClusterVersion 的升级源指向该地址 (如上级网络中的 OSUS)。
+
+使用方式:
+  ocpack day2 update-service demo`: `update-service applies the UpdateService resource generated by oc-mirror to the cluster and points the
+ClusterVersion upstream to the in-cluster OpenShift Update Service (OSUS).
+
+Note: before running this command, make sure that:
+- [save_image] graph = true is set in config.toml and save-image and load-image have been run again
+- cincinnati-operator has been added to [save_image] ops and installed through OperatorHub
+
+When [save_image] update_url_override is configured in an isolated network (enclave), no UpdateService is deployed
+in this cluster and the ClusterVersion upstream points directly to that address (such as an OSUS in the upper network).
+
+Usage:
+  ocpack day2 update-service demo`,
+	"配置 OpenShift Update Service 失败: %v": "failed to configure OpenShift Update Service: %v",
+	"🎉 OpenShift Update Service 配置完成!":   "🎉 OpenShift Update Service configured!",
+
+	// cmd/ocpack/cmd/delete_images.go
+	"💡 确认后移除 --dry-run 执行删除\n":                                           "💡 Remove --dry-run to delete after reviewing\n",
+	"✅ 已从 %s 删除 %s 的镜像，镜像列表: %s\n":                                       "✅ Deleted from %s the images of %s, image list: %s\n",
+	"💡 Registry 的存储空间在其垃圾回收后释放\n":                                        "💡 Registry storage is reclaimed after its garbage collection runs\n",
+	"要删除的 Operator (package 名称)":                                         "Operator to delete (package name)",
+	"版本范围，如 5.8.3、5.6.0..5.7.9、..5.7.9 或 5.6.0..，默认为全部版本":                "Version range, such as 5.8.3, 5.6.0..5.7.9, ..5.7.9 or 5.6.0.., defaults to all versions",
+	"只删除该通道中的版本":                                                         "Only delete versions in this channel",
+	"Operator 所在的目录地址或简称 (redhat、certified 等)，默认为 ops 中包含该 Operator 的目录": "Catalog address or short name (redhat, certified, ...) of the operator, defaults to the catalog in ops that contains it",
+	"只生成待删除的镜像列表，不删除":                                                    "Only generate the list of images to delete, do not delete",
+	"同时对本地缓存执行垃圾回收，释放不再被引用的 blob":                                        "Also garbage collect the local cache to free blobs that are no longer referenced",
+	"从私有仓库删除指定 Operator 版本的镜像":                                           "Delete images of specific operator versions from the private registry",
+	`delete-images 为指定 Operator 的版本范围生成 DeleteImageSetConfiguration，使用内置 oc-mirror 的
+delete 功能从私有仓库删除这些版本的 bundle 镜像和相关镜像，用于弃用 Operator 或旧版本后回收存储空间。
+
+此命令将执行以下操作：
+1. 在 config.toml 的 Operator 目录中查找该 Operator 所在的目录 (可用 --catalog 指定)
+2. 根据 load-image 工作目录中的目录数据解析出版本范围内的 bundle 及其相关镜像，
+   写入 images/working-dir/working-dir/delete/delete-images-<operator>.yaml
+3. 从私有仓库和本地缓存中删除列表中的镜像 (--dry-run 时跳过)
+
+--version 的格式: 5.8.3 (单个版本)、5.6.0..5.7.9、..5.7.9 或 5.6.0.. (均包含两端)，
+不指定时删除该 Operator 的全部版本。建议先使用 --dry-run 检查生成的镜像列表；
+仍需保留的版本共用的相关镜像同样会被删除，删除后请重新执行 load-image 补齐。
+
+使用方式:
+  ocpack delete-images demo --operator cluster-logging --version ..5.7.9 --dry-run
+  ocpack delete-images demo --operator cluster-logging --version 5.6.0..5.7.9
+  ocpack delete-images demo --operator odf-operator --catalog certified --channel stable-4.14`: `delete-images generates a DeleteImageSetConfiguration for a version range of an operator and uses the delete feature
+of the built-in oc-mirror to remove the bundle images and related images of those versions from the private registry,
+reclaiming storage after an operator or old versions are retired.
+
+This command will:
+1. Find the catalog in config.toml that contains the operator (can be set with --catalog)
+2. Resolve the bundles in the version range and their related images from the catalog data in the load-image workspace,
+   and write them to images/working-dir/working-dir/delete/delete-images-<operator>.yaml
+3. Delete the listed images from the private registry and the local cache (skipped with --dry-run)
+
+--version format: 5.8.3 (a single version), 5.6.0..5.7.9, ..5.7.9 or 5.6.0.. (both ends inclusive);
+all versions of the operator are deleted when it is not given. Check the generated image list with --dry-run first;
+related images shared with versions you still keep are deleted as well, run load-image again afterwards to restore them.
+
+Usage:
+  ocpack delete-images demo --operator cluster-logging --version ..5.7.9 --dry-run
+  ocpack delete-images demo --operator cluster-logging --version 5.6.0..5.7.9
+  ocpack delete-images demo --operator odf-operator --catalog certified --channel stable-4.14`,
+	"[registry] mirror_mode = \"proxy-cache\" 时镜像由 Registry 拉取代理按需缓存，请在 Registry 中直接清理": "with [registry] mirror_mode = \"proxy-cache\" images are cached on demand by the registry pull-through proxy, clean them up in the registry directly",
+	"⚠️  未指定 --version，将删除 %s 的全部版本\n":                                                  "⚠️  --version not given, all versions of %s will be deleted\n",
+	"🔍 执行就绪检查...":         "🔍 Running readiness checks...",
+	"删除镜像失败: %w":          "failed to delete images: %w",
+	"✅ %s 中没有 %s 匹配的镜像\n": "✅ No images in %s match %s\n",
+	"📋 待删除的镜像列表: %s\n":    "📋 Images to delete: %s\n",
+
+	// cmd/ocpack/cmd/deploy_bastion.go
+	"部署 Bastion 节点": "Deploy the Bastion node",
+	`配置和部署 OpenShift 集群的 Bastion 节点。
+
+这将安装和配置 Bastion 节点所需的所有软件和服务。
+
+[bastion.dns] 可为 named 配置上游转发、按域名转发 (如 IdM)、集群域中的额外 A/CNAME 记录，
+以及是否生成反向解析区域。
+
+使用方式:
+  ocpack deploy-bastion demo`: `Configures and deploys the Bastion node of an OpenShift cluster.
+
+This installs and configures all the software and services the Bastion node needs.
+
+[bastion.dns] configures upstream forwarders, per-domain forwarding (such as IdM), extra A/CNAME records in the cluster
+domain and whether reverse zones are generated for named.
+
+Usage:
+  ocpack deploy-bastion demo`,
+	"Bastion 节点部署成功！": "Bastion node deployed successfully!",
+
+	// cmd/ocpack/cmd/deploy_infra.go
+	"节点部署成功！":                    "Nodes deployed successfully!",
+	"并行部署 Bastion 和 Registry 节点": "Deploy the Bastion and Registry nodes in parallel",
+	`同时部署 Bastion 和 Registry 节点。
+
+两个节点相互独立，并行执行 deploy-bastion 和 deploy-registry 可以将部署时间缩短约一半。
+两者的输出以 [bastion] 和 [registry] 为前缀交错显示，任一节点失败不会中断另一个节点的部署，
+结束后汇总报告全部失败。bastion.enabled = false 时只部署 Registry 节点。
+
+deploy_bastion 和 deploy_registry 的 pre_ 钩子在部署开始前执行，post_ 钩子在对应节点部署成功后执行。
+
+使用方式:
+  ocpack deploy-infra demo`: `Deploys the Bastion and Registry nodes at the same time.
+
+The two nodes are independent, so running deploy-bastion and deploy-registry in parallel roughly halves the deployment time.
+Their output is interleaved with [bastion] and [registry] prefixes; a failure on one node does not interrupt the other,
+and all failures are reported at the end. Only the Registry node is deployed when bastion.enabled = false.
+
+The pre_ hooks of deploy_bastion and deploy_registry run before the deployment starts, and the post_ hooks run after the
+corresponding node has been deployed successfully.
+
+Usage:
+  ocpack deploy-infra demo`,
+	"ℹ️  bastion.enabled = false，跳过 Bastion 部署": "ℹ️  bastion.enabled = false, skipping the Bastion deployment",
+	"开始部署 %d 个节点...\n":                          "Deploying %d nodes...\n",
+	"节点部署失败:\n%v":                               "node deployment failed:\n%v",
+
+	// cmd/ocpack/cmd/deploy_registry.go
+	"部署 Registry 节点": "Deploy the Registry node",
+	`配置和部署 OpenShift 集群的 Registry 节点。
+这将安装和配置 Registry 节点所需的所有软件和服务，包括：
+- 基础软件包安装
+- 容器运行时 (Podman)
+- OpenShift 工具 (oc, kubectl, oc-mirror)
+- Quay 镜像仓库
+
+mirror-registry 离线安装包优先使用下载目录中的文件，不存在时使用 save-image 在
+[save_image] mirror_registry = true 时随镜像归档的副本，重建 Registry 无需访问互联网。
+
+部署前检查 storage_path 的可用空间是否足够存放镜像归档，配置 [registry.storage] device 时
+在该磁盘上创建文件系统并挂载到 storage_path，可选设置 XFS 项目配额。
+
+使用方式:
+  ocpack deploy-registry demo`: `Configures and deploys the Registry node of an OpenShift cluster.
+This installs and configures all the software and services the Registry node needs, including:
+- Base packages
+- Container runtime (Podman)
+- OpenShift tools (oc, kubectl, oc-mirror)
+- Quay registry
+
+The mirror-registry offline installer is taken from the download directory first; when it is missing, the copy archived
+with the images by save-image when [save_image] mirror_registry = true is used, so the Registry can be rebuilt without
+internet access.
+
+Before deploying, checks that storage_path has enough free space for the image archive. When [registry.storage] device is
+configured, a file system is created on that disk and mounted at storage_path, optionally with an XFS project quota.
+
+Usage:
+  ocpack deploy-registry demo`,
+	"Registry 节点部署成功！": "Registry node deployed successfully!",
+
+	// cmd/ocpack/cmd/dns_hosts.go
+	"生成集群的 /etc/hosts 片段和 dnsmasq 配置，并检查名称解析": "Generate the cluster /etc/hosts snippet and dnsmasq configuration, and check name resolution",
+	`站点 DNS 无法将集群域委派给 Bastion 时，在 config.toml 中设置 [bastion.dns] mode = "hosts"，
+deploy-bastion 改为在 Bastion 上部署 dnsmasq: 集群域只根据生成的 hosts 文件应答，*.apps 通配到负载均衡，
+其余查询转发到 [bastion.dns] forwarders。
+
+dns-hosts 将以下文件生成到 <集群名称>/dns:
+  hosts          /etc/hosts 片段 (Bastion、Registry、API、常用 *.apps 路由和节点)，可追加到管理员工作站
+  dnsmasq.conf   Bastion 上 /etc/dnsmasq.d 中的集群配置
+
+--verify 通过节点使用的 DNS 服务器 (即 agent-config.yaml 中 dns-resolver 的服务器) 解析全部名称，
+与 generate-iso 前的就绪检查相同。
+
+使用方式:
+  ocpack dns-hosts demo
+  ocpack dns-hosts demo --verify`: `When the site DNS cannot delegate the cluster domain to the Bastion, set [bastion.dns] mode = "hosts" in config.toml and
+deploy-bastion deploys dnsmasq on the Bastion instead: the cluster domain is answered only from the generated hosts file,
+*.apps is a wildcard to the load balancer, and all other queries are forwarded to the [bastion.dns] forwarders.
+
+dns-hosts generates the following files into <cluster-name>/dns:
+  hosts          /etc/hosts snippet (Bastion, Registry, API, common *.apps routes and nodes), can be appended on admin workstations
+  dnsmasq.conf   cluster configuration for /etc/dnsmasq.d on the Bastion
+
+--verify resolves all names through the DNS servers the nodes use (the dns-resolver servers in agent-config.yaml),
+the same as the readiness check before generate-iso.
+
+Usage:
+  ocpack dns-hosts demo
+  ocpack dns-hosts demo --verify`,
+	"✅ 已生成: %s\n": "✅ Generated: %s\n",
+	"💡 当前 bastion.dns.mode 为 %s，Bastion 使用 named；设置 mode = \"hosts\" 后重新执行 deploy-bastion 改用 dnsmasq\n": `💡 bastion.dns.mode is currently %s and the Bastion uses named; set mode = "hosts" and run deploy-bastion again to switch to dnsmasq
+`,
+	"🔍 通过 DNS 服务器 %v 检查名称解析...\n": "🔍 Checking name resolution through DNS servers %v...\n",
+	"✅ 全部名称解析正确":                  "✅ All names resolve correctly",
+	"通过节点使用的 DNS 服务器检查名称解析":       "Check name resolution through the DNS servers the nodes use",
+
+	// cmd/ocpack/cmd/doctor.go
+	"诊断镜像和认证相关的常见故障": "Diagnose common mirroring and authentication failures",
+	`doctor 命令扫描 oc-mirror 日志 (images/working-dir/logs) 和 --log 指定的文件，
+按已知的故障特征归类错误，并检查本地状态，最后给出针对性的修复建议。
+
+可识别的故障包括: pull-secret 过期、registry.redhat.io 返回 401、私有仓库认证失败、
+x509 证书不被信任、域名解析失败、磁盘空间不足、限流、网络不可达和镜像不存在。
+
+本地检查包括: pull-secret.txt、registry/merged-auth.json、集群目录所在分区的剩余空间，
+以及私有仓库的域名解析、服务状态和证书 (可用 --no-network 跳过)。
+
+发现问题时命令返回非零退出码。
+
+使用方式:
+  ocpack doctor demo
+  ocpack doctor demo --log save-image.log`: `doctor scans the oc-mirror logs (images/working-dir/logs) and the files given with --log, classifies errors by known
+failure patterns, checks the local state and finally gives targeted remediation advice.
+
+Recognized failures: expired pull secret, 401 from registry.redhat.io, private registry authentication failure,
+untrusted x509 certificate, DNS resolution failure, insufficient disk space, rate limiting, unreachable network and
+missing images.
+
+Local checks: pull-secret.txt, registry/merged-auth.json, free space on the partition of the cluster directory, and
+DNS resolution, service status and certificate of the private registry (skip with --no-network).
+
+The command exits non-zero when problems are found.
+
+Usage:
+  ocpack doctor demo
+  ocpack doctor demo --log save-image.log`,
+	"发现 %d 个问题": "found %d problems",
+	"额外扫描的日志文件，如保存下来的命令输出 (可重复指定)": "Additional log file to scan, such as saved command output (repeatable)",
+	"跳过私有仓库的域名解析、连通性和证书检查":         "Skip the DNS, connectivity and certificate checks of the private registry",
+
+	// cmd/ocpack/cmd/download.go
+	"<集群名称>...":            "<cluster-name>...",
+	"下载 OpenShift 安装所需的介质": "Download the media needed to install OpenShift",
+	`下载 OpenShift 安装所需的介质，包括镜像、工具等。
+这些文件将被下载到配置文件中指定的目录。
+
+可以同时指定多个集群，各集群并行下载，输出以集群名称为前缀。
+配置 download.shared = true 的集群共用项目目录下的 shared-downloads/<版本>，
+同版本的集群只下载一次；下载目录加有文件锁，多个 ocpack 进程同时下载也是安全的。
+
+使用方式:
+  ocpack download demo
+  ocpack download c1 c2`: `Downloads the media needed to install OpenShift, including images and tools.
+The files are downloaded into the directory specified in the configuration file.
+
+Several clusters can be given at once; they download in parallel and their output is prefixed with the cluster name.
+Clusters with download.shared = true share shared-downloads/<version> in the project directory, so clusters on the
+same version download only once; the download directory is protected by a file lock, so concurrent ocpack processes are safe.
+
+Usage:
+  ocpack download demo
+  ocpack download c1 c2`,
+	"所有文件下载完成！": "All files downloaded!",
+
+	// cmd/ocpack/cmd/edit.go
+	"❌ 配置验证失败: %s\n":            "❌ Configuration validation failed: %s\n",
+	"❌ 配置验证失败 (%s:%d:%d): %s\n": "❌ Configuration validation failed (%s:%d:%d): %s\n",
+	"编辑集群的 config.toml 并在保存后验证": "Edit the cluster config.toml and validate it after saving",
+	`edit 命令使用 $VISUAL 或 $EDITOR (默认 vi) 打开集群的 config.toml，编辑器退出后立即验证配置。
+
+验证失败时输出错误所在的行和列以及附近的内容，并询问是否重新打开编辑器。vi、vim、nano 和 emacs
+重新打开时直接定位到出错的行。语法错误按 TOML 解析器给出的位置定位，其他验证错误按错误信息中的
+配置项 (如 save_image.local_storage_port) 定位，无法定位时只输出错误信息。
+
+使用方式:
+  ocpack edit demo
+  EDITOR=nano ocpack edit demo`: `edit opens the cluster config.toml with $VISUAL or $EDITOR (vi by default) and validates the configuration as soon as the editor exits.
+
+When validation fails, the line and column of the error and the surrounding content are shown and you are asked whether
+to reopen the editor. vi, vim, nano and emacs reopen directly at the offending line. Syntax errors are located by the
+position reported by the TOML parser, other validation errors by the setting named in the error message (such as
+save_image.local_storage_port); when it cannot be located only the error message is shown.
+
+Usage:
+  ocpack edit demo
+  EDITOR=nano ocpack edit demo`,
+	"配置文件不存在: %s":         "configuration file does not exist: %s",
+	"执行编辑器 %s 失败: %w":     "failed to run editor %s: %w",
+	"是否重新打开编辑器修改? [Y/n] ": "Reopen the editor to fix it? [Y/n] ",
+	"配置验证失败: %w":          "configuration validation failed: %w",
+
+	// cmd/ocpack/cmd/generate_iso.go
+	"指定 RHCOS 基础 ISO 路径 (可选，用于 --unconfigured)": "Path of the RHCOS base ISO (optional, used with --unconfigured)",
+	"强制重新生成，覆盖现有 ISO 文件":                        "Regenerate and overwrite existing ISO files",
+	"跳过 release 镜像和 DNS 就绪检查":                   "Skip the release image and DNS readiness checks",
+	"生成不含集群配置的 ISO 和单独的配置镜像 (late-binding)":     "Generate an ISO without cluster configuration and a separate configuration image (late binding)",
+	"生成 OpenShift 安装 ISO 镜像":                    "Generate the OpenShift installation ISO",
+	`generate-iso 命令用于生成 OpenShift 集群的安装 ISO 镜像。
+
+此命令将执行以下操作：
+1. 验证集群配置和依赖工具
+2. 创建安装目录结构
+3. 生成 install-config.yaml 配置文件
+4. 生成 agent-config.yaml 配置文件
+5. 使用 openshift-install 生成 agent ISO 镜像
+
+生成的文件结构：
+  installation/
+  ├── install-config.yaml
+  ├── agent-config.yaml
+  ├── ignition/
+  │   └── [ignition files]
+  └── iso/
+      └── [generated ISO files]
+
+注意: 在运行此命令之前，请确保：
+- 已运行 'ocpack download' 命令下载必要工具
+- pull-secret.txt 文件存在
+- 集群配置文件已正确填写
+
+使用 --render-only 可只渲染 install-config.yaml 和 agent-config.yaml 并显示
+与现有文件的差异，不执行 openshift-install，便于在变更管控环境中审阅配置。
+
+生成 ISO 之前会检查私有仓库中是否已有 release 镜像，以及集群的 api、api-int 和 *.apps
+DNS 记录是否解析到负载均衡，检查失败时终止并给出修复建议 (可使用 --skip-checks 跳过)。
+
+节点配置了 kernel_args 或 console 时，参数相同的节点共用一个追加了这些内核参数的 ISO 副本
+(<集群名称>-agent-<节点名称>.x86_64.iso，需要 coreos-installer)，其他节点使用默认 ISO。
+
+使用 --unconfigured 生成 late-binding 所需的镜像 (OpenShift 4.14+，需要 coreos-installer):
+  - unconfigured-agent.x86_64.iso: 嵌入 unconfigured ignition 的 RHCOS ISO，不含集群配置，
+    使用同一私有仓库的多个集群可以复用
+  - <集群名称>-agentconfig.noarch.iso: 集群配置镜像，发布到 Bastion 的
+    http://<bastion>:8080/agent/<集群名称>/agentconfig.noarch.iso
+节点从前者启动后通过 BMC 虚拟介质挂载配置镜像，在启动时绑定到集群。
+RHCOS 基础 ISO 默认取自 openshift-install 的缓存，也可以通过 --base-iso 指定。
+
+使用方式:
+  ocpack generate-iso demo
+  ocpack generate-iso demo --render-only
+  ocpack generate-iso demo --unconfigured`: `generate-iso generates the installation ISO of an OpenShift cluster.
+
+This command will:
+1. Validate the cluster configuration and required tools
+2. Create the installation directory structure
+3. Generate install-config.yaml
+4. Generate agent-config.yaml
+5. Generate the agent ISO with openshift-install
+
+Generated files:
+  installation/
+  ├── install-config.yaml
+  ├── agent-config.yaml
+  ├── ignition/
+  │   └── [ignition files]
+  └── iso/
+      └── [generated ISO files]
+
+Note: before running this command, make sure that:
+- 'ocpack download' has been run to download the required tools
+- pull-secret.txt exists
+- The cluster configuration file is filled in correctly
+
+Use --render-only to only render install-config.yaml and agent-config.yaml and show the differences from the existing
+files without running openshift-install, for reviewing the configuration in change-controlled environments.
+
+Before generating the ISO, checks that the release images are in the private registry and that the cluster's api,
+api-int and *.apps DNS records resolve to the load balancer; stops with remediation advice when a check fails
+(skip with --skip-checks).
+
+When nodes configure kernel_args or console, nodes with the same arguments share a copy of the ISO with those kernel
+arguments appended (<cluster-name>-agent-<node-name>.x86_64.iso, requires coreos-installer); other nodes use the default ISO.
+
+Use --unconfigured to generate the images needed for late binding (OpenShift 4.14+, requires coreos-installer):
+  - unconfigured-agent.x86_64.iso: RHCOS ISO with the unconfigured ignition embedded and no cluster configuration,
+    reusable by several clusters that share the same private registry
+  - <cluster-name>-agentconfig.noarch.iso: the cluster configuration image, published on the Bastion at
+    http://<bastion>:8080/agent/<cluster-name>/agentconfig.noarch.iso
+Nodes boot from the former and mount the configuration image through BMC virtual media to bind to the cluster at boot.
+The RHCOS base ISO is taken from the openshift-install cache by default and can be given with --base-iso.
+
+Usage:
+  ocpack generate-iso demo
+  ocpack generate-iso demo --render-only
+  ocpack generate-iso demo --unconfigured`,
+	"ISO 生成完成!":              "ISO generated!",
+	"📁 安装文件位置: %s/\n":        "📁 Installation files: %s/\n",
+	"💿 ISO 文件位置: %s/\n":      "💿 ISO files: %s/\n",
+	"🔧 Ignition 文件位置: %s/\n": "🔧 Ignition files: %s/\n",
+	"指定输出目录 (可选)":            "Output directory (optional)",
+
+	// cmd/ocpack/cmd/inventory.go
+	"导出集群主机清单": "Export the cluster host inventory",
+	`导出集群主机清单，用于交接给机房运维人员。
+
+清单包含 Bastion、Registry 和全部集群节点的 IP、MAC，以及节点配置中可选的
+bmc_address、serial、location 和 console_url。集群已安装时通过 kubeconfig 执行
+oc get nodes 获取每个节点的状态 (Ready、NotReady、NotJoined) 和 kubelet 版本，
+集群中存在但未在配置中的节点以 node 角色列出。
+
+使用方式:
+  ocpack inventory demo
+  ocpack inventory demo --output csv --file demo-inventory.csv
+  ocpack inventory demo -o json --no-discover`: `Exports the cluster host inventory for handover to the data center operations team.
+
+The inventory contains the IP and MAC of the Bastion, the Registry and all cluster nodes, plus the optional bmc_address,
+serial, location and console_url from the node configuration. When the cluster is installed, oc get nodes is run through
+the kubeconfig to get each node's status (Ready, NotReady, NotJoined) and kubelet version; nodes that exist in the
+cluster but not in the configuration are listed with the node role.
+
+Usage:
+  ocpack inventory demo
+  ocpack inventory demo --output csv --file demo-inventory.csv
+  ocpack inventory demo -o json --no-discover`,
+	"创建文件 %s 失败: %v":            "failed to create file %s: %v",
+	"✅ 主机清单已保存到 %s\n":           "✅ Host inventory saved to %s\n",
+	"输出格式: csv、json 或 markdown": "Output format: csv, json or markdown",
+	"保存到文件，默认输出到标准输出":           "Save to a file, defaults to standard output",
+	"不访问集群，只导出配置中的信息":           "Do not contact the cluster, only export the information in the configuration",
+
+	// cmd/ocpack/cmd/kubeconfig.go
+	"🐚 进入集群 %s 的 shell (KUBECONFIG=%s)，输入 exit 退出\n":  "🐚 Entering a shell for cluster %s (KUBECONFIG=%s), type exit to leave\n",
+	"合并到 ~/.kube/config 并切换到该上下文":                     "Merge into ~/.kube/config and switch to that context",
+	"合并时使用的上下文名称 (默认: 集群名称)":                          "Context name used when merging (default: cluster name)",
+	"合并的目标 kubeconfig 文件 (默认: ~/.kube/config)":        "Target kubeconfig file for merging (default: ~/.kube/config)",
+	"输出集群 kubeconfig 的 export 命令，或合并到 ~/.kube/config": "Print the export command for the cluster kubeconfig, or merge it into ~/.kube/config",
+	`kubeconfig 命令用于管理集群安装生成的 kubeconfig。
+
+默认输出 export 命令，可配合 eval 使用；指定 --merge 时将集群凭据合并到
+~/.kube/config (或 --target 指定的文件)，并以 --context 命名上下文 (默认为集群名称)。
+
+使用方式:
+  eval $(ocpack kubeconfig demo)
+  ocpack kubeconfig demo --merge
+  ocpack kubeconfig demo --merge --context demo-admin`: `kubeconfig manages the kubeconfig generated by the cluster installation.
+
+By default it prints an export command to use with eval; with --merge the cluster credentials are merged into
+~/.kube/config (or the file given with --target) under the context named by --context (the cluster name by default).
+
+Usage:
+  eval $(ocpack kubeconfig demo)
+  ocpack kubeconfig demo --merge
+  ocpack kubeconfig demo --merge --context demo-admin`,
+	"合并 kubeconfig 失败: %v":         "failed to merge kubeconfig: %v",
+	"✅ 已将集群 %s 合并到 %s，当前上下文: %s\n": "✅ Merged cluster %s into %s, current context: %s\n",
+	"[集群名称] -- [oc 参数]":            "[cluster-name] -- [oc arguments]",
+	"使用集群 kubeconfig 执行 oc 命令":     "Run oc with the cluster kubeconfig",
+	`oc 命令使用集群安装生成的 kubeconfig 执行 oc，无需手动设置 KUBECONFIG。
+
+使用方式:
+  ocpack oc demo -- get nodes
+  ocpack oc demo -- get clusterversion`: `oc runs oc with the kubeconfig generated by the cluster installation, without setting KUBECONFIG manually.
+
+Usage:
+  ocpack oc demo -- get nodes
+  ocpack oc demo -- get clusterversion`,
+	"启动已设置集群 KUBECONFIG 的子 shell": "Start a subshell with the cluster KUBECONFIG set",
+	`shell 命令启动一个新的 shell ($SHELL，默认 /bin/bash)，其中 KUBECONFIG 已指向集群的
+kubeconfig，可直接执行 oc / kubectl。退出 shell 即恢复原环境。
+
+使用方式:
+  ocpack shell demo`: `shell starts a new shell ($SHELL, /bin/bash by default) with KUBECONFIG pointing to the cluster kubeconfig,
+so oc / kubectl can be used directly. Exit the shell to return to the original environment.
+
+Usage:
+  ocpack shell demo`,
+
+	// cmd/ocpack/cmd/load_image.go
+	"从本地磁盘加载镜像到 mirror registry": "Load images from local disk into the mirror registry",
+	`load-image 命令将已保存到本地磁盘的 OpenShift 镜像加载到 mirror registry 中。
+
+此命令将执行以下操作：
+1. 读取集群配置文件
+2. 使用 s3:// 存储时下载镜像归档，并验证镜像目录是否存在
+3. 验证 save-image 生成的传输清单：各归档的 sha256，配置了 [save_image.signing] 时还验证签名
+   (可使用 --skip-verify 跳过)
+4. 检查 registry 健康状态和认证 (可使用 --skip-checks 跳过)
+5. 配置了 [registry.quay] manage_organizations = true 时通过 Quay API 创建目标组织和仓库，
+   设置仓库可见性和组织配额
+6. 配置了 [scan] enabled = true 时扫描镜像漏洞，未通过 fail_on 阈值则终止
+7. 将镜像推送到 registry
+
+oc-mirror 日志实时输出，每个阶段开始时输出标题。使用 -v/-vv 输出 debug/trace 日志，
+--quiet 只输出错误和最终摘要。
+
+使用 --images-file 只推送 save-image --images-file 保存在镜像存储 adhoc/ 子目录中的镜像，
+跳过 release 和 Operator；启用 [scan] 时也只扫描列表中的镜像。
+
+推送前先查询 registry 中已有的镜像，跳过 digest 相同的镜像，重复执行时只推送变化的内容；
+使用 --full-copy 重新推送全部镜像。
+
+使用 --only release 先只推送 release，保证窗口关闭前已可安装集群，之后再推送 operators 等其余分组；
+生成的 IDMS/ITMS 和 CatalogSource 仍包含全部镜像。[save_image] mirror_order 按组依次推送。
+
+配置了 [save_image] max_cache_size 或 --max-cache-size 时，推送成功后按最近访问时间删除本地缓存中
+最久未使用的数据，使缓存不超过该大小。
+
+注意: 在运行此命令之前，请确保：
+- 已运行 'ocpack save-image' 命令保存镜像
+- Registry 已正确部署并运行
+
+使用方式:
+  ocpack load-image demo
+  ocpack load-image demo --images-file images.txt
+  ocpack load-image demo --full-copy
+  ocpack load-image demo --only release`: `load-image loads the OpenShift images saved to local disk into the mirror registry.
+
+This command will:
+1. Read the cluster configuration file
+2. Download the image archive when s3:// storage is used, and check that the image directory exists
+3. Verify the transfer manifest generated by save-image: the sha256 of each archive, and the signature when
+   [save_image.signing] is configured (skip with --skip-verify)
+4. Check the registry health and authentication (skip with --skip-checks)
+5. When [registry.quay] manage_organizations = true, create the target organizations and repositories through the
+   Quay API and set repository visibility and organization quotas
+6. When [scan] enabled = true, scan the images for vulnerabilities and stop if the fail_on threshold is not met
+7. Push the images to the registry
+
+oc-mirror logs are streamed live with a heading at the start of each phase. Use -v/-vv for debug/trace logs,
+--quiet prints only errors and the final summary.
+
+Use --images-file to push only the images that save-image --images-file saved in the adhoc/ subdirectory of the image
+storage, skipping release and operators; with [scan] enabled only the listed images are scanned.
+
+Before pushing, the images already in the registry are queried and images with the same digest are skipped, so repeated
+runs push only what changed; use --full-copy to push all images again.
+
+Use --only release to push only the release first, so the cluster can be installed before the window closes, and push
+operators and the remaining groups later; the generated IDMS/ITMS and CatalogSources still include all images.
+[save_image] mirror_order pushes the groups one after another.
+
+When [save_image] max_cache_size or --max-cache-size is set, the least recently used data in the local cache is deleted
+after a successful push to keep the cache within that size.
+
+Note: before running this command, make sure that:
+- 'ocpack save-image' has been run to save the images
+- The Registry is deployed and running
+
+Usage:
+  ocpack load-image demo
+  ocpack load-image demo --images-file images.txt
+  ocpack load-image demo --full-copy
+  ocpack load-image demo --only release`,
+	"✅ 干运行完成！实际操作请移除 --dry-run 参数\n":            "✅ Dry run complete! Remove --dry-run to perform the operation\n",
+	"📋 集群资源配置文件已生成在: %s/\n":                     "📋 Cluster resource files generated in: %s/\n",
+	"只显示操作而不实际执行":                               "Only show the operations without executing them",
+	"跳过 [scan] 配置的镜像漏洞扫描":                       "Skip the image vulnerability scan configured in [scan]",
+	"跳过 registry 健康状态和认证检查":                     "Skip the registry health and authentication checks",
+	"跳过传输清单 (transfer-manifest.json) 的签名和校验和验证": "Skip the signature and checksum verification of the transfer manifest (transfer-manifest.json)",
+	"只推送 save-image --images-file 保存的镜像列表中的镜像":  "Only push the images in the image list saved by save-image --images-file",
+
+	// cmd/ocpack/cmd/lock.go
+	"删除集群锁后再执行 (确认持有锁的命令已不再运行时使用)": "Remove the cluster lock before running (use when the command holding the lock is no longer running)",
+	"🔓 已删除集群锁: %s\n":    "🔓 Removed cluster lock: %s\n",
+	"⚠️  释放集群锁失败: %v\n": "⚠️  Failed to release cluster lock: %v\n",
+
+	// cmd/ocpack/cmd/migrate_config.go
+	"将 config.toml 升级到当前的格式版本": "Upgrade config.toml to the current format version",
+	"将旧版本的 config.toml 升级到当前的格式版本 (config_version)。\n\n其他命令读取旧版本的配置时只在内存中升级并给出提示，不修改文件。本命令在升级前将原文件备份到\nconfig.toml.bak-<时间>；只需补充 config_version 时保留原有的注释和格式，其他变更会重新生成文件，\n原有注释不保留，请对照备份检查。\n\n使用方式:\n  ocpack migrate-config demo --dry-run\n  ocpack migrate-config demo": `Upgrades an older config.toml to the current format version (config_version).
+
+Other commands upgrade an older configuration in memory only and print a hint without changing the file. This command first backs up
+the original file to config.toml.bak-<time>; when only config_version has to be added the existing comments and formatting are kept,
+other changes regenerate the file without the original comments, so compare it with the backup.
+
+Usage:
+  ocpack migrate-config demo --dry-run
+  ocpack migrate-config demo`,
+	"升级配置文件失败: %w":                 "failed to upgrade the configuration file: %w",
+	"✅ 配置文件已是当前版本 %d: %s\n":        "✅ The configuration file is already at the current version %d: %s\n",
+	"🔍 配置文件将从版本 %d 升级到版本 %d: %s\n": "🔍 The configuration file would be upgraded from version %d to version %d: %s\n",
+	"🔄 配置文件已从版本 %d 升级到版本 %d: %s\n": "🔄 The configuration file was upgraded from version %d to version %d: %s\n",
+	"   - %s\n": "   - %s\n",
+	"⚠️  文件按升级后的内容重新生成，原有注释和键的顺序不保留": "⚠️  The file was regenerated from the upgraded content; the original comments and key order are not kept",
+	"   原配置文件已备份到: %s\n":             "   The original configuration file was backed up to: %s\n",
+	"只显示变更，不修改文件":                    "Only show the changes without modifying the file",
+
+	// cmd/ocpack/cmd/mirror_output.go
+	"日志级别 (info, debug, trace, error)，-v 和 --quiet 优先": "Log level (info, debug, trace, error), -v and --quiet take precedence",
+	"只输出错误和最终摘要":                                       "Only print errors and the final summary",
+
+	// cmd/ocpack/cmd/mirror_rpms.go
+	"下载 Bastion/Registry/PXE 节点所需的 RPM 软件包并生成离线仓库": "Download the RPM packages needed by the Bastion/Registry/PXE nodes and build an offline repository",
+	`mirror-rpms 命令下载 deploy-bastion、deploy-registry 和 PXE 部署所需的软件包
+(haproxy、bind、podman、httpd 等) 及其全部依赖，并使用 createrepo_c 生成离线 yum 仓库。
+
+仓库保存在下载目录的 rpms/ 中。之后执行部署命令时，playbook 会自动将仓库复制到目标节点，
+并只从该仓库安装软件包，无需目标节点访问外部软件源。
+
+注意: 在运行此命令之前，请确保：
+- 当前主机为联网的 RHEL，且系统版本与目标节点一致 (或在 [rpms] 中设置 release_ver)
+- 已安装 dnf-plugins-core 和 createrepo_c
+- 可在 [rpms] 中设置 repos 限制软件源，或通过 extra_packages 添加额外的软件包
+
+使用方式:
+  ocpack mirror-rpms demo`: `mirror-rpms downloads the packages needed by deploy-bastion, deploy-registry and PXE deployments
+(haproxy, bind, podman, httpd, ...) with all their dependencies, and builds an offline yum repository with createrepo_c.
+
+The repository is stored in rpms/ in the download directory. The deploy commands then copy the repository to the target
+nodes and install packages only from it, so the target nodes do not need access to external package sources.
+
+Note: before running this command, make sure that:
+- The current host is a connected RHEL of the same release as the target nodes (or set release_ver in [rpms])
+- dnf-plugins-core and createrepo_c are installed
+- repos in [rpms] can restrict the package sources, and extra_packages adds additional packages
+
+Usage:
+  ocpack mirror-rpms demo`,
+	"下载 RPM 软件包失败: %v":     "failed to download RPM packages: %v",
+	"✅ 离线 RPM 仓库已生成: %s\n": "✅ Offline RPM repository generated: %s\n",
+
+	// cmd/ocpack/cmd/mon.go
+	"监控集群安装进度": "Monitor the cluster installation progress",
+	`执行 openshift-install agent wait-for install-complete，等待集群安装完成或失败。
+
+使用 generate-iso 复制到 <集群>/installation/ignition 的安装状态，wait-for 的输出追加到其中的
+.openshift_install.log，可以随后执行 ocpack timeline 查看各安装阶段的耗时。
+安装结束的结果记录为 monitor_install 阶段，配置了 [notify] 时发送通知。
+generate-iso 生成的 ignition 已超过 24 小时时先给出警告: 其中的证书已过期，节点无法完成安装，
+需要执行 ocpack regenerate-iso 并使用新 ISO 重新启动节点。
+配置了 [[cluster.compute_pool]] 时，安装完成后为节点池中的节点设置角色标签、labels 和 taints。
+
+使用方式:
+  ocpack mon demo`: `Runs openshift-install agent wait-for install-complete and waits until the cluster installation completes or fails.
+
+The installation state copied by generate-iso to <cluster>/installation/ignition is used and the wait-for output is
+appended to the .openshift_install.log there, so ocpack timeline can show the duration of each installation phase afterwards.
+The result of the installation is recorded as the monitor_install stage, and a notification is sent when [notify] is configured.
+When the ignition generated by generate-iso is older than 24 hours a warning is printed first: its certificates have
+expired and the nodes cannot finish the installation; run ocpack regenerate-iso and reboot the nodes from the new ISO.
+When [[cluster.compute_pool]] is configured, the role labels, labels and taints of the pool nodes are set after the installation.
+
+Usage:
+  ocpack mon demo`,
+	"\n⚠️  警告: %s\n\n":     "\n⚠️  Warning: %s\n\n",
+	"👀 监控集群 %s 的安装进度...\n": "👀 Monitoring the installation of cluster %s...\n",
+	"集群已安装完成，但设置计算节点池失败: %w\n💡 可执行 ocpack day2 compute-pools %s 重试": "the cluster is installed, but setting up the compute pools failed: %w\n💡 Run ocpack day2 compute-pools %s to retry",
+
+	// cmd/ocpack/cmd/new.go
+	"cluster [集群名称]": "cluster [cluster-name]",
+	"创建一个新的集群配置":     "Create a new cluster configuration",
+	"创建一个新的 OpenShift 集群配置目录和初始配置文件。\n\n例如:\n  ocpack new cluster my-cluster": `Creates the configuration directory and initial configuration files of a new OpenShift cluster.
+
+Example:
+  ocpack new cluster my-cluster`,
+	"目前只支持 'ocpack new cluster [集群名称]' 命令": "only 'ocpack new cluster [cluster-name]' is supported",
+	"创建集群目录失败: %v\n":                       "Failed to create the cluster directory: %v\n",
+	"生成配置文件失败: %v\n":                       "Failed to generate the configuration file: %v\n",
+	"创建目录 %s 失败: %v\n":                     "Failed to create directory %s: %v\n",
+	"集群 '%s' 初始化成功！\n":                     "Cluster '%s' initialized!\n",
+	"请编辑配置文件: %s\n":                        "Edit the configuration file: %s\n",
+
+	// cmd/ocpack/cmd/notify.go
+	"⚠️  发送阶段通知失败: %v\n": "⚠️  Failed to send the stage notification: %v\n",
+	"📣 已发送阶段 %s 的通知\n":   "📣 Sent the notification for stage %s\n",
+
+	// cmd/ocpack/cmd/plan.go
+	"创建镜像服务失败: %v":                         "failed to create the image service: %v",
+	"🔍 正在解析镜像集 (dry-run): %s\n":            "🔍 Resolving the image set (dry-run): %s\n",
+	"解析所选 Operator 的依赖，列出需要加入 ops 的依赖包和通道": "Resolve the dependencies of the selected operators and list the packages and channels to add to ops",
+	`operators 命令从 Operator 目录的 File-Based Catalog 离线解析 [[save_image.operator_catalogs]] ops 中所选
+Operator 的依赖关系，在开始同步之前列出依赖的包、选择的通道和 bundle，以及相关镜像数量。
+
+此命令将执行以下操作：
+1. 使用 oc-mirror --dry-run 获取 Operator 目录，目录内容解压到
+   images/working-dir/operator-catalogs/ 下 (不下载 Operator 镜像)
+2. 从每个 Operator 默认通道的最新 bundle 开始，按 olm.package.required 和
+   olm.gvk.required 递归解析依赖的包
+3. 按目录输出解析结果，未在 ops 中列出的依赖以 + 标记
+
+oc-mirror 只同步 ops 中列出的包，不会自动包含依赖；以 + 标记的依赖需要加入 ops，
+否则离线环境中 OLM 无法满足依赖，Operator 安装会停在 Pending。
+使用 --skip-dry-run 可直接使用上次 dry-run 或 save-image 解压的目录。
+
+使用方式:
+  ocpack plan operators demo
+  ocpack plan operators demo -o json`: `operators resolves the dependencies of the operators selected in [[save_image.operator_catalogs]] ops offline from the
+File-Based Catalog of each operator catalog, and lists the required packages, the selected channels and bundles, and the
+number of related images before mirroring starts.
+
+This command will:
+1. Fetch the operator catalogs with oc-mirror --dry-run and extract them under
+   images/working-dir/operator-catalogs/ (no operator images are downloaded)
+2. Starting from the latest bundle of each operator's default channel, resolve the required packages recursively
+   through olm.package.required and olm.gvk.required
+3. Print the result per catalog, marking dependencies that are not listed in ops with +
+
+oc-mirror only mirrors the packages listed in ops and does not include dependencies automatically; dependencies marked
+with + must be added to ops, otherwise OLM cannot satisfy them in the disconnected environment and operator
+installation stays Pending.
+Use --skip-dry-run to reuse the catalogs extracted by the last dry run or save-image.
+
+Usage:
+  ocpack plan operators demo
+  ocpack plan operators demo -o json`,
+	"配置中没有 Operator 目录，请先在 [[save_image.operator_catalogs]] 中选择 Operator": "there are no operator catalogs in the configuration, select operators in [[save_image.operator_catalogs]] first",
+	"镜像集解析失败: %w":                    "failed to resolve the image set: %w",
+	"直接使用已解压的 Operator 目录":           "Use the already extracted operator catalogs",
+	"不读取镜像大小，只列出镜像":                  "Do not read image sizes, only list the images",
+	"直接使用上次 dry-run 生成的镜像列表":         "Use the image list generated by the last dry run",
+	"日志级别 (info, debug, error)":      "Log level (info, debug, error)",
+	"列出 save-image 将要同步的全部镜像并估算传输大小": "List all images save-image will mirror and estimate the transfer size",
+	`plan 命令以 dry-run 模式执行 save-image 的镜像收集，列出将要同步的 release、Operator
+和附加镜像，并估算传输大小，便于在开始大规模下载之前确认镜像集的内容。
+
+此命令将执行以下操作：
+1. 使用 oc-mirror --dry-run 解析镜像集，生成 images/working-dir/dry-run/ 下的
+   mapping.txt 和带镜像类型的 images.json (不下载任何镜像)
+2. 使用 skopeo inspect --raw 读取每个镜像的清单，获取各层压缩后的大小
+3. 按类型分组输出镜像列表，预计传输大小按层去重计算
+
+读取镜像大小需要访问源仓库，失败的镜像标记为大小未知，不计入预计传输大小。
+使用 --skip-sizes 可只列出镜像；使用 --skip-dry-run 可直接使用上次 dry-run 的结果。
+升级图的查询结果在 [save_image] graph_cache_ttl 内复用，--offline-graph 只使用之前保存的升级图。
+
+使用方式:
+  ocpack plan demo
+  ocpack plan demo --skip-sizes
+  ocpack plan demo -o json > plan.json`: `plan runs the image collection of save-image in dry-run mode, lists the release, operator and additional images that
+will be mirrored and estimates the transfer size, so the content of the image set can be confirmed before a large download.
+
+This command will:
+1. Resolve the image set with oc-mirror --dry-run, generating mapping.txt and images.json with image types under
+   images/working-dir/dry-run/ (no images are downloaded)
+2. Read the manifest of each image with skopeo inspect --raw to get the compressed size of its layers
+3. Print the images grouped by type; the estimated transfer size counts each layer once
+
+Reading image sizes requires access to the source registries; images that fail are marked as unknown size and not
+counted in the estimate. Use --skip-sizes to only list the images, and --skip-dry-run to reuse the last dry-run result.
+Upgrade graph queries are reused within [save_image] graph_cache_ttl, and --offline-graph only uses the previously saved graph.
+
+Usage:
+  ocpack plan demo
+  ocpack plan demo --skip-sizes
+  ocpack plan demo -o json > plan.json`,
+	"生成认证文件失败: %w":          "failed to generate the authentication file: %w",
+	"📏 正在读取 %d 个镜像的大小...\n": "📏 Reading the sizes of %d images...\n",
+
+	// cmd/ocpack/cmd/provenance.go
+	"⚠️  记录复现清单失败: %v\n":        "⚠️  Failed to record the provenance manifest: %v\n",
+	"🧾 已记录 %d 个产物文件的复现信息: %s\n": "🧾 Recorded provenance for %d artifact files: %s\n",
+
+	// cmd/ocpack/cmd/regenerate_iso.go
+	"复用现有配置重新生成 ISO，轮换过期的 ignition 和认证文件": "Regenerate the ISO from the existing configuration, rotating expired ignition and credentials",
+	`generate-iso 生成的 ignition 中的证书在 24 小时后过期，此后 installation/ignition/auth/ 中的
+kubeconfig 和 kubeadmin-password 随之失效，尚未从 ISO 启动的节点也无法完成安装。
+mon 和 report 会在 ignition 超过 24 小时时给出警告。
+
+regenerate-iso 复用 installation/ 中已渲染的 install-config.yaml、agent-config.yaml 和 openshift/ 清单，
+重新执行 openshift-install 生成 ISO 和新的认证文件，不重新渲染配置，因此 config.toml 或模板在此期间的
+改动不会带入新的 ISO。需要应用配置改动时请使用 ocpack generate-iso --force。
+
+上次使用 generate-iso --unconfigured 时只重新生成并发布集群配置镜像，不含集群配置的 ISO 保持不变。
+重新生成后需要使用新的 ISO 或配置镜像重新启动所有节点。
+
+使用方式:
+  ocpack regenerate-iso demo`: `The certificates in the ignition generated by generate-iso expire after 24 hours; after that the kubeconfig and
+kubeadmin-password in installation/ignition/auth/ stop working, and nodes that have not booted from the ISO yet cannot
+finish the installation. mon and report warn when the ignition is older than 24 hours.
+
+regenerate-iso reuses the rendered install-config.yaml, agent-config.yaml and openshift/ manifests in installation/ and
+runs openshift-install again to generate the ISO and new credentials without rendering the configuration again, so changes
+to config.toml or the templates in the meantime are not carried into the new ISO. Use ocpack generate-iso --force to
+apply configuration changes.
+
+If generate-iso --unconfigured was used last time, only the cluster configuration image is regenerated and published;
+the ISO without cluster configuration stays unchanged.
+After regenerating, reboot all nodes from the new ISO or configuration image.
+
+Usage:
+  ocpack regenerate-iso demo`,
+	"创建 ISO 生成器失败: %w": "failed to create the ISO generator: %w",
+	"ISO 重新生成失败: %w":   "failed to regenerate the ISO: %w",
+
+	// cmd/ocpack/cmd/render.go
+	"在本地渲染节点配置文件，便于审阅或手动应用": "Render node configuration files locally for review or manual application",
+	"render 命令用于在本地生成节点的配置文件，不连接远程节点，也不执行 Ansible。\n\n使用方式:\n  ocpack render bastion-config demo": `render generates node configuration files locally without connecting to the nodes or running Ansible.
+
+Usage:
+  ocpack render bastion-config demo`,
+	"渲染 Bastion 节点的 DNS zone 文件和 haproxy.cfg": "Render the DNS zone files and haproxy.cfg of the Bastion node",
+	`bastion-config 命令根据 config.toml 在本地生成 Bastion 节点的配置文件：
+  named.conf
+  <cluster_id>.<domain>.zone
+  reverse.zone
+  haproxy.cfg
+
+[bastion.dns] mode = "hosts" 时以 dnsmasq.conf 和 hosts 代替 named.conf 和 zone 文件。
+
+默认输出到 <集群名称>/bastion-config/ 目录，可使用 --output 指定其他目录。
+生成的文件可用于审阅，或手动复制到 Bastion 节点的对应位置。
+
+使用方式:
+  ocpack render bastion-config demo
+  ocpack render bastion-config demo --output /tmp/bastion`: `bastion-config generates the configuration files of the Bastion node locally from config.toml:
+  named.conf
+  <cluster_id>.<domain>.zone
+  reverse.zone
+  haproxy.cfg
+
+With [bastion.dns] mode = "hosts", dnsmasq.conf and hosts replace named.conf and the zone files.
+
+Output goes to <cluster-name>/bastion-config/ by default; use --output to choose another directory.
+The generated files can be reviewed or copied manually to the corresponding locations on the Bastion node.
+
+Usage:
+  ocpack render bastion-config demo
+  ocpack render bastion-config demo --output /tmp/bastion`,
+	"创建 Bastion 配置渲染器失败: %v":             "failed to create the Bastion configuration renderer: %v",
+	"渲染 Bastion 配置失败: %v":                "failed to render the Bastion configuration: %v",
+	"✅ Bastion 配置已渲染到: %s\n":             "✅ Bastion configuration rendered to: %s\n",
+	"指定输出目录 (默认: <集群名称>/bastion-config)": "Output directory (default: <cluster-name>/bastion-config)",
+
+	// cmd/ocpack/cmd/report.go
+	"⚠️  记录阶段报告失败: %v\n": "⚠️  Failed to record the stage report: %v\n",
+	"汇总各阶段的耗时、结果和关键输出":   "Summarize the duration, result and key output of each stage",
+	`download、save-image、deploy-registry、generate-iso 等阶段命令执行结束时，
+将耗时、结果和关键输出 (ISO 路径、Registry 地址等) 记录到集群目录的 .ocpack-report.json，
+每个阶段只保留最近一次执行。report 命令将其汇总为一张表格。
+
+generate-iso、setup-pxe 和 save-image 生成产物后，还会将使用的工具版本 (ocpack、内置 oc-mirror、
+openshift-install、oc)、config.toml 的 SHA256、执行的命令和产物文件记录到 .ocpack-provenance.json，
+每类产物只保留最近一次生成的记录。--provenance 输出该复现清单，供支持人员按相同的输入复现产物。
+
+集群尚未安装完成且 generate-iso 生成的 ignition 已超过 24 小时时，report 会警告其中的证书已过期，
+并提示执行 ocpack regenerate-iso。
+
+使用方式:
+  ocpack report demo
+  ocpack report demo -o json
+  ocpack report demo --provenance`: `When stage commands such as download, save-image, deploy-registry and generate-iso finish, their duration, result and
+key output (ISO path, Registry address, ...) are recorded in .ocpack-report.json in the cluster directory, keeping only the
+latest run of each stage. report summarizes them in a table.
+
+After generate-iso, setup-pxe and save-image produce artifacts, the tool versions used (ocpack, built-in oc-mirror,
+openshift-install, oc), the SHA256 of config.toml, the command run and the artifact files are also recorded in
+.ocpack-provenance.json, keeping only the latest record for each kind of artifact. --provenance prints this manifest so
+support can reproduce the artifacts from the same inputs.
+
+When the cluster is not installed yet and the ignition generated by generate-iso is older than 24 hours, report warns
+that its certificates have expired and suggests running ocpack regenerate-iso.
+
+Usage:
+  ocpack report demo
+  ocpack report demo -o json
+  ocpack report demo --provenance`,
+	"\n⚠️  警告: %s\n": "\n⚠️  Warning: %s\n",
+	"输出产物的复现清单 (工具版本、配置哈希和产物文件)":                 "Print the provenance manifest of the artifacts (tool versions, configuration hash and artifact files)",
+	"⏱️  阶段 %s 耗时 %s，执行 ocpack report 查看各阶段汇总\n": "⏱️  Stage %s took %s, run ocpack report to see all stages\n",
+
+	// cmd/ocpack/cmd/rewrite_manifests.go
+	"将应用清单和 Helm values 中的镜像地址改写为私有仓库地址": "Rewrite image references in application manifests and Helm values to the private registry",
+	`按 load-image 记录的镜像映射 (mirror-mapping.json) 和 oc-mirror 生成的 IDMS/ITMS
+(proxy-cache 模式下为拉取代理) 将 --in 目录中的镜像地址改写为私有仓库中的地址，
+写入 --out 目录的相同位置，便于应用团队在离线环境中部署。
+
+改写的内容:
+  - 键为 image 或以 Image 结尾的字符串，如 Kubernetes 清单中的 containers[].image
+  - Helm values 中 {registry, repository, tag|digest} 形式的镜像，只改写 registry 和 repository
+
+*.yaml 和 *.yml 之外的文件，以及无法解析的 YAML (如 Helm 模板) 原样复制。
+没有对应镜像源的镜像保持不变并列出，通常需要将其加入 [save_image] additional_images 后重新镜像。
+
+使用方式:
+  ocpack rewrite-manifests demo --in ./app --out ./app-disconnected`: `Rewrites the image references in the --in directory to their addresses in the private registry, using the image
+mapping recorded by load-image (mirror-mapping.json) and the IDMS/ITMS generated by oc-mirror (the pull-through proxy in
+proxy-cache mode), and writes them to the same paths under the --out directory for deployment in disconnected environments.
+
+What is rewritten:
+  - Strings under keys named image or ending in Image, such as containers[].image in Kubernetes manifests
+  - Images in Helm values in the {registry, repository, tag|digest} form; only registry and repository are rewritten
+
+Files other than *.yaml and *.yml, and YAML that cannot be parsed (such as Helm templates), are copied as is.
+Images without a matching mirror source are left unchanged and listed; usually they need to be added to
+[save_image] additional_images and mirrored again.
+
+Usage:
+  ocpack rewrite-manifests demo --in ./app --out ./app-disconnected`,
+	"%v，请先执行 save-image 和 load-image":          "%v, run save-image and load-image first",
+	"改写清单失败: %w":                               "failed to rewrite manifests: %w",
+	"⚠️  %s:%d %s 没有对应的镜像源，保持不变\n":             "⚠️  %s:%d %s has no matching mirror source, left unchanged\n",
+	"⏭️  %s 无法解析为 YAML (如 Helm 模板)，已原样复制\n":    "⏭️  %s could not be parsed as YAML (such as a Helm template), copied as is\n",
+	"🎉 已写入 %d 个文件到 %s，改写 %d 个镜像":               "🎉 Wrote %d files to %s, rewrote %d images",
+	"，%d 个镜像没有对应的镜像源":                          ", %d images have no matching mirror source",
+	"包含 Kubernetes 清单或 Helm values 的输入目录 (必填)": "Input directory containing Kubernetes manifests or Helm values (required)",
+	"改写后文件的输出目录 (必填)":                          "Output directory for the rewritten files (required)",
+
+	// cmd/ocpack/cmd/root.go
+	"  构建时间: %s\n": "  Built: %s\n",
+	"不使用 emoji 和制表符，只输出 ASCII 符号 (默认在非 UTF-8 locale 或 TERM=dumb 时启用)": "Do not use emoji or box drawing, output only ASCII symbols (enabled by default with a non-UTF-8 locale or TERM=dumb)",
+	"命令失败时错误的输出格式: text 或 json (默认 text，命令的 --output 为 json 时为 json)": "Output format of errors when the command fails: text or json (default text, json when the command's --output is json)",
+	"不支持的错误输出格式: %s (可选 text 或 json)":                                 "Unsupported error format: %s (text or json)",
+	"命令的最长执行时间，如 2h，超时后终止正在执行的外部命令 (默认不限制)":                           "Maximum run time of the command, such as 2h; running external commands are terminated when it expires (no limit by default)",
+	"ocpack 是用于离线环境中部署 OpenShift 集群的工具":                               "ocpack deploys OpenShift clusters in disconnected environments",
+	`
+ ╔═══════════════════════════════════════════════════════════════════════════════╗
+ ║                                                                               ║
+ ║      ██████╗  ██████╗██████╗  █████╗  ██████╗██╗  ██╗                         ║
+ ║     ██╔═══██╗██╔════╝██╔══██╗██╔══██╗██╔════╝██║ ██╔╝                         ║
+ ║     ██║   ██║██║     ██████╔╝███████║██║     █████╔╝                          ║
+ ║     ██║   ██║██║     ██╔═══╝ ██╔══██║██║     ██╔═██╗                          ║
+ ║     ╚██████╔╝╚██████╗██║     ██║  ██║╚██████╗██║  ██╗                         ║
+ ║      ╚═════╝  ╚═════╝╚═╝     ╚═╝  ╚═╝ ╚═════╝╚═╝  ╚═╝                         ║
+ ║                                                                               ║
+ ╚═══════════════════════════════════════════════════════════════════════════════╝
+
+  快速开始 (一键部署):
+     1. ocpack new cluster [集群名称]
+     2. 编辑 [集群名称]/config.toml 和 pull-secret.txt
+     3. ocpack all [集群名称] [--mode=iso|pxe]
+
+  分步部署:
+     1. ocpack new cluster [集群名称]
+     2. 编辑 [集群名称]/config.toml
+     3. ocpack download [集群名称]
+     4. ocpack save-image [集群名称]
+     5. ocpack deploy-bastion [集群名称]
+     6. ocpack deploy-registry [集群名称]
+     7. ocpack load-image [集群名称]
+     8. ocpack generate-iso [集群名称] 或 ocpack setup-pxe [集群名称]`: `
+ ╔═══════════════════════════════════════════════════════════════════════════════╗
+ ║                                                                               ║
+ ║      ██████╗  ██████╗██████╗  █████╗  ██████╗██╗  ██╗                         ║
+ ║     ██╔═══██╗██╔════╝██╔══██╗██╔══██╗██╔════╝██║ ██╔╝                         ║
+ ║     ██║   ██║██║     ██████╔╝███████║██║     █████╔╝                          ║
+ ║     ██║   ██║██║     ██╔═══╝ ██╔══██║██║     ██╔═██╗                          ║
+ ║     ╚██████╔╝╚██████╗██║     ██║  ██║╚██████╗██║  ██╗                         ║
+ ║      ╚═════╝  ╚═════╝╚═╝     ╚═╝  ╚═╝ ╚═════╝╚═╝  ╚═╝                         ║
+ ║                                                                               ║
+ ╚═══════════════════════════════════════════════════════════════════════════════╝
+
+  Quick start (one-step deployment):
+     1. ocpack new cluster [cluster-name]
+     2. Edit [cluster-name]/config.toml and pull-secret.txt
+     3. ocpack all [cluster-name] [--mode=iso|pxe]
+
+  Step by step:
+     1. ocpack new cluster [cluster-name]
+     2. Edit [cluster-name]/config.toml
+     3. ocpack download [cluster-name]
+     4. ocpack save-image [cluster-name]
+     5. ocpack deploy-bastion [cluster-name]
+     6. ocpack deploy-registry [cluster-name]
+     7. ocpack load-image [cluster-name]
+     8. ocpack generate-iso [cluster-name] or ocpack setup-pxe [cluster-name]`,
+	"显示版本信息": "Show version information",
+	"显示 ocpack 的版本信息，包括版本号、提交哈希和构建时间": "Show the ocpack version information, including the version, commit hash and build time",
+	"ocpack 版本信息:\n": "ocpack version information:\n",
+	"  版本: %s\n":     "  Version: %s\n",
+	"  提交: %s\n":     "  Commit: %s\n",
+
+	// cmd/ocpack/cmd/save_image.go
+	"只解析镜像集而不下载镜像":                                       "Only resolve the image set without downloading images",
+	"包含 Operator 镜像 (覆盖 [save_image] include_operators)": "Include operator images (overrides [save_image] include_operators)",
+	"启用重试机制":    "Enable retries",
+	"最大重试次数":    "Maximum number of retries",
+	"重试间隔时间（秒）": "Retry interval (seconds)",
+	"只保存镜像列表文件中的镜像，跳过 release 和 Operator":                                  "Only save the images in the image list file, skipping release and operators",
+	"只使用之前保存在工作目录中的升级图，不访问 Cincinnati (api.openshift.com)":                 "Only use the upgrade graph previously saved in the working directory, without accessing Cincinnati (api.openshift.com)",
+	"忽略 catalog_cache_ttl，总是从源仓库获取最新的 Operator 目录":                         "Ignore catalog_cache_ttl and always fetch the latest operator catalogs from the source registry",
+	"只使用之前下载到工作目录中的 Operator 目录，不访问源仓库":                                    "Only use the operator catalogs previously downloaded to the working directory, without accessing the source registry",
+	"复制全部镜像，不预先跳过目标中 digest 相同的已有镜像":                                       "Copy all images without skipping images that already exist in the target with the same digest",
+	"只复制这些分组的镜像: release、operators、additional、helm，可指定多个，如 --only release": "Only copy the images of these groups: release, operators, additional, helm; can be repeated, such as --only release",
+	"每个镜像复制失败时最多尝试的次数，覆盖 [save_image.retry] attempts (默认 3)":               "Maximum number of attempts for each image copy, overrides [save_image.retry] attempts (default 3)",
+	"镜像复制重试的退避方式: linear 或 exponential，覆盖 [save_image.retry] backoff":      "Backoff between image copy retries: linear or exponential, overrides [save_image.retry] backoff",
+	"本地缓存的大小上限，如 200Gi (覆盖 [save_image] max_cache_size)，镜像成功后删除最久未使用的数据":   "Size limit of the local cache, such as 200Gi (overrides [save_image] max_cache_size); the least recently used data is removed after a successful run",
+	"保存 OpenShift 镜像到本地磁盘或共享存储":                                            "Save OpenShift images to local disk or shared storage",
+	`save-image 命令根据 config.toml 生成镜像集配置，使用内置的 oc-mirror 将 release、Operator
+和附加镜像保存为镜像归档 (mirror_*.tar)，供离线环境中的 load-image 推送到私有仓库。
+
+镜像归档默认保存在 <集群名称>/images，可通过 [save_image.storage] 修改：
+  file:///mnt/nfs/ocp-images   直接写入 NFS 等共享存储，离线站点挂载后即可 load-image
+  s3://<bucket>/<前缀>         保存到本地后使用 aws s3 sync 上传到 S3 兼容的对象存储，
+                               load-image 前自动下载
+
+[save_image] mirror_registry = true 时，同时将下载目录中的 mirror-registry 离线安装包
+归档到 mirror-registry/ 目录 (附 sha256 校验和)，随镜像一起保存。Registry 主机损坏后，
+deploy-registry 在下载目录中没有安装包时使用该副本重建 Registry，无需访问互联网。
+
+oc-mirror 日志实时输出，每个阶段 (collect、copy release、copy operators、archive) 开始时
+输出标题。使用 -v/-vv 输出 debug/trace 日志，--quiet 只输出错误和最终摘要；完整日志
+保存在 working-dir/logs/oc-mirror.log。
+
+使用 --dry-run 只解析镜像集，镜像列表写入 <集群名称>/images/working-dir/dry-run/，
+不下载镜像也不上传到存储。
+
+升级图 (Cincinnati graph) 的查询结果保存在 working-dir/hold-release/cincinnati-graph-data，
+在 [save_image] graph_cache_ttl (默认 1h) 内直接复用，查询失败时回退到已有的结果。
+无法访问 api.openshift.com 时，使用 --offline-graph 只使用之前保存的升级图。
+
+Operator 目录解析出的摘要记录在 working-dir/operator-catalogs/catalog-digests.json，目录已下载到工作目录时
+在 [save_image] catalog_cache_ttl (默认 24h) 内直接复用，不再查询 registry.redhat.io；无法访问源仓库时
+回退到之前下载的目录。--refresh-catalog 忽略缓存时间，总是查询源仓库获取最新的目录；--offline-catalog
+只使用之前下载的目录，从不查询源仓库。
+
+下载前先查询本地缓存中已有的镜像，跳过 digest 相同的镜像，使用 --full-copy 重新下载全部镜像。
+配置了 [save_image] max_cache_size 或 --max-cache-size 时，保存成功后按最近访问时间删除本地缓存中
+最久未使用的数据，使缓存不超过该大小，并输出被移除的镜像。
+
+传输窗口有限时，使用 --only release 先只保存 release (分组: release、operators、additional、helm)，
+归档中只包含所选分组的镜像，之后再不带 --only 执行一次补全。[save_image] mirror_order 按组依次复制，
+前一组完成后才开始下一组。
+
+使用 --images-file 只保存列表文件中的镜像 (每行一个，# 开头为注释)，跳过 release 和 Operator，
+适合为已有的私有仓库补充少量新的应用镜像。归档保存在镜像存储的 adhoc/ 子目录，不影响完整镜像集，
+之后使用 load-image --images-file 推送。
+
+保存完成后在镜像目录中生成 transfer-manifest.json，记录每个归档的大小和 sha256；配置了
+[save_image.signing] 时同时使用 gpg 或 cosign 签名。load-image 在导入前验证，发现摆渡传输中
+损坏或被篡改的归档。
+
+使用方式:
+  ocpack save-image demo
+  ocpack save-image demo --include-operators
+  ocpack save-image demo --dry-run
+  ocpack save-image demo --images-file images.txt
+  ocpack save-image demo --offline-graph
+  ocpack save-image demo --refresh-catalog
+  ocpack save-image demo --only release
+  ocpack save-image demo --quiet`: `save-image generates the image set configuration from config.toml and uses the built-in oc-mirror to save the release,
+operator and additional images as image archives (mirror_*.tar), which load-image pushes to the private registry in the disconnected environment.
+
+Image archives are saved in <cluster-name>/images by default; use [save_image.storage] to change this:
+  file:///mnt/nfs/ocp-images   write directly to shared storage such as NFS; the disconnected site mounts it and runs load-image
+  s3://<bucket>/<prefix>       save locally, then upload to S3-compatible object storage with aws s3 sync;
+                               downloaded automatically before load-image
+
+With [save_image] mirror_registry = true, the mirror-registry offline installer in the download directory is also
+archived to the mirror-registry/ directory (with a sha256 checksum) and saved with the images. If the Registry host is
+lost, deploy-registry uses this copy to rebuild the Registry when the download directory has no installer, without internet access.
+
+oc-mirror logs are streamed and a heading is printed when each phase (collect, copy release, copy operators, archive)
+starts. Use -v/-vv for debug/trace logs and --quiet to only print errors and the final summary; the full log is
+saved in working-dir/logs/oc-mirror.log.
+
+Use --dry-run to only resolve the image set; the image list is written to <cluster-name>/images/working-dir/dry-run/,
+and no images are downloaded or uploaded to storage.
+
+Upgrade graph (Cincinnati graph) queries are saved in working-dir/hold-release/cincinnati-graph-data and reused within
+[save_image] graph_cache_ttl (default 1h); when a query fails the existing result is used.
+When api.openshift.com is not reachable, use --offline-graph to only use the previously saved graph.
+
+Digests resolved from operator catalogs are recorded in working-dir/operator-catalogs/catalog-digests.json; catalogs
+already downloaded to the working directory are reused within [save_image] catalog_cache_ttl (default 24h) without
+querying registry.redhat.io, and the previously downloaded catalogs are used when the source registry is not reachable.
+--refresh-catalog ignores the cache time and always queries the source registry for the latest catalogs; --offline-catalog
+only uses the previously downloaded catalogs and never queries the source registry.
+
+Before downloading, images already in the local cache are checked and images with the same digest are skipped; use
+--full-copy to download all images again. When [save_image] max_cache_size or --max-cache-size is set, the least recently
+used data is removed from the local cache after a successful run so the cache stays within that size, and the removed images are printed.
+
+When the transfer window is limited, use --only release to save only the release first (groups: release, operators,
+additional, helm); the archive contains only the images of the selected groups, and a later run without --only completes
+the set. [save_image] mirror_order copies the groups in order, starting the next group only after the previous one completes.
+
+Use --images-file to only save the images in a list file (one per line, lines starting with # are comments), skipping
+release and operators; this suits adding a few new application images to an existing private registry. The archive is
+saved in the adhoc/ subdirectory of the image storage without affecting the full image set, and is pushed
+later with load-image --images-file.
+
+After saving, transfer-manifest.json is generated in the image directory, recording the size and sha256 of each archive;
+when [save_image.signing] is configured it is also signed with gpg or cosign. load-image verifies it before importing,
+detecting archives corrupted or tampered with during the transfer.
+
+Usage:
+  ocpack save-image demo
+  ocpack save-image demo --include-operators
+  ocpack save-image demo --dry-run
+  ocpack save-image demo --images-file images.txt
+  ocpack save-image demo --offline-graph
+  ocpack save-image demo --refresh-catalog
+  ocpack save-image demo --only release
+  ocpack save-image demo --quiet`,
+	"✅ 干运行完成！镜像列表: %s\n":                                         "✅ Dry run completed! Image list: %s\n",
+	"💡 离线环境中使用 'ocpack load-image %s --images-file %s' 推送这些镜像\n": "💡 In the disconnected environment run 'ocpack load-image %s --images-file %s' to push these images\n",
+
+	// cmd/ocpack/cmd/scan_images.go
+	"扫描镜像集中的镜像漏洞": "Scan the images in the image set for vulnerabilities",
+	`scan-images 命令使用 [scan] 配置的扫描器扫描镜像集，报告保存在 <集群名称>/scan/report.json。
+
+待扫描的镜像来自 scan.images_file，默认读取 save-image --dry-run 生成的
+images/working-dir/dry-run/mapping.txt；不存在时只扫描 release 镜像和 additional_images。
+
+扫描器:
+  trivy    执行 trivy image --format json (默认)
+  command  执行 scan.command，镜像通过环境变量 OCPACK_SCAN_IMAGE 传入，需输出 Trivy 格式的 JSON
+
+配置了 scan.fail_on 时，存在该级别及以上的漏洞或有镜像扫描失败则命令返回错误。
+配置 [scan] enabled = true 后，load-image 会在推送镜像前自动执行扫描。
+
+使用方式:
+  ocpack scan-images demo`: `scan-images scans the image set with the scanner configured in [scan]; the report is saved in <cluster-name>/scan/report.json.
+
+The images to scan come from scan.images_file, by default images/working-dir/dry-run/mapping.txt generated by
+save-image --dry-run; when it does not exist only the release images and additional_images are scanned.
+
+Scanners:
+  trivy    runs trivy image --format json (default)
+  command  runs scan.command with the image in the OCPACK_SCAN_IMAGE environment variable; it must print Trivy JSON
+
+When scan.fail_on is set, the command returns an error if there are vulnerabilities at or above that severity or an image fails to scan.
+With [scan] enabled = true, load-image runs the scan automatically before pushing images.
+
+Usage:
+  ocpack scan-images demo`,
+	"✅ 镜像扫描通过!": "✅ Image scan passed!",
+
+	// cmd/ocpack/cmd/serve_pxe.go
+	"在本机提供 TFTP 和 ProxyDHCP 服务，引导节点从 PXE 安装": "Serve TFTP and ProxyDHCP from this host so nodes install over PXE",
+	`在本机启动内置的 TFTP 服务器，提供 <集群>/pxe/files 中生成的 PXE 文件，按 Ctrl+C 停止。
+
+使用 --proxy-dhcp 时同时启动 ProxyDHCP (UDP 67 和 4011)，适用于无法修改站点 DHCP 服务器的实验环境:
+站点 DHCP 服务器照常分配地址，ProxyDHCP 只为 config.toml 中配置了 MAC 地址的节点补充启动信息，
+其他主机的请求会被忽略。节点首先通过 TFTP 加载 iPXE (BIOS 使用 undionly.kpxe，UEFI 使用 ipxe.efi)，
+iPXE 再通过 TFTP 加载生成的 iPXE 脚本，内核、initrd 和 rootfs 从脚本中的 PXE 资源服务器下载。
+
+前提条件:
+  - 已生成 PXE 文件 (<集群>/pxe/files/*.ipxe)
+  - --bootloader-dir 中有 undionly.kpxe 和 ipxe.efi (或 ipxe-x86_64.efi)，可安装 ipxe-bootimgs 软件包
+  - 以 root 运行，且本机没有其他 DHCP/TFTP 服务占用端口
+
+使用方式:
+  sudo ocpack serve-pxe demo --proxy-dhcp
+  sudo ocpack serve-pxe demo --proxy-dhcp --server-ip 192.168.1.5`: `Starts the built-in TFTP server on this host, serving the PXE files generated in <cluster>/pxe/files; press Ctrl+C to stop.
+
+With --proxy-dhcp, ProxyDHCP (UDP 67 and 4011) is started as well, for lab environments where the site DHCP server cannot be changed:
+the site DHCP server assigns addresses as usual, and ProxyDHCP only adds boot information for the nodes whose MAC addresses
+are configured in config.toml; requests from other hosts are ignored. Nodes first load iPXE over TFTP (undionly.kpxe for BIOS,
+ipxe.efi for UEFI), iPXE then loads the generated iPXE script over TFTP, and the kernel, initrd and rootfs are downloaded from the PXE asset server in the script.
+
+Prerequisites:
+  - The PXE files have been generated (<cluster>/pxe/files/*.ipxe)
+  - --bootloader-dir contains undionly.kpxe and ipxe.efi (or ipxe-x86_64.efi); install the ipxe-bootimgs package
+  - Run as root, and no other DHCP/TFTP service on this host uses the ports
+
+Usage:
+  sudo ocpack serve-pxe demo --proxy-dhcp
+  sudo ocpack serve-pxe demo --proxy-dhcp --server-ip 192.168.1.5`,
+	"⚠️  %s 中未找到 %s，BIOS 节点将无法启动\n":                        "⚠️  %s does not contain %s, BIOS nodes will not be able to boot\n",
+	"✅ TFTP 服务: %s:69 (%s)\n":                              "✅ TFTP service: %s:69 (%s)\n",
+	"✅ ProxyDHCP 服务: %s:67, %s:4011，应答 %d 个节点\n":           "✅ ProxyDHCP service: %s:67, %s:4011, answering %d nodes\n",
+	"💡 未启用 --proxy-dhcp，请在站点 DHCP 中将 next-server 设置为 %s\n": "💡 --proxy-dhcp is not enabled, set next-server to %s in the site DHCP\n",
+	"同时启动 ProxyDHCP，为 config.toml 中的节点提供启动信息":              "Also start ProxyDHCP to provide boot information for the nodes in config.toml",
+	"对节点通告的本机 IP，默认为本机位于 machine_network 中的地址":             "IP of this host advertised to the nodes, defaults to the address of this host in machine_network",
+	"包含 undionly.kpxe 和 ipxe.efi 的目录":                      "Directory containing undionly.kpxe and ipxe.efi",
+
+	// cmd/ocpack/cmd/setup_pxe.go
+	"PXE 启动文件的下载地址 (可选，默认使用 infra.pxe_asset_url 或 Bastion 上的 PXE 服务器)": "Download URL of the PXE boot files (optional, defaults to infra.pxe_asset_url or the PXE server on the Bastion)",
+	"跳过镜像验证步骤":                           "Skip the image verification step",
+	"强制重新生成并上传，即使配置没有变化":                 "Force regeneration and upload even if the configuration has not changed",
+	"只渲染配置文件并显示差异，不执行 openshift-install": "Only render the configuration files and show the differences, without running openshift-install",
+	"同时上传到 Bastion 的文件数":                 "Number of files uploaded to the Bastion in parallel",
+	"生成 PXE 启动文件并上传到 PXE 服务器":            "Generate PXE boot files and upload them to the PXE server",
+	`setup-pxe 命令用于生成 OpenShift 集群的 PXE 启动文件。
+
+此命令将执行以下操作：
+1. 验证集群配置和依赖工具
+2. 创建 PXE 目录结构
+3. 生成 install-config.yaml 配置文件
+4. 生成 agent-config.yaml 配置文件
+5. 使用 openshift-install 生成 PXE 启动文件 (内核、initrd、rootfs 和 iPXE 脚本)
+6. 将文件上传到 Bastion 上的 PXE 服务器
+
+上传使用内置的 SSH 客户端 (密码认证时不需要 sshpass)，先同步到 Bastion 上 SSH 用户主目录中的
+.ocpack/upload/<集群名称>/pxe，按 SHA256 只上传内容变化的文件并显示每个文件的进度，
+--upload-parallel 控制同时上传的文件数，然后由上传脚本发布到 TFTP 和 HTTP 目录。
+
+生成的文件结构：
+  pxe/
+  ├── config/
+  │   ├── install-config.yaml
+  │   └── agent-config.yaml
+  ├── files/
+  │   └── [generated PXE files]
+  └── .fingerprint
+
+.fingerprint 记录生成 PXE 文件时的配置、OpenShift 版本和固定的 release 镜像。
+再次执行时如果这些内容没有变化，跳过生成和上传；有变化时先清空 files/ 目录再重新生成，
+避免新旧文件混在一起。使用 --force 可强制重新生成并上传。
+
+使用 --render-only 可只渲染 install-config.yaml 和 agent-config.yaml 并显示
+与现有文件的差异，不执行 openshift-install，也不上传文件。
+
+使用方式:
+  ocpack setup-pxe demo
+  ocpack setup-pxe demo --force
+  ocpack setup-pxe demo --render-only`: `setup-pxe generates the PXE boot files of an OpenShift cluster.
+
+This command will:
+1. Validate the cluster configuration and required tools
+2. Create the PXE directory structure
+3. Generate the install-config.yaml configuration file
+4. Generate the agent-config.yaml configuration file
+5. Generate the PXE boot files (kernel, initrd, rootfs and iPXE scripts) with openshift-install
+6. Upload the files to the PXE server on the Bastion
+
+Uploads use the built-in SSH client (sshpass is not needed for password authentication). Files are first synced to
+.ocpack/upload/<cluster-name>/pxe in the home directory of the SSH user on the Bastion, uploading only files whose SHA256 changed
+and showing the progress of each file; --upload-parallel controls the number of files uploaded in parallel. An upload script then publishes them to the TFTP and HTTP directories.
+
+Generated file structure:
+  pxe/
+  ├── config/
+  │   ├── install-config.yaml
+  │   └── agent-config.yaml
+  ├── files/
+  │   └── [generated PXE files]
+  └── .fingerprint
+
+.fingerprint records the configuration, OpenShift version and pinned release image used to generate the PXE files.
+When they have not changed on the next run, generation and upload are skipped; when they changed, the files/ directory is
+emptied before regenerating so old and new files are not mixed. Use --force to regenerate and upload anyway.
+
+Use --render-only to only render install-config.yaml and agent-config.yaml and show the differences from the
+existing files, without running openshift-install or uploading files.
+
+Usage:
+  ocpack setup-pxe demo
+  ocpack setup-pxe demo --force
+  ocpack setup-pxe demo --render-only`,
+	"创建 PXE 生成器失败: %w": "failed to create the PXE generator: %w",
+	"PXE 文件生成失败: %w":   "failed to generate the PXE files: %w",
+
+	// cmd/ocpack/cmd/sync_dns.go
+	"在站点托管的外部 DNS 中创建集群记录，并等待记录传播": "Create the cluster records in the site-managed external DNS and wait for them to propagate",
+	`站点使用 Infoblox、Route53 等托管 DNS 而不是 Bastion 上的 named 时，在 config.toml 中配置
+[infra.external_dns]，sync-dns 将集群需要的记录交给站点的自动化创建:
+
+  api.<集群域>       -> 负载均衡
+  api-int.<集群域>   -> 负载均衡
+  *.apps.<集群域>    -> 负载均衡
+  registry.<集群域>  -> Registry 节点 (registry = false 时不创建)
+
+负载均衡配置为主机名时使用 CNAME 记录。记录以 JSON 交给以下之一:
+  provider = "webhook"   POST 到 url，配置 token 时带 Authorization: Bearer 请求头
+  provider = "exec"      在集群目录中执行 command，JSON 写入标准输入
+  provider = "<名称>"    执行 PATH 中的 ocpack-dns-<名称> 插件 (参数为 upsert)，JSON 写入标准输入
+
+创建后通过 resolvers (默认为节点使用的 DNS 服务器) 解析每条记录，通配符记录使用随机名称解析，
+直到全部生效或超过 propagation_timeout。generate-iso 前的就绪检查也会检查这些记录。
+
+使用方式:
+  ocpack sync-dns demo
+  ocpack sync-dns demo --dry-run
+  ocpack sync-dns demo --verify`: `When the site uses managed DNS such as Infoblox or Route53 instead of named on the Bastion, configure
+[infra.external_dns] in config.toml and sync-dns hands the records the cluster needs to the site automation:
+
+  api.<cluster-domain>       -> load balancer
+  api-int.<cluster-domain>   -> load balancer
+  *.apps.<cluster-domain>    -> load balancer
+  registry.<cluster-domain>  -> Registry node (not created when registry = false)
+
+CNAME records are used when the load balancer is configured as a host name. The records are passed as JSON to one of:
+  provider = "webhook"   POST to url, with an Authorization: Bearer header when token is set
+  provider = "exec"      run command in the cluster directory with the JSON on standard input
+  provider = "<name>"    run the ocpack-dns-<name> plugin from PATH (argument upsert) with the JSON on standard input
+
+After creation each record is resolved through resolvers (the DNS servers used by the nodes by default), wildcard records
+using a random name, until all are in effect or propagation_timeout expires. The readiness check before generate-iso also checks these records.
+
+Usage:
+  ocpack sync-dns demo
+  ocpack sync-dns demo --dry-run
+  ocpack sync-dns demo --verify`,
+	"未配置 [infra.external_dns]\n💡 请在 config.toml 中配置 provider 和 url、command 或插件名称": "[infra.external_dns] is not configured\n💡 Configure provider and url, command or a plugin name in config.toml",
+	"🌐 通过 %s 创建 %d 条记录 (区域 %s)...\n":                                              "🌐 Using %s to create %d records (zone %s)...\n",
+	"🔍 通过 DNS 服务器 %v 检查记录传播...\n":                                                 "🔍 Checking record propagation through DNS servers %v...\n",
+	"✅ 全部记录已生效":                                                                   "✅ All records are in effect",
+	"只输出交给外部 DNS 的请求，不创建记录":                                                       "Only print the request passed to the external DNS without creating records",
+	"不创建记录，只检查记录是否已传播":                                                            "Do not create records, only check whether they have propagated",
+
+	// cmd/ocpack/cmd/templates.go
+	"管理用户自定义的配置模板": "Manage user-defined configuration templates",
+	`templates 命令用于管理集群目录下的自定义模板。
+
+当 <集群名称>/templates/ 目录中存在以下文件时，将在渲染时替代内置模板：
+  install-config.yaml     generate-iso 和 setup-pxe 使用
+  agent-config.yaml       generate-iso 使用
+  agent-config-pxe.yaml   setup-pxe 使用
+  imageset-config.yaml    save-image 和 load-image 使用
+  named.conf、forward.zone、reverse.zone、haproxy.cfg
+                          render bastion-config 使用
+  nodes-config.yaml       add-worker 使用
+
+使用方式:
+  ocpack templates dump demo`: `templates manages the custom templates in the cluster directory.
+
+When the following files exist in <cluster-name>/templates/, they replace the built-in templates when rendering:
+  install-config.yaml     used by generate-iso and setup-pxe
+  agent-config.yaml       used by generate-iso
+  agent-config-pxe.yaml   used by setup-pxe
+  imageset-config.yaml    used by save-image and load-image
+  named.conf, forward.zone, reverse.zone, haproxy.cfg
+                          used by render bastion-config
+  nodes-config.yaml       used by add-worker
+
+Usage:
+  ocpack templates dump demo`,
+	"导出内置模板到集群的 templates 目录以便修改":       "Export the built-in templates to the templates directory of the cluster for editing",
+	"获取当前目录失败: %v":                      "failed to get the current directory: %v",
+	"集群目录不存在: %s":                       "cluster directory does not exist: %s",
+	"导出模板失败: %v":                        "failed to export templates: %v",
+	"🟡 模板已存在于 %s，未做修改。使用 --force 覆盖。\n": "🟡 Template already exists in %s, left unchanged. Use --force to overwrite.\n",
+	"📝 已导出: %s\n":                       "📝 Exported: %s\n",
+	"✅ 模板已导出到: %s\n":                    "✅ Templates exported to: %s\n",
+	"覆盖已存在的模板文件":                        "Overwrite existing template files",
+
+	// cmd/ocpack/cmd/timeline.go
+	"生成集群安装时间线": "Generate the cluster installation timeline",
+	`合并安装日志和集群 Operator 状态，生成按时间排序的安装时间线，并统计每个阶段的耗时。
+
+时间线来源:
+  - installation/ignition/.openshift_install.log: generate-iso 生成 ISO 的日志，
+    以及使用 --dir installation/ignition 执行 openshift-install agent wait-for 时追加的输出
+  - --log 指定的其他 openshift-install 日志文件
+  - 集群安装后通过 kubeconfig 获取的 ClusterOperator Available/Degraded 状态变化
+
+阶段包括引导主机、主机发现与验证、安装准备、写入磁盘、Bootstrap 和集群初始化，
+便于定位安装慢在哪个阶段。
+
+使用方式:
+  ocpack timeline demo
+  ocpack timeline demo --verbose
+  ocpack timeline demo -o json --no-discover`: `Merges the installation logs and the cluster operator status into a chronological installation timeline and
+reports the duration of each phase.
+
+Timeline sources:
+  - installation/ignition/.openshift_install.log: the log of generate-iso generating the ISO, plus
+    the output appended when running openshift-install agent wait-for with --dir installation/ignition
+  - other openshift-install log files given with --log
+  - ClusterOperator Available/Degraded transitions read through the kubeconfig after the cluster is installed
+
+Phases include host boot, host discovery and validation, installation preparation, writing to disk, bootstrap and
+cluster initialization, to locate which phase made the installation slow.
+
+Usage:
+  ocpack timeline demo
+  ocpack timeline demo --verbose
+  ocpack timeline demo -o json --no-discover`,
+	"输出格式: text 或 json":                      "Output format: text or json",
+	"额外的 openshift-install 日志文件，可重复指定":       "Additional openshift-install log file, can be repeated",
+	"列出全部 info 事件，默认只列出阶段切换、警告和 Operator 事件": "List all info events; by default only phase changes, warnings and operator events are listed",
+	"不访问集群，只使用日志":                            "Do not access the cluster, only use the logs",
+
+	// cmd/ocpack/cmd/ui.go
+	"启动本地 Web 面板，查看阶段进度、镜像同步进度、磁盘占用、日志和产物": "Start a local web dashboard showing stage progress, image sync progress, disk usage, logs and artifacts",
+	`在本机启动只读的 Web 面板，按 Ctrl+C 停止。多人共用跳板机时，可以在浏览器中查看集群进度而不必翻阅终端输出:
+
+  - 各阶段 (download、save-image、deploy-registry、load-image、generate-iso 等) 最近一次执行的结果和耗时
+  - 正在进行的 save-image/load-image 的镜像同步进度，每个镜像完成后更新
+  - 集群目录下各子目录的磁盘占用和所在分区的剩余空间
+  - oc-mirror 和 openshift-install 的日志
+  - ISO、PXE 文件和镜像归档的下载链接
+
+面板默认只监听 127.0.0.1，可以通过 SSH 端口转发访问；使用 --listen 监听其他地址时，
+任何能访问该端口的人都可以下载日志和产物。
+
+使用方式:
+  ocpack ui demo
+  ocpack ui demo --listen 0.0.0.0:8088`: `Starts a read-only web dashboard on this host; press Ctrl+C to stop. When several people share a jump host, the cluster
+progress can be viewed in a browser instead of reading terminal output:
+
+  - the result and duration of the latest run of each stage (download, save-image, deploy-registry, load-image, generate-iso, ...)
+  - the image sync progress of a running save-image/load-image, updated as each image completes
+  - the disk usage of each subdirectory of the cluster directory and the free space of its file system
+  - the oc-mirror and openshift-install logs
+  - download links for the ISO, PXE files and image archives
+
+The dashboard listens only on 127.0.0.1 by default and can be reached through SSH port forwarding; when --listen is used
+with another address, anyone who can reach the port can download the logs and artifacts.
+
+Usage:
+  ocpack ui demo
+  ocpack ui demo --listen 0.0.0.0:8088`,
+	"🌐 Web 面板: http://%s/\n": "🌐 Web dashboard: http://%s/\n",
+	"按 Ctrl+C 停止":            "Press Ctrl+C to stop",
+	"面板的监听地址":                "Listen address of the dashboard",
+
+	// cmd/ocpack/cmd/validate.go
+	"[集群名称]":      "[cluster-name]",
+	"验证集群配置和节点规格": "Validate the cluster configuration and node specifications",
+	`验证 config.toml，并检查节点规格是否满足 OpenShift 的最低要求。
+
+节点配置了 cpu、memory_gb 或 disk_gb 时，按集群拓扑 (单节点 SNO、紧凑集群、标准集群)
+和节点角色与文档中的最低要求比较，低于要求的节点会给出警告。generate-iso 和 PXE
+生成时也会执行同样的检查。集群目录名与 cluster_info.cluster_id 不一致时同样给出警告。使用 --strict 时存在警告也返回错误，可用于在流水线中阻止安装。
+
+使用方式:
+  ocpack validate demo
+  ocpack validate demo --strict`: `Validates config.toml and checks whether the node specifications meet the OpenShift minimum requirements.
+
+When nodes set cpu, memory_gb or disk_gb, they are compared with the documented minimums for the cluster topology (single-node SNO,
+compact cluster, standard cluster) and node role, and nodes below the requirements produce warnings. generate-iso and PXE
+generation run the same check. A warning is also given when the cluster directory name differs from cluster_info.cluster_id. With --strict, warnings also return an error, which can stop the installation in a pipeline.
+
+Usage:
+  ocpack validate demo
+  ocpack validate demo --strict`,
+	"加载配置失败: %w": "failed to load the configuration: %w",
+	"配置验证失败: %v": "configuration validation failed: %v",
+	"集群拓扑: %s (%d 个 Control Plane 节点, %d 个 Worker 节点)\n": "Cluster topology: %s (%d control plane nodes, %d worker nodes)\n",
+	"存在 %d 个警告": "%d warnings",
+	"✅ 配置验证通过":  "✅ Configuration is valid",
+	"存在警告 (如节点规格低于最低要求) 时返回错误": "Return an error when there are warnings (such as node specifications below the minimum requirements)",
+
+	// pkg/agentinstall/bastion.go
+	"连接 Bastion %s 失败: %w": "failed to connect to Bastion %s: %w",
+
+	// pkg/agentinstall/installer.go
+	"skopeo inspect 失败: %w, 输出: %s":               "skopeo inspect failed: %w, output: %s",
+	"解析 skopeo inspect 输出失败: %w":                  "failed to parse the skopeo inspect output: %w",
+	"镜像摘要为空":                                      "image digest is empty",
+	"获取到镜像摘要: %s":                                 "Got image digest: %s",
+	"执行命令: %s":                                    "Running command: %s",
+	"提取 openshift-install 失败: %w, 输出: %s":         "failed to extract openshift-install: %w, output: %s",
+	"准备仓库 TLS 配置失败: %w":                           "failed to prepare registry TLS: %w",
+	"重命名提取的 openshift-install 失败: %w":             "failed to rename the extracted openshift-install: %w",
+	"设置 openshift-install 权限失败: %w":               "failed to set the openshift-install permissions: %w",
+	"查找 openshift-install 失败: %w":                 "failed to find openshift-install: %w",
+	"创建临时目录失败: %w":                                "failed to create the temporary directory: %w",
+	"复制 %s 失败: %w":                                "failed to copy %s: %w",
+	"复制 %s 目录失败: %w":                              "failed to copy the %s directory: %w",
+	"固定安装 release 镜像: %s":                         "Pinned installation release image: %s",
+	"执行 openshift-install agent create %s 失败: %w": "openshift-install agent create %s failed: %w",
+
+	// pkg/agentinstall/renderer.go
+	"加载配置文件失败: %w":                                                          "failed to load the configuration file: %w",
+	"缺少必需的工具: %s，请先运行 'ocpack download' 命令":                                 "missing required tool: %s, run 'ocpack download' first",
+	"缺少 %s 文件，请先获取 Red Hat pull-secret":                                     "missing %s, get the Red Hat pull secret first",
+	"未配置 [cluster.network] ntp_servers，且 Bastion 未提供 NTP 服务；节点时钟偏差可能导致安装失败": "[cluster.network] ntp_servers is not configured and the Bastion does not provide NTP; node clock skew may cause the installation to fail",
+	"节点 %s 通过 DHCP 获取地址且未配置 ip，Bastion 不会为其生成 DNS 记录和 HAProxy 后端":           "node %s gets its address through DHCP and has no ip configured, the Bastion will not generate DNS records or HAProxy backends for it",
+	"合并 CA 证书失败: %w":                                                        "failed to merge CA certificates: %w",
+	"未找到 CA 证书，将跳过 additionalTrustBundle":                                   "no CA certificate found, skipping additionalTrustBundle",
+	"install_config.feature_set = %s，集群安装后无法升级":                             "install_config.feature_set = %s, the cluster cannot be upgraded after installation",
+	"默认第一个 Control Plane 节点":                                                "defaults to the first control plane node",
+	"读取现有 %s 失败: %w":                                                        "failed to read the existing %s: %w",
+	"生成 %s 失败: %w":                                                          "failed to generate %s: %w",
+	"读取生成的 %s 失败: %w":                                                       "failed to read the generated %s: %w",
+	"\n🆕 新文件: %s\n%s":                                                       "\n🆕 New file: %s\n%s",
+	"\n✅ 无变化: %s\n":                                                         "\n✅ Unchanged: %s\n",
+	"\n📝 已更新: %s\n%s":                                                       "\n📝 Updated: %s\n%s",
+	"解析模板 %s 失败: %w":                                                        "failed to parse template %s: %w",
+	"创建文件 %s 失败: %w":                                                        "failed to create file %s: %w",
+	"执行模板生成 %s 失败: %w":                                                      "failed to execute the template generating %s: %w",
+	"删除旧的节点池清单失败: %w":                                                       "failed to remove the old node pool manifests: %w",
+	"创建目录 %s 失败: %w":                                                        "failed to create directory %s: %w",
+	"计算节点池 %s: %d 个节点，已生成 MachineConfigPool %s":                             "Compute pool %s: %d nodes, generated MachineConfigPool %s",
+	"未找到镜像源配置文件，将跳过: %v":                                                    "image source configuration file not found, skipping: %v",
+	"生成镜像源清单失败: %w":                                                         "failed to generate the image source manifests: %w",
+	"已生成镜像源清单: %s":                                                          "Generated image source manifest: %s",
+	"Registry 为 proxy-cache 模式，镜像源指向拉取代理":                                   "Registry is in proxy-cache mode, image sources point to the pull-through proxy",
+	"为 %d 个按 tag 引用的附加镜像补充 ImageTagMirrorSet 镜像源":                           "Added ImageTagMirrorSet image sources for %d additional images referenced by tag",
+
+	// pkg/agentinstall/secrets.go
+	"additionalTrustBundle 包含 %d 个证书，来自 %s": "additionalTrustBundle contains %d certificates from %s",
+
+	// pkg/clusterlock/clusterlock.go
+	"%s (PID %d@%s，开始于 %s)": "%s (PID %d@%s, started at %s)",
+	"未知持有者":                 "unknown holder",
+	"集群正被其他 ocpack 命令使用: %s\n锁文件: %s\n确认该命令已不再运行后，可使用 --force-unlock 删除锁": "the cluster is in use by another ocpack command: %s\nlock file: %s\nonce you are sure that command is no longer running, use --force-unlock to remove the lock",
+	"获取集群锁失败: %w":    "failed to acquire the cluster lock: %w",
+	"写入集群锁持有者失败: %w": "failed to write the cluster lock holder: %w",
+	"删除锁文件失败: %w":    "failed to remove the lock file: %w",
+	"解析锁文件失败: %w":    "failed to parse the lock file: %w",
+
+	// pkg/config/architecture.go
+	"save_image.architectures 中的架构 %q 无效，可选 %s": "invalid architecture %q in save_image.architectures, valid values: %s",
+	"、": ", ",
+	"save_image.architectures 中的架构 %s 重复":                 "duplicate architecture %s in save_image.architectures",
+	"save_image.architectures 必须包含 %s，控制平面节点使用 x86_64 架构": "save_image.architectures must include %s, the control plane nodes use the x86_64 architecture",
+
+	// pkg/config/bastion_dns.go
+	"bastion.dns.forwarders 中的 %q 不是有效的 IP 地址":                  "%q in bastion.dns.forwarders is not a valid IP address",
+	"bastion.dns.conditional_forwarders 中的域名 %q 无效":             "invalid domain %q in bastion.dns.conditional_forwarders",
+	"bastion.dns.conditional_forwarders 不能转发集群域 %s":             "bastion.dns.conditional_forwarders cannot forward the cluster domain %s",
+	"bastion.dns.conditional_forwarders 中的域名 %s 重复":             "duplicate domain %s in bastion.dns.conditional_forwarders",
+	"bastion.dns.conditional_forwarders 中的域 %s 必须配置 servers":    "domain %s in bastion.dns.conditional_forwarders must set servers",
+	"bastion.dns.conditional_forwarders 中域 %s 的 %q 不是有效的 IP 地址": "domain %s in bastion.dns.conditional_forwarders: %q is not a valid IP address",
+	"bastion.dns.records 中的记录名 %q 无效，应为相对于 %s 的名称，如 ntp":        "invalid record name %q in bastion.dns.records, it must be relative to %s, e.g. ntp",
+	"bastion.dns.records 中的 %s 与 ocpack 生成的记录冲突":                "%s in bastion.dns.records conflicts with a record generated by ocpack",
+	"bastion.dns.records 中的记录名 %s 重复":                           "duplicate record name %s in bastion.dns.records",
+	"bastion.dns.records 中 %s 的值 %q 不是有效的 IPv4 地址":              "record %s in bastion.dns.records: value %q is not a valid IPv4 address",
+	"bastion.dns.records 中 %s 的值 %q 不是有效的主机名":                   "record %s in bastion.dns.records: value %q is not a valid hostname",
+	"bastion.dns.records 中 %s 的类型 %q 不支持，支持: A、CNAME":           "record %s in bastion.dns.records: type %q is not supported, supported: A, CNAME",
+
+	// pkg/config/boot_artifacts.go
+	"bastion.keep_boot_artifacts %d 无效，不能为负数": "invalid bastion.keep_boot_artifacts %d, it cannot be negative",
+
+	// pkg/config/cache.go
+	"大小":        "size",
+	"200Gi、1Ti": "200Gi, 1Ti",
+
+	// pkg/config/catalog.go
+	"config.toml 中没有 Operator 目录 %s":                                         "config.toml has no Operator catalog %s",
+	"多个 Operator 目录包含 %s，请使用 --catalog 指定目录":                                 "several Operator catalogs contain %s, use --catalog to choose one",
+	"config.toml 中未配置 Operator 目录":                                           "no Operator catalog is configured in config.toml",
+	"没有 Operator 目录的 ops 包含 %s，请使用 --catalog 指定目录":                           "no Operator catalog lists %s in ops, use --catalog to choose a catalog",
+	"operator_catalogs[%d] 的 catalog 不能为空":                                   "catalog of operator_catalogs[%d] cannot be empty",
+	"operator_catalogs[%d] %s 的 ops 不能为空":                                    "ops of operator_catalogs[%d] %s cannot be empty",
+	"operator_catalogs[%d] 的 OCI 目录 %s 缺少路径":                                 "operator_catalogs[%d]: OCI catalog %s is missing a path",
+	"operator_catalogs[%d] 的 target_catalog %s 不能包含标签或摘要":                    "operator_catalogs[%d]: target_catalog %s cannot contain a tag or digest",
+	"operator_catalogs[%d] 的 target_tag %s 无效":                               "operator_catalogs[%d]: invalid target_tag %s",
+	"operator_catalogs[%d] 的 CatalogSource 名称 %s 无效，只能包含小写字母、数字和 '-'，且以字母开头": "operator_catalogs[%d]: invalid CatalogSource name %s, it may only contain lowercase letters, digits and '-' and must start with a letter",
+	"目录 %s 和 %s 的 CatalogSource 名称均为 %s，请设置 catalog_source_name":             "catalogs %s and %s both use the CatalogSource name %s, set catalog_source_name",
+	"OCI 目录 %s 无效，缺少 %s: %w":                                                 "invalid OCI catalog %s, missing %s: %w",
+	"save_image.catalog_cache_ttl %q 必须是非负的时长，如 \"6h\"、\"48h\" 或 \"0\"":      "save_image.catalog_cache_ttl %q must be a non-negative duration, e.g. \"6h\", \"48h\" or \"0\"",
+
+	// pkg/config/channel.go
+	"无法查询通道 %s 中的版本 (%v)，继续使用该通道":                            "cannot query the versions in channel %s (%v), keeping that channel",
+	"通道 %s 中未找到版本 %s，按 cluster_info.channel 的配置继续使用该通道":      "channel %s does not contain version %s, keeping that channel as configured in cluster_info.channel",
+	"通道 %s 中未找到版本 %s，且无法查询通道 %s (%v)，继续使用 %s":                "channel %s does not contain version %s and channel %s cannot be queried (%v), keeping %s",
+	"版本 %s 尚未进入通道 %s，改用 %s":                                  "version %s is not in channel %s yet, using %s instead",
+	"通道 %s 中均未找到版本 %s，请检查 openshift_version":                 "none of the channels %s contain version %s, check openshift_version",
+	"openshift_version_min %s 不能高于 openshift_version_max %s": "openshift_version_min %s cannot be higher than openshift_version_max %s",
+	"openshift_version %s 不在镜像版本范围 %s - %s 内":                "openshift_version %s is outside the mirrored version range %s - %s",
+	"升级通道 %s 无效，cluster_info.channel 只能是 %s 或完整的通道名称":        "invalid update channel %s, cluster_info.channel must be %s or a full channel name",
+	"升级通道 %s 无效，版本格式应为 <主版本>.<次版本>":                          "invalid update channel %s, the version must be <major>.<minor>",
+	"升级通道 %s 无效，EUS 通道只适用于偶数次版本":                             "invalid update channel %s, EUS channels only exist for even minor versions",
+
+	// pkg/config/compute_pool.go
+	"%s 的名称无效，只能包含小写字母、数字和 -":                                    "%s has an invalid name, it may only contain lowercase letters, digits and -",
+	"%s 的名称不能为 worker 或 master，不属于任何节点池的 worker 节点即为 worker 节点池": "%s cannot be named worker or master, worker nodes that belong to no pool form the worker pool",
+	"%s 的名称重复": "%s has a duplicate name",
+	"%s 中没有节点，请在 [[cluster.worker]] 中设置 pool = %q":                      "%s has no nodes, set pool = %q in [[cluster.worker]]",
+	"%s 的 replicas = %d，但有 %d 个 worker 节点设置了 pool = %q":                 "%s has replicas = %d, but %d worker nodes set pool = %q",
+	"%s 的架构 %q 无效，可选 %s":                                                "%s: invalid architecture %q, valid values: %s",
+	"%s 的架构 %s 不在 save_image.architectures 中，私有仓库中没有该架构的镜像":             "%s: architecture %s is not in save_image.architectures, the mirror registry has no images for it",
+	"%s 的标签 %s=%s 无效":                                                   "%s: invalid label %s=%s",
+	"%s 的污点 %s 无效":                                                      "%s: invalid taint %s",
+	"%s 的污点 %s 的 effect 无效，可选 %s、%s、%s":                                 "%s: taint %s has an invalid effect, valid values: %s, %s, %s",
+	"control Plane节点[%d] %s 不能设置 pool，节点池只适用于 worker 节点":                "control plane node[%d] %s cannot set pool, compute pools only apply to worker nodes",
+	"worker节点[%d] %s 的 pool %q 未在 [[cluster.compute_pool]] 中定义":         "worker node[%d] %s: pool %q is not defined in [[cluster.compute_pool]]",
+	"install-config.yaml 只有一个 compute 节点池，所有 worker 节点的架构必须相同 (当前: %s)": "install-config.yaml has a single compute pool, so all worker nodes must share one architecture (currently: %s)",
+
+	// pkg/config/config.go
+	"⚠️  配置文件 %s 的格式版本为 %d，已按版本 %d 读取，执行 ocpack migrate-config 升级文件\n": "⚠️  Config file %s uses format version %d and was read as version %d, run ocpack migrate-config to upgrade the file\n",
+	"序列化配置失败: %w": "failed to serialize the configuration: %w",
+	"OpenShift 版本 %s 不受支持，最低支持 4.10 (4.10 至 4.13 的 release 通过 oc adm release mirror 镜像)": "OpenShift version %s is not supported, the minimum is 4.10 (releases 4.10 to 4.13 are mirrored with oc adm release mirror)",
+	"至少需要配置一个Control Plane节点":                                      "at least one Control Plane node must be configured",
+	"control Plane节点[%d] %s 的IP不能为空 (通过 DHCP 获取地址时设置 dhcp = true)": "IP of control plane node[%d] %s cannot be empty (set dhcp = true to get the address through DHCP)",
+	"control Plane节点[%d] %s 的MAC地址不能为空":                            "MAC address of control plane node[%d] %s cannot be empty",
+	"worker节点[%d] %s 的IP不能为空 (通过 DHCP 获取地址时设置 dhcp = true)":        "IP of worker node[%d] %s cannot be empty (set dhcp = true to get the address through DHCP)",
+	"worker节点[%d] %s 的MAC地址不能为空":                                   "MAC address of worker node[%d] %s cannot be empty",
+	"NTP服务器[%d]不能为空":                                               "NTP server[%d] cannot be empty",
+	"集群域名不能为空":                                                     "cluster domain cannot be empty",
+	"bastion.enabled = false，无需部署 Bastion 节点":                      "bastion.enabled = false, there is no Bastion node to deploy",
+	"Bastion节点IP不能为空":                                              "Bastion node IP cannot be empty",
+	"Bastion节点用户名不能为空":                                             "Bastion node username cannot be empty",
+	"Bastion节点必须提供SSH密钥或密码":                                        "Bastion node requires an SSH key or password",
+	"registry节点IP不能为空（Bastion需要配置Registry的DNS解析）":                  "registry node IP cannot be empty (the Bastion needs it for the Registry DNS record)",
+	"至少需要配置一个control Plane节点":                                      "at least one control plane node must be configured",
+	"control Plane节点[%d]名称不能为空":                                    "name of control plane node[%d] cannot be empty",
+	"control Plane节点[%d] %s 的IP不能为空":                               "IP of control plane node[%d] %s cannot be empty",
+	"worker节点[%d]名称不能为空":                                           "name of worker node[%d] cannot be empty",
+	"worker节点[%d] %s 的IP不能为空":                                      "IP of worker node[%d] %s cannot be empty",
+	"集群网络CIDR不能为空":                                                 "cluster network CIDR cannot be empty",
+	"服务网络CIDR不能为空":                                                 "service network CIDR cannot be empty",
+	"机器网络CIDR不能为空":                                                 "machine network CIDR cannot be empty",
+	"集群ID不能为空":                                                     "cluster ID cannot be empty",
+	"registry节点IP不能为空":                                             "registry node IP cannot be empty",
+	"registry节点用户名不能为空":                                            "registry node username cannot be empty",
+	"registry节点必须提供SSH密钥或密码":                                       "registry node requires an SSH key or password",
+	"registry节点存储路径不能为空":                                           "registry node storage path cannot be empty",
+	"OpenShift 客户端工具":                                              "OpenShift client",
+	"Kubernetes 客户端工具":                                             "Kubernetes client",
+	"OpenShift 镜像同步工具 (可选)":                                        "OpenShift image mirroring tool (optional)",
+	"缺少必需的文件: %s (%s)\n请先运行 'ocpack download' 命令下载所需文件":            "missing required file: %s (%s)\nrun 'ocpack download' first to download the required files",
+	"ℹ️  可选文件不存在: %s (%s)\n":                                       "ℹ️  Optional file not found: %s (%s)\n",
+	"OpenShift版本不能为空":                                              "OpenShift version cannot be empty",
+
+	// pkg/config/dns_hosts.go
+	"集群目录 %s 与 cluster_info.cluster_id (%s) 不一致，仓库等主机名将使用 %s":      "cluster directory %s does not match cluster_info.cluster_id (%s), hostnames such as the registry will use %s",
+	"bastion.dns.mode %q 不支持，支持: %s、%s":                            "bastion.dns.mode %q is not supported, supported: %s, %s",
+	"bastion.dns.mode = %q 时节点 %s 必须配置 ip (DHCP 节点填写保留地址)":         "with bastion.dns.mode = %q node %s must set ip (use the reserved address for DHCP nodes)",
+	"bastion.dns.mode = %q 时 bastion.dns.records 只支持 A 记录，%s 为 %s": "with bastion.dns.mode = %q bastion.dns.records only supports A records, %s is %s",
+
+	// pkg/config/external_dns.go
+	"infra.external_dns.url %q 必须是 http 或 https 地址":                  "infra.external_dns.url %q must be an http or https URL",
+	"infra.external_dns.provider = \"exec\" 时必须配置 command":           "infra.external_dns.provider = \"exec\" requires command",
+	"infra.external_dns.provider %q 无效，可选值: %s、%s 或插件名称 (执行 %s<名称>)": "invalid infra.external_dns.provider %q, valid values: %s, %s or a plugin name (runs %s<name>)",
+	"infra.external_dns.ttl 不能为负数":                                   "infra.external_dns.ttl cannot be negative",
+	"infra.external_dns.zone %q 不包含集群域 %s":                           "infra.external_dns.zone %q does not contain the cluster domain %s",
+	"infra.external_dns.resolvers[%d] %q 不是有效的 IP 地址":                "infra.external_dns.resolvers[%d] %q is not a valid IP address",
+	"infra.external_dns.propagation_timeout %q 必须是正的时长，如 \"10m\"":    "infra.external_dns.propagation_timeout %q must be a positive duration, e.g. \"10m\"",
+
+	// pkg/config/haproxy.go
+	"bastion.haproxy.%s %d 无效，必须在 1-65535 之间": "invalid bastion.haproxy.%s %d, it must be between 1 and 65535",
+	"PXE HTTP 服务": "PXE HTTP service",
+	"bastion.haproxy.%s %d 与 %s 的端口冲突":                     "bastion.haproxy.%s %d conflicts with the %s port",
+	"bastion.haproxy 的 stats_user 和 stats_password 必须同时设置": "stats_user and stats_password of bastion.haproxy must be set together",
+
+	// pkg/config/hooks.go
+	"hooks.%s 无效，支持的阶段: %s (前缀 pre_ 或 post_)": "invalid hooks.%s, supported stages: %s (prefixed with pre_ or post_)",
+	"hooks.%s[%d] 不能为空":                       "hooks.%s[%d] cannot be empty",
+
+	// pkg/config/image_retry.go
+	"save_image.retry.attempts 不能为负数":               "save_image.retry.attempts cannot be negative",
+	"save_image.retry.delay %q 无效，应为非负的时长，如 \"2s\"": "invalid save_image.retry.delay %q, it must be a non-negative duration, e.g. \"2s\"",
+
+	// pkg/config/images_file.go
+	"%s 第 %d 行不是合法的镜像引用: %s": "%s line %d is not a valid image reference: %s",
+	"读取镜像列表失败: %w":           "failed to read the image list: %w",
+	"镜像列表 %s 中没有镜像":          "image list %s contains no images",
+
+	// pkg/config/infra.go
+	"infra.dns_servers[%d] %q 不是有效的 IP 地址":                                     "infra.dns_servers[%d] %q is not a valid IP address",
+	"infra.rendezvous_ip %s 必须是某个 Control Plane 节点的 IP":                        "infra.rendezvous_ip %s must be the IP of a Control Plane node",
+	"infra.bootstrap_node %s 必须是某个 Control Plane 节点的名称":                        "infra.bootstrap_node %s must be the name of a Control Plane node",
+	"infra.rendezvous_ip %s 与 infra.bootstrap_node %s 的 IP %s 不一致，只需配置其中一项":    "infra.rendezvous_ip %s does not match the IP of infra.bootstrap_node %s (%s), set only one of them",
+	"infra.trust_bundle_paths[%d] 不能为空":                                        "infra.trust_bundle_paths[%d] cannot be empty",
+	"infra.ca_bundle 不能为空白":                                                    "infra.ca_bundle cannot be blank",
+	"infra.trust_bundle_policy %q 无效，可选值: %s、%s":                               "invalid infra.trust_bundle_policy %q, valid values: %s, %s",
+	"infra.pxe_asset_url %q 必须是 http:// 或 https:// 地址":                         "infra.pxe_asset_url %q must be an http:// or https:// URL",
+	"bastion.enabled = false 时必须配置 infra.dns_servers":                          "infra.dns_servers is required when bastion.enabled = false",
+	"bastion.enabled = false 时必须配置 infra.load_balancer":                        "infra.load_balancer is required when bastion.enabled = false",
+	"bastion.enabled = false 时必须配置 infra.rendezvous_ip 或 infra.bootstrap_node": "infra.rendezvous_ip or infra.bootstrap_node is required when bastion.enabled = false",
+
+	// pkg/config/install_config.go
+	"install_config.feature_set %q 不支持，支持: %s、%s、%s":                                    "install_config.feature_set %q is not supported, supported: %s, %s, %s",
+	"install_config.feature_gates 只能与 feature_set = %q 一起使用":                            "install_config.feature_gates can only be used with feature_set = %q",
+	"install_config.feature_gates 中的 %q 格式无效，应为 <特性名称>=true 或 <特性名称>=false":             "invalid format %q in install_config.feature_gates, it must be <feature>=true or <feature>=false",
+	"install_config.capabilities 需要 OpenShift 4.11 及以上版本，当前为 %s":                        "install_config.capabilities requires OpenShift 4.11 or later, the current version is %s",
+	"install_config.capabilities.baseline_capability_set %s 不适用于 OpenShift %s":          "install_config.capabilities.baseline_capability_set %s does not apply to OpenShift %s",
+	"install_config.capabilities.baseline_capability_set %q 无效，应为 None、vCurrent 或 v4.x": "invalid install_config.capabilities.baseline_capability_set %q, it must be None, vCurrent or v4.x",
+	"install_config.capabilities.additional_enabled_capabilities 中的 %q 不是已知的组件，支持: %s":  "%q in install_config.capabilities.additional_enabled_capabilities is not a known capability, supported: %s",
+	"组件 %s 需要 OpenShift %s 及以上版本，当前为 %s":                                                "capability %s requires OpenShift %s or later, the current version is %s",
+	"install_config.capabilities.additional_enabled_capabilities 中的 %s 重复":              "duplicate %s in install_config.capabilities.additional_enabled_capabilities",
+	"组件 %s 依赖 %s，请将其加入 install_config.capabilities.additional_enabled_capabilities":     "capability %s depends on %s, add it to install_config.capabilities.additional_enabled_capabilities",
+
+	// pkg/config/migrate.go
+	"解析配置文件失败: %w": "failed to parse the config file: %w",
+	"配置文件版本 %d 高于当前 ocpack 支持的版本 %d，请升级 ocpack": "config file version %d is newer than version %d supported by this ocpack, upgrade ocpack",
+	"统一集群标识与网络配置位置":                             "unify the cluster ID and network settings",
+	"已添加 config_version = %d":                   "added config_version = %d",
+	"序列化升级后的配置失败: %w":                           "failed to serialize the upgraded configuration: %w",
+	"备份配置文件失败: %w":                              "failed to back up the config file: %w",
+	"写入升级后的配置文件失败: %w":                          "failed to write the upgraded config file: %w",
+	"config_version 必须是非负整数，当前值: %v":            "config_version must be a non-negative integer, current value: %v",
+	"[cluster_info] name 已重命名为 cluster_id":      "[cluster_info] name renamed to cluster_id",
+	"[cluster_info] 已移除与 cluster_id 重复的 name":   "[cluster_info] removed name, which duplicated cluster_id",
+	"顶层 [network] 已移动到 [cluster.network]":       "top-level [network] moved to [cluster.network]",
+	`[download] 已补全 local_path = "downloads"`:   `[download] added local_path = "downloads"`,
+
+	// pkg/config/mirror_order.go
+	"%s 中的分组 %q 无效，可选值: %v": "%s: invalid group %q, valid values: %v",
+	"%s 中的分组 %q 重复":         "%s: duplicate group %q",
+
+	// pkg/config/mirror_port.go
+	"save_image.local_storage_port %d 无效，必须在 1024-65535 之间": "invalid save_image.local_storage_port %d, it must be between 1024 and 65535",
+
+	// pkg/config/mirror_target.go
+	"save_image.target_namespace %q 无效，每一段只能包含小写字母、数字和 '.'、'_'、'-'": "invalid save_image.target_namespace %q, each segment may only contain lowercase letters, digits and '.', '_', '-'",
+
+	// pkg/config/network.go
+	"cluster.network.%s %q 不是有效的 CIDR": "cluster.network.%s %q is not a valid CIDR",
+	"cluster.network.%s %s 与 %s %s 重叠": "cluster.network.%s %s overlaps %s %s",
+	"cluster.network.host_prefix %d 无效，需要大于 cluster_network 的前缀长度 %d 且不超过 %d": "invalid cluster.network.host_prefix %d, it must be greater than the cluster_network prefix length %d and at most %d",
+
+	// pkg/config/node.go
+	"%s节点[%d] %s 的 cpu、memory_gb 和 disk_gb 不能为负数":                                        "%s node[%d] %s: cpu, memory_gb and disk_gb cannot be negative",
+	"%s节点[%d] %s 的 bmc_address %q 不能包含空白字符":                                              "%s node[%d] %s: bmc_address %q cannot contain whitespace",
+	"%s节点[%d] %s 的 console_url %q 必须是 http 或 https 地址":                                   "%s node[%d] %s: console_url %q must be an http or https URL",
+	"infra.bootstrap_node %s 是 DHCP 节点，rendezvous 节点必须使用静态 IP":                           "infra.bootstrap_node %s is a DHCP node, the rendezvous node must use a static IP",
+	"所有 Control Plane 节点都配置了 dhcp = true，至少需要一个静态 IP 的 Control Plane 节点作为 rendezvous 节点": "all Control Plane nodes set dhcp = true, at least one Control Plane node with a static IP is needed as the rendezvous node",
+	"rendezvous IP %s 属于 DHCP 节点 %s，rendezvous 节点必须使用静态 IP":                              "rendezvous IP %s belongs to DHCP node %s, the rendezvous node must use a static IP",
+
+	// pkg/config/node_address.go
+	"%s节点[%d] %s": "%s node[%d] %s",
+	"%s 的MAC地址 %q 格式无效，应为 xx:xx:xx:xx:xx:xx":            "%s: invalid MAC address %q, it must be xx:xx:xx:xx:xx:xx",
+	"%s 的MAC地址 %s 与 %s 重复":                              "%s: MAC address %s duplicates %s",
+	"%s 的IP %q 无效":                                      "%s: invalid IP %q",
+	"%s 的IP %s 与 %s 重复":                                 "%s: IP %s duplicates %s",
+	"%s 的IP %s 不在 cluster.network.machine_network %s 中": "%s: IP %s is not in cluster.network.machine_network %s",
+	"%s %q 无效":         "%s %q is invalid",
+	"%s %s 与 %s 的IP相同": "%s %s is the same as the IP of %s",
+
+	// pkg/config/node_boot.go
+	"%s节点[%d] %s 的 root_device %q 必须是 /dev/ 下的设备路径":              "%s node[%d] %s: root_device %q must be a device path under /dev/",
+	"%s节点[%d] %s 的 kernel_args %q 无效，每项只能包含一个参数且不能包含空白字符或引号":     "%s node[%d] %s: invalid kernel_args %q, each entry must be a single argument without whitespace or quotes",
+	"%s节点[%d] %s 同时配置了 console 和 kernel_args 中的 %s，只能使用其中一种":     "%s node[%d] %s sets both console and %s in kernel_args, use only one of them",
+	"%s节点[%d] %s 的 console %q 格式无效，应为 tty0 或 ttyS0,115200n8 的形式": "%s node[%d] %s: invalid console %q, it must look like tty0 or ttyS0,115200n8",
+
+	// pkg/config/notify.go
+	"notify.webhooks[%d].url %q 必须是 http:// 或 https:// 地址": "notify.webhooks[%d].url %q must be an http:// or https:// URL",
+	"notify.webhooks[%d].format %s 无效，支持: %s":              "invalid notify.webhooks[%d].format %s, supported: %s",
+	"notify.smtp 需要配置 host":                                "notify.smtp requires host",
+	"notify.smtp 需要配置 from 和 to":                           "notify.smtp requires from and to",
+	"notify.smtp.port %d 无效":                               "invalid notify.smtp.port %d",
+	"notify.smtp 的 username 和 password 需要同时配置":             "username and password of notify.smtp must be set together",
+
+	// pkg/config/presets.go
+	"save_image.presets 中的 %q 无效，支持: %s": "invalid %q in save_image.presets, supported: %s",
+
+	// pkg/config/problem.go
+	"第 %d 行第 %d 列: %s": "line %d, column %d: %s",
+	"升级配置文件失败: %v":     "failed to upgrade the config file: %v",
+
+	// pkg/config/proxy_cache.go
+	"registry.mirror_mode %q 无效，可选值: %s、%s":                      "invalid registry.mirror_mode %q, valid values: %s, %s",
+	"registry.proxy_cache[%d] 的 source %q 无效，应为仓库地址，如 quay.io":   "registry.proxy_cache[%d]: invalid source %q, it must be a registry address such as quay.io",
+	"registry.proxy_cache 中的 source %s 重复":                       "duplicate source %s in registry.proxy_cache",
+	"registry.proxy_cache[%d] %s 的 port %d 无效，必须在 1024-65535 之间": "registry.proxy_cache[%d] %s: invalid port %d, it must be between 1024 and 65535",
+	"registry.proxy_cache[%d] %s 的 port %d 与 %s 冲突":              "registry.proxy_cache[%d] %s: port %d conflicts with %s",
+
+	// pkg/config/quay.go
+	"配额":        "quota",
+	"500Gi、2Ti": "500Gi, 2Ti",
+	"%s %q 无效，应为数字加单位，如 %s": "invalid %s %q, it must be a number with a unit, e.g. %s",
+	"%s %q 无效，必须大于 0":       "invalid %s %q, it must be greater than 0",
+	"registry.quay.organizations[%d] 的 name %q 无效，只能包含小写字母、数字和 '.'、'_'、'-'": "registry.quay.organizations[%d]: invalid name %q, it may only contain lowercase letters, digits and '.', '_', '-'",
+	"registry.quay.organizations 中的组织 %s 重复":                                "duplicate organization %s in registry.quay.organizations",
+	"registry.quay.organizations[%d] %s 的 visibility":                       "visibility of registry.quay.organizations[%d] %s",
+	"registry.quay.organizations[%d] %s 的 quota: %v":                        "quota of registry.quay.organizations[%d] %s: %v",
+	"registry.quay.organizations[%d] %s 的仓库 %q 无效":                          "registry.quay.organizations[%d] %s: invalid repository %q",
+	"%s %q 无效，可选值: %s、%s":                                                   "invalid %s %q, valid values: %s, %s",
+
+	// pkg/config/registry_auth.go
+	"registry.auths[%d] 的 host 不能为空":                  "host of registry.auths[%d] cannot be empty",
+	"registry.auths[%d] 的 host %s 不能包含协议前缀":           "registry.auths[%d]: host %s cannot contain a scheme",
+	"registry.auths[%d] %s 的 username 不能为空":           "registry.auths[%d] %s: username cannot be empty",
+	"registry.auths[%d] %s 的 password 和 token 只能设置一个": "registry.auths[%d] %s: set only one of password and token",
+	"registry.auths[%d] %s 必须设置 password 或 token":     "registry.auths[%d] %s must set password or token",
+	"registry.auths[%d] 的 host %s 重复":                 "registry.auths[%d]: duplicate host %s",
+
+	// pkg/config/registry_storage.go
+	"registry.storage.device %q 无效，应为块设备路径，如 /dev/sdb":                                    "invalid registry.storage.device %q, it must be a block device path such as /dev/sdb",
+	"registry.storage.filesystem %q 无效，可选值: %s、%s":                                        "invalid registry.storage.filesystem %q, valid values: %s, %s",
+	"配置 registry.storage.device 时 registry.storage_path %q 必须是单独的挂载点，如 /var/lib/registry": "with registry.storage.device set, registry.storage_path %q must be a dedicated mount point such as /var/lib/registry",
+	"registry.storage.quota 使用 XFS 项目配额，需要配置 registry.storage.device 且 filesystem 为 %s":   "registry.storage.quota uses XFS project quotas and requires registry.storage.device with filesystem %s",
+	"registry.storage.min_free (%s) 不能大于 quota (%s)":                                      "registry.storage.min_free (%s) cannot be greater than quota (%s)",
+
+	// pkg/config/registry_tls.go
+	"save_image.registry_tls[%d] 的 registry 不能为空":                         "registry of save_image.registry_tls[%d] cannot be empty",
+	"save_image.registry_tls[%d] 的 registry %q 必须是 host[:port]，不能包含协议或路径": "save_image.registry_tls[%d]: registry %q must be host[:port] without a scheme or path",
+	"save_image.registry_tls 中的仓库 %s 重复":                                  "duplicate registry %s in save_image.registry_tls",
+	"save_image.registry_tls 中的仓库 %s 不能同时配置 ca_file 和 insecure":           "registry %s in save_image.registry_tls cannot set both ca_file and insecure",
+	"save_image.registry_tls 中的仓库 %s 需要配置 ca_file 或 insecure = true":      "registry %s in save_image.registry_tls requires ca_file or insecure = true",
+
+	// pkg/config/rpms.go
+	"rpms.repos[%d] 不能为空":          "rpms.repos[%d] cannot be empty",
+	"rpms.extra_packages[%d] 不能为空": "rpms.extra_packages[%d] cannot be empty",
+
+	// pkg/config/scan.go
+	"scan.scanner 为 command 时必须设置 scan.command": "scan.command is required when scan.scanner is command",
+	"scan.scanner %s 无效，支持: %s, %s":             "invalid scan.scanner %s, supported: %s, %s",
+	"scan.fail_on %s 无效，支持: %s":                 "invalid scan.fail_on %s, supported: %s",
+
+	// pkg/config/signing.go
+	"设置 save_image.signing.key 或 public_key 时需要同时设置 method (%s 或 %s)":                 "save_image.signing.key or public_key requires method (%s or %s)",
+	"save_image.signing.method = %q 时需要设置 key (save-image) 或 public_key (load-image)": "save_image.signing.method = %q requires key (save-image) or public_key (load-image)",
+	"save_image.signing.method %q 无效，可选值: %s、%s":                                      "invalid save_image.signing.method %q, valid values: %s, %s",
+
+	// pkg/config/sizing.go
+	"单节点 (SNO)":                 "single-node (SNO)",
+	"紧凑集群":                      "compact cluster",
+	"标准集群":                      "standard cluster",
+	"%s Control Plane 节点 %s %s": "%s Control Plane node %s %s",
+	"Worker 节点 %s %s":           "Worker node %s %s",
+	"低于 OpenShift 最低要求: %s":     "is below the OpenShift minimum: %s",
+
+	// pkg/config/storage.go
+	"save_image.storage.url %q 必须是绝对路径，如 file:///mnt/nfs/ocp-images":      "save_image.storage.url %q must be an absolute path such as file:///mnt/nfs/ocp-images",
+	"save_image.storage 的 endpoint、access_key 和 secret_key 只适用于 s3:// 存储": "endpoint, access_key and secret_key of save_image.storage only apply to s3:// storage",
+	"save_image.storage.url %q 无效，格式为 s3://<bucket>/<前缀>":                 "invalid save_image.storage.url %q, the format is s3://<bucket>/<prefix>",
+	"save_image.storage.endpoint %q 必须是 http 或 https 地址":                  "save_image.storage.endpoint %q must be an http or https URL",
+	"save_image.storage 的 access_key 和 secret_key 必须同时设置":                 "access_key and secret_key of save_image.storage must be set together",
+	"save_image.storage.url %q 不支持，支持: file://<路径>、s3://<bucket>/<前缀>":    "save_image.storage.url %q is not supported, supported: file://<path>, s3://<bucket>/<prefix>",
+
+	// pkg/config/update_url.go
+	"save_image.update_url_override %q 必须是 http 或 https 地址，如 https://osus.example.com/api/upgrades_info/graph": "save_image.update_url_override %q must be an http or https URL such as https://osus.example.com/api/upgrades_info/graph",
+	"save_image.graph_cache_ttl %q 必须是非负的时长，如 \"30m\"、\"6h\" 或 \"0\"":                                          "save_image.graph_cache_ttl %q must be a non-negative duration, e.g. \"30m\", \"6h\" or \"0\"",
+
+	// pkg/config/worker.go
+	"读取配置文件失败: %w":         "failed to read the config file: %w",
+	"写入配置文件失败: %w":         "failed to write the config file: %w",
+	"节点名称 %s 已存在":          "node name %s already exists",
+	"IP %s 已被节点 %s 使用":     "IP %s is already used by node %s",
+	"MAC 地址 %s 已被节点 %s 使用": "MAC address %s is already used by node %s",
+
+	// pkg/deploy/ansible_executor.go
+	"创建变量文件失败: %w":                         "failed to create the variables file: %w",
+	"解析配置文件路径失败: %w":                       "failed to resolve the configuration file path: %w",
+	"未找到 ansible-playbook 命令，请先安装 Ansible": "ansible-playbook not found, install Ansible first",
+	"读取嵌入文件 %s 失败: %w":                     "failed to read embedded file %s: %w",
+	"写入文件 %s 失败: %w":                       "failed to write file %s: %w",
+	"提取 Ansible 文件失败: %w":                  "failed to extract the Ansible files: %w",
+	"读取 inventory 模板失败: %w":                "failed to read the inventory template: %w",
+	"解析 inventory 模板失败: %w":                "failed to parse the inventory template: %w",
+	"创建 inventory 文件失败: %w":                "failed to create the inventory file: %w",
+	"生成 inventory 文件失败: %w":                "failed to generate the inventory file: %w",
+	"执行 Ansible playbook: %s\n":            "Running Ansible playbook: %s\n",
+	"使用 inventory: %s\n":                   "Using inventory: %s\n",
+	"工作目录: %s\n":                           "Working directory: %s\n",
+	"执行 Ansible playbook 失败: %w":           "Ansible playbook failed: %w",
+
+	// pkg/deploy/bastion.go
+	"▶️  开始部署 Bastion 节点 (%s)...\n":              "▶️  Deploying the Bastion node (%s)...\n",
+	"➡️  正在初始化部署环境...":                           "➡️  Initializing the deployment environment...",
+	"生成 hosts 模式 DNS 配置失败: %w":                   "failed to generate the hosts mode DNS configuration: %w",
+	"📝 DNS 使用 hosts 模式，已生成: %s\n":                "📝 DNS uses hosts mode, generated: %s\n",
+	"🚀 正在执行 Bastion 部署 playbook (此过程可能需要几分钟)...": "🚀 Running the Bastion deployment playbook (this may take a few minutes)...",
+	"bastion节点部署失败: %w":                          "Bastion node deployment failed: %w",
+	"\n✅ Bastion 节点部署完成！":                        "\n✅ Bastion node deployed!",
+	"   DNS 服务器: %s:%d\n":                        "   DNS server: %s:%d\n",
+	"   DNS 模式: hosts (dnsmasq)，管理员工作站可使用 ocpack dns-hosts 生成的 /etc/hosts 片段\n": "   DNS mode: hosts (dnsmasq); admin workstations can use the /etc/hosts snippet generated by ocpack dns-hosts\n",
+	"   HAProxy 统计页面: http://%s:%d/stats\n":                                     "   HAProxy stats page: http://%s:%d/stats\n",
+	"   统计页面用户: %s (密码见 [bastion.haproxy] stats_password)\n":                    "   Stats page user: %s (password in [bastion.haproxy] stats_password)\n",
+
+	// pkg/deploy/pxe.go
+	"bastion.enabled = false，PXE 服务需部署在 Bastion 节点上；请使用站点已有的 PXE 服务并配置 infra.pxe_asset_url": "bastion.enabled = false, the PXE service must be deployed on the Bastion node; use the existing site PXE service and configure infra.pxe_asset_url",
+	"开始在 Bastion 节点 (%s) 上部署 PXE 服务...\n":                                                   "Deploying the PXE service on the Bastion node (%s)...\n",
+	"创建 Ansible 执行器失败: %w": "failed to create the Ansible executor: %w",
+	"PXE 服务部署失败: %w":       "PXE service deployment failed: %w",
+	"PXE 服务部署完成！":          "PXE service deployed!",
+	"PXE 服务器: %s\n":        "PXE server: %s\n",
+	"TFTP 服务: tftp://%s\n": "TFTP service: tftp://%s\n",
+	"HTTP 服务: http://%s:8080/pxe (端口8080避免与HAProxy冲突)\n": "HTTP service: http://%s:8080/pxe (port 8080 avoids conflicts with HAProxy)\n",
+	"DHCP 服务: %s (已配置MAC-IP映射)\n":                        "DHCP service: %s (MAC-IP mapping configured)\n",
+	"PXE 文件目录: /var/lib/tftpboot\n":                      "PXE file directory: /var/lib/tftpboot\n",
+	"HTTP 文件目录: /var/www/html/pxe\n":                     "HTTP file directory: /var/www/html/pxe\n",
+
+	// pkg/deploy/registry.go
+	"➡️  Registry 为 proxy-cache 模式，正在检查 %s 上的拉取代理...\n":  "➡️  Registry is in proxy-cache mode, checking the pull-through proxy on %s...\n",
+	"🔄 拉取代理已经部署并运行。跳过重复部署。":                              "🔄 The pull-through proxy is already deployed and running. Skipping deployment.",
+	"ℹ️  检查失败 (这通常意味着拉取代理未部署): %v\n":                     "ℹ️  Check failed (this usually means the pull-through proxy is not deployed): %v\n",
+	"🚀 开始执行拉取代理部署 playbook (%s)...\n":                    "🚀 Running the pull-through proxy deployment playbook (%s)...\n",
+	"创建ansible执行器失败: %w":                                 "failed to create the Ansible executor: %w",
+	"拉取代理部署失败: %w":                                       "pull-through proxy deployment failed: %w",
+	"无法访问 %s 的拉取代理 '%s': %w":                             "cannot reach the pull-through proxy on %s ('%s'): %w",
+	"%s 的拉取代理 '%s' 返回 %s":                                "the pull-through proxy on %s ('%s') returned %s",
+	"✅ 拉取代理部署完成！":                                        "✅ Pull-through proxy deployed!",
+	"   镜像在节点首次拉取时从上游缓存，无需执行 save-image 和 load-image":    "   Images are cached from upstream when nodes first pull them; save-image and load-image are not needed",
+	"➡️  下载目录中没有 mirror-registry 安装包，尝试从镜像存储 %s 获取...\n": "➡️  No mirror-registry installer in the download directory, trying to fetch it from image storage %s...\n",
+	"✅ Registry 部署完成！":                                   "✅ Registry deployed!",
+	"   Quay 镜像仓库: %s\n":                                 "   Quay registry: %s\n",
+	"   用户名: %s\n":                                       "   Username: %s\n",
+	"   密码: %s\n":                                        "   Password: %s\n",
+	"▶️  开始部署 Registry 节点...":                            "▶️  Deploying the Registry node...",
+	"➡️  正在检查 Registry 在 %s 的状态...\n":                    "➡️  Checking the Registry status at %s...\n",
+	"🔄 Registry 节点已经部署并运行。跳过重复部署。":                       "🔄 The Registry node is already deployed and running. Skipping deployment.",
+	"ℹ️  检查失败 (这通常意味着 Registry 未部署): %v\n":               "ℹ️  Check failed (this usually means the Registry is not deployed): %v\n",
+	"📦 mirror-registry 安装包: %s\n":                        "📦 mirror-registry installer: %s\n",
+	"🚀 Registry 未部署或不可访问，开始执行部署 playbook (%s)...\n":      "🚀 Registry is not deployed or not reachable, running the deployment playbook (%s)...\n",
+	"registry节点部署失败: %w":                                 "Registry node deployment failed: %w",
+
+	// pkg/deploy/registry_storage.go
+	"Registry 节点上不存在块设备 %s\n💡 请使用 lsblk 确认磁盘名称，或使用 /dev/disk/by-id 下不会随启动顺序变化的路径": `block device %s does not exist on the Registry node
+💡 Check the disk name with lsblk, or use a /dev/disk/by-id path that does not change with the boot order`,
+	`%s 上已有 %s 文件系统，与 registry.storage.filesystem = %s 不一致，ocpack 不会格式化已有数据的磁盘
+💡 确认磁盘上的数据不再需要后执行 wipefs -a %s，或将 filesystem 改为 %s`: `%s already has a %s file system, which differs from registry.storage.filesystem = %s; ocpack does not format disks with existing data
+💡 Run wipefs -a %s once the data on the disk is no longer needed, or change filesystem to %s`,
+	"%s 已挂载到 %s，不是 storage_path %s\n💡 请先卸载该设备并从 /etc/fstab 中删除": "%s is mounted on %s, not on storage_path %s\n💡 Unmount the device and remove it from /etc/fstab first",
+	"%s (%s 所在的文件系统)": "%s (file system of %s)",
+	`Registry 存储 %s 可用 %s，预计需要 %s
+💡 扩容该文件系统、在 [registry.storage] 中设置 device 使用单独的磁盘，或将 storage_path 改到更大的文件系统；需要的空间为镜像归档大小加 %d%% 余量，且不小于 registry.storage.min_free`: `Registry storage %s has %s available, about %s is needed
+💡 Grow the file system, set device in [registry.storage] to use a dedicated disk, or move storage_path to a larger file system; the space needed is the image archive size plus %d%% headroom, and at least registry.storage.min_free`,
+	"统计镜像归档大小失败: %w":                                          "failed to measure the image archive size: %w",
+	"➡️  正在检查 Registry 存储空间 (镜像归档 %s，预计需要 %s)...\n":           "➡️  Checking Registry storage space (image archives %s, about %s needed)...\n",
+	"➡️  正在检查 Registry 存储空间 (至少需要 %s)...\n":                   "➡️  Checking Registry storage space (at least %s needed)...\n",
+	"检查 Registry 存储失败: %v\n💡 请确认 [registry] 的 SSH 用户、密码或密钥正确": "failed to check the Registry storage: %v\n💡 Check the SSH user, password or key in [registry]",
+	"Registry 存储检查发现 %d 个问题:\n  %s":                           "Registry storage check found %d problems:\n  %s",
+	"连接 Registry 节点 %s 失败: %w":                                "failed to connect to Registry node %s: %w",
+	"无法解析 df 的输出 %q":                                          "cannot parse the df output %q",
+	"无法解析 lsblk 的输出 %q":                                       "cannot parse the lsblk output %q",
+	"无法获取存储路径的可用空间: %s":                                       "cannot get the free space of the storage path: %s",
+
+	// pkg/download/downloader.go
+	"▶️  开始下载所需工具和文件...":                    "▶️  Downloading the required tools and files...",
+	"创建下载目录失败: %w":                          "failed to create the download directory: %w",
+	"⏳ 下载目录 %s 正被其他 ocpack 进程使用，等待其完成...\n": "⏳ Download directory %s is in use by another ocpack process, waiting for it to finish...\n",
+	"锁定下载目录失败: %w":                          "failed to lock the download directory: %w",
+	"\n➡️  任务 %d/%d: %s\n":                  "\n➡️  Task %d/%d: %s\n",
+	"ℹ️  跳过 %s: OpenShift %s 的 release 通过 oc adm release mirror 镜像 (oc-mirror 需要 4.14.0 及以上版本)\n": "ℹ️  Skipping %s: the OpenShift %s release is mirrored with oc adm release mirror (oc-mirror requires 4.14.0 or later)\n",
+	"下载必需文件 '%s' 失败: %w":                 "failed to download required file '%s': %w",
+	"⚠️  下载可选文件 '%s' 失败，已跳过: %v\n":       "⚠️  Failed to download optional file '%s', skipped: %v\n",
+	"\n➡️  正在提取工具...":                    "\n➡️  Extracting tools...",
+	"提取工具失败: %w":                         "failed to extract tools: %w",
+	"\n🎉 所有下载和提取操作完成！":                   "\n🎉 All downloads and extractions are complete!",
+	"OpenShift 客户端 (oc, kubectl)":        "OpenShift client (oc, kubectl)",
+	"OpenShift 安装程序 (openshift-install)": "OpenShift installer (openshift-install)",
+	"oc-mirror 工具":                       "oc-mirror tool",
+	"Butane 工具":                          "Butane tool",
+	"Quay 镜像仓库安装包":                       "Quay mirror registry installer",
+	"✅ 文件已存在，跳过下载: %s\n":                 "✅ File already exists, skipping download: %s\n",
+	"保存文件时出错: %w":                        "failed to save the file: %w",
+	"HTTP GET 请求失败: %w":                  "HTTP GET request failed: %w",
+	"下载失败，HTTP 状态码: %d":                  "download failed, HTTP status code: %d",
+	"⬇️  %s 下载完成 (%s, %s)\n":             "⬇️  %s downloaded (%s, %s)\n",
+	"✅ 工具已从 %s 提取，跳过下载\n":                "✅ Tools already extracted from %s, skipping download\n",
+	"创建临时文件失败: %w":                       "failed to create a temporary file: %w",
+	"边下载边提取失败: %w":                       "failed to extract while downloading: %w",
+	"重命名临时文件失败: %w":                      "failed to rename the temporary file: %w",
+	"记录提取来源失败: %w":                       "failed to record the extraction source: %w",
+	"创建 bin 目录失败: %w":                    "failed to create the bin directory: %w",
+	"✅ %s 已提取，跳过。\n":                     "✅ %s already extracted, skipping.\n",
+	"ℹ️  归档文件 %s 不存在，跳过提取。\n":            "ℹ️  Archive %s does not exist, skipping extraction.\n",
+	"⚠️  清理 bin 目录时发出警告: %v\n":           "⚠️  Warning while cleaning the bin directory: %v\n",
+	"提取 '%s' 失败: %w":                     "failed to extract '%s': %w",
+	"复制 butane 工具失败: %w":                 "failed to copy the butane tool: %w",
+	"设置可执行权限失败: %w":                      "failed to set the executable permission: %w",
+	"✅ 工具提取完成。":                          "✅ Tools extracted.",
+	"删除文件 %s 失败: %w":                     "failed to remove file %s: %w",
+	"butane 源文件不存在: %s":                  "butane source file does not exist: %s",
+
+	// pkg/gate/external_dns.go
+	"%d 条外部 DNS 记录尚未生效:\n  %s\n💡 请执行 ocpack sync-dns %s 创建记录并等待传播":                                          "%d external DNS records are not in effect yet:\n  %s\n💡 Run ocpack sync-dns %s to create the records and wait for them to propagate",
+	"%s 内 %d 条外部 DNS 记录未传播到全部 DNS 服务器:\n  %s\n💡 请检查外部 DNS 的同步状态，或增大 infra.external_dns.propagation_timeout": "after %s, %d external DNS records have not propagated to all DNS servers:\n  %s\n💡 Check the sync status of the external DNS, or increase infra.external_dns.propagation_timeout",
+	"⏳ %d 条记录尚未生效，%s 后重新检查...\n":                                                                            "⏳ %d records are not in effect yet, checking again in %s...\n",
+
+	// pkg/gate/gate.go
+	"私有仓库中的 release 镜像":                   "Release images in the mirror registry",
+	"集群 DNS 记录":                           "Cluster DNS records",
+	"外部 DNS 记录传播":                         "External DNS record propagation",
+	"hosts 模式主机名解析":                       "Hostname resolution in hosts mode",
+	"Bastion 负载均衡":                        "Bastion load balancer",
+	"节点视角的名称解析和端口":                        "Name resolution and ports as seen by the nodes",
+	"私有仓库状态":                              "Mirror registry health",
+	"私有仓库认证":                              "Mirror registry authentication",
+	"就绪检查未通过 (可使用 --skip-checks 跳过):\n%w": "readiness checks failed (use --skip-checks to skip them):\n%w",
+	"私有仓库拒绝访问 (%v)\n💡 请检查 [registry] registry_user 和 registry_password 是否与部署时一致": "the mirror registry denied access (%v)\n💡 Check that [registry] registry_user and registry_password match the deployment",
+	"%v\n💡 请确认 Registry 已部署 (ocpack deploy-registry) 且本机可以解析并访问该地址":              "%v\n💡 Make sure the Registry is deployed (ocpack deploy-registry) and this host can resolve and reach it",
+	"%s: 未找到": "%s: not found",
+	"私有仓库 %s 中未找到 OpenShift %s 的 release 镜像 (%v)\n💡 请先执行 ocpack save-image 和 ocpack load-image": "mirror registry %s has no release images for OpenShift %s (%v)\n💡 Run ocpack save-image and ocpack load-image first",
+	"无法访问 %s 的拉取代理 %s: %v\n💡 请确认已执行 ocpack deploy-registry 且端口 %d 可访问":                          "cannot reach the pull-through proxy for %s at %s: %v\n💡 Make sure ocpack deploy-registry has been run and port %d is reachable",
+	"%s 的拉取代理访问上游被拒绝 (%s)\n💡 请检查 pull-secret.txt 中 %s 的认证并重新执行 ocpack deploy-registry":          "the pull-through proxy for %s was denied by the upstream (%s)\n💡 Check the credentials for %s in pull-secret.txt and run ocpack deploy-registry again",
+	"%s 的拉取代理 %s 返回 %s\n💡 请确认 Registry 节点可以访问 %s":                                               "the pull-through proxy for %s at %s returned %s\n💡 Make sure the Registry node can reach %s",
+	"未配置 DNS 服务器\n💡 请配置 [bastion] ip 或 [infra] dns_servers":                                     "no DNS server is configured\n💡 Configure [bastion] ip or [infra] dns_servers",
+	"请确认已执行 ocpack deploy-bastion":         "Make sure ocpack deploy-bastion has been run",
+	"请执行 ocpack sync-dns %s 在外部 DNS 中创建记录": "Run ocpack sync-dns %s to create the records in the external DNS",
+	"请在站点 DNS 中添加 api、api-int 和 *.apps 记录": "Add the api, api-int and *.apps records to the site DNS",
+	"DNS 服务器 %s 无法解析 %s: %v\n💡 %s":         "DNS server %s cannot resolve %s: %v\n💡 %s",
+	"%s 解析为 %v，未指向负载均衡 %s\n💡 %s":           "%s resolves to %v, not to the load balancer %s\n💡 %s",
+	"%s @%s 解析为 %v，期望 %s":                  "%s @%s resolves to %v, expected %s",
+	"%d 个名称解析失败:\n  %s\n💡 请执行 ocpack deploy-bastion 更新 Bastion 上的 dnsmasq，或检查 ocpack dns-hosts 生成的文件": "%d names failed to resolve:\n  %s\n💡 Run ocpack deploy-bastion to update dnsmasq on the Bastion, or check the files generated by ocpack dns-hosts",
+	"无法访问 HAProxy 统计页面 %s: %v\n💡 请确认已执行 ocpack deploy-bastion 且 [bastion.haproxy] stats_port 与部署时一致":  "cannot reach the HAProxy stats page %s: %v\n💡 Make sure ocpack deploy-bastion has been run and [bastion.haproxy] stats_port matches the deployment",
+	"HAProxy 统计页面拒绝访问 (%s)\n💡 请检查 [bastion.haproxy] stats_user 和 stats_password 是否与部署时一致":             "the HAProxy stats page denied access (%s)\n💡 Check that [bastion.haproxy] stats_user and stats_password match the deployment",
+	"HAProxy 统计页面 %s 返回 %s": "HAProxy stats page %s returned %s",
+	"无法连接 HAProxy 的 %s 前端 %s: %v\n💡 请确认 haproxy 服务正在运行，且端口未被其他服务占用":                "cannot connect to the HAProxy %s frontend %s: %v\n💡 Make sure the haproxy service is running and the port is not used by another service",
+	"私有仓库健康检查 %s 返回 %s\n💡 请登录 Registry 节点检查 quay-app 服务状态":                         "mirror registry health check %s returned %s\n💡 Log in to the Registry node and check the quay-app service",
+	"%v\n💡 请确认 Registry 已部署 (ocpack deploy-registry) 且 8443 端口可访问":                 "%v\n💡 Make sure the Registry is deployed (ocpack deploy-registry) and port 8443 is reachable",
+	"用户 %s 认证失败 (%v)\n💡 请检查 [registry] registry_user 和 registry_password 是否与部署时一致": "authentication failed for user %s (%v)\n💡 Check that [registry] registry_user and registry_password match the deployment",
+	"私有仓库 %s/v2/ 返回 %s":                        "mirror registry %s/v2/ returned %s",
+	"%v\n💡 请确认本机可以解析 %s (可在 /etc/hosts 中添加记录)": "%v\n💡 Make sure this host can resolve %s (you can add an entry to /etc/hosts)",
+
+	// pkg/gate/simulate.go
+	"%s 拉取代理": "%s pull-through proxy",
+	"Bastion 上没有 dig 命令\n💡 请安装 bind-utils (dnf install -y bind-utils)": "the dig command is not available on the Bastion\n💡 Install bind-utils (dnf install -y bind-utils)",
+	"无记录":                 "no records",
+	"%s @%s 解析为 %s，期望 %s": "%s @%s resolves to %s, expected %s",
+	"无法连接 %s %s":          "cannot connect to %s %s",
+	"在 Bastion 上执行检查失败: %v\n💡 请确认 [bastion] 的 SSH 用户、密码或密钥正确":                                                       "failed to run the checks on the Bastion: %v\n💡 Make sure the SSH user, password or key in [bastion] is correct",
+	"从机器网络 (Bastion %s) 检查发现 %d 个问题:\n  %s\n💡 请执行 ocpack deploy-bastion 更新 DNS 和 HAProxy 配置，并检查 firewalld 是否放行这些端口": "checks from the machine network (Bastion %s) found %d problems:\n  %s\n💡 Run ocpack deploy-bastion to update the DNS and HAProxy configuration, and check that firewalld allows these ports",
+
+	// pkg/iso/generator.go
+	"\n🎉 ISO 生成完成！\n   文件位置: %s\n":                                   "\n🎉 ISO generated!\n   Location: %s\n",
+	"   配置了 kernel_args 或 console 的节点使用上面生成的 ISO 副本启动，其他节点使用默认 ISO。": "   Nodes with kernel_args or console boot from the ISO copies generated above, other nodes use the default ISO.",
+	"\n✅ 配置文件已渲染到: %s\n":                                             "\n✅ Configuration files rendered to: %s\n",
+	"   未执行 openshift-install，移除 --render-only 以生成 ISO。":             "   openshift-install was not run, remove --render-only to generate the ISO.",
+	"生成 agent ISO 失败: %w":                                            "failed to generate the agent ISO: %w",
+	"移动 ISO 文件失败: %w":                                                "failed to move the ISO file: %w",
+	"⚠️  复制 %s 失败: %v\n":                                             "⚠️  Failed to copy %s: %v\n",
+	"⚠️  记录 kubeconfig 路径失败: %v\n":                                   "⚠️  Failed to record the kubeconfig path: %v\n",
+	"⚠️  记录 ISO 生成时间失败: %v\n":                                        "⚠️  Failed to record the ISO generation time: %v\n",
+	"\n🟡 ISO 文件已存在: %s\n":                                            "\n🟡 ISO file already exists: %s\n",
+	"   跳过生成。使用 --force 标志可强制重新生成。":                                  "   Skipping generation. Use --force to regenerate.",
+
+	// pkg/iso/profiles.go
+	"删除旧的 ISO 副本 %s 失败: %w": "failed to remove the old ISO copy %s: %w",
+	"节点配置了 kernel_args 或 console，生成 ISO 副本需要 coreos-installer (dnf install -y coreos-installer): %w": "nodes set kernel_args or console; generating ISO copies requires coreos-installer (dnf install -y coreos-installer): %w",
+	"生成节点 %s 的 ISO 副本失败: %w": "failed to generate the ISO copy for node %s: %w",
+	"✅ ISO 副本已生成: %s\n":      "✅ ISO copy generated: %s\n",
+	"   内核参数: %s\n":          "   Kernel arguments: %s\n",
+	"   适用节点: %s\n":          "   Nodes: %s\n",
+
+	// pkg/iso/regenerate.go
+	"%s 不存在，请先执行 ocpack generate-iso %s":  "%s does not exist, run ocpack generate-iso %s first",
+	"\n🎉 配置镜像已重新生成: %s\n":                 "\n🎉 Configuration image regenerated: %s\n",
+	"   请通过 BMC 重新挂载配置镜像并重启节点。":           "   Mount the configuration image again through the BMC and reboot the nodes.",
+	"生成 ISO 文件失败: %w":                     "failed to generate the ISO file: %w",
+	"\n🎉 ISO 已重新生成: %s\n":                 "\n🎉 ISO regenerated: %s\n",
+	"   旧 ISO 中的证书已失效，请使用新 ISO 重新启动所有节点。": "   The certificates in the old ISO are no longer valid, reboot all nodes from the new ISO.",
+
+	// pkg/iso/unconfigured.go
+	"   Rendezvous 节点: %s\n": "   Rendezvous node: %s\n",
+	"   节点从启动 ISO 启动后，通过 BMC 虚拟介质挂载配置镜像，即绑定到该集群并开始安装。": "   After the nodes boot from the boot ISO, mount the configuration image through BMC virtual media to bind them to this cluster and start the installation.",
+	"   执行命令: %s\n":      "   Running command: %s\n",
+	"嵌入 ignition 失败: %w": "failed to embed the ignition: %w",
+	"移动 %s 失败: %w":       "failed to move %s: %w",
+	"   ℹ️  未启用 Bastion，请将配置镜像发布到 BMC 可访问的 HTTP 服务器: %s\n":                            "   ℹ️  Bastion is not enabled, publish the configuration image to an HTTP server reachable by the BMCs: %s\n",
+	"⚠️  发布配置镜像到 Bastion 失败: %v\n":                                                    "⚠️  Failed to publish the configuration image to the Bastion: %v\n",
+	"   手动发布: scp %s %s@%s:/tmp/ && ssh %s@%s 'sudo install -D -m 0644 /tmp/%s %s'\n": "   Publish manually: scp %s %s@%s:/tmp/ && ssh %s@%s 'sudo install -D -m 0644 /tmp/%s %s'\n",
+	"基础 ISO 不存在: %s": "base ISO does not exist: %s",
+	"获取用户主目录失败: %w":  "failed to get the user home directory: %w",
+	"未找到 RHCOS 基础 ISO %s，请使用 --base-iso 指定，或先执行一次 generate-iso 由 openshift-install 缓存": "RHCOS base ISO %s not found, specify it with --base-iso, or run generate-iso once so openshift-install caches it",
+	"未找到 coreos-installer，请先安装 (dnf install -y coreos-installer): %w":                  "coreos-installer not found, install it first (dnf install -y coreos-installer): %w",
+	"🟡 不含集群配置的 ISO 由多个集群共用，不为配置了 kernel_args 或 console 的节点生成 ISO 副本，请在启动时手动添加内核参数":     "🟡 The ISO without cluster configuration is shared by several clusters, so no ISO copies are generated for nodes with kernel_args or console; add the kernel arguments manually at boot",
+	"创建安装目录失败: %w":                  "failed to create the installation directory: %w",
+	"✅ 目录结构已创建":                     "✅ Directory structure created",
+	"生成 install-config.yaml 失败: %w": "failed to generate install-config.yaml: %w",
+	"✅ install-config.yaml 已生成":     "✅ install-config.yaml generated",
+	"生成 agent-config.yaml 失败: %w":   "failed to generate agent-config.yaml: %w",
+	"✅ agent-config.yaml 已生成":       "✅ agent-config.yaml generated",
+	"🟡 不含集群配置的 ISO 已存在，直接复用: %s (使用 --force 重新生成)\n": "🟡 The ISO without cluster configuration already exists, reusing it: %s (use --force to regenerate)\n",
+	"生成不含集群配置的 ISO 失败: %w":                           "failed to generate the ISO without cluster configuration: %w",
+	"✅ 不含集群配置的 ISO 已生成: %s\n":                        "✅ ISO without cluster configuration generated: %s\n",
+	"生成配置镜像失败: %w":                                   "failed to generate the configuration image: %w",
+	"✅ 配置镜像已生成: %s\n":                                "✅ Configuration image generated: %s\n",
+	"\n🎉 Late-binding 镜像生成完成！\n":                     "\n🎉 Late-binding images generated!\n",
+	"   启动 ISO: %s\n":                                "   Boot ISO: %s\n",
+	"   配置镜像: %s\n":                                  "   Configuration image: %s\n",
+	"   配置镜像地址: %s\n":                                "   Configuration image URL: %s\n",
+
+	// pkg/loadimage/loader.go
+	"ℹ️  正在验证 Quay registry 连接: %s\n":              "ℹ️  Verifying the Quay registry connection: %s\n",
+	"使用用户 %s 访问 registry 失败: %w":                   "failed to access the registry as user %s: %w",
+	"ℹ️  认证配置已更新/创建于: %s\n":                        "ℹ️  Authentication configuration updated/created at: %s\n",
+	"ℹ️  认证配置已是最新: %s\n":                           "ℹ️  Authentication configuration is up to date: %s\n",
+	"oc-mirror 工具不存在: %s":                          "oc-mirror tool does not exist: %s",
+	"ℹ️  执行命令: %s\n":                               "ℹ️  Running command: %s\n",
+	"⚠️  错误: oc-mirror 工具架构与当前系统不兼容。":              "⚠️  Error: the oc-mirror tool architecture is not compatible with this system.",
+	"oc-mirror 架构不兼容，请手动执行":                        "oc-mirror architecture is not compatible, run it manually",
+	"oc-mirror 命令执行失败: %w":                         "oc-mirror command failed: %w",
+	"   请在与 oc-mirror 工具架构兼容的 Linux 系统上，手动执行以下命令:": "   On a Linux system compatible with the oc-mirror architecture, run the following command manually:",
+	"CA 证书文件不存在: %s":                               "CA certificate file does not exist: %s",
+	"ℹ️  已合并 %d 个 CA 证书到: %s\n":                    "ℹ️  Merged %d CA certificates into: %s\n",
+	"   macOS 用户请手动执行: sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s\n": "   macOS users run manually: sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s\n",
+	"   Windows 用户请手动将证书添加到 '受信任的根证书颁发机构': %s\n":                                                                  "   Windows users add the certificate to 'Trusted Root Certification Authorities' manually: %s\n",
+	"⚠️  不支持为操作系统 '%s' 自动配置证书，请手动完成。\n":                                                                           "⚠️  Automatic certificate configuration is not supported for operating system '%s', complete it manually.\n",
+	"未找到系统证书目录 (如 /etc/pki/ca-trust/source/anchors 或 /usr/local/share/ca-certificates)":                           "system certificate directory not found (such as /etc/pki/ca-trust/source/anchors or /usr/local/share/ca-certificates)",
+	"   为了使系统信任 registry 证书，需要 root 权限执行以下命令。":                                                                    "   Making the system trust the registry certificate requires running the following commands as root.",
+	"   请复制并执行:\n":                                 "   Copy and run:\n",
+	"   正在尝试自动执行...":                               "   Trying to run them automatically...",
+	"自动复制证书失败 (可能需要手动执行): %w, 输出: %s":              "failed to copy the certificate automatically (may need to be done manually): %w, output: %s",
+	"自动更新证书库失败 (可能需要手动执行): %w, 输出: %s":             "failed to update the certificate store automatically (may need to be done manually): %w, output: %s",
+	"▶️  开始从磁盘加载镜像到 Quay registry":                 "▶️  Loading images from disk into the Quay registry",
+	"镜像目录不存在: %s\n请先运行 'ocpack save-image' 命令保存镜像": "image directory does not exist: %s\nRun 'ocpack save-image' first to save the images",
+	"➡️  步骤 1/%d: 配置 CA 证书...\n":                   "➡️  Step 1/%d: configuring CA certificates...\n",
+	"⚠️  CA 证书自动配置失败: %v\n":                        "⚠️  Automatic CA certificate configuration failed: %v\n",
+	"   请根据提示手动完成证书信任配置。":                          "   Complete the certificate trust configuration manually as instructed.",
+	"✅ CA 证书配置完成。":                                 "✅ CA certificates configured.",
+	"➡️  步骤 2/%d: 验证 registry 连接...\n":             "➡️  Step 2/%d: verifying the registry connection...\n",
+	"registry 连接验证失败: %w":                          "registry connection verification failed: %w",
+	"✅ Registry 连接验证成功。":                           "✅ Registry connection verified.",
+	"➡️  步骤 3/%d: 配置认证信息...\n":                     "➡️  Step 3/%d: configuring authentication...\n",
+	"配置 registry 认证失败: %w":                         "failed to configure registry authentication: %w",
+	"✅ Registry 认证配置完成。":                           "✅ Registry authentication configured.",
+	"➡️  步骤 4/%d: 执行镜像加载...\n":                     "➡️  Step 4/%d: loading images...\n",
+	"oc-mirror 加载镜像失败: %w":                         "oc-mirror failed to load images: %w",
+	"\n🎉 镜像加载到 Quay registry 完成！":                  "\n🎉 Images loaded into the Quay registry!",
+
+	// pkg/mirror/wrapper/delete.go
+	"版本范围 %q 至少需要一端，如 1.2.0..1.4.0、..1.4.0 或 1.2.0..": "version range %q needs at least one end, such as 1.2.0..1.4.0, ..1.4.0 or 1.2.0..",
+	"版本范围 %q 中的 %q 不是有效的版本: %v":                       "version range %q: %q is not a valid version: %v",
+	"版本范围 %q 的最低版本高于最高版本":                             "the lower bound of version range %q is higher than the upper bound",
+
+	// pkg/mirror/wrapper/legacy.go
+	"%s 失败: %w": "%s failed: %w",
+	"未找到 release %s 的镜像目录 %s，请先执行 save-image: %w": "release %s: image directory %s not found, run save-image first: %w",
+
+	// pkg/mirror/wrapper/port.go
+	"%s 指定的端口 %d 已被占用，请更换端口或等待占用该端口的镜像任务结束":              "%s: port %d is already in use, choose another port or wait for the mirroring job using it to finish",
+	"无法为 oc-mirror 本地缓存 registry 选择端口: %w，请使用 --port 指定": "cannot choose a port for the oc-mirror local cache registry: %w, specify one with --port",
+
+	// pkg/mirror/wrapper/wrapper.go
+	"⚠️  pull-secret.txt 不存在，将使用默认认证配置": "⚠️  pull-secret.txt does not exist, using the default authentication configuration",
+	"创建合并认证配置失败: %v":                    "failed to create the merged authentication configuration: %v",
+	"✅ 认证配置已保存到: %s":                    "✅ Authentication configuration saved to: %s",
+	"ℹ️  检测到CA证书文件，建议手动配置证书信任":          "ℹ️  CA certificate file found, configuring certificate trust manually is recommended",
+	"   CA证书路径: %s":                     "   CA certificate path: %s",
+	"写入配置文件失败: %v":                      "failed to write the configuration file: %v",
+	"临时配置文件创建: %s":                      "Temporary configuration file created: %s",
+	"配置文件内容:\n%s":                       "Configuration file content:\n%s",
+	"解析模板 %s 失败: %v":                    "failed to parse template %s: %v",
+	"渲染模板 %s 失败: %v":                    "failed to render template %s: %v",
+	"错误日志文件不存在: %s":                     "error log file does not exist: %s",
+	"无法打开错误日志文件: %v":                    "cannot open the error log file: %v",
+	"读取错误日志文件失败: %v":                    "failed to read the error log file: %v",
+	"日志目录不存在: %s":                       "log directory does not exist: %s",
+	"无法读取日志目录: %v":                      "cannot read the log directory: %v",
+	"未找到错误日志文件":                         "no error log file found",
+
+	// pkg/ocpack/deploy.go
+	"Bastion 节点部署失败: %v":  "Bastion node deployment failed: %v",
+	"Registry 节点部署失败: %v": "Registry node deployment failed: %v",
+	"ℹ️  bastion.enabled = false，使用 [infra] 中站点已有的 DNS 和负载均衡，跳过 Bastion 部署\n": `ℹ️  bastion.enabled = false, using the existing site DNS and load balancer from [infra], skipping the Bastion deployment
+`,
+	"开始部署 Bastion 节点...\n": "Deploying the Bastion node...\n",
+
+	// pkg/ocpack/download.go
+	"未指定集群":            "no cluster specified",
+	"集群 %s: %w":        "cluster %s: %w",
+	"下载失败: %v":         "download failed: %v",
+	"下载失败: %w":         "download failed: %w",
+	"将下载文件保存到: %s\n":   "Saving downloaded files to: %s\n",
+	"%s 的文件将保存到: %s\n": "Files for %s will be saved to: %s\n",
+	"使用集群配置文件: %s\n":   "Using cluster configuration file: %s\n",
+
+	// pkg/ocpack/images.go
+	"--copy-attempts 不能为负数":  "--copy-attempts cannot be negative",
+	"创建镜像目录失败: %v":           "failed to create the image directory: %v",
+	"镜像保存失败: %w":             "failed to save images: %w",
+	"镜像数据不存在: %s\n请先运行 '%s'": "image data does not exist: %s\nRun '%s' first",
+	"镜像扫描未通过，已终止加载: %v":      "image scan did not pass, loading aborted: %v",
+	"镜像加载失败: %w":             "failed to load images: %w",
+	"已配置 save_image.signing，但 %s 中没有传输清单 %s，请使用当前版本重新执行 save-image": "save_image.signing is configured, but %s has no transfer manifest %s; run save-image again with the current version",
+	"校验和一致": "checksums match",
+	"签名有效，": "signature valid, ",
+	"ℹ️  [registry] mirror_mode = \"proxy-cache\"，镜像由 Registry 拉取代理按需缓存，跳过 save-image\n": `ℹ️  [registry] mirror_mode = "proxy-cache", images are cached on demand by the Registry pull-through proxy, skipping save-image
+`,
+	"🔄 开始保存镜像: %s\n": "🔄 Saving images: %s\n",
+	"📦 镜像存储: %s\n":   "📦 Image storage: %s\n",
+	"📋 镜像列表: %s (%d 个镜像)，跳过 release 和 Operator\n": "📋 Image list: %s (%d images), skipping release and operators\n",
+	"🔍 干运行模式: 只解析镜像集而不下载镜像\n":                     "🔍 Dry-run mode: only resolving the image set without downloading images\n",
+	"📦 已归档 mirror-registry 安装包: %s\n":             "📦 Archived the mirror-registry installer: %s\n",
+	"✅ mirror-registry 安装包未变化，跳过归档: %s\n":         "✅ mirror-registry installer unchanged, skipping the archive: %s\n",
+	"🔏 已生成并签名 (%s) 传输清单: %s\n":                    "🔏 Generated and signed (%s) the transfer manifest: %s\n",
+	"🔐 已生成传输清单: %s\n":                             "🔐 Generated the transfer manifest: %s\n",
+	"✅ 镜像保存完成！镜像归档: %s\n":                         "✅ Images saved! Image archives: %s\n",
+	"🔄 开始从本地磁盘加载镜像到 registry: %s\n":               "🔄 Loading images from local disk into the registry: %s\n",
+	"⚙️  配置文件: %s\n":                              "⚙️  Configuration file: %s\n",
+	"🔍 干运行模式: 只显示操作而不实际执行\n":                      "🔍 Dry-run mode: only showing the operations without running them\n",
+	"ℹ️  [registry] mirror_mode = \"proxy-cache\"，镜像由 Registry 拉取代理按需缓存，跳过 load-image\n": `ℹ️  [registry] mirror_mode = "proxy-cache", images are cached on demand by the Registry pull-through proxy, skipping load-image
+`,
+	"🏢 准备 Quay 组织和仓库...\n":                          "🏢 Preparing Quay organizations and repositories...\n",
+	"🛡️  开始扫描镜像漏洞...\n":                             "🛡️  Scanning images for vulnerabilities...\n",
+	"✅ 镜像加载完成！目标仓库: %s\n":                           "✅ Images loaded! Target registry: %s\n",
+	"🔐 验证镜像归档的传输清单...\n":                            "🔐 Verifying the transfer manifest of the image archives...\n",
+	"⚠️  %s 中没有传输清单 %s，跳过镜像归档的完整性验证\n":              "⚠️  %s has no transfer manifest %s, skipping the integrity verification of the image archives\n",
+	"✅ %d 个文件%s (集群 %s，OpenShift %s，保存于 %s)\n":      "✅ %d files: %s (cluster %s, OpenShift %s, saved at %s)\n",
+	"⚠️  清理本地缓存失败: %v\n":                            "⚠️  Failed to clean up the local cache: %v\n",
+	"💾 本地缓存 %s，未超过上限 %s\n":                          "💾 Local cache %s, within the limit %s\n",
+	"🧹 本地缓存 %s 超过上限 %s，已删除 %d 个最久未使用的 blob，释放 %s\n": "🧹 Local cache %s exceeds the limit %s, removed %d least recently used blobs, freeing %s\n",
+	"   以下 %d 个镜像已从缓存中移除，下次执行时重新下载:\n":              "   The following %d images were removed from the cache and will be downloaded again on the next run:\n",
+	"   - ... 等 %d 个镜像\n":                           "   - ... and %d more images\n",
+
+	// pkg/ocpack/iso.go
+	"RenderOnly 和 Unconfigured 不能同时设置": "RenderOnly and Unconfigured cannot both be set",
+	"ISO 生成失败: %w":                     "ISO generation failed: %w",
+	"开始为集群 %s 生成 ISO 镜像\n":             "Generating the ISO image for cluster %s\n",
+	"🔍 执行就绪检查...\n":                    "🔍 Running readiness checks...\n",
+
+	// pkg/ocpack/ocpack.go
+	"未指定项目目录 (ProjectRoot)": "project directory not specified (ProjectRoot)",
+	"解析项目目录失败: %w":          "failed to resolve the project directory: %w",
+
+	// pkg/pxe/fingerprint.go
+	"计算 PXE 配置指纹失败: %w": "failed to compute the PXE configuration fingerprint: %w",
+	"删除 PXE 指纹文件失败: %w": "failed to remove the PXE fingerprint file: %w",
+	"清理 %s 失败: %w":      "failed to clean up %s: %w",
+
+	// pkg/pxe/generator.go
+	"创建目录失败":                                "Failed to create directories",
+	"目录结构已创建":                               "Directory structure created",
+	"生成 install-config.yaml":                "Generating install-config.yaml",
+	"生成 install-config.yaml 失败":             "Failed to generate install-config.yaml",
+	"install-config.yaml 已生成":               "install-config.yaml generated",
+	"生成 agent-config.yaml":                  "Generating agent-config.yaml",
+	"生成 agent-config.yaml 失败":               "Failed to generate agent-config.yaml",
+	"agent-config.yaml 已生成":                 "agent-config.yaml generated",
+	"生成 PXE 启动文件":                           "Generating PXE boot files",
+	"配置和版本未变化，跳过生成和上传":                      "Configuration and version unchanged, skipping generation and upload",
+	"🟡 PXE 文件已是最新: %s\n":                    "🟡 PXE files are up to date: %s\n",
+	"   使用 --force 标志可强制重新生成并上传。":           "   Use --force to regenerate and upload.",
+	"清理旧的 PXE 文件失败":                         "Failed to clean up the old PXE files",
+	"生成 PXE 文件失败":                           "Failed to generate PXE files",
+	"记录 PXE 配置指纹失败":                         "Failed to record the PXE configuration fingerprint",
+	"上传文件到 PXE 服务器":                         "Uploading files to the PXE server",
+	"未启用 Bastion，请将 %s 中的文件复制到 PXE 资源服务器":   "Bastion is not enabled, copy the files in %s to the PXE asset server",
+	"自动上传失败":                                "Automatic upload failed",
+	"文件已自动上传到服务器":                           "Files uploaded to the server",
+	"PXE 配置渲染 (render-only)":                "PXE configuration rendering (render-only)",
+	"   未执行 openshift-install，也未上传 PXE 文件。": "   openshift-install was not run and no PXE files were uploaded.",
+	"从模板生成 install-config.yaml":             "Generating install-config.yaml from the template",
+	"生成 PXE 文件失败: %w":                       "failed to generate PXE files: %w",
+	"已生成 %d 个 PXE 文件":                       "Generated %d PXE files",
+	"PXE 启动文件生成完成":                          "PXE boot files generated",
+	"bastion.enabled = false 时必须配置 infra.pxe_asset_url 指定 PXE 启动文件地址": "infra.pxe_asset_url must be configured with the PXE boot file location when bastion.enabled = false",
+	"PXE 文件目录不存在: %s":                                             "PXE file directory does not exist: %s",
+	"同步 PXE 文件到 Bastion 的 %s":                                     "Syncing PXE files to %s on the Bastion",
+	"上传 %d 个文件 (%s)，%d 个文件未变化已跳过":                                 "Uploading %d files (%s), %d unchanged files skipped",
+	"执行上传脚本失败: %w":                                                "upload script failed: %w",
+	"清理 Bastion 上的历史 PXE 文件失败":                                    "Failed to clean up old PXE files on the Bastion",
+	"已清理历史 PXE 文件: %s":                                            "Cleaned up old PXE files: %s",
+	"读取 iPXE 文件 %s 失败":                                            "Failed to read iPXE file %s",
+	"更新 iPXE 文件 %s 失败: %w":                                        "failed to update iPXE file %s: %w",
+	"读取目录 %s 失败: %w":                                              "failed to read directory %s: %w",
+	"移动文件 %s 失败":                                                  "Failed to move file %s",
+	"\n📋 手动上传步骤:\n":                                               "\n📋 Manual upload steps:\n",
+	"│ 1. 使用上传脚本:                                            │\n": "│ 1. Use the upload script:                                   │\n",
+	"│ 2. 或手动复制文件:                                          │\n":  "│ 2. Or copy the files manually:                              │\n",
+	"│ 3. 验证文件访问:                                            │\n": "│ 3. Verify file access:                                      │\n",
+	"✅ PXE 文件生成完成！":                                               "✅ PXE files generated!",
+	"📁 文件位置: %s":                                                  "📁 Location: %s",
+	"🌐 PXE 服务器: %s":                                               "🌐 PXE server: %s",
+	"🧭 Rendezvous 节点: %s":                                         "🧭 Rendezvous node: %s",
+	"🚀 下一步: 配置目标机器从 PXE 启动":                                       "🚀 Next step: configure the target machines to boot from PXE",
+	"PXE 文件生成":                                                    "PXE file generation",
+	"验证配置和依赖":                                                     "Validating configuration and dependencies",
+	"配置验证失败":                                                      "Configuration validation failed",
+	"配置验证通过":                                                      "Configuration is valid",
+	"创建目录结构":                                                      "Creating directory structure",
+
+	// pkg/pxe/retention.go
+	"列出 Bastion 上的历史 PXE 文件失败: %w\n输出: %s": "failed to list old PXE files on the Bastion: %w\noutput: %s",
+	"拒绝删除 %s: 不是 %s 下的历史版本目录":              "refusing to delete %s: not an old version directory under %s",
+	"删除 Bastion 上的历史 PXE 文件失败: %w\n输出: %s": "failed to remove old PXE files on the Bastion: %w\noutput: %s",
+
+	// pkg/storage/storage.go
+	"未找到 aws CLI，使用 s3:// 存储需要先安装: %w": "aws CLI not found, install it to use s3:// storage: %w",
+	"☁️  同步镜像归档: %s -> %s\n":           "☁️  Syncing image archives: %s -> %s\n",
+	"同步镜像归档失败 (%s): %w":                "failed to sync image archives (%s): %w",
+
+	// cmd/ocpack/cmd/lang.go
+	"输出语言: zh 或 en (默认按 OCPACK_LANG、LC_ALL、LC_MESSAGES 和 LANG 判断)": "Output language: zh or en (by default chosen from OCPACK_LANG, LC_ALL, LC_MESSAGES and LANG)",
+}
@@ -0,0 +1,149 @@
+// Package i18n 提供命令输出的本地化。源代码中的消息以中文书写并作为消息 ID，
+// 选择英文时按 catalog 查找对应的译文，没有译文的消息保持原文输出。
+//
+// 格式化输出使用本包的 Printf、Sprintf、Errorf 等函数代替 fmt 中的同名函数，
+// 它们先翻译格式字符串再格式化，go vet 仍然可以检查格式字符串与参数是否匹配。
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// english 为 true 时输出英文
+var english atomic.Bool
+
+// Parse 将 --lang 的取值或 locale (如 zh_CN.UTF-8、en-US) 解析为支持的语言
+func Parse(value string) (string, error) {
+	lang := strings.ToLower(value)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case Chinese, English:
+		return lang, nil
+	}
+	return "", fmt.Errorf("unsupported language %q, supported: %s, %s", value, Chinese, English)
+}
+
+// Detect 根据环境选择语言: 依次读取 OCPACK_LANG、LC_ALL、LC_MESSAGES 和 LANG，使用第一个非空的值。
+// 中文 locale 和 C.UTF-8 使用中文；C/POSIX 以及均未设置时终端无法显示中文 (plain.Detect 同时启用纯 ASCII 输出)，
+// 与其他语言的 locale 一样使用英文
+func Detect() string {
+	if lang, err := Parse(os.Getenv("OCPACK_LANG")); err == nil {
+		return lang
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if lang := strings.ToLower(value); lang == "c.utf-8" || lang == "c.utf8" || strings.HasPrefix(lang, Chinese) {
+			return Chinese
+		}
+		return English
+	}
+	return English
+}
+
+// SetLang 设置输出语言，不支持的值按中文处理
+func SetLang(lang string) {
+	english.Store(lang == English)
+}
+
+// Lang 返回当前的输出语言
+func Lang() string {
+	if english.Load() {
+		return English
+	}
+	return Chinese
+}
+
+// T 返回消息在当前语言下的文本
+func T(message string) string {
+	if !english.Load() {
+		return message
+	}
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	return message
+}
+
+// translateArgs 翻译 Println 等函数参数中的字符串
+func translateArgs(a []any) []any {
+	if !english.Load() {
+		return a
+	}
+	translated := make([]any, len(a))
+	for i, arg := range a {
+		if s, ok := arg.(string); ok {
+			arg = T(s)
+		}
+		translated[i] = arg
+	}
+	return translated
+}
+
+// Sprintf 翻译格式字符串后格式化
+func Sprintf(format string, a ...any) string {
+	if !english.Load() {
+		return fmt.Sprintf(format, a...)
+	}
+	return fmt.Sprintf(T(format), a...)
+}
+
+// Errorf 翻译格式字符串后创建错误，支持 %w
+func Errorf(format string, a ...any) error {
+	if !english.Load() {
+		return fmt.Errorf(format, a...)
+	}
+	return fmt.Errorf(T(format), a...)
+}
+
+// Fprintf 翻译格式字符串后写入 w
+func Fprintf(w io.Writer, format string, a ...any) (int, error) {
+	if !english.Load() {
+		return fmt.Fprintf(w, format, a...)
+	}
+	return fmt.Fprintf(w, T(format), a...)
+}
+
+// Printf 翻译格式字符串后写入标准输出
+func Printf(format string, a ...any) (int, error) {
+	return Fprintf(os.Stdout, format, a...)
+}
+
+// Fprintln 翻译字符串参数后写入 w
+func Fprintln(w io.Writer, a ...any) (int, error) {
+	return fmt.Fprintln(w, translateArgs(a)...)
+}
+
+// Println 翻译字符串参数后写入标准输出
+func Println(a ...any) (int, error) {
+	return Fprintln(os.Stdout, a...)
+}
+
+// Fprint 翻译字符串参数后写入 w
+func Fprint(w io.Writer, a ...any) (int, error) {
+	return fmt.Fprint(w, translateArgs(a)...)
+}
+
+// Print 翻译字符串参数后写入标准输出
+func Print(a ...any) (int, error) {
+	return Fprint(os.Stdout, a...)
+}
+
+// Sprint 翻译字符串参数后拼接
+func Sprint(a ...any) string {
+	return fmt.Sprint(translateArgs(a)...)
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"zh", Chinese},
+		{"en", English},
+		{"EN", English},
+		{"zh_CN.UTF-8", Chinese},
+		{"en-US", English},
+		{"en_GB.utf8", English},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "fr", "de_DE.UTF-8"} {
+		if _, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"unset", nil, English},
+		{"chinese locale", map[string]string{"LANG": "zh_CN.UTF-8"}, Chinese},
+		{"english locale", map[string]string{"LANG": "en_US.UTF-8"}, English},
+		{"other locale", map[string]string{"LANG": "de_DE.UTF-8"}, English},
+		{"C locale", map[string]string{"LANG": "C"}, English},
+		{"POSIX locale", map[string]string{"LC_ALL": "POSIX"}, English},
+		{"C.UTF-8", map[string]string{"LANG": "C.UTF-8"}, Chinese},
+		{"LC_ALL overrides LANG", map[string]string{"LC_ALL": "en_US.UTF-8", "LANG": "zh_CN.UTF-8"}, English},
+		{"LC_MESSAGES overrides LANG", map[string]string{"LC_MESSAGES": "zh_CN.UTF-8", "LANG": "en_US.UTF-8"}, Chinese},
+		{"OCPACK_LANG overrides locale", map[string]string{"OCPACK_LANG": "en", "LANG": "zh_CN.UTF-8"}, English},
+		{"invalid OCPACK_LANG ignored", map[string]string{"OCPACK_LANG": "fr", "LANG": "zh_CN.UTF-8"}, Chinese},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"OCPACK_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := Detect(); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// withLang 在测试期间切换输出语言
+func withLang(t *testing.T, lang string) {
+	t.Helper()
+	previous := Lang()
+	SetLang(lang)
+	t.Cleanup(func() { SetLang(previous) })
+}
+
+func TestTranslate(t *testing.T) {
+	withLang(t, English)
+	if got := T("✅ 配置验证通过"); got != "✅ Configuration is valid" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := T("没有译文的消息"); got != "没有译文的消息" {
+		t.Errorf("T() without translation = %q, want the source text", got)
+	}
+	if got := Sprintf("存在 %d 个警告", 2); got != "2 warnings" {
+		t.Errorf("Sprintf() = %q", got)
+	}
+	var buf bytes.Buffer
+	Fprintln(&buf, "✅ 配置验证通过", 3)
+	if got := buf.String(); got != "✅ Configuration is valid 3\n" {
+		t.Errorf("Fprintln() = %q", got)
+	}
+
+	SetLang(Chinese)
+	if got := T("✅ 配置验证通过"); got != "✅ 配置验证通过" {
+		t.Errorf("T() in Chinese = %q", got)
+	}
+}
+
+func TestErrorfWraps(t *testing.T) {
+	withLang(t, English)
+	cause := errors.New("no such file")
+	err := Errorf("加载配置失败: %w", cause)
+	if !errors.Is(err, cause) {
+		t.Error("Errorf() does not wrap the cause")
+	}
+	if got := err.Error(); got != "failed to load the configuration: no such file" {
+		t.Errorf("Errorf() = %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package imagepolicy
+
+import "strings"
+
+// MirrorImage 按镜像源配置返回镜像在私有仓库中的地址，source 匹配时保留其后的路径和 tag/digest。
+// 按 digest 引用的镜像优先使用 digest 镜像源，按 tag 引用的镜像优先使用 tag 镜像源，
+// 多个 source 匹配时使用最长的一个。Docker Hub 的简写 (如 nginx:1.25) 按 docker.io/library/nginx 匹配。
+// 没有匹配的镜像源时返回 false
+func (p *Policy) MirrorImage(image string) (string, bool) {
+	repository, reference := splitReference(image)
+	if repository == "" {
+		return "", false
+	}
+	repository = normalizeRepository(repository)
+
+	groups := [][]Mirror{p.TagMirrors, p.DigestMirrors}
+	if strings.HasPrefix(reference, "@") {
+		groups = [][]Mirror{p.DigestMirrors, p.TagMirrors}
+	}
+	for _, mirrors := range groups {
+		var best *Mirror
+		for i := range mirrors {
+			m := &mirrors[i]
+			if len(m.Mirrors) == 0 || !matchesSource(repository, m.Source) {
+				continue
+			}
+			if best == nil || len(m.Source) > len(best.Source) {
+				best = m
+			}
+		}
+		if best != nil {
+			return best.Mirrors[0] + strings.TrimPrefix(repository, best.Source) + reference, true
+		}
+	}
+	return "", false
+}
+
+// splitReference 将镜像地址拆分为仓库和 ":tag" / "@sha256:..." 引用部分
+func splitReference(image string) (repository, reference string) {
+	image = strings.TrimSpace(image)
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i:]
+	}
+	return image, ""
+}
+
+// normalizeRepository 为 Docker Hub 的简写加上 docker.io 和 library 前缀
+func normalizeRepository(repository string) string {
+	first, _, found := strings.Cut(repository, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return repository
+	}
+	if !found {
+		return "docker.io/library/" + repository
+	}
+	return "docker.io/" + repository
+}
+
+// matchesSource 判断仓库是否为 source 本身或位于 source 之下
+func matchesSource(repository, source string) bool {
+	return repository == source || strings.HasPrefix(repository, source+"/")
+}
@@ -0,0 +1,39 @@
+package imagepolicy
+
+import "testing"
+
+func TestMirrorImage(t *testing.T) {
+	policy := &Policy{
+		DigestMirrors: []Mirror{
+			{Source: "quay.io/openshift-release-dev", Mirrors: []string{"registry.demo.example.com:8443/openshift-release-dev"}},
+			{Source: "quay.io/openshift-release-dev/ocp-release", Mirrors: []string{"registry.demo.example.com:8443/openshift/release-images"}},
+			{Source: "docker.io/library/nginx", Mirrors: []string{"registry.demo.example.com:8443/library/nginx"}},
+		},
+		TagMirrors: []Mirror{
+			{Source: "quay.io/example", Mirrors: []string{"registry.demo.example.com:8443/tags/example"}},
+		},
+	}
+
+	tests := []struct {
+		image string
+		want  string
+		ok    bool
+	}{
+		{"quay.io/openshift-release-dev/ocp-release:4.14.10-x86_64", "registry.demo.example.com:8443/openshift/release-images:4.14.10-x86_64", true},
+		{"quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:abcd", "registry.demo.example.com:8443/openshift-release-dev/ocp-v4.0-art-dev@sha256:abcd", true},
+		{"quay.io/example/app:v1", "registry.demo.example.com:8443/tags/example/app:v1", true},
+		{"nginx:1.25", "registry.demo.example.com:8443/library/nginx:1.25", true},
+		{"docker.io/library/nginx", "registry.demo.example.com:8443/library/nginx", true},
+		{"registry.example.com:5000/team/app:v2", "", false},
+		{"quay.io/openshift-release-devx/app:v1", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, ok := policy.MirrorImage(tt.image)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("MirrorImage(%q) = %q, %v, want %q, %v", tt.image, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package imagepolicy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// --- Constants ---
+const (
+	icspFilename        = "imageContentSourcePolicy.yaml"
+	idmsFilename        = "idms-oc-mirror.yaml"
+	itmsFilename        = "itms-oc-mirror.yaml"
+	clusterResourcesDir = "cluster-resources"
+	// legacyReleaseFilename 4.14 以下的 release 通过 oc adm release mirror 推送后，ocpack 写入的 release ICSP
+	legacyReleaseFilename = "icsp-ocpack-release.yaml"
+
+	// ICSPManifestFilename 等为写入 openshift/ 额外清单目录时使用的文件名
+	ICSPManifestFilename = "image-content-source-policy.yaml"
+	IDMSManifestFilename = "image-digest-mirror-set.yaml"
+	ITMSManifestFilename = "image-tag-mirror-set.yaml"
+
+	// mirrorSetMinVersion 起集群使用 ImageDigestMirrorSet/ImageTagMirrorSet 替代 ICSP
+	mirrorSetMinVersion = "4.13"
+	// digestSourcesMinVersion 起 install-config 使用 imageDigestSources 替代 imageContentSources
+	digestSourcesMinVersion = "4.14"
+)
+
+// Mirror 一个源仓库及其镜像仓库列表
+type Mirror struct {
+	Source  string   `yaml:"source"`
+	Mirrors []string `yaml:"mirrors"`
+}
+
+// Policy oc-mirror 生成的镜像源配置，按 digest 和 tag 两类汇总
+type Policy struct {
+	DigestMirrors []Mirror
+	TagMirrors    []Mirror
+	SourceFiles   []string
+}
+
+// document 用于解析 ICSP / IDMS / ITMS 三种资源的最小结构
+type document struct {
+	Kind string `yaml:"kind"`
+	Spec struct {
+		RepositoryDigestMirrors []Mirror `yaml:"repositoryDigestMirrors"`
+		ImageDigestMirrors      []Mirror `yaml:"imageDigestMirrors"`
+		ImageTagMirrors         []Mirror `yaml:"imageTagMirrors"`
+	} `yaml:"spec"`
+}
+
+// manifest 写入 openshift/ 目录的镜像源资源
+type manifest struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   map[string]string      `yaml:"metadata"`
+	Spec       map[string]interface{} `yaml:"spec"`
+}
+
+// --- Main Logic ---
+
+// UseMirrorSets 判断目标 OpenShift 版本是否应使用 IDMS/ITMS (4.13 及以上)，否则使用 ICSP
+func UseMirrorSets(openshiftVersion string) bool {
+	return utils.CompareVersion(openshiftVersion, mirrorSetMinVersion) >= 0
+}
+
+// InstallConfigKey 返回 install-config.yaml 中镜像源字段的名称
+func InstallConfigKey(openshiftVersion string) string {
+	if utils.CompareVersion(openshiftVersion, digestSourcesMinVersion) >= 0 {
+		return "imageDigestSources"
+	}
+	return "imageContentSources"
+}
+
+// Load 在集群目录中查找 oc-mirror 生成的镜像源文件并解析。
+// 优先使用 oc-mirror v2 的 cluster-resources 目录，其次是最新的 results-* 目录；
+// 同一目录中 IDMS/ITMS 优先于 ICSP
+func Load(clusterDir string) (*Policy, error) {
+	var dirs []string
+	dirs = append(dirs, filepath.Join(clusterDir, "images", "working-dir", clusterResourcesDir))
+	if resultsDir, err := findLatestResultsDir(clusterDir); err == nil {
+		dirs = append(dirs, resultsDir)
+	}
+
+	for _, dir := range dirs {
+		policy, err := loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			return policy, nil
+		}
+	}
+	return nil, errors.New("未找到 IDMS/ITMS 或 ICSP 文件")
+}
+
+// ProxyCache 返回 proxy-cache 模式的镜像源：每个上游仓库按 digest 和 tag 都指向 Registry 节点上的拉取代理，
+// 不依赖 oc-mirror 生成的文件
+func ProxyCache(cfg *config.ClusterConfig) *Policy {
+	policy := &Policy{}
+	for _, upstream := range cfg.GetProxyCacheUpstreams() {
+		mirror := Mirror{Source: upstream.Source, Mirrors: []string{cfg.ProxyCacheHost(upstream)}}
+		policy.DigestMirrors = append(policy.DigestMirrors, mirror)
+		policy.TagMirrors = append(policy.TagMirrors, mirror)
+	}
+	return policy
+}
+
+// AddTagMirrors 为按 tag 引用的附加镜像补充 tag 镜像源 (按仓库)。IDMS 只在按 digest 拉取时生效，
+// oc-mirror 的输出中没有 ITMS (如 oc-mirror v1 只生成 ICSP) 时，这些镜像需要 ITMS 才能从私有仓库拉取。
+// 已有 tag 镜像源覆盖的仓库不重复添加，返回新增的镜像源数量
+func (p *Policy) AddTagMirrors(cfg *config.ClusterConfig) int {
+	added := 0
+	for _, image := range TagReferencedImages(cfg) {
+		source, path := imageRepository(image)
+		if p.coversTag(source) {
+			continue
+		}
+		p.TagMirrors = append(p.TagMirrors, Mirror{Source: source, Mirrors: []string{cfg.GetMirrorDestination() + "/" + path}})
+		added++
+	}
+	return added
+}
+
+// TagReferencedImages 返回按 tag 引用 (不含 digest) 的附加镜像，这些镜像不匹配 IDMS
+func TagReferencedImages(cfg *config.ClusterConfig) []string {
+	var images []string
+	for _, image := range cfg.GetAdditionalImages() {
+		if !strings.Contains(image, "@") {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// coversTag 判断 source 仓库是否已被某个 tag 镜像源覆盖 (相同仓库或上级命名空间)
+func (p *Policy) coversTag(source string) bool {
+	for _, m := range p.TagMirrors {
+		if source == m.Source || strings.HasPrefix(source, m.Source+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// imageRepository 返回镜像的源仓库 (含 registry 主机) 和 oc-mirror 推送到私有仓库时使用的路径。
+// Docker Hub 的简写 (如 nginx:1.25) 按 docker.io/library/nginx 处理
+func imageRepository(image string) (source, path string) {
+	ref := image
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		ref = ref[:idx]
+	}
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		ref = ref[:idx]
+	}
+	host, rest, ok := strings.Cut(ref, "/")
+	if !ok || !(strings.ContainsAny(host, ".:") || host == "localhost") {
+		host, rest = "docker.io", ref
+	}
+	if host == "docker.io" && !strings.Contains(rest, "/") {
+		rest = "library/" + rest
+	}
+	return host + "/" + rest, rest
+}
+
+// InstallConfigSources 生成 install-config.yaml 中镜像源字段的内容 (仅包含 digest 镜像)。
+// install-config 不支持 tag 镜像源，tag 镜像源通过 openshift/ 目录中的 ImageTagMirrorSet 生效
+func (p *Policy) InstallConfigSources() string {
+	var b strings.Builder
+	for _, m := range p.DigestMirrors {
+		fmt.Fprintf(&b, "- mirrors:\n  - %s\n  source: %s\n", strings.Join(m.Mirrors, "\n  - "), m.Source)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Manifests 根据目标版本生成需要放入 openshift/ 目录的镜像源资源，返回文件名到内容的映射。
+// 4.13 以下生成 ICSP，tag 镜像无法表达会被忽略并在 warnings 中说明
+func (p *Policy) Manifests(openshiftVersion string) (files map[string][]byte, warnings []string, err error) {
+	files = make(map[string][]byte)
+
+	if !UseMirrorSets(openshiftVersion) {
+		if len(p.TagMirrors) > 0 {
+			warnings = append(warnings, fmt.Sprintf("OpenShift %s 不支持 ImageTagMirrorSet，%d 个按 tag 引用的镜像源将被忽略", openshiftVersion, len(p.TagMirrors)))
+		}
+		if len(p.DigestMirrors) > 0 {
+			content, err := marshalManifest("operator.openshift.io/v1alpha1", "ImageContentSourcePolicy", "ocpack-mirror", "repositoryDigestMirrors", p.DigestMirrors)
+			if err != nil {
+				return nil, warnings, err
+			}
+			files[ICSPManifestFilename] = content
+		}
+		return files, warnings, nil
+	}
+
+	if len(p.DigestMirrors) > 0 {
+		content, err := marshalManifest("config.openshift.io/v1", "ImageDigestMirrorSet", "ocpack-digest-mirror", "imageDigestMirrors", p.DigestMirrors)
+		if err != nil {
+			return nil, warnings, err
+		}
+		files[IDMSManifestFilename] = content
+	}
+	if len(p.TagMirrors) > 0 {
+		content, err := marshalManifest("config.openshift.io/v1", "ImageTagMirrorSet", "ocpack-tag-mirror", "imageTagMirrors", p.TagMirrors)
+		if err != nil {
+			return nil, warnings, err
+		}
+		files[ITMSManifestFilename] = content
+	}
+	return files, warnings, nil
+}
+
+// WriteManifests 将 Manifests 生成的资源写入 dir，并删除不再适用的旧文件
+func (p *Policy) WriteManifests(dir, openshiftVersion string) ([]string, []string, error) {
+	files, warnings, err := p.Manifests(openshiftVersion)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, warnings, fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+
+	var written []string
+	for _, name := range []string{ICSPManifestFilename, IDMSManifestFilename, ITMSManifestFilename} {
+		path := filepath.Join(dir, name)
+		content, ok := files[name]
+		if !ok {
+			os.Remove(path)
+			continue
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return written, warnings, fmt.Errorf("写入 %s 失败: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, warnings, nil
+}
+
+// --- Helper Functions ---
+
+// loadDir 解析目录中的 IDMS/ITMS 文件，不存在时回退到 ICSP；都不存在时返回 nil
+func loadDir(dir string) (*Policy, error) {
+	policy := &Policy{}
+	for _, name := range []string{idmsFilename, itmsFilename} {
+		if err := policy.parseFile(filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+	if len(policy.SourceFiles) == 0 {
+		if err := policy.parseFile(filepath.Join(dir, icspFilename)); err != nil {
+			return nil, err
+		}
+	}
+	// oc-mirror 不处理 4.14 以下的 release，其镜像源单独保存，与 Operator 和附加镜像的镜像源合并
+	if err := policy.parseFile(filepath.Join(dir, legacyReleaseFilename)); err != nil {
+		return nil, err
+	}
+	if len(policy.DigestMirrors) == 0 && len(policy.TagMirrors) == 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// WriteLegacyReleasePolicy 将通过 oc adm release mirror 推送的 release 的镜像源写入 workingDir/cluster-resources，
+// 格式为 ICSP，可以直接应用到 4.14 以下的集群。返回写入的文件路径
+func WriteLegacyReleasePolicy(workingDir string, mirrors []Mirror) (string, error) {
+	content, err := marshalManifest("operator.openshift.io/v1alpha1", "ImageContentSourcePolicy", "ocpack-release", "repositoryDigestMirrors", mirrors)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(workingDir, clusterResourcesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+	path := filepath.Join(dir, legacyReleaseFilename)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return path, nil
+}
+
+// parseFile 解析包含多个 YAML 文档的镜像源文件，文件不存在时忽略
+func (p *Policy) parseFile(path string) error {
+	content, err := utils.ReadFileIfExists(path)
+	if err != nil {
+		return fmt.Errorf("读取镜像源文件 %s 失败: %w", path, err)
+	}
+	if content == nil {
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc document
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("解析镜像源文件 %s 失败: %w", path, err)
+		}
+
+		switch doc.Kind {
+		case "ImageContentSourcePolicy":
+			p.DigestMirrors = append(p.DigestMirrors, doc.Spec.RepositoryDigestMirrors...)
+		case "ImageDigestMirrorSet":
+			p.DigestMirrors = append(p.DigestMirrors, doc.Spec.ImageDigestMirrors...)
+		case "ImageTagMirrorSet":
+			p.TagMirrors = append(p.TagMirrors, doc.Spec.ImageTagMirrors...)
+		}
+	}
+	p.SourceFiles = append(p.SourceFiles, path)
+	return nil
+}
+
+// findLatestResultsDir 在 oc-mirror 工作空间中查找最新的非空 results-* 目录
+func findLatestResultsDir(clusterDir string) (string, error) {
+	var latestDir string
+	var latestTime int64
+
+	for _, workspace := range []string{
+		filepath.Join(clusterDir, "working-dir"),
+		filepath.Join(clusterDir, "images", "working-dir"),
+		filepath.Join(clusterDir, "oc-mirror-workspace"),
+		filepath.Join(clusterDir, "images", "oc-mirror-workspace"),
+	} {
+		entries, err := os.ReadDir(workspace)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "results-") {
+				continue
+			}
+			dirPath := filepath.Join(workspace, entry.Name())
+			if entries, _ := os.ReadDir(dirPath); len(entries) == 0 {
+				continue // Skip empty dirs
+			}
+			if timeValue, err := utils.ParseTimestamp(strings.TrimPrefix(entry.Name(), "results-")); err == nil && timeValue > latestTime {
+				latestTime = timeValue
+				latestDir = dirPath
+			}
+		}
+	}
+
+	if latestDir == "" {
+		return "", errors.New("未找到有效的 results 目录")
+	}
+	return latestDir, nil
+}
+
+// marshalManifest 序列化一个镜像源资源
+func marshalManifest(apiVersion, kind, name, specKey string, mirrors []Mirror) ([]byte, error) {
+	content, err := yaml.Marshal(manifest{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Metadata:   map[string]string{"name": name},
+		Spec:       map[string]interface{}{specKey: mirrors},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化 %s 失败: %w", kind, err)
+	}
+	return content, nil
+}
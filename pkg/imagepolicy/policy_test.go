@@ -0,0 +1,223 @@
+package imagepolicy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+const testICSP = `apiVersion: operator.openshift.io/v1alpha1
+kind: ImageContentSourcePolicy
+metadata:
+  name: release-0
+spec:
+  repositoryDigestMirrors:
+  - mirrors:
+    - registry.demo.example.com:8443/openshift/release
+    source: quay.io/openshift-release-dev/ocp-v4.0-art-dev
+`
+
+const testIDMS = `apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: idms-release-0
+spec:
+  imageDigestMirrors:
+  - mirrors:
+    - registry.demo.example.com:8443/openshift/release
+    source: quay.io/openshift-release-dev/ocp-v4.0-art-dev
+  - mirrors:
+    - registry.demo.example.com:8443/openshift/release-images
+    source: quay.io/openshift-release-dev/ocp-release
+`
+
+const testITMS = `apiVersion: config.openshift.io/v1
+kind: ImageTagMirrorSet
+metadata:
+  name: itms-operator-0
+spec:
+  imageTagMirrors:
+  - mirrors:
+    - registry.demo.example.com:8443/redhat
+    source: registry.redhat.io/redhat
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	clusterDir := t.TempDir()
+	if _, err := Load(clusterDir); err == nil {
+		t.Error("Load() expected error when no policy files exist")
+	}
+
+	// oc-mirror v1 结果目录中只有 ICSP
+	writeFile(t, filepath.Join(clusterDir, "oc-mirror-workspace", "results-1700000000", icspFilename), testICSP)
+	policy, err := Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(policy.DigestMirrors) != 1 || len(policy.TagMirrors) != 0 {
+		t.Errorf("Load() ICSP = %+v, expected 1 digest mirror", policy)
+	}
+
+	// oc-mirror v2 cluster-resources 目录优先，并同时读取 IDMS 和 ITMS
+	resourcesDir := filepath.Join(clusterDir, "images", "working-dir", clusterResourcesDir)
+	writeFile(t, filepath.Join(resourcesDir, idmsFilename), testIDMS)
+	writeFile(t, filepath.Join(resourcesDir, itmsFilename), testITMS)
+	policy, err = Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(policy.DigestMirrors) != 2 || len(policy.TagMirrors) != 1 || len(policy.SourceFiles) != 2 {
+		t.Errorf("Load() IDMS/ITMS = %+v, expected 2 digest and 1 tag mirror", policy)
+	}
+}
+
+func TestLoadLegacyRelease(t *testing.T) {
+	clusterDir := t.TempDir()
+	workingDir := filepath.Join(clusterDir, "images", "working-dir")
+	resourcesDir := filepath.Join(workingDir, clusterResourcesDir)
+	writeFile(t, filepath.Join(resourcesDir, idmsFilename), testIDMS)
+
+	// oc adm release mirror 推送的 release 镜像源与 oc-mirror 生成的 IDMS 合并
+	if _, err := WriteLegacyReleasePolicy(workingDir, []Mirror{
+		{Source: "quay.io/openshift-release-dev/ocp-release", Mirrors: []string{"registry.example.com:8443/openshift/release-images"}},
+		{Source: "quay.io/openshift-release-dev/ocp-v4.0-art-dev", Mirrors: []string{"registry.example.com:8443/openshift/release"}},
+	}); err != nil {
+		t.Fatalf("WriteLegacyReleasePolicy() error = %v", err)
+	}
+	policy, err := Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(policy.DigestMirrors) != 4 || len(policy.SourceFiles) != 2 {
+		t.Errorf("Load() = %+v, expected IDMS and release ICSP to be merged", policy)
+	}
+	if got := policy.DigestMirrors[3]; got.Source != "quay.io/openshift-release-dev/ocp-v4.0-art-dev" || got.Mirrors[0] != "registry.example.com:8443/openshift/release" {
+		t.Errorf("release mirror = %+v", got)
+	}
+}
+
+func TestInstallConfigKey(t *testing.T) {
+	tests := map[string]string{
+		"4.12.30": "imageContentSources",
+		"4.13.0":  "imageContentSources",
+		"4.14.1":  "imageDigestSources",
+		"4.16.3":  "imageDigestSources",
+	}
+	for version, expected := range tests {
+		if got := InstallConfigKey(version); got != expected {
+			t.Errorf("InstallConfigKey(%q) = %q, expected %q", version, got, expected)
+		}
+	}
+}
+
+func TestInstallConfigSources(t *testing.T) {
+	policy := &Policy{DigestMirrors: []Mirror{{Source: "quay.io/a", Mirrors: []string{"mirror/a", "mirror/b"}}}}
+	expected := "- mirrors:\n  - mirror/a\n  - mirror/b\n  source: quay.io/a"
+	if got := policy.InstallConfigSources(); got != expected {
+		t.Errorf("InstallConfigSources() = %q, expected %q", got, expected)
+	}
+}
+
+func TestProxyCache(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.MirrorMode = config.MirrorModeProxyCache
+	policy := ProxyCache(cfg)
+	expected := "- mirrors:\n  - registry.demo.example.com:5001\n  source: quay.io\n" +
+		"- mirrors:\n  - registry.demo.example.com:5002\n  source: registry.redhat.io"
+	if got := policy.InstallConfigSources(); got != expected {
+		t.Errorf("InstallConfigSources() = %q, expected %q", got, expected)
+	}
+	if len(policy.TagMirrors) != 2 {
+		t.Errorf("TagMirrors = %v, expected one per upstream", policy.TagMirrors)
+	}
+}
+
+func TestManifests(t *testing.T) {
+	policy := &Policy{
+		DigestMirrors: []Mirror{{Source: "quay.io/a", Mirrors: []string{"mirror/a"}}},
+		TagMirrors:    []Mirror{{Source: "registry.redhat.io/b", Mirrors: []string{"mirror/b"}}},
+	}
+
+	files, warnings, err := policy.Manifests("4.12.30")
+	if err != nil {
+		t.Fatalf("Manifests() error = %v", err)
+	}
+	if len(files) != 1 || !strings.Contains(string(files[ICSPManifestFilename]), "kind: ImageContentSourcePolicy") {
+		t.Errorf("Manifests(4.12) = %v, expected only ICSP", files)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Manifests(4.12) warnings = %v, expected tag mirror warning", warnings)
+	}
+
+	files, warnings, err = policy.Manifests("4.14.1")
+	if err != nil {
+		t.Fatalf("Manifests() error = %v", err)
+	}
+	if len(files) != 2 || len(warnings) != 0 {
+		t.Errorf("Manifests(4.14) = %v, %v, expected IDMS and ITMS", files, warnings)
+	}
+	if !strings.Contains(string(files[IDMSManifestFilename]), "imageDigestMirrors:") ||
+		!strings.Contains(string(files[ITMSManifestFilename]), "imageTagMirrors:") {
+		t.Errorf("Manifests(4.14) content unexpected: %s", files)
+	}
+
+	// 版本降级后重新写入时应清理不再适用的清单
+	dir := t.TempDir()
+	if _, _, err := policy.WriteManifests(dir, "4.14.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := policy.WriteManifests(dir, "4.12.30"); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != ICSPManifestFilename {
+		t.Errorf("WriteManifests() left %v, expected only %s", entries, ICSPManifestFilename)
+	}
+}
+
+func TestAddTagMirrors(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.TargetNamespace = "mirror"
+	cfg.SaveImage.AdditionalImages = []string{
+		"quay.io/acme/app:v1",
+		"quay.io/acme/app:v2",
+		"registry.redhat.io/redhat/tool:latest",
+		"nginx:1.25",
+		"quay.io/acme/pinned@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+	policy := &Policy{TagMirrors: []Mirror{{Source: "registry.redhat.io/redhat", Mirrors: []string{"registry.demo.example.com:8443/mirror/redhat"}}}}
+
+	if added := policy.AddTagMirrors(cfg); added != 2 {
+		t.Errorf("AddTagMirrors() = %d, expected 2", added)
+	}
+	expected := []Mirror{
+		{Source: "registry.redhat.io/redhat", Mirrors: []string{"registry.demo.example.com:8443/mirror/redhat"}},
+		{Source: "quay.io/acme/app", Mirrors: []string{"registry.demo.example.com:8443/mirror/acme/app"}},
+		{Source: "docker.io/library/nginx", Mirrors: []string{"registry.demo.example.com:8443/mirror/library/nginx"}},
+	}
+	if !reflect.DeepEqual(policy.TagMirrors, expected) {
+		t.Errorf("TagMirrors = %v, expected %v", policy.TagMirrors, expected)
+	}
+	if len(policy.DigestMirrors) != 0 {
+		t.Errorf("DigestMirrors = %v, expected none", policy.DigestMirrors)
+	}
+
+	// 再次执行时不重复添加
+	if added := policy.AddTagMirrors(cfg); added != 0 {
+		t.Errorf("second AddTagMirrors() = %d, expected 0", added)
+	}
+}
@@ -0,0 +1,181 @@
+// Package inventory 生成集群主机清单：合并 config.toml 中的节点配置和资产信息 (BMC、序列号、位置、控制台)，
+// 以及从已安装集群中获取的节点状态，导出为 CSV、JSON 或 Markdown，便于交接给机房运维人员。
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+// 节点在集群中的状态
+const (
+	StatusReady     = "Ready"
+	StatusNotReady  = "NotReady"
+	StatusNotJoined = "NotJoined" // 配置中的节点未出现在集群中
+)
+
+// Host 清单中的一台主机
+type Host struct {
+	Role           string `json:"role"` // control-plane、worker、bastion、registry，集群中存在但未配置的节点为 node
+	Name           string `json:"name"`
+	IP             string `json:"ip"`
+	MAC            string `json:"mac,omitempty"`
+	BMCAddress     string `json:"bmc_address,omitempty"`
+	Serial         string `json:"serial,omitempty"`
+	Location       string `json:"location,omitempty"`
+	ConsoleURL     string `json:"console_url,omitempty"`
+	Status         string `json:"status,omitempty"` // 未获取集群状态时为空
+	KubeletVersion string `json:"kubelet_version,omitempty"`
+}
+
+// Inventory 集群主机清单
+type Inventory struct {
+	Cluster          string   `json:"cluster"`
+	Domain           string   `json:"domain"`
+	OpenShiftVersion string   `json:"openshift_version"`
+	APIURL           string   `json:"api_url"`
+	Hosts            []Host   `json:"hosts"`
+	Notes            []string `json:"notes,omitempty"` // 获取集群状态失败等说明
+}
+
+// Collect 根据集群配置生成主机清单。discover 为 true 时通过集群 kubeconfig 使用 r 执行 oc get nodes 获取节点状态，
+// 集群未安装或无法访问时只记录说明，不返回错误
+func Collect(r runner.CommandRunner, cfg *config.ClusterConfig, clusterDir string, discover bool) *Inventory {
+	clusterID := cfg.ClusterInfo.ClusterID
+	inv := &Inventory{
+		Cluster:          clusterID,
+		Domain:           cfg.ClusterInfo.Domain,
+		OpenShiftVersion: cfg.ClusterInfo.OpenShiftVersion,
+		APIURL:           fmt.Sprintf("https://%s:6443", cfg.APIHostname()),
+	}
+
+	if cfg.BastionEnabled() {
+		inv.Hosts = append(inv.Hosts, Host{Role: "bastion", Name: "bastion", IP: cfg.Bastion.IP})
+	}
+	inv.Hosts = append(inv.Hosts, Host{Role: "registry", Name: "registry", IP: cfg.Registry.IP})
+	for _, node := range cfg.Cluster.ControlPlane {
+		inv.Hosts = append(inv.Hosts, nodeHost("control-plane", node))
+	}
+	for _, node := range cfg.Cluster.Worker {
+		inv.Hosts = append(inv.Hosts, nodeHost("worker", node))
+	}
+
+	if discover {
+		if err := inv.discover(r, clusterDir); err != nil {
+			inv.Notes = append(inv.Notes, err.Error())
+		}
+	}
+	return inv
+}
+
+func nodeHost(role string, node config.Node) Host {
+	return Host{
+		Role:       role,
+		Name:       node.Name,
+		IP:         node.IP,
+		MAC:        node.MAC,
+		BMCAddress: node.BMCAddress,
+		Serial:     node.Serial,
+		Location:   node.Location,
+		ConsoleURL: node.ConsoleURL,
+	}
+}
+
+// nodeList oc get nodes -o json 中用到的字段
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+			Addresses []struct {
+				Type    string `json:"type"`
+				Address string `json:"address"`
+			} `json:"addresses"`
+			NodeInfo struct {
+				KubeletVersion string `json:"kubeletVersion"`
+			} `json:"nodeInfo"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// discover 获取集群节点状态并合并到清单中
+func (inv *Inventory) discover(r runner.CommandRunner, clusterDir string) error {
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return fmt.Errorf("未找到集群 kubeconfig，未获取节点状态")
+	}
+
+	result, err := r.Run(runner.Command{
+		Name:    "oc",
+		Args:    []string{"get", "nodes", "-o", "json"},
+		Env:     []string{"KUBECONFIG=" + kubeconfigPath},
+		Timeout: runner.DefaultTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("获取集群节点失败，未获取节点状态: %v", err)
+	}
+
+	var nodes nodeList
+	if err := json.Unmarshal(result.Stdout, &nodes); err != nil {
+		return fmt.Errorf("解析 oc get nodes 输出失败: %w", err)
+	}
+
+	matched := make(map[int]bool)
+	for _, item := range nodes.Items {
+		status := StatusNotReady
+		for _, condition := range item.Status.Conditions {
+			if condition.Type == "Ready" && condition.Status == "True" {
+				status = StatusReady
+			}
+		}
+		var internalIP string
+		for _, address := range item.Status.Addresses {
+			if address.Type == "InternalIP" {
+				internalIP = address.Address
+			}
+		}
+
+		index := inv.findClusterHost(item.Metadata.Name, internalIP)
+		if index < 0 {
+			inv.Hosts = append(inv.Hosts, Host{Role: "node", Name: item.Metadata.Name, IP: internalIP})
+			index = len(inv.Hosts) - 1
+		}
+		inv.Hosts[index].Status = status
+		inv.Hosts[index].KubeletVersion = item.Status.NodeInfo.KubeletVersion
+		matched[index] = true
+	}
+
+	for i, host := range inv.Hosts {
+		if isClusterRole(host.Role) && !matched[i] {
+			inv.Hosts[i].Status = StatusNotJoined
+		}
+	}
+	return nil
+}
+
+// findClusterHost 按节点名 (或以节点名开头的 FQDN) 或 IP 查找配置中的集群节点
+func (inv *Inventory) findClusterHost(nodeName, ip string) int {
+	for i, host := range inv.Hosts {
+		if !isClusterRole(host.Role) {
+			continue
+		}
+		if host.Name == nodeName || strings.HasPrefix(nodeName, host.Name+".") || (ip != "" && host.IP == ip) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isClusterRole(role string) bool {
+	return role == "control-plane" || role == "worker"
+}
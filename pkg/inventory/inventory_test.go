@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+const nodesJSON = `{"items": [
+  {"metadata": {"name": "master-0.demo.example.com"},
+   "status": {"conditions": [{"type": "Ready", "status": "True"}],
+              "addresses": [{"type": "InternalIP", "address": "192.168.1.10"}],
+              "nodeInfo": {"kubeletVersion": "v1.29.5"}}},
+  {"metadata": {"name": "node-x"},
+   "status": {"conditions": [{"type": "Ready", "status": "False"}],
+              "addresses": [{"type": "InternalIP", "address": "192.168.1.21"}],
+              "nodeInfo": {"kubeletVersion": "v1.29.5"}}}
+]}`
+
+func testConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.ClusterID = "demo"
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Registry.IP = "192.168.1.3"
+	cfg.Cluster.ControlPlane = []config.Node{
+		{Name: "master-0", IP: "192.168.1.10", MAC: "aa:bb:cc:dd:ee:00", BMCAddress: "10.0.0.10", Serial: "SN0", Location: "DC1/R01/U10"},
+	}
+	cfg.Cluster.Worker = []config.Node{
+		{Name: "worker-0", IP: "192.168.1.20", MAC: "aa:bb:cc:dd:ee:01", ConsoleURL: "https://10.0.0.20/console"},
+	}
+	return cfg
+}
+
+func TestCollectWithoutCluster(t *testing.T) {
+	fake := &runner.Fake{}
+
+	inv := Collect(fake, testConfig(), t.TempDir(), true)
+	if len(fake.Calls()) != 0 {
+		t.Errorf("expected no oc calls without kubeconfig, got %v", fake.CommandLines())
+	}
+	if len(inv.Notes) != 1 {
+		t.Errorf("expected a note about missing kubeconfig, got %v", inv.Notes)
+	}
+
+	var roles []string
+	for _, host := range inv.Hosts {
+		roles = append(roles, host.Role)
+		if host.Status != "" {
+			t.Errorf("host %s status = %q, expected empty without discovery", host.Name, host.Status)
+		}
+	}
+	if got := strings.Join(roles, ","); got != "bastion,registry,control-plane,worker" {
+		t.Errorf("roles = %s", got)
+	}
+}
+
+func TestCollectDiscover(t *testing.T) {
+	clusterDir := t.TempDir()
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Stdout: []byte(nodesJSON)}, nil
+	}}
+
+	inv := Collect(fake, testConfig(), clusterDir, true)
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Env[0] != "KUBECONFIG="+kubeconfigPath {
+		t.Fatalf("unexpected oc calls: %v", fake.CommandLines())
+	}
+
+	status := make(map[string]string)
+	for _, host := range inv.Hosts {
+		status[host.Name] = host.Status
+	}
+	expected := map[string]string{
+		"bastion":  "",
+		"registry": "",
+		"master-0": StatusReady,
+		"worker-0": StatusNotJoined,
+		"node-x":   StatusNotReady,
+	}
+	for name, want := range expected {
+		if got, ok := status[name]; !ok || got != want {
+			t.Errorf("status[%s] = %q, expected %q", name, got, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	inv := Collect(&runner.Fake{}, testConfig(), t.TempDir(), false)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, inv, "csv"); err != nil {
+		t.Fatalf("Write(csv) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "cluster,role,name,ip,mac,bmc_address,serial,location,console_url,status,kubelet_version" {
+		t.Errorf("csv header = %s", lines[0])
+	}
+	if lines[3] != "demo,control-plane,master-0,192.168.1.10,aa:bb:cc:dd:ee:00,10.0.0.10,SN0,DC1/R01/U10,,," {
+		t.Errorf("csv row = %s", lines[3])
+	}
+
+	buf.Reset()
+	if err := Write(&buf, inv, "json"); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded Inventory
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if len(decoded.Hosts) != 4 || decoded.Hosts[3].ConsoleURL != "https://10.0.0.20/console" {
+		t.Errorf("decoded hosts = %+v", decoded.Hosts)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, inv, "markdown"); err != nil {
+		t.Fatalf("Write(markdown) error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "| worker | worker-0 | 192.168.1.20 |") {
+		t.Errorf("markdown output missing worker row:\n%s", buf.String())
+	}
+
+	if err := Write(&buf, inv, "xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
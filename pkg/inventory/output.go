@@ -0,0 +1,73 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats 支持的导出格式
+var Formats = []string{"csv", "json", "markdown"}
+
+// columns 表格列名，与 row 的字段顺序一致
+var columns = []string{"role", "name", "ip", "mac", "bmc_address", "serial", "location", "console_url", "status", "kubelet_version"}
+
+func row(host Host) []string {
+	return []string{host.Role, host.Name, host.IP, host.MAC, host.BMCAddress, host.Serial, host.Location, host.ConsoleURL, host.Status, host.KubeletVersion}
+}
+
+// Write 按指定格式输出主机清单
+func Write(w io.Writer, inv *Inventory, format string) error {
+	switch format {
+	case "csv":
+		return writeCSV(w, inv)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inv)
+	case "markdown", "md":
+		return writeMarkdown(w, inv)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeCSV(w io.Writer, inv *Inventory) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"cluster"}, columns...)); err != nil {
+		return err
+	}
+	for _, host := range inv.Hosts {
+		if err := writer.Write(append([]string{inv.Cluster}, row(host)...)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMarkdown(w io.Writer, inv *Inventory) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s 主机清单\n\n", inv.Cluster)
+	fmt.Fprintf(&b, "- 域名: %s.%s\n", inv.Cluster, inv.Domain)
+	fmt.Fprintf(&b, "- OpenShift 版本: %s\n", inv.OpenShiftVersion)
+	fmt.Fprintf(&b, "- API: %s\n", inv.APIURL)
+	for _, note := range inv.Notes {
+		fmt.Fprintf(&b, "- 说明: %s\n", note)
+	}
+
+	b.WriteString("\n| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, host := range inv.Hosts {
+		cells := row(host)
+		for i, cell := range cells {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,61 @@
+package iso
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+)
+
+// ProfileISOPath 返回启动参数组 profile 使用的 ISO 路径，profile 为组内第一个节点的名称
+func ProfileISOPath(clusterDir, clusterName, profile string) string {
+	return filepath.Join(clusterDir, installDirName, isoDirName, fmt.Sprintf("%s-agent-%s.x86_64.iso", clusterName, profile))
+}
+
+// generateProfileISOs 为配置了 kernel_args 或 console 的节点生成 ISO 副本。agent ISO 对所有节点相同，
+// 使用 coreos-installer iso kargs modify 在副本中追加各组节点的内核参数，不影响默认 ISO。
+// 上次生成的副本先被删除，避免节点配置修改后继续使用过期的 ISO
+func (g *ISOGenerator) generateProfileISOs(isoPath string) error {
+	stale, _ := filepath.Glob(ProfileISOPath(g.ClusterDir, g.ClusterName, "*"))
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return i18n.Errorf("删除旧的 ISO 副本 %s 失败: %w", path, err)
+		}
+	}
+
+	profiles := g.Config.BootProfiles()
+	if len(profiles) == 0 {
+		return nil
+	}
+	if _, err := g.Runner.LookPath("coreos-installer"); err != nil {
+		return i18n.Errorf("节点配置了 kernel_args 或 console，生成 ISO 副本需要 coreos-installer (dnf install -y coreos-installer): %w", err)
+	}
+
+	for _, profile := range profiles {
+		target := ProfileISOPath(g.ClusterDir, g.ClusterName, profile.Name)
+		args := []string{"iso", "kargs", "modify"}
+		for _, karg := range profile.KernelArgs {
+			args = append(args, "--append", karg)
+		}
+		args = append(args, "--output", target, isoPath)
+		if _, err := g.Runner.Run(runner.Command{Name: "coreos-installer", Args: args}); err != nil {
+			return i18n.Errorf("生成节点 %s 的 ISO 副本失败: %w", profile.Name, err)
+		}
+		i18n.Fprintf(g.Out, "✅ ISO 副本已生成: %s\n", target)
+		i18n.Fprintf(g.Out, "   内核参数: %s\n", strings.Join(profile.KernelArgs, " "))
+		i18n.Fprintf(g.Out, "   适用节点: %s\n", profileNodeNames(profile))
+	}
+	return nil
+}
+
+func profileNodeNames(profile config.BootProfile) string {
+	var names []string
+	for _, node := range profile.Nodes {
+		names = append(names, node.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,60 @@
+package iso
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/utils"
+)
+
+// RegenerateISO 复用 installation/ 中已渲染的 install-config.yaml、agent-config.yaml 和 openshift/ 清单，
+// 重新执行 openshift-install 轮换 ignition 和 auth/ 中的认证文件。ISO 中的证书在生成 24 小时后过期，
+// 节点在此之后才启动时使用该方法重新生成，不会因为 config.toml 或模板的改动而改变集群配置。
+// 上次使用 --unconfigured 生成时只重新生成配置镜像，不含集群配置的 ISO 中没有证书，无需重新生成。
+// ctx 被取消或超时时终止正在执行的 openshift-install
+func (g *ISOGenerator) RegenerateISO(ctx context.Context) error {
+	g.Runner = runner.WithContext(ctx, g.Runner)
+	installDir := filepath.Join(g.ClusterDir, installDirName)
+	for _, filename := range []string{agentinstall.InstallConfigFilename, agentinstall.AgentConfigFilename} {
+		if !utils.FileExists(filepath.Join(installDir, filename)) {
+			return i18n.Errorf("%s 不存在，请先执行 ocpack generate-iso %s", filepath.Join(installDir, filename), g.ClusterName)
+		}
+	}
+	if err := g.ValidateConfig(); err != nil {
+		return i18n.Errorf("配置验证失败: %w", err)
+	}
+
+	targetISOPath := ISOPath(g.ClusterDir, g.ClusterName)
+	configImage := ConfigImagePath(g.ClusterDir, g.ClusterName)
+	if !utils.FileExists(targetISOPath) && utils.FileExists(configImage) {
+		fmt.Fprintf(g.Out, "▶️  Regenerating config image for cluster %s with existing configs\n", g.ClusterName)
+		if err := g.generateConfigImage(installDir, configImage); err != nil {
+			return i18n.Errorf("生成配置镜像失败: %w", err)
+		}
+		g.publishConfigImage(configImage)
+		i18n.Fprintf(g.Out, "\n🎉 配置镜像已重新生成: %s\n", configImage)
+		i18n.Fprintln(g.Out, "   请通过 BMC 重新挂载配置镜像并重启节点。")
+		return nil
+	}
+
+	fmt.Fprintf(g.Out, "▶️  Regenerating ISO image for cluster %s with existing configs\n", g.ClusterName)
+	if err := os.MkdirAll(filepath.Dir(targetISOPath), 0755); err != nil {
+		return i18n.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetISOPath), err)
+	}
+	generatedPath, err := g.generateISOFiles(installDir, targetISOPath)
+	if err != nil {
+		return i18n.Errorf("生成 ISO 文件失败: %w", err)
+	}
+	if err := g.generateProfileISOs(generatedPath); err != nil {
+		return err
+	}
+
+	i18n.Fprintf(g.Out, "\n🎉 ISO 已重新生成: %s\n", generatedPath)
+	i18n.Fprintln(g.Out, "   旧 ISO 中的证书已失效，请使用新 ISO 重新启动所有节点。")
+	return nil
+}
@@ -0,0 +1,204 @@
+package iso
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/sshsync"
+	"ocpack/pkg/utils"
+)
+
+// --- Constants ---
+const (
+	unconfiguredIgnitionFilename = "unconfigured-agent.ign"
+	unconfiguredISOFilename      = "unconfigured-agent.x86_64.iso"
+	configImageFilename          = "agentconfig.noarch.iso"
+	// openshift-install agent create image 缓存 RHCOS 基础 ISO 的位置，相对于用户主目录
+	baseISOCachePath = ".cache/agent/image_cache/coreos-x86_64.iso"
+	// Bastion 上 httpd (8080 端口) 发布配置镜像的目录
+	remoteConfigImageDir = "/var/www/html/agent"
+	bastionHTTPPort      = 8080
+)
+
+// UnconfiguredISOPath 返回 generate-iso --unconfigured 生成的不含集群配置的 ISO 路径
+func UnconfiguredISOPath(clusterDir string) string {
+	return filepath.Join(clusterDir, installDirName, isoDirName, unconfiguredISOFilename)
+}
+
+// ConfigImagePath 返回 generate-iso --unconfigured 生成的集群配置镜像路径
+func ConfigImagePath(clusterDir, clusterName string) string {
+	return filepath.Join(clusterDir, installDirName, isoDirName, fmt.Sprintf("%s-%s", clusterName, configImageFilename))
+}
+
+// generateUnconfigured 生成 late-binding 所需的两个镜像：嵌入 unconfigured ignition 的 RHCOS ISO，
+// 以及包含 install-config.yaml 和 agent-config.yaml 的配置镜像。节点从前者启动后挂载后者，
+// 在启动时才绑定到具体集群，因此使用同一私有仓库的多个集群可以复用同一个 ISO
+func (g *ISOGenerator) generateUnconfigured(installDir string, options *GenerateOptions) error {
+	fmt.Fprintf(g.Out, "▶️  Starting unconfigured ISO generation for cluster %s\n", g.ClusterName)
+
+	steps := 6
+	fmt.Fprintf(g.Out, "➡️  Step 1/%d: Validating configuration and dependencies...\n", steps)
+	if err := g.ValidateConfig(); err != nil {
+		return i18n.Errorf("配置验证失败: %w", err)
+	}
+	if _, err := g.Runner.LookPath("coreos-installer"); err != nil {
+		return i18n.Errorf("未找到 coreos-installer，请先安装 (dnf install -y coreos-installer): %w", err)
+	}
+	if len(g.Config.BootProfiles()) > 0 {
+		i18n.Fprintln(g.Out, "🟡 不含集群配置的 ISO 由多个集群共用，不为配置了 kernel_args 或 console 的节点生成 ISO 副本，请在启动时手动添加内核参数")
+	}
+	i18n.Fprintln(g.Out, "✅ 配置验证通过")
+
+	fmt.Fprintf(g.Out, "➡️  Step 2/%d: Creating installation directory structure...\n", steps)
+	if err := g.createInstallationDirs(installDir); err != nil {
+		return i18n.Errorf("创建安装目录失败: %w", err)
+	}
+	i18n.Fprintln(g.Out, "✅ 目录结构已创建")
+
+	fmt.Fprintf(g.Out, "➡️  Step 3/%d: Generating install-config.yaml...\n", steps)
+	if err := g.RenderInstallConfig(installDir); err != nil {
+		return i18n.Errorf("生成 install-config.yaml 失败: %w", err)
+	}
+	i18n.Fprintln(g.Out, "✅ install-config.yaml 已生成")
+
+	fmt.Fprintf(g.Out, "➡️  Step 4/%d: Generating agent-config.yaml...\n", steps)
+	if err := g.generateAgentConfig(installDir); err != nil {
+		return i18n.Errorf("生成 agent-config.yaml 失败: %w", err)
+	}
+	i18n.Fprintln(g.Out, "✅ agent-config.yaml 已生成")
+
+	// 5. 不含集群配置的 ISO 与集群无关，已存在时直接复用
+	fmt.Fprintf(g.Out, "➡️  Step 5/%d: Generating unconfigured ISO...\n", steps)
+	unconfiguredISO := UnconfiguredISOPath(g.ClusterDir)
+	if _, err := os.Stat(unconfiguredISO); err == nil && !options.Force {
+		i18n.Fprintf(g.Out, "🟡 不含集群配置的 ISO 已存在，直接复用: %s (使用 --force 重新生成)\n", unconfiguredISO)
+	} else if err := g.generateUnconfiguredISO(installDir, unconfiguredISO, options.BaseISOPath); err != nil {
+		return i18n.Errorf("生成不含集群配置的 ISO 失败: %w", err)
+	} else {
+		i18n.Fprintf(g.Out, "✅ 不含集群配置的 ISO 已生成: %s\n", unconfiguredISO)
+	}
+
+	fmt.Fprintf(g.Out, "➡️  Step 6/%d: Generating config image...\n", steps)
+	configImage := ConfigImagePath(g.ClusterDir, g.ClusterName)
+	if err := g.generateConfigImage(installDir, configImage); err != nil {
+		return i18n.Errorf("生成配置镜像失败: %w", err)
+	}
+	i18n.Fprintf(g.Out, "✅ 配置镜像已生成: %s\n", configImage)
+
+	configImageURL := g.publishConfigImage(configImage)
+
+	i18n.Fprintf(g.Out, "\n🎉 Late-binding 镜像生成完成！\n")
+	i18n.Fprintf(g.Out, "   启动 ISO: %s\n", unconfiguredISO)
+	i18n.Fprintf(g.Out, "   配置镜像: %s\n", configImage)
+	if configImageURL != "" {
+		i18n.Fprintf(g.Out, "   配置镜像地址: %s\n", configImageURL)
+	}
+	i18n.Fprintf(g.Out, "   Rendezvous 节点: %s\n", g.RendezvousSummary())
+	i18n.Fprintln(g.Out, "   节点从启动 ISO 启动后，通过 BMC 虚拟介质挂载配置镜像，即绑定到该集群并开始安装。")
+	return nil
+}
+
+// generateUnconfiguredISO 生成 unconfigured ignition 并嵌入 RHCOS 基础 ISO
+func (g *ISOGenerator) generateUnconfiguredISO(installDir, targetISOPath, baseISOPath string) error {
+	baseISO, err := resolveBaseISO(baseISOPath)
+	if err != nil {
+		return err
+	}
+
+	tempDir := filepath.Join(installDir, tempDirName)
+	defer os.RemoveAll(tempDir)
+	if err := g.RunInstaller(installDir, tempDir, "unconfigured-ignition"); err != nil {
+		return err
+	}
+
+	ignitionPath := filepath.Join(installDir, ignitionDirName, unconfiguredIgnitionFilename)
+	if err := utils.MoveFile(filepath.Join(tempDir, unconfiguredIgnitionFilename), ignitionPath); err != nil {
+		return i18n.Errorf("移动 %s 失败: %w", unconfiguredIgnitionFilename, err)
+	}
+
+	cmd := runner.Command{
+		Name:   "coreos-installer",
+		Args:   []string{"iso", "ignition", "embed", "--force", "-i", ignitionPath, "-o", targetISOPath, baseISO},
+		Stream: true,
+	}
+	i18n.Fprintf(g.Out, "   执行命令: %s\n", cmd)
+	if _, err := g.Runner.Run(cmd); err != nil {
+		return i18n.Errorf("嵌入 ignition 失败: %w", err)
+	}
+	return nil
+}
+
+// generateConfigImage 使用 openshift-install agent create config-image 生成集群配置镜像
+func (g *ISOGenerator) generateConfigImage(installDir, targetPath string) error {
+	tempDir := filepath.Join(installDir, tempDirName)
+	defer os.RemoveAll(tempDir)
+	if err := g.RunInstaller(installDir, tempDir, "config-image"); err != nil {
+		return err
+	}
+	if err := utils.MoveFile(filepath.Join(tempDir, configImageFilename), targetPath); err != nil {
+		return i18n.Errorf("移动 %s 失败: %w", configImageFilename, err)
+	}
+	g.saveInstallerState(installDir, tempDir)
+	return nil
+}
+
+// publishConfigImage 将配置镜像上传到 Bastion 并复制到 httpd 目录，便于 BMC 通过 URL 挂载，返回访问地址。
+// 上传使用内置的 SSH 客户端，暂存目录中的配置镜像未变化时不重复上传。失败时只给出手动步骤
+func (g *ISOGenerator) publishConfigImage(configImage string) string {
+	if !g.Config.BastionEnabled() {
+		i18n.Fprintf(g.Out, "   ℹ️  未启用 Bastion，请将配置镜像发布到 BMC 可访问的 HTTP 服务器: %s\n", configImage)
+		return ""
+	}
+
+	remotePath := path.Join(remoteConfigImageDir, g.ClusterName, configImageFilename)
+	if err := g.uploadConfigImage(configImage, remotePath); err != nil {
+		i18n.Fprintf(g.Out, "⚠️  发布配置镜像到 Bastion 失败: %v\n", err)
+		i18n.Fprintf(g.Out, "   手动发布: scp %s %s@%s:/tmp/ && ssh %s@%s 'sudo install -D -m 0644 /tmp/%s %s'\n",
+			configImage, g.Config.Bastion.Username, g.Config.Bastion.IP,
+			g.Config.Bastion.Username, g.Config.Bastion.IP, filepath.Base(configImage), remotePath)
+		return ""
+	}
+	return fmt.Sprintf("http://%s:%d/agent/%s/%s", g.Config.Bastion.IP, bastionHTTPPort, g.ClusterName, configImageFilename)
+}
+
+// uploadConfigImage 将配置镜像同步到 Bastion 上的暂存目录，再以 root 权限安装到 remotePath
+func (g *ISOGenerator) uploadConfigImage(configImage, remotePath string) error {
+	client, err := agentinstall.DialBastion(g.Config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	stagingDir := path.Join(".ocpack", "upload", g.ClusterName, "iso")
+	if _, err := sshsync.Sync(client, []string{configImage}, stagingDir, sshsync.Options{Progress: g.Out}); err != nil {
+		return err
+	}
+	staged := path.Join(stagingDir, filepath.Base(configImage))
+	_, err = client.RunCommand(fmt.Sprintf("sudo install -D -m 0644 %s %s", staged, remotePath))
+	return err
+}
+
+// resolveBaseISO 返回 RHCOS 基础 ISO 的路径：优先使用 --base-iso，其次是 openshift-install 的缓存
+func resolveBaseISO(baseISOPath string) (string, error) {
+	if baseISOPath != "" {
+		if !utils.FileExists(baseISOPath) {
+			return "", i18n.Errorf("基础 ISO 不存在: %s", baseISOPath)
+		}
+		return baseISOPath, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", i18n.Errorf("获取用户主目录失败: %w", err)
+	}
+	cached := filepath.Join(home, baseISOCachePath)
+	if !utils.FileExists(cached) {
+		return "", i18n.Errorf("未找到 RHCOS 基础 ISO %s，请使用 --base-iso 指定，或先执行一次 generate-iso 由 openshift-install 缓存", cached)
+	}
+	return cached, nil
+}
@@ -0,0 +1,222 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/plain"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// --- Constants ---
+const (
+	stateFilename = ".ocpack-state.json"
+
+	// CredentialLifetime generate-iso 生成的 ignition 中 bootstrap 证书的有效期，超过后 auth/ 中的
+	// kubeconfig 和 kubeadmin-password 随之失效，节点需要使用重新生成的 ISO 启动
+	CredentialLifetime = 24 * time.Hour
+)
+
+// State 集群状态文件，记录安装过程中产生的需要在后续操作中复用的信息
+type State struct {
+	KubeconfigPath string    `json:"kubeconfig_path,omitempty"`
+	ReleaseVersion string    `json:"release_version,omitempty"` // 固定摘要对应的 release 版本
+	ReleaseDigest  string    `json:"release_digest,omitempty"`  // 镜像时记录的 release 镜像摘要，如 sha256:abcd...
+	ISOGeneratedAt time.Time `json:"iso_generated_at,omitzero"` // generate-iso 生成 ignition 和认证文件的时间
+}
+
+// DefaultPath 返回 generate-iso 保存的 kubeconfig 默认位置
+func DefaultPath(clusterDir string) string {
+	return filepath.Join(clusterDir, "installation", "ignition", "auth", "kubeconfig")
+}
+
+// LoadState 读取集群状态文件，文件不存在时返回空状态
+func LoadState(clusterDir string) (*State, error) {
+	state := &State{}
+	data, err := os.ReadFile(filepath.Join(clusterDir, stateFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取集群状态文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("解析集群状态文件失败: %w", err)
+	}
+	return state, nil
+}
+
+// SaveState 保存集群状态文件
+func SaveState(clusterDir string, state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化集群状态失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(clusterDir, stateFilename), data, 0644); err != nil {
+		return fmt.Errorf("写入集群状态文件失败: %w", err)
+	}
+	return nil
+}
+
+// Record 将安装生成的 kubeconfig 路径记录到集群状态中
+func Record(clusterDir, kubeconfigPath string) error {
+	absPath, err := filepath.Abs(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("获取 kubeconfig 绝对路径失败: %w", err)
+	}
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		return err
+	}
+	state.KubeconfigPath = absPath
+	return SaveState(clusterDir, state)
+}
+
+// RecordRelease 将镜像时得到的 release 镜像摘要记录到集群状态中，安装时固定使用该摘要
+func RecordRelease(clusterDir, version, digest string) error {
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		return err
+	}
+	state.ReleaseVersion = version
+	state.ReleaseDigest = digest
+	return SaveState(clusterDir, state)
+}
+
+// RecordISO 将 ignition 和认证文件的生成时间记录到集群状态中
+func RecordISO(clusterDir string, generatedAt time.Time) error {
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		return err
+	}
+	state.ISOGeneratedAt = generatedAt.UTC()
+	return SaveState(clusterDir, state)
+}
+
+// IgnitionAge 返回 ignition 生成后经过的时间，没有记录时返回 false
+func (s *State) IgnitionAge(now time.Time) (time.Duration, bool) {
+	if s.ISOGeneratedAt.IsZero() {
+		return 0, false
+	}
+	return now.Sub(s.ISOGeneratedAt), true
+}
+
+// CredentialWarning 在 ignition 生成超过 CredentialLifetime 时返回警告，否则返回空字符串。
+// 读取状态失败或没有记录生成时间时不警告
+func CredentialWarning(clusterDir, clusterName string, now time.Time) string {
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		return ""
+	}
+	age, ok := state.IgnitionAge(now)
+	if !ok || age < CredentialLifetime {
+		return ""
+	}
+	return fmt.Sprintf("ISO 中的 ignition 已生成 %s (%s)，超过 %s 的证书有效期，"+
+		"尚未完成安装的节点将无法加入集群，auth/ 中的 kubeconfig 和 kubeadmin-password 也会失效。\n"+
+		"   执行 ocpack regenerate-iso %s 复用现有配置重新生成 ISO，并使用新 ISO 重新启动节点",
+		age.Round(time.Hour), state.ISOGeneratedAt.Local().Format("2006-01-02 15:04"), CredentialLifetime, clusterName)
+}
+
+// PinnedReleaseDigest 返回记录的 release 版本 version 的镜像摘要，没有记录或版本不同时返回空字符串
+func (s *State) PinnedReleaseDigest(version string) string {
+	if s.ReleaseVersion != version {
+		return ""
+	}
+	return s.ReleaseDigest
+}
+
+// Find 返回集群的 kubeconfig 路径，优先使用集群状态中记录的路径，其次查找默认安装目录
+func Find(clusterDir string) (string, error) {
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		return "", err
+	}
+	if state.KubeconfigPath != "" {
+		if _, err := os.Stat(state.KubeconfigPath); err == nil {
+			return state.KubeconfigPath, nil
+		}
+	}
+
+	path := DefaultPath(clusterDir)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("kubeconfig 文件不存在: %s\n请确保集群已经安装完成", path)
+	}
+	return path, nil
+}
+
+// Merge 将集群 kubeconfig 合并到 targetPath (通常为 ~/.kube/config)，使用 contextName 命名上下文。
+// 集群条目以 contextName 命名、用户条目命名为 admin/<contextName>，避免与已有条目冲突；
+// setCurrent 为 true 时切换到该上下文
+func Merge(kubeconfigPath, targetPath, contextName string, setCurrent bool) error {
+	source, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("读取 kubeconfig %s 失败: %w", kubeconfigPath, err)
+	}
+	current, ok := source.Contexts[source.CurrentContext]
+	if !ok {
+		return fmt.Errorf("kubeconfig %s 中没有有效的 current-context", kubeconfigPath)
+	}
+	cluster, ok := source.Clusters[current.Cluster]
+	if !ok {
+		return fmt.Errorf("kubeconfig %s 中缺少集群 %s", kubeconfigPath, current.Cluster)
+	}
+	authInfo, ok := source.AuthInfos[current.AuthInfo]
+	if !ok {
+		return fmt.Errorf("kubeconfig %s 中缺少用户 %s", kubeconfigPath, current.AuthInfo)
+	}
+
+	target := clientcmdapi.NewConfig()
+	if _, err := os.Stat(targetPath); err == nil {
+		target, err = clientcmd.LoadFromFile(targetPath)
+		if err != nil {
+			return fmt.Errorf("读取 kubeconfig %s 失败: %w", targetPath, err)
+		}
+	}
+
+	userName := "admin/" + contextName
+	target.Clusters[contextName] = cluster
+	target.AuthInfos[userName] = authInfo
+	target.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   contextName,
+		AuthInfo:  userName,
+		Namespace: current.Namespace,
+	}
+	if setCurrent || target.CurrentContext == "" {
+		target.CurrentContext = contextName
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return fmt.Errorf("创建目录 %s 失败: %w", filepath.Dir(targetPath), err)
+	}
+	if err := clientcmd.WriteToFile(*target, targetPath); err != nil {
+		return fmt.Errorf("写入 kubeconfig %s 失败: %w", targetPath, err)
+	}
+	return nil
+}
+
+// DefaultTargetPath 返回默认的用户 kubeconfig 路径 (~/.kube/config)
+func DefaultTargetPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Command 构造一个使用集群 kubeconfig 的命令，标准输入输出直接透传
+func Command(kubeconfigPath, name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfigPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = plain.Stdout()
+	cmd.Stderr = plain.Stderr()
+	return cmd
+}
@@ -0,0 +1,137 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func writeKubeconfig(t *testing.T, path, server string) {
+	t.Helper()
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["demo"] = &clientcmdapi.Cluster{Server: server}
+	cfg.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "token"}
+	cfg.Contexts["admin"] = &clientcmdapi.Context{Cluster: "demo", AuthInfo: "admin"}
+	cfg.CurrentContext = "admin"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	clusterDir := t.TempDir()
+	if _, err := Find(clusterDir); err == nil {
+		t.Error("Find() expected error when kubeconfig does not exist")
+	}
+
+	writeKubeconfig(t, DefaultPath(clusterDir), "https://api.demo.example.com:6443")
+	path, err := Find(clusterDir)
+	if err != nil || path != DefaultPath(clusterDir) {
+		t.Errorf("Find() = %q, %v, expected default path", path, err)
+	}
+
+	recorded := filepath.Join(clusterDir, "custom", "kubeconfig")
+	writeKubeconfig(t, recorded, "https://api.demo.example.com:6443")
+	if err := Record(clusterDir, recorded); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if path, err := Find(clusterDir); err != nil || path != recorded {
+		t.Errorf("Find() = %q, %v, expected recorded path %q", path, err, recorded)
+	}
+}
+
+func TestRecordRelease(t *testing.T) {
+	clusterDir := t.TempDir()
+	recorded := filepath.Join(clusterDir, "kubeconfig")
+	if err := Record(clusterDir, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordRelease(clusterDir, "4.16.3", "sha256:abcd"); err != nil {
+		t.Fatalf("RecordRelease() error = %v", err)
+	}
+
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.KubeconfigPath != recorded {
+		t.Errorf("KubeconfigPath = %q, expected it to be preserved", state.KubeconfigPath)
+	}
+	if got := state.PinnedReleaseDigest("4.16.3"); got != "sha256:abcd" {
+		t.Errorf("PinnedReleaseDigest(4.16.3) = %q", got)
+	}
+	if got := state.PinnedReleaseDigest("4.16.5"); got != "" {
+		t.Errorf("PinnedReleaseDigest(4.16.5) = %q, expected no digest for another version", got)
+	}
+}
+
+func TestCredentialWarning(t *testing.T) {
+	clusterDir := t.TempDir()
+	now := time.Now()
+	if got := CredentialWarning(clusterDir, "demo", now); got != "" {
+		t.Errorf("CredentialWarning() = %q, expected no warning without a recorded ISO", got)
+	}
+
+	if err := RecordRelease(clusterDir, "4.16.3", "sha256:abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordISO(clusterDir, now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("RecordISO() error = %v", err)
+	}
+	if got := CredentialWarning(clusterDir, "demo", now); got != "" {
+		t.Errorf("CredentialWarning() = %q, expected no warning for a fresh ISO", got)
+	}
+
+	state, err := LoadState(clusterDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ReleaseDigest != "sha256:abcd" {
+		t.Errorf("ReleaseDigest = %q, expected it to be preserved", state.ReleaseDigest)
+	}
+	if age, ok := state.IgnitionAge(now); !ok || age.Round(time.Hour) != 2*time.Hour {
+		t.Errorf("IgnitionAge() = %v, %v", age, ok)
+	}
+
+	got := CredentialWarning(clusterDir, "demo", now.Add(CredentialLifetime))
+	if !strings.Contains(got, "ocpack regenerate-iso demo") {
+		t.Errorf("CredentialWarning() = %q, expected a regenerate-iso hint after 24h", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "kubeconfig")
+	target := filepath.Join(dir, ".kube", "config")
+	writeKubeconfig(t, source, "https://api.demo.example.com:6443")
+	writeKubeconfig(t, target, "https://api.other.example.com:6443")
+
+	if err := Merge(source, target, "demo-ctx", true); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	merged, err := clientcmd.LoadFromFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.CurrentContext != "demo-ctx" {
+		t.Errorf("CurrentContext = %q, expected demo-ctx", merged.CurrentContext)
+	}
+	if _, ok := merged.Contexts["admin"]; !ok {
+		t.Error("existing context should be preserved")
+	}
+	if got := merged.Clusters["demo-ctx"].Server; got != "https://api.demo.example.com:6443" {
+		t.Errorf("merged cluster server = %q", got)
+	}
+	if got := merged.Contexts["demo-ctx"].AuthInfo; got != "admin/demo-ctx" {
+		t.Errorf("merged context user = %q", got)
+	}
+}
@@ -12,7 +12,8 @@ import (
 
 	"github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
-	"golang.org/x/term"
+
+	"ocpack/pkg/plain"
 )
 
 type MirrorUnArchiver struct {
@@ -57,8 +58,8 @@ func (o MirrorUnArchiver) Unarchive() error {
 		return fmt.Errorf("unable to create cache dir %q: %w", o.cacheDir, err)
 	}
 
-	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
-	p := mpb.New(mpb.PopCompletedMode())
+	isTerminal := plain.IsTerminal()
+	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithAutoRefresh(), isTerminal))
 	for i, chunkPath := range o.archiveFiles {
 		if !isTerminal {
 			// FIXME: replace this by a proper log call
@@ -66,13 +66,17 @@ func saveErrors(logger clog.PluggableLoggerInterface, logsDir string, errArray [
 }
 
 func formatErrorMsg(err mirrorErrorSchema) string {
+	var attempts string
+	if err.attempts > 1 {
+		attempts = fmt.Sprintf(" (failed after %d attempts)", err.attempts)
+	}
 	if len(err.operators) > 0 || len(err.bundles) > 0 {
 		bundles := slices.Sorted(maps.Values(err.bundles))
 		operators := slices.Sorted(maps.Keys(err.operators))
-		return fmt.Sprintf("error mirroring image %s (Operator bundles: %v - Operators: %v) error: %s", err.image.Origin, bundles, operators, err.err.Error())
+		return fmt.Sprintf("error mirroring image %s (Operator bundles: %v - Operators: %v) error: %s%s", err.image.Origin, bundles, operators, err.err.Error(), attempts)
 	}
 
-	return fmt.Sprintf("error mirroring image %s error: %s", err.image.Origin, err.err.Error())
+	return fmt.Sprintf("error mirroring image %s error: %s%s", err.image.Origin, err.err.Error(), attempts)
 }
 
 func (s StringMap) Has(key string) bool {
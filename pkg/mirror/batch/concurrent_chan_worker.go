@@ -17,6 +17,7 @@ import (
 	"ocpack/pkg/mirror/emoji"
 	clog "ocpack/pkg/mirror/log"
 	"ocpack/pkg/mirror/mirror"
+	"ocpack/pkg/mirror/progress"
 	"ocpack/pkg/mirror/spinners"
 )
 
@@ -68,11 +69,22 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 
 	opts.PreserveDigests = true
 
+	// with an image order, each group is copied completely before the next one starts, so that
+	// e.g. the release is in the destination even if the run is interrupted during the operators
+	ordered := len(opts.Global.ImageOrder) > 0
+	if ordered {
+		collectorSchema.AllImages = OrderByGroup(collectorSchema.AllImages, opts.Global.ImageOrder)
+	}
+
 	total := len(collectorSchema.AllImages)
+	retry := newRetryPolicy(opts.Global)
 
 	o.Log.Info("🚀 "+mirrorMsg+" %d images...", total)
 
-	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !opts.Global.IsTerminal))
+	runProgress := RunProgress{Function: opts.Function, Total: total, Start: startTime}
+	writeProgress(o.LogsDir, runProgress)
+
+	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !opts.Global.IsTerminal), mpb.ContainerOptional(mpb.WithAutoRefresh(), opts.Global.IsTerminal))
 	results := make(chan GoroutineResult, total)
 	progressCh := make(chan int, total)
 	semaphore := make(chan struct{}, o.MaxGoroutines)
@@ -89,6 +101,7 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 		defer close(results)
 		defer close(semaphore)
 
+		var currentGroup string
 		for _, img := range collectorSchema.AllImages {
 
 			select {
@@ -98,8 +111,19 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 			default:
 			}
 
+			if group := GroupOf(img.Type); ordered && group != currentGroup {
+				if currentGroup != "" {
+					wg.Wait()
+					o.Log.Info("✅ %s images finished, starting %s images", currentGroup, group)
+				}
+				currentGroup = group
+			}
+
 			semaphore <- struct{}{}
 
+			// images are sorted by type, so the phase changes once per type
+			progress.Start(o.Log, phaseOf(img.Type, opts))
+
 			sp := newSpinner(img, opts.LocalStorageFQDN, p)
 
 			wg.Add(1)
@@ -128,41 +152,35 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 				}
 
 				var err error
-				var triggered bool
+				attempt := 0
+				for attempt < retry.attempts {
+					attempt++
+					timeoutCtx, _ := opts.Global.CommandTimeoutContext()
+
+					options := opts
+					if img.Type.IsOperatorCatalog() && img.RebuiltTag != "" {
+						options.RemoveSignatures = true
+					}
 
-				// 添加重试机制
-				maxRetries := 3
-				for attempt := 0; attempt < maxRetries; attempt++ {
+					err = o.Mirror.Run(timeoutCtx, img.Source, img.Destination, mirror.Mode(opts.Function), &options) //nolint:contextcheck
+					if err == nil {
+						spinner.Increment()
+						results <- result
+						return
+					}
+
+					// 最后一次尝试或不可重试的错误
+					if attempt == retry.attempts || !retry.retryable(err) {
+						break
+					}
+
+					wait := retry.wait(attempt)
+					o.Log.Debug("retrying %s in %v (attempt %d/%d): %v", img.Origin, wait, attempt+1, retry.attempts, err)
 					select {
 					case <-cancelCtx.Done():
 						spinner.Abort(false)
 						return
-					default:
-						if !triggered || attempt > 0 {
-							triggered = true
-							timeoutCtx, _ := opts.Global.CommandTimeoutContext()
-
-							options := opts
-							if img.Type.IsOperatorCatalog() && img.RebuiltTag != "" {
-								options.RemoveSignatures = true
-							}
-
-							err = o.Mirror.Run(timeoutCtx, img.Source, img.Destination, mirror.Mode(opts.Function), &options) //nolint:contextcheck
-
-							if err == nil {
-								spinner.Increment()
-								results <- result
-								return
-							}
-
-							// 如果是最后一次尝试或者是不可重试的错误
-							if attempt == maxRetries-1 || isNonRetryableError(err) {
-								break
-							}
-
-							// 短暂延迟后重试
-							time.Sleep(time.Duration(attempt+1) * time.Second)
-						}
+					case <-time.After(wait):
 					}
 				}
 
@@ -171,10 +189,10 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 				case img.Type.IsOperator():
 					operators := collectorSchema.CopyImageSchemaMap.OperatorsByImage[img.Origin]
 					bundles := collectorSchema.CopyImageSchemaMap.BundlesByImage[img.Origin]
-					result.err = &mirrorErrorSchema{image: img, err: err, operators: operators, bundles: bundles}
+					result.err = &mirrorErrorSchema{image: img, err: err, operators: operators, bundles: bundles, attempts: attempt}
 					spinner.Abort(false)
 				case img.Type.IsRelease() || img.Type.IsAdditionalImage() || img.Type.IsHelmImage():
-					result.err = &mirrorErrorSchema{image: img, err: err}
+					result.err = &mirrorErrorSchema{image: img, err: err, attempts: attempt}
 					spinner.Abort(false)
 				}
 				results <- result
@@ -195,7 +213,7 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 		if err == nil {
 			logImageSuccess(o.Log, &res.img, &opts)
 			copiedImages.AllImages = append(copiedImages.AllImages, res.img)
-			incrementTotals(res.imgType, &copiedImages)
+			IncrementTotals(res.imgType, &copiedImages)
 		} else {
 			m.Lock()
 			errArray = append(errArray, *err)
@@ -219,9 +237,18 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 
 		completed++
 		progressCh <- 1
+
+		runProgress.Phase = phaseOf(res.imgType, opts)
+		runProgress.Completed = completed
+		runProgress.Succeeded = len(copiedImages.AllImages)
+		runProgress.Failed = len(errArray)
+		writeProgress(o.LogsDir, runProgress)
 	}
 	close(progressCh)
 
+	runProgress.Done = true
+	writeProgress(o.LogsDir, runProgress)
+
 	p.Wait()
 
 	// 增强的结果统计
@@ -232,7 +259,7 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 	logResults(o.Log, opts.Function, &copiedImages, &collectorSchema)
 
 	// 显示详细的执行摘要
-	o.Log.Info("📊 执行摘要: 总计 %d 个镜像, 成功 %d, 失败 %d, 跳过 %d, 用时 %v",
+	progress.Summary(o.Log, "📊 执行摘要: 总计 %d 个镜像, 成功 %d, 失败 %d, 跳过 %d, 用时 %v",
 		total, successCount, failureCount, skipCount, duration.Round(time.Second))
 
 	if successCount > 0 {
@@ -332,9 +359,9 @@ func logResults(log clog.PluggableLoggerInterface, copyMode string, copiedImages
 	expected := collectorSchema.TotalReleaseImages + collectorSchema.TotalOperatorImages + collectorSchema.TotalAdditionalImages + collectorSchema.TotalHelmImages
 
 	if total == expected {
-		log.Info("✅ %s %d/%d images successfully", copyModeMsg, total, expected)
+		progress.Summary(log, "✅ %s %d/%d images successfully", copyModeMsg, total, expected)
 	} else {
-		log.Info("⚠️  %s %d/%d images (some failed)", copyModeMsg, total, expected)
+		progress.Summary(log, "⚠️  %s %d/%d images (some failed)", copyModeMsg, total, expected)
 		// 只在有失败时显示详细分解
 		if copiedImages.TotalReleaseImages != collectorSchema.TotalReleaseImages {
 			logResult(log, copyModeMsg, "release", copiedImages.TotalReleaseImages, collectorSchema.TotalReleaseImages)
@@ -354,13 +381,29 @@ func logResults(log clog.PluggableLoggerInterface, copyMode string, copiedImages
 func logResult(log clog.PluggableLoggerInterface, copyMode, imageType string, copied, total int) {
 	if total != 0 {
 		if copied == total {
-			log.Info(emoji.SpinnerCheckMark+" %d / %d %s images %s successfully", copied, total, imageType, copyMode)
+			progress.Summary(log, emoji.SpinnerCheckMark+" %d / %d %s images %s successfully", copied, total, imageType, copyMode)
 		} else {
-			log.Info(emoji.SpinnerCrossMark+" %d / %d %s images %s: Some %s images failed to be %s - please check the logs", copied, total, imageType, copyMode, imageType, copyMode)
+			progress.Summary(log, emoji.SpinnerCrossMark+" %d / %d %s images %s: Some %s images failed to be %s - please check the logs", copied, total, imageType, copyMode, imageType, copyMode)
 		}
 	}
 }
 
+// phaseOf returns the progress phase an image is copied (or deleted) in
+func phaseOf(imgType v2alpha1.ImageType, opts mirror.CopyOptions) string {
+	switch {
+	case opts.IsDelete():
+		return progress.PhaseDelete
+	case imgType.IsRelease():
+		return progress.PhaseCopyRelease
+	case imgType.IsOperator():
+		return progress.PhaseCopyOperators
+	case imgType.IsHelmImage():
+		return progress.PhaseCopyHelm
+	default:
+		return progress.PhaseCopyAdditional
+	}
+}
+
 func logImageSuccess(log clog.PluggableLoggerInterface, image *v2alpha1.CopyImageSchema, opts *mirror.CopyOptions) {
 	if opts.Global.IsTerminal {
 		// It'll be printed by the spinner
@@ -457,7 +500,8 @@ func runOverallProgress(overallProgress *mpb.Bar, cancelCtx context.Context, pro
 	}
 }
 
-func incrementTotals(imgType v2alpha1.ImageType, copiedImages *v2alpha1.CollectorSchema) {
+// IncrementTotals counts an image of imgType in the per-type totals of a collector schema
+func IncrementTotals(imgType v2alpha1.ImageType, copiedImages *v2alpha1.CollectorSchema) {
 	switch imgType {
 	case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent:
 		copiedImages.TotalReleaseImages++
@@ -503,37 +547,6 @@ func shouldSkipImage(img v2alpha1.CopyImageSchema, opts mirror.CopyOptions, errA
 
 // 错误处理辅助函数
 
-// isNonRetryableError 判断是否为不可重试的错误
-func isNonRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-
-	// 认证错误通常不可重试
-	if strings.Contains(errStr, "unauthorized") ||
-		strings.Contains(errStr, "forbidden") ||
-		strings.Contains(errStr, "authentication") ||
-		strings.Contains(errStr, "login") {
-		return true
-	}
-
-	// 镜像不存在错误
-	if strings.Contains(errStr, "not found") ||
-		strings.Contains(errStr, "does not exist") {
-		return true
-	}
-
-	// 配置错误
-	if strings.Contains(errStr, "invalid") ||
-		strings.Contains(errStr, "malformed") {
-		return true
-	}
-
-	return false
-}
-
 // isCriticalError 判断是否为关键错误
 func isCriticalError(err error) bool {
 	if err == nil {
@@ -99,6 +99,11 @@ func TestChannelConcurrentWorker(t *testing.T) {
 			t.Fatal("should pass")
 		}
 		assert.ElementsMatch(t, relatedImages, copiedImages.AllImages)
+
+		p, err := ReadProgress(tempDir)
+		assert.NoError(t, err)
+		assert.Equal(t, RunProgress{Function: "copy", Phase: p.Phase, Total: len(relatedImages), Completed: len(relatedImages),
+			Succeeded: len(relatedImages), Start: p.Start, Updated: p.Updated, Done: true}, *p)
 	})
 
 	t.Run("Testing m2d Worker - no errors: should pass", func(t *testing.T) {
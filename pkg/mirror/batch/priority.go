@@ -0,0 +1,80 @@
+package batch
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"ocpack/pkg/mirror/api/v2alpha1"
+)
+
+// Image groups accepted by --image-order and --only
+const (
+	GroupRelease    = "release"
+	GroupOperators  = "operators"
+	GroupAdditional = "additional"
+	GroupHelm       = "helm"
+)
+
+// Groups lists the image groups in their default order
+var Groups = []string{GroupRelease, GroupOperators, GroupAdditional, GroupHelm}
+
+// GroupOf returns the image group of an image type
+func GroupOf(imgType v2alpha1.ImageType) string {
+	switch {
+	case imgType.IsRelease():
+		return GroupRelease
+	case imgType.IsOperator():
+		return GroupOperators
+	case imgType.IsHelmImage():
+		return GroupHelm
+	default:
+		return GroupAdditional
+	}
+}
+
+// ValidateGroups checks that groups only contains known image groups, without duplicates
+func ValidateGroups(groups []string) error {
+	seen := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		if !slices.Contains(Groups, group) {
+			return fmt.Errorf("unknown image group %q, expected one of %v", group, Groups)
+		}
+		if seen[group] {
+			return fmt.Errorf("image group %q is listed more than once", group)
+		}
+		seen[group] = true
+	}
+	return nil
+}
+
+// OrderByGroup sorts images by the position of their group in order, keeping the existing
+// type priority within a group. Groups missing from order come last, in their default order.
+func OrderByGroup(images []v2alpha1.CopyImageSchema, order []string) []v2alpha1.CopyImageSchema {
+	rank := func(img v2alpha1.CopyImageSchema) int {
+		group := GroupOf(img.Type)
+		if i := slices.Index(order, group); i >= 0 {
+			return i
+		}
+		return len(order) + slices.Index(Groups, group)
+	}
+	sorted := slices.Clone(images)
+	sort.SliceStable(sorted, func(i, j int) bool { return rank(sorted[i]) < rank(sorted[j]) })
+	return sorted
+}
+
+// FilterGroups splits images into those belonging to one of groups and the others
+func FilterGroups(collectorSchema v2alpha1.CollectorSchema, groups []string) (selected v2alpha1.CollectorSchema, excluded []v2alpha1.CopyImageSchema) {
+	selected = collectorSchema
+	selected.AllImages = nil
+	selected.TotalReleaseImages, selected.TotalOperatorImages, selected.TotalAdditionalImages, selected.TotalHelmImages = 0, 0, 0, 0
+	for _, img := range collectorSchema.AllImages {
+		if !slices.Contains(groups, GroupOf(img.Type)) {
+			excluded = append(excluded, img)
+			continue
+		}
+		selected.AllImages = append(selected.AllImages, img)
+		IncrementTotals(img.Type, &selected)
+	}
+	return selected, excluded
+}
@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"testing"
+
+	"ocpack/pkg/mirror/api/v2alpha1"
+)
+
+func TestOrderByGroup(t *testing.T) {
+	images := []v2alpha1.CopyImageSchema{
+		{Source: "release-content", Type: v2alpha1.TypeOCPReleaseContent},
+		{Source: "release", Type: v2alpha1.TypeOCPRelease},
+		{Source: "related", Type: v2alpha1.TypeOperatorRelatedImage},
+		{Source: "generic", Type: v2alpha1.TypeGeneric},
+		{Source: "helm", Type: v2alpha1.TypeHelmImage},
+		{Source: "bundle", Type: v2alpha1.TypeOperatorBundle},
+		{Source: "catalog", Type: v2alpha1.TypeOperatorCatalog},
+	}
+
+	sorted := OrderByGroup(images, []string{GroupAdditional, GroupRelease})
+	var got []string
+	for _, img := range sorted {
+		got = append(got, img.Source)
+	}
+	want := []string{"generic", "release-content", "release", "related", "bundle", "catalog", "helm"}
+	if len(got) != len(want) {
+		t.Fatalf("OrderByGroup() = %v, expected %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("OrderByGroup() = %v, expected %v", got, want)
+		}
+	}
+	if images[0].Source != "release-content" {
+		t.Error("OrderByGroup() should not modify its input")
+	}
+}
+
+func TestFilterGroups(t *testing.T) {
+	schema := v2alpha1.CollectorSchema{AllImages: []v2alpha1.CopyImageSchema{
+		{Source: "release", Type: v2alpha1.TypeOCPRelease},
+		{Source: "kubevirt", Type: v2alpha1.TypeKubeVirtContainer},
+		{Source: "related", Type: v2alpha1.TypeOperatorRelatedImage},
+		{Source: "generic", Type: v2alpha1.TypeGeneric},
+	}, TotalReleaseImages: 2, TotalOperatorImages: 1, TotalAdditionalImages: 1}
+
+	selected, excluded := FilterGroups(schema, []string{GroupRelease})
+	if len(selected.AllImages) != 2 || selected.TotalReleaseImages != 1 || selected.TotalOperatorImages != 0 || selected.TotalAdditionalImages != 0 {
+		t.Errorf("unexpected selected images: %+v", selected)
+	}
+	if len(excluded) != 2 || excluded[0].Source != "related" || excluded[1].Source != "generic" {
+		t.Errorf("unexpected excluded images: %+v", excluded)
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	if err := ValidateGroups([]string{GroupRelease, GroupOperators}); err != nil {
+		t.Errorf("ValidateGroups() error = %v", err)
+	}
+	if err := ValidateGroups([]string{"platform"}); err == nil {
+		t.Error("ValidateGroups() should reject unknown groups")
+	}
+	if err := ValidateGroups([]string{GroupRelease, GroupRelease}); err == nil {
+		t.Error("ValidateGroups() should reject duplicate groups")
+	}
+}
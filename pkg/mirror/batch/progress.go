@@ -0,0 +1,78 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ProgressFilename is the file in the logs directory that holds the live state
+// of the current batch run, read by `ocpack ui` while the run is in progress
+const ProgressFilename = "progress.json"
+
+// RunProgress is the state of a batch run
+type RunProgress struct {
+	Function  string    `json:"function"` // copy or delete
+	Phase     string    `json:"phase"`    // phase of the last finished image, see package progress
+	Total     int       `json:"total"`
+	Completed int       `json:"completed"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Start     time.Time `json:"start"`
+	Updated   time.Time `json:"updated"`
+	Done      bool      `json:"done"`
+}
+
+// writeProgress replaces the progress file in logsDir. Write errors are ignored,
+// the progress file is informational only.
+func writeProgress(logsDir string, p RunProgress) {
+	if logsDir == "" {
+		return
+	}
+	p.Updated = time.Now()
+	data, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	path := filepath.Join(logsDir, ProgressFilename)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, path)
+}
+
+// ReadProgress reads the progress file in logsDir, returning nil when no run
+// has written one
+func ReadProgress(logsDir string) (*RunProgress, error) {
+	data, err := os.ReadFile(filepath.Join(logsDir, ProgressFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &RunProgress{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LatestProgress returns the most recently updated progress found in logsDirs,
+// or nil when none of them holds a progress file
+func LatestProgress(logsDirs []string) *RunProgress {
+	var latest *RunProgress
+	for _, dir := range logsDirs {
+		p, err := ReadProgress(dir)
+		if err != nil || p == nil {
+			continue
+		}
+		if latest == nil || p.Updated.After(latest.Updated) {
+			latest = p
+		}
+	}
+	return latest
+}
@@ -0,0 +1,116 @@
+package batch
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"ocpack/pkg/mirror/mirror"
+)
+
+// Backoff strategies accepted by --copy-backoff
+const (
+	BackoffLinear      = "linear"
+	BackoffExponential = "exponential"
+)
+
+const (
+	defaultCopyAttempts   = 3
+	defaultCopyRetryDelay = time.Second
+	maxCopyRetryDelay     = 5 * time.Minute
+)
+
+// nonRetryableErrors are error substrings that retrying does not fix
+var nonRetryableErrors = []string{
+	// 认证错误
+	"unauthorized", "forbidden", "authentication", "login",
+	// 镜像不存在
+	"not found", "does not exist",
+	// 配置错误
+	"invalid", "malformed",
+}
+
+// retryPolicy decides whether and when a failed image copy is attempted again
+type retryPolicy struct {
+	attempts     int
+	backoff      string
+	delay        time.Duration
+	jitter       bool
+	nonRetryable []string
+	alwaysRetry  []string
+}
+
+// ValidateRetry checks the per-image retry options
+func ValidateRetry(opts *mirror.GlobalOptions) error {
+	if opts.CopyAttempts < 0 {
+		return fmt.Errorf("--copy-attempts must not be negative")
+	}
+	if opts.CopyRetryDelay < 0 {
+		return fmt.Errorf("--copy-retry-delay must not be negative")
+	}
+	switch opts.CopyBackoff {
+	case "", BackoffLinear, BackoffExponential:
+		return nil
+	}
+	return fmt.Errorf("--copy-backoff %q is invalid, expected %s or %s", opts.CopyBackoff, BackoffLinear, BackoffExponential)
+}
+
+// newRetryPolicy builds the retry policy from the global options, unset values fall back to
+// 3 attempts with a linear backoff starting at 1s
+func newRetryPolicy(opts *mirror.GlobalOptions) retryPolicy {
+	policy := retryPolicy{attempts: defaultCopyAttempts, backoff: BackoffLinear, delay: defaultCopyRetryDelay}
+	if opts == nil {
+		policy.nonRetryable = nonRetryableErrors
+		return policy
+	}
+	if opts.CopyAttempts > 0 {
+		policy.attempts = opts.CopyAttempts
+	}
+	if opts.CopyBackoff != "" {
+		policy.backoff = opts.CopyBackoff
+	}
+	if opts.CopyRetryDelay > 0 {
+		policy.delay = opts.CopyRetryDelay
+	}
+	policy.jitter = opts.CopyRetryJitter
+	policy.nonRetryable = append(append([]string{}, nonRetryableErrors...), opts.NonRetryableErrors...)
+	policy.alwaysRetry = opts.RetryableErrors
+	return policy
+}
+
+// wait returns how long to wait before the given retry (1 for the first retry)
+func (p retryPolicy) wait(retry int) time.Duration {
+	wait := p.delay * time.Duration(retry)
+	if p.backoff == BackoffExponential {
+		wait = p.delay
+		for i := 1; i < retry && wait < maxCopyRetryDelay; i++ {
+			wait *= 2
+		}
+	}
+	wait = min(wait, maxCopyRetryDelay)
+	if p.jitter && wait > 0 {
+		// somewhere between half and one and a half times the wait
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait)+1))
+	}
+	return wait
+}
+
+// retryable reports whether a failed copy should be attempted again
+func (p retryPolicy) retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	for _, pattern := range p.alwaysRetry {
+		if pattern != "" && strings.Contains(errStr, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	for _, pattern := range p.nonRetryable {
+		if pattern != "" && strings.Contains(errStr, strings.ToLower(pattern)) {
+			return false
+		}
+	}
+	return true
+}
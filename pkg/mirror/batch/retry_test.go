@@ -0,0 +1,68 @@
+package batch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ocpack/pkg/mirror/mirror"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		policy := newRetryPolicy(&mirror.GlobalOptions{})
+		if policy.attempts != 3 || policy.wait(1) != time.Second || policy.wait(2) != 2*time.Second {
+			t.Errorf("unexpected default policy: %+v", policy)
+		}
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		policy := newRetryPolicy(&mirror.GlobalOptions{CopyAttempts: 20, CopyBackoff: BackoffExponential, CopyRetryDelay: 2 * time.Second})
+		expected := map[int]time.Duration{1: 2 * time.Second, 2: 4 * time.Second, 3: 8 * time.Second, 15: maxCopyRetryDelay}
+		for retry, wait := range expected {
+			if got := policy.wait(retry); got != wait {
+				t.Errorf("wait(%d) = %v, expected %v", retry, got, wait)
+			}
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := newRetryPolicy(&mirror.GlobalOptions{CopyRetryDelay: 10 * time.Second, CopyRetryJitter: true})
+		for range 100 {
+			if got := policy.wait(1); got < 5*time.Second || got > 15*time.Second {
+				t.Fatalf("wait(1) = %v, expected between 5s and 15s", got)
+			}
+		}
+	})
+
+	t.Run("classification", func(t *testing.T) {
+		policy := newRetryPolicy(&mirror.GlobalOptions{
+			NonRetryableErrors: []string{"Quota Exceeded"},
+			RetryableErrors:    []string{"manifest unknown: not found"},
+		})
+		tests := map[string]bool{
+			"connection reset by peer":                      true,
+			"unauthorized: authentication required":         false,
+			"denied: quota exceeded":                        false,
+			"reading manifest: manifest unknown: not found": true,
+			"repository not found":                          false,
+		}
+		for msg, retryable := range tests {
+			if got := policy.retryable(errors.New(msg)); got != retryable {
+				t.Errorf("retryable(%q) = %t, expected %t", msg, got, retryable)
+			}
+		}
+	})
+}
+
+func TestValidateRetry(t *testing.T) {
+	if err := ValidateRetry(&mirror.GlobalOptions{CopyAttempts: 3, CopyBackoff: BackoffExponential}); err != nil {
+		t.Errorf("ValidateRetry() error = %v", err)
+	}
+	if err := ValidateRetry(&mirror.GlobalOptions{CopyBackoff: "fibonacci"}); err == nil {
+		t.Error("ValidateRetry() should reject an unknown backoff")
+	}
+	if err := ValidateRetry(&mirror.GlobalOptions{CopyAttempts: -1}); err == nil {
+		t.Error("ValidateRetry() should reject negative attempts")
+	}
+}
@@ -16,6 +16,7 @@ type mirrorErrorSchema struct {
 	err       error
 	operators map[string]struct{}
 	bundles   StringMap
+	attempts  int // number of times the copy was attempted
 }
 
 func (e mirrorErrorSchema) Error() string {
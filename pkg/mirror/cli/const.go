@@ -23,6 +23,8 @@ const (
 	dryRunOutDir                  string = "dry-run"
 	mappingFile                   string = "mapping.txt"
 	missingImgsFile               string = "missing.txt"
+	imagesListFile                string = "images.json"
+	mirroredImagesFile            string = "mirrored-images.json"
 	clusterResourcesDir           string = "cluster-resources"
 	helmDir                       string = "helm"
 	helmChartDir                  string = "charts"
@@ -63,32 +63,23 @@ func NewDeleteCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOption
 		PreRun: func(cmd *cobra.Command, args []string) {
 			opts.Function = string(mirror.DeleteMode)
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			err := ex.ValidateDelete(args)
-			if err != nil {
-				log.Error("%v ", err)
-				os.Exit(1)
+		// errors are returned instead of exiting, the command is embedded in ocpack
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ex.ValidateDelete(args); err != nil {
+				return err
 			}
-			err = ex.CompleteDelete(args)
-			if err != nil {
-				log.Error("%v ", err)
-				os.Exit(1)
+			if err := ex.CompleteDelete(args); err != nil {
+				return err
 			}
 			defer ex.logFile.Close()
 			cmd.SetOutput(ex.logFile)
 
 			// prepare internal storage
-			err = ex.setupLocalStorage(cmd.Context())
-			if err != nil {
-				log.Error(" %v ", err)
-				os.Exit(1)
+			if err := ex.setupLocalStorage(cmd.Context()); err != nil {
+				return err
 			}
 
-			err = ex.RunDelete(cmd)
-			if err != nil {
-				log.Error("%v ", err)
-				os.Exit(1)
-			}
+			return ex.RunDelete(cmd)
 		},
 	}
 	cmd.Flags().StringVar(&opts.Global.DeleteID, "delete-id", "", "Used to differentiate between versions for files created by the delete functionality")
@@ -195,6 +186,9 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 		} else {
 			return fmt.Errorf("--workspace flag must have a file:// protocol prefix")
 		}
+	} else if strings.HasPrefix(o.Opts.Global.WorkingDir, fileProtocol) {
+		// keep the logs of the delete run in the workspace instead of the current directory
+		o.Opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol), workingDir)
 	}
 
 	// setup logs level, and logsDir under workingDir
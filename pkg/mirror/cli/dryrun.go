@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 
@@ -10,6 +11,14 @@ import (
 	"ocpack/pkg/mirror/emoji"
 )
 
+// dryRunImage is an entry of images.json, the typed counterpart of mapping.txt
+// used by `ocpack plan` to group images by content type
+type dryRunImage struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Type        string `json:"type"`
+}
+
 func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyImageSchema) error {
 	// set up location of logs dir
 	outDir := filepath.Join(o.Opts.Global.WorkingDir, dryRunOutDir)
@@ -51,6 +60,9 @@ func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyIm
 	if err != nil {
 		return err
 	}
+	if err := writeImagesList(filepath.Join(outDir, imagesListFile), allImages); err != nil {
+		return err
+	}
 	if nbMissingImgs > 0 {
 		// creating file for storing list of cached images
 		missingImgsFilePath := filepath.Join(outDir, missingImgsFile)
@@ -73,3 +85,16 @@ func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyIm
 	o.Log.Info(emoji.PageFacingUp+" list of all images for mirroring in : %s", mappingTxtFilePath)
 	return nil
 }
+
+// writeImagesList writes the source, destination and type of every image to path
+func writeImagesList(path string, allImages []v2alpha1.CopyImageSchema) error {
+	images := make([]dryRunImage, 0, len(allImages))
+	for _, img := range allImages {
+		images = append(images, dryRunImage{Source: img.Source, Destination: img.Destination, Type: img.Type.String()})
+	}
+	content, err := json.MarshalIndent(images, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -21,7 +21,6 @@ import (
 	"text/template"
 	"time"
 
-	"golang.org/x/term"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	"github.com/distribution/distribution/v3/configuration"
@@ -50,10 +49,12 @@ import (
 	"ocpack/pkg/mirror/manifest"
 	"ocpack/pkg/mirror/mirror"
 	"ocpack/pkg/mirror/operator"
+	"ocpack/pkg/mirror/progress"
 	"ocpack/pkg/mirror/registriesd"
 	"ocpack/pkg/mirror/release"
 	"ocpack/pkg/mirror/spinners"
 	"ocpack/pkg/mirror/version"
+	"ocpack/pkg/plain"
 )
 
 var (
@@ -152,8 +153,14 @@ func (o MakeDir) makeDirAll(dir string, mode os.FileMode) error {
 
 // NewMirrorCmd - cobra entry point
 func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
+	// with --plain stdout is a pipe to the ASCII translator, so ask plain whether the
+	// real stdout is a terminal; progress containers then force auto refresh
 	global := &mirror.GlobalOptions{
-		IsTerminal: term.IsTerminal(int(os.Stdout.Fd())),
+		IsTerminal: plain.IsTerminal(),
+	}
+	// progress spinners would interleave with a live line stream
+	if s, ok := log.(interface{ Streaming() bool }); ok && s.Streaming() {
+		global.IsTerminal = false
 	}
 
 	flagSharedOpts, sharedOpts := mirror.SharedImageFlags()
@@ -264,6 +271,7 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&opts.Global.MemProf, "mem-prof", false, "Enable Memory profiling")
 	cmd.PersistentFlags().StringVar(&opts.Global.RegistriesDirPath, "registries.d", "", "use registry configuration files in `DIR` (e.g. for container signature storage)")
 	cmd.PersistentFlags().StringVar(&opts.Global.PolicyPath, "policy", "", "Path to a trust policy file")
+	cmd.PersistentFlags().StringVar(&opts.Global.RegistriesConfPath, "registries-conf", "", "Path to a registries.conf file, takes precedence over CONTAINERS_REGISTRIES_CONF")
 	cmd.PersistentFlags().AddFlagSet(&flagSharedOpts)
 	cmd.PersistentFlags().AddFlagSet(&flagRetryOpts)
 	cmd.PersistentFlags().AddFlagSet(&flagDepTLS)
@@ -282,6 +290,20 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.Flags().StringVar(&opts.RootlessStoragePath, "rootless-storage-path", "", "Override the default container rootless storage path (usually in etc/containers/storage.conf)")
 	cmd.Flags().BoolVar(&opts.RemoveSignatures, "remove-signatures", true, "Do not copy image signature")
 	cmd.Flags().BoolVar(&opts.Global.IgnoreReleaseSignature, "ignore-release-signature", false, "Ignore release signature")
+	cmd.Flags().DurationVar(&opts.Global.GraphCacheTTL, "graph-cache-ttl", 0, "Reuse upgrade graph data saved in the working-dir by a previous run if it is younger than this duration")
+	cmd.Flags().BoolVar(&opts.Global.OfflineGraph, "offline-graph", false, "Only use upgrade graph data saved in the working-dir by a previous run, never query the upstream update service")
+	cmd.Flags().DurationVar(&opts.Global.CatalogCacheTTL, "catalog-cache-ttl", 0, "Reuse the operator catalog digest resolved by a previous run if it is younger than this duration")
+	cmd.Flags().BoolVar(&opts.Global.RefreshCatalog, "refresh-catalog", false, "Always resolve operator catalogs from the source registry, ignoring --catalog-cache-ttl")
+	cmd.Flags().BoolVar(&opts.Global.OfflineCatalog, "offline-catalog", false, "Only use operator catalogs collected in the working-dir by a previous run, never query the source registry")
+	cmd.Flags().BoolVar(&opts.Global.SkipPresent, "skip-present", false, "Query the destination before copying and skip images that are already present with the same digest")
+	cmd.Flags().StringSliceVar(&opts.Global.ImageOrder, "image-order", nil, "Copy the image groups (release, operators, additional, helm) one after another in this order")
+	cmd.Flags().StringSliceVar(&opts.Global.Only, "only", nil, "Only copy the images of these groups (release, operators, additional, helm)")
+	cmd.Flags().IntVar(&opts.Global.CopyAttempts, "copy-attempts", 3, "Maximum number of times the copy of an image is attempted")
+	cmd.Flags().StringVar(&opts.Global.CopyBackoff, "copy-backoff", batch.BackoffLinear, "How the wait between copy attempts grows: linear or exponential")
+	cmd.Flags().DurationVar(&opts.Global.CopyRetryDelay, "copy-retry-delay", time.Second, "Wait before the first retry of an image copy")
+	cmd.Flags().BoolVar(&opts.Global.CopyRetryJitter, "copy-retry-jitter", false, "Randomize the wait between copy attempts so that concurrent retries do not hit the registry at once")
+	cmd.Flags().StringSliceVar(&opts.Global.NonRetryableErrors, "non-retryable-errors", nil, "Case-insensitive error substrings that are not retried, in addition to authentication, not found and invalid reference errors")
+	cmd.Flags().StringSliceVar(&opts.Global.RetryableErrors, "retryable-errors", nil, "Case-insensitive error substrings that are always retried, taking precedence over the non-retryable errors")
 	HideFlags(cmd)
 
 	ex.Opts.Stdout = cmd.OutOrStdout()
@@ -370,6 +392,18 @@ func (o ExecutorSchema) Validate(dest []string) error {
 			return fmt.Errorf("--since flag needs to be in format yyyy-MM-dd")
 		}
 	}
+	if err := batch.ValidateGroups(o.Opts.Global.ImageOrder); err != nil {
+		return fmt.Errorf("--image-order: %w", err)
+	}
+	if err := batch.ValidateGroups(o.Opts.Global.Only); err != nil {
+		return fmt.Errorf("--only: %w", err)
+	}
+	if err := batch.ValidateRetry(o.Opts.Global); err != nil {
+		return err
+	}
+	if o.Opts.Global.RefreshCatalog && o.Opts.Global.OfflineCatalog {
+		return fmt.Errorf("--refresh-catalog and --offline-catalog cannot be used together")
+	}
 	if strings.Contains(dest[0], fileProtocol) && o.Opts.Global.WorkingDir != "" {
 		return fmt.Errorf("when destination is file://, mirrorToDisk workflow is assumed, and the --workspace argument is not needed")
 	}
@@ -388,7 +422,7 @@ func (o ExecutorSchema) Validate(dest []string) error {
 
 // Complete - do the final setup of modules
 func (o *ExecutorSchema) Complete(args []string) error {
-	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok {
+	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok && o.Opts.Global.RegistriesConfPath == "" {
 		o.Opts.Global.RegistriesConfPath = envOverride
 	}
 
@@ -530,8 +564,8 @@ func (o *ExecutorSchema) Run(cmd *cobra.Command, args []string) error {
 
 	o.stopLocalRegistry(cmd.Context())
 
-	o.Log.Info("mirror time     : %v", time.Since(startTime))
-	o.Log.Info(emoji.CheckMarkButton + " Mirror operation completed")
+	progress.Summary(o.Log, "mirror time     : %v", time.Since(startTime))
+	progress.Summary(o.Log, emoji.CheckMarkButton+" Mirror operation completed")
 
 	return err
 }
@@ -791,7 +825,7 @@ func (o *ExecutorSchema) RunMirrorToDisk(cmd *cobra.Command, args []string) erro
 
 	// call the batch worker
 	// NOTE: we will check for batch errors at the end
-	copiedSchema, batchError := o.Batch.Worker(cmd.Context(), collectorSchema, *o.Opts)
+	copiedSchema, _, batchError := o.copyImages(cmd.Context(), collectorSchema)
 
 	// OCPBUGS-45580: add the rebuilt catalog image to the collectorSchema so that
 	// it also gets added to the archive. When using the GCRCatalogBuilder implementation,
@@ -809,6 +843,7 @@ func (o *ExecutorSchema) RunMirrorToDisk(cmd *cobra.Command, args []string) erro
 	}
 
 	// prepare tar.gz when mirror to disk
+	progress.Start(o.Log, progress.PhaseArchive)
 	o.Log.Info(emoji.Package + " Preparing the tarball archive...")
 	// next, generate the archive
 	return o.MirrorArchiver.BuildArchive(cmd.Context(), copiedSchema.AllImages)
@@ -851,24 +886,31 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 
 	// call the batch worker
 	// NOTE: we will check for batch errors at the end
-	copiedSchema, batchError := o.Batch.Worker(cmd.Context(), collectorSchema, *o.Opts)
+	copiedSchema, deferred, batchError := o.copyImages(cmd.Context(), collectorSchema)
+	resourceImages := clusterResourceImages(copiedSchema, deferred)
+
+	// record the source -> mirror -> digest mapping of the copied images
+	if err := o.writeMirroredImages(cmd.Context(), copiedSchema.AllImages); err != nil {
+		// the mapping is informational, mirroring itself succeeded
+		o.Log.Warn("unable to record mirrored images: %v", err)
+	}
 
 	// create IDMS/ITMS
 	forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
-	if err := o.ClusterResources.IDMS_ITMSGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+	if err := o.ClusterResources.IDMS_ITMSGenerator(resourceImages, forceRepositoryScope); err != nil {
 		return err
 	}
 
-	if err := o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.CatalogSourceGenerator(resourceImages); err != nil {
 		return err
 	}
 
-	if err := o.ClusterResources.ClusterCatalogGenerator(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.ClusterCatalogGenerator(resourceImages); err != nil {
 		return err
 	}
 
 	// generate signature config map
-	if err := o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.GenerateSignatureConfigMap(resourceImages); err != nil {
 		// as this is not a seriously fatal error we just log the error
 		o.Log.Warn("%s", err)
 	}
@@ -894,6 +936,7 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 // RunDiskToMirror execute the disk to mirror functionality
 func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) error {
 	// extract the archive
+	progress.Start(o.Log, progress.PhaseExtract)
 	o.Log.Info(emoji.Package + " Extracting mirror archive(s)...")
 	if err := o.MirrorUnArchiver.Unarchive(); err != nil {
 		o.Log.Error(" %v ", err)
@@ -931,25 +974,32 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 
 	// call the batch worker
 	// NOTE: we will check for batch errors at the end
-	copiedSchema, batchError := o.Batch.Worker(cmd.Context(), collectorSchema, *o.Opts)
+	copiedSchema, deferred, batchError := o.copyImages(cmd.Context(), collectorSchema)
+	resourceImages := clusterResourceImages(copiedSchema, deferred)
+
+	// record the source -> mirror -> digest mapping of the copied images
+	if err := o.writeMirroredImages(cmd.Context(), copiedSchema.AllImages); err != nil {
+		// the mapping is informational, mirroring itself succeeded
+		o.Log.Warn("unable to record mirrored images: %v", err)
+	}
 
 	// create IDMS/ITMS
 	forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
-	if err := o.ClusterResources.IDMS_ITMSGenerator(copiedSchema.AllImages, forceRepositoryScope); err != nil {
+	if err := o.ClusterResources.IDMS_ITMSGenerator(resourceImages, forceRepositoryScope); err != nil {
 		return err
 	}
 
 	// create catalog source
-	if err := o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.CatalogSourceGenerator(resourceImages); err != nil {
 		return err
 	}
 
-	if err := o.ClusterResources.ClusterCatalogGenerator(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.ClusterCatalogGenerator(resourceImages); err != nil {
 		return err
 	}
 
 	// generate signature config map
-	if err := o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages); err != nil {
+	if err := o.ClusterResources.GenerateSignatureConfigMap(resourceImages); err != nil {
 		// as this is not a seriously fatal error we just log the error
 		o.Log.Warn("%s", err)
 	}
@@ -994,6 +1044,9 @@ func (o *ExecutorSchema) setupLogsLevelAndDir() error {
 		panic(err)
 	}
 	o.logFile = l
+	if f, ok := o.Log.(interface{ SetLogFile(io.Writer) }); ok {
+		f.SetLogFile(o.logFile)
+	}
 	mw := io.MultiWriter(os.Stdout, o.logFile)
 	log.SetOutput(mw)
 	return nil
@@ -1018,6 +1071,7 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 		allRelatedImages []v2alpha1.CopyImageSchema
 	)
 
+	progress.Start(o.Log, progress.PhaseCollect)
 	o.Log.Info(emoji.SleuthOrSpy + "  going to discover the necessary images...")
 	o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting release images...")
 	// collect releases
@@ -1124,7 +1178,7 @@ func (o *ExecutorSchema) RebuildCatalogs(ctx context.Context, operatorImgs v2alp
 				if !o.Opts.Global.IsTerminal {
 					o.Log.Info("Rebuilding catalog %s", copyImage.Origin)
 				}
-				p := mpb.New(mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal))
+				p := mpb.New(mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal), mpb.ContainerOptional(mpb.WithAutoRefresh(), o.Opts.Global.IsTerminal))
 				spinner := p.AddSpinner(
 					1, mpb.BarFillerMiddleware(spinners.ModernSpinnerLeft),
 					mpb.BarWidth(3),
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/mirror/api/v2alpha1"
+)
+
+// mirroredImage is an entry of mirrored-images.json: where a copied image came from,
+// where it lives in the destination registry and the digest of its manifest
+type mirroredImage struct {
+	Source string `json:"source"`
+	Mirror string `json:"mirror"`
+	Digest string `json:"digest,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+// writeMirroredImages records every image copied to the destination registry in
+// the working dir. Images referenced by tag have their digest looked up in the
+// destination registry; lookup failures leave the digest empty.
+func (o *ExecutorSchema) writeMirroredImages(ctx context.Context, copied []v2alpha1.CopyImageSchema) error {
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return err
+	}
+
+	images := make([]mirroredImage, 0, len(copied))
+	for _, img := range copied {
+		source := img.Origin
+		if source == "" {
+			source = img.Source
+		}
+		entry := mirroredImage{
+			Source: strings.TrimPrefix(source, dockerProtocol),
+			Mirror: strings.TrimPrefix(img.Destination, dockerProtocol),
+			Type:   img.Type.String(),
+		}
+		entry.Digest = referenceDigest(entry.Mirror)
+		if entry.Digest == "" {
+			entry.Digest = referenceDigest(entry.Source)
+		}
+		if entry.Digest == "" {
+			digest, err := o.Manifest.ImageDigest(ctx, destCtx, img.Destination)
+			if err != nil {
+				o.Log.Debug("unable to get digest of %s: %v", img.Destination, err)
+			} else {
+				entry.Digest = "sha256:" + digest
+			}
+		}
+		images = append(images, entry)
+	}
+
+	content, err := json.MarshalIndent(images, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(o.Opts.Global.WorkingDir, mirroredImagesFile), content, 0644)
+}
+
+// referenceDigest returns the digest of a by-digest reference, or an empty string
+func referenceDigest(ref string) string {
+	if _, digest, found := strings.Cut(ref, "@"); found {
+		return digest
+	}
+	return ""
+}
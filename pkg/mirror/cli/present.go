@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"ocpack/pkg/mirror/api/v2alpha1"
+	"ocpack/pkg/mirror/batch"
+	"ocpack/pkg/mirror/emoji"
+
+	"github.com/containers/image/v5/types"
+)
+
+// copyImages runs the batch worker. With --only, images outside the selected groups are
+// not copied and are returned as deferred, so that the cluster resources can still cover the
+// whole image set. With --skip-present, images already present in the destination are left
+// out of the batch and added back to the copied schema afterwards, so the archive and the
+// generated cluster resources still cover the whole image set.
+func (o *ExecutorSchema) copyImages(ctx context.Context, collectorSchema v2alpha1.CollectorSchema) (copied v2alpha1.CollectorSchema, deferred []v2alpha1.CopyImageSchema, err error) {
+	if len(o.Opts.Global.Only) > 0 {
+		collectorSchema, deferred = batch.FilterGroups(collectorSchema, o.Opts.Global.Only)
+		o.Log.Info("%s copying only %v images: %d images, %d images left for a later run",
+			emoji.LeftPointingMagnifyingGlass, o.Opts.Global.Only, len(collectorSchema.AllImages), len(deferred))
+		if len(collectorSchema.AllImages) == 0 {
+			return collectorSchema, deferred, nil
+		}
+	}
+
+	if !o.Opts.Global.SkipPresent {
+		copied, err = o.Batch.Worker(ctx, collectorSchema, *o.Opts)
+		return copied, deferred, err
+	}
+
+	toCopy, present := o.partitionPresentImages(ctx, collectorSchema)
+	o.Log.Info("%s %d of %d images already present in the destination, skipping them",
+		emoji.LeftPointingMagnifyingGlass, len(present.AllImages), len(collectorSchema.AllImages))
+
+	if len(toCopy.AllImages) > 0 {
+		copied, err = o.Batch.Worker(ctx, toCopy, *o.Opts)
+	}
+	copied.AllImages = append(copied.AllImages, present.AllImages...)
+	copied.TotalReleaseImages += present.TotalReleaseImages
+	copied.TotalOperatorImages += present.TotalOperatorImages
+	copied.TotalAdditionalImages += present.TotalAdditionalImages
+	copied.TotalHelmImages += present.TotalHelmImages
+	return copied, deferred, err
+}
+
+// clusterResourceImages returns the images the cluster resources (IDMS/ITMS, catalog sources)
+// are generated from: the copied images and those deferred by --only, which are expected in
+// the destination after a later run and must not disappear from the resources meanwhile.
+func clusterResourceImages(copied v2alpha1.CollectorSchema, deferred []v2alpha1.CopyImageSchema) []v2alpha1.CopyImageSchema {
+	return append(slices.Clone(copied.AllImages), deferred...)
+}
+
+// partitionPresentImages splits the collected images into those that still need to be
+// copied and those whose manifest is already in the destination. Destinations referenced
+// by digest only need to exist; for tags the destination digest must match the source.
+// Lookup failures are treated as "not present" so the batch worker copies the image.
+func (o *ExecutorSchema) partitionPresentImages(ctx context.Context, collectorSchema v2alpha1.CollectorSchema) (toCopy, present v2alpha1.CollectorSchema) {
+	toCopy = collectorSchema
+	toCopy.AllImages = nil
+	toCopy.TotalReleaseImages, toCopy.TotalOperatorImages, toCopy.TotalAdditionalImages, toCopy.TotalHelmImages = 0, 0, 0, 0
+
+	srcCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		o.Log.Warn("unable to check the destination for present images: %v", err)
+		return collectorSchema, present
+	}
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		o.Log.Warn("unable to check the destination for present images: %v", err)
+		return collectorSchema, present
+	}
+
+	found := make([]bool, len(collectorSchema.AllImages))
+	parallel := int(o.Opts.ParallelImages)
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, img := range collectorSchema.AllImages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, img v2alpha1.CopyImageSchema) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			found[i] = o.isPresent(ctx, srcCtx, destCtx, img)
+		}(i, img)
+	}
+	wg.Wait()
+
+	for i, img := range collectorSchema.AllImages {
+		schema := &toCopy
+		if found[i] {
+			schema = &present
+		}
+		schema.AllImages = append(schema.AllImages, img)
+		batch.IncrementTotals(img.Type, schema)
+	}
+	return toCopy, present
+}
+
+// isPresent reports whether the manifest of img is already in the destination
+func (o *ExecutorSchema) isPresent(ctx context.Context, srcCtx, destCtx *types.SystemContext, img v2alpha1.CopyImageSchema) bool {
+	destDigest, err := o.Manifest.ImageDigest(ctx, destCtx, img.Destination)
+	if err != nil {
+		o.Log.Debug("%s not present in the destination: %v", img.Destination, err)
+		return false
+	}
+	if strings.Contains(img.Destination, "@") {
+		return true
+	}
+	srcDigest, err := o.Manifest.ImageDigest(ctx, srcCtx, img.Source)
+	if err != nil {
+		o.Log.Debug("unable to get the digest of %s: %v", img.Source, err)
+		return false
+	}
+	return srcDigest == destDigest
+}
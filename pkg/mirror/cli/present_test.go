@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+
+	"ocpack/pkg/mirror/api/v2alpha1"
+	clog "ocpack/pkg/mirror/log"
+	"ocpack/pkg/mirror/manifest"
+	"ocpack/pkg/mirror/mirror"
+)
+
+// digestManifest returns the digests of the images it knows about and an error for the others
+type digestManifest struct {
+	manifest.ManifestInterface
+	digests map[string]string
+}
+
+func (o digestManifest) ImageDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	if digest, ok := o.digests[imgRef]; ok {
+		return digest, nil
+	}
+	return "", fmt.Errorf("manifest unknown")
+}
+
+func TestCopyImagesSkipPresent(t *testing.T) {
+	global := &mirror.GlobalOptions{SkipPresent: true}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+
+	ex := &ExecutorSchema{
+		Log: clog.New("error"),
+		Opts: &mirror.CopyOptions{
+			Global:         global,
+			SrcImage:       srcOpts,
+			DestImage:      destOpts,
+			ParallelImages: 2,
+		},
+		Batch: &Batch{},
+		Manifest: digestManifest{digests: map[string]string{
+			"docker://mirror.example.com/openshift/release@sha256:aaa": "aaa",
+			"docker://localhost:55000/ubi8/ubi:latest":                 "bbb",
+			"docker://mirror.example.com/ubi8/ubi:latest":              "bbb",
+			"docker://localhost:55000/acme/app:v1":                     "ccc",
+			"docker://mirror.example.com/acme/app:v1":                  "old",
+		}},
+	}
+
+	collectorSchema := v2alpha1.CollectorSchema{
+		TotalReleaseImages:    2,
+		TotalAdditionalImages: 2,
+		AllImages: []v2alpha1.CopyImageSchema{
+			// by digest, already in the destination
+			{Source: "docker://localhost:55000/openshift/release@sha256:aaa", Destination: "docker://mirror.example.com/openshift/release@sha256:aaa", Type: v2alpha1.TypeOCPReleaseContent},
+			// by digest, missing from the destination
+			{Source: "docker://localhost:55000/openshift/release@sha256:ddd", Destination: "docker://mirror.example.com/openshift/release@sha256:ddd", Type: v2alpha1.TypeOCPReleaseContent},
+			// by tag, same digest
+			{Source: "docker://localhost:55000/ubi8/ubi:latest", Destination: "docker://mirror.example.com/ubi8/ubi:latest", Type: v2alpha1.TypeGeneric},
+			// by tag, stale digest in the destination
+			{Source: "docker://localhost:55000/acme/app:v1", Destination: "docker://mirror.example.com/acme/app:v1", Type: v2alpha1.TypeGeneric},
+		},
+	}
+
+	toCopy, present := ex.partitionPresentImages(context.Background(), collectorSchema)
+	if len(present.AllImages) != 2 || present.TotalReleaseImages != 1 || present.TotalAdditionalImages != 1 {
+		t.Errorf("unexpected present images: %+v", present)
+	}
+	if len(toCopy.AllImages) != 2 || toCopy.AllImages[0].Destination != "docker://mirror.example.com/openshift/release@sha256:ddd" ||
+		toCopy.AllImages[1].Destination != "docker://mirror.example.com/acme/app:v1" {
+		t.Errorf("unexpected images to copy: %+v", toCopy.AllImages)
+	}
+
+	copied, deferred, err := ex.copyImages(context.Background(), collectorSchema)
+	if err != nil {
+		t.Fatalf("copyImages() error = %v", err)
+	}
+	if len(copied.AllImages) != 4 || copied.TotalReleaseImages != 2 || copied.TotalAdditionalImages != 2 || len(deferred) != 0 {
+		t.Errorf("copied schema should include present images: %+v", copied)
+	}
+
+	// --only release leaves the additional images for a later run, but keeps them for the cluster resources
+	global.Only = []string{"release"}
+	copied, deferred, err = ex.copyImages(context.Background(), collectorSchema)
+	if err != nil {
+		t.Fatalf("copyImages() error = %v", err)
+	}
+	if len(copied.AllImages) != 2 || copied.TotalReleaseImages != 2 || copied.TotalAdditionalImages != 0 {
+		t.Errorf("copied schema should only include release images: %+v", copied)
+	}
+	if len(deferred) != 2 || len(clusterResourceImages(copied, deferred)) != 4 {
+		t.Errorf("unexpected deferred images: %+v", deferred)
+	}
+}
@@ -61,6 +61,20 @@ type GlobalOptions struct {
 	CacheDir               string        // Path to the cache directory
 	IsTerminal             bool          // Whether we're running in a terminal console or not
 	IgnoreReleaseSignature bool          // Ignore release signatures, used primarily for qe testing unpublished signatures
+	GraphCacheTTL          time.Duration // Reuse graph data saved in the working-dir if it is younger than this, 0 always queries upstream
+	OfflineGraph           bool          // Only use graph data saved in the working-dir by previous runs, never query upstream
+	CatalogCacheTTL        time.Duration // Reuse the catalog digest resolved by a previous run if it is younger than this, 0 always resolves it
+	RefreshCatalog         bool          // Always resolve catalog digests from the source registry, ignoring CatalogCacheTTL
+	OfflineCatalog         bool          // Only use catalogs collected in the working-dir by previous runs, never query the source registry
+	SkipPresent            bool          // Skip images whose manifest is already in the destination instead of copying them again
+	ImageOrder             []string      // Image groups (release, operators, additional, helm) copied one after another in this order
+	Only                   []string      // Only copy images of these groups, the others are left for a later run
+	CopyAttempts           int           // Maximum number of times an image copy is attempted
+	CopyBackoff            string        // How the wait between copy attempts grows: linear or exponential
+	CopyRetryDelay         time.Duration // Wait before the first retry of an image copy
+	CopyRetryJitter        bool          // Randomize the wait between copy attempts
+	NonRetryableErrors     []string      // Error substrings that are never retried, in addition to the built-in ones
+	RetryableErrors        []string      // Error substrings that are always retried, taking precedence over the non-retryable ones
 }
 
 type CopyOptions struct {
@@ -356,8 +370,12 @@ func (opts *imageOptions) NewSystemContext() (*types.SystemContext, error) {
 		ctx.DockerInsecureSkipTLSVerify = types.NewOptionalBool(!opts.deprecatedTLSVerify.tlsVerify.Value())
 	}
 
+	// Only force skipping verification when it is disabled, so that registries marked
+	// insecure in registries.conf still apply while verification is enabled
 	ctx.DockerDaemonInsecureSkipTLSVerify = !opts.TlsVerify
-	ctx.DockerInsecureSkipTLSVerify = types.NewOptionalBool(!opts.TlsVerify)
+	if !opts.TlsVerify {
+		ctx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
 
 	if err := opts.validateCredentials(); err != nil {
 		return nil, err
@@ -0,0 +1,116 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/mirror/image"
+)
+
+// catalogDigestsFile records, in the operator-catalogs directory of the working-dir, the digest each
+// catalog reference resolved to and when. Later mirror-to-disk runs reuse it within Global.CatalogCacheTTL,
+// with Global.OfflineCatalog, or when the source registry cannot be reached.
+const catalogDigestsFile = "catalog-digests.json"
+
+// resolvedCatalog is the digest a catalog reference resolved to
+type resolvedCatalog struct {
+	Digest     string    `json:"digest"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// resolveCatalogDigest returns the digest of a catalog pulled from a registry. The digest resolved by a
+// previous run is reused when it is younger than Global.CatalogCacheTTL or when --offline-catalog is set,
+// and as a fallback when the source registry is unreachable, as long as the catalog it points to is still
+// in the working-dir. --refresh-catalog always queries the source registry.
+func (o FilterCollector) resolveCatalogDigest(ctx context.Context, imgSpec image.ImageSpec) (string, error) {
+	global := o.Opts.Global
+	cacheFile := filepath.Join(global.WorkingDir, operatorCatalogsDir, catalogDigestsFile)
+	cached, ok := o.cachedCatalogDigest(cacheFile, imgSpec)
+
+	if global.OfflineCatalog {
+		if !ok {
+			return "", fmt.Errorf("--offline-catalog is set but catalog %s was not collected in %s by a previous run", imgSpec.Reference, global.WorkingDir)
+		}
+		o.Log.Debug("Using catalog %s@%s collected %s (--offline-catalog)", imgSpec.Reference, cached.Digest, cached.ResolvedAt.Format(time.RFC3339))
+		return cached.Digest, nil
+	}
+	if ok && !global.RefreshCatalog && global.CatalogCacheTTL > 0 && time.Since(cached.ResolvedAt) < global.CatalogCacheTTL {
+		o.Log.Debug("Using catalog %s@%s (age %s, ttl %s)", imgSpec.Reference, cached.Digest, time.Since(cached.ResolvedAt).Round(time.Second), global.CatalogCacheTTL)
+		return cached.Digest, nil
+	}
+
+	srcCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return "", err
+	}
+	digest, err := o.Manifest.ImageDigest(ctx, srcCtx, imgSpec.ReferenceWithTransport)
+	if err != nil {
+		// tolerate an unreachable source registry: fall back to the catalog collected by a previous run, however old
+		if ok && !global.RefreshCatalog {
+			o.Log.Warn("Resolving catalog %s failed (%v), using catalog %s collected %s", imgSpec.Reference, err, cached.Digest, cached.ResolvedAt.Format(time.RFC3339))
+			return cached.Digest, nil
+		}
+		return "", err
+	}
+
+	if err := saveCatalogDigest(cacheFile, imgSpec.ReferenceWithTransport, resolvedCatalog{Digest: digest, ResolvedAt: time.Now().UTC()}); err != nil {
+		o.Log.Warn("Unable to record the digest of catalog %s: %v", imgSpec.Reference, err)
+	}
+	return digest, nil
+}
+
+// cachedCatalogDigest returns the digest recorded for the catalog, if the catalog image it points to is in the working-dir
+func (o FilterCollector) cachedCatalogDigest(cacheFile string, imgSpec image.ImageSpec) (resolvedCatalog, bool) {
+	digests, err := readCatalogDigests(cacheFile)
+	if err != nil {
+		o.Log.Debug("Ignoring recorded catalog digests: %v", err)
+		return resolvedCatalog{}, false
+	}
+	cached, ok := digests[imgSpec.ReferenceWithTransport]
+	if !ok || cached.Digest == "" {
+		return resolvedCatalog{}, false
+	}
+	index := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), cached.Digest, operatorCatalogImageDir, "index.json")
+	if _, err := os.Stat(index); err != nil {
+		return resolvedCatalog{}, false
+	}
+	return cached, true
+}
+
+// readCatalogDigests reads the recorded catalog digests, keyed by catalog reference
+func readCatalogDigests(cacheFile string) (map[string]resolvedCatalog, error) {
+	digests := make(map[string]resolvedCatalog)
+	data, err := os.ReadFile(cacheFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return digests, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &digests); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", cacheFile, err)
+	}
+	return digests, nil
+}
+
+// saveCatalogDigest records the digest a catalog reference resolved to
+func saveCatalogDigest(cacheFile, ref string, resolved resolvedCatalog) error {
+	digests, err := readCatalogDigests(cacheFile)
+	if err != nil {
+		digests = make(map[string]resolvedCatalog)
+	}
+	digests[ref] = resolved
+	data, err := json.MarshalIndent(digests, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, data, 0644)
+}
@@ -0,0 +1,108 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+
+	"ocpack/pkg/mirror/image"
+	clog "ocpack/pkg/mirror/log"
+	"ocpack/pkg/mirror/manifest"
+	"ocpack/pkg/mirror/mirror"
+)
+
+// registryManifest resolves catalogs to digest, or fails as an unreachable registry would when digest is empty
+type registryManifest struct {
+	manifest.ManifestInterface
+	digest string
+	calls  int
+}
+
+func (o *registryManifest) ImageDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	o.calls++
+	if o.digest == "" {
+		return "", fmt.Errorf("dial tcp: lookup registry.redhat.io: no such host")
+	}
+	return o.digest, nil
+}
+
+func TestResolveCatalogDigest(t *testing.T) {
+	global := &mirror.GlobalOptions{WorkingDir: t.TempDir(), CatalogCacheTTL: time.Hour}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	registry := &registryManifest{}
+	collector := FilterCollector{OperatorCollector{
+		Log:      clog.New("error"),
+		Opts:     mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.MirrorToDisk},
+		Manifest: registry,
+	}}
+	imgSpec, err := image.ParseRef("registry.redhat.io/redhat/redhat-operator-index:v4.16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	collect := func(digest string) {
+		dir := filepath.Join(global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), digest, operatorCatalogImageDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// nothing collected yet
+	global.OfflineCatalog = true
+	if _, err := collector.resolveCatalogDigest(ctx, imgSpec); err == nil {
+		t.Error("--offline-catalog should fail without a collected catalog")
+	}
+	global.OfflineCatalog = false
+	if _, err := collector.resolveCatalogDigest(ctx, imgSpec); err == nil {
+		t.Error("an unreachable registry should fail without a collected catalog")
+	}
+
+	registry.digest = "aaa"
+	if digest, err := collector.resolveCatalogDigest(ctx, imgSpec); err != nil || digest != "aaa" {
+		t.Fatalf("resolveCatalogDigest() = %q, %v", digest, err)
+	}
+	// the recorded digest is only reused once its catalog is in the working-dir
+	registry.digest, registry.calls = "bbb", 0
+	if digest, _ := collector.resolveCatalogDigest(ctx, imgSpec); digest != "bbb" || registry.calls != 1 {
+		t.Errorf("resolveCatalogDigest() = %q after %d queries, expected to query the registry", digest, registry.calls)
+	}
+
+	collect("bbb")
+	registry.digest, registry.calls = "ccc", 0
+	if digest, _ := collector.resolveCatalogDigest(ctx, imgSpec); digest != "bbb" || registry.calls != 0 {
+		t.Errorf("resolveCatalogDigest() = %q after %d queries, expected the cached digest within the ttl", digest, registry.calls)
+	}
+
+	global.RefreshCatalog = true
+	if digest, _ := collector.resolveCatalogDigest(ctx, imgSpec); digest != "ccc" || registry.calls != 1 {
+		t.Errorf("resolveCatalogDigest() = %q after %d queries, expected --refresh-catalog to query the registry", digest, registry.calls)
+	}
+	global.RefreshCatalog = false
+
+	// ccc is recorded but not collected: an unreachable registry falls back to nothing
+	registry.digest = ""
+	if _, err := collector.resolveCatalogDigest(ctx, imgSpec); err == nil {
+		t.Error("expected an error when the recorded catalog is not in the working-dir")
+	}
+
+	collect("ccc")
+	global.CatalogCacheTTL = 0
+	if digest, err := collector.resolveCatalogDigest(ctx, imgSpec); err != nil || digest != "ccc" {
+		t.Errorf("resolveCatalogDigest() = %q, %v, expected to fall back to the collected catalog", digest, err)
+	}
+	global.OfflineCatalog = true
+	registry.calls = 0
+	if digest, err := collector.resolveCatalogDigest(ctx, imgSpec); err != nil || digest != "ccc" || registry.calls != 0 {
+		t.Errorf("resolveCatalogDigest() = %q, %v after %d queries with --offline-catalog", digest, err, registry.calls)
+	}
+}
@@ -48,7 +48,7 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 	// found during the preparation of the images.
 	allErrs := []error{}
 
-	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal))
+	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal), mpb.ContainerOptional(mpb.WithAutoRefresh(), o.Opts.Global.IsTerminal))
 	for _, op := range o.Config.Mirror.Operators {
 		// download the operator index image
 		o.Log.Debug(collectorPrefix+"copying operator image %s", op.Catalog)
@@ -284,6 +284,11 @@ func (o FilterCollector) getCatalogDigest(ctx context.Context, op v2alpha1.Opera
 		return "", err
 	}
 
+	// catalogs on disk are always read directly
+	if imgSpec.Transport != ociProtocol {
+		return o.resolveCatalogDigest(ctx, imgSpec)
+	}
+
 	srcCtx, err := o.Opts.SrcImage.NewSystemContext()
 	if err != nil {
 		return "", err
@@ -386,6 +391,12 @@ func (o FilterCollector) ensureCatalogInOCIFormat(ctx context.Context, imgSpec i
 	catalogImageDir := filepath.Join(imageIndexDir, operatorCatalogImageDir)
 
 	if imgSpec.Transport != ociProtocol {
+		// the directory is named after the catalog digest: a complete copy never needs to be pulled again,
+		// which also lets --offline-catalog and the catalog cache work without the source registry
+		if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); err == nil {
+			o.Log.Debug("Catalog %q already in the working-dir", catalog)
+			return nil
+		}
 		opts := o.Opts
 		opts.Stdout = io.Discard
 		opts.RemoveSignatures = true
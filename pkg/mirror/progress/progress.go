@@ -0,0 +1,211 @@
+// Package progress provides a line-oriented logger for the embedded oc-mirror
+// runs. Every message is written as soon as it is logged, the executor marks
+// the start of each phase (collect, copy release, copy operators, archive, ...)
+// with a header, and the amount of output is selected with a verbosity level.
+//
+// The logger implements the method set of log.PluggableLoggerInterface, so it
+// can be handed to the oc-mirror executor directly.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Verbosity selects which messages are printed
+type Verbosity int
+
+const (
+	// Quiet prints only errors and the final summary
+	Quiet Verbosity = iota - 1
+	// Normal prints phase headers, info and warnings
+	Normal
+	// Verbose additionally prints debug messages (-v)
+	Verbose
+	// Trace additionally prints trace messages (-vv)
+	Trace
+)
+
+// Phases reported by the oc-mirror executor
+const (
+	PhaseCollect        = "collect"
+	PhaseCopyRelease    = "copy release"
+	PhaseCopyOperators  = "copy operators"
+	PhaseCopyAdditional = "copy additional"
+	PhaseCopyHelm       = "copy helm"
+	PhaseDelete         = "delete"
+	PhaseArchive        = "archive"
+	PhaseExtract        = "extract"
+)
+
+// phaser is implemented by loggers that render phase headers
+type phaser interface {
+	Phase(name string)
+}
+
+// summarizer is implemented by loggers that treat summary lines specially
+type summarizer interface {
+	Summary(msg string, val ...interface{})
+}
+
+// infoLogger is the part of the logger interface used by the helpers below
+type infoLogger interface {
+	Info(msg string, val ...interface{})
+}
+
+// Start reports the beginning of a phase to log. Loggers without phase
+// support are left untouched, so callers can use it unconditionally.
+func Start(log interface{}, name string) {
+	if p, ok := log.(phaser); ok {
+		p.Phase(name)
+	}
+}
+
+// Summary logs a line of the final summary, which is printed even in quiet mode
+func Summary(log infoLogger, msg string, val ...interface{}) {
+	if s, ok := log.(summarizer); ok {
+		s.Summary(msg, val...)
+		return
+	}
+	log.Info(msg, val...)
+}
+
+// Logger writes timestamped lines to an output stream and, when set, a log file
+type Logger struct {
+	mu        sync.Mutex
+	out       io.Writer
+	file      io.Writer
+	verbosity Verbosity
+	quiet     bool
+	start     time.Time
+	phase     string
+	now       func() time.Time
+}
+
+// New returns a logger writing to out with the given verbosity
+func New(out io.Writer, verbosity Verbosity) *Logger {
+	l := &Logger{out: out, verbosity: verbosity, quiet: verbosity <= Quiet, now: time.Now}
+	l.start = l.now()
+	return l
+}
+
+// Streaming reports that the logger prints a live line stream; the executor
+// disables its progress spinners so that they do not interleave with it
+func (l *Logger) Streaming() bool {
+	return true
+}
+
+// SetLogFile additionally writes every message up to debug level to w,
+// regardless of the console verbosity
+func (l *Logger) SetLogFile(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file = w
+}
+
+// Phase prints a header for a new phase. Repeated calls for the current phase are ignored.
+func (l *Logger) Phase(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if name == l.phase {
+		return
+	}
+	l.phase = name
+	header := fmt.Sprintf("==> %s (+%s)", name, l.now().Sub(l.start).Truncate(time.Second))
+	if !l.quiet {
+		fmt.Fprintf(l.out, "\n%s\n", header)
+	}
+	if l.file != nil {
+		fmt.Fprintln(l.file, header)
+	}
+}
+
+// Summary prints a line of the final summary
+func (l *Logger) Summary(msg string, val ...interface{}) {
+	l.write(Quiet, "", msg, val...)
+}
+
+// Error prints an error, always shown
+func (l *Logger) Error(msg string, val ...interface{}) {
+	l.write(Quiet, "ERROR", msg, val...)
+}
+
+// Warn prints a warning
+func (l *Logger) Warn(msg string, val ...interface{}) {
+	l.write(Normal, "WARN", msg, val...)
+}
+
+// Info prints an informational message
+func (l *Logger) Info(msg string, val ...interface{}) {
+	l.write(Normal, "", msg, val...)
+}
+
+// Debug prints a debug message (-v)
+func (l *Logger) Debug(msg string, val ...interface{}) {
+	l.write(Verbose, "DEBUG", msg, val...)
+}
+
+// Trace prints a trace message (-vv)
+func (l *Logger) Trace(msg string, val ...interface{}) {
+	l.write(Trace, "TRACE", msg, val...)
+}
+
+// Level is called by the executor with the oc-mirror --log-level value.
+// Quiet mode is kept, otherwise the verbosity follows the level.
+func (l *Logger) Level(level string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.quiet {
+		return
+	}
+	switch level {
+	case "error":
+		l.verbosity = Quiet
+	case "debug":
+		l.verbosity = Verbose
+	case "trace":
+		l.verbosity = Trace
+	default:
+		l.verbosity = Normal
+	}
+}
+
+// GetLevel returns the oc-mirror log level matching the verbosity
+func (l *Logger) GetLevel() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LevelOf(l.verbosity)
+}
+
+// LevelOf returns the oc-mirror --log-level value for a verbosity
+func LevelOf(v Verbosity) string {
+	switch {
+	case v <= Quiet:
+		return "error"
+	case v == Verbose:
+		return "debug"
+	case v >= Trace:
+		return "trace"
+	default:
+		return "info"
+	}
+}
+
+func (l *Logger) write(minVerbosity Verbosity, tag, msg string, val ...interface{}) {
+	line := strings.TrimRight(fmt.Sprintf(msg, val...), "\n")
+	if tag != "" {
+		line = tag + " " + line
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stamped := fmt.Sprintf("%s %s", l.now().Format("15:04:05"), line)
+	if l.verbosity >= minVerbosity {
+		fmt.Fprintln(l.out, stamped)
+	}
+	if l.file != nil && minVerbosity <= Verbose {
+		fmt.Fprintln(l.file, stamped)
+	}
+}
@@ -0,0 +1,98 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger(v Verbosity) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := New(&buf, v)
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	l.start = start
+	l.now = func() time.Time { return start.Add(90 * time.Second) }
+	return l, &buf
+}
+
+func logAll(l *Logger) {
+	Start(l, PhaseCollect)
+	Start(l, PhaseCollect)
+	l.Info("collecting release images...")
+	l.Debug("total release images %d", 190)
+	l.Trace("manifest %s", "sha256:abc")
+	Start(l, PhaseCopyRelease)
+	l.Warn("retrying %s", "quay.io/a")
+	l.Error("Failed to copy %s", "quay.io/b")
+	Summary(l, "mirrored %d/%d images", 189, 190)
+}
+
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		want      []string
+		unwanted  []string
+	}{
+		{Quiet,
+			[]string{"ERROR Failed to copy quay.io/b", "mirrored 189/190 images"},
+			[]string{"==>", "collecting", "WARN", "DEBUG", "TRACE"}},
+		{Normal,
+			[]string{"==> collect (+1m30s)", "==> copy release", "10:01:30 collecting release images...", "WARN retrying", "ERROR", "mirrored"},
+			[]string{"DEBUG", "TRACE"}},
+		{Verbose,
+			[]string{"DEBUG total release images 190"},
+			[]string{"TRACE"}},
+		{Trace,
+			[]string{"DEBUG", "TRACE manifest sha256:abc"},
+			nil},
+	}
+	for _, tt := range tests {
+		l, buf := newTestLogger(tt.verbosity)
+		logAll(l)
+		out := buf.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("verbosity %d: output missing %q:\n%s", tt.verbosity, want, out)
+			}
+		}
+		for _, unwanted := range tt.unwanted {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("verbosity %d: output contains %q:\n%s", tt.verbosity, unwanted, out)
+			}
+		}
+		if strings.Count(out, "==> collect") > 1 {
+			t.Errorf("repeated phase header:\n%s", out)
+		}
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	l, _ := newTestLogger(Quiet)
+	var file bytes.Buffer
+	l.SetLogFile(&file)
+	logAll(l)
+	out := file.String()
+	for _, want := range []string{"==> collect", "collecting release images", "DEBUG total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log file missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "TRACE") {
+		t.Errorf("log file should not contain trace messages:\n%s", out)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	l, _ := newTestLogger(Normal)
+	l.Level("debug")
+	if l.GetLevel() != "debug" {
+		t.Errorf("GetLevel = %s, want debug", l.GetLevel())
+	}
+
+	quiet, _ := newTestLogger(Quiet)
+	quiet.Level("info")
+	if quiet.GetLevel() != "error" {
+		t.Errorf("quiet logger level = %s, want error", quiet.GetLevel())
+	}
+}
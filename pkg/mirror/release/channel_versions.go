@@ -0,0 +1,90 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/uuid"
+
+	clog "ocpack/pkg/mirror/log"
+	"ocpack/pkg/mirror/mirror"
+)
+
+// ChannelVersions returns all OCP release versions in the channel, queried from the upstream Cincinnati service.
+// The graph is saved to the graph data directory of global.WorkingDir, where the release collector of the following
+// mirror-to-disk run finds it, and is reused according to global.GraphCacheTTL and global.OfflineGraph.
+// Without a working dir the graph is saved to a temporary directory and always queried.
+func ChannelVersions(ctx context.Context, log clog.PluggableLoggerInterface, global *mirror.GlobalOptions, arch, channel string) ([]string, error) {
+	client, err := NewOCPClient(uuid.New(), log)
+	if err != nil {
+		return nil, err
+	}
+
+	var graphDataDir string
+	if global.WorkingDir != "" {
+		graphDataDir = filepath.Join(global.WorkingDir, releaseImageExtractDir, cincinnatiGraphDataDir)
+		if err := os.MkdirAll(graphDataDir, 0755); err != nil {
+			return nil, err
+		}
+	} else {
+		// getGraphData saves the downloaded graph, keep it out of the working directory
+		graphDataDir, err = os.MkdirTemp("", "ocpack-graph-data")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(graphDataDir)
+	}
+
+	cs := CincinnatiSchema{
+		Log:              log,
+		Client:           client,
+		Opts:             mirror.CopyOptions{Global: global, Mode: mirror.MirrorToDisk},
+		CincinnatiParams: CincinnatiParams{Arch: arch, GraphDataDir: graphDataDir},
+	}
+	return versionStrings(GetVersions(ctx, cs, channel))
+}
+
+// LocalChannelVersions returns the release versions in the channel from the graph data saved
+// in an oc-mirror working directory by mirror-to-disk, so disk-to-mirror can resolve the same channel offline.
+func LocalChannelVersions(ctx context.Context, log clog.PluggableLoggerInterface, workingDir, arch, channel string) ([]string, error) {
+	graphDataDir := filepath.Join(workingDir, releaseImageExtractDir, cincinnatiGraphDataDir)
+	if _, err := os.Stat(graphDataDir); err != nil {
+		return nil, err
+	}
+	// a channel that was not mirrored has no graph data file
+	if _, err := os.Stat(filepath.Join(graphDataDir, arch+"-"+channel+".json")); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	client, err := NewOCPClient(uuid.New(), log)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := CincinnatiSchema{
+		Log:              log,
+		Client:           client,
+		Opts:             mirror.CopyOptions{Mode: mirror.DiskToMirror},
+		CincinnatiParams: CincinnatiParams{Arch: arch, GraphDataDir: graphDataDir},
+	}
+	return versionStrings(GetVersions(ctx, cs, channel))
+}
+
+// versionStrings converts the result of GetVersions, an empty channel (e.g. stable before GA) is not an error here
+func versionStrings(versions []semver.Version, err error) ([]string, error) {
+	var cincinnatiErr *Error
+	if errors.As(err, &cincinnatiErr) && cincinnatiErr.Reason == "NoVersionsFound" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := make([]string, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, v.String())
+	}
+	return list, nil
+}
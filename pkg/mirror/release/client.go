@@ -3,6 +3,7 @@ package release
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,6 +18,10 @@ var (
 	_ Client = &okdClient{}
 )
 
+// AdditionalTrustBundle holds PEM certificates trusted in addition to the system pool when
+// querying the update graph and downloading release signatures, e.g. a corporate proxy CA.
+var AdditionalTrustBundle []byte
+
 // Client is a Cincinnati client which can be used to fetch update graphs from
 // an upstream Cincinnati stack.
 type Client interface {
@@ -133,6 +138,9 @@ func getTLSConfig() (*tls.Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(AdditionalTrustBundle) > 0 && !certPool.AppendCertsFromPEM(AdditionalTrustBundle) {
+		return nil, fmt.Errorf("no valid certificates in the additional trust bundle")
+	}
 	config := &tls.Config{
 		RootCAs:    certPool,
 		MinVersion: tls.VersionTLS12,
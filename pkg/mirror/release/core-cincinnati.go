@@ -483,6 +483,65 @@ func getGraphData(ctx context.Context, cs CincinnatiSchema) (graph graph, err er
 		return graph, nil
 	}
 
+	var cacheTTL time.Duration
+	var offline bool
+	if cs.Opts.Global != nil {
+		cacheTTL, offline = cs.Opts.Global.GraphCacheTTL, cs.Opts.Global.OfflineGraph
+	}
+	cacheFile := graphDataFile(cs)
+
+	if offline {
+		graph, err := readGraphDataFile(cacheFile)
+		if err != nil {
+			return graph, &Error{Reason: "NoCachedGraph", Message: fmt.Sprintf("--offline-graph is set but no usable cached graph data was found: %v", err), cause: err}
+		}
+		cs.Log.Debug("Using cached graph data %s (--offline-graph)", cacheFile)
+		return graph, nil
+	}
+
+	if info, statErr := os.Stat(cacheFile); statErr == nil && cacheTTL > 0 && time.Since(info.ModTime()) < cacheTTL {
+		if graph, err := readGraphDataFile(cacheFile); err == nil {
+			cs.Log.Debug("Using cached graph data %s (age %s, ttl %s)", cacheFile, time.Since(info.ModTime()).Round(time.Second), cacheTTL)
+			return graph, nil
+		}
+	}
+
+	graph, err = downloadGraphData(ctx, cs)
+	if err == nil {
+		return graph, nil
+	}
+
+	// tolerate a flaky upstream: fall back to graph data saved by a previous run, however old
+	if info, statErr := os.Stat(cacheFile); statErr == nil {
+		if cached, readErr := readGraphDataFile(cacheFile); readErr == nil {
+			cs.Log.Warn("Querying the upgrade graph failed (%v), using cached graph data %s from %s", err, cacheFile, info.ModTime().Format(time.RFC3339))
+			return cached, nil
+		}
+	}
+	return graph, err
+}
+
+// graphDataFile returns the file the graph of the currently queried arch and channel is saved to
+func graphDataFile(cs CincinnatiSchema) string {
+	queryValues := cs.Client.GetURL().Query()
+	filename := fmt.Sprintf("%s-%s.json", queryValues.Get("arch"), queryValues.Get("channel"))
+	return path.Join(cs.CincinnatiParams.GraphDataDir, filename)
+}
+
+// readGraphDataFile parses graph data saved by writeGraphDataToFile
+func readGraphDataFile(filepath string) (graph graph, err error) {
+	fileData, err := os.ReadFile(filepath)
+	if err != nil {
+		return graph, err
+	}
+	if err = json.Unmarshal(fileData, &graph); err != nil {
+		return graph, fmt.Errorf("could not parse graph data %s: %v", filepath, err)
+	}
+	return graph, nil
+}
+
+// downloadGraphData queries the upstream Cincinnati service and saves the graph to GraphDataDir
+func downloadGraphData(ctx context.Context, cs CincinnatiSchema) (graph graph, err error) {
 	transport := cs.Client.GetTransport()
 	uri := cs.Client.GetURL()
 	// Download the update graph.
@@ -8,11 +8,14 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/google/uuid"
 	clog "ocpack/pkg/mirror/log"
+	"ocpack/pkg/mirror/mirror"
 	"github.com/stretchr/testify/require"
 )
 
@@ -850,3 +853,59 @@ func getHandlerMulti(t *testing.T, requestQuery chan<- string) http.HandlerFunc
 		}
 	}
 }
+
+func TestGetGraphDataCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		getHandlerMulti(t, make(chan string, 1))(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	endpoint, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	graphDataDir := t.TempDir()
+	versions := func(global *mirror.GlobalOptions) ([]semver.Version, error) {
+		u := *endpoint
+		cs := CincinnatiSchema{
+			Log:              clog.New("trace"),
+			Client:           &mockClient{url: &u},
+			Opts:             mirror.CopyOptions{Global: global, Mode: mirror.MirrorToDisk},
+			CincinnatiParams: CincinnatiParams{Arch: "test-arch", GraphDataDir: graphDataDir},
+		}
+		return GetVersions(context.Background(), cs, "stable-4.0")
+	}
+	expected := getSemVers([]string{"4.0.0-0.okd-0", "4.0.0-4", "4.0.0-5", "4.0.0-6", "4.0.0-7", "4.0.0-8"})
+
+	// offline without a previous query
+	_, err = versions(&mirror.GlobalOptions{OfflineGraph: true})
+	require.ErrorContains(t, err, "NoCachedGraph")
+	require.Equal(t, 0, requests)
+
+	// the first query saves the graph, within the ttl it is reused
+	for i := 0; i < 2; i++ {
+		got, err := versions(&mirror.GlobalOptions{GraphCacheTTL: time.Hour})
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	}
+	require.Equal(t, 1, requests)
+
+	// expired
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(graphDataDir, "test-arch-stable-4.0.json"), old, old))
+	_, err = versions(&mirror.GlobalOptions{GraphCacheTTL: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+
+	// offline reuses the saved graph regardless of its age
+	got, err := versions(&mirror.GlobalOptions{OfflineGraph: true})
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+	require.Equal(t, 2, requests)
+
+	// a failed query falls back to the saved graph
+	ts.Close()
+	got, err = versions(&mirror.GlobalOptions{})
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+}
@@ -98,7 +98,7 @@ func (o *LocalStorageCollector) collectImageFromMirror(ctx context.Context) ([]v
 	var allImages []v2alpha1.CopyImageSchema
 
 	// prepare progress bar
-	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal))
+	p := mpb.New(mpb.PopCompletedMode(), mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal), mpb.ContainerOptional(mpb.WithAutoRefresh(), o.Opts.Global.IsTerminal))
 
 	for _, value := range releases {
 		spinner := spinners.AddSpinner(p, "Collecting release "+value.Source)
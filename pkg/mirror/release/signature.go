@@ -3,7 +3,6 @@ package release
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -51,8 +50,12 @@ func NewSignatureClient(log clog.PluggableLoggerInterface, config v2alpha1.Image
 func (o SignatureSchema) GenerateReleaseSignatures(ctx context.Context, images []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
 	var imgs []v2alpha1.CopyImageSchema
 	// set up http object
+	tlsConfig, err := getTLSConfig()
+	if err != nil {
+		return []v2alpha1.CopyImageSchema{}, fmt.Errorf("[GenerateReleaseSignatures] loading trusted CAs: %w", err)
+	}
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: false},
+		TLSClientConfig: tlsConfig,
 		Proxy:           http.ProxyFromEnvironment,
 	}
 	httpClient := &http.Client{Transport: tr}
@@ -8,6 +8,7 @@ import (
 	"github.com/vbauerster/mpb/v8/decor"
 
 	"ocpack/pkg/mirror/emoji"
+	"ocpack/pkg/plain"
 )
 
 // ANSI 颜色代码
@@ -47,8 +48,12 @@ const (
 // 当前使用的完成图标 - 您可以修改这里来选择不同的图标
 var CompletedIcon = CompletedIcon1 // 默认使用简洁勾号
 
-// 更和谐的动态spinner样式 - 使用圆形旋转动画
+// 更和谐的动态spinner样式 - 使用圆形旋转动画，--plain 时使用经典旋转
 func ModernSpinnerLeft(original mpb.BarFiller) mpb.BarFiller {
+	if plain.Enabled() {
+		return mpb.SpinnerStyle("|", "/", "-", "\\").PositionLeft().Build()
+	}
+
 	// 选项1: 圆形旋转动画 (用户要求)
 	return mpb.SpinnerStyle("◐", "◓", "◑", "◒").PositionLeft().Build()
 
@@ -62,6 +67,30 @@ func ModernSpinnerLeft(original mpb.BarFiller) mpb.BarFiller {
 	// return mpb.SpinnerStyle("←", "↖", "↑", "↗", "→", "↘", "↓", "↙").PositionLeft().Build()
 }
 
+// completedIcon 返回完成状态图标，--plain 时为 ASCII
+func completedIcon() string {
+	if plain.Enabled() {
+		return "OK"
+	}
+	return CompletedIcon
+}
+
+// abortedIcon 返回失败状态图标，--plain 时为 ASCII
+func abortedIcon() string {
+	if plain.Enabled() {
+		return "FAIL"
+	}
+	return "❌"
+}
+
+// arrow 返回镜像和目标之间的箭头，--plain 时为 ASCII
+func arrow() string {
+	if plain.Enabled() {
+		return "->"
+	}
+	return "→"
+}
+
 func EmptyDecorator() decor.Decorator {
 	return decor.Any(func(s decor.Statistics) string {
 		return ""
@@ -86,8 +115,8 @@ func AddColorfulSpinner(progressBar *mpb.Progress, message string) *mpb.Bar {
 		1, mpb.BarFillerMiddleware(ModernSpinnerLeft),
 		mpb.BarWidth(2),
 		mpb.PrependDecorators(
-			decor.OnComplete(EmptyDecorator(), ColorGreen+CompletedIcon+ColorReset),
-			decor.OnAbort(EmptyDecorator(), ColorRed+"❌"+ColorReset),
+			decor.OnComplete(EmptyDecorator(), ColorGreen+completedIcon()+ColorReset),
+			decor.OnAbort(EmptyDecorator(), ColorRed+abortedIcon()+ColorReset),
 		),
 		mpb.AppendDecorators(
 			// 时间前置，加括号和颜色
@@ -105,14 +134,14 @@ func AddAlignedSpinner(progressBar *mpb.Progress, imageName, destination string,
 	// 格式化对齐的消息
 	alignedImage := fmt.Sprintf("%-*s", maxImageWidth, imageName)
 	alignedDest := fmt.Sprintf("%-*s", maxDestWidth, destination)
-	message := fmt.Sprintf("%s%s%s → %s%s%s", ColorBlue, alignedImage, ColorReset, ColorCyan, alignedDest, ColorReset)
+	message := fmt.Sprintf("%s%s%s %s %s%s%s", ColorBlue, alignedImage, ColorReset, arrow(), ColorCyan, alignedDest, ColorReset)
 
 	return progressBar.AddSpinner(
 		1, mpb.BarFillerMiddleware(ModernSpinnerLeft),
 		mpb.BarWidth(2),
 		mpb.PrependDecorators(
-			decor.OnComplete(EmptyDecorator(), ColorGreen+CompletedIcon+ColorReset),
-			decor.OnAbort(EmptyDecorator(), ColorRed+"❌"+ColorReset),
+			decor.OnComplete(EmptyDecorator(), ColorGreen+completedIcon()+ColorReset),
+			decor.OnAbort(EmptyDecorator(), ColorRed+abortedIcon()+ColorReset),
 		),
 		mpb.AppendDecorators(
 			// 时间前置，加括号和颜色
@@ -129,7 +158,12 @@ func AddAlignedSpinner(progressBar *mpb.Progress, imageName, destination string,
 func AddColorfulOverallProgress(progressBar *mpb.Progress, total int) *mpb.Bar {
 	return progressBar.AddBar(int64(total),
 		mpb.PrependDecorators(
-			decor.Name(ColorPurple+"📦 "+ColorReset),
+			decor.Any(func(s decor.Statistics) string {
+				if plain.Enabled() {
+					return ""
+				}
+				return ColorPurple + "📦 " + ColorReset
+			}),
 			decor.Any(func(s decor.Statistics) string {
 				return fmt.Sprintf("%s%d/%d%s", ColorCyan, s.Current, total, ColorReset)
 			}),
@@ -160,6 +194,9 @@ func AddColorfulOverallProgress(progressBar *mpb.Progress, total int) *mpb.Bar {
 					color = ColorBlue
 					icon = "🔄"
 				}
+				if plain.Enabled() {
+					return fmt.Sprintf("%s%d%%%s", color, percentage, ColorReset)
+				}
 				return fmt.Sprintf("%s%s %d%%%s", color, icon, percentage, ColorReset)
 			}),
 		),
@@ -173,8 +210,8 @@ func AddSpinner(progressBar *mpb.Progress, message string) *mpb.Bar {
 		1, mpb.BarFillerMiddleware(ModernSpinnerLeft),
 		mpb.BarWidth(3),
 		mpb.PrependDecorators(
-			decor.OnComplete(EmptyDecorator(), emoji.SpinnerCheckMark),
-			decor.OnAbort(EmptyDecorator(), emoji.SpinnerCrossMark),
+			decor.OnComplete(EmptyDecorator(), spinnerMark(emoji.SpinnerCheckMark, "\x1b[1;92m OK \x1b[0m")),
+			decor.OnAbort(EmptyDecorator(), spinnerMark(emoji.SpinnerCrossMark, "\x1b[1;91m FAIL \x1b[0m")),
 		),
 		mpb.AppendDecorators(
 			decor.Name("("),
@@ -185,3 +222,11 @@ func AddSpinner(progressBar *mpb.Progress, message string) *mpb.Bar {
 		BarFillerClearOnAbort(),
 	)
 }
+
+// spinnerMark 返回 AddSpinner 的状态标记，--plain 时使用 ASCII 版本
+func spinnerMark(mark, ascii string) string {
+	if plain.Enabled() {
+		return ascii
+	}
+	return mark
+}
@@ -0,0 +1,203 @@
+package wrapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/api/v2alpha1"
+	"ocpack/pkg/mirror/cli"
+	"ocpack/pkg/secrets"
+
+	"github.com/blang/semver/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DeleteOptions 定向删除私有仓库中 Operator 版本的选项
+type DeleteOptions struct {
+	ClusterName string
+	Operator    string
+	Catalog     string // 可选，目录地址或简称，默认使用 ops 中包含该 Operator 的目录
+	Channel     string // 可选，只删除该通道中的版本
+	Version     string // 可选，版本范围，格式见 ParseVersionRange，为空时删除该 Operator 的全部版本
+	Port        uint16 // oc-mirror 本地缓存 registry 的端口，为 0 时使用 [save_image] local_storage_port 或自动选择
+	DryRun      bool   // 只生成待删除的镜像列表，不删除
+	// ForceCacheDelete 同时清理本地缓存中不再被引用的 blob
+	ForceCacheDelete bool
+}
+
+// ParseVersionRange 解析 --version 指定的版本范围，两端均包含在内:
+// 1.2.3 (单个版本)、1.2.0..1.4.0、..1.4.0 (不高于 1.4.0) 或 1.2.0.. (不低于 1.2.0)
+func ParseVersionRange(value string) (minVersion, maxVersion string, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", nil
+	}
+	minVersion, maxVersion = value, value
+	if idx := strings.Index(value, ".."); idx >= 0 {
+		minVersion, maxVersion = value[:idx], value[idx+2:]
+		if minVersion == "" && maxVersion == "" {
+			return "", "", i18n.Errorf("版本范围 %q 至少需要一端，如 1.2.0..1.4.0、..1.4.0 或 1.2.0..", value)
+		}
+	}
+
+	var versions []semver.Version
+	for _, v := range []string{minVersion, maxVersion} {
+		if v == "" {
+			continue
+		}
+		parsed, err := semver.ParseTolerant(v)
+		if err != nil {
+			return "", "", i18n.Errorf("版本范围 %q 中的 %q 不是有效的版本: %v", value, v, err)
+		}
+		versions = append(versions, parsed)
+	}
+	if minVersion != "" && maxVersion != "" && versions[0].GT(versions[1]) {
+		return "", "", i18n.Errorf("版本范围 %q 的最低版本高于最高版本", value)
+	}
+	return minVersion, maxVersion, nil
+}
+
+// deleteConfig 生成只包含 opts.Operator 指定版本的 DeleteImageSetConfiguration，
+// oc-mirror 按目录中的 bundle 解析出需要删除的 bundle 镜像和相关镜像
+func deleteConfig(catalog config.OperatorCatalog, clusterDir string, opts *DeleteOptions) (*v2alpha1.DeleteImageSetConfiguration, error) {
+	minVersion, maxVersion, err := ParseVersionRange(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	bundle := v2alpha1.IncludeBundle{MinVersion: minVersion, MaxVersion: maxVersion}
+
+	pkg := v2alpha1.IncludePackage{Name: opts.Operator}
+	if opts.Channel != "" {
+		pkg.Channels = []v2alpha1.IncludeChannel{{Name: opts.Channel, IncludeBundle: bundle}}
+	} else {
+		pkg.IncludeBundle = bundle
+	}
+
+	// 与 generateMirrorConfig 一致，本地 OCI 目录使用绝对路径
+	catalogRef := catalog.Catalog
+	if catalog.IsOCI() {
+		catalogRef = "oci://" + catalog.OCIPath(clusterDir)
+	}
+
+	return &v2alpha1.DeleteImageSetConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "mirror.openshift.io/v2alpha1",
+			Kind:       v2alpha1.DeleteImageSetConfigurationKind,
+		},
+		DeleteImageSetConfigurationSpec: v2alpha1.DeleteImageSetConfigurationSpec{
+			Delete: v2alpha1.Delete{
+				Operators: []v2alpha1.Operator{{
+					Catalog:       catalogRef,
+					TargetCatalog: catalog.TargetCatalog,
+					TargetTag:     catalog.TargetTag,
+					IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{pkg}},
+				}},
+			},
+		},
+	}, nil
+}
+
+// DeleteOperatorImages 从私有仓库 destination 中删除一个 Operator 的指定版本，分两步执行 oc-mirror delete:
+//  1. --generate 根据 load-image 工作目录中的目录数据解析出版本范围内的 bundle 及其相关镜像，
+//     写入 working-dir/delete/delete-images-<operator>.yaml
+//  2. --delete-yaml-file 从私有仓库和本地缓存中删除这些镜像
+//
+// DryRun 时只执行第一步。返回生成的镜像列表文件，没有匹配的镜像时为空
+func (w *MirrorWrapper) DeleteOperatorImages(cfg *config.ClusterConfig, destination string, opts *DeleteOptions) (string, error) {
+	port, err := w.resolveLocalStoragePort(cfg, opts.Port)
+	if err != nil {
+		return "", err
+	}
+	clusterDir, err := resolveClusterDir(cfg, opts.ClusterName)
+	if err != nil {
+		return "", err
+	}
+	workspaceDir, cacheDir, err := w.setupWorkspaceAndCache(clusterDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to setup workspace: %v", err)
+	}
+	authFilePath, err := w.setupAuthentication(cfg, clusterDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to setup authentication: %v", err)
+	}
+	tls, err := w.applyTrustBundle(cfg, clusterDir)
+	if err != nil {
+		return "", err
+	}
+
+	catalog, err := cfg.FindOperatorCatalog(opts.Operator, opts.Catalog)
+	if err != nil {
+		return "", err
+	}
+	deleteCfg, err := deleteConfig(catalog, clusterDir, opts)
+	if err != nil {
+		return "", err
+	}
+	content, err := yaml.Marshal(deleteCfg)
+	if err != nil {
+		return "", err
+	}
+	tempDir := filepath.Join(os.TempDir(), "ocpack-mirror", opts.ClusterName)
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	configPath := filepath.Join(tempDir, "delete-imageset-config.yaml")
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		return "", i18n.Errorf("写入配置文件失败: %v", err)
+	}
+	defer os.Remove(configPath)
+	w.log.Debug("DeleteImageSetConfiguration:\n%s", content)
+
+	w.log.Info("📦 Catalog: %s", catalog.Catalog)
+	commonArgs := []string{
+		"--v2",
+		"--log-level", w.log.GetLevel(),
+		"-p", strconv.Itoa(port),
+		"--workspace", workspaceDir,
+		"--cache-dir", cacheDir,
+		"--delete-id", opts.Operator,
+	}
+	commonArgs = append(commonArgs, tls.MirrorArgs()...)
+	if authFilePath != "" {
+		commonArgs = append(commonArgs, "--authfile", authFilePath)
+	}
+
+	w.log.Info("🔍 Resolving images of %s to delete...", opts.Operator)
+	generateArgs := append([]string{"delete", "-c", configPath, "--generate"}, commonArgs...)
+	if err := w.runMirrorCommand(append(generateArgs, destination)); err != nil {
+		return "", err
+	}
+
+	workingDir := filepath.Join(strings.TrimPrefix(workspaceDir, "file://"), "working-dir")
+	deleteFile := filepath.Join(workingDir, "delete", "delete-images-"+opts.Operator+".yaml")
+	if _, err := os.Stat(deleteFile); os.IsNotExist(err) {
+		return "", nil
+	}
+	if opts.DryRun {
+		return deleteFile, nil
+	}
+
+	w.log.Info("🗑️  Deleting images listed in %s...", deleteFile)
+	deleteArgs := append([]string{"delete", "--delete-yaml-file", deleteFile}, commonArgs...)
+	if opts.ForceCacheDelete {
+		deleteArgs = append(deleteArgs, "--force-cache-delete")
+	}
+	if err := w.runMirrorCommand(append(deleteArgs, destination)); err != nil {
+		return deleteFile, err
+	}
+	return deleteFile, nil
+}
+
+// runMirrorCommand 使用给定参数执行内置的 oc-mirror
+func (w *MirrorWrapper) runMirrorCommand(args []string) error {
+	cmd := cli.NewMirrorCmd(w.log)
+	cmd.SetArgs(args)
+	w.log.Debug("Command arguments: %v", secrets.RedactArgs(args))
+	return cmd.Execute()
+}
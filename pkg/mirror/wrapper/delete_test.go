@@ -0,0 +1,99 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirror/api/v2alpha1"
+	mirrorconfig "ocpack/pkg/mirror/config"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max string
+		valid    bool
+	}{
+		{"", "", "", true},
+		{"5.8.3", "5.8.3", "5.8.3", true},
+		{"5.6.0..5.7.9", "5.6.0", "5.7.9", true},
+		{"..5.7.9", "", "5.7.9", true},
+		{"5.6.0..", "5.6.0", "", true},
+		{"v4.2", "v4.2", "v4.2", true},
+		{"..", "", "", false},
+		{"5.8.0..5.6.0", "", "", false},
+		{"latest", "", "", false},
+	}
+	for _, tt := range tests {
+		minVersion, maxVersion, err := ParseVersionRange(tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseVersionRange(%q) error = %v, expected valid = %t", tt.value, err, tt.valid)
+			continue
+		}
+		if minVersion != tt.min || maxVersion != tt.max {
+			t.Errorf("ParseVersionRange(%q) = %q, %q, expected %q, %q", tt.value, minVersion, maxVersion, tt.min, tt.max)
+		}
+	}
+}
+
+func TestDeleteConfig(t *testing.T) {
+	catalog := config.OperatorCatalog{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", Ops: []string{"cluster-logging"}}
+
+	tests := []struct {
+		name     string
+		opts     DeleteOptions
+		expected []string
+	}{
+		{
+			name: "package 级别的版本范围",
+			opts: DeleteOptions{Operator: "cluster-logging", Version: "5.6.0..5.7.9"},
+			expected: []string{
+				"kind: DeleteImageSetConfiguration",
+				"- catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				"    - maxVersion: 5.7.9\n      minVersion: 5.6.0\n      name: cluster-logging",
+			},
+		},
+		{
+			name: "通道中的单个版本",
+			opts: DeleteOptions{Operator: "cluster-logging", Channel: "stable-5.7", Version: "5.7.1"},
+			expected: []string{
+				"    - channels:\n      - maxVersion: 5.7.1\n        minVersion: 5.7.1\n        name: stable-5.7\n      name: cluster-logging",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disc, err := deleteConfig(catalog, "/opt/ocpack/demo", &tt.opts)
+			if err != nil {
+				t.Fatalf("deleteConfig() error = %v", err)
+			}
+			if err := mirrorconfig.ValidateDelete(disc); err != nil {
+				t.Errorf("ValidateDelete() error = %v", err)
+			}
+			content, err := yaml.Marshal(disc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("generated config missing %q:\n%s", want, content)
+				}
+			}
+
+			var parsed v2alpha1.DeleteImageSetConfiguration
+			if err := yaml.Unmarshal(content, &parsed); err != nil {
+				t.Fatalf("yaml.Unmarshal() error = %v", err)
+			}
+			if pkgs := parsed.Delete.Operators[0].Packages; len(pkgs) != 1 || pkgs[0].Name != "cluster-logging" {
+				t.Errorf("unexpected packages: %+v", pkgs)
+			}
+		})
+	}
+
+	if _, err := deleteConfig(catalog, "", &DeleteOptions{Operator: "cluster-logging", Version: "5.8..5.6"}); err == nil {
+		t.Error("deleteConfig() expected error for an invalid version range")
+	}
+}
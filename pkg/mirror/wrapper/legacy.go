@@ -0,0 +1,124 @@
+package wrapper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/imagepolicy"
+	"ocpack/pkg/mirror/api/v2alpha1"
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/utils"
+)
+
+// Runner 执行 oc adm release mirror 等外部命令，测试时可替换为 runner.Fake
+var Runner runner.CommandRunner = runner.NewExecRunner()
+
+const (
+	// legacyReleaseDirName 4.14 以下的 release 在镜像存储中的目录，由 oc adm release mirror --to-dir 写入
+	legacyReleaseDirName = "legacy-release"
+
+	// upstreamReleaseRepository 和 upstreamComponentsRepository 为 release 镜像及其组件镜像的上游仓库
+	upstreamReleaseRepository    = "quay.io/openshift-release-dev/ocp-release"
+	upstreamComponentsRepository = "quay.io/openshift-release-dev/ocp-v4.0-art-dev"
+)
+
+// IsLegacyRelease 判断 release 是否需要通过 oc adm release mirror 镜像。内置的 oc-mirror (v2) 只支持 4.14 及以上版本，
+// 4.10 至 4.13 的 release 由 oc 直接镜像，Operator 和附加镜像仍由内置 oc-mirror 处理
+func IsLegacyRelease(cfg *config.ClusterConfig) bool {
+	return utils.IsLegacyMirrorVersion(cfg.ClusterInfo.OpenShiftVersion)
+}
+
+// withoutRelease 去掉镜像集中的 release，只保留 Operator、附加镜像和 Helm chart。没有其他内容时返回 nil
+func withoutRelease(mirrorConfig *v2alpha1.ImageSetConfiguration) *v2alpha1.ImageSetConfiguration {
+	mirrorConfig.Mirror.Platform = v2alpha1.Platform{}
+	m := mirrorConfig.Mirror
+	if len(m.Operators) == 0 && len(m.AdditionalImages) == 0 && len(m.Helm.Repositories) == 0 && len(m.Helm.Local) == 0 {
+		return nil
+	}
+	return mirrorConfig
+}
+
+// legacyReleaseToDisk 使用 oc adm release mirror --to-dir 将 release 镜像及其组件保存到镜像存储的 legacy-release 目录
+func (w *MirrorWrapper) legacyReleaseToDisk(ctx context.Context, cfg *config.ClusterConfig, clusterDir string, tls *registrytls.Policy, imagesDir, authFile string, dryRun bool) error {
+	releaseImage := upstreamReleaseRepository + ":" + cfg.GetReleaseTag()
+	w.log.Info("📦 OpenShift %s is older than 4.14, mirroring release %s with oc adm release mirror", cfg.ClusterInfo.OpenShiftVersion, releaseImage)
+	if minVersion, maxVersion := cfg.GetReleaseRange(); minVersion != maxVersion {
+		w.log.Warn("⚠️  Release ranges are not supported for OpenShift < 4.14, only %s is mirrored", cfg.ClusterInfo.OpenShiftVersion)
+	}
+
+	args := []string{"adm", "release", "mirror", "--to-dir=" + filepath.Join(imagesDir, legacyReleaseDirName)}
+	args = append(args, legacyAuthArgs(authFile)...)
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	args = append(args, tls.OCArgs(releaseImage)...)
+	args = append(args, releaseImage)
+	return w.runOc(ctx, cfg, clusterDir, tls, args)
+}
+
+// legacyReleaseToMirror 将 legacy-release 目录中的 release 推送到私有仓库，与 oc-mirror 的布局一致：
+// release 镜像推送到 openshift/release-images，组件镜像推送到 openshift/release。
+// 私有仓库的证书按 registrytls 的配置校验。推送后在工作目录的 cluster-resources 中写入 release 的 ICSP，供 generate-iso 和 rewrite-manifests 使用
+func (w *MirrorWrapper) legacyReleaseToMirror(ctx context.Context, cfg *config.ClusterConfig, clusterDir string, tls *registrytls.Policy, imagesDir, workspaceDir, authFile string, dryRun bool) error {
+	releaseDir := filepath.Join(imagesDir, legacyReleaseDirName)
+	if _, err := os.Stat(releaseDir); err != nil {
+		return i18n.Errorf("未找到 release %s 的镜像目录 %s，请先执行 save-image: %w", cfg.ClusterInfo.OpenShiftVersion, releaseDir, err)
+	}
+
+	tag := cfg.GetReleaseTag()
+	w.log.Info("📦 OpenShift %s is older than 4.14, pushing release %s with oc adm release mirror", cfg.ClusterInfo.OpenShiftVersion, tag)
+	args := []string{"adm", "release", "mirror",
+		"--from-dir=" + releaseDir,
+		"--to=" + cfg.GetReleaseComponentsRepository(),
+		"--to-release-image=" + cfg.GetReleaseRepository() + ":" + tag,
+	}
+	args = append(args, tls.OCArgs(cfg.GetReleaseRepository())...)
+	args = append(args, legacyAuthArgs(authFile)...)
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	args = append(args, "file://openshift/release:"+tag)
+	if err := w.runOc(ctx, cfg, clusterDir, tls, args); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	path, err := imagepolicy.WriteLegacyReleasePolicy(filepath.Join(strings.TrimPrefix(workspaceDir, "file://"), "working-dir"), []imagepolicy.Mirror{
+		{Source: upstreamReleaseRepository, Mirrors: []string{cfg.GetReleaseRepository()}},
+		{Source: upstreamComponentsRepository, Mirrors: []string{cfg.GetReleaseComponentsRepository()}},
+	})
+	if err != nil {
+		return err
+	}
+	w.log.Info("📄 Release ImageContentSourcePolicy written to %s", path)
+	return nil
+}
+
+// legacyAuthArgs 返回 oc 使用的认证文件参数
+func legacyAuthArgs(authFile string) []string {
+	if authFile == "" {
+		return nil
+	}
+	return []string{"--registry-config=" + authFile}
+}
+
+// runOc 执行 oc 命令，输出实时显示，额外信任 tls 中的 CA。优先使用下载目录中与集群版本对应的 oc，不存在时使用 PATH 中的 oc
+func (w *MirrorWrapper) runOc(ctx context.Context, cfg *config.ClusterConfig, clusterDir string, tls *registrytls.Policy, args []string) error {
+	name := "oc"
+	if path := filepath.Join(cfg.GetDownloadDir(clusterDir), "bin", "oc"); utils.FileExists(path) {
+		name = path
+	}
+	cmd := runner.Command{Name: name, Args: args, Env: tls.OCEnv(), Stream: true, Context: ctx}
+	w.log.Debug("Command: %s", cmd)
+	if _, err := Runner.Run(cmd); err != nil {
+		return i18n.Errorf("%s 失败: %w", strings.Join(args[:3], " "), err)
+	}
+	return nil
+}
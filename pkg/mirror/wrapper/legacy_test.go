@@ -0,0 +1,115 @@
+package wrapper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirror/api/v2alpha1"
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+)
+
+func legacyConfig(version string) *config.ClusterConfig {
+	cfg := &config.ClusterConfig{}
+	cfg.ClusterInfo.ClusterID = "demo"
+	cfg.ClusterInfo.Domain = "example.com"
+	cfg.ClusterInfo.OpenShiftVersion = version
+	return cfg
+}
+
+func TestIsLegacyRelease(t *testing.T) {
+	tests := map[string]bool{"4.10.3": true, "4.13.40": true, "4.14.0": false, "4.16.3": false, "4.9.59": false}
+	for version, want := range tests {
+		if got := IsLegacyRelease(legacyConfig(version)); got != want {
+			t.Errorf("IsLegacyRelease(%s) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestWithoutRelease(t *testing.T) {
+	releaseOnly := &v2alpha1.ImageSetConfiguration{}
+	releaseOnly.Mirror.Platform.Channels = []v2alpha1.ReleaseChannel{{Name: "stable-4.12"}}
+	if got := withoutRelease(releaseOnly); got != nil {
+		t.Errorf("withoutRelease() = %+v, want nil for a release-only image set", got)
+	}
+
+	withImages := &v2alpha1.ImageSetConfiguration{}
+	withImages.Mirror.Platform.Channels = []v2alpha1.ReleaseChannel{{Name: "stable-4.12"}}
+	withImages.Mirror.AdditionalImages = []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}}
+	got := withoutRelease(withImages)
+	if got == nil || len(got.Mirror.Platform.Channels) != 0 || len(got.Mirror.AdditionalImages) != 1 {
+		t.Errorf("withoutRelease() = %+v, want additional images without release", got)
+	}
+}
+
+func TestLegacyReleaseToMirror(t *testing.T) {
+	fake := &runner.Fake{}
+	orig := Runner
+	Runner = fake
+	defer func() { Runner = orig }()
+
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+	clusterDir := t.TempDir()
+	imagesDir := filepath.Join(clusterDir, "images")
+	cfg := legacyConfig("4.12.30")
+	tls := &registrytls.Policy{CertDir: filepath.Join(clusterDir, "certs")}
+
+	if err := w.legacyReleaseToMirror(context.Background(), cfg, clusterDir, tls, imagesDir, "file://"+imagesDir, "auth.json", false); err == nil {
+		t.Fatal("legacyReleaseToMirror() expected error without legacy-release directory")
+	}
+	if err := os.MkdirAll(filepath.Join(imagesDir, legacyReleaseDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.legacyReleaseToMirror(context.Background(), cfg, clusterDir, tls, imagesDir, "file://"+imagesDir, "auth.json", false); err != nil {
+		t.Fatalf("legacyReleaseToMirror() error = %v", err)
+	}
+
+	lines := fake.CommandLines()
+	if len(lines) != 1 {
+		t.Fatalf("expected one oc call, got %v", lines)
+	}
+	if strings.Contains(lines[0], "--insecure") {
+		t.Errorf("private registry certificate should be verified: %q", lines[0])
+	}
+	if env := fake.Calls()[0].Env; len(env) != 1 || !strings.HasPrefix(env[0], "SSL_CERT_DIR="+tls.CertDir) {
+		t.Errorf("oc should trust the cert dir, env = %v", env)
+	}
+	for _, want := range []string{
+		"oc adm release mirror",
+		"--from-dir=" + filepath.Join(imagesDir, legacyReleaseDirName),
+		"--to=registry.demo.example.com:8443/openshift/release ",
+		"--to-release-image=registry.demo.example.com:8443/openshift/release-images:4.12.30-x86_64",
+		"--registry-config=auth.json",
+		"file://openshift/release:4.12.30-x86_64",
+	} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("command %q missing %q", lines[0], want)
+		}
+	}
+
+	// 只有 registry_tls 中标记为 insecure 的仓库才跳过校验
+	tls.Insecure = []string{"registry.demo.example.com:8443"}
+	if err := w.legacyReleaseToMirror(context.Background(), cfg, clusterDir, tls, imagesDir, "file://"+imagesDir, "auth.json", false); err != nil {
+		t.Fatalf("legacyReleaseToMirror() error = %v", err)
+	}
+	if lines := fake.CommandLines(); len(lines) != 2 || !strings.Contains(lines[1], "--insecure") {
+		t.Errorf("insecure registry should skip verification: %v", lines)
+	}
+
+	icsp, err := os.ReadFile(filepath.Join(imagesDir, "working-dir", "cluster-resources", "icsp-ocpack-release.yaml"))
+	if err != nil {
+		t.Fatalf("release ICSP not written: %v", err)
+	}
+	for _, want := range []string{"kind: ImageContentSourcePolicy", "source: quay.io/openshift-release-dev/ocp-v4.0-art-dev"} {
+		if !strings.Contains(string(icsp), want) {
+			t.Errorf("release ICSP missing %q:\n%s", want, icsp)
+		}
+	}
+}
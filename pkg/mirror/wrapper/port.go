@@ -0,0 +1,39 @@
+package wrapper
+
+import (
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/utils"
+)
+
+// portSearchRange 默认端口被占用时向后查找空闲端口的数量
+const portSearchRange = 100
+
+// resolveLocalStoragePort 选择 oc-mirror 本地缓存 registry 的端口：
+// 优先使用命令行 --port，其次是 [save_image] local_storage_port，二者指定的端口被占用时报错；
+// 都未指定时从 55000 开始选择第一个空闲端口，避免与同一主机上正在运行的镜像任务冲突
+func (w *MirrorWrapper) resolveLocalStoragePort(cfg *config.ClusterConfig, requested uint16) (int, error) {
+	port, source := int(requested), "--port"
+	if port == 0 {
+		port, source = cfg.GetLocalStoragePort(), "save_image.local_storage_port"
+	}
+	if port != 0 {
+		if !utils.PortAvailable(port) {
+			return 0, clierr.New(clierr.Prereq, i18n.Errorf("%s 指定的端口 %d 已被占用，请更换端口或等待占用该端口的镜像任务结束", source, port))
+		}
+		w.log.Info("🔌 Using local storage port %d (%s)", port, source)
+		return port, nil
+	}
+
+	port, err := utils.FindAvailablePort(config.DefaultLocalStoragePort, portSearchRange)
+	if err != nil {
+		return 0, clierr.New(clierr.Prereq, i18n.Errorf("无法为 oc-mirror 本地缓存 registry 选择端口: %w，请使用 --port 指定", err))
+	}
+	if port != config.DefaultLocalStoragePort {
+		w.log.Warn("⚠️  Port %d is in use, using local storage port %d instead", config.DefaultLocalStoragePort, port)
+	} else {
+		w.log.Info("🔌 Using local storage port %d", port)
+	}
+	return port, nil
+}
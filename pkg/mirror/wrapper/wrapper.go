@@ -2,24 +2,46 @@ package wrapper
 
 import (
 	"bufio"
-	"encoding/base64"
-	"encoding/json"
+	"bytes"
+	"context"
+	"embed"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"ocpack/pkg/auth"
+	"ocpack/pkg/clierr"
 	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/kubeconfig"
 	"ocpack/pkg/mirror/api/v2alpha1"
 	"ocpack/pkg/mirror/cli"
 	clog "ocpack/pkg/mirror/log"
+	"ocpack/pkg/mirror/mirror"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/mirror/release"
+	"ocpack/pkg/mirrormap"
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/secrets"
+	"ocpack/pkg/utils"
+	ocworkspace "ocpack/pkg/workspace"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+//go:embed templates/*
+var templates embed.FS
+
+// imageSetConfigTemplate ImageSetConfiguration 的内置模板，可被 <cluster>/templates/imageset-config.yaml 覆盖
+const imageSetConfigTemplate = "templates/imageset-config.yaml"
+
 // MirrorWrapper oc-mirror 功能的内置包装器
 type MirrorWrapper struct {
 	log      clog.PluggableLoggerInterface
@@ -30,15 +52,37 @@ type MirrorWrapper struct {
 type MirrorOptions struct {
 	ClusterName string
 	ConfigPath  string
-	Port        uint16
+	Port        uint16 // oc-mirror 本地缓存 registry 的端口，为 0 时使用 [save_image] local_storage_port 或自动选择
 	DryRun      bool
 	Force       bool
+	// ClusterDir 集群目录，为空时为当前目录下的 ClusterName。嵌入其他工具时设置，不依赖当前目录
+	ClusterDir string
+	// Images 非空时只镜像这些镜像 (save-image/load-image --images-file)，跳过 release 和 Operator
+	Images []string
+	// OfflineGraph 只使用之前保存在工作目录中的升级图，不访问 Cincinnati (save-image/plan --offline-graph)
+	OfflineGraph bool
+	// RefreshCatalog 忽略 catalog_cache_ttl，总是从源仓库查询 Operator 目录 (save-image --refresh-catalog)
+	RefreshCatalog bool
+	// OfflineCatalog 只使用之前下载到工作目录中的 Operator 目录，不访问源仓库 (save-image --offline-catalog)
+	OfflineCatalog bool
+	// FullCopy 复制全部镜像，不预先查询目标中已存在的镜像 (save-image/load-image --full-copy)
+	FullCopy bool
+	// Only 非空时只复制这些分组 (release、operators、additional、helm) 的镜像 (save-image/load-image --only)
+	Only []string
+	// CopyAttempts 和 CopyBackoff 覆盖 [save_image.retry] 中单个镜像的尝试次数和退避方式 (save-image/load-image --copy-attempts/--copy-backoff)
+	CopyAttempts int
+	CopyBackoff  string
 	// 重试相关配置
 	EnableRetry   bool // 是否启用重试
 	MaxRetries    int  // 最大重试次数，默认为 2
 	RetryInterval int  // 重试间隔(秒)，默认为 30
 }
 
+// DumpTemplates 将内置的 imageset-config.yaml 模板导出到 dir，供用户修改
+func DumpTemplates(dir string, force bool) ([]string, error) {
+	return utils.DumpTemplates(templates, []string{imageSetConfigTemplate}, dir, force)
+}
+
 // NewMirrorWrapper 创建新的镜像包装器
 func NewMirrorWrapper(logLevel string) (*MirrorWrapper, error) {
 	log := clog.New(logLevel)
@@ -48,33 +92,81 @@ func NewMirrorWrapper(logLevel string) (*MirrorWrapper, error) {
 	}, nil
 }
 
-// MirrorToDisk 执行镜像到磁盘操作
-func (w *MirrorWrapper) MirrorToDisk(cfg *config.ClusterConfig, destination string, opts *MirrorOptions) error {
+// NewStreamingMirrorWrapper 创建实时输出日志的镜像包装器：每条日志立即写入 out，
+// 各阶段 (collect、copy release、copy operators、archive 等) 开始时输出标题，
+// verbosity 为 progress.Quiet 时只输出错误和最终摘要
+func NewStreamingMirrorWrapper(out io.Writer, verbosity progress.Verbosity) *MirrorWrapper {
+	return &MirrorWrapper{
+		log: progress.New(out, verbosity),
+	}
+}
+
+// MirrorToDisk 执行镜像到磁盘操作，ctx 被取消或超时时停止复制，不再重试
+func (w *MirrorWrapper) MirrorToDisk(ctx context.Context, cfg *config.ClusterConfig, destination string, opts *MirrorOptions) error {
 	w.log.Info("🔄 Mirroring to disk...")
 
+	port, err := w.resolveLocalStoragePort(cfg, opts.Port)
+	if err != nil {
+		return err
+	}
+
 	// 定义执行函数
 	executeFunc := func() error {
+		clusterDir, err := opts.clusterDir(cfg)
+		if err != nil {
+			return err
+		}
+
 		// 设置缓存目录，避免使用默认的 $HOME/.oc-mirror
 		// 注意：MirrorToDisk 不需要自定义 workspace，oc-mirror 会在目标目录自动创建
-		_, cacheDir, err := w.setupWorkspaceAndCache(cfg, opts.ClusterName)
+		_, cacheDir, err := w.setupWorkspaceAndCache(clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup cache directory: %v", err)
 		}
 
 		// 设置认证配置
-		authFilePath, err := w.setupAuthentication(cfg, opts.ClusterName)
+		authFilePath, err := w.setupAuthentication(cfg, clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup authentication: %v", err)
 		}
 
 		// 优先使用内置生成的配置（从 config.toml 读取）
 		w.log.Info("📋 Loading config...")
-		mirrorConfig, err := w.generateMirrorConfig(cfg)
+		if err := w.applyUpdateURLOverride(cfg); err != nil {
+			return err
+		}
+		tls, err := w.applyTrustBundle(cfg, clusterDir)
 		if err != nil {
-			return fmt.Errorf("failed to generate mirror config: %v", err)
+			return err
+		}
+		var mirrorConfig *v2alpha1.ImageSetConfiguration
+		if len(opts.Images) > 0 {
+			w.log.Info("📦 Mirroring only listed images: %d images", len(opts.Images))
+			mirrorConfig = additionalImagesConfig(opts.Images)
+		} else {
+			workingDir := filepath.Join(strings.TrimPrefix(destination, "file://"), "working-dir")
+			mirrorConfig, err = w.generateMirrorConfig(cfg, clusterDir, w.remoteChannelVersions(ctx, graphOptions(cfg, workingDir, opts), cfg.GetReleaseArchitecture()))
+			if err != nil {
+				return fmt.Errorf("failed to generate mirror config: %v", err)
+			}
+			if err := checkOCICatalogs(cfg, clusterDir); err != nil {
+				return err
+			}
+		}
+		legacy := len(opts.Images) == 0 && IsLegacyRelease(cfg)
+		if legacy {
+			if opts.includes(config.MirrorGroupRelease) {
+				if err := w.legacyReleaseToDisk(ctx, cfg, clusterDir, tls, strings.TrimPrefix(destination, "file://"), authFilePath, opts.DryRun); err != nil {
+					return err
+				}
+			}
+			if mirrorConfig = withoutRelease(mirrorConfig); mirrorConfig == nil || opts.onlyRelease() {
+				w.log.Info("✅ Mirror operation completed")
+				return nil
+			}
 		}
 
-		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, opts.ClusterName)
+		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, clusterDir, opts.ClusterName)
 		if err != nil {
 			return fmt.Errorf("failed to create temporary config file: %v", err)
 		}
@@ -87,11 +179,13 @@ func (w *MirrorWrapper) MirrorToDisk(cfg *config.ClusterConfig, destination stri
 		args := []string{
 			"-c", tempConfigPath,
 			"--v2",
-			"-p", strconv.Itoa(int(opts.Port)),
+			"--log-level", w.log.GetLevel(), // 与包装器的日志级别保持一致
+			"-p", strconv.Itoa(port),
 			"--cache-dir", cacheDir, // 明确指定缓存目录
-			"--src-tls-verify=false",
-			"--dest-tls-verify=false",
 		}
+		args = append(args, tls.MirrorArgs()...)
+		args = append(args, graphArgs(cfg, opts)...)
+		args = append(args, catalogArgs(cfg, opts)...)
 
 		if opts.DryRun {
 			args = append(args, "--dry-run")
@@ -101,6 +195,12 @@ func (w *MirrorWrapper) MirrorToDisk(cfg *config.ClusterConfig, destination stri
 			args = append(args, "--force")
 		}
 
+		if !opts.FullCopy {
+			args = append(args, "--skip-present")
+		}
+		args = append(args, orderArgs(cfg, opts)...)
+		args = append(args, retryArgs(cfg, opts)...)
+
 		// 添加目标路径
 		args = append(args, destination)
 
@@ -112,10 +212,10 @@ func (w *MirrorWrapper) MirrorToDisk(cfg *config.ClusterConfig, destination stri
 
 		cmd.SetArgs(args)
 
-		w.log.Debug("Command arguments: %v", args)
+		w.log.Debug("Command arguments: %v", secrets.RedactArgs(args))
 		w.log.Info("💾 Cache: %s", cacheDir)
 
-		err = cmd.Execute()
+		err = cmd.ExecuteContext(ctx)
 		if err != nil {
 			// 检查错误是否提到了部分失败但成功率较高的情况
 			if strings.Contains(err.Error(), "some errors occurred during the mirroring") {
@@ -124,44 +224,83 @@ func (w *MirrorWrapper) MirrorToDisk(cfg *config.ClusterConfig, destination stri
 				w.log.Warn("   Details: %v", err)
 				w.log.Info("💡 Suggestion: You can choose to ignore individual image failures and continue with subsequent deployment")
 				w.log.Info("   If deployment issues occur later, you can re-run this command to retry failed images")
+				return clierr.New(clierr.Partial, err)
 			}
 			return err
 		}
 
 		w.log.Info("✅ Mirror operation completed")
+		if !opts.DryRun && len(opts.Images) == 0 && !legacy && opts.includes(config.MirrorGroupRelease) {
+			w.recordReleaseDigest(cfg, clusterDir, destination)
+		}
 		return nil
 	}
 
 	// 使用重试机制执行
-	return w.executeWithRetry(executeFunc, destination, opts)
+	return w.executeWithRetry(ctx, executeFunc, destination, opts)
 }
 
-// DiskToMirror 执行磁盘到仓库操作
-func (w *MirrorWrapper) DiskToMirror(cfg *config.ClusterConfig, source, destination string, opts *MirrorOptions) error {
+// DiskToMirror 执行磁盘到仓库操作，ctx 被取消或超时时停止复制，不再重试
+func (w *MirrorWrapper) DiskToMirror(ctx context.Context, cfg *config.ClusterConfig, source, destination string, opts *MirrorOptions) error {
 	w.log.Info("🔄 Disk to mirror...")
 
+	port, err := w.resolveLocalStoragePort(cfg, opts.Port)
+	if err != nil {
+		return err
+	}
+
 	// 定义执行函数
 	executeFunc := func() error {
+		clusterDir, err := opts.clusterDir(cfg)
+		if err != nil {
+			return err
+		}
+
 		// 设置工作空间和缓存目录，避免使用默认的 $HOME/.oc-mirror
-		workspaceDir, cacheDir, err := w.setupWorkspaceAndCache(cfg, opts.ClusterName)
+		workspaceDir, cacheDir, err := w.setupWorkspaceAndCache(clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup workspace: %v", err)
 		}
 
 		// 设置认证配置
-		authFilePath, err := w.setupAuthentication(cfg, opts.ClusterName)
+		authFilePath, err := w.setupAuthentication(cfg, clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup authentication: %v", err)
 		}
 
 		// 优先使用内置生成的配置（从 config.toml 读取）
 		w.log.Info("📋 Loading config...")
-		mirrorConfig, err := w.generateMirrorConfig(cfg)
+		if err := w.applyUpdateURLOverride(cfg); err != nil {
+			return err
+		}
+		tls, err := w.applyTrustBundle(cfg, clusterDir)
 		if err != nil {
-			return fmt.Errorf("failed to generate mirror config: %v", err)
+			return err
+		}
+		var mirrorConfig *v2alpha1.ImageSetConfiguration
+		if len(opts.Images) > 0 {
+			w.log.Info("📦 Loading only listed images: %d images", len(opts.Images))
+			mirrorConfig = additionalImagesConfig(opts.Images)
+		} else {
+			mirrorConfig, err = w.generateMirrorConfig(cfg, clusterDir, w.localChannelVersions(ctx, source, cfg.GetReleaseArchitecture()))
+			if err != nil {
+				return fmt.Errorf("failed to generate mirror config: %v", err)
+			}
+		}
+		legacy := len(opts.Images) == 0 && IsLegacyRelease(cfg)
+		pushLegacy := legacy && opts.includes(config.MirrorGroupRelease)
+		imagesDir := strings.TrimPrefix(source, "file://")
+		if legacy {
+			if mirrorConfig = withoutRelease(mirrorConfig); mirrorConfig == nil || opts.onlyRelease() {
+				if !pushLegacy {
+					w.log.Info("✅ Nothing to mirror for %v", opts.Only)
+					return nil
+				}
+				return w.legacyReleaseToMirror(ctx, cfg, clusterDir, tls, imagesDir, workspaceDir, authFilePath, opts.DryRun)
+			}
 		}
 
-		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, opts.ClusterName)
+		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, clusterDir, opts.ClusterName)
 		if err != nil {
 			return fmt.Errorf("failed to create temporary config file: %v", err)
 		}
@@ -173,13 +312,13 @@ func (w *MirrorWrapper) DiskToMirror(cfg *config.ClusterConfig, source, destinat
 		args := []string{
 			"-c", tempConfigPath,
 			"--v2",
-			"-p", strconv.Itoa(int(opts.Port)),
+			"--log-level", w.log.GetLevel(), // 与包装器的日志级别保持一致
+			"-p", strconv.Itoa(port),
 			"--from", source,
 			"--workspace", workspaceDir, // 明确指定工作空间
 			"--cache-dir", cacheDir, // 明确指定缓存目录
-			"--src-tls-verify=false",
-			"--dest-tls-verify=false",
 		}
+		args = append(args, tls.MirrorArgs()...)
 
 		// 添加认证文件参数（如果存在）
 		if authFilePath != "" {
@@ -195,16 +334,22 @@ func (w *MirrorWrapper) DiskToMirror(cfg *config.ClusterConfig, source, destinat
 			args = append(args, "--force")
 		}
 
+		if !opts.FullCopy {
+			args = append(args, "--skip-present")
+		}
+		args = append(args, orderArgs(cfg, opts)...)
+		args = append(args, retryArgs(cfg, opts)...)
+
 		// 添加目标路径
 		args = append(args, destination)
 
 		cmd.SetArgs(args)
 
-		w.log.Debug("Command arguments: %v", args)
+		w.log.Debug("Command arguments: %v", secrets.RedactArgs(args))
 		w.log.Info("💾 Using workspace: %s", workspaceDir)
 		w.log.Info("💾 Using cache: %s", cacheDir)
 
-		err = cmd.Execute()
+		err = cmd.ExecuteContext(ctx)
 		if err != nil {
 			// 检查错误是否提到了部分失败但成功率较高的情况
 			if strings.Contains(err.Error(), "some errors occurred during the mirroring") {
@@ -213,38 +358,77 @@ func (w *MirrorWrapper) DiskToMirror(cfg *config.ClusterConfig, source, destinat
 				w.log.Warn("   Details: %v", err)
 				w.log.Info("💡 Suggestion: You can choose to ignore individual image failures and continue with subsequent deployment")
 				w.log.Info("   If deployment issues occur later, you can re-run this command to retry failed images")
+				return clierr.New(clierr.Partial, err)
 			}
 			return err
 		}
 
+		if pushLegacy {
+			if err := w.legacyReleaseToMirror(ctx, cfg, clusterDir, tls, imagesDir, workspaceDir, authFilePath, opts.DryRun); err != nil {
+				return err
+			}
+		}
+
 		w.log.Info("✅ Mirror operation completed")
+		if !opts.DryRun {
+			if len(opts.Images) == 0 && !legacy && opts.includes(config.MirrorGroupRelease) {
+				w.recordReleaseDigest(cfg, clusterDir, workspaceDir, source)
+			}
+			w.publishMirrorMapping(clusterDir, workspaceDir)
+		}
 		return nil
 	}
 
 	// 使用重试机制执行
-	return w.executeWithRetry(executeFunc, source, opts)
+	return w.executeWithRetry(ctx, executeFunc, source, opts)
 }
 
-// MirrorDirect 执行直接镜像操作
-func (w *MirrorWrapper) MirrorDirect(cfg *config.ClusterConfig, workspace, destination string, opts *MirrorOptions) error {
+// MirrorDirect 执行直接镜像操作，ctx 被取消或超时时停止复制，不再重试
+func (w *MirrorWrapper) MirrorDirect(ctx context.Context, cfg *config.ClusterConfig, workspace, destination string, opts *MirrorOptions) error {
 	w.log.Info("🔄 Mirror to mirror...")
 
+	port, err := w.resolveLocalStoragePort(cfg, opts.Port)
+	if err != nil {
+		return err
+	}
+
 	// 定义执行函数
 	executeFunc := func() error {
+		clusterDir, err := opts.clusterDir(cfg)
+		if err != nil {
+			return err
+		}
+
 		// 设置工作空间和缓存目录，避免使用默认的 $HOME/.oc-mirror
-		workspaceDir, cacheDir, err := w.setupWorkspaceAndCache(cfg, opts.ClusterName)
+		workspaceDir, cacheDir, err := w.setupWorkspaceAndCache(clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup workspace: %v", err)
 		}
 
 		// 生成 oc-mirror 配置
-		mirrorConfig, err := w.generateMirrorConfig(cfg)
+		if err := w.applyUpdateURLOverride(cfg); err != nil {
+			return err
+		}
+		tls, err := w.applyTrustBundle(cfg, clusterDir)
+		if err != nil {
+			return err
+		}
+		// 如果用户提供了workspace参数，使用用户的，否则使用我们计算的
+		if workspace == "" {
+			workspace = workspaceDir
+		}
+
+		workingDir := filepath.Join(strings.TrimPrefix(workspace, "file://"), "working-dir")
+		mirrorConfig, err := w.generateMirrorConfig(cfg, clusterDir, w.remoteChannelVersions(ctx, graphOptions(cfg, workingDir, opts), cfg.GetReleaseArchitecture()))
 		if err != nil {
 			return fmt.Errorf("failed to generate mirror config: %v", err)
 		}
+		if err := checkOCICatalogs(cfg, clusterDir); err != nil {
+			return err
+		}
 
 		// 创建临时配置文件
-		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, opts.ClusterName)
+		tempConfigPath, err := w.createTempMirrorConfig(mirrorConfig, clusterDir, opts.ClusterName)
 		if err != nil {
 			return fmt.Errorf("failed to create temporary config file: %v", err)
 		}
@@ -252,24 +436,21 @@ func (w *MirrorWrapper) MirrorDirect(cfg *config.ClusterConfig, workspace, desti
 
 		cmd := cli.NewMirrorCmd(w.log)
 
-		// 如果用户提供了workspace参数，使用用户的，否则使用我们计算的
-		if workspace == "" {
-			workspace = workspaceDir
-		}
-
 		// 设置Command arguments
 		args := []string{
 			"-c", tempConfigPath,
 			"--v2",
-			"-p", strconv.Itoa(int(opts.Port)),
+			"--log-level", w.log.GetLevel(), // 与包装器的日志级别保持一致
+			"-p", strconv.Itoa(port),
 			"--workspace", workspace,
 			"--cache-dir", cacheDir, // 明确指定缓存目录
-			"--src-tls-verify=false",
-			"--dest-tls-verify=false",
 		}
+		args = append(args, tls.MirrorArgs()...)
+		args = append(args, graphArgs(cfg, opts)...)
+		args = append(args, catalogArgs(cfg, opts)...)
 
 		// 添加认证文件参数（如果存在）
-		authFilePath, err := w.setupAuthentication(cfg, opts.ClusterName)
+		authFilePath, err := w.setupAuthentication(cfg, clusterDir)
 		if err != nil {
 			return fmt.Errorf("failed to setup authentication: %v", err)
 		}
@@ -286,16 +467,22 @@ func (w *MirrorWrapper) MirrorDirect(cfg *config.ClusterConfig, workspace, desti
 			args = append(args, "--force")
 		}
 
+		if !opts.FullCopy {
+			args = append(args, "--skip-present")
+		}
+		args = append(args, orderArgs(cfg, opts)...)
+		args = append(args, retryArgs(cfg, opts)...)
+
 		// 添加目标路径
 		args = append(args, destination)
 
 		cmd.SetArgs(args)
 
-		w.log.Debug("Command arguments: %v", args)
+		w.log.Debug("Command arguments: %v", secrets.RedactArgs(args))
 		w.log.Info("💾 Using workspace: %s", workspace)
 		w.log.Info("💾 Using cache: %s", cacheDir)
 
-		err = cmd.Execute()
+		err = cmd.ExecuteContext(ctx)
 		if err != nil {
 			// 检查错误是否提到了部分失败但成功率较高的情况
 			if strings.Contains(err.Error(), "some errors occurred during the mirroring") {
@@ -304,20 +491,55 @@ func (w *MirrorWrapper) MirrorDirect(cfg *config.ClusterConfig, workspace, desti
 				w.log.Warn("   Details: %v", err)
 				w.log.Info("💡 Suggestion: You can choose to ignore individual image failures and continue with subsequent deployment")
 				w.log.Info("   If deployment issues occur later, you can re-run this command to retry failed images")
+				return clierr.New(clierr.Partial, err)
 			}
 			return err
 		}
 
 		w.log.Info("✅ Mirror operation completed")
+		if !opts.DryRun {
+			w.recordReleaseDigest(cfg, clusterDir, workspace)
+			w.publishMirrorMapping(clusterDir, workspace)
+		}
 		return nil
 	}
 
 	// 使用重试机制执行
-	return w.executeWithRetry(executeFunc, workspace, opts)
+	return w.executeWithRetry(ctx, executeFunc, workspace, opts)
 }
 
-// generateMirrorConfig 根据 ocpack 配置生成 oc-mirror 配置
-func (w *MirrorWrapper) generateMirrorConfig(cfg *config.ClusterConfig) (*v2alpha1.ImageSetConfiguration, error) {
+// generateMirrorConfig 根据 ocpack 配置生成 oc-mirror 配置，OCI 目录的相对路径基于 clusterDir。
+// channelVersions 不为空时用它确认目标版本已进入通道，否则自动改用 fast/candidate 通道
+func (w *MirrorWrapper) generateMirrorConfig(cfg *config.ClusterConfig, clusterDir string, channelVersions config.ChannelVersionsFunc) (*v2alpha1.ImageSetConfiguration, error) {
+	if err := config.ValidateReleaseChannel(cfg); err != nil {
+		return nil, err
+	}
+	if err := config.ValidateReleaseRange(cfg); err != nil {
+		return nil, err
+	}
+	if err := config.ValidateTargetNamespace(cfg); err != nil {
+		return nil, err
+	}
+	if namespace := cfg.GetMirrorNamespace(); namespace != "" {
+		w.log.Info("📁 Target namespace: %s", namespace)
+	}
+	minVersion, maxVersion := cfg.GetReleaseRange()
+	channel := cfg.GetMirrorChannel()
+	if channelVersions != nil {
+		resolved, warning, err := cfg.ResolveMirrorChannel(channelVersions)
+		if err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			w.log.Warn("⚠️  %s", warning)
+		}
+		channel = resolved
+	}
+	w.log.Info("📡 Release channel: %s", channel)
+	if minVersion != maxVersion {
+		w.log.Info("🪜 Mirroring shortest upgrade path: %s -> %s", minVersion, maxVersion)
+	}
+
 	mirrorConfig := &v2alpha1.ImageSetConfiguration{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "mirror.openshift.io/v2alpha1",
@@ -327,11 +549,15 @@ func (w *MirrorWrapper) generateMirrorConfig(cfg *config.ClusterConfig) (*v2alph
 			ArchiveSize: 10, // 默认 10GB
 			Mirror: v2alpha1.Mirror{
 				Platform: v2alpha1.Platform{
+					Architectures:     []string{cfg.GetReleaseArchitecture()},
+					Graph:             cfg.SaveImage.Graph,
+					KubeVirtContainer: cfg.KubeVirtContainerEnabled(),
 					Channels: []v2alpha1.ReleaseChannel{
 						{
-							Name:       "stable-" + extractMajorMinorVersion(cfg.ClusterInfo.OpenShiftVersion),
-							MinVersion: cfg.ClusterInfo.OpenShiftVersion,
-							MaxVersion: cfg.ClusterInfo.OpenShiftVersion,
+							Name:         channel,
+							MinVersion:   minVersion,
+							MaxVersion:   maxVersion,
+							ShortestPath: minVersion != maxVersion,
 						},
 					},
 				},
@@ -339,35 +565,65 @@ func (w *MirrorWrapper) generateMirrorConfig(cfg *config.ClusterConfig) (*v2alph
 		},
 	}
 
-	// 添加 Operators 配置（如果启用）
-	if cfg.SaveImage.IncludeOperators && len(cfg.SaveImage.Ops) > 0 {
-		w.log.Info("📦 Including Operator images: %d operators", len(cfg.SaveImage.Ops))
+	if cfg.SaveImage.Graph {
+		w.log.Info("📈 Including Cincinnati graph data image for OpenShift Update Service")
+	}
+	if cfg.IsMultiArch() {
+		w.log.Info("🧬 Mirroring multi-arch release payload for node architectures: %s", strings.Join(cfg.GetArchitectures(), ", "))
+	}
+	if cfg.KubeVirtContainerEnabled() {
+		w.log.Info("💿 Including KubeVirt container boot source image from release payload")
+	}
 
-		// 构建 packages 列表
-		var packages []v2alpha1.IncludePackage
-		for _, opName := range cfg.SaveImage.Ops {
-			packages = append(packages, v2alpha1.IncludePackage{
-				Name: opName,
-				// 可以根据需要添加更多配置，如 channels, minVersion, maxVersion
-			})
+	// 添加 Operators 配置（如果启用），每个目录对应一个 operator 条目
+	if catalogs := cfg.GetOperatorCatalogs(); cfg.OperatorsEnabled() && len(catalogs) > 0 {
+		if err := config.ValidateOperatorCatalogs(cfg); err != nil {
+			return nil, err
 		}
 
-		operator := v2alpha1.Operator{
-			Catalog: cfg.GetOperatorCatalog(),
-			IncludeConfig: v2alpha1.IncludeConfig{
-				Packages: packages,
-			},
-		}
+		for _, catalog := range catalogs {
+			w.log.Info("📦 Including Operator images from %s: %d operators", catalog.Catalog, len(catalog.Ops))
+
+			// 本地 OCI 目录使用绝对路径，oc-mirror 过滤后重新构建目录镜像并按 target_catalog:target_tag 推送
+			catalogRef := catalog.Catalog
+			if catalog.IsOCI() {
+				catalogRef = "oci://" + catalog.OCIPath(clusterDir)
+				path, tag := catalog.MirroredRepository()
+				w.log.Info("📂 Local OCI catalog %s will be pushed as %s:%s", catalog.OCIPath(clusterDir), path, tag)
+			}
 
-		mirrorConfig.ImageSetConfigurationSpec.Mirror.Operators = []v2alpha1.Operator{operator}
+			// 构建 packages 列表
+			var packages []v2alpha1.IncludePackage
+			for _, opName := range catalog.Ops {
+				packages = append(packages, v2alpha1.IncludePackage{
+					Name: opName,
+					// 可以根据需要添加更多配置，如 channels, minVersion, maxVersion
+				})
+			}
+
+			mirrorConfig.ImageSetConfigurationSpec.Mirror.Operators = append(mirrorConfig.ImageSetConfigurationSpec.Mirror.Operators, v2alpha1.Operator{
+				Catalog:       catalogRef,
+				TargetCatalog: catalog.TargetCatalog,
+				TargetTag:     catalog.TargetTag,
+				IncludeConfig: v2alpha1.IncludeConfig{
+					Packages: packages,
+				},
+			})
+		}
 	}
 
 	// 添加额外镜像配置（如果有）
-	if len(cfg.SaveImage.AdditionalImages) > 0 {
-		w.log.Info("📦 Including additional images: %d images", len(cfg.SaveImage.AdditionalImages))
+	if images := cfg.GetAdditionalImages(); len(images) > 0 {
+		w.log.Info("📦 Including additional images: %d images", len(images))
+		if support := cfg.GetSupportImages(); len(support) > 0 {
+			w.log.Info("🩺 Including support images: %s", strings.Join(support, ", "))
+		}
+		if bootSources := cfg.GetCNVBootSourceImages(); len(bootSources) > 0 {
+			w.log.Info("🖥️  Including OpenShift Virtualization boot sources: %s", strings.Join(bootSources, ", "))
+		}
 
 		var additionalImages []v2alpha1.Image
-		for _, imgName := range cfg.SaveImage.AdditionalImages {
+		for _, imgName := range images {
 			additionalImages = append(additionalImages, v2alpha1.Image{
 				Name: imgName,
 			})
@@ -379,10 +635,203 @@ func (w *MirrorWrapper) generateMirrorConfig(cfg *config.ClusterConfig) (*v2alph
 	return mirrorConfig, nil
 }
 
-// createTempMirrorConfig 创建临时的 oc-mirror 配置文件
-func (w *MirrorWrapper) createTempMirrorConfig(config *v2alpha1.ImageSetConfiguration, clusterName string) (string, error) {
+// remoteChannelVersions 从 Cincinnati 查询 arch 架构的 release 在通道中的版本，
+// 查询结果保存在 global.WorkingDir 中，按 graph_cache_ttl 和 --offline-graph 复用
+func (w *MirrorWrapper) remoteChannelVersions(ctx context.Context, global *mirror.GlobalOptions, arch string) config.ChannelVersionsFunc {
+	return func(channel string) ([]string, error) {
+		return release.ChannelVersions(ctx, w.log, global, arch, channel)
+	}
+}
+
+// graphOptions 返回查询通道版本时升级图缓存的选项，与传递给 oc-mirror 的 graphArgs 一致
+func graphOptions(cfg *config.ClusterConfig, workingDir string, opts *MirrorOptions) *mirror.GlobalOptions {
+	return &mirror.GlobalOptions{
+		WorkingDir:    workingDir,
+		GraphCacheTTL: cfg.GetGraphCacheTTL(),
+		OfflineGraph:  opts.OfflineGraph,
+	}
+}
+
+// graphArgs 返回 oc-mirror 查询升级图时复用工作目录中已保存结果的参数
+func graphArgs(cfg *config.ClusterConfig, opts *MirrorOptions) []string {
+	args := []string{"--graph-cache-ttl", cfg.GetGraphCacheTTL().String()}
+	if opts.OfflineGraph {
+		args = append(args, "--offline-graph")
+	}
+	return args
+}
+
+// catalogArgs 返回 oc-mirror 复用工作目录中已下载的 Operator 目录的参数
+func catalogArgs(cfg *config.ClusterConfig, opts *MirrorOptions) []string {
+	args := []string{"--catalog-cache-ttl", cfg.GetCatalogCacheTTL().String()}
+	if opts.RefreshCatalog {
+		args = append(args, "--refresh-catalog")
+	}
+	if opts.OfflineCatalog {
+		args = append(args, "--offline-catalog")
+	}
+	return args
+}
+
+// orderArgs 返回镜像分组的复制顺序 ([save_image] mirror_order) 和 --only 对应的 oc-mirror 参数
+func orderArgs(cfg *config.ClusterConfig, opts *MirrorOptions) []string {
+	var args []string
+	if len(cfg.SaveImage.MirrorOrder) > 0 {
+		args = append(args, "--image-order", strings.Join(cfg.SaveImage.MirrorOrder, ","))
+	}
+	if len(opts.Only) > 0 {
+		args = append(args, "--only", strings.Join(opts.Only, ","))
+	}
+	return args
+}
+
+// retryArgs 返回单个镜像复制失败时的重试策略 ([save_image.retry]) 对应的 oc-mirror 参数，
+// --copy-attempts 和 --copy-backoff 优先于配置文件
+func retryArgs(cfg *config.ClusterConfig, opts *MirrorOptions) []string {
+	retry := cfg.SaveImage.Retry
+	if opts.CopyAttempts > 0 {
+		retry.Attempts = opts.CopyAttempts
+	}
+	if opts.CopyBackoff != "" {
+		retry.Backoff = opts.CopyBackoff
+	}
+
+	var args []string
+	if retry.Attempts > 0 {
+		args = append(args, "--copy-attempts", strconv.Itoa(retry.Attempts))
+	}
+	if retry.Backoff != "" {
+		args = append(args, "--copy-backoff", retry.Backoff)
+	}
+	if delay := retry.GetDelay(); delay > 0 {
+		args = append(args, "--copy-retry-delay", delay.String())
+	}
+	if retry.Jitter {
+		args = append(args, "--copy-retry-jitter")
+	}
+	if len(retry.NonRetryableErrors) > 0 {
+		args = append(args, "--non-retryable-errors", strings.Join(retry.NonRetryableErrors, ","))
+	}
+	if len(retry.RetryableErrors) > 0 {
+		args = append(args, "--retryable-errors", strings.Join(retry.RetryableErrors, ","))
+	}
+	return args
+}
+
+// includes 判断 --only 是否包含 group，未指定 --only 时包含全部分组
+func (opts *MirrorOptions) includes(group string) bool {
+	return len(opts.Only) == 0 || slices.Contains(opts.Only, group)
+}
+
+// onlyRelease 判断 --only 是否只包含 release
+func (opts *MirrorOptions) onlyRelease() bool {
+	return len(opts.Only) == 1 && opts.Only[0] == config.MirrorGroupRelease
+}
+
+// localChannelVersions 从 mirror-to-disk 保存在归档工作目录中的 graph 数据读取通道中的版本，
+// 保证 disk-to-mirror 离线时选出与保存镜像时相同的通道
+func (w *MirrorWrapper) localChannelVersions(ctx context.Context, source, arch string) config.ChannelVersionsFunc {
+	workingDir := filepath.Join(strings.TrimPrefix(source, "file://"), "working-dir")
+	return func(channel string) ([]string, error) {
+		return release.LocalChannelVersions(ctx, w.log, workingDir, arch, channel)
+	}
+}
+
+// applyUpdateURLOverride 通过 UPDATE_URL_OVERRIDE 将 update_url_override 传递给 oc-mirror，
+// 查询通道版本和升级路径时使用该地址。未配置时保留用户已导出的环境变量
+func (w *MirrorWrapper) applyUpdateURLOverride(cfg *config.ClusterConfig) error {
+	if err := config.ValidateUpdateURLOverride(cfg); err != nil {
+		return err
+	}
+	override := cfg.SaveImage.UpdateURLOverride
+	if override == "" {
+		return nil
+	}
+	w.log.Info("🔒 Using update graph endpoint: %s (%s)", override, config.UpdateURLOverrideEnv)
+	if cfg.SaveImage.Graph {
+		w.log.Info("📈 Graph image is reused from the cache or working-dir instead of being rebuilt")
+	}
+	return os.Setenv(config.UpdateURLOverrideEnv, override)
+}
+
+// applyTrustBundle 准备复制镜像时的 TLS 配置 (见 registrytls)，全部仓库默认校验证书。查询升级图和下载 release 签名的
+// HTTP 客户端信任同一组 CA，save_image.registry_tls 中的 insecure 仓库通过 --registries-conf 传递给 oc-mirror，
+// 不修改进程的环境变量。返回的配置同样用于 oc adm release mirror
+func (w *MirrorWrapper) applyTrustBundle(cfg *config.ClusterConfig, clusterDir string) (*registrytls.Policy, error) {
+	policy, err := registrytls.Prepare(cfg, clusterDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare registry TLS: %v", err)
+	}
+	bundle := policy.Bundle
+	for _, warning := range bundle.Warnings {
+		w.log.Warn("⚠️  %s", warning)
+	}
+	release.AdditionalTrustBundle = bundle.PEM()
+	if !bundle.Empty() {
+		w.log.Info("🔐 Trusting %d additional CA certificate(s) from %s", len(bundle.Certificates), strings.Join(bundle.Sources, ", "))
+	}
+	if policy.RegistriesConf != "" {
+		w.log.Warn("⚠️  TLS verification disabled for: %s (save_image.registry_tls)", strings.Join(policy.Insecure, ", "))
+	}
+	return policy, nil
+}
+
+// recordReleaseDigest 从 oc-mirror 工作目录的 release 签名中找到 openshift_version 的镜像摘要并记录到集群状态，
+// 之后 generate-iso 和 setup-pxe 固定安装该摘要，即使仓库中的标签被重新推送也不受影响。
+// workspaces 为 oc-mirror 的 file:// 目标、来源或工作空间，按顺序查找；找不到摘要只提示，不影响镜像结果
+func (w *MirrorWrapper) recordReleaseDigest(cfg *config.ClusterConfig, clusterDir string, workspaces ...string) {
+	version := cfg.ClusterInfo.OpenShiftVersion
+	for _, workspace := range workspaces {
+		workingDir := filepath.Join(strings.TrimPrefix(workspace, "file://"), "working-dir")
+		digest, err := ocworkspace.ReleaseDigest(workingDir, version)
+		if err != nil {
+			w.log.Warn("⚠️  Failed to determine release %s digest: %v", version, err)
+			return
+		}
+		if digest == "" {
+			continue
+		}
+		if err := kubeconfig.RecordRelease(clusterDir, version, digest); err != nil {
+			w.log.Warn("⚠️  Failed to record release digest: %v", err)
+			return
+		}
+		w.log.Info("📌 Pinned release %s to %s", version, digest)
+		return
+	}
+	w.log.Warn("⚠️  No release signature for %s found, installs will use the release tag", version)
+}
+
+// publishMirrorMapping 将本次复制到私有仓库的镜像合并到集群目录的 mirror-mapping.json
+func (w *MirrorWrapper) publishMirrorMapping(clusterDir, workspace string) {
+	workingDir := filepath.Join(strings.TrimPrefix(workspace, "file://"), "working-dir")
+	count, err := mirrormap.Publish(workingDir, clusterDir)
+	if err != nil {
+		w.log.Warn("⚠️  Failed to record mirror mapping: %v", err)
+		return
+	}
+	w.log.Info("📒 Mirror mapping of %d images written to %s", count, mirrormap.Path(clusterDir))
+}
+
+// checkOCICatalogs 检查启用的本地 OCI 目录是否存在。disk-to-mirror 时 oc-mirror 使用归档中的目录，不需要检查
+func checkOCICatalogs(cfg *config.ClusterConfig, clusterDir string) error {
+	if !cfg.OperatorsEnabled() {
+		return nil
+	}
+	for _, catalog := range cfg.GetOperatorCatalogs() {
+		if !catalog.IsOCI() {
+			continue
+		}
+		if err := catalog.CheckOCILayout(clusterDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTempMirrorConfig 创建临时的 oc-mirror 配置文件，OCI 目录的相对路径基于 clusterDir，tempName 用于区分临时目录
+func (w *MirrorWrapper) createTempMirrorConfig(config *v2alpha1.ImageSetConfiguration, clusterDir, tempName string) (string, error) {
 	// 创建临时目录
-	tempDir := filepath.Join(os.TempDir(), "ocpack-mirror", clusterName)
+	tempDir := filepath.Join(os.TempDir(), "ocpack-mirror", tempName)
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temporary directory: %v", err)
 	}
@@ -390,81 +839,53 @@ func (w *MirrorWrapper) createTempMirrorConfig(config *v2alpha1.ImageSetConfigur
 	// 创建配置文件路径
 	configPath := filepath.Join(tempDir, "mirror-config.yaml")
 
-	// 这里需要将配置序列化为 YAML
-	// 暂时使用简化的配置生成
-	configContent := w.generateConfigYAML(config)
+	configContent, err := w.generateConfigYAML(config, clusterDir)
+	if err != nil {
+		return "", err
+	}
 
 	// 写入文件
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
-		return "", fmt.Errorf("写入配置文件失败: %v", err)
+		return "", i18n.Errorf("写入配置文件失败: %v", err)
 	}
 
-	w.log.Debug("临时配置文件创建: %s", configPath)
-	w.log.Debug("配置文件内容:\n%s", configContent)
+	w.log.Debug(i18n.T("临时配置文件创建: %s"), configPath)
+	w.log.Debug(i18n.T("配置文件内容:\n%s"), configContent)
 	return configPath, nil
 }
 
-// generateConfigYAML 生成 YAML 配置内容
-func (w *MirrorWrapper) generateConfigYAML(config *v2alpha1.ImageSetConfiguration) string {
-	yaml := fmt.Sprintf(`apiVersion: %s
-kind: %s
-mirror:
-  platform:
-    channels:
-`, config.APIVersion, config.Kind)
-
-	// 添加平台通道
-	for _, channel := range config.ImageSetConfigurationSpec.Mirror.Platform.Channels {
-		yaml += fmt.Sprintf(`    - name: %s
-      minVersion: %s
-      maxVersion: %s
-`, channel.Name, channel.MinVersion, channel.MaxVersion)
-	}
-
-	// 添加额外镜像
-	if len(config.ImageSetConfigurationSpec.Mirror.AdditionalImages) > 0 {
-		yaml += "  additionalImages:\n"
-		for _, img := range config.ImageSetConfigurationSpec.Mirror.AdditionalImages {
-			yaml += fmt.Sprintf("    - name: %s\n", img.Name)
-		}
-	}
-
-	// 添加 Operator
-	if len(config.ImageSetConfigurationSpec.Mirror.Operators) > 0 {
-		yaml += "  operators:\n"
-		for _, op := range config.ImageSetConfigurationSpec.Mirror.Operators {
-			yaml += fmt.Sprintf("    - catalog: %s\n", op.Catalog)
-			if len(op.Packages) > 0 {
-				yaml += "      packages:\n"
-				for _, pkg := range op.Packages {
-					yaml += fmt.Sprintf("        - name: %s\n", pkg.Name)
-				}
-			}
-		}
+// generateConfigYAML 渲染 imageset-config.yaml 模板生成 YAML 配置内容，
+// clusterDir 下存在自定义模板时优先使用
+func (w *MirrorWrapper) generateConfigYAML(config *v2alpha1.ImageSetConfiguration, clusterDir string) (string, error) {
+	tmplContent, source, err := utils.ReadTemplate(clusterDir, templates, imageSetConfigTemplate)
+	if err != nil {
+		return "", err
+	}
+	if source != "" {
+		w.log.Info("📋 Using custom template: %s", source)
 	}
 
-	return yaml
-}
+	tmpl, err := template.New(filepath.Base(imageSetConfigTemplate)).Parse(string(tmplContent))
+	if err != nil {
+		return "", i18n.Errorf("解析模板 %s 失败: %v", imageSetConfigTemplate, err)
+	}
 
-// extractMajorMinorVersion 从版本字符串中提取主版本号和次版本号
-func extractMajorMinorVersion(version string) string {
-	// 简单的版本提取，例如 "4.16.0" -> "4.16"
-	parts := strings.Split(version, ".")
-	if len(parts) >= 2 {
-		return parts[0] + "." + parts[1]
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, config); err != nil {
+		return "", i18n.Errorf("渲染模板 %s 失败: %v", imageSetConfigTemplate, err)
 	}
-	return version
+	return buf.String(), nil
 }
 
 // parseErrorLogFile 解析错误日志文件，提取失败的镜像
 func (w *MirrorWrapper) parseErrorLogFile(logFilePath string) ([]string, error) {
 	if _, err := os.Stat(logFilePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("错误日志文件不存在: %s", logFilePath)
+		return nil, i18n.Errorf("错误日志文件不存在: %s", logFilePath)
 	}
 
 	file, err := os.Open(logFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("无法打开错误日志文件: %v", err)
+		return nil, i18n.Errorf("无法打开错误日志文件: %v", err)
 	}
 	defer file.Close()
 
@@ -483,7 +904,7 @@ func (w *MirrorWrapper) parseErrorLogFile(logFilePath string) ([]string, error)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取错误日志文件失败: %v", err)
+		return nil, i18n.Errorf("读取错误日志文件失败: %v", err)
 	}
 
 	// 去重
@@ -503,12 +924,12 @@ func (w *MirrorWrapper) parseErrorLogFile(logFilePath string) ([]string, error)
 func (w *MirrorWrapper) findLatestErrorLog(workingDir string) (string, error) {
 	logsDir := filepath.Join(workingDir, "logs")
 	if _, err := os.Stat(logsDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("日志目录不存在: %s", logsDir)
+		return "", i18n.Errorf("日志目录不存在: %s", logsDir)
 	}
 
 	files, err := os.ReadDir(logsDir)
 	if err != nil {
-		return "", fmt.Errorf("无法读取日志目录: %v", err)
+		return "", i18n.Errorf("无法读取日志目录: %v", err)
 	}
 
 	var latestFile string
@@ -528,16 +949,15 @@ func (w *MirrorWrapper) findLatestErrorLog(workingDir string) (string, error) {
 	}
 
 	if latestFile == "" {
-		return "", fmt.Errorf("未找到错误日志文件")
+		return "", i18n.Errorf("未找到错误日志文件")
 	}
 
 	return latestFile, nil
 }
 
-// createRetryConfig 为重试创建特殊的配置文件，只包含失败的镜像
-func (w *MirrorWrapper) createRetryConfig(cfg *config.ClusterConfig, failedImages []string, clusterName string) (string, error) {
-	// 创建只包含失败镜像的配置
-	retryConfig := &v2alpha1.ImageSetConfiguration{
+// additionalImagesConfig 生成只包含指定镜像的配置，不包含 release 和 Operator
+func additionalImagesConfig(images []string) *v2alpha1.ImageSetConfiguration {
+	mirrorConfig := &v2alpha1.ImageSetConfiguration{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "mirror.openshift.io/v2alpha1",
 			Kind:       "ImageSetConfiguration",
@@ -550,19 +970,22 @@ func (w *MirrorWrapper) createRetryConfig(cfg *config.ClusterConfig, failedImage
 		},
 	}
 
-	// 将失败的镜像添加为额外镜像
-	for _, imgName := range failedImages {
-		retryConfig.ImageSetConfigurationSpec.Mirror.AdditionalImages = append(
-			retryConfig.ImageSetConfigurationSpec.Mirror.AdditionalImages,
+	for _, imgName := range images {
+		mirrorConfig.ImageSetConfigurationSpec.Mirror.AdditionalImages = append(
+			mirrorConfig.ImageSetConfigurationSpec.Mirror.AdditionalImages,
 			v2alpha1.Image{Name: imgName},
 		)
 	}
+	return mirrorConfig
+}
 
-	return w.createTempMirrorConfig(retryConfig, clusterName+"-retry")
+// createRetryConfig 为重试创建特殊的配置文件，只包含失败的镜像
+func (w *MirrorWrapper) createRetryConfig(cfg *config.ClusterConfig, failedImages []string, clusterDir string) (string, error) {
+	return w.createTempMirrorConfig(additionalImagesConfig(failedImages), clusterDir, filepath.Base(clusterDir)+"-retry")
 }
 
-// executeWithRetry 执行带重试的镜像操作
-func (w *MirrorWrapper) executeWithRetry(executeFunc func() error, workingDir string, opts *MirrorOptions) error {
+// executeWithRetry 执行带重试的镜像操作，ctx 结束后不再重试
+func (w *MirrorWrapper) executeWithRetry(ctx context.Context, executeFunc func() error, workingDir string, opts *MirrorOptions) error {
 	if !opts.EnableRetry {
 		return executeFunc()
 	}
@@ -594,123 +1017,86 @@ func (w *MirrorWrapper) executeWithRetry(executeFunc func() error, workingDir st
 
 		lastErr = err
 
-		// 检查是否包含部分失败的提示 - 如果已经有高成功率，不需要重试
-		if strings.Contains(err.Error(), "some errors occurred during the mirroring") {
+		// 操作被取消或超时，重试也会立即失败
+		if ctx.Err() != nil {
+			return err
+		}
+
+		// 部分镜像失败时成功率已经较高，不需要重试，返回 clierr.Partial 错误让调用方以部分成功的退出码结束
+		if clierr.CategoryOf(err) == clierr.Partial {
 			w.log.Info("✅ Mirror operation partially successful with high success rate, no retry needed")
-			return nil
+			return err
 		}
 
 		// 如果还有重试机会，尝试重试失败的镜像
 		if attempt < maxRetries {
 			w.log.Warn("❌ Mirror operation failed: %v", err)
 			w.log.Info("⏰ Waiting %d seconds before retry...", retryInterval)
-			time.Sleep(time.Duration(retryInterval) * time.Second)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w (%w)", err, context.Cause(ctx))
+			case <-time.After(time.Duration(retryInterval) * time.Second):
+			}
 		}
 	}
 
 	w.log.Error("❌ Mirror operation failed after %d retries", maxRetries)
-	return fmt.Errorf("mirror operation failed after %d retries: %v", maxRetries, lastErr)
+	return fmt.Errorf("mirror operation failed after %d retries: %w", maxRetries, lastErr)
 }
 
 // setupAuthentication 设置认证配置
-func (w *MirrorWrapper) setupAuthentication(cfg *config.ClusterConfig, clusterName string) (string, error) {
-	// 获取当前工作目录
-	workingDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get working directory: %v", err)
-	}
-
-	clusterDir := filepath.Join(workingDir, clusterName)
-	pullSecretPath := filepath.Join(clusterDir, "pull-secret.txt")
-	mergedAuthPath := filepath.Join(clusterDir, "registry", "merged-auth.json")
-
+func (w *MirrorWrapper) setupAuthentication(cfg *config.ClusterConfig, clusterDir string) (string, error) {
 	// 检查 pull-secret.txt 是否存在
-	if _, err := os.Stat(pullSecretPath); os.IsNotExist(err) {
-		w.log.Warn("⚠️  pull-secret.txt 不存在，将使用默认认证配置")
+	if _, err := os.Stat(auth.PullSecretPath(clusterDir)); os.IsNotExist(err) {
+		w.log.Warn(i18n.T("⚠️  pull-secret.txt 不存在，将使用默认认证配置"))
 		return "", nil
 	}
 
-	// 检查合并认证文件是否已存在
-	if _, err := os.Stat(mergedAuthPath); err == nil {
-		w.log.Info("ℹ️  Using existing authentication configuration: %s", mergedAuthPath)
-		return mergedAuthPath, nil
-	}
-
-	// 创建合并认证配置
-	w.log.Info("🔐 创建合并的认证配置文件...")
-
-	pullSecretContent, err := os.ReadFile(pullSecretPath)
-	if err != nil {
-		return "", fmt.Errorf("读取 pull-secret.txt 失败: %v", err)
-	}
-
-	var pullSecretData map[string]interface{}
-	if err := json.Unmarshal(pullSecretContent, &pullSecretData); err != nil {
-		return "", fmt.Errorf("解析 pull-secret.txt JSON 失败: %v", err)
-	}
-
-	auths, ok := pullSecretData["auths"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("pull-secret.txt 格式无效: 缺少 'auths' 字段")
-	}
-
-	// 添加私有仓库认证信息
-	registryHostname := fmt.Sprintf("registry.%s.%s", cfg.ClusterInfo.ClusterID, cfg.ClusterInfo.Domain)
-	registryURL := fmt.Sprintf("%s:8443", registryHostname)
-	authString := fmt.Sprintf("%s:ztesoft123", cfg.Registry.RegistryUser)
-	authBase64 := base64.StdEncoding.EncodeToString([]byte(authString))
-
-	auths[registryURL] = map[string]interface{}{
-		"auth":  authBase64,
-		"email": "user@example.com",
-	}
-
-	mergedAuthContent, err := json.MarshalIndent(pullSecretData, "", "  ")
+	// 每次都重新合并，config.toml 中的仓库认证变化后会自动更新
+	mergedAuthPath, changed, err := auth.EnsureMergedAuth(clusterDir, cfg)
 	if err != nil {
-		return "", fmt.Errorf("序列化合并后的认证配置失败: %v", err)
+		return "", i18n.Errorf("创建合并认证配置失败: %v", err)
 	}
-
-	// 创建registry目录
-	registryDir := filepath.Dir(mergedAuthPath)
-	if err := os.MkdirAll(registryDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create registry directory: %v", err)
-	}
-
-	// 保存合并认证文件
-	if err := os.WriteFile(mergedAuthPath, mergedAuthContent, 0600); err != nil {
-		return "", fmt.Errorf("保存合并后的认证配置失败: %v", err)
+	if changed {
+		w.log.Info(i18n.T("✅ 认证配置已保存到: %s"), mergedAuthPath)
+	} else {
+		w.log.Info("ℹ️  Using existing authentication configuration: %s", mergedAuthPath)
 	}
 
-	w.log.Info("✅ 认证配置已保存到: %s", mergedAuthPath)
-
 	// 尝试设置CA证书信任（非阻塞）
-	caCertPath := filepath.Join(clusterDir, "registry", "*.pem")
+	caCertPath := filepath.Join(clusterDir, auth.RegistryDirName, "*.pem")
 	if matches, err := filepath.Glob(caCertPath); err == nil && len(matches) > 0 {
-		w.log.Info("ℹ️  检测到CA证书文件，建议手动配置证书信任")
-		w.log.Info("   CA证书路径: %s", matches[0])
+		w.log.Info(i18n.T("ℹ️  检测到CA证书文件，建议手动配置证书信任"))
+		w.log.Info(i18n.T("   CA证书路径: %s"), matches[0])
 		// 这里可以添加自动配置CA证书的逻辑，但通常需要root权限
 	}
 
 	return mergedAuthPath, nil
 }
 
-// setupWorkspaceAndCache 设置工作空间和缓存目录，避免使用默认的 $HOME/.oc-mirror
-func (w *MirrorWrapper) setupWorkspaceAndCache(cfg *config.ClusterConfig, clusterName string) (string, string, error) {
-	// 获取当前工作目录，确保在当前项目目录内创建文件
+// clusterDir 返回集群目录：设置了 ClusterDir 时直接使用，否则为当前目录下的 ClusterName
+func (opts *MirrorOptions) clusterDir(cfg *config.ClusterConfig) (string, error) {
+	if opts.ClusterDir != "" {
+		return filepath.Abs(opts.ClusterDir)
+	}
+	return resolveClusterDir(cfg, opts.ClusterName)
+}
+
+// resolveClusterDir 返回当前目录下的集群目录。使用命令行中的集群名称 (即目录名)，为空时使用 cluster_id，
+// 与 save-image、load-image 读取配置和镜像归档的目录一致，目录名与 cluster_id 不同时也不会写到其他目录
+func resolveClusterDir(cfg *config.ClusterConfig, clusterName string) (string, error) {
 	currentDir, err := os.Getwd()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get current working directory: %v", err)
+		return "", fmt.Errorf("failed to get current working directory: %v", err)
 	}
-
-	// 确定集群目录，优先使用配置中的 ClusterID，否则使用传入的 clusterName
-	clusterID := cfg.ClusterInfo.ClusterID
-	if clusterID == "" {
-		clusterID = clusterName
+	if clusterName == "" {
+		clusterName = cfg.ClusterInfo.ClusterID
 	}
+	return filepath.Join(currentDir, clusterName), nil
+}
 
-	// 构建完整的集群目录路径
-	clusterDir := filepath.Join(currentDir, clusterID)
-
+// setupWorkspaceAndCache 在集群目录中设置工作空间和缓存目录，避免使用默认的 $HOME/.oc-mirror
+func (w *MirrorWrapper) setupWorkspaceAndCache(clusterDir string) (string, string, error) {
 	// 设置工作空间目录（在集群目录内）
 	workspaceDir := filepath.Join(clusterDir, "images", "working-dir")
 	if err := os.MkdirAll(workspaceDir, 0755); err != nil {
@@ -748,19 +1134,10 @@ func (w *MirrorWrapper) setupWorkspaceAndCache(cfg *config.ClusterConfig, cluste
 
 // CleanCache 清理指定集群的缓存目录
 func (w *MirrorWrapper) CleanCache(cfg *config.ClusterConfig, clusterName string) error {
-	// 获取当前工作目录
-	currentDir, err := os.Getwd()
+	clusterDir, err := resolveClusterDir(cfg, clusterName)
 	if err != nil {
-		return fmt.Errorf("failed to get current working directory: %v", err)
-	}
-
-	// 确定集群目录
-	clusterID := cfg.ClusterInfo.ClusterID
-	if clusterID == "" {
-		clusterID = clusterName
+		return err
 	}
-
-	clusterDir := filepath.Join(currentDir, clusterID)
 	cacheDir := filepath.Join(clusterDir, "images", "cache")
 
 	// 检查缓存目录是否存在
@@ -789,21 +1166,26 @@ func (w *MirrorWrapper) CleanCache(cfg *config.ClusterConfig, clusterName string
 	return nil
 }
 
-// GetCacheInfo 获取缓存信息，包括大小和位置
-func (w *MirrorWrapper) GetCacheInfo(cfg *config.ClusterConfig, clusterName string) (map[string]interface{}, error) {
-	// 获取当前工作目录
-	currentDir, err := os.Getwd()
+// PruneCache 按 blob 的最近访问时间清理指定集群的缓存，使其不超过 maxSize 字节，返回删除的内容
+func (w *MirrorWrapper) PruneCache(cfg *config.ClusterConfig, clusterName string, maxSize int64, dryRun bool) (*ocworkspace.CacheResult, error) {
+	clusterDir, err := resolveClusterDir(cfg, clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current working directory: %v", err)
+		return nil, err
 	}
-
-	// 确定集群目录
-	clusterID := cfg.ClusterInfo.ClusterID
-	if clusterID == "" {
-		clusterID = clusterName
+	cacheDir := ocworkspace.CacheDir(clusterDir)
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		return &ocworkspace.CacheResult{}, nil
 	}
+	return ocworkspace.PruneCache(cacheDir, maxSize, dryRun)
+}
 
-	clusterDir := filepath.Join(currentDir, clusterID)
+// GetCacheInfo 获取缓存信息，包括大小和位置
+func (w *MirrorWrapper) GetCacheInfo(cfg *config.ClusterConfig, clusterName string) (map[string]interface{}, error) {
+	clusterDir, err := resolveClusterDir(cfg, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	clusterID := filepath.Base(clusterDir)
 	cacheDir := filepath.Join(clusterDir, "images", "cache")
 	workspaceDir := filepath.Join(clusterDir, "images", "working-dir")
 
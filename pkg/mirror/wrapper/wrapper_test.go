@@ -0,0 +1,308 @@
+package wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+func TestGenerateConfigYAMLPlatformOptions(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		graph             bool
+		kubeVirtContainer bool
+		expected          []string
+		unexpected        []string
+	}{
+		{
+			name:       "默认不包含 graph 和 kubeVirtContainer",
+			unexpected: []string{"graph: true", "kubeVirtContainer: true"},
+		},
+		{
+			name:     "启用 graph",
+			graph:    true,
+			expected: []string{"  platform:\n", "    graph: true\n    channels:\n"},
+		},
+		{
+			name:              "同时启用 graph 和 kubeVirtContainer",
+			graph:             true,
+			kubeVirtContainer: true,
+			expected:          []string{"    graph: true\n    kubeVirtContainer: true\n    channels:\n"},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := config.NewDefaultConfig("demo")
+		cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+		cfg.SaveImage.Graph = test.graph
+		cfg.SaveImage.KubeVirtContainer = test.kubeVirtContainer
+
+		mirrorConfig, err := w.generateMirrorConfig(cfg, "", nil)
+		if err != nil {
+			t.Fatalf("%s: generateMirrorConfig() error = %v", test.name, err)
+		}
+		yaml, err := w.generateConfigYAML(mirrorConfig, "")
+		if err != nil {
+			t.Fatalf("%s: generateConfigYAML() error = %v", test.name, err)
+		}
+
+		if !strings.Contains(yaml, "    - name: stable-4.16\n      minVersion: 4.16.3\n      maxVersion: 4.16.3\n") {
+			t.Errorf("%s: channel not rendered correctly:\n%s", test.name, yaml)
+		}
+		for _, s := range test.expected {
+			if !strings.Contains(yaml, s) {
+				t.Errorf("%s: expected %q in:\n%s", test.name, s, yaml)
+			}
+		}
+		for _, s := range test.unexpected {
+			if strings.Contains(yaml, s) {
+				t.Errorf("%s: unexpected %q in:\n%s", test.name, s, yaml)
+			}
+		}
+	}
+}
+
+func TestGenerateConfigYAMLOperatorCatalogs(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.IncludeOperators = true
+	cfg.SaveImage.OperatorCatalogs = []config.OperatorCatalog{
+		{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14", Ops: []string{"cluster-logging"}},
+		{
+			Catalog:       "registry.redhat.io/redhat/certified-operator-index:v4.14",
+			TargetCatalog: "certified/operator-index",
+			TargetTag:     "latest",
+			Ops:           []string{"gpu-operator-certified"},
+		},
+		{Catalog: "community", Ops: []string{"prometheus"}},
+	}
+
+	mirrorConfig, err := w.generateMirrorConfig(cfg, "", nil)
+	if err != nil {
+		t.Fatalf("generateMirrorConfig() error = %v", err)
+	}
+	yaml, err := w.generateConfigYAML(mirrorConfig, "")
+	if err != nil {
+		t.Fatalf("generateConfigYAML() error = %v", err)
+	}
+
+	expected := `  operators:
+    - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.14
+      packages:
+        - name: cluster-logging
+    - catalog: registry.redhat.io/redhat/certified-operator-index:v4.14
+      targetCatalog: certified/operator-index
+      targetTag: latest
+      packages:
+        - name: gpu-operator-certified
+    - catalog: registry.redhat.io/redhat/community-operator-index:v4.14
+      packages:
+        - name: prometheus
+`
+	if !strings.Contains(yaml, expected) {
+		t.Errorf("operators not rendered correctly, expected %q in:\n%s", expected, yaml)
+	}
+
+	// 重复的 CatalogSource 名称应报错
+	cfg.SaveImage.OperatorCatalogs[1].CatalogSourceName = "redhat-operators"
+	if _, err := w.generateMirrorConfig(cfg, "", nil); err == nil {
+		t.Error("expected error for duplicate catalog source names")
+	}
+}
+
+func TestGenerateMirrorConfigChannel(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.ClusterInfo.Channel = "eus"
+	mirrorConfig, err := w.generateMirrorConfig(cfg, "", nil)
+	if err != nil {
+		t.Fatalf("generateMirrorConfig() error = %v", err)
+	}
+	if name := mirrorConfig.Mirror.Platform.Channels[0].Name; name != "eus-4.16" {
+		t.Errorf("channel = %q, expected eus-4.16", name)
+	}
+
+	cfg.ClusterInfo.OpenShiftVersion = "4.15.2"
+	if _, err := w.generateMirrorConfig(cfg, "", nil); err == nil {
+		t.Error("expected error for eus channel on odd minor version")
+	}
+}
+
+func TestApplyUpdateURLOverride(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+	t.Setenv(config.UpdateURLOverrideEnv, "")
+
+	cfg := config.NewDefaultConfig("demo")
+	if err := w.applyUpdateURLOverride(cfg); err != nil {
+		t.Fatalf("applyUpdateURLOverride() error = %v", err)
+	}
+	if got := os.Getenv(config.UpdateURLOverrideEnv); got != "" {
+		t.Errorf("%s = %q, expected unset without update_url_override", config.UpdateURLOverrideEnv, got)
+	}
+
+	cfg.SaveImage.UpdateURLOverride = "https://osus.example.com/api/upgrades_info/graph"
+	if err := w.applyUpdateURLOverride(cfg); err != nil {
+		t.Fatalf("applyUpdateURLOverride() error = %v", err)
+	}
+	if got := os.Getenv(config.UpdateURLOverrideEnv); got != cfg.SaveImage.UpdateURLOverride {
+		t.Errorf("%s = %q, expected %q", config.UpdateURLOverrideEnv, got, cfg.SaveImage.UpdateURLOverride)
+	}
+
+	cfg.SaveImage.UpdateURLOverride = "osus.example.com"
+	if err := w.applyUpdateURLOverride(cfg); err == nil {
+		t.Error("expected error for update_url_override without scheme")
+	}
+}
+
+func TestGenerateConfigYAMLReleaseRange(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.14.10"
+	cfg.SaveImage.OpenShiftVersionMax = "4.16.3"
+	mirrorConfig, err := w.generateMirrorConfig(cfg, "", nil)
+	if err != nil {
+		t.Fatalf("generateMirrorConfig() error = %v", err)
+	}
+	yaml, err := w.generateConfigYAML(mirrorConfig, "")
+	if err != nil {
+		t.Fatalf("generateConfigYAML() error = %v", err)
+	}
+
+	expected := "    - name: stable-4.16\n      minVersion: 4.14.10\n      maxVersion: 4.16.3\n      shortestPath: true\n"
+	if !strings.Contains(yaml, expected) {
+		t.Errorf("expected %q in:\n%s", expected, yaml)
+	}
+}
+
+func TestGenerateConfigYAMLOCICatalog(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.IncludeOperators = true
+	cfg.SaveImage.OperatorCatalogs = []config.OperatorCatalog{
+		{Catalog: "oci://catalogs/my-index", Ops: []string{"my-operator"}},
+	}
+
+	mirrorConfig, err := w.generateMirrorConfig(cfg, clusterDir, nil)
+	if err != nil {
+		t.Fatalf("generateMirrorConfig() error = %v", err)
+	}
+	yaml, err := w.generateConfigYAML(mirrorConfig, "")
+	if err != nil {
+		t.Fatalf("generateConfigYAML() error = %v", err)
+	}
+
+	// 相对路径基于集群目录展开，未设置 target_tag 时使用 v4.x 标签
+	expected := "    - catalog: oci://" + filepath.Join(clusterDir, "catalogs", "my-index") + "\n      targetTag: v4.16\n"
+	if !strings.Contains(yaml, expected) {
+		t.Errorf("expected %q in:\n%s", expected, yaml)
+	}
+
+	if err := checkOCICatalogs(cfg, clusterDir); err == nil {
+		t.Error("expected error for missing OCI layout")
+	}
+}
+
+func TestGenerateConfigYAMLAdditionalImagesOnly(t *testing.T) {
+	w, err := NewMirrorWrapper("error")
+	if err != nil {
+		t.Fatalf("NewMirrorWrapper() error = %v", err)
+	}
+
+	yaml, err := w.generateConfigYAML(additionalImagesConfig([]string{"quay.io/acme/app:v1", "quay.io/acme/web:v2"}), "")
+	if err != nil {
+		t.Fatalf("generateConfigYAML() error = %v", err)
+	}
+
+	want := "mirror:\n  additionalImages:\n    - name: quay.io/acme/app:v1\n    - name: quay.io/acme/web:v2\n"
+	if !strings.Contains(yaml, want) {
+		t.Errorf("expected %q in:\n%s", want, yaml)
+	}
+	for _, s := range []string{"platform:", "channels:", "operators:"} {
+		if strings.Contains(yaml, s) {
+			t.Errorf("unexpected %q in:\n%s", s, yaml)
+		}
+	}
+}
+
+func TestCatalogArgs(t *testing.T) {
+	cfg := &config.ClusterConfig{}
+	if got := strings.Join(catalogArgs(cfg, &MirrorOptions{}), " "); got != "--catalog-cache-ttl 24h0m0s" {
+		t.Errorf("catalogArgs() = %q, expected the default ttl", got)
+	}
+
+	cfg.SaveImage.CatalogCacheTTL = "0"
+	want := "--catalog-cache-ttl 0s --offline-catalog"
+	if got := strings.Join(catalogArgs(cfg, &MirrorOptions{OfflineCatalog: true}), " "); got != want {
+		t.Errorf("catalogArgs() = %q, expected %q", got, want)
+	}
+}
+
+func TestOrderArgs(t *testing.T) {
+	cfg := &config.ClusterConfig{}
+	if args := orderArgs(cfg, &MirrorOptions{}); len(args) != 0 {
+		t.Errorf("orderArgs() = %v, expected no arguments", args)
+	}
+
+	cfg.SaveImage.MirrorOrder = []string{"release", "operators"}
+	opts := &MirrorOptions{Only: []string{"release"}}
+	want := "--image-order release,operators --only release"
+	if got := strings.Join(orderArgs(cfg, opts), " "); got != want {
+		t.Errorf("orderArgs() = %q, expected %q", got, want)
+	}
+	if !opts.includes("release") || opts.includes("operators") || !opts.onlyRelease() {
+		t.Errorf("unexpected group selection for --only %v", opts.Only)
+	}
+	if all := (&MirrorOptions{}); !all.includes("operators") || all.onlyRelease() {
+		t.Error("without --only every group should be included")
+	}
+}
+
+func TestRetryArgs(t *testing.T) {
+	cfg := &config.ClusterConfig{}
+	if args := retryArgs(cfg, &MirrorOptions{}); len(args) != 0 {
+		t.Errorf("retryArgs() = %v, expected no arguments", args)
+	}
+
+	cfg.SaveImage.Retry = config.ImageRetry{
+		Attempts:           5,
+		Backoff:            config.BackoffLinear,
+		Delay:              "2s",
+		Jitter:             true,
+		NonRetryableErrors: []string{"quota exceeded"},
+	}
+	opts := &MirrorOptions{CopyBackoff: config.BackoffExponential}
+	want := "--copy-attempts 5 --copy-backoff exponential --copy-retry-delay 2s --copy-retry-jitter --non-retryable-errors quota exceeded"
+	if got := strings.Join(retryArgs(cfg, opts), " "); got != want {
+		t.Errorf("retryArgs() = %q, expected %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+// Package mirrormap 维护 load-image 之后镜像的映射关系 (源镜像 → 私有仓库中的地址 → digest)，
+// 保存在集群目录的 mirror-mapping.json 中，供 rewrite-manifests 等功能直接使用，
+// 不再各自从 oc-mirror 工作目录的内部文件推导。
+package mirrormap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Filename 集群目录中映射文件的文件名
+const Filename = "mirror-mapping.json"
+
+// WorkingDirFilename oc-mirror 在 working-dir 中记录本次已复制镜像的文件名
+const WorkingDirFilename = "mirrored-images.json"
+
+// Entry 一个已镜像的镜像
+type Entry struct {
+	Source string `json:"source"`           // 源镜像地址，如 quay.io/openshift-release-dev/ocp-release@sha256:...
+	Mirror string `json:"mirror"`           // 私有仓库中的地址
+	Digest string `json:"digest,omitempty"` // 镜像 manifest 的 digest，无法确定时为空
+	Type   string `json:"type,omitempty"`   // 镜像类型，如 ocpRelease、operatorBundle、generic
+}
+
+// Mapping 集群的镜像映射，多次执行 load-image 时按源镜像合并
+type Mapping struct {
+	Updated time.Time `json:"updated"`
+	Images  []Entry   `json:"images"`
+}
+
+// Path 返回集群目录中映射文件的位置
+func Path(clusterDir string) string {
+	return filepath.Join(clusterDir, Filename)
+}
+
+// Load 读取集群目录中的映射文件
+func Load(clusterDir string) (*Mapping, error) {
+	data, err := os.ReadFile(Path(clusterDir))
+	if err != nil {
+		return nil, fmt.Errorf("读取镜像映射 %s 失败: %w", Path(clusterDir), err)
+	}
+	mapping := &Mapping{}
+	if err := json.Unmarshal(data, mapping); err != nil {
+		return nil, fmt.Errorf("解析镜像映射 %s 失败: %w", Path(clusterDir), err)
+	}
+	return mapping, nil
+}
+
+// Save 保存映射文件，镜像按源地址排序
+func Save(clusterDir string, mapping *Mapping) error {
+	sort.Slice(mapping.Images, func(i, j int) bool { return mapping.Images[i].Source < mapping.Images[j].Source })
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化镜像映射失败: %w", err)
+	}
+	if err := os.WriteFile(Path(clusterDir), data, 0644); err != nil {
+		return fmt.Errorf("写入镜像映射失败: %w", err)
+	}
+	return nil
+}
+
+// FromWorkingDir 读取 oc-mirror 在 working-dir 中记录的本次已复制镜像
+func FromWorkingDir(workingDir string) ([]Entry, error) {
+	path := filepath.Join(workingDir, WorkingDirFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Publish 将 working-dir 中本次已复制的镜像合并到集群目录的映射文件，返回合并后的镜像数
+func Publish(workingDir, clusterDir string) (int, error) {
+	entries, err := FromWorkingDir(workingDir)
+	if err != nil {
+		return 0, err
+	}
+	mapping, err := Load(clusterDir)
+	if errors.Is(err, os.ErrNotExist) {
+		mapping, err = &Mapping{}, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	mapping.Merge(entries)
+	mapping.Updated = time.Now()
+	if err := Save(clusterDir, mapping); err != nil {
+		return 0, err
+	}
+	return len(mapping.Images), nil
+}
+
+// Merge 合并镜像，源地址相同的镜像以新记录为准
+func (m *Mapping) Merge(entries []Entry) {
+	index := make(map[string]int, len(m.Images))
+	for i, entry := range m.Images {
+		index[entry.Source] = i
+	}
+	for _, entry := range entries {
+		if i, ok := index[entry.Source]; ok {
+			m.Images[i] = entry
+			continue
+		}
+		index[entry.Source] = len(m.Images)
+		m.Images = append(m.Images, entry)
+	}
+}
+
+// Lookup 按源地址查找镜像，Docker Hub 的简写 (如 nginx:1.25) 按 docker.io/library/nginx:1.25 匹配
+func (m *Mapping) Lookup(image string) (Entry, bool) {
+	candidates := []string{image, normalize(image)}
+	for _, entry := range m.Images {
+		for _, candidate := range candidates {
+			if entry.Source == candidate || normalize(entry.Source) == candidate {
+				return entry, true
+			}
+		}
+	}
+	return Entry{}, false
+}
+
+// MirrorImage 返回镜像在私有仓库中的地址，可直接作为 rewrite.MirrorFunc 使用
+func (m *Mapping) MirrorImage(image string) (string, bool) {
+	entry, ok := m.Lookup(image)
+	if !ok {
+		return "", false
+	}
+	return entry.Mirror, true
+}
+
+// normalize 补全 Docker Hub 简写镜像的仓库地址
+func normalize(image string) string {
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return image
+	}
+	if !found {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + first + "/" + rest
+}
+
+// WithFallback 返回查找镜像私有仓库地址的函数：映射文件中记录的镜像精确到每个镜像，优先使用，
+// 其余镜像交给 fallback (通常为镜像源规则)。集群目录中没有映射文件时直接返回 fallback
+func WithFallback(clusterDir string, fallback func(string) (string, bool)) (func(string) (string, bool), error) {
+	mapping, err := Load(clusterDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fallback, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(image string) (string, bool) {
+		if to, ok := mapping.MirrorImage(image); ok {
+			return to, true
+		}
+		return fallback(image)
+	}, nil
+}
@@ -0,0 +1,115 @@
+package mirrormap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeWorkingDir(t *testing.T, entries []Entry) string {
+	t.Helper()
+	workingDir := t.TempDir()
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workingDir, WorkingDirFilename), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return workingDir
+}
+
+func TestPublishMerges(t *testing.T) {
+	clusterDir := t.TempDir()
+
+	first := writeWorkingDir(t, []Entry{
+		{Source: "quay.io/example/app:v1", Mirror: "registry.demo.example.com:8443/example/app:v1", Digest: "sha256:aaaa", Type: "generic"},
+		{Source: "docker.io/library/nginx:1.25", Mirror: "registry.demo.example.com:8443/library/nginx:1.25", Type: "generic"},
+	})
+	if n, err := Publish(first, clusterDir); err != nil || n != 2 {
+		t.Fatalf("Publish() = %d, %v", n, err)
+	}
+
+	// 再次执行时同一源镜像以新记录为准，其他镜像保留
+	second := writeWorkingDir(t, []Entry{
+		{Source: "quay.io/example/app:v1", Mirror: "registry.demo.example.com:8443/example/app:v1", Digest: "sha256:bbbb", Type: "generic"},
+	})
+	if n, err := Publish(second, clusterDir); err != nil || n != 2 {
+		t.Fatalf("Publish() = %d, %v", n, err)
+	}
+
+	mapping, err := Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	entry, ok := mapping.Lookup("quay.io/example/app:v1")
+	if !ok || entry.Digest != "sha256:bbbb" {
+		t.Errorf("Lookup() = %+v, %v", entry, ok)
+	}
+	if mapping.Updated.IsZero() {
+		t.Error("Updated should be set")
+	}
+}
+
+func TestPublishMissingWorkingDirFile(t *testing.T) {
+	if _, err := Publish(t.TempDir(), t.TempDir()); err == nil {
+		t.Error("expected error when working-dir has no mirrored images")
+	}
+}
+
+func TestMirrorImage(t *testing.T) {
+	mapping := &Mapping{Images: []Entry{
+		{Source: "docker.io/library/nginx:1.25", Mirror: "registry.demo.example.com:8443/library/nginx:1.25"},
+		{Source: "bitnami/redis:7.2", Mirror: "registry.demo.example.com:8443/bitnami/redis:7.2"},
+		{Source: "quay.io/example/app@sha256:abcd", Mirror: "registry.demo.example.com:8443/example/app@sha256:abcd"},
+	}}
+
+	tests := []struct {
+		image string
+		want  string
+		ok    bool
+	}{
+		{"nginx:1.25", "registry.demo.example.com:8443/library/nginx:1.25", true},
+		{"docker.io/bitnami/redis:7.2", "registry.demo.example.com:8443/bitnami/redis:7.2", true},
+		{"quay.io/example/app@sha256:abcd", "registry.demo.example.com:8443/example/app@sha256:abcd", true},
+		{"quay.io/example/app:v1", "", false},
+		{"localhost/nginx:1.25", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := mapping.MirrorImage(tt.image)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("MirrorImage(%q) = %q, %v, want %q, %v", tt.image, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	fallback := func(image string) (string, bool) {
+		return "fallback.example.com/" + image, true
+	}
+
+	clusterDir := t.TempDir()
+	mirror, err := WithFallback(clusterDir, fallback)
+	if err != nil {
+		t.Fatalf("WithFallback() error = %v", err)
+	}
+	if got, _ := mirror("quay.io/a/b:1"); got != "fallback.example.com/quay.io/a/b:1" {
+		t.Errorf("without mapping got %q", got)
+	}
+
+	mapping := &Mapping{Images: []Entry{{Source: "quay.io/a/b:1", Mirror: "registry.example.com:8443/a/b:1"}}}
+	if err := Save(clusterDir, mapping); err != nil {
+		t.Fatal(err)
+	}
+	mirror, err = WithFallback(clusterDir, fallback)
+	if err != nil {
+		t.Fatalf("WithFallback() error = %v", err)
+	}
+	if got, _ := mirror("quay.io/a/b:1"); got != "registry.example.com:8443/a/b:1" {
+		t.Errorf("mapped image got %q", got)
+	}
+	if got, _ := mirror("quay.io/c/d:2"); got != "fallback.example.com/quay.io/c/d:2" {
+		t.Errorf("unmapped image got %q", got)
+	}
+}
@@ -3,62 +3,53 @@ package monitor
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
 )
 
-// MonitorCluster 监控集群安装进度
-func MonitorCluster(clusterName string) error {
-	// 构建安装目录路径
-	installDir := filepath.Join("installation", "ignition")
+// InstallDir 返回 generate-iso 复制安装状态 (auth、.openshift_install_state.json) 的目录，
+// wait-for 使用该目录时输出会追加到其中的 .openshift_install.log
+func InstallDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "installation", "ignition")
+}
 
-	// 检查安装目录是否存在
+// MonitorCluster 执行 openshift-install agent wait-for install-complete 监控集群安装进度，直到安装完成或失败
+func MonitorCluster(r runner.CommandRunner, cfg *config.ClusterConfig, clusterDir string) error {
+	installDir := InstallDir(clusterDir)
 	if _, err := os.Stat(installDir); os.IsNotExist(err) {
-		return fmt.Errorf("安装目录不存在: %s", installDir)
+		return fmt.Errorf("安装目录不存在: %s，请先生成 ISO", installDir)
 	}
 
-	// 查找 openshift-install 工具
-	openshiftInstallPath, err := findOpenshiftInstall()
+	openshiftInstallPath, err := findOpenshiftInstall(r, cfg, clusterDir)
 	if err != nil {
-		return fmt.Errorf("找不到 openshift-install 工具: %v", err)
+		return err
 	}
 
-	// 直接执行 openshift-install 命令并透传输出
-	cmd := exec.Command(openshiftInstallPath, "agent", "wait-for", "install-complete", "--dir", installDir)
-
-	// 设置输出直接到标准输出和标准错误
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// 执行命令
-	return cmd.Run()
-}
-
-// findOpenshiftInstall 查找 openshift-install 工具
-func findOpenshiftInstall() (string, error) {
-	// 首先检查当前目录中的标准名称
-	if _, err := os.Stat("./openshift-install"); err == nil {
-		// 获取绝对路径
-		if absPath, err := filepath.Abs("./openshift-install"); err == nil {
-			return absPath, nil
-		}
-		return "./openshift-install", nil
+	cmd := runner.Command{
+		Name:   openshiftInstallPath,
+		Args:   []string{"agent", "wait-for", "install-complete", "--dir", installDir},
+		Stream: true,
+	}
+	if _, err := r.Run(cmd); err != nil {
+		return fmt.Errorf("等待集群安装完成失败: %w", err)
 	}
+	return nil
+}
 
-	// 查找当前目录中以 openshift-install 开头的文件
-	files, err := filepath.Glob("./openshift-install*")
-	if err == nil && len(files) > 0 {
-		// 获取绝对路径
-		if absPath, err := filepath.Abs(files[0]); err == nil {
-			return absPath, nil
+// findOpenshiftInstall 按 generate-iso 的优先顺序查找 openshift-install：从私有仓库提取的版本和下载目录中的版本，
+// 都不存在时使用 PATH 中的版本
+func findOpenshiftInstall(r runner.CommandRunner, cfg *config.ClusterConfig, clusterDir string) (string, error) {
+	paths := agentinstall.InstallerPaths(cfg, clusterDir)
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
 		}
-		return files[0], nil
 	}
-
-	// 然后检查 PATH 中的 openshift-install
-	if path, err := exec.LookPath("openshift-install"); err == nil {
+	if path, err := r.LookPath("openshift-install"); err == nil {
 		return path, nil
 	}
-
-	return "", fmt.Errorf("openshift-install 工具未找到")
+	return "", fmt.Errorf("openshift-install 工具未找到: %v", paths)
 }
@@ -0,0 +1,184 @@
+// Package notify 在 save-image、load-image、generate-iso 和安装监控等耗时较长的阶段结束或失败时，
+// 按 [notify] 配置发送通知 (通用 webhook、Slack/Teams 兼容的 webhook 或邮件)，通知中包含耗时、关键输出和镜像同步统计，
+// 运维人员不必守着数小时的执行过程。
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirror/batch"
+	"ocpack/pkg/report"
+)
+
+// sendTimeout 发送一个 webhook 通知的超时时间
+const sendTimeout = 30 * time.Second
+
+// Event 一次阶段执行的结果
+type Event struct {
+	Cluster  string             `json:"cluster"`
+	Stage    string             `json:"stage"`
+	Status   string             `json:"status"` // report.StatusSuccess 或 report.StatusFailed
+	Start    time.Time          `json:"start"`
+	Duration time.Duration      `json:"duration"`
+	Error    string             `json:"error,omitempty"`
+	Outputs  map[string]string  `json:"outputs,omitempty"`
+	Mirror   *batch.RunProgress `json:"mirror,omitempty"` // save-image/load-image 的镜像同步统计
+	Host     string             `json:"host,omitempty"`   // 执行 ocpack 的主机
+}
+
+// Failed 判断阶段是否失败
+func (e Event) Failed() bool {
+	return e.Status != report.StatusSuccess
+}
+
+// Title 返回通知的标题，如 "✅ ocpack demo: save_image 成功"
+func (e Event) Title() string {
+	if e.Failed() {
+		return fmt.Sprintf("❌ ocpack %s: %s 失败", e.Cluster, e.Stage)
+	}
+	return fmt.Sprintf("✅ ocpack %s: %s 成功", e.Cluster, e.Stage)
+}
+
+// Summary 返回通知正文，每项一行
+func (e Event) Summary() string {
+	lines := []string{fmt.Sprintf("耗时: %s", e.Duration.Round(time.Second))}
+	if e.Host != "" {
+		lines = append(lines, "主机: "+e.Host)
+	}
+	if e.Mirror != nil {
+		lines = append(lines, fmt.Sprintf("镜像: 总计 %d，成功 %d，失败 %d", e.Mirror.Total, e.Mirror.Succeeded, e.Mirror.Failed))
+	}
+	keys := make([]string, 0, len(e.Outputs))
+	for key := range e.Outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, e.Outputs[key]))
+	}
+	if e.Error != "" {
+		lines = append(lines, "错误: "+e.Error)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Notifier 按 [notify] 配置发送通知
+type Notifier struct {
+	Config config.Notify
+	HTTP   *http.Client
+	// SendMail 发送邮件，默认为 smtp.SendMail，测试时可替换
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// New 创建通知器，httpClient 为 nil 时使用默认的 HTTP 客户端
+func New(cfg config.Notify, httpClient *http.Client) *Notifier {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &Notifier{Config: cfg, HTTP: httpClient, SendMail: smtp.SendMail}
+}
+
+// Send 向全部 webhook 和邮件接收人发送通知。一个目标失败不影响其他目标，返回的错误由全部失败组成
+func (n *Notifier) Send(event Event) error {
+	var errs []error
+	for i, webhook := range n.Config.Webhooks {
+		if err := n.sendWebhook(webhook, event); err != nil {
+			errs = append(errs, fmt.Errorf("notify.webhooks[%d]: %w", i, err))
+		}
+	}
+	if n.Config.SMTP.Host != "" {
+		if err := n.sendMail(event); err != nil {
+			errs = append(errs, fmt.Errorf("notify.smtp: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Payload 返回 webhook 格式为 format 时的请求体
+func Payload(format string, event Event) ([]byte, error) {
+	switch format {
+	case config.WebhookSlack:
+		return json.Marshal(map[string]string{"text": event.Title() + "\n" + event.Summary()})
+	case config.WebhookTeams:
+		color := "2E7D32"
+		if event.Failed() {
+			color = "C62828"
+		}
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    event.Title(),
+			"themeColor": color,
+			"title":      event.Title(),
+			// MessageCard 的 text 为 Markdown，两个空格加换行才会换行
+			"text": strings.ReplaceAll(event.Summary(), "\n", "  \n"),
+		})
+	default:
+		return json.Marshal(event)
+	}
+}
+
+func (n *Notifier) sendWebhook(webhook config.Webhook, event Event) error {
+	body, err := Payload(webhook.GetFormat(), event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := *n.HTTP
+	if client.Timeout == 0 {
+		client.Timeout = sendTimeout
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s 返回 %s: %s", webhook.URL, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+func (n *Notifier) sendMail(event Event) error {
+	cfg := n.Config.SMTP
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.GetPort()))
+	return n.SendMail(addr, auth, cfg.From, cfg.To, mailMessage(cfg, event))
+}
+
+// mailMessage 生成邮件内容，标题使用 RFC 2047 编码以支持中文
+func mailMessage(cfg config.SMTP, event Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: =?UTF-8?B?%s?=\r\n", base64.StdEncoding.EncodeToString([]byte(event.Title())))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(event.Summary(), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
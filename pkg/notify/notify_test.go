@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/mirror/batch"
+	"ocpack/pkg/report"
+)
+
+func testEvent() Event {
+	return Event{
+		Cluster:  "demo",
+		Stage:    "save_image",
+		Status:   report.StatusFailed,
+		Start:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Duration: 2*time.Hour + 3*time.Second,
+		Error:    "镜像复制过程中出现 2 个错误",
+		Outputs:  map[string]string{"images": "/opt/ocpack/demo/images"},
+		Mirror:   &batch.RunProgress{Total: 120, Succeeded: 118, Failed: 2},
+	}
+}
+
+func TestSummary(t *testing.T) {
+	event := testEvent()
+	if got := event.Title(); got != "❌ ocpack demo: save_image 失败" {
+		t.Errorf("Title() = %q", got)
+	}
+	want := "耗时: 2h0m3s\n镜像: 总计 120，成功 118，失败 2\nimages: /opt/ocpack/demo/images\n错误: 镜像复制过程中出现 2 个错误"
+	if got := event.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	event := testEvent()
+	tests := map[string]string{
+		config.WebhookGeneric: `"stage":"save_image"`,
+		config.WebhookSlack:   `"text":"❌ ocpack demo: save_image 失败\n耗时`,
+		config.WebhookTeams:   `"themeColor":"C62828"`,
+	}
+	for format, want := range tests {
+		body, err := Payload(format, event)
+		if err != nil {
+			t.Fatalf("Payload(%s) error = %v", format, err)
+		}
+		if !json.Valid(body) || !strings.Contains(string(body), want) {
+			t.Errorf("Payload(%s) = %s, want %s", format, body, want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if r.URL.Path == "/broken" {
+			http.Error(w, "no such hook", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var mail string
+	notifier := New(config.Notify{
+		Webhooks: []config.Webhook{{URL: server.URL + "/slack", Format: "slack"}, {URL: server.URL + "/broken"}},
+		SMTP:     config.SMTP{Host: "smtp.example.com", From: "ocpack@example.com", To: []string{"ops@example.com"}},
+	}, nil)
+	notifier.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "smtp.example.com:25" || a != nil || from != "ocpack@example.com" || len(to) != 1 {
+			t.Errorf("SendMail(%s, %v, %s, %v)", addr, a, from, to)
+		}
+		mail = string(msg)
+		return nil
+	}
+
+	err := notifier.Send(testEvent())
+	if err == nil || !strings.Contains(err.Error(), "notify.webhooks[1]") || strings.Contains(err.Error(), "notify.webhooks[0]") {
+		t.Errorf("Send() error = %v, expected only the broken webhook to fail", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("expected both webhooks to be called, got %d", len(received))
+	}
+	if !strings.Contains(mail, "Subject: =?UTF-8?B?") || !strings.Contains(mail, "\r\n\r\n耗时: 2h0m3s\r\n") {
+		t.Errorf("unexpected mail:\n%s", mail)
+	}
+}
@@ -0,0 +1,59 @@
+package ocpack
+
+import (
+	"context"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/deploy"
+	"ocpack/pkg/i18n"
+)
+
+// DeployResult deploy-bastion 和 deploy-registry 的结果
+type DeployResult struct {
+	// Skipped 为 true 时未部署，如 bastion.enabled = false
+	Skipped bool
+}
+
+// DeployBastion 部署 Bastion 节点的 DNS、HAProxy 等服务。bastion.enabled = false 时跳过
+func (c *Client) DeployBastion(ctx context.Context, clusterName string) (*DeployResult, error) {
+	cfg, configPath, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	c.printf("使用集群配置文件: %s\n", configPath)
+
+	if !cfg.BastionEnabled() {
+		c.printf("ℹ️  bastion.enabled = false，使用 [infra] 中站点已有的 DNS 和负载均衡，跳过 Bastion 部署\n")
+		return &DeployResult{Skipped: true}, nil
+	}
+	if err := config.ValidateBastionConfig(cfg); err != nil {
+		return nil, clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+	}
+
+	deployer := deploy.NewBastionDeployer(cfg, cfg.GetDownloadDir(c.ClusterDir(clusterName)))
+	deployer.Out = c.out
+	c.printf("开始部署 Bastion 节点...\n")
+	if err := deployer.Deploy(ctx, configPath); err != nil {
+		return nil, i18n.Errorf("Bastion 节点部署失败: %v", err)
+	}
+	return &DeployResult{}, nil
+}
+
+// DeployRegistry 部署 Registry 节点的 Quay 镜像仓库，已部署并运行时不重复部署
+func (c *Client) DeployRegistry(ctx context.Context, clusterName string) (*DeployResult, error) {
+	cfg, configPath, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	c.printf("使用集群配置文件: %s\n", configPath)
+
+	// 验证 Registry 部署所需的配置和下载文件
+	if err := config.ValidateRegistryConfigWithDownloads(cfg, cfg.GetDownloadDir(c.ClusterDir(clusterName))); err != nil {
+		return nil, clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+	}
+	if err := deploy.DeployRegistryTo(ctx, c.out, cfg, configPath); err != nil {
+		return nil, i18n.Errorf("Registry 节点部署失败: %v", err)
+	}
+	return &DeployResult{}, nil
+}
@@ -0,0 +1,106 @@
+package ocpack
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/download"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/pipeline"
+	"ocpack/pkg/trustbundle"
+)
+
+// DownloadResult 下载结果
+type DownloadResult struct {
+	// Dirs 下载目录到使用该目录的集群，download.shared 的集群共用同一目录
+	Dirs map[string][]string
+}
+
+// Download 下载集群安装所需的介质。先验证全部集群，共用同一下载目录的集群只下载一次；
+// 多个下载目录时并行下载，输出以集群名称为前缀
+func (c *Client) Download(ctx context.Context, clusters ...string) (*DownloadResult, error) {
+	if len(clusters) == 0 {
+		return nil, clierr.New(clierr.Config, i18n.Errorf("未指定集群"))
+	}
+
+	var dirs []string
+	result := &DownloadResult{Dirs: make(map[string][]string)}
+	downloaders := make(map[string]*download.Downloader)
+	for _, clusterName := range clusters {
+		cfg, downloadDir, err := c.loadDownloadConfig(clusterName)
+		if err != nil {
+			return nil, i18n.Errorf("集群 %s: %w", clusterName, err)
+		}
+		if _, ok := result.Dirs[downloadDir]; !ok {
+			downloader, err := c.newDownloader(cfg, clusterName, downloadDir)
+			if err != nil {
+				return nil, i18n.Errorf("集群 %s: %w", clusterName, err)
+			}
+			dirs = append(dirs, downloadDir)
+			downloaders[downloadDir] = downloader
+		}
+		result.Dirs[downloadDir] = append(result.Dirs[downloadDir], clusterName)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(dirs) == 1 {
+		downloadDir := dirs[0]
+		c.printf("将下载文件保存到: %s\n", downloadDir)
+		if err := downloaders[downloadDir].DownloadAll(); err != nil {
+			return nil, i18n.Errorf("下载失败: %v", err)
+		}
+		return result, nil
+	}
+
+	var stages []pipeline.Stage
+	for _, downloadDir := range dirs {
+		downloadDir := downloadDir
+		names := result.Dirs[downloadDir]
+		c.printf("%s 的文件将保存到: %s\n", strings.Join(names, ", "), downloadDir)
+		stages = append(stages, pipeline.Stage{Name: names[0], Run: func(out io.Writer) error {
+			downloader := downloaders[downloadDir]
+			downloader.Out = out
+			downloader.Quiet = true
+			return downloader.DownloadAll()
+		}})
+	}
+	if err := pipeline.RunParallel(c.out, stages...); err != nil {
+		return nil, i18n.Errorf("下载失败: %w", err)
+	}
+	return result, nil
+}
+
+// newDownloader 创建下载器，配置了 infra.ca_bundle 时使用信任这些证书的 HTTP 客户端，
+// 使经过解密 TLS 的企业代理下载时也校验证书
+func (c *Client) newDownloader(cfg *config.ClusterConfig, clusterName, downloadDir string) (*download.Downloader, error) {
+	downloader := download.NewDownloader(cfg, downloadDir)
+	downloader.Out = c.out
+	bundle, err := trustbundle.LoadCABundle(cfg, c.ClusterDir(clusterName))
+	if err != nil {
+		return nil, clierr.New(clierr.Config, err)
+	}
+	if !bundle.Empty() {
+		downloader.HTTP = bundle.HTTPClient()
+	}
+	return downloader, nil
+}
+
+// loadDownloadConfig 加载并验证集群配置，返回配置和集群使用的下载目录
+func (c *Client) loadDownloadConfig(clusterName string) (*config.ClusterConfig, string, error) {
+	cfg, configPath, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, "", err
+	}
+	c.printf("使用集群配置文件: %s\n", configPath)
+
+	// 验证下载所需的配置
+	if err := config.ValidateDownloadConfig(cfg); err != nil {
+		return nil, "", clierr.New(clierr.Config, i18n.Errorf("配置验证失败: %v", err))
+	}
+	return cfg, cfg.GetDownloadDir(c.ClusterDir(clusterName)), nil
+}
@@ -0,0 +1,404 @@
+package ocpack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/gate"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
+	"ocpack/pkg/mirror/wrapper"
+	"ocpack/pkg/quay"
+	"ocpack/pkg/registrybundle"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/scan"
+	"ocpack/pkg/storage"
+	"ocpack/pkg/transfer"
+	"ocpack/pkg/workspace"
+)
+
+// maxListedImages 缓存清理结果中最多列出的镜像数量
+const maxListedImages = 10
+
+// MirrorOptions save-image 和 load-image 共用的选项
+type MirrorOptions struct {
+	DryRun bool
+	// Port oc-mirror 本地缓存 registry 的端口，为 0 时使用 [save_image] local_storage_port 或自动选择
+	Port uint16
+	// EnableRetry 整个 oc-mirror 操作失败时重试 MaxRetries 次，间隔 RetryInterval 秒
+	EnableRetry   bool
+	MaxRetries    int
+	RetryInterval int
+	// Images 非空时只复制这些镜像，跳过 release 和 Operator
+	Images []string
+	// ImagesFile Images 的来源文件，只用于输出提示
+	ImagesFile string
+	// FullCopy 复制全部镜像，不预先跳过目标中 digest 相同的镜像
+	FullCopy bool
+	// Only 只复制这些分组: release、operators、additional、helm
+	Only []string
+	// CopyAttempts、CopyBackoff 覆盖 [save_image.retry]
+	CopyAttempts int
+	CopyBackoff  string
+	// MaxCacheSize 本地缓存的大小上限，如 200Gi，为空时使用 [save_image] max_cache_size
+	MaxCacheSize string
+}
+
+// SaveImagesOptions save-image 的选项
+type SaveImagesOptions struct {
+	MirrorOptions
+	// IncludeOperators 覆盖 [save_image] include_operators
+	IncludeOperators bool
+	// OfflineGraph 只使用之前保存的升级图，不访问 Cincinnati
+	OfflineGraph bool
+	// RefreshCatalog、OfflineCatalog 忽略或只使用 Operator 目录缓存，不能同时设置
+	RefreshCatalog bool
+	OfflineCatalog bool
+}
+
+// SaveImagesResult save-image 的结果
+type SaveImagesResult struct {
+	// Skipped 为 true 时 Registry 使用 proxy-cache 模式，未保存镜像
+	Skipped bool
+	// ImagesDir 镜像归档所在的本地目录
+	ImagesDir string
+	// Storage 镜像存储的位置，如 file:///mnt/nfs/ocp-images 或 s3://bucket/prefix
+	Storage string
+	// MappingFile DryRun 时生成的镜像列表
+	MappingFile string
+	// ManifestFile 传输清单，DryRun 时为空
+	ManifestFile string
+}
+
+// LoadImagesOptions load-image 的选项
+type LoadImagesOptions struct {
+	MirrorOptions
+	// SkipScan 跳过 [scan] 配置的镜像漏洞扫描
+	SkipScan bool
+	// SkipChecks 跳过 registry 健康状态和认证检查
+	SkipChecks bool
+	// SkipVerify 跳过传输清单的签名和校验和验证
+	SkipVerify bool
+}
+
+// LoadImagesResult load-image 的结果
+type LoadImagesResult struct {
+	// Skipped 为 true 时 Registry 使用 proxy-cache 模式，未推送镜像
+	Skipped bool
+	// Registry 镜像推送到的仓库地址，包含 target_namespace
+	Registry string
+	// ClusterResourcesDir oc-mirror 生成的 IDMS/ITMS 和 CatalogSource 所在目录
+	ClusterResourcesDir string
+}
+
+// validate 验证分组、重试和缓存大小选项，返回缓存大小上限
+func (o MirrorOptions) validate(cfg *config.ClusterConfig) (int64, error) {
+	if err := config.ValidateMirrorGroups(o.Only, "--only"); err != nil {
+		return 0, clierr.New(clierr.Config, err)
+	}
+	if o.CopyAttempts < 0 {
+		return 0, clierr.New(clierr.Config, i18n.Errorf("--copy-attempts 不能为负数"))
+	}
+	if err := config.ValidateBackoff(o.CopyBackoff, "--copy-backoff"); err != nil {
+		return 0, clierr.New(clierr.Config, err)
+	}
+	if o.MaxCacheSize == "" {
+		size, err := cfg.GetMaxCacheSize()
+		if err != nil {
+			return 0, clierr.New(clierr.Config, err)
+		}
+		return size, nil
+	}
+	size, err := config.ParseSize(o.MaxCacheSize)
+	if err != nil {
+		return 0, clierr.New(clierr.Config, fmt.Errorf("--max-cache-size: %w", err))
+	}
+	return size, nil
+}
+
+// wrapperOptions 转换为镜像包装器的选项
+func (o MirrorOptions) wrapperOptions(clusterName, clusterDir, configPath string) *wrapper.MirrorOptions {
+	return &wrapper.MirrorOptions{
+		ClusterName:   clusterName,
+		ClusterDir:    clusterDir,
+		ConfigPath:    configPath,
+		Port:          o.Port,
+		DryRun:        o.DryRun,
+		EnableRetry:   o.EnableRetry,
+		MaxRetries:    o.MaxRetries,
+		RetryInterval: o.RetryInterval,
+		Images:        o.Images,
+		FullCopy:      o.FullCopy,
+		Only:          o.Only,
+		CopyAttempts:  o.CopyAttempts,
+		CopyBackoff:   o.CopyBackoff,
+	}
+}
+
+// SaveImages 使用内置的 oc-mirror 将镜像集保存为镜像归档，生成传输清单并同步到 [save_image.storage]
+func (c *Client) SaveImages(ctx context.Context, clusterName string, opts SaveImagesOptions) (*SaveImagesResult, error) {
+	quiet := c.verbosity == progress.Quiet
+	cfg, configPath, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.IsProxyCache() {
+		c.printf("ℹ️  [registry] mirror_mode = \"proxy-cache\"，镜像由 Registry 拉取代理按需缓存，跳过 save-image\n")
+		return &SaveImagesResult{Skipped: true}, nil
+	}
+	if opts.IncludeOperators {
+		cfg.SaveImage.IncludeOperators = true
+	}
+	maxCache, err := opts.validate(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterDir := c.ClusterDir(clusterName)
+	backend, err := storage.NewTo(c.out, clusterDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// dry-run 的镜像列表保存在集群目录中，供 scan-images 和 plan 读取
+	imagesPath := backend.Dir()
+	if opts.DryRun {
+		imagesPath = filepath.Join(clusterDir, "images")
+	}
+	// 指定镜像列表时归档到单独的目录，不影响完整镜像集的归档和 dry-run 结果
+	if len(opts.Images) > 0 {
+		imagesPath = config.AdhocImagesDir(imagesPath)
+	}
+	if err := os.MkdirAll(imagesPath, 0755); err != nil {
+		return nil, i18n.Errorf("创建镜像目录失败: %v", err)
+	}
+
+	if !quiet {
+		c.printf("🔄 开始保存镜像: %s\n", clusterName)
+		c.printf("⚙️  配置文件: %s\n", configPath)
+		c.printf("📦 镜像存储: %s\n", backend)
+		if len(opts.Images) > 0 {
+			c.printf("📋 镜像列表: %s (%d 个镜像)，跳过 release 和 Operator\n", opts.ImagesFile, len(opts.Images))
+		}
+		if opts.DryRun {
+			c.printf("🔍 干运行模式: 只解析镜像集而不下载镜像\n")
+		}
+	}
+
+	wrapperOpts := opts.wrapperOptions(clusterName, clusterDir, configPath)
+	wrapperOpts.OfflineGraph = opts.OfflineGraph
+	wrapperOpts.RefreshCatalog = opts.RefreshCatalog
+	wrapperOpts.OfflineCatalog = opts.OfflineCatalog
+	mirrorWrapper := wrapper.NewStreamingMirrorWrapper(c.out, c.verbosity)
+	if err := mirrorWrapper.MirrorToDisk(ctx, cfg, "file://"+imagesPath, wrapperOpts); err != nil {
+		return nil, i18n.Errorf("镜像保存失败: %w", err)
+	}
+
+	result := &SaveImagesResult{ImagesDir: imagesPath, Storage: backend.String()}
+	if opts.DryRun {
+		result.MappingFile = filepath.Join(imagesPath, "working-dir", "dry-run", "mapping.txt")
+		return result, nil
+	}
+	// 随镜像归档 mirror-registry 离线安装包，Registry 主机损坏后可离线重建
+	if cfg.SaveImage.MirrorRegistry && len(opts.Images) == 0 {
+		bundle, copied, err := registrybundle.Archive(cfg.GetDownloadDir(clusterDir), backend.Dir())
+		if err != nil {
+			return nil, err
+		}
+		if copied {
+			c.printf("📦 已归档 mirror-registry 安装包: %s\n", bundle)
+		} else if !quiet {
+			c.printf("✅ mirror-registry 安装包未变化，跳过归档: %s\n", bundle)
+		}
+	}
+	// 记录各归档的校验和 (可选签名)，load-image 导入前验证
+	manifest := transfer.Manifest{
+		Cluster:          clusterName,
+		OpenShiftVersion: cfg.ClusterInfo.OpenShiftVersion,
+		Tool:             c.version,
+		Groups:           opts.Only,
+		Created:          time.Now().UTC(),
+	}
+	if result.ManifestFile, err = transfer.Write(ctx, runner.NewExecRunner(), imagesPath, manifest, cfg.SaveImage.Signing); err != nil {
+		return nil, err
+	}
+	if cfg.SaveImage.Signing.Method != "" {
+		c.printf("🔏 已生成并签名 (%s) 传输清单: %s\n", cfg.SaveImage.Signing.Method, result.ManifestFile)
+	} else if !quiet {
+		c.printf("🔐 已生成传输清单: %s\n", result.ManifestFile)
+	}
+	if err := backend.Push(ctx); err != nil {
+		return nil, err
+	}
+	c.printf("✅ 镜像保存完成！镜像归档: %s\n", backend)
+	c.pruneCache(clusterDir, maxCache)
+	return result, nil
+}
+
+// LoadImages 验证镜像归档、检查 registry、准备 Quay 组织并扫描镜像后，将镜像推送到私有仓库
+func (c *Client) LoadImages(ctx context.Context, clusterName string, opts LoadImagesOptions) (*LoadImagesResult, error) {
+	quiet := c.verbosity == progress.Quiet
+	cfg, configPath, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if !quiet {
+		c.printf("🔄 开始从本地磁盘加载镜像到 registry: %s\n", clusterName)
+		c.printf("⚙️  配置文件: %s\n", configPath)
+		if opts.DryRun {
+			c.printf("🔍 干运行模式: 只显示操作而不实际执行\n")
+		}
+	}
+	if cfg.IsProxyCache() {
+		c.printf("ℹ️  [registry] mirror_mode = \"proxy-cache\"，镜像由 Registry 拉取代理按需缓存，跳过 load-image\n")
+		return &LoadImagesResult{Skipped: true}, nil
+	}
+	maxCache, err := opts.validate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ImagesFile != "" {
+		// 只扫描列表中的镜像
+		if cfg.Scan.ImagesFile, err = filepath.Abs(opts.ImagesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// s3:// 存储先将镜像归档下载到本地目录
+	clusterDir := c.ClusterDir(clusterName)
+	backend, err := storage.NewTo(c.out, clusterDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Pull(ctx); err != nil {
+		return nil, err
+	}
+
+	// 检查镜像数据是否存在
+	imagesPath := backend.Dir()
+	saveCommand := "ocpack save-image " + clusterName
+	if len(opts.Images) > 0 {
+		imagesPath = config.AdhocImagesDir(imagesPath)
+		saveCommand += " --images-file " + opts.ImagesFile
+	}
+	if _, err := os.Stat(imagesPath); os.IsNotExist(err) {
+		return nil, i18n.Errorf("镜像数据不存在: %s\n请先运行 '%s'", imagesPath, saveCommand)
+	}
+
+	// 导入前验证传输清单的签名和各归档的校验和
+	if !opts.DryRun && !opts.SkipVerify {
+		if err := c.verifyTransfer(ctx, imagesPath, cfg, quiet); err != nil {
+			return nil, err
+		}
+	}
+
+	// 推送前确认 registry 可用且认证有效，避免 oc-mirror 在推送过程中失败
+	if !opts.DryRun && !opts.SkipChecks {
+		c.printf("🔍 执行就绪检查...\n")
+		if err := gate.RunTo(c.out, cfg, gate.BeforeLoadImage(clusterDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 按 [registry.quay] 创建目标组织和仓库，避免推送到中途因组织不存在或超出配额返回 403
+	if cfg.Registry.Quay.ManageOrganizations && !opts.DryRun {
+		c.printf("🏢 准备 Quay 组织和仓库...\n")
+		if err := quay.EnsureTo(c.out, cfg, clusterDir, opts.Images); err != nil {
+			return nil, err
+		}
+	}
+
+	// 推送到 registry 之前扫描镜像
+	if cfg.Scan.Enabled && !opts.SkipScan && !opts.DryRun {
+		c.printf("🛡️  开始扫描镜像漏洞...\n")
+		if _, err := scan.RunTo(ctx, c.out, runner.NewExecRunner(), clusterDir, cfg); err != nil {
+			return nil, i18n.Errorf("镜像扫描未通过，已终止加载: %v", err)
+		}
+	}
+
+	// 配置了 target_namespace 时镜像推送到该命名空间下
+	registryHost := cfg.GetMirrorDestination()
+	mirrorWrapper := wrapper.NewStreamingMirrorWrapper(c.out, c.verbosity)
+	err = mirrorWrapper.DiskToMirror(ctx, cfg, "file://"+imagesPath, "docker://"+registryHost, opts.wrapperOptions(clusterName, clusterDir, configPath))
+	if err != nil {
+		return nil, i18n.Errorf("镜像加载失败: %w", err)
+	}
+
+	result := &LoadImagesResult{Registry: registryHost}
+	if !opts.DryRun {
+		c.printf("✅ 镜像加载完成！目标仓库: %s\n", registryHost)
+		c.pruneCache(clusterDir, maxCache)
+		if len(opts.Images) == 0 {
+			result.ClusterResourcesDir = filepath.Join(clusterDir, "images", "working-dir", "cluster-resources")
+		}
+	}
+	return result, nil
+}
+
+// verifyTransfer 验证 save-image 生成的传输清单。没有清单的旧归档只输出警告，
+// 但配置了 [save_image.signing] 时必须有经过签名的清单
+func (c *Client) verifyTransfer(ctx context.Context, imagesPath string, cfg *config.ClusterConfig, quiet bool) error {
+	signing := cfg.SaveImage.Signing
+	if !quiet {
+		c.printf("🔐 验证镜像归档的传输清单...\n")
+	}
+	manifest, err := transfer.Verify(ctx, runner.NewExecRunner(), imagesPath, signing)
+	if errors.Is(err, transfer.ErrNoManifest) {
+		if signing.Method != "" {
+			return clierr.New(clierr.Prereq, i18n.Errorf("已配置 save_image.signing，但 %s 中没有传输清单 %s，请使用当前版本重新执行 save-image", imagesPath, transfer.ManifestFile))
+		}
+		c.printf("⚠️  %s 中没有传输清单 %s，跳过镜像归档的完整性验证\n", imagesPath, transfer.ManifestFile)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		verified := i18n.T("校验和一致")
+		if signing.Method != "" {
+			verified = i18n.T("签名有效，") + verified
+		}
+		c.printf("✅ %d 个文件%s (集群 %s，OpenShift %s，保存于 %s)\n", len(manifest.Files), verified,
+			manifest.Cluster, manifest.OpenShiftVersion, manifest.Created.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// pruneCache 镜像成功后将本地缓存清理到 maxSize 以内。清理失败不影响镜像结果，只输出警告
+func (c *Client) pruneCache(clusterDir string, maxSize int64) {
+	if maxSize <= 0 {
+		return
+	}
+	cacheDir := workspace.CacheDir(clusterDir)
+	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
+		return
+	}
+	result, err := workspace.PruneCache(cacheDir, maxSize, false)
+	if err != nil {
+		c.printf("⚠️  清理本地缓存失败: %v\n", err)
+		return
+	}
+	if result.Blobs == 0 {
+		if c.verbosity != progress.Quiet {
+			c.printf("💾 本地缓存 %s，未超过上限 %s\n", workspace.FormatSize(result.SizeBefore), workspace.FormatSize(maxSize))
+		}
+		return
+	}
+	c.printf("🧹 本地缓存 %s 超过上限 %s，已删除 %d 个最久未使用的 blob，释放 %s\n",
+		workspace.FormatSize(result.SizeBefore), workspace.FormatSize(maxSize), result.Blobs, workspace.FormatSize(result.Reclaimed))
+	if len(result.Images) > 0 {
+		c.printf("   以下 %d 个镜像已从缓存中移除，下次执行时重新下载:\n", len(result.Images))
+		for i, image := range result.Images {
+			if i == maxListedImages {
+				c.printf("   - ... 等 %d 个镜像\n", len(result.Images))
+				break
+			}
+			c.printf("   - %s\n", image)
+		}
+	}
+}
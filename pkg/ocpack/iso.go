@@ -0,0 +1,82 @@
+package ocpack
+
+import (
+	"context"
+	"path/filepath"
+
+	"ocpack/pkg/gate"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/iso"
+)
+
+// GenerateISOOptions generate-iso 的选项
+type GenerateISOOptions struct {
+	// OutputPath 指定输出目录
+	OutputPath string
+	// BaseISOPath RHCOS 基础 ISO，用于 Unconfigured
+	BaseISOPath string
+	// SkipVerify 跳过镜像验证步骤
+	SkipVerify bool
+	// Force 覆盖已有的 ISO 文件
+	Force bool
+	// RenderOnly 只渲染 install-config.yaml 和 agent-config.yaml 并输出差异，不执行 openshift-install
+	RenderOnly bool
+	// SkipChecks 跳过 release 镜像和 DNS 就绪检查
+	SkipChecks bool
+	// Unconfigured 生成不含集群配置的 ISO 和单独的配置镜像 (late-binding)，不能与 RenderOnly 同时设置
+	Unconfigured bool
+}
+
+// GenerateISOResult generate-iso 的结果
+type GenerateISOResult struct {
+	// InstallDir install-config.yaml、agent-config.yaml 所在的安装目录
+	InstallDir string
+	// ISODir ISO 文件所在目录
+	ISODir string
+	// IgnitionDir ignition 文件所在目录
+	IgnitionDir string
+}
+
+// GenerateISO 生成集群的 agent 安装 ISO。生成前检查私有仓库中的 release 镜像和集群 DNS 记录
+func (c *Client) GenerateISO(ctx context.Context, clusterName string, opts GenerateISOOptions) (*GenerateISOResult, error) {
+	if opts.RenderOnly && opts.Unconfigured {
+		return nil, i18n.Errorf("RenderOnly 和 Unconfigured 不能同时设置")
+	}
+	cfg, _, err := c.LoadConfig(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	c.printf("开始为集群 %s 生成 ISO 镜像\n", clusterName)
+	generator, err := iso.NewISOGeneratorTo(c.out, clusterName, c.projectRoot)
+	if err != nil {
+		return nil, i18n.Errorf("创建 ISO 生成器失败: %w", err)
+	}
+
+	// 生成 ISO 前确认 release 镜像和 DNS 已就绪，避免节点启动后才发现安装无法进行
+	if !opts.RenderOnly && !opts.SkipChecks {
+		c.printf("🔍 执行就绪检查...\n")
+		if err := gate.RunTo(c.out, cfg, gate.BeforeGenerateISO(c.ClusterDir(clusterName))); err != nil {
+			return nil, err
+		}
+	}
+
+	options := &iso.GenerateOptions{
+		OutputPath:   opts.OutputPath,
+		BaseISOPath:  opts.BaseISOPath,
+		SkipVerify:   opts.SkipVerify,
+		Force:        opts.Force,
+		RenderOnly:   opts.RenderOnly,
+		Unconfigured: opts.Unconfigured,
+	}
+	if err := generator.GenerateISO(ctx, options); err != nil {
+		return nil, i18n.Errorf("ISO 生成失败: %w", err)
+	}
+
+	installDir := filepath.Join(c.ClusterDir(clusterName), "installation")
+	return &GenerateISOResult{
+		InstallDir:  installDir,
+		ISODir:      filepath.Join(installDir, "iso"),
+		IgnitionDir: filepath.Join(installDir, "ignition"),
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Package ocpack 是供其他 Go 工具嵌入使用的稳定 API，提供 download、save-image、load-image、
+// generate-iso、deploy-bastion 和 deploy-registry 的类型化选项和结果。
+//
+// 与命令行不同，Client 不读取当前工作目录、不调用 os.Exit，全部进度输出写入 Options.Out；
+// 长时间运行的操作接受 context.Context，取消或超时时终止正在执行的外部命令。
+// ocpack 命令行是这些函数的薄封装，阶段钩子、集群锁和报告仍由命令行负责。
+//
+//	client, err := ocpack.New(ocpack.Options{ProjectRoot: "/srv/ocpack", Out: logWriter})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := client.SaveImages(ctx, "demo", ocpack.SaveImagesOptions{})
+package ocpack
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/mirror/progress"
+)
+
+// Options 创建 Client 的选项
+type Options struct {
+	// ProjectRoot 项目目录，每个集群是其中的一个子目录 (ocpack new 创建)，必填
+	ProjectRoot string
+	// Out 接收全部进度输出，为 nil 时丢弃
+	Out io.Writer
+	// Verbosity save-image、load-image 中 oc-mirror 日志的详细程度，默认 progress.Normal
+	Verbosity progress.Verbosity
+	// Version 写入 save-image 传输清单的工具版本，为空时为 "ocpack"
+	Version string
+}
+
+// Client 在一个项目目录中执行 ocpack 的各个阶段，可以被多个 goroutine 同时使用，
+// 但同一集群的操作应由调用方串行执行 (命令行使用集群锁)
+type Client struct {
+	projectRoot string
+	out         io.Writer
+	verbosity   progress.Verbosity
+	version     string
+}
+
+// New 创建 Client
+func New(opts Options) (*Client, error) {
+	if opts.ProjectRoot == "" {
+		return nil, clierr.New(clierr.Config, i18n.Errorf("未指定项目目录 (ProjectRoot)"))
+	}
+	projectRoot, err := filepath.Abs(opts.ProjectRoot)
+	if err != nil {
+		return nil, i18n.Errorf("解析项目目录失败: %w", err)
+	}
+	out := opts.Out
+	if out == nil {
+		out = io.Discard
+	}
+	version := opts.Version
+	if version == "" {
+		version = "ocpack"
+	}
+	return &Client{projectRoot: projectRoot, out: out, verbosity: opts.Verbosity, version: version}, nil
+}
+
+// ProjectRoot 返回项目目录的绝对路径
+func (c *Client) ProjectRoot() string {
+	return c.projectRoot
+}
+
+// ClusterDir 返回集群目录的绝对路径，不检查目录是否存在
+func (c *Client) ClusterDir(clusterName string) string {
+	return filepath.Join(c.projectRoot, clusterName)
+}
+
+// LoadConfig 加载集群的 config.toml，返回配置和配置文件路径。集群目录不存在时返回 clierr.Config 类别的错误
+func (c *Client) LoadConfig(clusterName string) (*config.ClusterConfig, string, error) {
+	clusterDir := c.ClusterDir(clusterName)
+	if _, err := os.Stat(clusterDir); os.IsNotExist(err) {
+		return nil, "", clierr.New(clierr.Config, i18n.Errorf("集群目录不存在: %s", clusterDir))
+	}
+	configPath := filepath.Join(clusterDir, "config.toml")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, "", i18n.Errorf("配置文件不存在: %s", configPath)
+	}
+	cfg, err := config.LoadConfigTo(c.out, configPath)
+	if err != nil {
+		return nil, "", i18n.Errorf("加载配置失败: %w", err)
+	}
+	return cfg, configPath, nil
+}
+
+// printf 向进度输出写入一行
+func (c *Client) printf(format string, args ...any) {
+	i18n.Fprintf(c.out, format, args...)
+}
@@ -0,0 +1,132 @@
+package ocpack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+)
+
+// newTestClient 在临时项目目录中创建集群 demo，modify 可修改保存前的配置
+func newTestClient(t *testing.T, modify func(cfg *config.ClusterConfig)) (*Client, *strings.Builder) {
+	t.Helper()
+	projectRoot := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	if modify != nil {
+		modify(cfg)
+	}
+	if err := os.MkdirAll(filepath.Join(projectRoot, "demo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveConfig(cfg, filepath.Join(projectRoot, "demo", "config.toml")); err != nil {
+		t.Fatal(err)
+	}
+	out := &strings.Builder{}
+	client, err := New(Options{ProjectRoot: projectRoot, Out: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, out
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Options{}); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("New() without ProjectRoot error = %v, expected a config error", err)
+	}
+	client, err := New(Options{ProjectRoot: "projects"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filepath.IsAbs(client.ProjectRoot()) || client.out == nil {
+		t.Errorf("New() = %+v, expected an absolute project root and a discarding writer", client)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	if _, _, err := client.LoadConfig("missing"); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("LoadConfig() for a missing cluster error = %v, expected a config error", err)
+	}
+	cfg, configPath, err := client.LoadConfig("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClusterInfo.ClusterID != "demo" || configPath != filepath.Join(client.ProjectRoot(), "demo", "config.toml") {
+		t.Errorf("LoadConfig() = %s from %s", cfg.ClusterInfo.ClusterID, configPath)
+	}
+}
+
+func TestLoadConfigMigrationHint(t *testing.T) {
+	// 旧版本 (未声明 config_version) 配置的升级提示写入 Options.Out
+	client, out := newTestClient(t, nil)
+	configPath := filepath.Join(client.ProjectRoot(), "demo", "config.toml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := regexp.MustCompile(`(?m)^config_version = \d+\n`).ReplaceAll(data, nil)
+	if err := os.WriteFile(configPath, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.LoadConfig("demo"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "ocpack migrate-config") {
+		t.Errorf("expected the migration hint in Options.Out, got %q", out.String())
+	}
+}
+
+func TestSkippedStages(t *testing.T) {
+	disabled := false
+	client, out := newTestClient(t, func(cfg *config.ClusterConfig) {
+		cfg.Bastion.Enabled = &disabled
+		cfg.Registry.MirrorMode = config.MirrorModeProxyCache
+	})
+	ctx := context.Background()
+
+	deployed, err := client.DeployBastion(ctx, "demo")
+	if err != nil || !deployed.Skipped {
+		t.Errorf("DeployBastion() = %+v, %v, expected it to be skipped", deployed, err)
+	}
+	saved, err := client.SaveImages(ctx, "demo", SaveImagesOptions{})
+	if err != nil || !saved.Skipped {
+		t.Errorf("SaveImages() = %+v, %v, expected it to be skipped", saved, err)
+	}
+	loaded, err := client.LoadImages(ctx, "demo", LoadImagesOptions{})
+	if err != nil || !loaded.Skipped {
+		t.Errorf("LoadImages() = %+v, %v, expected it to be skipped", loaded, err)
+	}
+	if !strings.Contains(out.String(), "跳过 Bastion 部署") || !strings.Contains(out.String(), "跳过 save-image") {
+		t.Errorf("progress output was not written to Options.Out:\n%s", out)
+	}
+}
+
+func TestMirrorOptionsValidate(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.MaxCacheSize = "100Gi"
+	tests := []struct {
+		name    string
+		opts    MirrorOptions
+		want    int64
+		wantErr bool
+	}{
+		{"config max_cache_size", MirrorOptions{}, 100 << 30, false},
+		{"override", MirrorOptions{MaxCacheSize: "1Gi"}, 1 << 30, false},
+		{"bad size", MirrorOptions{MaxCacheSize: "lots"}, 0, true},
+		{"bad group", MirrorOptions{Only: []string{"everything"}}, 0, true},
+		{"negative attempts", MirrorOptions{CopyAttempts: -1}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.validate(cfg)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("validate() = %d, %v, want %d (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
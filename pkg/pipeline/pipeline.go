@@ -0,0 +1,126 @@
+// Package pipeline 编排相互独立的部署阶段 (如 Bastion 和 Registry 部署) 并行执行，
+// 各阶段的输出按行加上阶段名称前缀后交错写入同一终端，失败的阶段汇总为一个错误返回。
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stage 一个可以与其他阶段并行执行的部署阶段
+type Stage struct {
+	Name string                    // 阶段名称，用作输出前缀
+	Run  func(out io.Writer) error // 阶段的全部输出都应写入 out
+}
+
+// StageError 阶段执行失败的错误
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// RunParallel 并行执行 stages 并等待全部完成。每个阶段的输出以 "[名称] " 为前缀按行写入 out，
+// 一个阶段失败不会中断其他阶段；返回的错误由全部失败阶段的 *StageError 组成 (errors.Join)
+func RunParallel(out io.Writer, stages ...Stage) error {
+	var mu sync.Mutex
+	width := 0
+	for _, stage := range stages {
+		width = max(width, len(stage.Name))
+	}
+
+	errs := make([]error, len(stages))
+	durations := make([]time.Duration, len(stages))
+	var wg sync.WaitGroup
+	for i, stage := range stages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &prefixWriter{mu: &mu, out: out, prefix: fmt.Sprintf("[%-*s] ", width, stage.Name)}
+			start := time.Now()
+			if err := stage.Run(w); err != nil {
+				errs[i] = &StageError{Stage: stage.Name, Err: err}
+			}
+			durations[i] = time.Since(start)
+			w.Flush()
+		}()
+	}
+	wg.Wait()
+
+	fmt.Fprintln(out, "\n📋 并行部署结果:")
+	for i, stage := range stages {
+		status := "✅ 成功"
+		if errs[i] != nil {
+			status = "❌ 失败"
+		}
+		fmt.Fprintf(out, "   %-*s  %s (%s)\n", width, stage.Name, status, durations[i].Round(time.Second))
+	}
+	return errors.Join(errs...)
+}
+
+// Failed 判断 RunParallel 返回的错误中名为 stage 的阶段是否失败
+func Failed(err error, stage string) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return false
+	}
+	for _, e := range joined.Unwrap() {
+		var stageErr *StageError
+		if errors.As(e, &stageErr) && stageErr.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixWriter 为每一行输出加上前缀。不完整的行缓存到换行或 Flush 时再写出，
+// 多个 prefixWriter 共享同一把锁，保证不同阶段的行不会互相截断
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// ansible 等命令同时写 stdout 和 stderr，buf 也需要在锁内访问
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// 不完整的行放回缓冲区
+			w.buf.WriteString(line)
+			break
+		}
+		if _, err := io.WriteString(w.out, w.prefix+line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush 写出缓冲区中没有换行结尾的内容
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() == 0 {
+		return
+	}
+	io.WriteString(w.out, w.prefix+strings.TrimRight(w.buf.String(), "\r")+"\n")
+	w.buf.Reset()
+}
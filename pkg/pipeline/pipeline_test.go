@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunParallelPrefixesOutput(t *testing.T) {
+	var out strings.Builder
+	started := make(chan struct{})
+	err := RunParallel(&out,
+		Stage{Name: "bastion", Run: func(w io.Writer) error {
+			fmt.Fprint(w, "first ")
+			close(started)
+			fmt.Fprintln(w, "line")
+			fmt.Fprint(w, "no newline")
+			return nil
+		}},
+		Stage{Name: "registry", Run: func(w io.Writer) error {
+			<-started
+			fmt.Fprintln(w, "a\nb")
+			return nil
+		}},
+	)
+	if err != nil {
+		t.Fatalf("RunParallel() error = %v", err)
+	}
+
+	for _, expected := range []string{
+		"[bastion ] first line\n",
+		"[bastion ] no newline\n",
+		"[registry] a\n",
+		"[registry] b\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output missing %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestRunParallelAggregatesErrors(t *testing.T) {
+	errBastion := errors.New("playbook failed")
+	errRegistry := errors.New("unreachable")
+	ran := make(chan string, 3)
+
+	err := RunParallel(io.Discard,
+		Stage{Name: "bastion", Run: func(io.Writer) error { ran <- "bastion"; return errBastion }},
+		Stage{Name: "registry", Run: func(io.Writer) error { ran <- "registry"; return errRegistry }},
+		Stage{Name: "pxe", Run: func(io.Writer) error { ran <- "pxe"; return nil }},
+	)
+	if len(ran) != 3 {
+		t.Errorf("expected all stages to run despite failures, ran %d", len(ran))
+	}
+	if !errors.Is(err, errBastion) || !errors.Is(err, errRegistry) {
+		t.Fatalf("RunParallel() error = %v, expected both stage errors", err)
+	}
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "bastion" {
+		t.Errorf("expected first StageError for bastion, got %v", stageErr)
+	}
+	if !Failed(err, "bastion") || !Failed(err, "registry") || Failed(err, "pxe") {
+		t.Errorf("Failed() reports wrong stages for %v", err)
+	}
+	if strings.Contains(err.Error(), "pxe") {
+		t.Errorf("successful stage reported as failed: %v", err)
+	}
+}
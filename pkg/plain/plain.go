@@ -0,0 +1,209 @@
+// Package plain 提供纯 ASCII 输出模式。串口控制台和部分 CI 系统无法显示 emoji 和制表符，
+// 启用后进程的标准输出和标准错误经过 Writer 转换: 状态图标替换为 [OK]、[WARN] 等标记，
+// 制表符替换为 -、|、+，其他装饰性 emoji 直接删除，文字不做转换。Detect 自动启用的环境 (未设置 locale、
+// C/POSIX 等非 UTF-8 locale) 同样无法显示中文，i18n.Detect 在这些环境中选择英文输出。
+package plain
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+var (
+	mu       sync.Mutex
+	enabled  bool
+	terminal bool
+	stdout   = os.Stdout
+	stderr   = os.Stderr
+)
+
+// Detect 根据环境判断是否应使用纯 ASCII 输出: TERM=dumb，或 LC_ALL、LC_CTYPE、LANG 中
+// 第一个非空的值不是 UTF-8 locale (包括未设置 locale 时的 C/POSIX)
+func Detect() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return !strings.Contains(value, "utf-8") && !strings.Contains(value, "utf8")
+		}
+	}
+	return true
+}
+
+// Enabled 返回是否已启用纯 ASCII 输出
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Enable 启用纯 ASCII 输出: os.Stdout 和 os.Stderr 替换为管道，由后台 goroutine 转换后写入原来的文件。
+// 返回的 restore 关闭管道、等待已写入的输出全部转换完成并恢复 os.Stdout 和 os.Stderr，进程退出前必须调用
+func Enable() (restore func(), err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		return func() {}, nil
+	}
+
+	terminal = term.IsTerminal(int(os.Stdout.Fd()))
+	stdout, stderr = os.Stdout, os.Stderr
+	var wg sync.WaitGroup
+	var pipes []*os.File
+	redirect := func(target *os.File) (*os.File, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		pipes = append(pipes, w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Close()
+			io.Copy(NewWriter(target), r)
+		}()
+		return w, nil
+	}
+	outPipe, err := redirect(stdout)
+	if err != nil {
+		return nil, err
+	}
+	errPipe, err := redirect(stderr)
+	if err != nil {
+		outPipe.Close()
+		wg.Wait()
+		return nil, err
+	}
+	os.Stdout, os.Stderr = outPipe, errPipe
+	enabled = true
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		os.Stdout, os.Stderr = stdout, stderr
+		for _, pipe := range pipes {
+			pipe.Close()
+		}
+		wg.Wait()
+		enabled = false
+	}, nil
+}
+
+// Stdout 返回进程原来的标准输出。编辑器、oc 等交互式子进程需要直接使用终端，不经过转换
+func Stdout() *os.File {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		return stdout
+	}
+	return os.Stdout
+}
+
+// Stderr 返回进程原来的标准错误
+func Stderr() *os.File {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		return stderr
+	}
+	return os.Stderr
+}
+
+// IsTerminal 返回原来的标准输出是否为终端。启用纯 ASCII 输出后 os.Stdout 是管道，
+// 进度显示需要以此判断是否可以刷新同一行
+func IsTerminal() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		return terminal
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// replacements 有明确含义的符号对应的 ASCII 文本，其他符号类字符 (unicode.So) 直接删除
+var replacements = map[rune]string{
+	'✅': "[OK]", '✓': "[OK]", '✔': "[OK]", '🎉': "[OK]",
+	'❌': "[FAIL]", '✗': "[FAIL]",
+	'⚠': "[WARN]", '❗': "[!]", '❓': "[?]",
+	'💡': "[HINT]", 'ℹ': "[INFO]",
+	'⏳': "[WAIT]", '⏭': "[SKIP]",
+	'•': "*", '●': "*", '■': "*", '◼': "*", '▣': "*",
+	'→': "->", '➡': "->", '▶': ">", '►': ">", '⬇': "v", '←': "<-", '↩': "<-",
+	'═': "=", '━': "=", '─': "-",
+	'║': "|", '│': "|",
+	'█': "#", '░': ".",
+}
+
+// drop 转换时删除的不可见字符: emoji 变体选择符和零宽连接符
+func drop(r rune) bool {
+	return r == '\uFE0F' || r == '\uFE0E' || r == '\u200D'
+}
+
+// writer 将 UTF-8 输出转换为纯 ASCII 符号，跨 Write 调用的不完整 UTF-8 序列保留到下一次写入
+type writer struct {
+	out       io.Writer
+	pending   []byte
+	skipSpace bool // 刚删除了一个图标，跳过其后的空格避免行首多出空格
+}
+
+// NewWriter 返回将 emoji 和制表符转换为 ASCII 后写入 out 的 Writer
+func NewWriter(out io.Writer) io.Writer {
+	return &writer{out: out}
+}
+
+// Write 转换并写入 p，返回值总是 len(p)，便于与 io.Copy 等配合
+func (w *writer) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	w.pending = nil
+	var buf bytes.Buffer
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 && !utf8.FullRune(data) {
+			w.pending = append([]byte(nil), data...)
+			break
+		}
+		text := data[:size]
+		data = data[size:]
+		switch {
+		case r < utf8.RuneSelf:
+			if r == ' ' && w.skipSpace {
+				w.skipSpace = false
+				continue
+			}
+			buf.Write(text)
+		case drop(r):
+			continue
+		case replacements[r] != "":
+			buf.WriteString(replacements[r])
+		case r >= 0x2500 && r <= 0x257F:
+			// 其余制表符为转角和交叉
+			buf.WriteByte('+')
+		case unicode.Is(unicode.So, r):
+			w.skipSpace = true
+			continue
+		default:
+			buf.Write(text)
+		}
+		w.skipSpace = false
+	}
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// String 返回 s 转换为纯 ASCII 符号后的结果
+func String(s string) string {
+	var b strings.Builder
+	NewWriter(&b).Write([]byte(s))
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package plain
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/i18n"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"✅ 镜像保存完成", "[OK] 镜像保存完成"},
+		{"⚠️  磁盘空间不足", "[WARN]  磁盘空间不足"},
+		{"💡 请执行 ocpack sync-dns demo", "[HINT] 请执行 ocpack sync-dns demo"},
+		{"🔍 检查集群 DNS 记录...", "检查集群 DNS 记录..."},
+		{"release ➡️ registry", "release -> registry"},
+		{"╔══╗\n║ok║\n╚══╝", "+==+\n|ok|\n+==+"},
+		{"├── 📁 images", "+-- images"},
+		{"plain ASCII text", "plain ASCII text"},
+	}
+	for _, tt := range tests {
+		if got := String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriterSplitRunes(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b)
+	input := []byte("✅ 完成 🎉")
+	for i := range input {
+		if n, err := w.Write(input[i : i+1]); n != 1 || err != nil {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+	if got := b.String(); got != "[OK] 完成 [OK]" {
+		t.Errorf("byte-by-byte writes produced %q", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		term, lcAll, lang string
+		want              bool
+	}{
+		{"xterm", "", "en_US.UTF-8", false},
+		{"xterm", "", "zh_CN.utf8", false},
+		{"xterm", "C", "en_US.UTF-8", true},
+		{"xterm", "", "", true},
+		{"dumb", "", "en_US.UTF-8", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("TERM", tt.term)
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(); got != tt.want {
+			t.Errorf("Detect() with TERM=%q LC_ALL=%q LANG=%q = %t, want %t", tt.term, tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
+
+// TestDetectSelectsEnglish 自动启用纯 ASCII 输出的 locale 无法显示中文，i18n.Detect 应同时选择英文
+func TestDetectSelectsEnglish(t *testing.T) {
+	tests := []struct {
+		lang      string
+		wantPlain bool
+		wantLang  string
+	}{
+		{"", true, i18n.English},
+		{"C", true, i18n.English},
+		{"POSIX", true, i18n.English},
+		{"C.UTF-8", false, i18n.Chinese},
+		{"zh_CN.UTF-8", false, i18n.Chinese},
+		{"en_US.UTF-8", false, i18n.English},
+	}
+	for _, tt := range tests {
+		t.Setenv("TERM", "xterm")
+		for _, name := range []string{"OCPACK_LANG", "LC_ALL", "LC_CTYPE", "LC_MESSAGES"} {
+			t.Setenv(name, "")
+		}
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(); got != tt.wantPlain {
+			t.Errorf("Detect() with LANG=%q = %t, want %t", tt.lang, got, tt.wantPlain)
+		}
+		if got := i18n.Detect(); got != tt.wantLang {
+			t.Errorf("i18n.Detect() with LANG=%q = %q, want %q", tt.lang, got, tt.wantLang)
+		}
+	}
+}
+
+func TestEnable(t *testing.T) {
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	original := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = original }()
+
+	restore, err := Enable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Enabled() || Stdout() != out || IsTerminal() {
+		t.Errorf("Enable() did not record the original stdout")
+	}
+	fmt.Println("✅ 部署完成")
+	restore()
+
+	if Enabled() || os.Stdout != out {
+		t.Errorf("restore() did not restore os.Stdout")
+	}
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[OK] 部署完成\n" {
+		t.Errorf("stdout = %q", data)
+	}
+}
@@ -0,0 +1,428 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"ocpack/pkg/config"
+)
+
+// OperatorReport Operator 依赖解析报告
+type OperatorReport struct {
+	Cluster  string              `json:"cluster"`
+	Catalogs []CatalogResolution `json:"catalogs"`
+}
+
+// CatalogResolution 一个目录中 ops 及其依赖的解析结果
+type CatalogResolution struct {
+	Catalog string `json:"catalog"`
+	// Source 读取的 FBC 目录
+	Source   string              `json:"source"`
+	Packages []PackageResolution `json:"packages"`
+	// Missing 依赖但未在 ops 中列出的包，oc-mirror 不会镜像，安装时 OLM 无法满足依赖
+	Missing []string `json:"missing,omitempty"`
+	// Unresolved 目录中找不到满足条件的包或 API 的依赖
+	Unresolved []string `json:"unresolved,omitempty"`
+	// RelatedImages 所选 bundle 的镜像和相关镜像去重后的数量
+	RelatedImages int `json:"related_images"`
+}
+
+// PackageResolution 一个包的解析结果
+type PackageResolution struct {
+	Package       string   `json:"package"`
+	Channel       string   `json:"channel"`
+	Bundle        string   `json:"bundle"`
+	Version       string   `json:"version"`
+	Requested     bool     `json:"requested"`
+	RequiredBy    []string `json:"required_by,omitempty"`
+	Requires      []string `json:"requires,omitempty"`
+	RelatedImages int      `json:"related_images"`
+}
+
+// OperatorCatalogsDir 返回 oc-mirror 解压 Operator 目录的工作目录
+func OperatorCatalogsDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "working-dir", "operator-catalogs")
+}
+
+// CatalogConfigDir 返回 oc-mirror 在 catalogsDir 中为目录解压的完整 FBC，
+// 目录的摘要变化后会有多个解压结果，使用最近更新的一个
+func CatalogConfigDir(catalogsDir string, catalog config.OperatorCatalog) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(catalogsDir, catalogComponentName(catalog), "*", "catalog-config"))
+	if err != nil {
+		return "", err
+	}
+	var latest string
+	var latestTime int64
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if t := info.ModTime().UnixNano(); latest == "" || t > latestTime {
+			latest, latestTime = match, t
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("未找到目录 %s 的 FBC (%s)，请先执行 dry-run", catalog.Catalog, catalogsDir)
+	}
+	return latest, nil
+}
+
+// catalogComponentName oc-mirror 保存目录时使用的名称：仓库路径的最后一段 (不含标签和摘要)
+func catalogComponentName(catalog config.OperatorCatalog) string {
+	ref := catalog.Catalog
+	if catalog.IsOCI() {
+		return filepath.Base(catalog.OCIPath(""))
+	}
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		ref = ref[:idx]
+	}
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		ref = ref[:idx]
+	}
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// LoadCatalog 读取目录中的 FBC，ctx 取消时停止读取
+func LoadCatalog(ctx context.Context, dir string) (*declcfg.DeclarativeConfig, error) {
+	fbc, err := declcfg.LoadFS(ctx, os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("读取 FBC %s 失败: %w", dir, err)
+	}
+	return fbc, nil
+}
+
+// catalogIndex 按包和 API 索引的 FBC
+type catalogIndex struct {
+	packages  map[string]declcfg.Package
+	channels  map[string][]declcfg.Channel // 包名 -> 通道
+	bundles   map[string]*indexedBundle    // 包名/bundle 名 -> bundle
+	providers map[string][]string          // group/version/kind -> 提供该 API 的包
+}
+
+type indexedBundle struct {
+	declcfg.Bundle
+	version    semver.Version
+	properties *property.Properties
+}
+
+func newCatalogIndex(fbc *declcfg.DeclarativeConfig) (*catalogIndex, error) {
+	idx := &catalogIndex{
+		packages:  map[string]declcfg.Package{},
+		channels:  map[string][]declcfg.Channel{},
+		bundles:   map[string]*indexedBundle{},
+		providers: map[string][]string{},
+	}
+	for _, pkg := range fbc.Packages {
+		idx.packages[pkg.Name] = pkg
+	}
+	for _, ch := range fbc.Channels {
+		idx.channels[ch.Package] = append(idx.channels[ch.Package], ch)
+	}
+	for _, b := range fbc.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("解析 bundle %s 的属性失败: %w", b.Name, err)
+		}
+		bundle := &indexedBundle{Bundle: b, properties: props}
+		if len(props.Packages) > 0 {
+			bundle.version, _ = semver.ParseTolerant(props.Packages[0].Version)
+		}
+		idx.bundles[b.Package+"/"+b.Name] = bundle
+		for _, gvk := range props.GVKs {
+			key := gvkKey(gvk.Group, gvk.Version, gvk.Kind)
+			if !contains(idx.providers[key], b.Package) {
+				idx.providers[key] = append(idx.providers[key], b.Package)
+			}
+		}
+	}
+	for key := range idx.providers {
+		sort.Strings(idx.providers[key])
+	}
+	return idx, nil
+}
+
+func gvkKey(group, version, kind string) string {
+	return group + "/" + version + "/" + kind
+}
+
+// head 返回通道的最新 bundle：没有被同一通道中其他条目 replaces 或 skips 的条目，有多个时取版本最高的
+func (idx *catalogIndex) head(ch declcfg.Channel) *indexedBundle {
+	replaced := map[string]bool{}
+	for _, entry := range ch.Entries {
+		replaced[entry.Replaces] = true
+		for _, skip := range entry.Skips {
+			replaced[skip] = true
+		}
+	}
+	var head *indexedBundle
+	for _, entry := range ch.Entries {
+		bundle := idx.bundles[ch.Package+"/"+entry.Name]
+		if replaced[entry.Name] || bundle == nil {
+			continue
+		}
+		if head == nil || bundle.version.GT(head.version) {
+			head = bundle
+		}
+	}
+	return head
+}
+
+// defaultHead 返回包默认通道的最新 bundle
+func (idx *catalogIndex) defaultHead(pkg string) (string, *indexedBundle) {
+	channel := idx.packages[pkg].DefaultChannel
+	for _, ch := range idx.channels[pkg] {
+		if ch.Name == channel {
+			return channel, idx.head(ch)
+		}
+	}
+	return channel, nil
+}
+
+// selectInRange 选择包中满足版本范围的 bundle：优先使用默认通道的最新 bundle，否则使用版本最高的 bundle
+func (idx *catalogIndex) selectInRange(pkg string, versionRange semver.Range) (string, *indexedBundle) {
+	channel, head := idx.defaultHead(pkg)
+	if head != nil && versionRange(head.version) {
+		return channel, head
+	}
+	var (
+		bestChannel string
+		best        *indexedBundle
+	)
+	for _, ch := range idx.channels[pkg] {
+		for _, entry := range ch.Entries {
+			bundle := idx.bundles[pkg+"/"+entry.Name]
+			if bundle == nil || !versionRange(bundle.version) {
+				continue
+			}
+			if best == nil || bundle.version.GT(best.version) || (bundle == best && ch.Name == channel) {
+				bestChannel, best = ch.Name, bundle
+			}
+		}
+	}
+	return bestChannel, best
+}
+
+// ResolveOperators 按 OLM 的方式解析 packages 的依赖：每个包选择默认通道的最新 bundle，
+// 再按 bundle 的 olm.package.required 和 olm.gvk.required 依次选择依赖的包。
+// gvk 依赖优先使用已选择的包，否则使用提供该 API 的第一个包 (按名称排序)
+func ResolveOperators(catalog string, fbc *declcfg.DeclarativeConfig, packages []string) (*CatalogResolution, error) {
+	idx, err := newCatalogIndex(fbc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CatalogResolution{Catalog: catalog}
+	selected := map[string]*PackageResolution{}
+	var order []string
+	var queue []string
+	requested := map[string]bool{}
+
+	choose := func(pkg, channel string, bundle *indexedBundle, requiredBy string) {
+		if res, ok := selected[pkg]; ok {
+			if requiredBy != "" && !contains(res.RequiredBy, requiredBy) {
+				res.RequiredBy = append(res.RequiredBy, requiredBy)
+			}
+			return
+		}
+		res := &PackageResolution{
+			Package:       pkg,
+			Channel:       channel,
+			Bundle:        bundle.Name,
+			Version:       bundle.version.String(),
+			Requested:     requested[pkg],
+			RelatedImages: len(bundleImages(bundle)),
+		}
+		if requiredBy != "" {
+			res.RequiredBy = []string{requiredBy}
+		}
+		selected[pkg] = res
+		order = append(order, pkg)
+		queue = append(queue, pkg)
+	}
+
+	for _, pkg := range packages {
+		requested[pkg] = true
+	}
+	for _, pkg := range packages {
+		if _, ok := idx.packages[pkg]; !ok {
+			result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s: 目录中没有该包", pkg))
+			continue
+		}
+		channel, head := idx.defaultHead(pkg)
+		if head == nil {
+			result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s: 默认通道 %s 中没有 bundle", pkg, channel))
+			continue
+		}
+		choose(pkg, channel, head, "")
+	}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		res := selected[pkg]
+		bundle := idx.bundles[pkg+"/"+res.Bundle]
+
+		for _, req := range bundle.properties.PackagesRequired {
+			versionRange, err := semver.ParseRange(req.VersionRange)
+			if err != nil {
+				result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s 依赖的 %s: 无法解析版本范围 %q", pkg, req.PackageName, req.VersionRange))
+				continue
+			}
+			channel, dep := idx.selectInRange(req.PackageName, versionRange)
+			if dep == nil {
+				result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s 依赖的 %s %s: 目录中没有满足条件的 bundle", pkg, req.PackageName, req.VersionRange))
+				continue
+			}
+			res.Requires = appendUnique(res.Requires, req.PackageName)
+			choose(req.PackageName, channel, dep, pkg)
+		}
+
+		for _, req := range bundle.properties.GVKsRequired {
+			key := gvkKey(req.Group, req.Version, req.Kind)
+			providers := idx.providers[key]
+			if len(providers) == 0 {
+				result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s 依赖的 API %s: 目录中没有提供该 API 的包", pkg, key))
+				continue
+			}
+			provider := providers[0]
+			for _, p := range providers {
+				if _, ok := selected[p]; ok {
+					provider = p
+					break
+				}
+			}
+			if provider == pkg {
+				continue
+			}
+			res.Requires = appendUnique(res.Requires, provider)
+			if _, ok := selected[provider]; ok {
+				choose(provider, "", nil, pkg)
+				continue
+			}
+			channel, head := idx.defaultHead(provider)
+			if head == nil {
+				result.Unresolved = append(result.Unresolved, fmt.Sprintf("%s 依赖的 API %s: 提供者 %s 的默认通道中没有 bundle", pkg, key, provider))
+				continue
+			}
+			choose(provider, channel, head, pkg)
+		}
+	}
+
+	images := map[string]bool{}
+	for _, pkg := range order {
+		res := selected[pkg]
+		result.Packages = append(result.Packages, *res)
+		if !res.Requested {
+			result.Missing = append(result.Missing, pkg)
+		}
+		for _, image := range bundleImages(idx.bundles[pkg+"/"+res.Bundle]) {
+			images[image] = true
+		}
+	}
+	result.RelatedImages = len(images)
+	return result, nil
+}
+
+// bundleImages 返回 bundle 镜像和相关镜像，去重后保持顺序
+func bundleImages(bundle *indexedBundle) []string {
+	var images []string
+	if bundle.Image != "" {
+		images = append(images, bundle.Image)
+	}
+	for _, related := range bundle.RelatedImages {
+		if related.Image != "" {
+			images = appendUnique(images, related.Image)
+		}
+	}
+	return images
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// BuildOperatorReport 读取 clusterDir 中 oc-mirror 解压的 FBC，解析每个目录的 ops 及其依赖
+func BuildOperatorReport(ctx context.Context, cluster, clusterDir string, catalogs []config.OperatorCatalog) (*OperatorReport, error) {
+	report := &OperatorReport{Cluster: cluster}
+	for _, catalog := range catalogs {
+		dir, err := CatalogConfigDir(OperatorCatalogsDir(clusterDir), catalog)
+		if err != nil {
+			return nil, err
+		}
+		fbc, err := LoadCatalog(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		resolution, err := ResolveOperators(catalog.Catalog, fbc, catalog.Ops)
+		if err != nil {
+			return nil, fmt.Errorf("解析目录 %s 失败: %w", catalog.Catalog, err)
+		}
+		resolution.Source = dir
+		report.Catalogs = append(report.Catalogs, *resolution)
+	}
+	return report, nil
+}
+
+// WriteOperatorReport 按指定格式输出依赖解析报告
+func WriteOperatorReport(w io.Writer, report *OperatorReport, format string) error {
+	switch format {
+	case "text":
+		return writeOperatorText(w, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeOperatorText(w io.Writer, report *OperatorReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "集群 %s Operator 依赖解析\n", report.Cluster)
+	for _, catalog := range report.Catalogs {
+		fmt.Fprintf(&b, "\n目录 %s (%d 个包，%d 个 bundle 及相关镜像):\n", catalog.Catalog, len(catalog.Packages), catalog.RelatedImages)
+		for _, pkg := range catalog.Packages {
+			mark := "  "
+			if !pkg.Requested {
+				mark = "+ "
+			}
+			fmt.Fprintf(&b, "  %s%-40s %-20s %-12s %3d 个镜像", mark, pkg.Package, pkg.Channel, pkg.Version, pkg.RelatedImages)
+			if len(pkg.RequiredBy) > 0 {
+				fmt.Fprintf(&b, "  ← %s", strings.Join(pkg.RequiredBy, ", "))
+			}
+			b.WriteString("\n")
+		}
+		if len(catalog.Missing) > 0 {
+			fmt.Fprintf(&b, "⚠️  以下依赖未在 ops 中列出 (+)，oc-mirror 不会镜像，安装时 OLM 无法满足依赖，请加入 ops: %s\n", strings.Join(catalog.Missing, ", "))
+		}
+		for _, unresolved := range catalog.Unresolved {
+			fmt.Fprintf(&b, "❌ %s\n", unresolved)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,187 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"ocpack/pkg/config"
+)
+
+// testBundle 生成带版本、依赖和提供 API 的 bundle
+func testBundle(t *testing.T, pkg, version string, requires []property.PackageRequired, gvks, gvksRequired []string, related ...string) declcfg.Bundle {
+	t.Helper()
+	props := []property.Property{property.MustBuildPackage(pkg, version)}
+	for _, req := range requires {
+		props = append(props, property.MustBuildPackageRequired(req.PackageName, req.VersionRange))
+	}
+	for _, gvk := range gvks {
+		parts := strings.Split(gvk, "/")
+		props = append(props, property.MustBuildGVK(parts[0], parts[1], parts[2]))
+	}
+	for _, gvk := range gvksRequired {
+		parts := strings.Split(gvk, "/")
+		props = append(props, property.MustBuildGVKRequired(parts[0], parts[1], parts[2]))
+	}
+	bundle := declcfg.Bundle{
+		Schema:     declcfg.SchemaBundle,
+		Name:       pkg + ".v" + version,
+		Package:    pkg,
+		Image:      "registry.example.com/" + pkg + "-bundle:v" + version,
+		Properties: props,
+	}
+	for _, image := range related {
+		bundle.RelatedImages = append(bundle.RelatedImages, declcfg.RelatedImage{Image: image})
+	}
+	return bundle
+}
+
+func testCatalog(t *testing.T) *declcfg.DeclarativeConfig {
+	return &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "app-operator", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "cert-operator", DefaultChannel: "stable-v1"},
+			{Schema: declcfg.SchemaPackage, Name: "storage-operator", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "metrics-operator", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			{Schema: declcfg.SchemaChannel, Name: "stable", Package: "app-operator", Entries: []declcfg.ChannelEntry{
+				{Name: "app-operator.v1.0.0"},
+				{Name: "app-operator.v1.1.0", Replaces: "app-operator.v1.0.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Name: "stable-v1", Package: "cert-operator", Entries: []declcfg.ChannelEntry{
+				{Name: "cert-operator.v1.5.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Name: "stable-v2", Package: "cert-operator", Entries: []declcfg.ChannelEntry{
+				{Name: "cert-operator.v2.1.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Name: "stable", Package: "storage-operator", Entries: []declcfg.ChannelEntry{
+				{Name: "storage-operator.v4.16.0"},
+			}},
+			{Schema: declcfg.SchemaChannel, Name: "stable", Package: "metrics-operator", Entries: []declcfg.ChannelEntry{
+				{Name: "metrics-operator.v0.9.0"},
+			}},
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle(t, "app-operator", "1.0.0", nil, nil, nil),
+			testBundle(t, "app-operator", "1.1.0",
+				[]property.PackageRequired{{PackageName: "cert-operator", VersionRange: ">=2.0.0"}},
+				nil, []string{"storage.example.com/v1/Volume"},
+				"registry.example.com/app:v1.1", "registry.example.com/shared:v1"),
+			testBundle(t, "cert-operator", "1.5.0", nil, nil, nil),
+			testBundle(t, "cert-operator", "2.1.0", nil, nil, nil, "registry.example.com/cert:v2"),
+			testBundle(t, "storage-operator", "4.16.0", nil, []string{"storage.example.com/v1/Volume"}, []string{"monitoring.example.com/v1/Probe"},
+				"registry.example.com/storage:v4.16", "registry.example.com/shared:v1"),
+			testBundle(t, "metrics-operator", "0.9.0", nil, nil, nil),
+		},
+	}
+}
+
+func TestResolveOperators(t *testing.T) {
+	got, err := ResolveOperators("registry.example.com/index:v4.16", testCatalog(t), []string{"app-operator", "storage-operator"})
+	if err != nil {
+		t.Fatalf("ResolveOperators() error = %v", err)
+	}
+
+	want := []PackageResolution{
+		{Package: "app-operator", Channel: "stable", Bundle: "app-operator.v1.1.0", Version: "1.1.0", Requested: true,
+			Requires: []string{"cert-operator", "storage-operator"}, RelatedImages: 3},
+		{Package: "storage-operator", Channel: "stable", Bundle: "storage-operator.v4.16.0", Version: "4.16.0", Requested: true,
+			RequiredBy: []string{"app-operator"}, RelatedImages: 3},
+		// 默认通道 stable-v1 的 1.5.0 不满足 >=2.0.0，使用 stable-v2
+		{Package: "cert-operator", Channel: "stable-v2", Bundle: "cert-operator.v2.1.0", Version: "2.1.0",
+			RequiredBy: []string{"app-operator"}, RelatedImages: 2},
+	}
+	if !reflect.DeepEqual(got.Packages, want) {
+		t.Errorf("Packages =\n%+v\nwant\n%+v", got.Packages, want)
+	}
+	if !reflect.DeepEqual(got.Missing, []string{"cert-operator"}) {
+		t.Errorf("Missing = %v", got.Missing)
+	}
+	if len(got.Unresolved) != 1 || !strings.Contains(got.Unresolved[0], "monitoring.example.com/v1/Probe") {
+		t.Errorf("Unresolved = %v", got.Unresolved)
+	}
+	// 3 个 bundle 镜像 + app、shared、cert、storage
+	if got.RelatedImages != 7 {
+		t.Errorf("RelatedImages = %d, want 7", got.RelatedImages)
+	}
+}
+
+func TestResolveOperatorsUnknownPackage(t *testing.T) {
+	got, err := ResolveOperators("index", testCatalog(t), []string{"missing-operator", "metrics-operator"})
+	if err != nil {
+		t.Fatalf("ResolveOperators() error = %v", err)
+	}
+	if len(got.Packages) != 1 || got.Packages[0].Package != "metrics-operator" || len(got.Missing) != 0 {
+		t.Errorf("Packages = %+v, Missing = %v", got.Packages, got.Missing)
+	}
+	if len(got.Unresolved) != 1 || !strings.HasPrefix(got.Unresolved[0], "missing-operator") {
+		t.Errorf("Unresolved = %v", got.Unresolved)
+	}
+}
+
+func TestBuildOperatorReport(t *testing.T) {
+	clusterDir := t.TempDir()
+	configDir := filepath.Join(OperatorCatalogsDir(clusterDir), "index", "abc123", "catalog-config", "configs")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := declcfg.WriteJSON(*testCatalog(t), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "catalog.json"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalogs := []config.OperatorCatalog{{Catalog: "registry.example.com/redhat/index:v4.16", Ops: []string{"metrics-operator"}}}
+	report, err := BuildOperatorReport(context.Background(), "demo", clusterDir, catalogs)
+	if err != nil {
+		t.Fatalf("BuildOperatorReport() error = %v", err)
+	}
+	if len(report.Catalogs) != 1 || len(report.Catalogs[0].Packages) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	var text bytes.Buffer
+	if err := WriteOperatorReport(&text, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "metrics-operator") || !strings.Contains(text.String(), "stable") {
+		t.Errorf("text output:\n%s", text.String())
+	}
+	var decoded OperatorReport
+	text.Reset()
+	if err := WriteOperatorReport(&text, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(text.Bytes(), &decoded); err != nil || decoded.Cluster != "demo" {
+		t.Errorf("json output = %s, error %v", text.String(), err)
+	}
+
+	if _, err := BuildOperatorReport(context.Background(), "demo", clusterDir, []config.OperatorCatalog{{Catalog: "registry.example.com/other:v4.16"}}); err == nil {
+		t.Error("BuildOperatorReport() without extracted catalog should fail")
+	}
+}
+
+func TestCatalogComponentName(t *testing.T) {
+	tests := map[string]string{
+		"registry.redhat.io/redhat/redhat-operator-index:v4.16": "redhat-operator-index",
+		"registry.example.com/index@sha256:abc":                 "index",
+		"localhost:5000/catalogs/index":                         "index",
+		"oci://catalogs/my-operator-index":                      "my-operator-index",
+	}
+	for catalog, want := range tests {
+		if got := catalogComponentName(config.OperatorCatalog{Catalog: catalog}); got != want {
+			t.Errorf("catalogComponentName(%s) = %s, want %s", catalog, got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats 支持的输出格式
+var Formats = []string{"text", "json"}
+
+// groupTitles 分组在文本输出中的标题
+var groupTitles = map[string]string{
+	GroupRelease:    "Release 镜像",
+	GroupOperator:   "Operator 镜像",
+	GroupAdditional: "附加镜像",
+	GroupHelm:       "Helm 镜像",
+	GroupOther:      "其他镜像",
+}
+
+// Write 按指定格式输出同步计划
+func Write(w io.Writer, p *Plan, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, p)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(p)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeText(w io.Writer, p *Plan) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "集群 %s 镜像同步计划: 共 %d 个镜像\n", p.Cluster, p.Images)
+	for _, group := range p.Groups {
+		fmt.Fprintf(&b, "\n%s (%d 个", groupTitles[group.Name], len(group.Images))
+		if !p.SizesSkipped {
+			fmt.Fprintf(&b, ", %s", formatBytes(group.Size))
+		}
+		b.WriteString("):\n")
+		for _, image := range group.Images {
+			size := ""
+			if !p.SizesSkipped {
+				size = "未知"
+				if image.Size > 0 {
+					size = formatBytes(image.Size)
+				}
+			}
+			fmt.Fprintf(&b, "  %10s  %s\n", size, image.Source)
+		}
+	}
+
+	if p.SizesSkipped {
+		b.WriteString("\n未读取镜像大小\n")
+	} else {
+		fmt.Fprintf(&b, "\n预计传输大小: %s (按层去重", formatBytes(p.TotalSize))
+		if p.UnknownSizes > 0 {
+			fmt.Fprintf(&b, "，不包括 %d 个大小未知的镜像", p.UnknownSizes)
+		}
+		b.WriteString(")\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatBytes 格式化字节数为人类可读格式
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,171 @@
+// Package plan 汇总 oc-mirror dry-run 解析出的待同步镜像，按 release、Operator 和附加镜像分组，
+// 并通过 skopeo 读取镜像清单估算传输大小，便于在执行 save-image 之前确认镜像集的内容和体积。
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// 镜像分组
+const (
+	GroupRelease    = "release"
+	GroupOperator   = "operator"
+	GroupAdditional = "additional"
+	GroupHelm       = "helm"
+	GroupOther      = "other"
+)
+
+// groupOrder 分组的输出顺序
+var groupOrder = []string{GroupRelease, GroupOperator, GroupAdditional, GroupHelm, GroupOther}
+
+// groupsByType oc-mirror 镜像类型 (v2alpha1.ImageType 的字符串形式) 所属的分组
+var groupsByType = map[string]string{
+	"ocpRelease":           GroupRelease,
+	"ocpReleaseContent":    GroupRelease,
+	"cincinnatiGraph":      GroupRelease,
+	"operatorCatalog":      GroupOperator,
+	"operatorBundle":       GroupOperator,
+	"operatorRelatedImage": GroupOperator,
+	"generic":              GroupAdditional,
+	"helmImage":            GroupHelm,
+}
+
+// sizeWorkers 并发读取镜像清单的数量
+const sizeWorkers = 8
+
+// ImagesFile 返回 save-image --dry-run 或 plan 生成的带类型的镜像列表
+func ImagesFile(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "working-dir", "dry-run", "images.json")
+}
+
+// Image 一个待同步的镜像
+type Image struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Type        string `json:"type"`
+	// Size 镜像配置和各层压缩后的大小之和，为 0 表示大小未知
+	Size      int64  `json:"size,omitempty"`
+	SizeError string `json:"size_error,omitempty"`
+
+	blobs map[string]int64
+}
+
+// Group 同一分组的镜像
+type Group struct {
+	Name   string  `json:"name"`
+	Images []Image `json:"images"`
+	// Size 组内镜像去重后的层大小之和
+	Size         int64 `json:"size"`
+	UnknownSizes int   `json:"unknown_sizes"`
+}
+
+// Plan 镜像同步计划
+type Plan struct {
+	Cluster string  `json:"cluster"`
+	Groups  []Group `json:"groups"`
+	Images  int     `json:"images"`
+	// TotalSize 所有镜像按层去重后的预计传输大小，不包含大小未知的镜像
+	TotalSize    int64 `json:"total_size"`
+	UnknownSizes int   `json:"unknown_sizes"`
+	SizesSkipped bool  `json:"sizes_skipped,omitempty"`
+}
+
+// Load 读取 dry-run 生成的镜像列表
+func Load(clusterDir string) ([]Image, error) {
+	path := ImagesFile(clusterDir)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("未找到镜像列表 %s，请先执行 dry-run", path)
+		}
+		return nil, fmt.Errorf("读取镜像列表失败: %w", err)
+	}
+	var images []Image
+	if err := json.Unmarshal(content, &images); err != nil {
+		return nil, fmt.Errorf("解析镜像列表 %s 失败: %w", path, err)
+	}
+	return images, nil
+}
+
+// Build 按分组汇总镜像。sizer 不为 nil 时读取每个镜像的层大小，单个镜像读取失败只记录在该镜像上
+func Build(cluster string, images []Image, sizer Sizer) *Plan {
+	p := &Plan{Cluster: cluster, Images: len(images), SizesSkipped: sizer == nil}
+	if sizer != nil {
+		inspectAll(images, sizer)
+	}
+
+	grouped := map[string][]Image{}
+	for _, image := range images {
+		name, ok := groupsByType[image.Type]
+		if !ok {
+			name = GroupOther
+		}
+		grouped[name] = append(grouped[name], image)
+	}
+
+	total := map[string]int64{}
+	for _, name := range groupOrder {
+		members := grouped[name]
+		if len(members) == 0 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Source < members[j].Source })
+		group := Group{Name: name, Images: members}
+		blobs := map[string]int64{}
+		for _, image := range members {
+			if image.blobs == nil {
+				group.UnknownSizes++
+				continue
+			}
+			for digest, size := range image.blobs {
+				blobs[digest] = size
+				total[digest] = size
+			}
+		}
+		group.Size = sumSizes(blobs)
+		p.UnknownSizes += group.UnknownSizes
+		p.Groups = append(p.Groups, group)
+	}
+	p.TotalSize = sumSizes(total)
+	return p
+}
+
+// inspectAll 并发读取镜像的层大小
+func inspectAll(images []Image, sizer Sizer) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < sizeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				blobs, err := sizer.Blobs(images[i].Source)
+				if err != nil {
+					images[i].SizeError = err.Error()
+					continue
+				}
+				images[i].blobs = blobs
+				images[i].Size = sumSizes(blobs)
+			}
+		}()
+	}
+	for i := range images {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// sumSizes 返回各层大小之和
+func sumSizes(blobs map[string]int64) int64 {
+	var total int64
+	for _, size := range blobs {
+		total += size
+	}
+	return total
+}
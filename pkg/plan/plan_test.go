@@ -0,0 +1,193 @@
+package plan
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+)
+
+const testImages = `[
+  {"source": "docker://quay.io/openshift-release-dev/ocp-release:4.14.0-x86_64", "destination": "docker://localhost:55000/openshift/release-images:4.14.0-x86_64", "type": "ocpRelease"},
+  {"source": "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:aaaa", "destination": "docker://localhost:55000/openshift/release:4.14.0-x86_64-etcd", "type": "ocpReleaseContent"},
+  {"source": "docker://registry.redhat.io/redhat/redhat-operator-index:v4.14", "destination": "docker://localhost:55000/redhat/redhat-operator-index:v4.14", "type": "operatorCatalog"},
+  {"source": "docker://docker.io/library/busybox:latest", "destination": "docker://localhost:55000/library/busybox:latest", "type": "generic"}
+]`
+
+// manifests skopeo inspect --raw 的返回结果，release 和 etcd 共用 sha256:shared 层
+var manifests = map[string]string{
+	"docker://quay.io/openshift-release-dev/ocp-release:4.14.0-x86_64": `{"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+		"config": {"digest": "sha256:c1", "size": 100}, "layers": [{"digest": "sha256:shared", "size": 1000}, {"digest": "sha256:l1", "size": 500}]}`,
+	"docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:aaaa": `{"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": {"digest": "sha256:c2", "size": 200}, "layers": [{"digest": "sha256:shared", "size": 1000}]}`,
+	"docker://registry.redhat.io/redhat/redhat-operator-index:v4.14": `{"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [{"digest": "sha256:arm", "platform": {"architecture": "arm64", "os": "linux"}},
+		              {"digest": "sha256:amd", "platform": {"architecture": "amd64", "os": "linux"}}]}`,
+	"docker://registry.redhat.io/redhat/redhat-operator-index@sha256:amd": `{"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": {"digest": "sha256:c3", "size": 300}, "layers": [{"digest": "sha256:l3", "size": 3000}]}`,
+	"docker://registry.redhat.io/redhat/redhat-operator-index@sha256:arm": `{"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": {"digest": "sha256:c4", "size": 400}, "layers": [{"digest": "sha256:l4", "size": 4000}]}`,
+}
+
+func writeImages(t *testing.T, clusterDir string) {
+	t.Helper()
+	path := ImagesFile(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(testImages), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fakeSkopeo(t *testing.T) *runner.Fake {
+	t.Helper()
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		ref := cmd.Args[len(cmd.Args)-1]
+		if content, ok := manifests[ref]; ok {
+			return &runner.Result{Stdout: []byte(content)}, nil
+		}
+		return &runner.Result{Stderr: []byte("manifest unknown")}, errors.New("exit status 1")
+	}}
+	return fake
+}
+
+func TestBuild(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeImages(t, clusterDir)
+	fake := fakeSkopeo(t)
+
+	images, err := Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := Build("demo", images, &SkopeoSizer{AuthFile: "/tmp/auth.json", Arch: "amd64", Runner: fake})
+
+	if p.Images != 4 || len(p.Groups) != 3 {
+		t.Fatalf("unexpected plan: %d images, %d groups", p.Images, len(p.Groups))
+	}
+	release := p.Groups[0]
+	if release.Name != GroupRelease || len(release.Images) != 2 {
+		t.Fatalf("unexpected release group: %+v", release)
+	}
+	// 共用的层只计算一次
+	if release.Size != 100+1000+500+200 {
+		t.Errorf("release group size = %d", release.Size)
+	}
+	operator := p.Groups[1]
+	if operator.Name != GroupOperator || operator.Size != 3300 {
+		t.Errorf("unexpected operator group: %+v", operator)
+	}
+	additional := p.Groups[2]
+	if additional.Name != GroupAdditional || additional.UnknownSizes != 1 || additional.Images[0].SizeError == "" {
+		t.Errorf("expected busybox size to be unknown: %+v", additional)
+	}
+	if p.TotalSize != 1800+3300 || p.UnknownSizes != 1 {
+		t.Errorf("total = %d, unknown = %d", p.TotalSize, p.UnknownSizes)
+	}
+
+	for _, line := range fake.CommandLines() {
+		if !strings.HasPrefix(line, "skopeo inspect --raw --authfile /tmp/auth.json docker://") {
+			t.Errorf("unexpected command: %s", line)
+		}
+	}
+}
+
+func TestSkopeoSizerAllPlatforms(t *testing.T) {
+	fake := fakeSkopeo(t)
+	image := "docker://registry.redhat.io/redhat/redhat-operator-index:v4.14"
+
+	blobs, err := (&SkopeoSizer{Runner: fake}).Blobs(image)
+	if err != nil {
+		t.Fatalf("Blobs() error = %v", err)
+	}
+	if len(blobs) != 4 || blobs["sha256:l3"] != 3000 || blobs["sha256:l4"] != 4000 {
+		t.Errorf("Blobs() without Arch = %v, want the blobs of both platforms", blobs)
+	}
+	if _, err := (&SkopeoSizer{Arch: "s390x", Runner: fake}).Blobs(image); err == nil {
+		t.Error("Blobs() for a missing platform should fail")
+	}
+}
+
+func TestSkopeoSizerTLS(t *testing.T) {
+	fake := fakeSkopeo(t)
+	image := "docker://registry.redhat.io/redhat/redhat-operator-index:v4.14"
+	inspected := func() string {
+		calls := fake.Calls()
+		return strings.Join(calls[len(calls)-1].Args, " ")
+	}
+
+	if _, err := (&SkopeoSizer{Runner: fake}).Blobs(image); err != nil {
+		t.Fatalf("Blobs() error = %v", err)
+	}
+	if args := inspected(); strings.Contains(args, "--tls-verify") || strings.Contains(args, "--cert-dir") {
+		t.Errorf("skopeo args without TLS policy = %s", args)
+	}
+
+	policy := &registrytls.Policy{CertDir: "/work/demo/registry/ca-bundle.d"}
+	if _, err := (&SkopeoSizer{TLS: policy, Runner: fake}).Blobs(image); err != nil {
+		t.Fatalf("Blobs() error = %v", err)
+	}
+	if args := inspected(); strings.Contains(args, "--tls-verify=false") || !strings.Contains(args, "--cert-dir /work/demo/registry/ca-bundle.d") {
+		t.Errorf("skopeo args with CertDir = %s", args)
+	}
+
+	policy.Insecure = []string{"registry.redhat.io"}
+	if _, err := (&SkopeoSizer{TLS: policy, Runner: fake}).Blobs(image); err != nil {
+		t.Fatalf("Blobs() error = %v", err)
+	}
+	if args := inspected(); !strings.Contains(args, "--tls-verify=false") {
+		t.Errorf("skopeo args for insecure registry = %s", args)
+	}
+}
+
+func TestBuildWithoutSizes(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeImages(t, clusterDir)
+	fake := fakeSkopeo(t)
+
+	images, err := Load(clusterDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Build("demo", images, nil)
+	if !p.SizesSkipped || p.TotalSize != 0 || len(fake.Calls()) != 0 {
+		t.Errorf("expected sizes to be skipped: %+v", p)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, p, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"共 4 个镜像", "Release 镜像 (2 个)", "Operator 镜像 (1 个)", "附加镜像 (1 个)", "未读取镜像大小"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("expected error without dry-run output")
+	}
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]string{
+		"quay.io/ns/app:v1":           "quay.io/ns/app",
+		"quay.io/ns/app@sha256:abc":   "quay.io/ns/app",
+		"localhost:5000/ns/app":       "localhost:5000/ns/app",
+		"localhost:5000/ns/app:1.0.0": "localhost:5000/ns/app",
+	}
+	for ref, want := range tests {
+		if got := repository(ref); got != want {
+			t.Errorf("repository(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
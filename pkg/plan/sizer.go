@@ -0,0 +1,138 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ocpack/pkg/registrytls"
+	"ocpack/pkg/runner"
+)
+
+// Sizer 返回镜像各层 (包括镜像配置) 的摘要和压缩后的大小
+type Sizer interface {
+	Blobs(image string) (map[string]int64, error)
+}
+
+// SkopeoSizer 使用 skopeo inspect --raw 读取镜像清单。多架构镜像按 Arch 选择对应平台的清单，
+// Arch 为空时统计清单列表中全部平台的清单 (multi release payload 同步全部架构)。
+// 按 TLS 校验仓库的证书，TLS 为 nil 时只信任系统 CA
+type SkopeoSizer struct {
+	AuthFile string
+	Arch     string
+	TLS      *registrytls.Policy
+	Runner   runner.CommandRunner // 执行 skopeo 命令，测试时可替换为 runner.Fake
+}
+
+// manifest 镜像清单和多架构清单列表中用到的字段 (Docker v2 和 OCI 格式)
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    *blob  `json:"config"`
+	Layers    []blob `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type blob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// Blobs 读取镜像清单并返回其中的配置和各层
+func (s *SkopeoSizer) Blobs(image string) (map[string]int64, error) {
+	ref, err := dockerReference(image)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.inspect(ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) == 0 {
+		return manifestBlobs(m, make(map[string]int64))
+	}
+
+	var digests []string
+	for _, entry := range m.Manifests {
+		if entry.Platform.OS == "linux" && (s.Arch == "" || entry.Platform.Architecture == s.Arch) {
+			digests = append(digests, entry.Digest)
+		}
+	}
+	if len(digests) == 0 {
+		return nil, fmt.Errorf("镜像不包含 linux/%s 平台", s.Arch)
+	}
+	blobs := make(map[string]int64)
+	for _, digest := range digests {
+		platform, err := s.inspect(repository(ref) + "@" + digest)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := manifestBlobs(platform, blobs); err != nil {
+			return nil, err
+		}
+	}
+	return blobs, nil
+}
+
+// manifestBlobs 将单个平台清单中的配置和各层加入 blobs
+func manifestBlobs(m *manifest, blobs map[string]int64) (map[string]int64, error) {
+	if m.Config == nil || len(m.Layers) == 0 {
+		return nil, fmt.Errorf("不支持的镜像清单格式 %q，无法获取大小", m.MediaType)
+	}
+	blobs[m.Config.Digest] = m.Config.Size
+	for _, layer := range m.Layers {
+		blobs[layer.Digest] = layer.Size
+	}
+	return blobs, nil
+}
+
+// inspect 执行 skopeo inspect --raw 并解析清单
+func (s *SkopeoSizer) inspect(ref string) (*manifest, error) {
+	args := []string{"inspect", "--raw"}
+	if s.TLS != nil {
+		args = append(args, s.TLS.SkopeoArgs(ref)...)
+	}
+	if s.AuthFile != "" {
+		args = append(args, "--authfile", s.AuthFile)
+	}
+	result, err := s.Runner.Run(runner.Command{
+		Name:    "skopeo",
+		Args:    append(args, "docker://"+ref),
+		Timeout: runner.DefaultTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("skopeo inspect 失败: %w, 输出: %s", err, strings.TrimSpace(string(result.Stderr)))
+	}
+	var m manifest
+	if err := json.Unmarshal(result.Stdout, &m); err != nil {
+		return nil, fmt.Errorf("解析镜像清单失败: %w", err)
+	}
+	return &m, nil
+}
+
+// dockerReference 去掉 docker:// 前缀。oci:// 等本地镜像无法通过 skopeo 从仓库读取大小
+func dockerReference(image string) (string, error) {
+	if idx := strings.Index(image, "://"); idx >= 0 {
+		if image[:idx] != "docker" {
+			return "", fmt.Errorf("不支持读取 %s:// 镜像的大小", image[:idx])
+		}
+		image = image[idx+3:]
+	}
+	return image, nil
+}
+
+// repository 去掉镜像引用中的标签和摘要
+func repository(ref string) string {
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		return ref[:idx]
+	}
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		return ref[:idx]
+	}
+	return ref
+}
@@ -0,0 +1,269 @@
+// Package provenance 为 ocpack 生成的产物 (ISO、PXE 文件、镜像归档) 记录复现信息：使用的工具版本
+// (ocpack、内置 oc-mirror、openshift-install、oc)、config.toml 的哈希、执行的命令和每个文件的生成时间，
+// 保存在集群目录的 .ocpack-provenance.json 中，便于支持人员按相同的输入复现用户生成的产物。
+package provenance
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Filename 复现清单文件名，位于集群目录下
+const Filename = ".ocpack-provenance.json"
+
+// 产物类型
+const (
+	ArtifactISO           = "iso"
+	ArtifactPXE           = "pxe"
+	ArtifactMirrorArchive = "mirror_archive"
+)
+
+// versionTimeout 执行工具版本命令的超时时间
+const versionTimeout = 30 * time.Second
+
+// Formats 支持的输出格式
+var Formats = []string{"text", "json"}
+
+// Tool 生成产物时使用的工具
+type Tool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"` // 外部二进制的路径，内置工具为空
+	Version string `json:"version"`
+}
+
+// File 一个产物文件
+type File struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	SHA256   string    `json:"sha256,omitempty"` // 镜像归档体积较大，不计算
+}
+
+// Host 执行命令的主机环境
+type Host struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// Record 一类产物最近一次生成的复现信息
+type Record struct {
+	Artifact         string    `json:"artifact"`
+	Stage            string    `json:"stage"`
+	Generated        time.Time `json:"generated"`
+	Command          string    `json:"command"`
+	OpenShiftVersion string    `json:"openshift_version"`
+	ConfigSHA256     string    `json:"config_sha256"`
+	Tools            []Tool    `json:"tools"`
+	Host             Host      `json:"host"`
+	Files            []File    `json:"files"`
+}
+
+// Manifest 集群的复现清单，每类产物只保留最近一次生成的记录
+type Manifest struct {
+	Records []Record `json:"records"`
+}
+
+// 读取外部工具版本和主机名的函数，测试时可替换
+var (
+	runVersion = func(path string, args ...string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, path, args...).Output()
+		return string(out), err
+	}
+	hostname = os.Hostname
+)
+
+// Path 返回集群目录中复现清单的位置
+func Path(clusterDir string) string {
+	return filepath.Join(clusterDir, Filename)
+}
+
+// Load 读取集群目录中的复现清单，文件不存在时返回空清单
+func Load(clusterDir string) (*Manifest, error) {
+	manifest := &Manifest{}
+	data, err := os.ReadFile(Path(clusterDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取复现清单失败: %w", err)
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("解析复现清单失败: %w", err)
+	}
+	return manifest, nil
+}
+
+// Save 写入复现清单，替换同类产物的旧记录
+func Save(clusterDir string, record Record) error {
+	manifest, err := Load(clusterDir)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range manifest.Records {
+		if manifest.Records[i].Artifact == record.Artifact {
+			manifest.Records[i] = record
+			replaced = true
+		}
+	}
+	if !replaced {
+		manifest.Records = append(manifest.Records, record)
+	}
+	sort.SliceStable(manifest.Records, func(i, j int) bool {
+		return manifest.Records[i].Artifact < manifest.Records[j].Artifact
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(Path(clusterDir), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入复现清单失败: %w", err)
+	}
+	return nil
+}
+
+// NewRecord 创建产物记录，填充 config.toml 的哈希、执行的命令和主机环境
+func NewRecord(artifact, stage, clusterDir, openshiftVersion string, generated time.Time) (Record, error) {
+	configHash, err := FileSHA256(filepath.Join(clusterDir, "config.toml"))
+	if err != nil {
+		return Record{}, fmt.Errorf("计算 config.toml 的哈希失败: %w", err)
+	}
+	host := Host{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	host.Hostname, _ = hostname()
+	return Record{
+		Artifact:         artifact,
+		Stage:            stage,
+		Generated:        generated,
+		Command:          strings.Join(os.Args, " "),
+		OpenShiftVersion: openshiftVersion,
+		ConfigSHA256:     configHash,
+		Host:             host,
+	}, nil
+}
+
+// CollectFiles 返回 paths 中在 since 之后生成或修改的文件，hash 为 true 时计算 SHA256。
+// paths 可以包含通配符，不存在的路径忽略
+func CollectFiles(paths []string, since time.Time, hash bool) ([]File, error) {
+	var files []File
+	seen := make(map[string]bool)
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.ModTime().Before(since) || seen[path] {
+				continue
+			}
+			seen[path] = true
+			file := File{Path: path, Size: info.Size(), Modified: info.ModTime()}
+			if hash {
+				if file.SHA256, err = FileSHA256(path); err != nil {
+					return nil, fmt.Errorf("计算 %s 的哈希失败: %w", path, err)
+				}
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// FileSHA256 返回文件内容的 SHA256
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ExternalTool 执行 path 的版本命令，返回输出的第一行作为版本。path 为空或执行失败时版本记为 unknown
+func ExternalTool(name, path string, args ...string) Tool {
+	tool := Tool{Name: name, Path: path, Version: "unknown"}
+	if path == "" {
+		return tool
+	}
+	out, err := runVersion(path, args...)
+	if err != nil {
+		return tool
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	if scanner.Scan() && strings.TrimSpace(scanner.Text()) != "" {
+		tool.Version = strings.TrimSpace(scanner.Text())
+	}
+	return tool
+}
+
+// Write 按指定格式输出复现清单
+func Write(w io.Writer, clusterName string, manifest *Manifest, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, clusterName, manifest)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeText(w io.Writer, clusterName string, manifest *Manifest) error {
+	if len(manifest.Records) == 0 {
+		_, err := fmt.Fprintf(w, "集群 %s 还没有生成产物的记录\n", clusterName)
+		return err
+	}
+	for i, record := range manifest.Records {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "📦 %s (阶段 %s，生成于 %s)\n", record.Artifact, record.Stage, record.Generated.Local().Format("2006-01-02 15:04:05"))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  命令\t%s\n", record.Command)
+		fmt.Fprintf(tw, "  OpenShift 版本\t%s\n", record.OpenShiftVersion)
+		fmt.Fprintf(tw, "  config.toml SHA256\t%s\n", record.ConfigSHA256)
+		fmt.Fprintf(tw, "  主机\t%s (%s/%s)\n", record.Host.Hostname, record.Host.OS, record.Host.Arch)
+		for _, tool := range record.Tools {
+			version := tool.Version
+			if tool.Path != "" {
+				version += "  " + tool.Path
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", tool.Name, version)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		for _, file := range record.Files {
+			checksum := file.SHA256
+			if checksum == "" {
+				checksum = "-"
+			}
+			fmt.Fprintf(w, "  - %s  %d 字节  %s  %s\n", file.Path, file.Size, file.Modified.Local().Format("2006-01-02 15:04:05"), checksum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package provenance
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "mirror_000001.tar"), "abc")
+	writeFile(t, filepath.Join(dir, "mirror_000002.tar"), "defg")
+	old := filepath.Join(dir, "mirror_000003.tar")
+	writeFile(t, old, "old")
+	since := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(old, since.Add(-time.Hour), since.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := CollectFiles([]string{filepath.Join(dir, "mirror_*.tar"), filepath.Join(dir, "missing.iso")}, since, true)
+	if err != nil {
+		t.Fatalf("CollectFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("CollectFiles() = %+v, expected the 2 files modified since start", files)
+	}
+	if files[0].Size != 3 || files[0].SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("files[0] = %+v", files[0])
+	}
+
+	files, err = CollectFiles([]string{filepath.Join(dir, "mirror_*.tar")}, since, false)
+	if err != nil || len(files) != 2 || files[0].SHA256 != "" {
+		t.Errorf("CollectFiles(hash=false) = %+v, %v", files, err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeFile(t, filepath.Join(clusterDir, "config.toml"), "[cluster_info]\n")
+	hostname = func() (string, error) { return "bastion", nil }
+	defer func() { hostname = os.Hostname }()
+
+	manifest, err := Load(clusterDir)
+	if err != nil || len(manifest.Records) != 0 {
+		t.Fatalf("Load() without file = %+v, %v", manifest, err)
+	}
+
+	generated := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	iso, err := NewRecord(ArtifactISO, "generate_iso", clusterDir, "4.16.3", generated)
+	if err != nil {
+		t.Fatalf("NewRecord() error = %v", err)
+	}
+	if iso.ConfigSHA256 == "" || iso.Host.Hostname != "bastion" || iso.OpenShiftVersion != "4.16.3" {
+		t.Errorf("NewRecord() = %+v", iso)
+	}
+	iso.Files = []File{{Path: "demo.iso", Size: 1}}
+	if err := Save(clusterDir, iso); err != nil {
+		t.Fatal(err)
+	}
+	archive, _ := NewRecord(ArtifactMirrorArchive, "save_image", clusterDir, "4.16.3", generated)
+	if err := Save(clusterDir, archive); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同类产物只保留最近一次的记录
+	iso.Files = []File{{Path: "demo.iso", Size: 2}}
+	if err := Save(clusterDir, iso); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = Load(clusterDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Records) != 2 || manifest.Records[0].Artifact != ArtifactISO || manifest.Records[0].Files[0].Size != 2 {
+		t.Errorf("Load() = %+v", manifest.Records)
+	}
+
+	if _, err := NewRecord(ArtifactISO, "generate_iso", t.TempDir(), "4.16.3", generated); err == nil {
+		t.Error("NewRecord() without config.toml should fail")
+	}
+}
+
+func TestExternalTool(t *testing.T) {
+	original := runVersion
+	defer func() { runVersion = original }()
+	runVersion = func(path string, args ...string) (string, error) {
+		if path == "/broken/oc" {
+			return "", errors.New("exec format error")
+		}
+		return "openshift-install 4.16.3\nbuilt from commit abc\n", nil
+	}
+
+	tool := ExternalTool("openshift-install", "/demo/openshift-install", "version")
+	if tool.Version != "openshift-install 4.16.3" || tool.Path != "/demo/openshift-install" {
+		t.Errorf("ExternalTool() = %+v", tool)
+	}
+	if tool := ExternalTool("oc", "/broken/oc", "version"); tool.Version != "unknown" {
+		t.Errorf("ExternalTool(broken) = %+v", tool)
+	}
+	if tool := ExternalTool("oc", ""); tool.Version != "unknown" {
+		t.Errorf("ExternalTool(missing) = %+v", tool)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	manifest := &Manifest{Records: []Record{{
+		Artifact:     ArtifactISO,
+		Stage:        "generate_iso",
+		Command:      "ocpack generate-iso demo",
+		ConfigSHA256: "cafe",
+		Tools:        []Tool{{Name: "ocpack", Version: "v1.2.0"}, {Name: "openshift-install", Path: "/bin/openshift-install", Version: "4.16.3"}},
+		Files:        []File{{Path: "demo.iso", Size: 10, SHA256: "beef"}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "demo", manifest, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ocpack generate-iso demo", "cafe", "v1.2.0", "/bin/openshift-install", "demo.iso", "beef"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, "demo", &Manifest{}, "text"); err != nil || !strings.Contains(buf.String(), "还没有") {
+		t.Errorf("empty text output = %q, %v", buf.String(), err)
+	}
+	if err := Write(&buf, "demo", manifest, "yaml"); err == nil {
+		t.Error("Write(yaml) should fail")
+	}
+}
@@ -0,0 +1,81 @@
+package pxe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/i18n"
+)
+
+// fingerprintFilename records the inputs of the last successful generation. It is kept next to
+// files/ rather than inside it, so it is neither uploaded nor served over TFTP.
+const fingerprintFilename = ".fingerprint"
+
+// fingerprint hashes everything that determines the generated PXE artifacts: the rendered
+// install-config.yaml, agent-config.yaml and extra manifests in configDir, the OpenShift version
+// and the pinned release image.
+func (g *PXEGenerator) fingerprint(configDir string) (string, error) {
+	pinned, err := g.PinnedReleaseImage()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "version=%s\nrelease=%s\n", g.Config.ClusterInfo.OpenShiftVersion, pinned)
+	err = filepath.WalkDir(configDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(configDir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "file=%s\n", filepath.ToSlash(rel))
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", i18n.Errorf("计算 PXE 配置指纹失败: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// artifactsUpToDate reports whether the files in pxeDir were generated from the same inputs and the
+// iPXE script is still present.
+func artifactsUpToDate(pxeDir, fingerprint string) bool {
+	recorded, err := os.ReadFile(filepath.Join(pxeDir, fingerprintFilename))
+	if err != nil || strings.TrimSpace(string(recorded)) != fingerprint {
+		return false
+	}
+	scripts, _ := filepath.Glob(filepath.Join(pxeDir, filesDirName, "*.ipxe"))
+	return len(scripts) > 0
+}
+
+// resetFilesDir removes the previous artifacts and fingerprint, so a new generation never mixes
+// stale files with fresh ones and a failed run is not mistaken for an up-to-date one.
+func resetFilesDir(pxeDir string) error {
+	if err := os.Remove(filepath.Join(pxeDir, fingerprintFilename)); err != nil && !os.IsNotExist(err) {
+		return i18n.Errorf("删除 PXE 指纹文件失败: %w", err)
+	}
+	filesDir := filepath.Join(pxeDir, filesDirName)
+	if err := os.RemoveAll(filesDir); err != nil {
+		return i18n.Errorf("清理 %s 失败: %w", filesDir, err)
+	}
+	return os.MkdirAll(filesDir, 0755)
+}
+
+// writeFingerprint records the inputs of a successful generation.
+func writeFingerprint(pxeDir, fingerprint string) error {
+	return os.WriteFile(filepath.Join(pxeDir, fingerprintFilename), []byte(fingerprint+"\n"), 0644)
+}
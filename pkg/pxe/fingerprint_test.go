@@ -0,0 +1,95 @@
+package pxe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/config"
+	"ocpack/pkg/kubeconfig"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	clusterDir := t.TempDir()
+	g := &PXEGenerator{Renderer: &agentinstall.Renderer{Config: config.NewDefaultConfig("demo"), ClusterDir: clusterDir}}
+	configDir := filepath.Join(clusterDir, pxeDirName, configDirName)
+	writeTestFile(t, filepath.Join(configDir, agentinstall.InstallConfigFilename), "install")
+	writeTestFile(t, filepath.Join(configDir, agentinstall.AgentConfigFilename), "agent")
+
+	base, err := g.fingerprint(configDir)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if again, _ := g.fingerprint(configDir); again != base {
+		t.Error("fingerprint is not stable for unchanged inputs")
+	}
+
+	changes := []struct {
+		name   string
+		change func()
+	}{
+		{"agent config", func() { writeTestFile(t, filepath.Join(configDir, agentinstall.AgentConfigFilename), "agent2") }},
+		{"manifest", func() { writeTestFile(t, filepath.Join(configDir, agentinstall.ManifestsDirName, "idms.yaml"), "idms") }},
+		{"version", func() { g.Config.ClusterInfo.OpenShiftVersion = "4.99.0" }},
+		{"pinned release", func() {
+			if err := kubeconfig.RecordRelease(clusterDir, g.Config.ClusterInfo.OpenShiftVersion, "sha256:abcd"); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, c := range changes {
+		c.change()
+		got, err := g.fingerprint(configDir)
+		if err != nil {
+			t.Fatalf("fingerprint after %s change: %v", c.name, err)
+		}
+		if got == base {
+			t.Errorf("fingerprint unchanged after %s change", c.name)
+		}
+		base = got
+	}
+}
+
+func TestArtifactsUpToDate(t *testing.T) {
+	pxeDir := t.TempDir()
+	if artifactsUpToDate(pxeDir, "abc") {
+		t.Error("expected stale without recorded fingerprint")
+	}
+
+	writeTestFile(t, filepath.Join(pxeDir, filesDirName, "agent.x86_64-initrd.img"), "initrd")
+	if err := writeFingerprint(pxeDir, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if artifactsUpToDate(pxeDir, "abc") {
+		t.Error("expected stale without iPXE script")
+	}
+	writeTestFile(t, filepath.Join(pxeDir, filesDirName, "agent.x86_64.ipxe"), "#!ipxe")
+	if !artifactsUpToDate(pxeDir, "abc") {
+		t.Error("expected up to date with matching fingerprint")
+	}
+	if artifactsUpToDate(pxeDir, "def") {
+		t.Error("expected stale with different fingerprint")
+	}
+
+	if err := resetFilesDir(pxeDir); err != nil {
+		t.Fatalf("resetFilesDir: %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(pxeDir, filesDirName))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("files dir after reset: %v, %v", entries, err)
+	}
+	if artifactsUpToDate(pxeDir, "abc") {
+		t.Error("expected fingerprint to be removed by reset")
+	}
+}
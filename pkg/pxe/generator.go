@@ -1,22 +1,21 @@
 package pxe
 
 import (
-	"bytes"
 	"embed"
 	"errors"
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
-	"text/template"
 
-	"ocpack/pkg/config"
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/sshsync"
 	"ocpack/pkg/utils"
+	"ocpack/pkg/workspace"
 
 	"github.com/mattn/go-runewidth"
-	"gopkg.in/yaml.v3"
 )
 
 //go:embed templates/*
@@ -28,160 +27,169 @@ const (
 	filesDirName            = "files"
 	tempDirName             = "temp"
 	pxeDirName              = "pxe"
-	installConfigFilename   = "install-config.yaml"
-	agentConfigFilename     = "agent-config.yaml"
-	icspFilename            = "imageContentSourcePolicy.yaml"
-	pullSecretFilename      = "pull-secret.txt"
-	mergedAuthFilename      = "merged-auth.json"
-	registryDirName         = "registry"
-	ocMirrorWorkspaceDir    = "oc-mirror-workspace"
-	imagesDirName           = "images"
-	openshiftInstallCmd     = "openshift-install"
-	defaultInterface        = "ens3"
+	agentConfigTemplate     = "templates/agent-config-pxe.yaml"
 	uploadScriptPath        = "/usr/local/bin/upload-pxe-files.sh"
 	defaultPxeWebServerPort = 8080
 )
 
 // --- Struct Definitions ---
 
-// PXEGenerator holds the configuration and paths for generating PXE assets.
+// PXEGenerator generates PXE assets; shared config rendering and openshift-install
+// handling is provided by agentinstall.Renderer.
 type PXEGenerator struct {
-	Config      *config.ClusterConfig
-	ClusterName string
-	ProjectRoot string
-	ClusterDir  string
-	DownloadDir string
+	*agentinstall.Renderer
 }
 
 // GenerateOptions defines options for the PXE generation process.
 type GenerateOptions struct {
 	AssetServerURL string
 	SkipVerify     bool
+	RenderOnly     bool // Only render configs and print a diff, without running openshift-install.
+	Force          bool // Regenerate and re-upload even if the configs are unchanged since the last run.
+	UploadParallel int  // Number of files uploaded to the bastion concurrently; 0 uses sshsync.DefaultParallel.
 }
 
-// AgentConfigDataPXE is the template data for agent-config.yaml.
-type AgentConfigDataPXE struct {
-	ClusterName          string
-	RendezvousIP         string
-	Hosts                []HostConfig
-	Port0                string
-	PrefixLength         int
-	NextHopAddress       string
-	DNSServers           []string
-	BootArtifactsBaseURL string
+// DumpTemplates writes the embedded PXE-specific templates to dir so they can be customized.
+// install-config.yaml is shared with the ISO flow and is dumped by the agentinstall package.
+func DumpTemplates(dir string, force bool) ([]string, error) {
+	return utils.DumpTemplates(templates, []string{agentConfigTemplate}, dir, force)
 }
 
-// HostConfig represents a single host's configuration.
-type HostConfig struct {
-	Hostname   string
-	Role       string
-	MACAddress string
-	IPAddress  string
-	Interface  string
+// FilesDir returns the directory holding the generated PXE boot files for a cluster.
+func FilesDir(clusterDir string) string {
+	return filepath.Join(clusterDir, pxeDirName, filesDirName)
 }
 
 // --- Main Logic ---
 
 // NewPXEGenerator creates a new PXE generator instance.
 func NewPXEGenerator(clusterName, projectRoot string) (*PXEGenerator, error) {
-	clusterDir := filepath.Join(projectRoot, clusterName)
-	configPath := filepath.Join(clusterDir, "config.toml")
-
-	cfg, err := config.LoadConfig(configPath)
+	renderer, err := agentinstall.NewRenderer(clusterName, projectRoot)
 	if err != nil {
-		return nil, fmt.Errorf("加载配置文件失败: %w", err)
+		return nil, err
 	}
 
-	return &PXEGenerator{
-		Config:      cfg,
-		ClusterName: clusterName,
-		ProjectRoot: projectRoot,
-		ClusterDir:  clusterDir,
-		DownloadDir: filepath.Join(clusterDir, cfg.Download.LocalPath),
-	}, nil
+	g := &PXEGenerator{Renderer: renderer}
+	g.Hooks = agentinstall.Hooks{
+		Info: g.printInfo,
+		Warn: func(message string) { fmt.Printf("   ⚠️  %s\n", message) },
+	}
+	return g, nil
 }
 
 // GeneratePXE orchestrates the entire PXE file generation process.
 func (g *PXEGenerator) GeneratePXE(options *GenerateOptions) error {
-	g.printHeader("PXE 文件生成", g.ClusterName)
+	if options.RenderOnly {
+		return g.RenderConfigs(options.AssetServerURL)
+	}
+
+	g.printHeader(i18n.T("PXE 文件生成"), g.ClusterName)
 	steps := 6
 
 	// 1. Validate configuration and dependencies
-	g.printStep(1, steps, "验证配置和依赖")
+	g.printStep(1, steps, i18n.T("验证配置和依赖"))
 	if err := g.ValidateConfig(); err != nil {
-		g.printError("配置验证失败", err)
+		g.printError(i18n.T("配置验证失败"), err)
 		return err
 	}
-	g.printSuccess("配置验证通过")
+	g.printSuccess(i18n.T("配置验证通过"))
 
 	// 2. Create PXE directory structure
-	g.printStep(2, steps, "创建目录结构")
+	g.printStep(2, steps, i18n.T("创建目录结构"))
 	pxeDir := filepath.Join(g.ClusterDir, pxeDirName)
 	if err := g.createPXEDirs(pxeDir); err != nil {
-		g.printError("创建目录失败", err)
+		g.printError(i18n.T("创建目录失败"), err)
 		return err
 	}
-	g.printSuccess("目录结构已创建")
+	g.printSuccess(i18n.T("目录结构已创建"))
 
 	// 3. Generate install-config.yaml
-	g.printStep(3, steps, "生成 install-config.yaml")
+	g.printStep(3, steps, i18n.T("生成 install-config.yaml"))
 	if err := g.generateInstallConfig(pxeDir); err != nil {
-		g.printError("生成 install-config.yaml 失败", err)
+		g.printError(i18n.T("生成 install-config.yaml 失败"), err)
 		return err
 	}
-	g.printSuccess("install-config.yaml 已生成")
+	g.printSuccess(i18n.T("install-config.yaml 已生成"))
 
 	// 4. Generate agent-config.yaml
-	g.printStep(4, steps, "生成 agent-config.yaml")
+	g.printStep(4, steps, i18n.T("生成 agent-config.yaml"))
 	if err := g.generateAgentConfig(pxeDir, options.AssetServerURL); err != nil {
-		g.printError("生成 agent-config.yaml 失败", err)
+		g.printError(i18n.T("生成 agent-config.yaml 失败"), err)
 		return err
 	}
-	g.printSuccess("agent-config.yaml 已生成")
+	g.printSuccess(i18n.T("agent-config.yaml 已生成"))
 
-	// 5. Generate PXE boot files using openshift-install
-	g.printStep(5, steps, "生成 PXE 启动文件")
+	// 5. Generate PXE boot files using openshift-install, unless the existing ones were built from the same inputs
+	g.printStep(5, steps, i18n.T("生成 PXE 启动文件"))
+	fingerprint, err := g.fingerprint(filepath.Join(pxeDir, configDirName))
+	if err != nil {
+		g.printError(i18n.T("生成 PXE 文件失败"), err)
+		return err
+	}
+	if !options.Force && artifactsUpToDate(pxeDir, fingerprint) {
+		g.printSuccess(i18n.T("配置和版本未变化，跳过生成和上传"))
+		i18n.Printf("🟡 PXE 文件已是最新: %s\n", filepath.Join(pxeDir, filesDirName))
+		i18n.Println("   使用 --force 标志可强制重新生成并上传。")
+		return nil
+	}
+	if err := resetFilesDir(pxeDir); err != nil {
+		g.printError(i18n.T("清理旧的 PXE 文件失败"), err)
+		return err
+	}
 	if err := g.generatePXEFiles(pxeDir, options.AssetServerURL); err != nil {
-		g.printError("生成 PXE 文件失败", err)
+		g.printError(i18n.T("生成 PXE 文件失败"), err)
 		return err
 	}
+	if err := writeFingerprint(pxeDir, fingerprint); err != nil {
+		g.printWarning(i18n.T("记录 PXE 配置指纹失败"), err)
+	}
 
 	// 6. Upload files to PXE server
-	g.printStep(6, steps, "上传文件到 PXE 服务器")
-	if err := g.uploadPXEFiles(pxeDir); err != nil {
-		g.printWarning("自动上传失败", err)
+	g.printStep(6, steps, i18n.T("上传文件到 PXE 服务器"))
+	if !g.Config.BastionEnabled() {
+		g.printInfo(i18n.Sprintf("未启用 Bastion，请将 %s 中的文件复制到 PXE 资源服务器", filepath.Join(pxeDir, filesDirName)))
+		fmt.Println()
+	} else if err := g.uploadPXEFiles(pxeDir, options.UploadParallel); err != nil {
+		g.printWarning(i18n.T("自动上传失败"), err)
 		g.printManualUploadInstructions(pxeDir)
 	} else {
-		g.printSuccess("文件已自动上传到服务器")
+		g.printSuccess(i18n.T("文件已自动上传到服务器"))
+		g.pruneRemoteHistory()
 	}
 
 	g.printCompletion(pxeDir)
 	return nil
 }
 
-// --- Step Implementations ---
+// RenderConfigs only renders install-config.yaml and agent-config.yaml and prints a diff against existing files.
+func (g *PXEGenerator) RenderConfigs(assetServerURL string) error {
+	g.printHeader(i18n.T("PXE 配置渲染 (render-only)"), g.ClusterName)
 
-// ValidateConfig checks for required configurations and tools.
-func (g *PXEGenerator) ValidateConfig() error {
-	if err := config.ValidateConfig(g.Config); err != nil {
-		return err
+	if err := g.ValidateRenderConfig(); err != nil {
+		return i18n.Errorf("配置验证失败: %w", err)
 	}
 
-	// Validate required tools
-	toolPath := filepath.Join(g.DownloadDir, "bin", openshiftInstallCmd)
-	if _, err := os.Stat(toolPath); os.IsNotExist(err) {
-		return fmt.Errorf("缺少必需的工具: %s，请先运行 'ocpack download' 命令", openshiftInstallCmd)
+	pxeDir := filepath.Join(g.ClusterDir, pxeDirName)
+	if err := g.createPXEDirs(pxeDir); err != nil {
+		return err
 	}
 
-	// Validate pull-secret file
-	pullSecretPath := filepath.Join(g.ClusterDir, pullSecretFilename)
-	if _, err := os.Stat(pullSecretPath); errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("缺少 %s 文件，请先获取 Red Hat pull-secret", pullSecretFilename)
+	configDir := filepath.Join(pxeDir, configDirName)
+	if err := g.RenderWithDiff(configDir, []agentinstall.RenderStep{
+		{Filename: agentinstall.InstallConfigFilename, Render: func() error { return g.generateInstallConfig(pxeDir) }},
+		{Filename: agentinstall.AgentConfigFilename, Render: func() error { return g.generateAgentConfig(pxeDir, assetServerURL) }},
+	}); err != nil {
+		return err
 	}
 
+	i18n.Printf("\n✅ 配置文件已渲染到: %s\n", configDir)
+	i18n.Printf("   Rendezvous 节点: %s\n", g.RendezvousSummary())
+	i18n.Println("   未执行 openshift-install，也未上传 PXE 文件。")
 	return nil
 }
 
+// --- Step Implementations ---
+
 // createPXEDirs creates the necessary directory structure for PXE files.
 func (g *PXEGenerator) createPXEDirs(pxeDir string) error {
 	dirs := []string{
@@ -192,7 +200,7 @@ func (g *PXEGenerator) createPXEDirs(pxeDir string) error {
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+			return i18n.Errorf("创建目录 %s 失败: %w", dir, err)
 		}
 	}
 	return nil
@@ -200,46 +208,36 @@ func (g *PXEGenerator) createPXEDirs(pxeDir string) error {
 
 // generateInstallConfig generates the install-config.yaml from a template.
 func (g *PXEGenerator) generateInstallConfig(pxeDir string) error {
-	g.printInfo("从模板生成 install-config.yaml")
-	configPath := filepath.Join(pxeDir, configDirName, installConfigFilename)
-	return g.generateInstallConfigFromTemplate(configPath)
+	g.printInfo(i18n.T("从模板生成 install-config.yaml"))
+	return g.RenderInstallConfig(filepath.Join(pxeDir, configDirName))
 }
 
-// generateAgentConfig generates the agent-config.yaml from a template.
+// generateAgentConfig generates the agent-config.yaml from the PXE template,
+// pointing bootArtifactsBaseURL at the asset server.
 func (g *PXEGenerator) generateAgentConfig(pxeDir, assetServerURL string) error {
-	configPath := filepath.Join(pxeDir, configDirName, agentConfigFilename)
-	return g.generateAgentConfigFromTemplate(configPath, assetServerURL)
+	if assetServerURL == "" {
+		var err error
+		if assetServerURL, err = g.assetServerURL(pxeDirName); err != nil {
+			return err
+		}
+	}
+
+	err := g.RenderAgentConfig(filepath.Join(pxeDir, configDirName), templates, agentConfigTemplate, func(data *agentinstall.AgentConfigData) {
+		data.BootArtifactsBaseURL = assetServerURL
+	})
+	if err == nil {
+		g.printInfo(fmt.Sprintf("bootArtifactsBaseURL: %s", assetServerURL))
+	}
+	return err
 }
 
 // generatePXEFiles runs 'openshift-install' to create boot files.
 func (g *PXEGenerator) generatePXEFiles(pxeDir, assetServerURL string) error {
-	openshiftInstallPath, err := g.findOpenshiftInstall()
-	if err != nil {
-		return fmt.Errorf("查找 openshift-install 失败: %w", err)
-	}
-	g.printInfo(fmt.Sprintf("使用工具: %s", filepath.Base(openshiftInstallPath)))
-
 	tempDir := filepath.Join(pxeDir, tempDirName)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("创建临时目录失败: %w", err)
-	}
 	defer os.RemoveAll(tempDir)
 
-	// Copy configs to temp dir as openshift-install might modify them.
-	for _, filename := range []string{installConfigFilename, agentConfigFilename} {
-		src := filepath.Join(pxeDir, configDirName, filename)
-		dst := filepath.Join(tempDir, filename)
-		if err := utils.CopyFile(src, dst); err != nil {
-			return fmt.Errorf("复制 %s 失败: %w", filename, err)
-		}
-	}
-
-	g.printInfo("执行 openshift-install agent create pxe-files")
-	cmd := exec.Command(openshiftInstallPath, "agent", "create", "pxe-files", "--dir", tempDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("生成 PXE 文件失败: %w", err)
+	if err := g.RunInstaller(filepath.Join(pxeDir, configDirName), tempDir, "pxe-files"); err != nil {
+		return i18n.Errorf("生成 PXE 文件失败: %w", err)
 	}
 
 	filesDir := filepath.Join(pxeDir, filesDirName)
@@ -256,352 +254,89 @@ func (g *PXEGenerator) generatePXEFiles(pxeDir, assetServerURL string) error {
 		g.updateIPXEScript(filesDir, assetServerURL)
 	} else {
 		// Older versions place files in the root.
-		ignore := map[string]bool{installConfigFilename: true, agentConfigFilename: true}
+		ignore := map[string]bool{agentinstall.InstallConfigFilename: true, agentinstall.AgentConfigFilename: true}
 		fileCount, err = g.moveAndCountFiles(tempDir, filesDir, ignore)
 		if err != nil {
 			return err
 		}
 	}
 
-	g.printInfo(fmt.Sprintf("已生成 %d 个 PXE 文件", fileCount))
-	g.printSuccess("PXE 启动文件生成完成")
+	g.printInfo(i18n.Sprintf("已生成 %d 个 PXE 文件", fileCount))
+	g.printSuccess(i18n.T("PXE 启动文件生成完成"))
 	return nil
 }
 
-// uploadPXEFiles uploads the generated PXE files to the bastion server.
-func (g *PXEGenerator) uploadPXEFiles(pxeDir string) error {
-	filesDir := filepath.Join(pxeDir, filesDirName)
-	if _, err := os.Stat(filesDir); errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("PXE 文件目录不存在: %s", filesDir)
-	}
-
-	uploadCmdStr := fmt.Sprintf("sudo %s %s", uploadScriptPath, filesDir)
-	sshUserHost := fmt.Sprintf("%s@%s", g.Config.Bastion.Username, g.Config.Bastion.IP)
-
-	var sshCmd *exec.Cmd
-	if g.Config.Bastion.SSHKeyPath != "" {
-		sshCmd = exec.Command("ssh", "-i", g.Config.Bastion.SSHKeyPath, "-o", "StrictHostKeyChecking=no", sshUserHost, uploadCmdStr)
-	} else {
-		sshCmd = exec.Command("sshpass", "-p", g.Config.Bastion.Password, "ssh", "-o", "StrictHostKeyChecking=no", sshUserHost, uploadCmdStr)
-	}
-
-	sshCmd.Stdout = os.Stdout
-	sshCmd.Stderr = os.Stderr
-
-	if err := sshCmd.Run(); err != nil {
-		return fmt.Errorf("执行上传脚本失败: %w", err)
-	}
-	return nil
-}
-
-// --- Template Generation and Data Gathering ---
-
-// generateInstallConfigFromTemplate fills and writes the install-config.yaml template.
-func (g *PXEGenerator) generateInstallConfigFromTemplate(configPath string) error {
-	pullSecret, err := g.getPullSecret()
-	if err != nil {
-		return err
-	}
-
-	sshKey, _ := g.getSSHKey() // SSH key is optional
-
-	trustBundle, err := g.getAdditionalTrustBundle()
-	if err != nil {
-		g.printInfo(fmt.Sprintf("未找到证书文件，将跳过: %v", err))
-	} else {
-		g.printInfo("已找到并加载 CA 证书")
-	}
-
-	icsp, err := g.findAndParseICSP()
-	if err != nil {
-		g.printInfo(fmt.Sprintf("未找到 ICSP 文件，将跳过: %v", err))
-	} else {
-		g.printInfo("已找到并解析 ICSP 文件")
-	}
-
-	data := struct {
-		BaseDomain            string
-		ClusterName           string
-		NumWorkers            int
-		NumMasters            int
-		MachineNetwork        string
-		PrefixLength          int
-		HostPrefix            int
-		PullSecret            string
-		SSHKeyPub             string
-		AdditionalTrustBundle string
-		ImageContentSources   string
-		ArchShort             string
-		UseProxy              bool
-		HTTPProxy             string
-		HTTPSProxy            string
-		NoProxy               string
-	}{
-		BaseDomain:            g.Config.ClusterInfo.Domain,
-		ClusterName:           g.Config.ClusterInfo.ClusterID,
-		NumWorkers:            len(g.Config.Cluster.Worker),
-		NumMasters:            len(g.Config.Cluster.ControlPlane),
-		MachineNetwork:        utils.ExtractNetworkBase(g.Config.Cluster.Network.MachineNetwork),
-		PrefixLength:          utils.ExtractPrefixLength(g.Config.Cluster.Network.MachineNetwork),
-		HostPrefix:            23, // Default host prefix
-		PullSecret:            pullSecret,
-		SSHKeyPub:             sshKey,
-		AdditionalTrustBundle: trustBundle,
-		ImageContentSources:   icsp,
-		ArchShort:             "amd64",
-		UseProxy:              false, // Proxy settings can be added here
-	}
-
-	funcMap := template.FuncMap{
-		"indent": func(spaces int, text string) string {
-			if text == "" {
-				return ""
-			}
-			indentStr := strings.Repeat(" ", spaces)
-			lines := strings.Split(text, "\n")
-			for i, line := range lines {
-				if line != "" {
-					lines[i] = indentStr + line
-				}
-			}
-			return strings.Join(lines, "\n")
-		},
-	}
-
-	return g.executeTemplate("templates/install-config.yaml", configPath, data, funcMap)
-}
-
-// generateAgentConfigFromTemplate fills and writes the agent-config.yaml template.
-func (g *PXEGenerator) generateAgentConfigFromTemplate(configPath, assetServerURL string) error {
-	var hosts []HostConfig
-	for _, cp := range g.Config.Cluster.ControlPlane {
-		hosts = append(hosts, HostConfig{
-			Hostname:   cp.Name,
-			Role:       "master",
-			MACAddress: cp.MAC,
-			IPAddress:  cp.IP,
-			Interface:  defaultInterface,
-		})
-	}
-	for _, worker := range g.Config.Cluster.Worker {
-		hosts = append(hosts, HostConfig{
-			Hostname:   worker.Name,
-			Role:       "worker",
-			MACAddress: worker.MAC,
-			IPAddress:  worker.IP,
-			Interface:  defaultInterface,
-		})
-	}
-
-	if assetServerURL == "" {
-		assetServerURL = fmt.Sprintf("http://%s:%d/%s", g.Config.Bastion.IP, defaultPxeWebServerPort, pxeDirName)
-	}
-
-	data := AgentConfigDataPXE{
-		ClusterName:          g.Config.ClusterInfo.ClusterID,
-		RendezvousIP:         g.Config.Cluster.ControlPlane[0].IP,
-		Hosts:                hosts,
-		Port0:                defaultInterface,
-		PrefixLength:         utils.ExtractPrefixLength(g.Config.Cluster.Network.MachineNetwork),
-		NextHopAddress:       utils.ExtractGateway(g.Config.Cluster.Network.MachineNetwork),
-		DNSServers:           []string{g.Config.Bastion.IP},
-		BootArtifactsBaseURL: assetServerURL,
-	}
-
-	err := g.executeTemplate("templates/agent-config-pxe.yaml", configPath, data, nil)
-	if err == nil {
-		g.printInfo(fmt.Sprintf("bootArtifactsBaseURL: %s", assetServerURL))
-	}
-	return err
-}
-
-// getPullSecret reads the pull secret from either merged-auth.json or pull-secret.txt.
-func (g *PXEGenerator) getPullSecret() (string, error) {
-	mergedAuthPath := filepath.Join(g.ClusterDir, registryDirName, mergedAuthFilename)
-	if _, err := os.Stat(mergedAuthPath); err == nil {
-		g.printInfo("使用合并后的认证文件 " + mergedAuthFilename)
-		secretBytes, err := os.ReadFile(mergedAuthPath)
-		if err != nil {
-			return "", fmt.Errorf("读取合并认证文件失败: %w", err)
-		}
-		return strings.TrimSpace(string(secretBytes)), nil
+// assetServerURL returns infra.pxe_asset_url, or the PXE web server on the bastion under path.
+func (g *PXEGenerator) assetServerURL(path string) (string, error) {
+	if g.Config.Infra.PXEAssetURL != "" {
+		return strings.TrimSuffix(g.Config.Infra.PXEAssetURL, "/"), nil
 	}
-
-	pullSecretPath := filepath.Join(g.ClusterDir, pullSecretFilename)
-	g.printInfo("使用 " + pullSecretFilename)
-	secretBytes, err := os.ReadFile(pullSecretPath)
-	if err != nil {
-		return "", fmt.Errorf("读取 pull-secret 失败: %w", err)
+	if !g.Config.BastionEnabled() {
+		return "", i18n.Errorf("bastion.enabled = false 时必须配置 infra.pxe_asset_url 指定 PXE 启动文件地址")
 	}
-	return strings.TrimSpace(string(secretBytes)), nil
+	return fmt.Sprintf("http://%s:%d/%s", g.Config.Bastion.IP, defaultPxeWebServerPort, path), nil
 }
 
-// getSSHKey reads the user's public SSH key.
-func (g *PXEGenerator) getSSHKey() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("无法获取用户主目录: %w", err)
+// uploadPXEFiles syncs the generated PXE files to a staging directory on the bastion over the
+// built-in SSH client, uploading only files whose checksum changed, and then runs the upload script
+// on the staging copy to publish them to the TFTP and HTTP roots.
+func (g *PXEGenerator) uploadPXEFiles(pxeDir string, parallel int) error {
+	filesDir := filepath.Join(pxeDir, filesDirName)
+	if _, err := os.Stat(filesDir); errors.Is(err, os.ErrNotExist) {
+		return i18n.Errorf("PXE 文件目录不存在: %s", filesDir)
 	}
-	sshKeyPath := filepath.Join(home, ".ssh", "id_rsa.pub")
-	sshKeyBytes, err := os.ReadFile(sshKeyPath)
+	files, err := filepath.Glob(filepath.Join(filesDir, "*"))
 	if err != nil {
-		return "", fmt.Errorf("读取 SSH 公钥失败 (%s): %w", sshKeyPath, err)
-	}
-	return strings.TrimSpace(string(sshKeyBytes)), nil
-}
-
-// getAdditionalTrustBundle finds and reads the custom CA certificate.
-func (g *PXEGenerator) getAdditionalTrustBundle() (string, error) {
-	possibleCertPaths := []string{
-		filepath.Join(g.ClusterDir, registryDirName, g.Config.Registry.IP, "rootCA.pem"),
-		filepath.Join(g.ClusterDir, registryDirName, fmt.Sprintf("registry.%s.%s", g.Config.ClusterInfo.ClusterID, g.Config.ClusterInfo.Domain), "rootCA.pem"),
-		filepath.Join(g.ClusterDir, registryDirName, "rootCA.pem"),
-	}
-
-	for _, certPath := range possibleCertPaths {
-		if caCertBytes, err := os.ReadFile(certPath); err == nil {
-			return string(caCertBytes), nil
-		}
+		return err
 	}
-	return "", errors.New("未在任何预期位置找到 rootCA.pem")
-}
-
-// --- ICSP Parsing (Robust Version) ---
 
-// ICSP represents the structure of an ImageContentSourcePolicy YAML file.
-type ICSP struct {
-	Spec struct {
-		RepositoryDigestMirrors []struct {
-			Source  string   `yaml:"source"`
-			Mirrors []string `yaml:"mirrors"`
-		} `yaml:"repositoryDigestMirrors"`
-	} `yaml:"spec"`
-}
-
-// findAndParseICSP finds the latest ICSP file and parses it robustly using a YAML library.
-func (g *PXEGenerator) findAndParseICSP() (string, error) {
-	workspaceDir, err := g.findOcMirrorWorkspace()
+	client, err := agentinstall.DialBastion(g.Config)
 	if err != nil {
-		return "", err
+		return err
 	}
+	defer client.Close()
 
-	latestResultsDir, err := g.findLatestResultsDir(workspaceDir)
+	stagingDir := RemoteUploadDir(g.ClusterName)
+	g.printInfo(i18n.Sprintf("同步 PXE 文件到 Bastion 的 %s", stagingDir))
+	result, err := sshsync.Sync(client, files, stagingDir, sshsync.Options{Parallel: parallel, Progress: os.Stdout})
 	if err != nil {
-		return "", fmt.Errorf("查找最新 results 目录失败: %w", err)
+		return err
 	}
+	g.printInfo(i18n.Sprintf("上传 %d 个文件 (%s)，%d 个文件未变化已跳过", len(result.Uploaded), workspace.FormatSize(result.Bytes), len(result.Skipped)))
 
-	icspFile := filepath.Join(latestResultsDir, icspFilename)
-	icspContent, err := os.ReadFile(icspFile)
+	uploadCmdStr := fmt.Sprintf("sudo %s %s", uploadScriptPath, stagingDir)
+	g.printInfo(i18n.Sprintf("执行命令: %s", uploadCmdStr))
+	output, err := client.RunCommand(uploadCmdStr)
+	fmt.Print(output)
 	if err != nil {
-		return "", fmt.Errorf("读取 ICSP 文件 %s 失败: %w", icspFile, err)
-	}
-
-	decoder := yaml.NewDecoder(bytes.NewReader(icspContent))
-	var resultBuilder strings.Builder
-
-	for {
-		var icspDoc ICSP
-		if err := decoder.Decode(&icspDoc); err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return "", fmt.Errorf("解析 ICSP YAML 文档失败: %w", err)
-		}
-
-		for _, rdm := range icspDoc.Spec.RepositoryDigestMirrors {
-			// Using a simple template for consistent formatting.
-			mirrorBlock := fmt.Sprintf("- mirrors:\n  - %s\n  source: %s", strings.Join(rdm.Mirrors, "\n  - "), rdm.Source)
-			resultBuilder.WriteString(mirrorBlock)
-			resultBuilder.WriteString("\n")
-		}
-	}
-
-	if resultBuilder.Len() == 0 {
-		return "", errors.New("ICSP 文件中未找到有效的镜像源配置")
+		return i18n.Errorf("执行上传脚本失败: %w", err)
 	}
-	return strings.TrimSpace(resultBuilder.String()), nil
+	return nil
 }
 
-// findOcMirrorWorkspace locates the oc-mirror workspace directory.
-func (g *PXEGenerator) findOcMirrorWorkspace() (string, error) {
-	dirsToCheck := []string{
-		filepath.Join(g.ClusterDir, ocMirrorWorkspaceDir),
-		filepath.Join(g.ClusterDir, imagesDirName, ocMirrorWorkspaceDir),
-	}
-	for _, dir := range dirsToCheck {
-		if _, err := os.Stat(dir); err == nil {
-			return dir, nil
-		}
-	}
-	return "", errors.New("oc-mirror workspace 目录不存在")
+// RemoteUploadDir returns the staging directory on the bastion, relative to the SSH user's home,
+// that setup-pxe keeps in sync with the local PXE files of clusterName.
+func RemoteUploadDir(clusterName string) string {
+	return path.Join(".ocpack", "upload", clusterName, "pxe")
 }
 
-// findLatestResultsDir finds the most recent non-empty 'results-*' directory.
-func (g *PXEGenerator) findLatestResultsDir(workspaceDir string) (string, error) {
-	entries, err := os.ReadDir(workspaceDir)
+// pruneRemoteHistory keeps only the newest bastion.keep_boot_artifacts previous generations
+// of PXE files on the bastion. Failures are reported but do not fail the upload.
+func (g *PXEGenerator) pruneRemoteHistory() {
+	removed, err := PruneHistory(g.Runner, g.Config, g.ClusterName, g.Config.GetKeepBootArtifacts())
 	if err != nil {
-		return "", fmt.Errorf("读取 workspace 目录失败: %w", err)
+		g.printWarning(i18n.T("清理 Bastion 上的历史 PXE 文件失败"), err)
+		return
 	}
-
-	var latestDir string
-	var latestTime int64
-
-	for _, entry := range entries {
-		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "results-") {
-			continue
-		}
-		dirPath := filepath.Join(workspaceDir, entry.Name())
-		if entries, _ := os.ReadDir(dirPath); len(entries) == 0 {
-			continue // Skip empty dirs
-		}
-
-		timestampStr := strings.TrimPrefix(entry.Name(), "results-")
-		if timeValue, err := utils.ParseTimestamp(timestampStr); err == nil {
-			if timeValue > latestTime {
-				latestTime = timeValue
-				latestDir = dirPath
-			}
-		}
-	}
-
-	if latestDir == "" {
-		return "", errors.New("未找到有效的 results 目录")
+	for _, artifact := range removed {
+		g.printInfo(i18n.Sprintf("已清理历史 PXE 文件: %s", artifact.Path))
 	}
-	return latestDir, nil
 }
 
 // --- Utility and Helper Functions ---
 
-// executeTemplate parses a template, executes it with data, and writes to a file.
-func (g *PXEGenerator) executeTemplate(templatePath, outputPath string, data interface{}, funcMap template.FuncMap) error {
-	tmplContent, err := templates.ReadFile(templatePath)
-	if err != nil {
-		return fmt.Errorf("读取模板 %s 失败: %w", templatePath, err)
-	}
-
-	tmpl := template.New(filepath.Base(templatePath))
-	if funcMap != nil {
-		tmpl = tmpl.Funcs(funcMap)
-	}
-
-	tmpl, err = tmpl.Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("解析模板 %s 失败: %w", templatePath, err)
-	}
-
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("创建文件 %s 失败: %w", outputPath, err)
-	}
-	defer file.Close()
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("生成文件 %s 失败: %w", outputPath, err)
-	}
-	return nil
-}
-
-// updateIPXEScript replaces hardcoded URLs in iPXE scripts with the correct asset server URL.
+// updateIPXEScript replaces hardcoded URLs in iPXE scripts with the correct asset server URL
+// and adds the per-host kernel arguments.
 func (g *PXEGenerator) updateIPXEScript(filesDir, assetServerURL string) error {
 	ipxeFiles, err := filepath.Glob(filepath.Join(filesDir, "*.ipxe"))
 	if err != nil || len(ipxeFiles) == 0 {
@@ -609,7 +344,10 @@ func (g *PXEGenerator) updateIPXEScript(filesDir, assetServerURL string) error {
 	}
 
 	if assetServerURL == "" {
-		assetServerURL = fmt.Sprintf("http://%s:%d/%s", g.Config.Bastion.IP, defaultPxeWebServerPort, g.ClusterName)
+		assetServerURL, err = g.assetServerURL(g.ClusterName)
+		if err != nil {
+			return err
+		}
 	}
 
 	// This is the default URL structure generated by openshift-install
@@ -618,42 +356,26 @@ func (g *PXEGenerator) updateIPXEScript(filesDir, assetServerURL string) error {
 	for _, ipxeFile := range ipxeFiles {
 		content, err := os.ReadFile(ipxeFile)
 		if err != nil {
-			g.printWarning(fmt.Sprintf("读取 iPXE 文件 %s 失败", ipxeFile), err)
+			g.printWarning(i18n.Sprintf("读取 iPXE 文件 %s 失败", ipxeFile), err)
 			continue
 		}
 		// Replace the base URL prefix for all assets
 		updatedContent := strings.ReplaceAll(string(content), oldBaseURL, assetServerURL)
+		// Hosts with kernel_args or console get their own arguments, selected by MAC
+		updatedContent = addHostKernelArgs(updatedContent, g.Config.BootProfiles())
 
 		if err := os.WriteFile(ipxeFile, []byte(updatedContent), 0644); err != nil {
-			return fmt.Errorf("更新 iPXE 文件 %s 失败: %w", ipxeFile, err)
+			return i18n.Errorf("更新 iPXE 文件 %s 失败: %w", ipxeFile, err)
 		}
 	}
 	return nil
 }
 
-// findOpenshiftInstall locates the openshift-install binary.
-func (g *PXEGenerator) findOpenshiftInstall() (string, error) {
-	// Prioritize the version extracted from the local registry
-	registryHost := fmt.Sprintf("registry.%s.%s", g.Config.ClusterInfo.ClusterID, g.Config.ClusterInfo.Domain)
-	extractedBinary := filepath.Join(g.ClusterDir, fmt.Sprintf("%s-%s-%s", openshiftInstallCmd, g.Config.ClusterInfo.OpenShiftVersion, registryHost))
-	if _, err := os.Stat(extractedBinary); err == nil {
-		return extractedBinary, nil
-	}
-
-	// Fallback to the one in the download directory
-	downloadedBinary := filepath.Join(g.DownloadDir, "bin", openshiftInstallCmd)
-	if _, err := os.Stat(downloadedBinary); err == nil {
-		return downloadedBinary, nil
-	}
-
-	return "", fmt.Errorf("在 %s 或 %s 中未找到 %s 工具", extractedBinary, downloadedBinary, openshiftInstallCmd)
-}
-
 // moveAndCountFiles moves files from src to dst, ignoring specified files, and returns the count.
 func (g *PXEGenerator) moveAndCountFiles(srcDir, dstDir string, ignore map[string]bool) (int, error) {
 	entries, err := os.ReadDir(srcDir)
 	if err != nil {
-		return 0, fmt.Errorf("读取目录 %s 失败: %w", srcDir, err)
+		return 0, i18n.Errorf("读取目录 %s 失败: %w", srcDir, err)
 	}
 
 	count := 0
@@ -666,7 +388,7 @@ func (g *PXEGenerator) moveAndCountFiles(srcDir, dstDir string, ignore map[strin
 		if err := utils.MoveFile(srcPath, dstPath); err == nil {
 			count++
 		} else {
-			g.printWarning(fmt.Sprintf("移动文件 %s 失败", entry.Name()), err)
+			g.printWarning(i18n.Sprintf("移动文件 %s 失败", entry.Name()), err)
 		}
 	}
 	return count, nil
@@ -711,28 +433,32 @@ func (g *PXEGenerator) printManualUploadInstructions(pxeDir string) {
 	sshUser := g.Config.Bastion.Username
 	sshIP := g.Config.Bastion.IP
 
-	fmt.Printf("\n📋 手动上传步骤:\n")
+	i18n.Printf("\n📋 手动上传步骤:\n")
 	fmt.Printf("┌─────────────────────────────────────────────────────────────┐\n")
-	fmt.Printf("│ 1. 使用上传脚本:                                            │\n")
+	i18n.Printf("│ 1. 使用上传脚本:                                            │\n")
 	fmt.Printf("│    ssh %s@%s 'sudo %s %s' │\n", sshUser, sshIP, uploadScriptPath, filesPath)
 	fmt.Printf("│                                                             │\n")
-	fmt.Printf("│ 2. 或手动复制文件:                                          │\n")
+	i18n.Printf("│ 2. 或手动复制文件:                                          │\n")
 	fmt.Printf("│    scp %s/* %s@%s:/tmp/                │\n", filesPath, sshUser, sshIP)
 	fmt.Printf("│    ssh %s@%s 'sudo cp /tmp/agent.x86_64-* /var/www/html/pxe/%s/' │\n", sshUser, sshIP, g.ClusterName)
 	fmt.Printf("│                                                             │\n")
-	fmt.Printf("│ 3. 验证文件访问:                                            │\n")
+	i18n.Printf("│ 3. 验证文件访问:                                            │\n")
 	fmt.Printf("│    curl http://%s:%d/pxe/%s/                         │\n", sshIP, defaultPxeWebServerPort, g.ClusterName)
 	fmt.Printf("└─────────────────────────────────────────────────────────────┘\n\n")
 }
 
 func (g *PXEGenerator) printCompletion(pxeDir string) {
 	pxeURL := fmt.Sprintf("http://%s:%d/pxe/%s", g.Config.Bastion.IP, defaultPxeWebServerPort, g.ClusterName)
+	if g.Config.Infra.PXEAssetURL != "" {
+		pxeURL = g.Config.Infra.PXEAssetURL
+	}
 	fmt.Printf("╔══════════════════════════════════════════════════════════════╗\n")
-	fmt.Printf("║ %s ║\n", padRight("✅ PXE 文件生成完成！", 60))
+	fmt.Printf("║ %s ║\n", padRight(i18n.T("✅ PXE 文件生成完成！"), 60))
 	fmt.Printf("║ %s ║\n", padRight("", 60))
-	fmt.Printf("║ %s ║\n", padRight(fmt.Sprintf("📁 文件位置: %s", pxeDir), 60))
-	fmt.Printf("║ %s ║\n", padRight(fmt.Sprintf("🌐 PXE 服务器: %s", pxeURL), 60))
+	fmt.Printf("║ %s ║\n", padRight(i18n.Sprintf("📁 文件位置: %s", pxeDir), 60))
+	fmt.Printf("║ %s ║\n", padRight(i18n.Sprintf("🌐 PXE 服务器: %s", pxeURL), 60))
+	fmt.Printf("║ %s ║\n", padRight(i18n.Sprintf("🧭 Rendezvous 节点: %s", g.RendezvousSummary()), 60))
 	fmt.Printf("║ %s ║\n", padRight("", 60))
-	fmt.Printf("║ %s ║\n", padRight("🚀 下一步: 配置目标机器从 PXE 启动", 60))
+	fmt.Printf("║ %s ║\n", padRight(i18n.T("🚀 下一步: 配置目标机器从 PXE 启动"), 60))
 	fmt.Printf("╚══════════════════════════════════════════════════════════════╝\n")
 }
@@ -0,0 +1,63 @@
+package pxe
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/auth"
+	"ocpack/pkg/config"
+)
+
+// TestGenerateInstallConfigImageSources checks that the PXE install-config picks up the
+// IDMS written by the built-in oc-mirror under working-dir/cluster-resources, like the ISO flow.
+func TestGenerateInstallConfigImageSources(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.Cluster.Network.MachineNetwork = "192.168.1.0/24"
+	clusterDir := t.TempDir()
+	writeTestFile(t, auth.MergedAuthPath(clusterDir), `{"auths":{}}`)
+	writeTestFile(t, filepath.Join(clusterDir, "images", "working-dir", "cluster-resources", "idms-oc-mirror.yaml"), `apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: idms-release-0
+spec:
+  imageDigestMirrors:
+  - mirrors:
+    - registry.demo.example.com:8443/openshift/release
+    source: quay.io/openshift-release-dev/ocp-v4.0-art-dev
+`)
+
+	g := &PXEGenerator{Renderer: &agentinstall.Renderer{
+		Config:      cfg,
+		ClusterName: "demo",
+		ClusterDir:  clusterDir,
+		Hooks:       agentinstall.Hooks{Info: func(string) {}, Warn: func(string) {}},
+	}}
+	pxeDir := filepath.Join(clusterDir, pxeDirName)
+	if err := g.createPXEDirs(pxeDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.generateInstallConfig(pxeDir); err != nil {
+		t.Fatalf("generateInstallConfig() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(pxeDir, configDirName, agentinstall.InstallConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"imageDigestSources:",
+		"  - registry.demo.example.com:8443/openshift/release",
+		"  source: quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("PXE install-config.yaml missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(pxeDir, configDirName, agentinstall.ManifestsDirName, "image-digest-mirror-set.yaml")); err != nil {
+		t.Errorf("expected IDMS manifest for the PXE install: %v", err)
+	}
+}
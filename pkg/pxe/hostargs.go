@@ -0,0 +1,50 @@
+package pxe
+
+import (
+	"fmt"
+	"strings"
+
+	"ocpack/pkg/config"
+)
+
+// hostKernelArgsVar is the iPXE variable holding the kernel arguments of the booting host.
+const hostKernelArgsVar = "ocpack_kargs"
+
+// addHostKernelArgs makes an iPXE script apply per-host kernel arguments (kernel_args and console).
+// All hosts boot the same script, so a branch per host compares ${netX/mac}, the MAC of the
+// interface that is booting, and sets ${ocpack_kargs}; the variable is appended to every kernel
+// line and expands to nothing for the other hosts. Scripts are returned unchanged when no host
+// has extra arguments or the script was already processed.
+func addHostKernelArgs(script string, profiles []config.BootProfile) string {
+	if len(profiles) == 0 || strings.Contains(script, "${"+hostKernelArgsVar+"}") {
+		return script
+	}
+
+	var branches []string
+	for _, profile := range profiles {
+		args := strings.Join(profile.KernelArgs, " ")
+		for _, node := range profile.Nodes {
+			branches = append(branches, fmt.Sprintf("iseq ${netX/mac} %s && set %s %s ||",
+				strings.ToLower(node.MAC), hostKernelArgsVar, args))
+		}
+	}
+
+	lines := strings.Split(script, "\n")
+	var out []string
+	inserted := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "kernel ") {
+			line = strings.TrimRight(line, " ") + " ${" + hostKernelArgsVar + "}"
+		}
+		out = append(out, line)
+		// The branches go right after the #!ipxe header, before any kernel line
+		if i == 0 && strings.HasPrefix(line, "#!ipxe") {
+			out = append(out, branches...)
+			inserted = true
+		}
+	}
+	if !inserted {
+		out = append(branches, out...)
+	}
+	return strings.Join(out, "\n")
+}
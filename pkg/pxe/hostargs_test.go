@@ -0,0 +1,47 @@
+package pxe
+
+import (
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+)
+
+const agentIPXE = `#!ipxe
+initrd --name initrd http://192.168.1.2:8080/pxe/demo/agent.x86_64-initrd.img
+kernel http://192.168.1.2:8080/pxe/demo/agent.x86_64-vmlinuz initrd=initrd coreos.live.rootfs_url=http://192.168.1.2:8080/pxe/demo/agent.x86_64-rootfs.img ignition.firstboot ignition.platform.id=metal
+boot
+`
+
+func TestAddHostKernelArgs(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	if got := addHostKernelArgs(agentIPXE, cfg.BootProfiles()); got != agentIPXE {
+		t.Errorf("script without host kernel args changed:\n%s", got)
+	}
+
+	cfg.Cluster.ControlPlane[0].MAC = "52:54:00:AA:00:01"
+	cfg.Cluster.ControlPlane[0].Console = "ttyS0,115200n8"
+	cfg.Cluster.Worker[0].MAC = "52:54:00:aa:00:02"
+	cfg.Cluster.Worker[0].KernelArgs = []string{"nomodeset", "rd.driver.blacklist=nouveau"}
+	got := addHostKernelArgs(agentIPXE, cfg.BootProfiles())
+
+	lines := strings.Split(got, "\n")
+	want := []string{
+		"#!ipxe",
+		"iseq ${netX/mac} 52:54:00:aa:00:01 && set ocpack_kargs console=ttyS0,115200n8 ||",
+		"iseq ${netX/mac} 52:54:00:aa:00:02 && set ocpack_kargs nomodeset rd.driver.blacklist=nouveau ||",
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], line)
+		}
+	}
+	if !strings.Contains(got, "ignition.platform.id=metal ${ocpack_kargs}\nboot") {
+		t.Errorf("kernel line missing ${ocpack_kargs}:\n%s", got)
+	}
+
+	// Processing the script again does not add the branches twice
+	if again := addHostKernelArgs(got, cfg.BootProfiles()); again != got {
+		t.Errorf("script processed twice:\n%s", again)
+	}
+}
@@ -0,0 +1,97 @@
+package pxe
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ocpack/pkg/agentinstall"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+)
+
+// remoteHistoryDir is where upload-pxe-files.sh moves the previous generations of
+// each cluster's PXE files, one timestamped directory per upload.
+const remoteHistoryDir = "/var/www/html/pxe/.history"
+
+// RemoteArtifact is a previous generation of PXE files kept on the bastion.
+type RemoteArtifact struct {
+	Path string
+	Size int64
+}
+
+// RemoteHistoryDir returns the bastion directory holding previous generations for clusterName.
+func RemoteHistoryDir(clusterName string) string {
+	return path.Join(remoteHistoryDir, clusterName)
+}
+
+// StaleArtifacts lists the previous generations of clusterName's PXE files on the bastion,
+// except for the newest keep. Generations are named by upload time, so they sort by name.
+func StaleArtifacts(r runner.CommandRunner, cfg *config.ClusterConfig, clusterName string, keep int) ([]RemoteArtifact, error) {
+	dir := RemoteHistoryDir(clusterName)
+	listCmd := fmt.Sprintf("sudo find %s -mindepth 1 -maxdepth 1 -type d -exec du -sb {} + 2>/dev/null || true", dir)
+	result, err := r.Run(agentinstall.BastionSSHCommand(cfg, listCmd))
+	if err != nil {
+		return nil, i18n.Errorf("列出 Bastion 上的历史 PXE 文件失败: %w\n输出: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+
+	var artifacts []RemoteArtifact
+	for _, line := range strings.Split(strings.TrimSpace(string(result.Stdout)), "\n") {
+		size, artifactPath, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		bytes, err := strconv.ParseInt(size, 10, 64)
+		if err != nil || path.Dir(artifactPath) != dir {
+			continue
+		}
+		artifacts = append(artifacts, RemoteArtifact{Path: artifactPath, Size: bytes})
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path > artifacts[j].Path })
+
+	if keep < 0 {
+		keep = 0
+	}
+	if len(artifacts) <= keep {
+		return nil, nil
+	}
+	return artifacts[keep:], nil
+}
+
+// RemoveArtifacts deletes previous generations of clusterName's PXE files from the bastion.
+// Only directories directly under the cluster's history directory are accepted.
+func RemoveArtifacts(r runner.CommandRunner, cfg *config.ClusterConfig, clusterName string, artifacts []RemoteArtifact) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	dir := RemoteHistoryDir(clusterName)
+	paths := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if path.Dir(artifact.Path) != dir || strings.ContainsAny(artifact.Path, " '\"$`\\;&|") {
+			return i18n.Errorf("拒绝删除 %s: 不是 %s 下的历史版本目录", artifact.Path, dir)
+		}
+		paths = append(paths, artifact.Path)
+	}
+
+	result, err := r.Run(agentinstall.BastionSSHCommand(cfg, "sudo rm -rf -- "+strings.Join(paths, " ")))
+	if err != nil {
+		return i18n.Errorf("删除 Bastion 上的历史 PXE 文件失败: %w\n输出: %s", err, strings.TrimSpace(string(result.Combined)))
+	}
+	return nil
+}
+
+// PruneHistory removes all but the newest keep previous generations of clusterName's
+// PXE files from the bastion and returns what was removed.
+func PruneHistory(r runner.CommandRunner, cfg *config.ClusterConfig, clusterName string, keep int) ([]RemoteArtifact, error) {
+	stale, err := StaleArtifacts(r, cfg, clusterName, keep)
+	if err != nil {
+		return nil, err
+	}
+	if err := RemoveArtifacts(r, cfg, clusterName, stale); err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
@@ -0,0 +1,95 @@
+package pxe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const historyListing = "1024\t/var/www/html/pxe/.history/demo/20260101-100000\n" +
+	"4096\t/var/www/html/pxe/.history/demo/20260301-100000\n" +
+	"2048\t/var/www/html/pxe/.history/demo/20260201-100000\n"
+
+func retentionConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Bastion.IP = "192.168.1.2"
+	cfg.Bastion.Username = "root"
+	cfg.Bastion.SSHKeyPath = "/root/.ssh/id_rsa"
+	return cfg
+}
+
+func TestStaleArtifacts(t *testing.T) {
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Stdout: []byte(historyListing)}, nil
+	}}
+
+	tests := []struct {
+		keep int
+		want []string
+	}{
+		{0, []string{
+			"/var/www/html/pxe/.history/demo/20260301-100000",
+			"/var/www/html/pxe/.history/demo/20260201-100000",
+			"/var/www/html/pxe/.history/demo/20260101-100000",
+		}},
+		{2, []string{"/var/www/html/pxe/.history/demo/20260101-100000"}},
+		{3, nil},
+	}
+	for _, tt := range tests {
+		stale, err := StaleArtifacts(fake, retentionConfig(), "demo", tt.keep)
+		if err != nil {
+			t.Fatalf("StaleArtifacts(keep=%d) error = %v", tt.keep, err)
+		}
+		var got []string
+		for _, artifact := range stale {
+			got = append(got, artifact.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StaleArtifacts(keep=%d) = %v, want %v", tt.keep, got, tt.want)
+		}
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		if strings.Contains(cmd.Args[len(cmd.Args)-1], "find ") {
+			return &runner.Result{Stdout: []byte(historyListing)}, nil
+		}
+		return nil, nil
+	}}
+
+	removed, err := PruneHistory(fake, retentionConfig(), "demo", 1)
+	if err != nil {
+		t.Fatalf("PruneHistory() error = %v", err)
+	}
+	if len(removed) != 2 || removed[0].Size != 2048 {
+		t.Errorf("removed = %+v", removed)
+	}
+
+	lines := fake.CommandLines()
+	want := "ssh -i /root/.ssh/id_rsa -o StrictHostKeyChecking=no root@192.168.1.2 sudo rm -rf -- " +
+		"/var/www/html/pxe/.history/demo/20260201-100000 /var/www/html/pxe/.history/demo/20260101-100000"
+	if len(lines) != 2 || lines[1] != want {
+		t.Errorf("commands = %q, want last %q", lines, want)
+	}
+}
+
+func TestRemoveArtifactsRejectsOtherPaths(t *testing.T) {
+	fake := &runner.Fake{}
+	for _, path := range []string{
+		"/var/www/html/pxe/demo",
+		"/var/www/html/pxe/.history/other/20260101-100000",
+		"/var/www/html/pxe/.history/demo/x; rm -rf /",
+	} {
+		err := RemoveArtifacts(fake, retentionConfig(), "demo", []RemoteArtifact{{Path: path}})
+		if err == nil {
+			t.Errorf("RemoveArtifacts(%q) expected error", path)
+		}
+	}
+	if len(fake.Calls()) != 0 {
+		t.Errorf("no command should run, got %v", fake.CommandLines())
+	}
+}
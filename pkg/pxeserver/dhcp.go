@@ -0,0 +1,159 @@
+package pxeserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+)
+
+// ProxyDHCP 使用的 DHCP 消息类型和选项代码 (RFC 2131、RFC 2132、RFC 4578)
+const (
+	dhcpDiscover = 1
+	dhcpOffer    = 2
+	dhcpRequest  = 3
+	dhcpAck      = 5
+
+	optPad            = 0
+	optVendorSpecific = 43
+	optMessageType    = 53
+	optServerID       = 54
+	optVendorClass    = 60
+	optUserClass      = 77
+	optClientArch     = 93
+	optClientUUID     = 97
+	optEnd            = 255
+
+	bootRequest = 1
+	bootReply   = 2
+
+	dhcpHeaderLen = 236
+	flagBroadcast = 0x8000
+)
+
+var dhcpMagicCookie = []byte{99, 130, 83, 99}
+
+// pxeVendorOptions 让 PXE ROM 跳过引导服务器发现，直接引导 offer 中的文件 (PXE_DISCOVERY_CONTROL = 8)
+var pxeVendorOptions = []byte{6, 1, 8, optEnd}
+
+// 选项 93 中的客户端系统架构 (RFC 4578)
+const (
+	archBIOS   = 0
+	archEFIx64 = 7
+	archEFIBC  = 9
+)
+
+// dhcpPacket ProxyDHCP 读写的 DHCP 消息字段
+type dhcpPacket struct {
+	op      byte
+	xid     []byte
+	secs    []byte
+	flags   uint16
+	ciaddr  net.IP
+	siaddr  net.IP
+	giaddr  net.IP
+	chaddr  net.HardwareAddr
+	file    string
+	options map[byte][]byte
+}
+
+// parseDHCP 解析 DHCP 消息，只支持以太网客户端
+func parseDHCP(b []byte) (*dhcpPacket, error) {
+	if len(b) < dhcpHeaderLen+len(dhcpMagicCookie) {
+		return nil, errors.New("packet too short")
+	}
+	if !bytes.Equal(b[dhcpHeaderLen:dhcpHeaderLen+4], dhcpMagicCookie) {
+		return nil, errors.New("missing DHCP magic cookie")
+	}
+	if b[1] != 1 || b[2] != 6 {
+		return nil, errors.New("unsupported hardware type")
+	}
+
+	p := &dhcpPacket{
+		op:      b[0],
+		xid:     append([]byte(nil), b[4:8]...),
+		secs:    append([]byte(nil), b[8:10]...),
+		flags:   binary.BigEndian.Uint16(b[10:12]),
+		ciaddr:  net.IP(append([]byte(nil), b[12:16]...)),
+		siaddr:  net.IP(append([]byte(nil), b[20:24]...)),
+		giaddr:  net.IP(append([]byte(nil), b[24:28]...)),
+		chaddr:  net.HardwareAddr(append([]byte(nil), b[28:34]...)),
+		file:    strings.TrimRight(string(b[108:236]), "\x00"),
+		options: make(map[byte][]byte),
+	}
+
+	opts := b[dhcpHeaderLen+4:]
+	for i := 0; i < len(opts); {
+		code := opts[i]
+		if code == optEnd {
+			break
+		}
+		if code == optPad {
+			i++
+			continue
+		}
+		if i+1 >= len(opts) || i+2+int(opts[i+1]) > len(opts) {
+			return nil, errors.New("truncated option")
+		}
+		length := int(opts[i+1])
+		p.options[code] = append(p.options[code], opts[i+2:i+2+length]...)
+		i += 2 + length
+	}
+	return p, nil
+}
+
+// marshal 编码消息，选项按代码升序写入
+func (p *dhcpPacket) marshal() []byte {
+	b := make([]byte, dhcpHeaderLen, 300)
+	b[0] = p.op
+	b[1], b[2] = 1, 6
+	copy(b[4:8], p.xid)
+	copy(b[8:10], p.secs)
+	binary.BigEndian.PutUint16(b[10:12], p.flags)
+	copy(b[12:16], p.ciaddr.To4())
+	copy(b[20:24], p.siaddr.To4())
+	copy(b[24:28], p.giaddr.To4())
+	copy(b[28:34], p.chaddr)
+	if p.siaddr != nil {
+		copy(b[44:108], p.siaddr.String())
+	}
+	copy(b[108:236], p.file)
+	b = append(b, dhcpMagicCookie...)
+
+	for code := 1; code < optEnd; code++ {
+		value, ok := p.options[byte(code)]
+		if !ok {
+			continue
+		}
+		b = append(b, byte(code), byte(len(value)))
+		b = append(b, value...)
+	}
+	b = append(b, optEnd)
+	return b
+}
+
+func (p *dhcpPacket) messageType() byte {
+	if v := p.options[optMessageType]; len(v) == 1 {
+		return v[0]
+	}
+	return 0
+}
+
+// isPXEClient 判断厂商类别标识是否表示 PXE ROM 或 iPXE
+func (p *dhcpPacket) isPXEClient() bool {
+	return strings.HasPrefix(string(p.options[optVendorClass]), "PXEClient")
+}
+
+// isIPXE 判断请求是否来自 iPXE (用户类别为 "iPXE")
+func (p *dhcpPacket) isIPXE() bool {
+	return string(p.options[optUserClass]) == "iPXE"
+}
+
+// clientArch 返回选项 93 中的客户端架构，默认为 BIOS
+func (p *dhcpPacket) clientArch() int {
+	if v := p.options[optClientArch]; len(v) >= 2 {
+		return int(binary.BigEndian.Uint16(v[:2]))
+	}
+	return archBIOS
+}
@@ -0,0 +1,285 @@
+// Package pxeserver 为无法修改现场 DHCP 服务器的 PXE 环境提供最小化的 ProxyDHCP 和 TFTP 服务。
+// 地址仍由现场 DHCP 服务器分配，ProxyDHCP 只为 config.toml 中列出的 MAC 地址补充引导信息：
+// 先通过 TFTP 链式加载 iPXE，再加载 openshift-install 生成的 iPXE 脚本
+package pxeserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ocpack/pkg/config"
+)
+
+// 提供给客户端的引导文件名，iPXE 程序从 BootloaderDir 读取
+const (
+	BIOSBootfile = "undionly.kpxe"
+	EFIBootfile  = "ipxe.efi"
+)
+
+// DefaultBootloaderDir ipxe-bootimgs 软件包安装 iPXE 程序的目录
+const DefaultBootloaderDir = "/usr/share/ipxe"
+
+// efiBootfileNames 在 BootloaderDir 中查找 EFIBootfile 时依次尝试的文件名
+var efiBootfileNames = []string{EFIBootfile, "ipxe-x86_64.efi"}
+
+// Server 使用 <集群目录>/pxe/files 中生成的引导文件应答 PXE 客户端
+type Server struct {
+	ServerIP      net.IP            // 作为 next-server 和 DHCP 服务器标识通告的地址
+	Hosts         map[string]string // 小写 MAC 地址 -> 节点名称，忽略其他客户端
+	FilesDir      string            // 生成的 PXE 文件，包括 *.ipxe 脚本
+	BootloaderDir string            // undionly.kpxe 和 ipxe.efi 所在目录
+	IPXEScript    string            // FilesDir 中 iPXE 脚本的文件名
+
+	// 监听地址，为空时使用标准端口
+	TFTPAddr string
+	DHCPAddr string
+	PXEAddr  string
+
+	Logf func(format string, args ...interface{})
+}
+
+// NewServer 为 clusterDir 中的集群创建服务。serverIP 为空时使用本机位于
+// cluster.network.machine_network 中的地址
+func NewServer(cfg *config.ClusterConfig, clusterDir, serverIP, bootloaderDir string) (*Server, error) {
+	ip, err := resolveServerIP(serverIP, cfg.Cluster.Network.MachineNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	filesDir := filepath.Join(clusterDir, "pxe", "files")
+	scripts, _ := filepath.Glob(filepath.Join(filesDir, "*.ipxe"))
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("%s 中未找到 iPXE 脚本，请先生成 PXE 文件", filesDir)
+	}
+	sort.Strings(scripts)
+
+	hosts := make(map[string]string)
+	for _, nodes := range [][]config.Node{cfg.Cluster.ControlPlane, cfg.Cluster.Worker} {
+		for _, node := range nodes {
+			if mac, err := net.ParseMAC(node.MAC); err == nil {
+				hosts[mac.String()] = node.Name
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("config.toml 中没有配置节点 MAC 地址")
+	}
+
+	if bootloaderDir == "" {
+		bootloaderDir = DefaultBootloaderDir
+	}
+	return &Server{
+		ServerIP:      ip,
+		Hosts:         hosts,
+		FilesDir:      filesDir,
+		BootloaderDir: bootloaderDir,
+		IPXEScript:    filepath.Base(scripts[0]),
+		Logf:          func(format string, args ...interface{}) { fmt.Printf(format+"\n", args...) },
+	}, nil
+}
+
+// ListenAndServe 提供 TFTP 服务，proxyDHCP 为 true 时同时在 67 和 4011 端口提供 ProxyDHCP，
+// 直到 ctx 取消。监听这些端口需要 root 权限
+func (s *Server) ListenAndServe(ctx context.Context, proxyDHCP bool) error {
+	type listener struct {
+		addr  string
+		serve func(net.PacketConn) error
+	}
+	listeners := []listener{{withDefault(s.TFTPAddr, ":69"), s.serveTFTP}}
+	if proxyDHCP {
+		listeners = append(listeners,
+			listener{withDefault(s.DHCPAddr, ":67"), func(conn net.PacketConn) error { return s.serveDHCP(conn, false) }},
+			listener{withDefault(s.PXEAddr, ":4011"), func(conn net.PacketConn) error { return s.serveDHCP(conn, true) }},
+		)
+	}
+
+	var conns []net.PacketConn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		conn, err := net.ListenPacket("udp4", l.addr)
+		if err != nil {
+			return fmt.Errorf("监听 %s 失败 (需要 root 权限，且端口未被 dnsmasq/dhcpd/tftp 占用): %w", l.addr, err)
+		}
+		conns = append(conns, conn)
+		go func(serve func(net.PacketConn) error, conn net.PacketConn) { errs <- serve(conn) }(l.serve, conn)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
+
+// serveDHCP 应答 conn 上的 PXE 请求直到连接关闭，bootServer 表示 PXE 引导服务器端口 4011
+func (s *Server) serveDHCP(conn net.PacketConn, bootServer bool) error {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		req, err := parseDHCP(buf[:n])
+		if err != nil {
+			continue
+		}
+		reply, dest := s.proxyReply(req, addr, bootServer)
+		if reply == nil {
+			continue
+		}
+		if _, err := conn.WriteTo(reply.marshal(), dest); err != nil {
+			s.logf("❌ 向 %s 发送 ProxyDHCP 应答失败: %v", req.chaddr, err)
+			continue
+		}
+		s.logf("📡 %s (%s) -> %s", req.chaddr, s.Hosts[req.chaddr.String()], reply.file)
+	}
+}
+
+// proxyReply 生成对 req 的 ProxyDHCP 应答及其发送地址，不是 config.toml 中 PXE 客户端的请求返回 nil。
+// 67 端口的 DHCPDISCOVER 应答不含地址的 offer，发往引导服务器的 DHCPREQUEST 应答 ack
+func (s *Server) proxyReply(req *dhcpPacket, from net.Addr, bootServer bool) (*dhcpPacket, net.Addr) {
+	if req.op != bootRequest || !req.isPXEClient() {
+		return nil, nil
+	}
+	if _, ok := s.Hosts[req.chaddr.String()]; !ok {
+		return nil, nil
+	}
+
+	var msgType byte
+	var dest net.Addr
+	switch {
+	case !bootServer && req.messageType() == dhcpDiscover:
+		msgType = dhcpOffer
+		dest = &net.UDPAddr{IP: net.IPv4bcast, Port: 68}
+		if !req.giaddr.IsUnspecified() {
+			dest = &net.UDPAddr{IP: req.giaddr, Port: 67}
+		}
+	case bootServer && req.messageType() == dhcpRequest:
+		// 客户端已从现场 DHCP 服务器获得地址，直接向本服务请求
+		msgType = dhcpAck
+		dest = from
+	default:
+		return nil, nil
+	}
+
+	bootfile := s.bootfile(req)
+	if bootfile == "" {
+		return nil, nil
+	}
+	reply := &dhcpPacket{
+		op:     bootReply,
+		xid:    req.xid,
+		secs:   req.secs,
+		flags:  req.flags | flagBroadcast,
+		ciaddr: req.ciaddr,
+		siaddr: s.ServerIP,
+		giaddr: req.giaddr,
+		chaddr: req.chaddr,
+		file:   bootfile,
+		options: map[byte][]byte{
+			optMessageType:    {msgType},
+			optServerID:       s.ServerIP.To4(),
+			optVendorClass:    []byte("PXEClient"),
+			optVendorSpecific: pxeVendorOptions,
+		},
+	}
+	if uuid, ok := req.options[optClientUUID]; ok {
+		reply.options[optClientUUID] = uuid
+	}
+	return reply, dest
+}
+
+// bootfile 返回客户端应引导的文件：iPXE 已运行时为 iPXE 脚本，否则为对应固件的 iPXE 程序。
+// 忽略 BIOS 和 x86_64 UEFI 以外的客户端
+func (s *Server) bootfile(req *dhcpPacket) string {
+	if req.isIPXE() {
+		return s.IPXEScript
+	}
+	switch req.clientArch() {
+	case archBIOS:
+		return BIOSBootfile
+	case archEFIx64, archEFIBC:
+		return EFIBootfile
+	default:
+		return ""
+	}
+}
+
+// resolveFile 将 TFTP 文件名映射为路径，只提供 iPXE 程序和 FilesDir 中的文件
+func (s *Server) resolveFile(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("不允许的文件名")
+	}
+
+	candidates := []string{filepath.Join(s.FilesDir, name)}
+	switch name {
+	case BIOSBootfile:
+		candidates = []string{filepath.Join(s.BootloaderDir, BIOSBootfile)}
+	case EFIBootfile:
+		candidates = nil
+		for _, n := range efiBootfileNames {
+			candidates = append(candidates, filepath.Join(s.BootloaderDir, n))
+		}
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("文件不存在")
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// resolveServerIP 解析 serverIP，为空时查找本机位于 machineNetwork 中的 IPv4 地址
+func resolveServerIP(serverIP, machineNetwork string) (net.IP, error) {
+	if serverIP != "" {
+		ip := net.ParseIP(serverIP).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("无效的服务器 IP: %s", serverIP)
+		}
+		return ip, nil
+	}
+
+	_, network, err := net.ParseCIDR(machineNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 machine_network %q，请使用 --server-ip 指定本机 IP", machineNetwork)
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && network.Contains(ipNet.IP) {
+			return ipNet.IP.To4(), nil
+		}
+	}
+	return nil, fmt.Errorf("本机没有位于 %s 的地址，请使用 --server-ip 指定", machineNetwork)
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,264 @@
+package pxeserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+)
+
+const testMAC = "52:54:00:00:00:21"
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	clusterDir := t.TempDir()
+	filesDir := filepath.Join(clusterDir, "pxe", "files")
+	bootloaderDir := filepath.Join(clusterDir, "ipxe")
+	for _, dir := range []string{filesDir, bootloaderDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(filesDir, "agent.x86_64.ipxe"):          "#!ipxe\n",
+		filepath.Join(bootloaderDir, BIOSBootfile):            "bios",
+		filepath.Join(bootloaderDir, "ipxe-x86_64.efi"):       "efi",
+		filepath.Join(clusterDir, "config.toml"):              "secret",
+		filepath.Join(filesDir, "agent.x86_64-initrd.img"):    string(bytes.Repeat([]byte("i"), 1300)),
+		filepath.Join(filesDir, "agent.x86_64-vmlinuz-empty"): "",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Cluster.ControlPlane[0].MAC = "52:54:00:00:00:21"
+	cfg.Cluster.ControlPlane[1].MAC = "52-54-00-00-00-22"
+	s, err := NewServer(cfg, clusterDir, "192.168.1.5", bootloaderDir)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.Logf = func(string, ...interface{}) {}
+	return s
+}
+
+func dhcpRequestPacket(t *testing.T, mac string, msgType byte, options map[byte][]byte) *dhcpPacket {
+	t.Helper()
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := map[byte][]byte{optMessageType: {msgType}, optVendorClass: []byte("PXEClient:Arch:00000:UNDI:002001")}
+	for code, value := range options {
+		opts[code] = value
+	}
+	// round-trip through the wire format so parsing is covered as well
+	p, err := parseDHCP((&dhcpPacket{
+		op:      bootRequest,
+		xid:     []byte{1, 2, 3, 4},
+		secs:    []byte{0, 0},
+		ciaddr:  net.IPv4zero,
+		giaddr:  net.IPv4zero,
+		chaddr:  hw,
+		options: opts,
+	}).marshal())
+	if err != nil {
+		t.Fatalf("parseDHCP: %v", err)
+	}
+	return p
+}
+
+func TestNewServer(t *testing.T) {
+	s := newTestServer(t)
+	if s.IPXEScript != "agent.x86_64.ipxe" {
+		t.Errorf("IPXEScript = %q", s.IPXEScript)
+	}
+	if s.Hosts["52:54:00:00:00:22"] != "master-1" {
+		t.Errorf("Hosts = %v, expected MAC addresses to be normalized", s.Hosts)
+	}
+
+	cfg := config.NewDefaultConfig("demo")
+	if _, err := NewServer(cfg, t.TempDir(), "192.168.1.5", ""); err == nil {
+		t.Error("expected error without generated iPXE script")
+	}
+	if _, err := NewServer(cfg, t.TempDir(), "not-an-ip", ""); err == nil {
+		t.Error("expected error for invalid server IP")
+	}
+}
+
+func TestProxyReply(t *testing.T) {
+	s := newTestServer(t)
+	arch := func(a uint16) []byte { return binary.BigEndian.AppendUint16(nil, a) }
+
+	tests := []struct {
+		name       string
+		mac        string
+		msgType    byte
+		options    map[byte][]byte
+		bootServer bool
+		wantType   byte
+		wantFile   string
+	}{
+		{"bios discover", testMAC, dhcpDiscover, map[byte][]byte{optClientArch: arch(archBIOS)}, false, dhcpOffer, BIOSBootfile},
+		{"uefi discover", testMAC, dhcpDiscover, map[byte][]byte{optClientArch: arch(archEFIx64)}, false, dhcpOffer, EFIBootfile},
+		{"ipxe discover", testMAC, dhcpDiscover, map[byte][]byte{optUserClass: []byte("iPXE")}, false, dhcpOffer, "agent.x86_64.ipxe"},
+		{"boot server request", testMAC, dhcpRequest, map[byte][]byte{optClientArch: arch(archEFIBC)}, true, dhcpAck, EFIBootfile},
+		{"request on port 67", testMAC, dhcpRequest, nil, false, 0, ""},
+		{"unknown mac", "52:54:00:00:00:99", dhcpDiscover, nil, false, 0, ""},
+		{"unsupported arch", testMAC, dhcpDiscover, map[byte][]byte{optClientArch: arch(11)}, false, 0, ""},
+		{"not pxe client", testMAC, dhcpDiscover, map[byte][]byte{optVendorClass: []byte("MSFT 5.0")}, false, 0, ""},
+	}
+
+	from := &net.UDPAddr{IP: net.ParseIP("192.168.1.21"), Port: 68}
+	for _, tt := range tests {
+		req := dhcpRequestPacket(t, tt.mac, tt.msgType, tt.options)
+		reply, dest := s.proxyReply(req, from, tt.bootServer)
+		if tt.wantType == 0 {
+			if reply != nil {
+				t.Errorf("%s: expected no reply, got %s", tt.name, reply.file)
+			}
+			continue
+		}
+		if reply == nil {
+			t.Errorf("%s: expected reply", tt.name)
+			continue
+		}
+
+		parsed, err := parseDHCP(reply.marshal())
+		if err != nil {
+			t.Fatalf("%s: parse reply: %v", tt.name, err)
+		}
+		if parsed.messageType() != tt.wantType || parsed.file != tt.wantFile {
+			t.Errorf("%s: reply type %d file %q, want %d %q", tt.name, parsed.messageType(), parsed.file, tt.wantType, tt.wantFile)
+		}
+		if !parsed.siaddr.Equal(s.ServerIP) || !bytes.Equal(parsed.options[optServerID], s.ServerIP.To4()) {
+			t.Errorf("%s: siaddr %s server id %v", tt.name, parsed.siaddr, parsed.options[optServerID])
+		}
+		if string(parsed.options[optVendorClass]) != "PXEClient" || !bytes.Equal(parsed.xid, req.xid) {
+			t.Errorf("%s: vendor class %q xid %v", tt.name, parsed.options[optVendorClass], parsed.xid)
+		}
+		wantDest := "255.255.255.255:68"
+		if tt.bootServer {
+			wantDest = from.String()
+		}
+		if dest.String() != wantDest {
+			t.Errorf("%s: dest = %s, want %s", tt.name, dest, wantDest)
+		}
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	s := newTestServer(t)
+	tests := map[string]string{
+		"agent.x86_64.ipxe":  "agent.x86_64.ipxe",
+		"/agent.x86_64.ipxe": "agent.x86_64.ipxe",
+		BIOSBootfile:         BIOSBootfile,
+		EFIBootfile:          "ipxe-x86_64.efi",
+		"../config.toml":     "",
+		"..":                 "",
+		"missing.img":        "",
+	}
+	for name, want := range tests {
+		path, err := s.resolveFile(name)
+		if want == "" {
+			if err == nil {
+				t.Errorf("resolveFile(%q) = %s, expected error", name, path)
+			}
+			continue
+		}
+		if err != nil || filepath.Base(path) != want {
+			t.Errorf("resolveFile(%q) = %s, %v, want %s", name, path, err, want)
+		}
+	}
+}
+
+// tftpGet downloads name from the server at addr, optionally negotiating blksize and tsize.
+func tftpGet(t *testing.T, addr net.Addr, name string, options ...string) ([]byte, error) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := binary.BigEndian.AppendUint16(nil, tftpRRQ)
+	req = appendOption(req, name, "octet")
+	for i := 0; i+1 < len(options); i += 2 {
+		req = appendOption(req, options[i], options[i+1])
+	}
+	if _, err := conn.WriteTo(req, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	var data []byte
+	blksize := tftpDefaultBlksize
+	buf := make([]byte, 70000)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, err
+		}
+		ack := func(block uint16) {
+			conn.WriteTo(binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(nil, tftpACK), block), from)
+		}
+		switch binary.BigEndian.Uint16(buf[:2]) {
+		case tftpOACK:
+			fields := bytes.Split(buf[2:n-1], []byte{0})
+			for i := 0; i+1 < len(fields); i += 2 {
+				if string(fields[i]) == "blksize" {
+					blksize = 0
+					for _, c := range fields[i+1] {
+						blksize = blksize*10 + int(c-'0')
+					}
+				}
+			}
+			ack(0)
+		case tftpDATA:
+			data = append(data, buf[4:n]...)
+			ack(binary.BigEndian.Uint16(buf[2:4]))
+			if n-4 < blksize {
+				return data, nil
+			}
+		case tftpERROR:
+			return nil, &net.OpError{Op: "tftp", Err: os.ErrNotExist}
+		}
+	}
+}
+
+func TestServeTFTP(t *testing.T) {
+	s := newTestServer(t)
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go s.serveTFTP(conn)
+
+	initrd, err := os.ReadFile(filepath.Join(s.FilesDir, "agent.x86_64-initrd.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := tftpGet(t, conn.LocalAddr(), "agent.x86_64-initrd.img")
+	if err != nil || !bytes.Equal(data, initrd) {
+		t.Errorf("default blksize: got %d bytes, err %v, want %d bytes", len(data), err, len(initrd))
+	}
+	data, err = tftpGet(t, conn.LocalAddr(), "agent.x86_64-initrd.img", "blksize", "1024", "tsize", "0")
+	if err != nil || !bytes.Equal(data, initrd) {
+		t.Errorf("blksize 1024: got %d bytes, err %v, want %d bytes", len(data), err, len(initrd))
+	}
+	data, err = tftpGet(t, conn.LocalAddr(), "agent.x86_64-vmlinuz-empty")
+	if err != nil || len(data) != 0 {
+		t.Errorf("empty file: got %d bytes, err %v", len(data), err)
+	}
+	if _, err := tftpGet(t, conn.LocalAddr(), "../config.toml"); err == nil {
+		t.Error("expected error for file outside the served directories")
+	}
+}
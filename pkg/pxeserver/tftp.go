@@ -0,0 +1,204 @@
+package pxeserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TFTP 操作码、错误码 (RFC 1350) 和选项限制 (RFC 2347、RFC 2348)
+const (
+	tftpRRQ   = 1
+	tftpWRQ   = 2
+	tftpDATA  = 3
+	tftpACK   = 4
+	tftpERROR = 5
+	tftpOACK  = 6
+
+	tftpErrNotFound     = 1
+	tftpErrAccess       = 2
+	tftpErrIllegal      = 4
+	tftpDefaultBlksize  = 512
+	tftpMinBlksize      = 8
+	tftpMaxBlksize      = 65464
+	tftpRetries         = 5
+	tftpMaxRequestBytes = 1024
+)
+
+// tftpTimeout 等待 ACK 的时间，超时后重传，测试时缩短
+var tftpTimeout = 2 * time.Second
+
+// tftpRequest 解析后的读请求
+type tftpRequest struct {
+	filename string
+	options  map[string]string
+}
+
+func parseTFTPRequest(b []byte) (*tftpRequest, error) {
+	if len(b) < 4 {
+		return nil, errors.New("request too short")
+	}
+	fields := strings.Split(string(b[2:]), "\x00")
+	// 依次为文件名、传输模式和选项名/值对，结尾的 NUL 产生一个空字段
+	if len(fields) < 3 || fields[0] == "" {
+		return nil, errors.New("malformed request")
+	}
+	req := &tftpRequest{filename: fields[0], options: make(map[string]string)}
+	for i := 2; i+1 < len(fields); i += 2 {
+		req.options[strings.ToLower(fields[i])] = fields[i+1]
+	}
+	return req, nil
+}
+
+// serveTFTP 应答 conn 上的读请求直到连接关闭，按 RFC 1350 的要求每个传输使用单独的临时端口
+func (s *Server) serveTFTP(conn net.PacketConn) error {
+	buf := make([]byte, tftpMaxRequestBytes)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if n < 2 {
+			continue
+		}
+		packet := append([]byte(nil), buf[:n]...)
+		switch binary.BigEndian.Uint16(packet[:2]) {
+		case tftpRRQ:
+			go s.handleRead(packet, addr)
+		case tftpWRQ:
+			sendTFTPError(conn, addr, tftpErrAccess, "write not supported")
+		}
+	}
+}
+
+func (s *Server) handleRead(packet []byte, addr net.Addr) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		s.logf("❌ TFTP 创建传输端口失败: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	req, err := parseTFTPRequest(packet)
+	if err != nil {
+		sendTFTPError(conn, addr, tftpErrIllegal, err.Error())
+		return
+	}
+	path, err := s.resolveFile(req.filename)
+	if err != nil {
+		s.logf("⚠️  TFTP %s 请求 %s: %v", addr, req.filename, err)
+		sendTFTPError(conn, addr, tftpErrNotFound, "file not found")
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		sendTFTPError(conn, addr, tftpErrNotFound, "file not found")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		sendTFTPError(conn, addr, tftpErrNotFound, "file not found")
+		return
+	}
+
+	s.logf("📤 TFTP %s 下载 %s (%d 字节)", addr, req.filename, info.Size())
+	if err := transfer(conn, addr, f, info.Size(), req.options); err != nil {
+		s.logf("❌ TFTP 向 %s 发送 %s 失败: %v", addr, req.filename, err)
+	}
+}
+
+// transfer 分块发送文件，客户端请求时协商 blksize 和 tsize
+func transfer(conn net.PacketConn, addr net.Addr, r io.Reader, size int64, options map[string]string) error {
+	blksize := tftpDefaultBlksize
+	oack := []byte{0, tftpOACK}
+	if v, ok := options["blksize"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			blksize = min(max(n, tftpMinBlksize), tftpMaxBlksize)
+			oack = appendOption(oack, "blksize", strconv.Itoa(blksize))
+		}
+	}
+	if _, ok := options["tsize"]; ok {
+		oack = appendOption(oack, "tsize", strconv.FormatInt(size, 10))
+	}
+	if len(oack) > 2 {
+		if err := sendAndWait(conn, addr, oack, 0); err != nil {
+			return err
+		}
+	}
+
+	data := make([]byte, 4+blksize)
+	binary.BigEndian.PutUint16(data[:2], tftpDATA)
+	for block := uint16(1); ; block++ {
+		n, err := io.ReadFull(r, data[4:])
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		binary.BigEndian.PutUint16(data[2:4], block)
+		if err := sendAndWait(conn, addr, data[:4+n], block); err != nil {
+			return err
+		}
+		// 不满一块表示传输结束
+		if n < blksize {
+			return nil
+		}
+	}
+}
+
+// sendAndWait 发送 packet 并等待 block 的 ACK，超时后重传
+func sendAndWait(conn net.PacketConn, addr net.Addr, packet []byte, block uint16) error {
+	buf := make([]byte, tftpMaxRequestBytes)
+	for attempt := 0; attempt < tftpRetries; attempt++ {
+		if _, err := conn.WriteTo(packet, addr); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(tftpTimeout)
+		for {
+			conn.SetReadDeadline(deadline)
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					break
+				}
+				return err
+			}
+			if from.String() != addr.String() || n < 4 {
+				continue
+			}
+			switch binary.BigEndian.Uint16(buf[:2]) {
+			case tftpACK:
+				if binary.BigEndian.Uint16(buf[2:4]) == block {
+					return nil
+				}
+			case tftpERROR:
+				return fmt.Errorf("client aborted: %s", bytes.TrimRight(buf[4:n], "\x00"))
+			}
+		}
+	}
+	return fmt.Errorf("no ACK for block %d after %d attempts", block, tftpRetries)
+}
+
+func appendOption(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	b = append(b, 0)
+	b = append(b, value...)
+	return append(b, 0)
+}
+
+func sendTFTPError(conn net.PacketConn, addr net.Addr, code uint16, message string) {
+	packet := make([]byte, 4, 5+len(message))
+	binary.BigEndian.PutUint16(packet[:2], tftpERROR)
+	binary.BigEndian.PutUint16(packet[2:4], code)
+	packet = append(packet, message...)
+	conn.WriteTo(append(packet, 0), addr)
+}
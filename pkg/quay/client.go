@@ -0,0 +1,226 @@
+package quay
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/trustbundle"
+)
+
+// requestTimeout 单个 API 请求的超时时间
+const requestTimeout = 30 * time.Second
+
+// Client Quay API v1 客户端
+type Client struct {
+	BaseURL  string // 如 https://registry.demo.example.com:8443
+	Token    string // OAuth 访问令牌，为空时使用 Username 和 Password
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewClient 创建访问集群 mirror-registry 的客户端，与 registry.ForCluster 相同使用集群目录中的私有仓库 CA
+// 和 trust_bundle_paths 校验证书。请求中带有 API 令牌和仓库密码，集群目录中没有私有仓库 CA 时返回错误而不是跳过校验
+func NewClient(cfg *config.ClusterConfig, clusterDir string) (*Client, error) {
+	bundle, err := trustbundle.Load(cfg, clusterDir)
+	if err != nil {
+		return nil, clierr.New(clierr.Config, err)
+	}
+	if bundle.Empty() {
+		return nil, clierr.New(clierr.Prereq, fmt.Errorf("集群目录中没有私有仓库的 CA 证书 (%s)\n💡 请先执行 ocpack deploy-registry，或在 [infra] trust_bundle_paths 中配置签发 Registry 证书的 CA",
+			trustbundle.RegistryCAPaths(cfg, clusterDir)[0]))
+	}
+	return &Client{
+		BaseURL:  "https://" + cfg.GetRegistryHost(),
+		Token:    cfg.Registry.Quay.APIToken,
+		Username: cfg.Registry.RegistryUser,
+		Password: cfg.GetRegistryPassword(),
+		HTTP: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: bundle.CertPool()},
+			},
+		},
+	}, nil
+}
+
+// APIError Quay API 返回的非预期状态
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Quay API %s %s 返回 %d", e.Method, e.Path, e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// repository Quay 仓库的信息
+type repository struct {
+	IsPublic bool `json:"is_public"`
+}
+
+// quota Quay 组织的配额
+type quota struct {
+	ID         int   `json:"id"`
+	LimitBytes int64 `json:"limit_bytes"`
+}
+
+// OrganizationExists 返回组织是否存在
+func (c *Client) OrganizationExists(org string) (bool, error) {
+	status, err := c.do(http.MethodGet, "/api/v1/organization/"+url.PathEscape(org), nil, nil, http.StatusNotFound)
+	return status == http.StatusOK, err
+}
+
+// CreateOrganization 创建组织
+func (c *Client) CreateOrganization(org string) error {
+	_, err := c.do(http.MethodPost, "/api/v1/organization/", map[string]string{"name": org}, nil)
+	return err
+}
+
+// Repository 返回仓库是否存在以及是否公开，repo 为组织内的路径，可以包含 '/'
+func (c *Client) Repository(org, repo string) (exists, public bool, err error) {
+	var info repository
+	status, err := c.do(http.MethodGet, repositoryPath(org, repo), nil, &info, http.StatusNotFound)
+	if err != nil || status == http.StatusNotFound {
+		return false, false, err
+	}
+	return true, info.IsPublic, nil
+}
+
+// CreateRepository 以指定的可见性创建仓库
+func (c *Client) CreateRepository(org, repo, visibility string) error {
+	body := map[string]string{
+		"namespace":   org,
+		"repository":  repo,
+		"visibility":  visibility,
+		"description": "Created by ocpack",
+		"repo_kind":   "image",
+	}
+	_, err := c.do(http.MethodPost, "/api/v1/repository", body, nil)
+	return err
+}
+
+// SetVisibility 修改仓库的可见性
+func (c *Client) SetVisibility(org, repo, visibility string) error {
+	_, err := c.do(http.MethodPost, repositoryPath(org, repo)+"/changevisibility", map[string]string{"visibility": visibility}, nil)
+	return err
+}
+
+// Quota 返回组织的配额，未设置时 id 为 0。Quay 未启用 FEATURE_QUOTA_MANAGEMENT 时返回错误
+func (c *Client) Quota(org string) (id int, limitBytes int64, err error) {
+	var quotas []quota
+	if _, err := c.do(http.MethodGet, "/api/v1/organization/"+url.PathEscape(org)+"/quota", nil, &quotas); err != nil {
+		return 0, 0, err
+	}
+	if len(quotas) == 0 {
+		return 0, 0, nil
+	}
+	return quotas[0].ID, quotas[0].LimitBytes, nil
+}
+
+// SetQuota 创建或修改组织的配额，id 为 0 时创建
+func (c *Client) SetQuota(org string, id int, limitBytes int64) error {
+	path := "/api/v1/organization/" + url.PathEscape(org) + "/quota"
+	body := map[string]int64{"limit_bytes": limitBytes}
+	if id == 0 {
+		_, err := c.do(http.MethodPost, path, body, nil)
+		return err
+	}
+	_, err := c.do(http.MethodPut, fmt.Sprintf("%s/%d", path, id), body, nil)
+	return err
+}
+
+// repositoryPath 仓库的 API 路径，组织内的路径可以包含 '/'
+func repositoryPath(org, repo string) string {
+	segments := []string{url.PathEscape(org)}
+	for _, segment := range strings.Split(repo, "/") {
+		segments = append(segments, url.PathEscape(segment))
+	}
+	return "/api/v1/repository/" + strings.Join(segments, "/")
+}
+
+// do 发送请求并将 2xx 响应解析到 out (可为 nil)。allowed 中的状态码不视为错误，由调用方根据返回的状态码处理
+func (c *Client) do(method, path string, body, out interface{}, allowed ...int) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, clierr.New(clierr.Network, fmt.Errorf("无法访问 Quay API %s: %w", c.BaseURL, err))
+	}
+	defer resp.Body.Close()
+
+	for _, status := range allowed {
+		if resp.StatusCode == status {
+			return resp.StatusCode, nil
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: errorMessage(resp.Body)}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return resp.StatusCode, clierr.New(clierr.Auth, apiErr)
+		}
+		return resp.StatusCode, apiErr
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("解析 Quay API %s 的响应失败: %w", path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// errorMessage 提取 Quay API 错误响应中的说明
+func errorMessage(body io.Reader) string {
+	var payload struct {
+		Detail           string `json:"detail"`
+		Message          string `json:"message"`
+		ErrorMessage     string `json:"error_message"`
+		ErrorDescription string `json:"error_description"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	if json.Unmarshal(data, &payload) != nil {
+		return strings.TrimSpace(string(data))
+	}
+	for _, msg := range []string{payload.ErrorMessage, payload.Detail, payload.Message, payload.ErrorDescription} {
+		if msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+// Package quay 在 load-image 推送镜像前通过 Quay API 准备 mirror-registry 中的组织和仓库：
+// 创建缺少的组织和仓库、设置仓库可见性和组织配额，避免推送到中途因组织不存在或超出配额返回 403。
+package quay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+)
+
+// Organization 需要在 Quay 中准备的组织
+type Organization struct {
+	Name         string
+	Visibility   string
+	QuotaBytes   int64 // 为 0 时不设置配额
+	Repositories []string
+}
+
+// Plan 按 load-image 推送的仓库和 [[registry.quay.organizations]] 计算需要准备的组织，
+// 同时返回需要提示用户的警告。images 非空时 (--images-file) 只包含这些镜像的仓库
+func Plan(cfg *config.ClusterConfig, images []string) ([]Organization, []string, error) {
+	var (
+		orgs     []Organization
+		index    = make(map[string]int)
+		warnings []string
+	)
+	add := func(name string) *Organization {
+		if i, ok := index[name]; ok {
+			return &orgs[i]
+		}
+		index[name] = len(orgs)
+		orgs = append(orgs, Organization{Name: name})
+		return &orgs[len(orgs)-1]
+	}
+	addRepository := func(org *Organization, repo string) {
+		for _, existing := range org.Repositories {
+			if existing == repo {
+				return
+			}
+		}
+		org.Repositories = append(org.Repositories, repo)
+	}
+
+	for _, path := range cfg.MirroredRepositories(images) {
+		name, repo, ok := strings.Cut(path, "/")
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("仓库 %s 没有组织，Quay 无法接收，请设置 [save_image] target_namespace", path))
+			continue
+		}
+		addRepository(add(name), repo)
+	}
+	if len(images) == 0 && cfg.OperatorsEnabled() && len(cfg.GetOperatorCatalogs()) > 0 && cfg.GetMirrorNamespace() == "" {
+		warnings = append(warnings, "未设置 [save_image] target_namespace，Operator 镜像按原始路径推送到多个组织 (如 rhel9、openshift4)，"+
+			"这些组织需要在 [[registry.quay.organizations]] 中列出，或设置 target_namespace 使全部镜像推送到同一组织")
+	}
+
+	for _, configured := range cfg.Registry.Quay.Organizations {
+		org := add(configured.Name)
+		for _, repo := range configured.Repositories {
+			addRepository(org, strings.Trim(repo, "/"))
+		}
+	}
+	for i := range orgs {
+		configured := config.QuayOrganization{Name: orgs[i].Name}
+		for _, o := range cfg.Registry.Quay.Organizations {
+			if o.Name == orgs[i].Name {
+				configured = o
+			}
+		}
+		orgs[i].Visibility = cfg.GetQuayVisibility(configured)
+		quota, err := cfg.GetQuayQuota(configured)
+		if err != nil {
+			return nil, nil, err
+		}
+		orgs[i].QuotaBytes = quota
+	}
+	return orgs, warnings, nil
+}
+
+// Ensure 创建 Plan 返回的组织和仓库，并设置仓库可见性和组织配额。已存在且设置一致的组织和仓库不做修改。
+// clusterDir 为集群目录，访问 Quay API 时使用其中的私有仓库 CA 校验证书
+func Ensure(cfg *config.ClusterConfig, clusterDir string, images []string) error {
+	return EnsureTo(os.Stdout, cfg, clusterDir, images)
+}
+
+// EnsureTo 与 Ensure 相同，创建和修改的结果写入 out
+func EnsureTo(out io.Writer, cfg *config.ClusterConfig, clusterDir string, images []string) error {
+	if err := config.ValidateRegistryQuay(cfg); err != nil {
+		return clierr.New(clierr.Config, err)
+	}
+	orgs, warnings, err := Plan(cfg, images)
+	if err != nil {
+		return clierr.New(clierr.Config, err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "⚠️  %s\n", warning)
+	}
+	client, err := NewClient(cfg, clusterDir)
+	if err != nil {
+		return err
+	}
+	return ensure(out, client, orgs)
+}
+
+func ensure(out io.Writer, client *Client, orgs []Organization) error {
+	for _, org := range orgs {
+		if err := ensureOrganization(out, client, org); err != nil {
+			return withHint(err)
+		}
+	}
+	return nil
+}
+
+func ensureOrganization(out io.Writer, client *Client, org Organization) error {
+	exists, err := client.OrganizationExists(org.Name)
+	if err != nil {
+		return fmt.Errorf("查询组织 %s 失败: %w", org.Name, err)
+	}
+	if !exists {
+		if err := client.CreateOrganization(org.Name); err != nil {
+			return fmt.Errorf("创建组织 %s 失败: %w", org.Name, err)
+		}
+		fmt.Fprintf(out, "➕ 已创建组织 %s\n", org.Name)
+	} else {
+		fmt.Fprintf(out, "✅ 组织 %s 已存在\n", org.Name)
+	}
+
+	if org.QuotaBytes > 0 {
+		id, limit, err := client.Quota(org.Name)
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("设置组织 %s 的配额失败: %w\n💡 Quay 未启用配额管理，请在 config.yaml 中设置 FEATURE_QUOTA_MANAGEMENT: true，或删除 [registry.quay] quota", org.Name, err)
+			}
+			return fmt.Errorf("查询组织 %s 的配额失败: %w", org.Name, err)
+		}
+		if limit != org.QuotaBytes {
+			if err := client.SetQuota(org.Name, id, org.QuotaBytes); err != nil {
+				return fmt.Errorf("设置组织 %s 的配额失败: %w", org.Name, err)
+			}
+			fmt.Fprintf(out, "📏 组织 %s 的配额设置为 %s\n", org.Name, formatBytes(org.QuotaBytes))
+		}
+	}
+
+	public := org.Visibility == config.QuayVisibilityPublic
+	for _, repo := range org.Repositories {
+		exists, isPublic, err := client.Repository(org.Name, repo)
+		if err != nil {
+			return fmt.Errorf("查询仓库 %s/%s 失败: %w", org.Name, repo, err)
+		}
+		switch {
+		case !exists:
+			if err := client.CreateRepository(org.Name, repo, org.Visibility); err != nil {
+				return fmt.Errorf("创建仓库 %s/%s 失败: %w", org.Name, repo, err)
+			}
+			fmt.Fprintf(out, "➕ 已创建仓库 %s/%s (%s)\n", org.Name, repo, org.Visibility)
+		case isPublic != public:
+			if err := client.SetVisibility(org.Name, repo, org.Visibility); err != nil {
+				return fmt.Errorf("修改仓库 %s/%s 的可见性失败: %w", org.Name, repo, err)
+			}
+			fmt.Fprintf(out, "🔁 仓库 %s/%s 的可见性修改为 %s\n", org.Name, repo, org.Visibility)
+		}
+	}
+	return nil
+}
+
+// withHint 为认证失败补充修复建议
+func withHint(err error) error {
+	if clierr.CategoryOf(err) != clierr.Auth {
+		return err
+	}
+	return clierr.New(clierr.Auth, fmt.Errorf("%w\n💡 创建组织和设置配额需要 Quay 超级用户权限：请确认 registry_user 是超级用户，"+
+		"或在 [registry.quay] api_token 中设置具有 super:user、org:admin 和 repo:create 权限的 OAuth 访问令牌", err))
+}
+
+// formatBytes 以二进制单位输出字节数
+func formatBytes(n int64) string {
+	units := []string{"Ki", "Mi", "Gi", "Ti"}
+	unit := ""
+	value := float64(n)
+	for _, u := range units {
+		if value < 1024 {
+			break
+		}
+		value /= 1024
+		unit = u
+	}
+	if unit == "" {
+		return fmt.Sprintf("%d B", n)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(fmt.Sprintf("%.1f", value), "0"), ".") + unit
+}
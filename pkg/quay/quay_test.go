@@ -0,0 +1,277 @@
+package quay
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/trustbundle"
+)
+
+// fakeQuay 记录组织、仓库和配额的简化 Quay API
+type fakeQuay struct {
+	mu        sync.Mutex
+	orgs      map[string]bool
+	repos     map[string]bool // org/repo -> is_public
+	quotas    map[string]int64
+	noQuota   bool
+	forbidden bool
+	requests  []string
+}
+
+func newFakeQuay() *fakeQuay {
+	return &fakeQuay{orgs: map[string]bool{}, repos: map[string]bool{}, quotas: map[string]int64{}}
+}
+
+func (f *fakeQuay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if f.forbidden {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error_message": "Unauthorized"})
+		return
+	}
+
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	switch {
+	case r.Method == http.MethodPost && path == "organization/":
+		f.orgs[body["name"].(string)] = true
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPost && path == "repository":
+		f.repos[body["namespace"].(string)+"/"+body["repository"].(string)] = body["visibility"] == "public"
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "organization/") && strings.Contains(path, "/quota"):
+		org := strings.Split(path, "/")[1]
+		if f.noQuota {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			quotas := []quota{}
+			if limit, ok := f.quotas[org]; ok {
+				quotas = append(quotas, quota{ID: 7, LimitBytes: limit})
+			}
+			json.NewEncoder(w).Encode(quotas)
+		default:
+			f.quotas[org] = int64(body["limit_bytes"].(float64))
+			w.WriteHeader(http.StatusCreated)
+		}
+	case strings.HasPrefix(path, "organization/"):
+		if !f.orgs[strings.TrimPrefix(path, "organization/")] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{})
+	case strings.HasSuffix(path, "/changevisibility"):
+		repo := strings.TrimSuffix(strings.TrimPrefix(path, "repository/"), "/changevisibility")
+		f.repos[repo] = body["visibility"] == "public"
+	case strings.HasPrefix(path, "repository/"):
+		public, ok := f.repos[strings.TrimPrefix(path, "repository/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(repository{IsPublic: public})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestClient(t *testing.T, f *fakeQuay) *Client {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return &Client{BaseURL: server.URL, Username: "ocp4", Password: "secret", HTTP: server.Client()}
+}
+
+func TestPlan(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.TargetNamespace = "redhat-mirror"
+	cfg.SaveImage.AdditionalImages = []string{"quay.io/acme/app:v1"}
+	cfg.Registry.Quay.Visibility = config.QuayVisibilityPublic
+	cfg.Registry.Quay.Quota = "500Gi"
+	cfg.Registry.Quay.Organizations = []config.QuayOrganization{
+		{Name: "redhat-mirror", Quota: "2Ti"},
+		{Name: "apps", Visibility: config.QuayVisibilityPrivate, Repositories: []string{"team/api"}},
+	}
+
+	orgs, warnings, err := Plan(cfg, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Plan() warnings = %v", warnings)
+	}
+	want := []Organization{
+		{Name: "redhat-mirror", Visibility: "public", QuotaBytes: 2 << 40, Repositories: []string{
+			"openshift/release-images", "openshift/release", "acme/app",
+		}},
+		{Name: "apps", Visibility: "private", QuotaBytes: 500 << 30, Repositories: []string{"team/api"}},
+	}
+	if !reflect.DeepEqual(orgs, want) {
+		t.Errorf("Plan() = %+v, want %+v", orgs, want)
+	}
+}
+
+func TestPlanWarnings(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.IncludeOperators = true
+	cfg.SaveImage.OperatorCatalogs = []config.OperatorCatalog{{Catalog: "redhat", Ops: []string{"cluster-logging"}}}
+
+	orgs, warnings, err := Plan(cfg, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "target_namespace") {
+		t.Errorf("Plan() warnings = %v, want target_namespace warning", warnings)
+	}
+	var names []string
+	for _, org := range orgs {
+		names = append(names, org.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"openshift", "redhat"}) {
+		t.Errorf("Plan() organizations = %v", names)
+	}
+
+	// --images-file 只准备列表中镜像的组织，单段仓库无法推送到 Quay
+	orgs, warnings, err = Plan(cfg, []string{"quay.io/acme/app:v1", "registry.example.com/tool:v1"})
+	if err != nil {
+		t.Fatalf("Plan(images) error = %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].Name != "acme" || len(warnings) != 1 || !strings.Contains(warnings[0], "tool") {
+		t.Errorf("Plan(images) = %+v, warnings %v", orgs, warnings)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	f := newFakeQuay()
+	f.orgs["mirror"] = true
+	f.repos["mirror/openshift/release"] = false
+	f.quotas["mirror"] = 100 << 30
+	client := newTestClient(t, f)
+
+	orgs := []Organization{
+		{Name: "mirror", Visibility: "public", QuotaBytes: 500 << 30, Repositories: []string{"openshift/release-images", "openshift/release"}},
+		{Name: "apps", Visibility: "private", Repositories: []string{"team/api"}},
+	}
+	if err := ensure(io.Discard, client, orgs); err != nil {
+		t.Fatalf("ensure() error = %v", err)
+	}
+
+	if !f.orgs["apps"] {
+		t.Error("organization apps not created")
+	}
+	if f.quotas["mirror"] != 500<<30 {
+		t.Errorf("quota = %d, want 500Gi", f.quotas["mirror"])
+	}
+	if _, ok := f.quotas["apps"]; ok {
+		t.Error("quota set for apps without quota configured")
+	}
+	wantRepos := map[string]bool{
+		"mirror/openshift/release-images": true,
+		"mirror/openshift/release":        true,
+		"apps/team/api":                   false,
+	}
+	if !reflect.DeepEqual(f.repos, wantRepos) {
+		t.Errorf("repositories = %v, want %v", f.repos, wantRepos)
+	}
+
+	// 再次执行时不做修改
+	f.requests = nil
+	if err := ensure(io.Discard, client, orgs); err != nil {
+		t.Fatalf("second ensure() error = %v", err)
+	}
+	for _, req := range f.requests {
+		if !strings.HasPrefix(req, http.MethodGet) {
+			t.Errorf("unexpected change on second run: %s", req)
+		}
+	}
+}
+
+func TestEnsureErrors(t *testing.T) {
+	f := newFakeQuay()
+	f.forbidden = true
+	err := ensure(io.Discard, newTestClient(t, f), []Organization{{Name: "mirror", Visibility: "private"}})
+	if clierr.CategoryOf(err) != clierr.Auth || !strings.Contains(err.Error(), "api_token") {
+		t.Errorf("ensure() forbidden error = %v", err)
+	}
+
+	f = newFakeQuay()
+	f.noQuota = true
+	err = ensure(io.Discard, newTestClient(t, f), []Organization{{Name: "mirror", Visibility: "private", QuotaBytes: 1 << 30}})
+	if err == nil || !strings.Contains(err.Error(), "FEATURE_QUOTA_MANAGEMENT") {
+		t.Errorf("ensure() without quota management error = %v", err)
+	}
+}
+
+func TestClientAuthorization(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Username: "ocp4", Password: "secret", HTTP: server.Client()}
+	client.OrganizationExists("mirror")
+	client.Token = "abc"
+	client.OrganizationExists("mirror")
+
+	want := []string{"Basic b2NwNDpzZWNyZXQ=", "Bearer abc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Authorization = %v, want %v", got, want)
+	}
+}
+
+func TestNewClientVerifiesRegistryCA(t *testing.T) {
+	f := newFakeQuay()
+	f.orgs["mirror"] = true
+	server := httptest.NewTLSServer(f)
+	defer server.Close()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.IP = "192.168.1.3"
+	clusterDir := t.TempDir()
+
+	if _, err := NewClient(cfg, clusterDir); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Fatalf("NewClient() without registry CA error = %v", err)
+	}
+
+	// 使用 rootCA.pem 校验服务器证书
+	caPath := trustbundle.RegistryCAPaths(cfg, clusterDir)[0]
+	if err := os.MkdirAll(filepath.Dir(caPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(cfg, clusterDir)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.BaseURL = server.URL
+	if exists, err := client.OrganizationExists("mirror"); err != nil || !exists {
+		t.Errorf("OrganizationExists() = %v, %v", exists, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{500 << 30: "500Gi", 2 << 40: "2Ti", 1536 << 20: "1.5Gi", 512: "512 B"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Package registry 通过 Docker Registry HTTP API V2 访问集群的私有仓库：检查仓库状态和认证、
+// 列出仓库和标签、查询镜像清单。仓库返回 Bearer 认证要求时按令牌认证流程使用配置中的仓库用户和密码
+// 获取令牌，返回 Basic 认证要求时直接使用用户和密码，不依赖 podman login 或 docker 的认证文件。
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/trustbundle"
+)
+
+// requestTimeout 单个请求的超时时间
+const requestTimeout = 30 * time.Second
+
+// ManifestMediaTypes 查询清单时接受的类型，镜像可能是单架构清单或多架构索引
+var ManifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Client 私有仓库的 Registry V2 API 客户端
+type Client struct {
+	Host     string // 仓库主机和端口，如 registry.demo.example.com:8443
+	Username string
+	Password string
+	HTTP     *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]string // scope -> Bearer 令牌
+}
+
+// NewClient 创建访问集群私有仓库的客户端，使用 rootCAs 校验 TLS 证书，rootCAs 为 nil 时使用系统信任的 CA
+func NewClient(cfg *config.ClusterConfig, rootCAs *x509.CertPool) *Client {
+	return newClient(cfg, &tls.Config{RootCAs: rootCAs})
+}
+
+// NewInsecureClient 创建不校验 TLS 证书的客户端，只用于部署 Registry 前探测健康检查接口：
+// 此时本机还没有 mirror-registry 的自签名 CA，且请求中不带用户和密码
+func NewInsecureClient(cfg *config.ClusterConfig) *Client {
+	return newClient(cfg, &tls.Config{InsecureSkipVerify: true})
+}
+
+func newClient(cfg *config.ClusterConfig, tlsConfig *tls.Config) *Client {
+	return &Client{
+		Host:     cfg.GetRegistryHost(),
+		Username: cfg.Registry.RegistryUser,
+		Password: cfg.GetRegistryPassword(),
+		HTTP: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+	}
+}
+
+// ForCluster 创建使用集群目录中私有仓库 CA 和 trust_bundle_paths 校验证书的客户端 (见 TLSConfig)
+func ForCluster(cfg *config.ClusterConfig, clusterDir string) (*Client, error) {
+	tlsConfig, err := TLSConfig(cfg, clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(cfg, tlsConfig), nil
+}
+
+// TLSConfig 返回访问 Registry 节点上的服务 (私有仓库、拉取代理) 的 TLS 配置：使用集群目录中的私有仓库 CA
+// 和 trust_bundle_paths 校验证书，集群目录中还没有私有仓库 CA 时只信任系统的 CA。
+// 只有 [registry] skip_tls_verify = true 时不校验证书
+func TLSConfig(cfg *config.ClusterConfig, clusterDir string) (*tls.Config, error) {
+	if cfg.Registry.SkipTLSVerify {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	bundle, err := trustbundle.Load(cfg, clusterDir)
+	if err != nil {
+		return nil, clierr.New(clierr.Config, err)
+	}
+	if bundle.Empty() {
+		return &tls.Config{}, nil
+	}
+	return &tls.Config{RootCAs: bundle.CertPool()}, nil
+}
+
+// StatusError 仓库返回的非预期状态
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s 返回 %s", e.Method, e.URL, e.Status)
+}
+
+// Manifest 镜像清单
+type Manifest struct {
+	MediaType string
+	Digest    string
+	Body      []byte
+}
+
+// Ping 访问 /v2/，确认仓库可以访问且用户和密码有效
+func (c *Client) Ping() error {
+	resp, err := c.do(http.MethodGet, "/v2/", "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Health 访问 Quay 的健康检查接口 /health/instance
+func (c *Client) Health() error {
+	req, err := http.NewRequest(http.MethodGet, c.url("/health/instance"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return clierr.New(clierr.Network, fmt.Errorf("无法访问私有仓库 %s: %w", c.Host, err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(req.Method, req.URL.String(), resp)
+	}
+	return nil
+}
+
+// Catalog 返回用户可以访问的全部仓库
+func (c *Client) Catalog() ([]string, error) {
+	var repositories []string
+	err := c.paginate("/v2/_catalog?n=100", "registry:catalog:*", func(body io.Reader) error {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return fmt.Errorf("解析仓库列表失败: %w", err)
+		}
+		repositories = append(repositories, page.Repositories...)
+		return nil
+	})
+	return repositories, err
+}
+
+// Tags 返回仓库中的全部标签
+func (c *Client) Tags(repository string) ([]string, error) {
+	var tags []string
+	err := c.paginate("/v2/"+repository+"/tags/list?n=100", pullScope(repository), func(body io.Reader) error {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return fmt.Errorf("解析 %s 的标签列表失败: %w", repository, err)
+		}
+		tags = append(tags, page.Tags...)
+		return nil
+	})
+	return tags, err
+}
+
+// ManifestExists 通过 HEAD 清单确认镜像是否存在，reference 为标签或摘要
+func (c *Client) ManifestExists(repository, reference string) (bool, error) {
+	resp, err := c.do(http.MethodHead, manifestPath(repository, reference), pullScope(repository), http.StatusNotFound)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// GetManifest 读取镜像清单，reference 为标签或摘要
+func (c *Client) GetManifest(repository, reference string) (*Manifest, error) {
+	resp, err := c.do(http.MethodGet, manifestPath(repository, reference), pullScope(repository))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s:%s 的清单失败: %w", repository, reference, err)
+	}
+	return &Manifest{
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    resp.Header.Get("Docker-Content-Digest"),
+		Body:      body,
+	}, nil
+}
+
+func manifestPath(repository, reference string) string {
+	return "/v2/" + repository + "/manifests/" + reference
+}
+
+func pullScope(repository string) string {
+	return "repository:" + repository + ":pull"
+}
+
+func (c *Client) url(path string) string {
+	return "https://" + c.Host + path
+}
+
+var nextLink = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// paginate 按 Link 响应头依次读取分页结果
+func (c *Client) paginate(path, scope string, handle func(io.Reader) error) error {
+	for path != "" {
+		resp, err := c.do(http.MethodGet, path, scope)
+		if err != nil {
+			return err
+		}
+		err = handle(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		path = ""
+		if match := nextLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+			next, err := neturl.Parse(match[1])
+			if err != nil {
+				return fmt.Errorf("无法解析分页链接 %s: %w", match[1], err)
+			}
+			path = next.RequestURI()
+		}
+	}
+	return nil
+}
+
+// do 发送请求，仓库要求认证时取得 scope 的令牌或使用 Basic 认证后重试。
+// 返回 2xx 或 allowed 中状态码的响应，其他状态返回 StatusError，401 和 403 归类为认证错误
+func (c *Client) do(method, path, scope string, allowed ...int) (*http.Response, error) {
+	authorization := c.cachedToken(scope)
+	resp, err := c.send(method, path, authorization)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if authorization, err = c.authorize(challenge, scope); err != nil {
+			return nil, err
+		}
+		if resp, err = c.send(method, path, authorization); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	for _, status := range allowed {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return nil, statusError(method, c.url(path), resp)
+}
+
+func (c *Client) send(method, path, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, mediaType := range ManifestMediaTypes {
+		req.Header.Add("Accept", mediaType)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, clierr.New(clierr.Network, fmt.Errorf("无法访问私有仓库 %s: %w", c.Host, err))
+	}
+	return resp, nil
+}
+
+func statusError(method, url string, resp *http.Response) error {
+	err := &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Status: resp.Status}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return clierr.New(clierr.Auth, err)
+	}
+	return err
+}
+
+func (c *Client) cachedToken(scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token, ok := c.tokens[scope]; ok {
+		return "Bearer " + token
+	}
+	return ""
+}
+
+// authorize 根据认证要求返回 Authorization 请求头。Bearer 认证的令牌按 scope 缓存
+func (c *Client) authorize(challenge, scope string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		credentials := c.Username + ":" + c.Password
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+	}
+	token, err := c.fetchToken(challenge, scope)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = make(map[string]string)
+	}
+	c.tokens[scope] = token
+	return "Bearer " + token, nil
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchToken 使用用户和密码从 Bearer 认证要求中的 realm 获取 scope 的令牌
+func (c *Client) fetchToken(challenge, scope string) (string, error) {
+	params := make(map[string]string)
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	realm, err := neturl.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("无法解析认证要求: %s", challenge)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", clierr.New(clierr.Network, fmt.Errorf("获取仓库令牌失败: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(req.Method, params["realm"], resp)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("解析仓库令牌失败: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
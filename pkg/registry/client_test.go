@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+)
+
+// newTestRegistry 模拟使用 Bearer 令牌认证的私有仓库，令牌按 scope 签发
+func newTestRegistry(t *testing.T, tokenRequests *int32) (*httptest.Server, *Client) {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v2/auth", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(tokenRequests, 1)
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "ocp4" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token": "token-%s"}`, r.URL.Query().Get("scope"))
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		scope := ""
+		switch {
+		case r.URL.Path == "/v2/_catalog":
+			scope = "registry:catalog:*"
+		case strings.HasPrefix(r.URL.Path, "/v2/openshift/release-images/"):
+			scope = "repository:openshift/release-images:pull"
+		}
+		if r.Header.Get("Authorization") != "Bearer token-"+scope {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/v2/auth",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/":
+		case "/v2/_catalog":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/_catalog?last=openshift%2Frelease&n=100>; rel="next"`)
+				fmt.Fprint(w, `{"repositories": ["openshift/release"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"repositories": ["openshift/release-images"]}`)
+		case "/v2/openshift/release-images/tags/list":
+			fmt.Fprint(w, `{"name": "openshift/release-images", "tags": ["4.16.3-x86_64"]}`)
+		case "/v2/openshift/release-images/manifests/4.16.3-x86_64":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.list.v2+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+			fmt.Fprint(w, `{"schemaVersion": 2}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/health/instance", func(w http.ResponseWriter, r *http.Request) {})
+	server = httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.RegistryPassword = "secret"
+	client := NewClient(cfg, nil)
+	client.Host = strings.TrimPrefix(server.URL, "https://")
+	client.HTTP = server.Client()
+	return server, client
+}
+
+func TestClient(t *testing.T) {
+	var tokenRequests int32
+	_, client := newTestRegistry(t, &tokenRequests)
+
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := client.Health(); err != nil {
+		t.Errorf("Health() error = %v", err)
+	}
+
+	repositories, err := client.Catalog()
+	if err != nil {
+		t.Fatalf("Catalog() error = %v", err)
+	}
+	if want := []string{"openshift/release", "openshift/release-images"}; !reflect.DeepEqual(repositories, want) {
+		t.Errorf("Catalog() = %v, want %v", repositories, want)
+	}
+
+	tags, err := client.Tags("openshift/release-images")
+	if err != nil || !reflect.DeepEqual(tags, []string{"4.16.3-x86_64"}) {
+		t.Errorf("Tags() = %v, %v", tags, err)
+	}
+
+	exists, err := client.ManifestExists("openshift/release-images", "4.16.3-x86_64")
+	if err != nil || !exists {
+		t.Errorf("ManifestExists() = %v, %v", exists, err)
+	}
+	exists, err = client.ManifestExists("openshift/release-images", "4.15.0-x86_64")
+	if err != nil || exists {
+		t.Errorf("ManifestExists(missing) = %v, %v", exists, err)
+	}
+
+	manifest, err := client.GetManifest("openshift/release-images", "4.16.3-x86_64")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if manifest.Digest != "sha256:abc" || !strings.Contains(manifest.MediaType, "manifest.list") || string(manifest.Body) != `{"schemaVersion": 2}` {
+		t.Errorf("GetManifest() = %+v", manifest)
+	}
+
+	// 每个 scope 只获取一次令牌：/v2/、目录和 release-images 仓库
+	if tokenRequests != 3 {
+		t.Errorf("token requests = %d, want 3", tokenRequests)
+	}
+
+	_, err = client.GetManifest("openshift/release-images", "missing")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetManifest(missing) error = %v", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	var tokenRequests int32
+	_, client := newTestRegistry(t, &tokenRequests)
+	client.Password = "wrong"
+	if err := client.Ping(); clierr.CategoryOf(err) != clierr.Auth {
+		t.Errorf("Ping() with wrong password error = %v, want auth error", err)
+	}
+
+	client.Host = "127.0.0.1:1"
+	if err := client.Health(); clierr.CategoryOf(err) != clierr.Network {
+		t.Errorf("Health() unreachable error = %v, want network error", err)
+	}
+}
+
+func TestClientBasicAuth(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ocp4" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Host: strings.TrimPrefix(server.URL, "https://"), Username: "ocp4", Password: "secret", HTTP: server.Client()}
+	if err := client.Ping(); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func TestForCluster(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	client, err := ForCluster(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("ForCluster() error = %v", err)
+	}
+	if client.Host != cfg.GetRegistryHost() {
+		t.Errorf("Host = %s, want %s", client.Host, cfg.GetRegistryHost())
+	}
+	if client.HTTP.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("client without registry CA should verify TLS certificates with the system CAs")
+	}
+	if !NewInsecureClient(cfg).HTTP.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("NewInsecureClient() should skip TLS verification")
+	}
+
+	cfg.Registry.SkipTLSVerify = true
+	if client, err = ForCluster(cfg, t.TempDir()); err != nil || !client.HTTP.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("ForCluster() with registry.skip_tls_verify should skip TLS verification (error %v)", err)
+	}
+	cfg.Registry.SkipTLSVerify = false
+
+	cfg.Infra.TrustBundlePaths = []string{"missing.pem"}
+	if _, err := ForCluster(cfg, t.TempDir()); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("ForCluster() with missing trust bundle error = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package registrybundle 管理 mirror-registry (Quay) 的离线安装包。save-image 可以将安装包连同校验和
+// 归档到镜像目录，与镜像归档一起保存到 NFS 或对象存储；Registry 主机损坏后，deploy-registry
+// 可以只使用本地文件重新部署，无需再次从互联网下载。
+package registrybundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/utils"
+)
+
+const (
+	// Filename ocpack download 下载的 mirror-registry 离线安装包
+	Filename = "mirror-registry-amd64.tar.gz"
+	// ArchiveDir 镜像目录中归档安装包的子目录
+	ArchiveDir = "mirror-registry"
+	// checksumSuffix 归档安装包的校验和文件后缀
+	checksumSuffix = ".sha256"
+)
+
+// requiredEntries 离线安装包中必须包含的文件：安装程序和 Quay、Redis 等容器镜像
+var requiredEntries = []string{"mirror-registry", "image-archive.tar"}
+
+// DownloadPath 返回下载目录中的安装包路径
+func DownloadPath(downloadDir string) string {
+	return filepath.Join(downloadDir, Filename)
+}
+
+// ArchivePath 返回镜像目录中归档的安装包路径
+func ArchivePath(imagesDir string) string {
+	return filepath.Join(imagesDir, ArchiveDir, Filename)
+}
+
+// Verify 检查安装包是否为包含安装程序和容器镜像的离线安装包
+func Verify(bundlePath string) error {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%s 不是有效的 tar.gz 文件: %w", bundlePath, err)
+	}
+	defer gz.Close()
+
+	found := map[string]bool{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", bundlePath, err)
+		}
+		found[path.Clean(strings.TrimPrefix(header.Name, "./"))] = true
+	}
+	for _, entry := range requiredEntries {
+		if !found[entry] {
+			return fmt.Errorf("%s 中缺少 %s，不是 mirror-registry 离线安装包", bundlePath, entry)
+		}
+	}
+	return nil
+}
+
+// Archive 校验下载目录中的安装包并复制到镜像目录，同时写入 sha256 校验和。
+// 已归档且内容相同时不再复制，返回归档路径和是否发生了复制
+func Archive(downloadDir, imagesDir string) (string, bool, error) {
+	source := DownloadPath(downloadDir)
+	if !utils.FileExists(source) {
+		return "", false, clierr.New(clierr.Prereq, fmt.Errorf("未找到 mirror-registry 安装包: %s\n💡 请先执行 ocpack download", source))
+	}
+	if err := Verify(source); err != nil {
+		return "", false, clierr.New(clierr.Prereq, err)
+	}
+	sum, err := checksum(source)
+	if err != nil {
+		return "", false, err
+	}
+
+	target := ArchivePath(imagesDir)
+	if recorded, err := os.ReadFile(target + checksumSuffix); err == nil && strings.TrimSpace(string(recorded)) == sum {
+		if current, err := checksum(target); err == nil && current == sum {
+			return target, false, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", false, fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := utils.CopyFile(source, target); err != nil {
+		return "", false, fmt.Errorf("归档 mirror-registry 安装包失败: %w", err)
+	}
+	if err := os.WriteFile(target+checksumSuffix, []byte(sum+"\n"), 0644); err != nil {
+		return "", false, fmt.Errorf("写入校验和失败: %w", err)
+	}
+	return target, true, nil
+}
+
+// Find 返回部署 Registry 使用的安装包：优先使用下载目录中的安装包，不存在时使用 save-image 归档的安装包
+// (校验和必须一致)。两者都不可用时返回 Prereq 错误
+func Find(downloadDir, imagesDir string) (string, error) {
+	source := DownloadPath(downloadDir)
+	if utils.FileExists(source) {
+		if err := Verify(source); err != nil {
+			return "", clierr.New(clierr.Prereq, err)
+		}
+		return source, nil
+	}
+
+	archived := ArchivePath(imagesDir)
+	if !utils.FileExists(archived) {
+		return "", clierr.New(clierr.Prereq, fmt.Errorf("未找到 mirror-registry 安装包: %s 和 %s 都不存在\n💡 请执行 ocpack download，或在联网环境中设置 [save_image] mirror_registry = true 后执行 save-image", source, archived))
+	}
+	recorded, err := os.ReadFile(archived + checksumSuffix)
+	if err != nil {
+		return "", clierr.New(clierr.Prereq, fmt.Errorf("读取 %s 的校验和失败: %w", archived, err))
+	}
+	sum, err := checksum(archived)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(recorded)) != sum {
+		return "", clierr.New(clierr.Prereq, fmt.Errorf("%s 的校验和不一致，文件可能已损坏，请重新执行 save-image", archived))
+	}
+	return archived, nil
+}
+
+// checksum 计算文件的 sha256
+func checksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("计算 %s 的校验和失败: %w", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
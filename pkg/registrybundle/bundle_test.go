@@ -0,0 +1,105 @@
+package registrybundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ocpack/pkg/clierr"
+)
+
+// writeBundle 生成包含指定文件的 tar.gz
+func writeBundle(t *testing.T, path string, entries ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range entries {
+		content := []byte("content of " + name)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.tar.gz")
+	writeBundle(t, valid, "./mirror-registry", "image-archive.tar", "execution-environment.tar")
+	if err := Verify(valid); err != nil {
+		t.Errorf("Verify(valid): %v", err)
+	}
+
+	online := filepath.Join(dir, "online.tar.gz")
+	writeBundle(t, online, "mirror-registry")
+	if err := Verify(online); err == nil {
+		t.Error("expected error for bundle without image archive")
+	}
+
+	plain := filepath.Join(dir, "plain.tar.gz")
+	if err := os.WriteFile(plain, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(plain); err == nil {
+		t.Error("expected error for invalid gzip")
+	}
+}
+
+func TestArchiveAndFind(t *testing.T) {
+	downloadDir := filepath.Join(t.TempDir(), "downloads")
+	imagesDir := filepath.Join(t.TempDir(), "images")
+
+	if _, _, err := Archive(downloadDir, imagesDir); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("expected prereq error without download, got %v", err)
+	}
+	if _, err := Find(downloadDir, imagesDir); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("expected prereq error without any bundle, got %v", err)
+	}
+
+	writeBundle(t, DownloadPath(downloadDir), "mirror-registry", "image-archive.tar")
+	archived, copied, err := Archive(downloadDir, imagesDir)
+	if err != nil || !copied || archived != ArchivePath(imagesDir) {
+		t.Fatalf("Archive = %s, %t, %v", archived, copied, err)
+	}
+	if _, copied, err := Archive(downloadDir, imagesDir); err != nil || copied {
+		t.Errorf("expected unchanged bundle to be skipped, copied = %t, err = %v", copied, err)
+	}
+
+	// 下载目录中的安装包优先
+	if found, err := Find(downloadDir, imagesDir); err != nil || found != DownloadPath(downloadDir) {
+		t.Errorf("Find = %s, %v", found, err)
+	}
+
+	// Registry 重建时下载目录不可用，使用归档的安装包
+	if err := os.Remove(DownloadPath(downloadDir)); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := Find(downloadDir, imagesDir); err != nil || found != archived {
+		t.Errorf("Find = %s, %v", found, err)
+	}
+
+	if err := os.WriteFile(archived, []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Find(downloadDir, imagesDir); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("expected checksum mismatch, got %v", err)
+	}
+}
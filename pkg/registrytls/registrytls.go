@@ -0,0 +1,210 @@
+// Package registrytls 生成复制镜像时的 TLS 配置。全部仓库默认都校验证书，信任系统 CA 和
+// trustbundle.LoadMirrorBundle 中的证书 (私有仓库 CA、trust_bundle_paths、ca_bundle 和 registry_tls 的 ca_file)；
+// save_image.registry_tls 中 insecure = true 的仓库在系统 registries.conf 的基础上标记为 insecure，只有这些仓库不校验证书。
+package registrytls
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/trustbundle"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// RegistriesConfEnv containers/image (oc-mirror、skopeo) 读取的 registries.conf 路径的环境变量
+	RegistriesConfEnv = "CONTAINERS_REGISTRIES_CONF"
+	// registriesConfFilename 生成的 registries.conf 在集群目录中的位置
+	registriesConfFilename = "registry/registries.conf"
+	// sslCertDirEnv Go 程序 (oc) 在系统 CA 之外额外读取的证书目录
+	sslCertDirEnv = "SSL_CERT_DIR"
+)
+
+// systemCertDirs Go 默认读取的证书目录，设置 SSL_CERT_DIR 后不再读取，因此一并保留
+var systemCertDirs = []string{"/etc/ssl/certs", "/etc/pki/tls/certs"}
+
+// systemRegistriesConf 系统的 registries.conf，生成的文件保留其中的镜像源、blocked 仓库和短名称配置，测试时可替换
+var systemRegistriesConf = "/etc/containers/registries.conf"
+
+// Policy 复制镜像时的 TLS 配置
+type Policy struct {
+	CertDir        string   // 额外信任的 CA 证书目录 (*.crt)，没有额外的 CA 时为空
+	RegistriesConf string   // 合并了系统配置并标记 insecure 仓库的 registries.conf，没有 insecure 仓库时为空
+	Insecure       []string // 不校验证书的仓库 host[:port]
+	Bundle         *trustbundle.Bundle
+}
+
+// RegistriesConfPath 返回生成的 registries.conf 的路径
+func RegistriesConfPath(clusterDir string) string {
+	return filepath.Join(clusterDir, registriesConfFilename)
+}
+
+// Prepare 读取额外信任的 CA 并写入证书目录，有 insecure 仓库时在系统 registries.conf 的基础上生成新的 registries.conf。
+// 不修改当前进程的环境变量，由调用方通过 MirrorArgs、SkopeoArgs、OCArgs 和 OCEnv 传给需要的命令
+func Prepare(cfg *config.ClusterConfig, clusterDir string) (*Policy, error) {
+	bundle, err := trustbundle.LoadMirrorBundle(cfg, clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	certDir, err := bundle.WriteCertDir(clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{CertDir: certDir, Insecure: cfg.GetInsecureRegistries(), Bundle: bundle}
+	if len(policy.Insecure) == 0 {
+		return policy, nil
+	}
+
+	path := RegistriesConfPath(clusterDir)
+	source := baseRegistriesConf(path)
+	base, err := os.ReadFile(source)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取 %s 失败: %w", source, err)
+	}
+	content, err := RenderRegistriesConf(base, policy.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", source, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	policy.RegistriesConf = path
+	return policy, nil
+}
+
+// baseRegistriesConf 返回合并的基础配置: 用户通过 CONTAINERS_REGISTRIES_CONF 指定的文件，否则为系统的 registries.conf
+func baseRegistriesConf(generated string) string {
+	if path := os.Getenv(RegistriesConfEnv); path != "" && filepath.Clean(path) != filepath.Clean(generated) {
+		return path
+	}
+	return systemRegistriesConf
+}
+
+// RenderRegistriesConf 在 base (系统的 registries.conf，可以为空) 的基础上将 registries 标记为 insecure。
+// v2 格式中已有同名 location 或 prefix 的 [[registry]] 只增加 insecure = true，保留其镜像源和 blocked 设置，
+// 其他仓库追加新的 [[registry]]；v1 格式加入 [registries.insecure]
+func RenderRegistriesConf(base []byte, registries []string) ([]byte, error) {
+	conf := map[string]any{}
+	if err := toml.Unmarshal(base, &conf); err != nil {
+		return nil, err
+	}
+	if v1, ok := conf["registries"].(map[string]any); ok {
+		markInsecureV1(v1, registries)
+	} else {
+		conf["registry"] = markInsecureV2(conf["registry"], registries)
+	}
+
+	content, err := toml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# 由 ocpack 根据系统 registries.conf 和 save_image.registry_tls 生成，请勿手动修改\n")
+	buf.Write(content)
+	return buf.Bytes(), nil
+}
+
+// markInsecureV2 将 registries 在 v2 格式的 [[registry]] 列表中标记为 insecure
+func markInsecureV2(value any, registries []string) []any {
+	entries, _ := value.([]any)
+	for _, registry := range registries {
+		found := false
+		for _, item := range entries {
+			entry, ok := item.(map[string]any)
+			if !ok || (entry["location"] != registry && entry["prefix"] != registry) {
+				continue
+			}
+			entry["insecure"] = true
+			found = true
+		}
+		if !found {
+			entries = append(entries, map[string]any{"location": registry, "insecure": true})
+		}
+	}
+	return entries
+}
+
+// markInsecureV1 将 registries 加入 v1 格式的 [registries.insecure]
+func markInsecureV1(conf map[string]any, registries []string) {
+	insecure, _ := conf["insecure"].(map[string]any)
+	if insecure == nil {
+		insecure = map[string]any{}
+		conf["insecure"] = insecure
+	}
+	existing, _ := insecure["registries"].([]any)
+	for _, registry := range registries {
+		found := false
+		for _, item := range existing {
+			if item == registry {
+				found = true
+			}
+		}
+		if !found {
+			existing = append(existing, registry)
+		}
+	}
+	insecure["registries"] = existing
+}
+
+// MirrorArgs 返回 oc-mirror 的证书参数，源仓库和目标仓库都信任 CertDir 中的 CA，有 insecure 仓库时使用生成的 registries.conf
+func (p *Policy) MirrorArgs() []string {
+	var args []string
+	if p.CertDir != "" {
+		args = append(args, "--src-cert-dir", p.CertDir, "--dest-cert-dir", p.CertDir)
+	}
+	if p.RegistriesConf != "" {
+		args = append(args, "--registries-conf", p.RegistriesConf)
+	}
+	return args
+}
+
+// IsInsecure 返回镜像 (如 docker://legacy.example.com/ns/app:v1) 所在的仓库是否不校验证书
+func (p *Policy) IsInsecure(image string) bool {
+	host, _, _ := strings.Cut(strings.TrimPrefix(image, "docker://"), "/")
+	for _, registry := range p.Insecure {
+		if registry == host {
+			return true
+		}
+	}
+	return false
+}
+
+// SkopeoArgs 返回 skopeo 读取镜像时的 TLS 参数，insecure 仓库不校验证书，其他仓库信任 CertDir 中的 CA
+func (p *Policy) SkopeoArgs(image string) []string {
+	if p.IsInsecure(image) {
+		return []string{"--tls-verify=false"}
+	}
+	if p.CertDir == "" {
+		return nil
+	}
+	return []string{"--cert-dir", p.CertDir}
+}
+
+// OCArgs 返回 oc adm release extract/mirror 读取镜像时的 TLS 参数，只有 insecure 仓库才加 --insecure
+func (p *Policy) OCArgs(image string) []string {
+	if p.IsInsecure(image) {
+		return []string{"--insecure"}
+	}
+	return nil
+}
+
+// OCEnv 返回 oc 子进程额外的环境变量，通过 SSL_CERT_DIR 在系统 CA 之外信任 CertDir 中的 CA
+func (p *Policy) OCEnv() []string {
+	if p.CertDir == "" {
+		return nil
+	}
+	dirs := append([]string{p.CertDir}, systemCertDirs...)
+	if existing := os.Getenv(sslCertDirEnv); existing != "" {
+		dirs = []string{p.CertDir, existing}
+	}
+	return []string{sslCertDirEnv + "=" + strings.Join(dirs, string(os.PathListSeparator))}
+}
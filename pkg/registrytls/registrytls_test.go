@@ -0,0 +1,221 @@
+package registrytls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/trustbundle"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+func writeCA(t *testing.T, path, name string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// withSystemRegistriesConf 在测试期间使用 content 作为系统的 registries.conf
+func withSystemRegistriesConf(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "registries.conf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	previous := systemRegistriesConf
+	systemRegistriesConf = path
+	t.Cleanup(func() { systemRegistriesConf = previous })
+	t.Setenv(RegistriesConfEnv, "")
+}
+
+func TestPrepareDefault(t *testing.T) {
+	clusterDir := t.TempDir()
+	policy, err := Prepare(config.NewDefaultConfig("demo"), clusterDir)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if policy.CertDir != "" || policy.RegistriesConf != "" || policy.MirrorArgs() != nil {
+		t.Errorf("Prepare() without extra CAs = %+v", policy)
+	}
+	if args := policy.SkopeoArgs("docker://quay.io/openshift/app:v1"); args != nil {
+		t.Errorf("SkopeoArgs() = %v, expected system trust", args)
+	}
+	if _, err := os.Stat(RegistriesConfPath(clusterDir)); !os.IsNotExist(err) {
+		t.Errorf("registries.conf should not be written without insecure registries: %v", err)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	withSystemRegistriesConf(t, "")
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.IP = "192.168.1.11"
+	writeCA(t, trustbundle.RegistryCAPaths(cfg, clusterDir)[0], "registry")
+	writeCA(t, filepath.Join(clusterDir, "certs", "proxy-ca.pem"), "proxy")
+	writeCA(t, filepath.Join(clusterDir, "certs", "lab-ca.pem"), "lab")
+	cfg.Infra.CABundle = "certs/proxy-ca.pem"
+	cfg.SaveImage.RegistryTLS = []config.RegistryTLS{
+		{Registry: "registry.lab.example.com:5000", CAFile: "certs/lab-ca.pem"},
+		{Registry: "legacy.example.com", Insecure: true},
+	}
+
+	policy, err := Prepare(cfg, clusterDir)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if len(policy.Bundle.Certificates) != 3 {
+		t.Errorf("expected registry, proxy and lab CAs, got %d", len(policy.Bundle.Certificates))
+	}
+	if policy.CertDir != trustbundle.CertDir(clusterDir) {
+		t.Errorf("CertDir = %q", policy.CertDir)
+	}
+	want := []string{"--src-cert-dir", policy.CertDir, "--dest-cert-dir", policy.CertDir, "--registries-conf", policy.RegistriesConf}
+	if args := policy.MirrorArgs(); !reflect.DeepEqual(args, want) {
+		t.Errorf("MirrorArgs() = %v, want %v", args, want)
+	}
+
+	conf, err := os.ReadFile(policy.RegistriesConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(conf), "[[registry]]\ninsecure = true\nlocation = 'legacy.example.com'\n") ||
+		strings.Contains(string(conf), "registry.lab.example.com") {
+		t.Errorf("unexpected registries.conf:\n%s", conf)
+	}
+
+	if args := policy.SkopeoArgs("docker://legacy.example.com/ns/app:v1"); !reflect.DeepEqual(args, []string{"--tls-verify=false"}) {
+		t.Errorf("SkopeoArgs() for insecure registry = %v", args)
+	}
+	if args := policy.SkopeoArgs("registry.lab.example.com:5000/ns/app:v1"); !reflect.DeepEqual(args, []string{"--cert-dir", policy.CertDir}) {
+		t.Errorf("SkopeoArgs() for verified registry = %v", args)
+	}
+	if args := policy.OCArgs("legacy.example.com/ns/release:4.16"); !reflect.DeepEqual(args, []string{"--insecure"}) {
+		t.Errorf("OCArgs() for insecure registry = %v", args)
+	}
+	if args := policy.OCArgs("registry.lab.example.com:5000/ns/release:4.16"); args != nil {
+		t.Errorf("OCArgs() for verified registry = %v", args)
+	}
+	t.Setenv("SSL_CERT_DIR", "/custom/certs")
+	if env := policy.OCEnv(); !reflect.DeepEqual(env, []string{"SSL_CERT_DIR=" + policy.CertDir + ":/custom/certs"}) {
+		t.Errorf("OCEnv() = %v", env)
+	}
+}
+
+func TestPrepareMergesSystemRegistriesConf(t *testing.T) {
+	withSystemRegistriesConf(t, `unqualified-search-registries = ["registry.access.redhat.com"]
+short-name-mode = "enforcing"
+
+[[registry]]
+location = "legacy.example.com"
+
+[[registry.mirror]]
+location = "mirror.example.com"
+
+[[registry]]
+location = "blocked.example.com"
+blocked = true
+`)
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.RegistryTLS = []config.RegistryTLS{
+		{Registry: "legacy.example.com", Insecure: true},
+		{Registry: "other.example.com:5000", Insecure: true},
+	}
+	policy, err := Prepare(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	conf, err := os.ReadFile(policy.RegistriesConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Search        []string `toml:"unqualified-search-registries"`
+		ShortNameMode string   `toml:"short-name-mode"`
+		Registry      []struct {
+			Location string
+			Insecure bool
+			Blocked  bool
+			Mirror   []struct{ Location string }
+		}
+	}
+	if err := toml.Unmarshal(conf, &got); err != nil {
+		t.Fatalf("generated registries.conf is invalid: %v\n%s", err, conf)
+	}
+	if !reflect.DeepEqual(got.Search, []string{"registry.access.redhat.com"}) || got.ShortNameMode != "enforcing" {
+		t.Errorf("system short-name settings lost:\n%s", conf)
+	}
+	if len(got.Registry) != 3 {
+		t.Fatalf("expected 3 registries, got:\n%s", conf)
+	}
+	legacy, blocked, other := got.Registry[0], got.Registry[1], got.Registry[2]
+	if !legacy.Insecure || len(legacy.Mirror) != 1 || legacy.Mirror[0].Location != "mirror.example.com" {
+		t.Errorf("existing registry should keep its mirrors and become insecure: %+v", legacy)
+	}
+	if !blocked.Blocked || blocked.Insecure {
+		t.Errorf("blocked registry changed: %+v", blocked)
+	}
+	if other.Location != "other.example.com:5000" || !other.Insecure {
+		t.Errorf("new insecure registry = %+v", other)
+	}
+}
+
+func TestRenderRegistriesConfV1(t *testing.T) {
+	conf, err := RenderRegistriesConf([]byte("[registries.search]\nregistries = [\"docker.io\"]\n[registries.insecure]\nregistries = [\"a.example.com\"]\n"),
+		[]string{"a.example.com", "b.example.com"})
+	if err != nil {
+		t.Fatalf("RenderRegistriesConf() error = %v", err)
+	}
+	var got struct {
+		Registries struct {
+			Search   struct{ Registries []string }
+			Insecure struct{ Registries []string }
+		}
+	}
+	if err := toml.Unmarshal(conf, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Registries.Insecure.Registries, []string{"a.example.com", "b.example.com"}) ||
+		!reflect.DeepEqual(got.Registries.Search.Registries, []string{"docker.io"}) {
+		t.Errorf("unexpected v1 registries.conf:\n%s", conf)
+	}
+}
+
+func TestPrepareMissingCAFile(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.RegistryTLS = []config.RegistryTLS{{Registry: "registry.lab.example.com", CAFile: "certs/missing.pem"}}
+	if _, err := Prepare(cfg, t.TempDir()); err == nil {
+		t.Error("Prepare() with missing ca_file should fail")
+	}
+}
@@ -0,0 +1,178 @@
+// Package report 记录各阶段命令 (download、save-image、deploy-registry、generate-iso 等) 的执行耗时、
+// 结果和关键输出，保存在集群目录的 .ocpack-report.json 中，并汇总为一张表格。
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Filename 阶段报告文件名，位于集群目录下
+const Filename = ".ocpack-report.json"
+
+// 阶段执行结果
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Formats 支持的输出格式
+var Formats = []string{"text", "json"}
+
+// StageRun 一个阶段最近一次执行的记录
+type StageRun struct {
+	Stage    string            `json:"stage"`
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration"`
+	Status   string            `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Outputs  map[string]string `json:"outputs,omitempty"` // 关键输出，如 ISO 路径、Registry 地址
+}
+
+// Report 集群的阶段报告，每个阶段只保留最近一次执行
+type Report struct {
+	Stages []StageRun `json:"stages"`
+}
+
+// Load 读取集群目录中的阶段报告，文件不存在时返回空报告
+func Load(clusterDir string) (*Report, error) {
+	report := &Report{}
+	data, err := os.ReadFile(filepath.Join(clusterDir, Filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取阶段报告失败: %w", err)
+	}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("解析阶段报告失败: %w", err)
+	}
+	return report, nil
+}
+
+// Save 保存阶段报告
+func Save(clusterDir string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化阶段报告失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(clusterDir, Filename), data, 0644); err != nil {
+		return fmt.Errorf("写入阶段报告失败: %w", err)
+	}
+	return nil
+}
+
+// Record 将一次阶段执行写入报告，替换该阶段之前的记录，阶段按开始时间排序
+func Record(clusterDir string, run StageRun) error {
+	report, err := Load(clusterDir)
+	if err != nil {
+		return err
+	}
+
+	stages := report.Stages[:0]
+	for _, existing := range report.Stages {
+		if existing.Stage != run.Stage {
+			stages = append(stages, existing)
+		}
+	}
+	report.Stages = append(stages, run)
+	sort.SliceStable(report.Stages, func(i, j int) bool {
+		return report.Stages[i].Start.Before(report.Stages[j].Start)
+	})
+	return Save(clusterDir, report)
+}
+
+// Last 返回阶段最近一次执行的记录
+func (r *Report) Last(stage string) (StageRun, bool) {
+	for _, run := range r.Stages {
+		if run.Stage == stage {
+			return run, true
+		}
+	}
+	return StageRun{}, false
+}
+
+// Total 返回全部阶段耗时之和
+func (r *Report) Total() time.Duration {
+	var total time.Duration
+	for _, run := range r.Stages {
+		total += run.Duration
+	}
+	return total
+}
+
+// Write 按指定格式输出阶段报告
+func Write(w io.Writer, clusterName string, report *Report, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, clusterName, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeText(w io.Writer, clusterName string, report *Report) error {
+	fmt.Fprintf(w, "集群 %s 阶段汇总\n\n", clusterName)
+	if len(report.Stages) == 0 {
+		fmt.Fprintln(w, "尚未记录任何阶段，执行 download、save-image、deploy-registry 等命令后生成")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "阶段\t开始时间\t耗时\t结果\t输出")
+	failed := 0
+	for _, run := range report.Stages {
+		status := "✅ 成功"
+		if run.Status != StatusSuccess {
+			status = "❌ 失败"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", run.Stage, run.Start.Local().Format("2006-01-02 15:04:05"),
+			run.Duration.Round(time.Second), status, formatOutputs(run))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n总耗时 %s，%d 个阶段", report.Total().Round(time.Second), len(report.Stages))
+	if failed > 0 {
+		fmt.Fprintf(w, "，%d 个失败", failed)
+	}
+	fmt.Fprintln(w)
+	for _, run := range report.Stages {
+		if run.Error != "" {
+			fmt.Fprintf(w, "  %s: %s\n", run.Stage, run.Error)
+		}
+	}
+	return nil
+}
+
+// formatOutputs 按键名排序输出关键输出，如 iso=demo/installation/iso/demo-agent.x86_64.iso
+func formatOutputs(run StageRun) string {
+	keys := make([]string, 0, len(run.Outputs))
+	for key := range run.Outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, key+"="+run.Outputs[key])
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
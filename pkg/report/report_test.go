@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReplacesStage(t *testing.T) {
+	clusterDir := t.TempDir()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	runs := []StageRun{
+		{Stage: "save_image", Start: start.Add(time.Hour), Duration: 40 * time.Minute, Status: StatusFailed, Error: "timeout"},
+		{Stage: "download", Start: start, Duration: 5 * time.Minute, Status: StatusSuccess},
+		{Stage: "save_image", Start: start.Add(2 * time.Hour), Duration: 30 * time.Minute, Status: StatusSuccess},
+	}
+	for _, run := range runs {
+		if err := Record(clusterDir, run); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	report, err := Load(clusterDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(report.Stages) != 2 {
+		t.Fatalf("expected 2 stages, got %+v", report.Stages)
+	}
+	if report.Stages[0].Stage != "download" || report.Stages[1].Stage != "save_image" {
+		t.Errorf("stages not ordered by start time: %+v", report.Stages)
+	}
+	if report.Stages[1].Status != StatusSuccess || report.Stages[1].Error != "" {
+		t.Errorf("save_image should keep only the latest run: %+v", report.Stages[1])
+	}
+	if got := report.Total(); got != 35*time.Minute {
+		t.Errorf("Total() = %s", got)
+	}
+	if run, ok := report.Last("save_image"); !ok || run.Status != StatusSuccess {
+		t.Errorf("Last(save_image) = %+v, %v", run, ok)
+	}
+	if _, ok := report.Last("monitor_install"); ok {
+		t.Error("Last(monitor_install) should report a missing stage")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	report, err := Load(t.TempDir())
+	if err != nil || len(report.Stages) != 0 {
+		t.Errorf("Load() = %+v, %v", report, err)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	report := &Report{Stages: []StageRun{
+		{Stage: "deploy_registry", Start: time.Now(), Duration: 90 * time.Second, Status: StatusSuccess,
+			Outputs: map[string]string{"registry": "registry.demo.example.com:8443"}},
+		{Stage: "generate_iso", Start: time.Now(), Duration: 2 * time.Minute, Status: StatusFailed, Error: "openshift-install 执行失败"},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "demo", report, "text"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"集群 demo 阶段汇总",
+		"registry=registry.demo.example.com:8443",
+		"1m30s",
+		"总耗时 3m30s，2 个阶段，1 个失败",
+		"generate_iso: openshift-install 执行失败",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := Write(&buf, "demo", report, "yaml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,248 @@
+// Package rewrite 将应用团队的 Kubernetes 清单和 Helm values 中的镜像地址改写为私有仓库中的地址，
+// 改写规则来自 oc-mirror 生成的 IDMS/ITMS (或 proxy-cache 模式的拉取代理)。
+package rewrite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MirrorFunc 返回镜像在私有仓库中的地址，没有对应的镜像源时返回 false
+type MirrorFunc func(image string) (string, bool)
+
+// Change 一处镜像地址改写。To 为空表示镜像没有对应的镜像源，保持不变
+type Change struct {
+	File string
+	Line int
+	From string
+	To   string
+}
+
+// Result 改写目录的结果
+type Result struct {
+	Changes   []Change // 已改写的镜像
+	Unmatched []Change // 没有对应镜像源的镜像
+	Skipped   []string // 无法解析 (如 Helm 模板) 而原样复制的 YAML 文件
+	Files     int      // 写入输出目录的文件数
+}
+
+// Dir 递归处理 in 目录中的文件并写入 out 目录的相同位置：*.yaml 和 *.yml 改写镜像地址，
+// 其他文件和无法解析的 YAML (如 Helm 模板) 原样复制
+func Dir(in, out string, mirror MirrorFunc) (*Result, error) {
+	absIn, err := filepath.Abs(in)
+	if err != nil {
+		return nil, err
+	}
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return nil, err
+	}
+	if absOut == absIn || strings.HasPrefix(absOut, absIn+string(filepath.Separator)) {
+		return nil, fmt.Errorf("输出目录 %s 不能是输入目录或位于输入目录之下", out)
+	}
+
+	result := &Result{}
+	err = filepath.WalkDir(absIn, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(absIn, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(absOut, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", path, err)
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == ".yaml" || ext == ".yml" {
+			rewritten, changes, err := YAML(content, mirror)
+			if err != nil {
+				result.Skipped = append(result.Skipped, rel)
+			} else {
+				content = rewritten
+				for _, change := range changes {
+					change.File = rel
+					if change.To == "" {
+						result.Unmatched = append(result.Unmatched, change)
+					} else {
+						result.Changes = append(result.Changes, change)
+					}
+				}
+			}
+		}
+
+		if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", target, err)
+		}
+		result.Files++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// YAML 改写多文档 YAML 中的镜像地址，返回改写后的内容和每个镜像的处理结果。
+// 没有改写任何镜像时原样返回输入，保留原有格式
+func YAML(content []byte, mirror MirrorFunc) ([]byte, []Change, error) {
+	var docs []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, fmt.Errorf("解析 YAML 失败: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	w := &walker{mirror: mirror}
+	for _, doc := range docs {
+		w.walk(doc, "")
+	}
+
+	rewritten := false
+	for _, change := range w.changes {
+		if change.To != "" {
+			rewritten = true
+		}
+	}
+	if !rewritten {
+		return content, w.changes, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, nil, fmt.Errorf("序列化 YAML 失败: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("序列化 YAML 失败: %w", err)
+	}
+	return buf.Bytes(), w.changes, nil
+}
+
+// walker 遍历 YAML 节点并改写镜像地址
+type walker struct {
+	mirror  MirrorFunc
+	changes []Change
+}
+
+// walk 处理两种写法：键为 image 或以 Image 结尾的字符串值 (Kubernetes 清单、values 中的完整地址)，
+// 以及包含 repository 和 registry/tag/digest 的映射 (Helm values 常见的拆分写法)
+func (w *walker) walk(node *yaml.Node, key string) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			w.walk(child, key)
+		}
+	case yaml.MappingNode:
+		if w.rewriteSplitImage(node) {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			w.walk(node.Content[i+1], node.Content[i].Value)
+		}
+	case yaml.ScalarNode:
+		if isImageKey(key) && isImageReference(node.Value) {
+			to := w.lookup(node.Value)
+			w.changes = append(w.changes, Change{Line: node.Line, From: node.Value, To: to})
+			if to != "" {
+				node.Value = to
+			}
+		}
+	}
+}
+
+// rewriteSplitImage 改写 {registry, repository, tag|digest} 形式的镜像，不是该形式时返回 false
+func (w *walker) rewriteSplitImage(node *yaml.Node) bool {
+	fields := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if value := node.Content[i+1]; value.Kind == yaml.ScalarNode {
+			fields[node.Content[i].Value] = value
+		}
+	}
+	repository, ok := fields["repository"]
+	if !ok || !isImageReference(repository.Value) {
+		return false
+	}
+	registry, tag, digest := fields["registry"], fields["tag"], fields["digest"]
+	if registry == nil && tag == nil && digest == nil {
+		return false
+	}
+
+	image := repository.Value
+	if registry != nil && registry.Value != "" {
+		image = strings.TrimSuffix(registry.Value, "/") + "/" + image
+	}
+	from := image
+	switch {
+	case digest != nil && digest.Value != "":
+		from += "@" + digest.Value
+	case tag != nil && tag.Value != "":
+		from += ":" + tag.Value
+	}
+
+	to := w.lookup(from)
+	w.changes = append(w.changes, Change{Line: repository.Line, From: from, To: to})
+	if to == "" {
+		return true
+	}
+
+	// 只改写仓库部分，tag 和 digest 保持原样
+	mirrored := strings.TrimSuffix(to, strings.TrimPrefix(from, image))
+	if registry != nil {
+		host, path, _ := strings.Cut(mirrored, "/")
+		registry.Value = host
+		repository.Value = path
+	} else {
+		repository.Value = mirrored
+	}
+	return true
+}
+
+// lookup 返回镜像在私有仓库中的地址，没有对应镜像源时返回空
+func (w *walker) lookup(image string) string {
+	if to, ok := w.mirror(image); ok {
+		return to
+	}
+	return ""
+}
+
+// isImageKey 判断键是否表示镜像地址，如 image、initImage、sidecarImage
+func isImageKey(key string) bool {
+	return key == "image" || strings.HasSuffix(key, "Image")
+}
+
+// isImageReference 排除空值和 Helm 模板表达式等不是镜像地址的值
+func isImageReference(value string) bool {
+	return value != "" && !strings.ContainsAny(value, " {}$")
+}
@@ -0,0 +1,173 @@
+package rewrite
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testMirror 将 quay.io/example 和 docker.io/bitnami 下的镜像映射到私有仓库
+func testMirror(image string) (string, bool) {
+	for source, mirror := range map[string]string{
+		"quay.io/example/":   "registry.demo.example.com:8443/example/",
+		"docker.io/bitnami/": "registry.demo.example.com:8443/bitnami/",
+	} {
+		if strings.HasPrefix(image, source) {
+			return mirror + strings.TrimPrefix(image, source), true
+		}
+	}
+	return "", false
+}
+
+func TestYAMLDeployment(t *testing.T) {
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: quay.io/example/init@sha256:abcd
+      containers:
+      - name: app
+        image: quay.io/example/app:v1 # 应用镜像
+      - name: proxy
+        image: registry.example.com/team/proxy:v2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  image: busybox:1.36
+`
+	out, changes, err := YAML([]byte(content), testMirror)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+
+	got := string(out)
+	for _, want := range []string{
+		"image: registry.demo.example.com:8443/example/init@sha256:abcd",
+		"image: registry.demo.example.com:8443/example/app:v1 # 应用镜像",
+		"image: registry.example.com/team/proxy:v2",
+		"kind: ConfigMap",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	var rewritten, unmatched int
+	for _, change := range changes {
+		if change.To == "" {
+			unmatched++
+		} else {
+			rewritten++
+		}
+	}
+	// ConfigMap data 中的 image 键同样按镜像处理，没有镜像源时保持不变
+	if rewritten != 2 || unmatched != 2 {
+		t.Errorf("expected 2 rewritten and 2 unmatched, got %+v", changes)
+	}
+}
+
+func TestYAMLHelmValues(t *testing.T) {
+	content := `image:
+  registry: docker.io
+  repository: bitnami/nginx
+  tag: 1.25.3
+metrics:
+  image:
+    repository: quay.io/example/exporter
+    digest: sha256:beef
+sidecarImage: quay.io/example/sidecar:latest
+templated:
+  image: "{{ .Values.global.image }}"
+`
+	out, changes, err := YAML([]byte(content), testMirror)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+
+	got := string(out)
+	for _, want := range []string{
+		"registry: registry.demo.example.com:8443",
+		"repository: bitnami/nginx",
+		"tag: 1.25.3",
+		"repository: registry.demo.example.com:8443/example/exporter",
+		"digest: sha256:beef",
+		"sidecarImage: registry.demo.example.com:8443/example/sidecar:latest",
+		`image: "{{ .Values.global.image }}"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected 3 changes, got %+v", changes)
+	}
+}
+
+func TestYAMLUnchangedKeepsFormatting(t *testing.T) {
+	content := "containers:\n    - image:   registry.example.com/app:v1\n"
+	out, _, err := YAML([]byte(content), testMirror)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	if string(out) != content {
+		t.Errorf("unchanged content was reformatted:\n%s", out)
+	}
+}
+
+func TestDir(t *testing.T) {
+	in := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(in, "chart", "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"deploy.yaml":                     "spec:\n  containers:\n  - image: quay.io/example/app:v1\n",
+		"chart/values.yml":                "image:\n  repository: quay.io/example/app\n  tag: v1\n",
+		"chart/templates/deployment.yaml": "{{- if .Values.enabled }}\nimage: {{ .Values.image }}\n{{- end }}\n",
+		"chart/Chart.txt":                 "name: demo\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(in, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	result, err := Dir(in, out, testMirror)
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	if result.Files != 4 || len(result.Changes) != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != filepath.Join("chart", "templates", "deployment.yaml") {
+		t.Errorf("Skipped = %v", result.Skipped)
+	}
+
+	content, err := os.ReadFile(filepath.Join(out, "deploy.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "registry.demo.example.com:8443/example/app:v1") {
+		t.Errorf("deploy.yaml not rewritten:\n%s", content)
+	}
+	template, err := os.ReadFile(filepath.Join(out, "chart", "templates", "deployment.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(template) != files["chart/templates/deployment.yaml"] {
+		t.Errorf("template should be copied unchanged:\n%s", template)
+	}
+
+	if _, err := Dir(in, filepath.Join(in, "out"), testMirror); err == nil {
+		t.Error("expected error when output directory is inside input directory")
+	}
+}
@@ -0,0 +1,150 @@
+// Package rpms 下载 Bastion、Registry 和 PXE 节点部署所需的软件包并生成离线 yum 仓库，
+// 部署时 playbook 将仓库复制到目标主机并只从该仓库安装软件包。
+package rpms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const (
+	// DirName 下载目录中离线仓库的目录名
+	DirName = "rpms"
+	// downloadTimeout dnf download 的超时时间
+	downloadTimeout = 60 * time.Minute
+)
+
+// Packages 各节点 playbook 安装的软件包，mirror-rpms 下载它们及其依赖
+var Packages = map[string][]string{
+	"bastion": {
+		"bind",
+		"bind-utils",
+		"dnsmasq",
+		"haproxy",
+		"firewalld",
+	},
+	"registry": {
+		"make",
+		"jq",
+		"python3-jinja2",
+		"python3-pyyaml",
+		"ncurses",
+		"which",
+		"file",
+		"hostname",
+		"diffutils",
+		"podman",
+		"bind-utils",
+		"nmstate",
+		"net-tools",
+		"skopeo",
+		"openssl",
+		"coreos-installer",
+		"httpd",
+	},
+	"pxe": {
+		"tftp-server",
+		"httpd",
+		"dhcp-server",
+		"syslinux",
+		"syslinux-tftpboot",
+	},
+}
+
+// OptionalPackages 可选的软件包，仓库中不存在时不会导致下载失败
+var OptionalPackages = []string{"ipxe-bootimgs"}
+
+// RepoDir 返回下载目录中离线仓库的路径
+func RepoDir(downloadDir string) string {
+	return filepath.Join(downloadDir, DirName)
+}
+
+// HasRepo 判断下载目录中是否已有生成好的离线仓库
+func HasRepo(downloadDir string) bool {
+	_, err := os.Stat(filepath.Join(RepoDir(downloadDir), "repodata", "repomd.xml"))
+	return err == nil
+}
+
+// PackageSet 返回需要下载的全部软件包 (去重并排序)，包括 [rpms] extra_packages
+func PackageSet(cfg *config.ClusterConfig) []string {
+	seen := make(map[string]bool)
+	var packages []string
+	add := func(names []string) {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				packages = append(packages, name)
+			}
+		}
+	}
+	for _, names := range Packages {
+		add(names)
+	}
+	add(cfg.Rpms.ExtraPackages)
+	sort.Strings(packages)
+	return packages
+}
+
+// Mirror 使用 dnf download --resolve --alldeps 下载软件包及其全部依赖到离线仓库目录，
+// 然后使用 createrepo_c 生成仓库元数据。需要在联网、与目标节点系统版本一致的 RHEL 主机上执行。
+// ctx 被取消或超时时终止正在执行的 dnf 和 createrepo_c
+func Mirror(ctx context.Context, r runner.CommandRunner, cfg *config.ClusterConfig, downloadDir string) error {
+	if err := config.ValidateRpmsConfig(cfg); err != nil {
+		return err
+	}
+	for _, tool := range []string{"dnf", "createrepo_c"} {
+		if _, err := r.LookPath(tool); err != nil {
+			return fmt.Errorf("未找到 %s，请在 RHEL 主机上安装 dnf-plugins-core 和 createrepo_c", tool)
+		}
+	}
+
+	r = runner.WithContext(ctx, r)
+	repoDir := RepoDir(downloadDir)
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return fmt.Errorf("创建离线仓库目录失败: %w", err)
+	}
+
+	packages := PackageSet(cfg)
+	fmt.Printf("📦 下载 %d 个软件包及其依赖到 %s\n", len(packages), repoDir)
+	if err := download(r, cfg, repoDir, packages); err != nil {
+		return err
+	}
+	for _, pkg := range OptionalPackages {
+		if err := download(r, cfg, repoDir, []string{pkg}); err != nil {
+			fmt.Printf("⚠️  可选软件包 %s 下载失败，已跳过: %v\n", pkg, err)
+		}
+	}
+
+	fmt.Println("🗂️  生成仓库元数据...")
+	cmd := runner.Command{Name: "createrepo_c", Args: []string{repoDir}, Timeout: runner.DefaultTimeout}
+	if result, err := r.Run(cmd); err != nil {
+		return fmt.Errorf("createrepo_c 执行失败: %w, 输出: %s", err, string(result.Combined))
+	}
+	return nil
+}
+
+// download 执行一次 dnf download
+func download(r runner.CommandRunner, cfg *config.ClusterConfig, repoDir string, packages []string) error {
+	args := []string{"download", "--resolve", "--alldeps", "--destdir", repoDir}
+	if cfg.Rpms.ReleaseVer != "" {
+		args = append(args, "--releasever", cfg.Rpms.ReleaseVer)
+	}
+	for _, repo := range cfg.Rpms.Repos {
+		args = append(args, "--repo", repo)
+	}
+	args = append(args, packages...)
+
+	cmd := runner.Command{Name: "dnf", Args: args, Stream: true, Timeout: downloadTimeout}
+	fmt.Printf("ℹ️  执行命令: %s\n", cmd)
+	if _, err := r.Run(cmd); err != nil {
+		return fmt.Errorf("dnf download 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package rpms
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func TestPackageSet(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Rpms.ExtraPackages = []string{"vim-enhanced", "httpd"}
+
+	packages := PackageSet(cfg)
+	seen := make(map[string]int)
+	for _, pkg := range packages {
+		seen[pkg]++
+	}
+	for _, expected := range []string{"haproxy", "bind", "podman", "httpd", "dhcp-server", "vim-enhanced"} {
+		if seen[expected] != 1 {
+			t.Errorf("package %s appears %d times in %v", expected, seen[expected], packages)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Rpms.ReleaseVer = "9"
+	cfg.Rpms.Repos = []string{"rhel-9-for-x86_64-baseos-rpms"}
+	downloadDir := t.TempDir()
+
+	fake := &runner.Fake{
+		Paths: map[string]string{"dnf": "/usr/bin/dnf", "createrepo_c": "/usr/bin/createrepo_c"},
+		Handler: func(cmd runner.Command) (*runner.Result, error) {
+			if cmd.Name == "dnf" && strings.Contains(strings.Join(cmd.Args, " "), "ipxe-bootimgs") {
+				return nil, errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+	}
+
+	if err := Mirror(context.Background(), fake, cfg, downloadDir); err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+
+	lines := fake.CommandLines()
+	if len(lines) != 3 {
+		t.Fatalf("expected dnf download, optional download and createrepo_c, got %v", lines)
+	}
+	repoDir := filepath.Join(downloadDir, DirName)
+	prefix := "dnf download --resolve --alldeps --destdir " + repoDir + " --releasever 9 --repo rhel-9-for-x86_64-baseos-rpms "
+	if !strings.HasPrefix(lines[0], prefix) || !strings.Contains(lines[0], " haproxy ") {
+		t.Errorf("unexpected dnf command: %s", lines[0])
+	}
+	if lines[2] != "createrepo_c "+repoDir {
+		t.Errorf("unexpected createrepo command: %s", lines[2])
+	}
+	if _, err := os.Stat(repoDir); err != nil {
+		t.Errorf("repo dir not created: %v", err)
+	}
+}
+
+func TestMirrorRequiresTools(t *testing.T) {
+	if err := Mirror(context.Background(), &runner.Fake{}, config.NewDefaultConfig("demo"), t.TempDir()); err == nil || !strings.Contains(err.Error(), "dnf") {
+		t.Errorf("expected missing dnf error, got %v", err)
+	}
+}
+
+func TestHasRepo(t *testing.T) {
+	downloadDir := t.TempDir()
+	if HasRepo(downloadDir) {
+		t.Fatal("HasRepo() = true for empty download dir")
+	}
+	repodata := filepath.Join(RepoDir(downloadDir), "repodata")
+	if err := os.MkdirAll(repodata, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repodata, "repomd.xml"), []byte("<repomd/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !HasRepo(downloadDir) {
+		t.Error("HasRepo() = false after createrepo")
+	}
+}
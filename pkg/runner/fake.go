@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Fake 记录所有调用并返回预设结果的 CommandRunner，供测试使用
+type Fake struct {
+	// Handler 决定每次调用的返回值，为 nil 时返回空结果
+	Handler func(cmd Command) (*Result, error)
+	// Paths LookPath 的查找结果，未列出的命令视为不存在
+	Paths map[string]string
+
+	mu    sync.Mutex
+	calls []Command
+}
+
+// Run 记录调用并交给 Handler 处理
+func (f *Fake) Run(cmd Command) (*Result, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	f.mu.Unlock()
+
+	if f.Handler == nil {
+		return &Result{}, nil
+	}
+	result, err := f.Handler(cmd)
+	if result == nil {
+		result = &Result{}
+	}
+	return result, err
+}
+
+// LookPath 返回 Paths 中预设的路径
+func (f *Fake) LookPath(file string) (string, error) {
+	if path, ok := f.Paths[file]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("executable file not found in $PATH: %s", file)
+}
+
+// Calls 返回已记录的调用
+func (f *Fake) Calls() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Command(nil), f.calls...)
+}
+
+// CommandLines 返回已记录调用的命令行，便于断言
+func (f *Fake) CommandLines() []string {
+	var lines []string
+	for _, cmd := range f.Calls() {
+		lines = append(lines, cmd.String())
+	}
+	return lines
+}
@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"ocpack/pkg/secrets"
+)
+
+// DefaultTimeout 适用于 oc get/patch/apply、skopeo inspect 等短时命令的超时时间
+const DefaultTimeout = 2 * time.Minute
+
+// CancelGracePeriod 命令因取消或超时被终止时，发送 SIGTERM 后等待其退出的时间，超过后强制结束。
+// ansible-playbook、openshift-install 等在收到 SIGTERM 后会结束自己启动的 ssh 等子进程
+const CancelGracePeriod = 10 * time.Second
+
+// Command 一次外部命令调用
+type Command struct {
+	Name    string
+	Args    []string
+	Dir     string        // 工作目录，为空时使用当前目录
+	Env     []string      // 追加到当前进程环境变量之后
+	Stdin   []byte        // 写入标准输入的内容，用于传递密码等不应出现在命令行中的数据
+	Stream  bool          // 为 true 时输出直接透传到终端，不再捕获到 Result 中
+	Output  io.Writer     // Stream 模式下 stdout 和 stderr 的输出目标，为空时使用终端
+	Timeout time.Duration // 为 0 时不限制执行时间
+	Secrets []string      // 需要在打印的命令行中隐藏的敏感值
+	// Context 取消或超时时终止命令，为空时使用 context.Background()，通常由 WithContext 设置
+	Context context.Context
+}
+
+// String 返回便于打印的命令行，--password 等参数的值和 Secrets 中的内容已被隐藏
+func (c Command) String() string {
+	line := strings.TrimSpace(c.Name + " " + strings.Join(secrets.RedactArgs(c.Args), " "))
+	return secrets.Redact(line, c.Secrets...)
+}
+
+// Result 命令执行捕获的输出 (Stream 模式下为空)
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	Combined []byte // 按输出顺序合并的 stdout 和 stderr
+}
+
+// CommandRunner 执行外部命令的接口，各模块通过它调用 oc、openshift-install、skopeo 等工具，
+// 测试时可替换为 Fake
+type CommandRunner interface {
+	// Run 执行命令。命令失败时返回的错误与 os/exec 一致 (例如 *exec.ExitError)，超时时返回包装了
+	// context.DeadlineExceeded 的错误，Context 被取消时返回包装了 context.Canceled 的错误；
+	// 这些情况下 Result 都包含已捕获的输出
+	Run(cmd Command) (*Result, error)
+	// LookPath 在 PATH 中查找可执行文件
+	LookPath(file string) (string, error)
+}
+
+// ExecRunner 基于 os/exec 的默认实现
+type ExecRunner struct{}
+
+// NewExecRunner 创建默认的 CommandRunner
+func NewExecRunner() CommandRunner {
+	return ExecRunner{}
+}
+
+// Run 执行命令并捕获输出
+func (ExecRunner) Run(c Command) (*Result, error) {
+	parent := c.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx := parent
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	// 先发送 SIGTERM 让命令结束自己的子进程，CancelGracePeriod 后仍未退出时强制结束
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = CancelGracePeriod
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	if c.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(c.Stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	combined := &lockedBuffer{}
+	if c.Stream && c.Output != nil {
+		cmd.Stdout = c.Output
+		cmd.Stderr = c.Output
+	} else if c.Stream {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	} else {
+		cmd.Stdout = io.MultiWriter(&stdout, combined)
+		cmd.Stderr = io.MultiWriter(&stderr, combined)
+	}
+
+	err := cmd.Run()
+	result := &Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), Combined: combined.Bytes()}
+	if err != nil && parent.Err() != nil {
+		// 整个操作被取消或超过 --timeout
+		return result, fmt.Errorf("命令 %s 已终止: %w", c.Name, context.Cause(parent))
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("命令 %s 执行超时 (%s): %w", c.Name, c.Timeout, context.DeadlineExceeded)
+	}
+	return result, err
+}
+
+// WithContext 返回将 ctx 绑定到每个命令的 CommandRunner：ctx 被取消或超时时终止正在执行的命令，
+// 命令已设置 Context 时保持不变
+func WithContext(ctx context.Context, r CommandRunner) CommandRunner {
+	return contextRunner{ctx: ctx, CommandRunner: r}
+}
+
+// contextRunner 为命令设置 Context 的 CommandRunner
+type contextRunner struct {
+	CommandRunner
+	ctx context.Context
+}
+
+// Run 为命令设置 Context 后交给被包装的 CommandRunner 执行，ctx 已结束时不再启动命令
+func (r contextRunner) Run(c Command) (*Result, error) {
+	if c.Context == nil {
+		c.Context = r.ctx
+	}
+	if err := c.Context.Err(); err != nil {
+		return &Result{}, fmt.Errorf("命令 %s 未执行: %w", c.Name, context.Cause(c.Context))
+	}
+	return r.CommandRunner.Run(c)
+}
+
+// LookPath 在 PATH 中查找可执行文件
+func (ExecRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// lockedBuffer 允许 stdout 和 stderr 并发写入的缓冲区
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecRunnerCapturesOutput(t *testing.T) {
+	r := NewExecRunner()
+	result, err := r.Run(Command{
+		Name: "sh",
+		Args: []string{"-c", `echo out; echo err >&2; echo "$OCPACK_TEST"`},
+		Env:  []string{"OCPACK_TEST=value"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(result.Stdout) != "out\nvalue\n" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+	if string(result.Stderr) != "err\n" {
+		t.Errorf("Stderr = %q", result.Stderr)
+	}
+	if !strings.Contains(string(result.Combined), "out") || !strings.Contains(string(result.Combined), "err") {
+		t.Errorf("Combined = %q", result.Combined)
+	}
+}
+
+func TestExecRunnerErrors(t *testing.T) {
+	r := NewExecRunner()
+
+	result, err := r.Run(Command{Name: "sh", Args: []string{"-c", "echo failed; exit 3"}})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Run() error = %v, expected exit code 3", err)
+	}
+	if string(result.Combined) != "failed\n" {
+		t.Errorf("Combined = %q, expected output of failed command", result.Combined)
+	}
+
+	_, err = r.Run(Command{Name: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, expected timeout", err)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := WithContext(ctx, NewExecRunner())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Run(Command{Name: "sleep", Args: []string{"30"}})
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() error = %v, expected the command to be canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command was not terminated on cancellation")
+	}
+
+	// no command starts once the context is done
+	fake := &Fake{}
+	if _, err := WithContext(ctx, fake).Run(Command{Name: "oc"}); !errors.Is(err, context.Canceled) || len(fake.Calls()) != 0 {
+		t.Errorf("Run() error = %v after %d calls, expected no call", err, len(fake.Calls()))
+	}
+}
+
+func TestFake(t *testing.T) {
+	f := &Fake{
+		Handler: func(cmd Command) (*Result, error) {
+			if cmd.Name == "oc" {
+				return &Result{Stdout: []byte("ok")}, nil
+			}
+			return nil, errors.New("unexpected")
+		},
+		Paths: map[string]string{"oc": "/usr/bin/oc"},
+	}
+
+	if result, err := f.Run(Command{Name: "oc", Args: []string{"get", "nodes"}}); err != nil || string(result.Stdout) != "ok" {
+		t.Errorf("Run(oc) = %v, %v", result, err)
+	}
+	if result, err := f.Run(Command{Name: "skopeo"}); err == nil || result == nil {
+		t.Errorf("Run(skopeo) = %v, %v, expected error with empty result", result, err)
+	}
+	if lines := f.CommandLines(); len(lines) != 2 || lines[0] != "oc get nodes" {
+		t.Errorf("CommandLines() = %v", lines)
+	}
+	if _, err := f.LookPath("podman"); err == nil {
+		t.Error("LookPath(podman) expected error")
+	}
+}
+
+func TestExecRunnerStdin(t *testing.T) {
+	result, err := NewExecRunner().Run(Command{Name: "cat", Stdin: []byte("s3cret")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(result.Stdout) != "s3cret" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+}
+
+func TestCommandStringRedactsSecrets(t *testing.T) {
+	cmd := Command{
+		Name:    "sshpass",
+		Args:    []string{"-p", "s3cret", "ssh", "root@host", "uptime"},
+		Secrets: []string{"s3cret"},
+	}
+	line := cmd.String()
+	if strings.Contains(line, "s3cret") {
+		t.Errorf("String() leaked secret: %s", line)
+	}
+	if cmd := (Command{Name: "podman", Args: []string{"login", "--password", "hunter2"}}); strings.Contains(cmd.String(), "hunter2") {
+		t.Errorf("String() leaked --password value: %s", cmd)
+	}
+}
+
+func TestExecRunnerStreamOutput(t *testing.T) {
+	var out strings.Builder
+	result, err := NewExecRunner().Run(Command{
+		Name:   "sh",
+		Args:   []string{"-c", "echo out; echo err >&2"},
+		Stream: true,
+		Output: &out,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.String() != "out\nerr\n" {
+		t.Errorf("Output = %q", out.String())
+	}
+	if len(result.Combined) != 0 {
+		t.Errorf("Combined = %q, expected nothing captured in stream mode", result.Combined)
+	}
+}
@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ocpack/pkg/config"
+)
+
+// releaseRepository OpenShift release 镜像仓库
+const releaseRepository = "quay.io/openshift-release-dev/ocp-release"
+
+// DefaultImagesFile 返回 oc-mirror dry-run 生成的镜像映射文件，包含镜像集中的全部镜像
+func DefaultImagesFile(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "working-dir", "dry-run", "mapping.txt")
+}
+
+// ListImages 返回待扫描的镜像和镜像列表的来源。优先读取 scan.images_file 或 dry-run 的 mapping.txt，
+// 两者都不存在时只扫描 openshift_version 对应的 release 镜像和 additional_images，并在 out 中输出提示
+func ListImages(out io.Writer, clusterDir string, cfg *config.ClusterConfig) ([]string, string, error) {
+	imagesFile := cfg.Scan.ImagesFile
+	if imagesFile != "" && !filepath.IsAbs(imagesFile) {
+		imagesFile = filepath.Join(clusterDir, imagesFile)
+	}
+	if imagesFile == "" {
+		imagesFile = DefaultImagesFile(clusterDir)
+		if _, err := os.Stat(imagesFile); os.IsNotExist(err) {
+			fmt.Fprintf(out, "⚠️  未找到 %s，只扫描 release 镜像和 additional_images；\n", imagesFile)
+			fmt.Fprintln(out, "   如需扫描完整镜像集 (包括 Operator)，请先使用 --dry-run 执行 save-image")
+			return configImages(cfg), "config.toml", nil
+		}
+	}
+
+	content, err := os.ReadFile(imagesFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取镜像列表失败: %w", err)
+	}
+	return parseImageList(content), imagesFile, nil
+}
+
+// configImages 根据配置生成镜像列表
+func configImages(cfg *config.ClusterConfig) []string {
+	images := []string{fmt.Sprintf("%s:%s", releaseRepository, cfg.GetReleaseTag())}
+	return dedupe(append(images, cfg.GetAdditionalImages()...))
+}
+
+// parseImageList 解析镜像列表，每行一个镜像，或 oc-mirror mapping.txt 的 源=目标 格式 (取源镜像)。
+// 空行和 # 开头的注释会被忽略。非 docker:// 传输方式的镜像 (如 oci:// 目录) 和 oc-mirror 本地缓存
+// (localhost，load-image --dry-run 生成的映射中的源镜像) 无法直接扫描，会被跳过
+func parseImageList(content []byte) []string {
+	var images []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 源镜像中的 '=' 只可能出现在分隔符处
+		if idx := strings.Index(line, "="); idx >= 0 {
+			line = line[:idx]
+		}
+		if transport := strings.Index(line, "://"); transport >= 0 {
+			if line[:transport] != "docker" {
+				continue
+			}
+			line = line[transport+3:]
+		}
+		if strings.HasPrefix(line, "localhost:") || strings.HasPrefix(line, "localhost/") {
+			continue
+		}
+		images = append(images, line)
+	}
+	return dedupe(images)
+}
+
+// dedupe 去掉重复的镜像并保持原有顺序
+func dedupe(images []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, image := range images {
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+		result = append(result, image)
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+// Package scan 在镜像加载到私有仓库之前对镜像集执行漏洞扫描，
+// 扫描器可以是 Trivy 或输出 Trivy 格式 JSON 的自定义命令，结果汇总为报告并按阈值判断是否通过。
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const (
+	// ReportDirName 集群目录中保存扫描报告的目录
+	ReportDirName = "scan"
+	// ReportFilename 扫描报告的文件名
+	ReportFilename = "report.json"
+)
+
+// Finding 一个漏洞
+type Finding struct {
+	ID               string `json:"id"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	FixedVersion     string `json:"fixedVersion,omitempty"`
+	Severity         string `json:"severity"`
+	Title            string `json:"title,omitempty"`
+}
+
+// Scanner 对单个镜像执行扫描
+type Scanner interface {
+	Name() string
+	Scan(image string) ([]Finding, error)
+}
+
+// ImageResult 单个镜像的扫描结果
+type ImageResult struct {
+	Image    string    `json:"image"`
+	Findings []Finding `json:"findings,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Report 一次扫描的报告
+type Report struct {
+	Scanner     string         `json:"scanner"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	FailOn      string         `json:"failOn,omitempty"`
+	Summary     map[string]int `json:"summary"` // 各严重级别的漏洞数量
+	Images      []ImageResult  `json:"images"`
+}
+
+// ReportPath 返回集群目录中扫描报告的路径
+func ReportPath(clusterDir string) string {
+	return filepath.Join(clusterDir, ReportDirName, ReportFilename)
+}
+
+// ScanImages 使用 scanner 依次扫描 images，进度写入 out，单个镜像扫描失败时记录错误并继续
+func ScanImages(out io.Writer, scanner Scanner, images []string, failOn string) *Report {
+	report := &Report{
+		Scanner:     scanner.Name(),
+		GeneratedAt: time.Now().UTC(),
+		FailOn:      failOn,
+		Summary:     make(map[string]int),
+	}
+	for i, image := range images {
+		fmt.Fprintf(out, "🔍 扫描镜像 (%d/%d): %s\n", i+1, len(images), image)
+		findings, err := scanner.Scan(image)
+		result := ImageResult{Image: image, Findings: findings}
+		if err != nil {
+			fmt.Fprintf(out, "⚠️  扫描失败: %v\n", err)
+			result.Error = err.Error()
+		}
+		sort.SliceStable(result.Findings, func(a, b int) bool {
+			return config.SeverityRank(result.Findings[a].Severity) > config.SeverityRank(result.Findings[b].Severity)
+		})
+		for _, finding := range result.Findings {
+			report.Summary[normalizeSeverity(finding.Severity)]++
+		}
+		report.Images = append(report.Images, result)
+	}
+	return report
+}
+
+// Violations 返回达到 FailOn 阈值的漏洞数量和扫描失败的镜像数量。未设置阈值时均为 0
+func (r *Report) Violations() (findings, failedImages int) {
+	if r.FailOn == "" {
+		return 0, 0
+	}
+	threshold := config.SeverityRank(r.FailOn)
+	for _, image := range r.Images {
+		if image.Error != "" {
+			failedImages++
+		}
+		for _, finding := range image.Findings {
+			if config.SeverityRank(finding.Severity) >= threshold {
+				findings++
+			}
+		}
+	}
+	return findings, failedImages
+}
+
+// Write 以 JSON 格式保存报告
+func (r *Report) Write(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化扫描报告失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建扫描报告目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("保存扫描报告失败: %w", err)
+	}
+	return nil
+}
+
+// PrintSummary 将各严重级别的漏洞数量写入 out
+func (r *Report) PrintSummary(out io.Writer) {
+	fmt.Fprintf(out, "📊 扫描了 %d 个镜像 (扫描器: %s)\n", len(r.Images), r.Scanner)
+	for i := len(config.SeverityLevels) - 1; i >= 0; i-- {
+		level := config.SeverityLevels[i]
+		fmt.Fprintf(out, "   %-8s %d\n", level, r.Summary[level])
+	}
+}
+
+// Run 按 [scan] 配置扫描集群的镜像集，报告保存到 scan/report.json。
+// 配置了 fail_on 且存在达到阈值的漏洞或无法扫描的镜像时返回错误。ctx 被取消或超时时终止正在执行的扫描命令
+func Run(ctx context.Context, clusterDir string, cfg *config.ClusterConfig) (*Report, error) {
+	return RunTo(ctx, os.Stdout, runner.NewExecRunner(), clusterDir, cfg)
+}
+
+// RunTo 与 Run 相同，扫描进度和结果写入 out，扫描命令通过 r 执行
+func RunTo(ctx context.Context, out io.Writer, r runner.CommandRunner, clusterDir string, cfg *config.ClusterConfig) (*Report, error) {
+	if err := config.ValidateScanConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	images, source, err := ListImages(out, clusterDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("没有需要扫描的镜像 (镜像列表: %s)", source)
+	}
+	fmt.Fprintf(out, "ℹ️  从 %s 读取到 %d 个待扫描镜像\n", source, len(images))
+
+	scanner, err := NewScanner(cfg.Scan, runner.WithContext(ctx, r))
+	if err != nil {
+		return nil, err
+	}
+	report := ScanImages(out, scanner, images, cfg.Scan.FailOn)
+	if ctx.Err() != nil {
+		return report, fmt.Errorf("镜像扫描被中断: %w", context.Cause(ctx))
+	}
+
+	reportPath := ReportPath(clusterDir)
+	if err := report.Write(reportPath); err != nil {
+		return report, err
+	}
+	report.PrintSummary(out)
+	fmt.Fprintf(out, "📄 扫描报告: %s\n", reportPath)
+
+	findings, failedImages := report.Violations()
+	if findings > 0 || failedImages > 0 {
+		return report, fmt.Errorf("镜像扫描未通过: %d 个 %s 及以上级别的漏洞，%d 个镜像扫描失败", findings, report.FailOn, failedImages)
+	}
+	return report, nil
+}
+
+// normalizeSeverity 将严重级别统一为 SeverityLevels 中的值
+func normalizeSeverity(severity string) string {
+	return config.SeverityLevels[config.SeverityRank(severity)]
+}
@@ -0,0 +1,138 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const trivyOutput = `{
+  "Results": [
+    {
+      "Target": "openshift/release (rhel 9.2)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.0.7", "FixedVersion": "3.0.8", "Severity": "HIGH", "Title": "openssl issue"},
+        {"VulnerabilityID": "CVE-2024-0002", "PkgName": "glibc", "InstalledVersion": "2.34", "Severity": "LOW"}
+      ]
+    }
+  ]
+}`
+
+func TestParseImageList(t *testing.T) {
+	content := []byte(`# mapping
+docker://registry.redhat.io/ubi9/ubi@sha256:abc=docker://localhost:55000/ubi9/ubi@sha256:abc
+docker://registry.redhat.io/ubi9/ubi@sha256:abc=docker://localhost:55000/ubi9/ubi@sha256:abc
+oci:///tmp/catalog=docker://localhost:55000/redhat/redhat-operator-index:v4.14
+docker://localhost:55000/openshift/release:4.14.10-x86_64=docker://registry.demo.example.com:8443/openshift/release:4.14.10-x86_64
+
+quay.io/example/app:1.0
+`)
+	images := parseImageList(content)
+	expected := []string{"registry.redhat.io/ubi9/ubi@sha256:abc", "quay.io/example/app:1.0"}
+	if strings.Join(images, ",") != strings.Join(expected, ",") {
+		t.Errorf("parseImageList() = %v, expected %v", images, expected)
+	}
+}
+
+func TestListImagesFallback(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.ClusterInfo.OpenShiftVersion = "4.14.10"
+	cfg.SaveImage.AdditionalImages = []string{"quay.io/example/app:1.0"}
+
+	images, source, err := ListImages(io.Discard, t.TempDir(), cfg)
+	if err != nil {
+		t.Fatalf("ListImages() error = %v", err)
+	}
+	expected := []string{"quay.io/openshift-release-dev/ocp-release:4.14.10-x86_64", "quay.io/example/app:1.0"}
+	if source != "config.toml" || strings.Join(images, ",") != strings.Join(expected, ",") {
+		t.Errorf("ListImages() = %v from %s, expected %v", images, source, expected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Scan.ImagesFile = "images.txt"
+	cfg.Scan.FailOn = "HIGH"
+	if err := os.WriteFile(filepath.Join(clusterDir, "images.txt"), []byte("quay.io/example/app:1.0\nquay.io/example/broken:1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{
+		Paths: map[string]string{"trivy": "/usr/bin/trivy"},
+		Handler: func(cmd runner.Command) (*runner.Result, error) {
+			if strings.Contains(strings.Join(cmd.Args, " "), "broken") {
+				return &runner.Result{Stderr: []byte("manifest unknown")}, errors.New("exit status 1")
+			}
+			return &runner.Result{Stdout: []byte(trivyOutput)}, nil
+		},
+	}
+	report, err := RunTo(context.Background(), io.Discard, fake, clusterDir, cfg)
+	if err == nil || !strings.Contains(err.Error(), "1 个 HIGH 及以上级别的漏洞，1 个镜像扫描失败") {
+		t.Fatalf("Run() error = %v, expected threshold failure", err)
+	}
+	if report.Summary["HIGH"] != 1 || report.Summary["LOW"] != 1 {
+		t.Errorf("unexpected summary: %v", report.Summary)
+	}
+	if lines := fake.CommandLines(); len(lines) != 2 || lines[0] != "trivy image --format json --quiet --scanners vuln quay.io/example/app:1.0" {
+		t.Errorf("unexpected commands: %v", lines)
+	}
+
+	content, err := os.ReadFile(ReportPath(clusterDir))
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	var saved Report
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Images) != 2 || saved.Images[0].Findings[0].ID != "CVE-2024-0001" || saved.Images[1].Error == "" {
+		t.Errorf("unexpected report: %s", content)
+	}
+
+	// 未设置阈值时只生成报告
+	cfg.Scan.FailOn = ""
+	if _, err := RunTo(context.Background(), io.Discard, fake, clusterDir, cfg); err != nil {
+		t.Errorf("Run() without fail_on error = %v", err)
+	}
+
+	// context 已取消时不再执行扫描命令
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := len(fake.Calls())
+	if _, err := RunTo(ctx, io.Discard, fake, clusterDir, cfg); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() with canceled context error = %v, want context.Canceled", err)
+	}
+	if len(fake.Calls()) != calls {
+		t.Errorf("scanner ran after cancel: %v", fake.CommandLines()[calls:])
+	}
+}
+
+func TestCommandScanner(t *testing.T) {
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Stdout: []byte(trivyOutput)}, nil
+	}}
+	scanner, err := NewScanner(config.Scan{Scanner: config.ScannerCommand, Command: "./clair-scan.sh"}, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := scanner.Scan("quay.io/example/app:1.0")
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(findings) != 2 {
+		t.Errorf("expected 2 findings, got %v", findings)
+	}
+	call := fake.Calls()[0]
+	if strings.Join(call.Args, " ") != "-c ./clair-scan.sh" || call.Env[0] != "OCPACK_SCAN_IMAGE=quay.io/example/app:1.0" {
+		t.Errorf("unexpected command: %+v", call)
+	}
+}
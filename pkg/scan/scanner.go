@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+// imageScanTimeout 单个镜像的扫描超时时间，首次运行 Trivy 需要下载漏洞库
+const imageScanTimeout = 30 * time.Minute
+
+// NewScanner 根据 [scan] 配置创建扫描器，扫描命令通过 r 执行
+func NewScanner(cfg config.Scan, r runner.CommandRunner) (Scanner, error) {
+	switch cfg.GetScanner() {
+	case config.ScannerTrivy:
+		return &TrivyScanner{Runner: r}, nil
+	case config.ScannerCommand:
+		return &CommandScanner{Command: cfg.Command, Runner: r}, nil
+	default:
+		return nil, fmt.Errorf("不支持的扫描器: %s", cfg.Scanner)
+	}
+}
+
+// TrivyScanner 使用 trivy image 扫描镜像，仓库认证读取 ~/.docker/config.json
+type TrivyScanner struct {
+	Runner runner.CommandRunner // 执行 trivy，测试时可替换为 runner.Fake
+}
+
+// Name 返回扫描器名称
+func (s *TrivyScanner) Name() string {
+	return config.ScannerTrivy
+}
+
+// Scan 执行 trivy image --format json 并解析结果
+func (s *TrivyScanner) Scan(image string) ([]Finding, error) {
+	if _, err := s.Runner.LookPath("trivy"); err != nil {
+		return nil, fmt.Errorf("未找到 trivy，请先安装或改用 scanner = \"command\": %w", err)
+	}
+	result, err := s.Runner.Run(runner.Command{
+		Name:    "trivy",
+		Args:    []string{"image", "--format", "json", "--quiet", "--scanners", "vuln", image},
+		Timeout: imageScanTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("trivy 扫描失败: %w, 输出: %s", err, string(result.Stderr))
+	}
+	return parseTrivyJSON(result.Stdout)
+}
+
+// CommandScanner 执行自定义扫描命令 (如调用 Clair 的脚本)。镜像通过环境变量 OCPACK_SCAN_IMAGE 传入，
+// 命令需要在标准输出打印 Trivy 格式的 JSON 报告
+type CommandScanner struct {
+	Command string
+	Runner  runner.CommandRunner // 执行扫描命令，测试时可替换为 runner.Fake
+}
+
+// Name 返回扫描器名称
+func (s *CommandScanner) Name() string {
+	return config.ScannerCommand
+}
+
+// Scan 执行扫描命令并解析其输出
+func (s *CommandScanner) Scan(image string) ([]Finding, error) {
+	result, err := s.Runner.Run(runner.Command{
+		Name:    "/bin/sh",
+		Args:    []string{"-c", s.Command},
+		Env:     []string{"OCPACK_SCAN_IMAGE=" + image},
+		Timeout: imageScanTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描命令执行失败: %w, 输出: %s", err, string(result.Stderr))
+	}
+	return parseTrivyJSON(result.Stdout)
+}
+
+// trivyReport Trivy JSON 报告中用到的字段
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// parseTrivyJSON 解析 Trivy 格式的 JSON 报告
+func parseTrivyJSON(data []byte) ([]Finding, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析扫描结果 JSON 失败: %w", err)
+	}
+
+	var findings []Finding
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, Finding{
+				ID:               vuln.VulnerabilityID,
+				Package:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				FixedVersion:     vuln.FixedVersion,
+				Severity:         vuln.Severity,
+				Title:            vuln.Title,
+			})
+		}
+	}
+	return findings, nil
+}
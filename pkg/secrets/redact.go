@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask 替换敏感内容的占位符
+const Mask = "******"
+
+// sensitiveFlags 其值需要脱敏的命令行参数
+var sensitiveFlags = map[string]bool{
+	"--password": true,
+	"--passwd":   true,
+	"--token":    true,
+	"--creds":    true,
+}
+
+// authFieldPattern 匹配 pull-secret / auth.json 中的认证字段
+var authFieldPattern = regexp.MustCompile(`("(?:auth|password|identitytoken|registrytoken)"\s*:\s*")[^"]*(")`)
+
+// Redact 将 s 中出现的所有 values 替换为 Mask，空字符串会被忽略
+func Redact(s string, values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			s = strings.ReplaceAll(s, value, Mask)
+		}
+	}
+	return s
+}
+
+// RedactArgs 返回命令行参数的副本，其中 --password、--token 等参数的值已替换为 Mask
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		if name, _, ok := strings.Cut(arg, "="); ok && sensitiveFlags[name] {
+			redacted[i] = name + "=" + Mask
+		} else if sensitiveFlags[arg] && i+1 < len(redacted) {
+			redacted[i+1] = Mask
+		}
+	}
+	return redacted
+}
+
+// RedactAuth 隐藏文本中 pull-secret / auth.json 的 auth、password 和 token 字段，
+// 适用于打印包含 pull-secret 的配置文件内容或差异 (如 install-config.yaml)
+func RedactAuth(s string) string {
+	return authFieldPattern.ReplaceAllString(s, "${1}"+Mask+"${2}")
+}
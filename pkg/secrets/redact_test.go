@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"login", "--username", "ocp4", "--password", "s3cret", "--token=abc", "registry:8443"}
+	expected := []string{"login", "--username", "ocp4", "--password", Mask, "--token=" + Mask, "registry:8443"}
+
+	if got := RedactArgs(args); !reflect.DeepEqual(got, expected) {
+		t.Errorf("RedactArgs() = %q, expected %q", got, expected)
+	}
+	if args[4] != "s3cret" {
+		t.Error("RedactArgs() should not modify its input")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	got := Redact("sshpass -p s3cret ssh root@host", "s3cret", "")
+	if got != "sshpass -p "+Mask+" ssh root@host" {
+		t.Errorf("Redact() = %q", got)
+	}
+}
+
+func TestRedactAuth(t *testing.T) {
+	content := `pullSecret: |
+  {"auths":{"quay.io":{"auth":"dXNlcjpwYXNz","email":"me@example.com"},"registry:8443":{"auth": "b2NwNDpzM2NyZXQ="}}}
+`
+	got := RedactAuth(content)
+	for _, secret := range []string{"dXNlcjpwYXNz", "b2NwNDpzM2NyZXQ="} {
+		if strings.Contains(got, secret) {
+			t.Errorf("RedactAuth() leaked %q:\n%s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `"email":"me@example.com"`) || !strings.Contains(got, `"auth":"`+Mask+`"`) {
+		t.Errorf("RedactAuth() = %s", got)
+	}
+}
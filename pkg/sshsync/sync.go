@@ -0,0 +1,235 @@
+// Package sshsync 通过 SSH 连接将本地文件同步到远程目录：比较本地和远程文件的 SHA256，只上传内容变化的文件，
+// 多个文件并行上传并显示每个文件的进度，远程目录中本地已不存在的文件会被删除。
+// 使用内置的 SSH 客户端，密码认证时不需要 sshpass。
+package sshsync
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+)
+
+// DefaultParallel 默认同时上传的文件数
+const DefaultParallel = 4
+
+// Conn 远程主机的 SSH 连接，utils.SSHClient 实现了该接口
+type Conn interface {
+	RunCommand(command string) (string, error)
+	Stream(command string, stdin io.Reader) (string, error)
+}
+
+// Options 同步选项
+type Options struct {
+	Parallel int       // 同时上传的文件数，0 时使用 DefaultParallel
+	Progress io.Writer // 进度输出，nil 时不显示进度
+}
+
+// Result 同步结果
+type Result struct {
+	Uploaded []string // 上传的文件名
+	Skipped  []string // 内容未变化、跳过的文件名
+	Removed  []string // 从远程目录删除的文件名
+	Bytes    int64    // 上传的字节数
+}
+
+// localFile 待同步的本地文件
+type localFile struct {
+	name string
+	path string
+	size int64
+	hash string
+}
+
+// Sync 将 files 同步到远程目录 remoteDir (不存在时创建)。远程目录只保存这些文件，其中本地已不存在的文件会被删除，
+// 因此 remoteDir 应当是专用的暂存目录
+func Sync(conn Conn, files []string, remoteDir string, opts Options) (*Result, error) {
+	locals, err := hashLocalFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := remoteHashes(conn, remoteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	var pending []localFile
+	wanted := make(map[string]bool)
+	for _, file := range locals {
+		wanted[file.name] = true
+		if remote[file.name] == file.hash {
+			result.Skipped = append(result.Skipped, file.name)
+			continue
+		}
+		pending = append(pending, file)
+	}
+	for name := range remote {
+		if !wanted[name] {
+			result.Removed = append(result.Removed, name)
+		}
+	}
+	sort.Strings(result.Removed)
+	if len(result.Removed) > 0 {
+		var paths []string
+		for _, name := range result.Removed {
+			paths = append(paths, quote(path.Join(remoteDir, name)))
+		}
+		if _, err := conn.RunCommand("rm -f -- " + strings.Join(paths, " ")); err != nil {
+			return nil, fmt.Errorf("删除远程目录中的旧文件失败: %w", err)
+		}
+	}
+
+	if err := upload(conn, pending, remoteDir, opts); err != nil {
+		return nil, err
+	}
+	for _, file := range pending {
+		result.Uploaded = append(result.Uploaded, file.name)
+		result.Bytes += file.size
+	}
+	return result, nil
+}
+
+// hashLocalFiles 计算本地文件的 SHA256，文件名 (不含目录) 不能重复
+func hashLocalFiles(files []string) ([]localFile, error) {
+	var locals []localFile
+	seen := make(map[string]bool)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取本地文件失败: %w", err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		name := filepath.Base(file)
+		if seen[name] {
+			return nil, fmt.Errorf("同步的文件名重复: %s", name)
+		}
+		seen[name] = true
+		hash, err := fileSHA256(file)
+		if err != nil {
+			return nil, fmt.Errorf("计算 %s 的 SHA256 失败: %w", file, err)
+		}
+		locals = append(locals, localFile{name: name, path: file, size: info.Size(), hash: hash})
+	}
+	return locals, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteHashes 创建远程目录并返回其中文件的 SHA256，键为文件名
+func remoteHashes(conn Conn, remoteDir string) (map[string]string, error) {
+	dir := quote(remoteDir)
+	cmd := fmt.Sprintf("mkdir -p %s && cd %s && find . -maxdepth 1 -type f ! -name '.*' -exec sha256sum {} +", dir, dir)
+	out, err := conn.RunCommand(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("读取远程目录 %s 失败: %w", remoteDir, err)
+	}
+
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		hash, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+		hashes[strings.TrimPrefix(name, "./")] = hash
+	}
+	return hashes, nil
+}
+
+// upload 并行上传文件。每个文件先写入同目录下的临时文件，完成后再改名，中断时不会留下不完整的文件
+func upload(conn Conn, files []localFile, remoteDir string, opts Options) error {
+	if len(files) == 0 {
+		return nil
+	}
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+	output := opts.Progress
+	if output == nil {
+		output = io.Discard
+	}
+	progress := mpb.New(mpb.WithOutput(output), mpb.WithWidth(40))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+		sem  = make(chan struct{}, parallel)
+	)
+	for _, file := range files {
+		bar := progress.AddBar(file.size,
+			mpb.PrependDecorators(decor.Name(file.name, decor.WCSyncSpaceR)),
+			mpb.AppendDecorators(decor.CountersKibiByte("% .1f / % .1f"), decor.Percentage(decor.WCSyncSpace)),
+		)
+		wg.Add(1)
+		go func(file localFile, bar *mpb.Bar) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := uploadFile(conn, file, remoteDir, bar); err != nil {
+				bar.Abort(false)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			if file.size == 0 {
+				// 空文件没有读取进度，直接标记完成
+				bar.SetTotal(-1, true)
+			}
+		}(file, bar)
+	}
+	wg.Wait()
+	progress.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("上传 %d 个文件失败: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func uploadFile(conn Conn, file localFile, remoteDir string, bar *mpb.Bar) error {
+	f, err := os.Open(file.path)
+	if err != nil {
+		return fmt.Errorf("打开本地文件失败: %w", err)
+	}
+	defer f.Close()
+
+	target := quote(path.Join(remoteDir, file.name))
+	temp := quote(path.Join(remoteDir, "."+file.name+".part"))
+	cmd := fmt.Sprintf("cat > %s && mv -f %s %s", temp, temp, target)
+	if _, err := conn.Stream(cmd, bar.ProxyReader(f)); err != nil {
+		return fmt.Errorf("上传 %s 失败: %w", file.name, err)
+	}
+	return nil
+}
+
+// quote 返回在远程 shell 中使用的单引号字符串
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
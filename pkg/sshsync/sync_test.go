@@ -0,0 +1,157 @@
+package sshsync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConn 在内存中模拟远程目录，按命令的格式处理列目录、删除和上传
+type fakeConn struct {
+	mu       sync.Mutex
+	files    map[string][]byte // 远程路径 -> 内容
+	commands []string
+	fail     string // 上传该文件时失败
+}
+
+var (
+	uploadCommand = regexp.MustCompile(`^cat > '[^']+' && mv -f '[^']+' '([^']+)'$`)
+	quotedPath    = regexp.MustCompile(`'([^']+)'`)
+)
+
+func (c *fakeConn) RunCommand(command string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commands = append(c.commands, command)
+	switch {
+	case strings.HasPrefix(command, "mkdir -p "):
+		dir := quotedPath.FindStringSubmatch(command)[1]
+		var out strings.Builder
+		for name, content := range c.files {
+			if path.Dir(name) == dir {
+				sum := sha256.Sum256(content)
+				fmt.Fprintf(&out, "%s  ./%s\n", hex.EncodeToString(sum[:]), path.Base(name))
+			}
+		}
+		return out.String(), nil
+	case strings.HasPrefix(command, "rm -f -- "):
+		for _, match := range quotedPath.FindAllStringSubmatch(command, -1) {
+			delete(c.files, match[1])
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected command: %s", command)
+}
+
+func (c *fakeConn) Stream(command string, stdin io.Reader) (string, error) {
+	match := uploadCommand.FindStringSubmatch(command)
+	if match == nil {
+		return "", fmt.Errorf("unexpected command: %s", command)
+	}
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commands = append(c.commands, command)
+	if path.Base(match[1]) == c.fail {
+		return "", errors.New("connection reset")
+	}
+	c.files[match[1]] = content
+	return "", nil
+}
+
+func writeLocal(t *testing.T, dir string, files map[string]string) []string {
+	t.Helper()
+	var paths []string
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestSync(t *testing.T) {
+	local := t.TempDir()
+	files := writeLocal(t, local, map[string]string{
+		"agent.x86_64-rootfs.img": strings.Repeat("r", 4096),
+		"agent.x86_64.ipxe":       "#!ipxe\n",
+		"empty":                   "",
+	})
+	conn := &fakeConn{files: map[string][]byte{
+		".ocpack/upload/demo/agent.x86_64-rootfs.img": bytes.Repeat([]byte("r"), 4096),
+		".ocpack/upload/demo/agent.x86_64.ipxe":       []byte("#!ipxe\nold\n"),
+		".ocpack/upload/demo/stale.img":               []byte("stale"),
+	}}
+
+	result, err := Sync(conn, files, ".ocpack/upload/demo", Options{Parallel: 2, Progress: io.Discard})
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := []string{"agent.x86_64.ipxe", "empty"}; !reflect.DeepEqual(result.Uploaded, want) {
+		t.Errorf("Uploaded = %v, want %v", result.Uploaded, want)
+	}
+	if want := []string{"agent.x86_64-rootfs.img"}; !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, want)
+	}
+	if want := []string{"stale.img"}; !reflect.DeepEqual(result.Removed, want) {
+		t.Errorf("Removed = %v, want %v", result.Removed, want)
+	}
+	if result.Bytes != int64(len("#!ipxe\n")) {
+		t.Errorf("Bytes = %d", result.Bytes)
+	}
+	if got := string(conn.files[".ocpack/upload/demo/agent.x86_64.ipxe"]); got != "#!ipxe\n" {
+		t.Errorf("remote ipxe = %q", got)
+	}
+	if _, ok := conn.files[".ocpack/upload/demo/stale.img"]; ok {
+		t.Error("stale file should be removed")
+	}
+
+	// 再次同步时全部跳过
+	result, err = Sync(conn, files, ".ocpack/upload/demo", Options{})
+	if err != nil || len(result.Uploaded) != 0 || len(result.Skipped) != 3 {
+		t.Errorf("second Sync() = %+v, %v", result, err)
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	local := t.TempDir()
+	files := writeLocal(t, local, map[string]string{"a.img": "a", "b.img": "b"})
+
+	conn := &fakeConn{files: map[string][]byte{}, fail: "b.img"}
+	if _, err := Sync(conn, files, "/srv/upload", Options{}); err == nil || !strings.Contains(err.Error(), "b.img") {
+		t.Errorf("Sync() with failed upload error = %v", err)
+	}
+
+	other := t.TempDir()
+	duplicate := writeLocal(t, other, map[string]string{"a.img": "other"})
+	if _, err := Sync(conn, append(files, duplicate...), "/srv/upload", Options{}); err == nil {
+		t.Error("Sync() with duplicate file names should fail")
+	}
+	if _, err := Sync(conn, []string{filepath.Join(local, "missing")}, "/srv/upload", Options{}); err == nil {
+		t.Error("Sync() with missing local file should fail")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote("/srv/it's here"); got != `'/srv/it'\''s here'` {
+		t.Errorf("quote() = %s", got)
+	}
+}
@@ -0,0 +1,183 @@
+// Package storage 管理 save-image 生成的镜像归档 (mirror_*.tar) 的存储位置：本地目录或 NFS 挂载点
+// 由 oc-mirror 直接读写；S3 兼容的对象存储在保存后上传、加载前下载归档，无需手动在站点之间复制。
+// oc-mirror 只能读写本地目录，因此 S3 存储的归档总是先完整地暂存在本地，本地需要能容纳全部归档的空间。
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/i18n"
+	"ocpack/pkg/runner"
+)
+
+// archivePatterns 需要同步的文件：oc-mirror 生成的镜像归档、4.14 以下 release 的 legacy-release 目录、
+// save-image 归档的 mirror-registry 安装包，以及记录它们校验和的传输清单及其签名
+var archivePatterns = []string{"mirror_*.tar", "legacy-release/**", "mirror-registry/*", "transfer-manifest.json*"}
+
+// Backend 镜像归档的存储位置
+type Backend interface {
+	// Dir 返回 oc-mirror 读写镜像归档的本地目录
+	Dir() string
+	// Push 在 save-image 完成后将本地目录中的归档上传到存储，本地存储时无操作。ctx 取消时终止上传
+	Push(ctx context.Context) error
+	// Pull 在 load-image 之前将存储中的归档下载到本地目录，本地存储时无操作。
+	// 指定 patterns 时只下载匹配的文件 (相对于存储目录)，如 mirror-registry/*。ctx 取消时终止下载
+	Pull(ctx context.Context, patterns ...string) error
+	// String 返回便于显示的存储位置
+	String() string
+}
+
+// New 根据 [save_image.storage] 配置创建存储，同步进度输出到标准输出
+func New(clusterDir string, cfg *config.ClusterConfig) (Backend, error) {
+	return NewTo(os.Stdout, clusterDir, cfg)
+}
+
+// NewTo 与 New 相同，同步进度和 aws CLI 的输出写入 out
+func NewTo(out io.Writer, clusterDir string, cfg *config.ClusterConfig) (Backend, error) {
+	if err := config.ValidateImageStorage(cfg); err != nil {
+		return nil, clierr.New(clierr.Config, err)
+	}
+	dir := cfg.GetImagesDir(clusterDir)
+	storage := cfg.SaveImage.Storage
+	if storage.Scheme() != config.StorageSchemeS3 {
+		return &Local{dir: dir}, nil
+	}
+	parsed, err := url.Parse(storage.URL)
+	if err != nil {
+		return nil, clierr.New(clierr.Config, err)
+	}
+	return &S3{
+		Bucket:   parsed.Host,
+		Prefix:   strings.Trim(parsed.Path, "/"),
+		Settings: storage,
+		Runner:   runner.NewExecRunner(),
+		Out:      out,
+		dir:      dir,
+	}, nil
+}
+
+// Local 本地目录或 NFS 等挂载到本机的共享存储
+type Local struct {
+	dir string
+}
+
+// Dir 返回存储目录
+func (l *Local) Dir() string {
+	return l.dir
+}
+
+// Push 无操作，oc-mirror 直接写入存储目录
+func (l *Local) Push(ctx context.Context) error {
+	return nil
+}
+
+// Pull 无操作，oc-mirror 直接读取存储目录
+func (l *Local) Pull(ctx context.Context, patterns ...string) error {
+	return nil
+}
+
+func (l *Local) String() string {
+	return "file://" + l.dir
+}
+
+// S3 S3 兼容的对象存储，通过 aws s3 sync 上传和下载镜像归档，分段上传和断点续传由 aws CLI 处理。
+// 归档在本地暂存目录 (集群目录下的 images) 中完整保存，不会边生成边上传
+type S3 struct {
+	Bucket   string
+	Prefix   string
+	Settings config.ImageStorage
+	Runner   runner.CommandRunner // 执行 aws 命令，测试时可替换为 runner.Fake
+	Out      io.Writer            // 接收同步进度和 aws CLI 的输出，为 nil 时丢弃
+
+	dir string
+}
+
+// Dir 返回本地暂存目录
+func (s *S3) Dir() string {
+	return s.dir
+}
+
+// Push 将暂存目录中的镜像归档同步到对象存储
+func (s *S3) Push(ctx context.Context) error {
+	return s.sync(ctx, s.dir, s.String(), archivePatterns)
+}
+
+// Pull 将对象存储中的镜像归档同步到暂存目录
+func (s *S3) Pull(ctx context.Context, patterns ...string) error {
+	if len(patterns) == 0 {
+		patterns = archivePatterns
+	}
+	return s.sync(ctx, s.String(), s.dir, patterns)
+}
+
+func (s *S3) String() string {
+	if s.Prefix == "" {
+		return "s3://" + s.Bucket
+	}
+	return fmt.Sprintf("s3://%s/%s", s.Bucket, s.Prefix)
+}
+
+// sync 执行 aws s3 sync，只同步匹配 patterns 的文件，不包括 oc-mirror 的缓存和工作目录。
+// 失败时按 aws CLI 的输出区分认证失败、存储桶不存在和网络错误
+func (s *S3) sync(ctx context.Context, source, destination string, patterns []string) error {
+	if _, err := s.Runner.LookPath("aws"); err != nil {
+		return clierr.New(clierr.Prereq, i18n.Errorf("未找到 aws CLI，使用 s3:// 存储需要先安装: %w", err))
+	}
+	args := []string{"s3", "sync", source, destination, "--exclude", "*"}
+	for _, pattern := range patterns {
+		args = append(args, "--include", pattern)
+	}
+	args = append(args, "--no-progress")
+	if s.Settings.Endpoint != "" {
+		args = append(args, "--endpoint-url", s.Settings.Endpoint)
+	}
+	if s.Settings.Region != "" {
+		args = append(args, "--region", s.Settings.Region)
+	}
+	if s.Settings.InsecureSkipVerify {
+		args = append(args, "--no-verify-ssl")
+	}
+	out := s.Out
+	if out == nil {
+		out = io.Discard
+	}
+	var output bytes.Buffer
+	cmd := runner.Command{Name: "aws", Args: args, Stream: true, Output: io.MultiWriter(out, &output)}
+	if s.Settings.AccessKey != "" {
+		cmd.Env = []string{
+			"AWS_ACCESS_KEY_ID=" + s.Settings.AccessKey,
+			"AWS_SECRET_ACCESS_KEY=" + s.Settings.SecretKey,
+		}
+		cmd.Secrets = []string{s.Settings.SecretKey}
+	}
+
+	i18n.Fprintf(out, "☁️  同步镜像归档: %s -> %s\n", source, destination)
+	if _, err := runner.WithContext(ctx, s.Runner).Run(cmd); err != nil {
+		return clierr.New(syncCategory(output.String()), i18n.Errorf("同步镜像归档失败 (%s): %w", cmd, err))
+	}
+	return nil
+}
+
+// syncCategory 根据 aws CLI 的输出返回同步失败的类别：凭据无效或拒绝访问为 Auth，
+// 存储桶不存在为 Config，其余 (连接失败、超时等) 为 Network
+func syncCategory(output string) clierr.Category {
+	for _, code := range []string{"AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "Unable to locate credentials", "(403)"} {
+		if strings.Contains(output, code) {
+			return clierr.Auth
+		}
+	}
+	for _, code := range []string{"NoSuchBucket", "InvalidBucketName"} {
+		if strings.Contains(output, code) {
+			return clierr.Config
+		}
+	}
+	return clierr.Network
+}
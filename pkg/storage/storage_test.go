@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func TestNewLocal(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	backend, err := New("/work/demo", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.Dir() != filepath.Join("/work/demo", "images") || backend.String() != "file:///work/demo/images" {
+		t.Errorf("unexpected default backend: %s", backend)
+	}
+
+	cfg.SaveImage.Storage.URL = "file:///mnt/nfs/ocp"
+	if backend, err = New("/work/demo", cfg); err != nil {
+		t.Fatal(err)
+	}
+	if backend.Dir() != "/mnt/nfs/ocp" || backend.Push(context.Background()) != nil || backend.Pull(context.Background()) != nil {
+		t.Errorf("unexpected nfs backend: %s", backend)
+	}
+
+	cfg.SaveImage.Storage.URL = "ftp://host/images"
+	if _, err := New("/work/demo", cfg); clierr.CategoryOf(err) != clierr.Config {
+		t.Errorf("expected config error, got %v", err)
+	}
+}
+
+func TestS3Sync(t *testing.T) {
+	fake := &runner.Fake{Paths: map[string]string{"aws": "/usr/bin/aws"}}
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.Storage = config.ImageStorage{
+		URL:                "s3://ocp-mirror/sites/demo/",
+		Endpoint:           "https://minio.example.com:9000",
+		AccessKey:          "AKIA",
+		SecretKey:          "s3cr3t",
+		InsecureSkipVerify: true,
+	}
+	var out strings.Builder
+	backend, err := NewTo(&out, "/work/demo", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.String() != "s3://ocp-mirror/sites/demo" || backend.Dir() != filepath.Join("/work/demo", "images") {
+		t.Fatalf("unexpected s3 backend: %s (%s)", backend, backend.Dir())
+	}
+	backend.(*S3).Runner = fake
+	ctx := context.Background()
+	if err := backend.Push(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Pull(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Pull(ctx, "mirror-registry/*"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := fake.CommandLines()
+	want := []string{
+		"aws s3 sync /work/demo/images s3://ocp-mirror/sites/demo --exclude * --include mirror_*.tar --include legacy-release/** --include mirror-registry/* --include transfer-manifest.json* --no-progress --endpoint-url https://minio.example.com:9000 --no-verify-ssl",
+		"aws s3 sync s3://ocp-mirror/sites/demo /work/demo/images --exclude * --include mirror_*.tar --include legacy-release/** --include mirror-registry/* --include transfer-manifest.json* --no-progress --endpoint-url https://minio.example.com:9000 --no-verify-ssl",
+		"aws s3 sync s3://ocp-mirror/sites/demo /work/demo/images --exclude * --include mirror-registry/* --no-progress --endpoint-url https://minio.example.com:9000 --no-verify-ssl",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected commands:\n%s", strings.Join(lines, "\n"))
+	}
+	env := fake.Calls()[0].Env
+	if len(env) != 2 || env[0] != "AWS_ACCESS_KEY_ID=AKIA" || env[1] != "AWS_SECRET_ACCESS_KEY=s3cr3t" {
+		t.Errorf("unexpected env: %v", env)
+	}
+	if !strings.Contains(out.String(), "同步镜像归档: /work/demo/images -> s3://ocp-mirror/sites/demo") {
+		t.Errorf("sync progress not written to out: %q", out.String())
+	}
+}
+
+func TestS3SyncErrors(t *testing.T) {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.SaveImage.Storage.URL = "s3://ocp-mirror"
+	backend, err := New("/work/demo", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s3 := backend.(*S3)
+	s3.Runner = &runner.Fake{}
+	if err := backend.Push(ctx); clierr.CategoryOf(err) != clierr.Prereq {
+		t.Errorf("expected prereq error without aws CLI, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		output string
+		want   clierr.Category
+	}{
+		{"fatal error: Could not connect to the endpoint URL: \"https://minio.example.com:9000/ocp-mirror\"", clierr.Network},
+		{"fatal error: An error occurred (InvalidAccessKeyId) when calling the ListObjectsV2 operation", clierr.Auth},
+		{"fatal error: An error occurred (AccessDenied) when calling the ListObjectsV2 operation: Access Denied", clierr.Auth},
+		{"fatal error: An error occurred (NoSuchBucket) when calling the ListObjectsV2 operation", clierr.Config},
+	} {
+		s3.Runner = &runner.Fake{
+			Paths: map[string]string{"aws": "/usr/bin/aws"},
+			Handler: func(cmd runner.Command) (*runner.Result, error) {
+				fmt.Fprintln(cmd.Output, tt.output)
+				return nil, errors.New("exit status 1")
+			},
+		}
+		if err := backend.Pull(ctx); clierr.CategoryOf(err) != tt.want {
+			t.Errorf("%s: category = %s, want %s", tt.output, clierr.CategoryOf(err), tt.want)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	fake := &runner.Fake{Paths: map[string]string{"aws": "/usr/bin/aws"}}
+	s3.Runner = fake
+	if err := backend.Push(canceled); clierr.CategoryOf(err) != clierr.Canceled || len(fake.Calls()) != 0 {
+		t.Errorf("expected canceled push without running aws, got %v", err)
+	}
+}
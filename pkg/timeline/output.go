@@ -0,0 +1,84 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats 支持的输出格式
+var Formats = []string{"text", "json"}
+
+// Write 按指定格式输出时间线。verbose 为 false 时文本格式只列出阶段切换、警告和错误以及 Operator 事件
+func Write(w io.Writer, tl *Timeline, format string, verbose bool) error {
+	switch format {
+	case "text":
+		return writeText(w, tl, verbose)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tl)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s，可选 %s", format, strings.Join(Formats, "、"))
+	}
+}
+
+func writeText(w io.Writer, tl *Timeline, verbose bool) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "集群 %s 安装时间线\n", tl.Cluster)
+	for _, note := range tl.Notes {
+		fmt.Fprintf(&b, "说明: %s\n", note)
+	}
+
+	if len(tl.Phases) > 0 {
+		b.WriteString("\n阶段耗时:\n")
+		for _, phase := range tl.Phases {
+			suffix := ""
+			if phase.Running {
+				suffix = " (进行中)"
+			}
+			fmt.Fprintf(&b, "  %-24s %s  %10s%s\n", phase.Name, phase.Start.Local().Format("2006-01-02 15:04:05"), formatDuration(phase.Duration), suffix)
+		}
+		status := "未完成"
+		if tl.Complete {
+			status = "已完成"
+		}
+		fmt.Fprintf(&b, "  总耗时 %s (%s)\n", formatDuration(tl.Total), status)
+	}
+
+	if len(tl.Events) > 0 {
+		b.WriteString("\n事件:\n")
+		start := tl.Events[0].Time
+		if len(tl.Phases) > 0 {
+			start = tl.Phases[0].Start
+		}
+		for _, event := range tl.Events {
+			if !verbose && !isKeyEvent(event) {
+				continue
+			}
+			offset := "+" + formatDuration(event.Time.Sub(start))
+			if event.Time.Before(start) {
+				offset = "-" + formatDuration(start.Sub(event.Time))
+			}
+			message := event.Message
+			if event.Phase != "" {
+				message = fmt.Sprintf("[%s] %s", event.Phase, message)
+			}
+			fmt.Fprintf(&b, "  %s %9s  %-15s %-7s %s\n", event.Time.Local().Format("15:04:05"), offset, event.Source, event.Level, message)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// isKeyEvent 阶段切换、警告和错误以及 Operator 事件始终显示
+func isKeyEvent(event Event) bool {
+	return event.Phase != "" || event.Source != SourceInstaller || (event.Level != "info" && event.Level != "")
+}
+
+// formatDuration 将耗时格式化为 1h2m3s，忽略秒以下的部分
+func formatDuration(d time.Duration) string {
+	return d.Truncate(time.Second).String()
+}
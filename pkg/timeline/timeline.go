@@ -0,0 +1,291 @@
+// Package timeline 生成集群安装时间线：合并 openshift-install 日志 (包括 agent wait-for 追加的输出)
+// 和安装完成后 ClusterOperator 的状态变化，按时间排序并统计每个安装阶段的耗时，用于定位安装慢在哪里。
+package timeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+// InstallLogFilename openshift-install 在安装目录中追加写入的日志。agent wait-for 使用相同的 --dir 时
+// 也写入该文件，因此 generate-iso 复制到 installation/ignition 的日志包含生成 ISO 和等待安装的全部过程
+const InstallLogFilename = ".openshift_install.log"
+
+// 事件来源
+const (
+	SourceInstaller       = "installer"
+	SourceClusterOperator = "clusteroperator"
+)
+
+// Event 时间线中的一条记录
+type Event struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Phase   string    `json:"phase,omitempty"` // 该事件开始的阶段
+}
+
+// Phase 安装阶段及耗时
+type Phase struct {
+	Name     string        `json:"name"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Running  bool          `json:"running,omitempty"` // 安装尚未完成，阶段以最后一条事件计时
+}
+
+// Timeline 集群安装时间线
+type Timeline struct {
+	Cluster  string        `json:"cluster"`
+	Phases   []Phase       `json:"phases"`
+	Total    time.Duration `json:"total"`
+	Complete bool          `json:"complete"`
+	Events   []Event       `json:"events"`
+	Notes    []string      `json:"notes,omitempty"` // 日志缺失、获取 Operator 状态失败等说明
+}
+
+// phaseMarker 日志中出现任一消息时进入对应阶段。阶段只会向后推进，
+// 因此重复执行 wait-for 产生的旧消息不会让时间线回退
+type phaseMarker struct {
+	name     string
+	messages []string
+}
+
+// phaseComplete 安装完成阶段，只作为最后一个阶段的结束时间
+const phaseComplete = "安装完成"
+
+var phaseMarkers = []phaseMarker{
+	{"引导主机 (ISO 已生成)", []string{"Generated ISO at", "Created iPXE script", "Generated PXE"}},
+	{"主机发现与验证", []string{"Waiting for cluster install to initialize", "Cluster is not ready for install", "Successfully registered"}},
+	{"安装准备", []string{"Cluster validation: All hosts in the cluster are ready to install", "Preparing cluster for installation"}},
+	{"写入磁盘", []string{"Cluster installation in progress", "Writing image to disk"}},
+	{"Bootstrap", []string{"Bootstrap Kube API Initialized", "Bootstrap configMap status is complete"}},
+	{"集群初始化", []string{"cluster bootstrap is complete", "Bootstrap is complete", "for the cluster at"}},
+	{phaseComplete, []string{"Install complete!", "Cluster is installed"}},
+}
+
+// Build 读取安装目录中的日志和 extraLogs 生成时间线。discover 为 true 时通过集群 kubeconfig
+// 获取 ClusterOperator 的状态变化 (oc 命令通过 r 执行)，集群未安装或无法访问时只记录说明，不返回错误
+func Build(r runner.CommandRunner, cluster, clusterDir string, extraLogs []string, discover bool) (*Timeline, error) {
+	tl := &Timeline{Cluster: cluster}
+
+	logs := append([]string{filepath.Join(clusterDir, "installation", "ignition", InstallLogFilename)}, extraLogs...)
+	for i, path := range logs {
+		events, err := readLog(path)
+		if os.IsNotExist(err) && i == 0 {
+			tl.Notes = append(tl.Notes, fmt.Sprintf("未找到安装日志 %s，请先运行 generate-iso", path))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tl.Events = append(tl.Events, events...)
+	}
+
+	if discover {
+		events, err := clusterOperatorEvents(r, clusterDir)
+		if err != nil {
+			tl.Notes = append(tl.Notes, err.Error())
+		}
+		tl.Events = append(tl.Events, events...)
+	}
+
+	tl.build()
+	return tl, nil
+}
+
+func readLog(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("读取日志 %s 失败: %w", path, err)
+	}
+	defer f.Close()
+	events, err := ParseInstallLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("解析日志 %s 失败: %w", path, err)
+	}
+	return events, nil
+}
+
+var (
+	timeField  = regexp.MustCompile(`time="([^"]+)"`)
+	levelField = regexp.MustCompile(`level=(\w+)`)
+	msgField   = regexp.MustCompile(`msg=("(?:[^"\\]|\\.)*")`)
+)
+
+// ParseInstallLog 解析 openshift-install 的 logfmt 日志 (time="..." level=info msg="...")，
+// 只保留 info 及以上级别的事件。没有时间戳的行 (如终端输出) 会被忽略
+func ParseInstallLog(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		timeMatch := timeField.FindStringSubmatch(line)
+		msgMatch := msgField.FindStringSubmatch(line)
+		if timeMatch == nil || msgMatch == nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, timeMatch[1])
+		if err != nil {
+			continue
+		}
+		level := "info"
+		if m := levelField.FindStringSubmatch(line); m != nil {
+			level = m[1]
+		}
+		if level == "debug" || level == "trace" {
+			continue
+		}
+		message, err := strconv.Unquote(msgMatch[1])
+		if err != nil {
+			message = strings.Trim(msgMatch[1], `"`)
+		}
+		events = append(events, Event{Time: t, Source: SourceInstaller, Level: level, Message: message})
+	}
+	return events, scanner.Err()
+}
+
+// clusterOperatorList oc get clusteroperators -o json 中用到的字段
+type clusterOperatorList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []struct {
+				Type               string    `json:"type"`
+				Status             string    `json:"status"`
+				LastTransitionTime time.Time `json:"lastTransitionTime"`
+				Message            string    `json:"message"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// clusterOperatorEvents 将每个 ClusterOperator 变为 Available 的时间，以及当前 Degraded 的状态转为事件
+func clusterOperatorEvents(r runner.CommandRunner, clusterDir string) ([]Event, error) {
+	kubeconfigPath, err := kubeconfig.Find(clusterDir)
+	if err != nil {
+		return nil, fmt.Errorf("未找到集群 kubeconfig，未获取 ClusterOperator 状态")
+	}
+
+	result, err := r.Run(runner.Command{
+		Name:    "oc",
+		Args:    []string{"get", "clusteroperators", "-o", "json"},
+		Env:     []string{"KUBECONFIG=" + kubeconfigPath},
+		Timeout: runner.DefaultTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取 ClusterOperator 失败，未获取 Operator 状态: %v", err)
+	}
+
+	var operators clusterOperatorList
+	if err := json.Unmarshal(result.Stdout, &operators); err != nil {
+		return nil, fmt.Errorf("解析 oc get clusteroperators 输出失败: %w", err)
+	}
+
+	var events []Event
+	for _, item := range operators.Items {
+		for _, condition := range item.Status.Conditions {
+			if condition.Status != "True" || condition.LastTransitionTime.IsZero() {
+				continue
+			}
+			switch condition.Type {
+			case "Available":
+				events = append(events, Event{
+					Time:    condition.LastTransitionTime,
+					Source:  SourceClusterOperator,
+					Level:   "info",
+					Message: fmt.Sprintf("%s Available", item.Metadata.Name),
+				})
+			case "Degraded":
+				events = append(events, Event{
+					Time:    condition.LastTransitionTime,
+					Source:  SourceClusterOperator,
+					Level:   "warning",
+					Message: fmt.Sprintf("%s Degraded: %s", item.Metadata.Name, condition.Message),
+				})
+			}
+		}
+	}
+	return events, nil
+}
+
+// build 对事件排序并计算各阶段耗时
+func (tl *Timeline) build() {
+	sort.SliceStable(tl.Events, func(i, j int) bool { return tl.Events[i].Time.Before(tl.Events[j].Time) })
+	if len(tl.Events) == 0 {
+		return
+	}
+
+	current := -1
+	var completeAt time.Time
+	for i, event := range tl.Events {
+		if event.Source != SourceInstaller {
+			continue
+		}
+		for j := current + 1; j < len(phaseMarkers); j++ {
+			if !matchesAny(event.Message, phaseMarkers[j].messages) {
+				continue
+			}
+			current = j
+			tl.Events[i].Phase = phaseMarkers[j].name
+			if phaseMarkers[j].name == phaseComplete {
+				completeAt = event.Time
+			} else {
+				tl.Phases = append(tl.Phases, Phase{Name: phaseMarkers[j].name, Start: event.Time})
+			}
+			break
+		}
+	}
+
+	tl.Complete = !completeAt.IsZero()
+	end := completeAt
+	if !tl.Complete {
+		// 安装未完成时以最后一条安装日志计时，Operator 状态变化可能远晚于安装过程
+		for _, event := range tl.Events {
+			if event.Source == SourceInstaller {
+				end = event.Time
+			}
+		}
+	}
+	for i := range tl.Phases {
+		phaseEnd := end
+		if i+1 < len(tl.Phases) {
+			phaseEnd = tl.Phases[i+1].Start
+		} else {
+			tl.Phases[i].Running = !tl.Complete
+		}
+		tl.Phases[i].End = phaseEnd
+		tl.Phases[i].Duration = phaseEnd.Sub(tl.Phases[i].Start)
+	}
+	if len(tl.Phases) > 0 {
+		tl.Total = end.Sub(tl.Phases[0].Start)
+	}
+}
+
+func matchesAny(message string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
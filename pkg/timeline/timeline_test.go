@@ -0,0 +1,152 @@
+package timeline
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/kubeconfig"
+	"ocpack/pkg/runner"
+)
+
+const installLog = `time="2024-05-01T10:00:00Z" level=debug msg="OpenShift Installer 4.16.0"
+time="2024-05-01T10:02:00Z" level=info msg="Generated ISO at /tmp/agent.x86_64.iso"
+time="2024-05-01T10:20:00Z" level=info msg="Waiting for cluster install to initialize. Sleeping for 30 seconds"
+time="2024-05-01T10:25:00Z" level=warning msg="Host master-0: validation \"ntp-synced\" failed"
+time="2024-05-01T10:30:00Z" level=info msg="Preparing cluster for installation"
+time="2024-05-01T10:32:00Z" level=info msg="Cluster installation in progress"
+time="2024-05-01T10:50:00Z" level=info msg="Bootstrap Kube API Initialized"
+time="2024-05-01T11:10:00Z" level=info msg="cluster bootstrap is complete"
+time="2024-05-01T11:40:00Z" level=info msg="Install complete!"
+INFO output without timestamp
+`
+
+const operatorsJSON = `{"items": [
+  {"metadata": {"name": "etcd"},
+   "status": {"conditions": [{"type": "Available", "status": "True", "lastTransitionTime": "2024-05-01T11:05:00Z"}]}},
+  {"metadata": {"name": "ingress"},
+   "status": {"conditions": [{"type": "Available", "status": "True", "lastTransitionTime": "2024-05-01T11:35:00Z"},
+                             {"type": "Degraded", "status": "False", "lastTransitionTime": "2024-05-01T11:35:00Z"}]}}
+]}`
+
+func writeInstallLog(t *testing.T, clusterDir, content string) {
+	t.Helper()
+	path := filepath.Join(clusterDir, "installation", "ignition", InstallLogFilename)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseInstallLog(t *testing.T) {
+	events, err := ParseInstallLog(strings.NewReader(installLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 8 {
+		t.Fatalf("expected 8 events without debug and untimed lines, got %d", len(events))
+	}
+	if events[2].Level != "warning" || events[2].Message != `Host master-0: validation "ntp-synced" failed` {
+		t.Errorf("unexpected event: %+v", events[2])
+	}
+}
+
+func TestBuildPhases(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeInstallLog(t, clusterDir, installLog)
+
+	tl, err := Build(&runner.Fake{}, "demo", clusterDir, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tl.Complete || tl.Total != 98*time.Minute {
+		t.Errorf("complete = %v, total = %s", tl.Complete, tl.Total)
+	}
+
+	durations := make(map[string]time.Duration)
+	var names []string
+	for _, phase := range tl.Phases {
+		names = append(names, phase.Name)
+		durations[phase.Name] = phase.Duration
+	}
+	if len(names) != 6 {
+		t.Fatalf("unexpected phases: %v", names)
+	}
+	if durations["写入磁盘"] != 18*time.Minute || durations["集群初始化"] != 30*time.Minute {
+		t.Errorf("unexpected durations: %v", durations)
+	}
+}
+
+func TestBuildRunning(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeInstallLog(t, clusterDir, strings.Join(strings.Split(installLog, "\n")[:6], "\n"))
+
+	tl, err := Build(&runner.Fake{}, "demo", clusterDir, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := tl.Phases[len(tl.Phases)-1]
+	if tl.Complete || !last.Running || last.Name != "写入磁盘" || last.Duration != 0 {
+		t.Errorf("unexpected running state: complete = %v, last = %+v", tl.Complete, last)
+	}
+}
+
+func TestBuildWithClusterOperators(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeInstallLog(t, clusterDir, installLog)
+	kubeconfigPath := kubeconfig.DefaultPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &runner.Fake{Handler: func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Stdout: []byte(operatorsJSON)}, nil
+	}}
+
+	tl, err := Build(fake, "demo", clusterDir, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(fake.CommandLines(), ";"); got != "oc get clusteroperators -o json" {
+		t.Errorf("unexpected oc calls: %s", got)
+	}
+
+	var operators []string
+	for _, event := range tl.Events {
+		if event.Source == SourceClusterOperator {
+			operators = append(operators, event.Message)
+		}
+	}
+	if strings.Join(operators, ",") != "etcd Available,ingress Available" {
+		t.Errorf("unexpected operator events: %v", operators)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, tl, "text", false); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"总耗时 1h38m0s (已完成)", "etcd Available", "ntp-synced"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBuildWithoutLog(t *testing.T) {
+	tl, err := Build(&runner.Fake{}, "demo", t.TempDir(), nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tl.Notes) != 1 || len(tl.Phases) != 0 {
+		t.Errorf("expected a note about missing log, got %+v", tl)
+	}
+}
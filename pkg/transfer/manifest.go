@@ -0,0 +1,333 @@
+// Package transfer 生成和验证镜像归档的传输清单。save-image 在镜像目录中写入 transfer-manifest.json，
+// 记录每个镜像归档 (mirror_*.tar 和 4.14 以下 release 的 legacy-release 目录) 和 mirror-registry 安装包的大小和 sha256，可选使用 gpg 或 cosign 签名；
+// load-image 在导入前验证签名和校验和，发现摆渡传输中损坏或被篡改的归档。
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+const (
+	// ManifestFile 镜像目录中的传输清单
+	ManifestFile = "transfer-manifest.json"
+	// SignatureFile 传输清单的分离签名
+	SignatureFile = ManifestFile + ".sig"
+
+	manifestVersion = 1
+)
+
+var (
+	// archivePatterns 镜像归档：oc-mirror 生成的 mirror_*.tar 和 oc adm release mirror 写入的 legacy-release 目录
+	archivePatterns = []string{"mirror_*.tar", "legacy-release/**"}
+	// filePatterns 清单覆盖的文件，与 storage 同步的归档一致。以 /** 结尾的模式包括目录中的全部文件
+	filePatterns = append(slices.Clone(archivePatterns), "mirror-registry/*")
+)
+
+// ErrNoManifest 镜像目录中没有传输清单，如旧版本 save-image 生成的归档
+var ErrNoManifest = errors.New("未找到传输清单")
+
+// Manifest 传输清单
+type Manifest struct {
+	Version          int       `json:"version"`
+	Cluster          string    `json:"cluster"`
+	OpenShiftVersion string    `json:"openshift_version"`
+	Tool             string    `json:"tool,omitempty"`   // 生成清单的 ocpack 版本
+	Groups           []string  `json:"groups,omitempty"` // save-image --only 保存的分组，为空时为全部分组
+	Created          time.Time `json:"created"`
+	Files            []File    `json:"files"`
+}
+
+// File 清单中的一个文件
+type File struct {
+	Name   string `json:"name"` // 相对于镜像目录的路径
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Write 计算 dir 中镜像归档的校验和并写入传输清单，配置了签名时同时生成分离签名。
+// manifest 提供集群、版本等元数据，签名命令通过 r 执行，返回清单路径。
+// ctx 被取消或超时时停止计算校验和并终止正在执行的签名命令
+func Write(ctx context.Context, r runner.CommandRunner, dir string, manifest Manifest, signing config.TransferSigning) (string, error) {
+	names, err := listFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("%s 中没有镜像归档", dir)
+	}
+
+	manifest.Version = manifestVersion
+	manifest.Files = nil
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return "", context.Cause(ctx)
+		}
+		file, err := describe(dir, name)
+		if err != nil {
+			return "", err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, ManifestFile)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("写入传输清单失败: %w", err)
+	}
+	// 旧的签名不再对应新的清单
+	if err := os.Remove(filepath.Join(dir, SignatureFile)); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if signing.Method != "" {
+		if err := sign(runner.WithContext(ctx, r), dir, signing); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// Verify 验证 dir 中的传输清单：配置了签名时先验证签名，再检查清单中每个文件的大小和 sha256，
+// 以及目录中没有清单之外的镜像归档。签名验证命令通过 r 执行，没有清单时返回 ErrNoManifest。
+// ctx 被取消或超时时停止校验并终止正在执行的签名验证命令
+func Verify(ctx context.Context, r runner.CommandRunner, dir string, signing config.TransferSigning) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+	if signing.Method != "" {
+		if err := verifySignature(runner.WithContext(ctx, r), dir, signing); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, clierr.New(clierr.Prereq, fmt.Errorf("解析传输清单 %s 失败: %w", path, err))
+	}
+	if manifest.Version != manifestVersion {
+		return nil, clierr.New(clierr.Prereq, fmt.Errorf("不支持的传输清单版本 %d", manifest.Version))
+	}
+
+	var problems []error
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, expected := range manifest.Files {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		listed[expected.Name] = true
+		actual, err := describe(dir, expected.Name)
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, fmt.Errorf("%s 不存在", expected.Name))
+		case err != nil:
+			problems = append(problems, err)
+		case actual.Size != expected.Size:
+			problems = append(problems, fmt.Errorf("%s 的大小为 %d 字节，清单中为 %d 字节，传输可能未完成", expected.Name, actual.Size, expected.Size))
+		case actual.SHA256 != expected.SHA256:
+			problems = append(problems, fmt.Errorf("%s 的 sha256 与清单不一致，文件已损坏或被修改", expected.Name))
+		}
+	}
+	names, err := listFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !listed[name] {
+			problems = append(problems, fmt.Errorf("%s 不在传输清单中", name))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, clierr.New(clierr.Prereq, fmt.Errorf("镜像归档校验失败:\n%w", errors.Join(problems...)))
+	}
+	return &manifest, nil
+}
+
+// ArchiveSize 返回 dir 中镜像归档 (mirror_*.tar 和 legacy-release 目录) 的总大小，用于估算 Registry 需要的存储空间。
+// 有传输清单时使用清单记录的大小 (归档可能还在传输中)，否则统计目录中的归档，没有归档时返回 0
+func ArchiveSize(dir string) (int64, error) {
+	isArchive := func(name string) bool {
+		for _, pattern := range archivePatterns {
+			if match(pattern, name) {
+				return true
+			}
+		}
+		return false
+	}
+	var total int64
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	switch {
+	case err == nil:
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return 0, fmt.Errorf("解析传输清单 %s 失败: %w", filepath.Join(dir, ManifestFile), err)
+		}
+		for _, file := range manifest.Files {
+			if isArchive(file.Name) {
+				total += file.Size
+			}
+		}
+		return total, nil
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+
+	names, err := listFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range names {
+		if !isArchive(name) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return 0, err
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// match 判断相对于镜像目录的路径 name 是否匹配 filePatterns 中的 pattern
+func match(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "**"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
+
+// listFiles 返回 dir 中清单覆盖的文件，路径相对于 dir
+func listFiles(dir string) ([]string, error) {
+	var names []string
+	for _, pattern := range filePatterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			err := filepath.WalkDir(filepath.Join(dir, prefix), func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Type().IsRegular() {
+					rel, _ := filepath.Rel(dir, path)
+					names = append(names, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			rel, _ := filepath.Rel(dir, match)
+			names = append(names, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(names)
+	return slices.Compact(names), nil
+}
+
+// describe 返回文件的大小和 sha256
+func describe(dir, name string) (File, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, fmt.Errorf("计算 %s 的校验和失败: %w", name, err)
+	}
+	return File{Name: name, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// sign 使用 gpg 或 cosign 为传输清单生成分离签名
+func sign(r runner.CommandRunner, dir string, signing config.TransferSigning) error {
+	manifest, signature := filepath.Join(dir, ManifestFile), filepath.Join(dir, SignatureFile)
+	var cmd runner.Command
+	switch signing.Method {
+	case config.SigningGPG:
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--output", signature}
+		if signing.Key != "" {
+			args = append(args, "--local-user", signing.Key)
+		}
+		cmd = runner.Command{Name: "gpg", Args: append(args, manifest)}
+	case config.SigningCosign:
+		if signing.Key == "" {
+			return clierr.New(clierr.Config, fmt.Errorf("使用 cosign 签名需要设置 save_image.signing.key"))
+		}
+		// 离线环境无法访问透明日志，签名不上传到 Rekor，私钥密码通过 COSIGN_PASSWORD 环境变量提供
+		cmd = runner.Command{Name: "cosign", Args: []string{"sign-blob", "--yes", "--tlog-upload=false", "--key", signing.Key, "--output-signature", signature, manifest}}
+	default:
+		return clierr.New(clierr.Config, fmt.Errorf("不支持的签名方式 %q", signing.Method))
+	}
+	if _, err := r.LookPath(cmd.Name); err != nil {
+		return clierr.New(clierr.Prereq, fmt.Errorf("未找到 %s，无法为传输清单签名: %w", cmd.Name, err))
+	}
+	if result, err := r.Run(cmd); err != nil {
+		return fmt.Errorf("传输清单签名失败 (%s): %w\n%s", cmd, err, result.Combined)
+	}
+	return nil
+}
+
+// verifySignature 验证传输清单的分离签名
+func verifySignature(r runner.CommandRunner, dir string, signing config.TransferSigning) error {
+	manifest, signature := filepath.Join(dir, ManifestFile), filepath.Join(dir, SignatureFile)
+	if _, err := os.Stat(signature); err != nil {
+		return clierr.New(clierr.Prereq, fmt.Errorf("已配置 save_image.signing，但未找到传输清单的签名 %s", signature))
+	}
+	var cmd runner.Command
+	switch signing.Method {
+	case config.SigningGPG:
+		cmd = runner.Command{Name: "gpg", Args: []string{"--batch", "--verify", signature, manifest}}
+		if signing.PublicKey != "" {
+			// gpgv 只信任指定的公钥，不依赖本机密钥环中的其他密钥
+			cmd = runner.Command{Name: "gpgv", Args: []string{"--keyring", signing.PublicKey, signature, manifest}}
+		}
+	case config.SigningCosign:
+		if signing.PublicKey == "" {
+			return clierr.New(clierr.Config, fmt.Errorf("使用 cosign 验证签名需要设置 save_image.signing.public_key"))
+		}
+		cmd = runner.Command{Name: "cosign", Args: []string{"verify-blob", "--insecure-ignore-tlog=true", "--key", signing.PublicKey, "--signature", signature, manifest}}
+	default:
+		return clierr.New(clierr.Config, fmt.Errorf("不支持的签名方式 %q", signing.Method))
+	}
+	if _, err := r.LookPath(cmd.Name); err != nil {
+		return clierr.New(clierr.Prereq, fmt.Errorf("未找到 %s，无法验证传输清单的签名: %w", cmd.Name, err))
+	}
+	if result, err := r.Run(cmd); err != nil {
+		return clierr.New(clierr.Prereq, fmt.Errorf("传输清单的签名验证失败，镜像归档可能被篡改 (%s): %w\n%s", cmd, err, result.Combined))
+	}
+	return nil
+}
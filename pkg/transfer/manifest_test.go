@@ -0,0 +1,205 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/clierr"
+	"ocpack/pkg/config"
+	"ocpack/pkg/runner"
+)
+
+func writeArchives(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"mirror_000001.tar":                            "release",
+		"mirror_000002.tar":                            "operators",
+		"mirror-registry/mirror-registry-amd64.tar.gz": "bundle",
+		"working-dir/logs/oc-mirror.log":               "not covered",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWriteAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Verify(context.Background(), &runner.Fake{}, dir, config.TransferSigning{}); !errors.Is(err, ErrNoManifest) {
+		t.Fatalf("expected ErrNoManifest, got %v", err)
+	}
+
+	writeArchives(t, dir)
+	meta := Manifest{Cluster: "demo", OpenShiftVersion: "4.16.3", Created: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Write(canceled, &runner.Fake{}, dir, meta, config.TransferSigning{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Write() with a canceled context error = %v, want context.Canceled", err)
+	}
+	if _, err := Write(context.Background(), &runner.Fake{}, dir, meta, config.TransferSigning{}); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := Verify(context.Background(), &runner.Fake{}, dir, config.TransferSigning{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+	}
+	if got := strings.Join(names, ","); got != "mirror-registry/mirror-registry-amd64.tar.gz,mirror_000001.tar,mirror_000002.tar" {
+		t.Errorf("unexpected manifest files: %s", got)
+	}
+	if manifest.Cluster != "demo" || manifest.Files[1].Size != int64(len("release")) {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+
+	// a modified, a truncated and an unlisted archive are all reported
+	if err := os.WriteFile(filepath.Join(dir, "mirror_000001.tar"), []byte("RELEASE"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mirror_000002.tar"), []byte("op"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mirror_000003.tar"), []byte("extra"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Verify(context.Background(), &runner.Fake{}, dir, config.TransferSigning{})
+	if clierr.CategoryOf(err) != clierr.Prereq {
+		t.Fatalf("expected prereq error, got %v", err)
+	}
+	for _, want := range []string{"mirror_000001.tar 的 sha256", "mirror_000002.tar 的大小", "mirror_000003.tar 不在传输清单中"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+}
+
+func TestArchiveSize(t *testing.T) {
+	dir := t.TempDir()
+	if size, err := ArchiveSize(dir); err != nil || size != 0 {
+		t.Fatalf("ArchiveSize() = %d, %v for an empty directory", size, err)
+	}
+	writeArchives(t, dir)
+	want := int64(len("release") + len("operators"))
+	if size, err := ArchiveSize(dir); err != nil || size != want {
+		t.Errorf("ArchiveSize() = %d, %v, want %d", size, err, want)
+	}
+
+	// with a manifest the recorded sizes count, even for archives still in transit
+	if _, err := Write(context.Background(), &runner.Fake{}, dir, Manifest{Cluster: "demo"}, config.TransferSigning{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "mirror_000002.tar")); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := ArchiveSize(dir); err != nil || size != want {
+		t.Errorf("ArchiveSize() = %d, %v from the manifest, want %d", size, err, want)
+	}
+}
+
+func TestLegacyRelease(t *testing.T) {
+	dir := t.TempDir()
+	// a release older than 4.14 written by oc adm release mirror --to-dir
+	files := map[string]string{
+		"legacy-release/v2/openshift/release/blobs/sha256/aaaa":        "layer",
+		"legacy-release/v2/openshift/release/manifests/4.12.30-x86_64": "manifest",
+		"legacy-release/config/signature-sha256-bbbb.json":             "signature",
+		"mirror_000001.tar": "operators",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := int64(len("layer") + len("manifest") + len("signature") + len("operators"))
+	if size, err := ArchiveSize(dir); err != nil || size != want {
+		t.Errorf("ArchiveSize() = %d, %v, want %d", size, err, want)
+	}
+
+	if _, err := Write(context.Background(), &runner.Fake{}, dir, Manifest{Cluster: "demo", OpenShiftVersion: "4.12.30"}, config.TransferSigning{}); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := Verify(context.Background(), &runner.Fake{}, dir, config.TransferSigning{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(manifest.Files) != len(files) {
+		t.Errorf("manifest covers %d files, want %d: %+v", len(manifest.Files), len(files), manifest.Files)
+	}
+	if size, err := ArchiveSize(dir); err != nil || size != want {
+		t.Errorf("ArchiveSize() = %d, %v from the manifest, want %d", size, err, want)
+	}
+
+	// a corrupted blob in the release tree is reported
+	blob := filepath.Join(dir, "legacy-release/v2/openshift/release/blobs/sha256/aaaa")
+	if err := os.WriteFile(blob, []byte("LAYER"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(context.Background(), &runner.Fake{}, dir, config.TransferSigning{}); err == nil || !strings.Contains(err.Error(), "legacy-release/v2/openshift/release/blobs/sha256/aaaa") {
+		t.Errorf("Verify() error = %v, want the corrupted blob reported", err)
+	}
+}
+
+func TestSigning(t *testing.T) {
+	fake := &runner.Fake{Paths: map[string]string{"gpg": "/usr/bin/gpg", "gpgv": "/usr/bin/gpgv", "cosign": "/usr/bin/cosign"}}
+	fake.Handler = func(cmd runner.Command) (*runner.Result, error) {
+		// the signing commands write the signature next to the manifest
+		for i, arg := range cmd.Args {
+			if arg == "--output" || arg == "--output-signature" {
+				return nil, os.WriteFile(cmd.Args[i+1], []byte("signature"), 0644)
+			}
+		}
+		return nil, nil
+	}
+
+	dir := t.TempDir()
+	writeArchives(t, dir)
+	gpg := config.TransferSigning{Method: config.SigningGPG, Key: "ocpack@example.com", PublicKey: "/etc/ocpack/transfer.gpg"}
+	if _, err := Write(context.Background(), fake, dir, Manifest{Cluster: "demo"}, gpg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(context.Background(), fake, dir, gpg); err != nil {
+		t.Fatal(err)
+	}
+	cosign := config.TransferSigning{Method: config.SigningCosign, Key: "cosign.key", PublicKey: "cosign.pub"}
+	if _, err := Write(context.Background(), fake, dir, Manifest{Cluster: "demo"}, cosign); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(context.Background(), fake, dir, cosign); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, signature := filepath.Join(dir, ManifestFile), filepath.Join(dir, SignatureFile)
+	want := []string{
+		"gpg --batch --yes --armor --detach-sign --output " + signature + " --local-user ocpack@example.com " + manifest,
+		"gpgv --keyring /etc/ocpack/transfer.gpg " + signature + " " + manifest,
+		"cosign sign-blob --yes --tlog-upload=false --key cosign.key --output-signature " + signature + " " + manifest,
+		"cosign verify-blob --insecure-ignore-tlog=true --key cosign.pub --signature " + signature + " " + manifest,
+	}
+	if got := fake.CommandLines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected commands:\n%s", strings.Join(got, "\n"))
+	}
+
+	// a manifest written without signing cannot pass a signed verification
+	if _, err := Write(context.Background(), fake, dir, Manifest{Cluster: "demo"}, config.TransferSigning{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(context.Background(), fake, dir, gpg); err == nil || !strings.Contains(err.Error(), "未找到传输清单的签名") {
+		t.Errorf("expected missing signature error, got %v", err)
+	}
+}
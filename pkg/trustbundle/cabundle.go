@@ -0,0 +1,94 @@
+package trustbundle
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"ocpack/pkg/config"
+)
+
+// CA 证书目录的位置，containers/image 读取目录中的 *.crt 作为额外信任的 CA
+const (
+	caBundleDirName  = "ca-bundle.d"
+	caBundleFilename = "ca.crt"
+)
+
+// LoadCABundle 读取 infra.ca_bundle 中的证书，这些证书只用于 ocpack 访问外部服务 (下载工具、查询升级图和
+// 从上游仓库复制镜像)，不加入集群的 additionalTrustBundle。未配置时返回空的 Bundle
+func LoadCABundle(cfg *config.ClusterConfig, clusterDir string) (*Bundle, error) {
+	b := &Bundle{}
+	path := cfg.GetCABundlePath(clusterDir)
+	if path == "" {
+		return b, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 infra.ca_bundle 中的 %s 失败: %w", path, err)
+	}
+	if err := b.add(path, data); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// LoadMirrorBundle 返回复制镜像时额外信任的全部 CA：Load 读取的私有仓库 CA 和 trust_bundle_paths、infra.ca_bundle
+// 以及 save_image.registry_tls 中各仓库的 ca_file，去除重复的证书
+func LoadMirrorBundle(cfg *config.ClusterConfig, clusterDir string) (*Bundle, error) {
+	b, err := Load(cfg, clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	caBundle, err := LoadCABundle(cfg, clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	if !caBundle.Empty() {
+		if err := b.add(caBundle.Sources[0], caBundle.PEM()); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range cfg.GetRegistryCAFiles(clusterDir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 save_image.registry_tls 中的 %s 失败: %w", path, err)
+		}
+		if err := b.add(path, data); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// CertDir 返回 WriteCertDir 写入证书的目录
+func CertDir(clusterDir string) string {
+	return filepath.Join(clusterDir, registryDirName, caBundleDirName)
+}
+
+// WriteCertDir 将证书写入 CertDir 中的 ca.crt，供 skopeo 和 oc-mirror 的 --cert-dir 使用，
+// 没有证书时不写入并返回空路径
+func (b *Bundle) WriteCertDir(clusterDir string) (string, error) {
+	if b.Empty() {
+		return "", nil
+	}
+	dir := CertDir(clusterDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, caBundleFilename), b.PEM(), 0644); err != nil {
+		return "", fmt.Errorf("写入 %s 失败: %w", caBundleFilename, err)
+	}
+	return dir, nil
+}
+
+// HTTPClient 返回信任系统 CA 和这些证书的 HTTP 客户端，代理使用 HTTPS_PROXY 等环境变量
+func (b *Bundle) HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: b.CertPool(), MinVersion: tls.VersionTLS12},
+		},
+	}
+}
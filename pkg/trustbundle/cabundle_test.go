@@ -0,0 +1,77 @@
+package trustbundle
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCABundle(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := testConfig()
+	// 私有仓库 CA 只用于集群，不属于 ca_bundle
+	writeFile(t, RegistryCAPaths(cfg, clusterDir)[0], newCA(t, "registry", time.Now().Add(time.Hour)))
+
+	bundle, err := LoadCABundle(cfg, clusterDir)
+	if err != nil || !bundle.Empty() {
+		t.Fatalf("LoadCABundle() without ca_bundle = %+v, %v", bundle, err)
+	}
+	if dir, err := bundle.WriteCertDir(clusterDir); err != nil || dir != "" {
+		t.Errorf("WriteCertDir() without certificates = %q, %v", dir, err)
+	}
+
+	proxyCA := newCA(t, "proxy", time.Now().Add(time.Hour))
+	writeFile(t, filepath.Join(clusterDir, "certs", "proxy-ca.pem"), proxyCA)
+	cfg.Infra.CABundle = "certs/proxy-ca.pem"
+	bundle, err = LoadCABundle(cfg, clusterDir)
+	if err != nil {
+		t.Fatalf("LoadCABundle() error = %v", err)
+	}
+	if len(bundle.Certificates) != 1 || bundle.Certificates[0].Subject.CommonName != "proxy" {
+		t.Errorf("LoadCABundle() certificates = %v", bundle.Certificates)
+	}
+
+	dir, err := bundle.WriteCertDir(clusterDir)
+	if err != nil || dir != CertDir(clusterDir) {
+		t.Fatalf("WriteCertDir() = %q, %v", dir, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil || !bytes.Equal(data, proxyCA) {
+		t.Errorf("ca.crt = %q, %v", data, err)
+	}
+
+	cfg.Infra.CABundle = "certs/missing.pem"
+	if _, err := LoadCABundle(cfg, clusterDir); err == nil {
+		t.Error("LoadCABundle() with missing file should fail")
+	}
+}
+
+func TestCABundleHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	clusterDir := t.TempDir()
+	cfg := testConfig()
+	cfg.Infra.CABundle = filepath.Join(clusterDir, "proxy-ca.pem")
+	writeFile(t, cfg.Infra.CABundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	bundle, err := LoadCABundle(cfg, clusterDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := bundle.HTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET with ca_bundle error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp, err := (&Bundle{}).HTTPClient().Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("GET without ca_bundle should fail certificate verification")
+	}
+}
@@ -0,0 +1,161 @@
+// Package trustbundle 合并集群需要信任的 CA 证书：私有仓库的 rootCA.pem 和 [infra] trust_bundle_paths
+// 中配置的证书 (如企业代理的 CA)。合并后的证书同时用于 install-config.yaml 的 additionalTrustBundle、
+// 查询升级图的 HTTP 客户端和私有仓库访问，保证各处信任的 CA 一致。
+// [infra] ca_bundle 中的证书由 LoadCABundle 单独读取，只用于 ocpack 访问外部服务，不加入集群。
+package trustbundle
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ocpack/pkg/config"
+)
+
+// 私有仓库 CA 证书和合并后证书文件的位置
+const (
+	registryDirName    = "registry"
+	rootCACertFilename = "rootCA.pem"
+	MergedFilename     = "trust-bundle.pem"
+)
+
+// Bundle 合并后的 CA 证书
+type Bundle struct {
+	Certificates []*x509.Certificate
+	Sources      []string // 读取了证书的文件，按读取顺序
+	Warnings     []string // 已过期或尚未生效的证书等不影响使用的问题
+}
+
+// RegistryCAPaths 返回私有仓库 CA 证书可能的位置，deploy-registry 按 Registry IP 保存
+func RegistryCAPaths(cfg *config.ClusterConfig, clusterDir string) []string {
+	registryHost := cfg.RegistryHostname()
+	return []string{
+		filepath.Join(clusterDir, registryDirName, cfg.Registry.IP, rootCACertFilename),
+		filepath.Join(clusterDir, registryDirName, registryHost, rootCACertFilename),
+		filepath.Join(clusterDir, registryDirName, rootCACertFilename),
+	}
+}
+
+// MergedPath 返回合并后证书文件的路径
+func MergedPath(clusterDir string) string {
+	return filepath.Join(clusterDir, registryDirName, MergedFilename)
+}
+
+// Load 读取私有仓库 CA (存在时) 和 trust_bundle_paths 中的全部证书，去除重复的证书。
+// trust_bundle_paths 中的相对路径相对于集群目录，文件不存在或不包含有效证书时返回错误
+func Load(cfg *config.ClusterConfig, clusterDir string) (*Bundle, error) {
+	b := &Bundle{}
+	for _, path := range RegistryCAPaths(cfg, clusterDir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := b.add(path, data); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	for _, path := range cfg.Infra.TrustBundlePaths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(clusterDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 infra.trust_bundle_paths 中的 %s 失败: %w", path, err)
+		}
+		if err := b.add(path, data); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// add 解析 data 中的 PEM 证书并加入合并结果，忽略私钥等非证书块
+func (b *Bundle) add(path string, data []byte) error {
+	now := time.Now()
+	found := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("%s 中的第 %d 个证书无效: %w", path, found+1, err)
+		}
+		found++
+		if b.contains(cert) {
+			continue
+		}
+		if now.After(cert.NotAfter) {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("%s 中的证书 %s 已于 %s 过期", path, cert.Subject, cert.NotAfter.Format("2006-01-02")))
+		} else if now.Before(cert.NotBefore) {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("%s 中的证书 %s 在 %s 之前无效", path, cert.Subject, cert.NotBefore.Format("2006-01-02")))
+		}
+		b.Certificates = append(b.Certificates, cert)
+	}
+	if found == 0 {
+		return fmt.Errorf("%s 中没有 PEM 格式的证书", path)
+	}
+	b.Sources = append(b.Sources, path)
+	return nil
+}
+
+func (b *Bundle) contains(cert *x509.Certificate) bool {
+	for _, existing := range b.Certificates {
+		if bytes.Equal(existing.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty 返回是否没有任何证书
+func (b *Bundle) Empty() bool {
+	return len(b.Certificates) == 0
+}
+
+// PEM 返回合并后的 PEM 文本，用于 additionalTrustBundle
+func (b *Bundle) PEM() []byte {
+	var buf bytes.Buffer
+	for _, cert := range b.Certificates {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// CertPool 返回系统信任的 CA 加上合并证书组成的证书池，系统证书池不可用时只包含合并证书
+func (b *Bundle) CertPool() *x509.CertPool {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for _, cert := range b.Certificates {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// Write 将合并后的证书写入 MergedPath，供需要证书文件的工具使用，没有证书时不写入并返回空路径
+func (b *Bundle) Write(clusterDir string) (string, error) {
+	if b.Empty() {
+		return "", nil
+	}
+	path := MergedPath(clusterDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, b.PEM(), 0644); err != nil {
+		return "", fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,136 @@
+package trustbundle
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+)
+
+// newCA 生成自签名 CA 证书的 PEM
+func newCA(t *testing.T, name string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testConfig() *config.ClusterConfig {
+	cfg := config.NewDefaultConfig("demo")
+	cfg.Registry.IP = "192.168.1.11"
+	return cfg
+}
+
+func TestLoad(t *testing.T) {
+	clusterDir := t.TempDir()
+	cfg := testConfig()
+	registryCA := newCA(t, "registry", time.Now().Add(24*time.Hour))
+	proxyCA := newCA(t, "proxy", time.Now().Add(24*time.Hour))
+	expiredCA := newCA(t, "expired", time.Now().Add(-24*time.Hour))
+
+	writeFile(t, RegistryCAPaths(cfg, clusterDir)[0], registryCA)
+	// 代理 CA 文件中重复包含私有仓库 CA，并带有非证书块
+	keyBlock := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("ignored")})
+	writeFile(t, filepath.Join(clusterDir, "certs", "proxy.pem"), bytes.Join([][]byte{proxyCA, registryCA, keyBlock}, nil))
+	absPath := filepath.Join(t.TempDir(), "expired.pem")
+	writeFile(t, absPath, expiredCA)
+	cfg.Infra.TrustBundlePaths = []string{"certs/proxy.pem", absPath}
+
+	bundle, err := Load(cfg, clusterDir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, cert := range bundle.Certificates {
+		names = append(names, cert.Subject.CommonName)
+	}
+	if strings.Join(names, ",") != "registry,proxy,expired" {
+		t.Errorf("certificates = %v, expected registry CA first and duplicates removed", names)
+	}
+	if len(bundle.Sources) != 3 {
+		t.Errorf("Sources = %v", bundle.Sources)
+	}
+	if len(bundle.Warnings) != 1 || !strings.Contains(bundle.Warnings[0], "expired") {
+		t.Errorf("Warnings = %v, expected expired certificate warning", bundle.Warnings)
+	}
+
+	pemData := bundle.PEM()
+	if strings.Count(string(pemData), "BEGIN CERTIFICATE") != 3 || strings.Contains(string(pemData), "PRIVATE KEY") {
+		t.Errorf("PEM() = %s", pemData)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(pemData) {
+		t.Error("PEM() is not a valid certificate bundle")
+	}
+
+	path, err := bundle.Write(clusterDir)
+	if err != nil || path != MergedPath(clusterDir) {
+		t.Fatalf("Write() = %s, %v", path, err)
+	}
+	if written, _ := os.ReadFile(path); !bytes.Equal(written, pemData) {
+		t.Error("written bundle differs from PEM()")
+	}
+}
+
+func TestLoadWithoutCertificates(t *testing.T) {
+	clusterDir := t.TempDir()
+	bundle, err := Load(testConfig(), clusterDir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !bundle.Empty() || len(bundle.PEM()) != 0 {
+		t.Errorf("expected empty bundle, got %d certificates", len(bundle.Certificates))
+	}
+	if path, err := bundle.Write(clusterDir); err != nil || path != "" {
+		t.Errorf("Write() = %q, %v, expected nothing written", path, err)
+	}
+}
+
+func TestLoadInvalidPaths(t *testing.T) {
+	clusterDir := t.TempDir()
+	writeFile(t, filepath.Join(clusterDir, "not-pem.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(clusterDir, "broken.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("broken")}))
+
+	for _, path := range []string{"missing.pem", "not-pem.txt", "broken.pem"} {
+		cfg := testConfig()
+		cfg.Infra.TrustBundlePaths = []string{path}
+		if _, err := Load(cfg, clusterDir); err == nil {
+			t.Errorf("Load with %s: expected error", path)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ReadFileIfExists 读取文件内容，文件不存在时返回 nil
+func ReadFileIfExists(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// UnifiedDiff 生成文件修改前后内容的 unified diff，内容相同时返回空字符串
+func UnifiedDiff(name string, before, after []byte) (string, error) {
+	if string(before) == string(after) {
+		return "", nil
+	}
+
+	fromFile := name
+	if before == nil {
+		fromFile = "/dev/null"
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: fromFile,
+		ToFile:   name,
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return "", fmt.Errorf("生成 %s 的 diff 失败: %w", name, err)
+	}
+	return text, nil
+}
@@ -98,7 +98,13 @@ func ExtractTarGz(tarPath, destDir string, targetFiles []string) error {
 	}
 	defer file.Close()
 
-	gzr, err := gzip.NewReader(file)
+	return ExtractTarGzReader(file, destDir, targetFiles)
+}
+
+// ExtractTarGzReader 从tar.gz数据流中提取指定的文件，用于边下载边提取。
+// 提取后读完剩余数据，使gzip校验和得到验证
+func ExtractTarGzReader(r io.Reader, destDir string, targetFiles []string) error {
+	gzr, err := gzip.NewReader(r)
 	if err != nil {
 		return fmt.Errorf("创建gzip读取器失败: %w", err)
 	}
@@ -155,6 +161,9 @@ func ExtractTarGz(tarPath, destDir string, targetFiles []string) error {
 		}
 	}
 
+	if _, err := io.Copy(io.Discard, gzr); err != nil {
+		return fmt.Errorf("读取tar文件失败: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLocked TryLockFile 的锁已被其他进程持有
+var ErrLocked = errors.New("文件锁已被其他进程持有")
+
+// LockFile 获取文件排他锁，用于多个 ocpack 进程共用同一目录 (如共享下载目录) 时串行化写操作
+// 锁已被其他进程持有时先调用 onWait (可为 nil)，再阻塞等待；返回的函数用于释放锁
+func LockFile(path string, onWait func()) (func() error, error) {
+	f, locked, err := openLock(path)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		if onWait != nil {
+			onWait()
+		}
+		if err := lock(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("等待文件锁失败: %w", err)
+		}
+	}
+	return releaseFunc(f), nil
+}
+
+// TryLockFile 与 LockFile 相同，但不等待：锁已被其他进程持有时返回 ErrLocked。
+// 锁随文件描述符释放，持有进程退出 (包括被 kill -9) 时由操作系统自动释放
+func TryLockFile(path string) (func() error, error) {
+	f, locked, err := openLock(path)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		f.Close()
+		return nil, ErrLocked
+	}
+	return releaseFunc(f), nil
+}
+
+// openLock 打开锁文件并尝试不等待地加锁
+func openLock(path string) (*os.File, bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, false, fmt.Errorf("打开锁文件失败: %w", err)
+	}
+	locked, err := tryLock(f)
+	if err != nil {
+		f.Close()
+		return nil, false, fmt.Errorf("获取文件锁失败: %w", err)
+	}
+	return f, locked, nil
+}
+
+func releaseFunc(f *os.File) func() error {
+	return func() error {
+		if err := unlock(f); err != nil {
+			f.Close()
+			return fmt.Errorf("释放文件锁失败: %w", err)
+		}
+		return f.Close()
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package utils
+
+import "os"
+
+// Windows 上 ocpack 不执行部署和下载，文件锁退化为空操作
+
+func tryLock(f *os.File) (bool, error) { return true, nil }
+
+func lock(f *os.File) error { return nil }
+
+func unlock(f *os.File) error { return nil }
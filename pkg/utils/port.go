@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+)
+
+// PortAvailable 检查本机 localhost 上的 TCP 端口是否可以监听
+func PortAvailable(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// FindAvailablePort 从 start 开始依次检查 count 个端口，返回第一个可以监听的端口
+func FindAvailablePort(start, count int) (int, error) {
+	for port := start; port < start+count && port <= 65535; port++ {
+		if PortAvailable(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("端口 %d-%d 均已被占用", start, start+count-1)
+}
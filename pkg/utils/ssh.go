@@ -84,6 +84,24 @@ func (s *SSHClient) RunCommand(command string) (string, error) {
 	return stdout.String(), nil
 }
 
+// Stream 执行命令并将 stdin 的内容写入命令的标准输入，返回命令的输出。用于不经过临时文件上传大文件
+func (s *SSHClient) Stream(command string, stdin io.Reader) (string, error) {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("创建SSH会话失败: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(command); err != nil {
+		return "", fmt.Errorf("执行命令失败: %v, 错误: %s", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
 // UploadFile 上传文件到远程服务器
 func (s *SSHClient) UploadFile(localPath, remotePath string) error {
 	// 打开本地文件
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// TemplateOverrideDirName 集群目录下存放用户自定义模板的目录名
+const TemplateOverrideDirName = "templates"
+
+// ReadTemplate 读取模板内容，<clusterDir>/templates/ 下存在同名文件时优先使用用户自定义模板，
+// 否则回退到内置模板。返回的 source 为自定义模板路径，使用内置模板时为空字符串
+func ReadTemplate(clusterDir string, embedded fs.FS, templatePath string) (content []byte, source string, err error) {
+	if clusterDir != "" {
+		overridePath := filepath.Join(clusterDir, TemplateOverrideDirName, path.Base(templatePath))
+		content, err := ReadFileIfExists(overridePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("读取自定义模板 %s 失败: %w", overridePath, err)
+		}
+		if content != nil {
+			return content, overridePath, nil
+		}
+	}
+
+	content, err = fs.ReadFile(embedded, templatePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取模板 %s 失败: %w", templatePath, err)
+	}
+	return content, "", nil
+}
+
+// DumpTemplates 将内置模板导出到 dir 以便用户修改，已存在的文件仅在 force 为 true 时覆盖。
+// 返回实际写入的文件路径
+func DumpTemplates(embedded fs.FS, templatePaths []string, dir string, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建模板目录 %s 失败: %w", dir, err)
+	}
+
+	var written []string
+	for _, templatePath := range templatePaths {
+		content, err := fs.ReadFile(embedded, templatePath)
+		if err != nil {
+			return written, fmt.Errorf("读取模板 %s 失败: %w", templatePath, err)
+		}
+
+		dst := filepath.Join(dir, path.Base(templatePath))
+		if !force {
+			if _, err := os.Stat(dst); err == nil {
+				continue
+			}
+		}
+		if err := os.WriteFile(dst, content, 0644); err != nil {
+			return written, fmt.Errorf("写入模板 %s 失败: %w", dst, err)
+		}
+		written = append(written, dst)
+	}
+	return written, nil
+}
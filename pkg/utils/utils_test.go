@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"testing/fstest"
 )
 
 func TestCompareVersion(t *testing.T) {
@@ -203,3 +209,128 @@ func TestSupportsOcMirror(t *testing.T) {
 		}
 	}
 }
+
+func TestReadTemplate(t *testing.T) {
+	embedded := fstest.MapFS{
+		"templates/install-config.yaml": {Data: []byte("embedded")},
+	}
+	clusterDir := t.TempDir()
+
+	content, source, err := ReadTemplate(clusterDir, embedded, "templates/install-config.yaml")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+	if string(content) != "embedded" || source != "" {
+		t.Errorf("ReadTemplate() = %q, %q, expected embedded template", content, source)
+	}
+
+	overrideDir := filepath.Join(clusterDir, TemplateOverrideDirName)
+	if _, err := DumpTemplates(embedded, []string{"templates/install-config.yaml"}, overrideDir, false); err != nil {
+		t.Fatalf("DumpTemplates() error = %v", err)
+	}
+	overridePath := filepath.Join(overrideDir, "install-config.yaml")
+	if err := os.WriteFile(overridePath, []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, source, err = ReadTemplate(clusterDir, embedded, "templates/install-config.yaml")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+	if string(content) != "custom" || source != overridePath {
+		t.Errorf("ReadTemplate() = %q, %q, expected override %s", content, source, overridePath)
+	}
+
+	// 未指定 force 时不覆盖用户修改过的模板
+	written, err := DumpTemplates(embedded, []string{"templates/install-config.yaml"}, overrideDir, false)
+	if err != nil {
+		t.Fatalf("DumpTemplates() error = %v", err)
+	}
+	if len(written) != 0 {
+		t.Errorf("DumpTemplates() wrote %v, expected existing template to be kept", written)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上文件锁为空操作")
+	}
+	path := filepath.Join(t.TempDir(), ".ocpack.lock")
+
+	unlock, err := LockFile(path, nil)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+
+	waited := make(chan struct{})
+	acquired := make(chan error, 1)
+	go func() {
+		unlock2, err := LockFile(path, func() { close(waited) })
+		if err == nil {
+			err = unlock2()
+		}
+		acquired <- err
+	}()
+
+	// 第二次加锁需等待第一次释放
+	<-waited
+	select {
+	case <-acquired:
+		t.Fatal("LockFile() acquired lock held by another holder")
+	default:
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	if err := <-acquired; err != nil {
+		t.Fatalf("LockFile() after release error = %v", err)
+	}
+}
+
+func TestTryLockFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上文件锁为空操作")
+	}
+	path := filepath.Join(t.TempDir(), ".ocpack.lock")
+
+	unlock, err := TryLockFile(path)
+	if err != nil {
+		t.Fatalf("TryLockFile() error = %v", err)
+	}
+	// flock 按打开的文件描述区分持有者，同一进程再次打开也会冲突
+	if _, err := TryLockFile(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("TryLockFile() on held lock error = %v, want ErrLocked", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	unlock, err = TryLockFile(path)
+	if err != nil {
+		t.Fatalf("TryLockFile() after release error = %v", err)
+	}
+	unlock()
+}
+
+func TestFindAvailablePort(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Skipf("无法监听本地端口: %v", err)
+	}
+	defer listener.Close()
+	busy := listener.Addr().(*net.TCPAddr).Port
+
+	if PortAvailable(busy) {
+		t.Errorf("PortAvailable(%d) = true，端口已被占用", busy)
+	}
+	port, err := FindAvailablePort(busy, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port == busy || port >= busy+20 {
+		t.Errorf("FindAvailablePort(%d) = %d", busy, port)
+	}
+	if _, err := FindAvailablePort(busy, 1); err == nil {
+		t.Error("全部端口被占用时应返回错误")
+	}
+}
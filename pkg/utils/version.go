@@ -199,6 +199,15 @@ func SupportsOcMirror(version string) bool {
 	return CompareVersion(version, "4.14.0") >= 0
 }
 
+// MinLegacyMirrorVersion 支持的最低 OpenShift 版本。4.14 以下的 release 不使用 oc-mirror，
+// 而是通过 oc adm release mirror 镜像
+const MinLegacyMirrorVersion = "4.10.0"
+
+// IsLegacyMirrorVersion 检查版本是否需要通过 oc adm release mirror 镜像 release (4.10 至 4.13)
+func IsLegacyMirrorVersion(version string) bool {
+	return !SupportsOcMirror(version) && CompareVersion(version, MinLegacyMirrorVersion) >= 0
+}
+
 // ParseTimestamp 解析时间戳字符串为 int64
 func ParseTimestamp(timestamp string) (int64, error) {
 	// 尝试解析为整数时间戳
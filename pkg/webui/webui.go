@@ -0,0 +1,336 @@
+// Package webui 实现 ocpack ui 的本地 Web 面板，供多人共用跳板机时查看集群进度而不必翻阅终端输出：
+// 阶段报告中各阶段的执行结果、batch worker 写入的镜像同步进度 (progress.json)、集群目录的磁盘占用、
+// 日志以及生成的 ISO、PXE 文件和镜像归档的下载链接。页面定时请求 /api/status 刷新。
+package webui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ocpack/pkg/mirror/batch"
+	"ocpack/pkg/report"
+	"ocpack/pkg/runner"
+	"ocpack/pkg/timeline"
+	"ocpack/pkg/workspace"
+)
+
+//go:embed static/index.html
+var static embed.FS
+
+// DefaultAddr 默认只监听本机，需要其他主机访问时通过 --listen 指定
+const DefaultAddr = "127.0.0.1:8088"
+
+// Pipeline 面板中按顺序显示的部署阶段，阶段报告中的其他阶段 (如 day2) 排在其后
+var Pipeline = []string{"download", "save_image", "deploy_bastion", "deploy_registry", "load_image", "generate_iso"}
+
+// artifactPatterns 可以下载的产物，相对于集群目录
+var artifactPatterns = []string{
+	"installation/iso/*",
+	"pxe/files/*",
+	"images/mirror_*.tar",
+}
+
+// maxLogBytes 日志接口返回的最大字节数，超出时只返回末尾部分
+const maxLogBytes = 256 * 1024
+
+// usageTTL 目录占用的缓存时间，镜像目录可能有数百 GB，不在每次刷新时遍历
+const usageTTL = time.Minute
+
+// Stage 流水线中的一个阶段，Run 为最近一次执行的记录，尚未执行时为 nil
+type Stage struct {
+	Name string           `json:"name"`
+	Run  *report.StageRun `json:"run,omitempty"`
+}
+
+// File 日志或产物文件，Path 相对于集群目录
+type File struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// DirUsage 集群目录下一个子目录的占用
+type DirUsage struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Disk 集群目录的磁盘占用和所在分区的剩余空间，df 不可用时 Available 为 -1
+type Disk struct {
+	Dirs      []DirUsage `json:"dirs"`
+	Available int64      `json:"available"`
+	Updated   time.Time  `json:"updated"`
+}
+
+// Status /api/status 返回的面板数据
+type Status struct {
+	Cluster   string             `json:"cluster"`
+	Stages    []Stage            `json:"stages"`
+	Mirror    *batch.RunProgress `json:"mirror,omitempty"`
+	Disk      *Disk              `json:"disk"`
+	Logs      []File             `json:"logs"`
+	Artifacts []File             `json:"artifacts"`
+	Time      time.Time          `json:"time"`
+}
+
+// Server 单个集群的 Web 面板
+type Server struct {
+	ClusterName string
+	ClusterDir  string
+	Runner      runner.CommandRunner // 执行 df 命令，测试时可替换为 runner.Fake
+
+	mu   sync.Mutex
+	disk *Disk
+}
+
+// NewServer 创建集群目录 clusterDir 的 Web 面板
+func NewServer(clusterName, clusterDir string) *Server {
+	return &Server{ClusterName: clusterName, ClusterDir: clusterDir, Runner: runner.NewExecRunner()}
+}
+
+// Handler 返回面板的 HTTP 处理器
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/log", s.handleLog)
+	mux.HandleFunc("GET /artifacts", s.handleArtifact)
+	return mux
+}
+
+// ListenAndServe 在 addr 上提供面板，ctx 取消时关闭服务
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", addr, err)
+	}
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Status 汇总面板数据
+func (s *Server) Status() (*Status, error) {
+	r, err := report.Load(s.ClusterDir)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.logFiles()
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := s.artifactFiles()
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		Cluster:   s.ClusterName,
+		Stages:    pipelineStages(r),
+		Mirror:    batch.LatestProgress(workspace.LogsDirs(s.ClusterDir)),
+		Disk:      s.diskUsage(),
+		Logs:      logs,
+		Artifacts: artifacts,
+		Time:      time.Now(),
+	}, nil
+}
+
+// pipelineStages 按 Pipeline 的顺序列出阶段，阶段报告中的其他阶段按执行时间排在后面
+func pipelineStages(r *report.Report) []Stage {
+	runs := make(map[string]*report.StageRun)
+	for i := range r.Stages {
+		runs[r.Stages[i].Stage] = &r.Stages[i]
+	}
+	var stages []Stage
+	for _, name := range Pipeline {
+		stages = append(stages, Stage{Name: name, Run: runs[name]})
+		delete(runs, name)
+	}
+	for i := range r.Stages {
+		if run, ok := runs[r.Stages[i].Stage]; ok {
+			stages = append(stages, Stage{Name: run.Stage, Run: run})
+		}
+	}
+	return stages
+}
+
+// diskUsage 返回集群目录下各子目录的占用，结果缓存 usageTTL
+func (s *Server) diskUsage() *Disk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.disk != nil && time.Since(s.disk.Updated) < usageTTL {
+		return s.disk
+	}
+
+	disk := &Disk{Available: availableBytes(s.Runner, s.ClusterDir), Updated: time.Now()}
+	entries, _ := os.ReadDir(s.ClusterDir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			disk.Dirs = append(disk.Dirs, DirUsage{Name: entry.Name(), Size: workspace.DiskUsage(filepath.Join(s.ClusterDir, entry.Name()))})
+		}
+	}
+	sort.Slice(disk.Dirs, func(i, j int) bool { return disk.Dirs[i].Size > disk.Dirs[j].Size })
+	s.disk = disk
+	return disk
+}
+
+// availableBytes 使用 df 返回 dir 所在分区的剩余空间，df 不可用时返回 -1
+func availableBytes(r runner.CommandRunner, dir string) int64 {
+	if _, err := r.LookPath("df"); err != nil {
+		return -1
+	}
+	result, err := r.Run(runner.Command{Name: "df", Args: []string{"-Pk", dir}, Timeout: runner.DefaultTimeout})
+	if err != nil {
+		return -1
+	}
+	// df -P 的输出第二行为: 文件系统 总容量 已用 可用 使用率 挂载点
+	lines := strings.Split(strings.TrimSpace(string(result.Stdout)), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 6 {
+		return -1
+	}
+	availableKB, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return availableKB * 1024
+}
+
+// logFiles 返回 oc-mirror 的日志和错误列表以及 openshift-install 的日志，按修改时间从新到旧排序
+func (s *Server) logFiles() ([]File, error) {
+	var paths []string
+	for _, dir := range workspace.LogsDirs(s.ClusterDir) {
+		for _, pattern := range []string{"*.log", "mirroring_errors_*.txt"} {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, matches...)
+		}
+	}
+	paths = append(paths, filepath.Join(s.ClusterDir, "installation", "ignition", timeline.InstallLogFilename))
+	return s.files(paths), nil
+}
+
+// artifactFiles 返回可以下载的产物，按修改时间从新到旧排序
+func (s *Server) artifactFiles() ([]File, error) {
+	var paths []string
+	for _, pattern := range artifactPatterns {
+		matches, err := filepath.Glob(filepath.Join(s.ClusterDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	return s.files(paths), nil
+}
+
+// files 返回 paths 中存在的普通文件，路径转换为相对于集群目录
+func (s *Server) files(paths []string) []File {
+	files := []File{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		rel, err := filepath.Rel(s.ClusterDir, path)
+		if err != nil || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		files = append(files, File{Path: filepath.ToSlash(rel), Size: info.Size(), Modified: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Modified.After(files[j].Modified) })
+	return files
+}
+
+// lookup 在 list 中查找 path，只允许访问面板列出的文件
+func (s *Server) lookup(list []File, path string) (string, bool) {
+	for _, file := range list {
+		if file.Path == path {
+			return filepath.Join(s.ClusterDir, filepath.FromSlash(file.Path)), true
+		}
+	}
+	return "", false
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	data, err := static.ReadFile("static/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.Status()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleLog 返回日志文件的末尾 maxLogBytes 字节
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	logs, err := s.logFiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	path, ok := s.lookup(logs, r.URL.Query().Get("path"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > maxLogBytes {
+		f.Seek(info.Size()-maxLogBytes, io.SeekStart)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.Copy(w, f)
+}
+
+func (s *Server) handleArtifact(w http.ResponseWriter, r *http.Request) {
+	artifacts, err := s.artifactFiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	path, ok := s.lookup(artifacts, r.URL.Query().Get("path"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+	http.ServeFile(w, r, path)
+}
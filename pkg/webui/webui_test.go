@@ -0,0 +1,131 @@
+package webui
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/report"
+	"ocpack/pkg/runner"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	fake := &runner.Fake{
+		Paths: map[string]string{"df": "/usr/bin/df"},
+		Handler: func(cmd runner.Command) (*runner.Result, error) {
+			return &runner.Result{Stdout: []byte("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 100 40 60 40% /\n")}, nil
+		},
+	}
+
+	clusterDir := t.TempDir()
+	logsDir := filepath.Join(clusterDir, "images", "working-dir", "logs")
+	writeFile(t, filepath.Join(logsDir, "oc-mirror.log"), "line 1\nline 2\n")
+	writeFile(t, filepath.Join(logsDir, "progress.json"),
+		`{"function": "copy", "phase": "copy release", "total": 10, "completed": 4, "succeeded": 3, "failed": 1, "updated": "2024-05-01T10:00:00Z"}`)
+	writeFile(t, filepath.Join(clusterDir, "installation", "iso", "demo-agent.x86_64.iso"), "iso")
+	writeFile(t, filepath.Join(clusterDir, "config.toml"), "secret")
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	for _, run := range []report.StageRun{
+		{Stage: "day2_operatorhub", Start: start.Add(time.Hour), Status: report.StatusSuccess},
+		{Stage: "download", Start: start, Duration: 5 * time.Minute, Status: report.StatusSuccess},
+	} {
+		if err := report.Record(clusterDir, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewServer("demo", clusterDir)
+	s.Runner = fake
+	server := httptest.NewServer(s.Handler())
+	t.Cleanup(server.Close)
+	return server, clusterDir
+}
+
+func get(t *testing.T, rawURL string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestStatus(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	code, body := get(t, server.URL+"/api/status")
+	if code != http.StatusOK {
+		t.Fatalf("GET /api/status = %d: %s", code, body)
+	}
+	var status Status
+	if err := json.Unmarshal([]byte(body), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(status.Stages) != len(Pipeline)+1 {
+		t.Fatalf("stages = %+v", status.Stages)
+	}
+	if status.Stages[0].Name != "download" || status.Stages[0].Run == nil || status.Stages[1].Run != nil {
+		t.Errorf("pipeline stages = %+v", status.Stages[:2])
+	}
+	if last := status.Stages[len(status.Stages)-1]; last.Name != "day2_operatorhub" || last.Run == nil {
+		t.Errorf("extra stage = %+v", last)
+	}
+	if status.Mirror == nil || status.Mirror.Completed != 4 || status.Mirror.Phase != "copy release" {
+		t.Errorf("mirror progress = %+v", status.Mirror)
+	}
+	if status.Disk.Available != 60*1024 || len(status.Disk.Dirs) != 2 {
+		t.Errorf("disk = %+v", status.Disk)
+	}
+	if len(status.Logs) != 1 || status.Logs[0].Path != "images/working-dir/logs/oc-mirror.log" {
+		t.Errorf("logs = %+v", status.Logs)
+	}
+	if len(status.Artifacts) != 1 || status.Artifacts[0].Path != "installation/iso/demo-agent.x86_64.iso" {
+		t.Errorf("artifacts = %+v", status.Artifacts)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	if code, body := get(t, server.URL+"/api/log?path="+url.QueryEscape("images/working-dir/logs/oc-mirror.log")); code != http.StatusOK || body != "line 1\nline 2\n" {
+		t.Errorf("GET /api/log = %d %q", code, body)
+	}
+	if code, body := get(t, server.URL+"/artifacts?path="+url.QueryEscape("installation/iso/demo-agent.x86_64.iso")); code != http.StatusOK || body != "iso" {
+		t.Errorf("GET /artifacts = %d %q", code, body)
+	}
+	// 只能访问面板列出的文件
+	for _, path := range []string{"/api/log?path=config.toml", "/artifacts?path=../config.toml", "/artifacts?path=" + url.QueryEscape("images/working-dir/logs/oc-mirror.log")} {
+		if code, _ := get(t, server.URL+path); code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, code)
+		}
+	}
+
+	if code, body := get(t, server.URL+"/"); code != http.StatusOK || !strings.Contains(body, "/api/status") {
+		t.Errorf("GET / = %d", code)
+	}
+}
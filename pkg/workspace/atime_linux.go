@@ -0,0 +1,17 @@
+//go:build linux
+
+package workspace
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime 返回文件的最近访问时间，无法获取时使用修改时间
+func accessTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atim.Sec, stat.Atim.Nsec)
+	}
+	return info.ModTime()
+}
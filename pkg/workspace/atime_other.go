@@ -0,0 +1,13 @@
+//go:build !linux
+
+package workspace
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime 返回文件的修改时间，非 Linux 平台不读取访问时间
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
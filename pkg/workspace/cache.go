@@ -0,0 +1,284 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheResult 本地缓存的清理结果
+type CacheResult struct {
+	SizeBefore int64    // 清理前的缓存大小
+	Blobs      int      // 删除的 blob 数量
+	Images     []string // 因 blob 被删除而从缓存中移除的镜像，如 openshift/release:4.16.3-x86_64 或 <仓库>@sha256:...
+	Reclaimed  int64
+}
+
+// cacheBlob 本地缓存 registry 中的一个 blob
+type cacheBlob struct {
+	digest string // sha256:<摘要>
+	dir    string
+	size   int64
+	atime  time.Time
+}
+
+// CacheDir 返回集群的 oc-mirror 本地缓存目录 (--cache-dir)
+func CacheDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "cache")
+}
+
+// cacheRegistryDir 返回本地缓存 registry 的存储目录，与 oc-mirror 在 --cache-dir 下使用的 .oc-mirror/.cache 一致
+func cacheRegistryDir(cacheDir string) string {
+	return filepath.Join(cacheDir, ".oc-mirror", ".cache", "docker", "registry", "v2")
+}
+
+// PruneCache 按最近访问时间 (LRU) 删除本地缓存中的 blob，直到缓存不超过 maxSize 字节。
+// 引用了已删除 blob 的镜像清单及其标签一并移除，下次执行时重新复制这些镜像，而不是将不完整的镜像视为已缓存。
+// dryRun 为 true 时只计算将要删除的内容
+func PruneCache(cacheDir string, maxSize int64, dryRun bool) (*CacheResult, error) {
+	result := &CacheResult{SizeBefore: DiskUsage(cacheDir)}
+	if result.SizeBefore <= maxSize {
+		return result, nil
+	}
+
+	root := cacheRegistryDir(cacheDir)
+	blobs, err := listCacheBlobs(filepath.Join(root, "blobs", "sha256"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].atime.Before(blobs[j].atime) })
+
+	removed := make(map[string]bool)
+	byDigest := make(map[string]cacheBlob, len(blobs))
+	for _, blob := range blobs {
+		byDigest[blob.digest] = blob
+	}
+	for _, blob := range blobs {
+		if result.SizeBefore-result.Reclaimed <= maxSize {
+			break
+		}
+		removed[blob.digest] = true
+		result.Reclaimed += blob.size
+	}
+
+	// 清单引用的 blob (层、配置或子清单) 被删除时，清单本身也需要删除，直到没有新的不完整清单
+	manifests, err := listCacheManifests(filepath.Join(root, "repositories"))
+	if err != nil {
+		return nil, err
+	}
+	for changed := true; changed; {
+		changed = false
+		for digest := range manifests {
+			if removed[digest] {
+				continue
+			}
+			blob, ok := byDigest[digest]
+			if !ok {
+				continue
+			}
+			if referencesAny(filepath.Join(blob.dir, "data"), removed) {
+				removed[digest] = true
+				result.Reclaimed += blob.size
+				changed = true
+			}
+		}
+	}
+
+	for digest := range removed {
+		result.Blobs++
+		if !dryRun {
+			if err := os.RemoveAll(byDigest[digest].dir); err != nil {
+				return result, fmt.Errorf("删除 %s 失败: %w", byDigest[digest].dir, err)
+			}
+		}
+	}
+	images, err := removeCacheLinks(filepath.Join(root, "repositories"), removed, dryRun)
+	result.Images = images
+	return result, err
+}
+
+// listCacheBlobs 返回 blobs/sha256 下的全部 blob，每个 blob 是 <前两位>/<摘要>/data
+func listCacheBlobs(dir string) ([]cacheBlob, error) {
+	var blobs []cacheBlob
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "data" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		blobDir := filepath.Dir(path)
+		blobs = append(blobs, cacheBlob{
+			digest: "sha256:" + filepath.Base(blobDir),
+			dir:    blobDir,
+			size:   info.Size(),
+			atime:  accessTime(info),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录 %s 失败: %w", dir, err)
+	}
+	return blobs, nil
+}
+
+// listCacheManifests 返回各仓库 _manifests/revisions 中记录的清单摘要
+func listCacheManifests(dir string) (map[string]bool, error) {
+	manifests := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "_layers" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == "link" && strings.Contains(path, string(filepath.Separator)+"revisions"+string(filepath.Separator)) {
+			manifests["sha256:"+filepath.Base(filepath.Dir(path))] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录 %s 失败: %w", dir, err)
+	}
+	return manifests, nil
+}
+
+// referencesAny 判断清单或清单列表是否引用了 digests 中的 blob
+func referencesAny(path string, digests map[string]bool) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var manifest struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Layers []struct {
+			Digest string `json:"digest"`
+		} `json:"layers"`
+		Manifests []struct {
+			Digest string `json:"digest"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+	if digests[manifest.Config.Digest] {
+		return true
+	}
+	for _, layer := range manifest.Layers {
+		if digests[layer.Digest] {
+			return true
+		}
+	}
+	for _, child := range manifest.Manifests {
+		if digests[child.Digest] {
+			return true
+		}
+	}
+	return false
+}
+
+// removeCacheLinks 删除仓库中指向已删除 blob 的层、清单和标签链接，返回被移除的镜像。
+// 有标签的清单以 <仓库>:<标签> 表示，没有标签的清单 (如按 digest 镜像的 release) 以 <仓库>@<摘要> 表示
+func removeCacheLinks(dir string, removed map[string]bool, dryRun bool) ([]string, error) {
+	var images, revisions, links []string
+	tagged := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "link" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || !removed[strings.TrimSpace(string(content))] {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		switch {
+		case hasSegments(parts, "_manifests", "tags") && parts[len(parts)-2] == "current":
+			repo, tag := repositoryOf(parts, "_manifests"), parts[len(parts)-3]
+			images = append(images, repo+":"+tag)
+			tagged[repo+"@"+strings.TrimSpace(string(content))] = true
+			links = append(links, filepath.Dir(filepath.Dir(path)))
+		case hasSegments(parts, "_manifests", "tags"):
+			// 标签的历史索引 index/sha256/<摘要>/link
+			links = append(links, filepath.Dir(path))
+		case hasSegments(parts, "_manifests", "revisions"):
+			revisions = append(revisions, repositoryOf(parts, "_manifests")+"@"+strings.TrimSpace(string(content)))
+			links = append(links, filepath.Dir(path))
+		case hasSegments(parts, "_layers"):
+			links = append(links, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存目录 %s 失败: %w", dir, err)
+	}
+	for _, revision := range revisions {
+		if !tagged[revision] {
+			images = append(images, revision)
+		}
+	}
+	if !dryRun {
+		for _, link := range links {
+			if err := os.RemoveAll(link); err != nil {
+				return images, fmt.Errorf("删除 %s 失败: %w", link, err)
+			}
+		}
+	}
+	sort.Strings(images)
+	return slices.Compact(images), nil
+}
+
+// hasSegments 判断路径中是否依次包含 segments
+func hasSegments(parts []string, segments ...string) bool {
+	for i := range parts {
+		if i+len(segments) > len(parts) {
+			return false
+		}
+		match := true
+		for j, segment := range segments {
+			if parts[i+j] != segment {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryOf 返回 marker (_manifests 或 _layers) 之前的仓库路径
+func repositoryOf(parts []string, marker string) string {
+	for i, part := range parts {
+		if part == marker {
+			return strings.Join(parts[:i], "/")
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,113 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCacheRegistry 在 cacheDir 中构造本地缓存 registry：ubi8/ubi:old 使用较早访问的层 a1，
+// ubi8/ubi:new 使用最近访问的层 b2，两者共用配置 c3；openshift/release 按 digest 保存，使用层 e5
+func writeCacheRegistry(t *testing.T, cacheDir string) string {
+	t.Helper()
+	root := cacheRegistryDir(cacheDir)
+	now := time.Now()
+	blob := func(hex, content string, atime time.Time) {
+		writeFile(t, filepath.Join(root, "blobs", "sha256", hex[:2], hex, "data"), content, atime)
+	}
+	link := func(path, digest string) {
+		writeFile(t, filepath.Join(root, "repositories", path, "link"), digest, time.Time{})
+	}
+
+	blob("a1", strings.Repeat("a", 1000), now.Add(-3*time.Hour))
+	blob("b2", strings.Repeat("b", 1000), now.Add(-time.Hour))
+	blob("c3", "{}", now.Add(-time.Hour))
+	blob("d4", `{"config":{"digest":"sha256:c3"},"layers":[{"digest":"sha256:a1"}]}`, now.Add(-time.Hour))
+	blob("f6", `{"config":{"digest":"sha256:c3"},"layers":[{"digest":"sha256:b2"}]}`, now.Add(-time.Hour))
+	blob("e5", strings.Repeat("e", 1000), now.Add(-2*time.Hour))
+	blob("g7", `{"layers":[{"digest":"sha256:e5"}]}`, now.Add(-time.Hour))
+
+	for _, hex := range []string{"a1", "b2", "c3"} {
+		link("ubi8/ubi/_layers/sha256/"+hex, "sha256:"+hex)
+	}
+	link("ubi8/ubi/_manifests/revisions/sha256/d4", "sha256:d4")
+	link("ubi8/ubi/_manifests/revisions/sha256/f6", "sha256:f6")
+	link("ubi8/ubi/_manifests/tags/old/current", "sha256:d4")
+	link("ubi8/ubi/_manifests/tags/old/index/sha256/d4", "sha256:d4")
+	link("ubi8/ubi/_manifests/tags/new/current", "sha256:f6")
+	link("ubi8/ubi/_manifests/tags/new/index/sha256/f6", "sha256:f6")
+	link("openshift/release/_layers/sha256/e5", "sha256:e5")
+	link("openshift/release/_manifests/revisions/sha256/g7", "sha256:g7")
+	return root
+}
+
+func TestPruneCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	root := writeCacheRegistry(t, cacheDir)
+	size := DiskUsage(cacheDir)
+
+	// 超出上限不到 1000 字节时只删除最久未访问的层 a1，以及引用它的清单 d4
+	result, err := PruneCache(cacheDir, size-500, false)
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if result.SizeBefore != size || result.Blobs != 2 {
+		t.Errorf("PruneCache() = %+v, want 2 blobs removed from %d bytes", result, size)
+	}
+	if want := []string{"ubi8/ubi:old"}; !reflect.DeepEqual(result.Images, want) {
+		t.Errorf("Images = %v, want %v", result.Images, want)
+	}
+	for _, path := range []string{
+		"blobs/sha256/a1/a1",
+		"blobs/sha256/d4/d4",
+		"repositories/ubi8/ubi/_layers/sha256/a1",
+		"repositories/ubi8/ubi/_manifests/revisions/sha256/d4",
+		"repositories/ubi8/ubi/_manifests/tags/old",
+	} {
+		if _, err := os.Stat(filepath.Join(root, path)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	for _, path := range []string{
+		"blobs/sha256/b2/b2/data",
+		"blobs/sha256/c3/c3/data",
+		"blobs/sha256/f6/f6/data",
+		"repositories/ubi8/ubi/_layers/sha256/c3/link",
+		"repositories/ubi8/ubi/_manifests/tags/new/current/link",
+	} {
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			t.Errorf("%s should be kept: %v", path, err)
+		}
+	}
+
+	// 未超过上限时不删除
+	result, err = PruneCache(cacheDir, size, false)
+	if err != nil || result.Blobs != 0 {
+		t.Errorf("PruneCache() = %+v, %v, want nothing removed", result, err)
+	}
+}
+
+func TestPruneCacheDryRun(t *testing.T) {
+	cacheDir := t.TempDir()
+	root := writeCacheRegistry(t, cacheDir)
+	size := DiskUsage(cacheDir)
+
+	// 需要释放 1000 字节以上时依次删除 a1 和 e5，release 按 digest 保存，以 <仓库>@<摘要> 表示
+	result, err := PruneCache(cacheDir, size-1500, true)
+	if err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	want := []string{"openshift/release@sha256:g7", "ubi8/ubi:old"}
+	if result.Blobs != 4 || !reflect.DeepEqual(result.Images, want) {
+		t.Errorf("PruneCache() = %+v, want 4 blobs and images %v", result, want)
+	}
+	if DiskUsage(cacheDir) != size {
+		t.Errorf("dry run should not remove anything")
+	}
+	if _, err := os.Stat(filepath.Join(root, "repositories/ubi8/ubi/_manifests/tags/old/current/link")); err != nil {
+		t.Errorf("dry run should keep tags: %v", err)
+	}
+}
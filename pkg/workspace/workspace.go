@@ -0,0 +1,285 @@
+// Package workspace 清理 oc-mirror 工作目录中多次执行积累的旧文件：旧的 results-* 目录、
+// 不在当前 release 范围内的签名以及旧的日志。最新的 results 目录、cluster-resources、
+// hold-release 等 generate-iso 和 day2 依赖的内容始终保留。
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ocpack/pkg/config"
+	"ocpack/pkg/utils"
+)
+
+// 清理项的类型
+const (
+	KindResults   = "results"
+	KindSignature = "signature"
+	KindLog       = "log"
+)
+
+// Item 一个待清理的文件或目录
+type Item struct {
+	Path string
+	Kind string
+	Size int64
+}
+
+// Result 清理结果
+type Result struct {
+	Items     []Item
+	Reclaimed int64
+}
+
+// releaseArchSuffixes release 镜像标签中的架构后缀
+var releaseArchSuffixes = []string{"-x86_64", "-aarch64", "-s390x", "-ppc64le", "-multi"}
+
+// digitsPattern 去掉日志文件名中的时间戳，得到同类日志的分组键
+var digitsPattern = regexp.MustCompile(`\d+`)
+
+// sha256HexPattern 签名文件名中的 sha256 摘要
+var sha256HexPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// WorkingDir 返回集群的 oc-mirror 工作目录
+func WorkingDir(clusterDir string) string {
+	return filepath.Join(clusterDir, "images", "working-dir")
+}
+
+// workspaceDirs 可能包含 results-* 目录的工作空间，与 imagepolicy 和 day2 查找最新 results 目录的位置一致
+func workspaceDirs(clusterDir string) []string {
+	return []string{
+		filepath.Join(clusterDir, "working-dir"),
+		WorkingDir(clusterDir),
+		filepath.Join(clusterDir, "oc-mirror-workspace"),
+		filepath.Join(clusterDir, "images", "oc-mirror-workspace"),
+	}
+}
+
+// LogsDirs 返回 oc-mirror 可能写入日志的目录。oc-mirror 在 --workspace 或 file:// 目标下创建 working-dir，
+// 因此日志位于各工作空间的 logs 或 working-dir/logs 中
+func LogsDirs(clusterDir string) []string {
+	var dirs []string
+	for _, dir := range workspaceDirs(clusterDir) {
+		dirs = append(dirs, filepath.Join(dir, "logs"), filepath.Join(dir, "working-dir", "logs"))
+	}
+	return dirs
+}
+
+// Clean 清理集群的 oc-mirror 工作目录，每类内容保留最新的 keep 份。dryRun 为 true 时只返回待清理的内容
+func Clean(cfg *config.ClusterConfig, clusterDir string, keep int, dryRun bool) (*Result, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("--keep 必须大于 0")
+	}
+
+	var items []Item
+	for _, dir := range workspaceDirs(clusterDir) {
+		found, err := oldResultsDirs(dir, keep)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	signatures, err := staleSignatures(cfg, filepath.Join(WorkingDir(clusterDir), "signatures"))
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, signatures...)
+	logs, err := oldLogs(filepath.Join(WorkingDir(clusterDir), "logs"), keep)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, logs...)
+
+	result := &Result{}
+	for _, item := range items {
+		item.Size = DiskUsage(item.Path)
+		if !dryRun {
+			if err := os.RemoveAll(item.Path); err != nil {
+				return result, fmt.Errorf("删除 %s 失败: %w", item.Path, err)
+			}
+		}
+		result.Items = append(result.Items, item)
+		result.Reclaimed += item.Size
+	}
+	return result, nil
+}
+
+// oldResultsDirs 返回工作空间中除最新 keep 个非空目录以外的 results-* 目录，空目录总是可以清理
+func oldResultsDirs(workspace string, keep int) ([]Item, error) {
+	entries, err := readDir(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	type resultsDir struct {
+		path      string
+		timestamp int64
+	}
+	var dirs []resultsDir
+	var items []Item
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "results-") {
+			continue
+		}
+		path := filepath.Join(workspace, entry.Name())
+		timestamp, err := utils.ParseTimestamp(strings.TrimPrefix(entry.Name(), "results-"))
+		if err != nil {
+			continue // 无法识别的目录不处理
+		}
+		if children, _ := os.ReadDir(path); len(children) == 0 {
+			items = append(items, Item{Path: path, Kind: KindResults})
+			continue
+		}
+		dirs = append(dirs, resultsDir{path: path, timestamp: timestamp})
+	}
+
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].timestamp > dirs[j].timestamp })
+	for i := keep; i < len(dirs); i++ {
+		items = append(items, Item{Path: dirs[i].path, Kind: KindResults})
+	}
+	return items, nil
+}
+
+// staleSignatures 返回版本不在当前 release 范围内的签名文件。签名文件名为 <标签>-sha256-<摘要>，
+// 最短升级路径中的中间版本都位于范围内，因此范围内的签名全部保留
+func staleSignatures(cfg *config.ClusterConfig, dir string) ([]Item, error) {
+	entries, err := readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	minVersion, maxVersion := cfg.GetReleaseRange()
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version := signatureVersion(entry.Name())
+		if !utils.IsValidVersionFormat(version) {
+			continue
+		}
+		if utils.CompareVersion(version, minVersion) < 0 || utils.CompareVersion(version, maxVersion) > 0 {
+			items = append(items, Item{Path: filepath.Join(dir, entry.Name()), Kind: KindSignature})
+		}
+	}
+	return items, nil
+}
+
+// signatureVersion 从签名文件名中提取 release 版本，如 4.16.3-x86_64-sha256-abcd 对应 4.16.3
+func signatureVersion(name string) string {
+	tag, _, found := strings.Cut(name, "-sha256-")
+	if !found {
+		return ""
+	}
+	for _, suffix := range releaseArchSuffixes {
+		tag = strings.TrimSuffix(tag, suffix)
+	}
+	return tag
+}
+
+// ReleaseDigest 从 workingDir 的签名文件中查找 release 版本 version 的镜像摘要，返回 sha256:<摘要>。
+// 签名在 save-image 时随 release 一起下载并校验过，因此摘要就是实际镜像的内容。没有该版本的签名时返回空字符串，
+// 同一版本存在多个不同摘要时返回错误
+func ReleaseDigest(workingDir, version string) (string, error) {
+	entries, err := readDir(filepath.Join(workingDir, "signatures"))
+	if err != nil {
+		return "", err
+	}
+
+	var digest string
+	for _, entry := range entries {
+		if entry.IsDir() || signatureVersion(entry.Name()) != version {
+			continue
+		}
+		_, hex, _ := strings.Cut(entry.Name(), "-sha256-")
+		if !sha256HexPattern.MatchString(hex) {
+			continue
+		}
+		if digest != "" && digest != "sha256:"+hex {
+			return "", fmt.Errorf("release %s 存在多个不同的签名摘要: %s, sha256:%s", version, digest, hex)
+		}
+		digest = "sha256:" + hex
+	}
+	return digest, nil
+}
+
+// oldLogs 返回日志目录中每类日志除最新 keep 个以外的文件，文件名去掉时间戳后相同的视为同一类
+func oldLogs(dir string, keep int) ([]Item, error) {
+	entries, err := readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type logFile struct {
+		path    string
+		modTime int64
+	}
+	groups := make(map[string][]logFile)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		key := digitsPattern.ReplaceAllString(entry.Name(), "")
+		groups[key] = append(groups[key], logFile{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime().UnixNano()})
+	}
+
+	var items []Item
+	for _, files := range groups {
+		sort.Slice(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+		for i := keep; i < len(files); i++ {
+			items = append(items, Item{Path: files[i].path, Kind: KindLog})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items, nil
+}
+
+// readDir 读取目录，目录不存在时返回空列表
+func readDir(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", dir, err)
+	}
+	return entries, nil
+}
+
+// DiskUsage 返回文件或目录的总大小，路径不存在时返回 0
+func DiskUsage(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// FormatSize 将字节数格式化为便于阅读的大小，如 1.5 GiB
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
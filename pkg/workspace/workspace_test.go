@@ -0,0 +1,212 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ocpack/pkg/config"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime.IsZero() {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testConfig() *config.ClusterConfig {
+	cfg := &config.ClusterConfig{}
+	cfg.ClusterInfo.OpenShiftVersion = "4.16.3"
+	cfg.SaveImage.OpenShiftVersionMax = "4.16.5"
+	return cfg
+}
+
+func relPaths(t *testing.T, clusterDir string, items []Item) []string {
+	t.Helper()
+	var paths []string
+	for _, item := range items {
+		rel, err := filepath.Rel(clusterDir, item.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestClean(t *testing.T) {
+	clusterDir := t.TempDir()
+	workingDir := WorkingDir(clusterDir)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	legacy := filepath.Join(clusterDir, "images", "oc-mirror-workspace")
+	writeFile(t, filepath.Join(legacy, "results-1700000000", "mapping.txt"), "old", time.Time{})
+	writeFile(t, filepath.Join(legacy, "results-1700000100", "mapping.txt"), "newer", time.Time{})
+	writeFile(t, filepath.Join(legacy, "results-1700000200", "mapping.txt"), "newest", time.Time{})
+	if err := os.MkdirAll(filepath.Join(legacy, "results-1700000300"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(legacy, "publish", ".metadata.json"), "{}", time.Time{})
+
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.15.9-x86_64-sha256-aaaa"), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.3-x86_64-sha256-bbbb"), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.5-x86_64-sha256-cccc"), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.17.0-x86_64-sha256-dddd"), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "unknown"), "sig", time.Time{})
+
+	writeFile(t, filepath.Join(workingDir, "logs", "oc-mirror_20240501_100000.log"), "log", base)
+	writeFile(t, filepath.Join(workingDir, "logs", "oc-mirror_20240502_100000.log"), "log", base.Add(time.Hour))
+	writeFile(t, filepath.Join(workingDir, "logs", "mirroring_errors_20240501_100000.txt"), "err", base)
+
+	writeFile(t, filepath.Join(workingDir, "cluster-resources", "idms-oc-mirror.yaml"), "idms", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "hold-release", "graph.tar"), "graph", time.Time{})
+
+	result, err := Clean(testConfig(), clusterDir, 1, true)
+	if err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	want := []string{
+		"images/oc-mirror-workspace/results-1700000000",
+		"images/oc-mirror-workspace/results-1700000100",
+		"images/oc-mirror-workspace/results-1700000300",
+		"images/working-dir/logs/oc-mirror_20240501_100000.log",
+		"images/working-dir/signatures/4.15.9-x86_64-sha256-aaaa",
+		"images/working-dir/signatures/4.17.0-x86_64-sha256-dddd",
+	}
+	got := relPaths(t, clusterDir, result.Items)
+	if len(got) != len(want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("items = %v, want %v", got, want)
+		}
+	}
+	// old + newer + 两个签名 + 一个日志
+	if result.Reclaimed != int64(len("old")+len("newer")+2*len("sig")+len("log")) {
+		t.Errorf("Reclaimed = %d", result.Reclaimed)
+	}
+
+	// dry-run 不删除任何文件
+	for _, item := range result.Items {
+		if _, err := os.Stat(item.Path); err != nil {
+			t.Errorf("dry-run removed %s", item.Path)
+		}
+	}
+
+	if _, err := Clean(testConfig(), clusterDir, 1, false); err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	for _, item := range result.Items {
+		if _, err := os.Stat(item.Path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", item.Path)
+		}
+	}
+	for _, kept := range []string{
+		filepath.Join(legacy, "results-1700000200", "mapping.txt"),
+		filepath.Join(legacy, "publish", ".metadata.json"),
+		filepath.Join(workingDir, "signatures", "4.16.3-x86_64-sha256-bbbb"),
+		filepath.Join(workingDir, "signatures", "unknown"),
+		filepath.Join(workingDir, "logs", "mirroring_errors_20240501_100000.txt"),
+		filepath.Join(workingDir, "cluster-resources", "idms-oc-mirror.yaml"),
+		filepath.Join(workingDir, "hold-release", "graph.tar"),
+	} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s should be kept: %v", kept, err)
+		}
+	}
+}
+
+func TestCleanKeep(t *testing.T) {
+	clusterDir := t.TempDir()
+	for _, ts := range []string{"1700000000", "1700000100", "1700000200"} {
+		writeFile(t, filepath.Join(clusterDir, "working-dir", "results-"+ts, "mapping.txt"), ts, time.Time{})
+	}
+
+	result, err := Clean(testConfig(), clusterDir, 2, true)
+	if err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	got := relPaths(t, clusterDir, result.Items)
+	if len(got) != 1 || got[0] != "working-dir/results-1700000000" {
+		t.Errorf("items = %v", got)
+	}
+
+	if _, err := Clean(testConfig(), clusterDir, 0, true); err == nil {
+		t.Error("expected error for keep 0")
+	}
+}
+
+func TestCleanMissingWorkspace(t *testing.T) {
+	result, err := Clean(testConfig(), t.TempDir(), 1, false)
+	if err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	if len(result.Items) != 0 || result.Reclaimed != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestSignatureVersion(t *testing.T) {
+	tests := map[string]string{
+		"4.16.3-x86_64-sha256-abcd":  "4.16.3",
+		"4.16.3-aarch64-sha256-abcd": "4.16.3",
+		"4.16.3-sha256-abcd":         "4.16.3",
+		"sha256-abcd":                "",
+		"README":                     "",
+	}
+	for name, want := range tests {
+		if got := signatureVersion(name); got != want {
+			t.Errorf("signatureVersion(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestReleaseDigest(t *testing.T) {
+	workingDir := t.TempDir()
+	digest := strings.Repeat("ab", 32)
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.3-x86_64-sha256-"+digest), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.5-x86_64-sha256-"+strings.Repeat("cd", 32)), "sig", time.Time{})
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.3-x86_64-sha256-short"), "sig", time.Time{})
+
+	if got, err := ReleaseDigest(workingDir, "4.16.3"); err != nil || got != "sha256:"+digest {
+		t.Errorf("ReleaseDigest(4.16.3) = %q, %v", got, err)
+	}
+	if got, err := ReleaseDigest(workingDir, "4.17.0"); err != nil || got != "" {
+		t.Errorf("ReleaseDigest(4.17.0) = %q, %v, expected no digest", got, err)
+	}
+	if got, err := ReleaseDigest(t.TempDir(), "4.16.3"); err != nil || got != "" {
+		t.Errorf("ReleaseDigest without signatures = %q, %v", got, err)
+	}
+
+	writeFile(t, filepath.Join(workingDir, "signatures", "4.16.3-sha256-"+strings.Repeat("ef", 32)), "sig", time.Time{})
+	if _, err := ReleaseDigest(workingDir, "4.16.3"); err == nil {
+		t.Error("expected error for conflicting digests")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512 B",
+		1536:                   "1.5 KiB",
+		5 * 1024 * 1024 * 1024: "5.0 GiB",
+	}
+	for bytes, want := range tests {
+		if got := FormatSize(bytes); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}